@@ -1,68 +1,32 @@
 package main
 
 import (
+	"agrisa_utils/logging"
+	"agrisa_utils/shutdown"
 	"auth-service/internal/config"
 	"auth-service/internal/database/minio"
 	"auth-service/internal/database/postgres"
 	"auth-service/internal/database/redis"
+	"auth-service/internal/ekyc"
 	"auth-service/internal/event"
 	"auth-service/internal/handlers"
+	"auth-service/internal/pii"
 	"auth-service/internal/repository"
 	"auth-service/internal/services"
 	"auth-service/utils"
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
-	"path/filepath"
 	"time"
 
+	"agrisa/migration"
+
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 )
 
-func setupLogging() (*os.File, error) {
-	// test
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic: %v\n", r)
-		}
-	}()
-
-	logDir := filepath.Join("/agrisa", "log", "auth_service")
-	fmt.Println("Log directory:", logDir)
-	err := os.MkdirAll(logDir, 0o755)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %v", err)
-	}
-
-	currentTime := time.Now()
-	logFileName := fmt.Sprintf("log_%s.log", currentTime.Format("2006-01-02"))
-	logFile := filepath.Join(logDir, logFileName)
-
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
-	}
-
-	if _, err := os.Stat(logFile); err == nil {
-		absPath, err := filepath.Abs(logFile)
-		if err != nil {
-			fmt.Printf("Failed to get absolute path of log file: %v\n", err)
-		} else {
-			fmt.Printf("Log file exists at absolute path: %s\n", absPath)
-		}
-	} else if os.IsNotExist(err) {
-		fmt.Println("Log file does not exist (it will be created)")
-	} else {
-		fmt.Printf("Error checking log file existence: %v\n", err)
-	}
-
-	log.SetOutput(file)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-
-	return file, nil
-}
-
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -71,7 +35,7 @@ func getEnvOrDefault(key, defaultValue string) string {
 }
 
 func main() {
-	logFile, err := setupLogging()
+	logFile, err := logging.Setup("auth_service")
 	if err != nil {
 		log.Fatalf("Failed to set up logging: %v", err)
 	}
@@ -82,6 +46,9 @@ func main() {
 		cfg.PostgresCfg.Host, cfg.PostgresCfg.Port, cfg.PostgresCfg.Username)
 
 	db, err := postgres.ConnectAndCreateDB(cfg.PostgresCfg)
+	if errors.Is(err, migration.ErrSchemaOutdated) {
+		log.Fatalf("Refusing to start, run cmd/migrate first: %v", err)
+	}
 	if err != nil {
 		log.Printf("error connect to database: %s", err)
 		go postgres.RetryConnectOnFailed(30*time.Second, &db, cfg.PostgresCfg)
@@ -106,29 +73,60 @@ func main() {
 	if err != nil {
 		log.Fatalf("CRITICAL: Cannot start policy service without RabbitMQ connection: %v", err)
 	}
-	defer rabbitConn.Close()
 
 	notificationPublisher := event.NewNotificationPublisher(rabbitConn)
+	accountEventPublisher := event.NewAccountEventPublisher(rabbitConn)
+	piiCipher, err := pii.NewCipher(cfg.AuthCfg.PIIEncryptionKeys, cfg.AuthCfg.PIIIndexKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize PII encryption: %v", err)
+	}
+
 	// repositories
-	userRepo := repository.NewUserRepository(db)
-	userCardRepo := repository.NewUserCardRepository(db)
-	ekycProgressRepo := repository.NewUserEkycProgressRepository(db)
+	userRepo := repository.NewUserRepository(db, piiCipher)
+	userCardRepo := repository.NewUserCardRepository(db, piiCipher)
+	ekycProgressRepo := repository.NewUserEkycProgressRepository(db, piiCipher)
 	roleRepo := repository.NewRoleRepository(db)
 	sessionRepo := repository.NewSessionRepository(redisClient.GetClient())
+	consentRepo := repository.NewConsentRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	ekycJobRepo := repository.NewEkycJobRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
 
 	// services
 	jwtService := services.NewJWTService(cfg.AuthCfg.JWTSecret)
 	roleService := services.NewRoleService(roleRepo)
 	sessionService := services.NewSessionService(sessionRepo)
-	userService := services.NewUserService(userRepo, mc, cfg, utils, userCardRepo, ekycProgressRepo, sessionService, jwtService, roleService, notificationPublisher)
+	consentService := services.NewConsentService(consentRepo, accountEventPublisher, roleService)
+	mfaService, err := services.NewMFAService(mfaRepo, userRepo, roleService, redisClient.GetClient(), cfg.AuthCfg.MFAEncryptionKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize MFA service: %v", err)
+	}
+	ekycProvider, err := ekyc.NewProvider(&cfg.AuthCfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize eKYC provider: %v", err)
+	}
+	log.Printf("Using eKYC provider %s", ekycProvider.Name())
+	ekycOrchestrator := services.NewEkycOrchestrator(ekycProgressRepo, userRepo, userCardRepo, mc,
+		ekycProvider, services.NewEkycStepCache(redisClient.GetClient()),
+		services.NewEkycWebhookNotifier(cfg.AuthCfg.EkycWebhookURLs, cfg.AuthCfg.EkycWebhookSecret), notificationPublisher)
+	ekycJobService := services.NewEkycJobService(ekycJobRepo, ekycOrchestrator, event.NewEkycJobQueueClient(rabbitConn), mc, utils, notificationPublisher)
+	userService := services.NewUserService(userRepo, mc, cfg, utils, userCardRepo, ekycProgressRepo, sessionService, jwtService, roleService, mfaService, ekycOrchestrator, notificationPublisher, accountEventPublisher, auditRepo)
+	impersonationService := services.NewImpersonationService(impersonationRepo, userRepo, roleService, sessionService, jwtService, notificationPublisher)
+	adminService := services.NewAdminService(userRepo, auditRepo, roleService, sessionService)
 	// handlers
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, ekycJobService)
 	authHandler := handlers.NewAuthHandler(userService, roleService)
-	middlewareHandler := handlers.NewMiddleware(jwtService, sessionService, &cfg.AuthCfg, roleService)
+	middlewareHandler := handlers.NewMiddleware(jwtService, sessionService, &cfg.AuthCfg, roleService, impersonationService)
 	roleHandler := handlers.NewRoleHandler(roleService)
+	consentHandler := handlers.NewConsentHandler(consentService, cfg.AuthCfg.APIKey)
+	impersonationHandler := handlers.NewImpersonationHandler(impersonationService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	adminHandler := handlers.NewAdminHandler(adminService)
 
 	// Setup Gin router
 	r := gin.Default()
+	r.Use(logging.GinMiddleware())
 	r.MaxMultipartMemory = 200 * 1024 * 1024
 
 	// Register routes
@@ -136,12 +134,28 @@ func main() {
 	authHandler.RegisterRoutes(r)
 	middlewareHandler.RegisterRoutes(r)
 	roleHandler.RegisterRoutes(r)
+	consentHandler.RegisterRoutes(r)
+	impersonationHandler.RegisterRoutes(r)
+	mfaHandler.RegisterRoutes(r)
+	adminHandler.RegisterRoutes(r)
 	roleHandler.InitDefaultRole()
 	err = authHandler.InitDefaultUser(*cfg)
 	if err != nil {
 		log.Printf("error initialize default users: %v", err)
 	}
 
+	coordinator := shutdown.New()
+
+	// Close impersonations whose time box has passed and notify the affected users
+	coordinator.Go("impersonation-expiry-watcher", func(ctx context.Context) {
+		impersonationService.StartExpiryWatcher(ctx, time.Minute)
+	})
+
+	// Run the OCR and face liveness jobs queued by the eKYC endpoints
+	coordinator.Go("ekyc-job-worker", func(ctx context.Context) {
+		ekycJobService.StartWorker(ctx, cfg.AuthCfg.EkycWorkerConcurrency)
+	})
+
 	// Start HTTP server
 	serverPort := os.Getenv("SERVER_PORT")
 	if serverPort == "" {
@@ -149,7 +163,20 @@ func main() {
 	}
 
 	log.Printf("Starting auth-service on port %s", serverPort)
-	if err := r.Run(":" + serverPort); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	coordinator.ListenAndServe("http", &http.Server{Addr: ":" + serverPort, Handler: r})
+
+	coordinator.Closer("postgres", func() error {
+		if db == nil {
+			return nil
+		}
+		return db.Close()
+	})
+	coordinator.Closer("redis", redisClient.Close)
+	coordinator.Closer("rabbitmq", rabbitConn.Close)
+
+	if err := coordinator.Wait(); err != nil {
+		log.Printf("auth-service stopped: %v", err)
+		logFile.Close()
+		os.Exit(1)
 	}
 }
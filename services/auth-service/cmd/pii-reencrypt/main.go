@@ -0,0 +1,42 @@
+// Command pii-reencrypt encrypts the national IDs, card numbers and addresses written before
+// field encryption, and moves values sealed with an older key to the current one. Run it after a
+// new key is put first in PII_ENCRYPTION_KEYS; the old key can be dropped once it reports no rows.
+//
+//	pii-reencrypt [-batch 500] [-dry-run]
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/database/postgres"
+	"auth-service/internal/pii"
+	"auth-service/internal/repository"
+	"flag"
+	"log"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 500, "rows read per query")
+	dryRun := flag.Bool("dry-run", false, "count the rows to re-encrypt without updating them")
+	flag.Parse()
+
+	cfg := config.New()
+	cfg.PostgresCfg.AutoMigrate = false
+	db, err := postgres.ConnectAndCreateDB(cfg.PostgresCfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	cipher, err := pii.NewCipher(cfg.AuthCfg.PIIEncryptionKeys, cfg.AuthCfg.PIIIndexKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize PII encryption: %v", err)
+	}
+
+	updated, err := repository.NewPIIReencryptor(db, cipher).Run(*batchSize, *dryRun)
+	for table, rows := range updated {
+		log.Printf("%s: %d rows", table, rows)
+	}
+	if err != nil {
+		log.Fatalf("Re-encryption failed: %v", err)
+	}
+}
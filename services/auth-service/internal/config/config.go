@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 type AuthServiceConfig struct {
 	Port        string
@@ -26,6 +29,10 @@ type PostgresConfig struct {
 	Password string
 	Host     string
 	Port     string
+	// Apply pending schema migrations when connecting, otherwise refuse a schema missing some
+	AutoMigrate bool
+	// Connect whatever the schema version, for cmd/migrate which manages the schema itself
+	SkipSchemaCheck bool
 }
 
 type RabbitMQConfig struct {
@@ -43,6 +50,8 @@ type RedisConfig struct {
 
 type AuthConfig struct {
 	JWTSecret          string
+	// eKYC vendor: fpt
+	EkycProvider       string
 	FptEkycApiKey      string
 	FptOcrUrl          string
 	FptFaceLivenessUrl string
@@ -50,17 +59,32 @@ type AuthConfig struct {
 	APIKey             string
 	CreateUserProfileURL string
 	CreateUserProfileHostAPI string
+	CscaCertDir        string
+	// Key the TOTP secrets of MFA are encrypted with, required; changing it invalidates every
+	// enrollment
+	MFAEncryptionKey string
+	// Comma separated "<id>:<secret>" keys encrypting national IDs and card data, newest first;
+	// older keys only decrypt. The index key hashes national IDs for lookups and must not change.
+	// Both are required; the service refuses to start without them.
+	PIIEncryptionKeys string
+	PIIIndexKey       string
+	// Comma separated URLs told when a user completes eKYC, and the secret signing the calls
+	EkycWebhookURLs   string
+	EkycWebhookSecret string
+	// OCR and face liveness jobs processed at the same time by this instance
+	EkycWorkerConcurrency int
 }
 
 func New() *AuthServiceConfig {
 	return &AuthServiceConfig{
 		Port: getEnvOrDefault("PORT", "8083"),
 		PostgresCfg: PostgresConfig{
-			DBname:   getEnvOrDefault("POSTGRES_DB", "agrisa"),
-			Username: getEnvOrDefault("POSTGRES_USER", "postgres"),
-			Password: getEnvOrDefault("POSTGRES_PASSWORD", "postgres"),
-			Host:     getEnvOrDefault("POSTGRES_HOST", "localhost"),
-			Port:     getEnvOrDefault("POSTGRES_PORT", "5432"),
+			DBname:      getEnvOrDefault("POSTGRES_DB", "agrisa"),
+			Username:    getEnvOrDefault("POSTGRES_USER", "postgres"),
+			Password:    getEnvOrDefault("POSTGRES_PASSWORD", "postgres"),
+			Host:        getEnvOrDefault("POSTGRES_HOST", "localhost"),
+			Port:        getEnvOrDefault("POSTGRES_PORT", "5432"),
+			AutoMigrate: getEnvOrDefault("DB_AUTO_MIGRATE", "true") == "true",
 		},
 		RabbitMQCfg: RabbitMQConfig{
 			Username: getEnvOrDefault("RABBITMQ_USER", "admin"),
@@ -69,6 +93,7 @@ func New() *AuthServiceConfig {
 		},
 		AuthCfg: AuthConfig{
 			JWTSecret:          getEnvOrDefault("JWT_SECRET", "default-secret"),
+			EkycProvider:       getEnvOrDefault("EKYC_PROVIDER", "fpt"),
 			FptEkycApiKey:      getEnvOrDefault("FPT_EKYC_API_KEY", ""),
 			FptOcrUrl:          getEnvOrDefault("FPT_OCR_URL", ""),
 			FptFaceLivenessUrl: getEnvOrDefault("FPT_FACE_LIVENESS_URL", ""),
@@ -76,6 +101,13 @@ func New() *AuthServiceConfig {
 			APIKey:             getEnvOrDefault("API_KEY", ""),
 			CreateUserProfileURL: getEnvOrDefault("CREATE_USER_PROFILE_URL", ""),
 			CreateUserProfileHostAPI: getEnvOrDefault("CREATE_USER_PROFILE_HOST_API", ""),
+			CscaCertDir:        getEnvOrDefault("CSCA_CERT_DIR", ""),
+			MFAEncryptionKey:   getEnvOrDefault("MFA_ENCRYPTION_KEY", ""),
+			PIIEncryptionKeys:  getEnvOrDefault("PII_ENCRYPTION_KEYS", ""),
+			PIIIndexKey:        getEnvOrDefault("PII_INDEX_KEY", ""),
+			EkycWebhookURLs:    getEnvOrDefault("EKYC_WEBHOOK_URLS", ""),
+			EkycWebhookSecret:  getEnvOrDefault("EKYC_WEBHOOK_SECRET", ""),
+			EkycWorkerConcurrency: getEnvIntOrDefault("EKYC_WORKER_CONCURRENCY", 4),
 		},
 		RedisCfg: RedisConfig{
 			Host:     getEnvOrDefault("REDIS_HOST", "localhost"),
@@ -100,3 +132,10 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -1,4 +1,6 @@
-
+-- Schema of the service before versioned migrations. Databases created from it are adopted at
+-- this version without running it again.
+-- +goose Up
 CREATE TABLE users (
     id VARCHAR(50) PRIMARY KEY,
     phone_number VARCHAR(15) UNIQUE,
@@ -208,3 +210,12 @@ CREATE INDEX idx_api_key_permissions_name ON api_key_permissions(permission_name
 CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
 CREATE INDEX idx_audit_logs_timestamp ON audit_logs(timestamp);
 CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+    RAISE EXCEPTION 'the baseline schema cannot be rolled back';
+END
+$$;
+-- +goose StatementEnd
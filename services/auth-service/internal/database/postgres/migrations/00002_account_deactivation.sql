@@ -0,0 +1,10 @@
+-- Users can deactivate their own account, which blocks login until they reactivate it
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN deactivated_at TIMESTAMP,
+    ADD COLUMN deactivation_reason TEXT;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deactivation_reason,
+    DROP COLUMN IF EXISTS deactivated_at;
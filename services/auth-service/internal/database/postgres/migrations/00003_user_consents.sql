@@ -0,0 +1,35 @@
+-- Versioned consent wording and the decisions of users on it. A new version is inserted for every
+-- change of wording; withdrawing a consent keeps its row and sets withdrawn_at.
+-- +goose Up
+CREATE TABLE consent_texts (
+    id SERIAL PRIMARY KEY,
+    consent_type VARCHAR(50) NOT NULL
+        CHECK (consent_type IN ('data_processing', 'satellite_monitoring', 'marketing')),
+    version INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    effective_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(50) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(consent_type, version)
+);
+
+CREATE TABLE user_consents (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    consent_type VARCHAR(50) NOT NULL,
+    consent_text_id INTEGER NOT NULL REFERENCES consent_texts(id),
+    version INTEGER NOT NULL,
+    granted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    withdrawn_at TIMESTAMP,
+    ip_address VARCHAR(45),
+    user_agent TEXT
+);
+
+CREATE INDEX idx_user_consents_user_type ON user_consents(user_id, consent_type);
+CREATE UNIQUE INDEX idx_user_consents_one_active ON user_consents(user_id, consent_type) WHERE withdrawn_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS user_consents;
+DROP TABLE IF EXISTS consent_texts;
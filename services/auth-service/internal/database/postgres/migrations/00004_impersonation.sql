@@ -0,0 +1,33 @@
+-- Time-boxed impersonation sessions opened by support staff. Requests made while impersonating
+-- are audited under the impersonation and the support user behind it.
+-- +goose Up
+CREATE TABLE impersonation_sessions (
+    id VARCHAR(50) PRIMARY KEY,
+    support_user_id VARCHAR(50) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_user_id VARCHAR(50) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id VARCHAR(50) NOT NULL,
+    reason TEXT NOT NULL,
+    ip_address VARCHAR(45),
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    end_reason VARCHAR(50),
+    action_count INTEGER DEFAULT 0,
+    user_notified BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX idx_impersonation_sessions_target ON impersonation_sessions(target_user_id);
+CREATE INDEX idx_impersonation_sessions_open ON impersonation_sessions(expires_at) WHERE ended_at IS NULL;
+
+ALTER TABLE audit_logs
+    ADD COLUMN impersonator_id VARCHAR(50) REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN impersonation_id VARCHAR(50);
+
+CREATE INDEX idx_audit_logs_impersonation_id ON audit_logs(impersonation_id) WHERE impersonation_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_audit_logs_impersonation_id;
+ALTER TABLE audit_logs
+    DROP COLUMN IF EXISTS impersonation_id,
+    DROP COLUMN IF EXISTS impersonator_id;
+DROP TABLE IF EXISTS impersonation_sessions;
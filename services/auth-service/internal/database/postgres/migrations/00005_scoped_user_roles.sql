@@ -0,0 +1,21 @@
+-- Role assignments limited to a scope, e.g. a role within one insurance provider
+-- +goose Up
+CREATE TABLE scoped_user_roles (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(50) REFERENCES users(id) ON DELETE CASCADE,
+    role_id INTEGER REFERENCES roles(id) ON DELETE CASCADE,
+    scope_type VARCHAR(50) NOT NULL,
+    scope_id VARCHAR(100) NOT NULL,
+    assigned_by VARCHAR(50) REFERENCES users(id),
+    assigned_at BIGINT,
+    expires_at BIGINT,
+    is_active BOOLEAN DEFAULT TRUE,
+
+    UNIQUE(user_id, role_id, scope_type, scope_id)
+);
+
+CREATE INDEX idx_scoped_user_roles_user_id ON scoped_user_roles(user_id);
+CREATE INDEX idx_scoped_user_roles_scope ON scoped_user_roles(scope_type, scope_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS scoped_user_roles;
@@ -0,0 +1,10 @@
+-- eKYC step for the CCCD chip, passed when the chip data passes passive authentication
+-- +goose Up
+ALTER TABLE user_ekyc_progress
+    ADD COLUMN is_nfc_verified BOOLEAN DEFAULT FALSE,
+    ADD COLUMN nfc_verified_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE user_ekyc_progress
+    DROP COLUMN IF EXISTS nfc_verified_at,
+    DROP COLUMN IF EXISTS is_nfc_verified;
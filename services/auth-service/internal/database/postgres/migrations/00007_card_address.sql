@@ -0,0 +1,26 @@
+-- Card addresses normalized to the post-2025 province/commune taxonomy. The raw text stays in
+-- address.
+-- +goose Up
+ALTER TABLE user_card
+    ADD COLUMN address_street VARCHAR DEFAULT '',
+    ADD COLUMN address_commune_code VARCHAR(10) DEFAULT '',
+    ADD COLUMN address_commune_name VARCHAR DEFAULT '',
+    ADD COLUMN address_province_code VARCHAR(10) DEFAULT '',
+    ADD COLUMN address_province_name VARCHAR DEFAULT '',
+    ADD COLUMN address_legacy_district VARCHAR DEFAULT '',
+    ADD COLUMN address_legacy_province VARCHAR DEFAULT '',
+    ADD COLUMN address_match_level VARCHAR(20) DEFAULT 'none';
+
+CREATE INDEX idx_user_card_address_province ON user_card(address_province_code);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_card_address_province;
+ALTER TABLE user_card
+    DROP COLUMN IF EXISTS address_match_level,
+    DROP COLUMN IF EXISTS address_legacy_province,
+    DROP COLUMN IF EXISTS address_legacy_district,
+    DROP COLUMN IF EXISTS address_province_name,
+    DROP COLUMN IF EXISTS address_province_code,
+    DROP COLUMN IF EXISTS address_commune_name,
+    DROP COLUMN IF EXISTS address_commune_code,
+    DROP COLUMN IF EXISTS address_street;
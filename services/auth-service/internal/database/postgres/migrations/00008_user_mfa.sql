@@ -0,0 +1,35 @@
+-- TOTP second factor. The secret is stored encrypted with the MFA key of the service, recovery
+-- codes only as hashes, and mfa_role_policies lists the roles that cannot log in without MFA.
+-- +goose Up
+CREATE TABLE user_mfa (
+    user_id VARCHAR(50) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_ciphertext TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Time step of the last accepted code, a code is never accepted twice
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE user_mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(user_id, code_hash)
+);
+
+CREATE TABLE mfa_role_policies (
+    role_name VARCHAR(50) PRIMARY KEY,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by VARCHAR(50) REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS mfa_role_policies;
+DROP TABLE IF EXISTS user_mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,37 @@
+-- eKYC as an explicit state machine: ocr_pending -> ocr_done -> liveness_pending -> verified.
+-- The booleans of user_ekyc_progress stay in sync for the readers still using them, and
+-- ekyc_step_events keeps a record of every step attempt.
+-- +goose Up
+ALTER TABLE user_ekyc_progress
+    ADD COLUMN state VARCHAR(20) NOT NULL DEFAULT 'ocr_pending',
+    ADD COLUMN state_updated_at TIMESTAMPTZ;
+
+UPDATE user_ekyc_progress
+SET state = CASE
+        WHEN (is_ocr_done OR is_nfc_verified) AND is_face_verified THEN 'verified'
+        WHEN is_ocr_done OR is_nfc_verified THEN 'ocr_done'
+        ELSE 'ocr_pending'
+    END,
+    state_updated_at = NOW();
+
+CREATE TABLE ekyc_step_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    step VARCHAR(32) NOT NULL,
+    -- succeeded, failed, or resumed from the cached result of an earlier attempt
+    outcome VARCHAR(16) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    -- Set when the step moved the user to another state
+    next_state VARCHAR(20),
+    error_code VARCHAR(50),
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ekyc_step_events_user_id ON ekyc_step_events(user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS ekyc_step_events;
+ALTER TABLE user_ekyc_progress
+    DROP COLUMN IF EXISTS state_updated_at,
+    DROP COLUMN IF EXISTS state;
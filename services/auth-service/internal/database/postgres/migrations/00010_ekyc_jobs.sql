@@ -0,0 +1,23 @@
+-- eKYC steps calling the vendor run as background jobs. The files of a job are stored in MinIO
+-- when it is submitted; a user has at most one active job of each kind.
+-- +goose Up
+CREATE TABLE ekyc_jobs (
+    id VARCHAR(50) PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    -- queued, processing, succeeded or failed
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    files JSONB NOT NULL DEFAULT '{}',
+    attempts INT NOT NULL DEFAULT 0,
+    error_code VARCHAR(50),
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_ekyc_jobs_user_id ON ekyc_jobs(user_id, created_at);
+CREATE UNIQUE INDEX idx_ekyc_jobs_active ON ekyc_jobs(user_id, kind) WHERE status IN ('queued', 'processing');
+
+-- +goose Down
+DROP TABLE IF EXISTS ekyc_jobs;
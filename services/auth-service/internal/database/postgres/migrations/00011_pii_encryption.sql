@@ -0,0 +1,29 @@
+-- National IDs, card numbers and addresses are encrypted by the application. The ciphertexts no
+-- longer fit the old column sizes, and uniqueness of the national ID moves to a keyed hash of it.
+-- Rows written before this migration stay plaintext until cmd/pii-reencrypt processes them.
+-- +goose Up
+ALTER TABLE users
+    ALTER COLUMN national_id TYPE TEXT,
+    DROP CONSTRAINT IF EXISTS users_national_id_key,
+    ADD COLUMN national_id_hash VARCHAR(64);
+DROP INDEX IF EXISTS idx_users_national_id;
+CREATE UNIQUE INDEX idx_users_national_id_hash ON users(national_id_hash);
+
+ALTER TABLE user_card
+    ALTER COLUMN national_id TYPE TEXT,
+    ADD COLUMN national_id_hash VARCHAR(64);
+CREATE UNIQUE INDEX idx_user_card_national_id_hash ON user_card(national_id_hash);
+
+-- +goose Down
+-- Encrypted values must be decrypted before going down, the old column sizes do not fit them
+DROP INDEX IF EXISTS idx_user_card_national_id_hash;
+ALTER TABLE user_card
+    DROP COLUMN IF EXISTS national_id_hash,
+    ALTER COLUMN national_id TYPE VARCHAR(12);
+
+DROP INDEX IF EXISTS idx_users_national_id_hash;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS national_id_hash,
+    ALTER COLUMN national_id TYPE VARCHAR(12),
+    ADD CONSTRAINT users_national_id_key UNIQUE (national_id);
+CREATE INDEX idx_users_national_id ON users(national_id);
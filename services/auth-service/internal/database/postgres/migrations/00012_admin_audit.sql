@@ -0,0 +1,9 @@
+-- Admin actions and logins are written to audit_logs, with the specifics of each entry, such as
+-- the roles changed or the device logged in from, kept in details.
+-- +goose Up
+ALTER TABLE audit_logs ADD COLUMN details JSONB;
+CREATE INDEX idx_audit_logs_resource ON audit_logs(resource_type, resource_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_audit_logs_resource;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS details;
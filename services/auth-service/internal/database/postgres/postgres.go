@@ -5,9 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -15,72 +12,6 @@ import (
 
 var DB_Status bool
 
-// executeSchemaFile reads and executes SQL statements from schema.sql file
-func executeSchemaFile(db *sqlx.DB) error {
-	// Get the current working directory
-	wd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-
-	// Look for schema.sql in the current directory and parent directories
-	schemaPath := findSchemaFile(wd)
-	if schemaPath == "" {
-		return fmt.Errorf("schema.sql file not found")
-	}
-
-	log.Printf("Found schema.sql at: %s", schemaPath)
-
-	// Read the schema file
-	schemaContent, err := os.ReadFile(schemaPath)
-	if err != nil {
-		return fmt.Errorf("failed to read schema.sql: %w", err)
-	}
-
-	// Split the content by semicolons to execute statements individually
-	statements := strings.Split(string(schemaContent), ";")
-
-	// Execute each statement
-	for i, statement := range statements {
-		statement = strings.TrimSpace(statement)
-		if statement == "" {
-			continue
-		}
-
-		_, err := db.Exec(statement)
-		if err != nil {
-			// Check if table already exists error
-			if strings.Contains(err.Error(), "already exists") {
-				log.Printf("Table/Index already exists, skipping statement %d", i+1)
-				continue
-			}
-			return fmt.Errorf("failed to execute statement %d: %w\nStatement: %s", i+1, err, statement)
-		}
-	}
-
-	log.Printf("Schema executed successfully")
-	return nil
-}
-
-// findSchemaFile searches for schema.sql in current directory and parent directories
-func findSchemaFile(startDir string) string {
-	currentDir := startDir
-	for {
-		schemaPath := filepath.Join(currentDir, "schema.sql")
-		if _, err := os.Stat(schemaPath); err == nil {
-			return schemaPath
-		}
-
-		parentDir := filepath.Dir(currentDir)
-		if parentDir == currentDir {
-			// Reached root directory
-			break
-		}
-		currentDir = parentDir
-	}
-	return ""
-}
-
 func ConnectAndCreateDB(cfg config.PostgresConfig) (*sqlx.DB, error) {
 	defaultConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=disable",
 		cfg.Host, cfg.Port, cfg.Username, cfg.Password)
@@ -126,10 +57,17 @@ func ConnectAndCreateDB(cfg config.PostgresConfig) (*sqlx.DB, error) {
 		return nil, fmt.Errorf("failed to ping target database: %w", err)
 	}
 
-	// Execute schema.sql file to create tables and indexes
-	if !exists {
-		if err := executeSchemaFile(db); err != nil {
-			return nil, fmt.Errorf("failed to execute schema.sql: %w", err)
+	// Bring the schema up to date; with auto migration off, migrations are run with cmd/migrate
+	// and the service refuses to start until they are
+	switch {
+	case cfg.SkipSchemaCheck:
+	case cfg.AutoMigrate:
+		if err := RunMigration(db, "up"); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	default:
+		if err := CheckMigration(db); err != nil {
+			return nil, err
 		}
 	}
 
@@ -0,0 +1,245 @@
+package ekyc
+
+import (
+	"auth-service/internal/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FptProvider calls the FPT.AI OCR and face liveness APIs. The liveness API also compares the
+// face with the card when given one, which is how face matching is done.
+type FptProvider struct {
+	cfg    *config.AuthConfig
+	client *http.Client
+}
+
+func NewFptProvider(cfg *config.AuthConfig) *FptProvider {
+	return &FptProvider{
+		cfg:    cfg,
+		client: &http.Client{},
+	}
+}
+
+func (p *FptProvider) Name() string {
+	return ProviderFPT
+}
+
+func (p *FptProvider) OCRFront(ctx context.Context, image File) (*IDCardFront, error) {
+	fields, err := p.ocr(ctx, image, "front")
+	if err != nil {
+		return nil, err
+	}
+	front := &IDCardFront{
+		NationalID:        stringField(fields, "id"),
+		Name:              stringField(fields, "name"),
+		Dob:               stringField(fields, "dob"),
+		Sex:               stringField(fields, "sex"),
+		Nationality:       stringField(fields, "nationality"),
+		Home:              stringField(fields, "home"),
+		Address:           stringField(fields, "address"),
+		Doe:               stringField(fields, "doe"),
+		NumberOfNameLines: stringField(fields, "number_of_name_lines"),
+	}
+	if front.NationalID == "" {
+		log.Printf("Error: missing id in front OCR response")
+		return nil, NewError("INTERNAL_ERROR", "missing id in front OCR response")
+	}
+	return front, nil
+}
+
+func (p *FptProvider) OCRBack(ctx context.Context, image File) (*IDCardBack, error) {
+	fields, err := p.ocr(ctx, image, "back")
+	if err != nil {
+		return nil, err
+	}
+	mrzArray, ok := fields["mrz"].([]any)
+	if !ok {
+		log.Printf("invalid mrz format in back OCR response: %v", fields)
+		return nil, NewError("INTERNAL_ERROR", "invalid mrz format in back OCR response")
+	}
+	back := &IDCardBack{
+		Features:  stringField(fields, "features"),
+		IssueDate: stringField(fields, "issue_date"),
+		IssueLoc:  stringField(fields, "issue_loc"),
+		MRZ:       make([]string, len(mrzArray)),
+	}
+	for i, v := range mrzArray {
+		back.MRZ[i], _ = v.(string)
+	}
+	return back, nil
+}
+
+func (p *FptProvider) Liveness(ctx context.Context, video File) (*LivenessResult, error) {
+	result, err := p.liveness(ctx, video, nil)
+	if err != nil {
+		return nil, err
+	}
+	liveness, _ := result["liveness"].(map[string]any)
+	return &LivenessResult{
+		IsLive:           boolField(liveness, "is_live"),
+		SpoofProbability: floatField(liveness, "spoof_prob"),
+	}, nil
+}
+
+func (p *FptProvider) FaceMatch(ctx context.Context, face, card File) (*FaceMatchResult, error) {
+	result, err := p.liveness(ctx, face, &card)
+	if err != nil {
+		return nil, err
+	}
+	match, ok := result["face_match"].(map[string]any)
+	if !ok {
+		log.Printf("missing face_match in FPT liveness response: %v", result)
+		return nil, NewError("EXTERNAL_API_ERROR", "Face match result is missing")
+	}
+	return &FaceMatchResult{
+		IsMatch:    boolField(match, "isMatch"),
+		Similarity: floatField(match, "similarity"),
+	}, nil
+}
+
+// ocr sends one side of the national ID card to FPT OCR and returns the fields read from it
+func (p *FptProvider) ocr(ctx context.Context, image File, side string) (map[string]any, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := copyFormFile(writer, "image", image); err != nil {
+		log.Printf("Error when preparing cccd_%s: %v", side, err)
+		return nil, NewError("BAD_REQUEST", fmt.Sprintf("Error when opening cccd_%s", side))
+	}
+	writer.Close()
+
+	status, respBody, err := p.post(ctx, p.cfg.FptOcrUrl, writer.FormDataContentType(), body)
+	if err != nil {
+		log.Printf("Error when sending %s OCR request: %v", side, err)
+		return nil, NewError("INTERNAL_ERROR", fmt.Sprintf("Error when sending %s OCR request", side))
+	}
+	if status != http.StatusOK {
+		log.Printf("FPT OCR %s API error: %s", side, string(respBody))
+		return nil, NewError("EXTERNAL_API_ERROR", fmt.Sprintf("FPT OCR %s API error", side))
+	}
+
+	var ocrResponse map[string]any
+	if err := json.Unmarshal(respBody, &ocrResponse); err != nil {
+		log.Printf("Error when parsing %s OCR response: %v", side, err)
+		return nil, NewError("INTERNAL_ERROR", fmt.Sprintf("Error when parsing %s OCR response", side))
+	}
+
+	data, ok := ocrResponse["data"].([]any)
+	if !ok || len(data) == 0 {
+		log.Printf("invalid %s OCR response data: %v", side, ocrResponse)
+		return nil, NewError("INTERNAL_ERROR", fmt.Sprintf("missing or invalid data in %s OCR response", side))
+	}
+	fields, ok := data[0].(map[string]any)
+	if !ok {
+		log.Printf("invalid %s OCR response data: %v", side, ocrResponse)
+		return nil, NewError("INTERNAL_ERROR", fmt.Sprintf("missing or invalid data in %s OCR response", side))
+	}
+	return fields, nil
+}
+
+// liveness sends the face video, and the card image when given, to FPT liveness
+func (p *FptProvider) liveness(ctx context.Context, video File, card *File) (map[string]any, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := copyFormFile(writer, "video", video); err != nil {
+		log.Printf("Error when preparing video file: %v", err)
+		return nil, NewError("BAD_REQUEST", "Error when opening video file")
+	}
+	if card != nil {
+		if err := copyFormFile(writer, "cmnd", *card); err != nil {
+			log.Printf("Error when preparing cmnd file: %v", err)
+			return nil, NewError("BAD_REQUEST", "Error when opening cmnd file")
+		}
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("Error when closing multipart writer: %v", err)
+		return nil, NewError("INTERNAL_ERROR", "Error when closing multipart writer")
+	}
+
+	// Liveness reports failures in the body rather than with the HTTP status
+	_, respBody, err := p.post(ctx, p.cfg.FptFaceLivenessUrl, writer.FormDataContentType(), body)
+	if err != nil {
+		log.Printf("FPT face liveness request failed: %v", err)
+		return nil, NewError("INTERNAL_ERROR", "Error when sending request")
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		log.Printf("Error when parsing response body: %v", err)
+		return nil, NewError("INTERNAL_ERROR", "Error when parsing response body")
+	}
+
+	if code, ok := result["code"].(string); ok && code != "200" {
+		message, ok := result["message"].(string)
+		if !ok {
+			message = "Unknown error"
+		}
+		return nil, NewError("EXTERNAL_API_ERROR", "Face liveness failed: "+message)
+	}
+	return result, nil
+}
+
+// post sends a multipart body with the FPT API key and returns the response status and body
+func (p *FptProvider) post(ctx context.Context, url, contentType string, body io.Reader) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("api-key", p.cfg.FptEkycApiKey)
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+func copyFormFile(writer *multipart.Writer, field string, file File) error {
+	part, err := writer.CreateFormFile(field, file.Name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file.Content)
+	return err
+}
+
+func stringField(fields map[string]any, key string) string {
+	value, _ := fields[key].(string)
+	return value
+}
+
+// FPT returns flags and scores either as JSON values or as strings
+func boolField(fields map[string]any, key string) bool {
+	switch value := fields[key].(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	}
+	return false
+}
+
+func floatField(fields map[string]any, key string) float64 {
+	switch value := fields[key].(type) {
+	case float64:
+		return value
+	case string:
+		parsed, _ := strconv.ParseFloat(value, 64)
+		return parsed
+	}
+	return 0
+}
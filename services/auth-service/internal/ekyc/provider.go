@@ -0,0 +1,89 @@
+package ekyc
+
+import (
+	"auth-service/internal/config"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Names of the vendors EKYC_PROVIDER selects from
+const (
+	ProviderFPT = "fpt"
+)
+
+// Provider reads national ID cards and checks faces for eKYC. Implementations return *Error for
+// failures the client should see, with the vendor response normalized into the models below.
+type Provider interface {
+	Name() string
+	OCRFront(ctx context.Context, image File) (*IDCardFront, error)
+	OCRBack(ctx context.Context, image File) (*IDCardBack, error)
+	// Liveness checks that the face video shows a live person
+	Liveness(ctx context.Context, video File) (*LivenessResult, error)
+	// FaceMatch compares the face, a video or an image, with the portrait on the card
+	FaceMatch(ctx context.Context, face, card File) (*FaceMatchResult, error)
+}
+
+// File is a document sent to the provider
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// IDCardFront holds the fields read from the front of a national ID card
+type IDCardFront struct {
+	NationalID        string `json:"national_id"`
+	Name              string `json:"name"`
+	Dob               string `json:"dob"`
+	Sex               string `json:"sex"`
+	Nationality       string `json:"nationality"`
+	Home              string `json:"home"`
+	Address           string `json:"address"`
+	Doe               string `json:"doe"`
+	NumberOfNameLines string `json:"number_of_name_lines"`
+}
+
+// IDCardBack holds the fields read from the back of a national ID card
+type IDCardBack struct {
+	Features  string   `json:"features"`
+	IssueDate string   `json:"issue_date"`
+	IssueLoc  string   `json:"issue_loc"`
+	MRZ       []string `json:"mrz"`
+}
+
+type LivenessResult struct {
+	IsLive bool `json:"is_live"`
+	// Probability in [0, 1] that the video is a spoof, when the provider reports it
+	SpoofProbability float64 `json:"spoof_probability"`
+}
+
+type FaceMatchResult struct {
+	IsMatch bool `json:"is_match"`
+	// Similarity in percent, when the provider reports it
+	Similarity float64 `json:"similarity"`
+}
+
+// Error is a provider failure with the error code returned to the client
+type Error struct {
+	Code    string
+	Message string
+}
+
+func NewError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// NewProvider returns the provider named in the configuration
+func NewProvider(cfg *config.AuthConfig) (Provider, error) {
+	switch strings.ToLower(cfg.EkycProvider) {
+	case ProviderFPT, "":
+		return NewFptProvider(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown ekyc provider %q", cfg.EkycProvider)
+	}
+}
@@ -0,0 +1,71 @@
+package event
+
+import (
+	"agrisa_utils/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AccountEventsExchange fans account changes out to the services that keep a copy of them, each
+// through its own queue
+const AccountEventsExchange string = "account_events"
+
+type AccountEventType string
+
+const (
+	AccountDeactivated AccountEventType = "account_deactivated"
+	AccountReactivated AccountEventType = "account_reactivated"
+	ConsentGranted     AccountEventType = "consent_granted"
+	ConsentWithdrawn   AccountEventType = "consent_withdrawn"
+)
+
+// AccountEvent is a change to an account. Consumers apply an event only when it is newer than the
+// last one they applied for the user, as events are not guaranteed to arrive in order.
+type AccountEvent struct {
+	Type        AccountEventType `json:"type"`
+	UserID      string           `json:"user_id"`
+	PhoneNumber string           `json:"phone_number,omitempty"`
+	ConsentType string           `json:"consent_type,omitempty"`
+	OccurredAt  time.Time        `json:"occurred_at"`
+}
+
+type AccountEventPublisher struct {
+	conn *RabbitMQConnection
+}
+
+func NewAccountEventPublisher(conn *RabbitMQConnection) *AccountEventPublisher {
+	return &AccountEventPublisher{
+		conn: conn,
+	}
+}
+
+// Publish sends an account event to every service bound to the exchange
+func (p *AccountEventPublisher) Publish(ctx context.Context, accountEvent AccountEvent) error {
+	err := p.conn.Channel.ExchangeDeclare(AccountEventsExchange, amqp.ExchangeFanout, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	body, err := json.Marshal(accountEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal account event: %w", err)
+	}
+	err = p.conn.Channel.PublishWithContext(ctx, AccountEventsExchange, "", false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "application/json",
+		Body:         body,
+		Timestamp:    accountEvent.OccurredAt,
+		Headers:      logging.MessageHeaders(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish account event: %w", err)
+	}
+
+	slog.Info("Account event published", "type", accountEvent.Type, "user_id", accountEvent.UserID)
+	return nil
+}
@@ -0,0 +1,121 @@
+package event
+
+import (
+	"agrisa_utils/logging"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// EkycJobQueue carries the IDs of queued eKYC jobs to the workers
+const EkycJobQueue string = "ekyc_jobs"
+
+// EkycJobHandler processes the job of a message. An error leaves the job to be delivered once
+// more, for failures of the service itself rather than of the job.
+type EkycJobHandler func(ctx context.Context, jobID string) error
+
+type EkycJobQueueClient struct {
+	conn *RabbitMQConnection
+}
+
+func NewEkycJobQueueClient(conn *RabbitMQConnection) *EkycJobQueueClient {
+	return &EkycJobQueueClient{
+		conn: conn,
+	}
+}
+
+func declareEkycJobQueue(ch *amqp.Channel) error {
+	_, err := ch.QueueDeclare(EkycJobQueue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+	return nil
+}
+
+// Publish queues a job for the workers
+func (q *EkycJobQueueClient) Publish(ctx context.Context, jobID string) error {
+	if err := declareEkycJobQueue(q.conn.Channel); err != nil {
+		return err
+	}
+	err := q.conn.Channel.PublishWithContext(ctx, "", EkycJobQueue, false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "text/plain",
+		Body:         []byte(jobID),
+		Timestamp:    time.Now(),
+		Headers:      logging.MessageHeaders(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish ekyc job: %w", err)
+	}
+	return nil
+}
+
+// Consume runs workers handling jobs until ctx is done, reconnecting when the channel fails
+func (q *EkycJobQueueClient) Consume(ctx context.Context, workers int, handle EkycJobHandler) {
+	for {
+		err := q.consume(ctx, workers, handle)
+		if ctx.Err() != nil {
+			slog.Info("ekyc job consumer stopped")
+			return
+		}
+		slog.Error("ekyc job consumer failed, reconnecting in 5 seconds", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (q *EkycJobQueueClient) consume(ctx context.Context, workers int, handle EkycJobHandler) error {
+	// Workers get their own channel, the shared one is used for publishing
+	ch, err := q.conn.Connection.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := declareEkycJobQueue(ch); err != nil {
+		return err
+	}
+	if err := ch.Qos(workers, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+	deliveries, err := ch.Consume(EkycJobQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range deliveries {
+				// A job being processed when ctx is cancelled is finished rather than redone
+				msgCtx := logging.FromMessageHeaders(context.WithoutCancel(ctx), msg.Headers)
+				jobID := string(msg.Body)
+				if err := handle(msgCtx, jobID); err != nil {
+					// Delivered once more; a job failing again is dropped and stays as it is
+					slog.ErrorContext(msgCtx, "failed to process ekyc job", "job_id", jobID, "redelivered", msg.Redelivered, "error", err)
+					msg.Nack(false, !msg.Redelivered)
+					continue
+				}
+				msg.Ack(false)
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		ch.Close()
+		return ctx.Err()
+	case err := <-ch.NotifyClose(make(chan *amqp.Error, 1)):
+		return fmt.Errorf("channel closed: %v", err)
+	}
+}
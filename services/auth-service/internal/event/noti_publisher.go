@@ -2,6 +2,7 @@ package event
 
 import (
 	utils "agrisa_utils"
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -28,6 +29,37 @@ func NewNotificationPublisher(conn *RabbitMQConnection) *NotificationPublisher {
 
 // PublishNotification publishes a notification event to the push_noti_events queue
 func (p *NotificationPublisher) PublishNotification(ctx context.Context, event NotificationEventPushModel) error {
+	return p.publish(ctx, NotificationMessage{
+		ID:          utils.GenerateRandomStringWithLength(6),
+		Type:        TypeSMS,
+		Priority:    PriorityHigh,
+		RecipientID: "",
+		Payload:     map[string]any{"payload": event},
+		RetryCount:  0,
+		MaxRetries:  5,
+		CreatedAt:   time.Now(),
+	}, event.Notification.Title)
+}
+
+// PublishInAppNotification publishes a notification pushed to the app of the user
+func (p *NotificationPublisher) PublishInAppNotification(ctx context.Context, userID, title, body string, data map[string]any) error {
+	return p.publish(ctx, NotificationMessage{
+		ID:          utils.GenerateRandomStringWithLength(6),
+		Type:        TypeInApp,
+		Priority:    PriorityNormal,
+		RecipientID: userID,
+		Payload: map[string]any{
+			"lstUserIds": []string{userID},
+			"title":      title,
+			"body":       body,
+			"data":       data,
+		},
+		MaxRetries: 5,
+		CreatedAt:  time.Now(),
+	}, title)
+}
+
+func (p *NotificationPublisher) publish(ctx context.Context, message NotificationMessage, title string) error {
 	// Ensure the queue exists
 	_, err := p.conn.Channel.QueueDeclare(
 		NotiQueue, // queue name
@@ -41,20 +73,9 @@ func (p *NotificationPublisher) PublishNotification(ctx context.Context, event N
 		p.messagesFailed++
 		return fmt.Errorf("failed to declare queue: %w", err)
 	}
-	totalEvent := NotificationMessage{
-		ID:           utils.GenerateRandomStringWithLength(6),
-		Type:         TypeSMS,
-		Priority:     PriorityHigh,
-		RecipientID:  "",
-		Payload:      map[string]any{"payload": event},
-		RetryCount:   0,
-		MaxRetries:   5,
-		CreatedAt:    time.Now(),
-		ScheduledFor: nil,
-	}
 
 	// Marshal the event to JSON
-	body, err := json.Marshal(totalEvent)
+	body, err := json.Marshal(message)
 	if err != nil {
 		p.messagesFailed++
 		return fmt.Errorf("failed to marshal notification event: %w", err)
@@ -72,6 +93,7 @@ func (p *NotificationPublisher) PublishNotification(ctx context.Context, event N
 			ContentType:  "application/json",
 			Body:         body,
 			Timestamp:    time.Now(),
+			Headers:      logging.MessageHeaders(ctx),
 		},
 	)
 	if err != nil {
@@ -84,7 +106,7 @@ func (p *NotificationPublisher) PublishNotification(ctx context.Context, event N
 
 	slog.Info("Notification event published",
 		"queue", NotiQueue,
-		"title", event.Notification.Title,
+		"title", title,
 	)
 
 	return nil
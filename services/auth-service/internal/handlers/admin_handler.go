@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/services"
+	"auth-service/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(router *gin.Engine) {
+	adminGroup := router.Group("/auth/protected/api/v2/admin")
+	adminGroup.GET("/users", h.SearchUsers)
+	adminGroup.GET("/users/:user_id/login-history", h.GetLoginHistory)
+	adminGroup.POST("/users/:user_id/logout-all", h.ForceLogout)
+	adminGroup.PUT("/users/:user_id/roles", h.SetUserRoles)
+	adminGroup.GET("/audit-logs", h.GetAuditLogs)
+}
+
+func (h *AdminHandler) mapAdminError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "not_found"):
+		return http.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "bad_request"):
+		return http.StatusBadRequest, "BAD_REQUEST"
+	case strings.Contains(errorMsg, "forbidden"):
+		return http.StatusForbidden, "ACTION_FORBIDDEN"
+	default:
+		return http.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+// adminUserID returns the caller's user ID. Admin actions are not available to support staff
+// impersonating an admin.
+func (h *AdminHandler) adminUserID(c *gin.Context) (string, bool) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return "", false
+	}
+	if c.GetHeader("X-Impersonation-ID") != "" {
+		c.JSON(http.StatusForbidden, utils.CreateErrorResponse("ACTION_FORBIDDEN", "Not allowed while impersonating"))
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	adminID, ok := h.adminUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter models.AdminUserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "Invalid search parameters"))
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	result, err := h.adminService.SearchUsers(adminID, &ipAddress, filter)
+	if err != nil {
+		slog.Error("failed to search users", "admin_id", adminID, "error", err)
+		statusCode, errorCode := h.mapAdminError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to search users"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
+
+func (h *AdminHandler) GetLoginHistory(c *gin.Context) {
+	adminID, ok := h.adminUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	userID := c.Param("user_id")
+	ipAddress := c.ClientIP()
+	logs, err := h.adminService.GetLoginHistory(adminID, userID, &ipAddress, limit, offset)
+	if err != nil {
+		slog.Error("failed to get login history", "admin_id", adminID, "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapAdminError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to retrieve login history"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(logs))
+}
+
+func (h *AdminHandler) ForceLogout(c *gin.Context) {
+	adminID, ok := h.adminUserID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.Param("user_id")
+	ipAddress := c.ClientIP()
+	if err := h.adminService.ForceLogout(c, adminID, userID, &ipAddress); err != nil {
+		slog.Error("failed to force logout", "admin_id", adminID, "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapAdminError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to log out user"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("all sessions of the user ended"))
+}
+
+func (h *AdminHandler) SetUserRoles(c *gin.Context) {
+	adminID, ok := h.adminUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "roles is required"))
+		return
+	}
+
+	userID := c.Param("user_id")
+	ipAddress := c.ClientIP()
+	result, err := h.adminService.SetUserRoles(c, adminID, userID, &ipAddress, req.Roles)
+	if err != nil {
+		slog.Error("failed to set user roles", "admin_id", adminID, "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapAdminError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to change user roles"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
+
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	adminID, ok := h.adminUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	filter := models.AuditLogFilter{
+		UserID:     c.Query("actor_id"),
+		ResourceID: c.Query("user_id"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	if filter.ResourceID != "" {
+		filter.ResourceType = models.AuditResourceUser
+	}
+	if action := c.Query("action"); action != "" {
+		filter.Actions = []string{action}
+	}
+
+	ipAddress := c.ClientIP()
+	logs, err := h.adminService.GetAuditLogs(adminID, &ipAddress, filter)
+	if err != nil {
+		slog.Error("failed to get audit logs", "admin_id", adminID, "error", err)
+		statusCode, errorCode := h.mapAdminError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to retrieve audit logs"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(logs))
+}
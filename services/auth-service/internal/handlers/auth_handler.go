@@ -5,10 +5,12 @@ import (
 	"auth-service/internal/models"
 	"auth-service/internal/services"
 	"auth-service/utils"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +40,8 @@ func (a *AuthHandler) RegisterRoutes(router *gin.Engine) {
 	authGrPub.POST("/phone-otp/validate/:phone_number", a.ValidatePhoneOTP)
 	authGrPub.POST("/login", a.Login)
 	authGrPub.POST("/verify-identifier", a.VerifyIdentifier)
+	authGrPub.POST("/account/reactivate/otp", a.RequestReactivationOTP)
+	authGrPub.POST("/account/reactivate", a.ReactivateAccount)
 
 	authGrPro := router.Group("/auth/protected/api/v2")
 	accountGr := router.Group("/account")
@@ -50,6 +54,21 @@ func (a *AuthHandler) RegisterRoutes(router *gin.Engine) {
 	sessionGr.POST("/verify-land-certificate", a.VerifyLandCertificate)
 	sessionGr.GET("/cards", a.GetCard)
 	sessionGr.POST("/reset-ekyc", a.ResetEkycData)
+
+	accountProGr := authGrPro.Group("/account")
+	accountProGr.POST("/deactivate", a.DeactivateAccount)
+	accountProGr.POST("/:user_id/unlock", a.UnlockLogin) // admin only, lifts a failed-login lockout
+
+	deviceGr := authGrPro.Group("/devices")
+	deviceGr.GET("", a.GetTrustedDevices)
+	deviceGr.POST("/trust", a.RegisterTrustedDevice)
+	deviceGr.DELETE("/:device_id", a.RevokeTrustedDevice)
+	deviceGr.DELETE("", a.RevokeAllTrustedDevices)
+
+	meSessionGr := authGrPro.Group("/me/sessions")
+	meSessionGr.GET("", a.GetMySessions)
+	meSessionGr.DELETE("/:session_id", a.RevokeMySession)
+	meSessionGr.DELETE("", a.RevokeOtherSessions) // every session but the current one
 }
 
 func (a *AuthHandler) InitDefaultUser(cfg config.AuthServiceConfig) error {
@@ -101,10 +120,44 @@ func (a *AuthHandler) Login(c *gin.Context) {
 	ipAddress := a.getClientIP(c)
 
 	// Attempt login
-	user, session, err := a.userService.Login(req.Email, req.Phone, req.Password, &deviceInfo, &ipAddress)
+	user, session, err := a.userService.Login(req.Email, req.Phone, req.Password, &deviceInfo, &ipAddress, req.DeviceFingerprint, req.OTP, req.TrustDevice, req.MFACode)
 	if err != nil {
 		log.Printf("Login failed for user %s/%s: %v", req.Email, req.Phone, err)
 
+		// Users whose roles require MFA get a ticket to enroll with before logging in again
+		var enrollmentRequired *services.MFAEnrollmentRequiredError
+		if errors.As(err, &enrollmentRequired) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error": utils.APIError{
+					Code:    "MFA_ENROLLMENT_REQUIRED",
+					Message: "Multi-factor authentication must be set up before logging in",
+				},
+				"mfa_enrollment_ticket": enrollmentRequired.Ticket,
+			})
+			return
+		}
+
+		// Locked accounts and attempts made too soon after a failure are told when to retry
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			retryAfter := int(throttled.RetryAfter.Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			statusCode, errorCode := http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS"
+			if throttled.Locked {
+				statusCode, errorCode = http.StatusForbidden, "ACCOUNT_LOCKED"
+			}
+			c.JSON(statusCode, gin.H{
+				"success": false,
+				"error": utils.APIError{
+					Code:    errorCode,
+					Message: "Login failed",
+				},
+				"retry_after_seconds": retryAfter,
+			})
+			return
+		}
+
 		// Map service errors to appropriate HTTP responses
 		statusCode, errorCode := a.mapLoginError(err)
 		c.JSON(statusCode, utils.ErrorResponse{
@@ -256,6 +309,16 @@ func (a *AuthHandler) mapLoginError(err error) (int, string) {
 		return http.StatusForbidden, "ACTION_FORBIDDEN"
 	case strings.Contains(errorMsg, "account blocked"):
 		return http.StatusForbidden, "ACCOUNT_BLOCKED"
+	case strings.Contains(errorMsg, "account deactivated"):
+		return http.StatusForbidden, "ACCOUNT_DEACTIVATED"
+	case strings.Contains(errorMsg, "device verification required"):
+		return http.StatusForbidden, "DEVICE_VERIFICATION_REQUIRED"
+	case strings.Contains(errorMsg, "incorrect otp"):
+		return http.StatusUnauthorized, "INVALID_OTP"
+	case strings.Contains(errorMsg, "mfa code required"):
+		return http.StatusUnauthorized, "MFA_REQUIRED"
+	case strings.Contains(errorMsg, "incorrect mfa code"):
+		return http.StatusUnauthorized, "INVALID_MFA_CODE"
 	case strings.Contains(errorMsg, "invalid password"):
 		return http.StatusUnauthorized, "INVALID_CREDENTIALS"
 	case strings.Contains(errorMsg, "email or password incorrect"):
@@ -479,7 +542,7 @@ func (a *AuthHandler) VerifyLandCertificate(c *gin.Context) {
 	log.Printf("National ID input value: %s", natinonalIDInput)
 	log.Printf("User ID: %s", userID)
 
-	isValid, err := a.userService.VerifyLandCertificate(userID, natinonalIDInput)
+	isValid, ownerName, err := a.userService.VerifyLandCertificate(userID, natinonalIDInput)
 	if err != nil {
 		log.Printf("Land certificate verification failed for user %s: %v", userID, err)
 		if strings.Contains(err.Error(), "no rows in result set") {
@@ -504,8 +567,9 @@ func (a *AuthHandler) VerifyLandCertificate(c *gin.Context) {
 		return
 	}
 
-	response := utils.CreateSuccessResponse(map[string]bool{
-		"is_valid": isValid,
+	response := utils.CreateSuccessResponse(map[string]any{
+		"is_valid":   isValid,
+		"owner_name": ownerName,
 	})
 	c.JSON(http.StatusOK, response)
 }
@@ -588,3 +652,264 @@ func (a *AuthHandler) ResetEkycData(c *gin.Context) {
 	response := utils.CreateSuccessResponse("success")
 	c.JSON(http.StatusOK, response)
 }
+
+// mapAccountLifecycleError maps deactivation/reactivation errors to HTTP responses
+func (a *AuthHandler) mapAccountLifecycleError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "not_found"):
+		return http.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "bad_request"):
+		return http.StatusBadRequest, "BAD_REQUEST"
+	case strings.Contains(errorMsg, "unauthorized"):
+		return http.StatusUnauthorized, "INVALID_CREDENTIALS"
+	case strings.Contains(errorMsg, "forbidden"):
+		return http.StatusForbidden, "ACTION_FORBIDDEN"
+	default:
+		return http.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+// rejectImpersonated blocks account-security actions on requests made by support staff impersonating the user
+func (a *AuthHandler) rejectImpersonated(c *gin.Context) bool {
+	if c.GetHeader("X-Impersonation-ID") == "" {
+		return false
+	}
+	c.JSON(http.StatusForbidden, utils.CreateErrorResponse("ACTION_FORBIDDEN", "Not allowed while impersonating"))
+	return true
+}
+
+func (a *AuthHandler) DeactivateAccount(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	var req models.DeactivateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "password is required"))
+		return
+	}
+
+	if err := a.userService.DeactivateAccount(c, userID, req.Password, req.Reason); err != nil {
+		slog.Error("account deactivation failed", "user_id", userID, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Account deactivation failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("account deactivated"))
+}
+
+func (a *AuthHandler) UnlockLogin(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if actorID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	userID := c.Param("user_id")
+	if err := a.userService.UnlockLogin(c, actorID, userID); err != nil {
+		slog.Error("account unlock failed", "user_id", userID, "admin_id", actorID, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Account unlock failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("account unlocked"))
+}
+
+func (a *AuthHandler) RequestReactivationOTP(c *gin.Context) {
+	var req models.ReactivationOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "phone is required"))
+		return
+	}
+
+	if err := a.userService.RequestReactivationOTP(c, req.Phone); err != nil {
+		slog.Error("reactivation otp request failed", "phone", req.Phone, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Reactivation request failed"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse("reactivation otp generated"))
+}
+
+func (a *AuthHandler) ReactivateAccount(c *gin.Context) {
+	var req models.ReactivateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "phone, otp and password are required"))
+		return
+	}
+
+	user, err := a.userService.ReactivateAccount(c, req.Phone, req.OTP, req.Password)
+	if err != nil {
+		slog.Error("account reactivation failed", "phone", req.Phone, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Account reactivation failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(map[string]any{
+		"id":             user.ID,
+		"email":          user.Email,
+		"phone_number":   user.PhoneNumber,
+		"status":         user.Status,
+		"phone_verified": user.PhoneVerified,
+		"kyc_verified":   user.KYCVerified,
+	}))
+}
+
+func (a *AuthHandler) RegisterTrustedDevice(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	var req models.RegisterTrustedDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "device_fingerprint and otp are required"))
+		return
+	}
+
+	deviceInfo := a.getDeviceInfo(c)
+	ipAddress := a.getClientIP(c)
+	device, err := a.userService.RegisterTrustedDevice(c, userID, req.DeviceFingerprint, req.DeviceName, req.OTP, &deviceInfo, &ipAddress)
+	if err != nil {
+		slog.Error("trusted device registration failed", "user_id", userID, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Trusted device registration failed"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(device))
+}
+
+func (a *AuthHandler) GetTrustedDevices(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+
+	devices, err := a.userService.GetTrustedDevices(c, userID)
+	if err != nil {
+		slog.Error("failed to get trusted devices", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to retrieve trusted devices"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(devices))
+}
+
+func (a *AuthHandler) RevokeTrustedDevice(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	deviceID := c.Param("device_id")
+	if err := a.userService.RevokeTrustedDevice(c, userID, deviceID); err != nil {
+		slog.Error("failed to revoke trusted device", "user_id", userID, "device_id", deviceID, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to revoke trusted device"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("trusted device revoked"))
+}
+
+func (a *AuthHandler) RevokeAllTrustedDevices(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	if err := a.userService.RevokeAllTrustedDevices(c, userID); err != nil {
+		slog.Error("failed to revoke trusted devices", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to revoke trusted devices"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("all trusted devices revoked"))
+}
+
+func (a *AuthHandler) GetMySessions(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+
+	sessions, err := a.userService.GetMySessions(c, userID, c.GetHeader("X-Session-ID"))
+	if err != nil {
+		slog.Error("failed to get sessions", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to retrieve sessions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(sessions))
+}
+
+func (a *AuthHandler) RevokeMySession(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	sessionID := c.Param("session_id")
+	if err := a.userService.RevokeMySession(c, userID, sessionID); err != nil {
+		slog.Error("failed to revoke session", "user_id", userID, "session_id", sessionID, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to revoke session"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("session revoked"))
+}
+
+func (a *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+	if a.rejectImpersonated(c) {
+		return
+	}
+
+	revoked, err := a.userService.RevokeOtherSessions(c, userID, c.GetHeader("X-Session-ID"))
+	if err != nil {
+		slog.Error("failed to revoke other sessions", "user_id", userID, "error", err)
+		statusCode, errorCode := a.mapAccountLifecycleError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to revoke other sessions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(models.RevokeSessionsResponse{Revoked: revoked}))
+}
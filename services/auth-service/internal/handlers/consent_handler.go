@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/services"
+	"auth-service/utils"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsentHandler struct {
+	consentService *services.ConsentService
+	// Key of the services allowed to check the consent of any user
+	apiKey string
+}
+
+func NewConsentHandler(consentService *services.ConsentService, apiKey string) *ConsentHandler {
+	return &ConsentHandler{
+		consentService: consentService,
+		apiKey:         apiKey,
+	}
+}
+
+func (h *ConsentHandler) RegisterRoutes(router *gin.Engine) {
+	publicGroup := router.Group("/auth/public/api/v2/consents")
+	publicGroup.GET("/texts", h.GetActiveConsentTexts)
+
+	protectedGroup := router.Group("/auth/protected/api/v2/consents")
+	protectedGroup.GET("/me", h.GetMyConsents)
+	protectedGroup.POST("", h.GrantConsent)
+	protectedGroup.DELETE("/:consent_type", h.WithdrawConsent)
+
+	// Admins, and services with the API key for the status
+	protectedGroup.POST("/texts", h.PublishConsentText)
+	protectedGroup.GET("/users/:user_id/status", h.GetConsentStatus)
+}
+
+func (h *ConsentHandler) mapConsentError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "forbidden"):
+		return http.StatusForbidden, "FORBIDDEN"
+	case strings.Contains(errorMsg, "not_found"):
+		return http.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "bad_request"):
+		return http.StatusBadRequest, "BAD_REQUEST"
+	case strings.Contains(errorMsg, "conflict"):
+		return http.StatusConflict, "CONSENT_VERSION_OUTDATED"
+	default:
+		return http.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+func (h *ConsentHandler) GetActiveConsentTexts(c *gin.Context) {
+	texts, err := h.consentService.GetActiveConsentTexts()
+	if err != nil {
+		slog.Error("failed to get consent texts", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to retrieve consent texts"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(texts))
+}
+
+func (h *ConsentHandler) PublishConsentText(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+
+	var req models.PublishConsentTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "consent_type and content are required"))
+		return
+	}
+
+	text, err := h.consentService.PublishConsentText(userID, req.ConsentType, req.Content)
+	if err != nil {
+		slog.Error("failed to publish consent text", "consent_type", req.ConsentType, "error", err)
+		statusCode, errorCode := h.mapConsentError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to publish consent text"))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(text))
+}
+
+func (h *ConsentHandler) GetMyConsents(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+
+	consents, err := h.consentService.GetUserConsents(userID)
+	if err != nil {
+		slog.Error("failed to get user consents", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to retrieve consents"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(consents))
+}
+
+func (h *ConsentHandler) GrantConsent(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+
+	var req models.GrantConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "consent_type and version are required"))
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+	consent, err := h.consentService.GrantConsent(userID, req.ConsentType, req.Version, &ipAddress, &userAgent)
+	if err != nil {
+		slog.Error("failed to grant consent", "user_id", userID, "consent_type", req.ConsentType, "error", err)
+		statusCode, errorCode := h.mapConsentError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to grant consent"))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(consent))
+}
+
+func (h *ConsentHandler) WithdrawConsent(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return
+	}
+
+	consentType := models.ConsentType(c.Param("consent_type"))
+	if err := h.consentService.WithdrawConsent(userID, consentType); err != nil {
+		slog.Error("failed to withdraw consent", "user_id", userID, "consent_type", consentType, "error", err)
+		statusCode, errorCode := h.mapConsentError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to withdraw consent"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("consent withdrawn"))
+}
+
+// GetConsentStatus is used by dependent services (monitoring, campaigns) to check a user's consent,
+// and by users and admins to check it through the API
+func (h *ConsentHandler) GetConsentStatus(c *gin.Context) {
+	userID := c.Param("user_id")
+	consentType := models.ConsentType(c.Query("type"))
+
+	if !h.isServiceCall(c) {
+		if err := h.consentService.CheckCanViewConsents(c.GetHeader("X-User-ID"), userID); err != nil {
+			slog.Warn("rejected consent status request", "user_id", userID, "actor_id", c.GetHeader("X-User-ID"), "error", err)
+			statusCode, errorCode := h.mapConsentError(err)
+			c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to retrieve consent status"))
+			return
+		}
+	}
+
+	status, err := h.consentService.GetConsentStatus(userID, consentType)
+	if err != nil {
+		slog.Error("failed to get consent status", "user_id", userID, "consent_type", consentType, "error", err)
+		statusCode, errorCode := h.mapConsentError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to retrieve consent status"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(status))
+}
+
+// isServiceCall reports whether the request comes from another service holding the API key
+func (h *ConsentHandler) isServiceCall(c *gin.Context) bool {
+	return h.apiKey != "" && c.GetHeader("API-KEY") == h.apiKey
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/services"
+	"auth-service/utils"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *services.ImpersonationService
+}
+
+func NewImpersonationHandler(impersonationService *services.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+	}
+}
+
+func (h *ImpersonationHandler) RegisterRoutes(router *gin.Engine) {
+	impersonationGroup := router.Group("/auth/protected/api/v2/impersonation")
+	impersonationGroup.POST("", h.StartImpersonation)
+	impersonationGroup.POST("/:id/end", h.EndImpersonation)
+	impersonationGroup.GET("/:id/audit", h.GetAuditTrail)
+}
+
+func (h *ImpersonationHandler) mapImpersonationError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "not_found"):
+		return http.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "bad_request"):
+		return http.StatusBadRequest, "BAD_REQUEST"
+	case strings.Contains(errorMsg, "forbidden"):
+		return http.StatusForbidden, "ACTION_FORBIDDEN"
+	default:
+		return http.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+// supportUserID returns the caller's user ID, rejecting callers that are themselves impersonating
+func (h *ImpersonationHandler) supportUserID(c *gin.Context) (string, bool) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return "", false
+	}
+	if c.GetHeader("X-Impersonation-ID") != "" {
+		c.JSON(http.StatusForbidden, utils.CreateErrorResponse("ACTION_FORBIDDEN", "Not allowed while impersonating"))
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	supportUserID, ok := h.supportUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "target_user_id and reason are required"))
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	result, err := h.impersonationService.StartImpersonation(c, supportUserID, req.TargetUserID, req.Reason, req.DurationMinutes, &ipAddress)
+	if err != nil {
+		slog.Error("failed to start impersonation", "support_user_id", supportUserID, "target_user_id", req.TargetUserID, "error", err)
+		statusCode, errorCode := h.mapImpersonationError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to start impersonation"))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(result))
+}
+
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	supportUserID, ok := h.supportUserID(c)
+	if !ok {
+		return
+	}
+
+	impersonationID := c.Param("id")
+	if err := h.impersonationService.EndImpersonation(c, supportUserID, impersonationID); err != nil {
+		slog.Error("failed to end impersonation", "impersonation_id", impersonationID, "error", err)
+		statusCode, errorCode := h.mapImpersonationError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to end impersonation"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("impersonation ended"))
+}
+
+func (h *ImpersonationHandler) GetAuditTrail(c *gin.Context) {
+	requesterID, ok := h.supportUserID(c)
+	if !ok {
+		return
+	}
+
+	impersonationID := c.Param("id")
+	impersonation, logs, err := h.impersonationService.GetImpersonationAuditTrail(requesterID, impersonationID)
+	if err != nil {
+		slog.Error("failed to get impersonation audit trail", "impersonation_id", impersonationID, "error", err)
+		statusCode, errorCode := h.mapImpersonationError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to retrieve audit trail"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(map[string]any{
+		"impersonation": impersonation,
+		"actions":       logs,
+	}))
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/services"
+	"auth-service/utils"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+func (h *MFAHandler) RegisterRoutes(router *gin.Engine) {
+	// Enrollment of users whose login was rejected until they set up MFA
+	publicGroup := router.Group("/auth/public/mfa")
+	publicGroup.POST("/enroll", h.BeginEnrollmentWithTicket)
+	publicGroup.POST("/enroll/confirm", h.ConfirmEnrollmentWithTicket)
+
+	mfaGroup := router.Group("/auth/protected/api/v2/mfa")
+	mfaGroup.GET("", h.GetStatus)
+	mfaGroup.POST("/enroll", h.BeginEnrollment)                 // returns the secret and the otpauth URI to show as a QR code
+	mfaGroup.POST("/enroll/confirm", h.ConfirmEnrollment)       // enables MFA and returns the recovery codes
+	mfaGroup.POST("/recovery-codes", h.RegenerateRecoveryCodes) // replaces the recovery codes
+	mfaGroup.POST("/disable", h.Disable)
+
+	// Admin policy requiring MFA per role
+	mfaGroup.GET("/policies", h.GetRolePolicies)
+	mfaGroup.PUT("/policies/:role", h.SetRolePolicy)
+}
+
+func (h *MFAHandler) mapMFAError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "not_found"):
+		return http.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "conflict"):
+		return http.StatusConflict, "CONFLICT"
+	case strings.Contains(errorMsg, "forbidden"):
+		return http.StatusForbidden, "ACTION_FORBIDDEN"
+	case strings.Contains(errorMsg, "invalid mfa code"):
+		return http.StatusUnauthorized, "INVALID_MFA_CODE"
+	case strings.Contains(errorMsg, "invalid enrollment ticket"):
+		return http.StatusUnauthorized, "INVALID_ENROLLMENT_TICKET"
+	default:
+		return http.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+// userID returns the caller's user ID. The second factor belongs to the user, support staff
+// impersonating them cannot change it.
+func (h *MFAHandler) userID(c *gin.Context) (string, bool) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+		return "", false
+	}
+	if c.GetHeader("X-Impersonation-ID") != "" {
+		c.JSON(http.StatusForbidden, utils.CreateErrorResponse("ACTION_FORBIDDEN", "Not allowed while impersonating"))
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(userID)
+	if err != nil {
+		slog.Error("failed to get mfa status", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to get MFA status"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(status))
+}
+
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(userID)
+	if err != nil {
+		slog.Error("failed to begin mfa enrollment", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to begin MFA enrollment"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(enrollment))
+}
+
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "code is required"))
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		slog.Error("failed to confirm mfa enrollment", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to confirm MFA enrollment"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(map[string]any{
+		"recovery_codes": codes,
+	}))
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "code is required"))
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		slog.Error("failed to regenerate recovery codes", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to regenerate recovery codes"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(map[string]any{
+		"recovery_codes": codes,
+	}))
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "code is required"))
+		return
+	}
+
+	if err := h.mfaService.Disable(userID, req.Code); err != nil {
+		slog.Error("failed to disable mfa", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to disable MFA"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("mfa disabled"))
+}
+
+func (h *MFAHandler) BeginEnrollmentWithTicket(c *gin.Context) {
+	var req models.MFATicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "ticket is required"))
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollmentWithTicket(c, req.Ticket)
+	if err != nil {
+		slog.Error("failed to begin mfa enrollment with ticket", "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to begin MFA enrollment"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(enrollment))
+}
+
+func (h *MFAHandler) ConfirmEnrollmentWithTicket(c *gin.Context) {
+	var req models.MFATicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "ticket and code are required"))
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollmentWithTicket(c, req.Ticket, req.Code)
+	if err != nil {
+		slog.Error("failed to confirm mfa enrollment with ticket", "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to confirm MFA enrollment"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(map[string]any{
+		"recovery_codes": codes,
+	}))
+}
+
+func (h *MFAHandler) GetRolePolicies(c *gin.Context) {
+	if _, ok := h.userID(c); !ok {
+		return
+	}
+
+	policies, err := h.mfaService.GetRolePolicies()
+	if err != nil {
+		slog.Error("failed to get mfa role policies", "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to get MFA policies"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(policies))
+}
+
+func (h *MFAHandler) SetRolePolicy(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMFARolePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "required is required"))
+		return
+	}
+
+	role := c.Param("role")
+	policy, err := h.mfaService.SetRolePolicy(userID, role, *req.Required)
+	if err != nil {
+		slog.Error("failed to set mfa role policy", "role", role, "error", err)
+		statusCode, errorCode := h.mapMFAError(err)
+		c.JSON(statusCode, utils.CreateErrorResponse(errorCode, "Failed to set MFA policy"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(policy))
+}
@@ -15,18 +15,20 @@ import (
 )
 
 type Middleware struct {
-	jwtService     *services.JWTService
-	sessionService *services.SessionService
-	config         *config.AuthConfig
-	roleService    *services.RoleService
+	jwtService           *services.JWTService
+	sessionService       *services.SessionService
+	config               *config.AuthConfig
+	roleService          *services.RoleService
+	impersonationService *services.ImpersonationService
 }
 
-func NewMiddleware(jwtService *services.JWTService, sessionService *services.SessionService, config *config.AuthConfig, roleService *services.RoleService) *Middleware {
+func NewMiddleware(jwtService *services.JWTService, sessionService *services.SessionService, config *config.AuthConfig, roleService *services.RoleService, impersonationService *services.ImpersonationService) *Middleware {
 	return &Middleware{
-		jwtService:     jwtService,
-		sessionService: sessionService,
-		config:         config,
-		roleService:    roleService,
+		jwtService:           jwtService,
+		sessionService:       sessionService,
+		config:               config,
+		roleService:          roleService,
+		impersonationService: impersonationService,
 	}
 }
 
@@ -99,9 +101,11 @@ func (m *Middleware) ValidateToken(c *gin.Context) {
 	}
 
 	isSessionValid := false
+	sessionID := ""
 	for _, session := range sessions {
 		if session.TokenHash == tokenString && session.IsActive {
 			isSessionValid = true
+			sessionID = session.ID
 			m.sessionService.RenewSession(c, session.ID)
 			break
 		}
@@ -130,8 +134,29 @@ func (m *Middleware) ValidateToken(c *gin.Context) {
 		return
 	}
 
+	// Impersonated requests are only let through once they are on the audit trail
+	if claims.ImpersonationID != "" {
+		method := c.GetHeader("X-Forwarded-Method")
+		uri := c.GetHeader("X-Forwarded-Uri")
+		ipAddress := c.GetHeader("X-Forwarded-For")
+		if err := m.impersonationService.RecordImpersonatedAction(claims, method, uri, &ipAddress); err != nil {
+			slog.Error("failed to audit impersonated request", "impersonation_id", claims.ImpersonationID, "error", err)
+			c.JSON(http.StatusServiceUnavailable, utils.ErrorResponse{
+				Success: false,
+				Error: utils.APIError{
+					Code:    "AUDIT_UNAVAILABLE",
+					Message: "impersonated request could not be audited",
+				},
+			})
+			return
+		}
+		c.Header("X-Impersonator-ID", claims.ImpersonatorID)
+		c.Header("X-Impersonation-ID", claims.ImpersonationID)
+	}
+
 	c.Header("X-User-ID", claims.UserID)
 	c.Header("X-User-Email", claims.Email)
+	c.Header("X-Session-ID", sessionID)
 
 	// Return success status for ForwardAuth middleware
 	c.JSON(http.StatusOK, utils.SuccessResponse{
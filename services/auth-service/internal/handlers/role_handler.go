@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -55,6 +56,13 @@ func (r *RoleHandler) RegisterRoutes(router *gin.Engine) {
 		protectedGroup.GET("/users/:userId/permissions", r.GetUserPermissions)
 		protectedGroup.POST("/users/:userId/permissions/check", r.CheckUserPermission)
 
+		// Scoped User-Role Management
+		protectedGroup.POST("/:id/users/:userId/scopes", r.AssignScopedRoleToUser)
+		protectedGroup.DELETE("/:id/users/:userId/scopes/:scopeType/:scopeId", r.RemoveScopedRoleFromUser)
+		protectedGroup.GET("/users/:userId/scoped-roles", r.GetUserScopedRoles)
+		protectedGroup.POST("/users/:userId/scopes/:scopeType/:scopeId/permissions/check", r.CheckUserScopedPermission)
+		protectedGroup.GET("/scopes/:scopeType/:scopeId/members", r.GetScopeMembers)
+
 		// Role Hierarchy
 		protectedGroup.POST("/hierarchy/:parentId/children/:childId", r.CreateRoleHierarchy)
 		protectedGroup.DELETE("/hierarchy/:parentId/children/:childId", r.DeleteRoleHierarchy)
@@ -399,6 +407,150 @@ func (r *RoleHandler) CheckUserPermission(c *gin.Context) {
 	})
 }
 
+// Scoped User-Role Management
+
+// scopedErrorStatus maps scoped role service errors to HTTP status codes
+func scopedErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "forbidden"):
+		return http.StatusForbidden
+	case strings.Contains(err.Error(), "bad_request"):
+		return http.StatusBadRequest
+	case strings.Contains(err.Error(), "not_found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (r *RoleHandler) AssignScopedRoleToUser(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if actorID == "" {
+		utils.SendError(c, http.StatusUnauthorized, "unauthorized", "missing user identity")
+		return
+	}
+
+	roleID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid role ID", err.Error())
+		return
+	}
+
+	userID := c.Param("userId")
+	if userID == "" {
+		utils.SendError(c, http.StatusBadRequest, "invalid user ID", "user ID cannot be empty")
+		return
+	}
+
+	var req models.AssignScopedRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	err = r.roleService.AssignScopedRoleToUser(actorID, userID, roleID, req.ScopeType, req.ScopeID, req.ExpiresAt)
+	if err != nil {
+		utils.SendError(c, scopedErrorStatus(err), "failed to assign scoped role to user", err.Error())
+		return
+	}
+
+	utils.SendMessage(c, http.StatusOK, "scoped role assigned to user successfully")
+}
+
+func (r *RoleHandler) RemoveScopedRoleFromUser(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if actorID == "" {
+		utils.SendError(c, http.StatusUnauthorized, "unauthorized", "missing user identity")
+		return
+	}
+
+	roleID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid role ID", err.Error())
+		return
+	}
+
+	userID := c.Param("userId")
+	if userID == "" {
+		utils.SendError(c, http.StatusBadRequest, "invalid user ID", "user ID cannot be empty")
+		return
+	}
+
+	err = r.roleService.RemoveScopedRoleFromUser(actorID, userID, roleID, c.Param("scopeType"), c.Param("scopeId"))
+	if err != nil {
+		utils.SendError(c, scopedErrorStatus(err), "failed to remove scoped role from user", err.Error())
+		return
+	}
+
+	utils.SendMessage(c, http.StatusOK, "scoped role removed from user successfully")
+}
+
+func (r *RoleHandler) GetUserScopedRoles(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		utils.SendError(c, http.StatusBadRequest, "invalid user ID", "user ID cannot be empty")
+		return
+	}
+
+	activeOnly := c.DefaultQuery("active_only", "true") == "true"
+
+	roles, err := r.roleService.GetUserScopedRoles(userID, activeOnly)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "failed to get user scoped roles", err.Error())
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, gin.H{"scoped_roles": roles})
+}
+
+func (r *RoleHandler) CheckUserScopedPermission(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		utils.SendError(c, http.StatusBadRequest, "invalid user ID", "user ID cannot be empty")
+		return
+	}
+
+	var req models.PermissionCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	scopeType := c.Param("scopeType")
+	scopeID := c.Param("scopeId")
+	hasPermission, err := r.roleService.UserHasScopedPermission(userID, scopeType, scopeID, req.Resource, req.Action)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "failed to check user scoped permission", err.Error())
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, gin.H{
+		"has_permission": hasPermission,
+		"scope_type":     scopeType,
+		"scope_id":       scopeID,
+		"resource":       req.Resource,
+		"action":         req.Action,
+	})
+}
+
+func (r *RoleHandler) GetScopeMembers(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if actorID == "" {
+		utils.SendError(c, http.StatusUnauthorized, "unauthorized", "missing user identity")
+		return
+	}
+
+	activeOnly := c.DefaultQuery("active_only", "true") == "true"
+
+	members, err := r.roleService.GetScopeMembers(actorID, c.Param("scopeType"), c.Param("scopeId"), activeOnly)
+	if err != nil {
+		utils.SendError(c, scopedErrorStatus(err), "failed to get scope members", err.Error())
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, gin.H{"members": members})
+}
+
 // Role Hierarchy
 
 func (r *RoleHandler) CreateRoleHierarchy(c *gin.Context) {
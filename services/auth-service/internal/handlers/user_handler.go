@@ -4,20 +4,26 @@ import (
 	"auth-service/internal/models"
 	"auth-service/internal/services"
 	"auth-service/utils"
+	"context"
+	"errors"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService services.IUserService
+	userService    services.IUserService
+	ekycJobService *services.EkycJobService
 }
 
-func NewUserHandler(userService services.IUserService) *UserHandler {
+func NewUserHandler(userService services.IUserService, ekycJobService *services.EkycJobService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		ekycJobService: ekycJobService,
 	}
 }
 
@@ -43,6 +49,9 @@ func (u *UserHandler) RegisterRoutes(router *gin.Engine, userHandler *UserHandle
 	userAuthGrPro.POST("/ocridcard", userHandler.OCRNationalIDCardHandler)
 	userAuthGrPro.GET("/ekyc-progress/:i", userHandler.GetUserEkycProgressByUserID)
 	userAuthGrPro.POST("/face-liveness", userHandler.VerifyFaceLiveness)
+	userAuthGrPro.POST("/ekyc/nfc", userHandler.VerifyNFCChip)
+	userAuthGrPro.GET("/ekyc/events", userHandler.GetEkycStepEvents)
+	userAuthGrPro.GET("/ekyc/jobs/:job_id", userHandler.GetEkycJob)
 	userAuthGrPro.POST("/user-card", userHandler.UpdateUserCardByUserID)
 
 	// For testing API
@@ -56,7 +65,7 @@ type InitSessionRequest struct {
 
 func (h *UserHandler) GetUserEkycProgressByUserID(c *gin.Context) {
 	userID := c.Param("i")
-	userEkycProgress, err := h.userService.GetUserEkycProgressByUserID(userID)
+	userEkycProgress, err := h.userService.GetEkycProgress(c, userID)
 	if err != nil {
 		if err.Error() == "user ekyc progress not found" {
 			c.JSON(http.StatusNotFound, utils.CreateErrorResponse("NOT_FOUND", "User ekyc progress not found"))
@@ -166,62 +175,133 @@ func (h *UserHandler) UpdatePasswordPhone(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.CreateSuccessResponse("password updated"))
 }
 
+// OCRNationalIDCardHandler queues OCR of the national ID card and returns the job to poll
 func (h *UserHandler) OCRNationalIDCardHandler(c *gin.Context) {
-	form, err := c.MultipartForm()
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse multipart form"})
+	h.submitEkycJob(c, h.ekycJobService.SubmitOCR)
+}
+
+func (h *UserHandler) VerifyNFCChip(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
 		return
 	}
 
-	result, err := h.userService.OCRNationalIDCard(form)
+	var req models.NFCChipVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("INVALID_REQUEST_FORMAT", "sod and dg1 are required"))
+		return
+	}
+
+	result, err := h.userService.VerifyNFCChip(c, userID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process OCR: " + err.Error()})
+		log.Printf("NFC chip verification failed for user %s: %v", userID, err)
+		errorMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errorMsg, "bad_request"):
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", errorMsg))
+		case strings.HasPrefix(errorMsg, "unauthorized"):
+			c.JSON(http.StatusUnprocessableEntity, utils.CreateErrorResponse("CHIP_AUTHENTICATION_FAILED", "Chip data could not be authenticated"))
+		case strings.HasPrefix(errorMsg, "forbidden"):
+			c.JSON(http.StatusForbidden, utils.CreateErrorResponse("NATIONAL_ID_MISMATCH", "Chip does not belong to this user"))
+		case strings.HasPrefix(errorMsg, "conflict"):
+			c.JSON(http.StatusConflict, utils.CreateErrorResponse("ALREADY_NFC_VERIFIED", "User has already completed NFC chip verification"))
+		case strings.HasPrefix(errorMsg, "not_found"):
+			c.JSON(http.StatusNotFound, utils.CreateErrorResponse("NOT_FOUND", "User ekyc progress not found"))
+		case strings.HasPrefix(errorMsg, "unavailable"):
+			c.JSON(http.StatusServiceUnavailable, utils.CreateErrorResponse("SERVICE_UNAVAILABLE", "NFC chip verification is not available"))
+		default:
+			c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to verify NFC chip"))
+		}
 		return
 	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
 
-	switch response := result.(type) {
-	case utils.SuccessResponse:
-		c.JSON(http.StatusOK, response)
+// GetEkycStepEvents lists the eKYC step attempts of the caller, newest first
+func (h *UserHandler) GetEkycStepEvents(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
 		return
-	case utils.ErrorResponse:
-		var statusCode int
-		if response.Error.Code == "INTERNAL_ERROR" {
-			statusCode = http.StatusInternalServerError // 500
-		} else {
-			statusCode = http.StatusBadRequest // 400
-		}
+	}
 
-		c.JSON(statusCode, response)
+	events, err := h.userService.GetEkycStepEvents(c, userID)
+	if err != nil {
+		log.Printf("Failed to get ekyc step events for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get ekyc step events"))
+		return
 	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(events))
 }
 
+// VerifyFaceLiveness queues face liveness of the video and returns the job to poll
 func (h *UserHandler) VerifyFaceLiveness(c *gin.Context) {
-	form, err := c.MultipartForm()
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse multipart form"})
+	h.submitEkycJob(c, h.ekycJobService.SubmitFaceLiveness)
+}
+
+func (h *UserHandler) submitEkycJob(c *gin.Context, submit func(ctx context.Context, userID string, form *multipart.Form) (*models.EkycJob, error)) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
 		return
 	}
 
-	result, err := h.userService.VerifyFaceLiveness(form)
+	form, err := c.MultipartForm()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process face liveness: " + err.Error()})
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Failed to parse multipart form"))
 		return
 	}
-
-	switch response := result.(type) {
-	case utils.SuccessResponse:
-		c.JSON(http.StatusOK, response)
+	// Older clients still send their user_id with the form
+	if formUserIDs := form.Value["user_id"]; len(formUserIDs) > 0 && formUserIDs[0] != userID {
+		c.JSON(http.StatusForbidden, utils.CreateErrorResponse("FORBIDDEN", "user_id does not match the authenticated user"))
 		return
-	case utils.ErrorResponse:
+	}
+
+	job, err := submit(c, userID, form)
+	if err != nil {
+		var stepErr *services.EkycStepError
+		if !errors.As(err, &stepErr) {
+			log.Printf("Failed to submit ekyc job for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to submit ekyc job"))
+			return
+		}
 		var statusCode int
-		if response.Error.Code == "INTERNAL_ERROR" || response.Error.Code == "EXTERNAL_API_ERROR" {
+		switch stepErr.Code {
+		case "INTERNAL_ERROR":
 			statusCode = http.StatusInternalServerError
-		} else {
+		case "SERVICE_UNAVAILABLE":
+			statusCode = http.StatusServiceUnavailable
+		case "JOB_IN_PROGRESS":
+			statusCode = http.StatusConflict
+		default:
 			statusCode = http.StatusBadRequest
 		}
+		c.JSON(statusCode, utils.CreateErrorResponse(stepErr.Code, stepErr.Message))
+		return
+	}
+	c.JSON(http.StatusAccepted, utils.CreateSuccessResponse(job))
+}
+
+// GetEkycJob returns the status of an eKYC job of the caller
+func (h *UserHandler) GetEkycJob(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+		return
+	}
 
-		c.JSON(statusCode, response)
+	job, err := h.ekycJobService.GetJob(userID, c.Param("job_id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not_found") {
+			c.JSON(http.StatusNotFound, utils.CreateErrorResponse("NOT_FOUND", "Ekyc job not found"))
+			return
+		}
+		log.Printf("Failed to get ekyc job %s: %v", c.Param("job_id"), err)
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get ekyc job"))
+		return
 	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(job))
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
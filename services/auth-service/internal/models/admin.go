@@ -0,0 +1,36 @@
+package models
+
+// AdminUserFilter narrows the admin user search. Phone numbers match by prefix and emails by any
+// part of the address.
+type AdminUserFilter struct {
+	Phone      string `form:"phone" json:"phone,omitempty"`
+	Email      string `form:"email" json:"email,omitempty"`
+	Status     string `form:"status" json:"status,omitempty"`
+	EkycStatus string `form:"ekyc_status" json:"ekyc_status,omitempty"`
+	Limit      int    `form:"limit" json:"limit"`
+	Offset     int    `form:"offset" json:"offset"`
+}
+
+// AdminUserView is a user as listed to admins, with where they are in eKYC
+type AdminUserView struct {
+	User
+	EkycState *string `json:"ekyc_state" db:"ekyc_state"`
+}
+
+type AdminUserSearchResponse struct {
+	Users  []*AdminUserView `json:"users"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// SetUserRolesRequest replaces the global roles of a user with the named ones
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
+type SetUserRolesResponse struct {
+	Roles   []string `json:"roles"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
@@ -1,17 +1,72 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audited actions. Logins are recorded against the user logging in; admin actions against the
+// admin, with the affected user as the resource.
+const (
+	AuditActionLogin       = "login"
+	AuditActionLoginFailed = "login_failed"
+
+	AuditActionAdminSearchUsers      = "admin.search_users"
+	AuditActionAdminViewLoginHistory = "admin.view_login_history"
+	AuditActionAdminForceLogout      = "admin.force_logout"
+	AuditActionAdminChangeRoles      = "admin.change_roles"
+	AuditActionAdminViewAuditLogs    = "admin.view_audit_logs"
+)
+
+const AuditResourceUser = "user"
 
 type AuditLog struct {
-	ID           int       `json:"id" db:"id"`
-	UserID       *string   `json:"user_id" db:"user_id"`
-	Action       string    `json:"action" db:"action"`
-	ResourceType *string   `json:"resource_type" db:"resource_type"`
-	ResourceID   *string   `json:"resource_id" db:"resource_id"`
-	IPAddress    *string   `json:"ip_address" db:"ip_address"`
-	Success      bool      `json:"success" db:"success"`
-	ErrorMessage *string   `json:"error_message" db:"error_message"`
-	Timestamp    time.Time `json:"timestamp" db:"timestamp"`
+	ID              int          `json:"id" db:"id"`
+	UserID          *string      `json:"user_id" db:"user_id"`
+	Action          string       `json:"action" db:"action"`
+	ResourceType    *string      `json:"resource_type" db:"resource_type"`
+	ResourceID      *string      `json:"resource_id" db:"resource_id"`
+	IPAddress       *string      `json:"ip_address" db:"ip_address"`
+	Success         bool         `json:"success" db:"success"`
+	ErrorMessage    *string      `json:"error_message" db:"error_message"`
+	ImpersonatorID  *string      `json:"impersonator_id" db:"impersonator_id"`
+	ImpersonationID *string      `json:"impersonation_id" db:"impersonation_id"`
+	Timestamp       time.Time    `json:"timestamp" db:"timestamp"`
+	Details         AuditDetails `json:"details,omitempty" db:"details"`
+}
+
+// AuditDetails holds what else is known about an audited action
+type AuditDetails map[string]any
+
+func (d AuditDetails) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(d)
+}
+
+func (d *AuditDetails) Scan(value any) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+	raw, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("AuditDetails: Scan failed, expected []byte but got %T", value)
+	}
+	return json.Unmarshal(raw, d)
+}
+
+// AuditLogFilter selects audit entries; empty fields match everything
+type AuditLogFilter struct {
+	UserID       string
+	ResourceType string
+	ResourceID   string
+	Actions      []string
+	Limit        int
+	Offset       int
 }
 
 type PasswordHistory struct {
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+type ConsentType string
+
+const (
+	ConsentDataProcessing      ConsentType = "data_processing"
+	ConsentSatelliteMonitoring ConsentType = "satellite_monitoring"
+	ConsentMarketing           ConsentType = "marketing"
+)
+
+func (t ConsentType) IsValid() bool {
+	switch t {
+	case ConsentDataProcessing, ConsentSatelliteMonitoring, ConsentMarketing:
+		return true
+	}
+	return false
+}
+
+// ConsentText is an immutable, versioned wording shown to users when they give consent
+type ConsentText struct {
+	ID          int         `json:"id" db:"id"`
+	ConsentType ConsentType `json:"consent_type" db:"consent_type"`
+	Version     int         `json:"version" db:"version"`
+	Content     string      `json:"content" db:"content"`
+	IsActive    bool        `json:"is_active" db:"is_active"`
+	EffectiveAt time.Time   `json:"effective_at" db:"effective_at"`
+	CreatedBy   *string     `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+}
+
+// UserConsent records a user's decision on a specific consent text version
+type UserConsent struct {
+	ID            int         `json:"id" db:"id"`
+	UserID        string      `json:"user_id" db:"user_id"`
+	ConsentType   ConsentType `json:"consent_type" db:"consent_type"`
+	ConsentTextID int         `json:"consent_text_id" db:"consent_text_id"`
+	Version       int         `json:"version" db:"version"`
+	GrantedAt     time.Time   `json:"granted_at" db:"granted_at"`
+	WithdrawnAt   *time.Time  `json:"withdrawn_at" db:"withdrawn_at"`
+	IPAddress     *string     `json:"ip_address" db:"ip_address"`
+	UserAgent     *string     `json:"user_agent" db:"user_agent"`
+}
+
+func (c *UserConsent) IsActive() bool {
+	return c.WithdrawnAt == nil
+}
+
+type PublishConsentTextRequest struct {
+	ConsentType ConsentType `json:"consent_type" binding:"required"`
+	Content     string      `json:"content" binding:"required"`
+}
+
+type GrantConsentRequest struct {
+	ConsentType ConsentType `json:"consent_type" binding:"required"`
+	Version     int         `json:"version" binding:"required"`
+}
+
+type ConsentStatusResponse struct {
+	UserID      string      `json:"user_id"`
+	ConsentType ConsentType `json:"consent_type"`
+	Granted     bool        `json:"granted"`
+	Version     int         `json:"version,omitempty"`
+	GrantedAt   *time.Time  `json:"granted_at,omitempty"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// eKYC states. The identity document is verified first, by OCR of the card or a chip read, then
+// face liveness; resetting eKYC starts over from ocr_pending.
+const (
+	EkycStateOCRPending      = "ocr_pending"
+	EkycStateOCRDone         = "ocr_done"
+	EkycStateLivenessPending = "liveness_pending"
+	EkycStateVerified        = "verified"
+)
+
+var EkycStates = []string{EkycStateOCRPending, EkycStateOCRDone, EkycStateLivenessPending, EkycStateVerified}
+
+// Outcomes of an eKYC step attempt
+const (
+	EkycStepSucceeded = "succeeded"
+	EkycStepFailed    = "failed"
+	EkycStepResumed   = "resumed"
+)
+
+// EkycStepEvent is the audit record of one eKYC step attempt
+type EkycStepEvent struct {
+	ID           int64     `json:"id" db:"id"`
+	UserID       string    `json:"user_id" db:"user_id"`
+	Step         string    `json:"step" db:"step"`
+	Outcome      string    `json:"outcome" db:"outcome"`
+	State        string    `json:"state" db:"state"`
+	NextState    *string   `json:"next_state,omitempty" db:"next_state"`
+	ErrorCode    *string   `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage *string   `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// EkycVerifiedEvent is sent to the eKYC webhooks when a user completes eKYC
+type EkycVerifiedEvent struct {
+	Event             string    `json:"event"`
+	UserID            string    `json:"user_id"`
+	VerificationLevel string    `json:"verification_level"`
+	VerifiedAt        time.Time `json:"verified_at"`
+}
+
+// Kinds of eKYC job, one per step calling the eKYC vendor
+const (
+	EkycJobKindOCR          = "ocr"
+	EkycJobKindFaceLiveness = "face_liveness"
+)
+
+// eKYC job statuses. Failed jobs can be submitted again and resume after the last step that
+// succeeded.
+const (
+	EkycJobQueued     = "queued"
+	EkycJobProcessing = "processing"
+	EkycJobSucceeded  = "succeeded"
+	EkycJobFailed     = "failed"
+)
+
+// EkycJob is an eKYC step run in the background, so clients do not wait on the vendor
+type EkycJob struct {
+	ID           string       `json:"id" db:"id"`
+	UserID       string       `json:"user_id" db:"user_id"`
+	Kind         string       `json:"kind" db:"kind"`
+	Status       string       `json:"status" db:"status"`
+	Files        EkycJobFiles `json:"-" db:"files"`
+	Attempts     int          `json:"attempts" db:"attempts"`
+	ErrorCode    *string      `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage *string      `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+	CompletedAt  *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// EkycJobFile is a file submitted with a job, stored in MinIO until the job runs
+type EkycJobFile struct {
+	ObjectName string `json:"object_name"`
+	URL        string `json:"url"`
+	// SHA256 of the content, telling whether a cached step ran on the same file
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// EkycJobFiles maps form fields, like cccd_front, to the stored files
+type EkycJobFiles map[string]EkycJobFile
+
+func (f EkycJobFiles) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(f)
+}
+
+func (f *EkycJobFiles) Scan(value any) error {
+	raw, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("EkycJobFiles: Scan failed, expected []byte but got %T", value)
+	}
+	return json.Unmarshal(raw, f)
+}
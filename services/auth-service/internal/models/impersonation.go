@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	ImpersonationEndManual  = "ended_by_support"
+	ImpersonationEndExpired = "expired"
+)
+
+type ImpersonationSession struct {
+	ID            string     `json:"id" db:"id"`
+	SupportUserID string     `json:"support_user_id" db:"support_user_id"`
+	TargetUserID  string     `json:"target_user_id" db:"target_user_id"`
+	SessionID     string     `json:"-" db:"session_id"`
+	Reason        string     `json:"reason" db:"reason"`
+	IPAddress     *string    `json:"ip_address" db:"ip_address"`
+	StartedAt     time.Time  `json:"started_at" db:"started_at"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	EndedAt       *time.Time `json:"ended_at" db:"ended_at"`
+	EndReason     *string    `json:"end_reason" db:"end_reason"`
+	ActionCount   int        `json:"action_count" db:"action_count"`
+	UserNotified  bool       `json:"user_notified" db:"user_notified"`
+}
+
+type StartImpersonationRequest struct {
+	TargetUserID    string `json:"target_user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"required"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+type StartImpersonationResponse struct {
+	Impersonation *ImpersonationSession `json:"impersonation"`
+	AccessToken   string                `json:"access_token"`
+}
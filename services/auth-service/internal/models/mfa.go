@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// UserMFA is the TOTP second factor of a user; it is pending until the first code confirms it
+type UserMFA struct {
+	UserID           string     `json:"user_id" db:"user_id"`
+	SecretCiphertext string     `json:"-" db:"secret_ciphertext"`
+	Enabled          bool       `json:"enabled" db:"enabled"`
+	LastUsedStep     int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt      *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MFARolePolicy tells whether the holders of a role must use MFA to log in
+type MFARolePolicy struct {
+	RoleName  string    `json:"role_name" db:"role_name"`
+	Required  bool      `json:"required" db:"required"`
+	UpdatedBy *string   `json:"updated_by" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MFAEnrollment is returned once when enrolling; the URI is rendered as a QR code by the client
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFATicketRequest is sent by users who must enroll before they can log in, with the ticket
+// returned by the rejected login
+type MFATicketRequest struct {
+	Ticket string `json:"ticket" binding:"required"`
+	Code   string `json:"code"`
+}
+
+type UpdateMFARolePolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
@@ -21,6 +21,27 @@ type UserRole struct {
 	IsActive   bool    `json:"is_active" db:"is_active"`
 }
 
+// ScopedUserRole grants a role only within a scope, e.g. admin_partner within one insurance provider
+type ScopedUserRole struct {
+	ID         int     `json:"id" db:"id"`
+	UserID     string  `json:"user_id" db:"user_id"`
+	RoleID     int     `json:"role_id" db:"role_id"`
+	RoleName   string  `json:"role_name" db:"role_name"`
+	ScopeType  string  `json:"scope_type" db:"scope_type"`
+	ScopeID    string  `json:"scope_id" db:"scope_id"`
+	AssignedBy *string `json:"assigned_by" db:"assigned_by"`
+	AssignedAt int64   `json:"assigned_at" db:"assigned_at"`
+	ExpiresAt  *int64  `json:"expires_at" db:"expires_at"`
+	IsActive   bool    `json:"is_active" db:"is_active"`
+}
+
+// RoleScope is the JWT claim form of a user's scoped roles
+type RoleScope struct {
+	ScopeType string   `json:"scope_type"`
+	ScopeID   string   `json:"scope_id"`
+	Roles     []string `json:"roles"`
+}
+
 type Permission struct {
 	ID          int       `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"`
@@ -48,3 +69,10 @@ const (
 	UserRoleID  int = 1
 	AdminRoleID int = 2
 )
+
+const (
+	ScopeTypeInsuranceProvider = "insurance_provider"
+
+	AdminRoleName        = "admin"
+	AdminPartnerRoleName = "admin_partner"
+)
@@ -6,9 +6,14 @@ import (
 
 // Authentication DTOs
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Password string `json:"password"`
+	Email             string `json:"email"`
+	Phone             string `json:"phone"`
+	Password          string `json:"password"`
+	DeviceFingerprint string `json:"device_fingerprint"`
+	OTP               string `json:"otp"`
+	TrustDevice       bool   `json:"trust_device"`
+	// Code of the authenticator app, or a recovery code, for users with MFA
+	MFACode string `json:"mfa_code"`
 }
 
 type RegisterRequest struct {
@@ -24,6 +29,22 @@ type LoginResponse struct {
 	AccessToken string       `json:"access_token"`
 }
 
+// Account lifecycle DTOs
+type DeactivateAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+type ReactivationOTPRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type ReactivateAccountRequest struct {
+	Phone    string `json:"phone" binding:"required"`
+	OTP      string `json:"otp" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
 // Role Management DTOs
 type CreateRoleRequest struct {
 	Name        string `json:"name" binding:"required"`
@@ -41,6 +62,12 @@ type AssignRoleRequest struct {
 	ExpiresAt  *time.Time `json:"expires_at"`
 }
 
+type AssignScopedRoleRequest struct {
+	ScopeType string     `json:"scope_type" binding:"required"`
+	ScopeID   string     `json:"scope_id" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
 type PermissionCheckRequest struct {
 	Resource string `json:"resource" binding:"required"`
 	Action   string `json:"action" binding:"required"`
@@ -111,3 +138,18 @@ type UpdateUserCardRequest struct {
 	ImageFront        *string `json:"image_front" db:"image_front"`
 	ImageBack         *string `json:"image_back" db:"image_back"`
 }
+
+type RegisterTrustedDeviceRequest struct {
+	DeviceFingerprint string `json:"device_fingerprint" binding:"required"`
+	DeviceName        string `json:"device_name"`
+	OTP               string `json:"otp" binding:"required"`
+}
+
+// NFCChipVerificationRequest carries the raw data groups read from the CCCD chip
+// by the mobile SDK, each base64 encoded
+type NFCChipVerificationRequest struct {
+	SOD  string `json:"sod" binding:"required"`
+	DG1  string `json:"dg1" binding:"required"`
+	DG2  string `json:"dg2"`
+	DG13 string `json:"dg13"`
+}
@@ -12,12 +12,38 @@ type UserSession struct {
 	TokenHash        string    `json:"-" db:"token_hash"`
 	RefreshTokenHash *string   `json:"-" db:"refresh_token_hash"`
 	DeviceInfo       *string   `json:"device_info" db:"device_info"`
+	DeviceHash       *string   `json:"-" db:"device_hash"`
 	IPAddress        *string   `json:"ip_address" db:"ip_address"`
 	ExpiresAt        time.Time `json:"expires_at" db:"expires_at"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	LastActiveAt     time.Time `json:"last_active_at" db:"last_active_at"`
 	IsActive         bool      `json:"is_active" db:"is_active"`
 }
 
+// ActiveSession is a session as listed to its user, marking the one making the request
+type ActiveSession struct {
+	*UserSession
+	IsCurrent bool `json:"is_current"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// TrustedDevice is a device the user has verified with OTP. Logins from a
+// trusted device skip the OTP step until the trust expires or is revoked.
+type TrustedDevice struct {
+	ID              string    `json:"id" db:"id"`
+	UserID          string    `json:"user_id" db:"user_id"`
+	FingerprintHash string    `json:"-" db:"fingerprint_hash"`
+	DeviceName      *string   `json:"device_name" db:"device_name"`
+	DeviceInfo      *string   `json:"device_info" db:"device_info"`
+	IPAddress       *string   `json:"ip_address" db:"ip_address"`
+	TrustedAt       time.Time `json:"trusted_at" db:"trusted_at"`
+	LastUsedAt      time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt       time.Time `json:"expires_at" db:"expires_at"`
+}
+
 type APIKey struct {
 	ID        int        `json:"id" db:"id"`
 	UserID    string     `json:"user_id" db:"user_id"`
@@ -43,4 +69,11 @@ type Claims struct {
 	UserID string
 	Email  string
 	Phone  string
+	// Names of the active unscoped roles, e.g. farmer or admin
+	Roles []string `json:",omitempty"`
+	// Roles held within a scope, e.g. staff roles within an insurance provider
+	Scopes []RoleScope `json:",omitempty"`
+	// Impersonation tokens carry the support user and impersonation session
+	ImpersonatorID  string `json:",omitempty"`
+	ImpersonationID string `json:",omitempty"`
 }
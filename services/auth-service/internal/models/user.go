@@ -7,21 +7,26 @@ import (
 const SystemID string = "System"
 
 type User struct {
-	ID            string     `json:"id" db:"id"`
-	PhoneNumber   string     `json:"phone_number" db:"phone_number"`
-	Email         string     `json:"email" db:"email"`
-	PasswordHash  string     `json:"-" db:"password_hash"`
-	NationalID    string     `json:"-" db:"national_id"`
-	Status        UserStatus `json:"status" db:"status"`
-	EmailVerified bool       `json:"email_verified" db:"email_verified"`
-	PhoneVerified bool       `json:"phone_verified" db:"phone_verified"`
-	KYCVerified   bool       `json:"kyc_verified" db:"kyc_verified"`
-	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
-	LastLogin     *time.Time `json:"last_login" db:"last_login"`
-	LockedUntil   int64      `json:"locked_until" db:"locked_until"`
-	LoginAttempts int64      `json:"login_attempts" db:"login_attempts"`
-	FaceLiveness  *string    `json:"face_liveness" db:"face_liveness"`
+	ID           string `json:"id" db:"id"`
+	PhoneNumber  string `json:"phone_number" db:"phone_number"`
+	Email        string `json:"email" db:"email"`
+	PasswordHash string `json:"-" db:"password_hash"`
+	NationalID   string `json:"-" db:"national_id"`
+	// Keyed hash of the national ID, which is stored encrypted
+	NationalIDHash *string    `json:"-" db:"national_id_hash"`
+	Status         UserStatus `json:"status" db:"status"`
+	EmailVerified  bool       `json:"email_verified" db:"email_verified"`
+	PhoneVerified  bool       `json:"phone_verified" db:"phone_verified"`
+	KYCVerified    bool       `json:"kyc_verified" db:"kyc_verified"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	LastLogin      *time.Time `json:"last_login" db:"last_login"`
+	LockedUntil    int64      `json:"locked_until" db:"locked_until"`
+	LoginAttempts  int64      `json:"login_attempts" db:"login_attempts"`
+	FaceLiveness   *string    `json:"face_liveness" db:"face_liveness"`
+
+	DeactivatedAt      *time.Time `json:"deactivated_at" db:"deactivated_at"`
+	DeactivationReason *string    `json:"deactivation_reason" db:"deactivation_reason"`
 }
 
 type UserStatus string
@@ -40,6 +45,67 @@ type UserEkycProgress struct {
 	OcrDoneAt      *time.Time `json:"ocr_done_at" db:"ocr_done_at"`
 	IsFaceVerified bool       `json:"is_face_verified" db:"is_face_verified"`
 	FaceVerifiedAt *time.Time `json:"face_verified_at" db:"face_verified_at"`
+	IsNfcVerified  bool       `json:"is_nfc_verified" db:"is_nfc_verified"`
+	NfcVerifiedAt  *time.Time `json:"nfc_verified_at" db:"nfc_verified_at"`
+	State          string     `json:"state" db:"state"`
+	StateUpdatedAt *time.Time `json:"state_updated_at" db:"state_updated_at"`
+}
+
+// Identity verification levels, from weakest to strongest. A chip read is
+// signed by the issuing authority, so it ranks above OCR of the card images.
+const (
+	VerificationLevelNone    = "none"
+	VerificationLevelOCR     = "ocr"
+	VerificationLevelNFCChip = "nfc_chip"
+)
+
+// IsDocumentVerified reports whether the identity document step is done, by OCR or chip read
+func (p *UserEkycProgress) IsDocumentVerified() bool {
+	return p.IsOcrDone || p.IsNfcVerified
+}
+
+// VerificationLevel returns the strongest document verification completed by the user
+func (p *UserEkycProgress) VerificationLevel() string {
+	switch {
+	case p.IsNfcVerified:
+		return VerificationLevelNFCChip
+	case p.IsOcrDone:
+		return VerificationLevelOCR
+	default:
+		return VerificationLevelNone
+	}
+}
+
+// NFCChipData holds the identity fields read from a CCCD chip after passive authentication
+type NFCChipData struct {
+	DocumentNumber string `json:"document_number"`
+	NationalID     string `json:"national_id"`
+	FullName       string `json:"full_name"`
+	DateOfBirth    string `json:"date_of_birth"`
+	Sex            string `json:"sex"`
+	DateOfExpiry   string `json:"date_of_expiry"`
+	IssuingState   string `json:"issuing_state"`
+	SignerSubject  string `json:"signer_subject"`
+}
+
+// EkycCachedStep is an intermediate eKYC result kept in Redis so a failed attempt can resume
+type EkycCachedStep struct {
+	Step        string    `json:"step"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+const (
+	EkycNextStepOCR          = "ocr"
+	EkycNextStepFaceLiveness = "face_liveness"
+	EkycNextStepCompleted    = "completed"
+)
+
+// EkycProgressResponse combines persisted eKYC progress with cached intermediate steps
+type EkycProgressResponse struct {
+	*UserEkycProgress
+	CachedSteps       []EkycCachedStep `json:"cached_steps"`
+	NextStep          string           `json:"next_step"`
+	VerificationLevel string           `json:"verification_level"`
 }
 
 type UserCard struct {
@@ -59,4 +125,15 @@ type UserCard struct {
 	ImageFront        string `json:"image_front" db:"image_front"`
 	ImageBack         string `json:"image_back" db:"image_back"`
 	UserID            string `json:"user_id" db:"user_id"`
+	// Keyed hash of the national ID, which is stored encrypted
+	NationalIDHash *string `json:"-" db:"national_id_hash"`
+
+	AddressStreet         string `json:"address_street" db:"address_street"`
+	AddressCommuneCode    string `json:"address_commune_code" db:"address_commune_code"`
+	AddressCommuneName    string `json:"address_commune_name" db:"address_commune_name"`
+	AddressProvinceCode   string `json:"address_province_code" db:"address_province_code"`
+	AddressProvinceName   string `json:"address_province_name" db:"address_province_name"`
+	AddressLegacyDistrict string `json:"address_legacy_district" db:"address_legacy_district"`
+	AddressLegacyProvince string `json:"address_legacy_province" db:"address_legacy_province"`
+	AddressMatchLevel     string `json:"address_match_level" db:"address_match_level"`
 }
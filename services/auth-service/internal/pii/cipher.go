@@ -0,0 +1,138 @@
+// Package pii encrypts personal data stored by auth-service, such as national IDs and card
+// addresses, before it reaches the database.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Encrypted values are stored as "enc:<key id>:<base64 of nonce and sealed value>". Values
+// without the prefix are plaintext written before encryption was introduced, and are read as is
+// until the re-encryption tool has processed them.
+const encryptedPrefix = "enc:"
+
+// Cipher encrypts fields with AES-256-GCM. Several keys can be loaded so values written under
+// an old key still decrypt while the re-encryption tool moves them to the current one.
+type Cipher struct {
+	currentKeyID string
+	keys         map[string]cipher.AEAD
+	indexKey     []byte
+}
+
+// NewCipher takes the keys as comma separated "<id>:<secret>" pairs, the first being the one new
+// values are encrypted with, and the secret blind indexes are computed with. Each AES key is the
+// SHA-256 of its secret, so secrets can come from the environment or a KMS in any format.
+func NewCipher(keys, indexSecret string) (*Cipher, error) {
+	c := &Cipher{keys: map[string]cipher.AEAD{}}
+	for i, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			// The entry is not echoed, as without a separator it is the secret itself
+			return nil, fmt.Errorf("invalid pii key at position %d, expected <id>:<secret>", i+1)
+		}
+		if _, exists := c.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate pii key id %q", id)
+		}
+
+		key := sha256.Sum256([]byte(secret))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pii cipher: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pii cipher: %w", err)
+		}
+		c.keys[id] = aead
+		if c.currentKeyID == "" {
+			c.currentKeyID = id
+		}
+	}
+	if c.currentKeyID == "" {
+		return nil, fmt.Errorf("no pii encryption key configured")
+	}
+	if indexSecret == "" {
+		return nil, fmt.Errorf("no pii index key configured")
+	}
+
+	indexKey := sha256.Sum256([]byte(indexSecret))
+	c.indexKey = indexKey[:]
+	return c, nil
+}
+
+// Encrypt seals a value of the given field, such as "users.national_id". The field is
+// authenticated with the value, so a ciphertext copied into another column fails to decrypt.
+// Empty values stay empty.
+func (c *Cipher) Encrypt(field, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead := c.keys[c.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+	return encryptedPrefix + c.currentKeyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value of the given field. Plaintext values are returned unchanged.
+func (c *Cipher) Decrypt(field, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", fmt.Errorf("invalid %s ciphertext", field)
+	}
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%s is encrypted with unknown key %q", field, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid %s ciphertext", field)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencryption tells whether a stored value is plaintext or sealed with an older key
+func (c *Cipher) NeedsReencryption(value string) bool {
+	if value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, encryptedPrefix+c.currentKeyID+":")
+}
+
+// BlindIndex returns the keyed hash of a value, stored next to its ciphertext so the column can
+// still be looked up and kept unique. Empty values have no index.
+func (c *Cipher) BlindIndex(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+	index := hex.EncodeToString(mac.Sum(nil))
+	return &index
+}
@@ -0,0 +1,213 @@
+package pii
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIndexKey = "test-index-key"
+
+func TestNewCipher_Keys(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		indexKey string
+		wantErr  string
+	}{
+		{name: "single key", keys: "v1:secret-one", indexKey: testIndexKey},
+		{name: "rotation list", keys: "v2:secret-two, v1:secret-one", indexKey: testIndexKey},
+		{name: "secret containing a colon", keys: "v1:secret:with:colons", indexKey: testIndexKey},
+		{name: "unset", keys: "", indexKey: testIndexKey, wantErr: "no pii encryption key configured"},
+		{name: "only separators", keys: " , ", indexKey: testIndexKey, wantErr: "no pii encryption key configured"},
+		{name: "missing id separator", keys: "secret-one", indexKey: testIndexKey, wantErr: "invalid pii key"},
+		{name: "empty secret", keys: "v1:", indexKey: testIndexKey, wantErr: "invalid pii key"},
+		{name: "empty id", keys: ":secret-one", indexKey: testIndexKey, wantErr: "invalid pii key"},
+		{name: "duplicate id", keys: "v1:secret-one,v1:secret-two", indexKey: testIndexKey, wantErr: "duplicate pii key id"},
+		{name: "no index key", keys: "v1:secret-one", wantErr: "no pii index key configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCipher(tt.keys, tt.indexKey)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, c)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, c)
+		})
+	}
+}
+
+func TestNewCipher_ErrorDoesNotLeakSecret(t *testing.T) {
+	_, err := NewCipher("v1secret-one", testIndexKey)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-one")
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{name: "national id", plaintext: "079203001234"},
+		{name: "unicode address", plaintext: "Số 1, Đường Lê Lợi, Quận 1, TP. Hồ Chí Minh"},
+		{name: "empty", plaintext: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := c.Encrypt("users.national_id", tt.plaintext)
+			require.NoError(t, err)
+			if tt.plaintext == "" {
+				assert.Empty(t, encrypted)
+			} else {
+				assert.True(t, strings.HasPrefix(encrypted, "enc:v1:"), encrypted)
+				assert.NotContains(t, encrypted, tt.plaintext)
+			}
+
+			decrypted, err := c.Decrypt("users.national_id", encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plaintext, decrypted)
+		})
+	}
+}
+
+func TestCipher_EncryptUsesFreshNonce(t *testing.T) {
+	c, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+
+	first, err := c.Encrypt("users.national_id", "079203001234")
+	require.NoError(t, err)
+	second, err := c.Encrypt("users.national_id", "079203001234")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestCipher_DecryptRejects(t *testing.T) {
+	c, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+	encrypted, err := c.Encrypt("users.national_id", "079203001234")
+	require.NoError(t, err)
+
+	other, err := NewCipher("v1:another-secret", testIndexKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		cipher  *Cipher
+		field   string
+		value   string
+		wantErr string
+	}{
+		{name: "value of another field", cipher: c, field: "user_card.national_id", value: encrypted, wantErr: "failed to decrypt"},
+		{name: "same key id with another secret", cipher: other, field: "users.national_id", value: encrypted, wantErr: "failed to decrypt"},
+		{name: "unknown key id", cipher: c, field: "users.national_id", value: strings.Replace(encrypted, "enc:v1:", "enc:v9:", 1), wantErr: "unknown key"},
+		{name: "missing key id", cipher: c, field: "users.national_id", value: "enc:abc", wantErr: "invalid users.national_id ciphertext"},
+		{name: "not base64", cipher: c, field: "users.national_id", value: "enc:v1:!!!", wantErr: "invalid users.national_id ciphertext"},
+		{name: "truncated", cipher: c, field: "users.national_id", value: "enc:v1:AAAA", wantErr: "invalid users.national_id ciphertext"},
+		{name: "tampered", cipher: c, field: "users.national_id", value: encrypted[:len(encrypted)-4] + "AAAA", wantErr: "failed to decrypt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cipher.Decrypt(tt.field, tt.value)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCipher_DecryptPlaintextPassesThrough(t *testing.T) {
+	c, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+
+	decrypted, err := c.Decrypt("users.national_id", "079203001234")
+	require.NoError(t, err)
+	assert.Equal(t, "079203001234", decrypted)
+}
+
+func TestCipher_KeyRotation(t *testing.T) {
+	before, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+	sealedV1, err := before.Encrypt("user_card.address", "Xã An Bình")
+	require.NoError(t, err)
+
+	after, err := NewCipher("v2:secret-two,v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+
+	// Values sealed with the old key still decrypt, and new values use the new key
+	decrypted, err := after.Decrypt("user_card.address", sealedV1)
+	require.NoError(t, err)
+	assert.Equal(t, "Xã An Bình", decrypted)
+
+	sealedV2, err := after.Encrypt("user_card.address", decrypted)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealedV2, "enc:v2:"), sealedV2)
+
+	// Once the old key is retired, only the re-encrypted value opens
+	retired, err := NewCipher("v2:secret-two", testIndexKey)
+	require.NoError(t, err)
+	_, err = retired.Decrypt("user_card.address", sealedV1)
+	assert.ErrorContains(t, err, "unknown key")
+	decrypted, err = retired.Decrypt("user_card.address", sealedV2)
+	require.NoError(t, err)
+	assert.Equal(t, "Xã An Bình", decrypted)
+}
+
+func TestCipher_NeedsReencryption(t *testing.T) {
+	before, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+	sealedV1, err := before.Encrypt("users.national_id", "079203001234")
+	require.NoError(t, err)
+
+	after, err := NewCipher("v2:secret-two,v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+	sealedV2, err := after.Encrypt("users.national_id", "079203001234")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "plaintext", value: "079203001234", want: true},
+		{name: "sealed with an older key", value: sealedV1, want: true},
+		{name: "sealed with the current key", value: sealedV2, want: false},
+		{name: "empty", value: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, after.NeedsReencryption(tt.value))
+		})
+	}
+}
+
+func TestCipher_BlindIndex(t *testing.T) {
+	c, err := NewCipher("v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+
+	index := c.BlindIndex("079203001234")
+	require.NotNil(t, index)
+	assert.Len(t, *index, 64)
+	assert.Equal(t, *index, *c.BlindIndex(" 079203001234 "), "surrounding spaces are ignored")
+	assert.NotEqual(t, *index, *c.BlindIndex("079203001235"))
+	assert.Nil(t, c.BlindIndex(""))
+	assert.Nil(t, c.BlindIndex("   "))
+
+	// The index does not depend on the encryption keys, so it survives rotation
+	rotated, err := NewCipher("v2:secret-two,v1:secret-one", testIndexKey)
+	require.NoError(t, err)
+	assert.Equal(t, *index, *rotated.BlindIndex("079203001234"))
+
+	otherIndexKey, err := NewCipher("v1:secret-one", "another-index-key")
+	require.NoError(t, err)
+	assert.NotEqual(t, *index, *otherIndexKey.BlindIndex("079203001234"))
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IAuditRepository interface {
+	CreateAuditLog(auditLog *models.AuditLog) error
+	GetAuditLogs(filter models.AuditLogFilter) ([]*models.AuditLog, error)
+}
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) IAuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) CreateAuditLog(auditLog *models.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (user_id, action, resource_type, resource_id, ip_address, success, error_message, impersonator_id, impersonation_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, timestamp`
+
+	err := r.db.QueryRow(query, auditLog.UserID, auditLog.Action, auditLog.ResourceType, auditLog.ResourceID, auditLog.IPAddress,
+		auditLog.Success, auditLog.ErrorMessage, auditLog.ImpersonatorID, auditLog.ImpersonationID, auditLog.Details).
+		Scan(&auditLog.ID, &auditLog.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// GetAuditLogs returns the matching entries, newest first
+func (r *AuditRepository) GetAuditLogs(filter models.AuditLogFilter) ([]*models.AuditLog, error) {
+	conditions := []string{}
+	args := []any{}
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != "" {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if filter.ResourceType != "" {
+		addCondition("resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		addCondition("resource_id = $%d", filter.ResourceID)
+	}
+	if len(filter.Actions) > 0 {
+		addCondition("action = ANY($%d)", pq.Array(filter.Actions))
+	}
+
+	query := `SELECT * FROM audit_logs`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY timestamp DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	var logs []*models.AuditLog
+	if err := r.db.Select(&logs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	return logs, nil
+}
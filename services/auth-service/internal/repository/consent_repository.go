@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IConsentRepository interface {
+	CreateConsentText(text *models.ConsentText) error
+	GetActiveConsentText(consentType models.ConsentType) (*models.ConsentText, error)
+	GetActiveConsentTexts() ([]*models.ConsentText, error)
+	GetConsentTextByVersion(consentType models.ConsentType, version int) (*models.ConsentText, error)
+	GrantConsent(consent *models.UserConsent) error
+	WithdrawConsent(userID string, consentType models.ConsentType) error
+	GetActiveUserConsent(userID string, consentType models.ConsentType) (*models.UserConsent, error)
+	GetUserConsentHistory(userID string) ([]*models.UserConsent, error)
+}
+
+type ConsentRepository struct {
+	db *sqlx.DB
+}
+
+func NewConsentRepository(db *sqlx.DB) IConsentRepository {
+	return &ConsentRepository{
+		db: db,
+	}
+}
+
+// CreateConsentText inserts the next version of a consent text and retires the previous one
+func (r *ConsentRepository) CreateConsentText(text *models.ConsentText) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentVersion int
+	err = tx.Get(&currentVersion, `SELECT COALESCE(MAX(version), 0) FROM consent_texts WHERE consent_type = $1`, text.ConsentType)
+	if err != nil {
+		return fmt.Errorf("failed to get current consent version: %w", err)
+	}
+
+	_, err = tx.Exec(`UPDATE consent_texts SET is_active = false WHERE consent_type = $1`, text.ConsentType)
+	if err != nil {
+		return fmt.Errorf("failed to retire previous consent texts: %w", err)
+	}
+
+	text.Version = currentVersion + 1
+	text.IsActive = true
+	query := `
+		INSERT INTO consent_texts (consent_type, version, content, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, effective_at, created_at`
+	err = tx.QueryRow(query, text.ConsentType, text.Version, text.Content, text.IsActive, text.CreatedBy).
+		Scan(&text.ID, &text.EffectiveAt, &text.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create consent text: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *ConsentRepository) GetActiveConsentText(consentType models.ConsentType) (*models.ConsentText, error) {
+	var text models.ConsentText
+	query := `SELECT * FROM consent_texts WHERE consent_type = $1 AND is_active = true`
+
+	err := r.db.Get(&text, query, consentType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("not_found: no active consent text for %s", consentType)
+		}
+		return nil, fmt.Errorf("failed to get active consent text: %w", err)
+	}
+	return &text, nil
+}
+
+func (r *ConsentRepository) GetActiveConsentTexts() ([]*models.ConsentText, error) {
+	var texts []*models.ConsentText
+	query := `SELECT * FROM consent_texts WHERE is_active = true ORDER BY consent_type`
+
+	err := r.db.Select(&texts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active consent texts: %w", err)
+	}
+	return texts, nil
+}
+
+func (r *ConsentRepository) GetConsentTextByVersion(consentType models.ConsentType, version int) (*models.ConsentText, error) {
+	var text models.ConsentText
+	query := `SELECT * FROM consent_texts WHERE consent_type = $1 AND version = $2`
+
+	err := r.db.Get(&text, query, consentType, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("not_found: consent text %s version %d not found", consentType, version)
+		}
+		return nil, fmt.Errorf("failed to get consent text: %w", err)
+	}
+	return &text, nil
+}
+
+// GrantConsent withdraws any previous active consent of the same type and records the new one
+func (r *ConsentRepository) GrantConsent(consent *models.UserConsent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE user_consents
+		SET withdrawn_at = NOW()
+		WHERE user_id = $1 AND consent_type = $2 AND withdrawn_at IS NULL`,
+		consent.UserID, consent.ConsentType)
+	if err != nil {
+		return fmt.Errorf("failed to supersede previous consent: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_consents (user_id, consent_type, consent_text_id, version, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, granted_at`
+	err = tx.QueryRow(query, consent.UserID, consent.ConsentType, consent.ConsentTextID, consent.Version, consent.IPAddress, consent.UserAgent).
+		Scan(&consent.ID, &consent.GrantedAt)
+	if err != nil {
+		return fmt.Errorf("failed to grant consent: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *ConsentRepository) WithdrawConsent(userID string, consentType models.ConsentType) error {
+	query := `
+		UPDATE user_consents
+		SET withdrawn_at = NOW()
+		WHERE user_id = $1 AND consent_type = $2 AND withdrawn_at IS NULL`
+
+	result, err := r.db.Exec(query, userID, consentType)
+	if err != nil {
+		return fmt.Errorf("failed to withdraw consent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("not_found: no active %s consent for user %s", consentType, userID)
+	}
+	return nil
+}
+
+func (r *ConsentRepository) GetActiveUserConsent(userID string, consentType models.ConsentType) (*models.UserConsent, error) {
+	var consent models.UserConsent
+	query := `SELECT * FROM user_consents WHERE user_id = $1 AND consent_type = $2 AND withdrawn_at IS NULL`
+
+	err := r.db.Get(&consent, query, userID, consentType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user consent: %w", err)
+	}
+	return &consent, nil
+}
+
+func (r *ConsentRepository) GetUserConsentHistory(userID string) ([]*models.UserConsent, error) {
+	var consents []*models.UserConsent
+	query := `SELECT * FROM user_consents WHERE user_id = $1 ORDER BY granted_at DESC`
+
+	err := r.db.Select(&consents, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user consent history: %w", err)
+	}
+	return consents, nil
+}
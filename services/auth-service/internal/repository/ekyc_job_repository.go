@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IEkycJobRepository interface {
+	CreateJob(job *models.EkycJob) error
+	GetJob(jobID string) (*models.EkycJob, error)
+	StartJob(jobID string) (*models.EkycJob, error)
+	FinishJob(jobID, status string, errorCode, errorMessage *string) error
+}
+
+type EkycJobRepository struct {
+	db *sqlx.DB
+}
+
+func NewEkycJobRepository(db *sqlx.DB) IEkycJobRepository {
+	return &EkycJobRepository{
+		db: db,
+	}
+}
+
+// CreateJob queues a job. A user with an active job of the same kind gets a conflict error.
+func (r *EkycJobRepository) CreateJob(job *models.EkycJob) error {
+	query := `
+		INSERT INTO ekyc_jobs (id, user_id, kind, status, files)
+		VALUES (:id, :user_id, :kind, :status, :files)
+		RETURNING created_at, updated_at`
+
+	rows, err := r.db.NamedQuery(query, job)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return fmt.Errorf("conflict: an ekyc %s job is already in progress", job.Kind)
+		}
+		return fmt.Errorf("failed to create ekyc job: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&job.CreatedAt, &job.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to create ekyc job: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *EkycJobRepository) GetJob(jobID string) (*models.EkycJob, error) {
+	var job models.EkycJob
+	err := r.db.Get(&job, `SELECT * FROM ekyc_jobs WHERE id = $1`, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("not_found: ekyc job not found")
+		}
+		return nil, fmt.Errorf("failed to get ekyc job: %w", err)
+	}
+	return &job, nil
+}
+
+// StartJob marks a queued job as processing. A job already processing is taken over too, as its
+// worker stopped before finishing it and the message was delivered again.
+func (r *EkycJobRepository) StartJob(jobID string) (*models.EkycJob, error) {
+	var job models.EkycJob
+	query := `
+		UPDATE ekyc_jobs
+		SET status = 'processing', attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND status IN ('queued', 'processing')
+		RETURNING *`
+
+	err := r.db.Get(&job, query, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conflict: ekyc job %s is already finished", jobID)
+		}
+		return nil, fmt.Errorf("failed to start ekyc job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *EkycJobRepository) FinishJob(jobID, status string, errorCode, errorMessage *string) error {
+	query := `
+		UPDATE ekyc_jobs
+		SET status = $2, error_code = $3, error_message = $4, updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.db.Exec(query, jobID, status, errorCode, errorMessage); err != nil {
+		return fmt.Errorf("failed to finish ekyc job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IImpersonationRepository interface {
+	CreateImpersonationSession(session *models.ImpersonationSession) error
+	GetImpersonationSession(id string) (*models.ImpersonationSession, error)
+	EndImpersonationSession(id string, endReason string) (bool, error)
+	MarkUserNotified(id string) error
+	GetExpiredOpenSessions(now time.Time) ([]*models.ImpersonationSession, error)
+	LogImpersonatedAction(auditLog *models.AuditLog) error
+	GetImpersonationAuditLogs(impersonationID string) ([]*models.AuditLog, error)
+}
+
+type ImpersonationRepository struct {
+	db *sqlx.DB
+}
+
+func NewImpersonationRepository(db *sqlx.DB) IImpersonationRepository {
+	return &ImpersonationRepository{
+		db: db,
+	}
+}
+
+func (r *ImpersonationRepository) CreateImpersonationSession(session *models.ImpersonationSession) error {
+	query := `
+		INSERT INTO impersonation_sessions (id, support_user_id, target_user_id, session_id, reason, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING started_at`
+
+	err := r.db.QueryRow(query, session.ID, session.SupportUserID, session.TargetUserID, session.SessionID, session.Reason, session.IPAddress, session.ExpiresAt).
+		Scan(&session.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+	return nil
+}
+
+func (r *ImpersonationRepository) GetImpersonationSession(id string) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	query := `SELECT * FROM impersonation_sessions WHERE id = $1`
+
+	err := r.db.Get(&session, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("not_found: impersonation session not found")
+		}
+		return nil, fmt.Errorf("failed to get impersonation session: %w", err)
+	}
+	return &session, nil
+}
+
+// EndImpersonationSession closes an open session and reports whether this call closed it; it is
+// a no-op for sessions that already ended, so only one of concurrent callers gets true
+func (r *ImpersonationRepository) EndImpersonationSession(id string, endReason string) (bool, error) {
+	query := `
+		UPDATE impersonation_sessions
+		SET ended_at = NOW(), end_reason = $2
+		WHERE id = $1 AND ended_at IS NULL`
+
+	result, err := r.db.Exec(query, id, endReason)
+	if err != nil {
+		return false, fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *ImpersonationRepository) MarkUserNotified(id string) error {
+	_, err := r.db.Exec(`UPDATE impersonation_sessions SET user_notified = true WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark impersonation notified: %w", err)
+	}
+	return nil
+}
+
+func (r *ImpersonationRepository) GetExpiredOpenSessions(now time.Time) ([]*models.ImpersonationSession, error) {
+	var sessions []*models.ImpersonationSession
+	query := `SELECT * FROM impersonation_sessions WHERE ended_at IS NULL AND expires_at <= $1`
+
+	err := r.db.Select(&sessions, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired impersonation sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// LogImpersonatedAction writes the audit entry and bumps the session's action counter
+func (r *ImpersonationRepository) LogImpersonatedAction(auditLog *models.AuditLog) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO audit_logs (user_id, action, resource_type, resource_id, ip_address, success, impersonator_id, impersonation_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, timestamp`
+	err = tx.QueryRow(query, auditLog.UserID, auditLog.Action, auditLog.ResourceType, auditLog.ResourceID, auditLog.IPAddress, auditLog.Success, auditLog.ImpersonatorID, auditLog.ImpersonationID).
+		Scan(&auditLog.ID, &auditLog.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	if auditLog.ImpersonationID != nil {
+		_, err = tx.Exec(`UPDATE impersonation_sessions SET action_count = action_count + 1 WHERE id = $1`, *auditLog.ImpersonationID)
+		if err != nil {
+			return fmt.Errorf("failed to update impersonation action count: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *ImpersonationRepository) GetImpersonationAuditLogs(impersonationID string) ([]*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	query := `SELECT * FROM audit_logs WHERE impersonation_id = $1 ORDER BY timestamp ASC`
+
+	err := r.db.Select(&logs, query, impersonationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impersonation audit logs: %w", err)
+	}
+	return logs, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IMFARepository interface {
+	SavePendingMFA(userID, secretCiphertext string) error
+	GetUserMFA(userID string) (*models.UserMFA, error)
+	EnableMFA(userID string, step int64, recoveryCodeHashes []string) error
+	UseStep(userID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID string, recoveryCodeHashes []string) error
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID string) (int, error)
+	DeleteMFA(userID string) error
+	GetMFARolePolicies() ([]*models.MFARolePolicy, error)
+	SetMFARolePolicy(roleName string, required bool, updatedBy string) (*models.MFARolePolicy, error)
+}
+
+type MFARepository struct {
+	db *sqlx.DB
+}
+
+func NewMFARepository(db *sqlx.DB) IMFARepository {
+	return &MFARepository{
+		db: db,
+	}
+}
+
+// SavePendingMFA stores a new secret awaiting confirmation, replacing a previous pending one.
+// The secret of an enabled second factor is never replaced.
+func (r *MFARepository) SavePendingMFA(userID, secretCiphertext string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret_ciphertext)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_ciphertext = EXCLUDED.secret_ciphertext, last_used_step = 0, updated_at = NOW()
+		WHERE user_mfa.enabled = false`
+
+	result, err := r.db.Exec(query, userID, secretCiphertext)
+	if err != nil {
+		return fmt.Errorf("failed to save mfa secret: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to save mfa secret: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("conflict: mfa is already enabled")
+	}
+	return nil
+}
+
+func (r *MFARepository) GetUserMFA(userID string) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	err := r.db.Get(&mfa, `SELECT * FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("not_found: mfa is not set up")
+		}
+		return nil, fmt.Errorf("failed to get mfa: %w", err)
+	}
+	return &mfa, nil
+}
+
+// EnableMFA confirms the pending secret with the step of the code that confirmed it and stores
+// the first recovery codes
+func (r *MFARepository) EnableMFA(userID string, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE user_mfa
+		SET enabled = true, confirmed_at = NOW(), last_used_step = $2, updated_at = NOW()
+		WHERE user_id = $1 AND enabled = false`,
+		userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return fmt.Errorf("conflict: mfa is already enabled")
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UseStep records that a code of the step was accepted. It returns false when a code of this or
+// a later step was already accepted, so a code cannot be replayed.
+func (r *MFARepository) UseStep(userID string, step int64) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_mfa
+		SET last_used_step = $2, updated_at = NOW()
+		WHERE user_id = $1 AND last_used_step < $2`,
+		userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record mfa step: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record mfa step: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(userID string, recoveryCodeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *sqlx.Tx, userID string, recoveryCodeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range recoveryCodeHashes {
+		_, err := tx.Exec(`INSERT INTO user_mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash)
+		if err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode spends an unused recovery code, reporting whether there was one
+func (r *MFARepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (r *MFARepository) CountUnusedRecoveryCodes(userID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM user_mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func (r *MFARepository) DeleteMFA(userID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *MFARepository) GetMFARolePolicies() ([]*models.MFARolePolicy, error) {
+	var policies []*models.MFARolePolicy
+	err := r.db.Select(&policies, `SELECT * FROM mfa_role_policies ORDER BY role_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa role policies: %w", err)
+	}
+	return policies, nil
+}
+
+func (r *MFARepository) SetMFARolePolicy(roleName string, required bool, updatedBy string) (*models.MFARolePolicy, error) {
+	var policy models.MFARolePolicy
+	query := `
+		INSERT INTO mfa_role_policies (role_name, required, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (role_name) DO UPDATE
+		SET required = EXCLUDED.required, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING *`
+
+	err := r.db.Get(&policy, query, roleName, required, updatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set mfa role policy: %w", err)
+	}
+	return &policy, nil
+}
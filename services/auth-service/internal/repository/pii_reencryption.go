@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"auth-service/internal/pii"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// piiTable describes the encrypted columns of a table for re-encryption
+type piiTable struct {
+	name string
+	// Primary key the table is paged on. Rows whose key is re-encrypted move elsewhere in the key
+	// order, so they may be read again, which leaves them unchanged.
+	key     string
+	columns []string
+	// Column holding the blind index of the national ID, if the table has one
+	hashColumn string
+}
+
+var piiTables = []piiTable{
+	{name: "users", key: "id", columns: []string{"national_id"}, hashColumn: "national_id_hash"},
+	{name: "user_card", key: "national_id", columns: UserCardEncryptedColumns, hashColumn: "national_id_hash"},
+	{name: "user_ekyc_progress", key: "user_id", columns: []string{"cic_no"}},
+}
+
+// PIIReencryptor encrypts the personal data still stored in plaintext, and moves values sealed
+// with an older key to the current one, filling the blind indexes on the way
+type PIIReencryptor struct {
+	db  *sqlx.DB
+	pii *pii.Cipher
+}
+
+func NewPIIReencryptor(db *sqlx.DB, cipher *pii.Cipher) *PIIReencryptor {
+	return &PIIReencryptor{
+		db:  db,
+		pii: cipher,
+	}
+}
+
+// Run processes every table in batches and returns the rows updated per table. With dryRun the
+// rows are only counted.
+func (r *PIIReencryptor) Run(batchSize int, dryRun bool) (map[string]int, error) {
+	updated := map[string]int{}
+	for _, table := range piiTables {
+		count, err := r.reencryptTable(table, batchSize, dryRun)
+		updated[table.name] = count
+		if err != nil {
+			return updated, err
+		}
+		slog.Info("pii re-encryption finished for table", "table", table.name, "rows", count, "dry_run", dryRun)
+	}
+	return updated, nil
+}
+
+func (r *PIIReencryptor) reencryptTable(table piiTable, batchSize int, dryRun bool) (int, error) {
+	selected := append([]string{table.key}, table.columns...)
+	if table.hashColumn != "" {
+		selected = append(selected, table.hashColumn)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s > $1 ORDER BY %s LIMIT $2",
+		strings.Join(selected, ", "), table.name, table.key, table.key)
+
+	updated := 0
+	lastKey := ""
+	for {
+		rows, err := r.db.Query(query, lastKey, batchSize)
+		if err != nil {
+			return updated, fmt.Errorf("failed to read %s: %w", table.name, err)
+		}
+
+		batch := [][]sql.NullString{}
+		for rows.Next() {
+			values := make([]sql.NullString, len(selected))
+			dest := make([]any, len(values))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return updated, fmt.Errorf("failed to read %s: %w", table.name, err)
+			}
+			batch = append(batch, values)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, fmt.Errorf("failed to read %s: %w", table.name, err)
+		}
+		if len(batch) == 0 {
+			return updated, nil
+		}
+
+		for _, values := range batch {
+			changed, err := r.reencryptRow(table, values, dryRun)
+			if err != nil {
+				return updated, err
+			}
+			if changed {
+				updated++
+			}
+		}
+		lastKey = batch[len(batch)-1][0].String
+	}
+}
+
+// reencryptRow updates one row when any of its values is plaintext, sealed with an older key or
+// missing its blind index
+func (r *PIIReencryptor) reencryptRow(table piiTable, values []sql.NullString, dryRun bool) (bool, error) {
+	key := values[0].String
+	stale := false
+	plaintexts := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		value := values[i+1].String
+		if r.pii.NeedsReencryption(value) {
+			stale = true
+		}
+		plaintext, err := r.pii.Decrypt(table.name+"."+column, value)
+		if err != nil {
+			return false, fmt.Errorf("%s %s: %w", table.name, key, err)
+		}
+		plaintexts[i] = plaintext
+	}
+
+	var hash *string
+	if table.hashColumn != "" {
+		// The national ID is the first column of the tables with a blind index
+		hash = r.pii.BlindIndex(plaintexts[0])
+		if stored := values[len(values)-1]; hash != nil && (!stored.Valid || stored.String != *hash) {
+			stale = true
+		}
+	}
+	if !stale || dryRun {
+		return stale, nil
+	}
+
+	setClauses := []string{}
+	args := []any{key}
+	for i, column := range table.columns {
+		encrypted, err := r.pii.Encrypt(table.name+"."+column, plaintexts[i])
+		if err != nil {
+			return false, fmt.Errorf("%s %s: %w", table.name, key, err)
+		}
+		args = append(args, encrypted)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if table.hashColumn != "" {
+		args = append(args, hash)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", table.hashColumn, len(args)))
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $1", table.name, strings.Join(setClauses, ", "), table.key)
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return false, fmt.Errorf("failed to update %s %s: %w", table.name, key, err)
+	}
+	return true, nil
+}
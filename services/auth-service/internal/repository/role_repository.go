@@ -43,6 +43,14 @@ type RoleRepository interface {
 	GetUserPermissions(userID string) ([]*models.Permission, error)
 	UserHasPermission(userID string, resource, action string) (bool, error)
 
+	// Scoped User-Role operations
+	AssignScopedRoleToUser(userID string, roleID int, scopeType, scopeID string, assignedBy *string, expiresAt *time.Time) error
+	RemoveScopedRoleFromUser(userID string, roleID int, scopeType, scopeID string) error
+	GetUserScopedRoles(userID string, activeOnly bool) ([]*models.ScopedUserRole, error)
+	GetScopeUsers(scopeType, scopeID string, activeOnly bool) ([]*models.ScopedUserRole, error)
+	UserHasScopedRole(userID, roleName, scopeType, scopeID string) (bool, error)
+	UserHasScopedPermission(userID, scopeType, scopeID, resource, action string) (bool, error)
+
 	// Role hierarchy operations
 	CreateRoleHierarchy(parentRoleID, childRoleID int) error
 	DeleteRoleHierarchy(parentRoleID, childRoleID int) error
@@ -583,6 +591,150 @@ func (r *roleRepository) UserHasPermission(userID string, resource, action strin
 	return count > 0, nil
 }
 
+// AssignScopedRoleToUser assigns a role to a user within a single scope
+func (r *roleRepository) AssignScopedRoleToUser(userID string, roleID int, scopeType, scopeID string, assignedBy *string, expiresAt *time.Time) error {
+	var expiresAtValue any
+	if expiresAt != nil {
+		expiresAtValue = expiresAt.Unix()
+	}
+	assignedAt := time.Now().Unix()
+
+	query := `
+		INSERT INTO scoped_user_roles (user_id, role_id, scope_type, scope_id, assigned_by, assigned_at, expires_at, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, TRUE)
+		ON CONFLICT (user_id, role_id, scope_type, scope_id) DO UPDATE SET
+			assigned_by = EXCLUDED.assigned_by,
+			assigned_at = EXCLUDED.assigned_at,
+			expires_at = EXCLUDED.expires_at,
+			is_active = true`
+
+	_, err := r.db.Exec(query, userID, roleID, scopeType, scopeID, assignedBy, assignedAt, expiresAtValue)
+	if err != nil {
+		return fmt.Errorf("failed to assign scoped role to user: %w", err)
+	}
+	return nil
+}
+
+// RemoveScopedRoleFromUser removes a scoped role from a user
+func (r *roleRepository) RemoveScopedRoleFromUser(userID string, roleID int, scopeType, scopeID string) error {
+	query := `
+		UPDATE scoped_user_roles SET is_active = false
+		WHERE user_id = $1 AND role_id = $2 AND scope_type = $3 AND scope_id = $4`
+
+	result, err := r.db.Exec(query, userID, roleID, scopeType, scopeID)
+	if err != nil {
+		return fmt.Errorf("failed to remove scoped role from user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("role not assigned to user in scope")
+	}
+
+	return nil
+}
+
+// GetUserScopedRoles retrieves all scoped role assignments of a user
+func (r *roleRepository) GetUserScopedRoles(userID string, activeOnly bool) ([]*models.ScopedUserRole, error) {
+	var roles []*models.ScopedUserRole
+	query := `
+		SELECT sur.id, sur.user_id, sur.role_id, r.name AS role_name, sur.scope_type, sur.scope_id,
+			sur.assigned_by, sur.assigned_at, sur.expires_at, sur.is_active
+		FROM scoped_user_roles sur
+		INNER JOIN roles r ON r.id = sur.role_id
+		WHERE sur.user_id = $1`
+	if activeOnly {
+		query += `
+		AND sur.is_active = true AND r.is_active = true
+		AND (sur.expires_at IS NULL OR sur.expires_at > EXTRACT(EPOCH FROM CURRENT_TIMESTAMP))`
+	}
+	query += ` ORDER BY sur.scope_type, sur.scope_id, r.name`
+
+	err := r.db.Select(&roles, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user scoped roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// GetScopeUsers retrieves all role assignments within a scope
+func (r *roleRepository) GetScopeUsers(scopeType, scopeID string, activeOnly bool) ([]*models.ScopedUserRole, error) {
+	var roles []*models.ScopedUserRole
+	query := `
+		SELECT sur.id, sur.user_id, sur.role_id, r.name AS role_name, sur.scope_type, sur.scope_id,
+			sur.assigned_by, sur.assigned_at, sur.expires_at, sur.is_active
+		FROM scoped_user_roles sur
+		INNER JOIN roles r ON r.id = sur.role_id
+		WHERE sur.scope_type = $1 AND sur.scope_id = $2`
+	if activeOnly {
+		query += `
+		AND sur.is_active = true AND r.is_active = true
+		AND (sur.expires_at IS NULL OR sur.expires_at > EXTRACT(EPOCH FROM CURRENT_TIMESTAMP))`
+	}
+	query += ` ORDER BY sur.assigned_at`
+
+	err := r.db.Select(&roles, query, scopeType, scopeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scope users: %w", err)
+	}
+
+	return roles, nil
+}
+
+// UserHasScopedRole checks if a user holds a named role within a scope
+func (r *roleRepository) UserHasScopedRole(userID, roleName, scopeType, scopeID string) (bool, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM scoped_user_roles sur
+		INNER JOIN roles r ON r.id = sur.role_id
+		WHERE sur.user_id = $1
+		AND r.name = $2
+		AND sur.scope_type = $3
+		AND sur.scope_id = $4
+		AND sur.is_active = true
+		AND r.is_active = true
+		AND (sur.expires_at IS NULL OR sur.expires_at > EXTRACT(EPOCH FROM CURRENT_TIMESTAMP))`
+
+	err := r.db.Get(&count, query, userID, roleName, scopeType, scopeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user scoped role: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// UserHasScopedPermission checks if a user has a permission through a role held within a scope
+func (r *roleRepository) UserHasScopedPermission(userID, scopeType, scopeID, resource, action string) (bool, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM permissions p
+		INNER JOIN role_permissions rp ON p.id = rp.permission_id
+		INNER JOIN scoped_user_roles sur ON rp.role_id = sur.role_id
+		INNER JOIN roles r ON sur.role_id = r.id
+		WHERE sur.user_id = $1
+		AND sur.scope_type = $2
+		AND sur.scope_id = $3
+		AND p.resource = $4
+		AND p.action = $5
+		AND sur.is_active = true
+		AND r.is_active = true
+		AND (sur.expires_at IS NULL OR sur.expires_at > EXTRACT(EPOCH FROM CURRENT_TIMESTAMP))`
+
+	err := r.db.Get(&count, query, userID, scopeType, scopeID, resource, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user scoped permission: %w", err)
+	}
+
+	return count > 0, nil
+}
+
 // CreateRoleHierarchy creates a parent-child relationship between roles
 func (r *roleRepository) CreateRoleHierarchy(parentRoleID, childRoleID int) error {
 	if parentRoleID == childRoleID {
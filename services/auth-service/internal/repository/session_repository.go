@@ -23,8 +23,22 @@ type SessionRepository interface {
 	RenewSession(ctx context.Context, sessionID string) error
 	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
 	GetUserSessions(ctx context.Context, userID string) ([]*models.UserSession, error)
+
+	// Devices the user has logged in from
+	AddKnownDevice(ctx context.Context, userID, deviceKey string) (bool, error)
+
+	// Trusted devices
+	SaveTrustedDevice(ctx context.Context, device *models.TrustedDevice) error
+	GetTrustedDevice(ctx context.Context, userID, deviceID string) (*models.TrustedDevice, error)
+	GetTrustedDeviceByFingerprint(ctx context.Context, userID, fingerprintHash string) (*models.TrustedDevice, error)
+	GetUserTrustedDevices(ctx context.Context, userID string) ([]*models.TrustedDevice, error)
+	DeleteTrustedDevice(ctx context.Context, userID, deviceID string) error
+	DeleteUserTrustedDevices(ctx context.Context, userID string) error
 }
 
+// knownDeviceTTL is how long a device is remembered after the last login from it
+const knownDeviceTTL = 180 * 24 * time.Hour
+
 // sessionRepository implements SessionRepository interface
 type sessionRepository struct {
 	client     *redis.Client
@@ -49,6 +63,7 @@ func (r *sessionRepository) CreateSession(ctx context.Context, session *models.U
 
 	// Set session expiration time
 	session.ExpiresAt = time.Now().Add(r.expiration)
+	session.LastActiveAt = time.Now()
 	session.IsActive = true
 
 	// Serialize session using gob
@@ -191,6 +206,7 @@ func (r *sessionRepository) RenewSession(ctx context.Context, sessionID string)
 
 	// Update expiration time
 	session.ExpiresAt = time.Now().Add(r.expiration)
+	session.LastActiveAt = time.Now()
 
 	// Serialize updated session using gob
 	var buf bytes.Buffer
@@ -257,6 +273,178 @@ func (r *sessionRepository) GetUserSessions(ctx context.Context, userID string)
 	return sessions, nil
 }
 
+// AddKnownDevice remembers a device the user logged in from. It reports whether the device is new
+// to an account that already had devices on record; the first device of an account is not new.
+func (r *sessionRepository) AddKnownDevice(ctx context.Context, userID, deviceKey string) (bool, error) {
+	if userID == "" || deviceKey == "" {
+		return false, fmt.Errorf("user ID and device key cannot be empty")
+	}
+
+	key := r.getUserKnownDevicesKey(userID)
+	pipe := r.client.Pipeline()
+	known := pipe.SCard(ctx, key)
+	added := pipe.SAdd(ctx, key, deviceKey)
+	pipe.Expire(ctx, key, knownDeviceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to record known device: %w", err)
+	}
+
+	return known.Val() > 0 && added.Val() > 0, nil
+}
+
+// SaveTrustedDevice stores or refreshes a trusted device, keyed by its ID and indexed by fingerprint
+func (r *sessionRepository) SaveTrustedDevice(ctx context.Context, device *models.TrustedDevice) error {
+	if device.ID == "" {
+		return fmt.Errorf("device ID cannot be empty")
+	}
+	if device.UserID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	ttl := time.Until(device.ExpiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("trusted device already expired")
+	}
+
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	if err := encoder.Encode(device); err != nil {
+		return fmt.Errorf("failed to encode trusted device: %w", err)
+	}
+
+	userDevicesKey := r.getUserTrustedDevicesKey(device.UserID)
+
+	pipe := r.client.Pipeline()
+	pipe.Set(ctx, r.getTrustedDeviceKey(device.UserID, device.ID), buf.Bytes(), ttl)
+	pipe.HSet(ctx, userDevicesKey, device.FingerprintHash, device.ID)
+	pipe.Expire(ctx, userDevicesKey, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store trusted device: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrustedDevice retrieves a trusted device by ID
+func (r *sessionRepository) GetTrustedDevice(ctx context.Context, userID, deviceID string) (*models.TrustedDevice, error) {
+	if userID == "" || deviceID == "" {
+		return nil, fmt.Errorf("user ID and device ID cannot be empty")
+	}
+
+	data, err := r.client.Get(ctx, r.getTrustedDeviceKey(userID, deviceID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("trusted device not found")
+		}
+		return nil, fmt.Errorf("failed to get trusted device: %w", err)
+	}
+
+	buf := bytes.NewBuffer([]byte(data))
+	decoder := gob.NewDecoder(buf)
+	var device models.TrustedDevice
+	if err := decoder.Decode(&device); err != nil {
+		return nil, fmt.Errorf("failed to decode trusted device: %w", err)
+	}
+
+	return &device, nil
+}
+
+// GetTrustedDeviceByFingerprint looks up a trusted device through the fingerprint index
+func (r *sessionRepository) GetTrustedDeviceByFingerprint(ctx context.Context, userID, fingerprintHash string) (*models.TrustedDevice, error) {
+	if userID == "" || fingerprintHash == "" {
+		return nil, fmt.Errorf("user ID and fingerprint cannot be empty")
+	}
+
+	deviceID, err := r.client.HGet(ctx, r.getUserTrustedDevicesKey(userID), fingerprintHash).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("trusted device not found")
+		}
+		return nil, fmt.Errorf("failed to get trusted device index: %w", err)
+	}
+
+	device, err := r.GetTrustedDevice(ctx, userID, deviceID)
+	if err != nil {
+		// Device entry expired, clean up the stale index entry
+		r.client.HDel(ctx, r.getUserTrustedDevicesKey(userID), fingerprintHash)
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// GetUserTrustedDevices retrieves all trusted devices for a user
+func (r *sessionRepository) GetUserTrustedDevices(ctx context.Context, userID string) ([]*models.TrustedDevice, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	index, err := r.client.HGetAll(ctx, r.getUserTrustedDevicesKey(userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return []*models.TrustedDevice{}, nil
+		}
+		return nil, fmt.Errorf("failed to get user trusted devices: %w", err)
+	}
+
+	devices := make([]*models.TrustedDevice, 0, len(index))
+	for fingerprintHash, deviceID := range index {
+		device, err := r.GetTrustedDevice(ctx, userID, deviceID)
+		if err != nil {
+			// Device trust expired, drop it from the index
+			r.client.HDel(ctx, r.getUserTrustedDevicesKey(userID), fingerprintHash)
+			continue
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// DeleteTrustedDevice revokes a single trusted device
+func (r *sessionRepository) DeleteTrustedDevice(ctx context.Context, userID, deviceID string) error {
+	device, err := r.GetTrustedDevice(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.Del(ctx, r.getTrustedDeviceKey(userID, deviceID))
+	pipe.HDel(ctx, r.getUserTrustedDevicesKey(userID), device.FingerprintHash)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete trusted device: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUserTrustedDevices revokes every trusted device of a user
+func (r *sessionRepository) DeleteUserTrustedDevices(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	userDevicesKey := r.getUserTrustedDevicesKey(userID)
+	deviceIDs, err := r.client.HVals(ctx, userDevicesKey).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get user trusted devices: %w", err)
+	}
+
+	pipe := r.client.Pipeline()
+	for _, deviceID := range deviceIDs {
+		pipe.Del(ctx, r.getTrustedDeviceKey(userID, deviceID))
+	}
+	pipe.Del(ctx, userDevicesKey)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete user trusted devices: %w", err)
+	}
+
+	return nil
+}
+
 // Helper methods
 
 // getSessionKey generates Redis key for session
@@ -268,3 +456,18 @@ func (r *sessionRepository) getSessionKey(sessionID string) string {
 func (r *sessionRepository) getUserSessionsKey(userID string) string {
 	return fmt.Sprintf("user_sessions:%s", userID)
 }
+
+// getUserKnownDevicesKey generates Redis key for the set of devices the user logged in from
+func (r *sessionRepository) getUserKnownDevicesKey(userID string) string {
+	return fmt.Sprintf("user_known_devices:%s", userID)
+}
+
+// getTrustedDeviceKey generates Redis key for a trusted device
+func (r *sessionRepository) getTrustedDeviceKey(userID, deviceID string) string {
+	return fmt.Sprintf("trusted_device:%s:%s", userID, deviceID)
+}
+
+// getUserTrustedDevicesKey generates Redis key for the user's fingerprint -> device ID index
+func (r *sessionRepository) getUserTrustedDevicesKey(userID string) string {
+	return fmt.Sprintf("user_trusted_devices:%s", userID)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"auth-service/internal/models"
+	"auth-service/internal/pii"
 	"fmt"
 	"log"
 	"strings"
@@ -13,20 +14,69 @@ type IUserCardRepository interface {
 	CreateUserCard(userCard *models.UserCard) (*models.UserCard, error)
 	GetUserCardByUserID(userID string) (*models.UserCard, error)
 	UpdateUserCardByUserID(userID string, req models.UpdateUserCardRequest) error
+	UpdateNormalizedAddress(userID string, userCard *models.UserCard) error
 }
 
 type UserCardRepository struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	pii *pii.Cipher
 }
 
-func NewUserCardRepository(db *sqlx.DB) IUserCardRepository {
+func NewUserCardRepository(db *sqlx.DB, cipher *pii.Cipher) IUserCardRepository {
 	return &UserCardRepository{
-		db: db,
+		db:  db,
+		pii: cipher,
 	}
 }
+
+// UserCardEncryptedColumns are the user_card columns stored encrypted
+var UserCardEncryptedColumns = []string{"national_id", "home", "address", "address_street", "mrz"}
+
+// cardFields points at the fields of the encrypted columns of a card
+func cardFields(userCard *models.UserCard) map[string]*string {
+	return map[string]*string{
+		"national_id":    &userCard.NationalID,
+		"home":           &userCard.Home,
+		"address":        &userCard.Address,
+		"address_street": &userCard.AddressStreet,
+		"mrz":            &userCard.Mrz,
+	}
+}
+
+// sealCard returns a copy of the card with its personal data encrypted, as stored
+func (u *UserCardRepository) sealCard(userCard *models.UserCard) (*models.UserCard, error) {
+	sealed := *userCard
+	for column, field := range cardFields(&sealed) {
+		value, err := u.pii.Encrypt("user_card."+column, *field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %w", column, err)
+		}
+		*field = value
+	}
+	sealed.NationalIDHash = u.pii.BlindIndex(userCard.NationalID)
+	return &sealed, nil
+}
+
+func (u *UserCardRepository) openCard(userCard *models.UserCard) error {
+	for column, field := range cardFields(userCard) {
+		value, err := u.pii.Decrypt("user_card."+column, *field)
+		if err != nil {
+			return fmt.Errorf("failed to read user card: %w", err)
+		}
+		*field = value
+	}
+	return nil
+}
+
 func (u *UserCardRepository) CreateUserCard(userCard *models.UserCard) (*models.UserCard, error) {
-	_, err := u.db.NamedExec(`INSERT INTO user_card (national_id, name, dob, sex, nationality, home, address, doe, number_of_name_lines, features, issue_date, mrz, issue_loc, image_front, image_back, user_id)
-		VALUES (:national_id, :name, :dob, :sex, :nationality, :home, :address, :doe, :number_of_name_lines, :features, :issue_date, :mrz, :issue_loc, :image_front, :image_back, :user_id)`, userCard)
+	sealed, err := u.sealCard(userCard)
+	if err != nil {
+		return nil, err
+	}
+	_, err = u.db.NamedExec(`INSERT INTO user_card (national_id, national_id_hash, name, dob, sex, nationality, home, address, doe, number_of_name_lines, features, issue_date, mrz, issue_loc, image_front, image_back, user_id,
+		address_street, address_commune_code, address_commune_name, address_province_code, address_province_name, address_legacy_district, address_legacy_province, address_match_level)
+		VALUES (:national_id, :national_id_hash, :name, :dob, :sex, :nationality, :home, :address, :doe, :number_of_name_lines, :features, :issue_date, :mrz, :issue_loc, :image_front, :image_back, :user_id,
+		:address_street, :address_commune_code, :address_commune_name, :address_province_code, :address_province_name, :address_legacy_district, :address_legacy_province, :address_match_level)`, sealed)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +89,9 @@ func (u *UserCardRepository) GetUserCardByUserID(userID string) (*models.UserCar
 	if err != nil {
 		return nil, err
 	}
+	if err := u.openCard(&userCard); err != nil {
+		return nil, err
+	}
 	return &userCard, nil
 }
 
@@ -96,6 +149,21 @@ func (u *UserCardRepository) UpdateUserCardByUserID(userID string, req models.Up
 		return fmt.Errorf("no fields to update")
 	}
 
+	for _, column := range UserCardEncryptedColumns {
+		value, ok := updates[column].(string)
+		if !ok {
+			continue
+		}
+		encrypted, err := u.pii.Encrypt("user_card."+column, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", column, err)
+		}
+		updates[column] = encrypted
+		if column == "national_id" {
+			updates["national_id_hash"] = u.pii.BlindIndex(value)
+		}
+	}
+
 	setClauses := make([]string, 0, len(updates))
 	args := make(map[string]any)
 
@@ -130,3 +198,29 @@ func (u *UserCardRepository) UpdateUserCardByUserID(userID string, req models.Up
 
 	return nil
 }
+
+// UpdateNormalizedAddress stores the normalized form of the card address
+func (u *UserCardRepository) UpdateNormalizedAddress(userID string, userCard *models.UserCard) error {
+	street, err := u.pii.Encrypt("user_card.address_street", userCard.AddressStreet)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt address_street: %w", err)
+	}
+	_, err = u.db.Exec(`
+		UPDATE user_card
+		SET address_street = $2,
+			address_commune_code = $3,
+			address_commune_name = $4,
+			address_province_code = $5,
+			address_province_name = $6,
+			address_legacy_district = $7,
+			address_legacy_province = $8,
+			address_match_level = $9
+		WHERE user_id = $1`,
+		userID, street, userCard.AddressCommuneCode, userCard.AddressCommuneName,
+		userCard.AddressProvinceCode, userCard.AddressProvinceName, userCard.AddressLegacyDistrict,
+		userCard.AddressLegacyProvince, userCard.AddressMatchLevel)
+	if err != nil {
+		return fmt.Errorf("failed to update normalized address: %w", err)
+	}
+	return nil
+}
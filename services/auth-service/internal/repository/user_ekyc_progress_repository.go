@@ -2,6 +2,7 @@ package repository
 
 import (
 	"auth-service/internal/models"
+	"auth-service/internal/pii"
 	"database/sql"
 	"fmt"
 
@@ -14,16 +15,22 @@ type IUserEkycProgressRepository interface {
 	UpdateOCRDone(userID string, ocrDone bool, nationalID string) error
 	GetUserEkycProgressByUserID(userID string) (*models.UserEkycProgress, error)
 	UpdateFaceLivenessDone(userID string, isFaceLivenessDone bool) error
+	UpdateNFCVerified(userID string, nationalID string) error
 	CreateUserEkycProgress(progress *models.UserEkycProgress) error
+	TransitionState(userID, from, to string) error
+	CreateStepEvent(event *models.EkycStepEvent) error
+	GetStepEvents(userID string, limit int) ([]*models.EkycStepEvent, error)
 }
 
 type UserEkycProgressRepository struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	pii *pii.Cipher
 }
 
-func NewUserEkycProgressRepository(db *sqlx.DB) IUserEkycProgressRepository {
+func NewUserEkycProgressRepository(db *sqlx.DB, cipher *pii.Cipher) IUserEkycProgressRepository {
 	return &UserEkycProgressRepository{
-		db: db,
+		db:  db,
+		pii: cipher,
 	}
 }
 
@@ -37,6 +44,9 @@ func (r *UserEkycProgressRepository) GetUserEkycProgressByUserID(userID string)
 		}
 		return nil, fmt.Errorf("failed to get user ekyc progress by user ID: %w", err)
 	}
+	if progress.CicNo, err = r.pii.Decrypt("user_ekyc_progress.cic_no", progress.CicNo); err != nil {
+		return nil, fmt.Errorf("failed to read user ekyc progress: %w", err)
+	}
 	return &progress, nil
 }
 
@@ -49,7 +59,11 @@ func (u *UserEkycProgressRepository) UpdateOCRDone(userID string, isOcrDone bool
 		WHERE user_id = $2
 	`
 
-	result, err := u.db.Exec(query, isOcrDone, userID, nationalID)
+	cicNo, err := u.pii.Encrypt("user_ekyc_progress.cic_no", nationalID)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cic_no: %w", err)
+	}
+	result, err := u.db.Exec(query, isOcrDone, userID, cicNo)
 	if err != nil {
 		return fmt.Errorf("failed to update ocr_done: %w", err)
 	}
@@ -89,6 +103,35 @@ func (u *UserEkycProgressRepository) UpdateFaceLivenessDone(userID string, isFac
 	return nil
 }
 
+func (u *UserEkycProgressRepository) UpdateNFCVerified(userID string, nationalID string) error {
+	query := `
+		UPDATE user_ekyc_progress
+		SET is_nfc_verified = true,
+		    nfc_verified_at = NOW(),
+			cic_no = $2
+		WHERE user_id = $1
+	`
+
+	cicNo, err := u.pii.Encrypt("user_ekyc_progress.cic_no", nationalID)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cic_no: %w", err)
+	}
+	result, err := u.db.Exec(query, userID, cicNo)
+	if err != nil {
+		return fmt.Errorf("failed to update nfc_verified: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no rows updated for user_id: %s", userID)
+	}
+	return nil
+}
+
 func (u *UserEkycProgressRepository) CreateUserEkycProgress(progress *models.UserEkycProgress) error {
 	query := `
 		INSERT INTO user_ekyc_progress (
@@ -100,9 +143,13 @@ func (u *UserEkycProgressRepository) CreateUserEkycProgress(progress *models.Use
 			face_verified_at
 		) VALUES ($1, $2, $3, $4, $5, $6)
 	`
+	cicNo, err := u.pii.Encrypt("user_ekyc_progress.cic_no", progress.CicNo)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cic_no: %w", err)
+	}
 	return agrisa_utils.ExecWithCheck(u.db, query, agrisa_utils.ExecInsert,
 		progress.UserID,
-		progress.CicNo,
+		cicNo,
 		progress.IsOcrDone,
 		progress.OcrDoneAt,
 		progress.IsFaceVerified,
@@ -110,3 +157,50 @@ func (u *UserEkycProgressRepository) CreateUserEkycProgress(progress *models.Use
 	)
 
 }
+
+// TransitionState moves the user to another eKYC state only while they are still in the expected
+// one, so two concurrent attempts cannot both complete a step
+func (u *UserEkycProgressRepository) TransitionState(userID, from, to string) error {
+	query := `
+		UPDATE user_ekyc_progress
+		SET state = $3,
+		    state_updated_at = NOW()
+		WHERE user_id = $1 AND state = $2
+	`
+
+	result, err := u.db.Exec(query, userID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to update ekyc state: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("conflict: ekyc state of user %s is no longer %s", userID, from)
+	}
+	return nil
+}
+
+func (u *UserEkycProgressRepository) CreateStepEvent(event *models.EkycStepEvent) error {
+	query := `
+		INSERT INTO ekyc_step_events (user_id, step, outcome, state, next_state, error_code, error_message)
+		VALUES (:user_id, :step, :outcome, :state, :next_state, :error_code, :error_message)
+	`
+	if _, err := u.db.NamedExec(query, event); err != nil {
+		return fmt.Errorf("failed to create ekyc step event: %w", err)
+	}
+	return nil
+}
+
+// GetStepEvents returns the latest step events of a user, newest first
+func (u *UserEkycProgressRepository) GetStepEvents(userID string, limit int) ([]*models.EkycStepEvent, error) {
+	events := []*models.EkycStepEvent{}
+	query := `SELECT * FROM ekyc_step_events WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+	if err := u.db.Select(&events, query, userID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get ekyc step events: %w", err)
+	}
+	return events, nil
+}
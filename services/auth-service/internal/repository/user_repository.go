@@ -2,9 +2,11 @@ package repository
 
 import (
 	"auth-service/internal/models"
+	"auth-service/internal/pii"
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -18,6 +20,7 @@ type IUserRepository interface {
 	GetUserByPhone(phone string) (*models.User, error)
 	GetAllUsers(limit, offset int) ([]*models.User, error)
 	GetUsersByStatus(status string) ([]*models.User, error)
+	SearchUsers(filter models.AdminUserFilter) ([]*models.AdminUserView, int, error)
 	UpdateUser(user *models.User) error
 	UpdatePassword(userID, newPassword string) error
 	VerifyEmail(userID string) error
@@ -31,18 +34,43 @@ type IUserRepository interface {
 	UpdateUserStatus(userID string, status models.UserStatus, lockedUntil *int64) error
 	CheckExistEmailOrPhone(value string) (bool, error)
 	ResetEkycData(userID string) error
+	DeactivateUser(userID string, reason *string) error
+	ReactivateUser(userID string, status models.UserStatus) error
 }
 
 type UserRepository struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	pii *pii.Cipher
 }
 
-func NewUserRepository(db *sqlx.DB) IUserRepository {
+func NewUserRepository(db *sqlx.DB, cipher *pii.Cipher) IUserRepository {
 	return &UserRepository{
-		db: db,
+		db:  db,
+		pii: cipher,
 	}
 }
 
+// sealUser returns a copy of the user with the national ID encrypted and its hash set, as stored
+func (r *UserRepository) sealUser(user *models.User) (*models.User, error) {
+	sealed := *user
+	nationalID, err := r.pii.Encrypt("users.national_id", user.NationalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt national_id: %w", err)
+	}
+	sealed.NationalID = nationalID
+	sealed.NationalIDHash = r.pii.BlindIndex(user.NationalID)
+	return &sealed, nil
+}
+
+func (r *UserRepository) openUser(user *models.User) error {
+	nationalID, err := r.pii.Decrypt("users.national_id", user.NationalID)
+	if err != nil {
+		return fmt.Errorf("failed to read user %s: %w", user.ID, err)
+	}
+	user.NationalID = nationalID
+	return nil
+}
+
 func (u *UserRepository) CreateUser(user *models.User) error {
 	hashedPassword, err := u.hashPassword(user.PasswordHash)
 	if err != nil {
@@ -50,9 +78,9 @@ func (u *UserRepository) CreateUser(user *models.User) error {
 	}
 
 	query := `
-		INSERT INTO users (id, phone_number, email, password_hash, national_id, status, 
+		INSERT INTO users (id, phone_number, email, password_hash, national_id, national_id_hash, status, 
 		                  email_verified, phone_verified, kyc_verified, created_at, updated_at, locked_until, face_liveness)
-		VALUES (:id, :phone_number, :email, :password_hash, :national_id, :status,
+		VALUES (:id, :phone_number, :email, :password_hash, :national_id, :national_id_hash, :status,
 		        :email_verified, :phone_verified, :kyc_verified, :created_at, :updated_at, :locked_until, :face_liveness)
 	`
 
@@ -60,7 +88,11 @@ func (u *UserRepository) CreateUser(user *models.User) error {
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	_, err = u.db.NamedExec(query, user)
+	sealed, err := u.sealUser(user)
+	if err != nil {
+		return err
+	}
+	_, err = u.db.NamedExec(query, sealed)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -80,6 +112,10 @@ func (r *UserRepository) GetUserByID(id string) (*models.User, error) {
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
 
+	if err := r.openUser(&user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -95,6 +131,10 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
+	if err := r.openUser(&user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -110,6 +150,10 @@ func (r *UserRepository) GetUserByPhone(phone string) (*models.User, error) {
 		return nil, fmt.Errorf("failed to get user by phone: %w", err)
 	}
 
+	if err := r.openUser(&user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -122,6 +166,12 @@ func (r *UserRepository) GetAllUsers(limit, offset int) ([]*models.User, error)
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
 
+	for _, user := range users {
+		if err := r.openUser(user); err != nil {
+			return nil, err
+		}
+	}
+
 	return users, nil
 }
 
@@ -134,22 +184,77 @@ func (r *UserRepository) GetUsersByStatus(status string) ([]*models.User, error)
 		return nil, fmt.Errorf("failed to get users by status: %w", err)
 	}
 
+	for _, user := range users {
+		if err := r.openUser(user); err != nil {
+			return nil, err
+		}
+	}
+
 	return users, nil
 }
 
+// SearchUsers returns a page of the users matching the filter, newest first, and the number of
+// users matching it
+func (r *UserRepository) SearchUsers(filter models.AdminUserFilter) ([]*models.AdminUserView, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []any{}
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Phone != "" {
+		addCondition("u.phone_number LIKE $%d || '%%'", filter.Phone)
+	}
+	if filter.Email != "" {
+		addCondition("u.email ILIKE '%%' || $%d || '%%'", filter.Email)
+	}
+	if filter.Status != "" {
+		addCondition("u.status = $%d", filter.Status)
+	}
+	if filter.EkycStatus != "" {
+		addCondition("COALESCE(p.state, 'ocr_pending') = $%d", filter.EkycStatus)
+	}
+
+	from := `FROM users u LEFT JOIN user_ekyc_progress p ON p.user_id = u.id WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) `+from, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT u.*, p.state AS ekyc_state %s ORDER BY u.created_at DESC LIMIT $%d OFFSET $%d`, from, len(args)-1, len(args))
+
+	var users []*models.AdminUserView
+	if err := r.db.Select(&users, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	for _, user := range users {
+		if err := r.openUser(&user.User); err != nil {
+			return nil, 0, err
+		}
+	}
+	return users, total, nil
+}
+
 func (r *UserRepository) UpdateUser(user *models.User) error {
 	user.UpdatedAt = time.Now()
 
 	query := `
 		UPDATE users 
-		SET phone_number = :phone_number, email = :email, national_id = :national_id,
+		SET phone_number = :phone_number, email = :email, national_id = :national_id, national_id_hash = :national_id_hash,
 		    status = :status, email_verified = :email_verified, phone_verified = :phone_verified,
 		    kyc_verified = :kyc_verified, updated_at = :updated_at, last_login = :last_login,
 		    login_attempts = :login_attempts, locked_until = :locked_until
 		WHERE id = :id
 	`
 
-	result, err := r.db.NamedExec(query, user)
+	sealed, err := r.sealUser(user)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.NamedExec(query, sealed)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -285,10 +390,15 @@ func (r *UserRepository) UpdateUserNationalID(userID string, nationalID string)
 	query := `
 		UPDATE users
 		SET national_id = $1,
-		    updated_at = $2
-		WHERE id = $3
+		    national_id_hash = $2,
+		    updated_at = $3
+		WHERE id = $4
 	`
-	result, err := r.db.Exec(query, nationalID, time.Now(), userID)
+	encrypted, err := r.pii.Encrypt("users.national_id", nationalID)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt national_id: %w", err)
+	}
+	result, err := r.db.Exec(query, encrypted, r.pii.BlindIndex(nationalID), time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to update national_id for user %s: %w", userID, err)
 	}
@@ -437,7 +547,11 @@ func (r *UserRepository) ResetEkycData(userID string) error {
             ocr_done_at = NULL,
             is_face_verified = false,
             face_verified_at = NULL,
-            cic_no = ''
+            is_nfc_verified = false,
+            nfc_verified_at = NULL,
+            cic_no = '',
+            state = 'ocr_pending',
+            state_updated_at = NOW()
         WHERE user_id = $1
     `
 	result, err = tx.Exec(updateEkycProgressQuery, userID)
@@ -476,3 +590,59 @@ func (r *UserRepository) ResetEkycData(userID string) error {
 
 	return nil
 }
+
+// DeactivateUser marks a user as voluntarily deactivated. Related records
+// (sessions aside) are kept untouched so policy history is preserved.
+func (r *UserRepository) DeactivateUser(userID string, reason *string) error {
+	query := `
+		UPDATE users
+		SET status = $1,
+		    deactivated_at = NOW(),
+		    deactivation_reason = $2,
+		    updated_at = NOW()
+		WHERE id = $3 AND status != $1
+	`
+	result, err := r.db.Exec(query, models.UserStatusDeactivated, reason, userID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate user %s: %w", userID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no active user found with id %s", userID)
+	}
+
+	return nil
+}
+
+// ReactivateUser restores a deactivated user to the given status and marks the
+// phone number as re-verified.
+func (r *UserRepository) ReactivateUser(userID string, status models.UserStatus) error {
+	query := `
+		UPDATE users
+		SET status = $1,
+		    deactivated_at = NULL,
+		    deactivation_reason = NULL,
+		    phone_verified = true,
+		    login_attempts = 0,
+		    updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`
+	result, err := r.db.Exec(query, status, userID, models.UserStatusDeactivated)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate user %s: %w", userID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no deactivated user found with id %s", userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+// AdminService is the user management surface for global admins. Every call, allowed or not, is
+// written to the audit log against the admin making it.
+type AdminService struct {
+	userRepo       repository.IUserRepository
+	auditRepo      repository.IAuditRepository
+	roleService    *RoleService
+	sessionService *SessionService
+}
+
+func NewAdminService(userRepo repository.IUserRepository, auditRepo repository.IAuditRepository, roleService *RoleService, sessionService *SessionService) *AdminService {
+	return &AdminService{
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		roleService:    roleService,
+		sessionService: sessionService,
+	}
+}
+
+// SearchUsers lists users by phone, email, account status or eKYC state
+func (s *AdminService) SearchUsers(actorID string, ipAddress *string, filter models.AdminUserFilter) (result *models.AdminUserSearchResponse, err error) {
+	filter.Limit, filter.Offset = adminPage(filter.Limit, filter.Offset)
+	defer func() {
+		s.audit(actorID, models.AuditActionAdminSearchUsers, "", ipAddress, models.AuditDetails{"filter": filter}, err)
+	}()
+
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	if filter.EkycStatus != "" && !slices.Contains(models.EkycStates, filter.EkycStatus) {
+		return nil, fmt.Errorf("bad_request: unknown ekyc status %q", filter.EkycStatus)
+	}
+
+	users, total, err := s.userRepo.SearchUsers(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AdminUserSearchResponse{
+		Users:  users,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// GetLoginHistory lists the logins of a user, failed ones included, newest first
+func (s *AdminService) GetLoginHistory(actorID, userID string, ipAddress *string, limit, offset int) (logs []*models.AuditLog, err error) {
+	limit, offset = adminPage(limit, offset)
+	defer func() {
+		s.audit(actorID, models.AuditActionAdminViewLoginHistory, userID, ipAddress, nil, err)
+	}()
+
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, fmt.Errorf("not_found: user not found")
+	}
+
+	return s.auditRepo.GetAuditLogs(models.AuditLogFilter{
+		UserID:  userID,
+		Actions: []string{models.AuditActionLogin, models.AuditActionLoginFailed},
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// ForceLogout ends every session of a user
+func (s *AdminService) ForceLogout(ctx context.Context, actorID, userID string, ipAddress *string) (err error) {
+	defer func() {
+		s.audit(actorID, models.AuditActionAdminForceLogout, userID, ipAddress, nil, err)
+	}()
+
+	if err := s.requireAdmin(actorID); err != nil {
+		return err
+	}
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return fmt.Errorf("not_found: user not found")
+	}
+	if err := s.sessionService.InvalidateUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to invalidate sessions: %w", err)
+	}
+	return nil
+}
+
+// SetUserRoles replaces the global roles of a user. Roles are embedded in the tokens, so the
+// sessions of the user are ended when they change and the new roles apply from the next login.
+func (s *AdminService) SetUserRoles(ctx context.Context, actorID, userID string, ipAddress *string, roleNames []string) (result *models.SetUserRolesResponse, err error) {
+	details := models.AuditDetails{"roles": roleNames}
+	defer func() {
+		if result != nil {
+			details["added"], details["removed"] = result.Added, result.Removed
+		}
+		s.audit(actorID, models.AuditActionAdminChangeRoles, userID, ipAddress, details, err)
+	}()
+
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, fmt.Errorf("not_found: user not found")
+	}
+	if actorID == userID && !slices.Contains(roleNames, models.AdminRoleName) {
+		return nil, fmt.Errorf("bad_request: admins cannot remove their own admin role")
+	}
+
+	wanted := map[string]*models.Role{}
+	for _, name := range roleNames {
+		role, err := s.roleService.GetRoleByName(name)
+		if err != nil || role == nil {
+			return nil, fmt.Errorf("bad_request: unknown role %q", name)
+		}
+		wanted[name] = role
+	}
+
+	current, err := s.roleService.GetUserRoles(userID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	result = &models.SetUserRolesResponse{Roles: roleNames, Added: []string{}, Removed: []string{}}
+	for _, role := range current {
+		if _, keep := wanted[role.Name]; keep {
+			delete(wanted, role.Name)
+			continue
+		}
+		if err := s.roleService.RemoveRoleFromUser(userID, role.ID); err != nil {
+			return result, fmt.Errorf("failed to remove role %s: %w", role.Name, err)
+		}
+		result.Removed = append(result.Removed, role.Name)
+	}
+	for name, role := range wanted {
+		if err := s.roleService.AssignRoleToUser(userID, role.ID, &actorID, nil); err != nil {
+			return result, fmt.Errorf("failed to assign role %s: %w", name, err)
+		}
+		result.Added = append(result.Added, name)
+	}
+
+	if len(result.Added) > 0 || len(result.Removed) > 0 {
+		if err := s.sessionService.InvalidateUserSessions(ctx, userID); err != nil {
+			slog.Error("failed to end sessions after role change", "user_id", userID, "error", err)
+		}
+	}
+	return result, nil
+}
+
+// GetAuditLogs lists the audit log, optionally only the entries of an actor or about a user
+func (s *AdminService) GetAuditLogs(actorID string, ipAddress *string, filter models.AuditLogFilter) (logs []*models.AuditLog, err error) {
+	filter.Limit, filter.Offset = adminPage(filter.Limit, filter.Offset)
+	defer func() {
+		s.audit(actorID, models.AuditActionAdminViewAuditLogs, filter.ResourceID, ipAddress, nil, err)
+	}()
+
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	return s.auditRepo.GetAuditLogs(filter)
+}
+
+func (s *AdminService) requireAdmin(actorID string) error {
+	isAdmin, err := s.roleService.isGlobalAdmin(actorID)
+	if err != nil {
+		return fmt.Errorf("failed to check admin role: %w", err)
+	}
+	if !isAdmin {
+		return fmt.Errorf("forbidden: only admins can manage users")
+	}
+	return nil
+}
+
+// audit records an admin action; a failure to record it is logged rather than failing the action
+func (s *AdminService) audit(actorID, action, targetUserID string, ipAddress *string, details models.AuditDetails, actionErr error) {
+	entry := &models.AuditLog{
+		UserID:    &actorID,
+		Action:    action,
+		IPAddress: ipAddress,
+		Success:   actionErr == nil,
+		Details:   details,
+	}
+	if targetUserID != "" {
+		resourceType := models.AuditResourceUser
+		entry.ResourceType = &resourceType
+		entry.ResourceID = &targetUserID
+	}
+	if actionErr != nil {
+		message := actionErr.Error()
+		entry.ErrorMessage = &message
+	}
+	if err := s.auditRepo.CreateAuditLog(entry); err != nil {
+		slog.Error("failed to record admin action", "actor_id", actorID, "action", action, "error", err)
+	}
+}
+
+func adminPage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
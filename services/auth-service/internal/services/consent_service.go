@@ -0,0 +1,165 @@
+package services
+
+import (
+	"auth-service/internal/event"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ConsentService manages versioned consent texts and user consent decisions
+type ConsentService struct {
+	consentRepo   repository.IConsentRepository
+	accountEvents *event.AccountEventPublisher
+	roleService   *RoleService
+}
+
+// NewConsentService creates a new consent service
+func NewConsentService(consentRepo repository.IConsentRepository, accountEvents *event.AccountEventPublisher, roleService *RoleService) *ConsentService {
+	return &ConsentService{
+		consentRepo:   consentRepo,
+		accountEvents: accountEvents,
+		roleService:   roleService,
+	}
+}
+
+// PublishConsentText creates a new version of a consent text, published by an admin. Existing
+// user consents remain tied to the version they agreed to.
+func (s *ConsentService) PublishConsentText(actorID string, consentType models.ConsentType, content string) (*models.ConsentText, error) {
+	isAdmin, err := s.roleService.isGlobalAdmin(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check admin role: %w", err)
+	}
+	if !isAdmin {
+		return nil, fmt.Errorf("forbidden: only admins can publish consent texts")
+	}
+	if !consentType.IsValid() {
+		return nil, fmt.Errorf("bad_request: invalid consent type %s", consentType)
+	}
+	if content == "" {
+		return nil, fmt.Errorf("bad_request: consent content cannot be empty")
+	}
+
+	text := &models.ConsentText{
+		ConsentType: consentType,
+		Content:     content,
+		CreatedBy:   &actorID,
+	}
+	if err := s.consentRepo.CreateConsentText(text); err != nil {
+		return nil, err
+	}
+
+	slog.Info("consent text published", "consent_type", consentType, "version", text.Version)
+	return text, nil
+}
+
+// GetActiveConsentTexts returns the current version of every consent text
+func (s *ConsentService) GetActiveConsentTexts() ([]*models.ConsentText, error) {
+	return s.consentRepo.GetActiveConsentTexts()
+}
+
+// GrantConsent records the user's consent to the given text version, which
+// must be the currently active one.
+func (s *ConsentService) GrantConsent(userID string, consentType models.ConsentType, version int, ipAddress, userAgent *string) (*models.UserConsent, error) {
+	if !consentType.IsValid() {
+		return nil, fmt.Errorf("bad_request: invalid consent type %s", consentType)
+	}
+
+	active, err := s.consentRepo.GetActiveConsentText(consentType)
+	if err != nil {
+		return nil, err
+	}
+	if active.Version != version {
+		return nil, fmt.Errorf("conflict: consent version %d is outdated, current version is %d", version, active.Version)
+	}
+
+	consent := &models.UserConsent{
+		UserID:        userID,
+		ConsentType:   consentType,
+		ConsentTextID: active.ID,
+		Version:       active.Version,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+	}
+	if err := s.consentRepo.GrantConsent(consent); err != nil {
+		return nil, err
+	}
+
+	slog.Info("consent granted", "user_id", userID, "consent_type", consentType, "version", version)
+	s.publishConsentEvent(event.ConsentGranted, userID, consentType)
+	return consent, nil
+}
+
+// WithdrawConsent withdraws the user's active consent of the given type
+func (s *ConsentService) WithdrawConsent(userID string, consentType models.ConsentType) error {
+	if !consentType.IsValid() {
+		return fmt.Errorf("bad_request: invalid consent type %s", consentType)
+	}
+	if err := s.consentRepo.WithdrawConsent(userID, consentType); err != nil {
+		return err
+	}
+
+	slog.Info("consent withdrawn", "user_id", userID, "consent_type", consentType)
+	s.publishConsentEvent(event.ConsentWithdrawn, userID, consentType)
+	return nil
+}
+
+// publishConsentEvent tells the services that keep a copy of the user's consents about the
+// decision. The decision is already recorded, so a failure is only logged.
+func (s *ConsentService) publishConsentEvent(eventType event.AccountEventType, userID string, consentType models.ConsentType) {
+	err := s.accountEvents.Publish(context.Background(), event.AccountEvent{
+		Type:        eventType,
+		UserID:      userID,
+		ConsentType: string(consentType),
+		OccurredAt:  time.Now(),
+	})
+	if err != nil {
+		slog.Error("failed to publish consent event", "user_id", userID, "consent_type", consentType, "error", err)
+	}
+}
+
+// GetUserConsents returns the full consent history of a user, newest first
+func (s *ConsentService) GetUserConsents(userID string) ([]*models.UserConsent, error) {
+	return s.consentRepo.GetUserConsentHistory(userID)
+}
+
+// GetConsentStatus reports whether the user currently holds an active consent of the given type
+// CheckCanViewConsents allows users to view their own consents and admins those of anyone
+func (s *ConsentService) CheckCanViewConsents(actorID, userID string) error {
+	if actorID != "" && actorID == userID {
+		return nil
+	}
+	isAdmin, err := s.roleService.isGlobalAdmin(actorID)
+	if err != nil {
+		return fmt.Errorf("failed to check admin role: %w", err)
+	}
+	if !isAdmin {
+		return fmt.Errorf("forbidden: not allowed to view the consents of another user")
+	}
+	return nil
+}
+
+func (s *ConsentService) GetConsentStatus(userID string, consentType models.ConsentType) (*models.ConsentStatusResponse, error) {
+	if !consentType.IsValid() {
+		return nil, fmt.Errorf("bad_request: invalid consent type %s", consentType)
+	}
+
+	consent, err := s.consentRepo.GetActiveUserConsent(userID, consentType)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.ConsentStatusResponse{
+		UserID:      userID,
+		ConsentType: consentType,
+	}
+	if consent != nil {
+		status.Granted = true
+		status.Version = consent.Version
+		status.GrantedAt = &consent.GrantedAt
+	}
+	return status, nil
+}
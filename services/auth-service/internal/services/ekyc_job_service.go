@@ -0,0 +1,238 @@
+package services
+
+import (
+	"auth-service/internal/database/minio"
+	"auth-service/internal/event"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"mime/multipart"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Extensions of the files accepted by each kind of job
+var ekycJobFileExts = map[string][]string{
+	models.EkycJobKindOCR:          {".jpg", ".png", ".jpeg"},
+	models.EkycJobKindFaceLiveness: {".mp4", ".jpg", ".png"},
+}
+
+// EkycJobService queues the OCR and face liveness steps of eKYC and runs them in workers, so
+// requests return before the vendor answers. Clients poll the job or wait for the push
+// notification sent when it finishes.
+type EkycJobService struct {
+	jobRepo        repository.IEkycJobRepository
+	ekyc           *EkycOrchestrator
+	queue          *event.EkycJobQueueClient
+	minioClient    *minio.MinioClient
+	utils          *utils.Utils
+	eventPublisher *event.NotificationPublisher
+}
+
+func NewEkycJobService(jobRepo repository.IEkycJobRepository, ekyc *EkycOrchestrator, queue *event.EkycJobQueueClient, minioClient *minio.MinioClient, utils *utils.Utils, eventPublisher *event.NotificationPublisher) *EkycJobService {
+	return &EkycJobService{
+		jobRepo:        jobRepo,
+		ekyc:           ekyc,
+		queue:          queue,
+		minioClient:    minioClient,
+		utils:          utils,
+		eventPublisher: eventPublisher,
+	}
+}
+
+// SubmitOCR queues OCR of the national ID card in the form
+func (s *EkycJobService) SubmitOCR(ctx context.Context, userID string, form *multipart.Form) (*models.EkycJob, error) {
+	if _, err := s.ekyc.CheckOCR(userID); err != nil {
+		return nil, err
+	}
+	return s.submit(ctx, userID, models.EkycJobKindOCR, form)
+}
+
+// SubmitFaceLiveness queues face liveness of the video in the form
+func (s *EkycJobService) SubmitFaceLiveness(ctx context.Context, userID string, form *multipart.Form) (*models.EkycJob, error) {
+	if _, err := s.ekyc.CheckFaceLiveness(userID); err != nil {
+		return nil, err
+	}
+	return s.submit(ctx, userID, models.EkycJobKindFaceLiveness, form)
+}
+
+// submit stores the files the job needs and queues it. The files are checked here so a client
+// missing one hears it right away rather than from a failed job.
+func (s *EkycJobService) submit(ctx context.Context, userID, kind string, form *multipart.Form) (*models.EkycJob, error) {
+	// Every submitted file is kept with its hash, so steps cached from an earlier attempt are only
+	// reused for the same files
+	files := map[string][]*multipart.FileHeader{}
+	submitted := models.EkycJobFiles{}
+	for _, field := range s.ekyc.StepFiles(kind) {
+		if len(form.File[field]) == 0 {
+			continue
+		}
+		hash, err := hashFormFile(form.File[field][0])
+		if err != nil {
+			log.Printf("Failed to read %s file: %v", field, err)
+			return nil, newEkycStepError("BAD_REQUEST", "Failed to read "+field+" file")
+		}
+		files[field] = form.File[field][:1]
+		submitted[field] = models.EkycJobFile{SHA256: hash}
+	}
+	for _, field := range s.ekyc.RequiredFiles(ctx, userID, kind, submitted) {
+		if _, ok := files[field]; !ok {
+			log.Printf("Error: %s file is required", field)
+			return nil, newEkycStepError("BAD_REQUEST", field+" file is required")
+		}
+	}
+
+	uploadedFiles, err := s.utils.ProcessFiles(s.minioClient, files, "auth-service", ekycJobFileExts[kind], 50)
+	if err != nil {
+		log.Printf("Failed to upload ekyc files to MinIO: %v", err)
+		if strings.Contains(err.Error(), "validation") {
+			return nil, newEkycStepError("BAD_REQUEST", err.Error())
+		}
+		return nil, newEkycStepError("INTERNAL_ERROR", "Failed to upload files to storage")
+	}
+
+	job := &models.EkycJob{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Kind:   kind,
+		Status: models.EkycJobQueued,
+		Files:  models.EkycJobFiles{},
+	}
+	for _, fileInfo := range uploadedFiles {
+		job.Files[fileInfo.FieldName] = models.EkycJobFile{
+			ObjectName: fileInfo.SafeName,
+			URL:        fileInfo.MinioURL,
+			SHA256:     submitted[fileInfo.FieldName].SHA256,
+		}
+	}
+
+	if err := s.jobRepo.CreateJob(job); err != nil {
+		if strings.Contains(err.Error(), "conflict") {
+			return nil, newEkycStepError("JOB_IN_PROGRESS", "An ekyc "+kind+" job is already in progress")
+		}
+		log.Printf("Failed to create ekyc job: %v", err)
+		return nil, newEkycStepError("INTERNAL_ERROR", "Failed to create ekyc job")
+	}
+
+	if err := s.queue.Publish(ctx, job.ID); err != nil {
+		slog.ErrorContext(ctx, "failed to queue ekyc job", "job_id", job.ID, "error", err)
+		code, message := "SERVICE_UNAVAILABLE", "Failed to queue ekyc job"
+		if err := s.jobRepo.FinishJob(job.ID, models.EkycJobFailed, &code, &message); err != nil {
+			slog.ErrorContext(ctx, "failed to fail unqueued ekyc job", "job_id", job.ID, "error", err)
+		}
+		return nil, newEkycStepError(code, "eKYC processing is not available, try again later")
+	}
+
+	slog.InfoContext(ctx, "ekyc job queued", "job_id", job.ID, "user_id", userID, "kind", kind)
+	return job, nil
+}
+
+// hashFormFile returns the hex SHA256 of the content of an uploaded file
+func hashFormFile(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// GetJob returns a job of the user; jobs of other users are reported as not found
+func (s *EkycJobService) GetJob(userID, jobID string) (*models.EkycJob, error) {
+	job, err := s.jobRepo.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, errors.New("not_found: ekyc job not found")
+	}
+	return job, nil
+}
+
+// Process runs a queued job. Failures of the job are recorded on it; an error is only returned
+// when the outcome could not be stored, so the job is delivered again.
+func (s *EkycJobService) Process(ctx context.Context, jobID string) error {
+	job, err := s.jobRepo.StartJob(jobID)
+	if err != nil {
+		if strings.Contains(err.Error(), "conflict") || strings.Contains(err.Error(), "not_found") {
+			slog.WarnContext(ctx, "skipping ekyc job", "job_id", jobID, "error", err)
+			return nil
+		}
+		return err
+	}
+
+	if job.Kind == models.EkycJobKindFaceLiveness {
+		_, err = s.ekyc.RunFaceLiveness(ctx, job.UserID, job.Files)
+	} else {
+		_, err = s.ekyc.RunOCR(ctx, job.UserID, job.Files)
+	}
+
+	job.Status = models.EkycJobSucceeded
+	if err != nil {
+		stepErr := ekycStepErrorOf(err)
+		// A job delivered again after its results were stored finds the step already done
+		alreadyDone := stepErr.Code == "ALREADY_OCR_DONE" || stepErr.Code == "ALREADY_FACE_LIVENESS_DONE"
+		if job.Attempts <= 1 || !alreadyDone {
+			job.Status = models.EkycJobFailed
+			job.ErrorCode = &stepErr.Code
+			job.ErrorMessage = &stepErr.Message
+		}
+	}
+
+	if err := s.jobRepo.FinishJob(job.ID, job.Status, job.ErrorCode, job.ErrorMessage); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "ekyc job finished", "job_id", job.ID, "user_id", job.UserID, "kind", job.Kind, "status", job.Status)
+	s.notifyFinished(ctx, job)
+	return nil
+}
+
+// StartWorker processes queued jobs until ctx is done
+func (s *EkycJobService) StartWorker(ctx context.Context, concurrency int) {
+	s.queue.Consume(ctx, concurrency, s.Process)
+}
+
+// notifyFinished pushes the outcome of a job to the app of the user
+func (s *EkycJobService) notifyFinished(ctx context.Context, job *models.EkycJob) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	title, body := "Xac Thuc Danh Tinh", "Buoc xac thuc eKYC cua ban da hoan tat."
+	if job.Status == models.EkycJobFailed {
+		body = "Buoc xac thuc eKYC cua ban khong thanh cong, vui long thu lai."
+	}
+	data := map[string]any{
+		"type":   "ekyc_job",
+		"job_id": job.ID,
+		"kind":   job.Kind,
+		"status": job.Status,
+	}
+	if job.ErrorCode != nil {
+		data["error_code"] = *job.ErrorCode
+	}
+	if err := s.eventPublisher.PublishInAppNotification(ctx, job.UserID, title, body, data); err != nil {
+		slog.ErrorContext(ctx, "failed to send ekyc job notification", "job_id", job.ID, "error", err)
+	}
+}
+
+func ekycStepErrorOf(err error) *EkycStepError {
+	var stepErr *EkycStepError
+	if errors.As(err, &stepErr) {
+		return stepErr
+	}
+	return newEkycStepError("INTERNAL_ERROR", err.Error())
+}
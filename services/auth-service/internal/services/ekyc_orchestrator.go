@@ -0,0 +1,595 @@
+package services
+
+import (
+	"auth-service/internal/database/minio"
+	"auth-service/internal/ekyc"
+	"auth-service/internal/event"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Steps that persist results or move the state machine. They are audited like the cached steps
+// but always run, since what they write is idempotent.
+const (
+	EkycStepOCRPersist    = "ocr_persist"
+	EkycStepLivenessStart = "liveness_start"
+	EkycStepFacePersist   = "face_persist"
+	EkycStepNFCChip       = "nfc_chip"
+	EkycStepReset         = "reset"
+)
+
+// Step events returned when a user lists their eKYC history
+const ekycStepEventLimit = 100
+
+// EkycStepError fails an eKYC step with the error code returned to the client
+type EkycStepError struct {
+	Step    string
+	Code    string
+	Message string
+}
+
+func newEkycStepError(code, message string) *EkycStepError {
+	return &EkycStepError{Code: code, Message: message}
+}
+
+func (e *EkycStepError) Error() string {
+	return fmt.Sprintf("ekyc step %s failed: %s: %s", e.Step, e.Code, e.Message)
+}
+
+// ekycRun holds the step results of one eKYC attempt
+type ekycRun struct {
+	userID   string
+	files    models.EkycJobFiles
+	progress *models.UserEkycProgress
+
+	front     *ekyc.IDCardFront
+	back      *ekyc.IDCardBack
+	uploads   map[string]string
+	liveness  *ekyc.LivenessResult
+	faceMatch *ekyc.FaceMatchResult
+	videoURL  string
+}
+
+// ekycStep is a step calling an external service. Its result is cached until the attempt
+// completes, so a failed attempt resumes after the last step that succeeded.
+type ekycStep struct {
+	name string
+	// Job files the step needs when it has to run
+	files []string
+	// result points at the field of the run holding the step result
+	result func(run *ekycRun) any
+	run    func(ctx context.Context, run *ekycRun) error
+}
+
+// EkycOrchestrator runs the eKYC flows through the state machine
+// ocr_pending → ocr_done → liveness_pending → verified, recording every step attempt
+type EkycOrchestrator struct {
+	progressRepo   repository.IUserEkycProgressRepository
+	userRepo       repository.IUserRepository
+	userCardRepo   repository.IUserCardRepository
+	minioClient    *minio.MinioClient
+	provider       ekyc.Provider
+	cache          *EkycStepCache
+	webhooks       *EkycWebhookNotifier
+	eventPublisher *event.NotificationPublisher
+}
+
+func NewEkycOrchestrator(progressRepo repository.IUserEkycProgressRepository, userRepo repository.IUserRepository, userCardRepo repository.IUserCardRepository, minioClient *minio.MinioClient, provider ekyc.Provider, cache *EkycStepCache, webhooks *EkycWebhookNotifier, eventPublisher *event.NotificationPublisher) *EkycOrchestrator {
+	return &EkycOrchestrator{
+		progressRepo:   progressRepo,
+		userRepo:       userRepo,
+		userCardRepo:   userCardRepo,
+		minioClient:    minioClient,
+		provider:       provider,
+		cache:          cache,
+		webhooks:       webhooks,
+		eventPublisher: eventPublisher,
+	}
+}
+
+// NextEkycStep tells the client which eKYC step to submit in the given state
+func NextEkycStep(state string) string {
+	switch state {
+	case models.EkycStateOCRDone, models.EkycStateLivenessPending:
+		return models.EkycNextStepFaceLiveness
+	case models.EkycStateVerified:
+		return models.EkycNextStepCompleted
+	default:
+		return models.EkycNextStepOCR
+	}
+}
+
+// CheckOCR returns the progress of a user who may run OCR
+func (o *EkycOrchestrator) CheckOCR(userID string) (*models.UserEkycProgress, error) {
+	progress, err := o.progressRepo.GetUserEkycProgressByUserID(userID)
+	if err != nil {
+		log.Printf("Failed to get user ekyc progress: %v", err)
+		return nil, newEkycStepError("INTERNAL_ERROR", "Failed to get user ekyc progress")
+	}
+	if progress.IsOcrDone {
+		log.Printf("User %s has already completed OCR Card verification", userID)
+		return nil, newEkycStepError("ALREADY_OCR_DONE", "User has already completed OCR Card verification")
+	}
+	return progress, nil
+}
+
+// CheckFaceLiveness returns the progress of a user who may run face liveness
+func (o *EkycOrchestrator) CheckFaceLiveness(userID string) (*models.UserEkycProgress, error) {
+	progress, err := o.progressRepo.GetUserEkycProgressByUserID(userID)
+	if err != nil {
+		log.Printf("Failed to get ekyc progress: %v", err)
+		return nil, newEkycStepError("INTERNAL_ERROR", "Failed to get ekyc progress")
+	}
+	if !progress.IsDocumentVerified() {
+		log.Printf("Error: user has not completed OCR")
+		return nil, newEkycStepError("BAD_REQUEST", "User has not completed OCR")
+	}
+	if progress.IsFaceVerified || progress.State == models.EkycStateVerified {
+		log.Printf("Error: user has already completed face liveness")
+		return nil, newEkycStepError("ALREADY_FACE_LIVENESS_DONE", "User has already completed face liveness")
+	}
+	return progress, nil
+}
+
+// StepFiles lists every file used by the steps of a job of the given kind
+func (o *EkycOrchestrator) StepFiles(kind string) []string {
+	fields := []string{}
+	seen := map[string]bool{}
+	for _, step := range o.stepsOf(kind) {
+		for _, field := range step.files {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// RequiredFiles lists the files a job of the given kind needs, leaving out those only used by
+// steps cached from an earlier attempt. submitted holds the files of the job, so a cached step
+// whose files were submitted again with other content has to run again.
+func (o *EkycOrchestrator) RequiredFiles(ctx context.Context, userID, kind string, submitted models.EkycJobFiles) []string {
+	required := []string{}
+	seen := map[string]bool{}
+	for _, step := range o.stepsOf(kind) {
+		if o.cache.IsReusable(ctx, userID, step.name, step.files, submitted) {
+			continue
+		}
+		for _, field := range step.files {
+			if !seen[field] {
+				seen[field] = true
+				required = append(required, field)
+			}
+		}
+	}
+	return required
+}
+
+func (o *EkycOrchestrator) stepsOf(kind string) []ekycStep {
+	if kind == models.EkycJobKindFaceLiveness {
+		return o.faceSteps()
+	}
+	return o.ocrSteps()
+}
+
+// RunOCR reads both sides of the national ID card and stores the card of the user. A user who
+// verified the document with the chip can still scan the card, but stays in their state.
+func (o *EkycOrchestrator) RunOCR(ctx context.Context, userID string, files models.EkycJobFiles) (*models.UserEkycProgress, error) {
+	progress, err := o.CheckOCR(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &ekycRun{userID: userID, files: files, progress: progress}
+	if err := o.runSteps(ctx, run, o.ocrSteps()); err != nil {
+		return nil, err
+	}
+	if err := o.persistOCR(run); err != nil {
+		return nil, o.fail(run, EkycStepOCRPersist, err)
+	}
+
+	next := ""
+	if progress.State == models.EkycStateOCRPending {
+		next = models.EkycStateOCRDone
+	}
+	if err := o.complete(run, EkycStepOCRPersist, next); err != nil {
+		return nil, err
+	}
+
+	// OCR results are persisted now, the cached intermediate steps are no longer needed
+	if err := o.cache.ClearSteps(ctx, userID, ekycOCRSteps...); err != nil {
+		log.Printf("Failed to clear cached OCR steps: %v", err)
+	}
+	return o.reloadProgress(userID)
+}
+
+// RunFaceLiveness checks the face video against the card and completes eKYC
+func (o *EkycOrchestrator) RunFaceLiveness(ctx context.Context, userID string, files models.EkycJobFiles) (*models.UserEkycProgress, error) {
+	progress, err := o.CheckFaceLiveness(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &ekycRun{userID: userID, files: files, progress: progress}
+	if progress.State == models.EkycStateOCRDone {
+		if err := o.complete(run, EkycStepLivenessStart, models.EkycStateLivenessPending); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := o.runSteps(ctx, run, o.faceSteps()); err != nil {
+		return nil, err
+	}
+	if err := o.persistFace(run); err != nil {
+		return nil, o.fail(run, EkycStepFacePersist, err)
+	}
+	if err := o.complete(run, EkycStepFacePersist, models.EkycStateVerified); err != nil {
+		return nil, err
+	}
+
+	// Face liveness is persisted now, the cached intermediate steps are no longer needed
+	if err := o.cache.ClearSteps(ctx, userID, ekycFaceSteps...); err != nil {
+		log.Printf("Failed to clear cached face liveness steps: %v", err)
+	}
+
+	updated, err := o.reloadProgress(userID)
+	if err != nil {
+		return nil, err
+	}
+	o.notifyVerified(updated)
+	return updated, nil
+}
+
+// CompleteNFC records a chip read verified by the caller. It stands in for OCR, so a user who
+// had not verified the document yet moves on to face liveness.
+func (o *EkycOrchestrator) CompleteNFC(progress *models.UserEkycProgress) error {
+	run := &ekycRun{userID: progress.UserID, progress: progress}
+	next := ""
+	if progress.State == models.EkycStateOCRPending {
+		next = models.EkycStateOCRDone
+	}
+	return o.complete(run, EkycStepNFCChip, next)
+}
+
+// RecordFailure audits a step that failed outside the orchestrator, such as a chip read. The
+// error category prefix of the service error, like "bad_request", becomes the error code.
+func (o *EkycOrchestrator) RecordFailure(progress *models.UserEkycProgress, step string, err error) {
+	code := "INTERNAL_ERROR"
+	if category, _, ok := strings.Cut(err.Error(), ":"); ok && !strings.Contains(category, " ") {
+		code = strings.ToUpper(category)
+	}
+	o.fail(&ekycRun{userID: progress.UserID, progress: progress}, step, newEkycStepError(code, err.Error()))
+}
+
+// RecordReset audits a reset of the eKYC data, which returns the user to ocr_pending
+func (o *EkycOrchestrator) RecordReset(userID, previousState string) {
+	next := models.EkycStateOCRPending
+	o.recordStep(userID, EkycStepReset, models.EkycStepSucceeded, previousState, &next, nil)
+}
+
+func (o *EkycOrchestrator) GetStepEvents(userID string) ([]*models.EkycStepEvent, error) {
+	return o.progressRepo.GetStepEvents(userID, ekycStepEventLimit)
+}
+
+func (o *EkycOrchestrator) ocrSteps() []ekycStep {
+	return []ekycStep{
+		{
+			name:   EkycStepOCRFront,
+			files:  []string{"cccd_front"},
+			result: func(run *ekycRun) any { return &run.front },
+			run: func(ctx context.Context, run *ekycRun) error {
+				image, err := o.openFile(ctx, run, "cccd_front")
+				if err != nil {
+					return err
+				}
+				defer image.Content.(io.Closer).Close()
+				run.front, err = o.provider.OCRFront(ctx, image)
+				return err
+			},
+		},
+		{
+			name:   EkycStepOCRBack,
+			files:  []string{"cccd_back"},
+			result: func(run *ekycRun) any { return &run.back },
+			run: func(ctx context.Context, run *ekycRun) error {
+				image, err := o.openFile(ctx, run, "cccd_back")
+				if err != nil {
+					return err
+				}
+				defer image.Content.(io.Closer).Close()
+				run.back, err = o.provider.OCRBack(ctx, image)
+				return err
+			},
+		},
+		{
+			name:   EkycStepOCRUpload,
+			files:  []string{"cccd_front", "cccd_back"},
+			result: func(run *ekycRun) any { return &run.uploads },
+			// The images were stored when the job was submitted, the step keeps their URLs
+			run: func(ctx context.Context, run *ekycRun) error {
+				run.uploads = map[string]string{
+					"cccd_front": run.files["cccd_front"].URL,
+					"cccd_back":  run.files["cccd_back"].URL,
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func (o *EkycOrchestrator) faceSteps() []ekycStep {
+	return []ekycStep{
+		{
+			name:   EkycStepFaceLiveness,
+			files:  []string{"video"},
+			result: func(run *ekycRun) any { return &run.liveness },
+			run: func(ctx context.Context, run *ekycRun) error {
+				video, err := o.openFile(ctx, run, "video")
+				if err != nil {
+					return err
+				}
+				defer video.Content.(io.Closer).Close()
+				result, err := o.provider.Liveness(ctx, video)
+				if err != nil {
+					return err
+				}
+				if !result.IsLive {
+					return newEkycStepError("LIVENESS_FAILED", "The video does not show a live person")
+				}
+				run.liveness = result
+				return nil
+			},
+		},
+		{
+			name:   EkycStepFaceMatch,
+			files:  []string{"video", "cmnd"},
+			result: func(run *ekycRun) any { return &run.faceMatch },
+			run: func(ctx context.Context, run *ekycRun) error {
+				video, err := o.openFile(ctx, run, "video")
+				if err != nil {
+					return err
+				}
+				defer video.Content.(io.Closer).Close()
+				card, err := o.openFile(ctx, run, "cmnd")
+				if err != nil {
+					return err
+				}
+				defer card.Content.(io.Closer).Close()
+				result, err := o.provider.FaceMatch(ctx, video, card)
+				if err != nil {
+					return err
+				}
+				if !result.IsMatch {
+					return newEkycStepError("FACE_MISMATCH", "The face does not match the national ID card")
+				}
+				run.faceMatch = result
+				return nil
+			},
+		},
+		{
+			name:   EkycStepFaceUpload,
+			files:  []string{"video"},
+			result: func(run *ekycRun) any { return &run.videoURL },
+			run: func(ctx context.Context, run *ekycRun) error {
+				run.videoURL = run.files["video"].URL
+				return nil
+			},
+		},
+	}
+}
+
+// runSteps restores the cached steps of an earlier attempt run on the same files and runs the
+// others in order
+func (o *EkycOrchestrator) runSteps(ctx context.Context, run *ekycRun, steps []ekycStep) error {
+	pending := []ekycStep{}
+	for _, step := range steps {
+		if o.cache.LoadStep(ctx, run.userID, step.name, step.files, run.files, step.result(run)) {
+			log.Printf("Resuming eKYC of user %s with cached %s result", run.userID, step.name)
+			o.recordStep(run.userID, step.name, models.EkycStepResumed, run.progress.State, nil, nil)
+			continue
+		}
+		pending = append(pending, step)
+	}
+
+	// Files are checked for every pending step up front, so a missing file is reported before
+	// any external service is called
+	for _, step := range pending {
+		for _, field := range step.files {
+			if _, ok := run.files[field]; !ok {
+				log.Printf("Error: %s file is required", field)
+				return o.fail(run, step.name, newEkycStepError("BAD_REQUEST", field+" file is required"))
+			}
+		}
+	}
+
+	for _, step := range pending {
+		if err := step.run(ctx, run); err != nil {
+			return o.fail(run, step.name, err)
+		}
+		if err := o.cache.SaveStep(ctx, run.userID, step.name, step.files, run.files, step.result(run)); err != nil {
+			log.Printf("Failed to cache %s result: %v", step.name, err)
+		}
+		o.recordStep(run.userID, step.name, models.EkycStepSucceeded, run.progress.State, nil, nil)
+	}
+	return nil
+}
+
+// openFile reads a job file back from storage for the provider. Its content is an io.Closer the
+// caller closes.
+func (o *EkycOrchestrator) openFile(ctx context.Context, run *ekycRun, field string) (ekyc.File, error) {
+	name := run.files[field].ObjectName
+	reader, err := o.minioClient.GetFile(ctx, "", name, "auth-service")
+	if err != nil {
+		log.Printf("Failed to get %s file from MinIO: %v", field, err)
+		return ekyc.File{}, newEkycStepError("INTERNAL_ERROR", "Failed to get "+field+" file from storage")
+	}
+	content, ok := reader.(io.ReadCloser)
+	if !ok {
+		content = io.NopCloser(reader)
+	}
+	return ekyc.File{Name: name, Content: content}, nil
+}
+
+func (o *EkycOrchestrator) persistOCR(run *ekycRun) error {
+	nationalID := run.front.NationalID
+	if err := o.userRepo.UpdateUserNationalID(run.userID, nationalID); err != nil {
+		log.Printf("Failed to update user national ID: %v", err)
+		return newEkycStepError("INTERNAL_ERROR", "Failed to update user national ID")
+	}
+
+	// The card may be stored already by an attempt that failed after storing it
+	existing, err := o.userCardRepo.GetUserCardByUserID(run.userID)
+	if err != nil || existing.NationalID != nationalID {
+		if err := o.createUserCard(run, nationalID); err != nil {
+			return err
+		}
+	}
+
+	if err := o.progressRepo.UpdateOCRDone(run.userID, true, nationalID); err != nil {
+		log.Printf("Failed to update ekyc progress: %v", err)
+		return newEkycStepError("INTERNAL_ERROR", "Failed to update ekyc progress")
+	}
+	return nil
+}
+
+func (o *EkycOrchestrator) createUserCard(run *ekycRun, nationalID string) error {
+	userCard := models.UserCard{
+		NationalID:        nationalID,
+		Name:              run.front.Name,
+		Dob:               run.front.Dob,
+		Sex:               run.front.Sex,
+		Nationality:       run.front.Nationality,
+		Home:              run.front.Home,
+		Address:           run.front.Address,
+		Doe:               run.front.Doe,
+		NumberOfNameLines: run.front.NumberOfNameLines,
+		Features:          run.back.Features,
+		IssueDate:         run.back.IssueDate,
+		Mrz:               strings.Join(run.back.MRZ, ", "),
+		IssueLoc:          run.back.IssueLoc,
+		ImageFront:        run.uploads["cccd_front"],
+		ImageBack:         run.uploads["cccd_back"],
+		UserID:            run.userID,
+	}
+	normalizeCardAddress(&userCard)
+
+	if _, err := o.userCardRepo.CreateUserCard(&userCard); err != nil {
+		log.Printf("Failed to create user card record: %v", err)
+		return newEkycStepError("INTERNAL_ERROR", "Failed to create user card record")
+	}
+	return nil
+}
+
+func (o *EkycOrchestrator) persistFace(run *ekycRun) error {
+	if err := o.userRepo.UpdateUserFaceLiveness(run.userID, run.videoURL); err != nil {
+		log.Printf("Failed to update user face liveness: %v", err)
+		return newEkycStepError("INTERNAL_ERROR", "Failed to update user face liveness")
+	}
+	if err := o.progressRepo.UpdateFaceLivenessDone(run.userID, true); err != nil {
+		log.Printf("Failed to update ekyc progress: %v", err)
+		return newEkycStepError("INTERNAL_ERROR", "Failed to update ekyc progress")
+	}
+	if err := o.userRepo.UpdateUserKycStatus(run.userID, true); err != nil {
+		log.Printf("Failed to update user status: %v", err)
+		return newEkycStepError("INTERNAL_ERROR", "Failed to update user status")
+	}
+	return nil
+}
+
+// complete records a step that succeeded and moves the user to the next state, if any
+func (o *EkycOrchestrator) complete(run *ekycRun, step, next string) error {
+	state := run.progress.State
+	if next == "" || next == state {
+		o.recordStep(run.userID, step, models.EkycStepSucceeded, state, nil, nil)
+		return nil
+	}
+
+	if err := o.progressRepo.TransitionState(run.userID, state, next); err != nil {
+		log.Printf("Failed to move ekyc of user %s from %s to %s: %v", run.userID, state, next, err)
+		return o.fail(run, step, newEkycStepError("INTERNAL_ERROR", "Failed to update ekyc progress"))
+	}
+	o.recordStep(run.userID, step, models.EkycStepSucceeded, state, &next, nil)
+	run.progress.State = next
+	return nil
+}
+
+// fail records a failed step and returns its error, as an EkycStepError naming the step
+func (o *EkycOrchestrator) fail(run *ekycRun, step string, err error) error {
+	var stepErr *EkycStepError
+	var providerErr *ekyc.Error
+	switch {
+	case errors.As(err, &stepErr):
+	case errors.As(err, &providerErr):
+		stepErr = newEkycStepError(providerErr.Code, providerErr.Message)
+	default:
+		stepErr = newEkycStepError("INTERNAL_ERROR", err.Error())
+	}
+	stepErr.Step = step
+	o.recordStep(run.userID, step, models.EkycStepFailed, run.progress.State, nil, stepErr)
+	return stepErr
+}
+
+func (o *EkycOrchestrator) recordStep(userID, step, outcome, state string, next *string, stepErr *EkycStepError) {
+	event := &models.EkycStepEvent{
+		UserID:    userID,
+		Step:      step,
+		Outcome:   outcome,
+		State:     state,
+		NextState: next,
+	}
+	if stepErr != nil {
+		event.ErrorCode = &stepErr.Code
+		event.ErrorMessage = &stepErr.Message
+	}
+	if err := o.progressRepo.CreateStepEvent(event); err != nil {
+		slog.Error("failed to record ekyc step", "user_id", userID, "step", step, "outcome", outcome, "error", err)
+	}
+}
+
+func (o *EkycOrchestrator) reloadProgress(userID string) (*models.UserEkycProgress, error) {
+	progress, err := o.progressRepo.GetUserEkycProgressByUserID(userID)
+	if err != nil {
+		log.Printf("Failed to get updated ekyc progress: %v", err)
+		return nil, newEkycStepError("INTERNAL_ERROR", "Failed to get updated ekyc progress")
+	}
+	return progress, nil
+}
+
+// notifyVerified tells the user and the webhook consumers that eKYC is complete
+func (o *EkycOrchestrator) notifyVerified(progress *models.UserEkycProgress) {
+	o.webhooks.Send(models.EkycVerifiedEvent{
+		Event:             "ekyc.verified",
+		UserID:            progress.UserID,
+		VerificationLevel: progress.VerificationLevel(),
+		VerifiedAt:        time.Now(),
+	})
+
+	if o.eventPublisher == nil {
+		return
+	}
+	user, err := o.userRepo.GetUserByID(progress.UserID)
+	if err != nil || user.PhoneNumber == "" {
+		return
+	}
+	go func() {
+		notice := event.NotificationEventPushModel{
+			Notification: event.Notification{
+				Title: "Xac Thuc Danh Tinh Thanh Cong",
+				Body:  "Tai khoan Agrisa cua ban da hoan tat xac thuc danh tinh (eKYC).",
+			},
+			Destinations: []string{user.PhoneNumber},
+		}
+		if err := o.eventPublisher.PublishNotification(context.Background(), notice); err != nil {
+			slog.Error("failed to send ekyc completion notice", "user_id", progress.UserID, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// eKYC steps whose results are cached so a failed attempt can resume from the
+// last completed step instead of calling the provider again
+const (
+	EkycStepOCRFront     = "ocr_front"
+	EkycStepOCRBack      = "ocr_back"
+	EkycStepOCRUpload    = "ocr_upload"
+	EkycStepFaceLiveness = "face_liveness"
+	EkycStepFaceMatch    = "face_match"
+	EkycStepFaceUpload   = "face_upload"
+)
+
+var ekycStepOrder = []string{
+	EkycStepOCRFront,
+	EkycStepOCRBack,
+	EkycStepOCRUpload,
+	EkycStepFaceLiveness,
+	EkycStepFaceMatch,
+	EkycStepFaceUpload,
+}
+
+var (
+	ekycOCRSteps  = []string{EkycStepOCRFront, EkycStepOCRBack, EkycStepOCRUpload}
+	ekycFaceSteps = []string{EkycStepFaceLiveness, EkycStepFaceMatch, EkycStepFaceUpload}
+)
+
+const ekycStepCacheTTL = 24 * time.Hour
+
+// cachedEkycStep is the stored form of a step, including its result and the SHA256 of the files
+// it ran on
+type cachedEkycStep struct {
+	Step        string            `json:"step"`
+	CompletedAt time.Time         `json:"completed_at"`
+	Inputs      map[string]string `json:"inputs,omitempty"`
+	Result      json.RawMessage   `json:"result"`
+}
+
+// EkycStepCache stores intermediate eKYC results in Redis, one hash per user
+type EkycStepCache struct {
+	client *redis.Client
+}
+
+func NewEkycStepCache(client *redis.Client) *EkycStepCache {
+	return &EkycStepCache{
+		client: client,
+	}
+}
+
+func (c *EkycStepCache) key(userID string) string {
+	return fmt.Sprintf("ekyc:steps:%s", userID)
+}
+
+// SaveStep caches the result of a completed step run on the given files and refreshes the cache
+// expiry
+func (c *EkycStepCache) SaveStep(ctx context.Context, userID, step string, inputs []string, files models.EkycJobFiles, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ekyc step result: %w", err)
+	}
+
+	cached, err := json.Marshal(cachedEkycStep{
+		Step:        step,
+		CompletedAt: time.Now(),
+		Inputs:      ekycStepInputs(inputs, files),
+		Result:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ekyc step: %w", err)
+	}
+
+	pipe := c.client.Pipeline()
+	pipe.HSet(ctx, c.key(userID), step, cached)
+	pipe.Expire(ctx, c.key(userID), ekycStepCacheTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache ekyc step: %w", err)
+	}
+	return nil
+}
+
+// LoadStep decodes a cached step result into out, reporting whether the step was cached. A step
+// is only reused when none of its input files was submitted again with other content.
+func (c *EkycStepCache) LoadStep(ctx context.Context, userID, step string, inputs []string, files models.EkycJobFiles, out any) bool {
+	cached, ok := c.reusable(ctx, userID, step, inputs, files)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(cached.Result, out) == nil
+}
+
+// IsReusable reports whether a cached step can be reused with the given files
+func (c *EkycStepCache) IsReusable(ctx context.Context, userID, step string, inputs []string, files models.EkycJobFiles) bool {
+	_, ok := c.reusable(ctx, userID, step, inputs, files)
+	return ok
+}
+
+func (c *EkycStepCache) reusable(ctx context.Context, userID, step string, inputs []string, files models.EkycJobFiles) (*cachedEkycStep, bool) {
+	raw, err := c.client.HGet(ctx, c.key(userID), step).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedEkycStep
+	if err := json.Unmarshal(raw, &cached); err != nil {
+		return nil, false
+	}
+	if !ekycInputsMatch(cached.Inputs, inputs, files) {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// ekycStepInputs maps the input files of a step to their SHA256
+func ekycStepInputs(inputs []string, files models.EkycJobFiles) map[string]string {
+	hashes := map[string]string{}
+	for _, field := range inputs {
+		if file, ok := files[field]; ok && file.SHA256 != "" {
+			hashes[field] = file.SHA256
+		}
+	}
+	return hashes
+}
+
+// ekycInputsMatch reports whether every input file submitted again has the content the step was
+// cached with. Inputs that were not submitted again keep the cached result; a file of unknown
+// content never matches.
+func ekycInputsMatch(recorded map[string]string, inputs []string, files models.EkycJobFiles) bool {
+	for _, field := range inputs {
+		file, ok := files[field]
+		if !ok {
+			continue
+		}
+		if file.SHA256 == "" || recorded[field] != file.SHA256 {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCachedSteps returns the cached steps of a user in flow order
+func (c *EkycStepCache) GetCachedSteps(ctx context.Context, userID string) ([]models.EkycCachedStep, error) {
+	entries, err := c.client.HGetAll(ctx, c.key(userID)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get cached ekyc steps: %w", err)
+	}
+
+	steps := []models.EkycCachedStep{}
+	for _, step := range ekycStepOrder {
+		raw, ok := entries[step]
+		if !ok {
+			continue
+		}
+		var cached cachedEkycStep
+		if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+			continue
+		}
+		steps = append(steps, models.EkycCachedStep{
+			Step:        cached.Step,
+			CompletedAt: cached.CompletedAt,
+		})
+	}
+	return steps, nil
+}
+
+// ClearSteps drops cached steps once their results are persisted; with no steps given, everything is dropped
+func (c *EkycStepCache) ClearSteps(ctx context.Context, userID string, steps ...string) error {
+	var err error
+	if len(steps) == 0 {
+		err = c.client.Del(ctx, c.key(userID)).Err()
+	} else {
+		err = c.client.HDel(ctx, c.key(userID), steps...).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear cached ekyc steps: %w", err)
+	}
+	return nil
+}
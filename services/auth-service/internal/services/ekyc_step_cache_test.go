@@ -0,0 +1,60 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEkycInputsMatch(t *testing.T) {
+	inputs := []string{"cccd_front", "cccd_back"}
+	recorded := ekycStepInputs(inputs, models.EkycJobFiles{
+		"cccd_front": {SHA256: "front"},
+		"cccd_back":  {SHA256: "back"},
+	})
+
+	tests := []struct {
+		name     string
+		recorded map[string]string
+		files    models.EkycJobFiles
+		match    bool
+	}{
+		{
+			name:     "same files",
+			recorded: recorded,
+			files:    models.EkycJobFiles{"cccd_front": {SHA256: "front"}, "cccd_back": {SHA256: "back"}},
+			match:    true,
+		},
+		{
+			name:     "files not submitted again",
+			recorded: recorded,
+			files:    models.EkycJobFiles{},
+			match:    true,
+		},
+		{
+			name:     "new front image",
+			recorded: recorded,
+			files:    models.EkycJobFiles{"cccd_front": {SHA256: "other"}},
+			match:    false,
+		},
+		{
+			name:     "file of unknown content",
+			recorded: recorded,
+			files:    models.EkycJobFiles{"cccd_back": {}},
+			match:    false,
+		},
+		{
+			name:     "step cached without hashes",
+			recorded: nil,
+			files:    models.EkycJobFiles{"cccd_front": {SHA256: "front"}},
+			match:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, ekycInputsMatch(tt.recorded, inputs, tt.files))
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery of an event is given up after this many attempts, retried 10, 20 and 40 seconds apart
+const (
+	ekycWebhookMaxAttempts = 4
+	ekycWebhookRetryDelay  = 10 * time.Second
+)
+
+// Headers of an eKYC webhook delivery, signed the same way as the weather webhooks: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by the shared secret
+const (
+	EkycWebhookSignatureHeader = "X-Agrisa-Signature"
+	EkycWebhookTimestampHeader = "X-Agrisa-Timestamp"
+	EkycWebhookEventIDHeader   = "X-Agrisa-Event-ID"
+)
+
+// EkycWebhookNotifier posts eKYC events to the consumer URLs set in the configuration
+type EkycWebhookNotifier struct {
+	urls   []string
+	secret string
+	client *http.Client
+}
+
+// NewEkycWebhookNotifier takes the comma separated consumer URLs; without any, events are dropped
+func NewEkycWebhookNotifier(urls, secret string) *EkycWebhookNotifier {
+	notifier := &EkycWebhookNotifier{
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			notifier.urls = append(notifier.urls, url)
+		}
+	}
+	return notifier
+}
+
+// Send delivers the event to every consumer in the background
+func (n *EkycWebhookNotifier) Send(event any) {
+	if len(n.urls) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to marshal ekyc webhook event", "error", err)
+		return
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		slog.Error("failed to generate ekyc webhook event id", "error", err)
+		return
+	}
+	eventID := "evt_" + hex.EncodeToString(id)
+
+	for _, url := range n.urls {
+		go n.deliver(url, eventID, body)
+	}
+}
+
+func (n *EkycWebhookNotifier) deliver(url, eventID string, body []byte) {
+	delay := ekycWebhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := n.post(url, eventID, body)
+		if err == nil {
+			slog.Info("ekyc webhook delivered", "url", url, "event_id", eventID)
+			return
+		}
+		if attempt == ekycWebhookMaxAttempts {
+			slog.Error("ekyc webhook delivery failed", "url", url, "event_id", eventID, "attempts", attempt, "error", err)
+			return
+		}
+		slog.Warn("ekyc webhook delivery attempt failed", "url", url, "event_id", eventID, "attempt", attempt, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (n *EkycWebhookNotifier) post(url, eventID string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(n.secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EkycWebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set(EkycWebhookTimestampHeader, timestamp)
+	req.Header.Set(EkycWebhookEventIDHeader, eventID)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("consumer responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
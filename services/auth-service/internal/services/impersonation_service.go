@@ -0,0 +1,287 @@
+package services
+
+import (
+	"auth-service/internal/event"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	impersonationPermissionResource = "user"
+	impersonationPermissionAction   = "impersonate"
+	defaultImpersonationDuration    = 15 * time.Minute
+	maxImpersonationDuration        = 60 * time.Minute
+)
+
+// ImpersonationService lets permissioned support staff act as a user for a
+// limited time. Every impersonated request is written to the audit log and the
+// user is notified once the impersonation ends.
+type ImpersonationService struct {
+	impersonationRepo repository.IImpersonationRepository
+	userRepo          repository.IUserRepository
+	roleService       *RoleService
+	sessionService    *SessionService
+	jwtService        *JWTService
+	eventPublisher    *event.NotificationPublisher
+}
+
+// NewImpersonationService creates a new impersonation service
+func NewImpersonationService(impersonationRepo repository.IImpersonationRepository, userRepo repository.IUserRepository, roleService *RoleService, sessionService *SessionService, jwtService *JWTService, eventPublisher *event.NotificationPublisher) *ImpersonationService {
+	return &ImpersonationService{
+		impersonationRepo: impersonationRepo,
+		userRepo:          userRepo,
+		roleService:       roleService,
+		sessionService:    sessionService,
+		jwtService:        jwtService,
+		eventPublisher:    eventPublisher,
+	}
+}
+
+// checkImpersonationTarget refuses targets whose token would grant more than a regular user's,
+// since the impersonation token carries the roles and scopes of the target
+func checkImpersonationTarget(roleNames []string, scopes []models.RoleScope) error {
+	for _, roleName := range roleNames {
+		if roleName == models.AdminRoleName || roleName == models.AdminPartnerRoleName {
+			return fmt.Errorf("forbidden: %s accounts cannot be impersonated", roleName)
+		}
+	}
+	if len(scopes) > 0 {
+		return fmt.Errorf("forbidden: accounts holding scoped roles cannot be impersonated")
+	}
+	return nil
+}
+
+func (s *ImpersonationService) canImpersonate(userID string) (bool, error) {
+	return s.roleService.UserHasPermission(userID, impersonationPermissionResource, impersonationPermissionAction)
+}
+
+// StartImpersonation issues a time-boxed token that acts as the target user
+func (s *ImpersonationService) StartImpersonation(ctx context.Context, supportUserID, targetUserID, reason string, durationMinutes int, ipAddress *string) (*models.StartImpersonationResponse, error) {
+	if supportUserID == targetUserID {
+		return nil, fmt.Errorf("bad_request: cannot impersonate yourself")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("bad_request: reason is required")
+	}
+
+	allowed, err := s.canImpersonate(supportUserID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking impersonation permission: %w", err)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: missing impersonation permission")
+	}
+
+	target, err := s.userRepo.GetUserByID(targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("not_found: target user not found")
+	}
+	// Staff accounts are never impersonated, to keep privileges from being chained
+	targetIsStaff, err := s.canImpersonate(targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking target permissions: %w", err)
+	}
+	if targetIsStaff {
+		return nil, fmt.Errorf("forbidden: support accounts cannot be impersonated")
+	}
+
+	// The token carries the roles of the target, so services authorize it as the target
+	targetRoles, err := s.roleService.GetUserRoles(target.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error getting target roles: %w", err)
+	}
+	roleNames := make([]string, 0, len(targetRoles))
+	for _, role := range targetRoles {
+		roleNames = append(roleNames, role.Name)
+	}
+	scopes, err := s.roleService.GetUserRoleScopes(target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting target role scopes: %w", err)
+	}
+	if err := checkImpersonationTarget(roleNames, scopes); err != nil {
+		return nil, err
+	}
+
+	duration := defaultImpersonationDuration
+	if durationMinutes > 0 {
+		duration = time.Duration(durationMinutes) * time.Minute
+	}
+	if duration > maxImpersonationDuration {
+		duration = maxImpersonationDuration
+	}
+
+	impersonation := &models.ImpersonationSession{
+		ID:            uuid.New().String(),
+		SupportUserID: supportUserID,
+		TargetUserID:  targetUserID,
+		Reason:        reason,
+		IPAddress:     ipAddress,
+		ExpiresAt:     time.Now().Add(duration),
+	}
+
+	token, err := s.jwtService.GenerateImpersonationToken(roleNames, scopes, target.PhoneNumber, target.Email, target.ID, supportUserID, impersonation.ID, impersonation.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error generating impersonation token: %w", err)
+	}
+
+	deviceInfo := fmt.Sprintf("impersonation:%s", supportUserID)
+	session, err := s.sessionService.CreateSession(ctx, target.ID, token, nil, &deviceInfo, nil, ipAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error creating impersonation session: %w", err)
+	}
+	impersonation.SessionID = session.ID
+
+	if err := s.impersonationRepo.CreateImpersonationSession(impersonation); err != nil {
+		s.sessionService.InvalidateSession(ctx, session.ID)
+		return nil, err
+	}
+
+	slog.Info("impersonation started",
+		"impersonation_id", impersonation.ID,
+		"support_user_id", supportUserID,
+		"target_user_id", targetUserID,
+		"expires_at", impersonation.ExpiresAt)
+
+	return &models.StartImpersonationResponse{
+		Impersonation: impersonation,
+		AccessToken:   token,
+	}, nil
+}
+
+// RecordImpersonatedAction audits a request made with an impersonation token
+func (s *ImpersonationService) RecordImpersonatedAction(claims *models.Claims, method, uri string, ipAddress *string) error {
+	if claims.ImpersonationID == "" {
+		return nil
+	}
+
+	auditLog := &models.AuditLog{
+		UserID:          &claims.UserID,
+		Action:          "impersonated_request",
+		ResourceType:    &method,
+		ResourceID:      &uri,
+		IPAddress:       ipAddress,
+		Success:         true,
+		ImpersonatorID:  &claims.ImpersonatorID,
+		ImpersonationID: &claims.ImpersonationID,
+	}
+	return s.impersonationRepo.LogImpersonatedAction(auditLog)
+}
+
+// EndImpersonation is called by the support user to close an impersonation early
+func (s *ImpersonationService) EndImpersonation(ctx context.Context, supportUserID, impersonationID string) error {
+	impersonation, err := s.impersonationRepo.GetImpersonationSession(impersonationID)
+	if err != nil {
+		return err
+	}
+	if impersonation.SupportUserID != supportUserID {
+		return fmt.Errorf("forbidden: impersonation belongs to another support user")
+	}
+	if impersonation.EndedAt != nil {
+		return fmt.Errorf("bad_request: impersonation already ended")
+	}
+
+	return s.closeImpersonation(ctx, impersonation, models.ImpersonationEndManual)
+}
+
+// GetImpersonationAuditTrail returns the impersonation session and every action taken during it
+func (s *ImpersonationService) GetImpersonationAuditTrail(requesterID, impersonationID string) (*models.ImpersonationSession, []*models.AuditLog, error) {
+	allowed, err := s.canImpersonate(requesterID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error checking impersonation permission: %w", err)
+	}
+	if !allowed {
+		return nil, nil, fmt.Errorf("forbidden: missing impersonation permission")
+	}
+
+	impersonation, err := s.impersonationRepo.GetImpersonationSession(impersonationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logs, err := s.impersonationRepo.GetImpersonationAuditLogs(impersonationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return impersonation, logs, nil
+}
+
+// ExpireImpersonations closes every impersonation whose time box has passed
+func (s *ImpersonationService) ExpireImpersonations(ctx context.Context) {
+	expired, err := s.impersonationRepo.GetExpiredOpenSessions(time.Now())
+	if err != nil {
+		slog.Error("failed to get expired impersonations", "error", err)
+		return
+	}
+
+	for _, impersonation := range expired {
+		if err := s.closeImpersonation(ctx, impersonation, models.ImpersonationEndExpired); err != nil {
+			slog.Error("failed to close expired impersonation", "impersonation_id", impersonation.ID, "error", err)
+		}
+	}
+}
+
+// StartExpiryWatcher periodically closes expired impersonations until ctx is cancelled. Every
+// instance runs it; an impersonation is closed, and its user notified, by one of them only.
+func (s *ImpersonationService) StartExpiryWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ExpireImpersonations(ctx)
+		}
+	}
+}
+
+func (s *ImpersonationService) closeImpersonation(ctx context.Context, impersonation *models.ImpersonationSession, endReason string) error {
+	if err := s.sessionService.InvalidateSession(ctx, impersonation.SessionID); err != nil {
+		return fmt.Errorf("error invalidating impersonation session: %w", err)
+	}
+	ended, err := s.impersonationRepo.EndImpersonationSession(impersonation.ID, endReason)
+	if err != nil {
+		return err
+	}
+	if !ended {
+		// Closed meanwhile by another instance's expiry watcher or by the support user, who
+		// notified the user already
+		return nil
+	}
+
+	slog.Info("impersonation ended", "impersonation_id", impersonation.ID, "end_reason", endReason)
+	s.notifyUser(ctx, impersonation)
+	return nil
+}
+
+func (s *ImpersonationService) notifyUser(ctx context.Context, impersonation *models.ImpersonationSession) {
+	target, err := s.userRepo.GetUserByID(impersonation.TargetUserID)
+	if err != nil || target.PhoneNumber == "" {
+		slog.Error("failed to load user for impersonation notice", "impersonation_id", impersonation.ID, "error", err)
+		return
+	}
+
+	notice := event.NotificationEventPushModel{
+		Notification: event.Notification{
+			Title: "Ho Tro Truy Cap Tai Khoan",
+			Body: fmt.Sprintf("Nhan vien ho tro Agrisa da truy cap tai khoan cua ban tu %s den %s de xu ly su co. Ly do: %s",
+				impersonation.StartedAt.Format("15:04 02/01/2006"), time.Now().Format("15:04 02/01/2006"), impersonation.Reason),
+		},
+		Destinations: []string{target.PhoneNumber},
+	}
+	if err := s.eventPublisher.PublishNotification(ctx, notice); err != nil {
+		slog.Error("failed to send impersonation notice", "impersonation_id", impersonation.ID, "error", err)
+		return
+	}
+
+	if err := s.impersonationRepo.MarkUserNotified(impersonation.ID); err != nil {
+		slog.Error("failed to mark impersonation notice sent", "impersonation_id", impersonation.ID, "error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// An impersonation token carries the roles and scopes of its target, so impersonating a privileged
+// account would hand its privileges to the support user
+func TestCheckImpersonationTarget(t *testing.T) {
+	partnerScope := models.RoleScope{
+		ScopeType: models.ScopeTypeInsuranceProvider,
+		ScopeID:   "provider-1",
+		Roles:     []string{models.AdminPartnerRoleName},
+	}
+
+	tests := []struct {
+		name      string
+		roleNames []string
+		scopes    []models.RoleScope
+		allowed   bool
+	}{
+		{name: "farmer", roleNames: []string{"user_default", "farmer"}, allowed: true},
+		{name: "no roles", allowed: true},
+		{name: "platform admin", roleNames: []string{"user_default", models.AdminRoleName}},
+		{name: "partner admin", roleNames: []string{models.AdminPartnerRoleName}},
+		{name: "scoped partner admin", roleNames: []string{"user_default"}, scopes: []models.RoleScope{partnerScope}},
+		{name: "scoped role of any kind", roleNames: []string{"farmer"}, scopes: []models.RoleScope{{
+			ScopeType: models.ScopeTypeInsuranceProvider,
+			ScopeID:   "provider-2",
+			Roles:     []string{"farmer"},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkImpersonationTarget(tt.roleNames, tt.scopes)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "forbidden:")
+		})
+	}
+}
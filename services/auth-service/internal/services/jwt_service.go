@@ -19,7 +19,7 @@ func NewJWTService(jwtSecret string) *JWTService {
 	}
 }
 
-func (jwt_s *JWTService) GenerateNewToken(roles []string, phone, email, userID string) (string, error) {
+func (jwt_s *JWTService) GenerateNewToken(roles []string, scopes []models.RoleScope, phone, email, userID string) (string, error) {
 	claim_id := "C-" + utils.GenerateRandomStringWithLength(6)
 	claim := models.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -30,6 +30,8 @@ func (jwt_s *JWTService) GenerateNewToken(roles []string, phone, email, userID s
 		UserID: userID,
 		Phone:  phone,
 		Email:  email,
+		Roles:  roles,
+		Scopes: scopes,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
 	tokenString, err := token.SignedString([]byte(jwt_s.JWTSecret))
@@ -61,3 +63,31 @@ func (jwt_s *JWTService) VerifyToken(tokenString string) (*models.Claims, error)
 
 	return claims, nil
 }
+
+// GenerateImpersonationToken issues a token that acts as the target user, with the roles of the
+// target, tagged with the support user and impersonation session, and rejected once expiresAt
+// has passed.
+func (jwt_s *JWTService) GenerateImpersonationToken(roles []string, scopes []models.RoleScope, phone, email, userID, impersonatorID, impersonationID string, expiresAt time.Time) (string, error) {
+	claim_id := "I-" + utils.GenerateRandomStringWithLength(6)
+	claim := models.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    "auth-service",
+		},
+		Id:              claim_id,
+		UserID:          userID,
+		Phone:           phone,
+		Email:           email,
+		Roles:           roles,
+		Scopes:          scopes,
+		ImpersonatorID:  impersonatorID,
+		ImpersonationID: impersonationID,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	tokenString, err := token.SignedString([]byte(jwt_s.JWTSecret))
+	if err != nil {
+		return "", fmt.Errorf("error generate token string: %s", err)
+	}
+	return tokenString, nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Failed logins are counted over a sliding window. Each failure makes the next attempt wait a
+// little longer, and reaching the threshold locks the account for the current lockout window.
+const (
+	failedLoginWindow    = 15 * time.Minute
+	failedLoginThreshold = 5
+	loginDelayBase       = 1 * time.Second
+	loginDelayMax        = 30 * time.Second
+	// Lockouts within this period of the previous one move on to the next, longer window
+	lockoutLevelTTL = 24 * time.Hour
+)
+
+var lockoutWindows = []time.Duration{
+	5 * time.Minute,
+	15 * time.Minute,
+	1 * time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// recordFailureScript adds a failure to the window of the user and either locks the account or
+// sets the delay before the next attempt. It uses the Redis clock so all replicas agree.
+//
+// KEYS: failures (sorted set), delay, lock, lockout level
+// ARGV: window ms, threshold, member, delay base ms, delay max ms, level ttl ms, lockout windows ms...
+var recordFailureScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+redis.call('ZADD', KEYS[1], now, ARGV[3])
+redis.call('PEXPIRE', KEYS[1], window)
+local failures = redis.call('ZCARD', KEYS[1])
+
+if failures >= tonumber(ARGV[2]) then
+	local level = redis.call('INCR', KEYS[4])
+	redis.call('PEXPIRE', KEYS[4], tonumber(ARGV[6]))
+	local windows = #ARGV - 6
+	local lock = tonumber(ARGV[6 + math.min(level, windows)])
+	redis.call('SET', KEYS[3], level, 'PX', lock)
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return {failures, lock, level}
+end
+
+local delay = math.min(tonumber(ARGV[4]) * math.pow(2, failures - 1), tonumber(ARGV[5]))
+delay = math.floor(delay)
+redis.call('SET', KEYS[2], 1, 'PX', delay)
+return {failures, 0, delay}
+`)
+
+// LoginThrottledError rejects a login attempt made while the account is locked or before the
+// delay following the last failure has passed
+type LoginThrottledError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account blocked due to too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// FailedLogin is the outcome of recording a failed login
+type FailedLogin struct {
+	Failures int
+	// LockedFor is set when this failure locked the account
+	LockedFor time.Duration
+	Delay     time.Duration
+}
+
+// LoginAttemptTracker keeps failed logins and account lockouts in Redis, so they survive restarts
+// and are shared by every replica
+type LoginAttemptTracker struct {
+	client *redis.Client
+}
+
+func NewLoginAttemptTracker(client *redis.Client) *LoginAttemptTracker {
+	return &LoginAttemptTracker{
+		client: client,
+	}
+}
+
+func (t *LoginAttemptTracker) keys(userID string) []string {
+	return []string{
+		fmt.Sprintf("login:failures:%s", userID),
+		fmt.Sprintf("login:delay:%s", userID),
+		fmt.Sprintf("login:locked:%s", userID),
+		fmt.Sprintf("login:lockout-level:%s", userID),
+	}
+}
+
+// Check returns a LoginThrottledError when the user may not attempt to log in yet
+func (t *LoginAttemptTracker) Check(ctx context.Context, userID string) error {
+	keys := t.keys(userID)
+	pipe := t.client.Pipeline()
+	delayTTL := pipe.PTTL(ctx, keys[1])
+	lockTTL := pipe.PTTL(ctx, keys[2])
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+
+	if ttl := lockTTL.Val(); ttl > 0 {
+		return &LoginThrottledError{Locked: true, RetryAfter: ttl}
+	}
+	if ttl := delayTTL.Val(); ttl > 0 {
+		return &LoginThrottledError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and locks the account once the threshold is reached
+func (t *LoginAttemptTracker) RecordFailure(ctx context.Context, userID string) (*FailedLogin, error) {
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	args := []any{
+		failedLoginWindow.Milliseconds(),
+		failedLoginThreshold,
+		hex.EncodeToString(member),
+		loginDelayBase.Milliseconds(),
+		loginDelayMax.Milliseconds(),
+		lockoutLevelTTL.Milliseconds(),
+	}
+	for _, window := range lockoutWindows {
+		args = append(args, window.Milliseconds())
+	}
+
+	values, err := recordFailureScript.Run(ctx, t.client, t.keys(userID), args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	result := &FailedLogin{Failures: int(values[0])}
+	if values[1] > 0 {
+		result.LockedFor = time.Duration(values[1]) * time.Millisecond
+	} else {
+		result.Delay = time.Duration(values[2]) * time.Millisecond
+	}
+	return result, nil
+}
+
+// Reset forgets the failures of a user after a successful login. The lockout level is kept until
+// it expires, so an attacker spacing out guesses still meets longer lockouts.
+func (t *LoginAttemptTracker) Reset(ctx context.Context, userID string) error {
+	keys := t.keys(userID)
+	if err := t.client.Del(ctx, keys[0], keys[1]).Err(); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+// Unlock lifts a lockout and clears all failure history of the user
+func (t *LoginAttemptTracker) Unlock(ctx context.Context, userID string) error {
+	if err := t.client.Del(ctx, t.keys(userID)...).Err(); err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tracker keeps its counters in Lua scripts run by Redis, so its tests need a server. They run
+// against the Redis at REDIS_TEST_ADDR, e.g. "localhost:6379", and are skipped without one.
+func newTestLoginAttemptTracker(t *testing.T) (*LoginAttemptTracker, string) {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	tracker := NewLoginAttemptTracker(client)
+	userID := uuid.New().String()
+	t.Cleanup(func() { tracker.Unlock(context.Background(), userID) })
+	return tracker, userID
+}
+
+func throttled(t *testing.T, err error) *LoginThrottledError {
+	t.Helper()
+	var throttledErr *LoginThrottledError
+	require.True(t, errors.As(err, &throttledErr), "expected LoginThrottledError, got %v", err)
+	return throttledErr
+}
+
+func TestLoginAttemptTracker_DelaysUntilThreshold(t *testing.T) {
+	tracker, userID := newTestLoginAttemptTracker(t)
+	ctx := context.Background()
+
+	require.NoError(t, tracker.Check(ctx, userID))
+
+	tests := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{failures: 1, delay: 1 * time.Second},
+		{failures: 2, delay: 2 * time.Second},
+		{failures: 3, delay: 4 * time.Second},
+		{failures: 4, delay: 8 * time.Second},
+	}
+	for _, tt := range tests {
+		result, err := tracker.RecordFailure(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, tt.failures, result.Failures)
+		assert.Equal(t, tt.delay, result.Delay)
+		assert.Zero(t, result.LockedFor)
+
+		throttledErr := throttled(t, tracker.Check(ctx, userID))
+		assert.False(t, throttledErr.Locked)
+		assert.LessOrEqual(t, throttledErr.RetryAfter, tt.delay)
+	}
+}
+
+func TestLoginAttemptTracker_LocksAtThreshold(t *testing.T) {
+	tracker, userID := newTestLoginAttemptTracker(t)
+	ctx := context.Background()
+
+	var result *FailedLogin
+	for range failedLoginThreshold {
+		var err error
+		result, err = tracker.RecordFailure(ctx, userID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, failedLoginThreshold, result.Failures)
+	assert.Equal(t, lockoutWindows[0], result.LockedFor)
+
+	throttledErr := throttled(t, tracker.Check(ctx, userID))
+	assert.True(t, throttledErr.Locked)
+	assert.LessOrEqual(t, throttledErr.RetryAfter, lockoutWindows[0])
+	assert.Greater(t, throttledErr.RetryAfter, lockoutWindows[0]-time.Minute)
+}
+
+func TestLoginAttemptTracker_RepeatedLockoutsGrow(t *testing.T) {
+	tracker, userID := newTestLoginAttemptTracker(t)
+	ctx := context.Background()
+
+	for level := range len(lockoutWindows) + 1 {
+		var result *FailedLogin
+		for range failedLoginThreshold {
+			var err error
+			result, err = tracker.RecordFailure(ctx, userID)
+			require.NoError(t, err)
+		}
+		// The last window repeats once every level was reached
+		want := lockoutWindows[min(level, len(lockoutWindows)-1)]
+		assert.Equal(t, want, result.LockedFor, "lockout %d", level+1)
+	}
+}
+
+func TestLoginAttemptTracker_ResetKeepsLockoutLevel(t *testing.T) {
+	tracker, userID := newTestLoginAttemptTracker(t)
+	ctx := context.Background()
+
+	for range failedLoginThreshold {
+		_, err := tracker.RecordFailure(ctx, userID)
+		require.NoError(t, err)
+	}
+	_, err := tracker.RecordFailure(ctx, userID)
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.Reset(ctx, userID))
+	// The lock itself is only lifted by Unlock or its expiry
+	assert.True(t, throttled(t, tracker.Check(ctx, userID)).Locked)
+
+	result, err := tracker.RecordFailure(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failures, "reset forgets the failures")
+
+	for range failedLoginThreshold - 1 {
+		result, err = tracker.RecordFailure(ctx, userID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, lockoutWindows[1], result.LockedFor, "reset keeps the lockout level")
+}
+
+func TestLoginAttemptTracker_Unlock(t *testing.T) {
+	tracker, userID := newTestLoginAttemptTracker(t)
+	ctx := context.Background()
+
+	for range failedLoginThreshold {
+		_, err := tracker.RecordFailure(ctx, userID)
+		require.NoError(t, err)
+	}
+	require.Error(t, tracker.Check(ctx, userID))
+
+	require.NoError(t, tracker.Unlock(ctx, userID))
+	assert.NoError(t, tracker.Check(ctx, userID))
+
+	result, err := tracker.RecordFailure(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failures)
+
+	for range failedLoginThreshold - 1 {
+		result, err = tracker.RecordFailure(ctx, userID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, lockoutWindows[0], result.LockedFor, "unlock clears the lockout level")
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *LoginThrottledError
+		want string
+	}{
+		{
+			name: "locked",
+			err:  &LoginThrottledError{Locked: true, RetryAfter: 5*time.Minute - 300*time.Millisecond},
+			want: "account blocked due to too many failed login attempts, retry after 5m0s",
+		},
+		{
+			name: "delayed",
+			err:  &LoginThrottledError{RetryAfter: 1600 * time.Millisecond},
+			want: "too many login attempts, retry after 2s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+}
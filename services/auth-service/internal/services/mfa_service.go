@@ -0,0 +1,439 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	mfaIssuer = "Agrisa"
+
+	recoveryCodeCount = 10
+	// Lowercase base32 without padding, printed as two groups of 5
+	recoveryCodeLength = 10
+
+	// How long a user required to use MFA has to enroll after a rejected login
+	mfaEnrollmentTicketTTL = 10 * time.Minute
+)
+
+var recoveryCodeEncoding = totpEncoding
+
+// MFAEnrollmentRequiredError is returned by a login of a user whose roles require MFA but who has
+// not enrolled yet. The ticket lets the user enroll without a session.
+type MFAEnrollmentRequiredError struct {
+	Ticket string
+}
+
+func (e *MFAEnrollmentRequiredError) Error() string {
+	return "mfa enrollment required"
+}
+
+// MFAService manages the TOTP second factor of users: enrollment, verification at login,
+// recovery codes, and the per-role policy requiring it
+type MFAService struct {
+	mfaRepo     repository.IMFARepository
+	userRepo    repository.IUserRepository
+	roleService *RoleService
+	redisClient *redis.Client
+	aead        cipher.AEAD
+}
+
+// NewMFAService creates the service; secrets are encrypted with AES-256-GCM under the SHA-256 of
+// encryptionKey
+func NewMFAService(mfaRepo repository.IMFARepository, userRepo repository.IUserRepository, roleService *RoleService, redisClient *redis.Client, encryptionKey string) (*MFAService, error) {
+	if encryptionKey == "" {
+		return nil, fmt.Errorf("no mfa encryption key configured")
+	}
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mfa cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mfa cipher: %w", err)
+	}
+	return &MFAService{
+		mfaRepo:     mfaRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+		redisClient: redisClient,
+		aead:        aead,
+	}, nil
+}
+
+func (s *MFAService) encryptSecret(userID, secret string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The user ID is authenticated with the secret, so a ciphertext moved to another user fails
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), []byte(userID))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *MFAService) decryptSecret(userID, ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", fmt.Errorf("invalid mfa secret ciphertext")
+	}
+	nonce, data := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, data, []byte(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt mfa secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// IsRequiredForRoles reports whether a policy requires MFA for any of the roles
+func (s *MFAService) IsRequiredForRoles(roleNames []string) (bool, error) {
+	policies, err := s.mfaRepo.GetMFARolePolicies()
+	if err != nil {
+		return false, err
+	}
+	for _, policy := range policies {
+		if !policy.Required {
+			continue
+		}
+		for _, name := range roleNames {
+			if name == policy.RoleName {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// userRoleNames returns the roles of the user, unscoped and within any scope
+func (s *MFAService) userRoleNames(userID string) ([]string, error) {
+	roles, err := s.roleService.GetUserRoles(userID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	scopes, err := s.roleService.GetUserRoleScopes(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user role scopes: %w", err)
+	}
+	return MFARoleNames(roles, scopes), nil
+}
+
+// MFARoleNames lists the names of the roles and scoped roles a MFA policy is matched against
+func MFARoleNames(roles []*models.Role, scopes []models.RoleScope) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	for _, scope := range scopes {
+		names = append(names, scope.Roles...)
+	}
+	return names
+}
+
+// IsEnabled reports whether the user has a confirmed second factor
+func (s *MFAService) IsEnabled(userID string) (bool, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(userID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "not_found") {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.Enabled, nil
+}
+
+func (s *MFAService) GetStatus(userID string) (*models.MFAStatus, error) {
+	roleNames, err := s.userRoleNames(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.IsRequiredForRoles(roleNames)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.MFAStatus{Required: required}
+
+	mfa, err := s.mfaRepo.GetUserMFA(userID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "not_found") {
+			return status, nil
+		}
+		return nil, err
+	}
+	if !mfa.Enabled {
+		return status, nil
+	}
+	status.Enabled = true
+	status.ConfirmedAt = mfa.ConfirmedAt
+	status.RecoveryCodesLeft, err = s.mfaRepo.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// BeginEnrollment creates a new secret for the user, pending until ConfirmEnrollment
+func (s *MFAService) BeginEnrollment(userID string) (*models.MFAEnrollment, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("not_found: user not found")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := s.encryptSecret(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SavePendingMFA(userID, ciphertext); err != nil {
+		return nil, err
+	}
+
+	accountName := user.Email
+	if accountName == "" {
+		accountName = user.PhoneNumber
+	}
+	return &models.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(mfaIssuer, accountName, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables the pending secret with a code from the authenticator app and returns
+// the recovery codes, which are not shown again
+func (s *MFAService) ConfirmEnrollment(userID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, fmt.Errorf("conflict: mfa is already enabled")
+	}
+	secret, err := s.decryptSecret(userID, mfa.SecretCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := verifyTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid mfa code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.EnableMFA(userID, step, hashes); err != nil {
+		return nil, err
+	}
+	slog.Info("MFA enabled", "user_id", userID)
+	return codes, nil
+}
+
+// Verify checks a code from the authenticator app, or spends a recovery code
+func (s *MFAService) Verify(userID, code string) error {
+	mfa, err := s.mfaRepo.GetUserMFA(userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled {
+		return fmt.Errorf("not_found: mfa is not enabled")
+	}
+
+	if len(strings.TrimSpace(code)) == totpDigits {
+		return s.verifyTOTPCode(mfa, code)
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("invalid mfa code")
+	}
+	slog.Info("MFA recovery code used", "user_id", userID)
+	return nil
+}
+
+func (s *MFAService) verifyTOTPCode(mfa *models.UserMFA, code string) error {
+	secret, err := s.decryptSecret(mfa.UserID, mfa.SecretCiphertext)
+	if err != nil {
+		return err
+	}
+	step, ok := verifyTOTP(secret, code, time.Now())
+	if !ok {
+		return fmt.Errorf("invalid mfa code")
+	}
+	fresh, err := s.mfaRepo.UseStep(mfa.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return fmt.Errorf("invalid mfa code: already used")
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user, confirmed with a code from the
+// authenticator app
+func (s *MFAService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.Enabled {
+		return nil, fmt.Errorf("not_found: mfa is not enabled")
+	}
+	if err := s.verifyTOTPCode(mfa, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable removes the second factor of the user, unless a role of the user requires it
+func (s *MFAService) Disable(userID, code string) error {
+	roleNames, err := s.userRoleNames(userID)
+	if err != nil {
+		return err
+	}
+	required, err := s.IsRequiredForRoles(roleNames)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("forbidden: mfa is required for your role")
+	}
+
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DeleteMFA(userID); err != nil {
+		return err
+	}
+	slog.Info("MFA disabled", "user_id", userID)
+	return nil
+}
+
+func (s *MFAService) ticketKey(ticket string) string {
+	return fmt.Sprintf("mfa:enrollment-ticket:%s", ticket)
+}
+
+// IssueEnrollmentTicket lets a user who must use MFA enroll after a rejected login
+func (s *MFAService) IssueEnrollmentTicket(ctx context.Context, userID string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate enrollment ticket: %w", err)
+	}
+	ticket := hex.EncodeToString(raw)
+	if err := s.redisClient.Set(ctx, s.ticketKey(ticket), userID, mfaEnrollmentTicketTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store enrollment ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+func (s *MFAService) ticketUser(ctx context.Context, ticket string) (string, error) {
+	userID, err := s.redisClient.Get(ctx, s.ticketKey(ticket)).Result()
+	if err == redis.Nil {
+		return "", fmt.Errorf("invalid enrollment ticket")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get enrollment ticket: %w", err)
+	}
+	return userID, nil
+}
+
+func (s *MFAService) BeginEnrollmentWithTicket(ctx context.Context, ticket string) (*models.MFAEnrollment, error) {
+	userID, err := s.ticketUser(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	return s.BeginEnrollment(userID)
+}
+
+// ConfirmEnrollmentWithTicket confirms the enrollment and spends the ticket; the user then logs
+// in again with a code
+func (s *MFAService) ConfirmEnrollmentWithTicket(ctx context.Context, ticket, code string) ([]string, error) {
+	userID, err := s.ticketUser(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.ConfirmEnrollment(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redisClient.Del(ctx, s.ticketKey(ticket)).Err(); err != nil {
+		slog.Error("failed to delete mfa enrollment ticket", "user_id", userID, "error", err)
+	}
+	return codes, nil
+}
+
+func (s *MFAService) GetRolePolicies() ([]*models.MFARolePolicy, error) {
+	return s.mfaRepo.GetMFARolePolicies()
+}
+
+// SetRolePolicy requires MFA, or stops requiring it, for a role; only global admins may
+func (s *MFAService) SetRolePolicy(actorID, roleName string, required bool) (*models.MFARolePolicy, error) {
+	isAdmin, err := s.roleService.isGlobalAdmin(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check admin role: %w", err)
+	}
+	if !isAdmin {
+		return nil, fmt.Errorf("forbidden: only admins can change mfa policies")
+	}
+	if _, err := s.roleService.GetRoleByName(roleName); err != nil {
+		return nil, fmt.Errorf("not_found: role %s not found", roleName)
+	}
+
+	policy, err := s.mfaRepo.SetMFARolePolicy(roleName, required, actorID)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("MFA role policy changed", "role", roleName, "required", required, "updated_by", actorID)
+	return policy, nil
+}
+
+// generateRecoveryCodes returns the codes to show the user and the hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))[:recoveryCodeLength]
+		code := encoded[:recoveryCodeLength/2] + "-" + encoded[recoveryCodeLength/2:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code as typed, ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
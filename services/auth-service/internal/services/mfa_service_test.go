@@ -0,0 +1,350 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMFARepository keeps the MFA state of users in memory, with the same replay and single use
+// rules as the SQL repository
+type fakeMFARepository struct {
+	mfa           map[string]*models.UserMFA
+	recoveryCodes map[string]map[string]bool
+	policies      []*models.MFARolePolicy
+}
+
+func newFakeMFARepository() *fakeMFARepository {
+	return &fakeMFARepository{
+		mfa:           map[string]*models.UserMFA{},
+		recoveryCodes: map[string]map[string]bool{},
+	}
+}
+
+func (r *fakeMFARepository) SavePendingMFA(userID, secretCiphertext string) error {
+	r.mfa[userID] = &models.UserMFA{UserID: userID, SecretCiphertext: secretCiphertext}
+	return nil
+}
+
+func (r *fakeMFARepository) GetUserMFA(userID string) (*models.UserMFA, error) {
+	mfa, ok := r.mfa[userID]
+	if !ok {
+		return nil, fmt.Errorf("not_found: mfa is not set up")
+	}
+	copied := *mfa
+	return &copied, nil
+}
+
+func (r *fakeMFARepository) EnableMFA(userID string, step int64, recoveryCodeHashes []string) error {
+	mfa, ok := r.mfa[userID]
+	if !ok || mfa.Enabled {
+		return fmt.Errorf("conflict: mfa is already enabled")
+	}
+	mfa.Enabled = true
+	mfa.LastUsedStep = step
+	return r.ReplaceRecoveryCodes(userID, recoveryCodeHashes)
+}
+
+func (r *fakeMFARepository) UseStep(userID string, step int64) (bool, error) {
+	mfa := r.mfa[userID]
+	if mfa.LastUsedStep >= step {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeMFARepository) ReplaceRecoveryCodes(userID string, recoveryCodeHashes []string) error {
+	r.recoveryCodes[userID] = map[string]bool{}
+	for _, hash := range recoveryCodeHashes {
+		r.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *fakeMFARepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	used, ok := r.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+func (r *fakeMFARepository) CountUnusedRecoveryCodes(userID string) (int, error) {
+	count := 0
+	for _, used := range r.recoveryCodes[userID] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeMFARepository) DeleteMFA(userID string) error {
+	delete(r.mfa, userID)
+	delete(r.recoveryCodes, userID)
+	return nil
+}
+
+func (r *fakeMFARepository) GetMFARolePolicies() ([]*models.MFARolePolicy, error) {
+	return r.policies, nil
+}
+
+func (r *fakeMFARepository) SetMFARolePolicy(roleName string, required bool, updatedBy string) (*models.MFARolePolicy, error) {
+	policy := &models.MFARolePolicy{RoleName: roleName, Required: required}
+	r.policies = append(r.policies, policy)
+	return policy, nil
+}
+
+// enrolledMFAService returns a service with the user enrolled under a new secret, the secret and
+// the recovery codes handed out at enrollment
+func enrolledMFAService(t *testing.T, userID string) (*MFAService, *fakeMFARepository, string, []string) {
+	t.Helper()
+	repo := newFakeMFARepository()
+	service, err := NewMFAService(repo, nil, nil, nil, "test-mfa-key")
+	require.NoError(t, err)
+
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+	ciphertext, err := service.encryptSecret(userID, secret)
+	require.NoError(t, err)
+	require.NoError(t, repo.SavePendingMFA(userID, ciphertext))
+
+	// Enroll with the code of the previous step, so the current one is still unused
+	code, err := totpCode(secret, totpStep(time.Now())-1)
+	require.NoError(t, err)
+	recoveryCodes, err := service.ConfirmEnrollment(userID, code)
+	require.NoError(t, err)
+	return service, repo, secret, recoveryCodes
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totpCode(secret, totpStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestNewMFAService_RequiresKey(t *testing.T) {
+	_, err := NewMFAService(newFakeMFARepository(), nil, nil, nil, "")
+	assert.Error(t, err, "secrets must not be encrypted under a key anyone can derive")
+}
+
+func TestMFAService_SecretBoundToUser(t *testing.T) {
+	service, err := NewMFAService(newFakeMFARepository(), nil, nil, nil, "test-mfa-key")
+	require.NoError(t, err)
+
+	ciphertext, err := service.encryptSecret("user-1", rfcTOTPSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, ciphertext, rfcTOTPSecret)
+
+	secret, err := service.decryptSecret("user-1", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, rfcTOTPSecret, secret)
+
+	_, err = service.decryptSecret("user-2", ciphertext)
+	assert.Error(t, err, "a secret moved to another user must not decrypt")
+
+	other, err := NewMFAService(newFakeMFARepository(), nil, nil, nil, "other-mfa-key")
+	require.NoError(t, err)
+	_, err = other.decryptSecret("user-1", ciphertext)
+	assert.Error(t, err, "a secret must not decrypt under another key")
+}
+
+func TestMFAService_ConfirmEnrollment(t *testing.T) {
+	service, repo, _, recoveryCodes := enrolledMFAService(t, "user-1")
+
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	enabled, err := service.IsEnabled("user-1")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	left, err := repo.CountUnusedRecoveryCodes("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodeCount, left)
+
+	_, err = service.ConfirmEnrollment("user-1", "000000")
+	assert.ErrorContains(t, err, "conflict:")
+}
+
+func TestMFAService_ConfirmEnrollmentRejectsWrongCode(t *testing.T) {
+	repo := newFakeMFARepository()
+	service, err := NewMFAService(repo, nil, nil, nil, "test-mfa-key")
+	require.NoError(t, err)
+	ciphertext, err := service.encryptSecret("user-1", rfcTOTPSecret)
+	require.NoError(t, err)
+	require.NoError(t, repo.SavePendingMFA("user-1", ciphertext))
+
+	_, err = service.ConfirmEnrollment("user-1", "12345")
+	assert.ErrorContains(t, err, "invalid mfa code")
+	enabled, err := service.IsEnabled("user-1")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestMFAService_VerifyTOTPReplay(t *testing.T) {
+	service, _, secret, _ := enrolledMFAService(t, "user-1")
+	code := currentTOTPCode(t, secret)
+
+	require.NoError(t, service.Verify("user-1", code))
+	assert.ErrorContains(t, service.Verify("user-1", code), "already used")
+}
+
+func TestMFAService_VerifyRejectsCodeUsedAtEnrollment(t *testing.T) {
+	repo := newFakeMFARepository()
+	service, err := NewMFAService(repo, nil, nil, nil, "test-mfa-key")
+	require.NoError(t, err)
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+	ciphertext, err := service.encryptSecret("user-1", secret)
+	require.NoError(t, err)
+	require.NoError(t, repo.SavePendingMFA("user-1", ciphertext))
+
+	code := currentTOTPCode(t, secret)
+	_, err = service.ConfirmEnrollment("user-1", code)
+	require.NoError(t, err)
+	assert.ErrorContains(t, service.Verify("user-1", code), "already used")
+}
+
+func TestMFAService_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		code    func(secret string, recoveryCodes []string) string
+		wantErr string
+	}{
+		{
+			name:   "current totp code",
+			userID: "user-1",
+			code: func(secret string, _ []string) string {
+				code, _ := totpCode(secret, totpStep(time.Now()))
+				return code
+			},
+		},
+		{
+			name:   "totp code outside the window",
+			userID: "user-1",
+			code: func(secret string, _ []string) string {
+				code, _ := totpCode(secret, totpStep(time.Now())-5)
+				return code
+			},
+			wantErr: "invalid mfa code",
+		},
+		{
+			name:   "recovery code",
+			userID: "user-1",
+			code:   func(_ string, recoveryCodes []string) string { return recoveryCodes[0] },
+		},
+		{
+			name:   "recovery code typed in upper case without dash",
+			userID: "user-1",
+			code: func(_ string, recoveryCodes []string) string {
+				return strings.ToUpper(strings.ReplaceAll(recoveryCodes[1], "-", ""))
+			},
+		},
+		{
+			name:    "unknown recovery code",
+			userID:  "user-1",
+			code:    func(string, []string) string { return "aaaaa-bbbbb" },
+			wantErr: "invalid mfa code",
+		},
+		{
+			name:    "user without mfa",
+			userID:  "user-2",
+			code:    func(string, []string) string { return "123456" },
+			wantErr: "not_found:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, secret, recoveryCodes := enrolledMFAService(t, "user-1")
+			err := service.Verify(tt.userID, tt.code(secret, recoveryCodes))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMFAService_RecoveryCodeSingleUse(t *testing.T) {
+	service, repo, _, recoveryCodes := enrolledMFAService(t, "user-1")
+
+	require.NoError(t, service.Verify("user-1", recoveryCodes[0]))
+	assert.ErrorContains(t, service.Verify("user-1", recoveryCodes[0]), "invalid mfa code")
+
+	left, err := repo.CountUnusedRecoveryCodes("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodeCount-1, left)
+}
+
+func TestMFAService_IsRequiredForRoles(t *testing.T) {
+	repo := newFakeMFARepository()
+	repo.policies = []*models.MFARolePolicy{
+		{RoleName: models.AdminRoleName, Required: true},
+		{RoleName: "farmer", Required: false},
+	}
+	service, err := NewMFAService(repo, nil, nil, nil, "test-mfa-key")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		roles    []string
+		required bool
+	}{
+		{name: "required role", roles: []string{"user_default", models.AdminRoleName}, required: true},
+		{name: "role with policy not requiring mfa", roles: []string{"farmer"}},
+		{name: "role without policy", roles: []string{models.AdminPartnerRoleName}},
+		{name: "no roles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			required, err := service.IsRequiredForRoles(tt.roles)
+			require.NoError(t, err)
+			assert.Equal(t, tt.required, required)
+		})
+	}
+}
+
+func TestMFARoleNames(t *testing.T) {
+	roles := []*models.Role{{Name: "user_default"}}
+	scopes := []models.RoleScope{{
+		ScopeType: models.ScopeTypeInsuranceProvider,
+		ScopeID:   "provider-1",
+		Roles:     []string{models.AdminPartnerRoleName},
+	}}
+
+	assert.Equal(t, []string{"user_default", models.AdminPartnerRoleName}, MFARoleNames(roles, scopes))
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for i, code := range codes {
+		assert.Len(t, code, recoveryCodeLength+1)
+		assert.Equal(t, byte('-'), code[recoveryCodeLength/2])
+		assert.Equal(t, hashRecoveryCode(code), hashes[i])
+		assert.False(t, seen[code], "recovery codes must be unique")
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode_Normalizes(t *testing.T) {
+	want := hashRecoveryCode("abcde-fghij")
+	for _, typed := range []string{"abcdefghij", "ABCDE-FGHIJ", "abcde fghij", " abcde-fghij "} {
+		assert.Equal(t, want, hashRecoveryCode(typed), typed)
+	}
+	assert.NotEqual(t, want, hashRecoveryCode("abcde-fghik"))
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidRSASSAPSS     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+)
+
+var chipDigestAlgorithms = map[string]crypto.Hash{
+	"1.3.14.3.2.26":          crypto.SHA1,
+	"2.16.840.1.101.3.4.2.4": crypto.SHA224,
+	"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+	"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+	"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+}
+
+// ASN.1 structures of the document security object (ICAO 9303 part 10), a CMS
+// SignedData whose content lists the hash of every data group on the chip
+type chipContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type chipSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo chipEncapContentInfo
+	Certificates     asn1.RawValue    `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue    `asn1:"optional,tag:1"`
+	SignerInfos      []chipSignerInfo `asn1:"set"`
+}
+
+type chipEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,tag:0"`
+}
+
+type chipSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type chipAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type ldsSecurityObject struct {
+	Version             int
+	HashAlgorithm       pkix.AlgorithmIdentifier
+	DataGroupHashValues []ldsDataGroupHash
+	LDSVersionInfo      asn1.RawValue `asn1:"optional"`
+}
+
+type ldsDataGroupHash struct {
+	DataGroupNumber    int
+	DataGroupHashValue []byte
+}
+
+// ChipVerifier performs passive authentication of CCCD chip data: the document
+// signer certificate must be issued by a trusted CSCA certificate, the SOD
+// signature must be valid and every data group must match its signed hash.
+type ChipVerifier struct {
+	cscaCerts []*x509.Certificate
+}
+
+// NewChipVerifier loads the issuing authority (CSCA) certificates from certDir.
+// Both PEM and DER files are accepted; unreadable files are skipped.
+func NewChipVerifier(certDir string) *ChipVerifier {
+	verifier := &ChipVerifier{}
+	if certDir == "" {
+		slog.Warn("CSCA certificate directory not configured, NFC chip verification is disabled")
+		return verifier
+	}
+
+	entries, err := os.ReadDir(certDir)
+	if err != nil {
+		slog.Error("failed to read CSCA certificate directory", "dir", certDir, "error", err)
+		return verifier
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(certDir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Error("failed to read CSCA certificate", "file", path, "error", err)
+			continue
+		}
+		certs, err := parseCertificateFile(data)
+		if err != nil {
+			slog.Error("failed to parse CSCA certificate", "file", path, "error", err)
+			continue
+		}
+		verifier.cscaCerts = append(verifier.cscaCerts, certs...)
+	}
+
+	slog.Info("loaded CSCA certificates", "count", len(verifier.cscaCerts))
+	return verifier
+}
+
+func parseCertificateFile(data []byte) ([]*x509.Certificate, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		return x509.ParseCertificates(data)
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// Enabled reports whether any trusted CSCA certificate is loaded
+func (v *ChipVerifier) Enabled() bool {
+	return len(v.cscaCerts) > 0
+}
+
+// Verify authenticates the SOD and data groups read from the chip and returns the identity in DG1.
+// dataGroups is keyed by data group number and must contain DG1.
+func (v *ChipVerifier) Verify(sod []byte, dataGroups map[int][]byte) (*models.NFCChipData, error) {
+	if !v.Enabled() {
+		return nil, fmt.Errorf("unavailable: no trusted CSCA certificates configured")
+	}
+	if len(dataGroups[1]) == 0 {
+		return nil, fmt.Errorf("bad_request: DG1 is required")
+	}
+
+	signedData, err := parseSOD(sod)
+	if err != nil {
+		return nil, fmt.Errorf("bad_request: invalid SOD: %w", err)
+	}
+
+	dsCert, err := v.verifySignedData(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized: %w", err)
+	}
+
+	var securityObject ldsSecurityObject
+	if _, err := asn1.Unmarshal(signedData.EncapContentInfo.EContent, &securityObject); err != nil {
+		return nil, fmt.Errorf("bad_request: invalid LDS security object: %w", err)
+	}
+	if err := verifyDataGroupHashes(&securityObject, dataGroups); err != nil {
+		return nil, fmt.Errorf("unauthorized: %w", err)
+	}
+
+	chipData, err := parseDG1(dataGroups[1])
+	if err != nil {
+		return nil, fmt.Errorf("bad_request: %w", err)
+	}
+	chipData.SignerSubject = dsCert.Subject.String()
+	return chipData, nil
+}
+
+func parseSOD(sod []byte) (*chipSignedData, error) {
+	// The SOD file is wrapped in application tag 23 (0x77)
+	var wrapper asn1.RawValue
+	if _, err := asn1.Unmarshal(sod, &wrapper); err != nil {
+		return nil, err
+	}
+	content := sod
+	if wrapper.Class == asn1.ClassApplication && wrapper.Tag == 23 {
+		content = wrapper.Bytes
+	}
+
+	var contentInfo chipContentInfo
+	if _, err := asn1.Unmarshal(content, &contentInfo); err != nil {
+		return nil, err
+	}
+	if !contentInfo.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unexpected content type %s", contentInfo.ContentType)
+	}
+
+	var signedData chipSignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+	if len(signedData.SignerInfos) != 1 {
+		return nil, fmt.Errorf("expected one signer, got %d", len(signedData.SignerInfos))
+	}
+	return &signedData, nil
+}
+
+// verifySignedData checks the document signer certificate against the CSCA
+// certificates and the SOD signature against the document signer key
+func (v *ChipVerifier) verifySignedData(signedData *chipSignedData) (*x509.Certificate, error) {
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil || len(certs) == 0 {
+		return nil, fmt.Errorf("missing document signer certificate")
+	}
+	dsCert := certs[0]
+
+	if err := v.verifyDocumentSigner(dsCert); err != nil {
+		return nil, err
+	}
+
+	signer := signedData.SignerInfos[0]
+	hash, ok := chipDigestAlgorithms[signer.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %s", signer.DigestAlgorithm.Algorithm)
+	}
+	if len(signer.SignedAttrs.FullBytes) == 0 {
+		return nil, fmt.Errorf("missing signed attributes")
+	}
+
+	// The signature covers the signed attributes re-tagged as a SET
+	signedAttrs := append([]byte{}, signer.SignedAttrs.FullBytes...)
+	signedAttrs[0] = 0x31
+
+	var attrs []chipAttribute
+	if _, err := asn1.UnmarshalWithParams(signedAttrs, &attrs, "set"); err != nil {
+		return nil, fmt.Errorf("invalid signed attributes: %w", err)
+	}
+	var messageDigest []byte
+	for _, attr := range attrs {
+		if attr.Type.Equal(oidMessageDigest) {
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &messageDigest); err != nil {
+				return nil, fmt.Errorf("invalid message digest attribute: %w", err)
+			}
+		}
+	}
+	if !bytes.Equal(messageDigest, digest(hash, signedData.EncapContentInfo.EContent)) {
+		return nil, fmt.Errorf("security object digest mismatch")
+	}
+
+	if err := verifySignature(dsCert.PublicKey, signer.SignatureAlgorithm.Algorithm, hash, signedAttrs, signer.Signature); err != nil {
+		return nil, err
+	}
+	return dsCert, nil
+}
+
+func (v *ChipVerifier) verifyDocumentSigner(dsCert *x509.Certificate) error {
+	// Document signer certificates expire long before the cards they signed,
+	// so the chain is checked at the time the certificate was issued
+	for _, csca := range v.cscaCerts {
+		if !bytes.Equal(dsCert.RawIssuer, csca.RawSubject) {
+			continue
+		}
+		if dsCert.NotBefore.Before(csca.NotBefore) || dsCert.NotBefore.After(csca.NotAfter) {
+			continue
+		}
+		if err := dsCert.CheckSignatureFrom(csca); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("document signer certificate is not issued by a trusted CSCA")
+}
+
+func verifySignature(publicKey any, algorithm asn1.ObjectIdentifier, hash crypto.Hash, signed, signature []byte) error {
+	hashed := digest(hash, signed)
+
+	var err error
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if algorithm.Equal(oidRSASSAPSS) {
+			err = rsa.VerifyPSS(key, hash, hashed, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		} else {
+			err = rsa.VerifyPKCS1v15(key, hash, hashed, signature)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hashed, signature) {
+			err = fmt.Errorf("ecdsa verification failed")
+		}
+	default:
+		return fmt.Errorf("unsupported document signer key type %T", publicKey)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid SOD signature: %w", err)
+	}
+	return nil
+}
+
+func verifyDataGroupHashes(securityObject *ldsSecurityObject, dataGroups map[int][]byte) error {
+	hash, ok := chipDigestAlgorithms[securityObject.HashAlgorithm.Algorithm.String()]
+	if !ok {
+		return fmt.Errorf("unsupported data group hash algorithm %s", securityObject.HashAlgorithm.Algorithm)
+	}
+
+	signedHashes := make(map[int][]byte, len(securityObject.DataGroupHashValues))
+	for _, dgHash := range securityObject.DataGroupHashValues {
+		signedHashes[dgHash.DataGroupNumber] = dgHash.DataGroupHashValue
+	}
+
+	for number, content := range dataGroups {
+		if len(content) == 0 {
+			continue
+		}
+		expected, ok := signedHashes[number]
+		if !ok {
+			return fmt.Errorf("DG%d is not covered by the SOD", number)
+		}
+		if !bytes.Equal(expected, digest(hash, content)) {
+			return fmt.Errorf("DG%d hash mismatch", number)
+		}
+	}
+	return nil
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// parseDG1 reads the TD1 machine readable zone stored in DG1. On a CCCD the
+// optional data of the first line starts with the full 12-digit ID number.
+func parseDG1(dg1 []byte) (*models.NFCChipData, error) {
+	var outer asn1.RawValue
+	if _, err := asn1.Unmarshal(dg1, &outer); err != nil {
+		return nil, fmt.Errorf("invalid DG1: %w", err)
+	}
+	var mrzValue asn1.RawValue
+	if _, err := asn1.Unmarshal(outer.Bytes, &mrzValue); err != nil {
+		return nil, fmt.Errorf("invalid DG1 MRZ: %w", err)
+	}
+
+	mrz := string(mrzValue.Bytes)
+	if len(mrz) != 90 {
+		return nil, fmt.Errorf("unsupported MRZ length %d", len(mrz))
+	}
+	line1, line2, line3 := mrz[0:30], mrz[30:60], mrz[60:90]
+
+	optionalData := strings.TrimRight(line1[15:30], "<")
+	nationalID := ""
+	if len(optionalData) >= 12 && isDigits(optionalData[:12]) {
+		nationalID = optionalData[:12]
+	}
+
+	names := strings.SplitN(line3, "<<", 2)
+	fullName := strings.TrimSpace(strings.ReplaceAll(strings.Join(names, " "), "<", " "))
+
+	return &models.NFCChipData{
+		DocumentNumber: strings.TrimRight(line1[5:14], "<"),
+		NationalID:     nationalID,
+		FullName:       strings.Join(strings.Fields(fullName), " "),
+		DateOfBirth:    line2[0:6],
+		Sex:            strings.TrimRight(line2[7:8], "<"),
+		DateOfExpiry:   line2[8:14],
+		IssuingState:   line1[2:5],
+	}, nil
+}
+
+// chipDocumentExpired reports whether the YYMMDD expiry date from the MRZ has passed
+func chipDocumentExpired(dateOfExpiry string, now time.Time) bool {
+	expiry, err := time.Parse("060102", dateOfExpiry)
+	if err != nil {
+		return false
+	}
+	// time.Parse maps 69-99 to the 1900s, but CCCD expiries are all in this century
+	if expiry.Year() < 2000 {
+		expiry = expiry.AddDate(100, 0, 0)
+	}
+	return now.After(expiry.AddDate(0, 0, 1))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -249,3 +249,133 @@ func (s *RoleService) DeleteRoleHierarchy(parentRoleID, childRoleID int) error {
 func (s *RoleService) GetEffectiveRolePermissions(roleID int) ([]*models.Permission, error) {
 	return s.roleRepo.GetEffectiveRolePermissions(roleID)
 }
+
+// isGlobalAdmin reports whether the user holds the unscoped admin role
+func (s *RoleService) isGlobalAdmin(userID string) (bool, error) {
+	roles, err := s.roleRepo.GetUserRoles(userID, true)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.Name == models.AdminRoleName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanManageScope reports whether the actor may manage role assignments within the scope.
+// Global admins manage every scope; partner admins manage only the scope they belong to.
+func (s *RoleService) CanManageScope(actorID, scopeType, scopeID string) (bool, error) {
+	isAdmin, err := s.isGlobalAdmin(actorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin role: %w", err)
+	}
+	if isAdmin {
+		return true, nil
+	}
+	return s.roleRepo.UserHasScopedRole(actorID, models.AdminPartnerRoleName, scopeType, scopeID)
+}
+
+func validateScopeType(scopeType string) error {
+	if scopeType != models.ScopeTypeInsuranceProvider {
+		return fmt.Errorf("bad_request: unsupported scope type %s", scopeType)
+	}
+	return nil
+}
+
+// AssignScopedRoleToUser assigns a role to a user within a scope on behalf of actorID
+func (s *RoleService) AssignScopedRoleToUser(actorID, userID string, roleID int, scopeType, scopeID string, expiresAt *time.Time) error {
+	if err := validateScopeType(scopeType); err != nil {
+		return err
+	}
+	allowed, err := s.CanManageScope(actorID, scopeType, scopeID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("forbidden: not allowed to manage roles in %s %s", scopeType, scopeID)
+	}
+
+	role, err := s.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return fmt.Errorf("not_found: role not found: %w", err)
+	}
+	if !role.IsActive {
+		return fmt.Errorf("bad_request: cannot assign inactive role")
+	}
+	// The platform admin role is global only and never handed out inside a scope
+	if role.Name == models.AdminRoleName {
+		return fmt.Errorf("forbidden: role %s cannot be scoped", role.Name)
+	}
+
+	return s.roleRepo.AssignScopedRoleToUser(userID, roleID, scopeType, scopeID, &actorID, expiresAt)
+}
+
+// RemoveScopedRoleFromUser removes a scoped role from a user on behalf of actorID
+func (s *RoleService) RemoveScopedRoleFromUser(actorID, userID string, roleID int, scopeType, scopeID string) error {
+	if err := validateScopeType(scopeType); err != nil {
+		return err
+	}
+	allowed, err := s.CanManageScope(actorID, scopeType, scopeID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("forbidden: not allowed to manage roles in %s %s", scopeType, scopeID)
+	}
+
+	return s.roleRepo.RemoveScopedRoleFromUser(userID, roleID, scopeType, scopeID)
+}
+
+// GetUserScopedRoles retrieves all scoped role assignments of a user
+func (s *RoleService) GetUserScopedRoles(userID string, activeOnly bool) ([]*models.ScopedUserRole, error) {
+	return s.roleRepo.GetUserScopedRoles(userID, activeOnly)
+}
+
+// GetScopeMembers lists role assignments within a scope, visible only to those who manage it
+func (s *RoleService) GetScopeMembers(actorID, scopeType, scopeID string, activeOnly bool) ([]*models.ScopedUserRole, error) {
+	if err := validateScopeType(scopeType); err != nil {
+		return nil, err
+	}
+	allowed, err := s.CanManageScope(actorID, scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not allowed to view members of %s %s", scopeType, scopeID)
+	}
+
+	return s.roleRepo.GetScopeUsers(scopeType, scopeID, activeOnly)
+}
+
+// UserHasScopedPermission checks if a user has a permission within a scope
+func (s *RoleService) UserHasScopedPermission(userID, scopeType, scopeID, resource, action string) (bool, error) {
+	return s.roleRepo.UserHasScopedPermission(userID, scopeType, scopeID, resource, action)
+}
+
+// GetUserRoleScopes groups a user's active scoped roles into the form embedded in JWT claims
+func (s *RoleService) GetUserRoleScopes(userID string) ([]models.RoleScope, error) {
+	assignments, err := s.roleRepo.GetUserScopedRoles(userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []models.RoleScope{}
+	index := map[string]int{}
+	for _, assignment := range assignments {
+		key := assignment.ScopeType + ":" + assignment.ScopeID
+		i, ok := index[key]
+		if !ok {
+			scopes = append(scopes, models.RoleScope{
+				ScopeType: assignment.ScopeType,
+				ScopeID:   assignment.ScopeID,
+			})
+			i = len(scopes) - 1
+			index[key] = i
+		}
+		scopes[i].Roles = append(scopes[i].Roles, assignment.RoleName)
+	}
+
+	return scopes, nil
+}
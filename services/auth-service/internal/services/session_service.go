@@ -4,12 +4,17 @@ import (
 	"auth-service/internal/models"
 	"auth-service/internal/repository"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// trustedDeviceTTL is how long a device stays trusted after its last OTP verification
+const trustedDeviceTTL = 30 * 24 * time.Hour
+
 // SessionService provides business logic for session management
 type SessionService struct {
 	sessionRepo repository.SessionRepository
@@ -22,8 +27,14 @@ func NewSessionService(sessionRepo repository.SessionRepository) *SessionService
 	}
 }
 
+// HashDeviceFingerprint hashes a client-supplied device fingerprint so raw fingerprints are never stored
+func HashDeviceFingerprint(fingerprint string) string {
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
+
 // CreateSession creates a new user session
-func (s *SessionService) CreateSession(ctx context.Context, userID, tokenHash string, refreshTokenHash *string, deviceInfo, ipAddress *string) (*models.UserSession, error) {
+func (s *SessionService) CreateSession(ctx context.Context, userID, tokenHash string, refreshTokenHash *string, deviceInfo, deviceHash, ipAddress *string) (*models.UserSession, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
@@ -37,6 +48,7 @@ func (s *SessionService) CreateSession(ctx context.Context, userID, tokenHash st
 		TokenHash:        tokenHash,
 		RefreshTokenHash: refreshTokenHash,
 		DeviceInfo:       deviceInfo,
+		DeviceHash:       deviceHash,
 		IPAddress:        ipAddress,
 		CreatedAt:        time.Now(),
 		IsActive:         true,
@@ -114,6 +126,54 @@ func (s *SessionService) GetUserSessions(ctx context.Context, userID string) ([]
 	return s.sessionRepo.GetUserSessions(ctx, userID)
 }
 
+// RevokeUserSession ends one session of a user; sessions of other users are reported as not found
+func (s *SessionService) RevokeUserSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil || session.UserID != userID {
+		return fmt.Errorf("not_found: session not found")
+	}
+
+	return s.sessionRepo.DeleteSession(ctx, sessionID)
+}
+
+// RevokeOtherSessions ends every session of a user except the one given, returning how many ended
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) (int, error) {
+	sessions, err := s.GetUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.sessionRepo.DeleteSession(ctx, session.ID); err != nil {
+			return revoked, fmt.Errorf("failed to delete session: %w", err)
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// RememberDevice records the device of a login and reports whether the account has not logged in
+// from it before. Devices are told apart by fingerprint when the client sends one, otherwise by
+// the device info of the request.
+func (s *SessionService) RememberDevice(ctx context.Context, userID string, deviceHash, deviceInfo *string) (bool, error) {
+	var deviceKey string
+	switch {
+	case deviceHash != nil:
+		deviceKey = *deviceHash
+	case deviceInfo != nil && *deviceInfo != "":
+		deviceKey = HashDeviceFingerprint(*deviceInfo)
+	default:
+		return false, nil
+	}
+
+	return s.sessionRepo.AddKnownDevice(ctx, userID, deviceKey)
+}
+
 // GetSessionCount returns the number of active sessions for a user
 func (s *SessionService) GetSessionCount(ctx context.Context, userID string) (int, error) {
 	sessions, err := s.GetUserSessions(ctx, userID)
@@ -145,3 +205,102 @@ func (s *SessionService) CleanupExpiredSessions(ctx context.Context, userID stri
 
 	return nil
 }
+
+// TrustDevice registers a device as trusted for the user, or refreshes its trust window if it already is
+func (s *SessionService) TrustDevice(ctx context.Context, userID, fingerprint string, deviceName, deviceInfo, ipAddress *string) (*models.TrustedDevice, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+	if fingerprint == "" {
+		return nil, fmt.Errorf("device fingerprint cannot be empty")
+	}
+
+	now := time.Now()
+	fingerprintHash := HashDeviceFingerprint(fingerprint)
+
+	device, err := s.sessionRepo.GetTrustedDeviceByFingerprint(ctx, userID, fingerprintHash)
+	if err != nil {
+		device = &models.TrustedDevice{
+			ID:              uuid.New().String(),
+			UserID:          userID,
+			FingerprintHash: fingerprintHash,
+			TrustedAt:       now,
+		}
+	}
+	if deviceName != nil && *deviceName != "" {
+		device.DeviceName = deviceName
+	}
+	device.DeviceInfo = deviceInfo
+	device.IPAddress = ipAddress
+	device.LastUsedAt = now
+	device.ExpiresAt = now.Add(trustedDeviceTTL)
+
+	if err := s.sessionRepo.SaveTrustedDevice(ctx, device); err != nil {
+		return nil, fmt.Errorf("failed to trust device: %w", err)
+	}
+
+	return device, nil
+}
+
+// IsDeviceTrusted reports whether the fingerprint belongs to one of the user's trusted devices.
+// A trusted device's last-used time is updated on every successful check.
+func (s *SessionService) IsDeviceTrusted(ctx context.Context, userID, fingerprint string) bool {
+	if userID == "" || fingerprint == "" {
+		return false
+	}
+
+	device, err := s.sessionRepo.GetTrustedDeviceByFingerprint(ctx, userID, HashDeviceFingerprint(fingerprint))
+	if err != nil {
+		return false
+	}
+
+	// Failing to record usage should not block a login from a trusted device
+	device.LastUsedAt = time.Now()
+	_ = s.sessionRepo.SaveTrustedDevice(ctx, device)
+
+	return true
+}
+
+// GetTrustedDevices retrieves all trusted devices for a user
+func (s *SessionService) GetTrustedDevices(ctx context.Context, userID string) ([]*models.TrustedDevice, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	return s.sessionRepo.GetUserTrustedDevices(ctx, userID)
+}
+
+// RevokeTrustedDevice removes a trusted device and logs out the sessions opened from it
+func (s *SessionService) RevokeTrustedDevice(ctx context.Context, userID, deviceID string) error {
+	device, err := s.sessionRepo.GetTrustedDevice(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.DeleteTrustedDevice(ctx, userID, deviceID); err != nil {
+		return err
+	}
+
+	sessions, err := s.sessionRepo.GetUserSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.DeviceHash != nil && *session.DeviceHash == device.FingerprintHash {
+			if err := s.sessionRepo.DeleteSession(ctx, session.ID); err != nil {
+				return fmt.Errorf("failed to delete session of revoked device: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RevokeAllTrustedDevices removes every trusted device of a user
+func (s *SessionService) RevokeAllTrustedDevices(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	return s.sessionRepo.DeleteUserTrustedDevices(ctx, userID)
+}
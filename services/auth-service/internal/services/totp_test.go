@@ -0,0 +1,108 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Secret of the RFC 6238 SHA-1 test vectors, "12345678901234567890" in base32
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; the 6 digit code is their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			code, err := totpCode(rfcTOTPSecret, totpStep(time.Unix(tt.unix, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestTOTPCode_LowercaseSecret(t *testing.T) {
+	code, err := totpCode(strings.ToLower(rfcTOTPSecret), totpStep(time.Unix(59, 0)))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+func TestTOTPCode_InvalidSecret(t *testing.T) {
+	_, err := totpCode("not base32!", 1)
+	assert.Error(t, err)
+}
+
+func TestVerifyTOTP_Window(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	current := totpStep(now)
+
+	codeAt := func(step int64) string {
+		code, err := totpCode(rfcTOTPSecret, step)
+		require.NoError(t, err)
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "previous step within skew", code: codeAt(current - totpSkew), wantStep: current - totpSkew, wantOK: true},
+		{name: "next step within skew", code: codeAt(current + totpSkew), wantStep: current + totpSkew, wantOK: true},
+		{name: "surrounding spaces", code: " " + codeAt(current) + " ", wantStep: current, wantOK: true},
+		{name: "too old", code: codeAt(current - totpSkew - 1)},
+		{name: "too far ahead", code: codeAt(current + totpSkew + 1)},
+		{name: "too short", code: codeAt(current)[:totpDigits-1]},
+		{name: "too long", code: codeAt(current) + "0"},
+		{name: "empty", code: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := verifyTOTP(rfcTOTPSecret, tt.code, now)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantStep, step)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+
+	key, err := totpEncoding.DecodeString(secret)
+	require.NoError(t, err)
+	assert.Len(t, key, totpSecretSize)
+
+	other, err := generateTOTPSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := totpProvisioningURI("Agrisa", "farmer@example.com", rfcTOTPSecret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Agrisa:farmer@example.com?"), uri)
+	assert.Contains(t, uri, "secret="+rfcTOTPSecret)
+	assert.Contains(t, uri, "issuer=Agrisa")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
@@ -10,8 +10,10 @@ import (
 	"auth-service/utils"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -19,8 +21,8 @@ import (
 	"mime/multipart"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,18 +31,19 @@ import (
 
 type IUserService interface {
 	RegisterNewUser(phone, email, password, nationalID string, phoneVerificationStatus, isDefault bool) (*models.User, error)
-	Login(email, phone, password string, deviceInfo, ipAddress *string) (*models.User, *models.UserSession, error)
+	Login(email, phone, password string, deviceInfo, ipAddress *string, deviceFingerprint, otp string, trustDevice bool, mfaCode string) (*models.User, *models.UserSession, error)
 	GetUserByID(userID string) (*models.User, error)
 	BanUser(userID string, until int64) error
 	UnbanUser(userID string) error
+	UnlockLogin(ctx context.Context, actorID, userID string) error
 	GetAllUsers(limit, offset int) (*models.GetAllUsersResponse, error)
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserEkycProgressByUserID(userID string) (*models.UserEkycProgress, error)
+	GetEkycProgress(ctx context.Context, userID string) (*models.EkycProgressResponse, error)
+	GetEkycStepEvents(ctx context.Context, userID string) ([]*models.EkycStepEvent, error)
 	UploadToMinIO(c *gin.Context, file io.Reader, header *multipart.FileHeader, serviceName string) error
 	ProcessAndUploadFiles(files map[string][]*multipart.FileHeader, serviceName string, allowedExts []string, maxMB int64) ([]utils.FileInfo, error)
-	OCRNationalIDCard(form *multipart.Form) (any, error)
-	VerifyFaceLiveness(form *multipart.Form) (any, error)
-	VerifyLandCertificate(userID string, NationalIDInput string) (result bool, err error)
+	VerifyLandCertificate(userID string, NationalIDInput string) (result bool, ownerName string, err error)
 	CheckExistEmailOrPhone(input string) (bool, error)
 	GetUserCardByUserID(userID string) (*models.UserCard, error)
 	ResetEkycData(userID string) error
@@ -50,6 +53,17 @@ type IUserService interface {
 	UpdatePassword(ctx context.Context, userID, otp, newPassword string) error
 	UpdatePasswordPhone(ctx context.Context, phone, otp, newPassword string) error
 	CreateFarmerProfile(userID string, phone string, email string, role string) (bool, error)
+	DeactivateAccount(ctx context.Context, userID, password, reason string) error
+	RequestReactivationOTP(ctx context.Context, phone string) error
+	ReactivateAccount(ctx context.Context, phone, otp, password string) (*models.User, error)
+	RegisterTrustedDevice(ctx context.Context, userID, fingerprint, deviceName, otp string, deviceInfo, ipAddress *string) (*models.TrustedDevice, error)
+	GetTrustedDevices(ctx context.Context, userID string) ([]*models.TrustedDevice, error)
+	RevokeTrustedDevice(ctx context.Context, userID, deviceID string) error
+	RevokeAllTrustedDevices(ctx context.Context, userID string) error
+	GetMySessions(ctx context.Context, userID, currentSessionID string) ([]*models.ActiveSession, error)
+	RevokeMySession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error)
+	VerifyNFCChip(ctx context.Context, userID string, req models.NFCChipVerificationRequest) (*models.EkycProgressResponse, error)
 }
 
 type UserService struct {
@@ -62,14 +76,19 @@ type UserService struct {
 	sessionService   *SessionService
 	roleService      *RoleService
 	jwtService       *JWTService
+	mfaService       *MFAService
 	eventPublisher   *event.NotificationPublisher
-
-	globalLoginAttempt map[string]int
-	mu                 *sync.Mutex
-	redisClient        *redis.Client
+	accountEvents    *event.AccountEventPublisher
+	auditRepo        repository.IAuditRepository
+
+	redisClient   *redis.Client
+	loginAttempts *LoginAttemptTracker
+	ekycCache     *EkycStepCache
+	ekyc          *EkycOrchestrator
+	chipVerifier  *ChipVerifier
 }
 
-func NewUserService(userRepo repository.IUserRepository, minioClient *minio.MinioClient, cfg *config.AuthServiceConfig, utils *utils.Utils, userCardRepo repository.IUserCardRepository, ekycProgressRepo repository.IUserEkycProgressRepository, sessionService *SessionService, jwtService *JWTService, roleService *RoleService, eventPublisher *event.NotificationPublisher) IUserService {
+func NewUserService(userRepo repository.IUserRepository, minioClient *minio.MinioClient, cfg *config.AuthServiceConfig, utils *utils.Utils, userCardRepo repository.IUserCardRepository, ekycProgressRepo repository.IUserEkycProgressRepository, sessionService *SessionService, jwtService *JWTService, roleService *RoleService, mfaService *MFAService, ekyc *EkycOrchestrator, eventPublisher *event.NotificationPublisher, accountEvents *event.AccountEventPublisher, auditRepo repository.IAuditRepository) IUserService {
 	// Initialize Redis client
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.RedisCfg.Host, cfg.RedisCfg.Port),
@@ -85,19 +104,24 @@ func NewUserService(userRepo repository.IUserRepository, minioClient *minio.Mini
 	}
 
 	return &UserService{
-		userRepo:           userRepo,
-		minioClient:        minioClient,
-		cfg:                cfg,
-		utils:              utils,
-		userCardRepo:       userCardRepo,
-		ekycProgressRepo:   ekycProgressRepo,
-		sessionService:     sessionService,
-		jwtService:         jwtService,
-		roleService:        roleService,
-		globalLoginAttempt: make(map[string]int),
-		mu:                 &sync.Mutex{},
-		redisClient:        rdb,
-		eventPublisher:     eventPublisher,
+		userRepo:         userRepo,
+		minioClient:      minioClient,
+		cfg:              cfg,
+		utils:            utils,
+		userCardRepo:     userCardRepo,
+		ekycProgressRepo: ekycProgressRepo,
+		sessionService:   sessionService,
+		jwtService:       jwtService,
+		roleService:      roleService,
+		mfaService:       mfaService,
+		redisClient:      rdb,
+		loginAttempts:    NewLoginAttemptTracker(rdb),
+		ekycCache:        NewEkycStepCache(rdb),
+		ekyc:             ekyc,
+		chipVerifier:     NewChipVerifier(cfg.AuthCfg.CscaCertDir),
+		eventPublisher:   eventPublisher,
+		accountEvents:    accountEvents,
+		auditRepo:        auditRepo,
 	}
 }
 
@@ -105,6 +129,28 @@ func (s *UserService) GetUserEkycProgressByUserID(userID string) (*models.UserEk
 	return s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
 }
 
+// GetEkycProgress returns persisted eKYC progress together with the cached steps of an
+// unfinished attempt, and the next eKYC step the client should submit.
+func (s *UserService) GetEkycProgress(ctx context.Context, userID string) (*models.EkycProgressResponse, error) {
+	progress, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedSteps, err := s.ekycCache.GetCachedSteps(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get cached ekyc steps for user %s: %v", userID, err)
+		cachedSteps = []models.EkycCachedStep{}
+	}
+
+	return &models.EkycProgressResponse{
+		UserEkycProgress:  progress,
+		CachedSteps:       cachedSteps,
+		NextStep:          NextEkycStep(progress.State),
+		VerificationLevel: progress.VerificationLevel(),
+	}, nil
+}
+
 func (s *UserService) UploadToMinIO(c *gin.Context, file io.Reader, header *multipart.FileHeader, serviceName string) error {
 	// Lấy thông tin file
 	fileName := header.Filename
@@ -128,727 +174,90 @@ func (s *UserService) ProcessAndUploadFiles(files map[string][]*multipart.FileHe
 	return s.utils.ProcessFiles(s.minioClient, files, serviceName, allowedExts, maxMB)
 }
 
-func (s *UserService) OCRNationalIDCard(form *multipart.Form) (any, error) {
-	userIDs := form.Value["user_id"]
-	if len(userIDs) == 0 {
-		log.Printf("Error: user_id is required in the form data")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "user_id is required",
-			},
-		}, nil
-	}
-
-	userID := userIDs[0]
-
-	frontFiles := form.File["cccd_front"]
-	if len(frontFiles) == 0 {
-		log.Printf("Error: cccd_front file is required")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "cccd_front file is required",
-			},
-		}, nil
-	}
-
-	backFiles := form.File["cccd_back"]
-	if len(backFiles) == 0 {
-		log.Printf("Error: cccd_back file is required")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "cccd_back file is required",
-			},
-		}, nil
-	}
-	frontHeader := frontFiles[0]
-	backHeader := backFiles[0]
-
-	// Step 1: GetUserEkycProgressByUserID
-	userEkycProgress, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
-	if err != nil {
-		log.Printf("Failed to get user ekyc progress: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to get user ekyc progress",
-			},
-		}, nil
-	}
-	// Step 1.1: Found progress
-	if userEkycProgress.IsOcrDone {
-		// Step 2.1: Already verified
-		log.Printf("User %s has already completed OCR Card verification", userID)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "ALREADY_OCR_DONE",
-				Message: "User has already completed OCR Card verification",
-			},
-		}, nil
-	}
-	// Step 3: Create OCR front request
-	frontFile, err := frontHeader.Open()
-	if err != nil {
-		log.Printf("Error when opening cccd_front: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "Error when opening cccd_front",
-			},
-		}, nil
-	}
-	defer frontFile.Close()
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("image", frontHeader.Filename)
-	if err != nil {
-		log.Printf("Error when creating form file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when creating form file",
-			},
-		}, nil
-	}
-	_, err = io.Copy(part, frontFile)
-	if err != nil {
-		log.Printf("Error when copying front file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when copying front file",
-			},
-		}, nil
-	}
-	writer.Close()
-
-	// Step 4: Send OCR front request
-	req, err := http.NewRequest("POST", s.cfg.AuthCfg.FptOcrUrl, body)
-	if err != nil {
-		log.Printf("Failed to create OCR front request: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to create OCR front request",
-			},
-		}, nil
-	}
-	req.Header.Add("api-key", s.cfg.AuthCfg.FptEkycApiKey)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Error when sending front OCR request: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when sending front OCR request",
-			},
-		}, nil
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error when reading front OCR response: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when reading front OCR response",
-			},
-		}, nil
-	}
-
-	// Step 5: Check front OCR response status
-	if resp.StatusCode != http.StatusOK {
-		// Step 5.1: Return FPT response
-		log.Printf("FPT OCR front API error: %s", string(respBody))
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "EXTERNAL_API_ERROR",
-				Message: "FPT OCR front API error",
-			},
-		}, nil
-	}
-
-	// Step 6: Parse front OCR response
-	var idCardOcrFrontResponse map[string]any
-	if err := json.Unmarshal(respBody, &idCardOcrFrontResponse); err != nil {
-		log.Printf("Error when parsing front OCR response: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when parsing front OCR response",
-			},
-		}, nil
-	}
-
-	data, ok := idCardOcrFrontResponse["data"].([]any)
-	if !ok || len(data) == 0 {
-		log.Printf("Error: missing or invalid data in front OCR response")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "missing or invalid data in front OCR response",
-			},
-		}, nil
-	}
-	frontData := data[0].(map[string]any)
-	nationalID, ok := frontData["id"].(string)
-	if !ok {
-		log.Printf("Error: missing id in front OCR response")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "missing id in front OCR response",
-			},
-		}, nil
-	}
-
-	// Step 7: Create OCR back request
-	backFile, err := backHeader.Open()
-	if err != nil {
-		log.Printf("Error when opening cccd_back: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "Error when opening cccd_back",
-			},
-		}, nil
-	}
-	defer backFile.Close()
-
-	bodyBack := &bytes.Buffer{}
-	writerBack := multipart.NewWriter(bodyBack)
-	partBack, err := writerBack.CreateFormFile("image", backHeader.Filename)
-	if err != nil {
-		log.Printf("Error when creating form file for back: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when creating form file for back",
-			},
-		}, nil
-	}
-	_, err = io.Copy(partBack, backFile)
-	if err != nil {
-		log.Printf("Error when copying back file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when copying back file",
-			},
-		}, nil
-	}
-	writerBack.Close()
-
-	// Step 9: Send OCR back request
-	reqBack, err := http.NewRequest("POST", s.cfg.AuthCfg.FptOcrUrl, bodyBack)
-	if err != nil {
-		log.Printf("Error when creating back OCR request: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when creating back OCR request",
-			},
-		}, nil
-	}
-	reqBack.Header.Add("api-key", s.cfg.AuthCfg.FptEkycApiKey)
-	reqBack.Header.Set("Content-Type", writerBack.FormDataContentType())
-
-	respBack, err := client.Do(reqBack)
-	if err != nil {
-		log.Printf("Error when sending back OCR request: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when sending back OCR request",
-			},
-		}, nil
-	}
-	defer respBack.Body.Close()
-
-	respBodyBack, err := io.ReadAll(respBack.Body)
-	if err != nil {
-		log.Printf("Error when reading back OCR response: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when reading back OCR response",
-			},
-		}, nil
-	}
-
-	// Step 11: Check back OCR response status
-	if respBack.StatusCode != http.StatusOK {
-		// Step 11.1: Return FPT back response
-		log.Printf("FPT OCR back API error: %s", string(respBodyBack))
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "EXTERNAL_API_ERROR",
-				Message: "FPT OCR back API error",
-			},
-		}, nil
-	}
-
-	// Step 10: Parse back OCR response
-	var idCardOcrBackResponse map[string]any
-	if err := json.Unmarshal(respBodyBack, &idCardOcrBackResponse); err != nil {
-		log.Printf("Error when parsing back OCR response: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when parsing back OCR response",
-			},
-		}, nil
-	}
-
-	// Step 12: UpdateUserNationalID
-	err = s.userRepo.UpdateUserNationalID(userID, nationalID)
-	if err != nil {
-		// Step 12.1: Log error and return internal error
-		log.Printf("Failed to update user national ID: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to update user national ID",
-			},
-		}, nil
-	}
-
-	// Step 13: Create URL variables
-	var cccdFrontAccessURL, cccdBackAccessURL string
-
-	// Step 14: Upload files to MinIO
-	uploadedFiles, err := (s.utils.ProcessFiles(s.minioClient, form.File, "auth-service", []string{".jpg", ".png", ".jpeg"}, 50))
-	if err != nil {
-		// Step 14: Handle upload error
-		log.Printf("Failed to upload files to MinIO: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to upload files to storage",
-			},
-		}, nil
-	}
-
-	// Step 15: Assign URLs from uploaded files
-	for _, fileInfo := range uploadedFiles {
-		if fileInfo.FieldName == "cccd_front" {
-			cccdFrontAccessURL = fileInfo.MinioURL
-		} else if fileInfo.FieldName == "cccd_back" {
-			cccdBackAccessURL = fileInfo.MinioURL
-		}
-	}
-
-	// Extract front data
-	frontFieldData, ok := idCardOcrFrontResponse["data"].([]any)
-	if !ok || len(frontFieldData) == 0 {
-		log.Printf("invalid front OCR response data: %v", idCardOcrFrontResponse)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "invalid front OCR response data",
-			},
-		}, nil
-	}
-	front := frontFieldData[0].(map[string]any)
-
-	// Extract back data
-	backFieldData, ok := idCardOcrBackResponse["data"].([]any)
-	if !ok || len(backFieldData) == 0 {
-		log.Printf("invalid back OCR response data: %v", idCardOcrBackResponse)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "invalid back OCR response data",
-			},
-		}, nil
-	}
-	back := backFieldData[0].(map[string]any)
-
-	// Convert mrz array to string
-	mrzArray, ok := back["mrz"].([]any)
-	if !ok {
-		log.Printf("invalid mrz format in back OCR response: %v", back)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "invalid mrz format in back OCR response",
-			},
-		}, nil
-	}
-	mrzStrings := make([]string, len(mrzArray))
-	for i, v := range mrzArray {
-		mrzStrings[i], _ = v.(string)
-	}
-	mrz := strings.Join(mrzStrings, ", ")
-
-	userCard := models.UserCard{
-		NationalID:        front["id"].(string),
-		Name:              front["name"].(string),
-		Dob:               front["dob"].(string),
-		Sex:               front["sex"].(string),
-		Nationality:       front["nationality"].(string),
-		Home:              front["home"].(string),
-		Address:           front["address"].(string),
-		Doe:               front["doe"].(string),
-		NumberOfNameLines: front["number_of_name_lines"].(string),
-		Features:          back["features"].(string),
-		IssueDate:         back["issue_date"].(string),
-		Mrz:               mrz,
-		IssueLoc:          back["issue_loc"].(string),
-		ImageFront:        cccdFrontAccessURL,
-		ImageBack:         cccdBackAccessURL,
-		UserID:            userID,
-	}
-
-	_, err = s.userCardRepo.CreateUserCard(&userCard)
-	if err != nil {
-		log.Printf("Failed to create user card record: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to create user card record",
-			},
-		}, nil
-	}
-
-	// Step 16: Update Ekyc Progress
-	err = s.ekycProgressRepo.UpdateOCRDone(userID, true, nationalID)
-	if err != nil {
-		log.Printf("Failed to update ekyc progress: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to update ekyc progress",
-			},
-		}, nil
-	}
-	// Step 17: Get ekyc progress to include in response
-	updatedEkycProgress, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
-	if err != nil {
-		log.Printf("Failed to get updated ekyc progress: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to get updated ekyc progress",
-			},
-		}, nil
-	}
-
-	// Step 18: Return success response
-	return utils.SuccessResponse{
-		Success: true,
-		Data:    updatedEkycProgress,
-		Meta:    &utils.Meta{Timestamp: time.Now()},
-	}, nil
+// GetEkycStepEvents lists the latest eKYC step attempts of the user
+func (s *UserService) GetEkycStepEvents(ctx context.Context, userID string) ([]*models.EkycStepEvent, error) {
+	return s.ekyc.GetStepEvents(userID)
 }
 
-func (s *UserService) VerifyFaceLiveness(form *multipart.Form) (any, error) {
-	userIDs := form.Value["user_id"]
-	if len(userIDs) == 0 {
-		log.Printf("Error: user_id is required in the form data")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "user_id is required",
-			},
-		}, nil
-	}
-
-	userID := userIDs[0]
-
-	// Verify if user has done OCR before
-	ekycProgress, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
+// VerifyNFCChip authenticates the data read from the CCCD chip and records the
+// chip read as the user's document verification. The chip read stands in for
+// OCR and ranks above it, since the data is signed by the issuing authority.
+func (s *UserService) VerifyNFCChip(ctx context.Context, userID string, req models.NFCChipVerificationRequest) (*models.EkycProgressResponse, error) {
+	progress, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
 	if err != nil {
-		log.Printf("Failed to get ekyc progress: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to get ekyc progress",
-			},
-		}, nil
-	}
-	if !ekycProgress.IsOcrDone {
-		log.Printf("Error: user has not completed OCR")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "User has not completed OCR",
-			},
-		}, nil
-	}
-	if ekycProgress.IsFaceVerified {
-		log.Printf("Error: user has already completed face liveness")
-		return utils.CreateErrorResponse("ALREADY_FACE_LIVENESS_DONE", "User has already completed face liveness"), nil
-	}
-
-	// Get video file
-	videos := form.File["video"]
-	if len(videos) == 0 {
-		log.Printf("Error: failed to get video file")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "Failed to get video file",
-			},
-		}, nil
-	}
-
-	videoHeader := videos[0]
-	srcVideo, err := videoHeader.Open()
-	if err != nil {
-		log.Printf("Error when opening video file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "Error when opening video file",
-			},
-		}, nil
-	}
-	defer srcVideo.Close()
-
-	videoBuffer := &bytes.Buffer{}
-	_, err = io.Copy(videoBuffer, srcVideo)
-	if err != nil {
-		log.Printf("Error when reading video file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when reading video file",
-			},
-		}, nil
-	}
-
-	// getimage file
-	images := form.File["cmnd"]
-	if len(images) == 0 {
-		log.Printf("Error: failed to get cmnd file")
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "Failed to get cmnd file",
-			},
-		}, nil
+		return nil, fmt.Errorf("not_found: %w", err)
 	}
-
-	imageHeader := images[0]
-	srcImage, err := imageHeader.Open()
-	if err != nil {
-		log.Printf("Error when opening cmnd file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "BAD_REQUEST",
-				Message: "Error when opening cmnd file",
-			},
-		}, nil
+	if progress.IsNfcVerified {
+		return nil, fmt.Errorf("conflict: user has already completed NFC chip verification")
 	}
-	defer srcImage.Close()
 
-	imageBuffer := &bytes.Buffer{}
-	_, err = io.Copy(imageBuffer, srcImage)
-	if err != nil {
-		log.Printf("Error when reading cmnd file: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when reading cmnd file",
-			},
-		}, nil
-	}
-
-	// send API
-	url := s.cfg.AuthCfg.FptFaceLivenessUrl
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-
-	partVideo, err := writer.CreateFormFile("video", "face_video.mp4")
-	if err != nil {
-		log.Printf("Error when creating form file for video: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when creating form file for video",
-			},
-		}, nil
-	}
-
-	_, err = io.Copy(partVideo, videoBuffer)
-	if err != nil {
-		log.Printf("Error when copying video file to form: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when copying video file to form",
-			},
-		}, nil
-	}
-
-	partImage, err := writer.CreateFormFile("cmnd", "cccc_front.jpg")
-	if err != nil {
-		log.Printf("Error when creating form file for image: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when creating form file for image",
-			},
-		}, nil
+	if err := s.verifyNFCChip(userID, progress, req); err != nil {
+		s.ekyc.RecordFailure(progress, EkycStepNFCChip, err)
+		return nil, err
 	}
-	_, err = io.Copy(partImage, imageBuffer)
-	if err != nil {
-		log.Printf("Error when copying image file to form: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when copying image file to form",
-			},
-		}, nil
+	if err := s.ekyc.CompleteNFC(progress); err != nil {
+		return nil, fmt.Errorf("failed to update ekyc progress: %w", err)
 	}
 
-	err = writer.Close()
-	if err != nil {
-		log.Printf("Error when closing multipart writer: %v", err)
-		return utils.ErrorResponse{
-			Success: false,
-			Error: utils.APIError{
-				Code:    "INTERNAL_ERROR",
-				Message: "Error when closing multipart writer",
-			},
-		}, nil
-	}
+	return s.GetEkycProgress(ctx, userID)
+}
 
-	req, err := http.NewRequest("POST", url, &requestBody)
+// verifyNFCChip authenticates the chip data and stores the chip read
+func (s *UserService) verifyNFCChip(userID string, progress *models.UserEkycProgress, req models.NFCChipVerificationRequest) error {
+	sod, err := base64.StdEncoding.DecodeString(req.SOD)
 	if err != nil {
-		log.Printf("Error when creating request: %v", err)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Error when creating request"), nil
+		return fmt.Errorf("bad_request: sod is not valid base64")
 	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("api-key", s.cfg.AuthCfg.FptEkycApiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Error when sending request: %v", err)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Error when sending request"), nil
+	dataGroups := make(map[int][]byte)
+	for number, encoded := range map[int]string{1: req.DG1, 2: req.DG2, 13: req.DG13} {
+		if encoded == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("bad_request: dg%d is not valid base64", number)
+		}
+		dataGroups[number] = decoded
 	}
-	defer resp.Body.Close()
 
-	responseBody, err := io.ReadAll(resp.Body)
+	chipData, err := s.chipVerifier.Verify(sod, dataGroups)
 	if err != nil {
-		log.Printf("Error when reading response body: %v", err)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Error when reading response body"), nil
+		return err
 	}
-
-	var result map[string]any
-	if err := json.Unmarshal(responseBody, &result); err != nil {
-		log.Printf("Error when parsing response body: %v", err)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Error when parsing response body"), nil
+	if chipData.NationalID == "" {
+		return fmt.Errorf("bad_request: chip does not contain a national ID number")
 	}
-
-	if code, ok := result["code"].(string); ok {
-		if code != "200" {
-			message, ok := result["message"].(string)
-			if ok {
-				return utils.CreateErrorResponse("EXTERNAL_API_ERROR", "Face liveness failed: "+message), nil
-			}
-			return utils.CreateErrorResponse("EXTERNAL_API_ERROR", "Face liveness failed: Unknown error"), nil
-		}
+	if chipDocumentExpired(chipData.DateOfExpiry, time.Now()) {
+		return fmt.Errorf("bad_request: national ID card has expired")
 	}
 
-	videoAccessURL := ""
-
-	fileInfos, err := s.utils.ProcessFiles(s.minioClient, form.File, "auth-service", []string{".mp4", ".jpg", ".png"}, 50)
+	// The chip must belong to the same person as the account and any earlier OCR
+	user, err := s.userRepo.GetUserByID(userID)
 	if err != nil {
-		log.Printf("Error when processing files: %v", err)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Error when processing files"), nil
+		return fmt.Errorf("not_found: user not found")
 	}
-
-	for _, fileInfo := range fileInfos {
-		if fileInfo.FieldName == "video" {
-			videoAccessURL = fileInfo.MinioURL
-			break
-		}
+	if user.NationalID != "" && user.NationalID != chipData.NationalID {
+		return fmt.Errorf("forbidden: chip national ID does not match the account")
 	}
-
-	// Update user face liveness URL
-	errorUpdateUserFaceLiveness := s.userRepo.UpdateUserFaceLiveness(userID, videoAccessURL)
-	if errorUpdateUserFaceLiveness != nil {
-		log.Printf("Failed to update user face liveness: %v", errorUpdateUserFaceLiveness)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to update user face liveness"), nil
+	if progress.CicNo != "" && progress.CicNo != chipData.NationalID {
+		return fmt.Errorf("forbidden: chip national ID does not match the scanned card")
 	}
 
-	// Update ekyc progress (face liveness done)
-	errorUpdateEkycProgress := s.ekycProgressRepo.UpdateFaceLivenessDone(userID, true)
-	if errorUpdateEkycProgress != nil {
-		log.Printf("Failed to update ekyc progress: %v", errorUpdateEkycProgress)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to update ekyc progress"), nil
+	// Stored before the progress, so a failure leaves the chip read to be submitted again
+	if err := s.userRepo.UpdateUserNationalID(userID, chipData.NationalID); err != nil {
+		return fmt.Errorf("failed to update user national ID: %w", err)
 	}
-
-	ekycProgressUpdated, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(userID)
-	if err != nil {
-		log.Printf("Failed to get updated ekyc progress: %v", err)
-		return utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get updated ekyc progress"), nil
+	if err := s.ekycProgressRepo.UpdateNFCVerified(userID, chipData.NationalID); err != nil {
+		return fmt.Errorf("failed to update ekyc progress: %w", err)
 	}
+	slog.Info("nfc chip verified", "user_id", userID, "document_number", chipData.DocumentNumber, "signer", chipData.SignerSubject)
 
-	if ekycProgressUpdated.IsOcrDone && ekycProgressUpdated.IsFaceVerified {
-		errorUpdateUserEkycStatus := s.userRepo.UpdateUserKycStatus(userID, true)
-		if errorUpdateUserEkycStatus != nil {
-			log.Printf("Failed to update user status: %v", errorUpdateUserEkycStatus)
-			return utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to update user status"), nil
+	if progress.IsFaceVerified {
+		if err := s.userRepo.UpdateUserKycStatus(userID, true); err != nil {
+			return fmt.Errorf("failed to update user kyc status: %w", err)
 		}
 	}
-
-	return utils.CreateSuccessResponse(ekycProgressUpdated), nil
+	return nil
 }
 
 func (s *UserService) RegisterNewUser(phone, email, password, nationalID string, phoneVerificationStatus, isDefault bool) (*models.User, error) {
@@ -994,7 +403,7 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	return s.userRepo.GetUserByEmail(email)
 }
 
-func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress *string) (*models.User, *models.UserSession, error) {
+func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress *string, deviceFingerprint, otp string, trustDevice bool, mfaCode string) (*models.User, *models.UserSession, error) {
 	if email != "" && phone != "" {
 		log.Println("SUSPICIOUS ACTIVITY DETECTED : email & phone present reached service layer and blocked")
 		return nil, nil, fmt.Errorf("action forbidden")
@@ -1031,20 +440,18 @@ func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress
 		return nil, nil, fmt.Errorf("UNEXPECTED ERROR : user found but still null")
 	}
 
-	if !s.userRepo.CheckPasswordHash(password, login_attempt_user.PasswordHash) {
-		attemptCount := s.incrementLoginAttempts(login_attempt_user.ID)
-
-		if attemptCount%5 == 0 {
-			// event to notification service to send email/phone of suspicious login activities
-			log.Printf("Suspicious login activity detected for user %s: %d attempts", login_attempt_user.ID, attemptCount)
-		}
-		if attemptCount%10 == 0 {
-			log.Println("account blocked due to too many failed login attempts")
-			// lock account
-			s.BanUser(login_attempt_user.ID, time.Now().Unix()+(int64(attemptCount)*60))
-			return nil, nil, fmt.Errorf("account blocked due to too many failed login attempts")
+	// Locked accounts are refused before the password is checked, so guessing cannot go on
+	if err := s.loginAttempts.Check(context.Background(), login_attempt_user.ID); err != nil {
+		var throttled *LoginThrottledError
+		if errors.As(err, &throttled) {
+			s.recordLogin(login_attempt_user.ID, nil, deviceInfo, ipAddress, err)
+			return nil, nil, err
 		}
-		return nil, nil, fmt.Errorf("invalid password")
+		log.Printf("Failed to check login attempts of user %s: %v", login_attempt_user.ID, err)
+	}
+
+	if !s.userRepo.CheckPasswordHash(password, login_attempt_user.PasswordHash) {
+		return nil, nil, s.recordFailedLogin(login_attempt_user, deviceInfo, ipAddress, fmt.Errorf("invalid password"))
 	}
 	if login_attempt_user.Status == models.UserStatusSuspended {
 		// Check if the ban period has expired
@@ -1064,8 +471,35 @@ func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress
 		}
 	}
 	if login_attempt_user.Status == models.UserStatusDeactivated {
-		// event to email for deactivated account
-		return nil, nil, fmt.Errorf("account blocked, check email for further information")
+		return nil, nil, fmt.Errorf("account deactivated, reactivate to continue")
+	}
+
+	// Device verification: logins from a device that is not trusted, including clients sending
+	// no fingerprint, must be confirmed with a phone OTP; only trusted devices skip this step
+	var deviceHash *string
+	trusted := false
+	if deviceFingerprint != "" {
+		hash := HashDeviceFingerprint(deviceFingerprint)
+		deviceHash = &hash
+		trusted = s.sessionService.IsDeviceTrusted(context.Background(), login_attempt_user.ID, deviceFingerprint)
+	}
+	if !trusted {
+		if otp == "" {
+			if err := s.GeneratePhoneOTP(context.Background(), login_attempt_user.PhoneNumber); err != nil {
+				log.Printf("error sending device verification otp to user %s: %v", login_attempt_user.ID, err)
+				return nil, nil, fmt.Errorf("error sending device verification otp: %s", err)
+			}
+			return nil, nil, fmt.Errorf("device verification required, otp sent to registered phone")
+		}
+		if err := s.ValidatePhoneOTP(context.Background(), login_attempt_user.PhoneNumber, otp); err != nil {
+			return nil, nil, fmt.Errorf("incorrect otp")
+		}
+		// A device can only be remembered by its fingerprint
+		if trustDevice && deviceFingerprint != "" {
+			if _, err := s.sessionService.TrustDevice(context.Background(), login_attempt_user.ID, deviceFingerprint, nil, deviceInfo, ipAddress); err != nil {
+				log.Printf("error trusting device for user %s: %v", login_attempt_user.ID, err)
+			}
+		}
 	}
 
 	// get roles
@@ -1079,8 +513,19 @@ func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress
 		roleNames = append(roleNames, role.Name)
 	}
 
+	// scoped roles (e.g. partner staff roles) are embedded in the token
+	scopes, err := s.roleService.GetUserRoleScopes(login_attempt_user.ID)
+	if err != nil {
+		log.Println("error get user role scopes: ", err)
+		return nil, nil, fmt.Errorf("error get user role scopes: %s", err)
+	}
+
+	if err := s.checkLoginMFA(login_attempt_user, MFARoleNames(roles, scopes), mfaCode, deviceInfo, ipAddress); err != nil {
+		return nil, nil, err
+	}
+
 	// gen token
-	token, err := s.jwtService.GenerateNewToken(roleNames, login_attempt_user.PhoneNumber, login_attempt_user.Email, login_attempt_user.ID)
+	token, err := s.jwtService.GenerateNewToken(roleNames, scopes, login_attempt_user.PhoneNumber, login_attempt_user.Email, login_attempt_user.ID)
 	if err != nil {
 		log.Println("error generating token: ", err)
 		return nil, nil, fmt.Errorf("error generating token: %s", err)
@@ -1095,7 +540,11 @@ func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress
 		log.Printf("User %s session exists: %v", login_attempt_user.ID, len(sessions))
 		// process existing session
 		for _, session := range sessions {
-			if *deviceInfo == *session.DeviceInfo {
+			sameDevice := session.DeviceInfo != nil && *deviceInfo == *session.DeviceInfo
+			if deviceHash != nil {
+				sameDevice = session.DeviceHash != nil && *deviceHash == *session.DeviceHash
+			}
+			if sameDevice {
 				log.Printf("New login in the same device, retrieve old session (user id: %s --- session id: %s)", login_attempt_user.ID, session.ID)
 				finalSession = session
 				newSessionSignal = false
@@ -1105,20 +554,65 @@ func (s *UserService) Login(email, phone, password string, deviceInfo, ipAddress
 	}
 
 	if newSessionSignal {
-		finalSession, err = s.sessionService.CreateSession(context.Background(), login_attempt_user.ID, token, &token, deviceInfo, ipAddress)
+		finalSession, err = s.sessionService.CreateSession(context.Background(), login_attempt_user.ID, token, &token, deviceInfo, deviceHash, ipAddress)
 		if err != nil {
 			log.Println("error creating new session: ", err)
 			return nil, nil, fmt.Errorf("error creating new session: %s", err)
 		}
 		log.Printf("New session created (user id: %s --- session id: %s)", login_attempt_user.ID, finalSession.ID)
+
+		newDevice, err := s.sessionService.RememberDevice(context.Background(), login_attempt_user.ID, deviceHash, deviceInfo)
+		if err != nil {
+			log.Printf("Failed to remember login device of user %s: %v", login_attempt_user.ID, err)
+		}
+		if newDevice {
+			go s.notifyNewDeviceLogin(login_attempt_user, deviceInfo, ipAddress)
+		}
 	}
 
 	// Reset login attempts on successful login
-	s.resetLoginAttempts(login_attempt_user.ID)
+	if err := s.loginAttempts.Reset(context.Background(), login_attempt_user.ID); err != nil {
+		log.Printf("Failed to reset login attempts of user %s: %v", login_attempt_user.ID, err)
+	}
+	s.recordLogin(login_attempt_user.ID, &finalSession.ID, deviceInfo, ipAddress, nil)
 
 	return login_attempt_user, finalSession, nil
 }
 
+// checkLoginMFA asks users with MFA for a code, and users whose roles require MFA to enroll first
+func (s *UserService) checkLoginMFA(user *models.User, roleNames []string, mfaCode string, deviceInfo, ipAddress *string) error {
+	enabled, err := s.mfaService.IsEnabled(user.ID)
+	if err != nil {
+		return fmt.Errorf("error checking mfa: %s", err)
+	}
+	if !enabled {
+		required, err := s.mfaService.IsRequiredForRoles(roleNames)
+		if err != nil {
+			return fmt.Errorf("error checking mfa policy: %s", err)
+		}
+		if !required {
+			return nil
+		}
+		ticket, err := s.mfaService.IssueEnrollmentTicket(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("error issuing mfa enrollment ticket: %s", err)
+		}
+		return &MFAEnrollmentRequiredError{Ticket: ticket}
+	}
+
+	if mfaCode == "" {
+		return fmt.Errorf("mfa code required")
+	}
+	if err := s.mfaService.Verify(user.ID, mfaCode); err != nil {
+		if strings.HasPrefix(err.Error(), "invalid mfa code") {
+			// Wrong codes count as failed logins, so guessing codes ends with the account locked
+			return s.recordFailedLogin(user, deviceInfo, ipAddress, fmt.Errorf("incorrect mfa code"))
+		}
+		return fmt.Errorf("error verifying mfa code: %s", err)
+	}
+	return nil
+}
+
 // Cache helper methods
 func (s *UserService) getCachedUserByEmail(email string) *models.User {
 	if s.redisClient == nil {
@@ -1186,50 +680,136 @@ func (s *UserService) cacheUser(user *models.User) {
 	s.redisClient.Set(ctx, fmt.Sprintf("user:phone:%s", user.PhoneNumber), buf.Bytes(), ttl)
 }
 
-func (s *UserService) incrementLoginAttempts(userID string) int {
-	if s.redisClient == nil {
-		// Fallback to in-memory with proper locking
-		s.mu.Lock()
-		s.globalLoginAttempt[userID]++
-		attempts := s.globalLoginAttempt[userID]
-		s.mu.Unlock()
-		return attempts
+func (s *UserService) invalidateCachedUser(user *models.User) {
+	if s.redisClient == nil || user == nil {
+		return
 	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
 
-	key := fmt.Sprintf("login_attempts:%s", userID)
-	count, err := s.redisClient.Incr(ctx, key).Result()
+	s.redisClient.Del(ctx, fmt.Sprintf("user:email:%s", user.Email), fmt.Sprintf("user:phone:%s", user.PhoneNumber))
+}
+
+// recordFailedLogin counts a failed password or MFA code and returns the error for the attempt,
+// which is a lockout once the failures reach the threshold
+func (s *UserService) recordFailedLogin(user *models.User, deviceInfo, ipAddress *string, failure error) error {
+	s.recordLogin(user.ID, nil, deviceInfo, ipAddress, failure)
+
+	result, err := s.loginAttempts.RecordFailure(context.Background(), user.ID)
 	if err != nil {
-		// Fallback to in-memory
-		s.mu.Lock()
-		s.globalLoginAttempt[userID]++
-		attempts := s.globalLoginAttempt[userID]
-		s.mu.Unlock()
-		return attempts
+		log.Printf("Failed to record failed login of user %s: %v", user.ID, err)
+		return failure
+	}
+	if result.LockedFor == 0 {
+		return failure
 	}
 
-	// Set TTL for 24 hours on first attempt
-	if count == 1 {
-		s.redisClient.Expire(ctx, key, 24*time.Hour)
+	slog.Warn("login locked after repeated failures", "user_id", user.ID, "failures", result.Failures, "locked_for", result.LockedFor)
+	go s.notifyLoginLockout(user, result.LockedFor)
+	return &LoginThrottledError{Locked: true, RetryAfter: result.LockedFor}
+}
+
+// recordLogin adds a login attempt to the login history of the user, which admins can review
+func (s *UserService) recordLogin(userID string, sessionID, deviceInfo, ipAddress *string, failure error) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    models.AuditActionLogin,
+		IPAddress: ipAddress,
+		Success:   failure == nil,
+	}
+	if sessionID != nil {
+		resourceType := "session"
+		entry.ResourceType = &resourceType
+		entry.ResourceID = sessionID
 	}
+	if deviceInfo != nil {
+		entry.Details = models.AuditDetails{"device_info": *deviceInfo}
+	}
+	if failure != nil {
+		message := failure.Error()
+		entry.Action = models.AuditActionLoginFailed
+		entry.ErrorMessage = &message
+	}
+	if err := s.auditRepo.CreateAuditLog(entry); err != nil {
+		slog.Error("failed to record login", "user_id", userID, "error", err)
+	}
+}
 
-	return int(count)
+// notifyLoginLockout tells the user their account was locked, in case someone else is guessing
+func (s *UserService) notifyLoginLockout(user *models.User, lockedFor time.Duration) {
+	if s.eventPublisher == nil || user.PhoneNumber == "" {
+		return
+	}
+	notice := event.NotificationEventPushModel{
+		Notification: event.Notification{
+			Title: "Canh Bao Dang Nhap",
+			Body: fmt.Sprintf("Tai khoan cua ban da bi tam khoa den %s do dang nhap sai nhieu lan. Neu khong phai ban, hay doi mat khau hoac lien he ho tro Agrisa.",
+				time.Now().Add(lockedFor).Format("15:04 02/01/2006")),
+		},
+		Destinations: []string{user.PhoneNumber},
+	}
+	if err := s.eventPublisher.PublishNotification(context.Background(), notice); err != nil {
+		slog.Error("failed to send login lockout notice", "user_id", user.ID, "error", err)
+	}
+}
+
+// notifyNewDeviceLogin tells the user their account was logged in from a device it had not used
+// before, so a login they did not make can be revoked from their sessions
+func (s *UserService) notifyNewDeviceLogin(user *models.User, deviceInfo, ipAddress *string) {
+	if s.eventPublisher == nil || user.PhoneNumber == "" {
+		return
+	}
+	device, ip := "khong xac dinh", "khong xac dinh"
+	if deviceInfo != nil && *deviceInfo != "" {
+		device = *deviceInfo
+	}
+	if ipAddress != nil && *ipAddress != "" {
+		ip = *ipAddress
+	}
+	notice := event.NotificationEventPushModel{
+		Notification: event.Notification{
+			Title: "Dang Nhap Tu Thiet Bi Moi",
+			Body: fmt.Sprintf("Tai khoan cua ban vua dang nhap tu thiet bi moi (%s, IP %s) luc %s. Neu khong phai ban, hay dang xuat phien nay va doi mat khau.",
+				device, ip, time.Now().Format("15:04 02/01/2006")),
+		},
+		Destinations: []string{user.PhoneNumber},
+	}
+	if err := s.eventPublisher.PublishNotification(context.Background(), notice); err != nil {
+		slog.Error("failed to send new device login notice", "user_id", user.ID, "error", err)
+	}
 }
 
-func (s *UserService) resetLoginAttempts(userID string) {
-	if s.redisClient != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
-		defer cancel()
-		key := fmt.Sprintf("login_attempts:%s", userID)
-		s.redisClient.Del(ctx, key)
+// UnlockLogin lets a global admin lift the lockout of a user, and the suspension left by the
+// lockouts stored on the user before they moved to Redis
+func (s *UserService) UnlockLogin(ctx context.Context, actorID, userID string) error {
+	isAdmin, err := s.roleService.isGlobalAdmin(actorID)
+	if err != nil {
+		return fmt.Errorf("error checking admin role: %w", err)
+	}
+	if !isAdmin {
+		return fmt.Errorf("forbidden: only admins can unlock accounts")
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "user not found") {
+			return fmt.Errorf("not_found: user not found")
+		}
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	if err := s.loginAttempts.Unlock(ctx, userID); err != nil {
+		return err
+	}
+	if user.Status == models.UserStatusSuspended && user.LockedUntil > 0 {
+		if err := s.UnbanUser(userID); err != nil {
+			return err
+		}
+		s.invalidateCachedUser(user)
 	}
 
-	// Also clear in-memory
-	s.mu.Lock()
-	delete(s.globalLoginAttempt, userID)
-	s.mu.Unlock()
+	slog.Info("login unlocked by admin", "user_id", userID, "admin_id", actorID)
+	return nil
 }
 
 // BanUser bans a user by setting status to suspended and locked_until timestamp
@@ -1270,41 +850,46 @@ func (s *UserService) UnbanUser(userID string) error {
 	}
 
 	// Clear failed login attempts
-	s.resetLoginAttempts(userID)
+	if err := s.loginAttempts.Unlock(context.Background(), userID); err != nil {
+		log.Printf("Failed to clear login attempts of user %s: %v", userID, err)
+	}
 
 	log.Printf("User %s has been unbanned and reactivated", userID)
 	return nil
 }
 
-func (s *UserService) VerifyLandCertificate(userID string, NationalIDInput string) (bool, error) {
+// VerifyLandCertificate checks that a national ID is the one of the user's card and that the user
+// completed eKYC. The name on the card is returned so the owner on the land certificate can be
+// checked against it.
+func (s *UserService) VerifyLandCertificate(userID string, NationalIDInput string) (bool, string, error) {
 	var result bool = false
 	var isNationalIDMatch bool = true
 	userCard, err := s.userCardRepo.GetUserCardByUserID(userID)
 	if err != nil {
 		log.Printf("Failed to get user card: %v", err)
-		return false, fmt.Errorf(err.Error())
+		return false, "", fmt.Errorf(err.Error())
 	}
 
 	user, err := s.userRepo.GetUserByID(userID)
 	if err != nil {
 		log.Printf("Failed to get user: %v", err)
-		return false, err
+		return false, "", err
 	}
 
 	log.Printf("Comparing National IDs: userCard.NationalID=%s, NationalIDInput=%s", userCard.NationalID, NationalIDInput)
 
 	if userCard.NationalID != NationalIDInput {
 		isNationalIDMatch = false
-		return result, fmt.Errorf("bad_request: National ID does not match")
+		return result, "", fmt.Errorf("bad_request: National ID does not match")
 	}
 
 	if user.KYCVerified && isNationalIDMatch {
 		result = true
 	} else {
-		return result, fmt.Errorf("forbidden: User has not completed KYC verification")
+		return result, "", fmt.Errorf("forbidden: User has not completed KYC verification")
 	}
 
-	return result, nil
+	return result, userCard.Name, nil
 }
 
 func (s *UserService) GetAllUsers(limit, offset int) (*models.GetAllUsersResponse, error) {
@@ -1337,11 +922,19 @@ func (s *UserService) ResetEkycData(userID string) error {
 		slog.Error("user not found when resetting ekyc data", "user_id", userID)
 		return fmt.Errorf("note_found: user not found")
 	}
+	progress, err := s.ekycProgressRepo.GetUserEkycProgressByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("not_found: %w", err)
+	}
 
 	err = s.userRepo.ResetEkycData(user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user card data: %w", err)
 	}
+	s.ekyc.RecordReset(user.ID, progress.State)
+	if err := s.ekycCache.ClearSteps(context.Background(), user.ID); err != nil {
+		slog.Error("failed to clear cached ekyc steps", "user_id", user.ID, "error", err)
+	}
 	return nil
 }
 
@@ -1353,7 +946,37 @@ func (s *UserService) UpdateUserCardByUserID(userID string, req models.UpdateUse
 		return fmt.Errorf("not_found: user card not found")
 	}
 
-	return s.userCardRepo.UpdateUserCardByUserID(userID, req)
+	if err := s.userCardRepo.UpdateUserCardByUserID(userID, req); err != nil {
+		return err
+	}
+
+	if req.Address != nil {
+		userCard := models.UserCard{Address: *req.Address}
+		normalizeCardAddress(&userCard)
+		if err := s.userCardRepo.UpdateNormalizedAddress(userID, &userCard); err != nil {
+			log.Printf("Failed to update normalized address: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeCardAddress maps the free-text card address onto the province/commune
+// taxonomy. A failed lookup leaves a partial match, so eKYC is never blocked on it.
+func normalizeCardAddress(userCard *models.UserCard) {
+	normalized, err := agrisa_utils.NormalizeAddress(userCard.Address)
+	if err != nil {
+		log.Printf("Failed to normalize address %q: %v", userCard.Address, err)
+	}
+
+	userCard.AddressStreet = normalized.Street
+	userCard.AddressCommuneCode = normalized.CommuneCode
+	userCard.AddressCommuneName = normalized.CommuneName
+	userCard.AddressProvinceCode = normalized.ProvinceCode
+	userCard.AddressProvinceName = normalized.ProvinceName
+	userCard.AddressLegacyDistrict = normalized.LegacyDistrict
+	userCard.AddressLegacyProvince = normalized.LegacyProvince
+	userCard.AddressMatchLevel = normalized.MatchLevel
 }
 
 func (s *UserService) GeneratePhoneOTP(ctx context.Context, phoneNumber string) error {
@@ -1430,3 +1053,218 @@ func (s *UserService) UpdatePasswordPhone(ctx context.Context, phone, otp, newPa
 	}
 	return nil
 }
+
+// DeactivateAccount lets a user voluntarily disable their account. Login is
+// blocked and all sessions are revoked, but no data is removed so policy
+// history stays intact.
+func (s *UserService) DeactivateAccount(ctx context.Context, userID, password, reason string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("not_found: user not found")
+	}
+	if user.Status == models.UserStatusDeactivated {
+		return fmt.Errorf("bad_request: account already deactivated")
+	}
+	if user.Status == models.UserStatusSuspended {
+		return fmt.Errorf("forbidden: suspended accounts cannot be deactivated")
+	}
+	if !s.userRepo.CheckPasswordHash(password, user.PasswordHash) {
+		return fmt.Errorf("unauthorized: invalid password")
+	}
+
+	var reasonPtr *string
+	if reason != "" {
+		reasonPtr = &reason
+	}
+	if err := s.userRepo.DeactivateUser(userID, reasonPtr); err != nil {
+		return fmt.Errorf("error deactivating user error=%w", err)
+	}
+	s.invalidateCachedUser(user)
+
+	if err := s.sessionService.InvalidateUserSessions(ctx, userID); err != nil {
+		slog.Error("failed to invalidate sessions for deactivated user", "user_id", userID, "error", err)
+	}
+	// notification-service stops notifying the user once it has the event
+	s.publishAccountEvent(ctx, event.AccountDeactivated, user)
+	if err := s.sessionService.RevokeAllTrustedDevices(ctx, userID); err != nil {
+		slog.Error("failed to revoke trusted devices for deactivated user", "user_id", userID, "error", err)
+	}
+
+	// Last message the user receives until the account is reactivated
+	if user.PhoneNumber != "" {
+		confirmation := event.NotificationEventPushModel{
+			Notification: event.Notification{
+				Title: "Tai Khoan Da Vo Hieu Hoa",
+				Body:  "Tai khoan Agrisa cua ban da duoc vo hieu hoa. Dang nhap lai bang so dien thoai de kich hoat lai.",
+			},
+			Destinations: []string{user.PhoneNumber},
+		}
+		if err := s.eventPublisher.PublishNotification(ctx, confirmation); err != nil {
+			slog.Error("failed to send deactivation confirmation", "user_id", userID, "error", err)
+		}
+	}
+
+	slog.Info("user account deactivated", "user_id", userID)
+	return nil
+}
+
+// RequestReactivationOTP sends an OTP to the phone number of a deactivated
+// account so the owner can prove they still control it.
+func (s *UserService) RequestReactivationOTP(ctx context.Context, phone string) error {
+	user, err := s.userRepo.GetUserByPhone(phone)
+	if err != nil {
+		return fmt.Errorf("not_found: user not found")
+	}
+	if user.Status != models.UserStatusDeactivated {
+		return fmt.Errorf("bad_request: account is not deactivated")
+	}
+	return s.GeneratePhoneOTP(ctx, phone)
+}
+
+// ReactivateAccount restores a deactivated account after re-verifying the
+// phone number via OTP and the account password.
+func (s *UserService) ReactivateAccount(ctx context.Context, phone, otp, password string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByPhone(phone)
+	if err != nil {
+		return nil, fmt.Errorf("not_found: user not found")
+	}
+	if user.Status != models.UserStatusDeactivated {
+		return nil, fmt.Errorf("bad_request: account is not deactivated")
+	}
+	if !s.userRepo.CheckPasswordHash(password, user.PasswordHash) {
+		return nil, fmt.Errorf("unauthorized: invalid password")
+	}
+	if err := s.ValidatePhoneOTP(ctx, phone, otp); err != nil {
+		return nil, fmt.Errorf("unauthorized: incorrect otp")
+	}
+
+	status := models.UserStatusPendingVerification
+	if user.KYCVerified {
+		status = models.UserStatusActive
+	}
+	if err := s.userRepo.ReactivateUser(user.ID, status); err != nil {
+		return nil, fmt.Errorf("error reactivating user error=%w", err)
+	}
+	s.invalidateCachedUser(user)
+	if err := s.loginAttempts.Reset(ctx, user.ID); err != nil {
+		log.Printf("Failed to reset login attempts of user %s: %v", user.ID, err)
+	}
+
+	user.Status = status
+	user.PhoneVerified = true
+	user.DeactivatedAt = nil
+	user.DeactivationReason = nil
+	s.publishAccountEvent(ctx, event.AccountReactivated, user)
+
+	slog.Info("user account reactivated", "user_id", user.ID)
+	return user, nil
+}
+
+// RegisterTrustedDevice marks the caller's device as trusted after confirming
+// an OTP sent to the account's phone number.
+func (s *UserService) RegisterTrustedDevice(ctx context.Context, userID, fingerprint, deviceName, otp string, deviceInfo, ipAddress *string) (*models.TrustedDevice, error) {
+	if fingerprint == "" {
+		return nil, fmt.Errorf("bad_request: device fingerprint is required")
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("not_found: user not found")
+	}
+	if err := s.ValidatePhoneOTP(ctx, user.PhoneNumber, otp); err != nil {
+		return nil, fmt.Errorf("unauthorized: incorrect otp")
+	}
+
+	var deviceNamePtr *string
+	if deviceName != "" {
+		deviceNamePtr = &deviceName
+	}
+	device, err := s.sessionService.TrustDevice(ctx, userID, fingerprint, deviceNamePtr, deviceInfo, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("trusted device registered", "user_id", userID, "device_id", device.ID)
+	return device, nil
+}
+
+func (s *UserService) GetTrustedDevices(ctx context.Context, userID string) ([]*models.TrustedDevice, error) {
+	return s.sessionService.GetTrustedDevices(ctx, userID)
+}
+
+func (s *UserService) RevokeTrustedDevice(ctx context.Context, userID, deviceID string) error {
+	if err := s.sessionService.RevokeTrustedDevice(ctx, userID, deviceID); err != nil {
+		if strings.Contains(err.Error(), "trusted device not found") {
+			return fmt.Errorf("not_found: trusted device not found")
+		}
+		return err
+	}
+
+	slog.Info("trusted device revoked", "user_id", userID, "device_id", deviceID)
+	return nil
+}
+
+func (s *UserService) RevokeAllTrustedDevices(ctx context.Context, userID string) error {
+	if err := s.sessionService.RevokeAllTrustedDevices(ctx, userID); err != nil {
+		return err
+	}
+
+	slog.Info("all trusted devices revoked", "user_id", userID)
+	return nil
+}
+
+// GetMySessions lists the active sessions of a user, most recently active first
+func (s *UserService) GetMySessions(ctx context.Context, userID, currentSessionID string) ([]*models.ActiveSession, error) {
+	sessions, err := s.sessionService.GetUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastActiveAt.After(sessions[j].LastActiveAt)
+	})
+	result := make([]*models.ActiveSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, &models.ActiveSession{
+			UserSession: session,
+			IsCurrent:   session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+func (s *UserService) RevokeMySession(ctx context.Context, userID, sessionID string) error {
+	if err := s.sessionService.RevokeUserSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	slog.Info("session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// RevokeOtherSessions logs the user out everywhere but the session making the request
+func (s *UserService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	if currentSessionID == "" {
+		return 0, fmt.Errorf("bad_request: current session is unknown")
+	}
+
+	revoked, err := s.sessionService.RevokeOtherSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return revoked, err
+	}
+
+	slog.Info("other sessions revoked", "user_id", userID, "revoked", revoked)
+	return revoked, nil
+}
+
+func (s *UserService) publishAccountEvent(ctx context.Context, eventType event.AccountEventType, user *models.User) {
+	accountEvent := event.AccountEvent{
+		Type:        eventType,
+		UserID:      user.ID,
+		PhoneNumber: user.PhoneNumber,
+		OccurredAt:  time.Now(),
+	}
+	if err := s.accountEvents.Publish(ctx, accountEvent); err != nil {
+		slog.Error("failed to publish account event", "type", eventType, "user_id", user.ID, "error", err)
+	}
+}
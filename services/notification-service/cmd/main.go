@@ -1,84 +1,69 @@
 package main
 
 import (
+	"agrisa_utils/logging"
+	"agrisa_utils/shutdown"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"notification-service/internal/config"
+	"notification-service/internal/database/postgres"
 	"notification-service/internal/event"
 	"notification-service/internal/google"
 	"notification-service/internal/handlers"
 	"notification-service/internal/phone"
+	"notification-service/internal/profile"
+	"notification-service/internal/repository"
+	"notification-service/internal/services"
+	"notification-service/internal/zalo"
 	"os"
-	"os/signal"
-	"path/filepath"
-	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
-func setupLogging() (*os.File, error) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic: %v\n", r)
-		}
-	}()
-
-	logDir := filepath.Join("/agrisa", "log", "notification_service")
-	fmt.Println("Log directory:", logDir)
-	err := os.MkdirAll(logDir, 0o755)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %v", err)
-	}
-
-	currentTime := time.Now()
-	logFileName := fmt.Sprintf("log_%s.log", currentTime.Format("2006-01-02"))
-	logFile := filepath.Join(logDir, logFileName)
-
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
-	}
-
-	if _, err := os.Stat(logFile); err == nil {
-		absPath, err := filepath.Abs(logFile)
-		if err != nil {
-			fmt.Printf("Failed to get absolute path of log file: %v\n", err)
-		} else {
-			fmt.Printf("Log file exists at absolute path: %s\n", absPath)
-		}
-	} else if os.IsNotExist(err) {
-		fmt.Println("Log file does not exist (it will be created)")
-	} else {
-		fmt.Printf("Error checking log file existence: %v\n", err)
-	}
-
-	log.SetOutput(file)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-
-	return file, nil
-}
-
 func main() {
-	logFile, err := setupLogging()
+	logFile, err := logging.Setup("notification_service")
 	if err != nil {
 		log.Fatalf("Failed to set up logging: %v", err)
 	}
 	defer logFile.Close()
 	cfg := config.New()
 	app := fiber.New()
+	app.Use(logging.FiberMiddleware())
 	app.Get("/checkhealth", func(c fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).SendString("Policy service is healthy")
 	})
 
+	// Every protected route needs a token from auth-service; the handlers gate routes by role
+	authMiddleware := handlers.NewAuthMiddleware(cfg.JWTSecret, cfg.APIKey)
+	app.Use("/notification/protected", authMiddleware.Authenticate)
+
 	emailService := google.NewEmailService(cfg.GoogleConfig.MailUsername, cfg.GoogleConfig.MailPassword)
 
 	emailHandler := handlers.NewEmailHandler(emailService)
 
 	emailHandler.Register(app)
 
-	phoneService := phone.NewPhoneService(cfg.PhoneServerConfig.Host, cfg.PhoneServerConfig.Port, cfg.PhoneServerConfig.Username, cfg.PhoneServerConfig.Password)
+	db, err := postgres.Connect(cfg.PostgresCfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	deliveryLog := repository.NewDeliveryLogRepository(db)
+	inbox := repository.NewInboxRepository(db)
+
+	smsLog := repository.NewSMSRepository(db)
+	recipientAccounts := repository.NewRecipientAccountRepository(db)
+
+	phoneService := phone.NewPhoneService(cfg.SMSCfg)
+
+	// Users who prefer Zalo get their notifications from the Official Account, when one is configured
+	preferences := repository.NewPreferenceRepository(db)
+	zaloService := zalo.NewOAService(cfg.ZaloCfg, repository.NewZaloTokenRepository(db))
+	if zaloService == nil {
+		log.Printf("Zalo Official Account not configured, notifications go by SMS only")
+	}
 
 	// Setup queue consumer
 	consumerConfig := &event.ConsumerConfig{
@@ -87,38 +72,101 @@ func main() {
 			cfg.RabbitMQCfg.Password,
 			cfg.RabbitMQCfg.Port),
 		QueueName:       "notifications",
+		RetryQueue:      "notifications.retry",
 		DeadLetterQueue: "notifications.dlq",
 		PrefetchCount:   10,
+		MaxRetries:      cfg.DeliveryCfg.MaxRetries,
+		MaxAttempts:     cfg.DeliveryCfg.MaxAttempts,
 	}
 
-	consumer, err := event.NewQueueConsumer(consumerConfig, emailService, phoneService)
+	consumer, err := event.NewQueueConsumer(consumerConfig, emailService, phoneService, deliveryLog, inbox, smsLog, recipientAccounts, preferences, zaloService)
 	if err != nil {
 		log.Fatalf("Failed to setup queue consumer: %v", err)
 	}
 
-	// Start consuming in goroutine
-	go func() {
-		if err := consumer.StartConsuming(context.Background()); err != nil {
-			log.Printf("Consumer error: %v", err)
+	deliveryHandler := handlers.NewDeliveryHandler(deliveryLog, consumer)
+	deliveryHandler.Register(app)
+
+	inboxHandler := handlers.NewInboxHandler(inbox)
+	inboxHandler.Register(app)
+
+	smsHandler := handlers.NewSMSHandler(phoneService, smsLog)
+	smsHandler.Register(app)
+
+	preferenceHandler := handlers.NewPreferenceHandler(preferences, cfg.ZaloCfg.Enabled())
+	preferenceHandler.Register(app)
+
+	// FCM devices, topics and segment sends, when a Firebase service account is configured
+	if cfg.GoogleConfig.FirebaseCredentials != "" {
+		firebaseService, err := google.NewFirebaseService(&google.FirebaseConfig{
+			CredentialsPath: cfg.GoogleConfig.FirebaseCredentials,
+			ProjectID:       cfg.GoogleConfig.FirebaseProjectID,
+		})
+		if err != nil {
+			log.Fatalf("Failed to set up Firebase: %v", err)
 		}
-	}()
+		pushHandler := handlers.NewPushHandler(services.NewPushService(firebaseService, repository.NewPushDeviceRepository(db)))
+		pushHandler.Register(app)
+	} else {
+		log.Printf("Firebase not configured, push device routes are disabled")
+	}
+
+	publisher, err := consumer.NewPublisher()
+	if err != nil {
+		log.Fatalf("Failed to setup notification publisher: %v", err)
+	}
+	campaignService := services.NewCampaignService(repository.NewCampaignRepository(db),
+		profile.NewAudienceClient(cfg.CampaignCfg.ProfileServiceURL), publisher, cfg.CampaignCfg)
+	campaignHandler := handlers.NewCampaignHandler(campaignService)
+	campaignHandler.Register(app)
+
+	coordinator := shutdown.New()
 
-	// Add cleanup on shutdown
-	defer consumer.Close()
+	// Consume until the server has drained, the message in hand is finished first
+	coordinator.Go("notification-consumer", func(ctx context.Context) {
+		if err := consumer.StartConsuming(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Consumer error: %v", err)
+		}
+	})
+	// Deactivated users are not notified until they reactivate
+	coordinator.Go("account-event-consumer", func(ctx context.Context) {
+		if err := consumer.StartAccountEventConsumer(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Account event consumer error: %v", err)
+		}
+	})
+	if interval := cfg.DeliveryCfg.DLQReprocessIntervalMinutes; interval > 0 {
+		coordinator.Go("dlq-reprocessor", func(ctx context.Context) {
+			consumer.StartDLQReprocessor(ctx, time.Duration(interval)*time.Minute, cfg.DeliveryCfg.DLQReprocessBatchSize)
+		})
+	}
 
-	shutdownChan := make(chan os.Signal, 1)
-	doneChan := make(chan bool, 1)
+	// Unhealthy SMS gateways are tried last until they pass a check again
+	if interval := cfg.SMSCfg.HealthCheckIntervalSeconds; interval > 0 {
+		coordinator.Go("sms-health-checker", func(ctx context.Context) {
+			phoneService.StartHealthChecks(ctx, time.Duration(interval)*time.Second)
+		})
+	}
 
-	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
+	// Queue the batches of campaigns as they come due
+	coordinator.Go("campaign-scheduler", func(ctx context.Context) {
+		campaignService.StartScheduler(ctx, time.Duration(cfg.CampaignCfg.PollIntervalSeconds)*time.Second)
+	})
 
 	go func() {
 		log.Printf("Starting server on port %s", cfg.Port)
 		if err := app.Listen(fmt.Sprintf("0.0.0.0:%s", cfg.Port)); err != nil {
-			log.Fatalf("Error starting server: %v", err)
+			coordinator.Fail(fmt.Errorf("error starting server: %w", err))
 		}
-		doneChan <- true
 	}()
+	coordinator.Server("http", app.ShutdownWithContext)
+
+	coordinator.Closer("publisher", publisher.Close)
+	coordinator.Closer("rabbitmq", consumer.Close)
+	coordinator.Closer("postgres", db.Close)
 
-	<-shutdownChan
-	log.Println("Shutting down server...")
+	if err := coordinator.Wait(); err != nil {
+		log.Printf("notification-service stopped: %v", err)
+		logFile.Close()
+		os.Exit(1)
+	}
 }
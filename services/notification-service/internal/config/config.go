@@ -1,12 +1,54 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+)
 
 type NotificationService struct {
-	Port              string
-	RabbitMQCfg       RabbitMQConfig
-	GoogleConfig      GoogleConfig
-	PhoneServerConfig PhoneServerConfig
+	Port         string
+	APIKey       string
+	JWTSecret    string // shared with auth-service, which signs the tokens
+	RabbitMQCfg  RabbitMQConfig
+	GoogleConfig GoogleConfig
+	PostgresCfg  PostgresConfig
+	DeliveryCfg  DeliveryConfig
+	CampaignCfg  CampaignConfig
+	SMSCfg       SMSConfig
+	ZaloCfg      ZaloConfig
+}
+
+type PostgresConfig struct {
+	Host        string
+	Port        string
+	User        string
+	Password    string
+	DB          string
+	AutoMigrate bool
+	// Connect whatever the schema version, for cmd/migrate which manages the schema itself
+	SkipSchemaCheck bool
+}
+
+type DeliveryConfig struct {
+	// Retries of a failing notification before it is dead-lettered
+	MaxRetries int
+	// Attempts over all DLQ replays after which a notification is given up
+	MaxAttempts int
+	// How often the DLQ is replayed, in minutes; 0 leaves it to the reprocess endpoint
+	DLQReprocessIntervalMinutes int
+	DLQReprocessBatchSize       int
+}
+
+type CampaignConfig struct {
+	ProfileServiceURL string
+	// Farmers per queued notification
+	BatchSize int
+	// Recipients per second, to stay within the SMS gateway and FCM quotas; 0 for no limit
+	SMSPerSecond  float64
+	PushPerSecond float64
+	// How often due campaigns are looked for, in seconds
+	PollIntervalSeconds int
 }
 
 type RabbitMQConfig struct {
@@ -15,11 +57,39 @@ type RabbitMQConfig struct {
 	Port     string
 }
 
-type PhoneServerConfig struct {
-	Host     string
-	Port     string
+type SMSConfig struct {
+	// Gateways in the order they are tried; the first is the PHONE_* gateway
+	Providers []SMSProviderConfig
+	// How often the health of the gateways is checked, in seconds
+	HealthCheckIntervalSeconds int
+}
+
+type SMSProviderConfig struct {
+	Name     string
+	URL      string
 	Username string
 	Password string
+	// Price of one SMS segment sent to one recipient
+	CostPerSegment float64
+	// Key the gateway signs its delivery receipts with; receipts are not accepted without one
+	WebhookSecret string
+}
+
+// ZaloConfig is the Zalo Official Account notifications are sent from as ZNS template messages
+type ZaloConfig struct {
+	AppID     string
+	SecretKey string
+	// Refresh token the OA was authorized with, used until a refreshed pair is stored
+	RefreshToken string
+	APIURL       string
+	OAuthURL     string
+	// Template with title and content parameters that notifications are sent through
+	NotificationTemplateID string
+}
+
+// Enabled reports whether an OA is configured; without one every notification goes by SMS
+func (c ZaloConfig) Enabled() bool {
+	return c.AppID != "" && c.SecretKey != "" && c.NotificationTemplateID != ""
 }
 
 type GoogleConfig struct {
@@ -31,7 +101,9 @@ type GoogleConfig struct {
 
 func New() *NotificationService {
 	return &NotificationService{
-		Port: getEnvOrDefault("NOTIFICATION_SERVICE_PORT", "8088"),
+		Port:      getEnvOrDefault("NOTIFICATION_SERVICE_PORT", "8088"),
+		APIKey:    getEnvOrDefault("API_KEY", ""),
+		JWTSecret: getEnvOrDefault("JWT_SECRET", "default-secret"),
 		RabbitMQCfg: RabbitMQConfig{
 			Username: getEnvOrDefault("RABBITMQ_USER", "admin"),
 			Password: getEnvOrDefault("RABBITMQ_PWD", "admin"),
@@ -43,11 +115,38 @@ func New() *NotificationService {
 			FirebaseCredentials: getEnvOrDefault("FIREBASE_SERVICE_ACCOUNT_KEY", ""),
 			FirebaseProjectID:   getEnvOrDefault("FIREBASE_PROJECT_ID", ""),
 		},
-		PhoneServerConfig: PhoneServerConfig{
-			Host:     getEnvOrDefault("PHONE_HOST", ""),
-			Port:     getEnvOrDefault("PHONE_PORT", ""),
-			Username: getEnvOrDefault("PHONE_USERNAME", ""),
-			Password: getEnvOrDefault("PHONE_PASSWORD", ""),
+		PostgresCfg: PostgresConfig{
+			Host:        getEnvOrDefault("POSTGRES_HOST", "localhost"),
+			Port:        getEnvOrDefault("POSTGRES_PORT", "5432"),
+			User:        getEnvOrDefault("POSTGRES_USER", "postgres"),
+			Password:    getEnvOrDefault("POSTGRES_PASSWORD", ""),
+			DB:          getEnvOrDefault("POSTGRES_DB", "agrisa"),
+			AutoMigrate: getEnvOrDefault("DB_AUTO_MIGRATE", "true") == "true",
+		},
+		DeliveryCfg: DeliveryConfig{
+			MaxRetries:                  getEnvIntOrDefault("NOTIFICATION_MAX_RETRIES", 3),
+			MaxAttempts:                 getEnvIntOrDefault("NOTIFICATION_MAX_ATTEMPTS", 10),
+			DLQReprocessIntervalMinutes: getEnvIntOrDefault("NOTIFICATION_DLQ_REPROCESS_INTERVAL_MINUTES", 30),
+			DLQReprocessBatchSize:       getEnvIntOrDefault("NOTIFICATION_DLQ_REPROCESS_BATCH_SIZE", 100),
+		},
+		SMSCfg: SMSConfig{
+			Providers:                  smsProviders(),
+			HealthCheckIntervalSeconds: getEnvIntOrDefault("SMS_HEALTH_CHECK_INTERVAL_SECONDS", 60),
+		},
+		ZaloCfg: ZaloConfig{
+			AppID:                  getEnvOrDefault("ZALO_APP_ID", ""),
+			SecretKey:              getEnvOrDefault("ZALO_SECRET_KEY", ""),
+			RefreshToken:           getEnvOrDefault("ZALO_REFRESH_TOKEN", ""),
+			APIURL:                 getEnvOrDefault("ZALO_API_URL", "https://business.openapi.zalo.me"),
+			OAuthURL:               getEnvOrDefault("ZALO_OAUTH_URL", "https://oauth.zaloapp.com/v4/oa/access_token"),
+			NotificationTemplateID: getEnvOrDefault("ZALO_NOTIFICATION_TEMPLATE_ID", ""),
+		},
+		CampaignCfg: CampaignConfig{
+			ProfileServiceURL:   getEnvOrDefault("PROFILE_SERVICE_URL", "http://profile-service:8087"),
+			BatchSize:           getEnvIntOrDefault("CAMPAIGN_BATCH_SIZE", 500),
+			SMSPerSecond:        getEnvFloatOrDefault("CAMPAIGN_SMS_PER_SECOND", 20),
+			PushPerSecond:       getEnvFloatOrDefault("CAMPAIGN_PUSH_PER_SECOND", 500),
+			PollIntervalSeconds: getEnvIntOrDefault("CAMPAIGN_POLL_INTERVAL_SECONDS", 30),
 		},
 	}
 }
@@ -58,3 +157,47 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// smsProviders lists the PHONE_* gateway followed by the fallbacks named in SMS_FALLBACK_PROVIDERS,
+// each configured by SMS_<NAME>_URL, _USERNAME, _PASSWORD, _COST_PER_SEGMENT and _WEBHOOK_SECRET
+func smsProviders() []SMSProviderConfig {
+	providers := []SMSProviderConfig{{
+		Name:           "primary",
+		URL:            getEnvOrDefault("PHONE_HOST", "") + ":" + getEnvOrDefault("PHONE_PORT", ""),
+		Username:       getEnvOrDefault("PHONE_USERNAME", ""),
+		Password:       getEnvOrDefault("PHONE_PASSWORD", ""),
+		CostPerSegment: getEnvFloatOrDefault("PHONE_COST_PER_SEGMENT", 0),
+		WebhookSecret:  getEnvOrDefault("PHONE_WEBHOOK_SECRET", ""),
+	}}
+
+	for _, name := range strings.Split(getEnvOrDefault("SMS_FALLBACK_PROVIDERS", ""), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "SMS_" + strings.ToUpper(name) + "_"
+		providers = append(providers, SMSProviderConfig{
+			Name:           name,
+			URL:            getEnvOrDefault(prefix+"URL", ""),
+			Username:       getEnvOrDefault(prefix+"USERNAME", ""),
+			Password:       getEnvOrDefault(prefix+"PASSWORD", ""),
+			CostPerSegment: getEnvFloatOrDefault(prefix+"COST_PER_SEGMENT", 0),
+			WebhookSecret:  getEnvOrDefault(prefix+"WEBHOOK_SECRET", ""),
+		})
+	}
+	return providers
+}
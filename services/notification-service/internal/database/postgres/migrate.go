@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/jmoiron/sqlx"
+
+	"agrisa/migration"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// RunMigration runs a migration command such as up, down-to <version> or status against the
+// service database. A database created before migrations is adopted at the baseline first.
+func RunMigration(db *sqlx.DB, command string, args ...string) error {
+	migrations, err := serviceMigrations()
+	if err != nil {
+		return err
+	}
+	return migrations.Run(context.Background(), db.DB, command, args...)
+}
+
+// CheckMigration fails with migration.ErrSchemaOutdated when the service database is missing
+// migrations of this build
+func CheckMigration(db *sqlx.DB) error {
+	migrations, err := serviceMigrations()
+	if err != nil {
+		return err
+	}
+	return migrations.Check(context.Background(), db.DB)
+}
+
+func serviceMigrations() (migration.Migrations, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return migration.Migrations{}, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migration.Migrations{FS: files, VersionTable: "notification_service_schema_migrations", BaselineTable: "delivery_log"}, nil
+}
@@ -0,0 +1,29 @@
+-- Baseline schema of the service, which kept no data before deliveries were tracked.
+-- +goose Up
+-- One row per notification message, updated on every delivery attempt. Messages are told apart
+-- by the hash of their body, as the IDs given by publishers are short and may repeat. The payload
+-- is kept so a notification given up on can still be inspected and sent by hand.
+CREATE TABLE delivery_log (
+    id BIGSERIAL PRIMARY KEY,
+    message_hash CHAR(64) NOT NULL,
+    notification_id VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    recipient_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+
+    CONSTRAINT uq_delivery_log_message_hash UNIQUE (message_hash),
+    CONSTRAINT chk_delivery_log_status CHECK (status IN ('processing', 'retrying', 'delivered', 'dead_lettered', 'requeued', 'failed'))
+);
+
+CREATE INDEX idx_delivery_log_notification_id ON delivery_log(notification_id);
+CREATE INDEX idx_delivery_log_created_at ON delivery_log(created_at);
+CREATE INDEX idx_delivery_log_status ON delivery_log(status);
+
+-- +goose Down
+DROP TABLE IF EXISTS delivery_log;
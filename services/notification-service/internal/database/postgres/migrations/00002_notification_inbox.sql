@@ -0,0 +1,22 @@
+-- In-app notifications kept for each recipient, so they can be read again after the push is
+-- dismissed. A message retried by the consumer adds its entries once.
+-- +goose Up
+CREATE TABLE notification_inbox (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    message_hash CHAR(64) NOT NULL,
+    notification_id VARCHAR(100) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_notification_inbox_user_message UNIQUE (user_id, message_hash)
+);
+
+CREATE INDEX idx_notification_inbox_user_created ON notification_inbox(user_id, created_at DESC);
+CREATE INDEX idx_notification_inbox_unread ON notification_inbox(user_id) WHERE read_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_inbox;
@@ -0,0 +1,43 @@
+-- Notification campaigns sent to the farmers of an audience at a scheduled time. Each batch of a
+-- campaign is one queued notification per channel, linked to the campaign in delivery_log so the
+-- campaign can report how its deliveries went.
+-- +goose Up
+CREATE TABLE campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB,
+    channels TEXT[] NOT NULL,
+    audience JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    -- Last farmer batched, so an interrupted send resumes after it
+    audience_cursor VARCHAR(255),
+    batches_sent INT NOT NULL DEFAULT 0,
+    recipient_count INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_campaign_status CHECK (status IN ('scheduled', 'sending', 'sent', 'cancelled')),
+    CONSTRAINT chk_campaign_channels CHECK (CARDINALITY(channels) > 0 AND channels <@ ARRAY['in_app', 'sms']::TEXT[])
+);
+
+CREATE INDEX idx_campaigns_due ON campaigns(scheduled_at) WHERE status IN ('scheduled', 'sending');
+
+ALTER TABLE delivery_log
+    ADD COLUMN campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL,
+    ADD COLUMN recipient_count INT NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_delivery_log_campaign ON delivery_log(campaign_id) WHERE campaign_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_delivery_log_campaign;
+ALTER TABLE delivery_log
+    DROP COLUMN IF EXISTS recipient_count,
+    DROP COLUMN IF EXISTS campaign_id;
+DROP TABLE IF EXISTS campaigns;
@@ -0,0 +1,27 @@
+-- One row per recipient of an SMS accepted by a gateway, updated by the delivery receipts the
+-- gateway sends back. Cost is what the recipient's copy was billed, from the per-segment price of
+-- the gateway when it was sent.
+-- +goose Up
+CREATE TABLE sms_messages (
+    id BIGSERIAL PRIMARY KEY,
+    message_hash CHAR(64) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_message_id VARCHAR(100),
+    phone_number VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    segments INT NOT NULL,
+    cost NUMERIC(12, 4) NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_sms_messages_status CHECK (status IN ('queued', 'sent', 'delivered', 'failed'))
+);
+
+CREATE INDEX idx_sms_messages_provider_message ON sms_messages(provider, provider_message_id);
+CREATE INDEX idx_sms_messages_message_hash ON sms_messages(message_hash);
+CREATE INDEX idx_sms_messages_created_at ON sms_messages(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS sms_messages;
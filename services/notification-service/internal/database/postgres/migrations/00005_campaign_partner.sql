@@ -0,0 +1,11 @@
+-- Campaigns belong to the insurance partner they were created for, so the admins of that partner
+-- share them and no one else's are visible. Campaigns without a partner were created by platform
+-- admins.
+-- +goose Up
+ALTER TABLE campaigns ADD COLUMN partner_id VARCHAR(255);
+
+CREATE INDEX idx_campaigns_partner ON campaigns(partner_id, scheduled_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_campaigns_partner;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS partner_id;
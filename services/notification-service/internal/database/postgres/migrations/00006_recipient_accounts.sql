@@ -0,0 +1,17 @@
+-- Status of the accounts of notification recipients, kept from the account events of auth-service.
+-- Deactivated users are not notified until they reactivate. status_changed_at is when the last
+-- applied event happened, so an older event arriving late is ignored.
+-- +goose Up
+CREATE TABLE recipient_accounts (
+    user_id VARCHAR(100) PRIMARY KEY,
+    phone_number VARCHAR(20),
+    deactivated BOOLEAN NOT NULL,
+    status_changed_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recipient_accounts_deactivated_phone
+    ON recipient_accounts(RIGHT(REGEXP_REPLACE(phone_number, '\D', '', 'g'), 9)) WHERE deactivated;
+
+-- +goose Down
+DROP TABLE IF EXISTS recipient_accounts;
@@ -0,0 +1,21 @@
+-- Zalo Official Account channel. zalo_oa_tokens holds the one token pair of the OA; Zalo rotates the
+-- refresh token on every refresh, so the pair is kept here rather than in the environment.
+-- notification_preferences is the channel a user wants their notifications on, SMS by default.
+-- +goose Up
+CREATE TABLE zalo_oa_tokens (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE notification_preferences (
+    user_id VARCHAR(100) PRIMARY KEY,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('sms', 'zalo')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS zalo_oa_tokens;
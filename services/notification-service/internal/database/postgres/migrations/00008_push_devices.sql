@@ -0,0 +1,24 @@
+-- FCM devices of users and the topics they are subscribed to. A device token belongs to the user
+-- last signed in on it. push_topic_subscriptions is what every device of the user should be
+-- subscribed to, the per-province and per-crop topics segment sends are addressed to.
+-- +goose Up
+CREATE TABLE push_devices (
+    token TEXT PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    platform VARCHAR(20) NOT NULL CHECK (platform IN ('android', 'ios', 'web')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_push_devices_user ON push_devices(user_id);
+
+CREATE TABLE push_topic_subscriptions (
+    user_id VARCHAR(100) NOT NULL,
+    topic VARCHAR(200) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, topic)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS push_topic_subscriptions;
+DROP TABLE IF EXISTS push_devices;
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"fmt"
+	"notification-service/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// Connect opens the delivery tracking database, checks that it answers and migrates its schema
+func Connect(cfg config.PostgresConfig) (*sqlx.DB, error) {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DB)
+
+	db, err := sqlx.Connect("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Bring the schema up to date; with auto migration off, migrations are run with cmd/migrate
+	// and the service refuses to start until they are
+	switch {
+	case cfg.SkipSchemaCheck:
+	case cfg.AutoMigrate:
+		if err := RunMigration(db, "up"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	default:
+		if err := CheckMigration(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
@@ -0,0 +1,104 @@
+package event
+
+import (
+	"agrisa_utils/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// accountEventsExchange is the fanout exchange auth-service publishes account changes on
+const accountEventsExchange = "account_events"
+
+// accountEventsQueue is the queue of the account events notification-service keeps
+const accountEventsQueue = "notification.account_events"
+
+// Account events notification-service acts on
+const (
+	accountDeactivated = "account_deactivated"
+	accountReactivated = "account_reactivated"
+)
+
+type accountEvent struct {
+	Type        string    `json:"type"`
+	UserID      string    `json:"user_id"`
+	PhoneNumber string    `json:"phone_number"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// StartAccountEventConsumer records the deactivations and reactivations of accounts until ctx is
+// done, so deactivated users are not notified
+func (q *QueueConsumer) StartAccountEventConsumer(ctx context.Context) error {
+	ch, err := q.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open account event channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(accountEventsExchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare account event exchange: %w", err)
+	}
+	if _, err := ch.QueueDeclare(accountEventsQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare account event queue: %w", err)
+	}
+	if err := ch.QueueBind(accountEventsQueue, "", accountEventsExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind account event queue: %w", err)
+	}
+
+	msgs, err := ch.Consume(accountEventsQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register account event consumer: %w", err)
+	}
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("account event channel closed")
+			}
+			msgCtx := logging.FromMessageHeaders(ctx, msg.Headers)
+			if err := q.handleAccountEvent(msg.Body); err != nil {
+				slog.ErrorContext(msgCtx, "Failed to handle account event", "error", err)
+				msg.Nack(false, true)
+			} else {
+				msg.Ack(false)
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (q *QueueConsumer) handleAccountEvent(body []byte) error {
+	var accountEvent accountEvent
+	if err := json.Unmarshal(body, &accountEvent); err != nil {
+		// Redelivering a malformed event would not make it readable
+		slog.Error("Dropping malformed account event", "body", string(body), "error", err)
+		return nil
+	}
+
+	var deactivated bool
+	switch accountEvent.Type {
+	case accountDeactivated:
+		deactivated = true
+	case accountReactivated:
+		deactivated = false
+	default:
+		return nil
+	}
+	if accountEvent.UserID == "" {
+		slog.Error("Dropping account event without user", "type", accountEvent.Type)
+		return nil
+	}
+
+	var phoneNumber *string
+	if accountEvent.PhoneNumber != "" {
+		phoneNumber = &accountEvent.PhoneNumber
+	}
+	return q.accounts.SetDeactivated(accountEvent.UserID, phoneNumber, deactivated, accountEvent.OccurredAt)
+}
@@ -1,13 +1,20 @@
 package event
 
 import (
+	"agrisa_utils/logging"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"notification-service/internal/google"
+	"notification-service/internal/models"
 	"notification-service/internal/phone"
+	"notification-service/internal/repository"
+	"notification-service/internal/zalo"
 	"time"
 
 	"github.com/streadway/amqp"
@@ -19,18 +26,36 @@ type QueueConsumer struct {
 	firebaseService *google.FirebaseService
 	emailService    *google.EmailService
 	phoneService    *phone.PhoneService
+	deliveryLog     *repository.DeliveryLogRepository
+	inbox           *repository.InboxRepository
+	smsLog          *repository.SMSRepository
+	accounts        *repository.RecipientAccountRepository
+	preferences     *repository.PreferenceRepository
+	zalo            *zalo.OAService // nil when no Official Account is configured
 	queueName       string
+	retryQueue      string
 	deadLetterQueue string
+	maxRetries      int
+	maxAttempts     int
 }
 
 type ConsumerConfig struct {
-	RabbitMQURL     string
-	QueueName       string
+	RabbitMQURL string
+	QueueName   string
+	// Failed messages wait out their backoff in RetryQueue, which hands them back to QueueName
+	RetryQueue      string
 	DeadLetterQueue string
 	PrefetchCount   int
+	// Retries of a message before it is dead-lettered
+	MaxRetries int
+	// Attempts over all DLQ replays after which a message is given up
+	MaxAttempts int
 }
 
-func NewQueueConsumer(cfg *ConsumerConfig, email *google.EmailService, phoneService *phone.PhoneService) (*QueueConsumer, error) {
+// retryCountHeader counts the retries of a message since it was published or replayed from the DLQ
+const retryCountHeader = "x-retry-count"
+
+func NewQueueConsumer(cfg *ConsumerConfig, email *google.EmailService, phoneService *phone.PhoneService, deliveryLog *repository.DeliveryLogRepository, inbox *repository.InboxRepository, smsLog *repository.SMSRepository, accounts *repository.RecipientAccountRepository, preferences *repository.PreferenceRepository, zaloService *zalo.OAService) (*QueueConsumer, error) {
 	conn, err := amqp.Dial(cfg.RabbitMQURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
@@ -51,7 +76,7 @@ func NewQueueConsumer(cfg *ConsumerConfig, email *google.EmailService, phoneServ
 		return nil, fmt.Errorf("failed to set QoS: %v", err)
 	}
 
-	// Declare main queue with DLX
+	// Declare main queue
 
 	_, err = ch.QueueDeclare(
 		cfg.QueueName,
@@ -65,6 +90,22 @@ func NewQueueConsumer(cfg *ConsumerConfig, email *google.EmailService, phoneServ
 		return nil, fmt.Errorf("failed to declare queue: %v", err)
 	}
 
+	// Declare retry queue, whose expired messages go back to the main queue
+	_, err = ch.QueueDeclare(
+		cfg.RetryQueue,
+		true,
+		false,
+		false,
+		false,
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": cfg.QueueName,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare retry queue: %v", err)
+	}
+
 	// Declare dead letter queue
 	_, err = ch.QueueDeclare(
 		cfg.DeadLetterQueue,
@@ -83,8 +124,17 @@ func NewQueueConsumer(cfg *ConsumerConfig, email *google.EmailService, phoneServ
 		channel:         ch,
 		emailService:    email,
 		phoneService:    phoneService,
+		deliveryLog:     deliveryLog,
+		inbox:           inbox,
+		smsLog:          smsLog,
+		accounts:        accounts,
+		preferences:     preferences,
+		zalo:            zaloService,
 		queueName:       cfg.QueueName,
+		retryQueue:      cfg.RetryQueue,
 		deadLetterQueue: cfg.DeadLetterQueue,
+		maxRetries:      cfg.MaxRetries,
+		maxAttempts:     cfg.MaxAttempts,
 	}, nil
 }
 
@@ -104,24 +154,17 @@ func (q *QueueConsumer) StartConsuming(ctx context.Context) error {
 
 	for {
 		select {
-		case msg := <-msgs:
-			if err := q.processMessage(ctx, msg); err != nil {
-				log.Printf("Error processing message: %v", err)
-
-				// Check retry count
-				retryCount := 0
-				if val, ok := msg.Headers["x-retry-count"].(int32); ok {
-					retryCount = int(val)
-				}
-
-				if retryCount < 3 {
-					// Requeue with exponential backoff
-					q.requeueMessage(msg, retryCount+1)
-				} else {
-					// Send to DLQ
-					msg.Nack(false, false)
-					log.Printf("Message sent to DLQ after %d retries", retryCount)
-				}
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("delivery channel closed")
+			}
+			// Messages keep the request ID they were published under. A message being processed
+			// when ctx is cancelled is finished, so a shutdown does not send it twice.
+			msgCtx := logging.FromMessageHeaders(context.WithoutCancel(ctx), msg.Headers)
+			if err := q.handleMessage(msgCtx, msg); err != nil {
+				// Neither retried nor dead-lettered, so it is delivered again rather than lost
+				slog.ErrorContext(msgCtx, "Failed to handle message", "error", err)
+				msg.Nack(false, true)
 			} else {
 				msg.Ack(false)
 			}
@@ -132,36 +175,259 @@ func (q *QueueConsumer) StartConsuming(ctx context.Context) error {
 	}
 }
 
-func (q *QueueConsumer) processMessage(ctx context.Context, msg amqp.Delivery) error {
+// handleMessage delivers a message and records the outcome in the delivery log. A failed delivery
+// is retried with backoff, then dead-lettered; failures retrying cannot fix are dead-lettered
+// right away. An error is returned only when the message could not be passed on.
+func (q *QueueConsumer) handleMessage(ctx context.Context, msg amqp.Delivery) error {
+	hash := messageHash(msg.Body)
+	retryCount := retryCountOf(msg)
+
 	var notification NotificationMessage
-	if err := json.Unmarshal(msg.Body, &notification); err != nil {
-		return fmt.Errorf("failed to unmarshal message: %v", err)
+	err := json.Unmarshal(msg.Body, &notification)
+	if err != nil {
+		err = permanent(fmt.Errorf("failed to unmarshal message: %v", err))
+	} else {
+		// Tracking is best effort, a notification is still sent while the delivery log is down
+		attempts, trackErr := q.deliveryLog.StartAttempt(hash, notification.ID, string(notification.Type), recipientOf(&notification),
+			campaignOf(&notification), recipientCountOf(&notification), msg.Body)
+		if trackErr != nil {
+			slog.ErrorContext(ctx, "Failed to record delivery attempt", "message_hash", hash, "error", trackErr)
+		} else if attempts == 0 {
+			slog.InfoContext(ctx, "Skipping notification already delivered", "id", notification.ID, "message_hash", hash)
+			return nil
+		}
+		err = q.processMessage(ctx, hash, &notification)
+	}
+
+	if err == nil {
+		q.setStatus(ctx, hash, models.DeliveryDelivered, nil)
+		return nil
+	}
+	slog.ErrorContext(ctx, "Error processing message", "id", notification.ID, "type", notification.Type, "retry", retryCount, "error", err)
+
+	if !isPermanent(err) && retryCount < q.maxRetries {
+		if err := q.publishRetry(ctx, msg, retryCount+1); err != nil {
+			return err
+		}
+		q.setStatus(ctx, hash, models.DeliveryRetrying, err)
+		return nil
 	}
 
+	if err := q.publishDeadLetter(ctx, msg); err != nil {
+		return err
+	}
+	q.setStatus(ctx, hash, models.DeliveryDeadLettered, err)
+	log.Printf("Message sent to DLQ after %d retries", retryCount)
+	return nil
+}
+
+func (q *QueueConsumer) processMessage(ctx context.Context, hash string, notification *NotificationMessage) error {
 	switch notification.Type {
 	case TypeSMS:
-		return q.processSMS(ctx, &notification)
+		return q.processSMS(ctx, hash, notification)
+	case TypeInApp:
+		return q.processInApp(ctx, hash, notification)
+	case TypeZalo:
+		return q.processZalo(ctx, hash, notification)
 		//	case TypeEmail:
-		//		return q.processEmailNotification(ctx, &notification)
+		//		return q.processEmailNotification(ctx, notification)
 	default:
-		return fmt.Errorf("unsupported notification type: %s", notification.Type)
+		return permanent(fmt.Errorf("unsupported notification type: %s", notification.Type))
 	}
 }
 
-func (q *QueueConsumer) processSMS(ctx context.Context, notif *NotificationMessage) error {
+// processSMS sends an SMS through the first gateway that takes it and records its recipients,
+// whose delivery receipts the gateway reports later
+func (q *QueueConsumer) processSMS(ctx context.Context, hash string, notif *NotificationMessage) error {
 	payloadBytes, err := json.Marshal(notif.Payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %v", err)
 	}
 	var smsPayload NotificationEventPushModelPayload
 	if err := json.Unmarshal(payloadBytes, &smsPayload); err != nil {
-		return fmt.Errorf("failed to unmarshal push payload: %v", err)
+		return permanent(fmt.Errorf("failed to unmarshal push payload: %v", err))
+	}
+	slog.InfoContext(ctx, "SMS event receive", "payload", smsPayload)
+
+	destinations, err := q.activeDestinations(notif, smsPayload.Payload.Destinations)
+	if err != nil {
+		return err
 	}
-	slog.Info("SMS event receive", "payload", smsPayload)
-	err = q.phoneService.SendSMS(smsPayload.Payload.Notification.Title, smsPayload.Payload.Notification.Body, smsPayload.Payload.Destinations)
+	if len(destinations) == 0 {
+		slog.InfoContext(ctx, "Skipping SMS to deactivated accounts only", "id", notif.ID)
+		return nil
+	}
+	if q.sendByZalo(ctx, hash, notif, smsPayload.Payload.Notification, destinations) {
+		return nil
+	}
+	result, err := q.phoneService.Send(ctx, smsPayload.Payload.Notification.Title, smsPayload.Payload.Notification.Body, destinations)
 	if err != nil {
+		var rejected *phone.RejectedError
+		if errors.As(err, &rejected) {
+			return permanent(fmt.Errorf("SMS rejected: %w", err))
+		}
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
+
+	// The SMS is out, so failing to record it must not send it again
+	var providerMessageID *string
+	if result.MessageID != "" {
+		providerMessageID = &result.MessageID
+	}
+	if err := q.smsLog.RecordSent(hash, result.Provider, providerMessageID, destinations, result.Segments, result.CostPerRecipient); err != nil {
+		slog.ErrorContext(ctx, "Failed to record sent SMS", "message_hash", hash, "provider", result.Provider, "error", err)
+	}
+	return nil
+}
+
+// sendByZalo sends an SMS addressed to a user who prefers Zalo through the Official Account
+// instead, and reports whether it went out. The SMS is sent as usual when it did not, so a user not
+// on Zalo or Zalo being down costs no notification.
+func (q *QueueConsumer) sendByZalo(ctx context.Context, hash string, notif *NotificationMessage, content Notification, destinations []string) bool {
+	if q.zalo == nil || notif.RecipientID == "" || notif.CampaignID != "" {
+		return false
+	}
+	preference, err := q.preferences.Get(notif.RecipientID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get notification preference, sending SMS", "user_id", notif.RecipientID, "error", err)
+		return false
+	}
+	if preference.Channel != models.ChannelZalo {
+		return false
+	}
+
+	for i, destination := range destinations {
+		// The tracking ID tells the numbers of one notification apart in the OA statistics
+		msgID, err := q.zalo.SendNotification(ctx, destination, content.Title, content.Body, fmt.Sprintf("%.32s-%d", hash, i))
+		if err != nil {
+			if i > 0 {
+				// Numbers already sent to would get the SMS as well, so the rest is given up
+				slog.ErrorContext(ctx, "Zalo message failed after reaching some numbers", "id", notif.ID, "sent", i, "error", err)
+				return true
+			}
+			slog.WarnContext(ctx, "Zalo message failed, sending SMS", "id", notif.ID, "user_id", notif.RecipientID, "error", err)
+			return false
+		}
+		slog.InfoContext(ctx, "Notification sent by Zalo", "id", notif.ID, "user_id", notif.RecipientID, "msg_id", msgID)
+	}
+	return true
+}
+
+// processZalo sends a ZNS template message of the Official Account
+func (q *QueueConsumer) processZalo(ctx context.Context, hash string, notif *NotificationMessage) error {
+	if q.zalo == nil {
+		return permanent(fmt.Errorf("zalo channel is not configured"))
+	}
+	payloadBytes, err := json.Marshal(notif.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	var zaloPayload ZaloPayload
+	if err := json.Unmarshal(payloadBytes, &zaloPayload); err != nil {
+		return permanent(fmt.Errorf("failed to unmarshal zalo payload: %v", err))
+	}
+	if zaloPayload.PhoneNumber == "" || zaloPayload.TemplateID == "" {
+		return permanent(fmt.Errorf("zalo payload needs a phone number and a template"))
+	}
+
+	if notif.RecipientID != "" {
+		active, err := q.accounts.ActiveUsers([]string{notif.RecipientID})
+		if err != nil {
+			return err
+		}
+		if len(active) == 0 {
+			slog.InfoContext(ctx, "Skipping Zalo message to deactivated account", "id", notif.ID)
+			return nil
+		}
+	}
+
+	msgID, err := q.zalo.SendTemplate(ctx, zaloPayload.PhoneNumber, zaloPayload.TemplateID, zaloPayload.TemplateData, fmt.Sprintf("%.32s", hash))
+	if err != nil {
+		var rejected *zalo.RejectedError
+		if errors.As(err, &rejected) {
+			return permanent(fmt.Errorf("zalo message rejected: %w", err))
+		}
+		return fmt.Errorf("failed to send zalo message: %w", err)
+	}
+	slog.InfoContext(ctx, "Zalo message sent", "id", notif.ID, "template_id", zaloPayload.TemplateID, "msg_id", msgID)
+	return nil
+}
+
+// activeDestinations drops the numbers of deactivated accounts from an SMS. Only SMS addressed to
+// a user or sent by a campaign are filtered; others, such as the OTP a deactivated user reactivates
+// with, are sent as they are.
+func (q *QueueConsumer) activeDestinations(notif *NotificationMessage, destinations []string) ([]string, error) {
+	switch {
+	case notif.CampaignID != "":
+		return q.accounts.ActivePhones(destinations)
+	case notif.RecipientID != "":
+		active, err := q.accounts.ActiveUsers([]string{notif.RecipientID})
+		if err != nil || len(active) == 0 {
+			return nil, err
+		}
+	}
+	return destinations, nil
+}
+
+// processInApp puts an in-app notification in the inbox of its recipients and hands it to
+// noti-service, which pushes it to their devices
+func (q *QueueConsumer) processInApp(ctx context.Context, hash string, notif *NotificationMessage) error {
+	payloadBytes, err := json.Marshal(notif.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	var inApp InAppPayload
+	if err := json.Unmarshal(payloadBytes, &inApp); err != nil {
+		return permanent(fmt.Errorf("failed to unmarshal in-app payload: %v", err))
+	}
+	if len(inApp.LstUserIds) == 0 {
+		inApp.LstUserIds = []string{notif.RecipientID}
+	}
+	if inApp.LstUserIds, err = q.accounts.ActiveUsers(inApp.LstUserIds); err != nil {
+		return err
+	}
+	if len(inApp.LstUserIds) == 0 {
+		slog.InfoContext(ctx, "Skipping in-app notification to deactivated accounts only", "id", notif.ID)
+		return nil
+	}
+
+	// The inbox is written first, so a notification is never pushed without being kept
+	var data []byte
+	if inApp.Data != nil {
+		if data, err = json.Marshal(inApp.Data); err != nil {
+			return permanent(fmt.Errorf("failed to marshal in-app data: %v", err))
+		}
+	}
+	if err := q.inbox.AddEntries(inApp.LstUserIds, hash, notif.ID, inApp.Title, inApp.Body, data); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(inApp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal push notification: %v", err)
+	}
+
+	_, err = q.channel.QueueDeclare(PushNotiQueue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare push queue: %v", err)
+	}
+	err = q.channel.Publish(
+		"",            // exchange
+		PushNotiQueue, // routing key
+		false,         // mandatory
+		false,         // immediate
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         body,
+			Headers:      amqp.Table(logging.MessageHeaders(ctx)),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish push notification: %v", err)
+	}
+
+	slog.InfoContext(ctx, "In-app notification forwarded", "id", notif.ID, "user_count", len(inApp.LstUserIds))
 	return nil
 }
 
@@ -187,30 +453,144 @@ func (q *QueueConsumer) processPushNotification(ctx context.Context, notif *Noti
 	return nil
 }
 
-func (q *QueueConsumer) requeueMessage(msg amqp.Delivery, retryCount int) error {
-	// Add retry count to headers
-	headers := msg.Headers
-	if headers == nil {
-		headers = amqp.Table{}
+// publishRetry sends a failed message to the retry queue, from which it returns to the
+// notification queue after a backoff growing with the number of retries
+func (q *QueueConsumer) publishRetry(ctx context.Context, msg amqp.Delivery, retryCount int) error {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
 	}
-	headers["x-retry-count"] = int32(retryCount)
+	headers[retryCountHeader] = int32(retryCount)
 
-	// Calculate backoff delay
 	delay := time.Duration(retryCount*retryCount) * time.Second
-
-	// Publish with delay
-	return q.channel.Publish(
-		"",          // exchange
-		q.queueName, // routing key
-		false,       // mandatory
-		false,       // immediate
+	err := q.channel.Publish(
+		"",           // exchange
+		q.retryQueue, // routing key
+		false,        // mandatory
+		false,        // immediate
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        msg.Body,
-			Headers:     headers,
-			Expiration:  fmt.Sprintf("%d", delay.Milliseconds()),
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         msg.Body,
+			Headers:      headers,
+			Expiration:   fmt.Sprintf("%d", delay.Milliseconds()),
 		},
 	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message for retry: %w", err)
+	}
+	slog.InfoContext(ctx, "Message scheduled for retry", "retry", retryCount, "delay", delay)
+	return nil
+}
+
+func (q *QueueConsumer) publishDeadLetter(ctx context.Context, msg amqp.Delivery) error {
+	err := q.channel.Publish("", q.deadLetterQueue, false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "application/json",
+		Body:         msg.Body,
+		Headers:      msg.Headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish message to DLQ: %w", err)
+	}
+	return nil
+}
+
+// ReprocessDeadLetters moves up to limit messages from the DLQ back to the notification queue,
+// with their retries reset. Messages that used up their attempts are given up on instead; they
+// stay in the delivery log with their payload.
+func (q *QueueConsumer) ReprocessDeadLetters(ctx context.Context, limit int) (*models.DLQReprocessResult, error) {
+	ch, err := q.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	result := &models.DLQReprocessResult{}
+	for result.Requeued+result.Failed < limit && ctx.Err() == nil {
+		msg, ok, err := ch.Get(q.deadLetterQueue, false)
+		if err != nil {
+			return result, fmt.Errorf("failed to get dead letter: %w", err)
+		}
+		if !ok {
+			break
+		}
+		msgCtx := logging.FromMessageHeaders(ctx, msg.Headers)
+		hash := messageHash(msg.Body)
+
+		// Messages that are not notifications can never be delivered
+		var notification NotificationMessage
+		if err := json.Unmarshal(msg.Body, &notification); err != nil {
+			slog.ErrorContext(msgCtx, "Dropping malformed dead letter", "body", string(msg.Body), "error", err)
+			msg.Ack(false)
+			result.Failed++
+			continue
+		}
+
+		attempts, err := q.deliveryLog.GetAttempts(hash)
+		if err != nil {
+			msg.Nack(false, true)
+			return result, err
+		}
+		if attempts >= q.maxAttempts {
+			slog.WarnContext(msgCtx, "Giving up on notification", "id", notification.ID, "type", notification.Type, "attempts", attempts)
+			msg.Ack(false)
+			q.setStatus(msgCtx, hash, models.DeliveryFailed, nil)
+			result.Failed++
+			continue
+		}
+
+		headers := amqp.Table{}
+		for key, value := range msg.Headers {
+			headers[key] = value
+		}
+		headers[retryCountHeader] = int32(0)
+		err = ch.Publish("", q.queueName, false, false, amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         msg.Body,
+			Headers:      headers,
+		})
+		if err != nil {
+			msg.Nack(false, true)
+			return result, fmt.Errorf("failed to requeue dead letter: %w", err)
+		}
+		msg.Ack(false)
+		q.setStatus(msgCtx, hash, models.DeliveryRequeued, nil)
+		result.Requeued++
+	}
+
+	slog.InfoContext(ctx, "Dead letters reprocessed", "requeued", result.Requeued, "failed", result.Failed)
+	return result, nil
+}
+
+// StartDLQReprocessor replays the DLQ every interval until ctx is done
+func (q *QueueConsumer) StartDLQReprocessor(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := q.ReprocessDeadLetters(ctx, batchSize); err != nil {
+				slog.Error("Failed to reprocess dead letters", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// setStatus records a delivery outcome; the delivery log being down does not stop deliveries
+func (q *QueueConsumer) setStatus(ctx context.Context, hash, status string, deliveryErr error) {
+	var lastError *string
+	if deliveryErr != nil {
+		message := deliveryErr.Error()
+		lastError = &message
+	}
+	if err := q.deliveryLog.SetStatus(hash, status, lastError); err != nil {
+		slog.ErrorContext(ctx, "Failed to record delivery status", "message_hash", hash, "status", status, "error", err)
+	}
 }
 
 func (q *QueueConsumer) Close() error {
@@ -219,3 +599,64 @@ func (q *QueueConsumer) Close() error {
 	}
 	return q.conn.Close()
 }
+
+// permanentError is a delivery failure that retrying cannot fix, such as a malformed message
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanentErr *permanentError
+	return errors.As(err, &permanentErr)
+}
+
+// messageHash identifies a message across retries and DLQ replays, which keep its body as is
+func messageHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func retryCountOf(msg amqp.Delivery) int {
+	if val, ok := msg.Headers[retryCountHeader].(int32); ok {
+		return int(val)
+	}
+	return 0
+}
+
+func recipientOf(notification *NotificationMessage) *string {
+	if notification.RecipientID == "" {
+		return nil
+	}
+	return &notification.RecipientID
+}
+
+func campaignOf(notification *NotificationMessage) *string {
+	if notification.CampaignID == "" {
+		return nil
+	}
+	return &notification.CampaignID
+}
+
+// recipientCountOf counts the users a notification goes to, which is more than one for the
+// batches of a campaign
+func recipientCountOf(notification *NotificationMessage) int {
+	var count int
+	switch notification.Type {
+	case TypeInApp:
+		userIDs, _ := notification.Payload["lstUserIds"].([]any)
+		count = len(userIDs)
+	case TypeSMS:
+		payload, _ := notification.Payload["payload"].(map[string]any)
+		destinations, _ := payload["destinations"].([]any)
+		count = len(destinations)
+	}
+	return max(count, 1)
+}
@@ -0,0 +1,52 @@
+package event
+
+import (
+	"agrisa_utils/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// Publisher queues notifications on the queue the consumer reads, over a channel of its own
+type Publisher struct {
+	mu        sync.Mutex
+	channel   *amqp.Channel
+	queueName string
+}
+
+// NewPublisher opens a publisher on the connection of the consumer
+func (q *QueueConsumer) NewPublisher() (*Publisher, error) {
+	ch, err := q.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open publisher channel: %w", err)
+	}
+	return &Publisher{channel: ch, queueName: q.queueName}, nil
+}
+
+func (p *Publisher) Publish(ctx context.Context, notification *NotificationMessage) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err = p.channel.Publish("", p.queueName, false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "application/json",
+		Body:         body,
+		Priority:     uint8(notification.Priority),
+		Headers:      amqp.Table(logging.MessageHeaders(ctx)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish notification: %w", err)
+	}
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.channel.Close()
+}
@@ -8,6 +8,7 @@ const (
 	TypeEmail NotificationType = "email"
 	TypeSMS   NotificationType = "sms"
 	TypeInApp NotificationType = "in_app"
+	TypeZalo  NotificationType = "zalo"
 )
 
 type NotificationPriority int
@@ -28,6 +29,18 @@ type NotificationMessage struct {
 	MaxRetries   int                  `json:"max_retries"`
 	CreatedAt    time.Time            `json:"created_at"`
 	ScheduledFor *time.Time           `json:"scheduled_for,omitempty"`
+	CampaignID   string               `json:"campaign_id,omitempty"`
+}
+
+// PushNotiQueue is consumed by noti-service, which delivers push notifications to user devices
+const PushNotiQueue = "push_noti_events"
+
+// InAppPayload is the payload of an in_app notification, in the format noti-service expects
+type InAppPayload struct {
+	LstUserIds []string       `json:"lstUserIds"`
+	Title      string         `json:"title"`
+	Body       string         `json:"body"`
+	Data       map[string]any `json:"data,omitempty"`
 }
 
 type NotificationEventPushModelPayload struct {
@@ -43,3 +56,10 @@ type Notification struct {
 	Title string `json:"title"`
 	Body  string `json:"body"`
 }
+
+// ZaloPayload is the payload of a zalo notification, a ZNS template message of the Official Account
+type ZaloPayload struct {
+	PhoneNumber  string            `json:"phone_number"`
+	TemplateID   string            `json:"template_id"`
+	TemplateData map[string]string `json:"template_data"`
+}
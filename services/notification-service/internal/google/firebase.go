@@ -3,6 +3,7 @@ package google
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/messaging"
@@ -89,6 +90,158 @@ func (f *FirebaseService) SendPushNotification(ctx context.Context, payload *Pus
 	return response, nil
 }
 
+// FCM limits
+const (
+	maxMulticastTokens = 500
+	maxTopicTokens     = 1000
+	// Topics one condition may name
+	MaxConditionTopics = 5
+)
+
+// Reasons the instance ID service gives for tokens that no longer exist
+var staleTopicTokenReasons = map[string]bool{"NOT_FOUND": true, "INVALID_ARGUMENT": true}
+
+// ProvinceTopic is the topic of the devices of the farmers of a province
+func ProvinceTopic(provinceCode string) string {
+	return "province-" + topicSafe(provinceCode)
+}
+
+// CropTopic is the topic of the devices of the farmers growing a crop
+func CropTopic(crop string) string {
+	return "crop-" + topicSafe(crop)
+}
+
+// topicSafe lowercases a name and replaces what a topic name cannot hold with dashes
+func topicSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, strings.TrimSpace(name))
+}
+
+// SegmentCondition is the condition matching devices subscribed to any of the province topics and
+// any of the crop topics; an empty list does not narrow the segment
+func SegmentCondition(provinceCodes, crops []string) (string, error) {
+	if len(provinceCodes)+len(crops) == 0 {
+		return "", fmt.Errorf("bad_request: a segment needs at least one province or crop")
+	}
+	if len(provinceCodes)+len(crops) > MaxConditionTopics {
+		return "", fmt.Errorf("bad_request: a segment can name at most %d provinces and crops", MaxConditionTopics)
+	}
+
+	var clauses []string
+	for _, group := range [][]string{topicsOf(provinceCodes, ProvinceTopic), topicsOf(crops, CropTopic)} {
+		if len(group) == 0 {
+			continue
+		}
+		terms := make([]string, len(group))
+		for i, topic := range group {
+			terms[i] = fmt.Sprintf("'%s' in topics", topic)
+		}
+		clauses = append(clauses, "("+strings.Join(terms, " || ")+")")
+	}
+	return strings.Join(clauses, " && "), nil
+}
+
+func topicsOf(names []string, topic func(string) string) []string {
+	topics := make([]string, len(names))
+	for i, name := range names {
+		topics[i] = topic(name)
+	}
+	return topics
+}
+
+// SendToCondition sends a notification to every device matching an FCM topic condition
+func (f *FirebaseService) SendToCondition(ctx context.Context, condition string, payload *PushNotificationPayload) (string, error) {
+	message := &messaging.Message{
+		Condition: condition,
+		Notification: &messaging.Notification{
+			Title:    payload.Title,
+			Body:     payload.Body,
+			ImageURL: payload.ImageURL,
+		},
+		Data:    payload.Data,
+		Android: &messaging.AndroidConfig{Priority: "high"},
+	}
+
+	response, err := f.client.Send(ctx, message)
+	if err != nil {
+		return "", fmt.Errorf("error sending message to condition: %v", err)
+	}
+	return response, nil
+}
+
+// SendToTokens sends a notification to devices, in batches of what FCM takes at once, and returns
+// how many were reached with the tokens FCM reported as no longer registered
+func (f *FirebaseService) SendToTokens(ctx context.Context, tokens []string, payload *PushNotificationPayload) (int, []string, error) {
+	var sent int
+	var stale []string
+	for start := 0; start < len(tokens); start += maxMulticastTokens {
+		batch := tokens[start:min(start+maxMulticastTokens, len(tokens))]
+		response, err := f.client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+			Tokens: batch,
+			Notification: &messaging.Notification{
+				Title:    payload.Title,
+				Body:     payload.Body,
+				ImageURL: payload.ImageURL,
+			},
+			Data:    payload.Data,
+			Android: &messaging.AndroidConfig{Priority: "high"},
+		})
+		if err != nil {
+			return sent, stale, fmt.Errorf("error sending multicast: %v", err)
+		}
+		sent += response.SuccessCount
+		for i, result := range response.Responses {
+			if !result.Success && isStaleTokenError(result.Error) {
+				stale = append(stale, batch[i])
+			}
+		}
+	}
+	return sent, stale, nil
+}
+
+// isStaleTokenError reports whether FCM refused a token for good: the app was uninstalled, the
+// token expired, or it belongs to another project
+func isStaleTokenError(err error) bool {
+	return messaging.IsUnregistered(err) || messaging.IsSenderIDMismatch(err) ||
+		(messaging.IsInvalidArgument(err) && strings.Contains(err.Error(), "registration token"))
+}
+
+// SubscribeToTopic subscribes devices to a topic and returns the tokens FCM no longer knows
+func (f *FirebaseService) SubscribeToTopic(ctx context.Context, tokens []string, topic string) ([]string, error) {
+	return f.manageTopic(ctx, tokens, topic, f.client.SubscribeToTopic)
+}
+
+// UnsubscribeFromTopic unsubscribes devices from a topic and returns the tokens FCM no longer knows
+func (f *FirebaseService) UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) ([]string, error) {
+	return f.manageTopic(ctx, tokens, topic, f.client.UnsubscribeFromTopic)
+}
+
+func (f *FirebaseService) manageTopic(ctx context.Context, tokens []string, topic string,
+	manage func(context.Context, []string, string) (*messaging.TopicManagementResponse, error)) ([]string, error) {
+	var stale []string
+	for start := 0; start < len(tokens); start += maxTopicTokens {
+		batch := tokens[start:min(start+maxTopicTokens, len(tokens))]
+		response, err := manage(ctx, batch, topic)
+		if err != nil {
+			return stale, fmt.Errorf("error managing topic %s: %v", topic, err)
+		}
+		for _, info := range response.Errors {
+			if staleTopicTokenReasons[info.Reason] {
+				stale = append(stale, batch[info.Index])
+			}
+		}
+	}
+	return stale, nil
+}
+
 // Batch send for efficiency
 func (f *FirebaseService) SendBatchNotifications(ctx context.Context, messages []*messaging.Message) (*messaging.BatchResponse, error) {
 	if len(messages) > 500 {
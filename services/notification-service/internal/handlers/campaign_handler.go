@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type CampaignHandler struct {
+	campaignService *services.CampaignService
+}
+
+func NewCampaignHandler(campaignService *services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{campaignService: campaignService}
+}
+
+func (h *CampaignHandler) Register(app *fiber.App) {
+	// Campaigns reach whole provinces, so only partner and platform admins send them
+	protectedGr := app.Group("/notification/protected/api/v2")
+	campaignGr := protectedGr.Group("/campaigns", RequireRoles(RoleInsurerAdmin, RolePlatformAdmin))
+
+	campaignGr.Post("", h.Create)
+	campaignGr.Get("", h.List)
+	campaignGr.Get("/:id", h.Get)
+	campaignGr.Get("/:id/stats", h.GetStats)
+	campaignGr.Post("/:id/cancel", h.Cancel)
+}
+
+func (h *CampaignHandler) mapCampaignError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "not_found"):
+		return fiber.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "bad_request"):
+		return fiber.StatusBadRequest, "BAD_REQUEST"
+	case strings.Contains(errorMsg, "conflict"):
+		return fiber.StatusConflict, "CONFLICT"
+	case strings.Contains(errorMsg, "forbidden"):
+		return fiber.StatusForbidden, "FORBIDDEN"
+	default:
+		return fiber.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+// campaignActor returns the caller with the partners whose campaigns it acts on. Other services
+// calling with the API key act as platform admins.
+func campaignActor(c fiber.Ctx) models.CampaignActor {
+	principal := principalFrom(c)
+	return models.CampaignActor{
+		UserID:        principal.UserID,
+		PartnerIDs:    principal.ProviderIDs(),
+		PlatformAdmin: principal.Internal || principal.HasRole(RolePlatformAdmin),
+	}
+}
+
+func (h *CampaignHandler) Create(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	var req models.CreateCampaignRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+	}
+
+	campaign, err := h.campaignService.Create(campaignActor(c), &req)
+	if err != nil {
+		slog.Error("failed to create campaign", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapCampaignError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, err.Error()))
+	}
+	return c.Status(fiber.StatusCreated).JSON(utils.CreateSuccessResponse(campaign))
+}
+
+// List returns the campaigns of the caller's partners, every campaign for platform admins
+func (h *CampaignHandler) List(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	campaigns, err := h.campaignService.List(campaignActor(c), limit, offset)
+	if err != nil {
+		slog.Error("failed to list campaigns", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get campaigns"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(campaigns))
+}
+
+func (h *CampaignHandler) Get(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	campaignID := c.Params("id")
+	campaign, err := h.campaignService.Get(campaignID, campaignActor(c))
+	if err != nil {
+		slog.Error("failed to get campaign", "campaign_id", campaignID, "error", err)
+		statusCode, errorCode := h.mapCampaignError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, "Failed to get campaign"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(campaign))
+}
+
+func (h *CampaignHandler) GetStats(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	campaignID := c.Params("id")
+	stats, err := h.campaignService.GetStats(campaignID, campaignActor(c))
+	if err != nil {
+		slog.Error("failed to get campaign stats", "campaign_id", campaignID, "error", err)
+		statusCode, errorCode := h.mapCampaignError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, "Failed to get campaign stats"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(stats))
+}
+
+func (h *CampaignHandler) Cancel(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	campaignID := c.Params("id")
+	campaign, err := h.campaignService.Cancel(campaignID, campaignActor(c))
+	if err != nil {
+		slog.Error("failed to cancel campaign", "campaign_id", campaignID, "error", err)
+		statusCode, errorCode := h.mapCampaignError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, "Failed to cancel campaign"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(campaign))
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"notification-service/internal/event"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const defaultDLQReprocessLimit = 100
+
+type DeliveryHandler struct {
+	deliveryLog *repository.DeliveryLogRepository
+	consumer    *event.QueueConsumer
+}
+
+func NewDeliveryHandler(deliveryLog *repository.DeliveryLogRepository, consumer *event.QueueConsumer) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryLog: deliveryLog,
+		consumer:    consumer,
+	}
+}
+
+func (h *DeliveryHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("/notification/protected/api/v2")
+	deliveryGr := protectedGr.Group("/deliveries")
+
+	deliveryGr.Get("/metrics", RequireRoles(RolePlatformAdmin), h.GetMetrics)
+	deliveryGr.Post("/dlq/reprocess", RequireRoles(RolePlatformAdmin), h.ReprocessDLQ)
+	deliveryGr.Get("/:notification_id", h.GetDeliveries)
+}
+
+// GetMetrics reports delivery success per channel over the last hours, 24 by default
+func (h *DeliveryHandler) GetMetrics(c fiber.Ctx) error {
+	hours, err := strconv.Atoi(c.Query("hours", "24"))
+	if err != nil || hours <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "hours must be a positive number"))
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	stats, err := h.deliveryLog.GetStats(since)
+	if err != nil {
+		slog.Error("failed to get delivery metrics", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get delivery metrics"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(models.DeliveryMetrics{Since: since, Channels: stats}))
+}
+
+// GetDeliveries returns the delivery log of a notification. Admins and other services see every
+// delivery; users only see the deliveries to themselves, so the notifications of others look absent.
+func (h *DeliveryHandler) GetDeliveries(c fiber.Ctx) error {
+	notificationID := c.Params("notification_id")
+	logs, err := h.deliveryLog.GetByNotificationID(notificationID)
+	if err != nil {
+		slog.Error("failed to get delivery log", "notification_id", notificationID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get delivery log"))
+	}
+	if principal := principalFrom(c); principal == nil || !(principal.Internal || principal.HasRole(RolePlatformAdmin)) {
+		logs = deliveriesTo(logs, c.Get("X-User-ID"))
+	}
+	if len(logs) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", "No deliveries found for the notification"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(logs))
+}
+
+// ReprocessDLQ replays up to limit dead-lettered notifications without waiting for the worker
+func (h *DeliveryHandler) ReprocessDLQ(c fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultDLQReprocessLimit)))
+	if err != nil || limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "limit must be a positive number"))
+	}
+
+	result, err := h.consumer.ReprocessDeadLetters(c.Context(), limit)
+	if err != nil {
+		slog.Error("failed to reprocess dead letters", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to reprocess dead letters"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(result))
+}
+
+// deliveriesTo keeps the deliveries whose recipient is the user
+func deliveriesTo(logs []*models.DeliveryLog, userID string) []*models.DeliveryLog {
+	owned := make([]*models.DeliveryLog, 0, len(logs))
+	if userID == "" {
+		return owned
+	}
+	for _, log := range logs {
+		if log.RecipientID != nil && *log.RecipientID == userID {
+			owned = append(owned, log)
+		}
+	}
+	return owned
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	defaultInboxPageSize = 20
+	maxInboxPageSize     = 100
+)
+
+type InboxHandler struct {
+	inbox *repository.InboxRepository
+}
+
+func NewInboxHandler(inbox *repository.InboxRepository) *InboxHandler {
+	return &InboxHandler{inbox: inbox}
+}
+
+func (h *InboxHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("/notification/protected/api/v2")
+	inboxGr := protectedGr.Group("/inbox")
+
+	inboxGr.Get("", h.List)
+	inboxGr.Get("/unread-count", h.UnreadCount)
+	inboxGr.Post("/read-all", h.MarkAllRead)
+	inboxGr.Post("/:id/read", h.MarkRead)
+}
+
+// List returns the inbox of the caller, newest first, with the number of unread notifications
+func (h *InboxHandler) List(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	var filter models.InboxFilter
+	if err := c.Bind().Query(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid query parameters"))
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultInboxPageSize
+	}
+	filter.Limit = min(filter.Limit, maxInboxPageSize)
+	filter.Offset = max(filter.Offset, 0)
+
+	entries, err := h.inbox.List(userID, filter)
+	if err != nil {
+		slog.Error("failed to list inbox", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get notifications"))
+	}
+	unread, err := h.inbox.CountUnread(userID)
+	if err != nil {
+		slog.Error("failed to count unread notifications", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get notifications"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(models.InboxPage{
+		Entries:     entries,
+		UnreadCount: unread,
+		Limit:       filter.Limit,
+		Offset:      filter.Offset,
+	}))
+}
+
+func (h *InboxHandler) UnreadCount(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	unread, err := h.inbox.CountUnread(userID)
+	if err != nil {
+		slog.Error("failed to count unread notifications", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to count unread notifications"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(fiber.Map{"unread_count": unread}))
+}
+
+func (h *InboxHandler) MarkRead(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	entryID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid notification id"))
+	}
+	if err := h.inbox.MarkRead(userID, entryID); err != nil {
+		if strings.Contains(err.Error(), "not_found") {
+			return c.Status(fiber.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", "Notification not found"))
+		}
+		slog.Error("failed to mark notification read", "user_id", userID, "id", entryID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to mark notification read"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse("notification marked read"))
+}
+
+func (h *InboxHandler) MarkAllRead(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	marked, err := h.inbox.MarkAllRead(userID)
+	if err != nil {
+		slog.Error("failed to mark notifications read", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to mark notifications read"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(fiber.Map{"marked_read": marked}))
+}
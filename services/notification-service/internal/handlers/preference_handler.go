@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type PreferenceHandler struct {
+	preferences *repository.PreferenceRepository
+	zaloEnabled bool
+}
+
+func NewPreferenceHandler(preferences *repository.PreferenceRepository, zaloEnabled bool) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferences: preferences,
+		zaloEnabled: zaloEnabled,
+	}
+}
+
+func (h *PreferenceHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("/notification/protected/api/v2")
+	preferenceGr := protectedGr.Group("/preferences")
+
+	preferenceGr.Get("", h.Get)
+	preferenceGr.Put("", h.Update)
+}
+
+// Get returns the channel the caller receives their notifications on
+func (h *PreferenceHandler) Get(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	preference, err := h.preferences.Get(userID)
+	if err != nil {
+		slog.Error("failed to get notification preference", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get notification preference"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(preference))
+}
+
+// Update sets the channel of the caller. Zalo can only be chosen while an Official Account is
+// configured; notifications fall back to SMS for users Zalo cannot reach.
+func (h *PreferenceHandler) Update(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	var req models.UpdatePreferenceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid request body"))
+	}
+	switch req.Channel {
+	case models.ChannelSMS:
+	case models.ChannelZalo:
+		if !h.zaloEnabled {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Zalo notifications are not available"))
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "channel must be sms or zalo"))
+	}
+
+	preference, err := h.preferences.Set(userID, req.Channel)
+	if err != nil {
+		slog.Error("failed to set notification preference", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to update notification preference"))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(preference))
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type PushHandler struct {
+	pushService *services.PushService
+}
+
+func NewPushHandler(pushService *services.PushService) *PushHandler {
+	return &PushHandler{pushService: pushService}
+}
+
+func (h *PushHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("/notification/protected/api/v2")
+	pushGr := protectedGr.Group("/push")
+
+	// Users register the devices they sign in on
+	pushGr.Post("/devices", h.RegisterDevice)
+	pushGr.Delete("/devices/:token", h.UnregisterDevice)
+
+	// Segments follow the farms of a user, which profile-service and platform admins keep
+	pushGr.Put("/users/:user_id/segments", RequireRoles(RolePlatformAdmin), h.SetSegments)
+	pushGr.Post("/send", RequireRoles(RolePlatformAdmin), h.Send)
+}
+
+func (h *PushHandler) mapPushError(err error) (int, string) {
+	errorMsg := err.Error()
+
+	switch {
+	case strings.Contains(errorMsg, "not_found"):
+		return fiber.StatusNotFound, "NOT_FOUND"
+	case strings.Contains(errorMsg, "bad_request"):
+		return fiber.StatusBadRequest, "BAD_REQUEST"
+	default:
+		return fiber.StatusInternalServerError, "INTERNAL_ERROR"
+	}
+}
+
+func (h *PushHandler) RegisterDevice(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+	}
+	if err := h.pushService.RegisterDevice(c.Context(), userID, &req); err != nil {
+		slog.Error("failed to register push device", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapPushError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, err.Error()))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse("device registered"))
+}
+
+func (h *PushHandler) UnregisterDevice(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid session"))
+	}
+
+	if err := h.pushService.UnregisterDevice(c.Context(), userID, c.Params("token")); err != nil {
+		slog.Error("failed to unregister push device", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapPushError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, err.Error()))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse("device unregistered"))
+}
+
+// SetSegments sets the provinces and crops whose topics the devices of a user follow
+func (h *PushHandler) SetSegments(c fiber.Ctx) error {
+	userID := c.Params("user_id")
+
+	var req models.PushSegmentsRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+	}
+	topics, err := h.pushService.SetSegments(c.Context(), userID, &req)
+	if err != nil {
+		slog.Error("failed to set push segments", "user_id", userID, "error", err)
+		statusCode, errorCode := h.mapPushError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, err.Error()))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(topics))
+}
+
+func (h *PushHandler) Send(c fiber.Ctx) error {
+	var req models.PushSendRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+	}
+	result, err := h.pushService.Send(c.Context(), &req)
+	if err != nil {
+		slog.Error("failed to send push notification", "error", err)
+		statusCode, errorCode := h.mapPushError(err)
+		return c.Status(statusCode).JSON(utils.CreateErrorResponse(errorCode, err.Error()))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(result))
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"notification-service/internal/models"
+	"notification-service/internal/phone"
+	"notification-service/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type SMSHandler struct {
+	phoneService *phone.PhoneService
+	smsLog       *repository.SMSRepository
+}
+
+func NewSMSHandler(phoneService *phone.PhoneService, smsLog *repository.SMSRepository) *SMSHandler {
+	return &SMSHandler{
+		phoneService: phoneService,
+		smsLog:       smsLog,
+	}
+}
+
+func (h *SMSHandler) Register(app *fiber.App) {
+	// Gateways post receipts without a session; they are authenticated by their signature
+	publicGr := app.Group("/notification/public/api/v2")
+	publicGr.Post("/sms/receipts/:provider", h.ReceiveReceipt)
+
+	// Gateway settings and spend are for platform admins only
+	protectedGr := app.Group("/notification/protected/api/v2")
+	smsGr := protectedGr.Group("/sms", RequireRoles(RolePlatformAdmin))
+	smsGr.Get("/providers", h.GetProviders)
+	smsGr.Get("/costs", h.GetCosts)
+}
+
+func (h *SMSHandler) ReceiveReceipt(c fiber.Ctx) error {
+	providerName := c.Params("provider")
+	receipt, err := h.phoneService.ParseReceipt(providerName, c.Get("X-Signature"), c.Get("X-Timestamp"), c.Body())
+	if err != nil {
+		slog.Warn("rejected SMS receipt", "provider", providerName, "error", err)
+		errorMsg := err.Error()
+		switch {
+		case strings.Contains(errorMsg, "not_found"):
+			return c.Status(fiber.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", "Unknown SMS provider"))
+		case strings.Contains(errorMsg, "bad_request"):
+			return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid receipt"))
+		default:
+			return c.Status(fiber.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "Invalid signature"))
+		}
+	}
+	if receipt == nil {
+		return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse("event ignored"))
+	}
+
+	var reason *string
+	if receipt.Reason != "" {
+		reason = &receipt.Reason
+	}
+	matched, err := h.smsLog.ApplyReceipt(receipt.Provider, receipt.MessageID, receipt.PhoneNumber, receipt.Status, reason)
+	if err != nil {
+		slog.Error("failed to apply SMS receipt", "provider", receipt.Provider, "message_id", receipt.MessageID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to record receipt"))
+	}
+	if !matched {
+		// Receipts for unknown or already final messages are acknowledged, so the gateway stops retrying
+		slog.Info("SMS receipt matched no pending message", "provider", receipt.Provider, "message_id", receipt.MessageID, "status", receipt.Status)
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse("receipt recorded"))
+}
+
+func (h *SMSHandler) GetProviders(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(h.phoneService.Providers()))
+}
+
+// GetCosts reports what each gateway sent and cost over the last hours, 30 days by default
+func (h *SMSHandler) GetCosts(c fiber.Ctx) error {
+	hours, err := strconv.Atoi(c.Query("hours", "720"))
+	if err != nil || hours <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "hours must be a positive number"))
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	stats, err := h.smsLog.GetProviderStats(since)
+	if err != nil {
+		slog.Error("failed to get SMS costs", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_ERROR", "Failed to get SMS costs"))
+	}
+
+	report := models.SMSCostReport{Since: since, Providers: stats}
+	for _, s := range stats {
+		report.TotalCost += s.Cost
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(report))
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Campaign statuses
+const (
+	CampaignScheduled = "scheduled"
+	CampaignSending   = "sending"
+	CampaignSent      = "sent"
+	CampaignCancelled = "cancelled"
+)
+
+// Channels a campaign can go out on
+const (
+	CampaignChannelInApp = "in_app"
+	CampaignChannelSMS   = "sms"
+)
+
+// CampaignAudience selects the farmers a campaign goes to. A farmer matching any of the codes of
+// a list matches that list, and has to match every list given.
+type CampaignAudience struct {
+	ProvinceCodes    []string `json:"province_codes,omitempty"`
+	DistrictCodes    []string `json:"district_codes,omitempty"`
+	CooperativeCodes []string `json:"cooperative_codes,omitempty"`
+}
+
+func (a CampaignAudience) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *CampaignAudience) Scan(value any) error {
+	raw, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("CampaignAudience: Scan failed, expected []byte but got %T", value)
+	}
+	return json.Unmarshal(raw, a)
+}
+
+type Campaign struct {
+	ID             string           `json:"id" db:"id"`
+	Name           string           `json:"name" db:"name"`
+	Title          string           `json:"title" db:"title"`
+	Body           string           `json:"body" db:"body"`
+	Data           json.RawMessage  `json:"data,omitempty" db:"data"`
+	Channels       pq.StringArray   `json:"channels" db:"channels"`
+	Audience       CampaignAudience `json:"audience" db:"audience"`
+	Status         string           `json:"status" db:"status"`
+	ScheduledAt    time.Time        `json:"scheduled_at" db:"scheduled_at"`
+	AudienceCursor *string          `json:"-" db:"audience_cursor"`
+	BatchesSent    int              `json:"batches_sent" db:"batches_sent"`
+	RecipientCount int              `json:"recipient_count" db:"recipient_count"`
+	LastError      *string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedBy      string           `json:"created_by" db:"created_by"`
+	PartnerID      *string          `json:"partner_id,omitempty" db:"partner_id"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+	StartedAt      *time.Time       `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt    *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+type CreateCampaignRequest struct {
+	Name     string           `json:"name"`
+	Title    string           `json:"title"`
+	Body     string           `json:"body"`
+	Data     map[string]any   `json:"data,omitempty"`
+	Channels []string         `json:"channels"`
+	Audience CampaignAudience `json:"audience"`
+	// Sent right away when not set
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// Partner the campaign is sent for; needed from admins of several partners, and from platform
+	// admins sending for a partner
+	PartnerID string `json:"partner_id,omitempty"`
+}
+
+// CampaignActor is the caller of a campaign action. Partner admins act on the campaigns of their
+// partners, platform admins on every campaign.
+type CampaignActor struct {
+	UserID        string
+	PartnerIDs    []string
+	PlatformAdmin bool
+}
+
+// CampaignChannelStats counts the recipients a campaign reached on a channel by delivery status
+type CampaignChannelStats struct {
+	Channel      string  `json:"channel" db:"channel"`
+	Recipients   int     `json:"recipients" db:"recipients"`
+	Delivered    int     `json:"delivered" db:"delivered"`
+	Pending      int     `json:"pending" db:"pending"`
+	DeadLettered int     `json:"dead_lettered" db:"dead_lettered"`
+	Failed       int     `json:"failed" db:"failed"`
+	SuccessRate  float64 `json:"success_rate" db:"-"`
+}
+
+type CampaignStats struct {
+	CampaignID string                  `json:"campaign_id"`
+	Status     string                  `json:"status"`
+	Channels   []*CampaignChannelStats `json:"channels"`
+	// In-app notifications of the campaign opened from the inbox
+	InboxRead int `json:"inbox_read"`
+}
+
+// FarmerAudienceMember is a farmer of a campaign audience, as listed by profile-service
+type FarmerAudienceMember struct {
+	UserID string `json:"user_id"`
+	Phone  string `json:"phone"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Delivery statuses of a notification on a channel
+const (
+	DeliveryProcessing   = "processing"
+	DeliveryRetrying     = "retrying"
+	DeliveryDelivered    = "delivered"
+	DeliveryDeadLettered = "dead_lettered"
+	// Replayed from the dead letter queue and waiting to be attempted again
+	DeliveryRequeued = "requeued"
+	// Given up on after the maximum number of attempts
+	DeliveryFailed = "failed"
+)
+
+type DeliveryLog struct {
+	ID             int64           `json:"id" db:"id"`
+	MessageHash    string          `json:"message_hash" db:"message_hash"`
+	NotificationID string          `json:"notification_id" db:"notification_id"`
+	Channel        string          `json:"channel" db:"channel"`
+	RecipientID    *string         `json:"recipient_id,omitempty" db:"recipient_id"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	CampaignID     *string         `json:"campaign_id,omitempty" db:"campaign_id"`
+	// Users a campaign batch went to, 1 for other notifications
+	RecipientCount int        `json:"recipient_count" db:"recipient_count"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// ChannelDeliveryStats counts the notifications of a channel by outcome. The success rate is the
+// share of delivered notifications among those that reached an outcome.
+type ChannelDeliveryStats struct {
+	Channel      string  `json:"channel" db:"channel"`
+	Total        int     `json:"total" db:"total"`
+	Delivered    int     `json:"delivered" db:"delivered"`
+	Pending      int     `json:"pending" db:"pending"`
+	DeadLettered int     `json:"dead_lettered" db:"dead_lettered"`
+	Failed       int     `json:"failed" db:"failed"`
+	SuccessRate  float64 `json:"success_rate" db:"-"`
+}
+
+type DeliveryMetrics struct {
+	Since    time.Time               `json:"since"`
+	Channels []*ChannelDeliveryStats `json:"channels"`
+}
+
+// DLQReprocessResult is the outcome of a pass over the dead letter queue
+type DLQReprocessResult struct {
+	Requeued int `json:"requeued"`
+	// Given up on for having reached the maximum number of attempts
+	Failed int `json:"failed"`
+}
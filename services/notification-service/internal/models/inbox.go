@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// InboxEntry is an in-app notification as kept in the inbox of its recipient
+type InboxEntry struct {
+	ID             int64           `json:"id" db:"id"`
+	UserID         string          `json:"user_id" db:"user_id"`
+	MessageHash    string          `json:"-" db:"message_hash"`
+	NotificationID string          `json:"notification_id" db:"notification_id"`
+	Title          string          `json:"title" db:"title"`
+	Body           string          `json:"body" db:"body"`
+	Data           json.RawMessage `json:"data,omitempty" db:"data"`
+	ReadAt         *time.Time      `json:"read_at" db:"read_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+type InboxFilter struct {
+	UnreadOnly bool `query:"unread_only"`
+	Limit      int  `query:"limit"`
+	Offset     int  `query:"offset"`
+}
+
+type InboxPage struct {
+	Entries     []*InboxEntry `json:"entries"`
+	UnreadCount int           `json:"unread_count"`
+	Limit       int           `json:"limit"`
+	Offset      int           `json:"offset"`
+}
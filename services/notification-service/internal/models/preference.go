@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Channels a user can receive their notifications on
+const (
+	ChannelSMS  = "sms"
+	ChannelZalo = "zalo"
+)
+
+type NotificationPreference struct {
+	UserID    string    `json:"user_id" db:"user_id"`
+	Channel   string    `json:"channel" db:"channel"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdatePreferenceRequest struct {
+	Channel string `json:"channel"`
+}
+
+// ZaloToken is the token pair of the Zalo Official Account
+type ZaloToken struct {
+	AccessToken  string    `db:"access_token"`
+	RefreshToken string    `db:"refresh_token"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Platforms of a push device
+const (
+	PushPlatformAndroid = "android"
+	PushPlatformIOS     = "ios"
+	PushPlatformWeb     = "web"
+)
+
+type PushDevice struct {
+	Token     string    `json:"token" db:"token"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Platform  string    `json:"platform" db:"platform"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token"`
+	Platform string `json:"platform"`
+}
+
+// PushSegmentsRequest sets the provinces and crops whose topics the devices of a user follow
+type PushSegmentsRequest struct {
+	ProvinceCodes []string `json:"province_codes"`
+	Crops         []string `json:"crops"`
+}
+
+// PushSendRequest is a push notification to the devices of users, or to a segment of provinces
+// and crops
+type PushSendRequest struct {
+	UserIDs       []string          `json:"user_ids"`
+	ProvinceCodes []string          `json:"province_codes"`
+	Crops         []string          `json:"crops"`
+	Title         string            `json:"title"`
+	Body          string            `json:"body"`
+	Data          map[string]string `json:"data,omitempty"`
+}
+
+type PushSendResult struct {
+	// FCM message ID of a segment send
+	MessageID string `json:"message_id,omitempty"`
+	// Devices reached by a send to users, and the stale devices removed on the way
+	Sent    int `json:"sent"`
+	Removed int `json:"removed"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// SMS statuses of a recipient, from the gateway accepting the message to its delivery receipt
+const (
+	SMSQueued    = "queued"
+	SMSSent      = "sent"
+	SMSDelivered = "delivered"
+	SMSFailed    = "failed"
+)
+
+type SMSMessage struct {
+	ID                int64      `json:"id" db:"id"`
+	MessageHash       string     `json:"message_hash" db:"message_hash"`
+	Provider          string     `json:"provider" db:"provider"`
+	ProviderMessageID *string    `json:"provider_message_id,omitempty" db:"provider_message_id"`
+	PhoneNumber       string     `json:"phone_number" db:"phone_number"`
+	Status            string     `json:"status" db:"status"`
+	Segments          int        `json:"segments" db:"segments"`
+	Cost              float64    `json:"cost" db:"cost"`
+	FailureReason     *string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// SMSProviderStats counts the recipients a gateway sent SMS to by receipt status, with their cost
+type SMSProviderStats struct {
+	Provider   string  `json:"provider" db:"provider"`
+	Recipients int     `json:"recipients" db:"recipients"`
+	Segments   int     `json:"segments" db:"segments"`
+	Delivered  int     `json:"delivered" db:"delivered"`
+	Failed     int     `json:"failed" db:"failed"`
+	Pending    int     `json:"pending" db:"pending"`
+	Cost       float64 `json:"cost" db:"cost"`
+}
+
+type SMSCostReport struct {
+	Since     time.Time           `json:"since"`
+	Providers []*SMSProviderStats `json:"providers"`
+	TotalCost float64             `json:"total_cost"`
+}
@@ -0,0 +1,114 @@
+package phone
+
+import (
+	"agrisa_utils/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"notification-service/internal/config"
+	"time"
+)
+
+// gateway is an SMS gateway speaking the /message API of the Android SMS gateway app
+type gateway struct {
+	cfg    config.SMSProviderConfig
+	client *http.Client
+}
+
+type smsPayload struct {
+	TextMessage struct {
+		Text string `json:"text"`
+	} `json:"textMessage"`
+	PhoneNumbers []string `json:"phoneNumbers"`
+}
+
+type smsResponse struct {
+	ID string `json:"id"`
+}
+
+func newGateway(cfg config.SMSProviderConfig) *gateway {
+	return &gateway{
+		cfg:    cfg,
+		client: logging.NewHTTPClient(10 * time.Second),
+	}
+}
+
+// send hands a message to the gateway and returns the ID the gateway gave it
+func (g *gateway) send(ctx context.Context, text string, phoneNumbers []string) (string, error) {
+	log := slog.With("operation", "gateway.send", "provider", g.cfg.Name)
+	url := g.cfg.URL + "/message"
+
+	payload := smsPayload{PhoneNumbers: phoneNumbers}
+	payload.TextMessage.Text = text
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal SMS payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.SetBasicAuth(g.cfg.Username, g.cfg.Password)
+	req.Header.Set("Content-Type", "application/json")
+
+	startTime := time.Now()
+	resp, err := g.client.Do(req)
+	if err != nil {
+		log.Error("Failed to send SMS request (network/timeout error)", "error", err, "elapsed_time", time.Since(startTime))
+		return "", fmt.Errorf("failed to send SMS request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SMS response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		log.Error("External server returned non-success status",
+			"status_code", resp.StatusCode,
+			"response_body", string(responseBody),
+			"url", url,
+		)
+		err := fmt.Errorf("external server returned non-success status: %s. Response body: %s", resp.Status, responseBody)
+		// The gateway refusing the message itself, rather than failing, would not change on another
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusUnauthorized &&
+			resp.StatusCode != http.StatusTooManyRequests {
+			return "", &RejectedError{err: err}
+		}
+		return "", err
+	}
+
+	var result smsResponse
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		// The message was accepted; only its receipts cannot be matched
+		log.Warn("Failed to parse SMS response", "error", err, "response_body", string(responseBody))
+	}
+	log.Info("SMS successfully sent", "message_id", result.ID, "recipients_count", len(phoneNumbers), "elapsed_time", time.Since(startTime))
+	return result.ID, nil
+}
+
+// healthCheck calls the health endpoint of the gateway, which reports the phone behind it
+func (g *gateway) healthCheck(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.cfg.URL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+	req.SetBasicAuth(g.cfg.Username, g.cfg.Password)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
@@ -1,125 +1,193 @@
 package phone
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
+	"notification-service/internal/config"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
+// PhoneService sends SMS through a chain of gateways. Gateways are tried in order, those failing
+// their health check last, so a message goes out as long as one gateway works.
 type PhoneService struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
+	providers []*provider
 }
 
-type smsPayload struct {
-	TextMessage struct {
-		Text string `json:"text"`
-	} `json:"textMessage"`
-	PhoneNumbers []string `json:"phoneNumbers"`
+type provider struct {
+	gateway *gateway
+
+	mu          sync.RWMutex
+	healthy     bool
+	lastError   string
+	lastChecked *time.Time
 }
 
-func NewPhoneService(host, port, username, password string) *PhoneService {
-	return &PhoneService{
-		Host:     host,
-		Port:     port,
-		Username: username,
-		Password: password,
-	}
+// SendResult is a message accepted by a gateway
+type SendResult struct {
+	Provider  string
+	MessageID string
+	// Segments of the message each recipient is billed for
+	Segments int
+	// Cost of the message for each recipient
+	CostPerRecipient float64
+}
+
+// ProviderStatus is the health of a gateway as last seen by the chain
+type ProviderStatus struct {
+	Name           string     `json:"name"`
+	Healthy        bool       `json:"healthy"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastChecked    *time.Time `json:"last_checked,omitempty"`
+	CostPerSegment float64    `json:"cost_per_segment"`
 }
 
-func (p *PhoneService) SendSMS(title, content string, phoneNumbers []string) error {
-	// --- 1. Preparation & URL Construction ---
-	const op = "PhoneService.SendSMS"
-	log := slog.With("operation", op)
-
-	url := fmt.Sprintf("%s:%s/message", p.Host, p.Port)
-	log.Info("Starting SMS delivery process",
-		"target_url", url,
-		"recipients_count", len(phoneNumbers),
-		"title", title,
-	)
-
-	// --- 2. Payload Creation and Marshal ---
-	payload := smsPayload{
-		PhoneNumbers: phoneNumbers,
+// RejectedError is a message a gateway refused, such as one to an invalid number. Other gateways
+// are not tried as they would refuse it too.
+type RejectedError struct {
+	err error
+}
+
+func (e *RejectedError) Error() string { return e.err.Error() }
+
+func (e *RejectedError) Unwrap() error { return e.err }
+
+func NewPhoneService(cfg config.SMSConfig) *PhoneService {
+	service := &PhoneService{}
+	for _, providerCfg := range cfg.Providers {
+		// Gateways start healthy, so sending does not wait on the first health check
+		service.providers = append(service.providers, &provider{gateway: newGateway(providerCfg), healthy: true})
 	}
-	payload.TextMessage.Text = fmt.Sprintf("%s\n%s", title, content)
+	return service
+}
 
-	jsonBody, err := json.Marshal(payload)
-	if err != nil {
-		log.Error("Failed to marshal SMS payload",
-			"error", err,
-			"payload_struct", payload,
-		)
-		return fmt.Errorf("failed to marshal SMS payload: %w", err)
+// Send sends a message to the given numbers through the first gateway that accepts it
+func (p *PhoneService) Send(ctx context.Context, title, content string, phoneNumbers []string) (*SendResult, error) {
+	text := fmt.Sprintf("%s\n%s", title, content)
+
+	var errs []error
+	for _, provider := range p.ordered() {
+		messageID, err := provider.gateway.send(ctx, text, phoneNumbers)
+		if err != nil {
+			var rejected *RejectedError
+			if errors.As(err, &rejected) {
+				return nil, err
+			}
+			provider.setHealth(err)
+			slog.WarnContext(ctx, "SMS provider failed, trying the next", "provider", provider.gateway.cfg.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.gateway.cfg.Name, err))
+			continue
+		}
+
+		segments := Segments(text)
+		return &SendResult{
+			Provider:         provider.gateway.cfg.Name,
+			MessageID:        messageID,
+			Segments:         segments,
+			CostPerRecipient: float64(segments) * provider.gateway.cfg.CostPerSegment,
+		}, nil
 	}
-	log.Info("Payload successfully marshaled", "payload_bytes", string(jsonBody))
+	return nil, fmt.Errorf("all SMS providers failed: %w", errors.Join(errs...))
+}
 
-	// --- 3. Request Creation and Setup ---
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Error("Failed to create HTTP request", "error", err)
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+// ordered returns the healthy gateways followed by the unhealthy ones, each in configured order
+func (p *PhoneService) ordered() []*provider {
+	ordered := make([]*provider, 0, len(p.providers))
+	var unhealthy []*provider
+	for _, provider := range p.providers {
+		if provider.isHealthy() {
+			ordered = append(ordered, provider)
+		} else {
+			unhealthy = append(unhealthy, provider)
+		}
 	}
+	return append(ordered, unhealthy...)
+}
 
-	// Basic Auth credentials are set here but generally shouldn't be logged directly
-	req.SetBasicAuth(p.Username, p.Password)
-	req.Header.Set("Content-Type", "application/json")
+// StartHealthChecks checks every gateway each interval until ctx is done
+func (p *PhoneService) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, provider := range p.providers {
+			checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			err := provider.gateway.healthCheck(checkCtx)
+			cancel()
+			if err != nil && provider.isHealthy() {
+				slog.Warn("SMS provider unhealthy", "provider", provider.gateway.cfg.Name, "error", err)
+			}
+			provider.setHealth(err)
+		}
 
-	log.Info("HTTP request configured",
-		"method", req.Method,
-		"headers", req.Header,
-		"auth_user", p.Username, // Log the username, but not the password
-	)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
 
-	// --- 4. Request Execution ---
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+func (p *PhoneService) Providers() []ProviderStatus {
+	statuses := make([]ProviderStatus, 0, len(p.providers))
+	for _, provider := range p.providers {
+		provider.mu.RLock()
+		statuses = append(statuses, ProviderStatus{
+			Name:           provider.gateway.cfg.Name,
+			Healthy:        provider.healthy,
+			LastError:      provider.lastError,
+			LastChecked:    provider.lastChecked,
+			CostPerSegment: provider.gateway.cfg.CostPerSegment,
+		})
+		provider.mu.RUnlock()
 	}
+	return statuses
+}
 
-	startTime := time.Now()
-	resp, err := client.Do(req)
+// providerConfig returns the configuration of the named gateway
+func (p *PhoneService) providerConfig(name string) (config.SMSProviderConfig, bool) {
+	for _, provider := range p.providers {
+		if provider.gateway.cfg.Name == name {
+			return provider.gateway.cfg, true
+		}
+	}
+	return config.SMSProviderConfig{}, false
+}
 
-	// Log the total request duration
-	log.Info("Request execution complete", "duration", time.Since(startTime))
+func (p *provider) isHealthy() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.healthy
+}
 
+func (p *provider) setHealth(err error) {
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healthy = err == nil
+	p.lastError = ""
 	if err != nil {
-		log.Error("Failed to send SMS request (network/timeout error)",
-			"error", err,
-			"elapsed_time", time.Since(startTime),
-		)
-		return fmt.Errorf("failed to send SMS request: %w", err)
+		p.lastError = err.Error()
 	}
-	defer resp.Body.Close()
-
-	// --- 5. Response Check and Error Logging ---
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		// Read the body for detailed error information from the server
-		responseBody, readErr := io.ReadAll(resp.Body)
-		if readErr != nil {
-			responseBody = fmt.Appendf(nil, "failed to read response body: %v", readErr)
-		}
+	p.lastChecked = &now
+}
 
-		log.Error("External server returned non-success status",
-			"status_code", resp.StatusCode,
-			"status", resp.Status,
-			"response_body", string(responseBody), // Log the server's error message
-			"url", url,
-		)
-		return fmt.Errorf("external server returned non-success status: %s. Response body: %s", resp.Status, responseBody)
+// Segments counts the SMS a text is split into. Texts outside ASCII, such as Vietnamese with its
+// diacritics, are sent as UCS-2 and fit fewer characters per SMS.
+func Segments(text string) int {
+	single, multi := 160, 153
+	for _, r := range text {
+		if r >= utf8.RuneSelf {
+			single, multi = 70, 67
+			break
+		}
 	}
-
-	// --- 6. Success Log ---
-	log.Info("SMS successfully sent",
-		"status", resp.Status,
-		"elapsed_time", time.Since(startTime),
-	)
-
-	return nil
+	length := utf8.RuneCountInString(text)
+	if length <= single {
+		return 1
+	}
+	return (length + multi - 1) / multi
 }
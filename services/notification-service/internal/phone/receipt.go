@@ -0,0 +1,94 @@
+package phone
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Receipt statuses of an SMS to a recipient
+const (
+	ReceiptSent      = "sent"
+	ReceiptDelivered = "delivered"
+	ReceiptFailed    = "failed"
+)
+
+// receiptMaxAge bounds the age of a signed receipt, so a captured one cannot be replayed later
+const receiptMaxAge = 5 * time.Minute
+
+// Receipt reports what became of an SMS to one recipient
+type Receipt struct {
+	Provider    string
+	MessageID   string
+	PhoneNumber string
+	Status      string
+	Reason      string
+}
+
+type webhookEvent struct {
+	Event   string `json:"event"`
+	Payload struct {
+		MessageID   string `json:"messageId"`
+		PhoneNumber string `json:"phoneNumber"`
+		Reason      string `json:"reason"`
+	} `json:"payload"`
+}
+
+// ParseReceipt verifies and reads a webhook a gateway sent about one of its messages. The gateway
+// signs the body followed by the timestamp with HMAC-SHA256. Events other than receipts are
+// returned as nil.
+func (p *PhoneService) ParseReceipt(providerName, signature, timestamp string, body []byte) (*Receipt, error) {
+	cfg, ok := p.providerConfig(providerName)
+	if !ok {
+		return nil, fmt.Errorf("not_found: unknown SMS provider %s", providerName)
+	}
+	if cfg.WebhookSecret == "" {
+		return nil, fmt.Errorf("forbidden: receipts from %s are not enabled", providerName)
+	}
+
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized: invalid timestamp")
+	}
+	if age := time.Since(time.Unix(sentAt, 0)); age > receiptMaxAge || age < -receiptMaxAge {
+		return nil, fmt.Errorf("unauthorized: receipt timestamp out of range")
+	}
+	mac := hmac.New(sha256.New, []byte(cfg.WebhookSecret))
+	mac.Write(body)
+	mac.Write([]byte(timestamp))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, fmt.Errorf("unauthorized: invalid signature")
+	}
+
+	var event webhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("bad_request: invalid receipt: %v", err)
+	}
+	var status string
+	switch event.Event {
+	case "sms:sent":
+		status = ReceiptSent
+	case "sms:delivered":
+		status = ReceiptDelivered
+	case "sms:failed":
+		status = ReceiptFailed
+	default:
+		return nil, nil
+	}
+	if event.Payload.MessageID == "" || event.Payload.PhoneNumber == "" {
+		return nil, fmt.Errorf("bad_request: receipt without messageId or phoneNumber")
+	}
+
+	return &Receipt{
+		Provider:    providerName,
+		MessageID:   event.Payload.MessageID,
+		PhoneNumber: event.Payload.PhoneNumber,
+		Status:      status,
+		Reason:      event.Payload.Reason,
+	}, nil
+}
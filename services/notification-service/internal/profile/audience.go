@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"agrisa_utils/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"notification-service/internal/models"
+	"time"
+)
+
+// AudienceClient pages through the farmers of a campaign audience in profile-service
+type AudienceClient struct {
+	profileServiceURL string
+	client            *http.Client
+}
+
+func NewAudienceClient(profileServiceURL string) *AudienceClient {
+	return &AudienceClient{
+		profileServiceURL: profileServiceURL,
+		client:            logging.NewHTTPClient(30 * time.Second),
+	}
+}
+
+type audienceRequest struct {
+	models.CampaignAudience
+	AfterUserID string `json:"after_user_id"`
+	Limit       int    `json:"limit"`
+}
+
+// AudiencePage is a page of farmers; Next is the cursor of the following page, nil on the last
+type AudiencePage struct {
+	Members []models.FarmerAudienceMember `json:"members"`
+	Next    *string                       `json:"next_after_user_id"`
+}
+
+// GetAudiencePage returns up to limit farmers of the audience whose user ID sorts after afterUserID
+func (c *AudienceClient) GetAudiencePage(ctx context.Context, audience models.CampaignAudience, afterUserID string, limit int) (*AudiencePage, error) {
+	payload, err := json.Marshal(audienceRequest{CampaignAudience: audience, AfterUserID: afterUserID, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding audience request: %w", err)
+	}
+
+	endpoint := c.profileServiceURL + "/profile/internal/api/v1/farmer-profiles/audience"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating audience request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting audience: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading audience response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.ErrorContext(ctx, "unexpected audience response", "status_code", resp.StatusCode, "body", string(body))
+		return nil, fmt.Errorf("unexpected status code from profile-service: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data AudiencePage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing audience response: %w", err)
+	}
+	return &result.Data, nil
+}
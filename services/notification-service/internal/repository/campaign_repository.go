@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type CampaignRepository struct {
+	db *sqlx.DB
+}
+
+func NewCampaignRepository(db *sqlx.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+func (r *CampaignRepository) Create(campaign *models.Campaign) error {
+	query := `
+		INSERT INTO campaigns (name, title, body, data, channels, audience, scheduled_at, created_by, partner_id)
+		VALUES (:name, :title, :body, :data, :channels, :audience, :scheduled_at, :created_by, :partner_id)
+		RETURNING *`
+
+	rows, err := r.db.NamedQuery(query, campaign)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.StructScan(campaign); err != nil {
+			return fmt.Errorf("failed to read created campaign: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// GetByID returns a campaign the actor may see, nil when there is none
+func (r *CampaignRepository) GetByID(id string, actor models.CampaignActor) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := r.db.Get(&campaign, `SELECT * FROM campaigns WHERE id = $1 AND ($2 OR partner_id = ANY($3))`,
+		id, actor.PlatformAdmin, pq.Array(actor.PartnerIDs))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	return &campaign, nil
+}
+
+// List returns the campaigns the actor may see, latest scheduled first
+func (r *CampaignRepository) List(actor models.CampaignActor, limit, offset int) ([]*models.Campaign, error) {
+	query := `
+		SELECT * FROM campaigns
+		WHERE $1 OR partner_id = ANY($2)
+		ORDER BY scheduled_at DESC, created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	campaigns := []*models.Campaign{}
+	if err := r.db.Select(&campaigns, query, actor.PlatformAdmin, pq.Array(actor.PartnerIDs), limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	return campaigns, nil
+}
+
+// Cancel stops a campaign that has not finished sending. Batches already queued still go out.
+func (r *CampaignRepository) Cancel(id string, actor models.CampaignActor) error {
+	result, err := r.db.Exec(`
+		UPDATE campaigns SET status = 'cancelled', completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND ($2 OR partner_id = ANY($3)) AND status IN ('scheduled', 'sending')`,
+		id, actor.PlatformAdmin, pq.Array(actor.PartnerIDs))
+	if err != nil {
+		return fmt.Errorf("failed to cancel campaign: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("conflict: campaign is not scheduled or sending")
+	}
+	return nil
+}
+
+// ClaimDue marks the next campaign due for sending as sending and returns it, nil when none is
+// due. A campaign left sending without progress for staleAfter, by an instance that died, is
+// claimed again.
+func (r *CampaignRepository) ClaimDue(staleAfter time.Duration) (*models.Campaign, error) {
+	query := `
+		UPDATE campaigns
+		SET status = 'sending', started_at = COALESCE(started_at, NOW()), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM campaigns
+			WHERE (status = 'scheduled' AND scheduled_at <= NOW())
+				OR (status = 'sending' AND updated_at < NOW() - $1 * INTERVAL '1 second')
+			ORDER BY scheduled_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	var campaign models.Campaign
+	if err := r.db.Get(&campaign, query, staleAfter.Seconds()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim due campaign: %w", err)
+	}
+	return &campaign, nil
+}
+
+// GetStatus returns the status of a campaign, so a send can stop when it is cancelled
+func (r *CampaignRepository) GetStatus(id string) (string, error) {
+	var status string
+	if err := r.db.Get(&status, `SELECT status FROM campaigns WHERE id = $1`, id); err != nil {
+		return "", fmt.Errorf("failed to get campaign status: %w", err)
+	}
+	return status, nil
+}
+
+// SaveProgress records a batch queued by a sending campaign
+func (r *CampaignRepository) SaveProgress(id, cursor string, recipients int) error {
+	_, err := r.db.Exec(`
+		UPDATE campaigns
+		SET audience_cursor = $2, batches_sent = batches_sent + 1, recipient_count = recipient_count + $3,
+			last_error = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'sending'`, id, cursor, recipients)
+	if err != nil {
+		return fmt.Errorf("failed to save campaign progress: %w", err)
+	}
+	return nil
+}
+
+// MarkSent records that every batch of a sending campaign was queued
+func (r *CampaignRepository) MarkSent(id string) error {
+	_, err := r.db.Exec(`
+		UPDATE campaigns SET status = 'sent', completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'sending'`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark campaign sent: %w", err)
+	}
+	return nil
+}
+
+// Release hands a sending campaign back to the scheduler, which resumes it from its cursor
+func (r *CampaignRepository) Release(id string, lastError *string) error {
+	_, err := r.db.Exec(`
+		UPDATE campaigns SET status = 'scheduled', last_error = COALESCE($2, last_error), updated_at = NOW()
+		WHERE id = $1 AND status = 'sending'`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to release campaign: %w", err)
+	}
+	return nil
+}
+
+// GetChannelStats counts the recipients of the batches of a campaign by channel and delivery status
+func (r *CampaignRepository) GetChannelStats(id string) ([]*models.CampaignChannelStats, error) {
+	query := `
+		SELECT channel,
+			COALESCE(SUM(recipient_count), 0) AS recipients,
+			COALESCE(SUM(recipient_count) FILTER (WHERE status = 'delivered'), 0) AS delivered,
+			COALESCE(SUM(recipient_count) FILTER (WHERE status IN ('processing', 'retrying', 'requeued')), 0) AS pending,
+			COALESCE(SUM(recipient_count) FILTER (WHERE status = 'dead_lettered'), 0) AS dead_lettered,
+			COALESCE(SUM(recipient_count) FILTER (WHERE status = 'failed'), 0) AS failed
+		FROM delivery_log
+		WHERE campaign_id = $1
+		GROUP BY channel
+		ORDER BY channel`
+
+	stats := []*models.CampaignChannelStats{}
+	if err := r.db.Select(&stats, query, id); err != nil {
+		return nil, fmt.Errorf("failed to get campaign stats: %w", err)
+	}
+	for _, s := range stats {
+		if finished := s.Delivered + s.DeadLettered + s.Failed; finished > 0 {
+			s.SuccessRate = float64(s.Delivered) / float64(finished)
+		}
+	}
+	return stats, nil
+}
+
+// CountInboxRead counts the in-app notifications of a campaign that were read
+func (r *CampaignRepository) CountInboxRead(id string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notification_inbox i
+		JOIN delivery_log d ON d.message_hash = i.message_hash
+		WHERE d.campaign_id = $1 AND i.read_at IS NOT NULL`
+
+	var count int
+	if err := r.db.Get(&count, query, id); err != nil {
+		return 0, fmt.Errorf("failed to count read campaign notifications: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DeliveryLogRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeliveryLogRepository(db *sqlx.DB) *DeliveryLogRepository {
+	return &DeliveryLogRepository{db: db}
+}
+
+// StartAttempt records an attempt at delivering a notification and returns its attempt number.
+// It returns 0 when the notification was already delivered, as a message delivered twice by the
+// queue must not be sent twice.
+func (r *DeliveryLogRepository) StartAttempt(messageHash, notificationID, channel string, recipientID, campaignID *string, recipientCount int, payload []byte) (int, error) {
+	query := `
+		INSERT INTO delivery_log (message_hash, notification_id, channel, recipient_id, campaign_id, recipient_count, status, attempts, payload)
+		VALUES ($1, $2, $3, $4, $5, $6, 'processing', 1, $7)
+		ON CONFLICT (message_hash) DO UPDATE
+		SET status = 'processing', attempts = delivery_log.attempts + 1, updated_at = NOW()
+		WHERE delivery_log.status <> 'delivered'
+		RETURNING attempts`
+
+	var attempts int
+	err := r.db.Get(&attempts, query, messageHash, notificationID, channel, recipientID, campaignID, recipientCount, payload)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+	return attempts, nil
+}
+
+// GetAttempts returns how often a message was attempted, 0 for one never seen
+func (r *DeliveryLogRepository) GetAttempts(messageHash string) (int, error) {
+	var attempts int
+	err := r.db.Get(&attempts, `SELECT attempts FROM delivery_log WHERE message_hash = $1`, messageHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get delivery attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// SetStatus records the outcome of the latest attempt; lastError is kept when nil
+func (r *DeliveryLogRepository) SetStatus(messageHash, status string, lastError *string) error {
+	query := `
+		UPDATE delivery_log
+		SET status = $2,
+			last_error = COALESCE($3, last_error),
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END,
+			updated_at = NOW()
+		WHERE message_hash = $1`
+
+	if _, err := r.db.Exec(query, messageHash, status, lastError); err != nil {
+		return fmt.Errorf("failed to update delivery status: %w", err)
+	}
+	return nil
+}
+
+func (r *DeliveryLogRepository) GetByNotificationID(notificationID string) ([]*models.DeliveryLog, error) {
+	var logs []*models.DeliveryLog
+	err := r.db.Select(&logs, `SELECT * FROM delivery_log WHERE notification_id = $1 ORDER BY created_at`, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery log: %w", err)
+	}
+	return logs, nil
+}
+
+// GetStats counts the notifications of each channel received since the given time by status
+func (r *DeliveryLogRepository) GetStats(since time.Time) ([]*models.ChannelDeliveryStats, error) {
+	query := `
+		SELECT channel,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
+			COUNT(*) FILTER (WHERE status IN ('processing', 'retrying', 'requeued')) AS pending,
+			COUNT(*) FILTER (WHERE status = 'dead_lettered') AS dead_lettered,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed
+		FROM delivery_log
+		WHERE created_at >= $1
+		GROUP BY channel
+		ORDER BY channel`
+
+	var stats []*models.ChannelDeliveryStats
+	if err := r.db.Select(&stats, query, since); err != nil {
+		return nil, fmt.Errorf("failed to get delivery stats: %w", err)
+	}
+	for _, s := range stats {
+		if finished := s.Delivered + s.DeadLettered + s.Failed; finished > 0 {
+			s.SuccessRate = float64(s.Delivered) / float64(finished)
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+	"notification-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type InboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewInboxRepository(db *sqlx.DB) *InboxRepository {
+	return &InboxRepository{db: db}
+}
+
+// AddEntries puts a notification in the inbox of each user. Users who already have the message,
+// as when it is retried, are skipped.
+func (r *InboxRepository) AddEntries(userIDs []string, messageHash, notificationID, title, body string, data []byte) error {
+	query := `
+		INSERT INTO notification_inbox (user_id, message_hash, notification_id, title, body, data)
+		SELECT user_id, $2, $3, $4, $5, $6 FROM UNNEST($1::TEXT[]) AS user_id WHERE user_id <> ''
+		ON CONFLICT (user_id, message_hash) DO NOTHING`
+
+	if _, err := r.db.Exec(query, pq.Array(userIDs), messageHash, notificationID, title, body, data); err != nil {
+		return fmt.Errorf("failed to add inbox entries: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of the inbox of a user, newest first
+func (r *InboxRepository) List(userID string, filter models.InboxFilter) ([]*models.InboxEntry, error) {
+	query := `
+		SELECT * FROM notification_inbox
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4`
+
+	entries := []*models.InboxEntry{}
+	if err := r.db.Select(&entries, query, userID, filter.UnreadOnly, filter.Limit, filter.Offset); err != nil {
+		return nil, fmt.Errorf("failed to list inbox: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *InboxRepository) CountUnread(userID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM notification_inbox WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks an entry of the user as read; entries of other users are reported as not found
+func (r *InboxRepository) MarkRead(userID string, entryID int64) error {
+	result, err := r.db.Exec(`
+		UPDATE notification_inbox SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2`, entryID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("not_found: notification not found")
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread entry of the user as read and returns how many there were
+func (r *InboxRepository) MarkAllRead(userID string) (int, error) {
+	result, err := r.db.Exec(`UPDATE notification_inbox SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return int(rows), nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PreferenceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPreferenceRepository(db *sqlx.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+// Get returns the preference of a user, SMS for users who never chose
+func (r *PreferenceRepository) Get(userID string) (*models.NotificationPreference, error) {
+	preference := &models.NotificationPreference{}
+	err := r.db.Get(preference, `SELECT user_id, channel, updated_at FROM notification_preferences WHERE user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.NotificationPreference{UserID: userID, Channel: models.ChannelSMS}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+	return preference, nil
+}
+
+func (r *PreferenceRepository) Set(userID, channel string) (*models.NotificationPreference, error) {
+	query := `
+		INSERT INTO notification_preferences (user_id, channel)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET channel = EXCLUDED.channel, updated_at = NOW()
+		RETURNING user_id, channel, updated_at`
+
+	preference := &models.NotificationPreference{}
+	if err := r.db.Get(preference, query, userID, channel); err != nil {
+		return nil, fmt.Errorf("failed to set notification preference: %w", err)
+	}
+	return preference, nil
+}
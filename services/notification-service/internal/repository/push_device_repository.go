@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PushDeviceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPushDeviceRepository(db *sqlx.DB) *PushDeviceRepository {
+	return &PushDeviceRepository{db: db}
+}
+
+// Register assigns a device to a user and returns the user it belonged to before, empty when it is
+// new. A device signed in by another user moves to them.
+func (r *PushDeviceRepository) Register(userID, token, platform string) (string, error) {
+	var previousUserID string
+	err := r.db.Get(&previousUserID, `SELECT user_id FROM push_devices WHERE token = $1`, token)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to get push device: %w", err)
+	}
+
+	query := `
+		INSERT INTO push_devices (token, user_id, platform)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			platform = EXCLUDED.platform,
+			updated_at = NOW()`
+	if _, err := r.db.Exec(query, token, userID, platform); err != nil {
+		return "", fmt.Errorf("failed to register push device: %w", err)
+	}
+	return previousUserID, nil
+}
+
+// Delete removes a device of a user and reports whether it was theirs
+func (r *PushDeviceRepository) Delete(userID, token string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM push_devices WHERE token = $1 AND user_id = $2`, token, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete push device: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// DeleteTokens removes the devices FCM no longer knows, whoever they belong to
+func (r *PushDeviceRepository) DeleteTokens(tokens []string) (int, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+	result, err := r.db.Exec(`DELETE FROM push_devices WHERE token = ANY($1)`, pq.Array(tokens))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale push devices: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
+
+func (r *PushDeviceRepository) TokensOf(userIDs []string) ([]string, error) {
+	tokens := []string{}
+	if err := r.db.Select(&tokens, `SELECT token FROM push_devices WHERE user_id = ANY($1)`, pq.Array(userIDs)); err != nil {
+		return nil, fmt.Errorf("failed to get push devices: %w", err)
+	}
+	return tokens, nil
+}
+
+func (r *PushDeviceRepository) TopicsOf(userID string) ([]string, error) {
+	topics := []string{}
+	if err := r.db.Select(&topics, `SELECT topic FROM push_topic_subscriptions WHERE user_id = $1 ORDER BY topic`, userID); err != nil {
+		return nil, fmt.Errorf("failed to get push topics: %w", err)
+	}
+	return topics, nil
+}
+
+// SetTopics replaces the topics of a user
+func (r *PushDeviceRepository) SetTopics(userID string, topics []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM push_topic_subscriptions WHERE user_id = $1 AND topic <> ALL($2)`, userID, pq.Array(topics)); err != nil {
+		return fmt.Errorf("failed to remove push topics: %w", err)
+	}
+	query := `
+		INSERT INTO push_topic_subscriptions (user_id, topic)
+		SELECT $1, topic FROM UNNEST($2::TEXT[]) AS topic
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(query, userID, pq.Array(topics)); err != nil {
+		return fmt.Errorf("failed to add push topics: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit push topics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// RecipientAccountRepository keeps the account status of recipients, so deactivated users are
+// not notified
+type RecipientAccountRepository struct {
+	db *sqlx.DB
+}
+
+func NewRecipientAccountRepository(db *sqlx.DB) *RecipientAccountRepository {
+	return &RecipientAccountRepository{db: db}
+}
+
+// SetDeactivated records the status of an account as of changedAt, unless a later change is
+// already recorded
+func (r *RecipientAccountRepository) SetDeactivated(userID string, phoneNumber *string, deactivated bool, changedAt time.Time) error {
+	query := `
+		INSERT INTO recipient_accounts (user_id, phone_number, deactivated, status_changed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET phone_number = COALESCE(EXCLUDED.phone_number, recipient_accounts.phone_number),
+			deactivated = EXCLUDED.deactivated,
+			status_changed_at = EXCLUDED.status_changed_at,
+			updated_at = NOW()
+		WHERE recipient_accounts.status_changed_at < EXCLUDED.status_changed_at`
+
+	if _, err := r.db.Exec(query, userID, phoneNumber, deactivated, changedAt); err != nil {
+		return fmt.Errorf("failed to set recipient account status: %w", err)
+	}
+	return nil
+}
+
+// ActiveUsers returns the users of the list whose account is not deactivated, in list order
+func (r *RecipientAccountRepository) ActiveUsers(userIDs []string) ([]string, error) {
+	query := `
+		SELECT user_id FROM UNNEST($1::TEXT[]) WITH ORDINALITY AS u(user_id, position)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM recipient_accounts ra WHERE ra.user_id = u.user_id AND ra.deactivated
+		)
+		ORDER BY position`
+
+	active := []string{}
+	if err := r.db.Select(&active, query, pq.Array(userIDs)); err != nil {
+		return nil, fmt.Errorf("failed to filter deactivated users: %w", err)
+	}
+	return active, nil
+}
+
+// ActivePhones returns the numbers of the list that are not the number of a deactivated account,
+// in list order. Numbers are matched on their last nine digits, whatever their format.
+func (r *RecipientAccountRepository) ActivePhones(phoneNumbers []string) ([]string, error) {
+	query := `
+		SELECT phone_number FROM UNNEST($1::TEXT[]) WITH ORDINALITY AS p(phone_number, position)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM recipient_accounts ra
+			WHERE ra.deactivated
+				AND RIGHT(REGEXP_REPLACE(ra.phone_number, '\D', '', 'g'), 9) = RIGHT(REGEXP_REPLACE(p.phone_number, '\D', '', 'g'), 9)
+		)
+		ORDER BY position`
+
+	active := []string{}
+	if err := r.db.Select(&active, query, pq.Array(phoneNumbers)); err != nil {
+		return nil, fmt.Errorf("failed to filter deactivated phone numbers: %w", err)
+	}
+	return active, nil
+}
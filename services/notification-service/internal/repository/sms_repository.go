@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"fmt"
+	"notification-service/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type SMSRepository struct {
+	db *sqlx.DB
+}
+
+func NewSMSRepository(db *sqlx.DB) *SMSRepository {
+	return &SMSRepository{db: db}
+}
+
+// RecordSent records an SMS a gateway accepted, one row per recipient
+func (r *SMSRepository) RecordSent(messageHash, provider string, providerMessageID *string, phoneNumbers []string, segments int, costPerRecipient float64) error {
+	query := `
+		INSERT INTO sms_messages (message_hash, provider, provider_message_id, phone_number, segments, cost)
+		SELECT $1, $2, $3, phone_number, $5, $6 FROM UNNEST($4::TEXT[]) AS phone_number`
+
+	_, err := r.db.Exec(query, messageHash, provider, providerMessageID, pq.Array(phoneNumbers), segments, costPerRecipient)
+	if err != nil {
+		return fmt.Errorf("failed to record sent SMS: %w", err)
+	}
+	return nil
+}
+
+// ApplyReceipt records a delivery receipt and reports whether it matched a recipient. Gateways
+// report numbers in international format whatever format they were sent in, so numbers are matched
+// on their last nine digits, the subscriber number of a Vietnamese mobile. Delivered and failed
+// are final and not changed by a late receipt.
+func (r *SMSRepository) ApplyReceipt(provider, providerMessageID, phoneNumber, status string, reason *string) (bool, error) {
+	query := `
+		UPDATE sms_messages
+		SET status = $4,
+			failure_reason = COALESCE($5, failure_reason),
+			delivered_at = CASE WHEN $4 = 'delivered' THEN NOW() ELSE delivered_at END,
+			updated_at = NOW()
+		WHERE provider = $1 AND provider_message_id = $2
+			AND RIGHT(REGEXP_REPLACE(phone_number, '\D', '', 'g'), 9) = RIGHT(REGEXP_REPLACE($3, '\D', '', 'g'), 9)
+			AND status NOT IN ('delivered', 'failed')`
+
+	result, err := r.db.Exec(query, provider, providerMessageID, phoneNumber, status, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to apply SMS receipt: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// GetProviderStats reports the SMS of each gateway sent since the given time and their cost
+func (r *SMSRepository) GetProviderStats(since time.Time) ([]*models.SMSProviderStats, error) {
+	query := `
+		SELECT provider,
+			COUNT(*) AS recipients,
+			COALESCE(SUM(segments), 0) AS segments,
+			COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			COUNT(*) FILTER (WHERE status IN ('queued', 'sent')) AS pending,
+			COALESCE(SUM(cost), 0) AS cost
+		FROM sms_messages
+		WHERE created_at >= $1
+		GROUP BY provider
+		ORDER BY provider`
+
+	stats := []*models.SMSProviderStats{}
+	if err := r.db.Select(&stats, query, since); err != nil {
+		return nil, fmt.Errorf("failed to get SMS provider stats: %w", err)
+	}
+	return stats, nil
+}
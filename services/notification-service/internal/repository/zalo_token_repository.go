@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ZaloTokenRepository keeps the token pair of the Zalo Official Account, shared by all replicas
+type ZaloTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewZaloTokenRepository(db *sqlx.DB) *ZaloTokenRepository {
+	return &ZaloTokenRepository{db: db}
+}
+
+// Get returns the stored token pair, nil when none was stored yet
+func (r *ZaloTokenRepository) Get() (*models.ZaloToken, error) {
+	token := &models.ZaloToken{}
+	err := r.db.Get(token, `SELECT access_token, refresh_token, expires_at FROM zalo_oa_tokens`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Zalo token: %w", err)
+	}
+	return token, nil
+}
+
+func (r *ZaloTokenRepository) Save(token *models.ZaloToken) error {
+	query := `
+		INSERT INTO zalo_oa_tokens (id, access_token, refresh_token, expires_at)
+		VALUES (TRUE, $1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET
+			access_token = EXCLUDED.access_token,
+			refresh_token = EXCLUDED.refresh_token,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = NOW()`
+
+	if _, err := r.db.Exec(query, token.AccessToken, token.RefreshToken, token.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to save Zalo token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"notification-service/internal/config"
+	"notification-service/internal/event"
+	"notification-service/internal/models"
+	"notification-service/internal/profile"
+	"notification-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultCampaignPageSize = 20
+	maxCampaignPageSize     = 100
+	// Largest audience page profile-service returns
+	maxCampaignBatchSize = 1000
+	// A sending campaign saves its progress after every batch; one that has not for this long
+	// was left behind by an instance that stopped
+	campaignStaleAfter = 10 * time.Minute
+)
+
+// CampaignService sends a notification to every farmer of an audience at a scheduled time. The
+// audience is paged from profile-service and each page is queued as one notification per channel,
+// at a rate the SMS gateway and FCM accept.
+type CampaignService struct {
+	repo      *repository.CampaignRepository
+	audience  *profile.AudienceClient
+	publisher *event.Publisher
+	batchSize int
+	limiters  map[string]*rate.Limiter
+}
+
+func NewCampaignService(repo *repository.CampaignRepository, audience *profile.AudienceClient, publisher *event.Publisher, cfg config.CampaignConfig) *CampaignService {
+	batchSize := min(max(cfg.BatchSize, 1), maxCampaignBatchSize)
+	return &CampaignService{
+		repo:      repo,
+		audience:  audience,
+		publisher: publisher,
+		batchSize: batchSize,
+		// Limits are in recipients per second; the burst lets a whole batch through at once
+		limiters: map[string]*rate.Limiter{
+			models.CampaignChannelInApp: rate.NewLimiter(channelLimit(cfg.PushPerSecond), batchSize),
+			models.CampaignChannelSMS:   rate.NewLimiter(channelLimit(cfg.SMSPerSecond), batchSize),
+		},
+	}
+}
+
+func (s *CampaignService) Create(actor models.CampaignActor, req *models.CreateCampaignRequest) (*models.Campaign, error) {
+	partnerID, err := campaignPartner(actor, req.PartnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Name == "" || req.Title == "" || req.Body == "" {
+		return nil, fmt.Errorf("bad_request: name, title and body are required")
+	}
+	if len(req.Channels) == 0 {
+		return nil, fmt.Errorf("bad_request: at least one channel is required")
+	}
+	for _, channel := range req.Channels {
+		if channel != models.CampaignChannelInApp && channel != models.CampaignChannelSMS {
+			return nil, fmt.Errorf("bad_request: unsupported channel %q", channel)
+		}
+	}
+	audience := req.Audience
+	if len(audience.ProvinceCodes) == 0 && len(audience.DistrictCodes) == 0 && len(audience.CooperativeCodes) == 0 {
+		return nil, fmt.Errorf("bad_request: audience needs at least one of province_codes, district_codes or cooperative_codes")
+	}
+
+	scheduledAt := time.Now()
+	if req.ScheduledAt != nil {
+		if req.ScheduledAt.Before(scheduledAt.Add(-time.Minute)) {
+			return nil, fmt.Errorf("bad_request: scheduled_at is in the past")
+		}
+		scheduledAt = *req.ScheduledAt
+	}
+
+	campaign := &models.Campaign{
+		Name:        req.Name,
+		Title:       req.Title,
+		Body:        req.Body,
+		Channels:    slices.Compact(slices.Sorted(slices.Values(req.Channels))),
+		Audience:    audience,
+		ScheduledAt: scheduledAt,
+		CreatedBy:   actor.UserID,
+		PartnerID:   partnerID,
+	}
+	if len(req.Data) > 0 {
+		data, err := json.Marshal(req.Data)
+		if err != nil {
+			return nil, fmt.Errorf("bad_request: invalid data: %v", err)
+		}
+		campaign.Data = data
+	}
+
+	if err := s.repo.Create(campaign); err != nil {
+		return nil, err
+	}
+	slog.Info("Campaign scheduled", "campaign_id", campaign.ID, "created_by", actor.UserID, "partner_id", partnerID, "scheduled_at", campaign.ScheduledAt)
+	return campaign, nil
+}
+
+// campaignPartner returns the partner a campaign is created for. Partner admins send for one of
+// their partners, platform admins for any partner or, without one, for the platform.
+func campaignPartner(actor models.CampaignActor, requested string) (*string, error) {
+	if actor.PlatformAdmin {
+		if requested == "" {
+			return nil, nil
+		}
+		return &requested, nil
+	}
+
+	switch {
+	case len(actor.PartnerIDs) == 0:
+		return nil, fmt.Errorf("forbidden: campaigns are sent by insurance partner admins")
+	case requested == "" && len(actor.PartnerIDs) == 1:
+		return &actor.PartnerIDs[0], nil
+	case requested == "":
+		return nil, fmt.Errorf("bad_request: partner_id is required when administering several partners")
+	case !slices.Contains(actor.PartnerIDs, requested):
+		return nil, fmt.Errorf("forbidden: not an admin of partner %s", requested)
+	}
+	return &requested, nil
+}
+
+func (s *CampaignService) List(actor models.CampaignActor, limit, offset int) ([]*models.Campaign, error) {
+	if limit <= 0 {
+		limit = defaultCampaignPageSize
+	}
+	return s.repo.List(actor, min(limit, maxCampaignPageSize), max(offset, 0))
+}
+
+func (s *CampaignService) Get(id string, actor models.CampaignActor) (*models.Campaign, error) {
+	campaign, err := s.repo.GetByID(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil {
+		return nil, fmt.Errorf("not_found: campaign not found")
+	}
+	return campaign, nil
+}
+
+func (s *CampaignService) Cancel(id string, actor models.CampaignActor) (*models.Campaign, error) {
+	if _, err := s.Get(id, actor); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Cancel(id, actor); err != nil {
+		return nil, err
+	}
+	return s.Get(id, actor)
+}
+
+// GetStats reports how the notifications of a campaign were delivered, in recipients
+func (s *CampaignService) GetStats(id string, actor models.CampaignActor) (*models.CampaignStats, error) {
+	campaign, err := s.Get(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := s.repo.GetChannelStats(id)
+	if err != nil {
+		return nil, err
+	}
+	inboxRead, err := s.repo.CountInboxRead(id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CampaignStats{
+		CampaignID: campaign.ID,
+		Status:     campaign.Status,
+		Channels:   channels,
+		InboxRead:  inboxRead,
+	}, nil
+}
+
+// StartScheduler sends the campaigns that come due, checking every interval until ctx is done
+func (s *CampaignService) StartScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDueCampaigns(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *CampaignService) sendDueCampaigns(ctx context.Context) {
+	for ctx.Err() == nil {
+		campaign, err := s.repo.ClaimDue(campaignStaleAfter)
+		if err != nil {
+			slog.Error("Failed to claim due campaign", "error", err)
+			return
+		}
+		if campaign == nil {
+			return
+		}
+
+		if err := s.send(ctx, campaign); err != nil {
+			// A released campaign is picked up again on the next check and resumes from its cursor
+			var lastError *string
+			if ctx.Err() == nil {
+				slog.Error("Campaign send interrupted", "campaign_id", campaign.ID, "error", err)
+				message := err.Error()
+				lastError = &message
+			}
+			if err := s.repo.Release(campaign.ID, lastError); err != nil {
+				slog.Error("Failed to release campaign", "campaign_id", campaign.ID, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// send queues the batches of a campaign from its cursor on, until the audience is exhausted or the
+// campaign is cancelled
+func (s *CampaignService) send(ctx context.Context, campaign *models.Campaign) error {
+	cursor := ""
+	if campaign.AudienceCursor != nil {
+		cursor = *campaign.AudienceCursor
+	}
+	batch := campaign.BatchesSent
+	slog.InfoContext(ctx, "Sending campaign", "campaign_id", campaign.ID, "resume_batch", batch)
+
+	for {
+		status, err := s.repo.GetStatus(campaign.ID)
+		if err != nil {
+			return err
+		}
+		if status != models.CampaignSending {
+			slog.InfoContext(ctx, "Campaign stopped", "campaign_id", campaign.ID, "status", status)
+			return nil
+		}
+
+		page, err := s.audience.GetAudiencePage(ctx, campaign.Audience, cursor, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(page.Members) > 0 {
+			if err := s.sendBatch(ctx, campaign, batch, page.Members); err != nil {
+				return err
+			}
+			cursor = page.Members[len(page.Members)-1].UserID
+			if err := s.repo.SaveProgress(campaign.ID, cursor, len(page.Members)); err != nil {
+				return err
+			}
+			batch++
+		}
+
+		if page.Next == nil {
+			if err := s.repo.MarkSent(campaign.ID); err != nil {
+				return err
+			}
+			slog.InfoContext(ctx, "Campaign sent", "campaign_id", campaign.ID, "batches", batch)
+			return nil
+		}
+	}
+}
+
+// sendBatch queues a page of the audience on every channel of the campaign. The notifications of
+// a batch are built from the campaign alone, so a batch queued again after an interruption is the
+// same message and is not delivered twice.
+func (s *CampaignService) sendBatch(ctx context.Context, campaign *models.Campaign, batch int, members []models.FarmerAudienceMember) error {
+	var data map[string]any
+	if len(campaign.Data) > 0 {
+		if err := json.Unmarshal(campaign.Data, &data); err != nil {
+			return fmt.Errorf("failed to read campaign data: %w", err)
+		}
+	}
+	createdAt := campaign.ScheduledAt
+	if campaign.StartedAt != nil {
+		createdAt = *campaign.StartedAt
+	}
+
+	for _, channel := range campaign.Channels {
+		notification := &event.NotificationMessage{
+			ID:         fmt.Sprintf("%s:%d:%s", campaign.ID, batch, channel),
+			Priority:   event.PriorityLow,
+			CreatedAt:  createdAt,
+			CampaignID: campaign.ID,
+		}
+
+		var recipients []string
+		switch channel {
+		case models.CampaignChannelInApp:
+			for _, member := range members {
+				recipients = append(recipients, member.UserID)
+			}
+			notification.Type = event.TypeInApp
+			notification.Payload = map[string]any{
+				"lstUserIds": recipients,
+				"title":      campaign.Title,
+				"body":       campaign.Body,
+				"data":       data,
+			}
+		case models.CampaignChannelSMS:
+			for _, member := range members {
+				if member.Phone != "" {
+					recipients = append(recipients, member.Phone)
+				}
+			}
+			notification.Type = event.TypeSMS
+			notification.Payload = map[string]any{
+				"payload": map[string]any{
+					"notification": map[string]any{"title": campaign.Title, "body": campaign.Body},
+					"destinations": recipients,
+				},
+			}
+		}
+		if len(recipients) == 0 {
+			continue
+		}
+
+		if err := s.limiters[channel].WaitN(ctx, len(recipients)); err != nil {
+			return err
+		}
+		if err := s.publisher.Publish(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// channelLimit turns a configured rate into a limit, no limit when it is not set
+func channelLimit(perSecond float64) rate.Limit {
+	if perSecond <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(perSecond)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"notification-service/internal/google"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"slices"
+	"strings"
+)
+
+// PushService keeps the FCM devices of users subscribed to the topics of their province and crops,
+// and sends to users or to segments of those topics. Devices FCM reports as no longer registered
+// are removed whenever a send or a subscription comes across them.
+type PushService struct {
+	firebase *google.FirebaseService
+	devices  *repository.PushDeviceRepository
+}
+
+func NewPushService(firebase *google.FirebaseService, devices *repository.PushDeviceRepository) *PushService {
+	return &PushService{
+		firebase: firebase,
+		devices:  devices,
+	}
+}
+
+// RegisterDevice assigns a device to a user and subscribes it to the topics of the user. A device
+// that belonged to another user leaves the topics of theirs first.
+func (s *PushService) RegisterDevice(ctx context.Context, userID string, req *models.RegisterDeviceRequest) error {
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" {
+		return fmt.Errorf("bad_request: token is required")
+	}
+	switch req.Platform {
+	case models.PushPlatformAndroid, models.PushPlatformIOS, models.PushPlatformWeb:
+	default:
+		return fmt.Errorf("bad_request: platform must be android, ios or web")
+	}
+
+	previousUserID, err := s.devices.Register(userID, req.Token, req.Platform)
+	if err != nil {
+		return err
+	}
+	topics, err := s.devices.TopicsOf(userID)
+	if err != nil {
+		return err
+	}
+	if previousUserID != "" && previousUserID != userID {
+		previousTopics, err := s.devices.TopicsOf(previousUserID)
+		if err != nil {
+			return err
+		}
+		s.updateSubscriptions(ctx, []string{req.Token}, nil, withoutTopics(previousTopics, topics))
+	}
+	s.updateSubscriptions(ctx, []string{req.Token}, topics, nil)
+	return nil
+}
+
+// UnregisterDevice removes a device of a user, such as on sign out, and its topic subscriptions
+func (s *PushService) UnregisterDevice(ctx context.Context, userID, token string) error {
+	topics, err := s.devices.TopicsOf(userID)
+	if err != nil {
+		return err
+	}
+	removed, err := s.devices.Delete(userID, token)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("not_found: device not found")
+	}
+	s.updateSubscriptions(ctx, []string{token}, nil, topics)
+	return nil
+}
+
+// SetSegments sets the provinces and crops of a user and moves their devices to the matching topics
+func (s *PushService) SetSegments(ctx context.Context, userID string, req *models.PushSegmentsRequest) ([]string, error) {
+	var topics []string
+	for _, code := range req.ProvinceCodes {
+		if strings.TrimSpace(code) != "" {
+			topics = append(topics, google.ProvinceTopic(code))
+		}
+	}
+	for _, crop := range req.Crops {
+		if strings.TrimSpace(crop) != "" {
+			topics = append(topics, google.CropTopic(crop))
+		}
+	}
+	slices.Sort(topics)
+	topics = slices.Compact(topics)
+
+	current, err := s.devices.TopicsOf(userID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.devices.TokensOf([]string{userID})
+	if err != nil {
+		return nil, err
+	}
+	// Stored first, so a device registered meanwhile subscribes to the new topics
+	if err := s.devices.SetTopics(userID, topics); err != nil {
+		return nil, err
+	}
+	s.updateSubscriptions(ctx, tokens, withoutTopics(topics, current), withoutTopics(current, topics))
+	return topics, nil
+}
+
+// Send sends a notification to the devices of users, or to a segment of provinces and crops
+func (s *PushService) Send(ctx context.Context, req *models.PushSendRequest) (*models.PushSendResult, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Title == "" || req.Body == "" {
+		return nil, fmt.Errorf("bad_request: title and body are required")
+	}
+	hasSegment := len(req.ProvinceCodes)+len(req.Crops) > 0
+	if (len(req.UserIDs) > 0) == hasSegment {
+		return nil, fmt.Errorf("bad_request: send to either user_ids or a segment of province_codes and crops")
+	}
+	payload := &google.PushNotificationPayload{Title: req.Title, Body: req.Body, Data: req.Data}
+
+	if hasSegment {
+		condition, err := google.SegmentCondition(req.ProvinceCodes, req.Crops)
+		if err != nil {
+			return nil, err
+		}
+		messageID, err := s.firebase.SendToCondition(ctx, condition, payload)
+		if err != nil {
+			return nil, err
+		}
+		slog.InfoContext(ctx, "Push notification sent to segment", "condition", condition, "message_id", messageID)
+		return &models.PushSendResult{MessageID: messageID}, nil
+	}
+
+	tokens, err := s.devices.TokensOf(req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &models.PushSendResult{}, nil
+	}
+	sent, stale, err := s.firebase.SendToTokens(ctx, tokens, payload)
+	removed := s.removeStale(ctx, stale)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PushSendResult{Sent: sent, Removed: removed}, nil
+}
+
+// updateSubscriptions subscribes devices to topics and unsubscribes them from others. FCM failing
+// is logged rather than returned, the stored topics stay the ones to follow.
+func (s *PushService) updateSubscriptions(ctx context.Context, tokens, subscribe, unsubscribe []string) {
+	if len(tokens) == 0 {
+		return
+	}
+	var stale []string
+	for _, topic := range subscribe {
+		staleTokens, err := s.firebase.SubscribeToTopic(ctx, tokens, topic)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to subscribe devices to topic", "topic", topic, "error", err)
+		}
+		stale = append(stale, staleTokens...)
+	}
+	for _, topic := range unsubscribe {
+		staleTokens, err := s.firebase.UnsubscribeFromTopic(ctx, tokens, topic)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to unsubscribe devices from topic", "topic", topic, "error", err)
+		}
+		stale = append(stale, staleTokens...)
+	}
+	slices.Sort(stale)
+	s.removeStale(ctx, slices.Compact(stale))
+}
+
+func (s *PushService) removeStale(ctx context.Context, tokens []string) int {
+	if len(tokens) == 0 {
+		return 0
+	}
+	removed, err := s.devices.DeleteTokens(tokens)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to remove stale push devices", "count", len(tokens), "error", err)
+		return 0
+	}
+	slog.InfoContext(ctx, "Removed stale push devices", "count", removed)
+	return removed
+}
+
+// withoutTopics returns the topics not in others
+func withoutTopics(topics, others []string) []string {
+	var result []string
+	for _, topic := range topics {
+		if !slices.Contains(others, topic) {
+			result = append(result, topic)
+		}
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+package zalo
+
+import (
+	"agrisa_utils/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"notification-service/internal/config"
+	"notification-service/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshMargin is how long before it expires an access token is refreshed
+const refreshMargin = 5 * time.Minute
+
+// ZNS error codes of an access token that is invalid or expired
+var invalidTokenCodes = map[int]bool{-124: true, -216: true}
+
+// ZNS error codes of a message that would be refused again, such as one to a phone number with no
+// Zalo account
+var rejectedCodes = map[int]bool{-108: true, -109: true, -118: true, -119: true, -139: true}
+
+// TokenStore keeps the token pair of the OA, so replicas share it and a rotated refresh token
+// survives a restart
+type TokenStore interface {
+	Get() (*models.ZaloToken, error)
+	Save(token *models.ZaloToken) error
+}
+
+// RejectedError is a message Zalo refused, such as one to a user not on Zalo. It is sent by SMS
+// instead.
+type RejectedError struct {
+	err error
+}
+
+func (e *RejectedError) Error() string { return e.err.Error() }
+
+func (e *RejectedError) Unwrap() error { return e.err }
+
+// OAService sends ZNS template messages from the Zalo Official Account
+type OAService struct {
+	cfg    config.ZaloConfig
+	tokens TokenStore
+	client *http.Client
+
+	mu    sync.Mutex
+	token *models.ZaloToken
+}
+
+type templateRequest struct {
+	Phone        string            `json:"phone"`
+	TemplateID   string            `json:"template_id"`
+	TemplateData map[string]string `json:"template_data"`
+	TrackingID   string            `json:"tracking_id,omitempty"`
+}
+
+type templateResponse struct {
+	Error   int    `json:"error"`
+	Message string `json:"message"`
+	Data    struct {
+		MsgID string `json:"msg_id"`
+	} `json:"data"`
+}
+
+type tokenResponse struct {
+	AccessToken  string      `json:"access_token"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    json.Number `json:"expires_in"`
+	Error        int         `json:"error"`
+	ErrorName    string      `json:"error_name"`
+	ErrorReason  string      `json:"error_reason"`
+}
+
+// zaloError is an error Zalo answered a request with
+type zaloError struct {
+	code    int
+	message string
+}
+
+func (e *zaloError) Error() string {
+	return fmt.Sprintf("zalo error %d: %s", e.code, e.message)
+}
+
+// NewOAService returns nil when no OA is configured
+func NewOAService(cfg config.ZaloConfig, tokens TokenStore) *OAService {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &OAService{
+		cfg:    cfg,
+		tokens: tokens,
+		client: logging.NewHTTPClient(10 * time.Second),
+	}
+}
+
+// SendNotification sends a notification through the general template of the OA and returns the
+// ID Zalo gave the message
+func (s *OAService) SendNotification(ctx context.Context, phoneNumber, title, content, trackingID string) (string, error) {
+	return s.SendTemplate(ctx, phoneNumber, s.cfg.NotificationTemplateID, map[string]string{
+		"title":   title,
+		"content": content,
+	}, trackingID)
+}
+
+// SendTemplate sends a ZNS template message to a phone number. An access token Zalo no longer
+// accepts is refreshed and the message sent once more.
+func (s *OAService) SendTemplate(ctx context.Context, phoneNumber, templateID string, data map[string]string, trackingID string) (string, error) {
+	request := templateRequest{
+		Phone:        internationalPhone(phoneNumber),
+		TemplateID:   templateID,
+		TemplateData: data,
+		TrackingID:   trackingID,
+	}
+
+	accessToken, err := s.accessToken(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	msgID, err := s.sendTemplate(ctx, accessToken, &request)
+	var zErr *zaloError
+	if errors.As(err, &zErr) && invalidTokenCodes[zErr.code] {
+		slog.WarnContext(ctx, "Zalo access token refused, refreshing", "code", zErr.code)
+		if accessToken, err = s.accessToken(ctx, true); err != nil {
+			return "", err
+		}
+		msgID, err = s.sendTemplate(ctx, accessToken, &request)
+	}
+	if errors.As(err, &zErr) && rejectedCodes[zErr.code] {
+		return "", &RejectedError{err: err}
+	}
+	return msgID, err
+}
+
+func (s *OAService) sendTemplate(ctx context.Context, accessToken string, request *templateRequest) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Zalo template message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.APIURL+"/message/template", bytes.NewBuffer(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("access_token", accessToken)
+
+	var response templateResponse
+	if err := s.do(req, &response); err != nil {
+		return "", fmt.Errorf("failed to send Zalo template message: %w", err)
+	}
+	if response.Error != 0 {
+		return "", &zaloError{code: response.Error, message: response.Message}
+	}
+	return response.Data.MsgID, nil
+}
+
+// accessToken returns a token valid for a while yet, refreshing the stored pair when it is close to
+// expiring or force is set
+func (s *OAService) accessToken(ctx context.Context, force bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil || force {
+		// Another replica may have refreshed the pair already
+		stored, err := s.tokens.Get()
+		if err != nil {
+			return "", err
+		}
+		if stored != nil && (s.token == nil || stored.AccessToken != s.token.AccessToken) {
+			s.token = stored
+			force = false
+		}
+	}
+	if s.token != nil && !force && time.Until(s.token.ExpiresAt) > refreshMargin {
+		return s.token.AccessToken, nil
+	}
+
+	refreshToken := s.cfg.RefreshToken
+	if s.token != nil {
+		refreshToken = s.token.RefreshToken
+	}
+	if refreshToken == "" {
+		return "", fmt.Errorf("no Zalo refresh token, set ZALO_REFRESH_TOKEN")
+	}
+	token, err := s.refresh(ctx, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	// Zalo has already rotated the refresh token, a token that is not stored is lost on restart
+	if err := s.tokens.Save(token); err != nil {
+		slog.ErrorContext(ctx, "Failed to store refreshed Zalo token", "error", err)
+	}
+	s.token = token
+	slog.InfoContext(ctx, "Zalo access token refreshed", "expires_at", token.ExpiresAt)
+	return token.AccessToken, nil
+}
+
+func (s *OAService) refresh(ctx context.Context, refreshToken string) (*models.ZaloToken, error) {
+	form := url.Values{
+		"app_id":        {s.cfg.AppID},
+		"refresh_token": {refreshToken},
+		"grant_type":    {"refresh_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.OAuthURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("secret_key", s.cfg.SecretKey)
+
+	var response tokenResponse
+	if err := s.do(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to refresh Zalo token: %w", err)
+	}
+	if response.AccessToken == "" || response.RefreshToken == "" {
+		return nil, fmt.Errorf("failed to refresh Zalo token: %w",
+			&zaloError{code: response.Error, message: response.ErrorName + " " + response.ErrorReason})
+	}
+	expiresIn, err := strconv.Atoi(response.ExpiresIn.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid Zalo token expiry %q: %w", response.ExpiresIn, err)
+	}
+	return &models.ZaloToken{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
+
+func (s *OAService) do(req *http.Request, out any) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("zalo returned %s: %s", resp.Status, body)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// internationalPhone writes a Vietnamese number the way ZNS expects it, 84 followed by the
+// subscriber number
+func internationalPhone(phoneNumber string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phoneNumber)
+	if strings.HasPrefix(digits, "0") {
+		return "84" + digits[1:]
+	}
+	return digits
+}
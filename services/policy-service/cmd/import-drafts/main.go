@@ -0,0 +1,58 @@
+// Command import-drafts moves the draft base policies that were kept in Redis keys into the
+// base_policy_draft table. Run it once the migrations are applied; drafts imported already are
+// skipped, so it can be run again after a failure.
+//
+//	import-drafts [-dry-run]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"policy-service/internal/config"
+	"policy-service/internal/database/postgres"
+	"policy-service/internal/database/redis"
+	"policy-service/internal/repository"
+	"policy-service/internal/services"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the drafts to import without writing anything")
+	flag.Parse()
+
+	cfg := config.New()
+	cfg.PostgresCfg.AutoMigrate = false
+	db, err := postgres.ConnectAndCreateDB(cfg.PostgresCfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	redisClient, err := redis.NewRedisClient(cfg.RedisCfg.Host, cfg.RedisCfg.Port, cfg.RedisCfg.Password, cfg.RedisCfg.DB)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer redisClient.Close()
+
+	importer := services.NewDraftImporter(
+		redisClient.GetClient(),
+		repository.NewBasePolicyRepository(db, redisClient.GetClient()),
+		repository.NewBasePolicyDraftRepository(db, redisClient.GetClient()),
+	)
+	result, err := importer.Import(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	verb := "imported"
+	if *dryRun {
+		verb = "to import"
+	}
+	log.Printf("Found %d drafts: %d %s, %d skipped, %d failed", result.Found, result.Imported, verb, result.Skipped, len(result.Failures))
+	for _, failure := range result.Failures {
+		log.Printf("Failed: %s", failure)
+	}
+	if len(result.Failures) > 0 {
+		log.Fatalf("%d drafts were not imported", len(result.Failures))
+	}
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
+	"agrisa_utils/logging"
+	"agrisa_utils/shutdown"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"os"
-	"os/signal"
-	"path/filepath"
 	"policy-service/internal/ai/gemini"
+	"policy-service/internal/clamav"
 	"policy-service/internal/config"
 	"policy-service/internal/database/minio"
 	"policy-service/internal/database/postgres"
@@ -19,54 +21,13 @@ import (
 	"policy-service/internal/services"
 	"policy-service/internal/worker"
 	"strings"
-	"syscall"
 	"time"
 
+	"agrisa/migration"
+
 	"github.com/gofiber/fiber/v3"
 )
 
-func setupLogging() (*os.File, error) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic: %v\n", r)
-		}
-	}()
-
-	logDir := filepath.Join("/agrisa", "log", "policy_service")
-	fmt.Println("Log directory:", logDir)
-	err := os.MkdirAll(logDir, 0o755)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %v", err)
-	}
-
-	currentTime := time.Now()
-	logFileName := fmt.Sprintf("log_%s.log", currentTime.Format("2006-01-02"))
-	logFile := filepath.Join(logDir, logFileName)
-
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
-	}
-
-	if _, err := os.Stat(logFile); err == nil {
-		absPath, err := filepath.Abs(logFile)
-		if err != nil {
-			fmt.Printf("Failed to get absolute path of log file: %v\n", err)
-		} else {
-			fmt.Printf("Log file exists at absolute path: %s\n", absPath)
-		}
-	} else if os.IsNotExist(err) {
-		fmt.Println("Log file does not exist (it will be created)")
-	} else {
-		fmt.Printf("Error checking log file existence: %v\n", err)
-	}
-
-	log.SetOutput(file)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-
-	return file, nil
-}
-
 func main() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -74,7 +35,7 @@ func main() {
 		}
 	}()
 
-	logFile, err := setupLogging()
+	logFile, err := logging.Setup("policy_service")
 	if err != nil {
 		log.Fatalf("Failed to set up logging: %v", err)
 	}
@@ -83,14 +44,20 @@ func main() {
 	log.Printf("Connecting to PostgreSQL with: host=%s, port=%s, user=%s, dbname=auth_service",
 		cfg.PostgresCfg.Host, cfg.PostgresCfg.Port, cfg.PostgresCfg.Username)
 	db, err := postgres.ConnectAndCreateDB(cfg.PostgresCfg)
+	if errors.Is(err, migration.ErrSchemaOutdated) {
+		log.Fatalf("Refusing to start, run cmd/migrate first: %v", err)
+	}
 	if err != nil {
 		log.Printf("error connect to database: %s", err)
 		go postgres.RetryConnectOnFailed(30*time.Second, &db, cfg.PostgresCfg)
 	}
 
+	// Policy PDFs are uploaded straight to MinIO (see /policy-document-uploads); the limit only
+	// has to fit the files still sent base64 encoded, such as import files and land certificates
 	app := fiber.New(fiber.Config{
-		BodyLimit: 200 * 1024 * 1024,
+		BodyLimit: 20 * 1024 * 1024,
 	})
+	app.Use(logging.FiberMiddleware())
 	app.Get("/checkhealth", func(c fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).SendString("Policy service is healthy")
 	})
@@ -107,11 +74,16 @@ func main() {
 	if err != nil {
 		log.Fatalf("CRITICAL: Cannot start policy service without RabbitMQ connection: %v", err)
 	}
-	defer rabbitConn.Close()
 
+	geminiResilience := gemini.Resilience{
+		Timeout:         cfg.GeminiAPICfg.RequestTimeout,
+		MaxRetries:      cfg.GeminiAPICfg.MaxRetries,
+		BreakerFailures: cfg.GeminiAPICfg.BreakerFailures,
+		BreakerCooldown: cfg.GeminiAPICfg.BreakerCooldown,
+	}
 	keys := strings.SplitSeq(cfg.GeminiAPICfg.APIKey, ",")
 	for key := range keys {
-		geminiClient, err := gemini.NewGenAIClient(key, cfg.GeminiAPICfg.FlashName, cfg.GeminiAPICfg.ProName)
+		geminiClient, err := gemini.NewGenAIClient(key, cfg.GeminiAPICfg.FlashName, cfg.GeminiAPICfg.ProName, geminiResilience)
 		if err != nil {
 			slog.Error("error initializing gemini client", "error", err)
 		} else {
@@ -138,6 +110,8 @@ func main() {
 	// Initialize repositories
 	dataTierRepo := repository.NewDataTierRepository(db)
 	basePolicyRepo := repository.NewBasePolicyRepository(db, redisClient.GetClient())
+	basePolicyDraftRepo := repository.NewBasePolicyDraftRepository(db, redisClient.GetClient())
+	draftExpirationRepo := repository.NewDraftExpirationEventRepository(db)
 	dataSourceRepo := repository.NewDataSourceRepository(db)
 	registeredPolicyRepo := repository.NewRegisteredPolicyRepository(db)
 	farmRepo := repository.NewFarmRepository(db)
@@ -148,6 +122,18 @@ func main() {
 	payoutRepo := repository.NewPayoutRepository(db)
 	cancelRepo := repository.NewCancelRequestRepository(db)
 	dashboardRepo := repository.NewDashboardRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	basePolicyVersionRepo := repository.NewBasePolicyVersionRepository(db)
+	policyAttachmentRepo := repository.NewPolicyAttachmentRepository(db)
+	policyDocumentUploadRepo := repository.NewPolicyDocumentUploadRepository(db)
+	documentScanRepo := repository.NewDocumentScanRepository(db)
+	premiumScheduleRepo := repository.NewPremiumScheduleRepository(db)
+	policyImportRepo := repository.NewPolicyImportRepository(db)
+	aiUsageRepo := repository.NewAIUsageRepository(db)
+	claimDisputeRepo := repository.NewClaimDisputeRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	invoiceRepo := repository.NewInvoiceRepository(db)
 
 	// Initialize WorkerManagerV2
 	workerManager := worker.NewWorkerManagerV2(db, redisClient)
@@ -158,31 +144,60 @@ func main() {
 	// Initialize services
 	dataTierService := services.NewDataTierService(dataTierRepo)
 	dataSourceService := services.NewDataSourceService(dataSourceRepo, cfg)
-	basePolicyService := services.NewBasePolicyService(basePolicyRepo, dataSourceRepo, dataTierRepo, minioClient, gemini.GeminiClients, registeredPolicyRepo, notificationHelper, cancelRepo, redisClient)
-	farmService := services.NewFarmService(farmRepo, cfg, minioClient, workerManager)
+	providerDirectory := services.NewProviderDirectory(cfg)
+	auditService := services.NewAuditService(auditRepo)
+	aiUsageService := services.NewAIUsageService(aiUsageRepo, outboxRepo, cfg.GeminiAPICfg)
+	policyAttachmentService := services.NewPolicyAttachmentService(policyAttachmentRepo, basePolicyRepo, registeredPolicyRepo, minioClient)
+	policyDocumentUploadService := services.NewPolicyDocumentUploadService(policyDocumentUploadRepo, minioClient)
+	var clamavClient *clamav.Client
+	if cfg.ClamAVAddress != "" {
+		clamavClient = clamav.NewClient(cfg.ClamAVAddress, 2*time.Minute)
+		if err := clamavClient.Ping(context.Background()); err != nil {
+			slog.Error("clamav is not reachable, document scans will be retried until it is", "address", cfg.ClamAVAddress, "error", err)
+		}
+	} else {
+		slog.Warn("CLAMAV_ADDRESS is not set, uploaded documents only get signature checks")
+	}
+	documentScanService := services.NewDocumentScanService(documentScanRepo, minioClient, clamavClient, workerManager)
+	basePolicyService := services.NewBasePolicyService(basePolicyRepo, dataSourceRepo, dataTierRepo, minioClient, gemini.GeminiClients, registeredPolicyRepo, notificationHelper, cancelRepo, redisClient, providerDirectory, auditService, basePolicyVersionRepo, aiUsageService, basePolicyDraftRepo)
+	farmService := services.NewFarmService(farmRepo, cfg, minioClient, workerManager, documentScanService, geminiSelector, auditService, aiUsageService)
 	pdfDocumentService := services.NewPDFService(minioClient, minio.Storage.PolicyDocuments)
-	registeredPolicyService := services.NewRegisteredPolicyService(registeredPolicyRepo, basePolicyRepo, basePolicyService, farmService, workerManager, pdfDocumentService, dataSourceRepo, farmMonitoringDataRepo, minioClient, notificationHelper, geminiSelector, redisClient)
-	expirationService := services.NewPolicyExpirationService(redisClient.GetClient(), basePolicyService, minioClient, registeredPolicyRepo, basePolicyRepo, notificationHelper, workerManager, cancelRepo)
+	consentChecker := services.NewConsentChecker(cfg)
+	payoutCalculationService := services.NewPayoutCalculationService(basePolicyRepo, farmRepo)
+	registeredPolicyService := services.NewRegisteredPolicyService(registeredPolicyRepo, basePolicyRepo, basePolicyService, farmService, workerManager, pdfDocumentService, dataSourceRepo, farmMonitoringDataRepo, minioClient, notificationHelper, geminiSelector, redisClient, consentChecker, payoutCalculationService, providerDirectory, auditService, aiUsageService)
+	expirationService := services.NewPolicyExpirationService(redisClient.GetClient(), basePolicyService, minioClient, registeredPolicyRepo, basePolicyRepo, notificationHelper, workerManager, cancelRepo, outboxRepo, draftExpirationRepo)
 	basePolicyTriggerService := services.NewBasePolicyTriggerService(basePolicyTriggerRepo)
 	riskAnalysisService := services.NewRiskAnalysisCRUDService(registeredPolicyRepo)
 	claimService := services.NewClaimService(claimRepo, registeredPolicyRepo, farmRepo, payoutRepo, notificationHelper)
 	claimRejectionService := services.NewClaimRejectionService(registeredPolicyRepo, claimRepo, claimRejectionRepo)
 	dashboardService := services.NewDashboardService(registeredPolicyRepo, dashboardRepo)
+	analyticsService := services.NewAnalyticsService(analyticsRepo)
 	payoutServie := services.NewPayoutService(payoutRepo, registeredPolicyRepo, farmRepo)
 	cancelRequestService := services.NewCancelRequestService(registeredPolicyRepo, cancelRepo, notificationHelper, redisClient, claimRepo)
-
-	// Expiration Listener
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	go func() {
-		if err := expirationService.StartListener(ctx); err != nil {
+	premiumPaymentService := services.NewPremiumPaymentService(registeredPolicyRepo, premiumScheduleRepo, cfg)
+	premiumScheduleService := services.NewPremiumScheduleService(premiumScheduleRepo, registeredPolicyRepo, basePolicyRepo, workerManager, outboxRepo)
+	monitoringRetentionService := services.NewMonitoringRetentionService(farmMonitoringDataRepo, minioClient, cfg.MonitoringRetentionCfg)
+	invoiceService := services.NewInvoiceService(invoiceRepo, outboxRepo, registeredPolicyService, minioClient, cfg.InvoicingCfg)
+	claimDisputeService := services.NewClaimDisputeService(claimDisputeRepo, claimRepo, registeredPolicyRepo, payoutRepo, outboxRepo, documentScanService, minioClient, cfg.ClaimDisputeCfg)
+	policyImportService := services.NewPolicyImportService(policyImportRepo, registeredPolicyService, basePolicyService, farmService, minioClient, workerManager)
+
+	// Listeners and consumers run until the HTTP server has drained
+	coordinator := shutdown.New()
+	ctx := coordinator.Context()
+
+	// Expiration Listener, on every instance: each expired key is claimed by the first to hear it
+	coordinator.Go("expiration-listener", func(ctx context.Context) {
+		if err := expirationService.StartListener(ctx); err != nil && !errors.Is(err, context.Canceled) {
 			log.Printf("Expiration service error: %v", err)
 		}
-	}()
+	})
+
+	// Publish the notifications queued in the outbox
+	outboxDispatcher := event.NewOutboxDispatcher(outboxRepo, rabbitConn)
+	coordinator.Go("outbox-dispatcher", outboxDispatcher.Start)
 
 	// Start payment event consumer
-	paymentHandler := event.NewDefaultPaymentEventHandler(registeredPolicyRepo, basePolicyRepo, workerManager, claimRepo, payoutRepo, notificationHelper, cancelRepo, cancelRequestService)
+	paymentHandler := event.NewDefaultPaymentEventHandler(registeredPolicyRepo, basePolicyRepo, workerManager, claimRepo, payoutRepo, notificationHelper, cancelRepo, cancelRequestService, outboxRepo, premiumScheduleRepo)
 	paymentConsumer := event.NewPaymentConsumer(rabbitConn, paymentHandler)
 	if err := paymentConsumer.Start(ctx); err != nil {
 		log.Printf("error starting payment consumer: %v", err)
@@ -223,6 +238,9 @@ func main() {
 	workerManager.RegisterJobHandler("fetch-farm-monitoring-data", registeredPolicyService.FetchFarmMonitoringDataJob)
 	workerManager.RegisterJobHandler("document-validation", basePolicyService.AIPolicyValidationJob)
 	workerManager.RegisterJobHandler("farm-imagery", farmService.GetFarmPhotoJob)
+	workerManager.RegisterJobHandler("farm-weather-backfill", registeredPolicyService.FarmWeatherBackfillJob)
+	workerManager.RegisterJobHandler("crop-verification", farmService.CropVerificationJob)
+	workerManager.RegisterJobHandler(services.LandCertificateOCRJobType, farmService.LandCertificateOCRJob)
 	workerManager.RegisterJobHandler("risk-analysis", registeredPolicyService.RiskAnalysisJob)
 	worker.AIWorkerPoolUUID, err = workerManager.CreateAIWorkerInfrastructure(workerManager.ManagerContext())
 	if err != nil {
@@ -233,8 +251,56 @@ func main() {
 			slog.Error("error starting AI worker pool", "error", err)
 		}
 	}
+	workerManager.RegisterJobHandler(services.DocumentScanJobType, documentScanService.DocumentScanJob)
+	worker.ScanWorkerPoolUUID, err = workerManager.CreateDocumentScanWorkerInfrastructure(workerManager.ManagerContext())
+	if err != nil {
+		slog.Error("error create document scan worker pool", "error", err)
+	} else {
+		err = workerManager.StartAIWorkerInfrastructure(workerManager.ManagerContext(), *worker.ScanWorkerPoolUUID)
+		if err != nil {
+			slog.Error("error starting document scan worker pool", "error", err)
+		} else if err := documentScanService.RecoverPendingScans(); err != nil {
+			slog.Error("error recovering pending document scans", "error", err)
+		}
+	}
+	workerManager.RegisterJobHandler(services.PolicyImportJobType, policyImportService.PolicyImportJob)
+	worker.ImportWorkerPoolUUID, err = workerManager.CreatePolicyImportWorkerInfrastructure(workerManager.ManagerContext())
+	if err != nil {
+		slog.Error("error create policy import worker pool", "error", err)
+	} else {
+		err = workerManager.StartAIWorkerInfrastructure(workerManager.ManagerContext(), *worker.ImportWorkerPoolUUID)
+		if err != nil {
+			slog.Error("error starting policy import worker pool", "error", err)
+		}
+	}
 
-	// Recover active policy worker infrastructure after restart
+	// Periodic tasks, each tick is run by a single instance
+	cronJobs := []worker.CronJob{
+		// Mark missed premium installments overdue and lapse the policies behind on them
+		{Name: "premium-overdue-check", Schedule: "@hourly", Timeout: 30 * time.Minute, Run: premiumScheduleService.CheckOverdue},
+		// Create the coming monthly partitions of farm_monitoring_data and archive the expired ones
+		{Name: "monitoring-partition-maintenance", Schedule: "30 1 * * *", Timeout: 2 * time.Hour, Run: monitoringRetentionService.MaintainPartitions},
+		// Escalate the claim disputes the partner did not answer in time to arbitration
+		{Name: "claim-dispute-sla", Schedule: "@hourly", Timeout: 15 * time.Minute, Run: claimDisputeService.CheckSLA},
+		// Refresh the materialized views behind the insurer analytics
+		{Name: "analytics-refresh", Schedule: "*/15 * * * *", Timeout: 10 * time.Minute, Run: analyticsService.RefreshViews},
+		// Invoice the data cost of the previous month to the partners not invoiced yet
+		{Name: "invoice-generation", Schedule: "0 2 * * *", Timeout: time.Hour, Run: invoiceService.GenerateMonthlyInvoices},
+		// Mark the partner invoices past their due date overdue
+		{Name: "invoice-overdue-check", Schedule: "0 3 * * *", Timeout: 15 * time.Minute, Run: invoiceService.CheckOverdue},
+		// Close the validation windows of drafts whose key expiry was missed, and retry failed ones
+		{Name: "draft-expiration-sweep", Schedule: "@every 1m", Timeout: 5 * time.Minute, Run: expirationService.SweepExpiredDrafts},
+		// Take over the active policies no instance runs, those of an instance that died
+		{Name: "policy-recovery", Schedule: "@every 5m", Timeout: 4 * time.Minute, Run: registeredPolicyService.RecoverPolicies},
+	}
+	for _, job := range cronJobs {
+		if err := workerManager.RegisterCronJob(job); err != nil {
+			slog.Error("error registering cron job", "cron_job", job.Name, "error", err)
+		}
+	}
+	workerManager.StartCronScheduler()
+
+	// Recover the active policies no instance runs after restart
 	if err := registeredPolicyService.RecoverPolicies(ctx); err != nil {
 		log.Printf("Warning: failed to recover active policies: %v", err)
 		// Non-fatal: continue startup even if recovery fails
@@ -243,17 +309,32 @@ func main() {
 	// Initialize handlers
 	dataTierHandler := handlers.NewDataTierHandler(dataTierService)
 	dataSourceHandler := handlers.NewDataSourceHandler(dataSourceService)
-	basePolicyHandler := handlers.NewBasePolicyHandler(basePolicyService, minioClient, workerManager, registeredPolicyService)
-	farmHandler := handlers.NewFarmHandler(farmService, minioClient)
-	policyHandler := handlers.NewPolicyHandler(registeredPolicyService, riskAnalysisService, basePolicyService, cancelRequestService)
+	rateLimiter := services.NewRateLimiter(redisClient, cfg.RateLimitCfg)
+	basePolicyHandler := handlers.NewBasePolicyHandler(basePolicyService, minioClient, workerManager, registeredPolicyService, policyDocumentUploadService, documentScanService, rateLimiter)
+	farmHandler := handlers.NewFarmHandler(farmService, registeredPolicyService, minioClient, rateLimiter)
+	idempotencyStore := services.NewIdempotencyStore(redisClient)
+	policyHandler := handlers.NewPolicyHandler(registeredPolicyService, riskAnalysisService, basePolicyService, cancelRequestService, idempotencyStore, premiumScheduleService, premiumPaymentService, policyImportService)
 	basePolicyTriggerHandler := handlers.NewBasePolicyTriggerHandler(basePolicyTriggerService)
-	riskAnalysisHandler := handlers.NewRiskAnalysisHandler(riskAnalysisService)
+	riskAnalysisHandler := handlers.NewRiskAnalysisHandler(riskAnalysisService, registeredPolicyService, rateLimiter)
 	claimHandler := handlers.NewClaimHandler(claimService, registeredPolicyService)
 	claimRejectionHandler := handlers.NewClaimRejectionHandler(claimRejectionService, registeredPolicyService)
-	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
-	payoutHandler := handlers.NewPayoutHandler(payoutServie, registeredPolicyService)
+	claimDisputeHandler := handlers.NewClaimDisputeHandler(claimDisputeService, registeredPolicyService)
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService, registeredPolicyService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService, registeredPolicyService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService, registeredPolicyService)
+	payoutHandler := handlers.NewPayoutHandler(payoutServie, registeredPolicyService, payoutCalculationService)
 	cancelRequestHandler := handlers.NewCancelRequestHandler(registeredPolicyService, cancelRequestService)
 	dataBillHandler := handlers.NewDataBillHandler(basePolicyService, notificationHelper, registeredPolicyService)
+	workerHandler := handlers.NewWorkerHandler(workerManager)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	policyAttachmentHandler := handlers.NewPolicyAttachmentHandler(policyAttachmentService)
+	policyDocumentUploadHandler := handlers.NewPolicyDocumentUploadHandler(policyDocumentUploadService)
+	documentScanHandler := handlers.NewDocumentScanHandler(documentScanService)
+	aiUsageHandler := handlers.NewAIUsageHandler(aiUsageService)
+
+	// Every protected route needs a token from auth-service; the handlers gate routes by role
+	authMiddleware := handlers.NewAuthMiddleware(cfg.JWTSecret, cfg.APIKey)
+	app.Use("/policy/protected", authMiddleware.Authenticate)
 
 	// Register routes
 	dataTierHandler.Register(app)
@@ -265,28 +346,54 @@ func main() {
 	riskAnalysisHandler.Register(app)
 	claimHandler.Register(app)
 	claimRejectionHandler.Register(app)
+	claimDisputeHandler.Register(app)
 	dashboardHandler.Register(app)
+	analyticsHandler.Register(app)
+	invoiceHandler.Register(app)
 	payoutHandler.Register(app)
 	cancelRequestHandler.Register(app)
 	dataBillHandler.Register(app)
+	workerHandler.Register(app)
+	auditHandler.Register(app)
+	policyAttachmentHandler.Register(app)
+	policyDocumentUploadHandler.Register(app)
+	documentScanHandler.Register(app)
+	aiUsageHandler.Register(app)
 
 	// Register payment consumer health check endpoint
 	app.Get("/health/payment-consumer", paymentConsumerHealthHandler)
 
-	shutdownChan := make(chan os.Signal, 1)
-	doneChan := make(chan bool, 1)
-
-	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
-
 	go func() {
 		log.Printf("Starting server on port %s", cfg.Port)
 		if err := app.Listen(fmt.Sprintf("0.0.0.0:%s", cfg.Port)); err != nil {
-			log.Fatalf("Error starting server: %v", err)
+			coordinator.Fail(fmt.Errorf("error starting server: %w", err))
 		}
-		doneChan <- true
 	}()
+	coordinator.Server("http", app.ShutdownWithContext)
+
+	// Jobs in progress finish before the clients they use are closed
+	coordinator.Worker("worker-manager", func(ctx context.Context) error {
+		workerManager.Shutdown(ctx)
+		return nil
+	})
 
-	<-shutdownChan
-	log.Println("Shutting down server...")
-	workerManager.Shutdown()
+	coordinator.Closer("postgres", func() error {
+		if db == nil {
+			return nil
+		}
+		return db.Close()
+	})
+	if redisClient != nil {
+		coordinator.Closer("redis", redisClient.Close)
+	}
+	coordinator.Closer("rabbitmq", rabbitConn.Close)
+	if minioClient != nil {
+		coordinator.Closer("minio", minioClient.Close)
+	}
+
+	if err := coordinator.Wait(); err != nil {
+		log.Printf("policy-service stopped: %v", err)
+		logFile.Close()
+		os.Exit(1)
+	}
 }
@@ -1,6 +1,7 @@
 package gemini
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -73,9 +74,16 @@ func (s *GeminiClientSelector) TryAllClients(operation func(*GeminiClient, int)
 		lastErr = err
 		errorsCollected = append(errorsCollected, fmt.Sprintf("client[%d]: %v", clientIdx, err))
 
+		// Another key gets the same answer for blocked content or a caller that gave up
+		var aiErr *Error
+		if errors.As(err, &aiErr) && (aiErr.Kind == ErrorBlocked || aiErr.Kind == ErrorCanceled) {
+			return fmt.Errorf("gemini request failed on client %d: %w", clientIdx, err)
+		}
+
 		slog.Warn("Gemini API request failed, trying next client",
 			"client_index", clientIdx,
 			"attempt", attempt+1,
+			"circuit", client.breaker.state(),
 			"error", err)
 	}
 
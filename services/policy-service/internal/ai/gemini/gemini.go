@@ -19,9 +19,17 @@ type GeminiClient struct {
 	Client     *genai.Client
 	FlashModel *genai.GenerativeModel
 	ProModel   *genai.GenerativeModel
+
+	// Names of the models above, genai does not expose them
+	flashModelName string
+	proModelName   string
+
+	resilience Resilience
+	// Shared by the copies of the client, one circuit per API key
+	breaker *circuitBreaker
 }
 
-func NewGenAIClient(apiKey, flashModelName, proModelName string) (*GeminiClient, error) {
+func NewGenAIClient(apiKey, flashModelName, proModelName string, resilience Resilience) (*GeminiClient, error) {
 	ctx := context.Background()
 
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
@@ -33,58 +41,29 @@ func NewGenAIClient(apiKey, flashModelName, proModelName string) (*GeminiClient,
 		Client:     client,
 		FlashModel: client.GenerativeModel(flashModelName),
 		ProModel:   client.GenerativeModel(proModelName),
+
+		flashModelName: flashModelName,
+		proModelName:   proModelName,
+
+		resilience: resilience,
+		breaker:    newCircuitBreaker(resilience.BreakerFailures, resilience.BreakerCooldown),
 	}, nil
 }
 
 func (g *GeminiClient) SendAIWithPDF(ctx context.Context, prompt string, data map[string]any) (map[string]any, error) {
-	fileData := data["pdf"].([]byte)
-
-	var resp *genai.GenerateContentResponse
-	var err error
-
-	resp, err = g.ProModel.GenerateContent(ctx, genai.Text(prompt),
-		genai.Blob{
-			MIMEType: "application/pdf",
-			Data:     fileData,
-		},
-	)
-	if err != nil {
-		if strings.Contains(err.Error(), "Error 429") {
-			resp, err = g.FlashModel.GenerateContent(ctx, genai.Text(prompt),
-				genai.Blob{
-					MIMEType: "application/pdf",
-					Data:     fileData,
-				},
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate content with images: %w", err)
-			}
-		}
-		return nil, fmt.Errorf("failed to generate content with images: %w", err)
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
-	}
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.New("no content returned from AI")
-	}
-	textPart, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	fileData, ok := data["pdf"].([]byte)
 	if !ok {
-		return nil, fmt.Errorf("response part is not text, received %T", resp.Candidates[0].Content.Parts[0])
+		return nil, &Error{Kind: ErrorRejected, Err: errors.New("pdf data is missing")}
 	}
-	aiResponse := string(textPart)
-	if strings.HasPrefix(aiResponse, "```json") {
-		aiResponse = strings.TrimPrefix(aiResponse, "```json\n")
-		aiResponse = strings.TrimSuffix(aiResponse, "\n```")
-	}
-	aiResponse = strings.TrimSpace(aiResponse)
-	var resultMap map[string]any
-	err = json.Unmarshal([]byte(aiResponse), &resultMap)
+
+	resp, err := g.generate(ctx, genai.Text(prompt), genai.Blob{
+		MIMEType: "application/pdf",
+		Data:     fileData,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal AI response to JSON: %w. \nRaw response was: %s", err, aiResponse)
+		return nil, fmt.Errorf("failed to generate content with PDF: %w", err)
 	}
-	return resultMap, nil
+	return parseJSONResponse(resp)
 }
 
 // SendAIWithPDFAndRetry attempts the request with automatic failover across multiple clients
@@ -135,27 +114,79 @@ func (g *GeminiClient) SendAIWithImages(ctx context.Context, prompt string, imag
 		"prompt_length", len(prompt),
 		"image_count", len(parts)-1) // -1 for the text prompt
 
-	var resp *genai.GenerateContentResponse
-	var err error
-
-	resp, err = g.ProModel.GenerateContent(ctx, parts...)
+	resp, err := g.generate(ctx, parts...)
 	if err != nil {
-		if strings.Contains(err.Error(), "Error 429") {
-			resp, err = g.FlashModel.GenerateContent(ctx, parts...)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate content with images: %w", err)
-			}
-		}
 		return nil, fmt.Errorf("failed to generate content with images: %w", err)
 	}
+	return parseJSONResponse(resp)
+}
+
+// generate calls the Pro model and falls back to Flash, which has its own quota, when Pro is
+// rate limited. Transient failures are retried with backoff; after repeated ones the breaker
+// of the API key fails calls fast until its cooldown is over.
+func (g *GeminiClient) generate(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+	if !g.breaker.allow() {
+		return nil, &Error{Kind: ErrorCircuitOpen, Err: errors.New("too many recent failures with this API key")}
+	}
+
+	model, modelName := g.ProModel, g.proModelName
+	retries := 0
+	for {
+		resp, err := g.callModel(ctx, model, modelName, parts)
+		if err == nil {
+			g.breaker.record(nil)
+			return resp, nil
+		}
+
+		aiErr := classifyError(ctx, err)
+		if aiErr.Kind == ErrorRateLimited && model == g.ProModel && g.FlashModel != nil {
+			slog.Warn("Gemini Pro model rate limited, falling back to Flash", "error", err)
+			model, modelName = g.FlashModel, g.flashModelName
+			continue
+		}
+		if !aiErr.transient() || retries >= g.resilience.MaxRetries {
+			g.breaker.record(aiErr)
+			return nil, aiErr
+		}
+
+		retries++
+		delay := retryDelay(retries)
+		slog.Warn("Gemini call failed, retrying",
+			"model", modelName,
+			"error_kind", aiErr.Kind,
+			"retry", retries,
+			"max_retries", g.resilience.MaxRetries,
+			"delay", delay,
+			"error", err)
+		if err := sleepContext(ctx, delay); err != nil {
+			g.breaker.record(aiErr)
+			return nil, classifyError(ctx, err)
+		}
+	}
+}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.New("no content returned from AI")
+func (g *GeminiClient) callModel(ctx context.Context, model *genai.GenerativeModel, modelName string, parts []genai.Part) (*genai.GenerateContentResponse, error) {
+	callCtx := ctx
+	if g.resilience.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, g.resilience.Timeout)
+		defer cancel()
+	}
+	resp, err := model.GenerateContent(callCtx, parts...)
+	// genai returns no response, so no usage, for a failed or blocked call
+	meterUsage(ctx, modelName, resp)
+	return resp, err
+}
+
+// parseJSONResponse reads the JSON object the prompts ask for, with or without a markdown fence
+func parseJSONResponse(resp *genai.GenerateContentResponse) (map[string]any, error) {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, &Error{Kind: ErrorInvalidResponse, Err: errors.New("no content returned from AI")}
 	}
 
 	textPart, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
 	if !ok {
-		return nil, fmt.Errorf("response part is not text, received %T", resp.Candidates[0].Content.Parts[0])
+		return nil, &Error{Kind: ErrorInvalidResponse, Err: fmt.Errorf("response part is not text, received %T", resp.Candidates[0].Content.Parts[0])}
 	}
 
 	aiResponse := string(textPart)
@@ -168,11 +199,9 @@ func (g *GeminiClient) SendAIWithImages(ctx context.Context, prompt string, imag
 	aiResponse = strings.TrimSpace(aiResponse)
 
 	var resultMap map[string]any
-	err = json.Unmarshal([]byte(aiResponse), &resultMap)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal AI response to JSON: %w. \nRaw response was: %s", err, aiResponse)
+	if err := json.Unmarshal([]byte(aiResponse), &resultMap); err != nil {
+		return nil, &Error{Kind: ErrorInvalidResponse, Err: fmt.Errorf("failed to unmarshal AI response to JSON: %w", err), Response: aiResponse}
 	}
-
 	return resultMap, nil
 }
 
@@ -553,22 +553,48 @@ Your goal: Ensure the system operates exactly as the PDF contract states. Any de
 BEGIN YOUR JSON OUTPUT NOW (start with opening brace):
 `
 
+// ValidationRepairPromptTemplate is appended to the validation prompt when the previous answer
+// did not match the OUTPUT SCHEMA. Arguments: the problems found, the previous answer.
+const ValidationRepairPromptTemplate = `
+
+---
+
+## CORRECTION REQUIRED
+
+Your previous answer to this request could not be used. It was rejected for the following reasons:
+
+%s
+
+Previous answer:
+
+%s
+
+Answer again following the OUTPUT SCHEMA exactly:
+- Return ONLY one JSON object, no markdown fence, no text before or after it
+- Keep every required field: validation_status, total_checks, passed_checks, failed_checks, warning_count, mismatches, warnings, recommendations
+- Counts are non-negative integers, validation_status is one of 'passed_ai' | 'failed' | 'warning'
+- Fix only what was rejected; the comparison with the PDF itself stays the same
+
+BEGIN YOUR JSON OUTPUT NOW (start with opening brace):
+`
+
 // BuildRiskAnalysisPrompt constructs the comprehensive AI prompt for risk analysis
 func BuildRiskAnalysisPrompt(
 	farm models.Farm,
 	farmPhotos []models.FarmPhoto, // Will include base64 image data
 	farmPhotosData []string,
-	monitoringData []models.FarmMonitoringData,
+	monitoring models.MonitoringDataSummary,
 	trigger models.BasePolicyTrigger,
 	conditions []models.BasePolicyTriggerCondition,
 	dataSources map[string]models.DataSource, // keyed by data_source_id
 	policy models.RegisteredPolicy,
+	climatology string, // recent weather against climatological normals, as JSON; empty when unavailable
 ) string {
 	// Format farm photos with base64 data
 	farmPhotosJSON := formatFarmPhotosWithImages(farmPhotos, farmPhotosData)
 
-	// Format monitoring data grouped by parameter
-	monitoringDataJSON := formatMonitoringDataGrouped(monitoringData)
+	// Format the per-parameter summary of the monitoring data
+	monitoringDataJSON := formatMonitoringSummary(monitoring)
 
 	// Format trigger conditions with data source details
 	conditionsJSON := formatConditionsWithDataSources(conditions, dataSources)
@@ -577,11 +603,15 @@ func BuildRiskAnalysisPrompt(
 	dataSourcesJSON := formatDataSources(dataSources)
 
 	// Extract unique parameters being monitored
-	parametersMonitored := extractUniqueParameters(monitoringData)
+	parametersMonitored := extractUniqueParameters(monitoring)
 
 	// Current timestamp
 	currentTimestamp := time.Now().Unix()
 
+	if climatology == "" {
+		climatology = "Not available."
+	}
+
 	prompt := fmt.Sprintf(`# Agricultural Insurance Risk Analysis Task - Multi-Parameter Analysis
 
 ## Context
@@ -644,20 +674,18 @@ Analyze the provided multi-parameter data and generate a comprehensive risk asse
 
 **Parameters Being Monitored:** %s
 
-**Time-Series Dataset (Grouped by Parameter):**
+**Time-Series Summary (Grouped by Parameter):**
 %s
 
 **Data Structure Explanation:**
-Each measurement contains:
-- Parameter Name: The specific metric being measured
-- Measured Value: The quantitative reading
-- Unit: Measurement unit
-- Measurement Timestamp: Unix timestamp of measurement
-- Data Quality: "good", "acceptable", or "poor"
-- Confidence Score: 0.0 to 1.0 reliability metric
-- Component Data: Additional details specific to the parameter
-- Cloud Cover Percentage: Relevant for satellite measurements
-- Distance From Farm: Meters from measurement point (for weather stations)
+The measurements are summarized rather than listed one by one. For each parameter:
+- count, first_timestamp, last_timestamp: Number of measurements and the period they cover (Unix timestamps)
+- min, max, mean, median, std_dev: Statistics over every measurement
+- quality_counts: Measurements per data quality ("good", "acceptable", "poor")
+- avg_confidence, avg_cloud_cover_percentage, avg_distance_from_farm_meters: Averages where reported
+- series: The measurements downsampled to buckets of bucket_seconds, each with its period_start, count, mean, min and max
+- anomalies: Individual measurements at least 3 standard deviations from the mean, with their z_score
+raw_data_object names where the full series is archived; it is not part of this prompt.
 
 **Analysis Instructions:**
 For EACH parameter type, analyze:
@@ -669,6 +697,11 @@ For EACH parameter type, analyze:
 - Cross-parameter correlations
 - Alignment with crop growth stages
 
+**Climatological Context (Recent Weather vs. Long-Term Normals):**
+%s
+
+Rainfall is the total over the covered days with its percentage of the normal total; temperature is the mean with its anomaly from the normal in °C. Use it to judge whether recent conditions are unusual for the location rather than typical of the season.
+
 ### 4. Data Source Metadata
 
 **Data Sources Configuration:**
@@ -770,7 +803,7 @@ Evaluate and score (0-100) the following factors:
 
 **Key Analysis Tasks:**
 1. **Vegetation Health Analysis** (if NDVI, NDMI, EVI data present)
-   - Calculate mean, median, std dev across measurement period
+   - Compare the mean, median and std dev of the measurement period with crop benchmarks
    - Identify growth curve patterns and compare to normal crop phenology
    - Detect stress periods (NDVI < 0.4, NDMI < 0.2)
    - Analyze recent trends (last 30, 60, 90 days)
@@ -823,8 +856,8 @@ Evaluate and score (0-100) the following factors:
    - Consecutive requirement
 
 2. **Historical Simulation:**
-   - Apply aggregation function over specified window
-   - Scan through entire monitoring dataset
+   - Apply aggregation function over specified window, using the bucketed series and the anomalies
+   - Scan through the series of every parameter involved
    - Count how many times threshold would have been breached
    - Record specific dates and values at breach
    - Calculate margin to threshold (average distance)
@@ -1067,98 +1100,192 @@ Higher score = higher risk (concerning)
 		// Farm Photos (25)
 		farmPhotosJSON, // 25
 
-		// Monitoring Data (26-27)
+		// Monitoring Data (26-28)
 		strings.Join(parametersMonitored, ", "), // 26
 		monitoringDataJSON,                      // 27
-
-		// Data Sources (28)
-		dataSourcesJSON, // 28
-
-		// Trigger Configuration (29-35)
-		trigger.ID,                             // 29
-		trigger.LogicalOperator,                // 30
-		stringPtrOrEmpty(trigger.GrowthStage),  // 31
-		trigger.MonitorInterval,                // 32
-		trigger.MonitorFrequencyUnit,           // 33
-		formatJSONMap(trigger.BlackoutPeriods), // 34
-		len(conditions),                        // 35
-
-		// Conditions Details (36)
-		conditionsJSON, // 36
-
-		// Registered Policy (37-57)
-		policy.ID,                            // 37
-		policy.PolicyNumber,                  // 38
-		policy.BasePolicyID,                  // 39
-		policy.InsuranceProviderID,           // 40
-		policy.FarmerID,                      // 41
-		policy.CoverageAmount,                // 42
-		policy.CoverageStartDate,             // 43
-		policy.CoverageEndDate,               // 44
-		policy.PlantingDate,                  // 45
-		policy.AreaMultiplier,                // 46
-		policy.TotalFarmerPremium,            // 47
-		policy.PremiumPaidByFarmer,           // 48
-		int64PtrOrZero(policy.PremiumPaidAt), // 49
-		policy.DataComplexityScore,           // 50
-		policy.MonthlyDataCost,               // 51
-		policy.TotalDataCost,                 // 52
-		policy.Status,                        // 53
-		policy.UnderwritingStatus,            // 54
-		currentTimestamp,                     // 55
-
-		// Geographic context repeated for analysis sections (56-58)
-		stringPtrOrEmpty(farm.Province),               // 56
-		stringPtrOrEmpty(farm.District),               // 57
-		farm.HasIrrigation,                            // 58
-		stringPtrOrEmpty(farm.IrrigationType),         // 59
-		stringPtrOrEmpty(farm.SoilType),               // 60
-		farm.CropType,                                 // 61
-		stringPtrOrEmpty(farm.Province),               // 62
-		stringPtrOrEmpty(farm.District),               // 63
-		int64PtrOrZero(farm.PlantingDate),             // 64
+		climatology,                             // 28
+
+		// Data Sources (29)
+		dataSourcesJSON, // 29
+
+		// Trigger Configuration (30-36)
+		trigger.ID,                             // 30
+		trigger.LogicalOperator,                // 31
+		stringPtrOrEmpty(trigger.GrowthStage),  // 32
+		trigger.MonitorInterval,                // 33
+		trigger.MonitorFrequencyUnit,           // 34
+		formatJSONMap(trigger.BlackoutPeriods), // 35
+		len(conditions),                        // 36
+
+		// Conditions Details (37)
+		conditionsJSON, // 37
+
+		// Registered Policy (38-58)
+		policy.ID,                            // 38
+		policy.PolicyNumber,                  // 39
+		policy.BasePolicyID,                  // 40
+		policy.InsuranceProviderID,           // 41
+		policy.FarmerID,                      // 42
+		policy.CoverageAmount,                // 43
+		policy.CoverageStartDate,             // 44
+		policy.CoverageEndDate,               // 45
+		policy.PlantingDate,                  // 46
+		policy.AreaMultiplier,                // 47
+		policy.TotalFarmerPremium,            // 48
+		policy.PremiumPaidByFarmer,           // 49
+		int64PtrOrZero(policy.PremiumPaidAt), // 50
+		policy.DataComplexityScore,           // 51
+		policy.MonthlyDataCost,               // 52
+		policy.TotalDataCost,                 // 53
+		policy.Status,                        // 54
+		policy.UnderwritingStatus,            // 55
+		currentTimestamp,                     // 56
+
+		// Geographic context repeated for analysis sections (57-59)
+		stringPtrOrEmpty(farm.Province),               // 57
+		stringPtrOrEmpty(farm.District),               // 58
+		farm.HasIrrigation,                            // 59
+		stringPtrOrEmpty(farm.IrrigationType),         // 60
+		stringPtrOrEmpty(farm.SoilType),               // 61
+		farm.CropType,                                 // 62
+		stringPtrOrEmpty(farm.Province),               // 63
+		stringPtrOrEmpty(farm.District),               // 64
 		int64PtrOrZero(farm.PlantingDate),             // 65
-		int64PtrOrZero(farm.ExpectedHarvestDate),      // 66
-		policy.CoverageStartDate,                      // 67
-		policy.CoverageEndDate,                        // 68
-		farm.CropType,                                 // 69
-		float64PtrOrZero(farm.CropTypeConfidence)*100, // 70
-		farm.CropTypeVerified,                         // 71
-
-		// Trigger analysis context (72-73)
-		len(conditions),         // 72
-		trigger.LogicalOperator, // 73
-
-		// Fraud detection context (74-79)
-		policy.CoverageStartDate,                      // 74
+		int64PtrOrZero(farm.PlantingDate),             // 66
+		int64PtrOrZero(farm.ExpectedHarvestDate),      // 67
+		policy.CoverageStartDate,                      // 68
+		policy.CoverageEndDate,                        // 69
+		farm.CropType,                                 // 70
+		float64PtrOrZero(farm.CropTypeConfidence)*100, // 71
+		farm.CropTypeVerified,                         // 72
+
+		// Trigger analysis context (73-74)
+		len(conditions),         // 73
+		trigger.LogicalOperator, // 74
+
+		// Fraud detection context (75-80)
 		policy.CoverageStartDate,                      // 75
-		farm.LandOwnershipVerified,                    // 76
-		float64PtrOrZero(farm.CropTypeConfidence)*100, // 77
-		policy.CoverageAmount,                         // 78
-
-		// Vietnam context (79-84)
-		stringPtrOrEmpty(farm.Province),   // 79
-		stringPtrOrEmpty(farm.District),   // 80
-		stringPtrOrEmpty(farm.Commune),    // 81
-		int64PtrOrZero(farm.PlantingDate), // 82
-		policy.CoverageStartDate,          // 83
-		policy.CoverageEndDate,            // 84
-		farm.CropType,                     // 85
-
-		// Final context summary (86-91)
-		stringPtrOrEmpty(farm.FarmName),         // 86
-		farm.ID,                                 // 87
-		policy.PolicyNumber,                     // 88
-		policy.ID,                               // 89
-		strings.Join(parametersMonitored, ", "), // 90
-		len(conditions),                         // 91
-		len(monitoringData),                     // 92
-		currentTimestamp,                        // 93
+		policy.CoverageStartDate,                      // 76
+		farm.LandOwnershipVerified,                    // 77
+		float64PtrOrZero(farm.CropTypeConfidence)*100, // 78
+		policy.CoverageAmount,                         // 79
+
+		// Vietnam context (80-85)
+		stringPtrOrEmpty(farm.Province),   // 80
+		stringPtrOrEmpty(farm.District),   // 81
+		stringPtrOrEmpty(farm.Commune),    // 82
+		int64PtrOrZero(farm.PlantingDate), // 83
+		policy.CoverageStartDate,          // 84
+		policy.CoverageEndDate,            // 85
+		farm.CropType,                     // 86
+
+		// Final context summary (87-92)
+		stringPtrOrEmpty(farm.FarmName),         // 87
+		farm.ID,                                 // 88
+		policy.PolicyNumber,                     // 89
+		policy.ID,                               // 90
+		strings.Join(parametersMonitored, ", "), // 91
+		len(conditions),                         // 92
+		monitoring.TotalMeasurements,            // 93
+		currentTimestamp,                        // 94
 	)
 
 	return prompt
 }
 
+// BuildCropClassificationPrompt asks which crop grows on a farm, from its photos attached to the
+// request in the order of photos
+func BuildCropClassificationPrompt(farm models.Farm, photos []models.FarmPhoto) string {
+	var photoList strings.Builder
+	for i, photo := range photos {
+		takenAt := "unknown"
+		if photo.TakenAt != nil {
+			takenAt = time.Unix(*photo.TakenAt, 0).UTC().Format("2006-01-02")
+		}
+		fmt.Fprintf(&photoList, "%d. %s photo, taken %s\n", i+1, photo.PhotoType, takenAt)
+	}
+
+	plantingDate := "unknown"
+	if farm.PlantingDate != nil {
+		plantingDate = time.Unix(*farm.PlantingDate, 0).UTC().Format("2006-01-02")
+	}
+
+	return fmt.Sprintf(`# Crop Classification Task
+
+## Context
+You are verifying the crop declared for a farm insured under parametric crop insurance in Vietnam. The images attached to this request are photos of the farm: satellite images of its boundary and photos taken on the ground.
+
+## Farm
+- Province: %s
+- District: %s
+- Area (ha): %.2f
+- Declared crop type: %s
+- Declared planting date: %s
+- Irrigated: %t
+
+## Attached Photos (in order)
+%s
+## Task
+Identify the crop actually growing on the farm from the photos. Judge from field geometry, canopy texture and color, flooding or irrigation patterns and the growth stage against the planting date. Do not assume the declared crop is correct; the classification is used to detect misdeclared or changed crops.
+
+## Output Rules
+1. Output ONLY one JSON object - no markdown, no explanations, no preamble
+2. crop_type is the crop you see, lowercase English, using the declared crop type's name when it is the same crop (e.g. "rice", "coffee", "corn"); use "bare_soil" for fallow or freshly plowed land and "unknown" when the photos do not show the crop
+3. confidence is your confidence in crop_type, a number between 0 and 1
+4. reasoning is one or two sentences naming what in the photos supports the classification
+
+## Output Schema
+{
+  "crop_type": "string",
+  "confidence": 0.0,
+  "reasoning": "string"
+}
+
+BEGIN YOUR JSON OUTPUT NOW (start with opening brace):
+`,
+		stringPtrOrEmpty(farm.Province),
+		stringPtrOrEmpty(farm.District),
+		farm.AreaSqm/10000,
+		farm.CropType,
+		plantingDate,
+		farm.HasIrrigation,
+		photoList.String(),
+	)
+}
+
+// LandCertificateOCRPrompt asks for the fields of a Vietnamese land use right certificate, from the
+// photos of its pages attached to the request
+const LandCertificateOCRPrompt = `# Land Certificate Reading Task
+
+## Context
+The images attached to this request are photos of the pages of one Vietnamese land use right certificate (Giấy chứng nhận quyền sử dụng đất, "sổ đỏ" or "sổ hồng"), uploaded by a farmer insuring the land. The owner read from it is checked against the farmer's verified national ID.
+
+## Task
+Read the certificate and extract:
+1. certificate_number: the serial number of the certificate as printed (e.g. "CS 123456" or "BĐ 987654"), without the "Số vào sổ cấp GCN" registry number
+2. owner_names: the full name of every land user listed in section I ("Người sử dụng đất"), with Vietnamese diacritics, without titles such as "Ông" or "Bà"
+3. parcel_address: the address of the land parcel in section II ("Địa chỉ")
+
+## Output Rules
+1. Output ONLY one JSON object - no markdown, no explanations, no preamble
+2. readable is false when the images are not a land use right certificate or the owner cannot be read; leave the other fields empty then
+3. Copy names and numbers exactly as printed; do not guess characters that cannot be read, leave the field empty instead
+4. confidence is your confidence in the extracted owner names and certificate number, a number between 0 and 1
+5. reasoning is one sentence on the quality of the images and anything that could not be read
+
+## Output Schema
+{
+  "readable": true,
+  "certificate_number": "string",
+  "owner_names": ["string"],
+  "parcel_address": "string",
+  "confidence": 0.0,
+  "reasoning": "string"
+}
+
+BEGIN YOUR JSON OUTPUT NOW (start with opening brace):
+`
+
 // Helper functions
 
 func formatFarmPhotosWithImages(photos []models.FarmPhoto, imageData []string) string {
@@ -1206,68 +1333,15 @@ func formatFarmPhotosWithImages(photos []models.FarmPhoto, imageData []string) s
 	return builder.String()
 }
 
-func formatMonitoringDataGrouped(data []models.FarmMonitoringData) string {
-	if len(data) == 0 {
+func formatMonitoringSummary(summary models.MonitoringDataSummary) string {
+	if summary.TotalMeasurements == 0 {
 		return "No monitoring data available."
 	}
-
-	// Group by parameter name
-	grouped := make(map[models.DataSourceParameterName][]models.FarmMonitoringData)
-	for _, d := range data {
-		grouped[d.ParameterName] = append(grouped[d.ParameterName], d)
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "Monitoring data summary unavailable."
 	}
-
-	var builder strings.Builder
-	builder.WriteString("{\n")
-
-	paramCount := 0
-	totalParams := len(grouped)
-
-	for paramName, measurements := range grouped {
-		builder.WriteString(fmt.Sprintf(`  "%s": {
-    "total_measurements": %d,
-    "unit": "%s",
-    "measurements": [
-`,
-			paramName,
-			len(measurements),
-			stringPtrOrEmpty(measurements[0].Unit),
-		))
-
-		for i, m := range measurements {
-			measurementJSON := fmt.Sprintf(`      {
-        "measured_value": %.4f,
-        "measurement_timestamp": %d,
-        "data_quality": "%s",
-        "confidence_score": %.4f,
-        "component_data": %s,
-        "cloud_cover_percentage": %.2f,
-        "distance_from_farm_meters": %.2f
-      }`,
-				m.MeasuredValue,
-				m.MeasurementTimestamp,
-				m.DataQuality,
-				float64PtrOrZero(m.ConfidenceScore),
-				formatJSONMap(m.ComponentData),
-				float64PtrOrZero(m.CloudCoverPercentage),
-				float64PtrOrZero(m.DistanceFromFarmMeters),
-			)
-
-			builder.WriteString(measurementJSON)
-			if i < len(measurements)-1 {
-				builder.WriteString(",\n")
-			}
-		}
-
-		builder.WriteString("\n    ]\n  }")
-		paramCount++
-		if paramCount < totalParams {
-			builder.WriteString(",\n")
-		}
-	}
-
-	builder.WriteString("\n}")
-	return builder.String()
+	return string(b)
 }
 
 func formatConditionsWithDataSources(
@@ -1392,15 +1466,10 @@ func formatDataSources(dataSources map[string]models.DataSource) string {
 	return builder.String()
 }
 
-func extractUniqueParameters(data []models.FarmMonitoringData) []string {
-	paramMap := make(map[string]bool)
-	for _, d := range data {
-		paramMap[string(d.ParameterName)] = true
-	}
-
-	params := make([]string, 0, len(paramMap))
-	for param := range paramMap {
-		params = append(params, param)
+func extractUniqueParameters(summary models.MonitoringDataSummary) []string {
+	params := make([]string, 0, len(summary.Parameters))
+	for _, p := range summary.Parameters {
+		params = append(params, string(p.ParameterName))
 	}
 	return params
 }
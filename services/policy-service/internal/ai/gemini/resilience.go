@@ -0,0 +1,244 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+)
+
+// Resilience configures how a client handles a failing Gemini API
+type Resilience struct {
+	// Limit of a single generate call, 0 leaves it to the caller's context
+	Timeout time.Duration
+	// Retries of a call failing with a rate limit, a 5xx or a timeout
+	MaxRetries int
+	// Consecutive transient failures that open the circuit, 0 disables the breaker
+	BreakerFailures int
+	// How long an open circuit fails fast before letting a trial call through
+	BreakerCooldown time.Duration
+}
+
+const (
+	retryBaseDelay = 2 * time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// ErrorKind is the category of a failed Gemini call. It is recorded with the status of the job
+// that made the call.
+type ErrorKind string
+
+const (
+	ErrorRateLimited     ErrorKind = "ai_rate_limited"     // 429, quota of the key used up
+	ErrorUnavailable     ErrorKind = "ai_unavailable"      // 5xx
+	ErrorTimeout         ErrorKind = "ai_timeout"          // No answer within Resilience.Timeout
+	ErrorCircuitOpen     ErrorKind = "ai_circuit_open"     // Not sent, the key failed too often lately
+	ErrorRejected        ErrorKind = "ai_request_rejected" // Other 4xx: invalid key, request too large...
+	ErrorBlocked         ErrorKind = "ai_blocked"          // Refused by the safety filters
+	ErrorInvalidResponse ErrorKind = "ai_invalid_response" // Answer without the expected JSON
+	ErrorCanceled        ErrorKind = "ai_canceled"
+	ErrorUnknown         ErrorKind = "ai_unknown"
+)
+
+// Error is a classified Gemini failure
+type Error struct {
+	Kind       ErrorKind
+	StatusCode int // HTTP status of the API response, when there was one
+	Err        error
+	// Text the model answered, kept for ErrorInvalidResponse so callers can inspect or store it
+	Response string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("gemini %s: %v", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKind lets the worker record the category without knowing this package
+func (e *Error) ErrorKind() string {
+	return string(e.Kind)
+}
+
+// Retryable reports whether the same request may succeed later. A blocked or rejected request
+// fails the same way every time.
+func (e *Error) Retryable() bool {
+	switch e.Kind {
+	case ErrorBlocked, ErrorRejected, ErrorCanceled:
+		return false
+	default:
+		return true
+	}
+}
+
+// transient reports whether the API itself is struggling; only these failures count towards
+// opening the circuit and are retried right away
+func (e *Error) transient() bool {
+	switch e.Kind {
+	case ErrorRateLimited, ErrorUnavailable, ErrorTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// classifyError turns an error of the genai client into an *Error. ctx is the caller's context,
+// to tell a call timing out from a caller giving up.
+func classifyError(ctx context.Context, err error) *Error {
+	var aiErr *Error
+	if errors.As(err, &aiErr) {
+		return aiErr
+	}
+
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		return &Error{Kind: ErrorBlocked, Err: err}
+	}
+
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return &Error{Kind: ErrorCanceled, Err: err}
+		}
+		return &Error{Kind: ErrorTimeout, Err: err}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: ErrorTimeout, Err: err}
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return &Error{Kind: kindForStatus(apiErr.Code), StatusCode: apiErr.Code, Err: err}
+	}
+
+	// Errors the REST transport did not wrap in a googleapi.Error
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "Error 429"), strings.Contains(message, "RESOURCE_EXHAUSTED"):
+		return &Error{Kind: ErrorRateLimited, StatusCode: http.StatusTooManyRequests, Err: err}
+	case strings.Contains(message, "UNAVAILABLE"), strings.Contains(message, "INTERNAL"):
+		return &Error{Kind: ErrorUnavailable, Err: err}
+	case strings.Contains(message, "DEADLINE_EXCEEDED"):
+		return &Error{Kind: ErrorTimeout, Err: err}
+	}
+	return &Error{Kind: ErrorUnknown, Err: err}
+}
+
+func kindForStatus(code int) ErrorKind {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+		return ErrorTimeout
+	case code >= 500:
+		return ErrorUnavailable
+	case code >= 400:
+		return ErrorRejected
+	default:
+		return ErrorUnknown
+	}
+}
+
+// retryDelay returns the wait before retry n, counted from 1: doubling from retryBaseDelay up to
+// retryMaxDelay, with jitter so keys failing together do not retry together
+func retryDelay(retry int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < retry && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, retryMaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// sleepContext waits for d unless ctx ends first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// circuitBreaker stops calls with an API key after repeated transient failures. Once the
+// cooldown is over a single trial call is let through: success closes the circuit, failure
+// opens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may be made
+func (b *circuitBreaker) allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record updates the circuit with the outcome of a call, nil being a success. A failure that is
+// not transient still shows the API answering, so it closes the circuit like a success.
+func (b *circuitBreaker) record(err *Error) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil || !err.transient() {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// state describes the circuit for logs
+func (b *circuitBreaker) state() string {
+	if b == nil || b.threshold <= 0 {
+		return "disabled"
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.failures < b.threshold:
+		return "closed"
+	case b.now().Before(b.openUntil):
+		return "open"
+	default:
+		return "half_open"
+	}
+}
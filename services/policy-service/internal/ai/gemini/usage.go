@@ -0,0 +1,73 @@
+package gemini
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// Usage is the token count of the calls made to one model
+type Usage struct {
+	Model        string
+	Calls        int
+	PromptTokens int64
+	// Billed output: the answer plus the thinking tokens of 2.5 models
+	ResponseTokens int64
+	TotalTokens    int64
+}
+
+// UsageMeter sums the tokens of every call made with a context returned by WithUsageMeter,
+// retries and fallbacks included, so a job can account for what it cost
+type UsageMeter struct {
+	mu      sync.Mutex
+	byModel map[string]*Usage
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter returns a context whose Gemini calls are counted by the returned meter
+func WithUsageMeter(ctx context.Context) (context.Context, *UsageMeter) {
+	meter := &UsageMeter{byModel: make(map[string]*Usage)}
+	return context.WithValue(ctx, usageMeterKey{}, meter), meter
+}
+
+// Usage returns the usage per model, sorted by model name
+func (m *UsageMeter) Usage() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := make([]Usage, 0, len(m.byModel))
+	for _, u := range m.byModel {
+		usage = append(usage, *u)
+	}
+	slices.SortFunc(usage, func(a, b Usage) int { return strings.Compare(a.Model, b.Model) })
+	return usage
+}
+
+func (m *UsageMeter) add(model string, metadata *genai.UsageMetadata) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.byModel[model]
+	if !ok {
+		u = &Usage{Model: model}
+		m.byModel[model] = u
+	}
+	u.Calls++
+	u.PromptTokens += int64(metadata.PromptTokenCount)
+	// The total includes thinking tokens, which the candidate count leaves out
+	u.ResponseTokens += int64(max(metadata.TotalTokenCount-metadata.PromptTokenCount, metadata.CandidatesTokenCount))
+	u.TotalTokens += int64(metadata.TotalTokenCount)
+}
+
+// meterUsage counts the tokens of a response on the meter of ctx, if there is one
+func meterUsage(ctx context.Context, model string, resp *genai.GenerateContentResponse) {
+	meter, ok := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	if !ok || resp == nil || resp.UsageMetadata == nil {
+		return
+	}
+	meter.add(model, resp.UsageMetadata)
+}
@@ -0,0 +1,134 @@
+// Package clamav is a small client of the clamd daemon, run as a sidecar of the policy service.
+// Files are streamed with the INSTREAM command so clamd needs no access to MinIO.
+package clamav
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Chunks sent to clamd, well below its default StreamMaxLength
+const chunkSize = 64 * 1024
+
+// Result is the verdict of clamd on one stream
+type Result struct {
+	Infected  bool
+	Signature string // Name of the matched signature when infected
+}
+
+type Client struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClient returns a client of the clamd listening on address, e.g. clamav:3310
+func NewClient(address string, timeout time.Duration) *Client {
+	return &Client{address: address, timeout: timeout}
+}
+
+// Ping checks clamd answers
+func (c *Client) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "zPING\x00", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected clamd reply to PING: %q", reply)
+	}
+	return nil
+}
+
+// Scan streams r to clamd and returns its verdict
+func (c *Client) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	reply, err := c.command(ctx, "zINSTREAM\x00", r)
+	if err != nil {
+		return Result{}, err
+	}
+	return parseReply(reply)
+}
+
+func (c *Client) command(ctx context.Context, cmd string, stream io.Reader) (string, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd at %s: %w", c.address, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set clamd deadline: %w", err)
+	}
+
+	if _, err := io.WriteString(conn, cmd); err != nil {
+		return "", fmt.Errorf("failed to send clamd command: %w", err)
+	}
+	if stream != nil {
+		if err := writeChunks(conn, stream); err != nil {
+			return "", err
+		}
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return strings.TrimSpace(string(bytes.TrimRight(reply, "\x00"))), nil
+}
+
+// writeChunks sends the stream as length-prefixed chunks ended by a zero length chunk
+func writeChunks(w io.Writer, r io.Reader) error {
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, werr := w.Write(size); werr != nil {
+				return fmt.Errorf("failed to stream to clamd: %w", werr)
+			}
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return fmt.Errorf("failed to stream to clamd: %w", werr)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the scanned file: %w", err)
+		}
+	}
+
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := w.Write(size); err != nil {
+		return fmt.Errorf("failed to stream to clamd: %w", err)
+	}
+	return nil
+}
+
+// parseReply reads replies such as "stream: OK" and "stream: Eicar-Signature FOUND"
+func parseReply(reply string) (Result, error) {
+	verdict, ok := strings.CutPrefix(reply, "stream:")
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected clamd reply: %q", reply)
+	}
+	verdict = strings.TrimSpace(verdict)
+
+	switch {
+	case verdict == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return Result{}, fmt.Errorf("clamd failed to scan: %s", verdict)
+	}
+}
@@ -1,19 +1,35 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type PolicyServiceConfig struct {
 	Port                         string
 	APIKey                       string
+	JWTSecret                    string // shared with auth-service, which signs the tokens
 	PostgresCfg                  PostgresConfig
 	RabbitMQCfg                  RabbitMQConfig
 	RedisCfg                     RedisConfig
 	MinioCfg                     MinioConfig
 	GeminiAPICfg                 GeminiAPIConfig
+	RateLimitCfg                 RateLimitConfig
+	MonitoringRetentionCfg       MonitoringRetentionConfig
+	ClaimDisputeCfg              ClaimDisputeConfig
+	InvoicingCfg                 InvoicingConfig
 	VerifyNationalIDURL          string
 	VerifyLandCertificateHostAPI string
 	SatelliteDataServiceURL      string
 	WeatherDataServiceURL        string
+	AuthServiceURL               string
+	ProfileServiceURL            string
+	PaymentServiceURL            string
+	// clamd of the ClamAV sidecar; when empty uploads only get the built-in signature checks
+	ClamAVAddress string
 }
 
 type MinioConfig struct {
@@ -31,6 +47,10 @@ type PostgresConfig struct {
 	Password string
 	Host     string
 	Port     string
+	// Apply pending schema migrations when connecting, otherwise refuse a schema missing some
+	AutoMigrate bool
+	// Connect whatever the schema version, for cmd/migrate which manages the schema itself
+	SkipSchemaCheck bool
 }
 
 type RabbitMQConfig struct {
@@ -51,18 +71,78 @@ type GeminiAPIConfig struct {
 	APIKey    string
 	FlashName string
 	ProName   string
+	// Limit of a single generate call; PDF validation on the Pro model takes a while
+	RequestTimeout time.Duration
+	// Retries of a call failing with 429, 5xx or a timeout, on the same API key
+	MaxRetries int
+	// Consecutive transient failures that open the circuit of an API key, and how long it stays open
+	BreakerFailures int
+	BreakerCooldown time.Duration
+	// Prices in USD per million tokens, to estimate the cost of each job
+	ProInputPrice    float64
+	ProOutputPrice   float64
+	FlashInputPrice  float64
+	FlashOutputPrice float64
+	// Monthly AI spend per insurance provider, in USD, past which the provider is alerted; 0 disables alerts
+	MonthlyBudget float64
+}
+
+// RateLimit lets a user send Burst requests at once, the bucket refilling evenly over Period.
+// It is written as burst/period, e.g. 10/1h.
+type RateLimit struct {
+	Burst  int
+	Period time.Duration
+}
+
+type RateLimitConfig struct {
+	AIValidation RateLimit // policies sent to AI validation
+	OCR          RateLimit // farms created, whose land certificate photos are read
+	RiskAnalysis RateLimit // risk analyses run on demand
+	// Limits replacing the above for the users of an insurance provider, by provider then
+	// group, written as provider:group=burst/period entries separated by semicolons
+	ProviderOverrides map[string]map[string]RateLimit
+}
+
+// MonitoringRetentionConfig bounds how long farm monitoring readings stay in Postgres, where they
+// are kept in one partition per month
+type MonitoringRetentionConfig struct {
+	// Months of readings kept before the current one, older months are archived to MinIO and
+	// dropped; 0 keeps every month
+	RetentionMonths int
+	// Months past the current one whose partitions are created ahead of time
+	PrecreateMonths int
+}
+
+// ClaimDisputeConfig sets the deadlines of the disputes of rejected claims
+type ClaimDisputeConfig struct {
+	// Time after the rejection of a claim during which the farmer may dispute it
+	FilingWindow time.Duration
+	// Time the partner has to answer a dispute before it goes to arbitration
+	PartnerResponseSLA time.Duration
+	// Time platform admins have to arbitrate a dispute once it is escalated
+	ArbitrationSLA time.Duration
+}
+
+// InvoicingConfig sets the terms of the monthly data cost invoices of insurance partners
+type InvoicingConfig struct {
+	// VAT added to the subtotal of an invoice, in percent
+	TaxPercent float64
+	// Time after its issue a partner has to pay an invoice before it is overdue
+	PaymentTerm time.Duration
 }
 
 func New() *PolicyServiceConfig {
 	return &PolicyServiceConfig{
-		Port:   getEnvOrDefault("PORT", "8083"),
-		APIKey: getEnvOrDefault("API_KEY", ""),
+		Port:      getEnvOrDefault("PORT", "8083"),
+		APIKey:    getEnvOrDefault("API_KEY", ""),
+		JWTSecret: getEnvOrDefault("JWT_SECRET", "default-secret"),
 		PostgresCfg: PostgresConfig{
-			DBname:   getEnvOrDefault("POSTGRES_DB", "agrisa"),
-			Username: getEnvOrDefault("POSTGRES_USER", "postgres"),
-			Password: getEnvOrDefault("POSTGRES_PASSWORD", "postgres"),
-			Host:     getEnvOrDefault("POSTGRES_HOST", "localhost"),
-			Port:     getEnvOrDefault("POSTGRES_PORT", "5432"),
+			DBname:      getEnvOrDefault("POSTGRES_DB", "agrisa"),
+			Username:    getEnvOrDefault("POSTGRES_USER", "postgres"),
+			Password:    getEnvOrDefault("POSTGRES_PASSWORD", "postgres"),
+			Host:        getEnvOrDefault("POSTGRES_HOST", "localhost"),
+			Port:        getEnvOrDefault("POSTGRES_PORT", "5432"),
+			AutoMigrate: getEnvOrDefault("DB_AUTO_MIGRATE", "true") == "true",
 		},
 		RabbitMQCfg: RabbitMQConfig{
 			Host:     getEnvOrDefault("RABBITMQ_HOST", "rabbitmq"),
@@ -88,11 +168,45 @@ func New() *PolicyServiceConfig {
 			APIKey:    getEnvOrDefault("GEMINI_KEY", ""),
 			FlashName: getEnvOrDefault("GEMINI_FLASH_MODEL", "gemini-2.5-flash"),
 			ProName:   getEnvOrDefault("GEMINI_PRO_MODEL", "gemini-2.5-pro"),
+
+			RequestTimeout:  getEnvAsDurationOrDefault("GEMINI_REQUEST_TIMEOUT", 3*time.Minute),
+			MaxRetries:      getEnvAsIntOrDefault("GEMINI_MAX_RETRIES", 2),
+			BreakerFailures: getEnvAsIntOrDefault("GEMINI_BREAKER_FAILURES", 5),
+			BreakerCooldown: getEnvAsDurationOrDefault("GEMINI_BREAKER_COOLDOWN", 2*time.Minute),
+
+			ProInputPrice:    getEnvAsFloatOrDefault("GEMINI_PRO_INPUT_PRICE", 1.25),
+			ProOutputPrice:   getEnvAsFloatOrDefault("GEMINI_PRO_OUTPUT_PRICE", 10),
+			FlashInputPrice:  getEnvAsFloatOrDefault("GEMINI_FLASH_INPUT_PRICE", 0.30),
+			FlashOutputPrice: getEnvAsFloatOrDefault("GEMINI_FLASH_OUTPUT_PRICE", 2.50),
+			MonthlyBudget:    getEnvAsFloatOrDefault("AI_MONTHLY_BUDGET_USD", 0),
+		},
+		RateLimitCfg: RateLimitConfig{
+			AIValidation:      getEnvAsRateLimitOrDefault("RATE_LIMIT_AI_VALIDATION", RateLimit{Burst: 10, Period: time.Hour}),
+			OCR:               getEnvAsRateLimitOrDefault("RATE_LIMIT_OCR", RateLimit{Burst: 20, Period: time.Hour}),
+			RiskAnalysis:      getEnvAsRateLimitOrDefault("RATE_LIMIT_RISK_ANALYSIS", RateLimit{Burst: 30, Period: time.Hour}),
+			ProviderOverrides: getEnvAsRateLimitOverrides("RATE_LIMIT_PROVIDER_OVERRIDES"),
+		},
+		MonitoringRetentionCfg: MonitoringRetentionConfig{
+			RetentionMonths: getEnvAsIntOrDefault("MONITORING_RETENTION_MONTHS", 24),
+			PrecreateMonths: getEnvAsIntOrDefault("MONITORING_PRECREATE_MONTHS", 3),
+		},
+		ClaimDisputeCfg: ClaimDisputeConfig{
+			FilingWindow:       getEnvAsDurationOrDefault("CLAIM_DISPUTE_FILING_WINDOW", 30*24*time.Hour),
+			PartnerResponseSLA: getEnvAsDurationOrDefault("CLAIM_DISPUTE_PARTNER_SLA", 7*24*time.Hour),
+			ArbitrationSLA:     getEnvAsDurationOrDefault("CLAIM_DISPUTE_ARBITRATION_SLA", 14*24*time.Hour),
+		},
+		InvoicingCfg: InvoicingConfig{
+			TaxPercent:  getEnvAsFloatOrDefault("INVOICE_TAX_PERCENT", 10),
+			PaymentTerm: getEnvAsDurationOrDefault("INVOICE_PAYMENT_TERM", 30*24*time.Hour),
 		},
 		VerifyNationalIDURL:          getEnvOrDefault("VERIFY_NATIONAL_ID_URL", "key"),
 		VerifyLandCertificateHostAPI: getEnvOrDefault("VERIFY_LAND_CERTIFICATE_HOST_API", "key"),
 		SatelliteDataServiceURL:      getEnvOrDefault("SATELLITE_DATA_SERVICE_URL", "http://satellite-data-service:8000"),
 		WeatherDataServiceURL:        getEnvOrDefault("WEATHER_SERVICE_URL", "http://weather-service:8086"),
+		AuthServiceURL:               getEnvOrDefault("AUTH_SERVICE_URL", "http://auth-service:8083"),
+		ProfileServiceURL:            getEnvOrDefault("PROFILE_SERVICE_URL", "http://profile-service:8087"),
+		PaymentServiceURL:            getEnvOrDefault("PAYMENT_SERVICE_URL", "http://payment-service:3000"),
+		ClamAVAddress:                getEnvOrDefault("CLAMAV_ADDRESS", ""),
 	}
 }
 
@@ -102,3 +216,73 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvAsIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsFloatOrDefault(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsDurationOrDefault reads durations such as 90s or 2m
+func getEnvAsDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsRateLimitOrDefault(key string, defaultValue RateLimit) RateLimit {
+	if limit, err := parseRateLimit(os.Getenv(key)); err == nil {
+		return limit
+	}
+	return defaultValue
+}
+
+// getEnvAsRateLimitOverrides reads entries such as provider-1:ai-validation=50/1h;provider-2:ocr=5/1h,
+// skipping malformed ones
+func getEnvAsRateLimitOverrides(key string) map[string]map[string]RateLimit {
+	overrides := make(map[string]map[string]RateLimit)
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		target, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		providerID, group, ok := strings.Cut(target, ":")
+		if !ok || providerID == "" || group == "" {
+			continue
+		}
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			continue
+		}
+		if overrides[providerID] == nil {
+			overrides[providerID] = make(map[string]RateLimit)
+		}
+		overrides[providerID][group] = limit
+	}
+	return overrides
+}
+
+func parseRateLimit(value string) (RateLimit, error) {
+	burst, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("rate limit %q is not burst/period", value)
+	}
+	b, err := strconv.Atoi(burst)
+	if err != nil || b <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid burst in rate limit %q", value)
+	}
+	p, err := time.ParseDuration(period)
+	if err != nil || p <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid period in rate limit %q", value)
+	}
+	return RateLimit{Burst: b, Period: p}, nil
+}
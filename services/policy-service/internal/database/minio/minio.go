@@ -43,6 +43,7 @@ type FileUploadValidationErr struct {
 type FileUploadedInfo struct {
 	FieldName   string
 	ResourceURL string
+	ObjectName  string
 }
 
 // Storage defines bucket names for different data types in policy service
@@ -52,12 +53,18 @@ var Storage = struct {
 	PolicyAttachments string
 	DataSources       string
 	ValidationReports string
+	Quarantine        string
+	MonitoringArchive string
+	Invoices          string
 }{
 	PolicyService:     "policy-service",
 	PolicyDocuments:   "policy-documents",
 	PolicyAttachments: "policy-attachments",
 	DataSources:       "data-sources",
 	ValidationReports: "validation-reports",
+	Quarantine:        "quarantine",
+	MonitoringArchive: "monitoring-archive",
+	Invoices:          "partner-invoices",
 }
 
 // BucketNames contains all bucket names for policy service
@@ -67,6 +74,9 @@ var BucketNames = []string{
 	Storage.PolicyAttachments,
 	Storage.DataSources,
 	Storage.ValidationReports,
+	Storage.Quarantine,
+	Storage.MonitoringArchive,
+	Storage.Invoices,
 }
 
 // NewMinioClient initializes a new MinIO client with the provided configuration
@@ -253,6 +263,38 @@ func (mc *MinioClient) GetPresignedURL(ctx context.Context, bucketName, objectNa
 	return presignedURL.String(), nil
 }
 
+// GetPresignedUploadURL generates a presigned URL a client can PUT an object to
+func (mc *MinioClient) GetPresignedUploadURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	presignedURL, err := mc.client.PresignedPutObject(ctx, bucketName, objectName, expiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned upload URL for %s in bucket %s: %w", objectName, bucketName, err)
+	}
+
+	return presignedURL.String(), nil
+}
+
+// StatFile returns the metadata of an object, such as its size
+func (mc *MinioClient) StatFile(ctx context.Context, bucketName, objectName string) (minio.ObjectInfo, error) {
+	info, err := mc.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return minio.ObjectInfo{}, fmt.Errorf("failed to stat file %s in bucket %s: %w", objectName, bucketName, err)
+	}
+
+	return info, nil
+}
+
+// CopyFile copies an object, possibly into another bucket, without downloading it
+func (mc *MinioClient) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	_, err := mc.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: dstBucket, Object: dstObject},
+		minio.CopySrcOptions{Bucket: srcBucket, Object: srcObject})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s/%s to %s/%s: %w", srcBucket, srcObject, dstBucket, dstObject, err)
+	}
+
+	return nil
+}
+
 // ListFiles lists all files in a bucket with optional prefix
 func (mc *MinioClient) ListFiles(ctx context.Context, bucketName, prefix string) ([]minio.ObjectInfo, error) {
 	var objects []minio.ObjectInfo
@@ -474,6 +516,7 @@ func (mc *MinioClient) UploadFilesToMinio(
 		fileUploadedInfo := FileUploadedInfo{
 			FieldName:   "document_template_upload",
 			ResourceURL: minioResourceURL,
+			ObjectName:  safeFileName,
 		}
 
 		fileUploadedInfos = append(fileUploadedInfos, fileUploadedInfo)
@@ -1,3 +1,6 @@
+-- Schema of the service before versioned migrations. Databases created from it are adopted at
+-- this version without running it again.
+-- +goose Up
 -- ============================================================================
 -- AGRISA: Satellite-Powered Agricultural Insurance Platform
 -- PostgreSQL Database Schema - Corrected Version
@@ -827,6 +830,7 @@ INSERT INTO data_tier_category (category_name, category_description, category_co
     ('Satellite', 'Satellite imagery and derived indices', 1.5),
     ('Derived', 'Advanced calculated indices and analytics', 2.5);
 
+-- +goose StatementBegin
 DO $$
 DECLARE
     weather_cat_id UUID;
@@ -848,5 +852,13 @@ BEGIN
         (derived_cat_id, 2, 'Derived Tier 2', 1.4);
 END
 $$ LANGUAGE plpgsql;
+-- +goose StatementEnd
 
-
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+    RAISE EXCEPTION 'the baseline schema cannot be rolled back';
+END
+$$;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- Closed status for settled claims and the history of every claim status change. Enum values
+-- cannot be added inside a transaction on older PostgreSQL versions.
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE claim_status ADD VALUE IF NOT EXISTS 'closed';
+
+CREATE TABLE IF NOT EXISTS claim_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    claim_id UUID NOT NULL REFERENCES claim(id) ON DELETE CASCADE,
+
+    from_status claim_status,
+    to_status claim_status NOT NULL,
+    changed_by VARCHAR(100),
+    notes TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_status_history_claim ON claim_status_history(claim_id, created_at);
+
+-- +goose Down
+-- Enum values cannot be removed, so closed claims return to the status they were closed from
+-- and the value is kept
+UPDATE claim c SET status = h.from_status
+FROM claim_status_history h
+WHERE h.claim_id = c.id AND h.to_status = 'closed' AND c.status = 'closed';
+DROP TABLE IF EXISTS claim_status_history;
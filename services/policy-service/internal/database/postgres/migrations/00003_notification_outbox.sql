@@ -0,0 +1,27 @@
+-- Notifications written in the same transaction as the change they announce, published to
+-- RabbitMQ afterwards by the outbox dispatcher
+-- +goose Up
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+
+    queue VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    headers JSONB,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending ON notification_outbox(available_at)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_aggregate ON notification_outbox(aggregate_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_outbox;
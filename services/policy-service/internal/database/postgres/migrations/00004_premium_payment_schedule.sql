@@ -0,0 +1,50 @@
+-- Installment plans for farmer premiums, the payments made against them and the lapsed status of
+-- policies whose installments went unpaid. Enum values cannot be added inside a transaction on
+-- older PostgreSQL versions.
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE policy_status ADD VALUE IF NOT EXISTS 'lapsed';
+
+CREATE TABLE IF NOT EXISTS premium_payment_schedule (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    registered_policy_id UUID NOT NULL REFERENCES registered_policy(id) ON DELETE CASCADE,
+    installment_number INT NOT NULL,
+
+    due_date BIGINT NOT NULL,
+    amount_due DECIMAL(10,2) NOT NULL,
+    amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0,
+    status payment_status NOT NULL DEFAULT 'pending',
+    paid_at BIGINT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_policy_installment UNIQUE (registered_policy_id, installment_number),
+    CONSTRAINT positive_amount_due CHECK (amount_due > 0),
+    CONSTRAINT valid_amount_paid CHECK (amount_paid >= 0 AND amount_paid <= amount_due)
+);
+
+CREATE INDEX IF NOT EXISTS idx_premium_schedule_unpaid ON premium_payment_schedule(due_date)
+    WHERE status IN ('pending', 'overdue');
+
+-- Every payment applied to a schedule; the unique payment_id keeps a redelivered payment from
+-- being applied twice
+CREATE TABLE IF NOT EXISTS premium_payment (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    registered_policy_id UUID NOT NULL REFERENCES registered_policy(id) ON DELETE CASCADE,
+    payment_id VARCHAR(100) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    paid_at BIGINT NOT NULL,
+    recorded_by VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_policy_premium_payment UNIQUE (registered_policy_id, payment_id),
+    CONSTRAINT positive_premium_payment CHECK (amount > 0)
+);
+
+-- +goose Down
+-- Enum values cannot be removed, so lapsed policies are returned to cancelled and the value is
+-- kept
+UPDATE registered_policy SET status = 'cancelled' WHERE status = 'lapsed';
+DROP TABLE IF EXISTS premium_payment;
+DROP TABLE IF EXISTS premium_payment_schedule;
@@ -0,0 +1,39 @@
+-- Bulk registration of policies from a CSV/XLSX file uploaded by an insurance partner. The file
+-- is processed by a background job; rows that cannot be registered are kept for the error report.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS policy_import_job (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    insurance_provider_id VARCHAR(100) NOT NULL,
+    base_policy_id UUID NOT NULL REFERENCES base_policy(id),
+    file_name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows INT NOT NULL DEFAULT 0,
+    imported_rows INT NOT NULL DEFAULT 0,
+    rejected_rows INT NOT NULL DEFAULT 0,
+    error_message TEXT,
+
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_import_job_provider ON policy_import_job(insurance_provider_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS policy_import_rejected_row (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    import_job_id UUID NOT NULL REFERENCES policy_import_job(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    farmer_id VARCHAR(100),
+    farm_id VARCHAR(100),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_import_rejected_row_job ON policy_import_rejected_row(import_job_id, row_number);
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_import_rejected_row;
+DROP TABLE IF EXISTS policy_import_job;
@@ -0,0 +1,22 @@
+-- Worker jobs that failed all of their retries. The Redis dead-letter list is lost on a flush and
+-- cannot be queried, so failed jobs are kept here for admins to inspect and requeue.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS dead_letter_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_id VARCHAR(255) NOT NULL,
+    job_type VARCHAR(100) NOT NULL,
+    pool_name VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    error_message TEXT,
+    retry_count INT NOT NULL DEFAULT 0,
+
+    failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    requeued_at TIMESTAMP,
+    requeue_count INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_jobs_failed_at ON dead_letter_jobs(failed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_jobs_job_type ON dead_letter_jobs(job_type, failed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS dead_letter_jobs;
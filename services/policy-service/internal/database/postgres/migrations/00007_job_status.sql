@@ -0,0 +1,27 @@
+-- Progress of the background jobs clients wait on (document validation, risk analysis, policy
+-- import). One row per job, advanced by the worker pools as the job is queued, run and retried.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS job_status (
+    job_id VARCHAR(255) PRIMARY KEY,
+    job_type VARCHAR(100) NOT NULL,
+    pool_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+    submitted_by VARCHAR(100) NOT NULL DEFAULT '',
+    retry_count INT NOT NULL DEFAULT 0,
+    error_message TEXT,
+
+    -- Record holding the outcome, e.g. resource 'base_policy_validation' and the base policy ID
+    result_resource VARCHAR(100),
+    result_id VARCHAR(255),
+
+    queued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_status_type_status ON job_status(job_type, status, queued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_status_submitted_by ON job_status(submitted_by, queued_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_status;
@@ -0,0 +1,24 @@
+-- Who changed a base or registered policy and what changed, kept for insurers and regulators.
+-- Rows are only ever inserted.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL CHECK (entity_type IN ('base_policy', 'registered_policy')),
+    entity_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+
+    -- User ID set by the gateway, or 'system' for changes made by background jobs
+    actor_id VARCHAR(100) NOT NULL,
+    request_id VARCHAR(128),
+
+    -- Changed fields as {"field": {"before": ..., "after": ...}}
+    changes JSONB NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,64 @@
+-- Terms of a base policy as they were sold. A new version is inserted whenever the terms change;
+-- rows are never updated, and each registered policy points at the version it was sold under.
+-- +goose Up
+-- base_policy_terms is the single definition of what counts as the terms of a base policy: its
+-- own fields, trigger and conditions, without IDs, lifecycle status, validation results, the
+-- owning provider or timestamps.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION base_policy_terms(policy_id UUID) RETURNS JSONB AS $$
+    SELECT jsonb_build_object(
+        'base_policy',
+        to_jsonb(bp) - 'id' - 'insurance_provider_id' - 'status' - 'document_validation_status'
+            - 'document_validation_score' - 'created_at' - 'updated_at' - 'created_by',
+        'trigger',
+        (
+            SELECT (to_jsonb(bt) - 'id' - 'base_policy_id' - 'created_at' - 'updated_at')
+                || jsonb_build_object('conditions', COALESCE((
+                    SELECT jsonb_agg(
+                        to_jsonb(btc) - 'id' - 'base_policy_trigger_id' - 'created_at'
+                        ORDER BY btc.condition_order, btc.id)
+                    FROM base_policy_trigger_condition btc
+                    WHERE btc.base_policy_trigger_id = bt.id
+                ), '[]'::jsonb))
+            FROM base_policy_trigger bt
+            WHERE bt.base_policy_id = bp.id
+        )
+    )
+    FROM base_policy bp
+    WHERE bp.id = policy_id
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS base_policy_version (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    base_policy_id UUID NOT NULL REFERENCES base_policy(id) ON DELETE CASCADE,
+    version INT NOT NULL CHECK (version > 0),
+    terms JSONB NOT NULL,
+
+    -- User ID set by the gateway, or 'system' for versions made by background jobs
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_base_policy_version UNIQUE (base_policy_id, version)
+);
+
+-- The terms of existing base policies are their first version; earlier changes were not kept
+INSERT INTO base_policy_version (base_policy_id, version, terms, created_by)
+SELECT id, 1, base_policy_terms(id), 'system'
+FROM base_policy
+ON CONFLICT (base_policy_id, version) DO NOTHING;
+
+ALTER TABLE registered_policy ADD COLUMN IF NOT EXISTS base_policy_version INT;
+
+UPDATE registered_policy SET base_policy_version = 1 WHERE base_policy_version IS NULL;
+
+ALTER TABLE registered_policy
+    ADD CONSTRAINT fk_registered_policy_base_policy_version
+    FOREIGN KEY (base_policy_id, base_policy_version)
+    REFERENCES base_policy_version(base_policy_id, version);
+
+-- +goose Down
+ALTER TABLE registered_policy DROP CONSTRAINT IF EXISTS fk_registered_policy_base_policy_version;
+ALTER TABLE registered_policy DROP COLUMN IF EXISTS base_policy_version;
+DROP TABLE IF EXISTS base_policy_version;
+DROP FUNCTION IF EXISTS base_policy_terms(UUID);
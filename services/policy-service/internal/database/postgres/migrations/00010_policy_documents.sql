@@ -0,0 +1,39 @@
+-- Files attached to a base policy (contract, appendix, brochure) or a registered policy (signed
+-- copies), stored in the policy-attachments bucket. Each upload of a document type is a new
+-- version; earlier versions stay available. base_policy.template_document_url is unchanged.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS policy_documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    base_policy_id UUID REFERENCES base_policy(id) ON DELETE CASCADE,
+    registered_policy_id UUID REFERENCES registered_policy(id) ON DELETE CASCADE,
+
+    document_type VARCHAR(30) NOT NULL
+        CHECK (document_type IN ('contract', 'appendix', 'brochure', 'signed_copy', 'other')),
+    version INT NOT NULL CHECK (version > 0),
+    title VARCHAR(200),
+
+    -- Object in MinIO; the file is uploaded by the client with a presigned URL, so the row is
+    -- pending_upload until the upload is confirmed
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    object_key VARCHAR(500) NOT NULL UNIQUE,
+    size_bytes BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_upload'
+        CHECK (status IN ('pending_upload', 'uploaded')),
+
+    uploaded_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT policy_documents_one_owner CHECK (
+        (base_policy_id IS NULL) <> (registered_policy_id IS NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_documents_base_version
+    ON policy_documents(base_policy_id, document_type, version) WHERE base_policy_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_documents_registered_version
+    ON policy_documents(registered_policy_id, document_type, version) WHERE registered_policy_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_documents;
@@ -0,0 +1,26 @@
+-- Policy PDFs uploaded straight to MinIO with a presigned PUT URL before a base policy is
+-- created. The file is staged in the policy-attachments bucket and copied to its template path
+-- in the policy-documents bucket when a complete policy creation request references it.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS policy_document_uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name VARCHAR(255) NOT NULL,
+    object_key VARCHAR(500) NOT NULL UNIQUE,
+    content_type VARCHAR(100),
+    size_bytes BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_upload'
+        CHECK (status IN ('pending_upload', 'uploaded', 'used')),
+
+    -- Set once the file became the template document of a base policy
+    base_policy_id UUID,
+
+    uploaded_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_document_uploads_uploaded_by
+    ON policy_document_uploads(uploaded_by, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_document_uploads;
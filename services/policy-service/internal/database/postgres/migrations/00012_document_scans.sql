@@ -0,0 +1,31 @@
+-- Content scans of files clients put into MinIO (policy PDFs, land certificate photos). A file
+-- is scanned by a worker job after upload; a suspicious one is moved to the quarantine bucket.
+-- Jobs that read the file, such as the AI validation of a policy PDF, wait for a clean scan.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS document_scans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source VARCHAR(30) NOT NULL CHECK (source IN ('policy_document', 'land_certificate')),
+    -- Base policy or farm the file belongs to, when known
+    reference_id UUID,
+
+    bucket VARCHAR(100) NOT NULL,
+    object_key VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'clean', 'quarantined')),
+    findings TEXT,
+    -- Scanners the file went through, e.g. 'signature,clamav'
+    scanned_with VARCHAR(100),
+    quarantine_key VARCHAR(600),
+
+    -- Job submitted once the file is clean
+    follow_up_job JSONB,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    scanned_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_scans_object ON document_scans(bucket, object_key);
+CREATE INDEX IF NOT EXISTS idx_document_scans_pending ON document_scans(created_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS document_scans;
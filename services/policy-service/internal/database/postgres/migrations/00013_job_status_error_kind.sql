@@ -0,0 +1,7 @@
+-- Category of the error a job failed with, e.g. ai_rate_limited or ai_request_rejected, so a
+-- client can tell a temporary outage from a request that will never succeed.
+-- +goose Up
+ALTER TABLE job_status ADD COLUMN IF NOT EXISTS error_kind VARCHAR(50);
+
+-- +goose Down
+ALTER TABLE job_status DROP COLUMN IF EXISTS error_kind;
@@ -0,0 +1,30 @@
+-- Tokens used by each AI job and their estimated cost, one row per job and model. Rows are kept
+-- for the monthly reports and the budget alerts of insurance providers.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- Shared by the rows of one run of a job that used several models
+    job_run_id UUID NOT NULL,
+    provider VARCHAR(30) NOT NULL DEFAULT 'gemini',
+    model VARCHAR(100) NOT NULL,
+    job_type VARCHAR(100) NOT NULL,
+    insurance_provider_id VARCHAR(255) NOT NULL,
+    base_policy_id UUID,
+    registered_policy_id UUID,
+
+    call_count INT NOT NULL DEFAULT 0,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    response_tokens BIGINT NOT NULL DEFAULT 0,
+    total_tokens BIGINT NOT NULL DEFAULT 0,
+    estimated_cost_usd NUMERIC(14, 6) NOT NULL DEFAULT 0,
+    -- Whether the job got a usable answer; failed attempts are billed too
+    succeeded BOOLEAN NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_provider_created ON ai_usage(insurance_provider_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created ON ai_usage(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_usage;
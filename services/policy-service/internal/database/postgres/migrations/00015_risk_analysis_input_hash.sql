@@ -0,0 +1,7 @@
+-- Hash of the data a risk analysis was made from. The risk analysis job compares it with the
+-- hash of the current data and skips the AI call when nothing changed.
+-- +goose Up
+ALTER TABLE registered_policy_risk_analysis ADD COLUMN IF NOT EXISTS input_hash VARCHAR(64);
+
+-- +goose Down
+ALTER TABLE registered_policy_risk_analysis DROP COLUMN IF EXISTS input_hash;
@@ -0,0 +1,12 @@
+-- Policies whose monitoring is gated on the farmer's satellite monitoring consent. Policies are
+-- listed when registered; those registered before consent was recorded are not, so their
+-- monitoring carries on under the contract they were sold with.
+-- +goose Up
+CREATE TABLE policy_monitoring_consent (
+    registered_policy_id UUID PRIMARY KEY REFERENCES registered_policy(id) ON DELETE CASCADE,
+    farmer_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_monitoring_consent;
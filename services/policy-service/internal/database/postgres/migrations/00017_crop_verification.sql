@@ -0,0 +1,37 @@
+-- Every classification of the crop of a farm, by AI from its photos or by an insurer confirming or
+-- overriding it. The farm keeps the outcome in crop_type_verified and crop_type_confidence; the
+-- history stays here. Changes to the farm itself are also kept in the audit log.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS crop_verification (
+    id UUID PRIMARY KEY,
+    farm_id UUID NOT NULL REFERENCES farm(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('ai', 'insurer')),
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('verified', 'pending_review', 'change_detected', 'confirmed', 'overridden')),
+
+    declared_crop_type VARCHAR(100) NOT NULL,
+    detected_crop_type VARCHAR(100) NOT NULL,
+    confidence DECIMAL(3,2),
+
+    -- Photos the AI classification looked at, the newest taken at latest_photo_at
+    photo_count INT NOT NULL DEFAULT 0,
+    latest_photo_at BIGINT,
+
+    reasoning TEXT,
+    -- 'ai' for classifications, the reviewing user for insurer decisions
+    verified_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_crop_verification_farm ON crop_verification(farm_id, created_at DESC);
+
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_entity_type_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_entity_type_check
+    CHECK (entity_type IN ('base_policy', 'registered_policy', 'farm'));
+
+-- +goose Down
+DELETE FROM audit_log WHERE entity_type = 'farm';
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_entity_type_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_entity_type_check
+    CHECK (entity_type IN ('base_policy', 'registered_policy'));
+
+DROP TABLE IF EXISTS crop_verification;
@@ -0,0 +1,31 @@
+-- What the OCR of a farm's land certificate read and how it compared with the identity the farmer
+-- verified through eKYC. The farm keeps the outcome in land_ownership_verified; the evidence
+-- stays here.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS land_certificate_verification (
+    id UUID PRIMARY KEY,
+    farm_id UUID NOT NULL REFERENCES farm(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('verified', 'mismatch', 'unreadable')),
+
+    -- Read from the certificate
+    certificate_number VARCHAR(100),
+    owner_names JSONB NOT NULL DEFAULT '[]',
+    parcel_address TEXT,
+    confidence DECIMAL(3,2),
+
+    -- Compared against
+    identity_name VARCHAR(255) NOT NULL,
+    declared_certificate_number VARCHAR(100),
+    owner_matched BOOLEAN NOT NULL,
+    certificate_number_matched BOOLEAN,
+
+    -- Objects of the certificate photos in the policy documents bucket
+    document_objects JSONB NOT NULL DEFAULT '[]',
+    reasoning TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_land_certificate_verification_farm ON land_certificate_verification(farm_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS land_certificate_verification;
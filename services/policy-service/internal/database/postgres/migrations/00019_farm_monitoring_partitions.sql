@@ -0,0 +1,119 @@
+-- farm_monitoring_data becomes a table partitioned by month of measurement_timestamp (UTC), so a
+-- year of readings per farm stays fast to query and old months can be archived and dropped whole.
+-- Partitions are named farm_monitoring_data_pYYYYMM; the repository creates the partition of a
+-- month before writing to it and the monitoring-partition-maintenance cron job creates upcoming
+-- ones. Partitions past the retention window are exported to MinIO and dropped; the exports are
+-- listed in farm_monitoring_archive.
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ensure_farm_monitoring_partition(month_start TIMESTAMP) RETURNS TEXT AS $$
+DECLARE
+    range_start TIMESTAMP := date_trunc('month', month_start);
+    partition_name TEXT := 'farm_monitoring_data_p' || to_char(range_start, 'YYYYMM');
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF farm_monitoring_data FOR VALUES FROM (%s) TO (%s)',
+        partition_name,
+        EXTRACT(EPOCH FROM range_start)::BIGINT,
+        EXTRACT(EPOCH FROM range_start + INTERVAL '1 month')::BIGINT
+    );
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+ALTER TABLE farm_monitoring_data RENAME TO farm_monitoring_data_unpartitioned;
+ALTER TABLE farm_monitoring_data_unpartitioned RENAME CONSTRAINT farm_monitoring_data_pkey TO farm_monitoring_data_unpartitioned_pkey;
+DROP INDEX IF EXISTS idx_farm_monitoring_farm_time;
+DROP INDEX IF EXISTS idx_farm_monitoring_data_source;
+DROP INDEX IF EXISTS idx_farm_monitoring_parameter;
+
+CREATE TABLE farm_monitoring_data (
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    farm_id UUID NOT NULL REFERENCES farm(id),
+    data_source_id UUID NOT NULL REFERENCES data_source(id),
+
+    parameter_name VARCHAR(100) NOT NULL,
+    measured_value DECIMAL(10,4) NOT NULL,
+    unit VARCHAR(20),
+    measurement_timestamp INT NOT NULL,
+    component_data JSONB,
+
+    data_quality data_quality DEFAULT 'good',
+    confidence_score DECIMAL(3,2),
+
+    measurement_source VARCHAR(200),
+    distance_from_farm_meters DECIMAL(8,2),
+    cloud_cover_percentage DECIMAL(5,2),
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- The partition key has to be part of the primary key
+    PRIMARY KEY (id, measurement_timestamp)
+) PARTITION BY RANGE (measurement_timestamp);
+
+CREATE INDEX idx_farm_monitoring_farm_time ON farm_monitoring_data(farm_id, measurement_timestamp);
+CREATE INDEX idx_farm_monitoring_data_source ON farm_monitoring_data(data_source_id);
+CREATE INDEX idx_farm_monitoring_parameter ON farm_monitoring_data(parameter_name);
+
+-- Partitions for every month holding readings, up to three months ahead
+-- +goose StatementBegin
+DO $$
+DECLARE
+    month_start TIMESTAMP;
+BEGIN
+    FOR month_start IN
+        SELECT generate_series(
+            date_trunc('month', COALESCE(
+                (SELECT to_timestamp(MIN(measurement_timestamp)) AT TIME ZONE 'UTC' FROM farm_monitoring_data_unpartitioned),
+                NOW() AT TIME ZONE 'UTC')),
+            date_trunc('month', GREATEST(
+                COALESCE((SELECT to_timestamp(MAX(measurement_timestamp)) AT TIME ZONE 'UTC' FROM farm_monitoring_data_unpartitioned),
+                    NOW() AT TIME ZONE 'UTC'),
+                NOW() AT TIME ZONE 'UTC' + INTERVAL '3 months')),
+            INTERVAL '1 month')
+    LOOP
+        PERFORM ensure_farm_monitoring_partition(month_start);
+    END LOOP;
+END;
+$$;
+-- +goose StatementEnd
+
+INSERT INTO farm_monitoring_data SELECT * FROM farm_monitoring_data_unpartitioned;
+DROP TABLE farm_monitoring_data_unpartitioned;
+
+CREATE TABLE IF NOT EXISTS farm_monitoring_archive (
+    id UUID PRIMARY KEY,
+    partition_name VARCHAR(63) NOT NULL UNIQUE,
+    -- Measurement timestamps of the month, range_end excluded
+    range_start BIGINT NOT NULL,
+    range_end BIGINT NOT NULL,
+
+    bucket VARCHAR(63) NOT NULL,
+    object_key VARCHAR(500) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    row_count BIGINT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_farm_monitoring_archive_range ON farm_monitoring_archive(range_start);
+
+-- +goose Down
+-- Archived months are not restored, their readings stay in MinIO
+DROP TABLE IF EXISTS farm_monitoring_archive;
+
+CREATE TABLE farm_monitoring_data_unpartitioned (LIKE farm_monitoring_data INCLUDING DEFAULTS);
+INSERT INTO farm_monitoring_data_unpartitioned SELECT * FROM farm_monitoring_data;
+DROP TABLE farm_monitoring_data;
+ALTER TABLE farm_monitoring_data_unpartitioned RENAME TO farm_monitoring_data;
+ALTER TABLE farm_monitoring_data ADD PRIMARY KEY (id);
+ALTER TABLE farm_monitoring_data ADD FOREIGN KEY (farm_id) REFERENCES farm(id);
+ALTER TABLE farm_monitoring_data ADD FOREIGN KEY (data_source_id) REFERENCES data_source(id);
+
+CREATE INDEX idx_farm_monitoring_farm_time ON farm_monitoring_data(farm_id, measurement_timestamp);
+CREATE INDEX idx_farm_monitoring_data_source ON farm_monitoring_data(data_source_id);
+CREATE INDEX idx_farm_monitoring_parameter ON farm_monitoring_data(parameter_name);
+
+DROP FUNCTION IF EXISTS ensure_farm_monitoring_partition(TIMESTAMP);
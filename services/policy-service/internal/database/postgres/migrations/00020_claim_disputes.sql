@@ -0,0 +1,87 @@
+-- Disputes of rejected claims. The farmer opens a dispute with a reason and evidence, the partner
+-- answers in the message thread before its response deadline, and a platform admin arbitrates
+-- disputes the partner did not settle. An upheld dispute approves the claim, a dismissed or
+-- withdrawn one returns it to rejected; the outcome is kept on the claim in dispute_resolution.
+-- Enum values cannot be added inside a transaction on older PostgreSQL versions.
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE claim_status ADD VALUE IF NOT EXISTS 'disputed';
+
+ALTER TABLE claim ADD COLUMN IF NOT EXISTS dispute_resolution VARCHAR(20)
+    CHECK (dispute_resolution IN ('upheld', 'dismissed', 'withdrawn'));
+
+-- A claim is disputed at most once
+CREATE TABLE IF NOT EXISTS claim_dispute (
+    id UUID PRIMARY KEY,
+    claim_id UUID NOT NULL UNIQUE REFERENCES claim(id) ON DELETE CASCADE,
+    registered_policy_id UUID NOT NULL REFERENCES registered_policy(id),
+    farmer_id VARCHAR(100) NOT NULL,
+    insurance_provider_id VARCHAR(100) NOT NULL,
+
+    status VARCHAR(30) NOT NULL
+        CHECK (status IN ('awaiting_partner', 'partner_responded', 'in_arbitration', 'upheld', 'dismissed', 'withdrawn')),
+    reason TEXT NOT NULL,
+
+    -- SLA timers, unix seconds
+    partner_response_due_at BIGINT NOT NULL,
+    partner_responded_at BIGINT,
+    escalated_at BIGINT,
+    arbitration_due_at BIGINT,
+    sla_breached BOOLEAN NOT NULL DEFAULT FALSE,
+
+    resolution_notes TEXT,
+    resolved_by VARCHAR(100),
+    resolved_at BIGINT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_dispute_farmer ON claim_dispute(farmer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_claim_dispute_provider ON claim_dispute(insurance_provider_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_claim_dispute_open ON claim_dispute(status, partner_response_due_at)
+    WHERE status IN ('awaiting_partner', 'partner_responded', 'in_arbitration');
+
+CREATE TABLE IF NOT EXISTS claim_dispute_message (
+    id UUID PRIMARY KEY,
+    dispute_id UUID NOT NULL REFERENCES claim_dispute(id) ON DELETE CASCADE,
+    author_id VARCHAR(100) NOT NULL,
+    author_role VARCHAR(20) NOT NULL CHECK (author_role IN ('farmer', 'partner', 'platform_admin', 'system')),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_dispute_message_dispute ON claim_dispute_message(dispute_id, created_at);
+
+-- Files uploaded straight to MinIO with a presigned URL, listed once the upload is confirmed
+CREATE TABLE IF NOT EXISTS claim_dispute_evidence (
+    id UUID PRIMARY KEY,
+    dispute_id UUID NOT NULL REFERENCES claim_dispute(id) ON DELETE CASCADE,
+    uploaded_by VARCHAR(100) NOT NULL,
+    uploader_role VARCHAR(20) NOT NULL CHECK (uploader_role IN ('farmer', 'partner', 'platform_admin')),
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    object_key VARCHAR(500) NOT NULL,
+    size_bytes BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_upload' CHECK (status IN ('pending_upload', 'uploaded')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_dispute_evidence_dispute ON claim_dispute_evidence(dispute_id, created_at);
+
+ALTER TABLE document_scans DROP CONSTRAINT IF EXISTS document_scans_source_check;
+ALTER TABLE document_scans ADD CONSTRAINT document_scans_source_check
+    CHECK (source IN ('policy_document', 'land_certificate', 'dispute_evidence'));
+
+-- +goose Down
+-- Enum values cannot be removed, disputed claims return to rejected and the value is kept
+UPDATE claim SET status = 'rejected' WHERE status = 'disputed';
+DELETE FROM document_scans WHERE source = 'dispute_evidence';
+ALTER TABLE document_scans DROP CONSTRAINT IF EXISTS document_scans_source_check;
+ALTER TABLE document_scans ADD CONSTRAINT document_scans_source_check
+    CHECK (source IN ('policy_document', 'land_certificate'));
+
+DROP TABLE IF EXISTS claim_dispute_evidence;
+DROP TABLE IF EXISTS claim_dispute_message;
+DROP TABLE IF EXISTS claim_dispute;
+ALTER TABLE claim DROP COLUMN IF EXISTS dispute_resolution;
@@ -0,0 +1,97 @@
+-- Materialized views behind the insurer analytics endpoints. They are refreshed concurrently by a
+-- cron job, so each has a unique index, and analytics_refresh records when each was last
+-- refreshed.
+-- +goose Up
+
+-- Policies of each provider grouped by status, crop and province of the insured farm
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_policy_portfolio AS
+SELECT
+    rp.insurance_provider_id,
+    rp.status::TEXT AS status,
+    COALESCE(NULLIF(f.crop_type, ''), 'unknown') AS crop_type,
+    COALESCE(NULLIF(f.province, ''), 'unknown') AS province,
+    COUNT(*) AS policy_count,
+    COALESCE(SUM(rp.coverage_amount), 0)::DOUBLE PRECISION AS coverage_amount,
+    COALESCE(SUM(rp.total_farmer_premium), 0)::DOUBLE PRECISION AS premium_written
+FROM registered_policy rp
+LEFT JOIN farm f ON f.id = rp.farm_id
+GROUP BY 1, 2, 3, 4;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_policy_portfolio
+    ON analytics_policy_portfolio(insurance_provider_id, status, crop_type, province);
+
+-- Monthly activity of each provider. Premium collected counts the installments paid in the month,
+-- and the full premium of policies paid without a schedule; payouts count completed payouts.
+CREATE MATERIALIZED VIEW IF NOT EXISTS analytics_monthly_activity AS
+WITH activity AS (
+    SELECT rp.insurance_provider_id,
+           DATE_TRUNC('month', TO_TIMESTAMP(pp.paid_at))::DATE AS month,
+           pp.amount AS premium_collected,
+           0 AS payouts_paid, 0 AS payout_count,
+           0 AS claim_count, 0 AS rejected_claim_count, 0 AS policies_registered
+    FROM premium_payment pp
+    JOIN registered_policy rp ON rp.id = pp.registered_policy_id
+
+    UNION ALL
+    SELECT rp.insurance_provider_id,
+           DATE_TRUNC('month', TO_TIMESTAMP(rp.premium_paid_at))::DATE,
+           rp.total_farmer_premium,
+           0, 0, 0, 0, 0
+    FROM registered_policy rp
+    WHERE rp.premium_paid_by_farmer
+      AND rp.premium_paid_at IS NOT NULL
+      AND NOT EXISTS (SELECT 1 FROM premium_payment pp WHERE pp.registered_policy_id = rp.id)
+
+    UNION ALL
+    SELECT rp.insurance_provider_id,
+           DATE_TRUNC('month', TO_TIMESTAMP(p.completed_at))::DATE,
+           0, p.payout_amount, 1,
+           0, 0, 0
+    FROM payout p
+    JOIN registered_policy rp ON rp.id = p.registered_policy_id
+    WHERE p.status = 'completed' AND p.completed_at IS NOT NULL
+
+    UNION ALL
+    SELECT rp.insurance_provider_id,
+           DATE_TRUNC('month', c.created_at)::DATE,
+           0, 0, 0,
+           1, CASE WHEN c.status = 'rejected' THEN 1 ELSE 0 END, 0
+    FROM claim c
+    JOIN registered_policy rp ON rp.id = c.registered_policy_id
+
+    UNION ALL
+    SELECT rp.insurance_provider_id,
+           DATE_TRUNC('month', rp.created_at)::DATE,
+           0, 0, 0,
+           0, 0, 1
+    FROM registered_policy rp
+)
+SELECT
+    insurance_provider_id,
+    month,
+    SUM(premium_collected)::DOUBLE PRECISION AS premium_collected,
+    SUM(payouts_paid)::DOUBLE PRECISION AS payouts_paid,
+    SUM(payout_count)::BIGINT AS payout_count,
+    SUM(claim_count)::BIGINT AS claim_count,
+    SUM(rejected_claim_count)::BIGINT AS rejected_claim_count,
+    SUM(policies_registered)::BIGINT AS policies_registered
+FROM activity
+GROUP BY insurance_provider_id, month;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_monthly_activity
+    ON analytics_monthly_activity(insurance_provider_id, month);
+
+CREATE TABLE IF NOT EXISTS analytics_refresh (
+    view_name VARCHAR(100) PRIMARY KEY,
+    refreshed_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO analytics_refresh (view_name, refreshed_at) VALUES
+    ('analytics_policy_portfolio', NOW()),
+    ('analytics_monthly_activity', NOW())
+ON CONFLICT (view_name) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS analytics_refresh;
+DROP MATERIALIZED VIEW IF EXISTS analytics_monthly_activity;
+DROP MATERIALIZED VIEW IF EXISTS analytics_policy_portfolio;
@@ -0,0 +1,61 @@
+-- Monthly invoices of the data cost of each insurance partner. partner_invoice and
+-- invoice_line_item exist since the baseline but were never written: an invoice now snapshots the
+-- data cost of the policies a partner started in a month, adds the markup of its contract and VAT,
+-- and keeps the PDF rendered for it in MinIO. invoice_month is written as YYYYMM, due_date and
+-- paid_date as unix seconds.
+-- +goose Up
+ALTER TABLE partner_invoice
+    ALTER COLUMN total_data_complexity_fee TYPE DECIMAL(15,2),
+    ALTER COLUMN subtotal TYPE DECIMAL(15,2),
+    ALTER COLUMN tax TYPE DECIMAL(15,2),
+    ALTER COLUMN total_due TYPE DECIMAL(15,2),
+    ALTER COLUMN due_date TYPE BIGINT,
+    ALTER COLUMN paid_date TYPE BIGINT,
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'VND',
+    ADD COLUMN IF NOT EXISTS data_cost_markup DECIMAL(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS markup_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS contract_id VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS contract_version INT,
+    ADD COLUMN IF NOT EXISTS pdf_object_key VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS payment_reference VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS marked_paid_by VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS cancellation_reason TEXT,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- One invoice per partner and month; a cancelled invoice can be issued again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoice_provider_month ON partner_invoice(insurance_provider_id, invoice_month)
+    WHERE payment_status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS idx_invoice_open_due ON partner_invoice(due_date)
+    WHERE payment_status = 'pending';
+
+ALTER TABLE invoice_line_item
+    ALTER COLUMN unit_cost TYPE DECIMAL(15,4),
+    ALTER COLUMN total_cost TYPE DECIMAL(15,2);
+
+-- +goose Down
+ALTER TABLE invoice_line_item
+    ALTER COLUMN unit_cost TYPE DECIMAL(10,4),
+    ALTER COLUMN total_cost TYPE DECIMAL(10,2);
+
+DROP INDEX IF EXISTS idx_invoice_open_due;
+DROP INDEX IF EXISTS idx_invoice_provider_month;
+
+ALTER TABLE partner_invoice
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS cancellation_reason,
+    DROP COLUMN IF EXISTS marked_paid_by,
+    DROP COLUMN IF EXISTS payment_reference,
+    DROP COLUMN IF EXISTS pdf_object_key,
+    DROP COLUMN IF EXISTS contract_version,
+    DROP COLUMN IF EXISTS contract_id,
+    DROP COLUMN IF EXISTS tax_percent,
+    DROP COLUMN IF EXISTS markup_percent,
+    DROP COLUMN IF EXISTS data_cost_markup,
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN paid_date TYPE INT,
+    ALTER COLUMN due_date TYPE INT,
+    ALTER COLUMN total_due TYPE DECIMAL(12,2),
+    ALTER COLUMN tax TYPE DECIMAL(12,2),
+    ALTER COLUMN subtotal TYPE DECIMAL(12,2),
+    ALTER COLUMN total_data_complexity_fee TYPE DECIMAL(12,2);
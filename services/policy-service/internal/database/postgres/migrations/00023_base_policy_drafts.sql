@@ -0,0 +1,29 @@
+-- Base policies created through the complete policy flow are staged here until their document is
+-- validated, instead of in Redis keys that carried their state in the key name. content holds the
+-- policy, its trigger and conditions as they will be committed, validations the document
+-- validations recorded while the policy was a draft. The validation window ends at expires_at
+-- (unix seconds): auto_commit drafts are committed then, the others expire.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS base_policy_draft (
+    -- The ID the base policy keeps once committed
+    id UUID PRIMARY KEY,
+    insurance_provider_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'committed', 'expired')),
+    auto_commit BOOLEAN NOT NULL DEFAULT FALSE,
+
+    content JSONB NOT NULL,
+    validations JSONB NOT NULL DEFAULT '[]',
+
+    expires_at BIGINT NOT NULL,
+    committed_at BIGINT,
+    expired_at BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_base_policy_draft_provider ON base_policy_draft(insurance_provider_id, status);
+CREATE INDEX IF NOT EXISTS idx_base_policy_draft_pending_expiry ON base_policy_draft(expires_at)
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS base_policy_draft;
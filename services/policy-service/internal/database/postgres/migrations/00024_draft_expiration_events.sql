@@ -0,0 +1,26 @@
+-- The end of the validation window of each draft, recorded when the expiry of its Redis key is
+-- heard or, when that notification was lost, when the sweeper finds the pending draft past its
+-- expires_at. An event stays pending until its handler succeeds, retried from next_attempt_at
+-- (unix seconds); outcome is the status the draft was left in.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS draft_expiration_event (
+    id UUID PRIMARY KEY,
+    draft_id UUID NOT NULL UNIQUE REFERENCES base_policy_draft(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('listener', 'sweeper')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processed')),
+    outcome VARCHAR(20) CHECK (outcome IN ('committed', 'expired')),
+
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at BIGINT NOT NULL,
+    detected_at BIGINT NOT NULL,
+    processed_at BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_draft_expiration_event_due ON draft_expiration_event(next_attempt_at)
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS draft_expiration_event;
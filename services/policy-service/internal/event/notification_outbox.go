@@ -0,0 +1,316 @@
+package event
+
+import (
+	utils "agrisa_utils"
+	"agrisa_utils/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	outboxDispatchInterval = 5 * time.Second
+	outboxBatchSize        = 50
+	outboxMaxAttempts      = 10
+	outboxMaxBackoff       = 10 * time.Minute
+
+	// notification-service retries a message it fails to process this many times
+	notificationMaxRetries = 3
+	notificationPriority   = 5
+)
+
+// PolicyActivatedNotification tells the farmer their policy is active
+func PolicyActivatedNotification(ctx context.Context, policy *models.RegisteredPolicy) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxPolicyActivated, policy.ID, policy.FarmerID,
+		"Hợp Đồng Đã Được Kích Hoạt",
+		fmt.Sprintf("Hợp đồng bảo hiểm %s đã được kích hoạt và bắt đầu được giám sát.", policy.PolicyNumber),
+		map[string]any{"policy_id": policy.ID, "policy_number": policy.PolicyNumber})
+}
+
+// PolicyExpiredNotification tells the farmer their policy has expired
+func PolicyExpiredNotification(ctx context.Context, policy *models.RegisteredPolicy) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxPolicyExpired, policy.ID, policy.FarmerID,
+		"Hết Hạn Hợp Đồng",
+		fmt.Sprintf("Hợp đồng bảo hiểm %s đã hết hạn.", policy.PolicyNumber),
+		map[string]any{"policy_id": policy.ID, "policy_number": policy.PolicyNumber})
+}
+
+// PolicyLapsedNotification tells the farmer their policy lapsed for missing a premium installment
+func PolicyLapsedNotification(ctx context.Context, policy *models.RegisteredPolicy) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxPolicyLapsed, policy.ID, policy.FarmerID,
+		"Hợp Đồng Đã Mất Hiệu Lực",
+		fmt.Sprintf("Hợp đồng bảo hiểm %s đã mất hiệu lực do quá hạn thanh toán phí bảo hiểm.", policy.PolicyNumber),
+		map[string]any{"policy_id": policy.ID, "policy_number": policy.PolicyNumber})
+}
+
+// ClaimCreatedNotification tells the farmer a claim was raised on their policy
+func ClaimCreatedNotification(ctx context.Context, policy *models.RegisteredPolicy, claim *models.Claim) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxClaimCreated, claim.ID, policy.FarmerID,
+		"Sự Kiện Bảo Hiểm Đã Được Kích Hoạt",
+		fmt.Sprintf("Sự kiện bảo hiểm cho hợp đồng %s đã được kích hoạt.", policy.PolicyNumber),
+		map[string]any{
+			"policy_id":     policy.ID,
+			"policy_number": policy.PolicyNumber,
+			"claim_id":      claim.ID,
+			"claim_number":  claim.ClaimNumber,
+		})
+}
+
+// ClaimDisputeOpenedNotification tells the insurance provider the farmer disputes the rejection of
+// a claim and when their response is due
+func ClaimDisputeOpenedNotification(ctx context.Context, policy *models.RegisteredPolicy, claim *models.Claim, dispute *models.ClaimDispute) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxClaimDisputeOpened, dispute.ID, dispute.InsuranceProviderID,
+		"Khiếu Nại Yêu Cầu Bồi Thường",
+		fmt.Sprintf("Nông dân đã khiếu nại việc từ chối yêu cầu bồi thường %s của hợp đồng %s. Vui lòng phản hồi trước %s.",
+			claim.ClaimNumber, policy.PolicyNumber, time.Unix(dispute.PartnerResponseDueAt, 0).Format("02/01/2006 15:04")),
+		map[string]any{
+			"dispute_id":              dispute.ID,
+			"claim_id":                claim.ID,
+			"claim_number":            claim.ClaimNumber,
+			"policy_id":               policy.ID,
+			"policy_number":           policy.PolicyNumber,
+			"partner_response_due_at": dispute.PartnerResponseDueAt,
+		})
+}
+
+// ClaimDisputeEscalatedNotification tells the insurance provider a dispute went to platform
+// arbitration, on request of the farmer or because the response deadline passed
+func ClaimDisputeEscalatedNotification(ctx context.Context, dispute *models.ClaimDispute) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxClaimDisputeEscalated, dispute.ID, dispute.InsuranceProviderID,
+		"Khiếu Nại Được Chuyển Trọng Tài",
+		"Khiếu nại yêu cầu bồi thường đã được chuyển cho quản trị viên nền tảng xem xét.",
+		map[string]any{
+			"dispute_id":   dispute.ID,
+			"claim_id":     dispute.ClaimID,
+			"sla_breached": dispute.SLABreached,
+		})
+}
+
+// ClaimDisputeResolvedNotification tells the farmer the outcome of their dispute
+func ClaimDisputeResolvedNotification(ctx context.Context, claim *models.Claim, dispute *models.ClaimDispute) (*models.OutboxEvent, error) {
+	body := fmt.Sprintf("Khiếu nại của yêu cầu bồi thường %s đã bị bác bỏ.", claim.ClaimNumber)
+	if dispute.Status == models.DisputeUpheld {
+		body = fmt.Sprintf("Khiếu nại của yêu cầu bồi thường %s đã được chấp nhận, yêu cầu bồi thường được phê duyệt.", claim.ClaimNumber)
+	}
+	return newUserNotification(ctx, models.OutboxClaimDisputeResolved, dispute.ID, dispute.FarmerID,
+		"Kết Quả Khiếu Nại",
+		body,
+		map[string]any{
+			"dispute_id":   dispute.ID,
+			"claim_id":     claim.ID,
+			"claim_number": claim.ClaimNumber,
+			"status":       dispute.Status,
+		})
+}
+
+// InvoiceIssuedNotification tells an insurance provider its invoice of a month was issued
+func InvoiceIssuedNotification(ctx context.Context, invoice *models.PartnerInvoice) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxInvoiceIssued, invoice.ID, invoice.InsuranceProviderID,
+		"Hóa Đơn Chi Phí Dữ Liệu",
+		fmt.Sprintf("Hóa đơn %s tháng %s đã được phát hành: %.2f %s, hạn thanh toán %s.",
+			invoice.InvoiceNumber, invoice.Period().Format("01/2006"), invoice.TotalDue, invoice.Currency,
+			time.Unix(invoice.DueDate, 0).Format("02/01/2006")),
+		map[string]any{
+			"invoice_id":     invoice.ID,
+			"invoice_number": invoice.InvoiceNumber,
+			"invoice_month":  invoice.InvoiceMonth,
+			"total_due":      invoice.TotalDue,
+			"currency":       invoice.Currency,
+			"due_date":       invoice.DueDate,
+		})
+}
+
+// InvoiceOverdueNotification tells an insurance provider its invoice is past its due date
+func InvoiceOverdueNotification(ctx context.Context, invoice *models.PartnerInvoice) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxInvoiceOverdue, invoice.ID, invoice.InsuranceProviderID,
+		"Hóa Đơn Quá Hạn",
+		fmt.Sprintf("Hóa đơn %s tháng %s đã quá hạn thanh toán: %.2f %s.",
+			invoice.InvoiceNumber, invoice.Period().Format("01/2006"), invoice.TotalDue, invoice.Currency),
+		map[string]any{
+			"invoice_id":     invoice.ID,
+			"invoice_number": invoice.InvoiceNumber,
+			"total_due":      invoice.TotalDue,
+			"currency":       invoice.Currency,
+			"due_date":       invoice.DueDate,
+		})
+}
+
+// AIBudgetAlertNotification tells an insurance provider its AI spend of the month reached percent
+// of its budget. usageID is the usage that crossed the threshold.
+func AIBudgetAlertNotification(ctx context.Context, usageID uuid.UUID, insuranceProviderID, month string, percent int, spentUSD, budgetUSD float64) (*models.OutboxEvent, error) {
+	return newUserNotification(ctx, models.OutboxAIBudgetAlert, usageID, insuranceProviderID,
+		"Cảnh Báo Chi Phí AI",
+		fmt.Sprintf("Chi phí AI tháng %s đã đạt %d%% ngân sách: %.2f/%.2f USD.", month, percent, spentUSD, budgetUSD),
+		map[string]any{
+			"month":       month,
+			"percent":     percent,
+			"spent_usd":   spentUSD,
+			"budget_usd":  budgetUSD,
+			"provider_id": insuranceProviderID,
+		})
+}
+
+// newUserNotification builds an in-app notification to one user, a farmer or a partner, for the
+// notifications queue. The message ID is the ID of the outbox event, so a message published twice
+// can be recognised.
+func newUserNotification(ctx context.Context, eventType models.OutboxEventType, aggregateID uuid.UUID, userID, title, body string, data map[string]any) (*models.OutboxEvent, error) {
+	id := uuid.New()
+	data["event_type"] = eventType
+
+	message := NotificationMessage{
+		ID:          id.String(),
+		Type:        NotificationTypeInApp,
+		Priority:    notificationPriority,
+		RecipientID: userID,
+		Payload: map[string]any{
+			"lstUserIds": []string{userID},
+			"title":      title,
+			"body":       body,
+			"data":       data,
+		},
+		MaxRetries: notificationMaxRetries,
+		CreatedAt:  time.Now(),
+	}
+	raw, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification message: %w", err)
+	}
+	var payload utils.JSONMap
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("failed to convert notification message: %w", err)
+	}
+
+	return &models.OutboxEvent{
+		ID:          id,
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Queue:       NotificationsQueue,
+		Payload:     payload,
+		Headers:     logging.MessageHeaders(ctx),
+	}, nil
+}
+
+// OutboxDispatcher publishes the events of the notification outbox to RabbitMQ. An event is
+// marked published in the same transaction that locked it, so a crash between the publish and
+// the commit sends it again: delivery is at least once.
+type OutboxDispatcher struct {
+	outboxRepo *repository.OutboxRepository
+	conn       *RabbitMQConnection
+}
+
+func NewOutboxDispatcher(outboxRepo *repository.OutboxRepository, conn *RabbitMQConnection) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outboxRepo: outboxRepo,
+		conn:       conn,
+	}
+}
+
+// Start dispatches due events every few seconds until ctx is cancelled
+func (d *OutboxDispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(outboxDispatchInterval)
+	defer ticker.Stop()
+
+	slog.Info("Notification outbox dispatcher started", "interval", outboxDispatchInterval)
+	for {
+		// Keep going while full batches come back, so a backlog drains without waiting
+		for {
+			dispatched, err := d.dispatchBatch(ctx)
+			if err != nil {
+				slog.Error("failed to dispatch notification outbox", "error", err)
+				break
+			}
+			if dispatched < outboxBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Notification outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *OutboxDispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	tx, err := d.outboxRepo.BeginTransaction()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	events, err := d.outboxRepo.GetPendingForUpdateTx(tx, ctx, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	declared := make(map[string]bool)
+	for _, ev := range events {
+		// Log under the request ID the event was written in
+		evCtx := logging.FromMessageHeaders(ctx, ev.Headers)
+
+		publishErr := d.publish(evCtx, &ev, declared)
+		if publishErr == nil {
+			if err := d.outboxRepo.MarkPublishedTx(tx, ctx, ev.ID); err != nil {
+				return 0, err
+			}
+			slog.InfoContext(evCtx, "Outbox event published",
+				"event_id", ev.ID,
+				"event_type", ev.EventType,
+				"queue", ev.Queue)
+			continue
+		}
+
+		attempts := ev.Attempts + 1
+		giveUp := attempts >= outboxMaxAttempts
+		backoff := min(time.Duration(attempts*attempts)*10*time.Second, outboxMaxBackoff)
+		if err := d.outboxRepo.MarkAttemptFailedTx(tx, ctx, ev.ID, publishErr.Error(), time.Now().Add(backoff), giveUp); err != nil {
+			return 0, err
+		}
+		slog.ErrorContext(evCtx, "failed to publish outbox event",
+			"event_id", ev.ID,
+			"event_type", ev.EventType,
+			"attempts", attempts,
+			"gave_up", giveUp,
+			"error", publishErr)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit outbox batch: %w", err)
+	}
+	return len(events), nil
+}
+
+func (d *OutboxDispatcher) publish(ctx context.Context, ev *models.OutboxEvent, declared map[string]bool) error {
+	if !declared[ev.Queue] {
+		if _, err := d.conn.Channel.QueueDeclare(ev.Queue, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare queue: %w", err)
+		}
+		declared[ev.Queue] = true
+	}
+
+	body, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	return d.conn.Channel.PublishWithContext(ctx, "", ev.Queue, false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "application/json",
+		MessageId:    ev.ID.String(),
+		Body:         body,
+		Timestamp:    time.Now(),
+		Headers:      amqp.Table(ev.Headers),
+	})
+}
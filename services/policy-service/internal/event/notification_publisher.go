@@ -1,6 +1,7 @@
 package event
 
 import (
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -61,6 +62,7 @@ func (p *NotificationPublisher) PublishNotification(ctx context.Context, event N
 			ContentType:  "application/json",
 			Body:         body,
 			Timestamp:    time.Now(),
+			Headers:      logging.MessageHeaders(ctx),
 		},
 	)
 	if err != nil {
@@ -119,6 +121,7 @@ func (p *NotificationPublisher) PublishNotificationBatch(ctx context.Context, ev
 				ContentType:  "application/json",
 				Body:         body,
 				Timestamp:    time.Now(),
+				Headers:      logging.MessageHeaders(ctx),
 			},
 		)
 		if err != nil {
@@ -1,6 +1,7 @@
 package event
 
 import (
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -195,7 +196,8 @@ func (c *PaymentConsumer) startConsumerLoop(ctx context.Context) error {
 				slog.Warn("Payment consumer channel closed")
 				return fmt.Errorf("message channel closed")
 			}
-			c.processMessage(ctx, msg)
+			// Messages keep the request ID they were published under
+			c.processMessage(logging.FromMessageHeaders(ctx, msg.Headers), msg)
 		}
 	}
 }
@@ -207,14 +209,14 @@ func (c *PaymentConsumer) processMessage(ctx context.Context, msg amqp.Delivery)
 
 	var event PaymentEvent
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		slog.Error("failed to unmarshal payment event", "error", err)
+		slog.ErrorContext(ctx, "failed to unmarshal payment event", "error", err)
 		c.messagesFailed++
 		// Reject the message and don't requeue (malformed message)
 		msg.Nack(false, false)
 		return
 	}
 
-	slog.Info("Received payment event",
+	slog.InfoContext(ctx, "Received payment event",
 		"payment_id", event.ID,
 		"order_code", event.OrderCode,
 		"amount", event.Amount,
@@ -222,7 +224,7 @@ func (c *PaymentConsumer) processMessage(ctx context.Context, msg amqp.Delivery)
 	)
 
 	if err := c.handler.HandlePaymentCompleted(processCtx, event); err != nil {
-		slog.Error("failed to handle payment event",
+		slog.ErrorContext(ctx, "failed to handle payment event",
 			"payment_id", event.ID,
 			"error", err,
 		)
@@ -245,7 +247,7 @@ func (c *PaymentConsumer) processMessage(ctx context.Context, msg amqp.Delivery)
 	msg.Ack(false)
 	c.messagesProcessed++
 	c.lastMessageTime = time.Now()
-	slog.Info("Payment event processed successfully", "payment_id", event.ID)
+	slog.InfoContext(ctx, "Payment event processed successfully", "payment_id", event.ID)
 }
 
 // DefaultPaymentEventHandler is the default implementation of PaymentEventHandler
@@ -258,6 +260,8 @@ type DefaultPaymentEventHandler struct {
 	cancelRequestRepo    *repository.CancelRequestRepository
 	notievent            *NotificationHelper
 	cancelRequestService ICancelService
+	outboxRepo           *repository.OutboxRepository
+	premiumScheduleRepo  *repository.PremiumScheduleRepository
 }
 
 // NewDefaultPaymentEventHandler creates a new default payment event handler
@@ -270,6 +274,8 @@ func NewDefaultPaymentEventHandler(
 	notievent *NotificationHelper,
 	cancelRequestRepo *repository.CancelRequestRepository,
 	canRequestService ICancelService,
+	outboxRepo *repository.OutboxRepository,
+	premiumScheduleRepo *repository.PremiumScheduleRepository,
 ) *DefaultPaymentEventHandler {
 	return &DefaultPaymentEventHandler{
 		registeredPolicyRepo: registeredPolicyRepo,
@@ -280,6 +286,8 @@ func NewDefaultPaymentEventHandler(
 		notievent:            notievent,
 		cancelRequestRepo:    cancelRequestRepo,
 		cancelRequestService: canRequestService,
+		outboxRepo:           outboxRepo,
+		premiumScheduleRepo:  premiumScheduleRepo,
 	}
 }
 
@@ -320,6 +328,8 @@ func (h *DefaultPaymentEventHandler) HandlePaymentCompleted(ctx context.Context,
 		return h.handlePolicyCompensationPayment(ctx, event)
 	case models.PaymentTypeDataBill:
 		return h.handleDataBillPayment(ctx, event)
+	case models.PaymentTypePremiumInstallment:
+		return h.handlePremiumInstallmentPayment(ctx, event)
 
 	// ============================================================================
 	// TODO: ADD NEW PAYMENT TYPE HANDLERS HERE
@@ -719,6 +729,22 @@ func (h *DefaultPaymentEventHandler) processPolicyPayoutPayment(
 		return err
 	}
 
+	fromStatus := claim.Status
+	notes := fmt.Sprintf("paid by payment %s", event.ID)
+	err = h.claimRepo.CreateStatusHistoryTx(tx, ctx, &models.ClaimStatusHistory{
+		ClaimID:    claim.ID,
+		FromStatus: &fromStatus,
+		ToStatus:   models.ClaimPaid,
+		Notes:      &notes,
+	})
+	if err != nil {
+		tx.Rollback()
+		slog.Error("failed to record claim status",
+			"claim id", claim.ID,
+			"error", err)
+		return err
+	}
+
 	err = h.payoutRepo.UpdatePayoutTx(tx, payout)
 	if err != nil {
 		tx.Rollback()
@@ -865,6 +891,20 @@ func (h *DefaultPaymentEventHandler) processPolicyPayment(
 		return err
 	}
 
+	// The farmer is notified through the outbox once the activation commits
+	notification, err := PolicyActivatedNotification(ctx, registeredPolicy)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := h.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		tx.Rollback()
+		slog.Error("failed to queue policy activated notification",
+			"policy_id", registeredPolicyID,
+			"error", err)
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		slog.Error("failed to commit transaction",
@@ -1072,3 +1112,163 @@ func (h *DefaultPaymentEventHandler) handleDataBillPayment(ctx context.Context,
 	slog.Info("data bill payment processed successfully", "payment_id", event.ID)
 	return nil
 }
+
+// handlePremiumInstallmentPayment applies payments towards the installment plan of policies. Each
+// order item pays for one policy and may cover part of an installment or several of them.
+func (h *DefaultPaymentEventHandler) handlePremiumInstallmentPayment(ctx context.Context, event PaymentEvent) error {
+	paidAt := event.PaidAt.Unix()
+
+	slog.Info("processing premium installment payment",
+		"payment_id", event.ID,
+		"order_items_count", len(event.OrderItems),
+		"amount", event.Amount)
+
+	for _, orderItem := range event.OrderItems {
+		if err := h.processPremiumInstallmentPayment(ctx, event, orderItem, paidAt); err != nil {
+			slog.Error("failed to process premium installment payment",
+				"payment_id", event.ID,
+				"order_item_id", orderItem.ID,
+				"error", err)
+			return err
+		}
+	}
+
+	slog.Info("premium installment payment processed successfully", "payment_id", event.ID)
+	return nil
+}
+
+// processPremiumInstallmentPayment records the payment of one order item against the schedule of
+// its policy. A policy pending payment is activated once its first installment is settled, and
+// marked as paid once every installment is.
+func (h *DefaultPaymentEventHandler) processPremiumInstallmentPayment(
+	ctx context.Context,
+	event PaymentEvent,
+	orderItem OrderItem,
+	paidAt int64,
+) error {
+	registeredPolicyID, err := uuid.Parse(orderItem.ItemID)
+	if err != nil {
+		slog.Error("invalid policy id in order item",
+			"order_item_id", orderItem.ID,
+			"item_id", orderItem.ItemID,
+			"error", err)
+		return &PaymentValidationError{
+			PaymentID: event.ID,
+			Reason:    "invalid policy id format",
+		}
+	}
+
+	registeredPolicy, err := h.registeredPolicyRepo.GetByID(registeredPolicyID)
+	if err != nil {
+		slog.Error("failed to retrieve registered policy",
+			"policy_id", registeredPolicyID,
+			"error", err)
+		return err
+	}
+	if registeredPolicy.Status != models.PolicyPendingPayment && registeredPolicy.Status != models.PolicyActive {
+		return &PaymentValidationError{
+			PaymentID: event.ID,
+			Reason:    "policy no longer accepts premium payments",
+			Details: map[string]any{
+				"policy_id": registeredPolicyID,
+				"status":    registeredPolicy.Status,
+			},
+		}
+	}
+
+	tx, err := h.premiumScheduleRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	installments, applied, err := h.premiumScheduleRepo.ApplyPaymentTx(tx, ctx, &models.PremiumPayment{
+		RegisteredPolicyID: registeredPolicyID,
+		PaymentID:          orderItem.ID,
+		Amount:             orderItem.Price,
+		PaidAt:             paidAt,
+		RecordedBy:         &event.UserID,
+	})
+	if err != nil {
+		slog.Error("failed to apply premium installment payment",
+			"policy_id", registeredPolicyID,
+			"error", err)
+		return err
+	}
+	if !applied {
+		slog.Warn("premium installment payment already processed",
+			"policy_id", registeredPolicyID,
+			"payment_id", event.ID,
+			"order_item_id", orderItem.ID)
+		return nil
+	}
+
+	activate := registeredPolicy.Status == models.PolicyPendingPayment && installments[0].Status == models.PaymentPaid
+	if activate {
+		basePolicy, err := h.basePolicyRepo.GetBasePolicyByID(registeredPolicy.BasePolicyID)
+		if err != nil {
+			slog.Error("failed to retrieve base policy", "error", err)
+			return err
+		}
+		if registeredPolicy.CoverageStartDate == 0 {
+			registeredPolicy.CoverageStartDate = max(time.Now().Unix(), int64(*basePolicy.InsuranceValidFromDay))
+		}
+		registeredPolicy.Status = models.PolicyActive
+	}
+
+	fullyPaid := true
+	for _, installment := range installments {
+		if installment.Status != models.PaymentPaid {
+			fullyPaid = false
+			break
+		}
+	}
+	if fullyPaid {
+		registeredPolicy.PremiumPaidByFarmer = true
+		registeredPolicy.PremiumPaidAt = &paidAt
+	}
+
+	if activate || fullyPaid {
+		if err := h.registeredPolicyRepo.UpdateTx(tx, registeredPolicy); err != nil {
+			slog.Error("failed to update registered policy",
+				"policy_id", registeredPolicyID,
+				"error", err)
+			return err
+		}
+	}
+	if activate {
+		notification, err := PolicyActivatedNotification(ctx, registeredPolicy)
+		if err != nil {
+			return err
+		}
+		if err := h.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+			slog.Error("failed to queue policy activated notification",
+				"policy_id", registeredPolicyID,
+				"error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit transaction",
+			"policy_id", registeredPolicyID,
+			"error", err)
+		return err
+	}
+
+	slog.Info("premium installment payment recorded",
+		"policy_id", registeredPolicyID,
+		"payment_id", event.ID,
+		"amount", orderItem.Price,
+		"activated", activate,
+		"fully_paid", fullyPaid)
+
+	if activate {
+		if err := h.startPolicyMonitoring(registeredPolicyID, orderItem.ItemID); err != nil {
+			slog.Error("failed to start policy monitoring (payment still successful)",
+				"policy_id", registeredPolicyID,
+				"error", err)
+		}
+	}
+	return nil
+}
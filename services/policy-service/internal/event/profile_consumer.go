@@ -1,6 +1,7 @@
 package event
 
 import (
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -26,6 +27,9 @@ const (
 	ProfilePendingDelete = "pending_delete"
 	ProfileCancelDelete  = "delete_cancelled"
 	ProfleConfirmDelete  = "confirm_delete"
+	PartnerSuspended     = "partner_suspended"
+	PartnerReinstated    = "partner_reinstated"
+	PartnerTerminated    = "partner_terminated"
 )
 
 type ProfileEvent struct {
@@ -166,7 +170,8 @@ func (c *ProfileConsumer) startConsumerLoop(ctx context.Context) error {
 				slog.Warn("Profile consumer channel closed")
 				return fmt.Errorf("message channel closed")
 			}
-			c.processMessage(ctx, msg)
+			// Messages keep the request ID they were published under
+			c.processMessage(logging.FromMessageHeaders(ctx, msg.Headers), msg)
 		}
 	}
 }
@@ -178,21 +183,21 @@ func (c *ProfileConsumer) processMessage(ctx context.Context, msg amqp.Delivery)
 
 	var event ProfileEvent
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		slog.Error("failed to unmarshal profile event", "error", err)
+		slog.ErrorContext(ctx, "failed to unmarshal profile event", "error", err)
 		c.messagesFailed++
 		// Reject the message and don't requeue (malformed message)
 		msg.Nack(false, false)
 		return
 	}
 
-	slog.Info("Received profile event",
+	slog.InfoContext(ctx, "Received profile event",
 		"event_id", event.ID,
 		"user_id", event.UserID,
 		"profile_id", event.ProfileID,
 	)
 
 	if err := c.handler.HandleProfileEvent(processCtx, event); err != nil {
-		slog.Error("failed to handle profile event",
+		slog.ErrorContext(ctx, "failed to handle profile event",
 			"event_id", event.ID,
 			"error", err,
 		)
@@ -213,7 +218,7 @@ func (c *ProfileConsumer) processMessage(ctx context.Context, msg amqp.Delivery)
 	msg.Ack(false)
 	c.messagesProcessed++
 	c.lastMessageTime = time.Now()
-	slog.Info("Profile event processed successfully", "event_id", event.ID)
+	slog.InfoContext(ctx, "Profile event processed successfully", "event_id", event.ID)
 }
 
 type DefaultProfileEventHandler struct {
@@ -260,6 +265,12 @@ func (h *DefaultProfileEventHandler) HandleProfileEvent(ctx context.Context, eve
 		return h.handleProfileConfirmDelete(ctx, event)
 	case ProfileCancelDelete:
 		return h.handleProfileCancelDelete(ctx, event)
+	case PartnerSuspended:
+		return h.handlePartnerSuspended(ctx, event)
+	case PartnerReinstated:
+		return h.handlePartnerReinstated(ctx, event)
+	case PartnerTerminated:
+		return h.handlePartnerTerminated(ctx, event)
 	default:
 		return &PaymentValidationError{
 			PaymentID: event.ID,
@@ -342,3 +353,46 @@ func (h *DefaultProfileEventHandler) handleProfileCancelDelete(ctx context.Conte
 	h.redisClient.Del(ctx, fmt.Sprintf("Delete-Profile-%s", event.ProfileID))
 	return nil
 }
+
+// handlePartnerSuspended pauses new registrations on the base policies of a suspended partner;
+// existing policies keep running
+func (h *DefaultProfileEventHandler) handlePartnerSuspended(ctx context.Context, event ProfileEvent) error {
+	slog.Info("Partner suspended event", "event", event)
+	if err := h.redisClient.Set(ctx, fmt.Sprintf("Suspended-Profile-%s", event.ProfileID), "true", 0).Err(); err != nil {
+		return fmt.Errorf("failed to pause registrations of partner %s: %w", event.ProfileID, err)
+	}
+	return nil
+}
+
+func (h *DefaultProfileEventHandler) handlePartnerReinstated(ctx context.Context, event ProfileEvent) error {
+	slog.Info("Partner reinstated event", "event", event)
+	if err := h.redisClient.Del(ctx, fmt.Sprintf("Suspended-Profile-%s", event.ProfileID)).Err(); err != nil {
+		return fmt.Errorf("failed to resume registrations of partner %s: %w", event.ProfileID, err)
+	}
+	return nil
+}
+
+// handlePartnerTerminated stops registrations for good and closes the partner's active base policies
+func (h *DefaultProfileEventHandler) handlePartnerTerminated(ctx context.Context, event ProfileEvent) error {
+	slog.Info("Partner terminated event", "event", event)
+	if err := h.redisClient.Set(ctx, fmt.Sprintf("Suspended-Profile-%s", event.ProfileID), "true", 0).Err(); err != nil {
+		return fmt.Errorf("failed to pause registrations of partner %s: %w", event.ProfileID, err)
+	}
+
+	basePolicies, err := h.basePolicyRepo.GetBasePoliciesByProvider(event.ProfileID)
+	if err != nil {
+		return err
+	}
+	basePolicyIDs := []uuid.UUID{}
+	for _, basePolicy := range basePolicies {
+		if basePolicy.Status == models.BasePolicyActive {
+			basePolicyIDs = append(basePolicyIDs, basePolicy.ID)
+		}
+	}
+	res, err := h.basePolicyRepo.BulkUpdateBasePolicyStatus(basePolicyIDs, models.BasePolicyClosed)
+	if err != nil {
+		return err
+	}
+	slog.Info("closed all base policy of terminated partner", "count", res)
+	return nil
+}
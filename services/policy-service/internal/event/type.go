@@ -1,5 +1,7 @@
 package event
 
+import "time"
+
 // NotificationEventPushModel matches SendPayloadDto from noti-service
 // TypeScript interface: { lstUserIds?: string[], title: string, body: string, data?: any }
 type NotificationEventPushModel struct {
@@ -16,3 +18,24 @@ type Notification struct {
 }
 
 const PushNotiQueue string = "push_noti_events"
+
+// NotificationsQueue is consumed by notification-service
+const NotificationsQueue string = "notifications"
+
+// Notification types understood by notification-service
+const (
+	NotificationTypeSMS   = "sms"
+	NotificationTypeInApp = "in_app"
+)
+
+// NotificationMessage matches NotificationMessage of notification-service
+type NotificationMessage struct {
+	ID          string         `json:"id"`
+	Type        string         `json:"type"`
+	Priority    int            `json:"priority"`
+	RecipientID string         `json:"recipient_id"`
+	Payload     map[string]any `json:"payload"`
+	RetryCount  int            `json:"retry_count"`
+	MaxRetries  int            `json:"max_retries"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Months reported by the monthly summary when no range is given, the current one included
+const defaultAIUsageMonths = 12
+
+type AIUsageHandler struct {
+	aiUsageService *services.AIUsageService
+}
+
+func NewAIUsageHandler(aiUsageService *services.AIUsageService) *AIUsageHandler {
+	return &AIUsageHandler{aiUsageService: aiUsageService}
+}
+
+func (h *AIUsageHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	aiUsageGr := protectedGr.Group("/ai-usage")
+
+	// Admin routes
+	adminGr := aiUsageGr.Group("/read-all", RequireRoles(RolePlatformAdmin))
+	adminGr.Get("/monthly", h.GetMonthlyUsage)    // GET /ai-usage/read-all/monthly?from=2026-01&to=2026-06&insurance_provider_id=...
+	adminGr.Get("/providers", h.GetProviderUsage) // GET /ai-usage/read-all/providers?from=2026-06&to=2026-06
+}
+
+// GetMonthlyUsage sums the AI tokens and estimated cost per month, of one insurance provider
+// when insurance_provider_id is given. The range defaults to the last 12 months.
+func (h *AIUsageHandler) GetMonthlyUsage(c fiber.Ctx) error {
+	now := time.Now()
+	defaultFrom := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 1-defaultAIUsageMonths, 0)
+
+	filter, err := parseUsageFilter(c, defaultFrom)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_MONTH", err.Error()))
+	}
+
+	summaries, err := h.aiUsageService.MonthlyUsage(c.Context(), filter)
+	if err != nil {
+		return aiUsageError(c, "Failed to get monthly AI usage", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(summaries))
+}
+
+// GetProviderUsage sums the AI tokens and estimated cost per insurance provider. The range
+// defaults to the current month.
+func (h *AIUsageHandler) GetProviderUsage(c fiber.Ctx) error {
+	now := time.Now()
+	defaultFrom := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	filter, err := parseUsageFilter(c, defaultFrom)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_MONTH", err.Error()))
+	}
+
+	summaries, err := h.aiUsageService.ProviderUsage(c.Context(), filter)
+	if err != nil {
+		return aiUsageError(c, "Failed to get AI usage per provider", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(summaries))
+}
+
+// parseUsageFilter reads the from and to months (YYYY-MM, both included) and the provider of a
+// usage query; to defaults to the current month
+func parseUsageFilter(c fiber.Ctx, defaultFrom time.Time) (models.AIUsageFilter, error) {
+	now := time.Now()
+	filter := models.AIUsageFilter{
+		InsuranceProviderID: strings.TrimSpace(c.Query("insurance_provider_id")),
+		From:                defaultFrom,
+	}
+	toMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.ParseInLocation("2006-01", fromStr, now.Location())
+		if err != nil {
+			return filter, fmt.Errorf("from must be a month formatted as YYYY-MM")
+		}
+		filter.From = from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.ParseInLocation("2006-01", toStr, now.Location())
+		if err != nil {
+			return filter, fmt.Errorf("to must be a month formatted as YYYY-MM")
+		}
+		toMonth = to
+	}
+	filter.To = toMonth.AddDate(0, 1, 0)
+	return filter, nil
+}
+
+func aiUsageError(c fiber.Ctx, message string, err error) error {
+	if strings.Contains(err.Error(), "invalid") {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	slog.Error(message, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", message))
+}
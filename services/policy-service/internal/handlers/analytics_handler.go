@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Months covered by a series when no range is given, the current one included
+const defaultAnalyticsSeriesMonths = 12
+
+type AnalyticsHandler struct {
+	analyticsService        *services.AnalyticsService
+	registeredPolicyService *services.RegisteredPolicyService
+}
+
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService, registeredPolicyService *services.RegisteredPolicyService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService:        analyticsService,
+		registeredPolicyService: registeredPolicyService,
+	}
+}
+
+func (h *AnalyticsHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	analyticsGr := protectedGr.Group("/analytics")
+
+	// Partner routes - the portfolio of the caller's provider
+	partnerGr := analyticsGr.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
+	partnerGr.Get("/portfolio", h.GetPartnerPortfolio) // GET /analytics/read-partner/portfolio
+	partnerGr.Get("/series", h.GetPartnerSeries)       // GET /analytics/read-partner/series?bucket=month|quarter|year&from=2026-01&to=2026-06
+
+	// Admin routes - the whole platform, or one provider with insurance_provider_id
+	adminGr := analyticsGr.Group("/read-all", RequireRoles(RolePlatformAdmin))
+	adminGr.Get("/portfolio", h.GetPortfolio) // GET /analytics/read-all/portfolio?insurance_provider_id=...
+	adminGr.Get("/series", h.GetSeries)       // GET /analytics/read-all/series?insurance_provider_id=...&bucket=...&from=...&to=...
+}
+
+func (h *AnalyticsHandler) GetPartnerPortfolio(c fiber.Ctx) error {
+	partnerID, err := partnerIDOf(h.registeredPolicyService)(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if err != nil {
+		slog.Error("Failed to get partner ID from token", "error", err)
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "Failed to resolve insurance partner"))
+	}
+	return h.portfolio(c, partnerID)
+}
+
+func (h *AnalyticsHandler) GetPortfolio(c fiber.Ctx) error {
+	return h.portfolio(c, strings.TrimSpace(c.Query("insurance_provider_id")))
+}
+
+func (h *AnalyticsHandler) GetPartnerSeries(c fiber.Ctx) error {
+	partnerID, err := partnerIDOf(h.registeredPolicyService)(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if err != nil {
+		slog.Error("Failed to get partner ID from token", "error", err)
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "Failed to resolve insurance partner"))
+	}
+	return h.series(c, partnerID)
+}
+
+func (h *AnalyticsHandler) GetSeries(c fiber.Ctx) error {
+	return h.series(c, strings.TrimSpace(c.Query("insurance_provider_id")))
+}
+
+func (h *AnalyticsHandler) portfolio(c fiber.Ctx, providerID string) error {
+	summary, err := h.analyticsService.GetPortfolioSummary(c.Context(), providerID)
+	if err != nil {
+		return analyticsError(c, "Failed to get portfolio summary", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(summary))
+}
+
+func (h *AnalyticsHandler) series(c fiber.Ctx, providerID string) error {
+	req, err := parseSeriesRequest(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	req.ProviderID = providerID
+
+	series, err := h.analyticsService.GetSeries(c.Context(), req)
+	if err != nil {
+		return analyticsError(c, "Failed to get analytics series", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(series))
+}
+
+// parseSeriesRequest reads the bucket (month by default) and the from and to months (YYYY-MM,
+// both included) of a series query; the range defaults to the last 12 months
+func parseSeriesRequest(c fiber.Ctx) (models.AnalyticsSeriesRequest, error) {
+	now := time.Now().UTC()
+	toMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	req := models.AnalyticsSeriesRequest{
+		Bucket: models.AnalyticsBucket(c.Query("bucket", string(models.AnalyticsBucketMonth))),
+		From:   toMonth.AddDate(0, 1-defaultAnalyticsSeriesMonths, 0),
+	}
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse("2006-01", fromStr)
+		if err != nil {
+			return req, fmt.Errorf("from must be a month formatted as YYYY-MM")
+		}
+		req.From = from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse("2006-01", toStr)
+		if err != nil {
+			return req, fmt.Errorf("to must be a month formatted as YYYY-MM")
+		}
+		toMonth = to
+	}
+	req.To = toMonth.AddDate(0, 1, 0)
+	return req, nil
+}
+
+func analyticsError(c fiber.Ctx, message string, err error) error {
+	if strings.Contains(err.Error(), "invalid") {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	slog.Error(message, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", message))
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 200
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+func (h *AuditHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	auditGr := protectedGr.Group("/audit")
+
+	// Admin routes
+	adminGr := auditGr.Group("/read-all", RequireRoles(RolePlatformAdmin))
+	adminGr.Get("/", h.GetAuditLogs) // GET /audit/read-all?entity=base_policy&id=...
+}
+
+// GetAuditLogs lists the recorded changes of base or registered policies, most recent first
+func (h *AuditHandler) GetAuditLogs(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	filter := models.AuditLogFilter{
+		EntityType: models.AuditEntityType(c.Query("entity")),
+		ActorID:    c.Query("actor"),
+		Limit:      defaultAuditLogLimit,
+	}
+	switch filter.EntityType {
+	case models.AuditEntityBasePolicy, models.AuditEntityRegisteredPolicy, models.AuditEntityFarm:
+	default:
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_ENTITY", "entity must be base_policy, registered_policy or farm"))
+	}
+
+	if idStr := c.Query("id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_UUID", "Invalid entity ID format"))
+		}
+		filter.EntityID = &id
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_LIMIT", "limit must be a positive number"))
+		}
+		filter.Limit = min(limit, maxAuditLogLimit)
+	}
+
+	entries, err := h.auditService.List(c.Context(), filter)
+	if err != nil {
+		slog.Error("Failed to list audit logs", "entity", filter.EntityType, "entity_id", filter.EntityID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve audit logs"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"entries": entries,
+		"count":   len(entries),
+	}))
+}
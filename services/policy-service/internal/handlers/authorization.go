@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles issued by auth-service
+const (
+	RoleFarmer        = "farmer"
+	RoleInsurerAdmin  = "admin_partner"
+	RolePlatformAdmin = "admin"
+)
+
+const (
+	tokenIssuer                = "auth-service"
+	scopeTypeInsuranceProvider = "insurance_provider"
+	principalLocalsKey         = "principal"
+)
+
+// roleScope is a role held within a scope, e.g. admin_partner within one insurance provider
+type roleScope struct {
+	ScopeType string   `json:"scope_type"`
+	ScopeID   string   `json:"scope_id"`
+	Roles     []string `json:"roles"`
+}
+
+// tokenClaims is the part of the auth-service claims policy-service reads
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	UserID          string
+	Roles           []string    `json:",omitempty"`
+	Scopes          []roleScope `json:",omitempty"`
+	ImpersonationID string      `json:",omitempty"`
+}
+
+// Principal is the caller of a protected route
+type Principal struct {
+	UserID   string
+	Roles    []string
+	Scopes   []roleScope
+	Internal bool // another service, authenticated with the API key
+}
+
+// HasRole reports whether the caller holds any of the roles, unscoped or within a scope
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+		for _, scope := range p.Scopes {
+			if slices.Contains(scope.Roles, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProviderIDs returns the insurance providers the caller administers according to its token
+func (p *Principal) ProviderIDs() []string {
+	var ids []string
+	for _, scope := range p.Scopes {
+		if scope.ScopeType == scopeTypeInsuranceProvider && slices.Contains(scope.Roles, RoleInsurerAdmin) {
+			ids = append(ids, scope.ScopeID)
+		}
+	}
+	return ids
+}
+
+// AuthMiddleware authenticates the callers of protected routes. The gateway has already
+// checked the session with auth-service; the token is verified again here so that a request
+// reaching the service directly cannot claim any user through X-User-ID.
+type AuthMiddleware struct {
+	jwtSecret []byte
+	apiKey    string
+}
+
+func NewAuthMiddleware(jwtSecret, apiKey string) *AuthMiddleware {
+	return &AuthMiddleware{
+		jwtSecret: []byte(jwtSecret),
+		apiKey:    apiKey,
+	}
+}
+
+// Authenticate verifies the bearer token, sets X-User-ID to its user and stores the caller for
+// RequireRoles. Services calling with the API key are trusted with the X-User-ID they send.
+func (m *AuthMiddleware) Authenticate(c fiber.Ctx) error {
+	if m.apiKey != "" && c.Get("API-KEY") == m.apiKey {
+		c.Locals(principalLocalsKey, &Principal{UserID: c.Get("X-User-ID"), Internal: true})
+		return c.Next()
+	}
+
+	tokenString := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+	if tokenString == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("MISSING_TOKEN", "Authorization token is required"))
+	}
+
+	claims, err := m.verifyToken(tokenString)
+	if err != nil {
+		slog.Warn("Rejected request with invalid token", "path", c.Path(), "error", err)
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("INVALID_TOKEN", "Token validation failed"))
+	}
+
+	// The gateway sets X-User-ID from this same token, any other value was not set by it
+	if userID := c.Get("X-User-ID"); userID != "" && userID != claims.UserID {
+		slog.Warn("Rejected request with X-User-ID not matching its token",
+			"path", c.Path(),
+			"header_user_id", userID,
+			"token_user_id", claims.UserID)
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("INVALID_TOKEN", "Token does not belong to the user of the request"))
+	}
+	c.Request().Header.Set("X-User-ID", claims.UserID)
+
+	c.Locals(principalLocalsKey, &Principal{
+		UserID: claims.UserID,
+		Roles:  claims.Roles,
+		Scopes: claims.Scopes,
+	})
+	return c.Next()
+}
+
+func (m *AuthMiddleware) verifyToken(tokenString string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims,
+		func(token *jwt.Token) (any, error) {
+			return m.jwtSecret, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.UserID == "" {
+		return nil, fmt.Errorf("token has no user")
+	}
+	return claims, nil
+}
+
+// RequireRoles lets through callers holding any of the roles, and other services
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := principalFrom(c)
+		if principal == nil {
+			return c.Status(http.StatusUnauthorized).JSON(
+				utils.CreateErrorResponse("UNAUTHORIZED", "Authentication is required"))
+		}
+		if principal.Internal || principal.HasRole(roles...) {
+			return c.Next()
+		}
+
+		slog.Warn("Rejected request from user without the required role",
+			"path", c.Path(),
+			"user_id", principal.UserID,
+			"required_roles", roles,
+			"user_roles", principal.Roles)
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to access this resource"))
+	}
+}
+
+func principalFrom(c fiber.Ctx) *Principal {
+	principal, _ := c.Locals(principalLocalsKey).(*Principal)
+	return principal
+}
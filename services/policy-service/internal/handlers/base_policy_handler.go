@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"encoding/base64"
+	"agrisa_utils/logging"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -24,14 +24,20 @@ type BasePolicyHandler struct {
 	minioClient             *minio.MinioClient
 	workerManager           *worker.WorkerManagerV2
 	registeredPolicyService *services.RegisteredPolicyService
+	documentUploadService   *services.PolicyDocumentUploadService
+	documentScanService     *services.DocumentScanService
+	rateLimiter             *services.RateLimiter
 }
 
-func NewBasePolicyHandler(basePolicyService *services.BasePolicyService, minioClient *minio.MinioClient, workerManager *worker.WorkerManagerV2, registeredPolicyService *services.RegisteredPolicyService) *BasePolicyHandler {
+func NewBasePolicyHandler(basePolicyService *services.BasePolicyService, minioClient *minio.MinioClient, workerManager *worker.WorkerManagerV2, registeredPolicyService *services.RegisteredPolicyService, documentUploadService *services.PolicyDocumentUploadService, documentScanService *services.DocumentScanService, rateLimiter *services.RateLimiter) *BasePolicyHandler {
 	return &BasePolicyHandler{
 		basePolicyService:       basePolicyService,
 		minioClient:             minioClient,
 		workerManager:           workerManager,
 		registeredPolicyService: registeredPolicyService,
+		documentUploadService:   documentUploadService,
+		documentScanService:     documentScanService,
+		rateLimiter:             rateLimiter,
 	}
 }
 
@@ -40,25 +46,32 @@ func (bph *BasePolicyHandler) Register(app *fiber.App) {
 
 	// Base Policy routes - Business Process Endpoints
 	policyGroup := protectedGr.Group("/base-policies")
+	partnerOnly := RequireRoles(RoleInsurerAdmin, RolePlatformAdmin)
+	adminOnly := RequireRoles(RolePlatformAdmin)
 
 	// Core business process operations
-	policyGroup.Post("/complete", bph.CreateCompletePolicy)                        // POST /base-policies/complete - Create complete policy in Redis
-	policyGroup.Get("/draft/provider/:providerID", bph.GetDraftPoliciesByProvider) // GET  /base-policies/draft/provider/{id} - Get provider's draft policies
-	policyGroup.Get("/draft/filter", bph.GetDraftPoliciesWithFilter)               // GET  /base-policies/draft/filter - Get policies with flexible filters
-	policyGroup.Post("/validate", bph.ValidatePolicy)                              // POST /base-policies/validate - Validate policy & auto-commit
-	policyGroup.Post("/commit", bph.CommitPolicies)                                // POST /base-policies/commit - Manual commit policies to DB
+	policyGroup.Post("/complete", partnerOnly, RateLimited(bph.rateLimiter, services.RateLimitGroupAIValidation, bph.CreateCompletePolicy)) // POST /base-policies/complete - Create complete policy in Redis and send it to AI validation
+
+	policyGroup.Get("/draft/provider/:providerID", partnerOnly, bph.GetDraftPoliciesByProvider) // GET  /base-policies/draft/provider/{id} - Get provider's draft policies
+	policyGroup.Get("/draft/filter", adminOnly, bph.GetDraftPoliciesWithFilter)                 // GET  /base-policies/draft/filter - Get policies with flexible filters
+	policyGroup.Post("/validate", adminOnly, bph.ValidatePolicy)                                // POST /base-policies/validate - Validate policy & auto-commit
+	policyGroup.Post("/commit", adminOnly, bph.CommitPolicies)                                  // POST /base-policies/commit - Manual commit policies to DB
 	policyGroup.Get("/active", bph.GetAllActivePolicy)
 	policyGroup.Get("/all", bph.GetAllBasePolicies)         // GET /base-policies/all - Get all base policies
-	policyGroup.Get("/detail", bph.GetCompletePolicyDetail) // GET  /base-policies/detail - Get complete policy details with PDF
+	policyGroup.Get("/detail", bph.GetCompletePolicyDetail) // GET  /base-policies/detail - Get complete policy details with PDF (?bypass_cache=true skips the cache)
 	policyGroup.Get("/by-provider", bph.GetByProvider)
-	policyGroup.Put("/cancel/:id", bph.CancelBasePolicy)
+	policyGroup.Put("/cancel/:id", RequireRoles(RoleInsurerAdmin), bph.CancelBasePolicy)
 
 	// Utility routes
-	policyGroup.Get("/count", bph.GetBasePolicyCount)                                 // GET  /base-policies/count - Total policy count
-	policyGroup.Get("/count/status/:status", bph.GetBasePolicyCountByStatus)          // GET  /base-policies/count/status/{status} - Count by status
-	policyGroup.Patch("/:id/validation-status", bph.UpdateBasePolicyValidationStatus) // PATCH /base-policies/{id}/validation-status - Update validation
+	policyGroup.Get("/count", bph.GetBasePolicyCount)                                            // GET  /base-policies/count - Total policy count
+	policyGroup.Get("/count/status/:status", bph.GetBasePolicyCountByStatus)                     // GET  /base-policies/count/status/{status} - Count by status
+	policyGroup.Patch("/:id/validation-status", adminOnly, bph.UpdateBasePolicyValidationStatus) // PATCH /base-policies/{id}/validation-status - Update validation
+
+	// Versions of the terms
+	policyGroup.Get("/:id/versions", bph.GetPolicyVersions)       // GET  /base-policies/{id}/versions - Versions, newest first
+	policyGroup.Get("/:id/versions/diff", bph.DiffPolicyVersions) // GET  /base-policies/{id}/versions/diff?from=1&to=2 - Field-level diff
 
-	policyManagementGroup := protectedGr.Group("/base-policies-management")
+	policyManagementGroup := protectedGr.Group("/base-policies-management", adminOnly)
 	policyManagementGroup.Get("/base-policies/complete-response", bph.GetAllCompletePolicyCreations)
 }
 
@@ -123,6 +136,19 @@ func (bph *BasePolicyHandler) CreateCompletePolicy(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("VALIDATION_FAILED", err.Error()))
 	}
+	if err := authorizeProvider(c, req.BasePolicy.InsuranceProviderID, partnerIDOf(bph.registeredPolicyService)); err != nil {
+		return ownershipError(c, err)
+	}
+
+	// The PDF was uploaded to MinIO beforehand, the request only refers to it
+	upload, err := bph.documentUploadService.GetConfirmedUpload(c.Context(), req.PolicyDocument.ObjectKey, createdBy)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("INVALID_POLICY_DOCUMENT", err.Error()))
+		}
+		slog.Error("Failed to get policy document upload", "object_key", req.PolicyDocument.ObjectKey, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", "Failed to get policy document upload"))
+	}
 
 	response, err := bph.basePolicyService.CreateCompletePolicy(c.Context(), &req, expiration)
 	if err != nil {
@@ -130,20 +156,12 @@ func (bph *BasePolicyHandler) CreateCompletePolicy(c fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("CREATION_FAILED", err.Error()))
 	}
 
-	// Decode base64 PDF data before uploading
 	pathName := response.FilePath
-	pdfData, err := base64.StdEncoding.DecodeString(req.PolicyDocument.Data)
+	err = bph.documentUploadService.PublishUpload(c.Context(), upload, pathName, response.BasePolicyID)
 	if err != nil {
-		slog.Error("Failed to decode base64 PDF data",
-			"base_policy_id", response.BasePolicyID,
-			"error", err)
-		return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("INVALID_PDF_DATA", "Failed to decode base64 PDF data"))
-	}
-
-	err = bph.minioClient.UploadBytes(c.Context(), minio.Storage.PolicyDocuments, pathName, pdfData, "application/pdf")
-	if err != nil {
-		slog.Error("Failed to upload PDF to MinIO",
+		slog.Error("Failed to move policy document to its template path",
 			"base_policy_id", response.BasePolicyID,
+			"upload_id", upload.ID,
 			"path", pathName,
 			"error", err)
 		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("FILE_UPLOAD_FAILED", err.Error()))
@@ -152,20 +170,30 @@ func (bph *BasePolicyHandler) CreateCompletePolicy(c fiber.Ctx) error {
 	slog.Info("Successfully uploaded policy document",
 		"base_policy_id", response.BasePolicyID,
 		"path", pathName,
-		"size_bytes", len(pdfData))
+		"upload_id", upload.ID)
 	// send job to AI
 	job := worker.JobPayload{
-		JobID:      uuid.NewString(),
-		Type:       "document-validation",
-		Params:     map[string]any{"fileName": pathName, "base_policy_id": response.BasePolicyID},
-		MaxRetries: 100,
-		OneTime:    true,
-	}
-	scheduler, ok := bph.workerManager.GetSchedulerByPolicyID(*worker.AIWorkerPoolUUID)
-	if !ok {
-		slog.Error("error get AI scheduler", "error", "scheduler doesn't exist")
+		JobID:       uuid.NewString(),
+		Type:        "document-validation",
+		Params:      map[string]any{"fileName": pathName, "base_policy_id": response.BasePolicyID},
+		MaxRetries:  100,
+		OneTime:     true,
+		RequestID:   logging.RequestID(c.Context()),
+		SubmittedBy: createdBy,
+		Result:      &worker.JobResultRef{Resource: "base_policy_validation", ID: response.BasePolicyID.String()},
+	}
+	// The AI only reads the document once it passed the content scan
+	scan, err := bph.documentScanService.QueueScan(c.Context(), models.DocumentScanPolicyDocument,
+		minio.Storage.PolicyDocuments, pathName, &response.BasePolicyID, &job)
+	if err != nil {
+		slog.Error("Failed to queue policy document scan",
+			"base_policy_id", response.BasePolicyID,
+			"path", pathName,
+			"error", err)
+		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("DOCUMENT_SCAN_FAILED", err.Error()))
 	}
-	scheduler.AddJob(job)
+	response.ValidationJobID = job.JobID
+	response.DocumentScanID = &scan.ID
 
 	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(response))
 }
@@ -176,11 +204,17 @@ func (bph *BasePolicyHandler) GetDraftPoliciesByProvider(c fiber.Ctx) error {
 	if providerID == "" {
 		return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("INVALID_PARAMETER", "Provider ID is required"))
 	}
+	if err := authorizeProvider(c, providerID, partnerIDOf(bph.registeredPolicyService)); err != nil {
+		return ownershipError(c, err)
+	}
 
 	archiveStatus := c.Query("archive_status", "false") // Default to non-archived
 
 	policies, err := bph.basePolicyService.GetAllDraftPolicyWFilter(c.Context(), providerID, "", archiveStatus)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("INVALID_PARAMETERS", err.Error()))
+		}
 		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
 	}
 
@@ -205,6 +239,9 @@ func (bph *BasePolicyHandler) GetDraftPoliciesWithFilter(c fiber.Ctx) error {
 
 	policies, err := bph.basePolicyService.GetAllDraftPolicyWFilter(c.Context(), providerID, basePolicyID, archiveStatus)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("INVALID_PARAMETERS", err.Error()))
+		}
 		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
 	}
 
@@ -436,6 +473,8 @@ func (bph *BasePolicyHandler) GetCompletePolicyDetail(c fiber.Ctx) error {
 	// Parse boolean parameters
 	includePDFParam := c.Query("include_pdf", "true")
 	filter.IncludePDF = includePDFParam != "false" && includePDFParam != "0"
+	bypassCacheParam := c.Query("bypass_cache")
+	filter.BypassCache = bypassCacheParam == "true" || bypassCacheParam == "1"
 
 	// Parse PDF expiry hours (default 24)
 	filter.PDFExpiryHours = 24 // Default 24 hours
@@ -464,6 +503,7 @@ func (bph *BasePolicyHandler) GetCompletePolicyDetail(c fiber.Ctx) error {
 			utils.CreateErrorResponse("RETRIEVAL_FAILED",
 				fmt.Sprintf("Failed to retrieve policy details: %v", err)))
 	}
+	bph.basePolicyService.AttachInsuranceProvider(c.Context(), detail)
 
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(detail))
 }
@@ -562,6 +602,18 @@ func (bph *BasePolicyHandler) CancelBasePolicy(c fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL", "profile data not fould"))
 	}
 
+	basePolicy, err := bph.basePolicyService.GetByID(basePolicyID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(http.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", "Base policy not found"))
+		}
+		slog.Error("Failed to get base policy", "base_policy_id", basePolicyID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve base policy"))
+	}
+	if basePolicy.InsuranceProviderID != partnerID {
+		return c.Status(http.StatusForbidden).JSON(utils.CreateErrorResponse("FORBIDDEN", "Cannot cancel the policies of another provider"))
+	}
+
 	providerID := partnerID
 	res, err := bph.basePolicyService.CancelBasePolicy(c.Context(), basePolicyID, providerID, keepRegisterPolicy)
 	if err != nil {
@@ -579,3 +631,59 @@ func (bph *BasePolicyHandler) GetAllBasePolicies(c fiber.Ctx) error {
 	}
 	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(basePolicies))
 }
+
+// ============================================================================
+// VERSIONS
+// ============================================================================
+
+// GetPolicyVersions lists the versions of the terms of a base policy
+func (bph *BasePolicyHandler) GetPolicyVersions(c fiber.Ctx) error {
+	basePolicyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid id format"))
+	}
+
+	versions, err := bph.basePolicyService.GetPolicyVersions(c.Context(), basePolicyID)
+	if err != nil {
+		slog.Error("Failed to list base policy versions", "base_policy_id", basePolicyID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve base policy versions"))
+	}
+	if len(versions) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", "Base policy not found"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(versions))
+}
+
+// DiffPolicyVersions returns the fields of the terms that differ between the from and to versions
+func (bph *BasePolicyHandler) DiffPolicyVersions(c fiber.Ctx) error {
+	basePolicyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid id format"))
+	}
+	fromVersion, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "from must be a version number"))
+	}
+	toVersion, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "to must be a version number"))
+	}
+
+	diff, err := bph.basePolicyService.DiffPolicyVersions(c.Context(), basePolicyID, fromVersion, toVersion)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+		}
+		slog.Error("Failed to diff base policy versions", "base_policy_id", basePolicyID,
+			"from", fromVersion, "to", toVersion, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to diff base policy versions"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(diff))
+}
@@ -36,19 +36,18 @@ func (h *CancelRequestHandler) Register(app *fiber.App) {
 	cancelRequestGr.Put("/compensation-amount/:id", h.GetCompensationAmount)
 	cancelRequestGr.Post("/revoke/:id", h.RevokeRequest)
 
-	farmerGr := cancelRequestGr.Group("/read-own")
+	farmerGr := cancelRequestGr.Group("/read-own", RequireRoles(RoleFarmer, RolePlatformAdmin))
 	farmerGr.Get("/me", h.GetAllMyRequests)
 	farmerGr.Get("/transfer", h.GetLastestTranser)
 
-	partnerGroup := cancelRequestGr.Group("/read-partner")
+	partnerGroup := cancelRequestGr.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
 	partnerGroup.Get("/own", h.GetAllPartnerRequest)
 }
 
 func (h *CancelRequestHandler) GetAllMyRequests(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 	requests, err := h.cancelRequestService.GetAllFarmerCancelRequest(c.Context(), userID)
 	if err != nil {
@@ -65,10 +64,9 @@ func (h *CancelRequestHandler) GetAllMyRequests(c fiber.Ctx) error {
 }
 
 func (h *CancelRequestHandler) GetLastestTranser(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 	policyIDStr := c.Query("policy_id")
 	policyID, err := uuid.Parse(policyIDStr)
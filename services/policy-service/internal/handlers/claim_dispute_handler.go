@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type ClaimDisputeHandler struct {
+	disputeService          *services.ClaimDisputeService
+	registeredPolicyService *services.RegisteredPolicyService
+}
+
+func NewClaimDisputeHandler(disputeService *services.ClaimDisputeService, registeredPolicyService *services.RegisteredPolicyService) *ClaimDisputeHandler {
+	return &ClaimDisputeHandler{
+		disputeService:          disputeService,
+		registeredPolicyService: registeredPolicyService,
+	}
+}
+
+func (h *ClaimDisputeHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	disputeGr := protectedGr.Group("/claim-disputes")
+	farmerOnly := RequireRoles(RoleFarmer, RolePlatformAdmin)
+	adminOnly := RequireRoles(RolePlatformAdmin)
+	participants := RequireRoles(RoleFarmer, RoleInsurerAdmin, RolePlatformAdmin)
+
+	// Farmer routes - open and follow their own disputes
+	disputeGr.Post("/create-own/:claim_id", farmerOnly, h.OpenDispute)           // POST /claim-disputes/create-own/:claim_id
+	disputeGr.Get("/read-own/list", farmerOnly, h.ListOwnDisputes)               // GET  /claim-disputes/read-own/list
+	disputeGr.Post("/:id/escalate", RequireRoles(RoleFarmer), h.EscalateDispute) // POST /claim-disputes/:id/escalate - Send to arbitration before the partner deadline
+	disputeGr.Post("/:id/withdraw", RequireRoles(RoleFarmer), h.WithdrawDispute) // POST /claim-disputes/:id/withdraw
+
+	// Partner routes - disputes of the provider's claims
+	disputeGr.Get("/read-partner/list", RequireRoles(RoleInsurerAdmin), h.ListPartnerDisputes) // GET /claim-disputes/read-partner/list
+
+	// Admin routes - arbitration
+	disputeGr.Get("/read-all/list", adminOnly, h.ListAllDisputes) // GET  /claim-disputes/read-all/list?status=...&overdue=true
+	disputeGr.Post("/:id/resolve", adminOnly, h.ResolveDispute)   // POST /claim-disputes/:id/resolve
+
+	// Participant routes - the farmer, the partner and platform admins
+	disputeGr.Get("/:id", participants, h.GetDispute)                                     // GET  /claim-disputes/:id - With thread and evidence
+	disputeGr.Post("/:id/messages", participants, h.PostMessage)                          // POST /claim-disputes/:id/messages
+	disputeGr.Post("/:id/evidence", participants, h.CreateEvidence)                       // POST /claim-disputes/:id/evidence - Returns a presigned upload URL
+	disputeGr.Post("/:id/evidence/:evidence_id/confirm", participants, h.ConfirmEvidence) // POST /claim-disputes/:id/evidence/:evidence_id/confirm
+}
+
+func (h *ClaimDisputeHandler) OpenDispute(c fiber.Ctx) error {
+	claimID, err := uuid.Parse(c.Params("claim_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid claim ID format"))
+	}
+	farmerID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
+	var req models.OpenClaimDisputeRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	dispute, err := h.disputeService.OpenDispute(c.Context(), claimID, farmerID, req)
+	if err != nil {
+		return disputeError(c, "Failed to open claim dispute", err)
+	}
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(dispute))
+}
+
+func (h *ClaimDisputeHandler) ListOwnDisputes(c fiber.Ctx) error {
+	farmerID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
+	disputes, err := h.disputeService.ListFarmerDisputes(c.Context(), farmerID)
+	if err != nil {
+		return disputeError(c, "Failed to list claim disputes", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"disputes": disputes,
+		"count":    len(disputes),
+	}))
+}
+
+func (h *ClaimDisputeHandler) ListPartnerDisputes(c fiber.Ctx) error {
+	partnerID, err := partnerIDOf(h.registeredPolicyService)(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if err != nil {
+		slog.Error("Failed to get partner ID from token", "error", err)
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "Failed to resolve insurance partner"))
+	}
+
+	disputes, err := h.disputeService.ListProviderDisputes(c.Context(), partnerID)
+	if err != nil {
+		return disputeError(c, "Failed to list claim disputes", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"disputes": disputes,
+		"count":    len(disputes),
+	}))
+}
+
+func (h *ClaimDisputeHandler) ListAllDisputes(c fiber.Ctx) error {
+	filter := models.ClaimDisputeFilter{
+		Status: models.ClaimDisputeStatus(c.Query("status")),
+	}
+	if overdueStr := c.Query("overdue"); overdueStr != "" {
+		overdue, err := strconv.ParseBool(overdueStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", "overdue must be true or false"))
+		}
+		filter.Overdue = overdue
+	}
+
+	disputes, err := h.disputeService.ListDisputes(c.Context(), filter)
+	if err != nil {
+		return disputeError(c, "Failed to list claim disputes", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"disputes": disputes,
+		"count":    len(disputes),
+	}))
+}
+
+func (h *ClaimDisputeHandler) GetDispute(c fiber.Ctx) error {
+	dispute, _, err := h.authorizedDispute(c)
+	if err != nil {
+		return disputeAccessError(c, err)
+	}
+
+	detail, err := h.disputeService.GetDisputeDetail(c.Context(), dispute.ID)
+	if err != nil {
+		return disputeError(c, "Failed to get claim dispute", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(detail))
+}
+
+func (h *ClaimDisputeHandler) PostMessage(c fiber.Ctx) error {
+	dispute, participant, err := h.authorizedDispute(c)
+	if err != nil {
+		return disputeAccessError(c, err)
+	}
+
+	var req models.PostClaimDisputeMessageRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	message, err := h.disputeService.PostMessage(c.Context(), dispute.ID, participant, req)
+	if err != nil {
+		return disputeError(c, "Failed to post claim dispute message", err)
+	}
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(message))
+}
+
+func (h *ClaimDisputeHandler) CreateEvidence(c fiber.Ctx) error {
+	dispute, participant, err := h.authorizedDispute(c)
+	if err != nil {
+		return disputeAccessError(c, err)
+	}
+
+	var req models.CreateClaimDisputeEvidenceRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	response, err := h.disputeService.CreateEvidence(c.Context(), dispute.ID, participant, req)
+	if err != nil {
+		return disputeError(c, "Failed to create claim dispute evidence", err)
+	}
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(response))
+}
+
+func (h *ClaimDisputeHandler) ConfirmEvidence(c fiber.Ctx) error {
+	evidenceID, err := uuid.Parse(c.Params("evidence_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid evidence ID format"))
+	}
+	dispute, _, err := h.authorizedDispute(c)
+	if err != nil {
+		return disputeAccessError(c, err)
+	}
+
+	evidence, err := h.disputeService.ConfirmEvidence(c.Context(), dispute.ID, evidenceID)
+	if err != nil {
+		return disputeError(c, "Failed to confirm claim dispute evidence upload", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(evidence))
+}
+
+func (h *ClaimDisputeHandler) EscalateDispute(c fiber.Ctx) error {
+	dispute, participant, err := h.authorizedDispute(c)
+	if err != nil {
+		return disputeAccessError(c, err)
+	}
+	if participant.Role != models.DisputeRoleFarmer {
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "Only the farmer may escalate a dispute"))
+	}
+
+	dispute, err = h.disputeService.Escalate(c.Context(), dispute.ID, participant.UserID)
+	if err != nil {
+		return disputeError(c, "Failed to escalate claim dispute", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(dispute))
+}
+
+func (h *ClaimDisputeHandler) WithdrawDispute(c fiber.Ctx) error {
+	dispute, participant, err := h.authorizedDispute(c)
+	if err != nil {
+		return disputeAccessError(c, err)
+	}
+	if participant.Role != models.DisputeRoleFarmer {
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "Only the farmer may withdraw a dispute"))
+	}
+
+	dispute, err = h.disputeService.Withdraw(c.Context(), dispute.ID, participant.UserID)
+	if err != nil {
+		return disputeError(c, "Failed to withdraw claim dispute", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(dispute))
+}
+
+func (h *ClaimDisputeHandler) ResolveDispute(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid dispute ID format"))
+	}
+	adminID := c.Get("X-User-ID")
+	if adminID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	var req models.ResolveClaimDisputeRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	dispute, err := h.disputeService.Resolve(c.Context(), id, adminID, req)
+	if err != nil {
+		return disputeError(c, "Failed to resolve claim dispute", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(dispute))
+}
+
+// authorizedDispute loads the dispute of the :id param and the side the caller takes part as: the
+// farmer who opened it, a partner of its insurance provider, or a platform admin
+func (h *ClaimDisputeHandler) authorizedDispute(c fiber.Ctx) (*models.ClaimDispute, models.DisputeParticipant, error) {
+	var participant models.DisputeParticipant
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, participant, fmt.Errorf("invalid dispute ID format")
+	}
+	principal := principalFrom(c)
+	if principal == nil || principal.UserID == "" {
+		return nil, participant, fmt.Errorf("unauthorized: authentication is required")
+	}
+	dispute, err := h.disputeService.GetDispute(c.Context(), id)
+	if err != nil {
+		return nil, participant, err
+	}
+
+	participant.UserID = principal.UserID
+	switch {
+	case canOverrideOwnership(principal):
+		participant.Role = models.DisputeRolePlatformAdmin
+	case principal.HasRole(RoleFarmer):
+		err = requireFarmerOwner(c, dispute.FarmerID)
+		participant.Role = models.DisputeRoleFarmer
+	case principal.HasRole(RoleInsurerAdmin):
+		err = authorizeProvider(c, dispute.InsuranceProviderID, partnerIDOf(h.registeredPolicyService))
+		participant.Role = models.DisputeRolePartner
+	default:
+		err = fmt.Errorf("forbidden: not a participant of the dispute")
+	}
+	if err != nil {
+		return nil, participant, err
+	}
+	return dispute, participant, nil
+}
+
+// disputeAccessError writes the response for an error of authorizedDispute
+func disputeAccessError(c fiber.Ctx, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return disputeError(c, "Failed to get claim dispute", err)
+	}
+	return ownershipError(c, err)
+}
+
+// disputeError maps a service error to a response: unknown claims and disputes are 404, claims of
+// another farmer are 403, rejected input is 400 and a dispute or claim in the wrong status is 409
+func disputeError(c fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", err.Error()))
+	case strings.Contains(err.Error(), "invalid operation"):
+		return c.Status(http.StatusConflict).JSON(
+			utils.CreateErrorResponse("INVALID_STATUS", err.Error()))
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	slog.Error(message, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", message))
+}
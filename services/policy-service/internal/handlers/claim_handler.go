@@ -2,6 +2,7 @@ package handlers
 
 import (
 	utils "agrisa_utils"
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -37,28 +38,33 @@ func (h *ClaimHandler) Register(app *fiber.App) {
 	// ============================================================================
 
 	// Farmer routes - read own claims only
-	farmerGroup := claimGroup.Group("/read-own")
+	farmerGroup := claimGroup.Group("/read-own", RequireRoles(RoleFarmer, RolePlatformAdmin))
 	farmerGroup.Get("/list", h.GetFarmerOwnClaims)                      // GET /claims/read-own/list
 	farmerGroup.Get("/detail/:id", h.GetFarmerClaimDetail)              // GET /claims/read-own/detail/:id
 	farmerGroup.Get("/by-policy/:policy_id", h.GetFarmerClaimsByPolicy) // GET /claims/read-own/by-policy/:policy_id
 	farmerGroup.Get("/by-farm/:farm_id", h.GetFarmerClaimsByFarm)       // GET /claims/read-own/by-farm/:farm_id
+	farmerGroup.Get("/history/:id", h.GetFarmerClaimHistory)            // GET /claims/read-own/history/:id
 
 	// Insurance Partner routes - read partner's claims
-	partnerGroup := claimGroup.Group("/read-partner")
+	partnerGroup := claimGroup.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
 	partnerGroup.Get("/list", h.GetPartnerClaims)                         // GET /claims/read-partner/list
 	partnerGroup.Get("/detail/:id", h.GetPartnerClaimDetail)              // GET /claims/read-partner/detail/:id
 	partnerGroup.Get("/by-policy/:policy_id", h.GetPartnerClaimsByPolicy) // GET /claims/read-partner/by-policy/:policy_id
-	partnerWGroup := claimGroup.Group("/write")
-	partnerWGroup.Post("/validate/:claim_id", h.ValidateClaim)
+	partnerGroup.Get("/history/:id", h.GetPartnerClaimHistory)            // GET /claims/read-partner/history/:id
+	partnerWGroup := claimGroup.Group("/write", RequireRoles(RoleInsurerAdmin))
+	partnerWGroup.Post("/submit/:claim_id", h.SubmitClaim)     // POST /claims/write/submit/:claim_id
+	partnerWGroup.Post("/validate/:claim_id", h.ValidateClaim) // POST /claims/write/validate/:claim_id
+	partnerWGroup.Post("/close/:claim_id", h.CloseClaim)       // POST /claims/write/close/:claim_id
 
 	// Admin routes - full access to all claims
-	adminReadGroup := claimGroup.Group("/read-all")
+	adminReadGroup := claimGroup.Group("/read-all", RequireRoles(RolePlatformAdmin))
 	adminReadGroup.Get("/list", h.GetAllClaimsAdmin)                      // GET /claims/read-all/list
 	adminReadGroup.Get("/detail/:id", h.GetClaimDetailAdmin)              // GET /claims/read-all/detail/:id
 	adminReadGroup.Get("/by-policy/:policy_id", h.GetClaimsByPolicyAdmin) // GET /claims/read-all/by-policy/:policy_id
 	adminReadGroup.Get("/by-farm/:farm_id", h.GetClaimsByFarmAdmin)       // GET /claims/read-all/by-farm/:farm_id
+	adminReadGroup.Get("/history/:id", h.GetClaimHistoryAdmin)            // GET /claims/read-all/history/:id
 
-	adminDeleteGroup := claimGroup.Group("/delete-any")
+	adminDeleteGroup := claimGroup.Group("/delete-any", RequireRoles(RolePlatformAdmin))
 	adminDeleteGroup.Delete("/:id", h.DeleteClaimAdmin) // DELETE /claims/delete-any/:id
 }
 
@@ -68,10 +74,9 @@ func (h *ClaimHandler) Register(app *fiber.App) {
 
 // GetFarmerOwnClaims retrieves all claims for the authenticated farmer
 func (h *ClaimHandler) GetFarmerOwnClaims(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	claims, err := h.claimService.GetClaimsByFarmerID(c.Context(), userID)
@@ -90,10 +95,9 @@ func (h *ClaimHandler) GetFarmerOwnClaims(c fiber.Ctx) error {
 
 // GetFarmerClaimDetail retrieves a specific claim detail for the authenticated farmer
 func (h *ClaimHandler) GetFarmerClaimDetail(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	claimIDStr := c.Params("id")
@@ -121,12 +125,35 @@ func (h *ClaimHandler) GetFarmerClaimDetail(c fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(claim))
 }
 
+// GetFarmerClaimHistory retrieves the status history of a claim of the authenticated farmer
+func (h *ClaimHandler) GetFarmerClaimHistory(c fiber.Ctx) error {
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
+	claimID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid claim ID format"))
+	}
+
+	history, err := h.claimService.GetClaimStatusHistoryForFarmer(c.Context(), claimID, userID)
+	if err != nil {
+		return h.claimHistoryError(c, claimID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"claim_id": claimID,
+		"history":  history,
+	}))
+}
+
 // GetFarmerClaimsByPolicy retrieves claims for a specific policy owned by the farmer
 func (h *ClaimHandler) GetFarmerClaimsByPolicy(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policyIDStr := c.Params("policy_id")
@@ -160,10 +187,9 @@ func (h *ClaimHandler) GetFarmerClaimsByPolicy(c fiber.Ctx) error {
 
 // GetFarmerClaimsByFarm retrieves claims for a specific farm owned by the farmer
 func (h *ClaimHandler) GetFarmerClaimsByFarm(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	farmIDStr := c.Params("farm_id")
@@ -268,6 +294,37 @@ func (h *ClaimHandler) GetPartnerClaimDetail(c fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(claim))
 }
 
+// GetPartnerClaimHistory retrieves the status history of a claim of the insurance partner
+func (h *ClaimHandler) GetPartnerClaimHistory(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	partnerID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	claimID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid claim ID format"))
+	}
+
+	history, err := h.claimService.GetClaimStatusHistoryForPartner(c.Context(), claimID, partnerID)
+	if err != nil {
+		return h.claimHistoryError(c, claimID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"claim_id": claimID,
+		"history":  history,
+	}))
+}
+
 // GetPartnerClaimsByPolicy retrieves claims for a specific policy managed by the partner
 func (h *ClaimHandler) GetPartnerClaimsByPolicy(c fiber.Ctx) error {
 	userID := c.Get("X-User-ID")
@@ -350,6 +407,9 @@ func (h *ClaimHandler) ValidateClaim(c fiber.Ctx) error {
 
 	res, err := h.claimService.ValidateClaim(c.Context(), claimID, req, partnerID)
 	if err != nil {
+		if status, code, ok := claimTransitionErrorStatus(err); ok {
+			return c.Status(status).JSON(utils.CreateErrorResponse(code, err.Error()))
+		}
 		slog.Error("error validating claim", "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL", "error validating claim"))
 	}
@@ -357,6 +417,68 @@ func (h *ClaimHandler) ValidateClaim(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(res))
 }
 
+// SubmitClaim sends a generated claim to the partner for review
+func (h *ClaimHandler) SubmitClaim(c fiber.Ctx) error {
+	return h.transitionClaim(c, h.claimService.SubmitClaim)
+}
+
+// CloseClaim closes a paid or rejected claim
+func (h *ClaimHandler) CloseClaim(c fiber.Ctx) error {
+	return h.transitionClaim(c, h.claimService.CloseClaim)
+}
+
+// transitionClaim applies a status change of the claim in the path for the partner of the token
+func (h *ClaimHandler) transitionClaim(c fiber.Ctx, transition func(ctx context.Context, claimID uuid.UUID, notes string, partnerID string) (*models.Claim, error)) error {
+	claimID, err := uuid.Parse(c.Params("claim_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "invalid claim id format"))
+	}
+
+	var req models.ClaimTransitionRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body: "+err.Error()))
+		}
+	}
+
+	if c.Get("X-User-ID") == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	partnerID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	claim, err := transition(c.Context(), claimID, req.Notes, partnerID)
+	if err != nil {
+		if status, code, ok := claimTransitionErrorStatus(err); ok {
+			return c.Status(status).JSON(utils.CreateErrorResponse(code, err.Error()))
+		}
+		slog.Error("error changing claim status", "claim_id", claimID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL", "error changing claim status"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(claim))
+}
+
+// claimTransitionErrorStatus maps the errors of a claim status change caused by the request to a
+// response status and code
+func claimTransitionErrorStatus(err error) (int, string, bool) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound, "NOT_FOUND", true
+	case strings.Contains(err.Error(), "unauthorized"):
+		return http.StatusForbidden, "FORBIDDEN", true
+	case strings.Contains(err.Error(), "invalid operation"), strings.Contains(err.Error(), "invalid claim status"):
+		return http.StatusConflict, "INVALID_STATUS", true
+	}
+	return 0, "", false
+}
+
 // ============================================================================
 // ADMIN PERMISSION HANDLERS (read-all, delete-any)
 // ============================================================================
@@ -433,6 +555,31 @@ func (h *ClaimHandler) GetClaimDetailAdmin(c fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(claim))
 }
 
+// GetClaimHistoryAdmin retrieves the status history of any claim (admin access)
+func (h *ClaimHandler) GetClaimHistoryAdmin(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	claimID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid claim ID format"))
+	}
+
+	history, err := h.claimService.GetClaimStatusHistory(c.Context(), claimID)
+	if err != nil {
+		return h.claimHistoryError(c, claimID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"claim_id": claimID,
+		"history":  history,
+	}))
+}
+
 // GetClaimsByPolicyAdmin retrieves claims for a specific policy (admin access)
 func (h *ClaimHandler) GetClaimsByPolicyAdmin(c fiber.Ctx) error {
 	userID := c.Get("X-User-ID")
@@ -557,3 +704,17 @@ func (h *ClaimHandler) getPartnerIDFromToken(c fiber.Ctx) (string, error) {
 
 	return partnerID, nil
 }
+
+func (h *ClaimHandler) claimHistoryError(c fiber.Ctx, claimID uuid.UUID, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", "Claim not found"))
+	}
+	if strings.Contains(err.Error(), "unauthorized") {
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to view this claim"))
+	}
+	slog.Error("Failed to get claim status history", "claim_id", claimID, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve claim status history"))
+}
@@ -32,17 +32,17 @@ func (h *ClaimRejectionHandler) Register(app *fiber.App) {
 	claimRejectionGroup := protectedGr.Group("/claim-rejections")
 
 	// Partner routes - read partner's claim rejections
-	partnerGroup := claimRejectionGroup.Group("/read-partner")
+	partnerGroup := claimRejectionGroup.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
 	partnerGroup.Get("/list", h.GetPartnerClaimRejections)      // GET /claim-rejections/read-partner/list
 	partnerGroup.Get("/:id", h.GetPartnerClaimRejectionByID)    // GET /claim-rejections/read-partner/:id
 	partnerGroup.Get("/claim/:claim_id", h.GetPartnerByClaimID) // GET /claim-rejections/read-partner/claim/:claim_id
 
 	// Partner create routes - create claim rejection
-	partnerCreateGroup := claimRejectionGroup.Group("/create-partner")
+	partnerCreateGroup := claimRejectionGroup.Group("/create-partner", RequireRoles(RoleInsurerAdmin))
 	partnerCreateGroup.Post("/", h.CreatePartnerClaimRejection) // POST /claim-rejections/create-partner/
 
 	// Admin routes - full CRUD access
-	adminGroup := claimRejectionGroup.Group("/admin")
+	adminGroup := claimRejectionGroup.Group("/admin", RequireRoles(RolePlatformAdmin))
 	adminGroup.Get("/list", h.GetAllClaimRejections)   // GET /claim-rejections/admin/list
 	adminGroup.Get("/:id", h.GetClaimRejectionByID)    // GET /claim-rejections/admin/:id
 	adminGroup.Get("/claim/:claim_id", h.GetByClaimID) // GET /claim-rejections/admin/claim/:claim_id
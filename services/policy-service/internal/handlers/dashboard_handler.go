@@ -6,30 +6,37 @@ import (
 	"net/http"
 	"policy-service/internal/models"
 	"policy-service/internal/services"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
 )
 
 type DashboardHandler struct {
-	DashboardService *services.DashboardService
+	DashboardService        *services.DashboardService
+	registeredPolicyService *services.RegisteredPolicyService
 }
 
-func NewDashboardHandler(dashboardService *services.DashboardService) *DashboardHandler {
+func NewDashboardHandler(dashboardService *services.DashboardService, registeredPolicyService *services.RegisteredPolicyService) *DashboardHandler {
 	return &DashboardHandler{
-		DashboardService: dashboardService,
+		DashboardService:        dashboardService,
+		registeredPolicyService: registeredPolicyService,
 	}
 }
 
 func (h *DashboardHandler) Register(app *fiber.App) {
+	publicGr := app.Group("policy/public/api/v2")
 	protectedGr := app.Group("policy/protected/api/v2")
 
+	// Marketplace stats, read by profile-service
+	publicGr.Get("/dashboard/partner/:partner_id/public-stats", h.GetPartnerPublicStats)
+
 	dashboardGr := protectedGr.Group("/dashboard")
 
 	// Partner routes
-	dashboardGr.Post("/partner/overview", h.GetPartnerDashboardOverview)
+	dashboardGr.Post("/partner/overview", RequireRoles(RoleInsurerAdmin), h.GetPartnerDashboardOverview)
 
 	// Admin routes
-	dashboardGr.Post("/admin/revenue-overview", h.GetAdminRevenueOverview)
+	dashboardGr.Post("/admin/revenue-overview", RequireRoles(RolePlatformAdmin), h.GetAdminRevenueOverview)
 }
 
 func (h *DashboardHandler) GetAdminRevenueOverview(c fiber.Ctx) error {
@@ -75,6 +82,9 @@ func (h *DashboardHandler) GetPartnerDashboardOverview(c fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(
 			utils.CreateErrorResponse("BAD_REQUEST", "partner_id is required"))
 	}
+	if err := authorizeProvider(c, req.PartnerID, partnerIDOf(h.registeredPolicyService)); err != nil {
+		return ownershipError(c, err)
+	}
 
 	if req.StartDate == 0 || req.EndDate == 0 {
 		slog.Error("start_date and end_date are required", "user_id", userID)
@@ -97,3 +107,20 @@ func (h *DashboardHandler) GetPartnerDashboardOverview(c fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(overview))
 }
+
+func (h *DashboardHandler) GetPartnerPublicStats(c fiber.Ctx) error {
+	partnerID := c.Params("partner_id")
+
+	stats, err := h.DashboardService.GetPartnerPublicStats(partnerID)
+	if err != nil {
+		slog.Error("failed to get partner public stats", "partner_id", partnerID, "error", err)
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", "Failed to get partner stats"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(stats))
+}
@@ -149,5 +149,5 @@ func (h *DataBillHandler) GetDataCost(c fiber.Ctx) error {
 func (h *DataBillHandler) Register(app *fiber.App) {
 	app.Get("policy/protected/api/v2/data-bill/me", h.GetMyDataBillHandler)
 	app.Get("policy/protected/api/v2/data-bill/cost/:id", h.GetDataCost)
-	app.Post("policy/protected/api/v2/data-bill/mark-payment/:id", h.MarkPolicyForPaymentManual)
+	app.Post("policy/protected/api/v2/data-bill/mark-payment/:id", RequireRoles(RolePlatformAdmin), h.MarkPolicyForPaymentManual)
 }
@@ -24,19 +24,21 @@ func NewDataSourceHandler(dataSourceService *services.DataSourceService) *DataSo
 
 func (dsh *DataSourceHandler) Register(app *fiber.App) {
 	protectedGr := app.Group("policy/protected/api/v2")
+	// The platform maintains the data sources, any user may read them
+	adminOnly := RequireRoles(RolePlatformAdmin)
 
 	// Data Source routes
 	dataSourceGroup := protectedGr.Group("/data-sources")
-	dataSourceGroup.Post("/", dsh.CreateDataSource)
-	dataSourceGroup.Post("/batch", dsh.CreateDataSourcesBatch)
+	dataSourceGroup.Post("/", adminOnly, dsh.CreateDataSource)
+	dataSourceGroup.Post("/batch", adminOnly, dsh.CreateDataSourcesBatch)
 	dataSourceGroup.Get("/", dsh.GetAllDataSources)
 	dataSourceGroup.Get("/active", dsh.GetActiveDataSources)
 	dataSourceGroup.Get("/search", dsh.GetDataSourcesWithFilters)
 	dataSourceGroup.Get("/:id", dsh.GetDataSourceByID)
-	dataSourceGroup.Put("/:id", dsh.UpdateDataSource)
-	dataSourceGroup.Delete("/:id", dsh.DeleteDataSource)
-	dataSourceGroup.Patch("/:id/activate", dsh.ActivateDataSource)
-	dataSourceGroup.Patch("/:id/deactivate", dsh.DeactivateDataSource)
+	dataSourceGroup.Put("/:id", adminOnly, dsh.UpdateDataSource)
+	dataSourceGroup.Delete("/:id", adminOnly, dsh.DeleteDataSource)
+	dataSourceGroup.Patch("/:id/activate", adminOnly, dsh.ActivateDataSource)
+	dataSourceGroup.Patch("/:id/deactivate", adminOnly, dsh.DeactivateDataSource)
 	dataSourceGroup.Get("/type/:type", dsh.GetDataSourcesByType)
 	dataSourceGroup.Get("/tier/:tierId", dsh.GetDataSourcesByTierID)
 	dataSourceGroup.Get("/parameter/:parameterName", dsh.GetDataSourcesByParameterName)
@@ -24,22 +24,24 @@ func NewDataTierHandler(dataTierService *services.DataTierService) *DataTierHand
 
 func (dth *DataTierHandler) Register(app *fiber.App) {
 	protectedGr := app.Group("policy/protected/api/v2")
+	// Categories and tiers are priced by the platform; reading them is open to any user
+	adminOnly := RequireRoles(RolePlatformAdmin)
 
 	// Data Tier Category routes
 	categoryGroup := protectedGr.Group("/data-tier-categories")
-	categoryGroup.Post("/", dth.CreateDataTierCategory)
+	categoryGroup.Post("/", adminOnly, dth.CreateDataTierCategory)
 	categoryGroup.Get("/", dth.GetAllDataTierCategories)
 	categoryGroup.Get("/:id", dth.GetDataTierCategoryByID)
-	categoryGroup.Put("/:id", dth.UpdateDataTierCategory)
-	categoryGroup.Delete("/:id", dth.DeleteDataTierCategory)
+	categoryGroup.Put("/:id", adminOnly, dth.UpdateDataTierCategory)
+	categoryGroup.Delete("/:id", adminOnly, dth.DeleteDataTierCategory)
 
 	// Data Tier routes
 	tierGroup := protectedGr.Group("/data-tiers")
-	tierGroup.Post("/", dth.CreateDataTier)
+	tierGroup.Post("/", adminOnly, dth.CreateDataTier)
 	tierGroup.Get("/", dth.GetAllDataTiers)
 	tierGroup.Get("/:id", dth.GetDataTierByID)
-	tierGroup.Put("/:id", dth.UpdateDataTier)
-	tierGroup.Delete("/:id", dth.DeleteDataTier)
+	tierGroup.Put("/:id", adminOnly, dth.UpdateDataTier)
+	tierGroup.Delete("/:id", adminOnly, dth.DeleteDataTier)
 	tierGroup.Get("/category/:categoryId", dth.GetDataTiersByCategoryID)
 	tierGroup.Get("/:id/with-category", dth.GetDataTierWithCategory)
 	tierGroup.Get("/:id/total-multiplier", dth.CalculateTotalMultiplier)
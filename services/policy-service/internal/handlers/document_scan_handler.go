@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"net/http"
+	"policy-service/internal/services"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type DocumentScanHandler struct {
+	scanService *services.DocumentScanService
+}
+
+func NewDocumentScanHandler(scanService *services.DocumentScanService) *DocumentScanHandler {
+	return &DocumentScanHandler{scanService: scanService}
+}
+
+func (h *DocumentScanHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	scanGr := protectedGr.Group("/document-scans")
+	scanGr.Get("/:id", h.GetScan) // GET /document-scans/{id} - pending, clean or quarantined with findings
+}
+
+func (h *DocumentScanHandler) GetScan(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid scan ID format"))
+	}
+
+	scan, err := h.scanService.GetScan(c.Context(), id)
+	if err != nil {
+		return attachmentError(c, "Failed to get document scan", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(scan))
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	utils "agrisa_utils"
 	"errors"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
@@ -12,17 +13,22 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 )
 
 type FarmHandler struct {
-	farmService *services.FarmService
-	minioClient *minio.MinioClient
+	farmService             *services.FarmService
+	registeredPolicyService *services.RegisteredPolicyService
+	minioClient             *minio.MinioClient
+	rateLimiter             *services.RateLimiter
 }
 
-func NewFarmHandler(farmService *services.FarmService, minioClient *minio.MinioClient) *FarmHandler {
+func NewFarmHandler(farmService *services.FarmService, registeredPolicyService *services.RegisteredPolicyService, minioClient *minio.MinioClient, rateLimiter *services.RateLimiter) *FarmHandler {
 	return &FarmHandler{
-		farmService: farmService,
-		minioClient: minioClient,
+		farmService:             farmService,
+		registeredPolicyService: registeredPolicyService,
+		minioClient:             minioClient,
+		rateLimiter:             rateLimiter,
 	}
 }
 
@@ -31,10 +37,16 @@ func (h *FarmHandler) RegisterRoutes(app *fiber.App) {
 
 	protectedGr.Get("/farms/me", h.GetFarmByOwner)
 	protectedGr.Get("/farms/:id", h.GetFarmByID)
-	protectedGr.Post("/farms", h.CreateFarm)
+	protectedGr.Post("/farms", RateLimited(h.rateLimiter, services.RateLimitGroupOCR, h.CreateFarm)) // the land certificate photos are read by OCR
 	protectedGr.Put("/farms/:id", h.UpdateFarm)
 	protectedGr.Post("/farms/:id", h.DeleteFarm)
-	protectedGr.Get("/farms", h.GetAllFarms)
+	protectedGr.Get("/farms", RequireRoles(RolePlatformAdmin), h.GetAllFarms)
+	protectedGr.Get("/farms/:id/crop-verifications", h.GetCropVerifications)
+	protectedGr.Post("/farms/:id/crop-verification", RequireRoles(RoleInsurerAdmin, RolePlatformAdmin), h.ReviewCropVerification)
+	protectedGr.Get("/farms/:id/land-certificate-verifications", h.GetLandCertificateVerifications)
+
+	internalGr := app.Group("policy/internal/api/v2")
+	internalGr.Get("/farms/insured-locations", h.GetInsuredFarmLocations)
 }
 
 // func (h *FarmHandler) GetFarmByOwner(c fiber.Ctx) error {
@@ -106,6 +118,11 @@ func (h *FarmHandler) GetFarmByID(c fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", err.Error()))
 	}
+
+	// Partners see the farms they insure through the policy detail
+	if err := requireFarmerOwner(c, farm.OwnerID); err != nil {
+		return ownershipError(c, err)
+	}
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(farm))
 }
 
@@ -223,6 +240,110 @@ func (h *FarmHandler) GetAllFarms(c fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(farms))
 }
 
+// GetCropVerifications lists the classifications of the crop of a farm for its owner and the
+// insurers of its policies
+func (h *FarmHandler) GetCropVerifications(c fiber.Ctx) error {
+	farm, err := h.authorizedFarm(c, true)
+	if err != nil {
+		return farmAccessError(c, err)
+	}
+
+	verifications, err := h.farmService.GetCropVerifications(c.Context(), farm.ID)
+	if err != nil {
+		slog.Error("Failed to list crop verifications", "farm_id", farm.ID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve crop verifications"))
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(verifications))
+}
+
+// GetLandCertificateVerifications lists the readings of the land certificate of a farm, the
+// evidence of its land ownership, for its owner and the insurers of its policies
+func (h *FarmHandler) GetLandCertificateVerifications(c fiber.Ctx) error {
+	farm, err := h.authorizedFarm(c, true)
+	if err != nil {
+		return farmAccessError(c, err)
+	}
+
+	verifications, err := h.farmService.GetLandCertificateVerifications(c.Context(), farm.ID)
+	if err != nil {
+		slog.Error("Failed to list land certificate verifications", "farm_id", farm.ID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve land certificate verifications"))
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(verifications))
+}
+
+// ReviewCropVerification lets an insurer of the farm confirm its declared crop or override it
+func (h *FarmHandler) ReviewCropVerification(c fiber.Ctx) error {
+	var req models.ReviewCropVerificationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("BAD_REQUEST", "Invalid request body"))
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(utils.CreateErrorResponse("VALIDATION_FAILED", err.Error()))
+	}
+
+	farm, err := h.authorizedFarm(c, false)
+	if err != nil {
+		return farmAccessError(c, err)
+	}
+
+	reviewerID := c.Get("X-User-ID")
+	if reviewerID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+	updated, err := h.farmService.ReviewCropVerification(c.Context(), farm.ID, reviewerID, req)
+	if err != nil {
+		slog.Error("Failed to review crop verification", "farm_id", farm.ID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", "Failed to review crop verification"))
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(updated))
+}
+
+// authorizedFarm loads the farm of the request and checks that the caller insures it, or owns
+// it when allowOwner is set
+func (h *FarmHandler) authorizedFarm(c fiber.Ctx, allowOwner bool) (*models.Farm, error) {
+	farmID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid: farm ID format")
+	}
+	farm, err := h.farmService.GetByFarmID(c.Context(), farmID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if principal := principalFrom(c); allowOwner && principal != nil && principal.UserID == farm.OwnerID {
+		return farm, nil
+	}
+	providerIDs, err := h.farmService.GetInsuringProviderIDs(c.Context(), farm.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeAnyProvider(c, providerIDs, partnerIDOf(h.registeredPolicyService)); err != nil {
+		return nil, err
+	}
+	return farm, nil
+}
+
+// farmAccessError writes the response for an error of authorizedFarm
+func farmAccessError(c fiber.Ctx, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(http.StatusNotFound).JSON(utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+	}
+	return ownershipError(c, err)
+}
+
+// GetInsuredFarmLocations is used by weather-service to keep its polling registry in sync
+func (h *FarmHandler) GetInsuredFarmLocations(c fiber.Ctx) error {
+	locations, err := h.farmService.GetInsuredFarmLocations(c.Context())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", err.Error()))
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(locations))
+}
+
 func extractBearerToken(c fiber.Ctx) (string, error) {
 	authHeader := c.Get("Authorization")
 
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// Idempotent lets clients send the Idempotency-Key header with a request so that retrying it
+// returns the first response instead of processing it again. Reusing a key with a different
+// body is rejected, and responses with a server error are not kept so the request can be retried.
+// Requests without the header are handled as usual.
+func Idempotent(store *services.IdempotencyStore, operation string, next fiber.Handler) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		key := c.Get(idempotencyKeyHeader)
+		if key == "" {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be at most 255 characters"))
+		}
+
+		sum := sha256.Sum256(c.Body())
+		requestHash := hex.EncodeToString(sum[:])
+		redisKey := store.Key(operation, c.Get("X-User-ID"), key)
+
+		record, err := store.Begin(c.Context(), redisKey, requestHash)
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyMismatch):
+			return c.Status(http.StatusUnprocessableEntity).JSON(
+				utils.CreateErrorResponse("IDEMPOTENCY_KEY_REUSED", err.Error()))
+		case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+			return c.Status(http.StatusConflict).JSON(
+				utils.CreateErrorResponse("REQUEST_IN_PROGRESS", err.Error()))
+		case err != nil:
+			// Without Redis the request is still served, only without protection against retries
+			slog.Error("idempotency check failed, processing request without it", "operation", operation, "error", err)
+			return next(c)
+		case record != nil:
+			slog.Info("replaying idempotent response", "operation", operation, "status_code", record.StatusCode)
+			c.Set(idempotencyReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(record.StatusCode).SendString(record.Body)
+		}
+
+		if err := next(c); err != nil {
+			if releaseErr := store.Release(c.Context(), redisKey); releaseErr != nil {
+				slog.Error("failed to release idempotency key", "operation", operation, "error", releaseErr)
+			}
+			return err
+		}
+
+		statusCode := c.Response().StatusCode()
+		if statusCode >= http.StatusInternalServerError {
+			if err := store.Release(c.Context(), redisKey); err != nil {
+				slog.Error("failed to release idempotency key", "operation", operation, "error", err)
+			}
+			return nil
+		}
+		if err := store.Complete(c.Context(), redisKey, requestHash, statusCode, c.Response().Body()); err != nil {
+			slog.Error("failed to store idempotent response", "operation", operation, "error", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// Months covered by a revenue report when no range is given, the current one included
+const defaultRevenueReportMonths = 12
+
+type InvoiceHandler struct {
+	invoiceService          *services.InvoiceService
+	registeredPolicyService *services.RegisteredPolicyService
+}
+
+func NewInvoiceHandler(invoiceService *services.InvoiceService, registeredPolicyService *services.RegisteredPolicyService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService:          invoiceService,
+		registeredPolicyService: registeredPolicyService,
+	}
+}
+
+func (h *InvoiceHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	invoiceGr := protectedGr.Group("/invoices")
+
+	// Partner routes - the invoices of the caller's provider
+	partnerGr := invoiceGr.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
+	partnerGr.Get("/list", h.ListPartnerInvoices)     // GET /invoices/read-partner/list?status=...&month=2026-09
+	partnerGr.Get("/:id", h.GetPartnerInvoice)        // GET /invoices/read-partner/:id
+	partnerGr.Get("/:id/pdf", h.GetPartnerInvoicePDF) // GET /invoices/read-partner/:id/pdf - Presigned download URL
+
+	// Admin read routes - every invoice and the platform revenue
+	adminReadGr := invoiceGr.Group("/read-all", RequireRoles(RolePlatformAdmin))
+	adminReadGr.Get("/list", h.ListAllInvoices)     // GET /invoices/read-all/list?insurance_provider_id=...&status=...&month=2026-09
+	adminReadGr.Get("/revenue", h.GetRevenueReport) // GET /invoices/read-all/revenue?from=2026-01&to=2026-09
+	adminReadGr.Get("/:id", h.GetInvoice)           // GET /invoices/read-all/:id
+	adminReadGr.Get("/:id/pdf", h.GetInvoicePDF)    // GET /invoices/read-all/:id/pdf
+
+	// Admin write routes - issue invoices and track their payment
+	adminGr := invoiceGr.Group("/admin", RequireRoles(RolePlatformAdmin))
+	adminGr.Post("/generate", h.GenerateInvoices) // POST /invoices/admin/generate
+	adminGr.Post("/:id/mark-paid", h.MarkPaid)    // POST /invoices/admin/:id/mark-paid
+	adminGr.Post("/:id/cancel", h.CancelInvoice)  // POST /invoices/admin/:id/cancel
+}
+
+func (h *InvoiceHandler) ListPartnerInvoices(c fiber.Ctx) error {
+	partnerID, err := partnerIDOf(h.registeredPolicyService)(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if err != nil {
+		slog.Error("Failed to get partner ID from token", "error", err)
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "Failed to resolve insurance partner"))
+	}
+	filter, err := parseInvoiceFilter(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	filter.InsuranceProviderID = partnerID
+	return h.list(c, filter)
+}
+
+func (h *InvoiceHandler) ListAllInvoices(c fiber.Ctx) error {
+	filter, err := parseInvoiceFilter(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	filter.InsuranceProviderID = strings.TrimSpace(c.Query("insurance_provider_id"))
+	return h.list(c, filter)
+}
+
+func (h *InvoiceHandler) list(c fiber.Ctx, filter models.InvoiceFilter) error {
+	invoices, err := h.invoiceService.ListInvoices(c.Context(), filter)
+	if err != nil {
+		return invoiceError(c, "Failed to list invoices", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(fiber.Map{
+		"invoices": invoices,
+		"count":    len(invoices),
+	}))
+}
+
+func (h *InvoiceHandler) GetPartnerInvoice(c fiber.Ctx) error {
+	invoice, err := h.partnerInvoice(c)
+	if err != nil {
+		return invoiceError(c, "Failed to get invoice", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(invoice))
+}
+
+func (h *InvoiceHandler) GetInvoice(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid invoice ID format"))
+	}
+	invoice, err := h.invoiceService.GetInvoice(c.Context(), id)
+	if err != nil {
+		return invoiceError(c, "Failed to get invoice", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(invoice))
+}
+
+func (h *InvoiceHandler) GetPartnerInvoicePDF(c fiber.Ctx) error {
+	invoice, err := h.partnerInvoice(c)
+	if err != nil {
+		return invoiceError(c, "Failed to get invoice", err)
+	}
+	return h.pdf(c, invoice.ID)
+}
+
+func (h *InvoiceHandler) GetInvoicePDF(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid invoice ID format"))
+	}
+	return h.pdf(c, id)
+}
+
+func (h *InvoiceHandler) pdf(c fiber.Ctx, id uuid.UUID) error {
+	download, err := h.invoiceService.GetDownloadURL(c.Context(), id)
+	if err != nil {
+		return invoiceError(c, "Failed to get invoice PDF", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(download))
+}
+
+func (h *InvoiceHandler) GetRevenueReport(c fiber.Ctx) error {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 1-defaultRevenueReportMonths, 0)
+
+	var err error
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, err = time.Parse("2006-01", fromStr); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("BAD_REQUEST", "from must be a month formatted as YYYY-MM"))
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = time.Parse("2006-01", toStr); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("BAD_REQUEST", "to must be a month formatted as YYYY-MM"))
+		}
+	}
+
+	report, err := h.invoiceService.GetRevenueReport(c.Context(), from, to)
+	if err != nil {
+		return invoiceError(c, "Failed to get revenue report", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(report))
+}
+
+func (h *InvoiceHandler) GenerateInvoices(c fiber.Ctx) error {
+	var req models.GenerateInvoicesRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	response, err := h.invoiceService.GenerateInvoices(c.Context(), req)
+	if err != nil {
+		return invoiceError(c, "Failed to generate invoices", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(response))
+}
+
+func (h *InvoiceHandler) MarkPaid(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid invoice ID format"))
+	}
+	adminID := c.Get("X-User-ID")
+	if adminID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	var req models.MarkInvoicePaidRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	invoice, err := h.invoiceService.MarkPaid(c.Context(), id, adminID, req)
+	if err != nil {
+		return invoiceError(c, "Failed to mark invoice paid", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(invoice))
+}
+
+func (h *InvoiceHandler) CancelInvoice(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid invoice ID format"))
+	}
+	adminID := c.Get("X-User-ID")
+	if adminID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	var req models.CancelInvoiceRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	invoice, err := h.invoiceService.Cancel(c.Context(), id, adminID, req)
+	if err != nil {
+		return invoiceError(c, "Failed to cancel invoice", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(invoice))
+}
+
+// partnerInvoice loads the invoice of the :id param if it was issued to the caller's provider
+func (h *InvoiceHandler) partnerInvoice(c fiber.Ctx) (*models.PartnerInvoice, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice ID format")
+	}
+	partnerID, err := partnerIDOf(h.registeredPolicyService)(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if err != nil {
+		slog.Error("Failed to get partner ID from token", "error", err)
+		return nil, fmt.Errorf("unauthorized: failed to resolve insurance partner")
+	}
+	invoice, err := h.invoiceService.GetInvoice(c.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.InsuranceProviderID != partnerID {
+		return nil, fmt.Errorf("unauthorized: invoice was issued to another provider")
+	}
+	return invoice, nil
+}
+
+// parseInvoiceFilter reads the payment status and the month (YYYY-MM) an invoice list is filtered by
+func parseInvoiceFilter(c fiber.Ctx) (models.InvoiceFilter, error) {
+	filter := models.InvoiceFilter{PaymentStatus: models.PaymentStatus(c.Query("status"))}
+	switch filter.PaymentStatus {
+	case "", models.PaymentPending, models.PaymentPaid, models.PaymentOverdue, models.PaymentCancelled, models.PaymentRefunded:
+	default:
+		return filter, fmt.Errorf("invalid status %q", filter.PaymentStatus)
+	}
+	if monthStr := c.Query("month"); monthStr != "" {
+		month, err := time.Parse("2006-01", monthStr)
+		if err != nil {
+			return filter, fmt.Errorf("month must be formatted as YYYY-MM")
+		}
+		filter.InvoiceMonth = int64(month.Year()*100 + int(month.Month()))
+	}
+	return filter, nil
+}
+
+func invoiceError(c fiber.Ctx, message string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", err.Error()))
+	case strings.Contains(err.Error(), "invalid operation"):
+		return c.Status(http.StatusConflict).JSON(
+			utils.CreateErrorResponse("INVALID_STATUS", err.Error()))
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	slog.Error(message, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", message))
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/services"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// boundFarmerID returns the farmer a farmer-facing request acts for. It is the user of the token;
+// a farmer_id query naming another farmer is honoured only for platform admins and other
+// services, and each such override is logged.
+func boundFarmerID(c fiber.Ctx) (string, error) {
+	principal := principalFrom(c)
+	if principal == nil || principal.UserID == "" && !principal.Internal {
+		return "", fmt.Errorf("unauthorized: authentication is required")
+	}
+
+	requested := c.Query("farmer_id")
+	if requested == "" || requested == principal.UserID {
+		if principal.UserID == "" {
+			return "", fmt.Errorf("invalid: farmer_id is required")
+		}
+		return principal.UserID, nil
+	}
+
+	if !canOverrideOwnership(principal) {
+		slog.Warn("Rejected request for the resources of another farmer",
+			"path", c.Path(),
+			"user_id", principal.UserID,
+			"farmer_id", requested)
+		return "", fmt.Errorf("forbidden: cannot access the resources of another farmer")
+	}
+	slog.Info("Admin override of farmer ownership",
+		"path", c.Path(),
+		"user_id", principal.UserID,
+		"internal", principal.Internal,
+		"farmer_id", requested)
+	return requested, nil
+}
+
+// requireFarmerOwner checks that the caller owns a resource of ownerID, or is allowed to override
+// ownership
+func requireFarmerOwner(c fiber.Ctx, ownerID string) error {
+	principal := principalFrom(c)
+	if principal == nil {
+		return fmt.Errorf("unauthorized: authentication is required")
+	}
+	if principal.UserID != "" && principal.UserID == ownerID {
+		return nil
+	}
+	if !canOverrideOwnership(principal) {
+		slog.Warn("Rejected access to a resource of another farmer",
+			"path", c.Path(),
+			"user_id", principal.UserID,
+			"owner_id", ownerID)
+		return fmt.Errorf("forbidden: resource belongs to another farmer")
+	}
+	if !principal.Internal {
+		slog.Info("Admin override of farmer ownership",
+			"path", c.Path(),
+			"user_id", principal.UserID,
+			"owner_id", ownerID)
+	}
+	return nil
+}
+
+// authorizeProvider checks that the caller may act for the insurance provider. Platform admins
+// and other services may act for any provider, partner admins for their own: the one scoped in
+// their token, or, when the token has no provider scope, the one of their partner profile.
+func authorizeProvider(c fiber.Ctx, providerID string, partnerIDOf func(token string) (string, error)) error {
+	return authorizeAnyProvider(c, []string{providerID}, partnerIDOf)
+}
+
+// authorizeAnyProvider checks that the caller may act for one of the insurance providers, as
+// authorizeProvider does for one
+func authorizeAnyProvider(c fiber.Ctx, providerIDs []string, partnerIDOf func(token string) (string, error)) error {
+	principal := principalFrom(c)
+	if principal == nil {
+		return fmt.Errorf("unauthorized: authentication is required")
+	}
+	if canOverrideOwnership(principal) {
+		return nil
+	}
+	if !principal.HasRole(RoleInsurerAdmin) {
+		return fmt.Errorf("forbidden: only insurance partners may act for a provider")
+	}
+
+	scoped := principal.ProviderIDs()
+	if len(scoped) > 0 {
+		for _, providerID := range providerIDs {
+			if slices.Contains(scoped, providerID) {
+				return nil
+			}
+		}
+		return fmt.Errorf("forbidden: not a partner of insurance provider %s", strings.Join(providerIDs, ", "))
+	}
+
+	partnerID, err := partnerIDOf(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if err != nil {
+		return fmt.Errorf("failed to resolve partner of the caller: %w", err)
+	}
+	if !slices.Contains(providerIDs, partnerID) {
+		return fmt.Errorf("forbidden: not a partner of insurance provider %s", strings.Join(providerIDs, ", "))
+	}
+	return nil
+}
+
+// partnerIDOf returns a lookup of the insurance provider of the partner profile of a token's user
+func partnerIDOf(registeredPolicyService *services.RegisteredPolicyService) func(token string) (string, error) {
+	return func(token string) (string, error) {
+		profile, err := registeredPolicyService.GetInsurancePartnerProfile(token)
+		if err != nil {
+			return "", err
+		}
+		return registeredPolicyService.GetPartnerID(profile)
+	}
+}
+
+// canOverrideOwnership reports whether the caller may access resources of any farmer or provider
+func canOverrideOwnership(principal *Principal) bool {
+	return principal.Internal || principal.HasRole(RolePlatformAdmin)
+}
+
+// ownershipError writes the response for an error of boundFarmerID, requireFarmerOwner,
+// authorizeProvider or authorizeAnyProvider
+func ownershipError(c fiber.Ctx, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "Authentication is required"))
+	case strings.HasPrefix(err.Error(), "invalid"):
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	case strings.HasPrefix(err.Error(), "forbidden"):
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "You do not have access to this resource"))
+	}
+	slog.Error("Failed to check resource ownership", "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", "Failed to check resource ownership"))
+}
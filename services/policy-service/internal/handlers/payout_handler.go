@@ -14,14 +14,16 @@ import (
 )
 
 type PayoutHandler struct {
-	payoutService           *services.PayoutService
-	registeredPolicyService *services.RegisteredPolicyService
+	payoutService            *services.PayoutService
+	registeredPolicyService  *services.RegisteredPolicyService
+	payoutCalculationService *services.PayoutCalculationService
 }
 
-func NewPayoutHandler(payoutService *services.PayoutService, registeredPolicyService *services.RegisteredPolicyService) *PayoutHandler {
+func NewPayoutHandler(payoutService *services.PayoutService, registeredPolicyService *services.RegisteredPolicyService, payoutCalculationService *services.PayoutCalculationService) *PayoutHandler {
 	return &PayoutHandler{
-		payoutService:           payoutService,
-		registeredPolicyService: registeredPolicyService,
+		payoutService:            payoutService,
+		registeredPolicyService:  registeredPolicyService,
+		payoutCalculationService: payoutCalculationService,
 	}
 }
 
@@ -37,24 +39,25 @@ func (h *PayoutHandler) Register(app *fiber.App) {
 	// ============================================================================
 
 	// Farmer routes - read own payouts only
-	farmerGroup := payoutGroup.Group("/read-own")
+	farmerGroup := payoutGroup.Group("/read-own", RequireRoles(RoleFarmer, RolePlatformAdmin))
 	farmerGroup.Get("/list", h.GetFarmerOwnPayouts)                      // GET /payouts/read-own/list
 	farmerGroup.Get("/detail/:id", h.GetFarmerPayoutDetail)              // GET /payouts/read-own/detail/:id
 	farmerGroup.Get("/by-claim/:claim_id", h.GetFarmerPayoutByClaim)     // GET /payouts/read-own/by-claim/:claim_id
 	farmerGroup.Get("/by-policy/:policy_id", h.GetFarmerPayoutsByPolicy) // GET /payouts/read-own/by-policy/:policy_id
 	farmerGroup.Get("/by-farm/:farm_id", h.GetFarmerPayoutsByFarm)       // GET /payouts/read-own/by-farm/:farm_id
-	farmerPutGr := payoutGroup.Group("/update")
+	farmerPutGr := payoutGroup.Group("/update", RequireRoles(RoleFarmer))
 	farmerPutGr.Put("/confirm/:id", h.ConfirmPayout)
 
 	// Insurance Partner routes - read partner's payouts
-	partnerGroup := payoutGroup.Group("/read-partner")
+	partnerGroup := payoutGroup.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
 	partnerGroup.Get("/list", h.GetPartnerPayouts)                         // GET /payouts/read-partner/list (not implemented yet, would need service method)
 	partnerGroup.Get("/detail/:id", h.GetPartnerPayoutDetail)              // GET /payouts/read-partner/detail/:id
 	partnerGroup.Get("/by-policy/:policy_id", h.GetPartnerPayoutsByPolicy) // GET /payouts/read-partner/by-policy/:policy_id
 	partnerGroup.Get("/by-farm/:farm_id", h.GetPartnerPayoutsByFarm)       // GET /payouts/read-partner/by-farm/:farm_id
+	partnerGroup.Post("/preview", h.PreviewPayout)                         // POST /payouts/read-partner/preview
 
 	// Admin routes - full access to all payouts
-	adminReadGroup := payoutGroup.Group("/read-all")
+	adminReadGroup := payoutGroup.Group("/read-all", RequireRoles(RolePlatformAdmin))
 	adminReadGroup.Get("/detail/:id", h.GetPayoutDetailAdmin)              // GET /payouts/read-all/detail/:id
 	adminReadGroup.Get("/by-claim/:claim_id", h.GetPayoutByClaimAdmin)     // GET /payouts/read-all/by-claim/:claim_id
 	adminReadGroup.Get("/by-policy/:policy_id", h.GetPayoutsByPolicyAdmin) // GET /payouts/read-all/by-policy/:policy_id
@@ -68,10 +71,9 @@ func (h *PayoutHandler) Register(app *fiber.App) {
 
 // GetFarmerOwnPayouts retrieves all payouts for the authenticated farmer
 func (h *PayoutHandler) GetFarmerOwnPayouts(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	payouts, err := h.payoutService.GetPayoutsByFarmerID(c.Context(), userID)
@@ -90,10 +92,9 @@ func (h *PayoutHandler) GetFarmerOwnPayouts(c fiber.Ctx) error {
 
 // GetFarmerPayoutDetail retrieves a specific payout detail for the authenticated farmer
 func (h *PayoutHandler) GetFarmerPayoutDetail(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	payoutIDStr := c.Params("id")
@@ -123,10 +124,9 @@ func (h *PayoutHandler) GetFarmerPayoutDetail(c fiber.Ctx) error {
 
 // GetFarmerPayoutByClaim retrieves a payout for a specific claim owned by the farmer
 func (h *PayoutHandler) GetFarmerPayoutByClaim(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	claimIDStr := c.Params("claim_id")
@@ -159,10 +159,9 @@ func (h *PayoutHandler) GetFarmerPayoutByClaim(c fiber.Ctx) error {
 
 // GetFarmerPayoutsByPolicy retrieves payouts for a specific policy owned by the farmer
 func (h *PayoutHandler) GetFarmerPayoutsByPolicy(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policyIDStr := c.Params("policy_id")
@@ -196,10 +195,9 @@ func (h *PayoutHandler) GetFarmerPayoutsByPolicy(c fiber.Ctx) error {
 
 // GetFarmerPayoutsByFarm retrieves payouts for a specific farm owned by the farmer
 func (h *PayoutHandler) GetFarmerPayoutsByFarm(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	farmIDStr := c.Params("farm_id")
@@ -566,7 +564,7 @@ func (h *PayoutHandler) ConfirmPayout(c fiber.Ctx) error {
 			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body: "+err.Error()))
 	}
 
-	message, err := h.payoutService.ConfirmPayout(c.Context(), req, payoutID)
+	message, err := h.payoutService.ConfirmPayout(c.Context(), req, payoutID, userID)
 	if err != nil {
 		slog.Error("error confirming payout", "error", err)
 		if strings.Contains(err.Error(), "unauthorized") {
@@ -583,6 +581,49 @@ func (h *PayoutHandler) ConfirmPayout(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(message))
 }
 
+// PreviewPayout calculates the payout a base policy of the partner would make for a breach
+// without creating a claim
+func (h *PayoutHandler) PreviewPayout(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	var req models.PayoutPreviewRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body: "+err.Error()))
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("VALIDATION_FAILED", err.Error()))
+	}
+
+	partnerID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	calculation, err := h.payoutCalculationService.PreviewPayout(c.Context(), req, partnerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(http.StatusNotFound).JSON(
+				utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return c.Status(http.StatusForbidden).JSON(
+				utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to preview payouts of this base policy"))
+		}
+		slog.Error("Failed to preview payout", "base_policy_id", req.BasePolicyID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("CALCULATION_FAILED", "Failed to calculate payout"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(calculation))
+}
+
 // Helper function to extract partner ID from authorization token
 func (h *PayoutHandler) getPartnerIDFromToken(c fiber.Ctx) (string, error) {
 	tokenString := c.Get("Authorization")
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type PolicyAttachmentHandler struct {
+	attachmentService *services.PolicyAttachmentService
+}
+
+func NewPolicyAttachmentHandler(attachmentService *services.PolicyAttachmentService) *PolicyAttachmentHandler {
+	return &PolicyAttachmentHandler{attachmentService: attachmentService}
+}
+
+func (h *PolicyAttachmentHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	attachmentGr := protectedGr.Group("/policy-attachments")
+	attachmentGr.Post("/", h.CreateAttachment)         // POST   /policy-attachments - Create the next version, returns a presigned upload URL
+	attachmentGr.Post("/:id/confirm", h.ConfirmUpload) // POST   /policy-attachments/{id}/confirm - Confirm the file was uploaded
+	attachmentGr.Get("/", h.ListAttachments)           // GET    /policy-attachments?base_policy_id=...|registered_policy_id=...&document_type=...
+	attachmentGr.Get("/:id", h.GetAttachment)          // GET    /policy-attachments/{id}
+	attachmentGr.Put("/:id", h.UpdateAttachment)       // PUT    /policy-attachments/{id} - Update the title
+	attachmentGr.Delete("/:id", h.DeleteAttachment)    // DELETE /policy-attachments/{id}
+}
+
+func (h *PolicyAttachmentHandler) CreateAttachment(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	var req models.CreatePolicyAttachmentRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	response, err := h.attachmentService.CreateAttachment(c.Context(), req, userID)
+	if err != nil {
+		return attachmentError(c, "Failed to create policy attachment", err)
+	}
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(response))
+}
+
+func (h *PolicyAttachmentHandler) ConfirmUpload(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid attachment ID format"))
+	}
+
+	attachment, err := h.attachmentService.ConfirmUpload(c.Context(), id)
+	if err != nil {
+		return attachmentError(c, "Failed to confirm policy attachment upload", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(attachment))
+}
+
+func (h *PolicyAttachmentHandler) ListAttachments(c fiber.Ctx) error {
+	filter := models.PolicyAttachmentFilter{
+		DocumentType: models.PolicyAttachmentType(c.Query("document_type")),
+	}
+	if idStr := c.Query("base_policy_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_UUID", "Invalid base_policy_id format"))
+		}
+		filter.BasePolicyID = &id
+	}
+	if idStr := c.Query("registered_policy_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_UUID", "Invalid registered_policy_id format"))
+		}
+		filter.RegisteredPolicyID = &id
+	}
+	if pendingStr := c.Query("include_pending"); pendingStr != "" {
+		includePending, err := strconv.ParseBool(pendingStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", "include_pending must be true or false"))
+		}
+		filter.IncludePending = includePending
+	}
+
+	attachments, err := h.attachmentService.ListAttachments(c.Context(), filter)
+	if err != nil {
+		return attachmentError(c, "Failed to list policy attachments", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(attachments))
+}
+
+func (h *PolicyAttachmentHandler) GetAttachment(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid attachment ID format"))
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(c.Context(), id)
+	if err != nil {
+		return attachmentError(c, "Failed to get policy attachment", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(attachment))
+}
+
+func (h *PolicyAttachmentHandler) UpdateAttachment(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid attachment ID format"))
+	}
+
+	var req models.UpdatePolicyAttachmentRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	attachment, err := h.attachmentService.UpdateAttachment(c.Context(), id, req)
+	if err != nil {
+		return attachmentError(c, "Failed to update policy attachment", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(attachment))
+}
+
+func (h *PolicyAttachmentHandler) DeleteAttachment(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid attachment ID format"))
+	}
+
+	if err := h.attachmentService.DeleteAttachment(c.Context(), id); err != nil {
+		return attachmentError(c, "Failed to delete policy attachment", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"attachment_id": id,
+		"deleted":       true,
+	}))
+}
+
+// attachmentError maps a service error to a response: unknown policies and attachments are 404,
+// rejected input is 400, anything else is logged and 500
+func attachmentError(c fiber.Ctx, message string, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+	}
+	if strings.Contains(err.Error(), "invalid") {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+	}
+	slog.Error(message, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", message))
+}
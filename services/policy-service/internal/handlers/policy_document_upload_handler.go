@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/models"
+	"policy-service/internal/services"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type PolicyDocumentUploadHandler struct {
+	uploadService *services.PolicyDocumentUploadService
+}
+
+func NewPolicyDocumentUploadHandler(uploadService *services.PolicyDocumentUploadService) *PolicyDocumentUploadHandler {
+	return &PolicyDocumentUploadHandler{uploadService: uploadService}
+}
+
+func (h *PolicyDocumentUploadHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	uploadGr := protectedGr.Group("/policy-document-uploads", RequireRoles(RoleInsurerAdmin))
+	uploadGr.Post("/", h.CreateUpload)             // POST /policy-document-uploads - Returns a presigned URL to PUT the policy PDF to
+	uploadGr.Post("/:id/confirm", h.ConfirmUpload) // POST /policy-document-uploads/{id}/confirm - Check the uploaded file
+}
+
+func (h *PolicyDocumentUploadHandler) CreateUpload(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	var req models.CreatePolicyDocumentUploadRequest
+	if err := c.Bind().Body(&req); err != nil {
+		slog.Error("error parsing request", "error", err)
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	response, err := h.uploadService.CreateUpload(c.Context(), req, userID)
+	if err != nil {
+		return attachmentError(c, "Failed to create policy document upload", err)
+	}
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(response))
+}
+
+func (h *PolicyDocumentUploadHandler) ConfirmUpload(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid upload ID format"))
+	}
+
+	upload, err := h.uploadService.ConfirmUpload(c.Context(), id, userID)
+	if err != nil {
+		return attachmentError(c, "Failed to confirm policy document upload", err)
+	}
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(upload))
+}
@@ -19,14 +19,22 @@ type PolicyHandler struct {
 	basePolicyService       *services.BasePolicyService
 	riskAnalysisService     *services.RiskAnalysisCRUDService
 	cancelRequestService    *services.CancelRequestService
+	idempotencyStore        *services.IdempotencyStore
+	premiumScheduleService  *services.PremiumScheduleService
+	premiumPaymentService   *services.PremiumPaymentService
+	policyImportService     *services.PolicyImportService
 }
 
-func NewPolicyHandler(registeredPolicyService *services.RegisteredPolicyService, riskAnalysisService *services.RiskAnalysisCRUDService, basePolicyService *services.BasePolicyService, cancelRequestService *services.CancelRequestService) *PolicyHandler {
+func NewPolicyHandler(registeredPolicyService *services.RegisteredPolicyService, riskAnalysisService *services.RiskAnalysisCRUDService, basePolicyService *services.BasePolicyService, cancelRequestService *services.CancelRequestService, idempotencyStore *services.IdempotencyStore, premiumScheduleService *services.PremiumScheduleService, premiumPaymentService *services.PremiumPaymentService, policyImportService *services.PolicyImportService) *PolicyHandler {
 	return &PolicyHandler{
 		registeredPolicyService: registeredPolicyService,
 		basePolicyService:       basePolicyService,
 		riskAnalysisService:     riskAnalysisService,
 		cancelRequestService:    cancelRequestService,
+		idempotencyStore:        idempotencyStore,
+		premiumScheduleService:  premiumScheduleService,
+		premiumPaymentService:   premiumPaymentService,
+		policyImportService:     policyImportService,
 	}
 }
 
@@ -37,7 +45,7 @@ func (h *PolicyHandler) Register(app *fiber.App) {
 	policyGroup := protectedGr.Group("/policies")
 
 	// Policy registration endpoint
-	policyGroup.Post("/register", h.RegisterPolicy) // POST /policies/register - Register a new policy
+	policyGroup.Post("/register", RequireRoles(RoleFarmer), Idempotent(h.idempotencyStore, "RegisterPolicy", h.RegisterPolicy)) // POST /policies/register - Register a new policy, retried safely with an Idempotency-Key header
 
 	// ============================================================================
 	// PERMISSION-BASED ROUTES
@@ -45,30 +53,41 @@ func (h *PolicyHandler) Register(app *fiber.App) {
 	// ============================================================================
 
 	// Farmer routes - read own policies only
-	farmerGroup := policyGroup.Group("/read-own")
+	farmerGroup := policyGroup.Group("/read-own", RequireRoles(RoleFarmer, RolePlatformAdmin))
 	farmerGroup.Get("/list", h.GetFarmerOwnPolicies)                                                   // GET /policies/read-own/list
 	farmerGroup.Get("/detail/:id", h.GetFarmerPolicyDetail)                                            // GET /policies/read-own/detail/:id
 	farmerGroup.Get("/stats/overview", h.GetStatsOverview)                                             // GET /policies/read-own/stats/overview
 	farmerGroup.Get("/monitoring-data/:farm_id", h.GetFarmerMonitoringData)                            // GET /policies/read-own/monitoring-data/:farm_id
 	farmerGroup.Get("/monitoring-data/:farm_id/:parameter_name", h.GetFarmerMonitoringDataByParameter) // GET /policies/read-own/monitoring-data/:farm_id/:parameter_name
 	farmerGroup.Get("/underwriting/:policy_id", h.GetFarmerUnderwriting)
+	farmerGroup.Get("/premium-schedule/:policy_id", h.GetFarmerPremiumSchedule) // GET /policies/read-own/premium-schedule/:policy_id
+	farmerCreateGroup := policyGroup.Group("/create-own", RequireRoles(RoleFarmer))
+	farmerCreateGroup.Post("/premium-schedule/:policy_id", h.CreatePremiumSchedule)                                                       // POST /policies/create-own/premium-schedule/:policy_id - Pay the premium in installments
+	farmerCreateGroup.Post("/premium-payment/:policy_id", Idempotent(h.idempotencyStore, "CreatePremiumPayment", h.CreatePremiumPayment)) // POST /policies/create-own/premium-payment/:policy_id - Open a checkout for the premium due
 
 	// Insurance Partner routes - read/manage partner's policies
-	partnerGroup := policyGroup.Group("/read-partner")
+	partnerGroup := policyGroup.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
 	partnerGroup.Get("/list", h.GetPartnerPolicies)                                           // GET /policies/read-partner/list
 	partnerGroup.Get("/detail/:id", h.GetPartnerPolicyDetail)                                 // GET /policies/read-partner/detail/:id
 	partnerGroup.Get("/stats", h.GetPartnerPolicyStats)                                       // GET /policies/read-partner/stats
 	partnerGroup.Get("/monitoring-data/:farm_id/:parameter_name", h.GetPartnerMonitoringData) // GET /policies/read-partner/monitoring-data/:farm_id/:parameter_name
 	partnerGroup.Get("/underwriting/:id", h.GetUnderwritingsByPolicyID)
 	partnerGroup.Get("/by-base-policy/:base_policy_id", h.GetByBasePolicy)
-	partnerCreateGroup := policyGroup.Group("/create-partner")
-	partnerCreateGroup.Post("/underwriting/:id", h.CreatePartnerPolicyUnderwriting) // PATCH /policies/update-partner/underwriting/:id]
+	partnerGroup.Get("/premium-schedule/:policy_id", h.GetPartnerPremiumSchedule)       // GET /policies/read-partner/premium-schedule/:policy_id
+	partnerGroup.Get("/monitoring-calendar/:policy_id", h.GetPartnerMonitoringCalendar) // GET /policies/read-partner/monitoring-calendar/:policy_id - Monitored and blackout windows over the coverage
+	partnerGroup.Get("/trigger-evaluation/:policy_id", h.GetPartnerTriggerEvaluation)   // GET /policies/read-partner/trigger-evaluation/:policy_id - Evaluate the triggers on stored data without generating a claim
+	partnerGroup.Get("/import/list", h.GetPartnerPolicyImports)                         // GET /policies/read-partner/import/list
+	partnerGroup.Get("/import/:id", h.GetPartnerPolicyImport)                           // GET /policies/read-partner/import/:id
+	partnerGroup.Get("/import/:id/error-report", h.DownloadPolicyImportErrorReport)     // GET /policies/read-partner/import/:id/error-report - CSV of the rejected rows
+	partnerCreateGroup := policyGroup.Group("/create-partner", RequireRoles(RoleInsurerAdmin))
+	partnerCreateGroup.Post("/underwriting/:id", h.CreatePartnerPolicyUnderwriting)                                // PATCH /policies/update-partner/underwriting/:id]
+	partnerCreateGroup.Post("/import", Idempotent(h.idempotencyStore, "CreatePolicyImport", h.CreatePolicyImport)) // POST /policies/create-partner/import - Register policies in bulk from a CSV/XLSX file
 	partnerGroup.Post("/monthly-data-cost", h.GetMonthlyDataCost)
 	partnerGroup.Get("/active", h.GetActiveContracts)
 	partnerGroup.Get("/profile-cancel/ready-check", h.GetCancelProfileCheck)
 
 	// Admin routes - full access to all policies
-	adminReadGroup := policyGroup.Group("/read-all")
+	adminReadGroup := policyGroup.Group("/read-all", RequireRoles(RolePlatformAdmin))
 	adminReadGroup.Get("/list", h.GetAllPoliciesAdmin)                         // GET /policies/read-all/list
 	adminReadGroup.Get("/detail/:id", h.GetPolicyDetailAdmin)                  // GET /policies/read-all/detail/:id
 	adminReadGroup.Get("/stats", h.GetAllPolicyStatsAdmin)                     // GET /policies/read-all/stats
@@ -76,13 +95,16 @@ func (h *PolicyHandler) Register(app *fiber.App) {
 	adminReadGroup.Get("/monitoring-data", h.GetAllMonitoringData)             // GET /policies/read-all/monitoring-data - Get all monitoring data with policy status
 	adminReadGroup.Get("/monitoring-data/:farm_id", h.GetMonitoringDataByFarm) // GET /policies/read-all/monitoring-data/:farm_id - Get monitoring data by farm
 	adminReadGroup.Get("/underwriting", h.GetAllUnderwriting)
+	adminReadGroup.Get("/premium-schedule/:policy_id", h.GetPremiumScheduleAdmin)       // GET /policies/read-all/premium-schedule/:policy_id
+	adminReadGroup.Get("/monitoring-calendar/:policy_id", h.GetMonitoringCalendarAdmin) // GET /policies/read-all/monitoring-calendar/:policy_id
+	adminReadGroup.Get("/trigger-evaluation/:policy_id", h.GetTriggerEvaluationAdmin)   // GET /policies/read-all/trigger-evaluation/:policy_id
 
-	adminUpdateGroup := policyGroup.Group("/update-any")
+	adminUpdateGroup := policyGroup.Group("/update-any", RequireRoles(RolePlatformAdmin))
 	adminUpdateGroup.Patch("/status/:id", h.UpdatePolicyStatusAdmin)             // PATCH /policies/update-any/status/:id
 	adminUpdateGroup.Patch("/underwriting/:id", h.UpdatePolicyUnderwritingAdmin) // PATCH /policies/update-any/underwriting/:id
 
 	// Admin test routes
-	adminTestGroup := policyGroup.Group("/test")
+	adminTestGroup := policyGroup.Group("/test", RequireRoles(RolePlatformAdmin))
 	adminTestGroup.Post("/trigger-claim/:policy_id", h.TestTriggerClaim) // POST /policies/test/trigger-claim/:policy_id - Test claim generation with injected data
 }
 
@@ -284,10 +306,9 @@ func (h *PolicyHandler) GetPoliciesWithFilter(c fiber.Ctx) error {
 
 // GetFarmerOwnPolicies retrieves all policies for the authenticated farmer
 func (h *PolicyHandler) GetFarmerOwnPolicies(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policies, err := h.registeredPolicyService.GetPoliciesByFarmerID(userID)
@@ -306,10 +327,9 @@ func (h *PolicyHandler) GetFarmerOwnPolicies(c fiber.Ctx) error {
 
 // GetFarmerPolicyDetail retrieves a specific policy detail for the authenticated farmer
 func (h *PolicyHandler) GetFarmerPolicyDetail(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policyIDStr := c.Params("id")
@@ -319,7 +339,7 @@ func (h *PolicyHandler) GetFarmerPolicyDetail(c fiber.Ctx) error {
 			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
 	}
 
-	policy, err := h.registeredPolicyService.GetPolicyByID(policyID)
+	policy, err := h.registeredPolicyService.GetPolicyDetail(c.Context(), policyID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(http.StatusNotFound).JSON(
@@ -418,7 +438,7 @@ func (h *PolicyHandler) GetPartnerPolicyDetail(c fiber.Ctx) error {
 			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
 	}
 
-	policy, err := h.registeredPolicyService.GetPolicyByID(policyID)
+	policy, err := h.registeredPolicyService.GetPolicyDetail(c.Context(), policyID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(http.StatusNotFound).JSON(
@@ -445,25 +465,27 @@ func (h *PolicyHandler) GetPartnerPolicyStats(c fiber.Ctx) error {
 		return c.Status(http.StatusUnauthorized).JSON(
 			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
 	}
-	partnerIDQuery := c.Query("partner_id")
-	partnerProfileID, err := h.getPartnerIDFromToken(c)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(
-			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
-	}
-	if partnerIDQuery != partnerProfileID {
-		return c.Status(http.StatusForbidden).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "You do not have permission to view this policy"))
+
+	// partner_id defaults to the partner of the caller, any other one must be theirs too
+	partnerID := c.Query("partner_id")
+	if partnerID == "" {
+		var err error
+		partnerID, err = h.getPartnerIDFromToken(c)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(
+				utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+		}
+	} else if err := authorizeProvider(c, partnerID, partnerIDOf(h.registeredPolicyService)); err != nil {
+		return ownershipError(c, err)
 	}
 
-	stats, err := h.registeredPolicyService.GetPolicyStats(partnerIDQuery)
+	stats, err := h.registeredPolicyService.GetPolicyStats(partnerID)
 	if err != nil {
-		slog.Error("Failed to get partner policy stats", "provider_id", userID, "error", err)
+		slog.Error("Failed to get partner policy stats", "provider_id", partnerID, "error", err)
 		return c.Status(http.StatusInternalServerError).JSON(
 			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve statistics"))
 	}
 
-	stats["provider_id"] = userID
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(stats))
 }
 
@@ -764,7 +786,7 @@ func (h *PolicyHandler) GetPolicyDetailAdmin(c fiber.Ctx) error {
 			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
 	}
 
-	policy, err := h.registeredPolicyService.GetPolicyByID(policyID)
+	policy, err := h.registeredPolicyService.GetPolicyDetail(c.Context(), policyID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(http.StatusNotFound).JSON(
@@ -794,7 +816,7 @@ func (h *PolicyHandler) GetAllPolicyStatsAdmin(c fiber.Ctx) error {
 			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve statistics"))
 	}
 
-	stats["requested_by"] = userID
+	stats.RequestedBy = userID
 	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(stats))
 }
 
@@ -822,7 +844,7 @@ func (h *PolicyHandler) UpdatePolicyStatusAdmin(c fiber.Ctx) error {
 			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
 	}
 
-	err = h.registeredPolicyService.UpdatePolicyStatus(policyID, req.Status)
+	err = h.registeredPolicyService.UpdatePolicyStatus(c.Context(), policyID, req.Status)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(http.StatusNotFound).JSON(
@@ -864,7 +886,7 @@ func (h *PolicyHandler) UpdatePolicyUnderwritingAdmin(c fiber.Ctx) error {
 			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
 	}
 
-	err = h.registeredPolicyService.UpdateUnderwritingStatus(policyID, req.UnderwritingStatus)
+	err = h.registeredPolicyService.UpdateUnderwritingStatus(c.Context(), policyID, req.UnderwritingStatus)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(http.StatusNotFound).JSON(
@@ -883,10 +905,9 @@ func (h *PolicyHandler) UpdatePolicyUnderwritingAdmin(c fiber.Ctx) error {
 }
 
 func (h *PolicyHandler) GetStatsOverview(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	stats, err := h.registeredPolicyService.GetStatsOverview(userID)
@@ -901,10 +922,9 @@ func (h *PolicyHandler) GetStatsOverview(c fiber.Ctx) error {
 
 // GetFarmerMonitoringData retrieves monitoring data for a farmer's own farm
 func (h *PolicyHandler) GetFarmerMonitoringData(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	farmIDStr := c.Params("farm_id")
@@ -954,10 +974,9 @@ func (h *PolicyHandler) GetFarmerMonitoringData(c fiber.Ctx) error {
 
 // GetFarmerMonitoringDataByParameter retrieves monitoring data for a specific parameter from a farmer's own farm
 func (h *PolicyHandler) GetFarmerMonitoringDataByParameter(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	farmIDStr := c.Params("farm_id")
@@ -1017,10 +1036,9 @@ func (h *PolicyHandler) GetFarmerMonitoringDataByParameter(c fiber.Ctx) error {
 }
 
 func (h *PolicyHandler) GetFarmerUnderwriting(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policyIDStr := c.Params("policy_id")
@@ -1220,6 +1238,365 @@ func (h *PolicyHandler) GetCancelProfileCheck(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(utils.CreateSuccessResponse(res))
 }
 
+// ============================================================================
+// PREMIUM SCHEDULE OPERATIONS
+// ============================================================================
+
+// CreatePremiumSchedule splits the premium of the farmer's policy into installments
+func (h *PolicyHandler) CreatePremiumSchedule(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	var req models.CreatePremiumScheduleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	schedule, err := h.premiumScheduleService.CreateSchedule(c.Context(), policyID, userID, req)
+	if err != nil {
+		return h.premiumScheduleError(c, policyID, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(schedule))
+}
+
+// GetFarmerPremiumSchedule retrieves the premium schedule of the farmer's policy
+func (h *PolicyHandler) GetFarmerPremiumSchedule(c fiber.Ctx) error {
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	schedule, err := h.premiumScheduleService.GetScheduleForFarmer(c.Context(), policyID, userID)
+	if err != nil {
+		return h.premiumScheduleError(c, policyID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(schedule))
+}
+
+// GetPartnerPremiumSchedule retrieves the premium schedule of one of the partner's policies
+func (h *PolicyHandler) GetPartnerPremiumSchedule(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	schedule, err := h.premiumScheduleService.GetScheduleForPartner(c.Context(), policyID, partnerProfileID)
+	if err != nil {
+		return h.premiumScheduleError(c, policyID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(schedule))
+}
+
+// GetPremiumScheduleAdmin retrieves the premium schedule of any policy (admin access)
+func (h *PolicyHandler) GetPremiumScheduleAdmin(c fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	schedule, err := h.premiumScheduleService.GetSchedule(c.Context(), policyID)
+	if err != nil {
+		return h.premiumScheduleError(c, policyID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(schedule))
+}
+
+// CreatePremiumPayment opens a gateway checkout for what the farmer owes next on their policy
+func (h *PolicyHandler) CreatePremiumPayment(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	var req models.CreatePremiumPaymentRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	intent, err := h.premiumPaymentService.CreatePaymentIntent(c.Context(), policyID, userID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "payment-service") || strings.Contains(err.Error(), "payment link") {
+			slog.Error("Failed to create premium payment link", "policy_id", policyID, "error", err)
+			return c.Status(http.StatusBadGateway).JSON(
+				utils.CreateErrorResponse("PAYMENT_GATEWAY_ERROR", "Failed to create payment link"))
+		}
+		return h.premiumScheduleError(c, policyID, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(utils.CreateSuccessResponse(intent))
+}
+
+func (h *PolicyHandler) premiumScheduleError(c fiber.Ctx, policyID uuid.UUID, err error) error {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "not found"):
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", errMsg))
+	case strings.Contains(errMsg, "unauthorized"):
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to access this policy"))
+	case strings.Contains(errMsg, "invalid") || strings.Contains(errMsg, "validation"):
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", errMsg))
+	}
+	slog.Error("premium schedule operation failed", "policy_id", policyID, "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL", "Failed to process premium schedule"))
+}
+
+// GetPartnerMonitoringCalendar previews when the triggers of a partner's policy are monitored and
+// when they are paused by blackout periods
+func (h *PolicyHandler) GetPartnerMonitoringCalendar(c fiber.Ctx) error {
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+	return h.monitoringCalendar(c, partnerProfileID)
+}
+
+// GetMonitoringCalendarAdmin previews the monitoring calendar of any policy (admin access)
+func (h *PolicyHandler) GetMonitoringCalendarAdmin(c fiber.Ctx) error {
+	return h.monitoringCalendar(c, "")
+}
+
+func (h *PolicyHandler) monitoringCalendar(c fiber.Ctx, providerID string) error {
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	calendar, err := h.registeredPolicyService.GetMonitoringCalendar(policyID, providerID)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.Contains(errMsg, "not found"):
+			return c.Status(http.StatusNotFound).JSON(
+				utils.CreateErrorResponse("NOT_FOUND", "Policy not found"))
+		case strings.Contains(errMsg, "unauthorized"):
+			return c.Status(http.StatusForbidden).JSON(
+				utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to view this policy"))
+		case strings.Contains(errMsg, "invalid"):
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", errMsg))
+		}
+		slog.Error("Failed to build monitoring calendar", "policy_id", policyID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to build monitoring calendar"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(calendar))
+}
+
+// GetPartnerTriggerEvaluation evaluates the triggers of a partner's policy against its stored
+// monitoring data and reports whether a claim would be generated
+func (h *PolicyHandler) GetPartnerTriggerEvaluation(c fiber.Ctx) error {
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+	return h.triggerEvaluation(c, partnerProfileID)
+}
+
+// GetTriggerEvaluationAdmin evaluates the triggers of any policy (admin access)
+func (h *PolicyHandler) GetTriggerEvaluationAdmin(c fiber.Ctx) error {
+	return h.triggerEvaluation(c, "")
+}
+
+func (h *PolicyHandler) triggerEvaluation(c fiber.Ctx, providerID string) error {
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	evaluation, err := h.registeredPolicyService.EvaluatePolicyTriggers(c.Context(), policyID, providerID)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.Contains(errMsg, "not found"):
+			return c.Status(http.StatusNotFound).JSON(
+				utils.CreateErrorResponse("NOT_FOUND", "Policy not found"))
+		case strings.Contains(errMsg, "unauthorized"):
+			return c.Status(http.StatusForbidden).JSON(
+				utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to view this policy"))
+		}
+		slog.Error("Failed to evaluate policy triggers", "policy_id", policyID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("EVALUATION_FAILED", "Failed to evaluate policy triggers"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(evaluation))
+}
+
+// ============================================================================
+// BULK POLICY IMPORT
+// ============================================================================
+
+// CreatePolicyImport uploads a CSV/XLSX file of policies to register for the partner's farmers.
+// The rows are registered in the background, the response is the queued import.
+func (h *PolicyHandler) CreatePolicyImport(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	var req models.CreatePolicyImportRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "Invalid request body"))
+	}
+
+	token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+	partnerUserIDs, err := h.registeredPolicyService.GetAllUserIDsFromInsuranceProvider(partnerProfileID, token)
+	if err != nil {
+		slog.Error("error retrieving partner user ids", "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("INTERNAL", "error retrieving partner user ids"))
+	}
+
+	job, err := h.policyImportService.CreateImport(c.Context(), req, partnerProfileID, userID, partnerUserIDs)
+	if err != nil {
+		return h.policyImportError(c, err)
+	}
+
+	return c.Status(http.StatusAccepted).JSON(utils.CreateSuccessResponse(job))
+}
+
+// GetPartnerPolicyImports lists the imports of the partner, newest first
+func (h *PolicyHandler) GetPartnerPolicyImports(c fiber.Ctx) error {
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	jobs, err := h.policyImportService.GetImportsByProvider(c.Context(), partnerProfileID)
+	if err != nil {
+		return h.policyImportError(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"imports": jobs,
+		"count":   len(jobs),
+	}))
+}
+
+// GetPartnerPolicyImport retrieves the status and row counters of an import
+func (h *PolicyHandler) GetPartnerPolicyImport(c fiber.Ctx) error {
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	importID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid import ID format"))
+	}
+
+	job, err := h.policyImportService.GetImport(c.Context(), importID, partnerProfileID)
+	if err != nil {
+		return h.policyImportError(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(job))
+}
+
+// DownloadPolicyImportErrorReport downloads the rejected rows of an import as a CSV file
+func (h *PolicyHandler) DownloadPolicyImportErrorReport(c fiber.Ctx) error {
+	partnerProfileID, err := h.getPartnerIDFromToken(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", err.Error()))
+	}
+
+	importID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid import ID format"))
+	}
+
+	report, err := h.policyImportService.GetErrorReport(c.Context(), importID, partnerProfileID)
+	if err != nil {
+		return h.policyImportError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="import-%s-errors.csv"`, importID))
+	return c.Status(http.StatusOK).Send(report)
+}
+
+func (h *PolicyHandler) policyImportError(c fiber.Ctx, err error) error {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "not found"):
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", errMsg))
+	case strings.Contains(errMsg, "unauthorized"):
+		return c.Status(http.StatusForbidden).JSON(
+			utils.CreateErrorResponse("FORBIDDEN", "You do not have permission to access this import"))
+	case strings.Contains(errMsg, "invalid") || strings.Contains(errMsg, "validation"):
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", errMsg))
+	}
+	slog.Error("policy import operation failed", "error", err)
+	return c.Status(http.StatusInternalServerError).JSON(
+		utils.CreateErrorResponse("INTERNAL", "Failed to process policy import"))
+}
+
 // Helper function to extract partner ID from authorization token
 func (h *PolicyHandler) getPartnerIDFromToken(c fiber.Ctx) (string, error) {
 	tokenString := c.Get("Authorization")
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	utils "agrisa_utils"
+	"log/slog"
+	"math"
+	"net/http"
+	"policy-service/internal/services"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+)
+
+// RateLimited limits how often a user calls next, sharing the bucket of the group with the other
+// routes of the group. Rejected requests get 429 with Retry-After in seconds. Other services are
+// not limited, and when Redis is unavailable requests are served without a limit.
+func RateLimited(limiter *services.RateLimiter, group string, next fiber.Handler) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := principalFrom(c)
+		if principal == nil || principal.Internal {
+			return next(c)
+		}
+
+		result, err := limiter.Take(c.Context(), group, principal.UserID, principal.ProviderIDs())
+		if err != nil {
+			slog.Error("rate limit check failed, processing request without it", "group", group, "error", err)
+			return next(c)
+		}
+
+		c.Set(rateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Set(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			slog.Warn("Rate limited request",
+				"group", group,
+				"user_id", principal.UserID,
+				"path", c.Path(),
+				"retry_after_seconds", retryAfter)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.Status(http.StatusTooManyRequests).JSON(
+				utils.CreateErrorResponse("RATE_LIMITED", "Too many requests, retry after "+strconv.Itoa(retryAfter)+" seconds"))
+		}
+		return next(c)
+	}
+}
@@ -15,12 +15,16 @@ import (
 )
 
 type RiskAnalysisHandler struct {
-	riskAnalysisService *services.RiskAnalysisCRUDService
+	riskAnalysisService     *services.RiskAnalysisCRUDService
+	registeredPolicyService *services.RegisteredPolicyService
+	rateLimiter             *services.RateLimiter
 }
 
-func NewRiskAnalysisHandler(riskAnalysisService *services.RiskAnalysisCRUDService) *RiskAnalysisHandler {
+func NewRiskAnalysisHandler(riskAnalysisService *services.RiskAnalysisCRUDService, registeredPolicyService *services.RegisteredPolicyService, rateLimiter *services.RateLimiter) *RiskAnalysisHandler {
 	return &RiskAnalysisHandler{
-		riskAnalysisService: riskAnalysisService,
+		riskAnalysisService:     riskAnalysisService,
+		registeredPolicyService: registeredPolicyService,
+		rateLimiter:             rateLimiter,
 	}
 }
 
@@ -36,30 +40,31 @@ func (h *RiskAnalysisHandler) Register(app *fiber.App) {
 	// ============================================================================
 
 	// Farmer routes - read own risk analyses only
-	farmerGroup := riskGroup.Group("/read-own")
+	farmerGroup := riskGroup.Group("/read-own", RequireRoles(RoleFarmer, RolePlatformAdmin))
 	farmerGroup.Get("/by-policy/:policy_id", h.GetByPolicyIDOwn)    // GET /risk-analysis/read-own/by-policy/:policy_id
 	farmerGroup.Get("/latest/:policy_id", h.GetLatestByPolicyIDOwn) // GET /risk-analysis/read-own/latest/:policy_id
 
 	// Partner routes - read partner's risk analyses
-	partnerGroup := riskGroup.Group("/read-partner")
+	partnerGroup := riskGroup.Group("/read-partner", RequireRoles(RoleInsurerAdmin))
 	partnerGroup.Get("/by-policy/:policy_id", h.GetByPolicyID)    // GET /risk-analysis/read-partner/by-policy/:policy_id
 	partnerGroup.Get("/latest/:policy_id", h.GetLatestByPolicyID) // GET /risk-analysis/read-partner/latest/:policy_id
 	partnerGroup.Get("/:id", h.GetByID)                           // GET /risk-analysis/read-partner/:id
 
 	// Admin routes - full access to all risk analyses
-	adminReadGroup := riskGroup.Group("/read-all")
+	adminReadGroup := riskGroup.Group("/read-all", RequireRoles(RolePlatformAdmin))
 	adminReadGroup.Get("/", h.GetAll)                               // GET /risk-analysis/read-all
 	adminReadGroup.Get("/by-policy/:policy_id", h.GetByPolicyID)    // GET /risk-analysis/read-all/by-policy/:policy_id
 	adminReadGroup.Get("/latest/:policy_id", h.GetLatestByPolicyID) // GET /risk-analysis/read-all/latest/:policy_id
 	adminReadGroup.Get("/:id", h.GetByID)                           // GET /risk-analysis/read-all/:id
 
 	// Admin delete routes
-	adminDeleteGroup := riskGroup.Group("/delete-any")
+	adminDeleteGroup := riskGroup.Group("/delete-any", RequireRoles(RolePlatformAdmin))
 	adminDeleteGroup.Delete("/:id", h.Delete) // DELETE /risk-analysis/delete-any/:id
 
 	// Admin/Partner create routes
-	createGroup := riskGroup.Group("/create")
-	createGroup.Post("/", h.Create) // POST /risk-analysis/create
+	createGroup := riskGroup.Group("/create", RequireRoles(RoleInsurerAdmin, RolePlatformAdmin))
+	createGroup.Post("/", h.Create)                                                                                 // POST /risk-analysis/create
+	createGroup.Post("/rerun/:policy_id", RateLimited(h.rateLimiter, services.RateLimitGroupRiskAnalysis, h.Rerun)) // POST /risk-analysis/create/rerun/:policy_id?force=true
 }
 
 // ============================================================================
@@ -68,10 +73,9 @@ func (h *RiskAnalysisHandler) Register(app *fiber.App) {
 
 // GetByPolicyIDOwn retrieves all risk analyses for a farmer's own policy
 func (h *RiskAnalysisHandler) GetByPolicyIDOwn(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policyIDStr := c.Params("policy_id")
@@ -105,10 +109,9 @@ func (h *RiskAnalysisHandler) GetByPolicyIDOwn(c fiber.Ctx) error {
 
 // GetLatestByPolicyIDOwn retrieves the most recent risk analysis for a farmer's own policy
 func (h *RiskAnalysisHandler) GetLatestByPolicyIDOwn(c fiber.Ctx) error {
-	userID := c.Get("X-User-ID")
-	if userID == "" {
-		return c.Status(http.StatusUnauthorized).JSON(
-			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	userID, err := boundFarmerID(c)
+	if err != nil {
+		return ownershipError(c, err)
 	}
 
 	policyIDStr := c.Params("policy_id")
@@ -390,3 +393,49 @@ func (h *RiskAnalysisHandler) Create(c fiber.Ctx) error {
 		"risk_analysis": analysis,
 	}))
 }
+
+// Rerun queues the AI risk analysis of a policy under review. Without force=true the job keeps
+// the latest analysis when the policy, farm and monitoring data have not changed since.
+func (h *RiskAnalysisHandler) Rerun(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+
+	policyID, err := uuid.Parse(c.Params("policy_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_UUID", "Invalid policy ID format"))
+	}
+
+	force := false
+	if forceStr := c.Query("force"); forceStr != "" {
+		force, err = strconv.ParseBool(forceStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", "force must be true or false"))
+		}
+	}
+
+	jobID, err := h.registeredPolicyService.RequestRiskAnalysis(c.Context(), policyID, force)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(http.StatusNotFound).JSON(
+				utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("BAD_REQUEST", err.Error()))
+		}
+		slog.Error("Failed to request risk analysis", "policy_id", policyID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("INTERNAL_SERVER_ERROR", "Failed to request risk analysis"))
+	}
+
+	return c.Status(http.StatusAccepted).JSON(utils.CreateSuccessResponse(map[string]any{
+		"job_id":               jobID,
+		"registered_policy_id": policyID,
+		"force":                force,
+	}))
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"policy-service/internal/worker"
+	"strconv"
+	"strings"
+
+	utils "agrisa_utils"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// maxDeadLetterJobsLimit bounds a single listing of the dead-letter store
+const maxDeadLetterJobsLimit = 500
+
+// maxJobStatusesLimit bounds a single listing of job statuses
+const maxJobStatusesLimit = 200
+
+type WorkerHandler struct {
+	workerManager *worker.WorkerManagerV2
+}
+
+func NewWorkerHandler(workerManager *worker.WorkerManagerV2) *WorkerHandler {
+	return &WorkerHandler{
+		workerManager: workerManager,
+	}
+}
+
+func (h *WorkerHandler) Register(app *fiber.App) {
+	protectedGr := app.Group("policy/protected/api/v2")
+
+	// Worker routes
+	workerGroup := protectedGr.Group("/workers", RequireRoles(RolePlatformAdmin))
+
+	// Admin routes - jobs that failed all of their retries
+	adminReadGroup := workerGroup.Group("/read-all")
+	adminReadGroup.Get("/dead-letter-jobs", h.ListDeadLetterJobs) // GET /workers/read-all/dead-letter-jobs
+	adminReadGroup.Get("/cron-jobs", h.GetCronJobMetrics)         // GET /workers/read-all/cron-jobs
+
+	adminUpdateGroup := workerGroup.Group("/update-any")
+	adminUpdateGroup.Post("/dead-letter-jobs/:id/requeue", h.RequeueDeadLetterJob) // POST /workers/update-any/dead-letter-jobs/:id/requeue
+
+	// Job status routes, polled by UIs waiting on background work
+	jobGroup := protectedGr.Group("/jobs")
+
+	// Own routes - jobs submitted by the caller
+	ownGroup := jobGroup.Group("/read-own")
+	ownGroup.Get("/list", h.ListOwnJobStatuses) // GET /jobs/read-own/list?type=&status=
+	ownGroup.Get("/:id", h.GetOwnJobStatus)     // GET /jobs/read-own/:id
+
+	// Admin routes - all tracked jobs
+	adminJobGroup := jobGroup.Group("/read-all", RequireRoles(RolePlatformAdmin))
+	adminJobGroup.Get("/list", h.ListJobStatuses) // GET /jobs/read-all/list?type=&status=
+	adminJobGroup.Get("/:id", h.GetJobStatus)     // GET /jobs/read-all/:id
+}
+
+// ListDeadLetterJobs lists failed jobs, most recent first. Query: job_type, include_requeued, limit.
+func (h *WorkerHandler) ListDeadLetterJobs(c fiber.Ctx) error {
+	limit := 50 // default
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 {
+			limit = min(l, maxDeadLetterJobsLimit)
+		}
+	}
+
+	includeRequeued := false
+	if includeParam := c.Query("include_requeued"); includeParam != "" {
+		value, err := strconv.ParseBool(includeParam)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", "include_requeued must be true or false"))
+		}
+		includeRequeued = value
+	}
+
+	jobType := c.Query("job_type")
+	jobs, err := h.workerManager.ListDeadLetterJobs(c.Context(), jobType, includeRequeued, limit)
+	if err != nil {
+		slog.Error("Failed to list dead letter jobs", "job_type", jobType, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve dead letter jobs"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"dead_letter_jobs": jobs,
+		"count":            len(jobs),
+		"limit":            limit,
+	}))
+}
+
+// RequeueDeadLetterJob sends a failed job back to the pool it failed in
+func (h *WorkerHandler) RequeueDeadLetterJob(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_ID", "Invalid dead letter job ID format"))
+	}
+
+	job, err := h.workerManager.RequeueDeadLetterJob(c.Context(), id)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(http.StatusNotFound).JSON(
+				utils.CreateErrorResponse("NOT_FOUND", err.Error()))
+		case strings.Contains(err.Error(), "invalid"):
+			return c.Status(http.StatusBadRequest).JSON(
+				utils.CreateErrorResponse("INVALID_REQUEST", err.Error()))
+		default:
+			slog.Error("Failed to requeue dead letter job", "id", id, "error", err)
+			return c.Status(http.StatusInternalServerError).JSON(
+				utils.CreateErrorResponse("REQUEUE_FAILED", "Failed to requeue dead letter job"))
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"dead_letter_id": id,
+		"job_id":         job.JobID,
+		"job_type":       job.Type,
+	}))
+}
+
+// GetCronJobMetrics lists the cron jobs with their next run and run counts
+func (h *WorkerHandler) GetCronJobMetrics(c fiber.Ctx) error {
+	metrics, err := h.workerManager.GetCronJobMetrics(c.Context())
+	if err != nil {
+		slog.Error("Failed to get cron job metrics", "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve cron jobs"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"cron_jobs": metrics,
+		"count":     len(metrics),
+	}))
+}
+
+// GetOwnJobStatus returns the status of a job submitted by the caller
+func (h *WorkerHandler) GetOwnJobStatus(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+	return h.getJobStatus(c, userID)
+}
+
+// GetJobStatus returns the status of any tracked job
+func (h *WorkerHandler) GetJobStatus(c fiber.Ctx) error {
+	return h.getJobStatus(c, "")
+}
+
+// ListOwnJobStatuses lists the jobs submitted by the caller. Query: type, status, limit.
+func (h *WorkerHandler) ListOwnJobStatuses(c fiber.Ctx) error {
+	userID := c.Get("X-User-ID")
+	if userID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(
+			utils.CreateErrorResponse("UNAUTHORIZED", "User ID is required"))
+	}
+	return h.listJobStatuses(c, userID)
+}
+
+// ListJobStatuses lists all tracked jobs. Query: type, status, limit.
+func (h *WorkerHandler) ListJobStatuses(c fiber.Ctx) error {
+	return h.listJobStatuses(c, "")
+}
+
+// getJobStatus looks up a job; a non-empty submittedBy hides the jobs of other users
+func (h *WorkerHandler) getJobStatus(c fiber.Ctx, submittedBy string) error {
+	jobID := c.Params("id")
+	status, err := h.workerManager.GetJobStatus(c.Context(), jobID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(http.StatusNotFound).JSON(
+				utils.CreateErrorResponse("NOT_FOUND", "Job not found"))
+		}
+		slog.Error("Failed to get job status", "job_id", jobID, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve job status"))
+	}
+
+	// Reported as missing so job IDs of other users cannot be probed
+	if submittedBy != "" && status.SubmittedBy != submittedBy {
+		return c.Status(http.StatusNotFound).JSON(
+			utils.CreateErrorResponse("NOT_FOUND", "Job not found"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(status))
+}
+
+func (h *WorkerHandler) listJobStatuses(c fiber.Ctx, submittedBy string) error {
+	filter := worker.JobStatusFilter{
+		JobType:     c.Query("type"),
+		Status:      worker.JobState(c.Query("status")),
+		SubmittedBy: submittedBy,
+		Limit:       50, // default
+	}
+
+	switch filter.Status {
+	case "", worker.JobStateQueued, worker.JobStateRunning, worker.JobStateSucceeded, worker.JobStateFailed:
+	default:
+		return c.Status(http.StatusBadRequest).JSON(
+			utils.CreateErrorResponse("INVALID_REQUEST", "status must be one of queued, running, succeeded, failed"))
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 {
+			filter.Limit = min(l, maxJobStatusesLimit)
+		}
+	}
+
+	statuses, err := h.workerManager.ListJobStatuses(c.Context(), filter)
+	if err != nil {
+		slog.Error("Failed to list job statuses", "job_type", filter.JobType, "status", filter.Status, "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(
+			utils.CreateErrorResponse("RETRIEVAL_FAILED", "Failed to retrieve job statuses"))
+	}
+
+	return c.Status(http.StatusOK).JSON(utils.CreateSuccessResponse(map[string]any{
+		"jobs":  statuses,
+		"count": len(statuses),
+		"limit": filter.Limit,
+	}))
+}
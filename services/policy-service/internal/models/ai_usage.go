@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// AI USAGE
+// ============================================================================
+
+// AIUsage is the token usage of one AI job on one model, with its estimated cost in USD
+type AIUsage struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	JobRunID            uuid.UUID  `json:"job_run_id" db:"job_run_id"`
+	Provider            string     `json:"provider" db:"provider"`
+	Model               string     `json:"model" db:"model"`
+	JobType             string     `json:"job_type" db:"job_type"`
+	InsuranceProviderID string     `json:"insurance_provider_id" db:"insurance_provider_id"`
+	BasePolicyID        *uuid.UUID `json:"base_policy_id,omitempty" db:"base_policy_id"`
+	RegisteredPolicyID  *uuid.UUID `json:"registered_policy_id,omitempty" db:"registered_policy_id"`
+	CallCount           int        `json:"call_count" db:"call_count"`
+	PromptTokens        int64      `json:"prompt_tokens" db:"prompt_tokens"`
+	ResponseTokens      int64      `json:"response_tokens" db:"response_tokens"`
+	TotalTokens         int64      `json:"total_tokens" db:"total_tokens"`
+	EstimatedCostUSD    float64    `json:"estimated_cost_usd" db:"estimated_cost_usd"`
+	Succeeded           bool       `json:"succeeded" db:"succeeded"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AIUsageSource is what an AI job is accounted to
+type AIUsageSource struct {
+	JobType             string
+	InsuranceProviderID string
+	BasePolicyID        *uuid.UUID
+	RegisteredPolicyID  *uuid.UUID
+}
+
+// AIUsageSummary is the usage of a month or of an insurance provider. Only the key of the
+// grouping asked for is set.
+type AIUsageSummary struct {
+	Month               *string `json:"month,omitempty" db:"month"`
+	InsuranceProviderID *string `json:"insurance_provider_id,omitempty" db:"insurance_provider_id"`
+	JobCount            int     `json:"job_count" db:"job_count"`
+	CallCount           int64   `json:"call_count" db:"call_count"`
+	PromptTokens        int64   `json:"prompt_tokens" db:"prompt_tokens"`
+	ResponseTokens      int64   `json:"response_tokens" db:"response_tokens"`
+	TotalTokens         int64   `json:"total_tokens" db:"total_tokens"`
+	EstimatedCostUSD    float64 `json:"estimated_cost_usd" db:"estimated_cost_usd"`
+}
+
+// AIUsageFilter selects usage created in [From, To), of one insurance provider when set
+type AIUsageFilter struct {
+	InsuranceProviderID string
+	From                time.Time
+	To                  time.Time
+}
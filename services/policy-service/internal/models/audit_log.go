@@ -0,0 +1,52 @@
+package models
+
+import (
+	utils "agrisa_utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// AUDIT LOG
+// ============================================================================
+
+type AuditEntityType string
+
+const (
+	AuditEntityBasePolicy       AuditEntityType = "base_policy"
+	AuditEntityRegisteredPolicy AuditEntityType = "registered_policy"
+	AuditEntityFarm             AuditEntityType = "farm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditActorSystem is the actor of changes made without a user request, by jobs and consumers
+const AuditActorSystem = "system"
+
+// AuditLog records one change to a policy or farm: who made it, in which request, and the fields it
+// changed as {"field": {"before": ..., "after": ...}}
+type AuditLog struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	EntityType AuditEntityType `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id" db:"entity_id"`
+	Action     AuditAction     `json:"action" db:"action"`
+	ActorID    string          `json:"actor_id" db:"actor_id"`
+	RequestID  *string         `json:"request_id,omitempty" db:"request_id"`
+	Changes    utils.JSONMap   `json:"changes" db:"changes"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogFilter selects audit log entries, most recent first
+type AuditLogFilter struct {
+	EntityType AuditEntityType
+	EntityID   *uuid.UUID
+	ActorID    string
+	Limit      int
+}
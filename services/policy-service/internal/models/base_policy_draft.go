@@ -0,0 +1,144 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// BASE POLICY DRAFTS
+// ============================================================================
+
+type DraftPolicyStatus string
+
+const (
+	DraftPending   DraftPolicyStatus = "pending"
+	DraftCommitted DraftPolicyStatus = "committed"
+	DraftExpired   DraftPolicyStatus = "expired"
+)
+
+// DraftPolicy stages a base policy created through the complete policy flow until its
+// document is validated and it is committed to base_policy. When its validation window ends at
+// ExpiresAt an AutoCommit draft, created with is_archive, is committed as it is; the others expire.
+type DraftPolicy struct {
+	ID                  uuid.UUID         `json:"id" db:"id"`
+	InsuranceProviderID string            `json:"insurance_provider_id" db:"insurance_provider_id"`
+	Status              DraftPolicyStatus `json:"status" db:"status"`
+	AutoCommit          bool              `json:"auto_commit" db:"auto_commit"`
+	Content             DraftContent      `json:"content" db:"content"`
+	Validations         DraftValidations  `json:"validations" db:"validations"`
+	ExpiresAt           int64             `json:"expires_at" db:"expires_at"`
+	CommittedAt         *int64            `json:"committed_at,omitempty" db:"committed_at"`
+	ExpiredAt           *int64            `json:"expired_at,omitempty" db:"expired_at"`
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CompletePolicy returns the draft in the shape CommitPolicies commits
+func (d *DraftPolicy) CompletePolicy() *CompletePolicyData {
+	return &CompletePolicyData{
+		BasePolicy:  d.Content.BasePolicy,
+		Trigger:     d.Content.Trigger,
+		Conditions:  d.Content.Conditions,
+		Validations: d.Validations,
+	}
+}
+
+// DraftContent is the policy, trigger and conditions of a draft as they will be committed, with
+// the response its creation returned. Stored as a JSONB object.
+type DraftContent struct {
+	BasePolicy       *BasePolicy                     `json:"base_policy"`
+	Trigger          *BasePolicyTrigger              `json:"trigger"`
+	Conditions       []*BasePolicyTriggerCondition   `json:"conditions"`
+	CreationResponse *CompletePolicyCreationResponse `json:"creation_response"`
+}
+
+func (c DraftContent) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *DraftContent) Scan(value any) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("DraftContent: Scan failed, expected []byte but got %T", value)
+	}
+
+	return json.Unmarshal(b, c)
+}
+
+// DraftValidations are the document validations of a draft, oldest first. Stored as a JSONB array.
+type DraftValidations []*BasePolicyDocumentValidation
+
+func (v DraftValidations) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *DraftValidations) Scan(value any) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("DraftValidations: Scan failed, expected []byte but got %T", value)
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// DraftFilter selects the pending drafts GetAllDraftPolicyWFilter lists. Nil AutoCommit lists
+// drafts either way.
+type DraftFilter struct {
+	InsuranceProviderID string
+	ID                  *uuid.UUID
+	AutoCommit          *bool
+}
+
+// DraftExpirySource is how the end of a validation window was detected
+type DraftExpirySource string
+
+const (
+	DraftExpiryListener DraftExpirySource = "listener" // the expiry of the Redis key was heard
+	DraftExpirySweeper  DraftExpirySource = "sweeper"  // the notification was lost, found by the sweeper
+)
+
+type DraftExpirationEventStatus string
+
+const (
+	DraftExpirationPending   DraftExpirationEventStatus = "pending"
+	DraftExpirationProcessed DraftExpirationEventStatus = "processed"
+)
+
+// DraftExpirationEvent records the end of the validation window of a draft until it is processed.
+// Processing is retried from NextAttemptAt until it succeeds, and Outcome is the status the
+// draft was left in: committed for auto commit drafts, expired for the others.
+type DraftExpirationEvent struct {
+	ID            uuid.UUID                  `json:"id" db:"id"`
+	DraftID       uuid.UUID                  `json:"draft_id" db:"draft_id"`
+	Source        DraftExpirySource          `json:"source" db:"source"`
+	Status        DraftExpirationEventStatus `json:"status" db:"status"`
+	Outcome       *DraftPolicyStatus         `json:"outcome,omitempty" db:"outcome"`
+	Attempts      int                        `json:"attempts" db:"attempts"`
+	LastError     *string                    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt int64                      `json:"next_attempt_at" db:"next_attempt_at"`
+	DetectedAt    int64                      `json:"detected_at" db:"detected_at"`
+	ProcessedAt   *int64                     `json:"processed_at,omitempty" db:"processed_at"`
+	CreatedAt     time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at" db:"updated_at"`
+}
+
+// DraftImportResult reports an import of the drafts kept in Redis before they moved to Postgres
+type DraftImportResult struct {
+	Found    int      `json:"found"`
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Failures []string `json:"failures,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	utils "agrisa_utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// BASE POLICY VERSION
+// ============================================================================
+
+// BasePolicyVersion is an immutable copy of the terms of a base policy: its fields, trigger and
+// conditions as {"base_policy": {...}, "trigger": {..., "conditions": [...]}}
+type BasePolicyVersion struct {
+	ID           uuid.UUID     `json:"id" db:"id"`
+	BasePolicyID uuid.UUID     `json:"base_policy_id" db:"base_policy_id"`
+	Version      int           `json:"version" db:"version"`
+	Terms        utils.JSONMap `json:"terms" db:"terms"`
+	CreatedBy    string        `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+}
+
+// BasePolicyFieldChange is one field that differs between two versions, addressed by its path
+// in the terms such as "trigger.conditions[0].threshold_value"
+type BasePolicyFieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type BasePolicyVersionDiff struct {
+	BasePolicyID uuid.UUID               `json:"base_policy_id"`
+	FromVersion  int                     `json:"from_version"`
+	ToVersion    int                     `json:"to_version"`
+	Changes      []BasePolicyFieldChange `json:"changes"`
+}
@@ -1,15 +1,26 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 // ============================================================================
-// BILLING & INVOICING (DEPRECATED)
+// BILLING & INVOICING
 // ============================================================================
 
+// Line items of a partner invoice
+const (
+	InvoiceItemDataCost       = "data_cost"        // data cost of the policies of a base policy
+	InvoiceItemDataCostMarkup = "data_cost_markup" // markup of the partner contract on the data cost
+)
+
+// PartnerInvoice bills an insurance partner the data cost of the policies it started in a month.
+// InvoiceMonth is written as YYYYMM. TotalDataComplexityFee is the data cost before markup, and
+// Subtotal adds the markup of the partner contract to it.
 type PartnerInvoice struct {
 	ID                     uuid.UUID     `json:"id" db:"id"`
 	InsuranceProviderID    string        `json:"insurance_provider_id" db:"insurance_provider_id"`
@@ -17,13 +28,31 @@ type PartnerInvoice struct {
 	InvoiceNumber          string        `json:"invoice_number" db:"invoice_number"`
 	ActivePoliciesCount    int           `json:"active_policies_count" db:"active_policies_count"`
 	TotalDataComplexityFee float64       `json:"total_data_complexity_fee" db:"total_data_complexity_fee"`
+	DataCostMarkup         float64       `json:"data_cost_markup" db:"data_cost_markup"`
+	MarkupPercent          float64       `json:"markup_percent" db:"markup_percent"`
 	Subtotal               float64       `json:"subtotal" db:"subtotal"`
+	TaxPercent             float64       `json:"tax_percent" db:"tax_percent"`
 	Tax                    float64       `json:"tax" db:"tax"`
 	TotalDue               float64       `json:"total_due" db:"total_due"`
+	Currency               string        `json:"currency" db:"currency"`
+	ContractID             *string       `json:"contract_id,omitempty" db:"contract_id"`
+	ContractVersion        *int          `json:"contract_version,omitempty" db:"contract_version"`
 	PaymentStatus          PaymentStatus `json:"payment_status" db:"payment_status"`
 	DueDate                int64         `json:"due_date" db:"due_date"`
 	PaidDate               *int64        `json:"paid_date,omitempty" db:"paid_date"`
+	PaymentReference       *string       `json:"payment_reference,omitempty" db:"payment_reference"`
+	MarkedPaidBy           *string       `json:"marked_paid_by,omitempty" db:"marked_paid_by"`
+	CancellationReason     *string       `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	PDFObjectKey           *string       `json:"-" db:"pdf_object_key"`
 	CreatedAt              time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time     `json:"updated_at" db:"updated_at"`
+
+	LineItems []InvoiceLineItem `json:"line_items,omitempty" db:"-"`
+}
+
+// Period is the billed month, in UTC
+func (i *PartnerInvoice) Period() time.Time {
+	return time.Date(int(i.InvoiceMonth/100), time.Month(i.InvoiceMonth%100), 1, 0, 0, 0, 0, time.UTC)
 }
 
 type InvoiceLineItem struct {
@@ -39,3 +68,92 @@ type InvoiceLineItem struct {
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 }
 
+type InvoiceFilter struct {
+	InsuranceProviderID string
+	PaymentStatus       PaymentStatus
+	InvoiceMonth        int64 // YYYYMM, 0 for every month
+}
+
+// GenerateInvoicesRequest issues the invoices of a month, for one partner or, when
+// InsuranceProviderID is empty, every partner with billable policies
+type GenerateInvoicesRequest struct {
+	Year                int    `json:"year"`
+	Month               int    `json:"month"`
+	InsuranceProviderID string `json:"insurance_provider_id,omitempty"`
+}
+
+func (r *GenerateInvoicesRequest) Validate() error {
+	if r.Month < 1 || r.Month > 12 {
+		return fmt.Errorf("month must be between 1 and 12")
+	}
+	if r.Year < 2000 {
+		return fmt.Errorf("year is required")
+	}
+	r.InsuranceProviderID = strings.TrimSpace(r.InsuranceProviderID)
+	return nil
+}
+
+type GenerateInvoicesResponse struct {
+	Invoices []PartnerInvoice `json:"invoices"`
+	// Partners whose invoice could not be issued, with the reason
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+type MarkInvoicePaidRequest struct {
+	PaymentReference string `json:"payment_reference"`
+	// Unix seconds, the time of the request when omitted
+	PaidAt *int64 `json:"paid_at,omitempty"`
+}
+
+func (r *MarkInvoicePaidRequest) Validate() error {
+	r.PaymentReference = strings.TrimSpace(r.PaymentReference)
+	if r.PaymentReference == "" {
+		return fmt.Errorf("payment_reference is required")
+	}
+	if r.PaidAt != nil && *r.PaidAt > time.Now().Unix() {
+		return fmt.Errorf("paid_at cannot be in the future")
+	}
+	return nil
+}
+
+type CancelInvoiceRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r *CancelInvoiceRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	return nil
+}
+
+type InvoiceDownloadResponse struct {
+	InvoiceNumber string    `json:"invoice_number"`
+	DownloadURL   string    `json:"download_url"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// RevenueMonth sums the invoices issued for a month in one currency, cancelled ones left out.
+// Outstanding is what is still due, Overdue the part of it past its due date.
+type RevenueMonth struct {
+	InvoiceMonth int64   `json:"invoice_month" db:"invoice_month"`
+	Currency     string  `json:"currency" db:"currency"`
+	InvoiceCount int64   `json:"invoice_count" db:"invoice_count"`
+	DataCost     float64 `json:"data_cost" db:"data_cost"`
+	Markup       float64 `json:"markup" db:"markup"`
+	Tax          float64 `json:"tax" db:"tax"`
+	Invoiced     float64 `json:"invoiced" db:"invoiced"`
+	Collected    float64 `json:"collected" db:"collected"`
+	Outstanding  float64 `json:"outstanding" db:"outstanding"`
+	Overdue      float64 `json:"overdue" db:"overdue"`
+}
+
+// RevenueReport lists the invoiced revenue of the platform per month between FromMonth and
+// ToMonth (YYYYMM, both included), with the totals of each currency
+type RevenueReport struct {
+	FromMonth int64          `json:"from_month"`
+	ToMonth   int64          `json:"to_month"`
+	Months    []RevenueMonth `json:"months"`
+	Totals    []RevenueMonth `json:"totals"`
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	utils "agrisa_utils"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,10 +33,41 @@ type Claim struct {
 	AutoApprovalDeadline     *int64        `json:"auto_approval_deadline,omitempty" db:"auto_approval_deadline"`
 	AutoApproved             bool          `json:"auto_approved" db:"auto_approved"`
 	EvidenceSummary          utils.JSONMap `json:"evidence_summary,omitempty" db:"evidence_summary"` // JSONB
+	DisputeResolution        *DisputeResolution `json:"dispute_resolution,omitempty" db:"dispute_resolution"`
 	CreatedAt                time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt                time.Time     `json:"updated_at" db:"updated_at"`
 }
 
+// ClaimTransitions lists the statuses a claim may move to from each status. Generated claims are
+// submitted for partner review, reviewed claims are approved or rejected, approved claims are paid
+// and paid or rejected claims are closed. A rejected claim may be disputed by the farmer, the
+// dispute approving it or returning it to rejected.
+var ClaimTransitions = map[ClaimStatus][]ClaimStatus{
+	ClaimGenerated:            {ClaimPendingPartnerReview},
+	ClaimPendingPartnerReview: {ClaimApproved, ClaimRejected},
+	ClaimApproved:             {ClaimPaid},
+	ClaimRejected:             {ClaimClosed, ClaimDisputed},
+	ClaimPaid:                 {ClaimClosed},
+	ClaimDisputed:             {ClaimApproved, ClaimRejected},
+}
+
+// CanTransitionTo reports whether a claim in status s may move to next
+func (s ClaimStatus) CanTransitionTo(next ClaimStatus) bool {
+	return slices.Contains(ClaimTransitions[s], next)
+}
+
+// ClaimStatusHistory records one status change of a claim; FromStatus is nil for the status the
+// claim was created with
+type ClaimStatusHistory struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	ClaimID    uuid.UUID    `json:"claim_id" db:"claim_id"`
+	FromStatus *ClaimStatus `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   ClaimStatus  `json:"to_status" db:"to_status"`
+	ChangedBy  *string      `json:"changed_by,omitempty" db:"changed_by"`
+	Notes      *string      `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+}
+
 type ClaimRejection struct {
 	ID                  uuid.UUID          `json:"id" db:"id"`
 	ClaimID             uuid.UUID          `json:"claim_id" db:"claim_id"`
@@ -65,3 +97,15 @@ type Payout struct {
 	FarmerFeedback              *string      `json:"farmer_feedback,omitempty" db:"farmer_feedback"`
 	CreatedAt                   time.Time    `json:"created_at" db:"created_at"`
 }
+
+// PayoutCalculation is the payout of a claim worked out from the payout terms of its base policy
+type PayoutCalculation struct {
+	FarmAreaHectares float64 `json:"farm_area_hectares"`
+	// BreachMagnitude is how far the worst trigger condition went past its threshold, relative to
+	// the threshold
+	BreachMagnitude float64 `json:"breach_magnitude"`
+	FixPayout       float64 `json:"fix_payout"`
+	ThresholdPayout float64 `json:"threshold_payout"`
+	TotalPayout     float64 `json:"total_payout"`
+	Capped          bool    `json:"capped"`
+}
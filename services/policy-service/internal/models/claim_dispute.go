@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// CLAIM DISPUTE
+// ============================================================================
+
+type ClaimDisputeStatus string
+
+const (
+	DisputeAwaitingPartner  ClaimDisputeStatus = "awaiting_partner"
+	DisputePartnerResponded ClaimDisputeStatus = "partner_responded"
+	DisputeInArbitration    ClaimDisputeStatus = "in_arbitration"
+	DisputeUpheld           ClaimDisputeStatus = "upheld"
+	DisputeDismissed        ClaimDisputeStatus = "dismissed"
+	DisputeWithdrawn        ClaimDisputeStatus = "withdrawn"
+)
+
+// IsFinal reports whether the dispute has been settled
+func (s ClaimDisputeStatus) IsFinal() bool {
+	return s == DisputeUpheld || s == DisputeDismissed || s == DisputeWithdrawn
+}
+
+// DisputeResolution is the outcome of a dispute, kept on the disputed claim
+type DisputeResolution string
+
+const (
+	DisputeResolutionUpheld    DisputeResolution = "upheld"
+	DisputeResolutionDismissed DisputeResolution = "dismissed"
+	DisputeResolutionWithdrawn DisputeResolution = "withdrawn"
+)
+
+// DisputeRole is the side a participant of a dispute takes part as
+type DisputeRole string
+
+const (
+	DisputeRoleFarmer        DisputeRole = "farmer"
+	DisputeRolePartner       DisputeRole = "partner"
+	DisputeRolePlatformAdmin DisputeRole = "platform_admin"
+	// Messages posted by the service itself, such as SLA escalations
+	DisputeRoleSystem DisputeRole = "system"
+)
+
+// DisputeParticipant is the caller of a dispute request and the side it acts for
+type DisputeParticipant struct {
+	UserID string
+	Role   DisputeRole
+}
+
+// ClaimDispute is the contest of a rejected claim by its farmer
+type ClaimDispute struct {
+	ID                   uuid.UUID          `json:"id" db:"id"`
+	ClaimID              uuid.UUID          `json:"claim_id" db:"claim_id"`
+	RegisteredPolicyID   uuid.UUID          `json:"registered_policy_id" db:"registered_policy_id"`
+	FarmerID             string             `json:"farmer_id" db:"farmer_id"`
+	InsuranceProviderID  string             `json:"insurance_provider_id" db:"insurance_provider_id"`
+	Status               ClaimDisputeStatus `json:"status" db:"status"`
+	Reason               string             `json:"reason" db:"reason"`
+	PartnerResponseDueAt int64              `json:"partner_response_due_at" db:"partner_response_due_at"`
+	PartnerRespondedAt   *int64             `json:"partner_responded_at,omitempty" db:"partner_responded_at"`
+	EscalatedAt          *int64             `json:"escalated_at,omitempty" db:"escalated_at"`
+	ArbitrationDueAt     *int64             `json:"arbitration_due_at,omitempty" db:"arbitration_due_at"`
+	SLABreached          bool               `json:"sla_breached" db:"sla_breached"`
+	ResolutionNotes      *string            `json:"resolution_notes,omitempty" db:"resolution_notes"`
+	ResolvedBy           *string            `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt           *int64             `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt            time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+type ClaimDisputeMessage struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	DisputeID  uuid.UUID   `json:"dispute_id" db:"dispute_id"`
+	AuthorID   string      `json:"author_id" db:"author_id"`
+	AuthorRole DisputeRole `json:"author_role" db:"author_role"`
+	Body       string      `json:"body" db:"body"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+type ClaimDisputeEvidenceStatus string
+
+const (
+	DisputeEvidencePendingUpload ClaimDisputeEvidenceStatus = "pending_upload"
+	DisputeEvidenceUploaded      ClaimDisputeEvidenceStatus = "uploaded"
+)
+
+type ClaimDisputeEvidence struct {
+	ID           uuid.UUID                  `json:"id" db:"id"`
+	DisputeID    uuid.UUID                  `json:"dispute_id" db:"dispute_id"`
+	UploadedBy   string                     `json:"uploaded_by" db:"uploaded_by"`
+	UploaderRole DisputeRole                `json:"uploader_role" db:"uploader_role"`
+	FileName     string                     `json:"file_name" db:"file_name"`
+	ContentType  string                     `json:"content_type" db:"content_type"`
+	ObjectKey    string                     `json:"object_key" db:"object_key"`
+	SizeBytes    *int64                     `json:"size_bytes,omitempty" db:"size_bytes"`
+	Status       ClaimDisputeEvidenceStatus `json:"status" db:"status"`
+	CreatedAt    time.Time                  `json:"created_at" db:"created_at"`
+
+	// Set in responses, not stored
+	DownloadURL string `json:"download_url,omitempty" db:"-"`
+}
+
+// ClaimDisputeDetail is a dispute with its thread and the evidence uploaded to it
+type ClaimDisputeDetail struct {
+	ClaimDispute
+	Messages []ClaimDisputeMessage  `json:"messages"`
+	Evidence []ClaimDisputeEvidence `json:"evidence"`
+}
+
+// ClaimDisputeFilter narrows the disputes listed to platform admins
+type ClaimDisputeFilter struct {
+	Status ClaimDisputeStatus
+	// Only disputes still open past their partner response or arbitration deadline
+	Overdue bool
+}
+
+type OpenClaimDisputeRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r *OpenClaimDisputeRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	if len(r.Reason) > 5000 {
+		return fmt.Errorf("reason must be at most 5000 characters")
+	}
+	return nil
+}
+
+type PostClaimDisputeMessageRequest struct {
+	Body string `json:"body"`
+}
+
+func (r *PostClaimDisputeMessageRequest) Validate() error {
+	r.Body = strings.TrimSpace(r.Body)
+	if r.Body == "" {
+		return fmt.Errorf("body is required")
+	}
+	if len(r.Body) > 5000 {
+		return fmt.Errorf("body must be at most 5000 characters")
+	}
+	return nil
+}
+
+type CreateClaimDisputeEvidenceRequest struct {
+	FileName string `json:"file_name"`
+}
+
+type CreateClaimDisputeEvidenceResponse struct {
+	Evidence        *ClaimDisputeEvidence `json:"evidence"`
+	UploadURL       string                `json:"upload_url"`
+	UploadExpiresAt time.Time             `json:"upload_expires_at"`
+}
+
+type ResolveClaimDisputeRequest struct {
+	Resolution DisputeResolution `json:"resolution"`
+	Notes      string            `json:"notes"`
+}
+
+func (r *ResolveClaimDisputeRequest) Validate() error {
+	if r.Resolution != DisputeResolutionUpheld && r.Resolution != DisputeResolutionDismissed {
+		return fmt.Errorf("resolution must be upheld or dismissed")
+	}
+	r.Notes = strings.TrimSpace(r.Notes)
+	if r.Notes == "" {
+		return fmt.Errorf("notes are required")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// CROP VERIFICATION
+// ============================================================================
+
+type CropVerificationSource string
+
+const (
+	CropVerificationSourceAI      CropVerificationSource = "ai"
+	CropVerificationSourceInsurer CropVerificationSource = "insurer"
+)
+
+type CropVerificationDecision string
+
+const (
+	// Outcomes of an AI classification
+	CropVerificationVerified       CropVerificationDecision = "verified"
+	CropVerificationPendingReview  CropVerificationDecision = "pending_review"
+	CropVerificationChangeDetected CropVerificationDecision = "change_detected"
+	// Decisions of an insurer reviewing the crop
+	CropVerificationConfirmed  CropVerificationDecision = "confirmed"
+	CropVerificationOverridden CropVerificationDecision = "overridden"
+)
+
+// CropVerificationActorAI is the verifier recorded for crops verified by the AI classification
+const CropVerificationActorAI = "ai"
+
+// CropVerification is one classification of the crop of a farm, by AI from the farm photos or by
+// an insurer confirming or overriding it. Confidence is the confidence in DetectedCropType.
+type CropVerification struct {
+	ID               uuid.UUID                `json:"id" db:"id"`
+	FarmID           uuid.UUID                `json:"farm_id" db:"farm_id"`
+	Source           CropVerificationSource   `json:"source" db:"source"`
+	Decision         CropVerificationDecision `json:"decision" db:"decision"`
+	DeclaredCropType string                   `json:"declared_crop_type" db:"declared_crop_type"`
+	DetectedCropType string                   `json:"detected_crop_type" db:"detected_crop_type"`
+	Confidence       *float64                 `json:"confidence,omitempty" db:"confidence"`
+	PhotoCount       int                      `json:"photo_count" db:"photo_count"`
+	LatestPhotoAt    *int64                   `json:"latest_photo_at,omitempty" db:"latest_photo_at"`
+	Reasoning        *string                  `json:"reasoning,omitempty" db:"reasoning"`
+	VerifiedBy       string                   `json:"verified_by" db:"verified_by"`
+	CreatedAt        time.Time                `json:"created_at" db:"created_at"`
+}
+
+// CropClassification is the answer of the AI crop classification prompt
+type CropClassification struct {
+	CropType   string  `json:"crop_type"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type MonthlyRevenue struct {
 	Month                   int     `json:"month"`
 	Year                    int     `json:"year"`
@@ -83,3 +85,117 @@ type PartnerDashboardOverview struct {
 	PremiumGrowthYoY           []PremiumGrowthYoY      `json:"premium_growth_yoy"`
 	MonthlyPayoutPerClaimTrend []MonthlyPayoutPerClaim `json:"monthly_payout_per_claim_trend"`
 }
+
+// PartnerPublicStats - all-time figures about a partner shown to farmers on the marketplace
+type PartnerPublicStats struct {
+	PartnerID             string   `json:"partner_id" db:"partner_id"`
+	ActiveBasePolicyCount int64    `json:"active_base_policy_count" db:"active_base_policy_count"`
+	TotalClaims           int64    `json:"total_claims" db:"total_claims"`
+	SettledClaims         int64    `json:"settled_claims" db:"settled_claims"`
+	RejectedClaims        int64    `json:"rejected_claims" db:"rejected_claims"`
+	PendingClaims         int64    `json:"pending_claims" db:"pending_claims"`
+	SettlementRatePercent float64  `json:"settlement_rate_percent" db:"settlement_rate_percent"`
+	AvgSettlementDays     *float64 `json:"avg_settlement_days" db:"avg_settlement_days"`
+	TotalPayoutDisbursed  float64  `json:"total_payout_disbursed" db:"total_payout_disbursed"`
+}
+
+// PolicyStats - counts and totals of the registered policies of a provider, or of all providers
+type PolicyStats struct {
+	ProviderID            string           `json:"provider_id,omitempty"`
+	RequestedBy           string           `json:"requested_by,omitempty"`
+	TotalCount            int64            `json:"total_count"`
+	ByStatus              map[string]int64 `json:"by_status"`
+	ByUnderwritingStatus  map[string]int64 `json:"by_underwriting_status"`
+	TotalCoverageAmount   float64          `json:"total_coverage_amount"`
+	TotalPremiumCollected float64          `json:"total_premium_collected"`
+}
+
+// ============================================================================
+// PORTFOLIO ANALYTICS
+// ============================================================================
+
+// AnalyticsBucket is the length of the periods of an analytics series
+type AnalyticsBucket string
+
+const (
+	AnalyticsBucketMonth   AnalyticsBucket = "month"
+	AnalyticsBucketQuarter AnalyticsBucket = "quarter"
+	AnalyticsBucketYear    AnalyticsBucket = "year"
+)
+
+// PortfolioBreakdown - policies sharing one value of a dimension: a status, a crop or a province
+type PortfolioBreakdown struct {
+	Key            string  `json:"key" db:"key"`
+	PolicyCount    int64   `json:"policy_count" db:"policy_count"`
+	CoverageAmount float64 `json:"coverage_amount" db:"coverage_amount"`
+	PremiumWritten float64 `json:"premium_written" db:"premium_written"`
+}
+
+// PortfolioTotals - all-time activity of a portfolio, summed from the monthly activity
+type PortfolioTotals struct {
+	PremiumCollected   float64 `json:"premium_collected" db:"premium_collected"`
+	PayoutsPaid        float64 `json:"payouts_paid" db:"payouts_paid"`
+	PayoutCount        int64   `json:"payout_count" db:"payout_count"`
+	ClaimCount         int64   `json:"claim_count" db:"claim_count"`
+	RejectedClaimCount int64   `json:"rejected_claim_count" db:"rejected_claim_count"`
+}
+
+// PortfolioSummary - the policies of a provider, or of all providers when ProviderID is empty,
+// with their premium, payouts and claims. Figures are as of RefreshedAt.
+type PortfolioSummary struct {
+	ProviderID          string  `json:"provider_id,omitempty"`
+	TotalPolicies       int64   `json:"total_policies"`
+	ActivePolicies      int64   `json:"active_policies"`
+	TotalCoverageAmount float64 `json:"total_coverage_amount"`
+	PremiumWritten      float64 `json:"premium_written"`
+	PortfolioTotals
+	// Payouts paid per 100 of premium collected
+	LossRatioPercent float64 `json:"loss_ratio_percent"`
+	// Claims raised per 100 policies
+	ClaimFrequencyPercent float64 `json:"claim_frequency_percent"`
+
+	ByStatus   []PortfolioBreakdown `json:"by_status"`
+	ByCrop     []PortfolioBreakdown `json:"by_crop"`
+	ByProvince []PortfolioBreakdown `json:"by_province"`
+
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+}
+
+// MonthlyActivity - one month of activity of a provider, as kept in analytics_monthly_activity
+type MonthlyActivity struct {
+	Month              time.Time `json:"month" db:"month"`
+	PremiumCollected   float64   `json:"premium_collected" db:"premium_collected"`
+	PayoutsPaid        float64   `json:"payouts_paid" db:"payouts_paid"`
+	PayoutCount        int64     `json:"payout_count" db:"payout_count"`
+	ClaimCount         int64     `json:"claim_count" db:"claim_count"`
+	RejectedClaimCount int64     `json:"rejected_claim_count" db:"rejected_claim_count"`
+	PoliciesRegistered int64     `json:"policies_registered" db:"policies_registered"`
+}
+
+// AnalyticsSeriesPoint - the activity of one period of a series
+type AnalyticsSeriesPoint struct {
+	PeriodStart        time.Time `json:"period_start"`
+	Label              string    `json:"label"`
+	PremiumCollected   float64   `json:"premium_collected"`
+	PayoutsPaid        float64   `json:"payouts_paid"`
+	LossRatioPercent   float64   `json:"loss_ratio_percent"`
+	ClaimCount         int64     `json:"claim_count"`
+	RejectedClaimCount int64     `json:"rejected_claim_count"`
+	PoliciesRegistered int64     `json:"policies_registered"`
+}
+
+type AnalyticsSeriesRequest struct {
+	ProviderID string
+	Bucket     AnalyticsBucket
+	// First day of the first month included
+	From time.Time
+	// First day of the month after the last one included
+	To time.Time
+}
+
+type AnalyticsSeries struct {
+	ProviderID  string                 `json:"provider_id,omitempty"`
+	Bucket      AnalyticsBucket        `json:"bucket"`
+	Points      []AnalyticsSeriesPoint `json:"points"`
+	RefreshedAt *time.Time             `json:"refreshed_at,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	utils "agrisa_utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// DOCUMENT SCAN
+// ============================================================================
+
+type DocumentScanSource string
+
+const (
+	DocumentScanPolicyDocument  DocumentScanSource = "policy_document"
+	DocumentScanLandCertificate DocumentScanSource = "land_certificate"
+	DocumentScanDisputeEvidence DocumentScanSource = "dispute_evidence"
+)
+
+type DocumentScanStatus string
+
+const (
+	DocumentScanPending     DocumentScanStatus = "pending"
+	DocumentScanClean       DocumentScanStatus = "clean"
+	DocumentScanQuarantined DocumentScanStatus = "quarantined"
+)
+
+// DocumentScan is the content check of one uploaded file. FollowUpJob holds the job that reads
+// the file, it is only submitted once the file is clean.
+type DocumentScan struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	Source        DocumentScanSource `json:"source" db:"source"`
+	ReferenceID   *uuid.UUID         `json:"reference_id,omitempty" db:"reference_id"`
+	Bucket        string             `json:"bucket" db:"bucket"`
+	ObjectKey     string             `json:"object_key" db:"object_key"`
+	Status        DocumentScanStatus `json:"status" db:"status"`
+	Findings      *string            `json:"findings,omitempty" db:"findings"`
+	ScannedWith   *string            `json:"scanned_with,omitempty" db:"scanned_with"`
+	QuarantineKey *string            `json:"-" db:"quarantine_key"`
+	FollowUpJob   utils.JSONMap      `json:"-" db:"follow_up_job"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	ScannedAt     *time.Time         `json:"scanned_at,omitempty" db:"scanned_at"`
+}
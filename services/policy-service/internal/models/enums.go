@@ -1,5 +1,7 @@
 package models
 
+import "agrisa/parameter"
+
 type DataSourceType string
 
 const (
@@ -8,6 +10,10 @@ const (
 	DataSourceDerived   DataSourceType = "derived"
 )
 
+// DataProviderAgromonitoring is the data provider of satellite data sources read from the Agro
+// API through weather-service rather than from satellite-data-service
+const DataProviderAgromonitoring = "agromonitoring"
+
 type ParameterType string
 
 const (
@@ -40,6 +46,7 @@ const (
 	PolicyRejected                PolicyStatus = "rejected"
 	PolicyDispute                 PolicyStatus = "dispute"
 	PolicyCancelledPendingPayment PolicyStatus = "cancelled_pending_payment"
+	PolicyLapsed                  PolicyStatus = "lapsed"
 )
 
 type UnderwritingStatus string
@@ -68,6 +75,7 @@ const (
 	PaymentTypePolicyCompensation PaymentType = "policy_compensation_payment"
 	PaymentTypePolicyRenewal      PaymentType = "policy_renewal_payment"
 	PaymentTypeDataBill           PaymentType = "data_bill_payment"
+	PaymentTypePremiumInstallment PaymentType = "policy_premium_installment_payment"
 )
 
 type ValidationStatus string
@@ -120,6 +128,8 @@ const (
 	ClaimApproved             ClaimStatus = "approved"
 	ClaimRejected             ClaimStatus = "rejected"
 	ClaimPaid                 ClaimStatus = "paid"
+	ClaimClosed               ClaimStatus = "closed"
+	ClaimDisputed             ClaimStatus = "disputed"
 )
 
 type PayoutStatus string
@@ -204,18 +214,53 @@ type DataSourceAPIAddress string
 const (
 	SatelliteNDVI          DataSourceAPIAddress = "/satellite/public/ndvi/batch"
 	SatelliteNDMI          DataSourceAPIAddress = "/satellite/public/ndmi/batch"
+	VegetationNDVI         DataSourceAPIAddress = "/weather/public/api/v2/vegetation/ndvi"
 	WeatherRainFall        DataSourceAPIAddress = "/weather/public/api/v2/precipitation/polygon"
 	WeatherCurrentPolygon  DataSourceAPIAddress = "/weather/public/api/v2/current/polygon"
+	DerivedSPI1            DataSourceAPIAddress = "/weather/public/api/v2/derived/spi/1"
+	DerivedSPI3            DataSourceAPIAddress = "/weather/public/api/v2/derived/spi/3"
+	DerivedSPI6            DataSourceAPIAddress = "/weather/public/api/v2/derived/spi/6"
+	DerivedET0             DataSourceAPIAddress = "/weather/public/api/v2/derived/et0"
+	DerivedGDD             DataSourceAPIAddress = "/weather/public/api/v2/derived/gdd"
+	DerivedDiseaseRisk     DataSourceAPIAddress = "/weather/public/api/v2/derived/disease-risk"
+	DerivedDrySpell        DataSourceAPIAddress = "/weather/public/api/v2/derived/dry-spell"
+	WeatherAirQuality      DataSourceAPIAddress = "/weather/public/api/v2/air-quality/polygon"
 )
 
+// DataSourceParameterName takes its values from the parameter names shared with weather-service,
+// which normalizes every provider reading to them and their canonical units
 type DataSourceParameterName string
 
 const (
-	NDVI     DataSourceParameterName = "ndvi"
-	NDMI     DataSourceParameterName = "ndmi"
-	RainFall DataSourceParameterName = "rainfall"
+	NDVI     = DataSourceParameterName(parameter.NDVI)
+	NDMI     = DataSourceParameterName(parameter.NDMI)
+	RainFall = DataSourceParameterName(parameter.RainFall)
+	// Standardized Precipitation Index over 1, 3 and 6 months, derived by weather-service
+	SPI1 = DataSourceParameterName(parameter.SPI1)
+	SPI3 = DataSourceParameterName(parameter.SPI3)
+	SPI6 = DataSourceParameterName(parameter.SPI6)
+	// Daily reference evapotranspiration in mm and growing degree days above 10 °C, derived by weather-service
+	ET0 = DataSourceParameterName(parameter.ET0)
+	GDD = DataSourceParameterName(parameter.GDD)
+	// Daily fungal disease risk index from 0 to 100, derived by weather-service from humidity and temperature
+	DiseaseRisk = DataSourceParameterName(parameter.DiseaseRisk)
+	// Daily highest air quality index from 1 (good) to 5 (very poor)
+	AQI = DataSourceParameterName(parameter.AQI)
+	// Consecutive days with under 1 mm of rain up to each day, derived by weather-service
+	DrySpell = DataSourceParameterName(parameter.DrySpell)
+	// Weather readings pushed by the weather-service polling workers
+	Temperature = DataSourceParameterName(parameter.Temperature)
+	Humidity    = DataSourceParameterName(parameter.Humidity)
+	Pressure    = DataSourceParameterName(parameter.Pressure)
+	WindSpeed   = DataSourceParameterName(parameter.WindSpeed)
+	CloudCover  = DataSourceParameterName(parameter.CloudCover)
 )
 
+// CanonicalUnit is the unit every reading of the parameter is measured in
+func (n DataSourceParameterName) CanonicalUnit() string {
+	return string(parameter.Name(n).Unit())
+}
+
 type RiskAnalysisType string
 
 const (
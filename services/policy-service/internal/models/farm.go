@@ -68,3 +68,30 @@ type UpdateFarmRequest struct {
 	HasIrrigation       *bool   `json:"has_irrigation,omitempty"`
 	IrrigationType      *string `json:"irrigation_type,omitempty"`
 }
+
+// InsuredFarmLocation is the location of an active farm covered by at least one active policy
+type InsuredFarmLocation struct {
+	FarmID            uuid.UUID `json:"farm_id" db:"farm_id"`
+	AgroPolygonID     *string   `json:"agro_polygon_id,omitempty" db:"agro_polygon_id"`
+	Province          *string   `json:"province,omitempty" db:"province"`
+	Latitude          float64   `json:"latitude" db:"latitude"`
+	Longitude         float64   `json:"longitude" db:"longitude"`
+	ActivePolicyCount int       `json:"active_policy_count" db:"active_policy_count"`
+}
+
+// BoundaryAnalysis is what PostGIS makes of a farm boundary
+type BoundaryAnalysis struct {
+	Valid bool `db:"is_valid"`
+	// Why the polygon is invalid, such as "Self-intersection[106.7 10.8]"
+	InvalidReason string  `db:"invalid_reason"`
+	AreaSqm       float64 `db:"area_sqm"`
+	CentroidLng   float64 `db:"centroid_lng"`
+	CentroidLat   float64 `db:"centroid_lat"`
+}
+
+// BoundaryOverlap is the part of a farm of another owner a boundary covers
+type BoundaryOverlap struct {
+	FarmID     uuid.UUID `db:"farm_id"`
+	FarmCode   *string   `db:"farm_code"`
+	OverlapSqm float64   `db:"overlap_sqm"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// LAND CERTIFICATE VERIFICATION
+// ============================================================================
+
+type LandCertificateStatus string
+
+const (
+	LandCertificateVerified   LandCertificateStatus = "verified"
+	LandCertificateMismatch   LandCertificateStatus = "mismatch"
+	LandCertificateUnreadable LandCertificateStatus = "unreadable"
+)
+
+// LandCertificateVerification is the evidence of one OCR of a farm's land certificate: what was
+// read from it and how it compared with the identity the farmer verified through eKYC and the
+// certificate number the farmer declared
+type LandCertificateVerification struct {
+	ID                        uuid.UUID             `json:"id" db:"id"`
+	FarmID                    uuid.UUID             `json:"farm_id" db:"farm_id"`
+	Status                    LandCertificateStatus `json:"status" db:"status"`
+	CertificateNumber         *string               `json:"certificate_number,omitempty" db:"certificate_number"`
+	OwnerNames                StringList            `json:"owner_names" db:"owner_names"`
+	ParcelAddress             *string               `json:"parcel_address,omitempty" db:"parcel_address"`
+	Confidence                *float64              `json:"confidence,omitempty" db:"confidence"`
+	IdentityName              string                `json:"identity_name" db:"identity_name"`
+	DeclaredCertificateNumber *string               `json:"declared_certificate_number,omitempty" db:"declared_certificate_number"`
+	OwnerMatched              bool                  `json:"owner_matched" db:"owner_matched"`
+	CertificateNumberMatched  *bool                 `json:"certificate_number_matched,omitempty" db:"certificate_number_matched"`
+	DocumentObjects           StringList            `json:"document_objects" db:"document_objects"`
+	Reasoning                 *string               `json:"reasoning,omitempty" db:"reasoning"`
+	CreatedAt                 time.Time             `json:"created_at" db:"created_at"`
+}
+
+// LandCertificateReading is the answer of the land certificate OCR prompt
+type LandCertificateReading struct {
+	Readable          bool     `json:"readable"`
+	CertificateNumber string   `json:"certificate_number"`
+	OwnerNames        []string `json:"owner_names"`
+	ParcelAddress     string   `json:"parcel_address"`
+	Confidence        float64  `json:"confidence"`
+	Reasoning         string   `json:"reasoning"`
+}
+
+// StringList is a list of strings stored as a JSONB array
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value any) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("StringList: Scan failed, expected []byte but got %T", value)
+	}
+
+	return json.Unmarshal(b, l)
+}
@@ -50,3 +50,69 @@ type FarmMonitoringDataWithPolicyStatus struct {
 	PolicyStatus       *PolicyStatus `json:"policy_status,omitempty" db:"policy_status"`
 	PolicyNumber       *string       `json:"policy_number,omitempty" db:"policy_number"`
 }
+
+// FarmMonitoringPartition is the partition of farm_monitoring_data holding the readings of one
+// month, measured from MonthStart (UTC)
+type FarmMonitoringPartition struct {
+	Name       string
+	MonthStart time.Time
+}
+
+// FarmMonitoringArchive is a month of readings exported to MinIO before its partition was dropped
+type FarmMonitoringArchive struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	PartitionName string    `json:"partition_name" db:"partition_name"`
+	RangeStart    int64     `json:"range_start" db:"range_start"`
+	RangeEnd      int64     `json:"range_end" db:"range_end"`
+	Bucket        string    `json:"bucket" db:"bucket"`
+	ObjectKey     string    `json:"object_key" db:"object_key"`
+	Format        string    `json:"format" db:"format"`
+	RowCount      int64     `json:"row_count" db:"row_count"`
+	SizeBytes     int64     `json:"size_bytes" db:"size_bytes"`
+	ArchivedAt    time.Time `json:"archived_at" db:"archived_at"`
+}
+
+// MonitoringDataSummary condenses the monitoring data of a farm for an AI prompt: statistics,
+// a downsampled series and the anomalies of each parameter instead of every measurement
+type MonitoringDataSummary struct {
+	TotalMeasurements int                          `json:"total_measurements"`
+	RawDataObject     string                       `json:"raw_data_object,omitempty"` // bucket/key of the full series in MinIO
+	BucketSeconds     int64                        `json:"bucket_seconds"`
+	Parameters        []MonitoringParameterSummary `json:"parameters"`
+}
+
+type MonitoringParameterSummary struct {
+	ParameterName     DataSourceParameterName `json:"parameter_name"`
+	Unit              string                  `json:"unit,omitempty"`
+	Count             int                     `json:"count"`
+	FirstTimestamp    int64                   `json:"first_timestamp"`
+	LastTimestamp     int64                   `json:"last_timestamp"`
+	Min               float64                 `json:"min"`
+	Max               float64                 `json:"max"`
+	Mean              float64                 `json:"mean"`
+	Median            float64                 `json:"median"`
+	StdDev            float64                 `json:"std_dev"`
+	QualityCounts     map[DataQuality]int     `json:"quality_counts"`
+	AvgConfidence     *float64                `json:"avg_confidence,omitempty"`
+	AvgCloudCover     *float64                `json:"avg_cloud_cover_percentage,omitempty"`
+	AvgDistanceMeters *float64                `json:"avg_distance_from_farm_meters,omitempty"`
+	Series            []MonitoringSeriesPoint `json:"series"`
+	Anomalies         []MonitoringAnomaly     `json:"anomalies,omitempty"`
+}
+
+// MonitoringSeriesPoint aggregates the measurements of one parameter over one time bucket
+type MonitoringSeriesPoint struct {
+	PeriodStart int64   `json:"period_start"`
+	Count       int     `json:"count"`
+	Mean        float64 `json:"mean"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+}
+
+// MonitoringAnomaly is a measurement far from the mean of its parameter
+type MonitoringAnomaly struct {
+	MeasurementTimestamp int64       `json:"measurement_timestamp"`
+	MeasuredValue        float64     `json:"measured_value"`
+	ZScore               float64     `json:"z_score"`
+	DataQuality          DataQuality `json:"data_quality"`
+}
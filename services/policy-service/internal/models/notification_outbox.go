@@ -0,0 +1,54 @@
+package models
+
+import (
+	utils "agrisa_utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// NOTIFICATION OUTBOX
+// ============================================================================
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxPublished OutboxStatus = "published"
+	OutboxFailed    OutboxStatus = "failed"
+)
+
+type OutboxEventType string
+
+const (
+	OutboxPolicyActivated OutboxEventType = "policy_activated"
+	OutboxPolicyExpired   OutboxEventType = "policy_expired"
+	OutboxClaimCreated    OutboxEventType = "claim_created"
+	OutboxPolicyLapsed    OutboxEventType = "policy_lapsed"
+	OutboxAIBudgetAlert   OutboxEventType = "ai_budget_alert"
+
+	OutboxClaimDisputeOpened    OutboxEventType = "claim_dispute_opened"
+	OutboxClaimDisputeEscalated OutboxEventType = "claim_dispute_escalated"
+	OutboxClaimDisputeResolved  OutboxEventType = "claim_dispute_resolved"
+
+	OutboxInvoiceIssued  OutboxEventType = "partner_invoice_issued"
+	OutboxInvoiceOverdue OutboxEventType = "partner_invoice_overdue"
+)
+
+// OutboxEvent is a message stored with the change it announces and published to Queue by the
+// outbox dispatcher once that change is committed
+type OutboxEvent struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	EventType   OutboxEventType `json:"event_type" db:"event_type"`
+	AggregateID uuid.UUID       `json:"aggregate_id" db:"aggregate_id"`
+	Queue       string          `json:"queue" db:"queue"`
+	Payload     utils.JSONMap   `json:"payload" db:"payload"`
+	Headers     utils.JSONMap   `json:"headers,omitempty" db:"headers"`
+	Status      OutboxStatus    `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	AvailableAt time.Time       `json:"available_at" db:"available_at"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	PublishedAt *time.Time      `json:"published_at,omitempty" db:"published_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// POLICY ATTACHMENT
+// ============================================================================
+
+type PolicyAttachmentType string
+
+const (
+	PolicyAttachmentContract   PolicyAttachmentType = "contract"
+	PolicyAttachmentAppendix   PolicyAttachmentType = "appendix"
+	PolicyAttachmentBrochure   PolicyAttachmentType = "brochure"
+	PolicyAttachmentSignedCopy PolicyAttachmentType = "signed_copy"
+	PolicyAttachmentOther      PolicyAttachmentType = "other"
+)
+
+type PolicyAttachmentStatus string
+
+const (
+	PolicyAttachmentPendingUpload PolicyAttachmentStatus = "pending_upload"
+	PolicyAttachmentUploaded      PolicyAttachmentStatus = "uploaded"
+)
+
+// PolicyAttachment is a file attached to either a base policy or a registered policy, stored in
+// policy_documents. Uploading a document type again adds the next version instead of replacing
+// the file.
+type PolicyAttachment struct {
+	ID                 uuid.UUID              `json:"id" db:"id"`
+	BasePolicyID       *uuid.UUID             `json:"base_policy_id,omitempty" db:"base_policy_id"`
+	RegisteredPolicyID *uuid.UUID             `json:"registered_policy_id,omitempty" db:"registered_policy_id"`
+	DocumentType       PolicyAttachmentType   `json:"document_type" db:"document_type"`
+	Version            int                    `json:"version" db:"version"`
+	Title              *string                `json:"title,omitempty" db:"title"`
+	FileName           string                 `json:"file_name" db:"file_name"`
+	ContentType        string                 `json:"content_type" db:"content_type"`
+	ObjectKey          string                 `json:"object_key" db:"object_key"`
+	SizeBytes          *int64                 `json:"size_bytes,omitempty" db:"size_bytes"`
+	Status             PolicyAttachmentStatus `json:"status" db:"status"`
+	UploadedBy         string                 `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt          time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at" db:"updated_at"`
+
+	// Presigned download link, filled for uploaded documents in responses
+	DownloadURL *string `json:"download_url,omitempty" db:"-"`
+}
+
+type CreatePolicyAttachmentRequest struct {
+	BasePolicyID       *uuid.UUID           `json:"base_policy_id"`
+	RegisteredPolicyID *uuid.UUID           `json:"registered_policy_id"`
+	DocumentType       PolicyAttachmentType `json:"document_type"`
+	Title              *string              `json:"title"`
+	FileName           string               `json:"file_name"`
+}
+
+// CreatePolicyAttachmentResponse carries the URL the client uploads the file to with a PUT request
+type CreatePolicyAttachmentResponse struct {
+	Attachment      *PolicyAttachment `json:"attachment"`
+	UploadURL       string            `json:"upload_url"`
+	UploadExpiresAt time.Time         `json:"upload_expires_at"`
+}
+
+// UpdatePolicyAttachmentRequest edits the metadata of a document; a new file is a new version
+type UpdatePolicyAttachmentRequest struct {
+	Title *string `json:"title"`
+}
+
+// PolicyAttachmentFilter selects the documents of one policy, newest version first. Only uploaded
+// documents are listed unless IncludePending is set.
+type PolicyAttachmentFilter struct {
+	BasePolicyID       *uuid.UUID
+	RegisteredPolicyID *uuid.UUID
+	DocumentType       PolicyAttachmentType
+	IncludePending     bool
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// POLICY DOCUMENT UPLOAD
+// ============================================================================
+
+type PolicyDocumentUploadStatus string
+
+const (
+	PolicyDocumentUploadPending  PolicyDocumentUploadStatus = "pending_upload"
+	PolicyDocumentUploadUploaded PolicyDocumentUploadStatus = "uploaded"
+	PolicyDocumentUploadUsed     PolicyDocumentUploadStatus = "used"
+)
+
+// PolicyDocumentUpload is a policy PDF the client uploads to MinIO itself before creating a base
+// policy. CompletePolicyCreationRequest refers to it by object key once it is uploaded.
+type PolicyDocumentUpload struct {
+	ID           uuid.UUID                  `json:"id" db:"id"`
+	FileName     string                     `json:"file_name" db:"file_name"`
+	ObjectKey    string                     `json:"object_key" db:"object_key"`
+	ContentType  *string                    `json:"content_type,omitempty" db:"content_type"`
+	SizeBytes    *int64                     `json:"size_bytes,omitempty" db:"size_bytes"`
+	Status       PolicyDocumentUploadStatus `json:"status" db:"status"`
+	BasePolicyID *uuid.UUID                 `json:"base_policy_id,omitempty" db:"base_policy_id"`
+	UploadedBy   string                     `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt    time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time                  `json:"updated_at" db:"updated_at"`
+}
+
+type CreatePolicyDocumentUploadRequest struct {
+	FileName string `json:"file_name"`
+}
+
+// CreatePolicyDocumentUploadResponse carries the URL the PDF is PUT to. The request must send
+// Content-Type: application/pdf.
+type CreatePolicyDocumentUploadResponse struct {
+	Upload          *PolicyDocumentUpload `json:"upload"`
+	UploadURL       string                `json:"upload_url"`
+	UploadExpiresAt time.Time             `json:"upload_expires_at"`
+	MaxSizeBytes    int64                 `json:"max_size_bytes"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// BULK POLICY IMPORT
+// ============================================================================
+
+type PolicyImportStatus string
+
+const (
+	PolicyImportPending    PolicyImportStatus = "pending"
+	PolicyImportProcessing PolicyImportStatus = "processing"
+	PolicyImportCompleted  PolicyImportStatus = "completed"
+	PolicyImportFailed     PolicyImportStatus = "failed"
+)
+
+// PolicyImportJob is a file of policies uploaded by an insurance partner, registered row by row
+// in the background
+type PolicyImportJob struct {
+	ID                  uuid.UUID          `json:"id" db:"id"`
+	InsuranceProviderID string             `json:"insurance_provider_id" db:"insurance_provider_id"`
+	BasePolicyID        uuid.UUID          `json:"base_policy_id" db:"base_policy_id"`
+	FileName            string             `json:"file_name" db:"file_name"`
+	FilePath            string             `json:"-" db:"file_path"`
+	Status              PolicyImportStatus `json:"status" db:"status"`
+	TotalRows           int                `json:"total_rows" db:"total_rows"`
+	ImportedRows        int                `json:"imported_rows" db:"imported_rows"`
+	RejectedRows        int                `json:"rejected_rows" db:"rejected_rows"`
+	ErrorMessage        *string            `json:"error_message,omitempty" db:"error_message"`
+	CreatedBy           string             `json:"created_by" db:"created_by"`
+	CreatedAt           time.Time          `json:"created_at" db:"created_at"`
+	StartedAt           *time.Time         `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt          *time.Time         `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// PolicyImportRejectedRow is a row of an import that could not be registered, listed in the
+// error report of the import
+type PolicyImportRejectedRow struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ImportJobID uuid.UUID `json:"import_job_id" db:"import_job_id"`
+	RowNumber   int       `json:"row_number" db:"row_number"`
+	FarmerID    *string   `json:"farmer_id,omitempty" db:"farmer_id"`
+	FarmID      *string   `json:"farm_id,omitempty" db:"farm_id"`
+	Reason      string    `json:"reason" db:"reason"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// PolicyImportRow is a data row of an import file. RowNumber counts from the header row, so it
+// matches the line or row number shown by a spreadsheet.
+type PolicyImportRow struct {
+	RowNumber    int
+	FarmerID     string
+	FarmID       string
+	PlantingDate int64
+	PolicyTags   map[string]string
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// PREMIUM PAYMENT SCHEDULE
+// ============================================================================
+
+// MaxPremiumInstallments caps how many installments a premium can be split into
+const MaxPremiumInstallments = 12
+
+type PremiumInstallment struct {
+	ID                 uuid.UUID     `json:"id" db:"id"`
+	RegisteredPolicyID uuid.UUID     `json:"registered_policy_id" db:"registered_policy_id"`
+	InstallmentNumber  int           `json:"installment_number" db:"installment_number"`
+	DueDate            int64         `json:"due_date" db:"due_date"`
+	AmountDue          float64       `json:"amount_due" db:"amount_due"`
+	AmountPaid         float64       `json:"amount_paid" db:"amount_paid"`
+	Status             PaymentStatus `json:"status" db:"status"`
+	PaidAt             *int64        `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// Outstanding is what is still owed on the installment
+func (i *PremiumInstallment) Outstanding() float64 {
+	return math.Max(roundCents(i.AmountDue-i.AmountPaid), 0)
+}
+
+// PremiumPayment is a payment applied to the premium schedule of a policy
+type PremiumPayment struct {
+	ID                 uuid.UUID `json:"id" db:"id"`
+	RegisteredPolicyID uuid.UUID `json:"registered_policy_id" db:"registered_policy_id"`
+	PaymentID          string    `json:"payment_id" db:"payment_id"`
+	Amount             float64   `json:"amount" db:"amount"`
+	PaidAt             int64     `json:"paid_at" db:"paid_at"`
+	RecordedBy         *string   `json:"recorded_by,omitempty" db:"recorded_by"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// PremiumSchedule is the installment plan of a policy with its payment totals
+type PremiumSchedule struct {
+	RegisteredPolicyID uuid.UUID            `json:"registered_policy_id"`
+	TotalPremium       float64              `json:"total_premium"`
+	TotalPaid          float64              `json:"total_paid"`
+	Outstanding        float64              `json:"outstanding"`
+	Installments       []PremiumInstallment `json:"installments"`
+	Payments           []PremiumPayment     `json:"payments"`
+}
+
+// SplitPremium divides a premium into count installments due at even intervals over window,
+// starting from start. Amounts are rounded to cents with the remainder on the last installment,
+// and the last one is due at the end of the window.
+func SplitPremium(policyID uuid.UUID, totalPremium float64, count int, start time.Time, window time.Duration) []PremiumInstallment {
+	installments := make([]PremiumInstallment, count)
+	amount := math.Floor(totalPremium/float64(count)*100) / 100
+	for i := range installments {
+		installments[i] = PremiumInstallment{
+			RegisteredPolicyID: policyID,
+			InstallmentNumber:  i + 1,
+			DueDate:            start.Add(window * time.Duration(i+1) / time.Duration(count)).Unix(),
+			AmountDue:          amount,
+			Status:             PaymentPending,
+		}
+	}
+	installments[count-1].AmountDue = roundCents(totalPremium - amount*float64(count-1))
+	return installments
+}
+
+// ApplyPremiumPayment settles installments in order of their number with amount, marking the
+// ones fully paid, and returns what is left over once every installment is paid
+func ApplyPremiumPayment(installments []PremiumInstallment, amount float64, paidAt int64) float64 {
+	remaining := roundCents(amount)
+	for i := range installments {
+		inst := &installments[i]
+		if remaining <= 0 {
+			break
+		}
+		if inst.Status != PaymentPending && inst.Status != PaymentOverdue {
+			continue
+		}
+
+		applied := math.Min(remaining, inst.Outstanding())
+		inst.AmountPaid = roundCents(inst.AmountPaid + applied)
+		remaining = roundCents(remaining - applied)
+		if inst.Outstanding() == 0 {
+			inst.Status = PaymentPaid
+			inst.PaidAt = &paidAt
+		}
+	}
+	return remaining
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -25,6 +25,7 @@ type RegisteredPolicy struct {
 	ID                      uuid.UUID          `json:"id" db:"id"`
 	PolicyNumber            string             `json:"policy_number" db:"policy_number"`
 	BasePolicyID            uuid.UUID          `json:"base_policy_id" db:"base_policy_id"`
+	BasePolicyVersion       *int               `json:"base_policy_version,omitempty" db:"base_policy_version"` // Version of the base policy terms it was sold under
 	InsuranceProviderID     string             `json:"insurance_provider_id" db:"insurance_provider_id"`
 	FarmID                  uuid.UUID          `json:"farm_id,omitempty" db:"farm_id"`
 	FarmerID                string             `json:"farmer_id" db:"farmer_id"`
@@ -45,6 +46,9 @@ type RegisteredPolicy struct {
 	CreatedAt               time.Time          `json:"created_at" db:"created_at"`
 	UpdatedAt               time.Time          `json:"updated_at" db:"updated_at"`
 	RegisteredBy            *string            `json:"registered_by,omitempty" db:"registered_by"`
+
+	// Filled from profile-service for policy detail responses
+	InsuranceProvider *InsuranceProviderSummary `json:"insurance_provider,omitempty" db:"-"`
 }
 
 type RegisteredPolicyWFarm struct {
@@ -99,6 +103,7 @@ type RegisteredPolicyRiskAnalysis struct {
 	Recommendations    utils.JSONMap    `json:"recommendations,omitempty" db:"recommendations"`
 	RawOutput          utils.JSONMap    `json:"raw_output,omitempty" db:"raw_output"`
 	AnalysisNotes      *string          `json:"analysis_notes,omitempty" db:"analysis_notes"`
+	InputHash          *string          `json:"input_hash,omitempty" db:"input_hash"` // Hash of the data analyzed, set by the AI job
 	CreatedAt          time.Time        `json:"created_at" db:"created_at"`
 }
 type CancelRequest struct {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"policy-service/internal/database/minio"
 	"slices"
 	"strings"
@@ -25,7 +26,7 @@ func isValidDataSourceType(dataSource DataSourceType) bool {
 
 func isValidDataSourceParamName(paramName DataSourceParameterName) bool {
 	switch paramName {
-	case NDMI, NDVI, RainFall:
+	case NDMI, NDVI, RainFall, SPI1, SPI3, SPI6, ET0, GDD, DiseaseRisk, AQI, DrySpell:
 		return true
 	default:
 		return false
@@ -205,9 +206,10 @@ type CompletePolicyCreationRequest struct {
 	PolicyDocument PolicyDocument                `json:"policy_document" validate:"required"`
 }
 
+// PolicyDocument refers to a PDF uploaded through POST /policy-document-uploads and confirmed
 type PolicyDocument struct {
-	Name string `json:"name" validate:"required"`
-	Data string `json:"data" validate:"required"` // This is base64
+	Name      string `json:"name" validate:"required"`
+	ObjectKey string `json:"object_key" validate:"required"`
 }
 
 func (r CompletePolicyCreationRequest) Validate() error {
@@ -226,8 +228,8 @@ func (r CompletePolicyCreationRequest) Validate() error {
 	if r.PolicyDocument.Name == "" {
 		return errors.New("policy document name is required")
 	}
-	if r.PolicyDocument.Data == "" {
-		return errors.New("document data is required")
+	if r.PolicyDocument.ObjectKey == "" {
+		return errors.New("policy document object_key is required")
 	}
 	return nil
 }
@@ -241,6 +243,10 @@ type CompletePolicyCreationResponse struct {
 	TotalDataCost   float64     `json:"total_data_cost"`
 	FilePath        string      `json:"-"`
 	CreatedAt       time.Time   `json:"created_at"`
+	// Job validating the policy document, its progress is polled on /jobs
+	ValidationJobID string `json:"validation_job_id,omitempty"`
+	// Content scan of the policy document, the validation job is queued once it passes
+	DocumentScanID *uuid.UUID `json:"document_scan_id,omitempty"`
 }
 
 // CompletePolicyData represents a complete policy with all related entities
@@ -344,7 +350,8 @@ func (r CreateDataSourceRequest) Validate() error {
 
 	// Validate required fields with trimming
 	if !isValidDataSourceParamName(r.ParameterName) {
-		return fmt.Errorf("invalid parameter_name: must be one of %s, %s, %s", NDVI, NDMI, RainFall)
+		return fmt.Errorf("invalid parameter_name: must be one of %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+			NDVI, NDMI, RainFall, SPI1, SPI3, SPI6, ET0, GDD, DiseaseRisk, AQI, DrySpell)
 	}
 
 	if r.DataTierID == uuid.Nil {
@@ -437,7 +444,8 @@ func (r UpdateDataSourceRequest) Validate() error {
 	// Validate parameter name if provided
 	if r.ParameterName != nil {
 		if !isValidDataSourceParamName(*r.ParameterName) {
-			return fmt.Errorf("invalid parameter_name: must be one of %s, %s, %s", NDVI, NDMI, RainFall)
+			return fmt.Errorf("invalid parameter_name: must be one of %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+				NDVI, NDMI, RainFall, SPI1, SPI3, SPI6, ET0, GDD, DiseaseRisk, AQI, DrySpell)
 		}
 	}
 
@@ -567,7 +575,7 @@ type CommitPoliciesRequest struct {
 	ArchiveStatus string `json:"archive_status,omitempty"`
 
 	// Operational options
-	DeleteFromRedis bool `json:"delete_from_redis"`    // Clean up after commit
+	DeleteFromRedis bool `json:"delete_from_redis"`    // Ignored: committed drafts always leave the cache
 	ValidateOnly    bool `json:"validate_only"`        // Dry run mode
 	BatchSize       int  `json:"batch_size,omitempty"` // Control batch processing (default: 10)
 }
@@ -651,6 +659,8 @@ type CompletePolicyDetailResponse struct {
 	Triggers   []TriggerWithConditions `json:"triggers"`
 	Document   *PolicyDocumentInfo     `json:"document,omitempty"`
 	Metadata   PolicyDetailMetadata    `json:"metadata"`
+
+	InsuranceProvider *InsuranceProviderSummary `json:"insurance_provider,omitempty"`
 }
 
 // TriggerWithConditions - Trigger with nested conditions
@@ -697,6 +707,8 @@ type PolicyDetailFilterRequest struct {
 	Status         BasePolicyStatus `query:"status"`
 	IncludePDF     bool             `query:"include_pdf"`
 	PDFExpiryHours int              `query:"pdf_expiry_hours"`
+	// Read from the database even when the detail is cached, to debug a stale detail
+	BypassCache bool `query:"bypass_cache"`
 }
 
 // Validate validates the filter request
@@ -819,7 +831,8 @@ type VerifyNationalIDRequest struct {
 type VerifyNationalIDResponse struct {
 	Success bool `json:"success"`
 	Data    struct {
-		IsValid bool `json:"is_valid"`
+		IsValid   bool   `json:"is_valid"`
+		OwnerName string `json:"owner_name"`
 	} `json:"data"`
 	Meta struct {
 		Timestamp string `json:"timestamp"`
@@ -915,6 +928,31 @@ type ValidateClaimResponse struct {
 	PayoutID uuid.UUID `json:"payout_id"`
 }
 
+type ClaimTransitionRequest struct {
+	Notes string `json:"notes"`
+}
+
+type PayoutPreviewRequest struct {
+	BasePolicyID uuid.UUID `json:"base_policy_id"`
+	// FarmID takes the area from a registered farm; FarmAreaSqm is used otherwise
+	FarmID          *uuid.UUID `json:"farm_id,omitempty"`
+	FarmAreaSqm     float64    `json:"farm_area_sqm"`
+	BreachMagnitude float64    `json:"breach_magnitude"`
+}
+
+func (r *PayoutPreviewRequest) Validate() error {
+	if r.BasePolicyID == uuid.Nil {
+		return fmt.Errorf("base_policy_id is required")
+	}
+	if r.FarmID == nil && r.FarmAreaSqm <= 0 {
+		return fmt.Errorf("farm_id or a positive farm_area_sqm is required")
+	}
+	if r.BreachMagnitude < 0 {
+		return fmt.Errorf("breach_magnitude cannot be negative")
+	}
+	return nil
+}
+
 type ConfirmPayoutRequest struct {
 	FarmerConfirmed bool    `json:"farmer_confirmed" `
 	FarmerRating    *int    `json:"farmer_rating,omitempty" `
@@ -940,3 +978,74 @@ type ResolveConflictCancelRequestReq struct {
 	ReviewedBy    string
 	RequestID     uuid.UUID
 }
+
+type CreatePremiumScheduleRequest struct {
+	Installments int `json:"installments"`
+}
+
+func (r *CreatePremiumScheduleRequest) Validate() error {
+	if r.Installments < 1 || r.Installments > MaxPremiumInstallments {
+		return fmt.Errorf("installments must be between 1 and %d", MaxPremiumInstallments)
+	}
+	return nil
+}
+
+type CreatePremiumPaymentRequest struct {
+	ReturnURL string `json:"return_url"`
+	CancelURL string `json:"cancel_url"`
+}
+
+func (r *CreatePremiumPaymentRequest) Validate() error {
+	if r.ReturnURL == "" || r.CancelURL == "" {
+		return fmt.Errorf("return_url and cancel_url are required")
+	}
+	return nil
+}
+
+type CreatePolicyImportRequest struct {
+	BasePolicyID uuid.UUID `json:"base_policy_id"`
+	FileName     string    `json:"file_name"`
+	Data         string    `json:"data"` // base64 encoded CSV or XLSX file
+}
+
+func (r *CreatePolicyImportRequest) Validate() error {
+	if r.BasePolicyID == uuid.Nil {
+		return errors.New("base_policy_id is required")
+	}
+	ext := strings.ToLower(filepath.Ext(r.FileName))
+	if ext != ".csv" && ext != ".xlsx" {
+		return errors.New("file_name must be a .csv or .xlsx file")
+	}
+	if r.Data == "" {
+		return errors.New("data is required")
+	}
+	return nil
+}
+
+// ReviewCropVerificationRequest is an insurer confirming the declared crop of a farm or
+// overriding it with the crop actually grown
+type ReviewCropVerificationRequest struct {
+	Decision CropVerificationDecision `json:"decision"`
+	CropType string                   `json:"crop_type,omitempty"`
+	Reason   string                   `json:"reason,omitempty"`
+}
+
+func (r *ReviewCropVerificationRequest) Validate() error {
+	r.CropType = strings.TrimSpace(r.CropType)
+	switch r.Decision {
+	case CropVerificationConfirmed:
+		if r.CropType != "" {
+			return fmt.Errorf("crop_type is only given to override the crop")
+		}
+	case CropVerificationOverridden:
+		if r.CropType == "" {
+			return fmt.Errorf("crop_type is required to override the crop")
+		}
+		if strings.TrimSpace(r.Reason) == "" {
+			return fmt.Errorf("reason is required to override the crop")
+		}
+	default:
+		return fmt.Errorf("decision must be one of: confirmed, overridden")
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type BasePolicyDataCost struct {
 	BasePolicyID      uuid.UUID `json:"base_policy_id" db:"base_policy_id"`
@@ -17,4 +21,69 @@ type MonthlyDataCostResponse struct {
 	TotalActivePolicies     int                  `json:"total_active_policies"`
 	TotalBasePolicyDataCost float64              `json:"total_base_policy_data_cost"`
 	Currency                string               `json:"currency"`
+
+	// Billing under the partner contract in effect on the first day of the month;
+	// ContractTerms is null when the partner has no contract and no markup applies
+	ContractTerms         *PartnerContractTerms `json:"contract_terms"`
+	DataCostMarkup        float64               `json:"data_cost_markup"`
+	TotalBillableDataCost float64               `json:"total_billable_data_cost"`
+}
+
+// PartnerContractTerms - the platform↔partner contract terms served by profile-service
+type PartnerContractTerms struct {
+	ContractID            string  `json:"contract_id"`
+	Version               int     `json:"version"`
+	RevenueSharePercent   float64 `json:"revenue_share_percent"`
+	DataCostMarkupPercent float64 `json:"data_cost_markup_percent"`
+	Currency              string  `json:"currency"`
+	ValidFrom             string  `json:"valid_from"`
+	ValidTo               *string `json:"valid_to,omitempty"`
+}
+
+// InsuranceProviderSummary - the insurance partner summary served by profile-service
+type InsuranceProviderSummary struct {
+	PartnerID            string  `json:"partner_id"`
+	PartnerDisplayName   string  `json:"partner_display_name"`
+	PartnerLogoURL       string  `json:"partner_logo_url"`
+	PartnerPhone         string  `json:"partner_phone"`
+	PartnerOfficialEmail string  `json:"partner_official_email"`
+	Hotline              string  `json:"hotline"`
+	PartnerRatingScore   float64 `json:"partner_rating_score"`
+	PartnerRatingCount   int     `json:"partner_rating_count"`
+	Status               string  `json:"status"`
+}
+
+// PremiumPaymentIntent - a checkout created with payment-service for the premium of a policy
+type PremiumPaymentIntent struct {
+	RegisteredPolicyID uuid.UUID   `json:"registered_policy_id"`
+	PaymentType        PaymentType `json:"payment_type"`
+	Amount             float64     `json:"amount"`
+	InstallmentNumber  *int        `json:"installment_number,omitempty"`
+	CheckoutURL        string      `json:"checkout_url"`
+	OrderCode          string      `json:"order_code"`
+	QRCode             string      `json:"qr_code,omitempty"`
+	ExpiredAt          *time.Time  `json:"expired_at,omitempty"`
+}
+
+// MonitoringCalendar lays out over the coverage of a policy when each of its triggers is
+// evaluated, and the blackout windows in which it is skipped. Dates are YYYY-MM-DD, inclusive.
+type MonitoringCalendar struct {
+	PolicyID  uuid.UUID                   `json:"policy_id"`
+	StartDate string                      `json:"start_date"`
+	EndDate   string                      `json:"end_date"`
+	Triggers  []TriggerMonitoringCalendar `json:"triggers"`
+}
+
+type TriggerMonitoringCalendar struct {
+	TriggerID            uuid.UUID                  `json:"trigger_id"`
+	GrowthStage          *string                    `json:"growth_stage,omitempty"`
+	MonitorInterval      int                        `json:"monitor_interval"`
+	MonitorFrequencyUnit MonitorFrequency           `json:"monitor_frequency_unit"`
+	Windows              []MonitoringCalendarWindow `json:"windows"`
+}
+
+type MonitoringCalendarWindow struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Blackout  bool   `json:"blackout"`
 }
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AIUsageRepository struct {
+	db *sqlx.DB
+}
+
+func NewAIUsageRepository(db *sqlx.DB) *AIUsageRepository {
+	return &AIUsageRepository{db: db}
+}
+
+func (r *AIUsageRepository) BeginTransaction() (*sqlx.Tx, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// LockProviderTx serializes the recording of usage of an insurance provider until the end of
+// tx, so concurrent jobs see each other's spend when checking the budget
+func (r *AIUsageRepository) LockProviderTx(tx *sqlx.Tx, ctx context.Context, insuranceProviderID string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('ai_usage:' || $1))`, insuranceProviderID); err != nil {
+		return fmt.Errorf("failed to lock AI usage of provider: %w", err)
+	}
+	return nil
+}
+
+// GetCostTx returns the estimated cost of the usage of an insurance provider created in [from, to)
+func (r *AIUsageRepository) GetCostTx(tx *sqlx.Tx, ctx context.Context, insuranceProviderID string, from, to time.Time) (float64, error) {
+	var cost float64
+	query := `
+		SELECT COALESCE(SUM(estimated_cost_usd), 0)
+		FROM ai_usage
+		WHERE insurance_provider_id = $1 AND created_at >= $2 AND created_at < $3`
+
+	if err := tx.GetContext(ctx, &cost, query, insuranceProviderID, from, to); err != nil {
+		return 0, fmt.Errorf("failed to get AI cost of provider: %w", err)
+	}
+	return cost, nil
+}
+
+func (r *AIUsageRepository) CreateTx(tx *sqlx.Tx, ctx context.Context, usage *models.AIUsage) error {
+	if usage.ID == uuid.Nil {
+		usage.ID = uuid.New()
+	}
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO ai_usage (
+			id, job_run_id, provider, model, job_type, insurance_provider_id, base_policy_id, registered_policy_id,
+			call_count, prompt_tokens, response_tokens, total_tokens, estimated_cost_usd, succeeded, created_at
+		) VALUES (
+			:id, :job_run_id, :provider, :model, :job_type, :insurance_provider_id, :base_policy_id, :registered_policy_id,
+			:call_count, :prompt_tokens, :response_tokens, :total_tokens, :estimated_cost_usd, :succeeded, :created_at
+		)`
+
+	query, args, err := tx.BindNamed(query, usage)
+	if err != nil {
+		return fmt.Errorf("failed to bind AI usage: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create AI usage: %w", err)
+	}
+	return nil
+}
+
+// aiUsageTotals sums a group of usage rows; a job run on two models counts once
+const aiUsageTotals = `
+	COUNT(DISTINCT job_run_id) AS job_count,
+	COALESCE(SUM(call_count), 0) AS call_count,
+	COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+	COALESCE(SUM(response_tokens), 0) AS response_tokens,
+	COALESCE(SUM(total_tokens), 0) AS total_tokens,
+	COALESCE(SUM(estimated_cost_usd), 0) AS estimated_cost_usd`
+
+// SummarizeByMonth returns the usage per month of the filter range, most recent first
+func (r *AIUsageRepository) SummarizeByMonth(ctx context.Context, filter models.AIUsageFilter) ([]models.AIUsageSummary, error) {
+	query := `
+		SELECT TO_CHAR(DATE_TRUNC('month', created_at), 'YYYY-MM') AS month,` + aiUsageTotals + `
+		FROM ai_usage
+		WHERE created_at >= $1 AND created_at < $2
+			AND ($3 = '' OR insurance_provider_id = $3)
+		GROUP BY DATE_TRUNC('month', created_at)
+		ORDER BY DATE_TRUNC('month', created_at) DESC`
+
+	summaries := []models.AIUsageSummary{}
+	if err := r.db.SelectContext(ctx, &summaries, query, filter.From, filter.To, filter.InsuranceProviderID); err != nil {
+		return nil, fmt.Errorf("failed to summarize AI usage by month: %w", err)
+	}
+	return summaries, nil
+}
+
+// SummarizeByProvider returns the usage per insurance provider in the filter range, most
+// expensive first
+func (r *AIUsageRepository) SummarizeByProvider(ctx context.Context, filter models.AIUsageFilter) ([]models.AIUsageSummary, error) {
+	query := `
+		SELECT insurance_provider_id,` + aiUsageTotals + `
+		FROM ai_usage
+		WHERE created_at >= $1 AND created_at < $2
+			AND ($3 = '' OR insurance_provider_id = $3)
+		GROUP BY insurance_provider_id
+		ORDER BY estimated_cost_usd DESC`
+
+	summaries := []models.AIUsageSummary{}
+	if err := r.db.SelectContext(ctx, &summaries, query, filter.From, filter.To, filter.InsuranceProviderID); err != nil {
+		return nil, fmt.Errorf("failed to summarize AI usage by provider: %w", err)
+	}
+	return summaries, nil
+}
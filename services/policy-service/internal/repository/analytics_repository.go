@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Materialized views behind the analytics endpoints, refreshed in this order
+var analyticsViews = []string{
+	"analytics_policy_portfolio",
+	"analytics_monthly_activity",
+}
+
+// Columns of analytics_policy_portfolio a portfolio can be broken down by
+var portfolioDimensions = map[string]bool{
+	"status":    true,
+	"crop_type": true,
+	"province":  true,
+}
+
+// AnalyticsRepository reads the insurer analytics from materialized views, so the dashboards do
+// not aggregate the policy, claim and payout tables on each request
+type AnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+func NewAnalyticsRepository(db *sqlx.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// RefreshViews refreshes the analytics views without blocking their readers and records when
+// each was refreshed
+func (r *AnalyticsRepository) RefreshViews(ctx context.Context) error {
+	for _, view := range analyticsViews {
+		if _, err := r.db.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+view); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+		query := `
+			INSERT INTO analytics_refresh (view_name, refreshed_at) VALUES ($1, NOW())
+			ON CONFLICT (view_name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at`
+		if _, err := r.db.ExecContext(ctx, query, view); err != nil {
+			return fmt.Errorf("failed to record refresh of %s: %w", view, err)
+		}
+	}
+	return nil
+}
+
+// GetRefreshedAt returns when the least recently refreshed analytics view was refreshed, nil if
+// none was
+func (r *AnalyticsRepository) GetRefreshedAt(ctx context.Context) (*time.Time, error) {
+	var refreshedAt sql.NullTime
+	query := `SELECT MIN(refreshed_at) FROM analytics_refresh`
+	if err := r.db.GetContext(ctx, &refreshedAt, query); err != nil {
+		return nil, fmt.Errorf("failed to get analytics refresh time: %w", err)
+	}
+	if !refreshedAt.Valid {
+		return nil, nil
+	}
+	return &refreshedAt.Time, nil
+}
+
+// GetPortfolioBreakdown groups the policies of a provider, or of all providers when providerID is
+// empty, by dimension: status, crop_type or province. Largest groups come first.
+func (r *AnalyticsRepository) GetPortfolioBreakdown(ctx context.Context, providerID, dimension string) ([]models.PortfolioBreakdown, error) {
+	if !portfolioDimensions[dimension] {
+		return nil, fmt.Errorf("invalid portfolio dimension: %s", dimension)
+	}
+
+	breakdown := []models.PortfolioBreakdown{}
+	query := `
+		SELECT ` + dimension + ` AS key,
+			SUM(policy_count)::BIGINT AS policy_count,
+			SUM(coverage_amount) AS coverage_amount,
+			SUM(premium_written) AS premium_written
+		FROM analytics_policy_portfolio
+		WHERE ($1 = '' OR insurance_provider_id = $1)
+		GROUP BY ` + dimension + `
+		ORDER BY policy_count DESC, key`
+
+	if err := r.db.SelectContext(ctx, &breakdown, query, providerID); err != nil {
+		return nil, fmt.Errorf("failed to get portfolio by %s: %w", dimension, err)
+	}
+	return breakdown, nil
+}
+
+// GetPortfolioTotals sums the monthly activity of a provider, or of all providers when
+// providerID is empty
+func (r *AnalyticsRepository) GetPortfolioTotals(ctx context.Context, providerID string) (*models.PortfolioTotals, error) {
+	var totals models.PortfolioTotals
+	query := `
+		SELECT
+			COALESCE(SUM(premium_collected), 0) AS premium_collected,
+			COALESCE(SUM(payouts_paid), 0) AS payouts_paid,
+			COALESCE(SUM(payout_count), 0)::BIGINT AS payout_count,
+			COALESCE(SUM(claim_count), 0)::BIGINT AS claim_count,
+			COALESCE(SUM(rejected_claim_count), 0)::BIGINT AS rejected_claim_count
+		FROM analytics_monthly_activity
+		WHERE ($1 = '' OR insurance_provider_id = $1)`
+
+	if err := r.db.GetContext(ctx, &totals, query, providerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &totals, nil
+		}
+		return nil, fmt.Errorf("failed to get portfolio totals: %w", err)
+	}
+	return &totals, nil
+}
+
+// GetMonthlyActivity returns the months in [from, to) with activity of a provider, or of all
+// providers when providerID is empty, oldest first. Months without activity are left out.
+func (r *AnalyticsRepository) GetMonthlyActivity(ctx context.Context, providerID string, from, to time.Time) ([]models.MonthlyActivity, error) {
+	activity := []models.MonthlyActivity{}
+	query := `
+		SELECT
+			month,
+			SUM(premium_collected) AS premium_collected,
+			SUM(payouts_paid) AS payouts_paid,
+			SUM(payout_count)::BIGINT AS payout_count,
+			SUM(claim_count)::BIGINT AS claim_count,
+			SUM(rejected_claim_count)::BIGINT AS rejected_claim_count,
+			SUM(policies_registered)::BIGINT AS policies_registered
+		FROM analytics_monthly_activity
+		WHERE ($1 = '' OR insurance_provider_id = $1)
+			AND month >= $2::DATE AND month < $3::DATE
+		GROUP BY month
+		ORDER BY month`
+
+	if err := r.db.SelectContext(ctx, &activity, query, providerID, from.Format(time.DateOnly), to.Format(time.DateOnly)); err != nil {
+		return nil, fmt.Errorf("failed to get monthly activity: %w", err)
+	}
+	return activity, nil
+}
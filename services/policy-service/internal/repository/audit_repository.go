@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"policy-service/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+const insertAuditLogQuery = `
+	INSERT INTO audit_log (
+		id, entity_type, entity_id, action, actor_id, request_id, changes, created_at
+	) VALUES (
+		:id, :entity_type, :entity_id, :action, :actor_id, :request_id, :changes, :created_at
+	)`
+
+func prepareAuditLog(entry *models.AuditLog) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	entry.CreatedAt = time.Now()
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	prepareAuditLog(entry)
+	if _, err := r.db.NamedExecContext(ctx, insertAuditLogQuery, entry); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// CreateTx stores an entry in the transaction of the change it records
+func (r *AuditRepository) CreateTx(tx *sqlx.Tx, ctx context.Context, entry *models.AuditLog) error {
+	prepareAuditLog(entry)
+	query, args, err := tx.BindNamed(insertAuditLogQuery, entry)
+	if err != nil {
+		return fmt.Errorf("failed to bind audit log: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLog, error) {
+	conditions := []string{"entity_type = $1"}
+	args := []any{filter.EntityType}
+	if filter.EntityID != nil {
+		args = append(args, *filter.EntityID)
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", len(args)))
+	}
+	if filter.ActorID != "" {
+		args = append(args, filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, entity_type, entity_id, action, actor_id, request_id, changes, created_at
+		FROM audit_log
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	var entries []models.AuditLog
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return entries, nil
+}
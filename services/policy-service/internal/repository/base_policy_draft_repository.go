@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+// DraftExpiryKeySuffix ends the Redis key {draftID}--BasePolicyDraft--ValidationWindow, which
+// expires when the validation window of the draft ends
+const DraftExpiryKeySuffix = "--BasePolicyDraft--ValidationWindow"
+
+// basePolicyDraftCacheTTL bounds how long a cached draft stays stale when it is refilled from a
+// read that raced with a status change
+const basePolicyDraftCacheTTL = 10 * time.Minute
+
+// BasePolicyDraftRepository keeps the drafts of base policies in Postgres. Redis only caches
+// drafts read by ID and holds the key whose expiry ends their validation window.
+type BasePolicyDraftRepository struct {
+	db          *sqlx.DB
+	redisClient *redis.Client
+}
+
+func NewBasePolicyDraftRepository(db *sqlx.DB, redisClient *redis.Client) *BasePolicyDraftRepository {
+	return &BasePolicyDraftRepository{
+		db:          db,
+		redisClient: redisClient,
+	}
+}
+
+const basePolicyDraftColumns = `
+	id, insurance_provider_id, status, auto_commit, content, validations,
+	expires_at, committed_at, expired_at, created_at, updated_at`
+
+// ============================================================================
+// DRAFTS
+// ============================================================================
+
+// Create stages a draft. An import of a draft that exists already is ignored; created reports
+// whether the draft was inserted.
+func (r *BasePolicyDraftRepository) Create(ctx context.Context, draft *models.DraftPolicy) (created bool, err error) {
+	if draft.Status == "" {
+		draft.Status = models.DraftPending
+	}
+	draft.CreatedAt = time.Now()
+	draft.UpdatedAt = draft.CreatedAt
+
+	query := `
+		INSERT INTO base_policy_draft (` + basePolicyDraftColumns + `
+		) VALUES (
+			:id, :insurance_provider_id, :status, :auto_commit, :content, :validations,
+			:expires_at, :committed_at, :expired_at, :created_at, :updated_at
+		)
+		ON CONFLICT (id) DO NOTHING`
+
+	result, err := r.db.NamedExecContext(ctx, query, draft)
+	if err != nil {
+		return false, fmt.Errorf("failed to create base policy draft: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create base policy draft: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetByID returns a draft whatever its status, from the cache when it is there
+func (r *BasePolicyDraftRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DraftPolicy, error) {
+	if draft, ok := r.getCached(ctx, id); ok {
+		return draft, nil
+	}
+
+	var draft models.DraftPolicy
+	query := `SELECT ` + basePolicyDraftColumns + ` FROM base_policy_draft WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &draft, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("not found: base policy draft %s", id)
+		}
+		return nil, fmt.Errorf("failed to get base policy draft: %w", err)
+	}
+	r.cache(ctx, &draft)
+	return &draft, nil
+}
+
+// ListPending lists the pending drafts matching filter, oldest first
+func (r *BasePolicyDraftRepository) ListPending(ctx context.Context, filter models.DraftFilter) ([]models.DraftPolicy, error) {
+	drafts := []models.DraftPolicy{}
+	args := []any{models.DraftPending}
+	query := `SELECT ` + basePolicyDraftColumns + ` FROM base_policy_draft WHERE status = $1`
+
+	if filter.InsuranceProviderID != "" {
+		args = append(args, filter.InsuranceProviderID)
+		query += fmt.Sprintf(" AND insurance_provider_id = $%d", len(args))
+	}
+	if filter.ID != nil {
+		args = append(args, *filter.ID)
+		query += fmt.Sprintf(" AND id = $%d", len(args))
+	}
+	if filter.AutoCommit != nil {
+		args = append(args, *filter.AutoCommit)
+		query += fmt.Sprintf(" AND auto_commit = $%d", len(args))
+	}
+	query += " ORDER BY created_at, id"
+
+	if err := r.db.SelectContext(ctx, &drafts, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list base policy drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+// AddValidation appends a document validation to a pending draft
+func (r *BasePolicyDraftRepository) AddValidation(ctx context.Context, id uuid.UUID, validation *models.BasePolicyDocumentValidation) error {
+	validationJSON, err := json.Marshal(models.DraftValidations{validation})
+	if err != nil {
+		return fmt.Errorf("failed to encode draft validation: %w", err)
+	}
+
+	query := `
+		UPDATE base_policy_draft SET validations = validations || $2::jsonb, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+
+	result, err := r.db.ExecContext(ctx, query, id, validationJSON)
+	if err != nil {
+		return fmt.Errorf("failed to add draft validation: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("not found: pending base policy draft %s", id)
+	}
+	r.EvictCache(ctx, id)
+	return nil
+}
+
+// MarkCommittedTx marks a pending draft committed with the transaction writing its base policy,
+// so a draft committed concurrently fails the second transaction instead of being written twice
+func (r *BasePolicyDraftRepository) MarkCommittedTx(tx *sqlx.Tx, ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE base_policy_draft SET status = 'committed', committed_at = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+
+	result, err := tx.ExecContext(ctx, query, id, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to mark base policy draft committed: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark base policy draft committed: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("invalid operation: base policy draft %s is no longer pending", id)
+	}
+	return nil
+}
+
+// MarkExpired ends a pending draft whose validation window ended without a commit. expired
+// reports false when the draft was no longer pending.
+func (r *BasePolicyDraftRepository) MarkExpired(ctx context.Context, id uuid.UUID) (expired bool, err error) {
+	query := `
+		UPDATE base_policy_draft SET status = 'expired', expired_at = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+
+	result, err := r.db.ExecContext(ctx, query, id, time.Now().Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to mark base policy draft expired: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark base policy draft expired: %w", err)
+	}
+	r.EvictCache(ctx, id)
+	return rows > 0, nil
+}
+
+// ============================================================================
+// REDIS
+// ============================================================================
+
+func basePolicyDraftCacheKey(id uuid.UUID) string {
+	return "base_policy_draft:" + id.String()
+}
+
+// DraftExpiryKey is the key whose expiry ends the validation window of a draft
+func DraftExpiryKey(id uuid.UUID) string {
+	return id.String() + DraftExpiryKeySuffix
+}
+
+// ScheduleExpiry sets the key whose expiry ends the validation window of a draft after ttl
+func (r *BasePolicyDraftRepository) ScheduleExpiry(ctx context.Context, id uuid.UUID, ttl time.Duration) error {
+	if err := r.redisClient.Set(ctx, DraftExpiryKey(id), "", ttl).Err(); err != nil {
+		return fmt.Errorf("failed to schedule base policy draft expiry: %w", err)
+	}
+	return nil
+}
+
+// CancelExpiry deletes the expiry key of drafts that were committed before their window ended
+func (r *BasePolicyDraftRepository) CancelExpiry(ctx context.Context, ids ...uuid.UUID) {
+	if r.redisClient == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = DraftExpiryKey(id)
+	}
+	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+		slog.Warn("Failed to delete base policy draft expiry keys", "draft_ids", ids, "error", err)
+	}
+}
+
+func (r *BasePolicyDraftRepository) getCached(ctx context.Context, id uuid.UUID) (*models.DraftPolicy, bool) {
+	if r.redisClient == nil {
+		return nil, false
+	}
+	data, err := r.redisClient.Get(ctx, basePolicyDraftCacheKey(id)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Failed to read cached base policy draft", "draft_id", id, "error", err)
+		}
+		return nil, false
+	}
+
+	var draft models.DraftPolicy
+	if err := json.Unmarshal(data, &draft); err != nil {
+		slog.Warn("Failed to decode cached base policy draft", "draft_id", id, "error", err)
+		return nil, false
+	}
+	return &draft, true
+}
+
+func (r *BasePolicyDraftRepository) cache(ctx context.Context, draft *models.DraftPolicy) {
+	if r.redisClient == nil {
+		return
+	}
+	data, err := json.Marshal(draft)
+	if err != nil {
+		slog.Warn("Failed to encode base policy draft for cache", "draft_id", draft.ID, "error", err)
+		return
+	}
+	if err := r.redisClient.Set(ctx, basePolicyDraftCacheKey(draft.ID), data, basePolicyDraftCacheTTL).Err(); err != nil {
+		slog.Warn("Failed to cache base policy draft", "draft_id", draft.ID, "error", err)
+	}
+}
+
+// EvictCache drops the cached drafts whose status or validations changed. Writes through this
+// repository call it themselves; callers committing a transaction call it once it is committed.
+func (r *BasePolicyDraftRepository) EvictCache(ctx context.Context, ids ...uuid.UUID) {
+	if r.redisClient == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = basePolicyDraftCacheKey(id)
+	}
+	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+		slog.Error("Failed to evict cached base policy drafts", "draft_ids", ids, "error", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	utils "agrisa_utils"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"policy-service/internal/models"
@@ -41,29 +43,6 @@ func (r *BasePolicyRepository) GetTempBasePolicyModels(ctx context.Context, key
 	return data, nil
 }
 
-func (r *BasePolicyRepository) DeleteTempBasePolicyModel(ctx context.Context, key string) error {
-	err := r.redisClient.Del(ctx, key).Err()
-	return err
-}
-
-func (r *BasePolicyRepository) CreateTempBasePolicyModelsWTransaction(ctx context.Context, model []byte, key string, tx redis.Pipeliner, expiration time.Duration) error {
-	err := tx.Set(ctx, key, model, expiration+5*time.Minute).Err()
-	if err != nil {
-		return err
-	}
-	if strings.Contains(key, "--BasePolicy--archive:true") {
-		err := tx.Set(ctx, key+"--COMMIT_EVENT", 1, expiration).Err()
-		if err != nil {
-			slog.Error("commit event key failed", "error", err)
-		}
-	}
-	return err
-}
-
-func (r *BasePolicyRepository) BeginRedisTransaction() redis.Pipeliner {
-	return r.redisClient.TxPipeline()
-}
-
 func (r *BasePolicyRepository) FindKeysByPattern(ctx context.Context, pattern, exclude string) ([]string, error) {
 	var keys []string
 
@@ -81,6 +60,90 @@ func (r *BasePolicyRepository) FindKeysByPattern(ctx context.Context, pattern, e
 	return keys, nil
 }
 
+// ============================================================================
+// COMPLETE POLICY DETAIL CACHE
+// ============================================================================
+
+// basePolicyDetailCacheTTL bounds how long a detail stays stale when it is refilled from a read
+// that raced with an update
+const basePolicyDetailCacheTTL = 10 * time.Minute
+
+func basePolicyDetailCacheKey(basePolicyID uuid.UUID) string {
+	return "base_policy_detail:" + basePolicyID.String()
+}
+
+// GetCachedPolicyDetail returns the cached complete detail of a base policy. A miss, or Redis
+// being unavailable, reports false so the caller reads the database.
+func (r *BasePolicyRepository) GetCachedPolicyDetail(ctx context.Context, basePolicyID uuid.UUID) (*models.CompletePolicyDetailResponse, bool) {
+	if r.redisClient == nil {
+		return nil, false
+	}
+	data, err := r.redisClient.Get(ctx, basePolicyDetailCacheKey(basePolicyID)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Failed to read cached policy detail", "policy_id", basePolicyID, "error", err)
+		}
+		return nil, false
+	}
+
+	var detail models.CompletePolicyDetailResponse
+	if err := json.Unmarshal(data, &detail); err != nil {
+		slog.Warn("Failed to decode cached policy detail", "policy_id", basePolicyID, "error", err)
+		return nil, false
+	}
+	return &detail, true
+}
+
+// CachePolicyDetail stores the complete detail of a base policy until it changes
+func (r *BasePolicyRepository) CachePolicyDetail(ctx context.Context, detail *models.CompletePolicyDetailResponse) {
+	if r.redisClient == nil {
+		return
+	}
+	data, err := json.Marshal(detail)
+	if err != nil {
+		slog.Warn("Failed to encode policy detail for cache", "policy_id", detail.BasePolicy.ID, "error", err)
+		return
+	}
+	if err := r.redisClient.Set(ctx, basePolicyDetailCacheKey(detail.BasePolicy.ID), data, basePolicyDetailCacheTTL).Err(); err != nil {
+		slog.Warn("Failed to cache policy detail", "policy_id", detail.BasePolicy.ID, "error", err)
+	}
+}
+
+// InvalidatePolicyDetailCache drops the cached details of base policies whose policy, triggers
+// or conditions changed. Writes through this repository call it themselves; callers committing
+// a transaction call it once the transaction is committed.
+func (r *BasePolicyRepository) InvalidatePolicyDetailCache(ctx context.Context, basePolicyIDs ...uuid.UUID) {
+	if r.redisClient == nil || len(basePolicyIDs) == 0 {
+		return
+	}
+	keys := make([]string, len(basePolicyIDs))
+	for i, id := range basePolicyIDs {
+		keys[i] = basePolicyDetailCacheKey(id)
+	}
+	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+		slog.Error("Failed to invalidate cached policy details", "policy_ids", basePolicyIDs, "error", err)
+	}
+}
+
+// policyIDsOf returns the base policy IDs selected by query, for writes made by trigger or
+// condition ID. Looked up before the write, as a deleted row can no longer be joined.
+func (r *BasePolicyRepository) policyIDsOf(query string, args ...any) []uuid.UUID {
+	var ids []uuid.UUID
+	if err := r.db.Select(&ids, query, args...); err != nil {
+		slog.Warn("Failed to look up base policy for cache invalidation", "error", err)
+	}
+	return ids
+}
+
+const (
+	policyIDOfTriggerQuery   = `SELECT base_policy_id FROM base_policy_trigger WHERE id = $1`
+	policyIDOfConditionQuery = `
+		SELECT t.base_policy_id
+		FROM base_policy_trigger_condition c
+		JOIN base_policy_trigger t ON t.id = c.base_policy_trigger_id
+		WHERE c.id = $1`
+)
+
 func (r *BasePolicyRepository) CreateBasePolicy(policy *models.BasePolicy) error {
 	if policy.ID == uuid.Nil {
 		policy.ID = uuid.New()
@@ -379,6 +442,8 @@ func (r *BasePolicyRepository) UpdateBasePolicy(policy *models.BasePolicy) error
 		return fmt.Errorf("base policy not found")
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), policy.ID)
+
 	slog.Info("Successfully updated base policy",
 		"policy_id", policy.ID,
 		"rows_affected", rowsAffected,
@@ -493,6 +558,7 @@ func (r *BasePolicyRepository) DeleteBasePolicy(id uuid.UUID) error {
 		return fmt.Errorf("base policy not found")
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), id)
 	return nil
 }
 
@@ -560,6 +626,7 @@ func (r *BasePolicyRepository) CreateBasePolicyTrigger(trigger *models.BasePolic
 		return fmt.Errorf("failed to create base policy trigger: %w", err)
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), trigger.BasePolicyID)
 	return nil
 }
 
@@ -643,10 +710,12 @@ func (r *BasePolicyRepository) UpdateBasePolicyTrigger(trigger *models.BasePolic
 		return fmt.Errorf("base policy trigger not found")
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), r.policyIDsOf(policyIDOfTriggerQuery, trigger.ID)...)
 	return nil
 }
 
 func (r *BasePolicyRepository) DeleteBasePolicyTrigger(id uuid.UUID) error {
+	policyIDs := r.policyIDsOf(policyIDOfTriggerQuery, id)
 	query := `DELETE FROM base_policy_trigger WHERE id = $1`
 
 	result, err := r.db.Exec(query, id)
@@ -663,6 +732,7 @@ func (r *BasePolicyRepository) DeleteBasePolicyTrigger(id uuid.UUID) error {
 		return fmt.Errorf("base policy trigger not found")
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), policyIDs...)
 	return nil
 }
 
@@ -674,6 +744,7 @@ func (r *BasePolicyRepository) DeleteBasePolicyTriggersByPolicyID(policyID uuid.
 		return fmt.Errorf("failed to delete base policy triggers by policy ID: %w", err)
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), policyID)
 	return nil
 }
 
@@ -720,6 +791,7 @@ func (r *BasePolicyRepository) CreateBasePolicyTriggerCondition(condition *model
 		return fmt.Errorf("failed to create base policy trigger condition: %w", err)
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), r.policyIDsOf(policyIDOfTriggerQuery, condition.BasePolicyTriggerID)...)
 	return nil
 }
 
@@ -898,10 +970,12 @@ func (r *BasePolicyRepository) UpdateBasePolicyTriggerCondition(condition *model
 		return fmt.Errorf("base policy trigger condition not found")
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), r.policyIDsOf(policyIDOfConditionQuery, condition.ID)...)
 	return nil
 }
 
 func (r *BasePolicyRepository) DeleteBasePolicyTriggerCondition(id uuid.UUID) error {
+	policyIDs := r.policyIDsOf(policyIDOfConditionQuery, id)
 	query := `DELETE FROM base_policy_trigger_condition WHERE id = $1`
 
 	result, err := r.db.Exec(query, id)
@@ -918,6 +992,7 @@ func (r *BasePolicyRepository) DeleteBasePolicyTriggerCondition(id uuid.UUID) er
 		return fmt.Errorf("base policy trigger condition not found")
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), policyIDs...)
 	return nil
 }
 
@@ -929,6 +1004,7 @@ func (r *BasePolicyRepository) DeleteBasePolicyTriggerConditionsByTriggerID(trig
 		return fmt.Errorf("failed to delete base policy trigger conditions by trigger ID: %w", err)
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), r.policyIDsOf(policyIDOfTriggerQuery, triggerID)...)
 	return nil
 }
 
@@ -944,6 +1020,7 @@ func (r *BasePolicyRepository) DeleteBasePolicyTriggerConditionsByPolicyID(polic
 		return fmt.Errorf("failed to delete base policy trigger conditions by policy ID: %w", err)
 	}
 
+	r.InvalidatePolicyDetailCache(context.Background(), policyID)
 	return nil
 }
 
@@ -1633,48 +1710,8 @@ func (r *BasePolicyRepository) GetTriggersWithConditionsByPolicyID(
 	return result, nil
 }
 
-// SaveValidationToRedis saves a validation record to Redis using validation ID
-func (r *BasePolicyRepository) SaveValidationToRedis(
-	ctx context.Context,
-	validation *models.BasePolicyDocumentValidation,
-) error {
-	// Build Redis key using validation ID (no index calculation needed)
-	key := fmt.Sprintf("%s--BasePolicyDocumentValidation--%s",
-		validation.BasePolicyID, validation.ID)
-
-	slog.Info("Saving validation to Redis",
-		"base_policy_id", validation.BasePolicyID,
-		"validation_id", validation.ID,
-		"key", key)
-
-	// Serialize validation
-	validationBytes, err := utils.SerializeModel(validation)
-	if err != nil {
-		slog.Error("Failed to serialize validation",
-			"validation_id", validation.ID,
-			"error", err)
-		return fmt.Errorf("failed to serialize validation: %w", err)
-	}
-
-	// Save to Redis with TTL (24 hours)
-	ttl := 24 * time.Hour
-	if err := r.redisClient.Set(ctx, key, validationBytes, ttl).Err(); err != nil {
-		slog.Error("Failed to save validation to Redis",
-			"validation_id", validation.ID,
-			"key", key,
-			"error", err)
-		return fmt.Errorf("failed to save validation to Redis: %w", err)
-	}
-
-	slog.Info("Successfully saved validation to Redis",
-		"base_policy_id", validation.BasePolicyID,
-		"validation_id", validation.ID,
-		"key", key)
-
-	return nil
-}
-
-// GetValidationsFromRedis retrieves all validation records for a policy
+// GetValidationsFromRedis retrieves the validation records a draft kept in Redis before drafts
+// moved to Postgres, for their import
 func (r *BasePolicyRepository) GetValidationsFromRedis(
 	ctx context.Context,
 	basePolicyID uuid.UUID,
@@ -1802,6 +1839,7 @@ func (r *BasePolicyRepository) UpdateStatus(basePolicyID uuid.UUID, status model
 	if err != nil {
 		return fmt.Errorf("failed to update base policy status: %w", err)
 	}
+	r.InvalidatePolicyDetailCache(context.Background(), basePolicyID)
 	return nil
 }
 
@@ -1855,6 +1893,7 @@ func (r *BasePolicyRepository) BulkUpdateBasePolicyStatus(
 			"error", err)
 		return 0, fmt.Errorf("failed to execute bulk status update: %w", err)
 	}
+	r.InvalidatePolicyDetailCache(context.Background(), policyIDs...)
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
@@ -1973,6 +2012,7 @@ func (r *BasePolicyRepository) BulkUpdateProviderID(
 			"error", err)
 		return 0, fmt.Errorf("failed to execute bulk provider ID update: %w", err)
 	}
+	r.InvalidatePolicyDetailCache(context.Background(), policyIDs...)
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
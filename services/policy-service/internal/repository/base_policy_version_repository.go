@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"policy-service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type BasePolicyVersionRepository struct {
+	db *sqlx.DB
+}
+
+func NewBasePolicyVersionRepository(db *sqlx.DB) *BasePolicyVersionRepository {
+	return &BasePolicyVersionRepository{db: db}
+}
+
+// Inserts the current terms as the next version unless they equal the latest version.
+// base_policy_terms (migration 00009) defines what the terms are.
+const recordBasePolicyVersionQuery = `
+	WITH current_terms AS (
+		SELECT base_policy_terms($1) AS terms
+	), latest AS (
+		SELECT version, terms FROM base_policy_version
+		WHERE base_policy_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	)
+	INSERT INTO base_policy_version (id, base_policy_id, version, terms, created_by, created_at)
+	SELECT $2, $1, COALESCE((SELECT version FROM latest), 0) + 1, current_terms.terms, $3, NOW()
+	FROM current_terms
+	WHERE current_terms.terms IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM latest WHERE latest.terms = current_terms.terms)
+	RETURNING version`
+
+const latestBasePolicyVersionQuery = `
+	SELECT COALESCE(MAX(version), 0) FROM base_policy_version WHERE base_policy_id = $1`
+
+// RecordVersion makes sure the current terms of a base policy are kept as a version and returns
+// its number: a new version when the terms changed since the latest one, the latest otherwise
+func (r *BasePolicyVersionRepository) RecordVersion(ctx context.Context, basePolicyID uuid.UUID, createdBy string) (int, error) {
+	return recordBasePolicyVersion(ctx, r.db, basePolicyID, createdBy)
+}
+
+// RecordVersionTx is RecordVersion within the transaction that changed the terms
+func (r *BasePolicyVersionRepository) RecordVersionTx(tx *sqlx.Tx, ctx context.Context, basePolicyID uuid.UUID, createdBy string) (int, error) {
+	return recordBasePolicyVersion(ctx, tx, basePolicyID, createdBy)
+}
+
+func recordBasePolicyVersion(ctx context.Context, q sqlx.QueryerContext, basePolicyID uuid.UUID, createdBy string) (int, error) {
+	var version int
+	err := sqlx.GetContext(ctx, q, &version, recordBasePolicyVersionQuery, basePolicyID, uuid.New(), createdBy)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to record base policy version: %w", err)
+	}
+
+	// Terms unchanged, or no such base policy
+	if err := sqlx.GetContext(ctx, q, &version, latestBasePolicyVersionQuery, basePolicyID); err != nil {
+		return 0, fmt.Errorf("failed to get latest base policy version: %w", err)
+	}
+	if version == 0 {
+		return 0, fmt.Errorf("base policy not found: %s", basePolicyID)
+	}
+	return version, nil
+}
+
+func (r *BasePolicyVersionRepository) GetVersion(ctx context.Context, basePolicyID uuid.UUID, version int) (*models.BasePolicyVersion, error) {
+	var v models.BasePolicyVersion
+	query := `
+		SELECT id, base_policy_id, version, terms, created_by, created_at
+		FROM base_policy_version
+		WHERE base_policy_id = $1 AND version = $2`
+
+	if err := r.db.GetContext(ctx, &v, query, basePolicyID, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("base policy version not found: %s v%d", basePolicyID, version)
+		}
+		return nil, fmt.Errorf("failed to get base policy version: %w", err)
+	}
+	return &v, nil
+}
+
+func (r *BasePolicyVersionRepository) ListVersions(ctx context.Context, basePolicyID uuid.UUID) ([]models.BasePolicyVersion, error) {
+	var versions []models.BasePolicyVersion
+	query := `
+		SELECT id, base_policy_id, version, terms, created_by, created_at
+		FROM base_policy_version
+		WHERE base_policy_id = $1
+		ORDER BY version DESC`
+
+	if err := r.db.SelectContext(ctx, &versions, query, basePolicyID); err != nil {
+		return nil, fmt.Errorf("failed to list base policy versions: %w", err)
+	}
+	return versions, nil
+}
@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type ClaimDisputeRepository struct {
+	db *sqlx.DB
+}
+
+func NewClaimDisputeRepository(db *sqlx.DB) *ClaimDisputeRepository {
+	return &ClaimDisputeRepository{db: db}
+}
+
+func (r *ClaimDisputeRepository) BeginTransaction() (*sqlx.Tx, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+const claimDisputeColumns = `
+	id, claim_id, registered_policy_id, farmer_id, insurance_provider_id,
+	status, reason, partner_response_due_at, partner_responded_at,
+	escalated_at, arbitration_due_at, sla_breached,
+	resolution_notes, resolved_by, resolved_at, created_at, updated_at`
+
+// ============================================================================
+// DISPUTES
+// ============================================================================
+
+func (r *ClaimDisputeRepository) CreateTx(tx *sqlx.Tx, ctx context.Context, dispute *models.ClaimDispute) error {
+	if dispute.ID == uuid.Nil {
+		dispute.ID = uuid.New()
+	}
+	dispute.CreatedAt = time.Now()
+	dispute.UpdatedAt = dispute.CreatedAt
+
+	query := `
+		INSERT INTO claim_dispute (` + claimDisputeColumns + `
+		) VALUES (
+			:id, :claim_id, :registered_policy_id, :farmer_id, :insurance_provider_id,
+			:status, :reason, :partner_response_due_at, :partner_responded_at,
+			:escalated_at, :arbitration_due_at, :sla_breached,
+			:resolution_notes, :resolved_by, :resolved_at, :created_at, :updated_at
+		)`
+
+	query, args, err := tx.BindNamed(query, dispute)
+	if err != nil {
+		return fmt.Errorf("failed to bind claim dispute: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create claim dispute: %w", err)
+	}
+	return nil
+}
+
+// UpdateTx saves the status, SLA timers and resolution of a dispute
+func (r *ClaimDisputeRepository) UpdateTx(tx *sqlx.Tx, ctx context.Context, dispute *models.ClaimDispute) error {
+	dispute.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE claim_dispute SET
+			status = :status,
+			partner_responded_at = :partner_responded_at,
+			escalated_at = :escalated_at,
+			arbitration_due_at = :arbitration_due_at,
+			sla_breached = :sla_breached,
+			resolution_notes = :resolution_notes,
+			resolved_by = :resolved_by,
+			resolved_at = :resolved_at,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	query, args, err := tx.BindNamed(query, dispute)
+	if err != nil {
+		return fmt.Errorf("failed to bind claim dispute: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update claim dispute: %w", err)
+	}
+	return nil
+}
+
+func (r *ClaimDisputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ClaimDispute, error) {
+	var dispute models.ClaimDispute
+	query := `SELECT ` + claimDisputeColumns + ` FROM claim_dispute WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &dispute, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("not found: claim dispute %s", id)
+		}
+		return nil, fmt.Errorf("failed to get claim dispute: %w", err)
+	}
+	return &dispute, nil
+}
+
+// GetByIDForUpdateTx retrieves a dispute and locks it until the transaction ends
+func (r *ClaimDisputeRepository) GetByIDForUpdateTx(tx *sqlx.Tx, ctx context.Context, id uuid.UUID) (*models.ClaimDispute, error) {
+	var dispute models.ClaimDispute
+	query := `SELECT ` + claimDisputeColumns + ` FROM claim_dispute WHERE id = $1 FOR UPDATE`
+
+	if err := tx.GetContext(ctx, &dispute, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("not found: claim dispute %s", id)
+		}
+		return nil, fmt.Errorf("failed to get claim dispute: %w", err)
+	}
+	return &dispute, nil
+}
+
+// ExistsForClaim reports whether a claim has already been disputed
+func (r *ClaimDisputeRepository) ExistsForClaim(ctx context.Context, claimID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM claim_dispute WHERE claim_id = $1)`
+	if err := r.db.GetContext(ctx, &exists, query, claimID); err != nil {
+		return false, fmt.Errorf("failed to check claim dispute: %w", err)
+	}
+	return exists, nil
+}
+
+// ListByFarmerID lists the disputes of a farmer, most recent first
+func (r *ClaimDisputeRepository) ListByFarmerID(ctx context.Context, farmerID string) ([]models.ClaimDispute, error) {
+	disputes := []models.ClaimDispute{}
+	query := `SELECT ` + claimDisputeColumns + ` FROM claim_dispute WHERE farmer_id = $1 ORDER BY created_at DESC`
+	if err := r.db.SelectContext(ctx, &disputes, query, farmerID); err != nil {
+		return nil, fmt.Errorf("failed to list claim disputes: %w", err)
+	}
+	return disputes, nil
+}
+
+// ListByProviderID lists the disputes of the claims of an insurance provider, most recent first
+func (r *ClaimDisputeRepository) ListByProviderID(ctx context.Context, providerID string) ([]models.ClaimDispute, error) {
+	disputes := []models.ClaimDispute{}
+	query := `SELECT ` + claimDisputeColumns + ` FROM claim_dispute WHERE insurance_provider_id = $1 ORDER BY created_at DESC`
+	if err := r.db.SelectContext(ctx, &disputes, query, providerID); err != nil {
+		return nil, fmt.Errorf("failed to list claim disputes: %w", err)
+	}
+	return disputes, nil
+}
+
+// List lists the disputes matching filter, most recent first. now decides which are overdue.
+func (r *ClaimDisputeRepository) List(ctx context.Context, filter models.ClaimDisputeFilter, now int64) ([]models.ClaimDispute, error) {
+	disputes := []models.ClaimDispute{}
+	var args []any
+	query := `SELECT ` + claimDisputeColumns + ` FROM claim_dispute WHERE 1=1`
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Overdue {
+		args = append(args, now)
+		query += fmt.Sprintf(` AND (
+			(status = 'awaiting_partner' AND partner_response_due_at < $%[1]d)
+			OR (status = 'in_arbitration' AND arbitration_due_at < $%[1]d))`, len(args))
+	}
+	query += " ORDER BY created_at DESC"
+
+	if err := r.db.SelectContext(ctx, &disputes, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list claim disputes: %w", err)
+	}
+	return disputes, nil
+}
+
+// GetOverdueIDs returns the disputes of status whose deadline passed before now: the partner
+// response deadline for disputes awaiting the partner, the arbitration deadline otherwise
+func (r *ClaimDisputeRepository) GetOverdueIDs(ctx context.Context, status models.ClaimDisputeStatus, now int64) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	deadline := "arbitration_due_at"
+	if status == models.DisputeAwaitingPartner {
+		deadline = "partner_response_due_at"
+	}
+	query := `SELECT id FROM claim_dispute WHERE status = $1 AND ` + deadline + ` < $2 ORDER BY ` + deadline
+
+	if err := r.db.SelectContext(ctx, &ids, query, status, now); err != nil {
+		return nil, fmt.Errorf("failed to get overdue claim disputes: %w", err)
+	}
+	return ids, nil
+}
+
+// ============================================================================
+// MESSAGES
+// ============================================================================
+
+func (r *ClaimDisputeRepository) CreateMessageTx(tx *sqlx.Tx, ctx context.Context, message *models.ClaimDisputeMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	message.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO claim_dispute_message (id, dispute_id, author_id, author_role, body, created_at)
+		VALUES (:id, :dispute_id, :author_id, :author_role, :body, :created_at)`
+
+	query, args, err := tx.BindNamed(query, message)
+	if err != nil {
+		return fmt.Errorf("failed to bind claim dispute message: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create claim dispute message: %w", err)
+	}
+	return nil
+}
+
+// GetMessages retrieves the thread of a dispute, oldest first
+func (r *ClaimDisputeRepository) GetMessages(ctx context.Context, disputeID uuid.UUID) ([]models.ClaimDisputeMessage, error) {
+	messages := []models.ClaimDisputeMessage{}
+	query := `
+		SELECT id, dispute_id, author_id, author_role, body, created_at
+		FROM claim_dispute_message
+		WHERE dispute_id = $1
+		ORDER BY created_at ASC`
+
+	if err := r.db.SelectContext(ctx, &messages, query, disputeID); err != nil {
+		return nil, fmt.Errorf("failed to get claim dispute messages: %w", err)
+	}
+	return messages, nil
+}
+
+// ============================================================================
+// EVIDENCE
+// ============================================================================
+
+const claimDisputeEvidenceColumns = `
+	id, dispute_id, uploaded_by, uploader_role, file_name, content_type,
+	object_key, size_bytes, status, created_at`
+
+func (r *ClaimDisputeRepository) CreateEvidence(ctx context.Context, evidence *models.ClaimDisputeEvidence) error {
+	if evidence.ID == uuid.Nil {
+		evidence.ID = uuid.New()
+	}
+	evidence.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO claim_dispute_evidence (` + claimDisputeEvidenceColumns + `
+		) VALUES (
+			:id, :dispute_id, :uploaded_by, :uploader_role, :file_name, :content_type,
+			:object_key, :size_bytes, :status, :created_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, evidence); err != nil {
+		return fmt.Errorf("failed to create claim dispute evidence: %w", err)
+	}
+	return nil
+}
+
+func (r *ClaimDisputeRepository) GetEvidence(ctx context.Context, disputeID, evidenceID uuid.UUID) (*models.ClaimDisputeEvidence, error) {
+	var evidence models.ClaimDisputeEvidence
+	query := `SELECT ` + claimDisputeEvidenceColumns + ` FROM claim_dispute_evidence WHERE id = $1 AND dispute_id = $2`
+
+	if err := r.db.GetContext(ctx, &evidence, query, evidenceID, disputeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("not found: claim dispute evidence %s", evidenceID)
+		}
+		return nil, fmt.Errorf("failed to get claim dispute evidence: %w", err)
+	}
+	return &evidence, nil
+}
+
+func (r *ClaimDisputeRepository) MarkEvidenceUploaded(ctx context.Context, evidenceID uuid.UUID, sizeBytes int64) error {
+	query := `UPDATE claim_dispute_evidence SET status = $2, size_bytes = $3 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, evidenceID, models.DisputeEvidenceUploaded, sizeBytes); err != nil {
+		return fmt.Errorf("failed to mark claim dispute evidence uploaded: %w", err)
+	}
+	return nil
+}
+
+// GetUploadedEvidence lists the evidence of a dispute whose upload was confirmed, oldest first
+func (r *ClaimDisputeRepository) GetUploadedEvidence(ctx context.Context, disputeID uuid.UUID) ([]models.ClaimDisputeEvidence, error) {
+	evidence := []models.ClaimDisputeEvidence{}
+	query := `
+		SELECT ` + claimDisputeEvidenceColumns + `
+		FROM claim_dispute_evidence
+		WHERE dispute_id = $1 AND status = $2
+		ORDER BY created_at ASC`
+
+	if err := r.db.SelectContext(ctx, &evidence, query, disputeID, models.DisputeEvidenceUploaded); err != nil {
+		return nil, fmt.Errorf("failed to get claim dispute evidence: %w", err)
+	}
+	return evidence, nil
+}
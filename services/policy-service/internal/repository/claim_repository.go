@@ -38,7 +38,7 @@ func (r *ClaimRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Cl
 		       calculated_fix_payout, calculated_threshold_payout, claim_amount,
 		       status, auto_generated, partner_review_timestamp, partner_decision,
 		       partner_notes, reviewed_by, auto_approval_deadline, auto_approved,
-		       evidence_summary, created_at, updated_at
+		       evidence_summary, dispute_resolution, created_at, updated_at
 		FROM claim
 		WHERE id = $1
 	`
@@ -59,7 +59,7 @@ func (r *ClaimRepository) GetByPolicyID(ctx context.Context, policyID uuid.UUID)
 		       calculated_fix_payout, calculated_threshold_payout, claim_amount,
 		       status, auto_generated, partner_review_timestamp, partner_decision,
 		       partner_notes, reviewed_by, auto_approval_deadline, auto_approved,
-		       evidence_summary, created_at, updated_at
+		       evidence_summary, dispute_resolution, created_at, updated_at
 		FROM claim
 		WHERE registered_policy_id = $1
 	`
@@ -81,7 +81,7 @@ func (r *ClaimRepository) GetAll(ctx context.Context, filters map[string]any) ([
 		       calculated_fix_payout, calculated_threshold_payout, claim_amount,
 		       status, auto_generated, partner_review_timestamp, partner_decision,
 		       partner_notes, reviewed_by, auto_approval_deadline, auto_approved,
-		       evidence_summary, created_at, updated_at
+		       evidence_summary, dispute_resolution, created_at, updated_at
 		FROM claim
 		WHERE 1=1
 	`
@@ -127,7 +127,7 @@ func (r *ClaimRepository) GetByRegisteredPolicyID(ctx context.Context, policyID
 		       calculated_fix_payout, calculated_threshold_payout, claim_amount,
 		       status, auto_generated, partner_review_timestamp, partner_decision,
 		       partner_notes, reviewed_by, auto_approval_deadline, auto_approved,
-		       evidence_summary, created_at, updated_at
+		       evidence_summary, dispute_resolution, created_at, updated_at
 		FROM claim
 		WHERE registered_policy_id = $1
 		ORDER BY created_at DESC
@@ -150,7 +150,7 @@ func (r *ClaimRepository) GetByFarmID(ctx context.Context, farmID uuid.UUID) ([]
 		       calculated_fix_payout, calculated_threshold_payout, claim_amount,
 		       status, auto_generated, partner_review_timestamp, partner_decision,
 		       partner_notes, reviewed_by, auto_approval_deadline, auto_approved,
-		       evidence_summary, created_at, updated_at
+		       evidence_summary, dispute_resolution, created_at, updated_at
 		FROM claim
 		WHERE farm_id = $1
 		ORDER BY created_at DESC
@@ -272,6 +272,7 @@ func (r *ClaimRepository) Update(claim *models.Claim) error {
 			auto_approval_deadline = :auto_approval_deadline,
 			auto_approved = :auto_approved,
 			evidence_summary = :evidence_summary,
+			dispute_resolution = :dispute_resolution,
 			updated_at = :updated_at
 		WHERE id = :id`
 
@@ -308,6 +309,7 @@ func (r *ClaimRepository) UpdateTx(tx *sqlx.Tx, claim *models.Claim) error {
 			auto_approval_deadline = :auto_approval_deadline,
 			auto_approved = :auto_approved,
 			evidence_summary = :evidence_summary,
+			dispute_resolution = :dispute_resolution,
 			updated_at = :updated_at
 		WHERE id = :id`
 
@@ -318,3 +320,74 @@ func (r *ClaimRepository) UpdateTx(tx *sqlx.Tx, claim *models.Claim) error {
 
 	return nil
 }
+
+// GetByIDForUpdateTx retrieves a claim and locks it until the transaction ends, so concurrent
+// status changes of the claim are applied one after the other
+func (r *ClaimRepository) GetByIDForUpdateTx(tx *sqlx.Tx, ctx context.Context, id uuid.UUID) (*models.Claim, error) {
+	var claim models.Claim
+	query := `
+		SELECT id, claim_number, registered_policy_id, base_policy_id, farm_id,
+		       base_policy_trigger_id, trigger_timestamp, over_threshold_value,
+		       calculated_fix_payout, calculated_threshold_payout, claim_amount,
+		       status, auto_generated, partner_review_timestamp, partner_decision,
+		       partner_notes, reviewed_by, auto_approval_deadline, auto_approved,
+		       evidence_summary, dispute_resolution, created_at, updated_at
+		FROM claim
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	err := tx.GetContext(ctx, &claim, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim by id: %w", err)
+	}
+
+	return &claim, nil
+}
+
+// CreateStatusHistoryTx records a status change of a claim
+func (r *ClaimRepository) CreateStatusHistoryTx(tx *sqlx.Tx, ctx context.Context, history *models.ClaimStatusHistory) error {
+	return createClaimStatusHistory(ctx, tx, history)
+}
+
+func createClaimStatusHistory(ctx context.Context, tx *sqlx.Tx, history *models.ClaimStatusHistory) error {
+	if history.ID == uuid.Nil {
+		history.ID = uuid.New()
+	}
+	history.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO claim_status_history (
+			id, claim_id, from_status, to_status, changed_by, notes, created_at
+		) VALUES (
+			:id, :claim_id, :from_status, :to_status, :changed_by, :notes, :created_at
+		)`
+
+	query, args, err := tx.BindNamed(query, history)
+	if err != nil {
+		return fmt.Errorf("failed to bind claim status history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create claim status history: %w", err)
+	}
+
+	return nil
+}
+
+// GetStatusHistory retrieves the status changes of a claim, oldest first
+func (r *ClaimRepository) GetStatusHistory(ctx context.Context, claimID uuid.UUID) ([]models.ClaimStatusHistory, error) {
+	history := []models.ClaimStatusHistory{}
+	query := `
+		SELECT id, claim_id, from_status, to_status, changed_by, notes, created_at
+		FROM claim_status_history
+		WHERE claim_id = $1
+		ORDER BY created_at ASC
+	`
+
+	err := r.db.SelectContext(ctx, &history, query, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim status history: %w", err)
+	}
+
+	return history, nil
+}
@@ -36,7 +36,8 @@ func (r *DashboardRepository) GetMonthlyLossRatioTrend(partnerID string, startDa
 				-- Payout trả ra trong tháng (join với claim)
 				SUM(
 					CASE 
-						WHEN c.status = 'paid' 
+						WHEN (c.status = 'paid' 
+						 OR (c.status = 'closed' AND EXISTS (SELECT 1 FROM payout p WHERE p.claim_id = c.id AND p.status = 'completed')))
 						 AND DATE_TRUNC('month', c.updated_at) = DATE_TRUNC('month', TO_TIMESTAMP(rp.premium_paid_at))
 						THEN c.claim_amount 
 						ELSE 0 
@@ -366,3 +367,62 @@ func (r *DashboardRepository) GetFinancialSummary(partnerID string, startDate, e
 
 	return &result, nil
 }
+
+// GetPartnerPublicStats calculates the all-time base policy and claim settlement figures of a partner.
+// The settlement rate counts approved and paid claims out of all decided claims.
+func (r *DashboardRepository) GetPartnerPublicStats(partnerID string) (*models.PartnerPublicStats, error) {
+	query := `
+		WITH policies AS (
+			SELECT COUNT(*) AS active_base_policy_count
+			FROM base_policy
+			WHERE insurance_provider_id = $1
+				AND status = 'active'
+		),
+		claims AS (
+			SELECT 
+				COUNT(*) AS total_claims,
+				-- Closed claims were either paid out or rejected
+				COUNT(*) FILTER (WHERE c.status IN ('approved', 'paid') OR (c.status = 'closed' AND EXISTS (SELECT 1 FROM payout p WHERE p.claim_id = c.id))) AS settled_claims,
+				COUNT(*) FILTER (WHERE c.status = 'rejected' OR (c.status = 'closed' AND NOT EXISTS (SELECT 1 FROM payout p WHERE p.claim_id = c.id))) AS rejected_claims,
+				COUNT(*) FILTER (WHERE c.status IN ('generated', 'pending_partner_review')) AS pending_claims
+			FROM claim c
+			JOIN base_policy bp ON c.base_policy_id = bp.id
+			WHERE bp.insurance_provider_id = $1
+		),
+		payouts AS (
+			SELECT 
+				COALESCE(SUM(p.payout_amount), 0) AS total_payout_disbursed,
+				ROUND(AVG(EXTRACT(EPOCH FROM (TO_TIMESTAMP(p.completed_at) - c.created_at)) / 86400)::numeric, 1)::float8 AS avg_settlement_days
+			FROM payout p
+			JOIN claim c ON p.claim_id = c.id
+			JOIN base_policy bp ON c.base_policy_id = bp.id
+			WHERE bp.insurance_provider_id = $1
+				AND p.status = 'completed'
+				AND p.completed_at IS NOT NULL
+		)
+		SELECT 
+			$1 AS partner_id,
+			policies.active_base_policy_count,
+			claims.total_claims,
+			claims.settled_claims,
+			claims.rejected_claims,
+			claims.pending_claims,
+			CASE 
+				WHEN claims.settled_claims + claims.rejected_claims > 0 
+				THEN ROUND((claims.settled_claims::numeric / (claims.settled_claims + claims.rejected_claims)) * 100, 2)::float8
+				ELSE 0
+			END AS settlement_rate_percent,
+			payouts.avg_settlement_days,
+			payouts.total_payout_disbursed
+		FROM policies, claims, payouts
+	`
+
+	var stats models.PartnerPublicStats
+	err := r.db.Get(&stats, query, partnerID)
+	if err != nil {
+		slog.Error("failed to get partner public stats", "partner_id", partnerID, "error", err)
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type DocumentScanRepository struct {
+	db *sqlx.DB
+}
+
+func NewDocumentScanRepository(db *sqlx.DB) *DocumentScanRepository {
+	return &DocumentScanRepository{db: db}
+}
+
+const documentScanColumns = `
+	id, source, reference_id, bucket, object_key, status, findings, scanned_with,
+	quarantine_key, follow_up_job, created_at, scanned_at`
+
+func (r *DocumentScanRepository) Create(ctx context.Context, scan *models.DocumentScan) error {
+	if scan.ID == uuid.Nil {
+		scan.ID = uuid.New()
+	}
+	scan.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO document_scans (` + documentScanColumns + `
+		) VALUES (
+			:id, :source, :reference_id, :bucket, :object_key, :status, :findings, :scanned_with,
+			:quarantine_key, :follow_up_job, :created_at, :scanned_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, scan); err != nil {
+		return fmt.Errorf("failed to create document scan: %w", err)
+	}
+	return nil
+}
+
+func (r *DocumentScanRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DocumentScan, error) {
+	var scan models.DocumentScan
+	query := `SELECT ` + documentScanColumns + ` FROM document_scans WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &scan, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("document scan not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get document scan: %w", err)
+	}
+	return &scan, nil
+}
+
+// GetPending returns the scans still waiting for a result, oldest first
+func (r *DocumentScanRepository) GetPending(ctx context.Context) ([]models.DocumentScan, error) {
+	query := `SELECT ` + documentScanColumns + ` FROM document_scans
+		WHERE status = $1
+		ORDER BY created_at`
+
+	var scans []models.DocumentScan
+	if err := r.db.SelectContext(ctx, &scans, query, models.DocumentScanPending); err != nil {
+		return nil, fmt.Errorf("failed to get pending document scans: %w", err)
+	}
+	return scans, nil
+}
+
+// Complete stores the result of a pending scan. It returns false when another run already did.
+func (r *DocumentScanRepository) Complete(ctx context.Context, scan *models.DocumentScan) (bool, error) {
+	query := `
+		UPDATE document_scans
+		SET status = $2, findings = $3, scanned_with = $4, quarantine_key = $5, scanned_at = $6
+		WHERE id = $1 AND status = $7`
+
+	result, err := r.db.ExecContext(ctx, query, scan.ID, scan.Status, scan.Findings, scan.ScannedWith,
+		scan.QuarantineKey, scan.ScannedAt, models.DocumentScanPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete document scan: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to complete document scan: %w", err)
+	}
+	return rows == 1, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type DraftExpirationEventRepository struct {
+	db *sqlx.DB
+}
+
+func NewDraftExpirationEventRepository(db *sqlx.DB) *DraftExpirationEventRepository {
+	return &DraftExpirationEventRepository{db: db}
+}
+
+func (r *DraftExpirationEventRepository) BeginTransaction() (*sqlx.Tx, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+const draftExpirationEventColumns = `
+	id, draft_id, source, status, outcome, attempts, last_error,
+	next_attempt_at, detected_at, processed_at, created_at, updated_at`
+
+// Record records the end of the validation window of a draft, due for processing right away.
+// The window of a draft ends once: recording it again is ignored.
+func (r *DraftExpirationEventRepository) Record(ctx context.Context, draftID uuid.UUID, source models.DraftExpirySource) error {
+	now := time.Now().Unix()
+	query := `
+		INSERT INTO draft_expiration_event (id, draft_id, source, status, attempts, next_attempt_at, detected_at)
+		VALUES ($1, $2, $3, 'pending', 0, $4, $4)
+		ON CONFLICT (draft_id) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, uuid.New(), draftID, source, now); err != nil {
+		return fmt.Errorf("failed to record draft expiration: %w", err)
+	}
+	return nil
+}
+
+// RecordMissed records the end of the validation window of the pending drafts past their
+// expires_at that have no event, the expiries whose notification was lost. It returns how many
+// were recorded.
+func (r *DraftExpirationEventRepository) RecordMissed(ctx context.Context, now int64) (int64, error) {
+	query := `
+		INSERT INTO draft_expiration_event (id, draft_id, source, status, attempts, next_attempt_at, detected_at)
+		SELECT uuid_generate_v4(), d.id, 'sweeper', 'pending', 0, $1, $1
+		FROM base_policy_draft d
+		WHERE d.status = 'pending'
+			AND d.expires_at <= $1
+			AND NOT EXISTS (SELECT 1 FROM draft_expiration_event e WHERE e.draft_id = d.id)
+		ON CONFLICT (draft_id) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record missed draft expirations: %w", err)
+	}
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record missed draft expirations: %w", err)
+	}
+	return recorded, nil
+}
+
+// GetDueDraftIDs returns the drafts of up to limit pending events due at now, the longest due first
+func (r *DraftExpirationEventRepository) GetDueDraftIDs(ctx context.Context, now int64, limit int) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `
+		SELECT draft_id FROM draft_expiration_event
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2`
+
+	if err := r.db.SelectContext(ctx, &ids, query, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to get due draft expirations: %w", err)
+	}
+	return ids, nil
+}
+
+// GetDueForUpdateTx claims the pending event of a draft if it is due at now, locking it until the
+// transaction ends. It returns nil when the event is not due, processed, or claimed by another
+// transaction.
+func (r *DraftExpirationEventRepository) GetDueForUpdateTx(tx *sqlx.Tx, ctx context.Context, draftID uuid.UUID, now int64) (*models.DraftExpirationEvent, error) {
+	var event models.DraftExpirationEvent
+	query := `
+		SELECT ` + draftExpirationEventColumns + ` FROM draft_expiration_event
+		WHERE draft_id = $1 AND status = 'pending' AND next_attempt_at <= $2
+		FOR UPDATE SKIP LOCKED`
+
+	if err := tx.GetContext(ctx, &event, query, draftID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get draft expiration: %w", err)
+	}
+	return &event, nil
+}
+
+// UpdateTx saves the outcome of an attempt to process an event
+func (r *DraftExpirationEventRepository) UpdateTx(tx *sqlx.Tx, ctx context.Context, event *models.DraftExpirationEvent) error {
+	event.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE draft_expiration_event SET
+			status = :status,
+			outcome = :outcome,
+			attempts = :attempts,
+			last_error = :last_error,
+			next_attempt_at = :next_attempt_at,
+			processed_at = :processed_at,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	query, args, err := tx.BindNamed(query, event)
+	if err != nil {
+		return fmt.Errorf("failed to bind draft expiration: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update draft expiration: %w", err)
+	}
+	return nil
+}
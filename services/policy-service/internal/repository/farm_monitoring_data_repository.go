@@ -6,14 +6,23 @@ import (
 	"fmt"
 	"log/slog"
 	"policy-service/internal/models"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// FarmMonitoringDataRepository reads and writes farm_monitoring_data, which is partitioned by month
+// of measurement_timestamp. Readings are written to the partition of their month, created on the
+// first write; reads bounded by measurement_timestamp only touch the partitions of their range.
 type FarmMonitoringDataRepository struct {
 	db *sqlx.DB
+
+	// Partitions known to exist or to have been archived, by name, to skip their checks on writes
+	partitions sync.Map
 }
 
 func NewFarmMonitoringDataRepository(db *sqlx.DB) *FarmMonitoringDataRepository {
@@ -31,6 +40,14 @@ func (r *FarmMonitoringDataRepository) Create(ctx context.Context, data *models.
 	}
 	data.CreatedAt = time.Now()
 
+	kept, err := r.routeToPartitions(ctx, []models.FarmMonitoringData{*data})
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		return fmt.Errorf("invalid: the month of measurement timestamp %d has been archived", data.MeasurementTimestamp)
+	}
+
 	slog.Info("Creating farm monitoring data",
 		"id", data.ID,
 		"farm_id", data.FarmID,
@@ -52,7 +69,7 @@ func (r *FarmMonitoringDataRepository) Create(ctx context.Context, data *models.
 			:created_at
 		)`
 
-	_, err := r.db.NamedExecContext(ctx, query, data)
+	_, err = r.db.NamedExecContext(ctx, query, data)
 	if err != nil {
 		slog.Error("Failed to create farm monitoring data",
 			"id", data.ID,
@@ -73,6 +90,14 @@ func (r *FarmMonitoringDataRepository) CreateBatch(ctx context.Context, dataList
 
 	slog.Info("Creating farm monitoring data batch", "count", len(dataList))
 
+	dataList, err := r.routeToPartitions(ctx, dataList)
+	if err != nil {
+		return err
+	}
+	if len(dataList) == 0 {
+		return nil
+	}
+
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		slog.Error("Failed to begin transaction", "error", err)
@@ -746,3 +771,202 @@ func (r *FarmMonitoringDataRepository) GetByFarmIDAndParameterNameWithPolicyStat
 		"count", len(dataList))
 	return dataList, nil
 }
+
+// ============================================================================
+// PARTITION OPERATIONS
+// ============================================================================
+
+const monitoringPartitionPrefix = "farm_monitoring_data_p"
+
+// MonitoringPartitionName is the name of the partition holding the readings of the month of t
+func MonitoringPartitionName(t time.Time) string {
+	return monitoringPartitionPrefix + t.UTC().Format("200601")
+}
+
+// MonitoringMonthStart is the start of the month of t in UTC, the lower bound of its partition
+func MonitoringMonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ParseMonitoringPartitionName reads the month of a partition from its name
+func ParseMonitoringPartitionName(name string) (time.Time, bool) {
+	month, ok := strings.CutPrefix(name, monitoringPartitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	monthStart, err := time.Parse("200601", month)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return monthStart, true
+}
+
+// EnsurePartition creates the partition of the month of monthStart if it does not exist yet
+func (r *FarmMonitoringDataRepository) EnsurePartition(ctx context.Context, monthStart time.Time) (string, error) {
+	var name string
+	query := `SELECT ensure_farm_monitoring_partition($1)`
+	if err := r.db.GetContext(ctx, &name, query, MonitoringMonthStart(monthStart)); err != nil {
+		slog.Error("Failed to ensure farm monitoring partition", "month", monthStart.Format("2006-01"), "error", err)
+		return "", fmt.Errorf("failed to ensure farm monitoring partition: %w", err)
+	}
+	r.partitions.Store(name, false)
+	return name, nil
+}
+
+// routeToPartitions makes sure the partition of the month of every reading exists. Readings of
+// months already archived to MinIO are left out: their partitions are gone and writing them would
+// bring a month back that the archive no longer covers.
+func (r *FarmMonitoringDataRepository) routeToPartitions(ctx context.Context, dataList []models.FarmMonitoringData) ([]models.FarmMonitoringData, error) {
+	archivedMonths := make(map[string]bool)
+	for _, data := range dataList {
+		monthStart := MonitoringMonthStart(time.Unix(data.MeasurementTimestamp, 0))
+		name := MonitoringPartitionName(monthStart)
+		if _, seen := archivedMonths[name]; seen {
+			continue
+		}
+
+		if archived, known := r.partitions.Load(name); known {
+			archivedMonths[name] = archived.(bool)
+			continue
+		}
+
+		var archived bool
+		query := `SELECT EXISTS(SELECT 1 FROM farm_monitoring_archive WHERE partition_name = $1)`
+		if err := r.db.GetContext(ctx, &archived, query, name); err != nil {
+			return nil, fmt.Errorf("failed to check farm monitoring archive: %w", err)
+		}
+		if archived {
+			r.partitions.Store(name, true)
+		} else if _, err := r.EnsurePartition(ctx, monthStart); err != nil {
+			return nil, err
+		}
+		archivedMonths[name] = archived
+	}
+
+	kept := dataList[:0:0]
+	for _, data := range dataList {
+		if archivedMonths[MonitoringPartitionName(time.Unix(data.MeasurementTimestamp, 0))] {
+			continue
+		}
+		kept = append(kept, data)
+	}
+	if len(kept) == len(dataList) {
+		return dataList, nil
+	}
+
+	slog.Warn("Skipping farm monitoring data of archived months",
+		"skipped", len(dataList)-len(kept),
+		"kept", len(kept))
+	return kept, nil
+}
+
+// ListPartitions lists the partitions of farm_monitoring_data, oldest month first
+func (r *FarmMonitoringDataRepository) ListPartitions(ctx context.Context) ([]models.FarmMonitoringPartition, error) {
+	var names []string
+	query := `
+		SELECT child.relname
+		FROM pg_inherits i
+		JOIN pg_class child ON child.oid = i.inhrelid
+		JOIN pg_class parent ON parent.oid = i.inhparent
+		WHERE parent.relname = 'farm_monitoring_data'
+		ORDER BY child.relname`
+
+	if err := r.db.SelectContext(ctx, &names, query); err != nil {
+		slog.Error("Failed to list farm monitoring partitions", "error", err)
+		return nil, fmt.Errorf("failed to list farm monitoring partitions: %w", err)
+	}
+
+	partitions := make([]models.FarmMonitoringPartition, 0, len(names))
+	for _, name := range names {
+		monthStart, ok := ParseMonitoringPartitionName(name)
+		if !ok {
+			slog.Warn("Ignoring farm monitoring partition with unexpected name", "partition", name)
+			continue
+		}
+		partitions = append(partitions, models.FarmMonitoringPartition{Name: name, MonthStart: monthStart})
+	}
+	return partitions, nil
+}
+
+// ForEachInPartition calls fn with every reading of a partition, in measurement order, and returns
+// how many there were
+func (r *FarmMonitoringDataRepository) ForEachInPartition(ctx context.Context, partitionName string, fn func(data *models.FarmMonitoringData) error) (int64, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			id, farm_id, data_source_id,
+			parameter_name, measured_value, unit, measurement_timestamp,
+			component_data, data_quality, confidence_score,
+			measurement_source, distance_from_farm_meters, cloud_cover_percentage,
+			created_at
+		FROM %s
+		ORDER BY measurement_timestamp, id`, pq.QuoteIdentifier(partitionName))
+
+	rows, err := r.db.QueryxContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read farm monitoring partition %s: %w", partitionName, err)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var data models.FarmMonitoringData
+		if err := rows.StructScan(&data); err != nil {
+			return count, fmt.Errorf("failed to scan farm monitoring data: %w", err)
+		}
+		if err := fn(&data); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to read farm monitoring partition %s: %w", partitionName, err)
+	}
+	return count, nil
+}
+
+// DropArchivedPartition records the archive of a partition and drops the partition, so its month
+// is either archived and gone or still in place
+func (r *FarmMonitoringDataRepository) DropArchivedPartition(ctx context.Context, archive *models.FarmMonitoringArchive) error {
+	if archive.ID == uuid.Nil {
+		archive.ID = uuid.New()
+	}
+	archive.ArchivedAt = time.Now()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO farm_monitoring_archive (
+			id, partition_name, range_start, range_end,
+			bucket, object_key, format, row_count, size_bytes, archived_at
+		) VALUES (
+			:id, :partition_name, :range_start, :range_end,
+			:bucket, :object_key, :format, :row_count, :size_bytes, :archived_at
+		)`
+	if _, err := tx.NamedExecContext(ctx, query, archive); err != nil {
+		return fmt.Errorf("failed to record farm monitoring archive: %w", err)
+	}
+
+	partition := pq.QuoteIdentifier(archive.PartitionName)
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE farm_monitoring_data DETACH PARTITION `+partition); err != nil {
+		return fmt.Errorf("failed to detach farm monitoring partition %s: %w", archive.PartitionName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DROP TABLE `+partition); err != nil {
+		return fmt.Errorf("failed to drop farm monitoring partition %s: %w", archive.PartitionName, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit farm monitoring archive: %w", err)
+	}
+	r.partitions.Store(archive.PartitionName, true)
+
+	slog.Info("Dropped archived farm monitoring partition",
+		"partition", archive.PartitionName,
+		"object_key", archive.ObjectKey,
+		"row_count", archive.RowCount)
+	return nil
+}
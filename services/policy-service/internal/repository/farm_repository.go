@@ -819,6 +819,160 @@ func (r *FarmRepository) DeleteFarmPhotosByFarmIDTx(tx *sqlx.Tx, farmID uuid.UUI
 	return nil
 }
 
+// AnalyzeBoundary checks a boundary with PostGIS and measures its geodesic area and centroid
+func (r *FarmRepository) AnalyzeBoundary(ctx context.Context, boundary *models.GeoJSONPolygon) (*models.BoundaryAnalysis, error) {
+	query := `
+		WITH candidate AS (SELECT ST_SetSRID(ST_GeomFromText($1), 4326) AS geom)
+		SELECT
+			ST_IsValid(geom) AS is_valid,
+			ST_IsValidReason(geom) AS invalid_reason,
+			ST_Area(geom::geography) AS area_sqm,
+			ST_X(ST_Centroid(geom)) AS centroid_lng,
+			ST_Y(ST_Centroid(geom)) AS centroid_lat
+		FROM candidate
+	`
+
+	var analysis models.BoundaryAnalysis
+	if err := r.db.GetContext(ctx, &analysis, query, boundary); err != nil {
+		return nil, fmt.Errorf("failed to analyze farm boundary: %w", err)
+	}
+	return &analysis, nil
+}
+
+// GetBoundaryOverlaps lists the farms of other owners a valid boundary overlaps, largest overlap
+// first. Archived farms and farmID itself are left out.
+func (r *FarmRepository) GetBoundaryOverlaps(ctx context.Context, boundary *models.GeoJSONPolygon, ownerID string, farmID uuid.UUID) ([]models.BoundaryOverlap, error) {
+	query := `
+		WITH candidate AS (SELECT ST_SetSRID(ST_GeomFromText($1), 4326) AS geom)
+		SELECT
+			f.id AS farm_id,
+			f.farm_code,
+			ST_Area(ST_Intersection(f.boundary, c.geom)::geography) AS overlap_sqm
+		FROM farm f, candidate c
+		WHERE f.owner_id <> $2 AND f.id <> $3 AND f.status <> 'archived'
+			AND f.boundary && c.geom AND ST_IsValid(f.boundary) AND ST_Intersects(f.boundary, c.geom)
+		ORDER BY overlap_sqm DESC
+	`
+
+	var overlaps []models.BoundaryOverlap
+	if err := r.db.SelectContext(ctx, &overlaps, query, boundary, ownerID, farmID); err != nil {
+		return nil, fmt.Errorf("failed to get farm boundary overlaps: %w", err)
+	}
+	return overlaps, nil
+}
+
+// GetInsuredFarmLocations lists active farms that have a center location and an active policy
+func (r *FarmRepository) GetInsuredFarmLocations(ctx context.Context) ([]models.InsuredFarmLocation, error) {
+	query := `
+		SELECT
+			f.id AS farm_id,
+			f.agro_polygon_id,
+			f.province,
+			ST_Y(f.center_location::geometry) AS latitude,
+			ST_X(f.center_location::geometry) AS longitude,
+			COUNT(rp.id) AS active_policy_count
+		FROM farm f
+		JOIN registered_policy rp ON rp.farm_id = f.id AND rp.status = 'active'
+		WHERE f.status = 'active' AND f.center_location IS NOT NULL
+		GROUP BY f.id
+		ORDER BY f.id
+	`
+
+	var locations []models.InsuredFarmLocation
+	if err := r.db.SelectContext(ctx, &locations, query); err != nil {
+		return nil, fmt.Errorf("failed to get insured farm locations: %w", err)
+	}
+	return locations, nil
+}
+
+// GetInsuringProviderIDs lists the insurance providers with a policy on the farm that is under
+// review or in force
+func (r *FarmRepository) GetInsuringProviderIDs(ctx context.Context, farmID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT insurance_provider_id FROM registered_policy
+		WHERE farm_id = $1 AND status IN ('pending_review', 'pending_payment', 'active', 'payout', 'dispute', 'pending_cancel')
+	`
+
+	var providerIDs []string
+	if err := r.db.SelectContext(ctx, &providerIDs, query, farmID); err != nil {
+		return nil, fmt.Errorf("failed to get insuring providers of farm: %w", err)
+	}
+	return providerIDs, nil
+}
+
+// ============================================================================
+// CROP VERIFICATION
+// ============================================================================
+
+// UpdateCropVerificationTx stores the crop of a farm and its verification state
+func (r *FarmRepository) UpdateCropVerificationTx(tx *sqlx.Tx, farm *models.Farm) error {
+	farm.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE farm SET
+			crop_type = :crop_type, crop_type_verified = :crop_type_verified,
+			crop_type_verified_at = :crop_type_verified_at, crop_type_verified_by = :crop_type_verified_by,
+			crop_type_confidence = :crop_type_confidence, updated_at = :updated_at
+		WHERE id = :id`
+
+	if _, err := tx.NamedExec(query, farm); err != nil {
+		return fmt.Errorf("failed to update crop verification of farm: %w", err)
+	}
+	return nil
+}
+
+// CreateCropVerificationTx records a classification of the crop of a farm
+func (r *FarmRepository) CreateCropVerificationTx(tx *sqlx.Tx, verification *models.CropVerification) error {
+	if verification.ID == uuid.Nil {
+		verification.ID = uuid.New()
+	}
+	verification.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO crop_verification (
+			id, farm_id, source, decision, declared_crop_type, detected_crop_type, confidence,
+			photo_count, latest_photo_at, reasoning, verified_by, created_at
+		) VALUES (
+			:id, :farm_id, :source, :decision, :declared_crop_type, :detected_crop_type, :confidence,
+			:photo_count, :latest_photo_at, :reasoning, :verified_by, :created_at
+		)`
+
+	if _, err := tx.NamedExec(query, verification); err != nil {
+		return fmt.Errorf("failed to create crop verification: %w", err)
+	}
+	return nil
+}
+
+// GetCropVerificationsByFarmID lists the crop classifications of a farm, most recent first
+func (r *FarmRepository) GetCropVerificationsByFarmID(ctx context.Context, farmID uuid.UUID) ([]models.CropVerification, error) {
+	query := `SELECT * FROM crop_verification WHERE farm_id = $1 ORDER BY created_at DESC`
+
+	var verifications []models.CropVerification
+	if err := r.db.SelectContext(ctx, &verifications, query, farmID); err != nil {
+		return nil, fmt.Errorf("failed to get crop verifications: %w", err)
+	}
+	return verifications, nil
+}
+
+// GetLatestCropVerification returns the most recent classification of a farm from source, or nil
+// when there is none
+func (r *FarmRepository) GetLatestCropVerification(ctx context.Context, farmID uuid.UUID, source models.CropVerificationSource) (*models.CropVerification, error) {
+	query := `
+		SELECT * FROM crop_verification
+		WHERE farm_id = $1 AND source = $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	var verification models.CropVerification
+	if err := r.db.GetContext(ctx, &verification, query, farmID, source); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest crop verification: %w", err)
+	}
+	return &verification, nil
+}
+
 // count active farms by owner_id
 func (r *FarmRepository) CountActiveFarmsByOwnerID(ownerID string) (int, error) {
 	var count int
@@ -840,3 +994,62 @@ func (r *FarmRepository) CountInactiveFarmsByOwnerID(ownerID string) (int, error
 	}
 	return count, nil
 }
+
+// ============================================================================
+// LAND CERTIFICATE VERIFICATION
+// ============================================================================
+
+// UpdateLandOwnershipTx stores the land ownership verification of a farm and the certificate
+// number it was verified with
+func (r *FarmRepository) UpdateLandOwnershipTx(tx *sqlx.Tx, farm *models.Farm) error {
+	farm.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE farm SET
+			land_certificate_number = :land_certificate_number,
+			land_ownership_verified = :land_ownership_verified,
+			land_ownership_verified_at = :land_ownership_verified_at,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	if _, err := tx.NamedExec(query, farm); err != nil {
+		return fmt.Errorf("failed to update land ownership of farm: %w", err)
+	}
+	return nil
+}
+
+// CreateLandCertificateVerificationTx records the evidence of a land certificate OCR
+func (r *FarmRepository) CreateLandCertificateVerificationTx(tx *sqlx.Tx, verification *models.LandCertificateVerification) error {
+	if verification.ID == uuid.Nil {
+		verification.ID = uuid.New()
+	}
+	verification.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO land_certificate_verification (
+			id, farm_id, status, certificate_number, owner_names, parcel_address, confidence,
+			identity_name, declared_certificate_number, owner_matched, certificate_number_matched,
+			document_objects, reasoning, created_at
+		) VALUES (
+			:id, :farm_id, :status, :certificate_number, :owner_names, :parcel_address, :confidence,
+			:identity_name, :declared_certificate_number, :owner_matched, :certificate_number_matched,
+			:document_objects, :reasoning, :created_at
+		)`
+
+	if _, err := tx.NamedExec(query, verification); err != nil {
+		return fmt.Errorf("failed to create land certificate verification: %w", err)
+	}
+	return nil
+}
+
+// GetLandCertificateVerificationsByFarmID lists the land certificate OCRs of a farm, most recent
+// first
+func (r *FarmRepository) GetLandCertificateVerificationsByFarmID(ctx context.Context, farmID uuid.UUID) ([]models.LandCertificateVerification, error) {
+	query := `SELECT * FROM land_certificate_verification WHERE farm_id = $1 ORDER BY created_at DESC`
+
+	var verifications []models.LandCertificateVerification
+	if err := r.db.SelectContext(ctx, &verifications, query, farmID); err != nil {
+		return nil, fmt.Errorf("failed to get land certificate verifications: %w", err)
+	}
+	return verifications, nil
+}
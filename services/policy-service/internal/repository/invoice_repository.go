@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type InvoiceRepository struct {
+	db *sqlx.DB
+}
+
+func NewInvoiceRepository(db *sqlx.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+func (r *InvoiceRepository) BeginTransaction() (*sqlx.Tx, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+const partnerInvoiceColumns = `
+	id, insurance_provider_id, invoice_month, invoice_number, active_policies_count,
+	total_data_complexity_fee, data_cost_markup, markup_percent, subtotal, tax_percent, tax,
+	total_due, currency, contract_id, contract_version, payment_status, due_date, paid_date,
+	payment_reference, marked_paid_by, cancellation_reason, pdf_object_key, created_at, updated_at`
+
+// ============================================================================
+// INVOICES
+// ============================================================================
+
+// CreateTx inserts an invoice with its line items
+func (r *InvoiceRepository) CreateTx(tx *sqlx.Tx, ctx context.Context, invoice *models.PartnerInvoice) error {
+	if invoice.ID == uuid.Nil {
+		invoice.ID = uuid.New()
+	}
+	invoice.CreatedAt = time.Now()
+	invoice.UpdatedAt = invoice.CreatedAt
+
+	query := `
+		INSERT INTO partner_invoice (` + partnerInvoiceColumns + `
+		) VALUES (
+			:id, :insurance_provider_id, :invoice_month, :invoice_number, :active_policies_count,
+			:total_data_complexity_fee, :data_cost_markup, :markup_percent, :subtotal, :tax_percent, :tax,
+			:total_due, :currency, :contract_id, :contract_version, :payment_status, :due_date, :paid_date,
+			:payment_reference, :marked_paid_by, :cancellation_reason, :pdf_object_key, :created_at, :updated_at
+		)`
+
+	query, args, err := tx.BindNamed(query, invoice)
+	if err != nil {
+		return fmt.Errorf("failed to bind partner invoice: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create partner invoice: %w", err)
+	}
+
+	for i := range invoice.LineItems {
+		item := &invoice.LineItems[i]
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+		item.InvoiceID = invoice.ID
+		item.CreatedAt = invoice.CreatedAt
+
+		query := `
+			INSERT INTO invoice_line_item (
+				id, invoice_id, item_type, base_policy_id, registered_policy_id,
+				description, quantity, unit_cost, total_cost, created_at
+			) VALUES (
+				:id, :invoice_id, :item_type, :base_policy_id, :registered_policy_id,
+				:description, :quantity, :unit_cost, :total_cost, :created_at
+			)`
+
+		query, args, err := tx.BindNamed(query, item)
+		if err != nil {
+			return fmt.Errorf("failed to bind invoice line item: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to create invoice line item: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateStatusTx saves the payment status of an invoice, with its payment or cancellation
+func (r *InvoiceRepository) UpdateStatusTx(tx *sqlx.Tx, ctx context.Context, invoice *models.PartnerInvoice) error {
+	invoice.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE partner_invoice SET
+			payment_status = :payment_status,
+			paid_date = :paid_date,
+			payment_reference = :payment_reference,
+			marked_paid_by = :marked_paid_by,
+			cancellation_reason = :cancellation_reason,
+			updated_at = :updated_at
+		WHERE id = :id`
+
+	query, args, err := tx.BindNamed(query, invoice)
+	if err != nil {
+		return fmt.Errorf("failed to bind partner invoice: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update partner invoice: %w", err)
+	}
+	return nil
+}
+
+// SetPDFObjectKey records where the PDF of an invoice is stored
+func (r *InvoiceRepository) SetPDFObjectKey(ctx context.Context, id uuid.UUID, objectKey string) error {
+	query := `UPDATE partner_invoice SET pdf_object_key = $2, updated_at = NOW() WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, objectKey); err != nil {
+		return fmt.Errorf("failed to set invoice PDF: %w", err)
+	}
+	return nil
+}
+
+func (r *InvoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PartnerInvoice, error) {
+	var invoice models.PartnerInvoice
+	query := `SELECT ` + partnerInvoiceColumns + ` FROM partner_invoice WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &invoice, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("not found: partner invoice %s", id)
+		}
+		return nil, fmt.Errorf("failed to get partner invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+// GetByIDForUpdateTx retrieves an invoice and locks it until the transaction ends
+func (r *InvoiceRepository) GetByIDForUpdateTx(tx *sqlx.Tx, ctx context.Context, id uuid.UUID) (*models.PartnerInvoice, error) {
+	var invoice models.PartnerInvoice
+	query := `SELECT ` + partnerInvoiceColumns + ` FROM partner_invoice WHERE id = $1 FOR UPDATE`
+
+	if err := tx.GetContext(ctx, &invoice, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("not found: partner invoice %s", id)
+		}
+		return nil, fmt.Errorf("failed to get partner invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+// GetLineItems retrieves the line items of an invoice in the order they were billed
+func (r *InvoiceRepository) GetLineItems(ctx context.Context, invoiceID uuid.UUID) ([]models.InvoiceLineItem, error) {
+	items := []models.InvoiceLineItem{}
+	query := `
+		SELECT id, invoice_id, item_type, base_policy_id, registered_policy_id,
+			description, quantity, unit_cost, total_cost, created_at
+		FROM invoice_line_item
+		WHERE invoice_id = $1
+		ORDER BY item_type = 'data_cost_markup', total_cost DESC, id`
+
+	if err := r.db.SelectContext(ctx, &items, query, invoiceID); err != nil {
+		return nil, fmt.Errorf("failed to get invoice line items: %w", err)
+	}
+	return items, nil
+}
+
+// GetIssued returns the invoice of a partner for a month that was not cancelled, nil if there is none
+func (r *InvoiceRepository) GetIssued(ctx context.Context, providerID string, invoiceMonth int64) (*models.PartnerInvoice, error) {
+	var invoice models.PartnerInvoice
+	query := `
+		SELECT ` + partnerInvoiceColumns + ` FROM partner_invoice
+		WHERE insurance_provider_id = $1 AND invoice_month = $2 AND payment_status <> 'cancelled'`
+
+	if err := r.db.GetContext(ctx, &invoice, query, providerID, invoiceMonth); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get partner invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+// List lists the invoices matching filter, most recent month first
+func (r *InvoiceRepository) List(ctx context.Context, filter models.InvoiceFilter) ([]models.PartnerInvoice, error) {
+	invoices := []models.PartnerInvoice{}
+	var args []any
+	query := `SELECT ` + partnerInvoiceColumns + ` FROM partner_invoice WHERE 1=1`
+
+	if filter.InsuranceProviderID != "" {
+		args = append(args, filter.InsuranceProviderID)
+		query += fmt.Sprintf(" AND insurance_provider_id = $%d", len(args))
+	}
+	if filter.PaymentStatus != "" {
+		args = append(args, filter.PaymentStatus)
+		query += fmt.Sprintf(" AND payment_status = $%d", len(args))
+	}
+	if filter.InvoiceMonth != 0 {
+		args = append(args, filter.InvoiceMonth)
+		query += fmt.Sprintf(" AND invoice_month = $%d", len(args))
+	}
+	query += " ORDER BY invoice_month DESC, created_at DESC"
+
+	if err := r.db.SelectContext(ctx, &invoices, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list partner invoices: %w", err)
+	}
+	return invoices, nil
+}
+
+// GetOverdueIDs returns the pending invoices whose due date passed before now
+func (r *InvoiceRepository) GetOverdueIDs(ctx context.Context, now int64) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `SELECT id FROM partner_invoice WHERE payment_status = 'pending' AND due_date < $1 ORDER BY due_date`
+
+	if err := r.db.SelectContext(ctx, &ids, query, now); err != nil {
+		return nil, fmt.Errorf("failed to get overdue partner invoices: %w", err)
+	}
+	return ids, nil
+}
+
+// GetUninvoicedProviders returns the partners with billable policies whose coverage starts in
+// [periodStart, periodEnd) and no invoice issued for invoiceMonth. Billable policies are the
+// active, approved ones GetMonthlyDataCostByProvider counts.
+func (r *InvoiceRepository) GetUninvoicedProviders(ctx context.Context, invoiceMonth int64, periodStart, periodEnd int64) ([]string, error) {
+	providers := []string{}
+	query := `
+		SELECT DISTINCT rp.insurance_provider_id
+		FROM registered_policy rp
+		WHERE rp.status = 'active'
+			AND rp.underwriting_status = 'approved'
+			AND rp.coverage_start_date >= $2
+			AND rp.coverage_start_date < $3
+			AND NOT EXISTS (
+				SELECT 1 FROM partner_invoice pi
+				WHERE pi.insurance_provider_id = rp.insurance_provider_id
+					AND pi.invoice_month = $1
+					AND pi.payment_status <> 'cancelled'
+			)
+		ORDER BY rp.insurance_provider_id`
+
+	if err := r.db.SelectContext(ctx, &providers, query, invoiceMonth, periodStart, periodEnd); err != nil {
+		return nil, fmt.Errorf("failed to get uninvoiced providers: %w", err)
+	}
+	return providers, nil
+}
+
+// ============================================================================
+// REVENUE
+// ============================================================================
+
+// GetRevenueByMonth sums the invoices of the months between fromMonth and toMonth (YYYYMM, both
+// included) per month and currency, cancelled invoices left out
+func (r *InvoiceRepository) GetRevenueByMonth(ctx context.Context, fromMonth, toMonth int64) ([]models.RevenueMonth, error) {
+	revenue := []models.RevenueMonth{}
+	query := `
+		SELECT
+			invoice_month,
+			currency,
+			COUNT(*) AS invoice_count,
+			COALESCE(SUM(total_data_complexity_fee), 0)::DOUBLE PRECISION AS data_cost,
+			COALESCE(SUM(data_cost_markup), 0)::DOUBLE PRECISION AS markup,
+			COALESCE(SUM(tax), 0)::DOUBLE PRECISION AS tax,
+			COALESCE(SUM(total_due), 0)::DOUBLE PRECISION AS invoiced,
+			COALESCE(SUM(total_due) FILTER (WHERE payment_status = 'paid'), 0)::DOUBLE PRECISION AS collected,
+			COALESCE(SUM(total_due) FILTER (WHERE payment_status IN ('pending', 'overdue')), 0)::DOUBLE PRECISION AS outstanding,
+			COALESCE(SUM(total_due) FILTER (WHERE payment_status = 'overdue'), 0)::DOUBLE PRECISION AS overdue
+		FROM partner_invoice
+		WHERE payment_status <> 'cancelled'
+			AND invoice_month >= $1 AND invoice_month <= $2
+		GROUP BY invoice_month, currency
+		ORDER BY invoice_month, currency`
+
+	if err := r.db.SelectContext(ctx, &revenue, query, fromMonth, toMonth); err != nil {
+		return nil, fmt.Errorf("failed to get revenue by month: %w", err)
+	}
+	return revenue, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) BeginTransaction() (*sqlx.Tx, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// CreateTx stores an event in the transaction of the change it belongs to, so it is published
+// only if that change commits
+func (r *OutboxRepository) CreateTx(tx *sqlx.Tx, ctx context.Context, event *models.OutboxEvent) error {
+	return createOutboxEvent(ctx, tx, event)
+}
+
+func createOutboxEvent(ctx context.Context, tx *sqlx.Tx, event *models.OutboxEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	now := time.Now()
+	event.Status = models.OutboxPending
+	event.AvailableAt = now
+	event.CreatedAt = now
+
+	query := `
+		INSERT INTO notification_outbox (
+			id, event_type, aggregate_id, queue, payload, headers,
+			status, attempts, available_at, created_at
+		) VALUES (
+			:id, :event_type, :aggregate_id, :queue, :payload, :headers,
+			:status, :attempts, :available_at, :created_at
+		)`
+
+	query, args, err := tx.BindNamed(query, event)
+	if err != nil {
+		return fmt.Errorf("failed to bind outbox event: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingForUpdateTx locks up to limit pending events that are due, oldest first. Events
+// locked by another dispatcher are skipped rather than waited for.
+func (r *OutboxRepository) GetPendingForUpdateTx(tx *sqlx.Tx, ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	query := `
+		SELECT id, event_type, aggregate_id, queue, payload, headers,
+		       status, attempts, last_error, available_at, created_at, published_at
+		FROM notification_outbox
+		WHERE status = $1 AND available_at <= NOW()
+		ORDER BY created_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+
+	if err := tx.SelectContext(ctx, &events, query, models.OutboxPending, limit); err != nil {
+		return nil, fmt.Errorf("failed to get pending outbox events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublishedTx(tx *sqlx.Tx, ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = $1, attempts = attempts + 1, last_error = NULL, published_at = NOW()
+		WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, models.OutboxPublished, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailedTx records a failed publish. The event is retried from availableAt, or given
+// up on with status failed when giveUp is set.
+func (r *OutboxRepository) MarkAttemptFailedTx(tx *sqlx.Tx, ctx context.Context, id uuid.UUID, publishErr string, availableAt time.Time, giveUp bool) error {
+	status := models.OutboxPending
+	if giveUp {
+		status = models.OutboxFailed
+	}
+
+	query := `
+		UPDATE notification_outbox
+		SET status = $1, attempts = attempts + 1, last_error = $2, available_at = $3
+		WHERE id = $4`
+
+	if _, err := tx.ExecContext(ctx, query, status, publishErr, availableAt, id); err != nil {
+		return fmt.Errorf("failed to record outbox publish failure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"policy-service/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PolicyAttachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPolicyAttachmentRepository(db *sqlx.DB) *PolicyAttachmentRepository {
+	return &PolicyAttachmentRepository{db: db}
+}
+
+const policyAttachmentColumns = `
+	id, base_policy_id, registered_policy_id, document_type, version, title,
+	file_name, content_type, object_key, size_bytes, status, uploaded_by, created_at, updated_at`
+
+// NextVersion returns the version the next upload of a document type of a policy gets
+func (r *PolicyAttachmentRepository) NextVersion(ctx context.Context, basePolicyID, registeredPolicyID *uuid.UUID, documentType models.PolicyAttachmentType) (int, error) {
+	query := `
+		SELECT COALESCE(MAX(version), 0) + 1
+		FROM policy_documents
+		WHERE base_policy_id IS NOT DISTINCT FROM $1
+			AND registered_policy_id IS NOT DISTINCT FROM $2
+			AND document_type = $3`
+
+	var version int
+	if err := r.db.GetContext(ctx, &version, query, basePolicyID, registeredPolicyID, documentType); err != nil {
+		return 0, fmt.Errorf("failed to get next policy attachment version: %w", err)
+	}
+	return version, nil
+}
+
+func (r *PolicyAttachmentRepository) Create(ctx context.Context, doc *models.PolicyAttachment) error {
+	if doc.ID == uuid.Nil {
+		doc.ID = uuid.New()
+	}
+	doc.CreatedAt = time.Now()
+	doc.UpdatedAt = doc.CreatedAt
+
+	query := `
+		INSERT INTO policy_documents (` + policyAttachmentColumns + `
+		) VALUES (
+			:id, :base_policy_id, :registered_policy_id, :document_type, :version, :title,
+			:file_name, :content_type, :object_key, :size_bytes, :status, :uploaded_by, :created_at, :updated_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, doc); err != nil {
+		return fmt.Errorf("failed to create policy attachment: %w", err)
+	}
+	return nil
+}
+
+func (r *PolicyAttachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PolicyAttachment, error) {
+	var doc models.PolicyAttachment
+	query := `SELECT ` + policyAttachmentColumns + ` FROM policy_documents WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &doc, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("policy attachment not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get policy attachment: %w", err)
+	}
+	return &doc, nil
+}
+
+func (r *PolicyAttachmentRepository) List(ctx context.Context, filter models.PolicyAttachmentFilter) ([]models.PolicyAttachment, error) {
+	var conditions []string
+	var args []any
+	if filter.BasePolicyID != nil {
+		args = append(args, *filter.BasePolicyID)
+		conditions = append(conditions, fmt.Sprintf("base_policy_id = $%d", len(args)))
+	}
+	if filter.RegisteredPolicyID != nil {
+		args = append(args, *filter.RegisteredPolicyID)
+		conditions = append(conditions, fmt.Sprintf("registered_policy_id = $%d", len(args)))
+	}
+	if filter.DocumentType != "" {
+		args = append(args, filter.DocumentType)
+		conditions = append(conditions, fmt.Sprintf("document_type = $%d", len(args)))
+	}
+	if !filter.IncludePending {
+		args = append(args, models.PolicyAttachmentUploaded)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	query := `SELECT ` + policyAttachmentColumns + ` FROM policy_documents
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY document_type, version DESC`
+
+	var docs []models.PolicyAttachment
+	if err := r.db.SelectContext(ctx, &docs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list policy attachments: %w", err)
+	}
+	return docs, nil
+}
+
+func (r *PolicyAttachmentRepository) UpdateTitle(ctx context.Context, id uuid.UUID, title *string) error {
+	query := `UPDATE policy_documents SET title = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, title)
+	if err != nil {
+		return fmt.Errorf("failed to update policy attachment: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("policy attachment not found: %s", id)
+	}
+	return nil
+}
+
+// MarkUploaded records that the file of a pending document is in storage
+func (r *PolicyAttachmentRepository) MarkUploaded(ctx context.Context, id uuid.UUID, sizeBytes int64) error {
+	query := `
+		UPDATE policy_documents SET status = $2, size_bytes = $3, updated_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, models.PolicyAttachmentUploaded, sizeBytes); err != nil {
+		return fmt.Errorf("failed to mark policy attachment uploaded: %w", err)
+	}
+	return nil
+}
+
+func (r *PolicyAttachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM policy_documents WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete policy attachment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PolicyDocumentUploadRepository struct {
+	db *sqlx.DB
+}
+
+func NewPolicyDocumentUploadRepository(db *sqlx.DB) *PolicyDocumentUploadRepository {
+	return &PolicyDocumentUploadRepository{db: db}
+}
+
+const policyDocumentUploadColumns = `
+	id, file_name, object_key, content_type, size_bytes, status, base_policy_id,
+	uploaded_by, created_at, updated_at`
+
+func (r *PolicyDocumentUploadRepository) Create(ctx context.Context, upload *models.PolicyDocumentUpload) error {
+	if upload.ID == uuid.Nil {
+		upload.ID = uuid.New()
+	}
+	upload.CreatedAt = time.Now()
+	upload.UpdatedAt = upload.CreatedAt
+
+	query := `
+		INSERT INTO policy_document_uploads (` + policyDocumentUploadColumns + `
+		) VALUES (
+			:id, :file_name, :object_key, :content_type, :size_bytes, :status, :base_policy_id,
+			:uploaded_by, :created_at, :updated_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, upload); err != nil {
+		return fmt.Errorf("failed to create policy document upload: %w", err)
+	}
+	return nil
+}
+
+func (r *PolicyDocumentUploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PolicyDocumentUpload, error) {
+	var upload models.PolicyDocumentUpload
+	query := `SELECT ` + policyDocumentUploadColumns + ` FROM policy_document_uploads WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &upload, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("policy document upload not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get policy document upload: %w", err)
+	}
+	return &upload, nil
+}
+
+func (r *PolicyDocumentUploadRepository) GetByObjectKey(ctx context.Context, objectKey string) (*models.PolicyDocumentUpload, error) {
+	var upload models.PolicyDocumentUpload
+	query := `SELECT ` + policyDocumentUploadColumns + ` FROM policy_document_uploads WHERE object_key = $1`
+
+	if err := r.db.GetContext(ctx, &upload, query, objectKey); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("policy document upload not found: %s", objectKey)
+		}
+		return nil, fmt.Errorf("failed to get policy document upload: %w", err)
+	}
+	return &upload, nil
+}
+
+// MarkUploaded records the checked content type and size of a pending upload
+func (r *PolicyDocumentUploadRepository) MarkUploaded(ctx context.Context, id uuid.UUID, contentType string, sizeBytes int64) error {
+	query := `
+		UPDATE policy_document_uploads
+		SET status = $2, content_type = $3, size_bytes = $4, updated_at = NOW()
+		WHERE id = $1 AND status = $5`
+
+	_, err := r.db.ExecContext(ctx, query, id, models.PolicyDocumentUploadUploaded, contentType, sizeBytes,
+		models.PolicyDocumentUploadPending)
+	if err != nil {
+		return fmt.Errorf("failed to mark policy document upload uploaded: %w", err)
+	}
+	return nil
+}
+
+// MarkUsed links an uploaded file to the base policy it became the template of. It fails when
+// another request used the file first.
+func (r *PolicyDocumentUploadRepository) MarkUsed(ctx context.Context, id, basePolicyID uuid.UUID) error {
+	query := `
+		UPDATE policy_document_uploads
+		SET status = $2, base_policy_id = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $4`
+
+	result, err := r.db.ExecContext(ctx, query, id, models.PolicyDocumentUploadUsed, basePolicyID,
+		models.PolicyDocumentUploadUploaded)
+	if err != nil {
+		return fmt.Errorf("failed to mark policy document upload used: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("invalid policy document: upload %s is not available", id)
+	}
+	return nil
+}
+
+func (r *PolicyDocumentUploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM policy_document_uploads WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete policy document upload: %w", err)
+	}
+	return nil
+}
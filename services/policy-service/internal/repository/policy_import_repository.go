@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PolicyImportRepository struct {
+	db *sqlx.DB
+}
+
+func NewPolicyImportRepository(db *sqlx.DB) *PolicyImportRepository {
+	return &PolicyImportRepository{db: db}
+}
+
+const policyImportJobColumns = `
+	id, insurance_provider_id, base_policy_id, file_name, file_path, status, total_rows,
+	imported_rows, rejected_rows, error_message, created_by, created_at, started_at, finished_at`
+
+// Create records a new import waiting to be processed
+func (r *PolicyImportRepository) Create(ctx context.Context, job *models.PolicyImportJob) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.Status = models.PolicyImportPending
+	job.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO policy_import_job (
+			id, insurance_provider_id, base_policy_id, file_name, file_path, status, created_by, created_at
+		) VALUES (
+			:id, :insurance_provider_id, :base_policy_id, :file_name, :file_path, :status, :created_by, :created_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, job); err != nil {
+		return fmt.Errorf("failed to create policy import: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an import
+func (r *PolicyImportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PolicyImportJob, error) {
+	var job models.PolicyImportJob
+	query := `SELECT ` + policyImportJobColumns + ` FROM policy_import_job WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &job, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("policy import not found")
+		}
+		return nil, fmt.Errorf("failed to get policy import: %w", err)
+	}
+	return &job, nil
+}
+
+// GetByProviderID retrieves the imports of an insurance provider, newest first
+func (r *PolicyImportRepository) GetByProviderID(ctx context.Context, providerID string) ([]models.PolicyImportJob, error) {
+	var jobs []models.PolicyImportJob
+	query := `SELECT ` + policyImportJobColumns + `
+		FROM policy_import_job
+		WHERE insurance_provider_id = $1
+		ORDER BY created_at DESC`
+
+	if err := r.db.SelectContext(ctx, &jobs, query, providerID); err != nil {
+		return nil, fmt.Errorf("failed to get policy imports: %w", err)
+	}
+	return jobs, nil
+}
+
+// Claim moves a pending import to processing. It reports false when the import was already
+// claimed, so an import submitted twice is only processed once.
+func (r *PolicyImportRepository) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE policy_import_job
+		SET status = $1, started_at = NOW()
+		WHERE id = $2 AND status = $3`
+
+	res, err := r.db.ExecContext(ctx, query, models.PolicyImportProcessing, id, models.PolicyImportPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim policy import: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim policy import: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// UpdateProgress saves the row counters of an import in progress
+func (r *PolicyImportRepository) UpdateProgress(ctx context.Context, job *models.PolicyImportJob) error {
+	query := `
+		UPDATE policy_import_job
+		SET total_rows = $1, imported_rows = $2, rejected_rows = $3
+		WHERE id = $4`
+
+	if _, err := r.db.ExecContext(ctx, query, job.TotalRows, job.ImportedRows, job.RejectedRows, job.ID); err != nil {
+		return fmt.Errorf("failed to update policy import progress: %w", err)
+	}
+	return nil
+}
+
+// Finish saves the final counters and status of an import
+func (r *PolicyImportRepository) Finish(ctx context.Context, job *models.PolicyImportJob) error {
+	now := time.Now()
+	job.FinishedAt = &now
+
+	query := `
+		UPDATE policy_import_job
+		SET status = $1, total_rows = $2, imported_rows = $3, rejected_rows = $4,
+			error_message = $5, finished_at = $6
+		WHERE id = $7`
+
+	if _, err := r.db.ExecContext(ctx, query, job.Status, job.TotalRows, job.ImportedRows,
+		job.RejectedRows, job.ErrorMessage, job.FinishedAt, job.ID); err != nil {
+		return fmt.Errorf("failed to finish policy import: %w", err)
+	}
+	return nil
+}
+
+// CreateRejectedRow records a row that could not be registered
+func (r *PolicyImportRepository) CreateRejectedRow(ctx context.Context, row *models.PolicyImportRejectedRow) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	row.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO policy_import_rejected_row (
+			id, import_job_id, row_number, farmer_id, farm_id, reason, created_at
+		) VALUES (
+			:id, :import_job_id, :row_number, :farmer_id, :farm_id, :reason, :created_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, row); err != nil {
+		return fmt.Errorf("failed to record rejected import row: %w", err)
+	}
+	return nil
+}
+
+// GetRejectedRows retrieves the rejected rows of an import in file order
+func (r *PolicyImportRepository) GetRejectedRows(ctx context.Context, jobID uuid.UUID) ([]models.PolicyImportRejectedRow, error) {
+	var rows []models.PolicyImportRejectedRow
+	query := `
+		SELECT id, import_job_id, row_number, farmer_id, farm_id, reason, created_at
+		FROM policy_import_rejected_row
+		WHERE import_job_id = $1
+		ORDER BY row_number`
+
+	if err := r.db.SelectContext(ctx, &rows, query, jobID); err != nil {
+		return nil, fmt.Errorf("failed to get rejected import rows: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PremiumScheduleRepository struct {
+	db *sqlx.DB
+}
+
+func NewPremiumScheduleRepository(db *sqlx.DB) *PremiumScheduleRepository {
+	return &PremiumScheduleRepository{db: db}
+}
+
+func (r *PremiumScheduleRepository) BeginTransaction() (*sqlx.Tx, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+const premiumInstallmentColumns = `
+	id, registered_policy_id, installment_number, due_date, amount_due, amount_paid,
+	status, paid_at, created_at, updated_at`
+
+// CreateInstallmentsTx creates the installments of a schedule within a transaction
+func (r *PremiumScheduleRepository) CreateInstallmentsTx(tx *sqlx.Tx, ctx context.Context, installments []models.PremiumInstallment) error {
+	now := time.Now()
+	for i := range installments {
+		if installments[i].ID == uuid.Nil {
+			installments[i].ID = uuid.New()
+		}
+		installments[i].CreatedAt = now
+		installments[i].UpdatedAt = now
+	}
+
+	query := `
+		INSERT INTO premium_payment_schedule (` + premiumInstallmentColumns + `)
+		VALUES (
+			:id, :registered_policy_id, :installment_number, :due_date, :amount_due, :amount_paid,
+			:status, :paid_at, :created_at, :updated_at
+		)`
+
+	query, args, err := tx.BindNamed(query, installments)
+	if err != nil {
+		return fmt.Errorf("failed to bind premium installments: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create premium installments: %w", err)
+	}
+	return nil
+}
+
+// GetByPolicyID retrieves the installments of a policy in order
+func (r *PremiumScheduleRepository) GetByPolicyID(ctx context.Context, policyID uuid.UUID) ([]models.PremiumInstallment, error) {
+	var installments []models.PremiumInstallment
+	query := `SELECT ` + premiumInstallmentColumns + `
+		FROM premium_payment_schedule
+		WHERE registered_policy_id = $1
+		ORDER BY installment_number`
+
+	if err := r.db.SelectContext(ctx, &installments, query, policyID); err != nil {
+		return nil, fmt.Errorf("failed to get premium schedule: %w", err)
+	}
+	return installments, nil
+}
+
+// GetByPolicyIDForUpdateTx retrieves and locks the installments of a policy in order
+func (r *PremiumScheduleRepository) GetByPolicyIDForUpdateTx(tx *sqlx.Tx, ctx context.Context, policyID uuid.UUID) ([]models.PremiumInstallment, error) {
+	var installments []models.PremiumInstallment
+	query := `SELECT ` + premiumInstallmentColumns + `
+		FROM premium_payment_schedule
+		WHERE registered_policy_id = $1
+		ORDER BY installment_number
+		FOR UPDATE`
+
+	if err := tx.SelectContext(ctx, &installments, query, policyID); err != nil {
+		return nil, fmt.Errorf("failed to get premium schedule: %w", err)
+	}
+	return installments, nil
+}
+
+// UpdateInstallmentTx saves the payment state of an installment within a transaction
+func (r *PremiumScheduleRepository) UpdateInstallmentTx(tx *sqlx.Tx, ctx context.Context, installment *models.PremiumInstallment) error {
+	installment.UpdatedAt = time.Now()
+	query := `
+		UPDATE premium_payment_schedule
+		SET amount_paid = :amount_paid, status = :status, paid_at = :paid_at, updated_at = :updated_at
+		WHERE id = :id`
+
+	query, args, err := tx.BindNamed(query, installment)
+	if err != nil {
+		return fmt.Errorf("failed to bind premium installment: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update premium installment: %w", err)
+	}
+	return nil
+}
+
+// CreatePaymentTx records a payment within a transaction. It reports false without recording
+// anything when the payment was already recorded for the policy.
+func (r *PremiumScheduleRepository) CreatePaymentTx(tx *sqlx.Tx, ctx context.Context, payment *models.PremiumPayment) (bool, error) {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+	payment.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO premium_payment (
+			id, registered_policy_id, payment_id, amount, paid_at, recorded_by, created_at
+		) VALUES (
+			:id, :registered_policy_id, :payment_id, :amount, :paid_at, :recorded_by, :created_at
+		)
+		ON CONFLICT (registered_policy_id, payment_id) DO NOTHING`
+
+	query, args, err := tx.BindNamed(query, payment)
+	if err != nil {
+		return false, fmt.Errorf("failed to bind premium payment: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to create premium payment: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create premium payment: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetPaymentsByPolicyID retrieves the payments made against the schedule of a policy, oldest
+// first
+func (r *PremiumScheduleRepository) GetPaymentsByPolicyID(ctx context.Context, policyID uuid.UUID) ([]models.PremiumPayment, error) {
+	var payments []models.PremiumPayment
+	query := `
+		SELECT id, registered_policy_id, payment_id, amount, paid_at, recorded_by, created_at
+		FROM premium_payment
+		WHERE registered_policy_id = $1
+		ORDER BY paid_at, created_at`
+
+	if err := r.db.SelectContext(ctx, &payments, query, policyID); err != nil {
+		return nil, fmt.Errorf("failed to get premium payments: %w", err)
+	}
+	return payments, nil
+}
+
+// ApplyPaymentTx records a payment and settles the installments of its policy with it, within a
+// transaction. It returns the installments after the payment, or applied false when the payment
+// was already recorded.
+func (r *PremiumScheduleRepository) ApplyPaymentTx(tx *sqlx.Tx, ctx context.Context, payment *models.PremiumPayment) (installments []models.PremiumInstallment, applied bool, err error) {
+	installments, err = r.GetByPolicyIDForUpdateTx(tx, ctx, payment.RegisteredPolicyID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(installments) == 0 {
+		return nil, false, fmt.Errorf("policy has no premium schedule")
+	}
+
+	created, err := r.CreatePaymentTx(tx, ctx, payment)
+	if err != nil || !created {
+		return installments, false, err
+	}
+
+	before := make([]float64, len(installments))
+	for i := range installments {
+		before[i] = installments[i].AmountPaid
+	}
+	if leftover := models.ApplyPremiumPayment(installments, payment.Amount, payment.PaidAt); leftover > 0 {
+		return nil, false, fmt.Errorf("invalid payment: exceeds the outstanding premium by %.2f", leftover)
+	}
+	for i := range installments {
+		if installments[i].AmountPaid != before[i] {
+			if err := r.UpdateInstallmentTx(tx, ctx, &installments[i]); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return installments, true, nil
+}
+
+// MarkOverdue marks the pending installments due before now as overdue and returns how many
+// were marked
+func (r *PremiumScheduleRepository) MarkOverdue(ctx context.Context, now int64) (int64, error) {
+	query := `
+		UPDATE premium_payment_schedule
+		SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND due_date < $3`
+
+	res, err := r.db.ExecContext(ctx, query, models.PaymentOverdue, models.PaymentPending, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark overdue installments: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// GetPoliciesToLapse returns the policies still in force with an installment overdue since
+// before cutoff
+func (r *PremiumScheduleRepository) GetPoliciesToLapse(ctx context.Context, cutoff int64) ([]uuid.UUID, error) {
+	var policyIDs []uuid.UUID
+	query := `
+		SELECT DISTINCT s.registered_policy_id
+		FROM premium_payment_schedule s
+		JOIN registered_policy rp ON rp.id = s.registered_policy_id
+		WHERE s.status = $1 AND s.due_date < $2
+		  AND rp.status IN ($3, $4)
+		  AND NOT COALESCE(rp.premium_paid_by_farmer, false)`
+
+	if err := r.db.SelectContext(ctx, &policyIDs, query,
+		models.PaymentOverdue, cutoff, models.PolicyActive, models.PolicyPendingPayment); err != nil {
+		return nil, fmt.Errorf("failed to get policies to lapse: %w", err)
+	}
+	return policyIDs, nil
+}
+
+// CancelUnpaidTx cancels the installments of a policy not yet due, within a transaction
+func (r *PremiumScheduleRepository) CancelUnpaidTx(tx *sqlx.Tx, ctx context.Context, policyID uuid.UUID) error {
+	query := `
+		UPDATE premium_payment_schedule
+		SET status = $1, updated_at = NOW()
+		WHERE registered_policy_id = $2 AND status = $3`
+
+	if _, err := tx.ExecContext(ctx, query, models.PaymentCancelled, policyID, models.PaymentPending); err != nil {
+		return fmt.Errorf("failed to cancel unpaid installments: %w", err)
+	}
+	return nil
+}
@@ -30,14 +30,14 @@ func (r *RegisteredPolicyRepository) Create(policy *models.RegisteredPolicy) err
 
 	query := `
 		INSERT INTO registered_policy (
-			id, policy_number, base_policy_id, insurance_provider_id, farm_id, farmer_id,
+			id, policy_number, base_policy_id, base_policy_version, insurance_provider_id, farm_id, farmer_id,
 			coverage_amount, coverage_start_date, coverage_end_date, planting_date,
 			area_multiplier, total_farmer_premium, premium_paid_by_farmer, premium_paid_at,
 			data_complexity_score, monthly_data_cost, total_data_cost,
 			status, underwriting_status, signed_policy_document_url,
 			created_at, updated_at, registered_by
 		) VALUES (
-			:id, :policy_number, :base_policy_id, :insurance_provider_id, :farm_id, :farmer_id,
+			:id, :policy_number, :base_policy_id, :base_policy_version, :insurance_provider_id, :farm_id, :farmer_id,
 			:coverage_amount, :coverage_start_date, :coverage_end_date, :planting_date,
 			:area_multiplier, :total_farmer_premium, :premium_paid_by_farmer, :premium_paid_at,
 			:data_complexity_score, :monthly_data_cost, :total_data_cost,
@@ -76,6 +76,16 @@ func (r *RegisteredPolicyRepository) GetInsuranceProviderIDByID(id uuid.UUID) (s
 	return insuranceID, nil
 }
 
+// HasPremiumSchedule reports whether the premium of a policy is paid in installments
+func (r *RegisteredPolicyRepository) HasPremiumSchedule(id uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM premium_payment_schedule WHERE registered_policy_id = $1)`
+	if err := r.db.Get(&exists, query, id); err != nil {
+		return false, fmt.Errorf("failed to check premium schedule: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *RegisteredPolicyRepository) GetByPolicyNumber(policyNumber string) (*models.RegisteredPolicy, error) {
 	var policy models.RegisteredPolicy
 	query := `SELECT * FROM registered_policy WHERE policy_number = $1`
@@ -129,7 +139,7 @@ func (r *RegisteredPolicyRepository) Update(policy *models.RegisteredPolicy) err
 
 	query := `
 		UPDATE registered_policy SET
-			policy_number = :policy_number, base_policy_id = :base_policy_id,
+			policy_number = :policy_number, base_policy_id = :base_policy_id, base_policy_version = :base_policy_version,
 			insurance_provider_id = :insurance_provider_id, farm_id = :farm_id, farmer_id = :farmer_id,
 			coverage_amount = :coverage_amount, coverage_start_date = :coverage_start_date,
 			coverage_end_date = :coverage_end_date, planting_date = :planting_date,
@@ -440,14 +450,14 @@ func (r *RegisteredPolicyRepository) CreateTx(tx *sqlx.Tx, policy *models.Regist
 
 	query := `
 		INSERT INTO registered_policy (
-			id, policy_number, base_policy_id, insurance_provider_id, farm_id, farmer_id,
+			id, policy_number, base_policy_id, base_policy_version, insurance_provider_id, farm_id, farmer_id,
 			coverage_amount, coverage_start_date, coverage_end_date, planting_date,
 			area_multiplier, total_farmer_premium, premium_paid_by_farmer, premium_paid_at,
 			data_complexity_score, monthly_data_cost, total_data_cost,
 			status, underwriting_status, signed_policy_document_url,
 			created_at, updated_at, registered_by
 		) VALUES (
-			:id, :policy_number, :base_policy_id, :insurance_provider_id, :farm_id, :farmer_id,
+			:id, :policy_number, :base_policy_id, :base_policy_version, :insurance_provider_id, :farm_id, :farmer_id,
 			:coverage_amount, :coverage_start_date, :coverage_end_date, :planting_date,
 			:area_multiplier, :total_farmer_premium, :premium_paid_by_farmer, :premium_paid_at,
 			:data_complexity_score, :monthly_data_cost, :total_data_cost,
@@ -463,13 +473,34 @@ func (r *RegisteredPolicyRepository) CreateTx(tx *sqlx.Tx, policy *models.Regist
 	return nil
 }
 
+// RequireMonitoringConsentTx gates the monitoring of a policy on its farmer's satellite monitoring
+// consent, within a transaction
+func (r *RegisteredPolicyRepository) RequireMonitoringConsentTx(tx *sqlx.Tx, policyID uuid.UUID, farmerID string) error {
+	query := `INSERT INTO policy_monitoring_consent (registered_policy_id, farmer_id) VALUES ($1, $2)`
+	if _, err := tx.Exec(query, policyID, farmerID); err != nil {
+		return fmt.Errorf("failed to require monitoring consent: %w", err)
+	}
+	return nil
+}
+
+// MonitoringConsentRequired reports whether the monitoring of a policy is gated on its farmer's
+// satellite monitoring consent
+func (r *RegisteredPolicyRepository) MonitoringConsentRequired(policyID uuid.UUID) (bool, error) {
+	var required bool
+	query := `SELECT EXISTS (SELECT 1 FROM policy_monitoring_consent WHERE registered_policy_id = $1)`
+	if err := r.db.Get(&required, query, policyID); err != nil {
+		return false, fmt.Errorf("failed to check monitoring consent requirement: %w", err)
+	}
+	return required, nil
+}
+
 // UpdateTx updates a registered policy within a transaction
 func (r *RegisteredPolicyRepository) UpdateTx(tx *sqlx.Tx, policy *models.RegisteredPolicy) error {
 	policy.UpdatedAt = time.Now()
 
 	query := `
 		UPDATE registered_policy SET
-			policy_number = :policy_number, base_policy_id = :base_policy_id,
+			policy_number = :policy_number, base_policy_id = :base_policy_id, base_policy_version = :base_policy_version,
 			insurance_provider_id = :insurance_provider_id, farm_id = :farm_id, farmer_id = :farmer_id,
 			coverage_amount = :coverage_amount, coverage_start_date = :coverage_start_date,
 			coverage_end_date = :coverage_end_date, planting_date = :planting_date,
@@ -549,12 +580,12 @@ func (r *RegisteredPolicyRepository) CreateRiskAnalysis(analysis *models.Registe
 			id, registered_policy_id, analysis_status, analysis_type,
 			analysis_source, analysis_timestamp, overall_risk_score,
 			overall_risk_level, identified_risks, recommendations,
-			raw_output, analysis_notes, created_at
+			raw_output, analysis_notes, input_hash, created_at
 		) VALUES (
 			:id, :registered_policy_id, :analysis_status, :analysis_type,
 			:analysis_source, :analysis_timestamp, :overall_risk_score,
 			:overall_risk_level, :identified_risks, :recommendations,
-			:raw_output, :analysis_notes, :created_at
+			:raw_output, :analysis_notes, :input_hash, :created_at
 		)`
 
 	_, err := r.db.NamedExec(query, analysis)
@@ -587,12 +618,12 @@ func (r *RegisteredPolicyRepository) CreateRiskAnalysisTX(analysis *models.Regis
 			id, registered_policy_id, analysis_status, analysis_type,
 			analysis_source, analysis_timestamp, overall_risk_score,
 			overall_risk_level, identified_risks, recommendations,
-			raw_output, analysis_notes, created_at
+			raw_output, analysis_notes, input_hash, created_at
 		) VALUES (
 			:id, :registered_policy_id, :analysis_status, :analysis_type,
 			:analysis_source, :analysis_timestamp, :overall_risk_score,
 			:overall_risk_level, :identified_risks, :recommendations,
-			:raw_output, :analysis_notes, :created_at
+			:raw_output, :analysis_notes, :input_hash, :created_at
 		)`
 
 	_, err := tx.ExecContext(context.Background(), query, analysis)
@@ -835,9 +866,14 @@ func (r *RegisteredPolicyRepository) GetByInsuranceProviderIDAndStatus(providerI
 	return policies, nil
 }
 
-// GetPolicyStats retrieves aggregated statistics for policies
-func (r *RegisteredPolicyRepository) GetPolicyStats(providerID string) (map[string]any, error) {
-	stats := make(map[string]any)
+// GetPolicyStats retrieves aggregated statistics for policies, of all providers when providerID
+// is empty
+func (r *RegisteredPolicyRepository) GetPolicyStats(providerID string) (*models.PolicyStats, error) {
+	stats := &models.PolicyStats{
+		ProviderID:           providerID,
+		ByStatus:             make(map[string]int64),
+		ByUnderwritingStatus: make(map[string]int64),
+	}
 
 	// Base query with optional provider filter
 	whereClause := ""
@@ -847,68 +883,47 @@ func (r *RegisteredPolicyRepository) GetPolicyStats(providerID string) (map[stri
 		args = append(args, providerID)
 	}
 
-	// Total count
-	var totalCount int
-	countQuery := `SELECT COUNT(*) FROM registered_policy` + whereClause
-	err := r.db.Get(&totalCount, countQuery, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total count: %w", err)
+	var totals struct {
+		TotalCount    int64   `db:"total_count"`
+		TotalCoverage float64 `db:"total_coverage"`
+		TotalPremium  float64 `db:"total_premium"`
+	}
+	totalsQuery := `
+		SELECT COUNT(*) AS total_count,
+			COALESCE(SUM(coverage_amount), 0) AS total_coverage,
+			COALESCE(SUM(total_farmer_premium), 0) AS total_premium
+		FROM registered_policy` + whereClause
+	if err := r.db.Get(&totals, totalsQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to get policy totals: %w", err)
+	}
+	stats.TotalCount = totals.TotalCount
+	stats.TotalCoverageAmount = totals.TotalCoverage
+	stats.TotalPremiumCollected = totals.TotalPremium
+
+	type groupCount struct {
+		Key   string `db:"key"`
+		Count int64  `db:"count"`
 	}
-	stats["total_count"] = totalCount
 
 	// Count by status
-	statusCounts := make(map[string]int)
-	statusQuery := `SELECT status, COUNT(*) as count FROM registered_policy` + whereClause + ` GROUP BY status`
-	rows, err := r.db.Queryx(statusQuery, args...)
-	if err != nil {
+	var statusCounts []groupCount
+	statusQuery := `SELECT status::TEXT AS key, COUNT(*) AS count FROM registered_policy` + whereClause + ` GROUP BY status`
+	if err := r.db.Select(&statusCounts, statusQuery, args...); err != nil {
 		return nil, fmt.Errorf("failed to get status counts: %w", err)
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var status string
-		var count int
-		if err := rows.Scan(&status, &count); err != nil {
-			continue
-		}
-		statusCounts[status] = count
+	for _, row := range statusCounts {
+		stats.ByStatus[row.Key] = row.Count
 	}
-	stats["by_status"] = statusCounts
 
 	// Count by underwriting status
-	underwritingCounts := make(map[string]int)
-	uwQuery := `SELECT underwriting_status, COUNT(*) as count FROM registered_policy` + whereClause + ` GROUP BY underwriting_status`
-	rows2, err := r.db.Queryx(uwQuery, args...)
-	if err != nil {
+	var underwritingCounts []groupCount
+	uwQuery := `SELECT underwriting_status::TEXT AS key, COUNT(*) AS count FROM registered_policy` + whereClause + ` GROUP BY underwriting_status`
+	if err := r.db.Select(&underwritingCounts, uwQuery, args...); err != nil {
 		return nil, fmt.Errorf("failed to get underwriting status counts: %w", err)
 	}
-	defer rows2.Close()
-	for rows2.Next() {
-		var status string
-		var count int
-		if err := rows2.Scan(&status, &count); err != nil {
-			continue
-		}
-		underwritingCounts[status] = count
-	}
-	stats["by_underwriting_status"] = underwritingCounts
-
-	// Total coverage amount
-	var totalCoverage float64
-	coverageQuery := `SELECT COALESCE(SUM(coverage_amount), 0) FROM registered_policy` + whereClause
-	err = r.db.Get(&totalCoverage, coverageQuery, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total coverage: %w", err)
+	for _, row := range underwritingCounts {
+		stats.ByUnderwritingStatus[row.Key] = row.Count
 	}
-	stats["total_coverage_amount"] = totalCoverage
-
-	// Total premium collected
-	var totalPremium float64
-	premiumQuery := `SELECT COALESCE(SUM(total_farmer_premium), 0) FROM registered_policy` + whereClause
-	err = r.db.Get(&totalPremium, premiumQuery, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total premium: %w", err)
-	}
-	stats["total_premium_collected"] = totalPremium
 
 	return stats, nil
 }
@@ -1071,7 +1086,7 @@ func (r *RegisteredPolicyRepository) GetLatestUnderwriting(policyID uuid.UUID) (
 }
 
 // CreateClaim creates a new claim record
-func (r *RegisteredPolicyRepository) CreateClaim(claim *models.Claim) error {
+func (r *RegisteredPolicyRepository) CreateClaim(claim *models.Claim, notifications ...*models.OutboxEvent) error {
 	slog.Debug("Creating claim", "claim_id", claim.ID, "policy_id", claim.RegisteredPolicyID)
 
 	if claim.ID == uuid.Nil {
@@ -1097,12 +1112,34 @@ func (r *RegisteredPolicyRepository) CreateClaim(claim *models.Claim) error {
 			:evidence_summary, :created_at, :updated_at
 		)`
 
-	_, err := r.db.NamedExec(query, claim)
+	tx, err := r.db.Beginx()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExec(query, claim); err != nil {
 		slog.Error("Failed to create claim", "claim_id", claim.ID, "error", err)
 		return fmt.Errorf("failed to create claim: %w", err)
 	}
 
+	// The status the claim starts in opens its status history
+	history := &models.ClaimStatusHistory{ClaimID: claim.ID, ToStatus: claim.Status}
+	if err := createClaimStatusHistory(context.Background(), tx, history); err != nil {
+		slog.Error("Failed to record claim status", "claim_id", claim.ID, "error", err)
+		return err
+	}
+	for _, notification := range notifications {
+		if err := createOutboxEvent(context.Background(), tx, notification); err != nil {
+			slog.Error("Failed to queue claim notification", "claim_id", claim.ID, "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit claim: %w", err)
+	}
+
 	slog.Info("Successfully created claim", "claim_id", claim.ID, "claim_number", claim.ClaimNumber)
 	return nil
 }
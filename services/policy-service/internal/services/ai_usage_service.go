@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/ai/gemini"
+	"policy-service/internal/config"
+	"policy-service/internal/event"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const aiUsageProviderGemini = "gemini"
+
+// Share of the monthly budget at which an insurance provider is alerted, in percent
+var aiBudgetAlertThresholds = []int{80, 100}
+
+// aiModelPrice is what a model costs in USD per million tokens
+type aiModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// AIUsageService accounts the tokens AI jobs use to their insurance provider, estimates their
+// cost and alerts a provider whose monthly spend reaches its budget
+type AIUsageService struct {
+	usageRepo     *repository.AIUsageRepository
+	outboxRepo    *repository.OutboxRepository
+	prices        map[string]aiModelPrice
+	monthlyBudget float64
+}
+
+func NewAIUsageService(usageRepo *repository.AIUsageRepository, outboxRepo *repository.OutboxRepository, cfg config.GeminiAPIConfig) *AIUsageService {
+	return &AIUsageService{
+		usageRepo:  usageRepo,
+		outboxRepo: outboxRepo,
+		prices: map[string]aiModelPrice{
+			cfg.ProName:   {Input: cfg.ProInputPrice, Output: cfg.ProOutputPrice},
+			cfg.FlashName: {Input: cfg.FlashInputPrice, Output: cfg.FlashOutputPrice},
+		},
+		monthlyBudget: cfg.MonthlyBudget,
+	}
+}
+
+// RecordJob stores the usage counted by meter for one run of a job. Accounting must not fail the
+// job that already paid for its calls, so errors are only logged.
+func (s *AIUsageService) RecordJob(ctx context.Context, source models.AIUsageSource, meter *gemini.UsageMeter, succeeded bool) {
+	if s == nil || meter == nil {
+		return
+	}
+	usage := meter.Usage()
+	if len(usage) == 0 {
+		return
+	}
+	if err := s.record(ctx, source, usage, succeeded); err != nil {
+		slog.Error("Failed to record AI usage",
+			"job_type", source.JobType,
+			"insurance_provider_id", source.InsuranceProviderID,
+			"error", err)
+	}
+}
+
+func (s *AIUsageService) record(ctx context.Context, source models.AIUsageSource, usage []gemini.Usage, succeeded bool) error {
+	now := time.Now()
+	jobRunID := uuid.New()
+	rows := make([]models.AIUsage, 0, len(usage))
+	var jobCost float64
+	for _, u := range usage {
+		cost := s.estimateCost(u)
+		jobCost += cost
+		rows = append(rows, models.AIUsage{
+			ID:                  uuid.New(),
+			JobRunID:            jobRunID,
+			Provider:            aiUsageProviderGemini,
+			Model:               u.Model,
+			JobType:             source.JobType,
+			InsuranceProviderID: source.InsuranceProviderID,
+			BasePolicyID:        source.BasePolicyID,
+			RegisteredPolicyID:  source.RegisteredPolicyID,
+			CallCount:           u.Calls,
+			PromptTokens:        u.PromptTokens,
+			ResponseTokens:      u.ResponseTokens,
+			TotalTokens:         u.TotalTokens,
+			EstimatedCostUSD:    cost,
+			Succeeded:           succeeded,
+			CreatedAt:           now,
+		})
+	}
+
+	tx, err := s.usageRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var spentBefore float64
+	monthStart, monthEnd := monthBounds(now)
+	if s.monthlyBudget > 0 {
+		if err := s.usageRepo.LockProviderTx(tx, ctx, source.InsuranceProviderID); err != nil {
+			return err
+		}
+		spentBefore, err = s.usageRepo.GetCostTx(tx, ctx, source.InsuranceProviderID, monthStart, monthEnd)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range rows {
+		if err := s.usageRepo.CreateTx(tx, ctx, &rows[i]); err != nil {
+			return err
+		}
+	}
+
+	spentAfter := spentBefore + jobCost
+	for _, percent := range budgetThresholdsCrossed(spentBefore, spentAfter, s.monthlyBudget) {
+		notification, err := event.AIBudgetAlertNotification(ctx, rows[0].ID, source.InsuranceProviderID,
+			monthStart.Format("2006-01"), percent, spentAfter, s.monthlyBudget)
+		if err != nil {
+			return err
+		}
+		if err := s.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+			return err
+		}
+		slog.Warn("AI budget threshold reached",
+			"insurance_provider_id", source.InsuranceProviderID,
+			"percent", percent,
+			"spent_usd", spentAfter,
+			"budget_usd", s.monthlyBudget)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit AI usage: %w", err)
+	}
+
+	slog.Info("AI usage recorded",
+		"job_type", source.JobType,
+		"insurance_provider_id", source.InsuranceProviderID,
+		"models", len(rows),
+		"estimated_cost_usd", jobCost)
+	return nil
+}
+
+// MonthlyUsage returns the usage per month, of all providers unless the filter names one
+func (s *AIUsageService) MonthlyUsage(ctx context.Context, filter models.AIUsageFilter) ([]models.AIUsageSummary, error) {
+	if err := validateAIUsageFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.usageRepo.SummarizeByMonth(ctx, filter)
+}
+
+// ProviderUsage returns the usage per insurance provider over the filter range
+func (s *AIUsageService) ProviderUsage(ctx context.Context, filter models.AIUsageFilter) ([]models.AIUsageSummary, error) {
+	if err := validateAIUsageFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.usageRepo.SummarizeByProvider(ctx, filter)
+}
+
+// estimateCost prices usage at the configured rate of its model; a model without a price is
+// logged and counted as free rather than guessed
+func (s *AIUsageService) estimateCost(u gemini.Usage) float64 {
+	price, ok := s.prices[u.Model]
+	if !ok {
+		slog.Warn("No price configured for AI model, cost not estimated", "model", u.Model)
+		return 0
+	}
+	return (float64(u.PromptTokens)*price.Input + float64(u.ResponseTokens)*price.Output) / 1_000_000
+}
+
+// budgetThresholdsCrossed returns the alert thresholds, in percent of budget, that spend passed
+// going from before to after. A budget of 0 has no thresholds.
+func budgetThresholdsCrossed(before, after, budget float64) []int {
+	if budget <= 0 {
+		return nil
+	}
+	var crossed []int
+	for _, percent := range aiBudgetAlertThresholds {
+		limit := budget * float64(percent) / 100
+		if before < limit && after >= limit {
+			crossed = append(crossed, percent)
+		}
+	}
+	return crossed
+}
+
+// monthBounds returns the start of the month of t and of the next one
+func monthBounds(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+func validateAIUsageFilter(filter models.AIUsageFilter) error {
+	if !filter.From.Before(filter.To) {
+		return fmt.Errorf("invalid range: from must be before to")
+	}
+	return nil
+}
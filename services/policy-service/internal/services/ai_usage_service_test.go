@@ -0,0 +1,60 @@
+package services
+
+import (
+	"policy-service/internal/ai/gemini"
+	"policy-service/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAIUsageEstimateCost(t *testing.T) {
+	service := NewAIUsageService(nil, nil, config.GeminiAPIConfig{
+		ProName:          "gemini-2.5-pro",
+		FlashName:        "gemini-2.5-flash",
+		ProInputPrice:    1.25,
+		ProOutputPrice:   10,
+		FlashInputPrice:  0.30,
+		FlashOutputPrice: 2.50,
+	})
+
+	pro := gemini.Usage{Model: "gemini-2.5-pro", Calls: 2, PromptTokens: 400_000, ResponseTokens: 50_000}
+	assert.InDelta(t, 0.5+0.5, service.estimateCost(pro), 1e-9)
+
+	flash := gemini.Usage{Model: "gemini-2.5-flash", Calls: 1, PromptTokens: 1_000_000, ResponseTokens: 200_000}
+	assert.InDelta(t, 0.30+0.50, service.estimateCost(flash), 1e-9)
+
+	unknown := gemini.Usage{Model: "gemini-1.0-ultra", Calls: 1, PromptTokens: 1_000_000}
+	assert.Zero(t, service.estimateCost(unknown))
+}
+
+func TestBudgetThresholdsCrossed(t *testing.T) {
+	tests := []struct {
+		name   string
+		before float64
+		after  float64
+		budget float64
+		want   []int
+	}{
+		{name: "Below every threshold", before: 10, after: 50, budget: 100},
+		{name: "Reaches 80%", before: 70, after: 80, budget: 100, want: []int{80}},
+		{name: "Already past 80%", before: 85, after: 95, budget: 100},
+		{name: "Reaches the budget", before: 95, after: 100.5, budget: 100, want: []int{100}},
+		{name: "Jumps both thresholds", before: 10, after: 150, budget: 100, want: []int{80, 100}},
+		{name: "Already over budget", before: 120, after: 130, budget: 100},
+		{name: "No budget", before: 0, after: 1000, budget: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, budgetThresholdsCrossed(tt.before, tt.after, tt.budget))
+		})
+	}
+}
+
+func TestMonthBounds(t *testing.T) {
+	start, end := monthBounds(time.Date(2026, time.December, 17, 15, 4, 5, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), end)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"policy-service/internal/models"
+	"slices"
+	"strings"
+)
+
+// Values the OUTPUT SCHEMA of gemini.ValidationPromptTemplate allows
+var (
+	aiValidationStatuses = []models.ValidationStatus{models.ValidationPassedAI, models.ValidationFailed, models.ValidationWarning}
+	aiMismatchSeverities = []string{"critical", "important", "metadata"}
+	aiPriorities         = []string{"high", "medium", "low"}
+)
+
+// parseAIValidationResponse checks an AI answer to the validation prompt against its output schema
+// and converts it. Every problem found is listed in the error, which is sent back to the model
+// when asking it to repair the answer.
+func parseAIValidationResponse(resp map[string]any) (*models.BasePolicyDocumentValidation, error) {
+	var problems []string
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	status, ok := resp["validation_status"].(string)
+	switch {
+	case !ok:
+		addProblem("validation_status is missing or not a string")
+	case !slices.Contains(aiValidationStatuses, models.ValidationStatus(status)):
+		addProblem("validation_status %q is not one of passed_ai, failed, warning", status)
+	}
+
+	counts := make(map[string]int, 4)
+	for _, field := range []string{"total_checks", "passed_checks", "failed_checks", "warning_count"} {
+		value, present := resp[field]
+		number, ok := value.(float64)
+		switch {
+		case !present:
+			addProblem("%s is missing", field)
+		case !ok || number != math.Trunc(number):
+			addProblem("%s must be an integer, got %v", field, value)
+		case number < 0:
+			addProblem("%s must not be negative, got %v", field, number)
+		default:
+			counts[field] = int(number)
+		}
+	}
+	if len(counts) == 4 {
+		if counts["passed_checks"]+counts["failed_checks"] > counts["total_checks"] {
+			addProblem("passed_checks (%d) + failed_checks (%d) exceed total_checks (%d)",
+				counts["passed_checks"], counts["failed_checks"], counts["total_checks"])
+		}
+		if counts["failed_checks"] > 0 && status != string(models.ValidationFailed) {
+			addProblem("validation_status must be failed when failed_checks is %d", counts["failed_checks"])
+		}
+	}
+
+	mismatches := checkSchemaEntries(resp, "mismatches", &problems, func(path string, entry map[string]any) {
+		if severity, _ := entry["severity"].(string); !slices.Contains(aiMismatchSeverities, severity) {
+			addProblem("mismatches[%q].severity %q is not one of critical, important, metadata", path, entry["severity"])
+		}
+		if _, ok := entry["impact"].(string); !ok {
+			addProblem("mismatches[%q].impact is missing or not a string", path)
+		}
+	})
+	warnings := checkSchemaEntries(resp, "warnings", &problems, func(path string, entry map[string]any) {
+		if _, ok := entry["details"].(string); !ok {
+			addProblem("warnings[%q].details is missing or not a string", path)
+		}
+	})
+	recommendations := checkSchemaEntries(resp, "recommendations", &problems, func(category string, entry map[string]any) {
+		if _, ok := entry["suggestion"].(string); !ok {
+			addProblem("recommendations[%q].suggestion is missing or not a string", category)
+		}
+		if priority, _ := entry["priority"].(string); !slices.Contains(aiPriorities, priority) {
+			addProblem("recommendations[%q].priority %q is not one of high, medium, low", category, entry["priority"])
+		}
+		if fields, present := entry["affected_fields"]; present {
+			list, ok := fields.([]any)
+			if ok {
+				for _, field := range list {
+					if _, ok = field.(string); !ok {
+						break
+					}
+				}
+			}
+			if !ok {
+				addProblem("recommendations[%q].affected_fields must be an array of strings", category)
+			}
+		}
+	})
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid AI validation response: %s", strings.Join(problems, "; "))
+	}
+
+	return &models.BasePolicyDocumentValidation{
+		ValidationStatus: models.ValidationStatus(status),
+		TotalChecks:      counts["total_checks"],
+		PassedChecks:     counts["passed_checks"],
+		FailedChecks:     counts["failed_checks"],
+		WarningCount:     counts["warning_count"],
+		Mismatches:       mismatches,
+		Warnings:         warnings,
+		Recommendations:  recommendations,
+	}, nil
+}
+
+// checkSchemaEntries checks that field is an object of objects, the shape of mismatches, warnings
+// and recommendations, and runs check on each entry. A missing or null field is an empty object.
+func checkSchemaEntries(resp map[string]any, field string, problems *[]string, check func(key string, entry map[string]any)) map[string]any {
+	value, present := resp[field]
+	if !present || value == nil {
+		return map[string]any{}
+	}
+	entries, ok := value.(map[string]any)
+	if !ok {
+		*problems = append(*problems, fmt.Sprintf("%s must be an object, got %s", field, jsonType(value)))
+		return nil
+	}
+	for key, raw := range entries {
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s[%q] must be an object, got %s", field, key, jsonType(raw)))
+			continue
+		}
+		check(key, entry)
+	}
+	return entries
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
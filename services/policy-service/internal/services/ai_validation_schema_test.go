@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/json"
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validAIValidationResponse = `{
+	"id": "4a3c1e5e-9a0b-4c1d-8e2f-3b4a5c6d7e8f",
+	"validation_status": "failed",
+	"total_checks": 12,
+	"passed_checks": 11,
+	"failed_checks": 1,
+	"warning_count": 1,
+	"mismatches": {
+		"base_policy.fix_premium_amount": {
+			"json_value": 500000,
+			"pdf_value": 550000,
+			"severity": "critical",
+			"field_type": "int",
+			"impact": "Phí bảo hiểm tính sai"
+		}
+	},
+	"warnings": {
+		"base_policy.product_description": {
+			"json_value": "",
+			"details": "optional_field_absent",
+			"pdf_context": "",
+			"recommendation": "Kiểm tra lại"
+		}
+	},
+	"recommendations": {
+		"premium": {
+			"suggestion": "Cập nhật phí bảo hiểm",
+			"affected_fields": ["base_policy.fix_premium_amount"],
+			"priority": "high"
+		}
+	}
+}`
+
+func decodeAIResponse(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &resp))
+	return resp
+}
+
+func TestParseAIValidationResponse(t *testing.T) {
+	validation, err := parseAIValidationResponse(decodeAIResponse(t, validAIValidationResponse))
+	require.NoError(t, err)
+	assert.Equal(t, models.ValidationFailed, validation.ValidationStatus)
+	assert.Equal(t, 12, validation.TotalChecks)
+	assert.Equal(t, 11, validation.PassedChecks)
+	assert.Equal(t, 1, validation.FailedChecks)
+	assert.Equal(t, 1, validation.WarningCount)
+	assert.Contains(t, validation.Mismatches, "base_policy.fix_premium_amount")
+	assert.Len(t, validation.Warnings, 1)
+	assert.Len(t, validation.Recommendations, 1)
+}
+
+func TestParseAIValidationResponseEmptySections(t *testing.T) {
+	resp := decodeAIResponse(t, `{
+		"validation_status": "passed_ai",
+		"total_checks": 10, "passed_checks": 10, "failed_checks": 0, "warning_count": 0,
+		"mismatches": {}, "warnings": null
+	}`)
+
+	validation, err := parseAIValidationResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, models.ValidationPassedAI, validation.ValidationStatus)
+	assert.Empty(t, validation.Warnings)
+	assert.Empty(t, validation.Recommendations)
+}
+
+func TestParseAIValidationResponseRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(resp map[string]any)
+		wantErr string
+	}{
+		{
+			name:    "Missing status",
+			modify:  func(resp map[string]any) { delete(resp, "validation_status") },
+			wantErr: "validation_status is missing",
+		},
+		{
+			name:    "Unknown status",
+			modify:  func(resp map[string]any) { resp["validation_status"] = "passed" },
+			wantErr: `validation_status "passed" is not one of`,
+		},
+		{
+			name:    "Count as string",
+			modify:  func(resp map[string]any) { resp["total_checks"] = "12" },
+			wantErr: "total_checks must be an integer",
+		},
+		{
+			name:    "Fractional count",
+			modify:  func(resp map[string]any) { resp["warning_count"] = 1.5 },
+			wantErr: "warning_count must be an integer",
+		},
+		{
+			name:    "Negative count",
+			modify:  func(resp map[string]any) { resp["passed_checks"] = -1.0 },
+			wantErr: "passed_checks must not be negative",
+		},
+		{
+			name:    "Checks exceed total",
+			modify:  func(resp map[string]any) { resp["total_checks"] = 5.0 },
+			wantErr: "exceed total_checks",
+		},
+		{
+			name:    "Failed checks without failed status",
+			modify:  func(resp map[string]any) { resp["validation_status"] = "warning" },
+			wantErr: "validation_status must be failed",
+		},
+		{
+			name:    "Mismatches as array",
+			modify:  func(resp map[string]any) { resp["mismatches"] = []any{} },
+			wantErr: "mismatches must be an object, got array",
+		},
+		{
+			name: "Unknown severity",
+			modify: func(resp map[string]any) {
+				resp["mismatches"].(map[string]any)["base_policy.fix_premium_amount"].(map[string]any)["severity"] = "high"
+			},
+			wantErr: "severity \"high\" is not one of",
+		},
+		{
+			name: "Affected fields not strings",
+			modify: func(resp map[string]any) {
+				resp["recommendations"].(map[string]any)["premium"].(map[string]any)["affected_fields"] = []any{1.0}
+			},
+			wantErr: "affected_fields must be an array of strings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := decodeAIResponse(t, validAIValidationResponse)
+			tt.modify(resp)
+
+			_, err := parseAIValidationResponse(resp)
+			assert.ErrorContains(t, err, "invalid AI validation response")
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
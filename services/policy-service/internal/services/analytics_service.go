@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"time"
+)
+
+// Longest series served, in months, so a wide range cannot produce an unbounded response
+const maxAnalyticsSeriesMonths = 120
+
+// AnalyticsService serves the portfolio analytics of insurers from the materialized views of
+// AnalyticsRepository, which RefreshViews keeps current
+type AnalyticsService struct {
+	analyticsRepo *repository.AnalyticsRepository
+}
+
+func NewAnalyticsService(analyticsRepo *repository.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{analyticsRepo: analyticsRepo}
+}
+
+// RefreshViews refreshes the analytics views, run periodically by the analytics-refresh cron job
+func (s *AnalyticsService) RefreshViews(ctx context.Context) error {
+	start := time.Now()
+	if err := s.analyticsRepo.RefreshViews(ctx); err != nil {
+		return err
+	}
+	slog.Info("Analytics views refreshed", "duration", time.Since(start))
+	return nil
+}
+
+// GetPortfolioSummary summarizes the policies of a provider, or of all providers when providerID
+// is empty, with their premium, payouts and claims
+func (s *AnalyticsService) GetPortfolioSummary(ctx context.Context, providerID string) (*models.PortfolioSummary, error) {
+	summary := &models.PortfolioSummary{ProviderID: providerID}
+
+	var err error
+	if summary.ByStatus, err = s.analyticsRepo.GetPortfolioBreakdown(ctx, providerID, "status"); err != nil {
+		return nil, err
+	}
+	if summary.ByCrop, err = s.analyticsRepo.GetPortfolioBreakdown(ctx, providerID, "crop_type"); err != nil {
+		return nil, err
+	}
+	if summary.ByProvince, err = s.analyticsRepo.GetPortfolioBreakdown(ctx, providerID, "province"); err != nil {
+		return nil, err
+	}
+	totals, err := s.analyticsRepo.GetPortfolioTotals(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if summary.RefreshedAt, err = s.analyticsRepo.GetRefreshedAt(ctx); err != nil {
+		return nil, err
+	}
+
+	summary.PortfolioTotals = *totals
+	for _, group := range summary.ByStatus {
+		summary.TotalPolicies += group.PolicyCount
+		summary.TotalCoverageAmount += group.CoverageAmount
+		summary.PremiumWritten += group.PremiumWritten
+		if group.Key == string(models.PolicyActive) {
+			summary.ActivePolicies = group.PolicyCount
+		}
+	}
+	summary.LossRatioPercent = percentOf(totals.PayoutsPaid, totals.PremiumCollected)
+	summary.ClaimFrequencyPercent = percentOf(float64(totals.ClaimCount), float64(summary.TotalPolicies))
+	return summary, nil
+}
+
+// GetSeries returns the activity of a provider, or of all providers, per period of req.Bucket
+// between req.From and req.To. Every period of the range is listed, empty ones included.
+func (s *AnalyticsService) GetSeries(ctx context.Context, req models.AnalyticsSeriesRequest) (*models.AnalyticsSeries, error) {
+	if err := validateSeriesRequest(req); err != nil {
+		return nil, err
+	}
+
+	activity, err := s.analyticsRepo.GetMonthlyActivity(ctx, req.ProviderID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	refreshedAt, err := s.analyticsRepo.GetRefreshedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AnalyticsSeries{
+		ProviderID:  req.ProviderID,
+		Bucket:      req.Bucket,
+		Points:      bucketActivity(activity, req.Bucket, req.From, req.To),
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+func validateSeriesRequest(req models.AnalyticsSeriesRequest) error {
+	switch req.Bucket {
+	case models.AnalyticsBucketMonth, models.AnalyticsBucketQuarter, models.AnalyticsBucketYear:
+	default:
+		return fmt.Errorf("invalid bucket %q, allowed: month, quarter, year", req.Bucket)
+	}
+	if !req.From.Before(req.To) {
+		return fmt.Errorf("invalid range: from must be before to")
+	}
+	if req.From.AddDate(0, maxAnalyticsSeriesMonths, 0).Before(req.To) {
+		return fmt.Errorf("invalid range: at most %d months can be requested", maxAnalyticsSeriesMonths)
+	}
+	return nil
+}
+
+// bucketActivity sums monthly activity into the periods of bucket covering [from, to). Periods
+// are aligned to calendar quarters and years, so the first and last may be partly outside the
+// range; only the months inside it are counted.
+func bucketActivity(activity []models.MonthlyActivity, bucket models.AnalyticsBucket, from, to time.Time) []models.AnalyticsSeriesPoint {
+	var points []models.AnalyticsSeriesPoint
+	index := make(map[time.Time]int)
+	for start := periodStart(from, bucket); start.Before(to); start = nextPeriod(start, bucket) {
+		index[start] = len(points)
+		points = append(points, models.AnalyticsSeriesPoint{
+			PeriodStart: start,
+			Label:       periodLabel(start, bucket),
+		})
+	}
+
+	for _, month := range activity {
+		i, ok := index[periodStart(month.Month, bucket)]
+		if !ok {
+			continue
+		}
+		points[i].PremiumCollected += month.PremiumCollected
+		points[i].PayoutsPaid += month.PayoutsPaid
+		points[i].ClaimCount += month.ClaimCount
+		points[i].RejectedClaimCount += month.RejectedClaimCount
+		points[i].PoliciesRegistered += month.PoliciesRegistered
+	}
+	for i := range points {
+		points[i].LossRatioPercent = percentOf(points[i].PayoutsPaid, points[i].PremiumCollected)
+	}
+	return points
+}
+
+// periodStart is the first day of the period of bucket containing t, in UTC like the dates of the
+// analytics views
+func periodStart(t time.Time, bucket models.AnalyticsBucket) time.Time {
+	month := t.Month()
+	switch bucket {
+	case models.AnalyticsBucketQuarter:
+		month = month - (month-1)%3
+	case models.AnalyticsBucketYear:
+		month = time.January
+	}
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func nextPeriod(start time.Time, bucket models.AnalyticsBucket) time.Time {
+	switch bucket {
+	case models.AnalyticsBucketQuarter:
+		return start.AddDate(0, 3, 0)
+	case models.AnalyticsBucketYear:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// periodLabel names a period: 2026-03, 2026-Q1 or 2026
+func periodLabel(start time.Time, bucket models.AnalyticsBucket) string {
+	switch bucket {
+	case models.AnalyticsBucketQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case models.AnalyticsBucketYear:
+		return fmt.Sprintf("%d", start.Year())
+	}
+	return start.Format("2006-01")
+}
+
+// percentOf is part per 100 of whole, rounded to 2 decimals; 0 when whole is 0
+func percentOf(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(part/whole*10000) / 100
+}
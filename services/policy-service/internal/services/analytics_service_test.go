@@ -0,0 +1,76 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func analyticsMonth(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBucketActivityByMonth(t *testing.T) {
+	activity := []models.MonthlyActivity{
+		{Month: analyticsMonth(2026, 1), PremiumCollected: 1000, PayoutsPaid: 250, ClaimCount: 2, PoliciesRegistered: 5},
+		{Month: analyticsMonth(2026, 3), PremiumCollected: 400, ClaimCount: 1, RejectedClaimCount: 1},
+	}
+
+	points := bucketActivity(activity, models.AnalyticsBucketMonth, analyticsMonth(2026, 1), analyticsMonth(2026, 4))
+	assert.Len(t, points, 3)
+	assert.Equal(t, "2026-01", points[0].Label)
+	assert.Equal(t, 25.0, points[0].LossRatioPercent)
+	// A month without activity is still listed
+	assert.Equal(t, "2026-02", points[1].Label)
+	assert.Zero(t, points[1].PremiumCollected)
+	assert.Zero(t, points[1].LossRatioPercent)
+	assert.Equal(t, int64(1), points[2].RejectedClaimCount)
+}
+
+func TestBucketActivityByQuarterAndYear(t *testing.T) {
+	activity := []models.MonthlyActivity{
+		{Month: analyticsMonth(2025, 12), PremiumCollected: 100, PayoutsPaid: 300},
+		{Month: analyticsMonth(2026, 2), PremiumCollected: 200, PayoutsPaid: 50, ClaimCount: 1},
+		{Month: analyticsMonth(2026, 3), PremiumCollected: 300, PayoutsPaid: 50, ClaimCount: 2},
+		{Month: analyticsMonth(2026, 4), PremiumCollected: 500},
+	}
+
+	quarters := bucketActivity(activity, models.AnalyticsBucketQuarter, analyticsMonth(2025, 11), analyticsMonth(2026, 5))
+	assert.Len(t, quarters, 3)
+	assert.Equal(t, "2025-Q4", quarters[0].Label)
+	assert.Equal(t, analyticsMonth(2025, 10), quarters[0].PeriodStart)
+	assert.Equal(t, "2026-Q1", quarters[1].Label)
+	assert.Equal(t, 500.0, quarters[1].PremiumCollected)
+	assert.Equal(t, int64(3), quarters[1].ClaimCount)
+	assert.Equal(t, 20.0, quarters[1].LossRatioPercent)
+	assert.Equal(t, "2026-Q2", quarters[2].Label)
+
+	years := bucketActivity(activity, models.AnalyticsBucketYear, analyticsMonth(2026, 1), analyticsMonth(2027, 1))
+	assert.Len(t, years, 1)
+	assert.Equal(t, "2026", years[0].Label)
+	// December 2025 is outside the range
+	assert.Equal(t, 1000.0, years[0].PremiumCollected)
+}
+
+func TestValidateSeriesRequest(t *testing.T) {
+	req := models.AnalyticsSeriesRequest{Bucket: models.AnalyticsBucketMonth, From: analyticsMonth(2026, 1), To: analyticsMonth(2026, 7)}
+	assert.NoError(t, validateSeriesRequest(req))
+
+	req.Bucket = "week"
+	assert.ErrorContains(t, validateSeriesRequest(req), "invalid bucket")
+
+	req.Bucket = models.AnalyticsBucketYear
+	req.From, req.To = analyticsMonth(2026, 7), analyticsMonth(2026, 1)
+	assert.ErrorContains(t, validateSeriesRequest(req), "from must be before to")
+
+	req.From, req.To = analyticsMonth(2000, 1), analyticsMonth(2026, 1)
+	assert.ErrorContains(t, validateSeriesRequest(req), "at most 120 months")
+}
+
+func TestPercentOf(t *testing.T) {
+	assert.Equal(t, 33.33, percentOf(1, 3))
+	assert.Equal(t, 150.0, percentOf(3, 2))
+	assert.Zero(t, percentOf(5, 0))
+}
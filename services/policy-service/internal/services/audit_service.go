@@ -0,0 +1,144 @@
+package services
+
+import (
+	utils "agrisa_utils"
+	"agrisa_utils/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Fields every update touches; recording them would make each entry look like a change
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditService records who changed a policy or the crop of a farm and which fields changed
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record stores a change that is already committed. A failure is logged rather than returned so
+// the change is not reported as failed after it went through. before is nil for a create, after
+// is nil for a delete.
+func (s *AuditService) Record(ctx context.Context, entityType models.AuditEntityType, entityID uuid.UUID, action models.AuditAction, before, after any) {
+	if s == nil {
+		return
+	}
+	entry, err := newAuditLog(ctx, entityType, entityID, action, before, after)
+	if err == nil && entry != nil {
+		err = s.auditRepo.Create(context.WithoutCancel(ctx), entry)
+	}
+	if err != nil {
+		slog.Error("Failed to record audit log",
+			"entity_type", entityType,
+			"entity_id", entityID,
+			"action", action,
+			"error", err)
+	}
+}
+
+// RecordTx stores a change in the transaction making it, so neither is kept without the other
+func (s *AuditService) RecordTx(tx *sqlx.Tx, ctx context.Context, entityType models.AuditEntityType, entityID uuid.UUID, action models.AuditAction, before, after any) error {
+	if s == nil {
+		return nil
+	}
+	entry, err := newAuditLog(ctx, entityType, entityID, action, before, after)
+	if err != nil || entry == nil {
+		return err
+	}
+	return s.auditRepo.CreateTx(tx, ctx, entry)
+}
+
+func (s *AuditService) List(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLog, error) {
+	return s.auditRepo.List(ctx, filter)
+}
+
+// newAuditLog returns the entry for a change, or nil when the change left every field as it was
+func newAuditLog(ctx context.Context, entityType models.AuditEntityType, entityID uuid.UUID, action models.AuditAction, before, after any) (*models.AuditLog, error) {
+	changes, err := diffAuditStates(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	entry := &models.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		ActorID:    requestActor(ctx),
+		Changes:    changes,
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+	return entry, nil
+}
+
+// requestActor returns the user a change is made for, or the system actor for background work
+func requestActor(ctx context.Context) string {
+	if userID := logging.UserID(ctx); userID != "" {
+		return userID
+	}
+	return models.AuditActorSystem
+}
+
+// diffAuditStates compares the JSON form of two states field by field and returns the fields that
+// differ as {"field": {"before": ..., "after": ...}}
+func diffAuditStates(before, after any) (utils.JSONMap, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := utils.JSONMap{}
+	for field, value := range afterFields {
+		if old, ok := beforeFields[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = map[string]any{"before": beforeFields[field], "after": value}
+		}
+	}
+	for field, old := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			changes[field] = map[string]any{"before": old, "after": nil}
+		}
+	}
+	for field := range auditIgnoredFields {
+		delete(changes, field)
+	}
+	return changes, nil
+}
+
+func auditFields(state any) (map[string]any, error) {
+	fields := map[string]any{}
+	if state == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit state: %w", err)
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit state: %w", err)
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	return fields, nil
+}
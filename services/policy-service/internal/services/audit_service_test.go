@@ -0,0 +1,64 @@
+package services
+
+import (
+	"agrisa_utils/logging"
+	"context"
+	"policy-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAuditStates(t *testing.T) {
+	before := models.RegisteredPolicy{
+		Status:             models.PolicyPendingReview,
+		UnderwritingStatus: models.UnderwritingPending,
+		UpdatedAt:          time.Now().Add(-time.Hour),
+	}
+	after := before
+	after.Status = models.PolicyPendingPayment
+	after.UnderwritingStatus = models.UnderwritingApproved
+	after.UpdatedAt = time.Now()
+
+	changes, err := diffAuditStates(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"status":              map[string]any{"before": string(models.PolicyPendingReview), "after": string(models.PolicyPendingPayment)},
+		"underwriting_status": map[string]any{"before": string(models.UnderwritingPending), "after": string(models.UnderwritingApproved)},
+	}, map[string]any(changes))
+}
+
+func TestDiffAuditStates_CreateAndUnchanged(t *testing.T) {
+	changes, err := diffAuditStates(nil, map[string]any{"status": "active"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"before": nil, "after": "active"}, changes["status"])
+
+	changes, err = diffAuditStates(map[string]any{"status": "active"}, map[string]any{"status": "active"})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestNewAuditLog_Actor(t *testing.T) {
+	id := uuid.New()
+	before := map[string]any{"status": "active"}
+	after := map[string]any{"status": "archived"}
+
+	entry, err := newAuditLog(context.Background(), models.AuditEntityBasePolicy, id, models.AuditActionUpdate, before, after)
+	require.NoError(t, err)
+	assert.Equal(t, models.AuditActorSystem, entry.ActorID)
+	assert.Nil(t, entry.RequestID)
+
+	ctx := logging.WithRequestID(logging.WithUserID(context.Background(), "admin-1"), "req-1")
+	entry, err = newAuditLog(ctx, models.AuditEntityBasePolicy, id, models.AuditActionUpdate, before, after)
+	require.NoError(t, err)
+	assert.Equal(t, "admin-1", entry.ActorID)
+	require.NotNil(t, entry.RequestID)
+	assert.Equal(t, "req-1", *entry.RequestID)
+
+	entry, err = newAuditLog(ctx, models.AuditEntityBasePolicy, id, models.AuditActionUpdate, before, before)
+	require.NoError(t, err)
+	assert.Nil(t, entry, "a change leaving every field as it was is not recorded")
+}
@@ -3,7 +3,6 @@ package services
 import (
 	utils "agrisa_utils"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"policy-service/internal/ai/gemini"
@@ -30,9 +29,14 @@ type BasePolicyService struct {
 	notievent          *event.NotificationHelper
 	cancelRequestRepo  *repository.CancelRequestRepository
 	redisClient        *redis.Client
+	providerDirectory  *ProviderDirectory
+	auditService       *AuditService
+	versionRepo        *repository.BasePolicyVersionRepository
+	aiUsageService     *AIUsageService
+	draftRepo          *repository.BasePolicyDraftRepository
 }
 
-func NewBasePolicyService(basePolicyRepo *repository.BasePolicyRepository, dataSourceRepo *repository.DataSourceRepository, dataTierRepo *repository.DataTierRepository, minioClient *minio.MinioClient, geminiClients []gemini.GeminiClient, registerPolicyRepo *repository.RegisteredPolicyRepository, notievent *event.NotificationHelper, cancelRequestRepo *repository.CancelRequestRepository, redisClient *redis.Client) *BasePolicyService {
+func NewBasePolicyService(basePolicyRepo *repository.BasePolicyRepository, dataSourceRepo *repository.DataSourceRepository, dataTierRepo *repository.DataTierRepository, minioClient *minio.MinioClient, geminiClients []gemini.GeminiClient, registerPolicyRepo *repository.RegisteredPolicyRepository, notievent *event.NotificationHelper, cancelRequestRepo *repository.CancelRequestRepository, redisClient *redis.Client, providerDirectory *ProviderDirectory, auditService *AuditService, versionRepo *repository.BasePolicyVersionRepository, aiUsageService *AIUsageService, draftRepo *repository.BasePolicyDraftRepository) *BasePolicyService {
 	return &BasePolicyService{
 		basePolicyRepo:     basePolicyRepo,
 		dataSourceRepo:     dataSourceRepo,
@@ -43,6 +47,11 @@ func NewBasePolicyService(basePolicyRepo *repository.BasePolicyRepository, dataS
 		notievent:          notievent,
 		cancelRequestRepo:  cancelRequestRepo,
 		redisClient:        redisClient,
+		providerDirectory:  providerDirectory,
+		auditService:       auditService,
+		versionRepo:        versionRepo,
+		aiUsageService:     aiUsageService,
+		draftRepo:          draftRepo,
 	}
 }
 
@@ -207,6 +216,9 @@ func (s *BasePolicyService) validateBasePolicyTrigger(triggerGr *models.BasePoli
 	if !s.isValidMonitorFrequencyUnit(triggerGr.MonitorFrequencyUnit) {
 		return fmt.Errorf("invalid monitor frequency unit: %s", triggerGr.MonitorFrequencyUnit)
 	}
+	if err := validateBlackoutPeriods(triggerGr.BlackoutPeriods); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -415,6 +427,10 @@ func (s *BasePolicyService) CreateCompletePolicy(ctx context.Context, request *m
 	if res == "true" {
 		return nil, fmt.Errorf("profile is in deletion state")
 	}
+	if _, err := s.providerDirectory.VerifyActiveProvider(ctx, request.BasePolicy.InsuranceProviderID); err != nil {
+		slog.Error("insurance provider verification failed", "provider_id", request.BasePolicy.InsuranceProviderID, "error", err)
+		return nil, err
+	}
 
 	// Generate IDs and establish relationships
 	basePolicyID := uuid.New()
@@ -478,157 +494,15 @@ func (s *BasePolicyService) CreateCompletePolicy(ctx context.Context, request *m
 	request.BasePolicy.Status = models.BasePolicyDraft
 	request.BasePolicy.DocumentValidationStatus = models.ValidationPending
 
-	// Begin Redis transaction
-	slog.Info("Starting Redis transaction for complete policy creation",
-		"base_policy_id", basePolicyID,
-		"trigger_id", triggerID,
-		"provider_id", request.BasePolicy.InsuranceProviderID)
-
-	tx := s.basePolicyRepo.BeginRedisTransaction()
-	shouldCommit := false
-
-	defer func() {
-		if shouldCommit {
-			slog.Info("Executing Redis transaction commit",
-				"base_policy_id", basePolicyID,
-				"provider_id", request.BasePolicy.InsuranceProviderID)
-			_, err := tx.Exec(ctx)
-			if err != nil {
-				slog.Error("Redis transaction commit failed",
-					"base_policy_id", basePolicyID,
-					"provider_id", request.BasePolicy.InsuranceProviderID,
-					"error", err)
-			} else {
-				slog.Info("Redis transaction committed successfully",
-					"base_policy_id", basePolicyID,
-					"provider_id", request.BasePolicy.InsuranceProviderID)
-			}
-		} else {
-			slog.Info("Discarding Redis transaction due to error",
-				"base_policy_id", basePolicyID,
-				"provider_id", request.BasePolicy.InsuranceProviderID)
-			tx.Discard()
-		}
-	}()
-
-	if request.PolicyDocument.Data != "" && request.PolicyDocument.Name != "" {
-		//// upload policy document to Minio
-		//files := minio.FileUploadRequest{
-		//	minio.FileUpload{
-		//		FieldName: "template_document_url",
-		//		FileName:  request.PolicyDocument.Name,
-		//		Data:      request.PolicyDocument.Data,
-		//	},
-		//}
-
-		// allowedExts := []string{}
-
-		//processedInfo, err := s.minioClient.FileProcessing(files, ctx, allowedExts, 50)
-		//if err != nil {
-		//	slog.Error("File processing failed",
-		//		"base_policy_id", basePolicyID,
-		//		"error", err)
-		//	return nil, fmt.Errorf("file processing failed: %w", err)
-		//}
-		//slog.Info("file upload information", "info", processedInfo)
-
+	if request.PolicyDocument.ObjectKey != "" && request.PolicyDocument.Name != "" {
 		templatePath := request.PolicyDocument.Name + "-" + basePolicyID.String()
 		request.BasePolicy.TemplateDocumentURL = &templatePath
 	}
 
-	// Serialize and store BasePolicy
-	slog.Info("Serializing base policy",
-		"base_policy_id", basePolicyID,
-		"product_name", request.BasePolicy.ProductName,
-		"crop_type", request.BasePolicy.CropType)
-
-	basePolicyByte, err := utils.SerializeModel(request.BasePolicy)
-	if err != nil {
-		slog.Error("Base policy serialization failed",
-			"base_policy_id", basePolicyID,
-			"provider_id", request.BasePolicy.InsuranceProviderID,
-			"error", err)
-		return nil, fmt.Errorf("base policy serialization failed: %w", err)
-	}
-
-	basePolicyKey := fmt.Sprintf("%s--%s--BasePolicy--archive:%v", request.BasePolicy.InsuranceProviderID, basePolicyID, request.IsArchive)
-	slog.Info("Storing base policy in Redis transaction",
-		"base_policy_id", basePolicyID,
-		"key", basePolicyKey,
-		"data_size_bytes", len(basePolicyByte),
-		"expiration", expiration)
-
-	if err := s.basePolicyRepo.CreateTempBasePolicyModelsWTransaction(ctx, basePolicyByte, basePolicyKey, tx, expiration); err != nil {
-		slog.Error("Base policy storage in transaction failed",
-			"base_policy_id", basePolicyID,
-			"key", basePolicyKey,
-			"error", err)
-		return nil, fmt.Errorf("base policy creation failed: %w", err)
-	}
-
-	// Serialize and store BasePolicyTrigger
-	slog.Info("Serializing base policy trigger",
-		"trigger_id", triggerID,
-		"base_policy_id", basePolicyID,
-		"logical_operator", request.Trigger.LogicalOperator,
-		"monitor_frequency", request.Trigger.MonitorInterval)
-
-	basePolicyTriggerByte, err := utils.SerializeModel(request.Trigger)
-	if err != nil {
-		slog.Error("Base policy trigger serialization failed",
-			"trigger_id", triggerID,
-			"base_policy_id", basePolicyID,
-			"error", err)
-		return nil, fmt.Errorf("base policy trigger serialization failed: %w", err)
-	}
-
-	triggerKey := fmt.Sprintf("%s--%s--BasePolicyTrigger--%s--archive:%v", request.BasePolicy.InsuranceProviderID, triggerID, basePolicyID, request.IsArchive)
-	slog.Info("Storing base policy trigger in Redis transaction",
-		"trigger_id", triggerID,
-		"key", triggerKey,
-		"data_size_bytes", len(basePolicyTriggerByte),
-		"expiration", expiration)
-
-	if err := s.basePolicyRepo.CreateTempBasePolicyModelsWTransaction(ctx, basePolicyTriggerByte, triggerKey, tx, expiration); err != nil {
-		slog.Error("Base policy trigger storage in transaction failed",
-			"trigger_id", triggerID,
-			"key", triggerKey,
-			"error", err)
-		return nil, fmt.Errorf("base policy trigger creation failed: %w", err)
-	}
-
-	// Serialize and store each condition in transaction
-	slog.Info("Creating conditions in transaction", "condition_count", len(request.Conditions))
-	for i, condition := range request.Conditions {
-		conditionByte, err := utils.SerializeModel(condition)
-		if err != nil {
-			slog.Error("Failed to serialize condition",
-				"condition_id", condition.ID,
-				"condition_index", i+1,
-				"error", err)
-			return nil, fmt.Errorf("condition %d serialization failed: %w", i+1, err)
-		}
-
-		conditionKey := fmt.Sprintf("%s--%s--BasePolicyTriggerCondition--%d--%s--archive:%v", request.BasePolicy.InsuranceProviderID, condition.ID, i+1, basePolicyID, request.IsArchive)
-		if err := s.basePolicyRepo.CreateTempBasePolicyModelsWTransaction(ctx, conditionByte, conditionKey, tx, expiration); err != nil {
-			slog.Error("Failed to store condition in transaction",
-				"condition_id", condition.ID,
-				"condition_index", i+1,
-				"error", err)
-			return nil, fmt.Errorf("condition %d storage failed: %w", i+1, err)
-		}
-
-		slog.Info("Condition stored in transaction",
-			"condition_id", condition.ID,
-			"condition_index", i+1,
-			"key", conditionKey)
-	}
-
 	// Calculate total cost
 	slog.Info("Calculating total cost", "base_policy_id", basePolicyID)
 	totalCost := s.CalculateBasePolicyTotalCost(request.Conditions)
 
-	// Create and store response metadata in transaction
 	response := &models.CompletePolicyCreationResponse{
 		BasePolicyID:    basePolicyID,
 		TriggerID:       triggerID,
@@ -639,29 +513,43 @@ func (s *BasePolicyService) CreateCompletePolicy(ctx context.Context, request *m
 		CreatedAt:       time.Now(),
 	}
 
-	responseByte, err := utils.SerializeModel(response)
-	if err != nil {
-		slog.Error("Failed to serialize response metadata",
+	// Stage the policy as a draft until its document is validated
+	draft := &models.DraftPolicy{
+		ID:                  basePolicyID,
+		InsuranceProviderID: request.BasePolicy.InsuranceProviderID,
+		AutoCommit:          request.IsArchive,
+		Content: models.DraftContent{
+			BasePolicy:       request.BasePolicy,
+			Trigger:          request.Trigger,
+			Conditions:       request.Conditions,
+			CreationResponse: response,
+		},
+		ExpiresAt: validationWindowTime,
+	}
+
+	slog.Info("Storing base policy draft",
+		"base_policy_id", basePolicyID,
+		"trigger_id", triggerID,
+		"provider_id", request.BasePolicy.InsuranceProviderID,
+		"auto_commit", request.IsArchive,
+		"expiration", expiration)
+
+	if _, err := s.draftRepo.Create(ctx, draft); err != nil {
+		slog.Error("Base policy draft storage failed",
 			"base_policy_id", basePolicyID,
+			"provider_id", request.BasePolicy.InsuranceProviderID,
 			"error", err)
-		return nil, fmt.Errorf("response metadata serialization failed: %w", err)
+		return nil, fmt.Errorf("base policy creation failed: %w", err)
 	}
 
-	responseKey := fmt.Sprintf("%s--%s--CompletePolicyResponse", request.BasePolicy.InsuranceProviderID, basePolicyID)
-	if err := s.basePolicyRepo.CreateTempBasePolicyModelsWTransaction(ctx, responseByte, responseKey, tx, expiration); err != nil {
-		slog.Error("Failed to store response metadata in transaction",
+	// The expiration listener commits or expires the draft once this key expires; without it the
+	// sweeper does, from the expires_at of the draft
+	if err := s.draftRepo.ScheduleExpiry(ctx, basePolicyID, expiration); err != nil {
+		slog.Error("error scheduling base policy draft expiry",
 			"base_policy_id", basePolicyID,
 			"error", err)
-		return nil, fmt.Errorf("response metadata storage failed: %w", err)
 	}
 
-	slog.Info("Response metadata stored in transaction",
-		"base_policy_id", basePolicyID,
-		"key", responseKey)
-
-	// Mark transaction for commit
-	shouldCommit = true
-
 	slog.Info("Successfully created complete policy",
 		"base_policy_id", basePolicyID,
 		"trigger_id", triggerID,
@@ -680,6 +568,9 @@ func (s *BasePolicyService) CalculateBasePolicyTotalCost(datas []*models.BasePol
 	return total_cost
 }
 
+// GetAllDraftPolicyWFilter lists the pending drafts of a provider, of a base policy ID or with an
+// archive status: "true" or "archived" for drafts committed when their validation window ends,
+// "false" or "active" for the others
 func (s *BasePolicyService) GetAllDraftPolicyWFilter(ctx context.Context, providerID, basePolicyID, archiveStatus string) ([]*models.CompletePolicyData, error) {
 	slog.Info("Getting draft policies from provider",
 		"provider_id", providerID,
@@ -691,118 +582,24 @@ func (s *BasePolicyService) GetAllDraftPolicyWFilter(ctx context.Context, provid
 	if providerID == "" && basePolicyID == "" && archiveStatus == "" {
 		return nil, fmt.Errorf("at least one search parameter is required")
 	}
-
-	// Build flexible pattern with wildcards
-	provider := providerID
-	if provider == "" {
-		provider = "*"
-	}
-
-	policy := basePolicyID
-	if policy == "" {
-		policy = "*"
-	}
-
-	archive := archiveStatus
-	if archive == "" {
-		archive = "*"
+	filter, err := draftFilterOf(providerID, basePolicyID, archiveStatus)
+	if err != nil {
+		return nil, err
 	}
 
-	// Build Redis key pattern for specific policy
-	policyPattern := fmt.Sprintf("%s--%s--BasePolicy--archive:%s", provider, policy, archive)
-	slog.Info("Pattern DEBUG", "pattern", policyPattern)
-	policyKeys, err := s.basePolicyRepo.FindKeysByPattern(ctx, policyPattern, "")
+	drafts, err := s.draftRepo.ListPending(ctx, filter)
 	if err != nil {
-		slog.Error("Failed to find policy keys",
+		slog.Error("Failed to list draft policies",
 			"provider_id", providerID,
 			"base_policy_id", basePolicyID,
 			"archive_status", archiveStatus,
-			"pattern", policyPattern,
 			"error", err)
 		return nil, fmt.Errorf("error getting policy %s from provider %s with archive status %s: %w", basePolicyID, providerID, archiveStatus, err)
 	}
-	slog.Info("Key founds", "keys", policyKeys)
 
-	// Check if policy was found
-	if len(policyKeys) == 0 {
-		slog.Info("No policy found with given parameters",
-			"provider_id", providerID,
-			"base_policy_id", basePolicyID,
-			"archive_status", archiveStatus)
-		return []*models.CompletePolicyData{}, nil
-	}
-
-	var completePolicies []*models.CompletePolicyData
-
-	for _, key := range policyKeys {
-		// Get base policy
-		basePolicyByte, err := s.basePolicyRepo.GetTempBasePolicyModels(ctx, key)
-		if err != nil {
-			slog.Info("Failed to get base policy data", "key", key, "error", err)
-			continue
-		}
-
-		var basePolicy models.BasePolicy
-		if err := utils.DeserializeModel(basePolicyByte, &basePolicy); err != nil {
-			slog.Info("Failed to deserialize base policy", "key", key, "error", err)
-			continue
-		}
-
-		// Filter for draft policies only
-		if basePolicy.Status != models.BasePolicyDraft {
-			continue
-		}
-
-		completePolicy := &models.CompletePolicyData{
-			BasePolicy: &basePolicy,
-		}
-
-		// Get trigger for this policy
-		triggerPattern := fmt.Sprintf("%s--*--BasePolicyTrigger--%s--archive:%s", provider, basePolicy.ID, archive)
-		triggerKeys, err := s.basePolicyRepo.FindKeysByPattern(ctx, triggerPattern, "")
-		if err == nil && len(triggerKeys) > 0 {
-			triggerByte, err := s.basePolicyRepo.GetTempBasePolicyModels(ctx, triggerKeys[0])
-			if err == nil {
-				var trigger models.BasePolicyTrigger
-				if err := utils.DeserializeModel(triggerByte, &trigger); err == nil {
-					completePolicy.Trigger = &trigger
-				}
-			}
-		}
-
-		// Get conditions for this policy
-		conditionPattern := fmt.Sprintf("%s--*--BasePolicyTriggerCondition--*--%s--archive:%s", provider, basePolicy.ID, archive)
-		conditionKeys, err := s.basePolicyRepo.FindKeysByPattern(ctx, conditionPattern, "")
-		if err == nil && len(conditionKeys) > 0 {
-			var conditions []*models.BasePolicyTriggerCondition
-			for _, condKey := range conditionKeys {
-				conditionByte, err := s.basePolicyRepo.GetTempBasePolicyModels(ctx, condKey)
-				if err != nil {
-					continue
-				}
-				var condition models.BasePolicyTriggerCondition
-				if err := utils.DeserializeModel(conditionByte, &condition); err == nil {
-					conditions = append(conditions, &condition)
-				}
-			}
-			completePolicy.Conditions = conditions
-		}
-
-		// Get validations for this policy
-		validations, err := s.basePolicyRepo.GetValidationsFromRedis(ctx, basePolicy.ID)
-		if err != nil {
-			slog.Warn("Failed to get validations from Redis",
-				"base_policy_id", basePolicy.ID,
-				"error", err)
-			// Non-critical: continue without validations
-		} else if len(validations) > 0 {
-			completePolicy.Validations = validations
-			slog.Info("Retrieved validations for policy",
-				"base_policy_id", basePolicy.ID,
-				"validation_count", len(validations))
-		}
-
-		completePolicies = append(completePolicies, completePolicy)
+	completePolicies := make([]*models.CompletePolicyData, 0, len(drafts))
+	for i := range drafts {
+		completePolicies = append(completePolicies, drafts[i].CompletePolicy())
 	}
 
 	slog.Info("Successfully retrieved policy data",
@@ -815,6 +612,32 @@ func (s *BasePolicyService) GetAllDraftPolicyWFilter(ctx context.Context, provid
 	return completePolicies, nil
 }
 
+// draftFilterOf builds the filter of the pending drafts GetAllDraftPolicyWFilter lists
+func draftFilterOf(providerID, basePolicyID, archiveStatus string) (models.DraftFilter, error) {
+	filter := models.DraftFilter{InsuranceProviderID: providerID}
+	if basePolicyID != "" {
+		id, err := uuid.Parse(basePolicyID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid base_policy_id: %w", err)
+		}
+		filter.ID = &id
+	}
+
+	var autoCommit bool
+	switch archiveStatus {
+	case "":
+		return filter, nil
+	case "true", "archived":
+		autoCommit = true
+	case "false", "active":
+		autoCommit = false
+	default:
+		return filter, fmt.Errorf("invalid archive_status: %s", archiveStatus)
+	}
+	filter.AutoCommit = &autoCommit
+	return filter, nil
+}
+
 // UpdateBasePolicyValidationStatus updates the document validation status of a base policy
 func (s *BasePolicyService) UpdateBasePolicyValidationStatus(ctx context.Context, basePolicyID uuid.UUID, validationStatus models.ValidationStatus, validationScore *float64) error {
 	slog.Info("Updating base policy document validation status",
@@ -840,6 +663,7 @@ func (s *BasePolicyService) UpdateBasePolicyValidationStatus(ctx context.Context
 	}
 
 	// Update the validation fields
+	before := *basePolicy
 	oldStatus := basePolicy.DocumentValidationStatus
 
 	basePolicy.DocumentValidationStatus = validationStatus
@@ -859,6 +683,7 @@ func (s *BasePolicyService) UpdateBasePolicyValidationStatus(ctx context.Context
 			"error", err)
 		return fmt.Errorf("failed to update base policy: %w", err)
 	}
+	s.auditService.Record(ctx, models.AuditEntityBasePolicy, basePolicyID, models.AuditActionUpdate, before, basePolicy)
 
 	slog.Info("Successfully updated base policy document validation status",
 		"base_policy_id", basePolicyID,
@@ -869,14 +694,13 @@ func (s *BasePolicyService) UpdateBasePolicyValidationStatus(ctx context.Context
 	return nil
 }
 
-// CommitPolicies transfers temporary policy data from Redis to PostgreSQL database
+// CommitPolicies writes the pending drafts matching request to base_policy and marks them committed
 func (s *BasePolicyService) CommitPolicies(ctx context.Context, request *models.CommitPoliciesRequest) (*models.CommitPoliciesResponse, error) {
 	slog.Info("Starting policy commit operation",
 		"provider_id", request.ProviderID,
 		"base_policy_id", request.BasePolicyID,
 		"archive_status", request.ArchiveStatus,
 		"validate_only", request.ValidateOnly,
-		"delete_from_redis", request.DeleteFromRedis,
 		"batch_size", request.BatchSize)
 	start := time.Now()
 
@@ -898,11 +722,11 @@ func (s *BasePolicyService) CommitPolicies(ctx context.Context, request *models.
 		OperationTimestamp: time.Now(),
 	}
 
-	// Phase 1: Discovery - Find policies from Redis
-	slog.Info("Phase 1: Discovering policies from Redis")
+	// Phase 1: Discovery - Find pending drafts
+	slog.Info("Phase 1: Discovering draft policies")
 	completePolicies, err := s.GetAllDraftPolicyWFilter(ctx, request.ProviderID, request.BasePolicyID, request.ArchiveStatus)
 	if err != nil {
-		slog.Error("Failed to discover policies from Redis", "error", err)
+		slog.Error("Failed to discover draft policies", "error", err)
 		return nil, fmt.Errorf("failed to discover policies: %w", err)
 	}
 
@@ -1009,7 +833,9 @@ func (s *BasePolicyService) CommitPolicies(ctx context.Context, request *models.
 				}
 			} else {
 				// Mark all policies in this batch as successfully committed
+				committedIDs := make([]uuid.UUID, 0, len(batch))
 				for _, policy := range batch {
+					committedIDs = append(committedIDs, policy.BasePolicy.ID)
 					conditionCount := 0
 					if policy.Conditions != nil {
 						conditionCount = len(policy.Conditions)
@@ -1049,6 +875,10 @@ func (s *BasePolicyService) CommitPolicies(ctx context.Context, request *models.
 
 				}
 
+				// The drafts are committed, their validation window no longer matters
+				s.draftRepo.EvictCache(ctx, committedIDs...)
+				s.draftRepo.CancelExpiry(ctx, committedIDs...)
+
 				slog.Info("Batch committed successfully",
 					"batch_size", len(batch),
 					"batch_number", (i/batchSize)+1)
@@ -1061,18 +891,6 @@ func (s *BasePolicyService) CommitPolicies(ctx context.Context, request *models.
 		}
 	}
 
-	// Phase 4: Cleanup (Optional Redis cleanup)
-	if request.DeleteFromRedis && response.TotalCommitted > 0 {
-		slog.Info("Phase 4: Cleaning up Redis data")
-		if err := s.cleanupCommittedPoliciesFromRedis(ctx, response.CommittedPolicies); err != nil {
-			slog.Warn("Failed to cleanup Redis data", "error", err)
-			// Not a critical failure, just log and continue
-		} else {
-			slog.Info("Redis cleanup completed successfully",
-				"cleaned_policies", response.TotalCommitted)
-		}
-	}
-
 	response.ProcessingDuration = time.Since(start)
 
 	slog.Info("Policy commit operation completed",
@@ -1090,26 +908,31 @@ func (s *BasePolicyService) commitSinglePolicyInTransaction(ctx context.Context,
 		"base_policy_id", policy.BasePolicy.ID,
 		"product_name", policy.BasePolicy.ProductName)
 
-	// 1. Insert BasePolicy
+	// 1. Take the draft, failing if another commit took it first
+	if err := s.draftRepo.MarkCommittedTx(tx, ctx, policy.BasePolicy.ID); err != nil {
+		return err
+	}
+
+	// 2. Insert BasePolicy
 	if err := s.basePolicyRepo.CreateBasePolicyTx(tx, policy.BasePolicy); err != nil {
 		return fmt.Errorf("failed to insert base policy: %w", err)
 	}
 
-	// 2. Insert BasePolicyTrigger if present
+	// 3. Insert BasePolicyTrigger if present
 	if policy.Trigger != nil {
 		if err := s.basePolicyRepo.CreateBasePolicyTriggerTx(tx, policy.Trigger); err != nil {
 			return fmt.Errorf("failed to insert base policy trigger: %w", err)
 		}
 	}
 
-	// 3. Insert BasePolicyTriggerConditions if present
+	// 4. Insert BasePolicyTriggerConditions if present
 	if len(policy.Conditions) > 0 {
 		if err := s.basePolicyRepo.CreateBasePolicyTriggerConditionsBatchTx(tx, policy.Conditions); err != nil {
 			return fmt.Errorf("failed to insert base policy trigger conditions: %w", err)
 		}
 	}
 
-	// 4. Insert BasePolicyDocumentValidations if present
+	// 5. Insert BasePolicyDocumentValidations if present
 	if len(policy.Validations) > 0 {
 		slog.Info("Committing validations to database",
 			"base_policy_id", policy.BasePolicy.ID,
@@ -1127,6 +950,16 @@ func (s *BasePolicyService) commitSinglePolicyInTransaction(ctx context.Context,
 			"validation_count", len(policy.Validations))
 	}
 
+	// 6. Record the creation with the policy it committed
+	if err := s.auditService.RecordTx(tx, ctx, models.AuditEntityBasePolicy, policy.BasePolicy.ID, models.AuditActionCreate, nil, policy); err != nil {
+		return fmt.Errorf("failed to record base policy creation: %w", err)
+	}
+
+	// 7. Keep the committed terms as version 1
+	if _, err := s.RecordPolicyVersionTx(tx, ctx, policy.BasePolicy.ID); err != nil {
+		return fmt.Errorf("failed to record base policy version: %w", err)
+	}
+
 	slog.Info("Policy committed successfully",
 		"base_policy_id", policy.BasePolicy.ID,
 		"trigger_present", policy.Trigger != nil,
@@ -1136,48 +969,6 @@ func (s *BasePolicyService) commitSinglePolicyInTransaction(ctx context.Context,
 	return nil
 }
 
-// cleanupCommittedPoliciesFromRedis removes successfully committed policies from Redis
-func (s *BasePolicyService) cleanupCommittedPoliciesFromRedis(ctx context.Context, committedPolicies []models.CommittedPolicyInfo) error {
-	slog.Info("Starting Redis cleanup", "policy_count", len(committedPolicies))
-
-	for _, policy := range committedPolicies {
-		// Find and delete all Redis keys for this policy
-		patterns := []string{
-			fmt.Sprintf("*--%s--BasePolicy--*", policy.BasePolicyID),
-			fmt.Sprintf("*--%s--BasePolicyTrigger--*", policy.TriggerID),
-			fmt.Sprintf("*--*--BasePolicyTriggerCondition--*--%s--*", policy.BasePolicyID),
-			fmt.Sprintf("*--%s--CompletePolicyResponse", policy.BasePolicyID),
-			fmt.Sprintf("%s--BasePolicyDocumentValidation--*", policy.BasePolicyID),
-		}
-
-		for _, pattern := range patterns {
-			keys, err := s.basePolicyRepo.FindKeysByPattern(ctx, pattern, "")
-			if err != nil {
-				slog.Warn("Failed to find keys for cleanup",
-					"pattern", pattern,
-					"base_policy_id", policy.BasePolicyID,
-					"error", err)
-				continue
-			}
-
-			for _, key := range keys {
-				if err := s.basePolicyRepo.DeleteTempBasePolicyModel(ctx, key); err != nil {
-					slog.Warn("Failed to delete Redis key",
-						"key", key,
-						"base_policy_id", policy.BasePolicyID,
-						"error", err)
-				}
-			}
-		}
-
-		slog.Info("Policy cleanup completed",
-			"base_policy_id", policy.BasePolicyID,
-			"patterns_processed", len(patterns))
-	}
-
-	return nil
-}
-
 func (s *BasePolicyService) GetActivePolicies(ctx context.Context) ([]models.BasePolicy, error) {
 	return s.basePolicyRepo.GetBasePoliciesByStatus(models.BasePolicyActive)
 }
@@ -1186,26 +977,17 @@ func (s *BasePolicyService) GetPaymentDuePolicies(ctx context.Context) ([]models
 	return s.basePolicyRepo.GetBasePoliciesByStatus(models.BasePolicyPaymentDue)
 }
 
-func (s *BasePolicyService) GetAllPolicyCreationResponse(ctx context.Context) (any, error) {
-	keyParttern := "*--*--CompletePolicyResponse"
-	keys, err := s.basePolicyRepo.FindKeysByPattern(ctx, keyParttern, "")
+// GetAllPolicyCreationResponse returns what the creation of each pending draft responded
+func (s *BasePolicyService) GetAllPolicyCreationResponse(ctx context.Context) ([]*models.CompletePolicyCreationResponse, error) {
+	drafts, err := s.draftRepo.ListPending(ctx, models.DraftFilter{})
 	if err != nil {
 		return nil, err
 	}
-	res := []map[string]any{}
-	for _, key := range keys {
-		completeResponseData, err := s.basePolicyRepo.GetTempBasePolicyModels(ctx, key)
-		if err != nil {
-			slog.Error("complete response data retrive failed", "error", err)
-			continue
-		}
-		jsonFormat := make(map[string]any)
-		err = json.Unmarshal(completeResponseData, &jsonFormat)
-		if err != nil {
-			slog.Error("complete response data retrive failed", "error", err)
-			continue
+	res := make([]*models.CompletePolicyCreationResponse, 0, len(drafts))
+	for _, draft := range drafts {
+		if draft.Content.CreationResponse != nil {
+			res = append(res, draft.Content.CreationResponse)
 		}
-		res = append(res, jsonFormat)
 	}
 	return res, nil
 }
@@ -1214,7 +996,22 @@ func (s *BasePolicyService) GetAllPolicyCreationResponse(ctx context.Context) (a
 // COMPLETE POLICY DETAIL SERVICE METHODS
 // ============================================================================
 
-// GetCompletePolicyDetail retrieves complete policy details with document
+// AttachInsuranceProvider adds the provider of the base policy to a policy detail. A failed
+// lookup is only logged, the detail is still usable without it.
+func (s *BasePolicyService) AttachInsuranceProvider(ctx context.Context, detail *models.CompletePolicyDetailResponse) {
+	provider, err := s.providerDirectory.GetInsuranceProvider(ctx, detail.BasePolicy.InsuranceProviderID)
+	if err != nil {
+		slog.Warn("failed to get insurance provider for base policy detail",
+			"base_policy_id", detail.BasePolicy.ID,
+			"provider_id", detail.BasePolicy.InsuranceProviderID,
+			"error", err)
+		return
+	}
+	detail.InsuranceProvider = provider
+}
+
+// GetCompletePolicyDetail retrieves complete policy details with document. Details looked up by
+// ID are served from the cache unless filter.BypassCache is set.
 func (s *BasePolicyService) GetCompletePolicyDetail(
 	ctx context.Context,
 	filter models.PolicyDetailFilterRequest,
@@ -1223,10 +1020,21 @@ func (s *BasePolicyService) GetCompletePolicyDetail(
 		"id", filter.ID,
 		"provider_id", filter.ProviderID,
 		"crop_type", filter.CropType,
-		"status", filter.Status)
+		"status", filter.Status,
+		"bypass_cache", filter.BypassCache)
 
 	start := time.Now()
 
+	if filter.ID != nil && !filter.BypassCache {
+		cached, ok := s.basePolicyRepo.GetCachedPolicyDetail(ctx, *filter.ID)
+		if ok && cachedDetailMatches(cached, filter) {
+			slog.Info("Serving cached policy detail",
+				"policy_id", cached.BasePolicy.ID,
+				"duration", time.Since(start))
+			return s.servePolicyDetail(ctx, cached, filter), nil
+		}
+	}
+
 	// Step 1: Get base policy and triggers
 	basePolicy, triggers, err := s.basePolicyRepo.GetCompletePolicyByFilter(ctx, filter)
 	if err != nil {
@@ -1263,42 +1071,64 @@ func (s *BasePolicyService) GetCompletePolicyDetail(
 		RetrievedAt:     time.Now(),
 	}
 
-	// Step 3: Get document info from MinIO
-	var documentInfo *models.PolicyDocumentInfo
-	if filter.IncludePDF {
-		documentInfo = s.getDocumentInfo(ctx, basePolicy, filter.PDFExpiryHours)
-	}
-
+	// Step 3: Get document info from MinIO; the presigned URL is added per request
 	response := &models.CompletePolicyDetailResponse{
 		BasePolicy: *basePolicy,
 		Triggers:   triggers,
-		Document:   documentInfo,
+		Document:   s.getDocumentInfo(ctx, basePolicy),
 		Metadata:   metadata,
 	}
 
+	// A failed MinIO lookup is retried by the next request rather than cached
+	if response.Document.Error == nil {
+		s.basePolicyRepo.CachePolicyDetail(ctx, response)
+	}
+
 	slog.Info("Successfully retrieved complete policy detail",
 		"policy_id", basePolicy.ID,
 		"triggers", metadata.TotalTriggers,
 		"conditions", metadata.TotalConditions,
 		"duration", time.Since(start))
 
-	return response, nil
+	return s.servePolicyDetail(ctx, response, filter), nil
 }
 
-// getDocumentInfo retrieves document metadata and presigned URL from MinIO
+// cachedDetailMatches checks a cached detail against the filters given along with the ID
+func cachedDetailMatches(detail *models.CompletePolicyDetailResponse, filter models.PolicyDetailFilterRequest) bool {
+	policy := detail.BasePolicy
+	return (filter.ProviderID == "" || policy.InsuranceProviderID == filter.ProviderID) &&
+		(filter.CropType == "" || policy.CropType == filter.CropType) &&
+		(filter.Status == "" || policy.Status == filter.Status)
+}
+
+// servePolicyDetail copies a detail for one request: the document is dropped unless the PDF was
+// asked for, otherwise a presigned URL with the requested expiry is added to it
+func (s *BasePolicyService) servePolicyDetail(
+	ctx context.Context,
+	detail *models.CompletePolicyDetailResponse,
+	filter models.PolicyDetailFilterRequest,
+) *models.CompletePolicyDetailResponse {
+	served := *detail
+	if !filter.IncludePDF || detail.Document == nil {
+		served.Document = nil
+		return &served
+	}
+
+	docInfo := *detail.Document
+	s.presignDocument(ctx, &docInfo, filter.PDFExpiryHours)
+	served.Document = &docInfo
+	return &served
+}
+
+// getDocumentInfo retrieves document metadata from MinIO
 func (s *BasePolicyService) getDocumentInfo(
 	ctx context.Context,
 	policy *models.BasePolicy,
-	expiryHours int,
 ) *models.PolicyDocumentInfo {
 	docInfo := &models.PolicyDocumentInfo{
 		HasDocument: false,
 	}
 
-	if expiryHours <= 0 {
-		expiryHours = 1 // Default to 1 hour minimum
-	}
-
 	// Check if template document URL exists
 	if policy.TemplateDocumentURL == nil || *policy.TemplateDocumentURL == "" {
 		slog.Info("No template document URL found for policy",
@@ -1367,30 +1197,40 @@ func (s *BasePolicyService) getDocumentInfo(
 	docInfo.ContentType = objInfo.ContentType
 	docInfo.FileSizeBytes = objInfo.Size
 
+	slog.Info("Successfully retrieved document info",
+		"policy_id", policy.ID,
+		"object", objectName,
+		"size_bytes", docInfo.FileSizeBytes)
+
+	return docInfo
+}
+
+// presignDocument adds a presigned URL to a document found in MinIO
+func (s *BasePolicyService) presignDocument(ctx context.Context, docInfo *models.PolicyDocumentInfo, expiryHours int) {
+	if !docInfo.HasDocument || docInfo.Error != nil || s.minioClient == nil {
+		return
+	}
+
+	if expiryHours <= 0 {
+		expiryHours = 1 // Default to 1 hour minimum
+	}
+
 	// Generate presigned URL
 	expiry := time.Duration(expiryHours) * time.Hour
-	presignedURL, err := s.minioClient.GetPresignedURL(ctx, docInfo.BucketName, objectName, expiry)
+	presignedURL, err := s.minioClient.GetPresignedURL(ctx, docInfo.BucketName, docInfo.ObjectName, expiry)
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to generate presigned URL: %v", err)
 		docInfo.Error = &errMsg
 		slog.Error("MinIO presigned URL generation failed",
 			"bucket", docInfo.BucketName,
-			"object", objectName,
+			"object", docInfo.ObjectName,
 			"error", err)
-		return docInfo
+		return
 	}
 
 	docInfo.PresignedURL = &presignedURL
 	expiryTime := time.Now().Add(expiry)
 	docInfo.PresignedExpiry = &expiryTime
-
-	slog.Info("Successfully generated document info",
-		"policy_id", policy.ID,
-		"object", objectName,
-		"size_bytes", docInfo.FileSizeBytes,
-		"expiry_hours", expiryHours)
-
-	return docInfo
 }
 
 // extractObjectNameFromURL extracts object name from MinIO URL
@@ -1483,6 +1323,7 @@ func (s *BasePolicyService) CancelBasePolicy(ctx context.Context, basePolicyID u
 		}
 	}
 
+	before := *basePolicy
 	basePolicy.Status = models.BasePolicyArchived
 	err = s.basePolicyRepo.UpdateBasePolicyTx(tx, basePolicy)
 	if err != nil {
@@ -1490,12 +1331,19 @@ func (s *BasePolicyService) CancelBasePolicy(ctx context.Context, basePolicyID u
 		slog.Error("error updating base policy", "error", err)
 		return "", err
 	}
+	err = s.auditService.RecordTx(tx, ctx, models.AuditEntityBasePolicy, basePolicyID, models.AuditActionUpdate, before, basePolicy)
+	if err != nil {
+		tx.Rollback()
+		slog.Error("error recording base policy cancellation", "error", err)
+		return "", err
+	}
 
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
 		slog.Error("error committing", "error", err)
 		return "", err
 	}
+	s.basePolicyRepo.InvalidatePolicyDetailCache(ctx, basePolicyID)
 
 	if !isKeep {
 		go func() {
@@ -1522,7 +1370,12 @@ func (s *BasePolicyService) UpdateBasePolicyStatus(ctx context.Context, basePoli
 	if basePolicy.Status != models.BasePolicyActive {
 		return fmt.Errorf("base policy status is not active, current status=%s", basePolicy.Status)
 	}
-	return s.basePolicyRepo.UpdateStatus(basePolicyID, status)
+	if err := s.basePolicyRepo.UpdateStatus(basePolicyID, status); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, models.AuditEntityBasePolicy, basePolicyID, models.AuditActionUpdate,
+		map[string]any{"status": basePolicy.Status}, map[string]any{"status": status})
+	return nil
 }
 
 func (s *BasePolicyService) GetAllBasePolicies(ctx context.Context) ([]models.BasePolicy, error) {
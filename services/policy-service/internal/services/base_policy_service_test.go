@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedDetailMatches(t *testing.T) {
+	id := uuid.New()
+	detail := &models.CompletePolicyDetailResponse{
+		BasePolicy: models.BasePolicy{
+			ID:                  id,
+			InsuranceProviderID: "provider-1",
+			CropType:            "rice",
+			Status:              models.BasePolicyActive,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter models.PolicyDetailFilterRequest
+		want   bool
+	}{
+		{name: "ID only", filter: models.PolicyDetailFilterRequest{ID: &id}, want: true},
+		{name: "Matching provider", filter: models.PolicyDetailFilterRequest{ID: &id, ProviderID: "provider-1"}, want: true},
+		{name: "Other provider", filter: models.PolicyDetailFilterRequest{ID: &id, ProviderID: "provider-2"}, want: false},
+		{name: "Other crop type", filter: models.PolicyDetailFilterRequest{ID: &id, CropType: "coffee"}, want: false},
+		{name: "Other status", filter: models.PolicyDetailFilterRequest{ID: &id, Status: models.BasePolicyArchived}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cachedDetailMatches(detail, tt.filter))
+		})
+	}
+}
+
+func TestServePolicyDetail_DocumentOnlyWhenRequested(t *testing.T) {
+	s := &BasePolicyService{}
+	missing := "Document file not found in storage"
+	detail := &models.CompletePolicyDetailResponse{
+		Document: &models.PolicyDocumentInfo{HasDocument: true, Error: &missing},
+	}
+
+	served := s.servePolicyDetail(context.Background(), detail, models.PolicyDetailFilterRequest{IncludePDF: false})
+	assert.Nil(t, served.Document)
+	assert.NotNil(t, detail.Document, "the cached detail must not be modified")
+
+	served = s.servePolicyDetail(context.Background(), detail, models.PolicyDetailFilterRequest{IncludePDF: true, PDFExpiryHours: 24})
+	assert.Equal(t, detail.Document, served.Document)
+	assert.NotSame(t, detail.Document, served.Document)
+	assert.Nil(t, served.Document.PresignedURL)
+}
+
+func TestDraftFilterOf(t *testing.T) {
+	id := uuid.New()
+
+	filter, err := draftFilterOf("provider-1", id.String(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "provider-1", filter.InsuranceProviderID)
+	assert.Equal(t, id, *filter.ID)
+	assert.Nil(t, filter.AutoCommit)
+
+	for archiveStatus, autoCommit := range map[string]bool{"true": true, "archived": true, "false": false, "active": false} {
+		filter, err := draftFilterOf("provider-1", "", archiveStatus)
+		assert.NoError(t, err, archiveStatus)
+		assert.Nil(t, filter.ID)
+		assert.Equal(t, autoCommit, *filter.AutoCommit, archiveStatus)
+	}
+
+	_, err = draftFilterOf("", "not-a-uuid", "")
+	assert.ErrorContains(t, err, "invalid base_policy_id")
+	_, err = draftFilterOf("provider-1", "", "maybe")
+	assert.ErrorContains(t, err, "invalid archive_status")
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"reflect"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RecordPolicyVersion keeps the current terms of a base policy as a new version when they changed
+// since the latest one, and returns the number of the version matching them
+func (s *BasePolicyService) RecordPolicyVersion(ctx context.Context, basePolicyID uuid.UUID) (int, error) {
+	version, err := s.versionRepo.RecordVersion(ctx, basePolicyID, requestActor(ctx))
+	if err != nil {
+		return 0, err
+	}
+	slog.Info("Base policy version recorded", "base_policy_id", basePolicyID, "version", version)
+	return version, nil
+}
+
+// RecordPolicyVersionTx is RecordPolicyVersion within the transaction changing the terms or
+// selling a policy under them
+func (s *BasePolicyService) RecordPolicyVersionTx(tx *sqlx.Tx, ctx context.Context, basePolicyID uuid.UUID) (int, error) {
+	return s.versionRepo.RecordVersionTx(tx, ctx, basePolicyID, requestActor(ctx))
+}
+
+func (s *BasePolicyService) GetPolicyVersions(ctx context.Context, basePolicyID uuid.UUID) ([]models.BasePolicyVersion, error) {
+	return s.versionRepo.ListVersions(ctx, basePolicyID)
+}
+
+// DiffPolicyVersions returns the fields of the terms that differ between two versions
+func (s *BasePolicyService) DiffPolicyVersions(ctx context.Context, basePolicyID uuid.UUID, fromVersion, toVersion int) (*models.BasePolicyVersionDiff, error) {
+	if fromVersion <= 0 || toVersion <= 0 {
+		return nil, fmt.Errorf("invalid version: versions start at 1")
+	}
+
+	from, err := s.versionRepo.GetVersion(ctx, basePolicyID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.versionRepo.GetVersion(ctx, basePolicyID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BasePolicyVersionDiff{
+		BasePolicyID: basePolicyID,
+		FromVersion:  fromVersion,
+		ToVersion:    toVersion,
+		Changes:      diffPolicyTerms(map[string]any(from.Terms), map[string]any(to.Terms)),
+	}, nil
+}
+
+// diffPolicyTerms walks two versions of the terms and returns every leaf field that differs,
+// sorted by path. A field missing from one version compares as null.
+func diffPolicyTerms(before, after map[string]any) []models.BasePolicyFieldChange {
+	changes := []models.BasePolicyFieldChange{}
+	collectTermChanges("", before, after, &changes)
+	return changes
+}
+
+func collectTermChanges(path string, before, after any, changes *[]models.BasePolicyFieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for key := range beforeMap {
+			keys = append(keys, key)
+		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			collectTermChanges(joinTermPath(path, key), beforeMap[key], afterMap[key], changes)
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList {
+		for i := range max(len(beforeList), len(afterList)) {
+			var b, a any
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			collectTermChanges(fmt.Sprintf("%s[%d]", path, i), b, a, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, models.BasePolicyFieldChange{Field: path, Before: before, After: after})
+	}
+}
+
+func joinTermPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
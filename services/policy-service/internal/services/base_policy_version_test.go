@@ -0,0 +1,60 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffPolicyTerms(t *testing.T) {
+	v1 := map[string]any{
+		"base_policy": map[string]any{
+			"product_name":      "Rice drought cover",
+			"premium_base_rate": 0.05,
+			"payout_cap":        nil,
+		},
+		"trigger": map[string]any{
+			"logical_operator": "AND",
+			"conditions": []any{
+				map[string]any{"threshold_operator": "<", "threshold_value": 20.0},
+			},
+		},
+	}
+	v2 := map[string]any{
+		"base_policy": map[string]any{
+			"product_name":      "Rice drought cover",
+			"premium_base_rate": 0.06,
+		},
+		"trigger": map[string]any{
+			"logical_operator": "AND",
+			"conditions": []any{
+				map[string]any{"threshold_operator": "<", "threshold_value": 15.0},
+				map[string]any{"threshold_operator": ">", "threshold_value": 40.0},
+			},
+		},
+	}
+
+	assert.Equal(t, []models.BasePolicyFieldChange{
+		{Field: "base_policy.premium_base_rate", Before: 0.05, After: 0.06},
+		{Field: "trigger.conditions[0].threshold_value", Before: 20.0, After: 15.0},
+		{Field: "trigger.conditions[1]", Before: nil, After: map[string]any{"threshold_operator": ">", "threshold_value": 40.0}},
+	}, diffPolicyTerms(v1, v2))
+}
+
+func TestDiffPolicyTerms_Unchanged(t *testing.T) {
+	terms := map[string]any{
+		"base_policy": map[string]any{"product_name": "Rice drought cover"},
+		"trigger":     nil,
+	}
+	assert.Empty(t, diffPolicyTerms(terms, terms))
+}
+
+func TestDiffPolicyTerms_TriggerAdded(t *testing.T) {
+	before := map[string]any{"trigger": nil}
+	after := map[string]any{"trigger": map[string]any{"logical_operator": "OR"}}
+
+	assert.Equal(t, []models.BasePolicyFieldChange{
+		{Field: "trigger", Before: nil, After: map[string]any{"logical_operator": "OR"}},
+	}, diffPolicyTerms(before, after))
+}
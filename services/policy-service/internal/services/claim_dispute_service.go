@@ -0,0 +1,624 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"policy-service/internal/config"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/event"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ClaimDisputeService runs the disputes of rejected claims: the farmer opens one, the partner
+// answers it in the thread, and a platform admin arbitrates it once it is escalated, by the farmer
+// or because the partner missed its response deadline
+type ClaimDisputeService struct {
+	disputeRepo *repository.ClaimDisputeRepository
+	claimRepo   *repository.ClaimRepository
+	policyRepo  *repository.RegisteredPolicyRepository
+	payoutRepo  *repository.PayoutRepository
+	outboxRepo  *repository.OutboxRepository
+	scanService *DocumentScanService
+	minioClient *minio.MinioClient
+	cfg         config.ClaimDisputeConfig
+}
+
+func NewClaimDisputeService(
+	disputeRepo *repository.ClaimDisputeRepository,
+	claimRepo *repository.ClaimRepository,
+	policyRepo *repository.RegisteredPolicyRepository,
+	payoutRepo *repository.PayoutRepository,
+	outboxRepo *repository.OutboxRepository,
+	scanService *DocumentScanService,
+	minioClient *minio.MinioClient,
+	cfg config.ClaimDisputeConfig,
+) *ClaimDisputeService {
+	return &ClaimDisputeService{
+		disputeRepo: disputeRepo,
+		claimRepo:   claimRepo,
+		policyRepo:  policyRepo,
+		payoutRepo:  payoutRepo,
+		outboxRepo:  outboxRepo,
+		scanService: scanService,
+		minioClient: minioClient,
+		cfg:         cfg,
+	}
+}
+
+// OpenDispute disputes a rejected claim of the farmer. The claim moves to disputed and the
+// partner is notified and given PartnerResponseSLA to answer.
+func (s *ClaimDisputeService) OpenDispute(ctx context.Context, claimID uuid.UUID, farmerID string, req models.OpenClaimDisputeRequest) (*models.ClaimDispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	claim, err := s.claimRepo.GetByID(ctx, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("claim not found: %w", err)
+	}
+	policy, err := s.policyRepo.GetByID(claim.RegisteredPolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+	if policy.FarmerID != farmerID {
+		return nil, fmt.Errorf("unauthorized: claim does not belong to this farmer")
+	}
+
+	exists, err := s.disputeRepo.ExistsForClaim(ctx, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("invalid operation: claim %s has already been disputed", claim.ClaimNumber)
+	}
+
+	tx, err := s.disputeRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the claim so that a concurrent review or dispute sees the new status
+	claim, err = s.claimRepo.GetByIDForUpdateTx(tx, ctx, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("claim not found: %w", err)
+	}
+	now := time.Now()
+	if err := checkDisputeFiling(claim, now, s.cfg.FilingWindow); err != nil {
+		return nil, err
+	}
+
+	dispute := &models.ClaimDispute{
+		ID:                   uuid.New(),
+		ClaimID:              claim.ID,
+		RegisteredPolicyID:   policy.ID,
+		FarmerID:             policy.FarmerID,
+		InsuranceProviderID:  policy.InsuranceProviderID,
+		Status:               models.DisputeAwaitingPartner,
+		Reason:               req.Reason,
+		PartnerResponseDueAt: now.Add(s.cfg.PartnerResponseSLA).Unix(),
+	}
+	if err := s.disputeRepo.CreateTx(tx, ctx, dispute); err != nil {
+		return nil, err
+	}
+	if err := s.moveClaimTx(tx, ctx, claim, models.ClaimDisputed, farmerID, req.Reason); err != nil {
+		return nil, err
+	}
+
+	notification, err := event.ClaimDisputeOpenedNotification(ctx, policy, claim, dispute)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	slog.Info("Claim dispute opened",
+		"dispute_id", dispute.ID,
+		"claim_id", claim.ID,
+		"farmer_id", farmerID,
+		"partner_response_due_at", dispute.PartnerResponseDueAt)
+	return dispute, nil
+}
+
+func (s *ClaimDisputeService) GetDispute(ctx context.Context, id uuid.UUID) (*models.ClaimDispute, error) {
+	return s.disputeRepo.GetByID(ctx, id)
+}
+
+// GetDisputeDetail returns a dispute with its thread and the evidence uploaded to it, each with a
+// download link
+func (s *ClaimDisputeService) GetDisputeDetail(ctx context.Context, id uuid.UUID) (*models.ClaimDisputeDetail, error) {
+	dispute, err := s.disputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.disputeRepo.GetMessages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	evidence, err := s.disputeRepo.GetUploadedEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range evidence {
+		s.withEvidenceDownloadURL(ctx, &evidence[i])
+	}
+
+	return &models.ClaimDisputeDetail{
+		ClaimDispute: *dispute,
+		Messages:     messages,
+		Evidence:     evidence,
+	}, nil
+}
+
+func (s *ClaimDisputeService) ListFarmerDisputes(ctx context.Context, farmerID string) ([]models.ClaimDispute, error) {
+	return s.disputeRepo.ListByFarmerID(ctx, farmerID)
+}
+
+func (s *ClaimDisputeService) ListProviderDisputes(ctx context.Context, providerID string) ([]models.ClaimDispute, error) {
+	return s.disputeRepo.ListByProviderID(ctx, providerID)
+}
+
+func (s *ClaimDisputeService) ListDisputes(ctx context.Context, filter models.ClaimDisputeFilter) ([]models.ClaimDispute, error) {
+	return s.disputeRepo.List(ctx, filter, time.Now().Unix())
+}
+
+// PostMessage adds a message of a participant to the thread of an open dispute. The first message
+// of the partner answers the dispute and stops its response timer.
+func (s *ClaimDisputeService) PostMessage(ctx context.Context, id uuid.UUID, participant models.DisputeParticipant, req models.PostClaimDisputeMessageRequest) (*models.ClaimDisputeMessage, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	tx, err := s.disputeRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dispute, err := s.disputeRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status.IsFinal() {
+		return nil, fmt.Errorf("invalid operation: dispute is %s", dispute.Status)
+	}
+
+	message := &models.ClaimDisputeMessage{
+		DisputeID:  dispute.ID,
+		AuthorID:   participant.UserID,
+		AuthorRole: participant.Role,
+		Body:       req.Body,
+	}
+	if err := s.disputeRepo.CreateMessageTx(tx, ctx, message); err != nil {
+		return nil, err
+	}
+
+	if participant.Role == models.DisputeRolePartner && dispute.Status == models.DisputeAwaitingPartner {
+		now := time.Now().Unix()
+		dispute.Status = models.DisputePartnerResponded
+		dispute.PartnerRespondedAt = &now
+		if err := s.disputeRepo.UpdateTx(tx, ctx, dispute); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+	return message, nil
+}
+
+// CreateEvidence records a file a participant attaches to an open dispute and returns the URL it
+// is uploaded to. The file is listed once ConfirmEvidence finds it.
+func (s *ClaimDisputeService) CreateEvidence(ctx context.Context, id uuid.UUID, participant models.DisputeParticipant, req models.CreateClaimDisputeEvidenceRequest) (*models.CreateClaimDisputeEvidenceResponse, error) {
+	if err := validateEvidenceFileName(req.FileName); err != nil {
+		return nil, err
+	}
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+
+	dispute, err := s.disputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status.IsFinal() {
+		return nil, fmt.Errorf("invalid operation: dispute is %s", dispute.Status)
+	}
+
+	evidence := &models.ClaimDisputeEvidence{
+		ID:           uuid.New(),
+		DisputeID:    dispute.ID,
+		UploadedBy:   participant.UserID,
+		UploaderRole: participant.Role,
+		FileName:     req.FileName,
+		ContentType:  minio.GetContentType(req.FileName),
+		Status:       models.DisputeEvidencePendingUpload,
+	}
+	evidence.ObjectKey = disputeEvidenceObjectKey(evidence)
+
+	if err := s.disputeRepo.CreateEvidence(ctx, evidence); err != nil {
+		return nil, err
+	}
+	uploadURL, err := s.minioClient.GetPresignedUploadURL(ctx, minio.Storage.PolicyAttachments, evidence.ObjectKey, attachmentUploadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreateClaimDisputeEvidenceResponse{
+		Evidence:        evidence,
+		UploadURL:       uploadURL,
+		UploadExpiresAt: time.Now().Add(attachmentUploadExpiry),
+	}, nil
+}
+
+// ConfirmEvidence marks evidence uploaded once its file is in storage and queues its malware scan;
+// an infected file is removed from storage by the scan
+func (s *ClaimDisputeService) ConfirmEvidence(ctx context.Context, id, evidenceID uuid.UUID) (*models.ClaimDisputeEvidence, error) {
+	evidence, err := s.disputeRepo.GetEvidence(ctx, id, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if evidence.Status == models.DisputeEvidenceUploaded {
+		return s.withEvidenceDownloadURL(ctx, evidence), nil
+	}
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+
+	exists, err := s.minioClient.FileExists(ctx, minio.Storage.PolicyAttachments, evidence.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("invalid operation: file of evidence %s has not been uploaded", evidenceID)
+	}
+	info, err := s.minioClient.StatFile(ctx, minio.Storage.PolicyAttachments, evidence.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.disputeRepo.MarkEvidenceUploaded(ctx, evidenceID, info.Size); err != nil {
+		return nil, err
+	}
+	evidence.Status = models.DisputeEvidenceUploaded
+	evidence.SizeBytes = &info.Size
+
+	if _, err := s.scanService.QueueScan(ctx, models.DocumentScanDisputeEvidence,
+		minio.Storage.PolicyAttachments, evidence.ObjectKey, &evidence.ID, nil); err != nil {
+		return nil, fmt.Errorf("failed to queue dispute evidence scan: %w", err)
+	}
+
+	return s.withEvidenceDownloadURL(ctx, evidence), nil
+}
+
+// Escalate sends a dispute of the farmer to platform arbitration without waiting for the partner's
+// response deadline
+func (s *ClaimDisputeService) Escalate(ctx context.Context, id uuid.UUID, farmerID string) (*models.ClaimDispute, error) {
+	tx, err := s.disputeRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dispute, err := s.disputeRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.FarmerID != farmerID {
+		return nil, fmt.Errorf("unauthorized: dispute does not belong to this farmer")
+	}
+	if err := s.escalateTx(tx, ctx, dispute, time.Now(), false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+	slog.Info("Claim dispute escalated", "dispute_id", id, "farmer_id", farmerID)
+	return dispute, nil
+}
+
+// Withdraw closes an open dispute of the farmer; the claim returns to rejected
+func (s *ClaimDisputeService) Withdraw(ctx context.Context, id uuid.UUID, farmerID string) (*models.ClaimDispute, error) {
+	tx, err := s.disputeRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dispute, err := s.disputeRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.FarmerID != farmerID {
+		return nil, fmt.Errorf("unauthorized: dispute does not belong to this farmer")
+	}
+	if dispute.Status.IsFinal() {
+		return nil, fmt.Errorf("invalid operation: dispute is %s", dispute.Status)
+	}
+
+	if _, err := s.settleTx(tx, ctx, dispute, models.DisputeResolutionWithdrawn, farmerID, "Withdrawn by the farmer"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+	slog.Info("Claim dispute withdrawn", "dispute_id", id, "farmer_id", farmerID)
+	return dispute, nil
+}
+
+// Resolve settles an open dispute as a platform admin. An upheld dispute approves the claim and
+// starts its payout, a dismissed one returns the claim to rejected. The farmer is notified of the
+// outcome either way.
+func (s *ClaimDisputeService) Resolve(ctx context.Context, id uuid.UUID, adminID string, req models.ResolveClaimDisputeRequest) (*models.ClaimDispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	tx, err := s.disputeRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dispute, err := s.disputeRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status.IsFinal() {
+		return nil, fmt.Errorf("invalid operation: dispute is %s", dispute.Status)
+	}
+
+	claim, err := s.settleTx(tx, ctx, dispute, req.Resolution, adminID, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	notification, err := event.ClaimDisputeResolvedNotification(ctx, claim, dispute)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+	slog.Info("Claim dispute resolved",
+		"dispute_id", id,
+		"claim_id", claim.ID,
+		"resolution", req.Resolution,
+		"resolved_by", adminID)
+	return dispute, nil
+}
+
+// CheckSLA escalates the disputes whose partner missed the response deadline, marking their SLA
+// breached, and reports the disputes waiting on arbitration past theirs
+func (s *ClaimDisputeService) CheckSLA(ctx context.Context) error {
+	now := time.Now()
+	ids, err := s.disputeRepo.GetOverdueIDs(ctx, models.DisputeAwaitingPartner, now.Unix())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if err := s.escalateOverdue(ctx, id, now); err != nil {
+			slog.Error("Failed to escalate overdue claim dispute", "dispute_id", id, "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	overdue, err := s.disputeRepo.GetOverdueIDs(ctx, models.DisputeInArbitration, now.Unix())
+	if err != nil {
+		errs = append(errs, err)
+	} else if len(overdue) > 0 {
+		slog.Warn("Claim disputes overdue for arbitration", "count", len(overdue), "dispute_ids", overdue)
+	}
+
+	if len(ids) > 0 {
+		slog.Info("Overdue claim disputes escalated", "count", len(ids)-len(errs))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ClaimDisputeService) escalateOverdue(ctx context.Context, id uuid.UUID, now time.Time) error {
+	tx, err := s.disputeRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dispute, err := s.disputeRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return err
+	}
+	// The partner may have answered since the overdue disputes were listed
+	if dispute.Status != models.DisputeAwaitingPartner || dispute.PartnerResponseDueAt >= now.Unix() {
+		return nil
+	}
+
+	if err := s.escalateTx(tx, ctx, dispute, now, true); err != nil {
+		return err
+	}
+	if err := s.disputeRepo.CreateMessageTx(tx, ctx, &models.ClaimDisputeMessage{
+		DisputeID:  dispute.ID,
+		AuthorID:   string(models.DisputeRoleSystem),
+		AuthorRole: models.DisputeRoleSystem,
+		Body:       "The insurance partner did not respond before the deadline, the dispute was escalated to arbitration.",
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// escalateTx moves a dispute locked in tx to arbitration and notifies the partner
+func (s *ClaimDisputeService) escalateTx(tx *sqlx.Tx, ctx context.Context, dispute *models.ClaimDispute, now time.Time, breached bool) error {
+	if err := escalateDispute(dispute, now, s.cfg.ArbitrationSLA, breached); err != nil {
+		return err
+	}
+	if err := s.disputeRepo.UpdateTx(tx, ctx, dispute); err != nil {
+		return err
+	}
+
+	notification, err := event.ClaimDisputeEscalatedNotification(ctx, dispute)
+	if err != nil {
+		return err
+	}
+	return s.outboxRepo.CreateTx(tx, ctx, notification)
+}
+
+// settleTx closes a dispute locked in tx with resolution and moves its claim out of disputed,
+// creating the payout of an upheld claim. It returns the claim as updated.
+func (s *ClaimDisputeService) settleTx(tx *sqlx.Tx, ctx context.Context, dispute *models.ClaimDispute, resolution models.DisputeResolution, settledBy, notes string) (*models.Claim, error) {
+	claim, err := s.claimRepo.GetByIDForUpdateTx(tx, ctx, dispute.ClaimID)
+	if err != nil {
+		return nil, fmt.Errorf("claim not found: %w", err)
+	}
+
+	now := time.Now().Unix()
+	dispute.Status = disputeStatusOf(resolution)
+	dispute.ResolutionNotes = &notes
+	dispute.ResolvedBy = &settledBy
+	dispute.ResolvedAt = &now
+	if err := s.disputeRepo.UpdateTx(tx, ctx, dispute); err != nil {
+		return nil, err
+	}
+
+	next := models.ClaimRejected
+	if resolution == models.DisputeResolutionUpheld {
+		next = models.ClaimApproved
+	}
+	claim.DisputeResolution = &resolution
+	if err := s.moveClaimTx(tx, ctx, claim, next, settledBy, notes); err != nil {
+		return nil, err
+	}
+
+	if next == models.ClaimApproved {
+		policy, err := s.policyRepo.GetByID(claim.RegisteredPolicyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get policy: %w", err)
+		}
+		payout := newClaimPayout(claim, policy, now)
+		if err := s.payoutRepo.CreateTx(tx, &payout); err != nil {
+			return nil, fmt.Errorf("error creating payout: %w", err)
+		}
+	}
+	return claim, nil
+}
+
+// moveClaimTx moves a claim locked in tx to status next, saving its dispute resolution, and
+// records the change in the status history of the claim
+func (s *ClaimDisputeService) moveClaimTx(tx *sqlx.Tx, ctx context.Context, claim *models.Claim, next models.ClaimStatus, changedBy, notes string) error {
+	fromStatus := claim.Status
+	if !fromStatus.CanTransitionTo(next) {
+		return fmt.Errorf("invalid operation: claim cannot move from %s to %s", fromStatus, next)
+	}
+
+	claim.Status = next
+	if err := s.claimRepo.UpdateTx(tx, claim); err != nil {
+		return fmt.Errorf("error updating claim: %w", err)
+	}
+	return s.claimRepo.CreateStatusHistoryTx(tx, ctx, &models.ClaimStatusHistory{
+		ClaimID:    claim.ID,
+		FromStatus: &fromStatus,
+		ToStatus:   next,
+		ChangedBy:  &changedBy,
+		Notes:      &notes,
+	})
+}
+
+// withEvidenceDownloadURL fills the download link of uploaded evidence; a failure is only logged
+func (s *ClaimDisputeService) withEvidenceDownloadURL(ctx context.Context, evidence *models.ClaimDisputeEvidence) *models.ClaimDisputeEvidence {
+	if evidence.Status != models.DisputeEvidenceUploaded || s.minioClient == nil {
+		return evidence
+	}
+	downloadURL, err := s.minioClient.GetPresignedURL(ctx, minio.Storage.PolicyAttachments, evidence.ObjectKey, attachmentDownloadExpiry)
+	if err != nil {
+		slog.Error("Failed to presign claim dispute evidence", "evidence_id", evidence.ID, "error", err)
+		return evidence
+	}
+	evidence.DownloadURL = downloadURL
+	return evidence
+}
+
+// checkDisputeFiling checks that a claim is rejected and that its filing window, which starts at
+// the partner's review, is still open
+func checkDisputeFiling(claim *models.Claim, now time.Time, window time.Duration) error {
+	if claim.Status != models.ClaimRejected {
+		return fmt.Errorf("invalid operation: only rejected claims can be disputed, claim is %s", claim.Status)
+	}
+	rejectedAt := claim.UpdatedAt
+	if claim.PartnerReviewTimestamp != nil {
+		rejectedAt = time.Unix(*claim.PartnerReviewTimestamp, 0)
+	}
+	if now.After(rejectedAt.Add(window)) {
+		return fmt.Errorf("invalid operation: the dispute window of claim %s closed on %s",
+			claim.ClaimNumber, rejectedAt.Add(window).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// escalateDispute moves a dispute awaiting or answered by the partner to arbitration, due within
+// sla. breached marks a partner that missed its response deadline.
+func escalateDispute(dispute *models.ClaimDispute, now time.Time, sla time.Duration, breached bool) error {
+	if dispute.Status != models.DisputeAwaitingPartner && dispute.Status != models.DisputePartnerResponded {
+		return fmt.Errorf("invalid operation: dispute is %s", dispute.Status)
+	}
+	escalatedAt := now.Unix()
+	dueAt := now.Add(sla).Unix()
+	dispute.Status = models.DisputeInArbitration
+	dispute.EscalatedAt = &escalatedAt
+	dispute.ArbitrationDueAt = &dueAt
+	dispute.SLABreached = dispute.SLABreached || breached
+	return nil
+}
+
+// disputeStatusOf is the final status of a dispute settled with resolution
+func disputeStatusOf(resolution models.DisputeResolution) models.ClaimDisputeStatus {
+	switch resolution {
+	case models.DisputeResolutionUpheld:
+		return models.DisputeUpheld
+	case models.DisputeResolutionWithdrawn:
+		return models.DisputeWithdrawn
+	}
+	return models.DisputeDismissed
+}
+
+func validateEvidenceFileName(fileName string) error {
+	if strings.TrimSpace(fileName) == "" {
+		return fmt.Errorf("invalid request: file_name is required")
+	}
+	if len(fileName) > 255 {
+		return fmt.Errorf("invalid request: file_name is longer than 255 characters")
+	}
+	if ext := strings.ToLower(filepath.Ext(fileName)); !slices.Contains(allowedAttachmentExtensions, ext) {
+		return fmt.Errorf("invalid file type %q, allowed: %s", ext, strings.Join(allowedAttachmentExtensions, ", "))
+	}
+	return nil
+}
+
+// disputeEvidenceObjectKey places the file under its dispute, e.g.
+// claim-disputes/<dispute id>/<evidence id>-<file name>
+func disputeEvidenceObjectKey(evidence *models.ClaimDisputeEvidence) string {
+	return fmt.Sprintf("claim-disputes/%s/%s-%s", evidence.DisputeID, evidence.ID,
+		minio.GetSafeFileName(filepath.Base(evidence.FileName)))
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDisputeFiling(t *testing.T) {
+	window := 30 * 24 * time.Hour
+	reviewedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	reviewed := reviewedAt.Unix()
+	claim := &models.Claim{
+		ClaimNumber:            "CLM-001",
+		Status:                 models.ClaimRejected,
+		PartnerReviewTimestamp: &reviewed,
+		UpdatedAt:              reviewedAt.AddDate(0, 1, 0),
+	}
+
+	assert.NoError(t, checkDisputeFiling(claim, reviewedAt.Add(window), window))
+
+	// The window starts at the partner's review, not at the last update of the claim
+	err := checkDisputeFiling(claim, reviewedAt.Add(window+time.Second), window)
+	assert.ErrorContains(t, err, "invalid operation: the dispute window of claim CLM-001 closed")
+
+	claim.PartnerReviewTimestamp = nil
+	assert.NoError(t, checkDisputeFiling(claim, reviewedAt.Add(window+time.Second), window))
+
+	claim.Status = models.ClaimApproved
+	err = checkDisputeFiling(claim, reviewedAt, window)
+	assert.ErrorContains(t, err, "only rejected claims can be disputed")
+}
+
+func TestEscalateDispute(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	sla := 14 * 24 * time.Hour
+
+	dispute := &models.ClaimDispute{Status: models.DisputeAwaitingPartner}
+	assert.NoError(t, escalateDispute(dispute, now, sla, true))
+	assert.Equal(t, models.DisputeInArbitration, dispute.Status)
+	assert.Equal(t, now.Unix(), *dispute.EscalatedAt)
+	assert.Equal(t, now.Add(sla).Unix(), *dispute.ArbitrationDueAt)
+	assert.True(t, dispute.SLABreached)
+
+	// A dispute already in arbitration or settled is not escalated again
+	assert.ErrorContains(t, escalateDispute(dispute, now, sla, false), "invalid operation")
+	settled := &models.ClaimDispute{Status: models.DisputeDismissed}
+	assert.ErrorContains(t, escalateDispute(settled, now, sla, false), "invalid operation")
+
+	// The farmer may escalate once the partner answered, without breaching the SLA
+	answered := &models.ClaimDispute{Status: models.DisputePartnerResponded}
+	assert.NoError(t, escalateDispute(answered, now, sla, false))
+	assert.False(t, answered.SLABreached)
+}
+
+func TestDisputeSettlement(t *testing.T) {
+	assert.Equal(t, models.DisputeUpheld, disputeStatusOf(models.DisputeResolutionUpheld))
+	assert.Equal(t, models.DisputeDismissed, disputeStatusOf(models.DisputeResolutionDismissed))
+	assert.Equal(t, models.DisputeWithdrawn, disputeStatusOf(models.DisputeResolutionWithdrawn))
+
+	// A disputed claim is approved or returned to rejected, and is not reviewed by the partner again
+	assert.True(t, models.ClaimRejected.CanTransitionTo(models.ClaimDisputed))
+	assert.True(t, models.ClaimDisputed.CanTransitionTo(models.ClaimApproved))
+	assert.True(t, models.ClaimDisputed.CanTransitionTo(models.ClaimRejected))
+	assert.False(t, models.ClaimDisputed.CanTransitionTo(models.ClaimClosed))
+
+	req := models.ResolveClaimDisputeRequest{Resolution: models.DisputeResolutionWithdrawn, Notes: "x"}
+	assert.Error(t, req.Validate(), "only the farmer withdraws a dispute")
+	req = models.ResolveClaimDisputeRequest{Resolution: models.DisputeResolutionUpheld, Notes: "  "}
+	assert.Error(t, req.Validate())
+}
+
+func TestDisputeEvidenceFile(t *testing.T) {
+	assert.NoError(t, validateEvidenceFileName("field photo.JPG"))
+	assert.ErrorContains(t, validateEvidenceFileName("report.exe"), "invalid file type")
+	assert.ErrorContains(t, validateEvidenceFileName(" "), "file_name is required")
+
+	evidence := &models.ClaimDisputeEvidence{
+		ID:        uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		DisputeID: uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		FileName:  "../ảnh ruộng.jpg",
+	}
+	key := disputeEvidenceObjectKey(evidence)
+	assert.Contains(t, key, "claim-disputes/22222222-2222-2222-2222-222222222222/11111111-1111-1111-1111-111111111111-")
+	assert.NotContains(t, key, "..")
+}
@@ -63,6 +63,17 @@ func (c *ClaimRejectionService) CreateNewClaimRejection(ctx context.Context, cla
 		}
 	}()
 
+	// Lock the claim so that it is not reviewed twice at the same time
+	claim, err = c.claimRepo.GetByIDForUpdateTx(tx, ctx, claimID)
+	if err != nil {
+		slog.Error("error locking claim", "claim_id", claimID, "error", err)
+		return nil, fmt.Errorf("claim not found: %w", err)
+	}
+	if !claim.Status.CanTransitionTo(models.ClaimRejected) {
+		err = fmt.Errorf("invalid operation: claim must be in PendingPartnerReview status, current status: %s", claim.Status)
+		return nil, err
+	}
+
 	// Create claim rejection
 	err = c.claimRejectionRepo.CreateNewClaimRejectionTX(tx, *claimRejection)
 	if err != nil {
@@ -76,6 +87,17 @@ func (c *ClaimRejectionService) CreateNewClaimRejection(ctx context.Context, cla
 		slog.Error("error updating claim status to rejected", "claim_id", claimID, "error", err)
 		return nil, fmt.Errorf("error updating claim status: %w", err)
 	}
+	err = c.claimRepo.CreateStatusHistoryTx(tx, ctx, &models.ClaimStatusHistory{
+		ClaimID:    claimID,
+		FromStatus: &claim.Status,
+		ToStatus:   models.ClaimRejected,
+		ChangedBy:  claimRejection.ValidatedBy,
+		Notes:      claimRejection.Reason,
+	})
+	if err != nil {
+		slog.Error("error recording claim status", "claim_id", claimID, "error", err)
+		return nil, fmt.Errorf("error recording claim status: %w", err)
+	}
 
 	// Commit transaction
 	err = tx.Commit()
@@ -267,39 +267,60 @@ func (s *ClaimService) ValidateClaim(ctx context.Context, claimID uuid.UUID, req
 		return nil, fmt.Errorf("error starting transaction: %w", err)
 	}
 
+	// Lock the claim so that it is not reviewed twice at the same time
+	claim, err = s.claimRepo.GetByIDForUpdateTx(tx, ctx, claimID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("claim not found: %w", err)
+	}
+	fromStatus := claim.Status
+	if fromStatus == models.ClaimDisputed {
+		tx.Rollback()
+		return nil, fmt.Errorf("invalid operation: claim is disputed and is settled through its dispute")
+	}
+	if !fromStatus.CanTransitionTo(request.Status) {
+		tx.Rollback()
+		return nil, fmt.Errorf("invalid operation: claim cannot move from %s to %s", fromStatus, request.Status)
+	}
+
+	now := time.Now().Unix()
 	claim.Status = request.Status
 	claim.PartnerDecision = &request.PartnerDecision
 	claim.PartnerNotes = &request.PartnerNotes
 	claim.ReviewedBy = &partnerID
+	claim.PartnerReviewTimestamp = &now
 	err = s.claimRepo.UpdateTx(tx, claim)
 	if err != nil {
 		tx.Rollback()
 		slog.Error("error updating claim", "error", err)
 		return nil, fmt.Errorf("error updating claim: %w", err)
 	}
-
-	now := time.Now().Unix()
-	payout := models.Payout{
-		ClaimID:            claim.ID,
-		RegisteredPolicyID: policy.ID,
-		FarmID:             policy.FarmID,
-		FarmerID:           policy.FarmerID,
-		PayoutAmount:       claim.ClaimAmount,
-		Currency:           "VND",
-		Status:             models.PayoutProcessing,
-		InitiatedAt:        &now,
-	}
-	err = s.payoutRepo.CreateTx(tx, &payout)
+	err = s.claimRepo.CreateStatusHistoryTx(tx, ctx, &models.ClaimStatusHistory{
+		ClaimID:    claim.ID,
+		FromStatus: &fromStatus,
+		ToStatus:   claim.Status,
+		ChangedBy:  &partnerID,
+		Notes:      claim.PartnerNotes,
+	})
 	if err != nil {
 		tx.Rollback()
-		slog.Error("error creating payout", "error", err)
-		return nil, fmt.Errorf("error creating payout: %w", err)
+		slog.Error("error recording claim status", "error", err)
+		return nil, fmt.Errorf("error recording claim status: %w", err)
 	}
 
-	res := models.ValidateClaimResponse{
-		ClaimID:  claim.ID,
-		PayoutID: payout.ID,
+	res := models.ValidateClaimResponse{ClaimID: claim.ID}
+	// Only an approved claim is paid out
+	payout := newClaimPayout(claim, policy, now)
+	if claim.Status == models.ClaimApproved {
+		err = s.payoutRepo.CreateTx(tx, &payout)
+		if err != nil {
+			tx.Rollback()
+			slog.Error("error creating payout", "error", err)
+			return nil, fmt.Errorf("error creating payout: %w", err)
+		}
+		res.PayoutID = payout.ID
 	}
+
 	if err := tx.Commit(); err != nil {
 		slog.Error("error commiting transaction", "error", err)
 		return nil, fmt.Errorf("error commiting transaction: %w", err)
@@ -335,3 +356,115 @@ func (s *ClaimService) ValidateClaim(ctx context.Context, claimID uuid.UUID, req
 
 	return &res, nil
 }
+
+// newClaimPayout is the payout of an approved claim, for the amount calculated when it was generated
+func newClaimPayout(claim *models.Claim, policy *models.RegisteredPolicy, now int64) models.Payout {
+	return models.Payout{
+		ClaimID:            claim.ID,
+		RegisteredPolicyID: policy.ID,
+		FarmID:             policy.FarmID,
+		FarmerID:           policy.FarmerID,
+		PayoutAmount:       claim.ClaimAmount,
+		Currency:           "VND",
+		Status:             models.PayoutProcessing,
+		InitiatedAt:        &now,
+	}
+}
+
+// SubmitClaim sends a generated claim to the partner for review
+func (s *ClaimService) SubmitClaim(ctx context.Context, claimID uuid.UUID, notes string, partnerID string) (*models.Claim, error) {
+	return s.transitionClaim(ctx, claimID, models.ClaimPendingPartnerReview, notes, partnerID)
+}
+
+// CloseClaim settles a paid or rejected claim, after which its status no longer changes
+func (s *ClaimService) CloseClaim(ctx context.Context, claimID uuid.UUID, notes string, partnerID string) (*models.Claim, error) {
+	return s.transitionClaim(ctx, claimID, models.ClaimClosed, notes, partnerID)
+}
+
+// transitionClaim moves a claim of the partner to status next and records the change in the
+// status history of the claim
+func (s *ClaimService) transitionClaim(ctx context.Context, claimID uuid.UUID, next models.ClaimStatus, notes string, partnerID string) (*models.Claim, error) {
+	if _, err := s.GetClaimByIDForPartner(ctx, claimID, partnerID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.claimRepo.BeginTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claim, err := s.claimRepo.GetByIDForUpdateTx(tx, ctx, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("claim not found: %w", err)
+	}
+	fromStatus := claim.Status
+	if !fromStatus.CanTransitionTo(next) {
+		return nil, fmt.Errorf("invalid operation: claim cannot move from %s to %s", fromStatus, next)
+	}
+
+	if err := s.claimRepo.UpdateStatusTX(tx, ctx, claimID, next); err != nil {
+		return nil, fmt.Errorf("error updating claim status: %w", err)
+	}
+	history := &models.ClaimStatusHistory{
+		ClaimID:    claimID,
+		FromStatus: &fromStatus,
+		ToStatus:   next,
+		ChangedBy:  &partnerID,
+	}
+	if notes != "" {
+		history.Notes = &notes
+	}
+	if err := s.claimRepo.CreateStatusHistoryTx(tx, ctx, history); err != nil {
+		return nil, fmt.Errorf("error recording claim status: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	slog.Info("claim status changed", "claim_id", claimID, "from", fromStatus, "to", next, "changed_by", partnerID)
+	claim.Status = next
+	return claim, nil
+}
+
+// GetClaimStatusHistory retrieves the status changes of a claim (admin only - no authorization)
+func (s *ClaimService) GetClaimStatusHistory(ctx context.Context, claimID uuid.UUID) ([]models.ClaimStatusHistory, error) {
+	if _, err := s.GetClaimByID(ctx, claimID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.claimRepo.GetStatusHistory(ctx, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim status history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetClaimStatusHistoryForFarmer retrieves the status changes of a claim with farmer authorization
+func (s *ClaimService) GetClaimStatusHistoryForFarmer(ctx context.Context, claimID uuid.UUID, farmerID string) ([]models.ClaimStatusHistory, error) {
+	if _, err := s.GetClaimByIDForFarmer(ctx, claimID, farmerID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.claimRepo.GetStatusHistory(ctx, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim status history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetClaimStatusHistoryForPartner retrieves the status changes of a claim with partner authorization
+func (s *ClaimService) GetClaimStatusHistoryForPartner(ctx context.Context, claimID uuid.UUID, providerID string) ([]models.ClaimStatusHistory, error) {
+	if _, err := s.GetClaimByIDForPartner(ctx, claimID, providerID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.claimRepo.GetStatusHistory(ctx, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim status history: %w", err)
+	}
+
+	return history, nil
+}
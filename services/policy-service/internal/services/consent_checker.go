@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"policy-service/internal/config"
+	"time"
+)
+
+// Consent types recorded by auth-service
+const (
+	ConsentDataProcessing      = "data_processing"
+	ConsentSatelliteMonitoring = "satellite_monitoring"
+	ConsentMarketing           = "marketing"
+)
+
+// ConsentChecker queries auth-service for the consent state of a user
+type ConsentChecker struct {
+	authServiceURL string
+	apiKey         string
+	client         *http.Client
+}
+
+func NewConsentChecker(cfg *config.PolicyServiceConfig) *ConsentChecker {
+	return &ConsentChecker{
+		authServiceURL: cfg.AuthServiceURL,
+		apiKey:         cfg.APIKey,
+		client:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type consentStatusResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		UserID      string `json:"user_id"`
+		ConsentType string `json:"consent_type"`
+		Granted     bool   `json:"granted"`
+		Version     int    `json:"version"`
+	} `json:"data"`
+}
+
+// HasConsent reports whether the user currently holds an active consent of the given type
+func (c *ConsentChecker) HasConsent(userID, consentType string) (bool, error) {
+	endpoint := fmt.Sprintf("%s/auth/protected/api/v2/consents/users/%s/status?type=%s",
+		c.authServiceURL, url.PathEscape(userID), url.QueryEscape(consentType))
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating consent status request: %w", err)
+	}
+	req.Header.Set("API-KEY", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error requesting consent status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading consent status response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("unexpected consent status response", "status_code", resp.StatusCode, "body", string(body))
+		return false, fmt.Errorf("unexpected status code from auth-service: %d", resp.StatusCode)
+	}
+
+	var result consentStatusResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("error parsing consent status response: %w", err)
+	}
+
+	return result.Data.Granted, nil
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"policy-service/internal/ai/gemini"
+	"policy-service/internal/models"
+	"policy-service/internal/worker"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Most recent photos sent with a crop classification
+	cropVerificationMaxPhotos = 6
+
+	// CropAutoVerifyConfidence is the confidence from which a classification matching the
+	// declared crop verifies it without an insurer
+	CropAutoVerifyConfidence = 0.85
+	// CropChangeConfidence is the confidence from which a classification of another crop
+	// withdraws the verification of the declared crop
+	CropChangeConfidence = 0.7
+
+	// Crop type the classification answers when the photos do not show the crop
+	cropTypeUnknown = "unknown"
+)
+
+// cropVerificationJob is the daily classification of the crop of a farm, run after its imagery
+func cropVerificationJob(farmID uuid.UUID) worker.JobPayload {
+	return worker.JobPayload{
+		JobID:      uuid.NewString(),
+		Type:       "crop-verification",
+		Params:     map[string]any{"farm_id": farmID},
+		MaxRetries: 5,
+		OneTime:    false,
+	}
+}
+
+// CropVerificationJob classifies the crop of a farm from its latest photos. It runs with the
+// imagery of the farm and does nothing until a photo newer than the last classification arrives.
+func (s *FarmService) CropVerificationJob(params map[string]any) error {
+	farmIDStr, ok := params["farm_id"].(string)
+	if !ok {
+		slog.Error("CropVerificationJob: missing or invalid farm_id parameter")
+		return fmt.Errorf("missing or invalid farm_id parameter")
+	}
+	farmID, err := uuid.Parse(farmIDStr)
+	if err != nil {
+		slog.Error("CropVerificationJob: invalid farm_id format", "error", err)
+		return fmt.Errorf("invalid farm_id format: %w", err)
+	}
+	ctx := context.Background()
+
+	farm, err := s.farmRepository.GetFarmByID(ctx, farmID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get farm: %w", err)
+	}
+	if farm.Status != models.FarmActive {
+		slog.Info("CropVerificationJob: farm is not active, skipping", "farm_id", farmID, "status", farm.Status)
+		return nil
+	}
+
+	allPhotos, err := s.farmRepository.GetFarmPhotosByFarmID(farmID)
+	if err != nil {
+		return fmt.Errorf("failed to get farm photos: %w", err)
+	}
+	photos := cropPhotos(allPhotos, cropVerificationMaxPhotos)
+	if len(photos) == 0 {
+		slog.Info("CropVerificationJob: farm has no photos yet", "farm_id", farmID)
+		return nil
+	}
+	latestPhotoAt := photoTime(photos[0])
+
+	last, err := s.farmRepository.GetLatestCropVerification(ctx, farmID, models.CropVerificationSourceAI)
+	if err != nil {
+		return err
+	}
+	if last != nil && last.LatestPhotoAt != nil && *last.LatestPhotoAt >= latestPhotoAt {
+		slog.Info("CropVerificationJob: no photo since the last classification", "farm_id", farmID)
+		return nil
+	}
+
+	if s.geminiSelector == nil {
+		return fmt.Errorf("gemini selector is not configured")
+	}
+	photoData, err := downloadFarmPhotosParallel(ctx, s.minioClient, photos)
+	if err != nil {
+		slog.Warn("CropVerificationJob: some photos failed to download", "farm_id", farmID, "error", err)
+	}
+	if len(photoData) != len(photos) {
+		return fmt.Errorf("downloaded %d of %d farm photos", len(photoData), len(photos))
+	}
+
+	aiCtx, usageMeter := gemini.WithUsageMeter(ctx)
+	aiResp, err := gemini.SendAIWithImagesAndRetry(aiCtx, gemini.BuildCropClassificationPrompt(*farm, photos), photoData, s.geminiSelector)
+	s.aiUsageService.RecordJob(ctx, models.AIUsageSource{JobType: "crop-verification"}, usageMeter, err == nil)
+	if err != nil {
+		return fmt.Errorf("AI crop classification failed: %w", err)
+	}
+	classification, err := parseCropClassification(aiResp)
+	if err != nil {
+		return err
+	}
+
+	before := *farm
+	decision := applyCropClassification(farm, classification, time.Now().Unix())
+	verification := &models.CropVerification{
+		FarmID:           farmID,
+		Source:           models.CropVerificationSourceAI,
+		Decision:         decision,
+		DeclaredCropType: farm.CropType,
+		DetectedCropType: classification.CropType,
+		Confidence:       &classification.Confidence,
+		PhotoCount:       len(photos),
+		LatestPhotoAt:    &latestPhotoAt,
+		VerifiedBy:       models.CropVerificationActorAI,
+	}
+	if classification.Reasoning != "" {
+		verification.Reasoning = &classification.Reasoning
+	}
+
+	if err := s.saveCropVerification(ctx, &before, farm, verification); err != nil {
+		return err
+	}
+	slog.Info("CropVerificationJob: crop classified",
+		"farm_id", farmID,
+		"declared", farm.CropType,
+		"detected", classification.CropType,
+		"confidence", classification.Confidence,
+		"decision", decision)
+	return nil
+}
+
+// ReviewCropVerification records an insurer confirming the declared crop of a farm or overriding
+// it with the crop actually grown. Either way the crop is verified by the reviewer.
+func (s *FarmService) ReviewCropVerification(ctx context.Context, farmID uuid.UUID, reviewerID string, req models.ReviewCropVerificationRequest) (*models.Farm, error) {
+	farm, err := s.farmRepository.GetFarmByID(ctx, farmID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	before := *farm
+	now := time.Now().Unix()
+	fullConfidence := 1.0
+	if req.Decision == models.CropVerificationOverridden {
+		farm.CropType = req.CropType
+	}
+	farm.CropTypeVerified = true
+	farm.CropTypeVerifiedAt = &now
+	farm.CropTypeVerifiedBy = &reviewerID
+	farm.CropTypeConfidence = &fullConfidence
+
+	verification := &models.CropVerification{
+		FarmID:           farmID,
+		Source:           models.CropVerificationSourceInsurer,
+		Decision:         req.Decision,
+		DeclaredCropType: before.CropType,
+		DetectedCropType: farm.CropType,
+		Confidence:       &fullConfidence,
+		VerifiedBy:       reviewerID,
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		verification.Reasoning = &reason
+	}
+
+	if err := s.saveCropVerification(ctx, &before, farm, verification); err != nil {
+		return nil, err
+	}
+	return farm, nil
+}
+
+// GetCropVerifications lists the classifications of the crop of a farm, most recent first
+func (s *FarmService) GetCropVerifications(ctx context.Context, farmID uuid.UUID) ([]models.CropVerification, error) {
+	return s.farmRepository.GetCropVerificationsByFarmID(ctx, farmID)
+}
+
+// GetInsuringProviderIDs lists the insurance providers with a policy on the farm under review or
+// in force
+func (s *FarmService) GetInsuringProviderIDs(ctx context.Context, farmID uuid.UUID) ([]string, error) {
+	return s.farmRepository.GetInsuringProviderIDs(ctx, farmID)
+}
+
+// saveCropVerification stores the classification with the crop state of the farm it led to, and
+// audits the change of the farm in the same transaction
+func (s *FarmService) saveCropVerification(ctx context.Context, before, farm *models.Farm, verification *models.CropVerification) error {
+	tx, err := s.farmRepository.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.farmRepository.UpdateCropVerificationTx(tx, farm); err != nil {
+		return err
+	}
+	if err := s.farmRepository.CreateCropVerificationTx(tx, verification); err != nil {
+		return err
+	}
+	if err := s.auditService.RecordTx(tx, ctx, models.AuditEntityFarm, farm.ID, models.AuditActionUpdate,
+		cropAuditState(before), cropAuditState(farm)); err != nil {
+		return fmt.Errorf("failed to audit crop verification: %w", err)
+	}
+	return tx.Commit()
+}
+
+// cropAuditState is the part of a farm a crop verification changes
+func cropAuditState(farm *models.Farm) map[string]any {
+	return map[string]any{
+		"crop_type":             farm.CropType,
+		"crop_type_verified":    farm.CropTypeVerified,
+		"crop_type_verified_at": farm.CropTypeVerifiedAt,
+		"crop_type_verified_by": farm.CropTypeVerifiedBy,
+		"crop_type_confidence":  farm.CropTypeConfidence,
+	}
+}
+
+// applyCropClassification updates the crop verification of a farm with an AI classification and
+// returns the decision it led to. The farm keeps the confidence that its declared crop grows on it.
+// A confident classification of the declared crop verifies it; a confident classification of
+// another crop withdraws any verification, also one by an insurer, since the crop has changed.
+func applyCropClassification(farm *models.Farm, classification models.CropClassification, now int64) models.CropVerificationDecision {
+	known := classification.CropType != cropTypeUnknown
+	matches := sameCropType(farm.CropType, classification.CropType)
+
+	if known {
+		confidence := classification.Confidence
+		if !matches {
+			confidence = roundConfidence(1 - confidence)
+		}
+		farm.CropTypeConfidence = &confidence
+	}
+
+	switch {
+	case known && !matches && classification.Confidence >= CropChangeConfidence:
+		farm.CropTypeVerified = false
+		farm.CropTypeVerifiedAt = nil
+		farm.CropTypeVerifiedBy = nil
+		return models.CropVerificationChangeDetected
+	case matches && classification.Confidence >= CropAutoVerifyConfidence && !farm.CropTypeVerified:
+		verifiedBy := models.CropVerificationActorAI
+		farm.CropTypeVerified = true
+		farm.CropTypeVerifiedAt = &now
+		farm.CropTypeVerifiedBy = &verifiedBy
+		return models.CropVerificationVerified
+	case farm.CropTypeVerified:
+		return models.CropVerificationVerified
+	default:
+		return models.CropVerificationPendingReview
+	}
+}
+
+// parseCropClassification reads the answer of the crop classification prompt. A confidence given
+// in percent is scaled to 0-1.
+func parseCropClassification(resp map[string]any) (models.CropClassification, error) {
+	var classification models.CropClassification
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return classification, fmt.Errorf("failed to marshal AI response: %w", err)
+	}
+	if err := json.Unmarshal(raw, &classification); err != nil {
+		return classification, fmt.Errorf("failed to parse crop classification: %w", err)
+	}
+
+	classification.CropType = normalizeCropType(classification.CropType)
+	if classification.CropType == "" {
+		classification.CropType = cropTypeUnknown
+	}
+	if classification.Confidence > 1 {
+		classification.Confidence /= 100
+	}
+	classification.Confidence = roundConfidence(math.Max(0, math.Min(1, classification.Confidence)))
+	classification.Reasoning = strings.TrimSpace(classification.Reasoning)
+	return classification, nil
+}
+
+// cropPhotos returns the newest crop and satellite photos of a farm, up to limit
+func cropPhotos(photos []models.FarmPhoto, limit int) []models.FarmPhoto {
+	selected := make([]models.FarmPhoto, 0, len(photos))
+	for _, photo := range photos {
+		if photo.PhotoType == models.PhotoCrop || photo.PhotoType == models.PhotoSatellite {
+			selected = append(selected, photo)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return photoTime(selected[i]) > photoTime(selected[j])
+	})
+	if len(selected) > limit {
+		selected = selected[:limit]
+	}
+	return selected
+}
+
+// photoTime is when a photo was taken, or stored when that is unknown
+func photoTime(photo models.FarmPhoto) int64 {
+	if photo.TakenAt != nil {
+		return *photo.TakenAt
+	}
+	return photo.CreatedAt.Unix()
+}
+
+func sameCropType(declared, detected string) bool {
+	return normalizeCropType(declared) == normalizeCropType(detected)
+}
+
+func normalizeCropType(cropType string) string {
+	cropType = strings.ToLower(strings.TrimSpace(cropType))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(cropType)
+}
+
+// roundConfidence rounds to the two decimals crop_type_confidence keeps
+func roundConfidence(confidence float64) float64 {
+	return math.Round(confidence*100) / 100
+}
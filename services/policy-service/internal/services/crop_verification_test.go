@@ -0,0 +1,99 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCropClassification(t *testing.T) {
+	insurer := "insurer-1"
+	tests := []struct {
+		name           string
+		verified       bool
+		classification models.CropClassification
+		decision       models.CropVerificationDecision
+		wantVerified   bool
+		wantConfidence *float64
+	}{
+		{"confident match verifies", false, models.CropClassification{CropType: "rice", Confidence: 0.9},
+			models.CropVerificationVerified, true, confidencePtr(0.9)},
+		{"weak match waits for review", false, models.CropClassification{CropType: "rice", Confidence: 0.6},
+			models.CropVerificationPendingReview, false, confidencePtr(0.6)},
+		{"confident other crop withdraws verification", true, models.CropClassification{CropType: "coffee", Confidence: 0.8},
+			models.CropVerificationChangeDetected, false, confidencePtr(0.2)},
+		{"weak other crop keeps verification", true, models.CropClassification{CropType: "coffee", Confidence: 0.5},
+			models.CropVerificationVerified, true, confidencePtr(0.5)},
+		{"unknown crop leaves confidence", false, models.CropClassification{CropType: "unknown", Confidence: 0.9},
+			models.CropVerificationPendingReview, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			farm := &models.Farm{CropType: "Rice", CropTypeVerified: tt.verified}
+			if tt.verified {
+				farm.CropTypeVerifiedBy = &insurer
+			}
+
+			decision := applyCropClassification(farm, tt.classification, 1700000000)
+
+			assert.Equal(t, tt.decision, decision)
+			assert.Equal(t, tt.wantVerified, farm.CropTypeVerified)
+			assert.Equal(t, tt.wantConfidence, farm.CropTypeConfidence)
+			if !tt.wantVerified {
+				assert.Nil(t, farm.CropTypeVerifiedBy)
+			}
+		})
+	}
+}
+
+func TestApplyCropClassification_KeepsInsurerVerification(t *testing.T) {
+	insurer := "insurer-1"
+	farm := &models.Farm{CropType: "rice", CropTypeVerified: true, CropTypeVerifiedBy: &insurer}
+
+	applyCropClassification(farm, models.CropClassification{CropType: "rice", Confidence: 0.95}, 1700000000)
+
+	require.NotNil(t, farm.CropTypeVerifiedBy)
+	assert.Equal(t, insurer, *farm.CropTypeVerifiedBy)
+}
+
+func TestParseCropClassification(t *testing.T) {
+	classification, err := parseCropClassification(map[string]any{
+		"crop_type":  " Sweet Potato ",
+		"confidence": 87.0,
+		"reasoning":  "Mounded rows. ",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "sweet_potato", classification.CropType)
+	assert.Equal(t, 0.87, classification.Confidence)
+	assert.Equal(t, "Mounded rows.", classification.Reasoning)
+
+	classification, err = parseCropClassification(map[string]any{"confidence": -1.0})
+	require.NoError(t, err)
+	assert.Equal(t, "unknown", classification.CropType)
+	assert.Equal(t, 0.0, classification.Confidence)
+}
+
+func TestCropPhotos_NewestCropAndSatellitePhotos(t *testing.T) {
+	at := func(unix int64) *int64 { return &unix }
+	photos := []models.FarmPhoto{
+		{PhotoType: models.PhotoSatellite, TakenAt: at(100)},
+		{PhotoType: models.PhotoLandCertificate, TakenAt: at(400)},
+		{PhotoType: models.PhotoCrop, TakenAt: at(300)},
+		{PhotoType: models.PhotoSatellite, CreatedAt: time.Unix(200, 0)},
+	}
+
+	selected := cropPhotos(photos, 2)
+
+	require.Len(t, selected, 2)
+	assert.Equal(t, int64(300), photoTime(selected[0]))
+	assert.Equal(t, int64(200), photoTime(selected[1]))
+}
+
+func confidencePtr(v float64) *float64 {
+	return &v
+}
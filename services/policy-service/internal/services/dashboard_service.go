@@ -1,10 +1,13 @@
 package services
 
 import (
+	"fmt"
 	"log/slog"
 	"policy-service/internal/models"
 	"policy-service/internal/repository"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type DashboardService struct {
@@ -218,3 +221,11 @@ func (s *DashboardService) GetPartnerDashboardOverview(req models.PartnerDashboa
 		MonthlyPayoutPerClaimTrend: payoutPerClaimTrend,
 	}, nil
 }
+
+// GetPartnerPublicStats retrieves the figures profile-service shows on a partner's marketplace page
+func (s *DashboardService) GetPartnerPublicStats(partnerID string) (*models.PartnerPublicStats, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	return s.dashboardRepo.GetPartnerPublicStats(partnerID)
+}
@@ -6,6 +6,7 @@ import (
 	"policy-service/internal/config"
 	"policy-service/internal/models"
 	"policy-service/internal/repository"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -49,6 +50,9 @@ func (s *DataSourceService) CreateDataSource(dataSource *models.DataSource) erro
 	if dataSource.DataSource == models.DataSourceSatellite {
 		if dataSource.ParameterName == models.NDVI {
 			url = s.config.SatelliteDataServiceURL + string(models.SatelliteNDVI)
+			if dataSource.DataProvider != nil && strings.EqualFold(strings.TrimSpace(*dataSource.DataProvider), models.DataProviderAgromonitoring) {
+				url = s.config.WeatherDataServiceURL + string(models.VegetationNDVI)
+			}
 		}
 		if dataSource.ParameterName == models.NDMI {
 			url = s.config.SatelliteDataServiceURL + string(models.SatelliteNDMI)
@@ -57,6 +61,26 @@ func (s *DataSourceService) CreateDataSource(dataSource *models.DataSource) erro
 		if dataSource.ParameterName == models.RainFall {
 			url = s.config.WeatherDataServiceURL + string(models.WeatherRainFall)
 		}
+		if dataSource.ParameterName == models.AQI {
+			url = s.config.WeatherDataServiceURL + string(models.WeatherAirQuality)
+		}
+	} else if dataSource.DataSource == models.DataSourceDerived {
+		switch dataSource.ParameterName {
+		case models.SPI1:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedSPI1)
+		case models.SPI3:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedSPI3)
+		case models.SPI6:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedSPI6)
+		case models.ET0:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedET0)
+		case models.GDD:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedGDD)
+		case models.DiseaseRisk:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedDiseaseRisk)
+		case models.DrySpell:
+			url = s.config.WeatherDataServiceURL + string(models.DerivedDrySpell)
+		}
 	}
 	dataSource.APIEndpoint = &url
 	return s.repo.CreateDataSource(dataSource)
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"agrisa_utils/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"policy-service/internal/clamav"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"policy-service/internal/worker"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DocumentScanJobType = "document-scan"
+
+	// Files above this are quarantined unread, no upload path accepts them
+	maxScannedFileSize = 100 << 20 // 100MB
+)
+
+// PDF names that run code or carry other files when the document is opened
+var pdfActiveContent = regexp.MustCompile(`/(JavaScript|JS|Launch|EmbeddedFiles?|RichMedia)[\s/<>\[\]()]`)
+
+// Headers of the file types uploads may have, by extension
+var fileSignatures = map[string]func([]byte) bool{
+	".pdf": func(data []byte) bool {
+		// Readers accept the header anywhere in the first KB
+		return bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-"))
+	},
+	".png":  func(data []byte) bool { return bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) },
+	".jpg":  isJPEG,
+	".jpeg": isJPEG,
+	".webp": func(data []byte) bool {
+		return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+	},
+}
+
+func isJPEG(data []byte) bool { return bytes.HasPrefix(data, []byte("\xff\xd8\xff")) }
+
+var executableSignatures = [][]byte{
+	[]byte("MZ"),               // Windows PE
+	[]byte("\x7fELF"),          // Linux ELF
+	[]byte("\xcf\xfa\xed\xfe"), // Mach-O 64-bit
+	[]byte("\xca\xfe\xba\xbe"), // Mach-O universal
+	[]byte("#!"),               // Script
+}
+
+// DocumentScanService checks files clients put into MinIO before anything reads them. Each file
+// goes through the signature checks below and, when a sidecar is configured, ClamAV. A
+// suspicious file is moved to the quarantine bucket.
+type DocumentScanService struct {
+	scanRepo      *repository.DocumentScanRepository
+	minioClient   *minio.MinioClient
+	clamavClient  *clamav.Client // nil without a ClamAV sidecar
+	workerManager *worker.WorkerManagerV2
+}
+
+func NewDocumentScanService(
+	scanRepo *repository.DocumentScanRepository,
+	minioClient *minio.MinioClient,
+	clamavClient *clamav.Client,
+	workerManager *worker.WorkerManagerV2,
+) *DocumentScanService {
+	return &DocumentScanService{
+		scanRepo:      scanRepo,
+		minioClient:   minioClient,
+		clamavClient:  clamavClient,
+		workerManager: workerManager,
+	}
+}
+
+// QueueScan records a pending scan of an uploaded file and submits its job. followUp, when set,
+// is only submitted once the file is found clean.
+func (s *DocumentScanService) QueueScan(
+	ctx context.Context,
+	source models.DocumentScanSource,
+	bucket, objectKey string,
+	referenceID *uuid.UUID,
+	followUp *worker.JobPayload,
+) (*models.DocumentScan, error) {
+	scan := &models.DocumentScan{
+		ID:          uuid.New(),
+		Source:      source,
+		ReferenceID: referenceID,
+		Bucket:      bucket,
+		ObjectKey:   objectKey,
+		Status:      models.DocumentScanPending,
+	}
+	if followUp != nil {
+		job, err := jobToMap(*followUp)
+		if err != nil {
+			return nil, err
+		}
+		scan.FollowUpJob = job
+	}
+
+	if err := s.scanRepo.Create(ctx, scan); err != nil {
+		return nil, err
+	}
+	s.submitScan(scan.ID, logging.RequestID(ctx))
+	return scan, nil
+}
+
+func (s *DocumentScanService) GetScan(ctx context.Context, id uuid.UUID) (*models.DocumentScan, error) {
+	return s.scanRepo.GetByID(ctx, id)
+}
+
+// RecoverPendingScans submits again the scans left pending by a restart. A scan finishes once,
+// so a job submitted twice does no harm.
+func (s *DocumentScanService) RecoverPendingScans() error {
+	scans, err := s.scanRepo.GetPending(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, scan := range scans {
+		s.submitScan(scan.ID, "")
+	}
+	slog.Info("Pending document scans resubmitted", "count", len(scans))
+	return nil
+}
+
+// DocumentScanJob scans one file. Errors reaching the file or ClamAV are returned so the job is
+// retried; the follow-up job keeps waiting meanwhile.
+func (s *DocumentScanService) DocumentScanJob(params map[string]any) error {
+	scanIDStr, ok := params["scan_id"].(string)
+	if !ok || scanIDStr == "" {
+		return fmt.Errorf("invalid or missing scan_id parameter")
+	}
+	scanID, err := uuid.Parse(scanIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse scan_id: %w", err)
+	}
+
+	ctx := context.Background()
+	scan, err := s.scanRepo.GetByID(ctx, scanID)
+	if err != nil {
+		return err
+	}
+	if scan.Status != models.DocumentScanPending {
+		return nil
+	}
+
+	data, err := s.readObject(ctx, scan)
+	if err != nil {
+		return err
+	}
+
+	scannedWith := []string{"signature"}
+	var findings []string
+	if len(data) > maxScannedFileSize {
+		findings = append(findings, fmt.Sprintf("larger than %dMB", maxScannedFileSize>>20))
+	} else {
+		findings = sniffDocument(scan.ObjectKey, data)
+		if s.clamavClient != nil {
+			result, err := s.clamavClient.Scan(ctx, bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("clamav scan failed: %w", err)
+			}
+			scannedWith = append(scannedWith, "clamav")
+			if result.Infected {
+				findings = append(findings, "clamav: "+result.Signature)
+			}
+		}
+	}
+
+	now := time.Now()
+	joinedWith := strings.Join(scannedWith, ",")
+	scan.ScannedWith = &joinedWith
+	scan.ScannedAt = &now
+	scan.Status = models.DocumentScanClean
+	if len(findings) > 0 {
+		if err := s.quarantine(ctx, scan); err != nil {
+			return err
+		}
+		joinedFindings := strings.Join(findings, "; ")
+		scan.Findings = &joinedFindings
+		scan.Status = models.DocumentScanQuarantined
+	}
+
+	completed, err := s.scanRepo.Complete(ctx, scan)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return nil
+	}
+
+	if scan.Status == models.DocumentScanQuarantined {
+		slog.Warn("Uploaded document quarantined",
+			"scan_id", scan.ID,
+			"source", scan.Source,
+			"reference_id", scan.ReferenceID,
+			"object_key", scan.ObjectKey,
+			"findings", *scan.Findings)
+		return nil
+	}
+
+	slog.Info("Uploaded document is clean",
+		"scan_id", scan.ID,
+		"object_key", scan.ObjectKey,
+		"scanned_with", joinedWith)
+	if scan.FollowUpJob != nil {
+		s.submitFollowUp(scan)
+	}
+	return nil
+}
+
+func (s *DocumentScanService) readObject(ctx context.Context, scan *models.DocumentScan) ([]byte, error) {
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+	obj, err := s.minioClient.GetFile(ctx, scan.Bucket, scan.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	// One byte over the limit is enough to tell the file is too large
+	data, err := io.ReadAll(io.LimitReader(obj, maxScannedFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s: %w", scan.Bucket, scan.ObjectKey, err)
+	}
+	return data, nil
+}
+
+// quarantine moves a file to the quarantine bucket, under its original bucket and key
+func (s *DocumentScanService) quarantine(ctx context.Context, scan *models.DocumentScan) error {
+	quarantineKey := scan.Bucket + "/" + scan.ObjectKey
+	if err := s.minioClient.CopyFile(ctx, scan.Bucket, scan.ObjectKey, minio.Storage.Quarantine, quarantineKey); err != nil {
+		return err
+	}
+	if err := s.minioClient.DeleteFile(ctx, scan.Bucket, scan.ObjectKey); err != nil {
+		return err
+	}
+	scan.QuarantineKey = &quarantineKey
+	return nil
+}
+
+func (s *DocumentScanService) submitScan(scanID uuid.UUID, requestID string) {
+	if worker.ScanWorkerPoolUUID == nil {
+		slog.Error("error get document scan scheduler", "error", "scan pool doesn't exist", "scan_id", scanID)
+		return
+	}
+	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(*worker.ScanWorkerPoolUUID)
+	if !ok {
+		slog.Error("error get document scan scheduler", "error", "scheduler doesn't exist", "scan_id", scanID)
+		return
+	}
+	scheduler.AddJob(worker.JobPayload{
+		JobID:      uuid.NewString(),
+		Type:       DocumentScanJobType,
+		Params:     map[string]any{"scan_id": scanID.String()},
+		MaxRetries: 10,
+		OneTime:    true,
+		RunNow:     true,
+		RequestID:  requestID,
+	})
+}
+
+// submitFollowUp hands the job waiting for a clean file to the AI pool, the only jobs waiting on
+// scans are validations of policy documents and readings of land certificates
+func (s *DocumentScanService) submitFollowUp(scan *models.DocumentScan) {
+	var job worker.JobPayload
+	raw, err := json.Marshal(scan.FollowUpJob)
+	if err == nil {
+		err = json.Unmarshal(raw, &job)
+	}
+	if err != nil {
+		slog.Error("Failed to decode follow-up job of document scan", "scan_id", scan.ID, "error", err)
+		return
+	}
+
+	if worker.AIWorkerPoolUUID == nil {
+		slog.Error("error get AI scheduler", "error", "AI pool doesn't exist", "scan_id", scan.ID)
+		return
+	}
+	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(*worker.AIWorkerPoolUUID)
+	if !ok {
+		slog.Error("error get AI scheduler", "error", "scheduler doesn't exist", "scan_id", scan.ID)
+		return
+	}
+	scheduler.AddJob(job)
+	slog.Info("Follow-up job submitted after document scan", "scan_id", scan.ID, "job_id", job.JobID, "job_type", job.Type)
+}
+
+func jobToMap(job worker.JobPayload) (map[string]any, error) {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode follow-up job: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to encode follow-up job: %w", err)
+	}
+	return m, nil
+}
+
+// sniffDocument returns what makes a file suspicious: content that does not match the type its
+// name claims, executable content, or PDF features that run code. ClamAV, when configured, looks
+// deeper, e.g. into compressed PDF streams.
+func sniffDocument(objectKey string, data []byte) []string {
+	if len(data) == 0 {
+		return []string{"empty file"}
+	}
+
+	var findings []string
+	ext := strings.ToLower(filepath.Ext(objectKey))
+	matches, known := fileSignatures[ext]
+	switch {
+	case !known:
+		findings = append(findings, fmt.Sprintf("unexpected file type %q", ext))
+	case !matches(data):
+		findings = append(findings, fmt.Sprintf("content is not a %s file", ext))
+	}
+
+	for _, signature := range executableSignatures {
+		if bytes.HasPrefix(data, signature) {
+			findings = append(findings, "executable content")
+			break
+		}
+	}
+
+	if ext == ".pdf" {
+		var names []string
+		for _, match := range pdfActiveContent.FindAllSubmatch(data, -1) {
+			name := "/" + string(match[1])
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			findings = append(findings, "PDF contains "+strings.Join(names, ", "))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		objectKey string
+		data      string
+		want      []string
+	}{
+		{
+			name:      "Plain PDF",
+			objectKey: "contract-1.pdf",
+			data:      "%PDF-1.7\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj",
+		},
+		{
+			name:      "PDF header after junk",
+			objectKey: "contract-1.pdf",
+			data:      "\r\n%PDF-1.4\n",
+		},
+		{
+			name:      "PDF with JavaScript and launch action",
+			objectKey: "contract-1.pdf",
+			data:      "%PDF-1.7\n<< /OpenAction << /S /JavaScript /JS (app.alert(1)) >> /AA << /O << /S /Launch /F (cmd.exe) >> >> >>",
+			want:      []string{"PDF contains /JavaScript, /JS, /Launch"},
+		},
+		{
+			name:      "PDF with embedded file",
+			objectKey: "contract-1.pdf",
+			data:      "%PDF-1.7\n<< /Names << /EmbeddedFiles 4 0 R >> >>",
+			want:      []string{"PDF contains /EmbeddedFiles"},
+		},
+		{
+			name:      "Name that only starts like JS",
+			objectKey: "contract-1.pdf",
+			data:      "%PDF-1.7\n<< /JSONData 1 >>",
+		},
+		{
+			name:      "Executable named as PDF",
+			objectKey: "contract-1.pdf",
+			data:      "MZ\x90\x00\x03",
+			want:      []string{"content is not a .pdf file", "executable content"},
+		},
+		{
+			name:      "JPEG land certificate",
+			objectKey: "so-do.JPG",
+			data:      "\xff\xd8\xff\xe0\x00\x10JFIF",
+		},
+		{
+			name:      "PNG named as JPEG",
+			objectKey: "so-do.jpg",
+			data:      "\x89PNG\r\n\x1a\n",
+			want:      []string{"content is not a .jpg file"},
+		},
+		{
+			name:      "WebP",
+			objectKey: "so-do.webp",
+			data:      "RIFF\x24\x00\x00\x00WEBPVP8 ",
+		},
+		{
+			name:      "Shell script",
+			objectKey: "run.sh",
+			data:      "#!/bin/sh\nrm -rf /",
+			want:      []string{`unexpected file type ".sh"`, "executable content"},
+		},
+		{
+			name:      "Empty file",
+			objectKey: "contract-1.pdf",
+			data:      "",
+			want:      []string{"empty file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sniffDocument(tt.objectKey, []byte(tt.data)))
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// legacyDraftKeyGrace is how long the keys of a legacy draft outlived its validation window
+const legacyDraftKeyGrace = 5 * time.Minute
+
+// DraftImporter moves the drafts that were kept in Redis keys into base_policy_draft. A legacy
+// draft is the key {provider}--{id}--BasePolicy--archive:{true|false}, with its trigger,
+// conditions, creation response and validations under keys of their own, and for archive:true
+// drafts a --COMMIT_EVENT key expiring at the end of the validation window.
+type DraftImporter struct {
+	redisClient    *redis.Client
+	basePolicyRepo *repository.BasePolicyRepository
+	draftRepo      *repository.BasePolicyDraftRepository
+}
+
+func NewDraftImporter(redisClient *redis.Client, basePolicyRepo *repository.BasePolicyRepository, draftRepo *repository.BasePolicyDraftRepository) *DraftImporter {
+	return &DraftImporter{
+		redisClient:    redisClient,
+		basePolicyRepo: basePolicyRepo,
+		draftRepo:      draftRepo,
+	}
+}
+
+// legacyDraft is a draft read from its Redis keys, with the keys to delete once it is imported
+type legacyDraft struct {
+	draft *models.DraftPolicy
+	// Time left of the validation window, at least a second so an ended window is closed by the
+	// expiration listener as soon as the draft is imported
+	window time.Duration
+	keys   []string
+}
+
+// Import imports every legacy draft, schedules the end of its validation window and deletes its
+// Redis keys. Drafts imported already and drafts whose base policy was committed are skipped and
+// their keys deleted, so an interrupted import can be run again. With dryRun nothing is written.
+func (i *DraftImporter) Import(ctx context.Context, dryRun bool) (*models.DraftImportResult, error) {
+	keys, err := i.basePolicyRepo.FindKeysByPattern(ctx, "*--*--BasePolicy--archive:*", "--COMMIT_EVENT")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find legacy drafts: %w", err)
+	}
+
+	result := &models.DraftImportResult{Found: len(keys)}
+	for _, key := range keys {
+		legacy, err := i.readLegacyDraft(ctx, key)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+
+		if _, err := i.basePolicyRepo.GetBasePolicyByID(legacy.draft.ID); err == nil {
+			slog.Info("Legacy draft was committed already, skipping", "draft_id", legacy.draft.ID)
+			result.Skipped++
+			if !dryRun {
+				i.deleteKeys(ctx, legacy.keys)
+			}
+			continue
+		} else if err.Error() != "base policy not found" {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+
+		if dryRun {
+			result.Imported++
+			continue
+		}
+
+		created, err := i.draftRepo.Create(ctx, legacy.draft)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		if created {
+			result.Imported++
+		} else {
+			result.Skipped++
+		}
+		// Scheduled again for drafts imported by an interrupted run
+		if err := i.draftRepo.ScheduleExpiry(ctx, legacy.draft.ID, legacy.window); err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		i.deleteKeys(ctx, legacy.keys)
+
+		slog.Info("Legacy draft imported",
+			"draft_id", legacy.draft.ID,
+			"provider_id", legacy.draft.InsuranceProviderID,
+			"auto_commit", legacy.draft.AutoCommit,
+			"window", legacy.window)
+	}
+	return result, nil
+}
+
+func (i *DraftImporter) readLegacyDraft(ctx context.Context, key string) (*legacyDraft, error) {
+	providerID, draftID, autoCommit, ok := parseLegacyDraftKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid legacy draft key")
+	}
+
+	var content models.DraftContent
+	if err := i.readModel(ctx, key, &content.BasePolicy); err != nil {
+		return nil, err
+	}
+	legacy := &legacyDraft{keys: []string{key, key + "--COMMIT_EVENT"}}
+
+	triggerKeys, err := i.basePolicyRepo.FindKeysByPattern(ctx, fmt.Sprintf("%s--*--BasePolicyTrigger--%s--archive:*", providerID, draftID), "")
+	if err != nil {
+		return nil, err
+	}
+	if len(triggerKeys) > 0 {
+		if err := i.readModel(ctx, triggerKeys[0], &content.Trigger); err != nil {
+			return nil, err
+		}
+	}
+	legacy.keys = append(legacy.keys, triggerKeys...)
+
+	conditionKeys, err := i.basePolicyRepo.FindKeysByPattern(ctx, fmt.Sprintf("%s--*--BasePolicyTriggerCondition--*--%s--archive:*", providerID, draftID), "")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(conditionKeys, func(a, b int) bool {
+		return legacyConditionIndex(conditionKeys[a]) < legacyConditionIndex(conditionKeys[b])
+	})
+	for _, conditionKey := range conditionKeys {
+		var condition *models.BasePolicyTriggerCondition
+		if err := i.readModel(ctx, conditionKey, &condition); err != nil {
+			return nil, err
+		}
+		content.Conditions = append(content.Conditions, condition)
+	}
+	legacy.keys = append(legacy.keys, conditionKeys...)
+
+	responseKey := fmt.Sprintf("%s--%s--CompletePolicyResponse", providerID, draftID)
+	if err := i.readModel(ctx, responseKey, &content.CreationResponse); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	legacy.keys = append(legacy.keys, responseKey)
+
+	validations, err := i.basePolicyRepo.GetValidationsFromRedis(ctx, draftID)
+	if err != nil {
+		return nil, err
+	}
+	for _, validation := range validations {
+		legacy.keys = append(legacy.keys, fmt.Sprintf("%s--BasePolicyDocumentValidation--%s", draftID, validation.ID))
+	}
+
+	commitEventTTL := time.Duration(0)
+	if autoCommit {
+		commitEventTTL = i.redisClient.TTL(ctx, key+"--COMMIT_EVENT").Val()
+	}
+	legacy.window = legacyDraftWindow(commitEventTTL, i.redisClient.TTL(ctx, key).Val())
+
+	legacy.draft = &models.DraftPolicy{
+		ID:                  draftID,
+		InsuranceProviderID: providerID,
+		Status:              models.DraftPending,
+		AutoCommit:          autoCommit,
+		Content:             content,
+		Validations:         validations,
+		ExpiresAt:           time.Now().Add(legacy.window).Unix(),
+	}
+	return legacy, nil
+}
+
+func (i *DraftImporter) readModel(ctx context.Context, key string, target any) error {
+	data, err := i.basePolicyRepo.GetTempBasePolicyModels(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return nil
+}
+
+func (i *DraftImporter) deleteKeys(ctx context.Context, keys []string) {
+	if err := i.redisClient.Del(ctx, keys...).Err(); err != nil {
+		slog.Warn("Failed to delete legacy draft keys", "keys", keys, "error", err)
+	}
+}
+
+// parseLegacyDraftKey reads {provider}--{id}--BasePolicy--archive:{true|false}
+func parseLegacyDraftKey(key string) (providerID string, draftID uuid.UUID, autoCommit bool, ok bool) {
+	parts := strings.Split(key, "--")
+	if len(parts) != 4 || parts[2] != "BasePolicy" || !strings.HasPrefix(parts[3], "archive:") {
+		return "", uuid.Nil, false, false
+	}
+	draftID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return "", uuid.Nil, false, false
+	}
+	autoCommit, err = strconv.ParseBool(strings.TrimPrefix(parts[3], "archive:"))
+	if err != nil {
+		return "", uuid.Nil, false, false
+	}
+	return parts[0], draftID, autoCommit, true
+}
+
+// legacyConditionIndex reads the position of a condition in
+// {provider}--{id}--BasePolicyTriggerCondition--{index}--{draftID}--archive:{bool}
+func legacyConditionIndex(key string) int {
+	parts := strings.Split(key, "--")
+	if len(parts) < 4 {
+		return 0
+	}
+	index, _ := strconv.Atoi(parts[3])
+	return index
+}
+
+// legacyDraftWindow is the time left of the validation window of a legacy draft: what is left of
+// its commit event key, else of its keys minus the grace they were kept for. A negative TTL is a
+// key without expiry or already gone.
+func legacyDraftWindow(commitEventTTL, keyTTL time.Duration) time.Duration {
+	window := commitEventTTL
+	if window <= 0 && keyTTL > 0 {
+		window = keyTTL - legacyDraftKeyGrace
+	}
+	return max(window, time.Second)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLegacyDraftKey(t *testing.T) {
+	id := uuid.New()
+
+	providerID, draftID, autoCommit, ok := parseLegacyDraftKey("provider-1--" + id.String() + "--BasePolicy--archive:true")
+	assert.True(t, ok)
+	assert.Equal(t, "provider-1", providerID)
+	assert.Equal(t, id, draftID)
+	assert.True(t, autoCommit)
+
+	_, _, autoCommit, ok = parseLegacyDraftKey("provider-1--" + id.String() + "--BasePolicy--archive:false")
+	assert.True(t, ok)
+	assert.False(t, autoCommit)
+
+	for _, key := range []string{
+		"provider-1--" + id.String() + "--BasePolicy--archive:true--COMMIT_EVENT",
+		"provider-1--" + id.String() + "--CompletePolicyResponse",
+		"provider-1--not-a-uuid--BasePolicy--archive:true",
+		"provider-1--" + id.String() + "--BasePolicy--archive:maybe",
+		id.String() + "--BasePolicy--ValidDate",
+	} {
+		_, _, _, ok := parseLegacyDraftKey(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestLegacyConditionIndexOrdersConditions(t *testing.T) {
+	id := uuid.New().String()
+	keys := []string{
+		"p--c10--BasePolicyTriggerCondition--10--" + id + "--archive:false",
+		"p--c2--BasePolicyTriggerCondition--2--" + id + "--archive:false",
+		"p--c1--BasePolicyTriggerCondition--1--" + id + "--archive:false",
+	}
+	sort.Slice(keys, func(a, b int) bool { return legacyConditionIndex(keys[a]) < legacyConditionIndex(keys[b]) })
+
+	assert.Equal(t, []int{1, 2, 10}, []int{legacyConditionIndex(keys[0]), legacyConditionIndex(keys[1]), legacyConditionIndex(keys[2])})
+}
+
+func TestLegacyDraftWindow(t *testing.T) {
+	// The commit event key expires at the end of the window
+	assert.Equal(t, 2*time.Hour, legacyDraftWindow(2*time.Hour, 2*time.Hour+legacyDraftKeyGrace))
+	// Without one, the draft keys outlive the window by the grace
+	assert.Equal(t, time.Hour, legacyDraftWindow(0, time.Hour+legacyDraftKeyGrace))
+	// Windows that ended, and keys without expiry, are closed right after the import
+	assert.Equal(t, time.Second, legacyDraftWindow(0, time.Minute))
+	assert.Equal(t, time.Second, legacyDraftWindow(-2, -1))
+}
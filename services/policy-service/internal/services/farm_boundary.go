@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"policy-service/internal/models"
+	"slices"
+)
+
+const (
+	// Share by which a declared area_sqm may differ from the area of the boundary
+	farmAreaTolerance = 0.15
+	// Overlaps with farms of other owners up to this area, or farmOverlapToleranceShare of the
+	// boundary if larger, are digitizing slivers along a shared edge and are accepted
+	farmOverlapToleranceSqm   = 10.0
+	farmOverlapToleranceShare = 0.01
+)
+
+// normalizeBoundary checks the rings of a WGS84 boundary and winds them as GeoJSON requires:
+// the exterior ring counterclockwise and the holes clockwise
+func normalizeBoundary(boundary *models.GeoJSONPolygon) error {
+	if boundary == nil || len(boundary.Coordinates) == 0 {
+		return fmt.Errorf("badrequest: boundary is required")
+	}
+	for i, ring := range boundary.Coordinates {
+		name := "exterior ring"
+		if i > 0 {
+			name = fmt.Sprintf("hole %d", i)
+		}
+		if len(ring) < 4 {
+			return fmt.Errorf("badrequest: boundary %s needs at least 4 positions, got %d", name, len(ring))
+		}
+		for j, position := range ring {
+			if len(position) < 2 {
+				return fmt.Errorf("badrequest: boundary %s position %d needs a longitude and a latitude", name, j)
+			}
+			lng, lat := position[0], position[1]
+			if math.IsNaN(lng) || math.IsNaN(lat) || lng < -180 || lng > 180 || lat < -90 || lat > 90 {
+				return fmt.Errorf("badrequest: boundary %s position %d (%f, %f) is outside WGS84 bounds", name, j, lng, lat)
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("badrequest: boundary %s is not closed: its last position must repeat the first", name)
+		}
+
+		area := signedRingArea(ring)
+		if area == 0 {
+			return fmt.Errorf("badrequest: boundary %s has no area", name)
+		}
+		if counterclockwise := area > 0; counterclockwise != (i == 0) {
+			slices.Reverse(ring)
+		}
+	}
+	return nil
+}
+
+// signedRingArea is twice the planar area of a closed ring, positive when it is counterclockwise
+func signedRingArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return area
+}
+
+// prepareBoundary validates the WGS84 boundary of a farm with PostGIS and derives its area and
+// center from it. A declared area_sqm must match the boundary; overlaps with the farms of other
+// owners beyond a sliver are rejected.
+func (s *FarmService) prepareBoundary(ctx context.Context, farm *models.Farm) error {
+	if err := normalizeBoundary(farm.Boundary); err != nil {
+		return err
+	}
+
+	analysis, err := s.farmRepository.AnalyzeBoundary(ctx, farm.Boundary)
+	if err != nil {
+		return err
+	}
+	if !analysis.Valid {
+		return fmt.Errorf("badrequest: boundary is not a valid polygon: %s", analysis.InvalidReason)
+	}
+	if analysis.AreaSqm <= 0 {
+		return fmt.Errorf("badrequest: boundary has no area")
+	}
+	if farm.AreaSqm > 0 && math.Abs(farm.AreaSqm-analysis.AreaSqm) > farmAreaTolerance*analysis.AreaSqm {
+		return fmt.Errorf("badrequest: area_sqm %.2f does not match the boundary area of %.2f m²", farm.AreaSqm, analysis.AreaSqm)
+	}
+
+	overlaps, err := s.farmRepository.GetBoundaryOverlaps(ctx, farm.Boundary, farm.OwnerID, farm.ID)
+	if err != nil {
+		return err
+	}
+	tolerance := math.Max(farmOverlapToleranceSqm, farmOverlapToleranceShare*analysis.AreaSqm)
+	if len(overlaps) > 0 && overlaps[0].OverlapSqm > tolerance {
+		farmCode := overlaps[0].FarmID.String()
+		if overlaps[0].FarmCode != nil {
+			farmCode = *overlaps[0].FarmCode
+		}
+		return fmt.Errorf("badrequest: boundary overlaps farm %s of another owner by %.2f m²", farmCode, overlaps[0].OverlapSqm)
+	}
+
+	farm.AreaSqm = math.Round(analysis.AreaSqm*100) / 100
+	if farm.CenterLocation == nil {
+		farm.CenterLocation = &models.GeoJSONPoint{Type: "Point"}
+	}
+	farm.CenterLocation.Coordinates = []float64{analysis.CentroidLng, analysis.CentroidLat}
+	return nil
+}
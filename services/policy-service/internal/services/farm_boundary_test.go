@@ -0,0 +1,57 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBoundary_RewindsRings(t *testing.T) {
+	boundary := &models.GeoJSONPolygon{
+		Type: "Polygon",
+		Coordinates: [][][]float64{
+			// Exterior ring clockwise
+			{{106.0, 10.0}, {106.0, 10.1}, {106.1, 10.1}, {106.1, 10.0}, {106.0, 10.0}},
+			// Hole counterclockwise
+			{{106.02, 10.02}, {106.04, 10.02}, {106.04, 10.04}, {106.02, 10.04}, {106.02, 10.02}},
+		},
+	}
+
+	require.NoError(t, normalizeBoundary(boundary))
+
+	assert.Greater(t, signedRingArea(boundary.Coordinates[0]), 0.0)
+	assert.Less(t, signedRingArea(boundary.Coordinates[1]), 0.0)
+	assert.Equal(t, []float64{106.0, 10.0}, boundary.Coordinates[0][0])
+}
+
+func TestNormalizeBoundary_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		boundary *models.GeoJSONPolygon
+		message  string
+	}{
+		{"missing", nil, "boundary is required"},
+		{"too few positions", &models.GeoJSONPolygon{Coordinates: [][][]float64{
+			{{106.0, 10.0}, {106.1, 10.0}, {106.0, 10.0}},
+		}}, "needs at least 4 positions"},
+		{"not closed", &models.GeoJSONPolygon{Coordinates: [][][]float64{
+			{{106.0, 10.0}, {106.1, 10.0}, {106.1, 10.1}, {106.0, 10.1}},
+		}}, "is not closed"},
+		{"projected coordinates", &models.GeoJSONPolygon{Coordinates: [][][]float64{
+			{{585000, 1190000}, {585100, 1190000}, {585100, 1190100}, {585000, 1190000}},
+		}}, "outside WGS84 bounds"},
+		{"collinear", &models.GeoJSONPolygon{Coordinates: [][][]float64{
+			{{106.0, 10.0}, {106.1, 10.0}, {106.2, 10.0}, {106.0, 10.0}},
+		}}, "has no area"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeBoundary(tt.boundary)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "badrequest")
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
@@ -2,14 +2,17 @@ package services
 
 import (
 	utils "agrisa_utils"
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
 	"net/http"
 	"net/url"
+	"policy-service/internal/event"
 	"policy-service/internal/models"
 	"policy-service/internal/worker"
 	"strconv"
@@ -19,8 +22,24 @@ import (
 	"github.com/google/uuid"
 )
 
-// RecoverActivePolicies recovers worker infrastructure for all active policies after restart
+// policyRecoveryLock keeps instances from recovering policies at the same time
+const policyRecoveryLock = "lock:policy-recovery"
+
+// RecoverPolicies recovers the worker infrastructure of the active policies no instance runs: all of
+// them after a restart, and those of an instance that died since. A recovery going on in another
+// instance is left to finish alone.
 func (s *RegisteredPolicyService) RecoverPolicies(ctx context.Context) error {
+	ran, err := s.workerManager.Locker().WithLock(ctx, policyRecoveryLock, 2*time.Minute, s.recoverPolicies)
+	if err != nil {
+		return err
+	}
+	if !ran {
+		slog.Info("Policy recovery running on another instance, skipping")
+	}
+	return nil
+}
+
+func (s *RegisteredPolicyService) recoverPolicies(ctx context.Context) error {
 	slog.Info("Recovering active policy worker infrastructure")
 
 	// Load active policy IDs from database
@@ -33,8 +52,16 @@ func (s *RegisteredPolicyService) RecoverPolicies(ctx context.Context) error {
 
 	// Recover each policy's infrastructure
 	successCount := 0
+	elsewhereCount := 0
 	for _, policy := range activePolicies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err := s.recoverPolicyInfrastructure(ctx, &policy); err != nil {
+			if errors.Is(err, worker.ErrPolicyRunElsewhere) {
+				elsewhereCount++
+				continue
+			}
 			slog.Error("Failed to recover policy infrastructure",
 				"policy_id", policy.ID,
 				"error", err)
@@ -46,7 +73,8 @@ func (s *RegisteredPolicyService) RecoverPolicies(ctx context.Context) error {
 	slog.Info("Worker infrastructure recovery completed",
 		"total", len(activePolicies),
 		"successful", successCount,
-		"failed", len(activePolicies)-successCount)
+		"run_elsewhere", elsewhereCount,
+		"failed", len(activePolicies)-successCount-elsewhereCount)
 
 	return nil
 }
@@ -86,6 +114,13 @@ func (s *RegisteredPolicyService) recoverPolicyInfrastructure(ctx context.Contex
 		slog.Info("policy infrastructure already exist")
 		return nil
 	}
+	runElsewhere, err := s.workerManager.PolicyRunElsewhere(ctx, policy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check policy ownership: %w", err)
+	}
+	if runElsewhere {
+		return worker.ErrPolicyRunElsewhere
+	}
 
 	// 2. Load base policy
 	basePolicy, err := s.basePolicyRepo.GetBasePolicyByID(policy.BasePolicyID)
@@ -137,6 +172,7 @@ func (s *RegisteredPolicyService) recoverPolicyInfrastructure(ctx context.Contex
 		},
 		MaxRetries: 5,
 		RunNow:     true,
+		RequestID:  logging.RequestID(ctx),
 	}
 
 	scheduler.AddJob(dailyJob)
@@ -190,6 +226,8 @@ type DataPoint struct {
 	Data  float64 `json:"data"`  // Precipitation in mm
 	Count int     `json:"count"` // Number of measurements
 	Unit  string  `json:"unit"`
+	// Confidence in the value from 0 to 1, when weather-service scored it
+	ConfidenceScore *float64 `json:"confidence_score,omitempty"`
 }
 
 type TimeRange struct {
@@ -300,6 +338,16 @@ func (s *RegisteredPolicyService) FetchFarmMonitoringDataJob(params map[string]a
 		return fmt.Errorf("fetch farm monitoring data job blocked by policy status: %v", policy.Status)
 	}
 
+	// Monitoring the farmer's land requires their explicit consent for policies registered since
+	// consent is recorded; older policies keep being monitored under their contract. Only an
+	// explicit "not granted" answer blocks the job; auth-service outages must not interrupt
+	// contractual monitoring.
+	if s.consentChecker != nil {
+		if err := s.checkMonitoringConsent(policy); err != nil {
+			return err
+		}
+	}
+
 	basePolicyID := policy.BasePolicyID
 	farmID := policy.FarmID
 
@@ -358,10 +406,11 @@ func (s *RegisteredPolicyService) FetchFarmMonitoringDataJob(params map[string]a
 	riskAnalysisJob := worker.JobPayload{
 		JobID:      uuid.NewString(),
 		Type:       "risk-analysis",
-		Params:     map[string]any{"registered_policy_id": policyID, "force_reanalysis": false},
+		Params:     map[string]any{"registered_policy_id": policyID, "force": false},
 		MaxRetries: 5,
 		OneTime:    true,
 		RunNow:     true,
+		Result:     &worker.JobResultRef{Resource: "registered_policy_risk_analysis", ID: policyID.String()},
 	}
 
 	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(policyID)
@@ -859,16 +908,25 @@ func (s *RegisteredPolicyService) FetchFarmMonitoringDataJob(params map[string]a
 
 // TriggeredCondition represents a condition that has been satisfied
 type TriggeredCondition struct {
-	ConditionID           uuid.UUID
-	ParameterName         models.DataSourceParameterName
-	MeasuredValue         float64
-	ThresholdValue        float64
-	Operator              models.ThresholdOperator
-	Timestamp             int64
-	BaselineValue         *float64 // Baseline value for change-based conditions
-	ConsecutiveDays       int      // Number of consecutive days condition was met
-	IsEarlyWarning        bool     // True if only early warning threshold was breached
-	EarlyWarningThreshold *float64 // Early warning threshold value if applicable
+	ConditionID           uuid.UUID                      `json:"condition_id"`
+	ParameterName         models.DataSourceParameterName `json:"parameter_name"`
+	MeasuredValue         float64                        `json:"measured_value"`
+	ThresholdValue        float64                        `json:"threshold_value"`
+	Operator              models.ThresholdOperator       `json:"operator"`
+	Timestamp             int64                          `json:"timestamp"`
+	BaselineValue         *float64                       `json:"baseline_value,omitempty"`          // Baseline value for change-based conditions
+	ConsecutiveDays       int                            `json:"consecutive_days,omitempty"`        // Number of consecutive days condition was met
+	IsEarlyWarning        bool                           `json:"is_early_warning"`                  // True if only early warning threshold was breached
+	EarlyWarningThreshold *float64                       `json:"early_warning_threshold,omitempty"` // Early warning threshold value if applicable
+}
+
+// TriggerEvaluation is the outcome of evaluating the triggers of a policy on demand. The
+// monitoring job generates a claim for the same outcome when ClaimCandidate is set.
+type TriggerEvaluation struct {
+	PolicyID            uuid.UUID            `json:"policy_id"`
+	EvaluatedAt         int64                `json:"evaluated_at"`
+	ClaimCandidate      bool                 `json:"claim_candidate"`
+	TriggeredConditions []TriggeredCondition `json:"triggered_conditions"`
 }
 
 // generateClaimFromTrigger creates a claim when trigger conditions are satisfied
@@ -929,12 +987,19 @@ func (s *RegisteredPolicyService) generateClaimFromTrigger(
 		return nil, fmt.Errorf("failed to get base policy: %w", err)
 	}
 
+	farm, err := s.farmService.GetByFarmID(ctx, farmID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get farm: %w", err)
+	}
+
 	// Calculate payout amounts
-	fixPayout, thresholdPayout, totalPayout, overThresholdValue := s.calculateClaimPayouts(
-		policy,
-		basePolicy,
-		triggeredConditions,
-	)
+	breachMagnitude := s.payoutCalculator.BreachMagnitude(triggeredConditions)
+	payout := s.payoutCalculator.Calculate(basePolicy, farm.AreaSqm, breachMagnitude)
+	fixPayout, thresholdPayout, totalPayout := payout.FixPayout, payout.ThresholdPayout, payout.TotalPayout
+	var overThresholdValue *float64
+	if breachMagnitude > 0 {
+		overThresholdValue = &breachMagnitude
+	}
 
 	// Build evidence summary from triggered conditions
 	evidenceSummary := s.buildEvidenceSummary(triggeredConditions)
@@ -965,8 +1030,12 @@ func (s *RegisteredPolicyService) generateClaimFromTrigger(
 		EvidenceSummary:           evidenceSummary,
 	}
 
-	// Save claim to database
-	if err := s.registeredPolicyRepo.CreateClaim(claim); err != nil {
+	// Save claim to database, along with the notification to the farmer
+	notification, err := event.ClaimCreatedNotification(ctx, policy, claim)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.registeredPolicyRepo.CreateClaim(claim, notification); err != nil {
 		return nil, fmt.Errorf("failed to create claim: %w", err)
 	}
 
@@ -978,73 +1047,9 @@ func (s *RegisteredPolicyService) generateClaimFromTrigger(
 		"total_payout", totalPayout,
 		"over_threshold_value", overThresholdValue)
 
-	go func() {
-		for {
-			err := s.notievent.NotifyClaimGenerated(context.Background(), policy.FarmerID, policy.PolicyNumber)
-			if err == nil {
-				slog.Info("claim generated notification sent", "policy id", policy.ID)
-				return
-			}
-			slog.Error("error sending claim generated notification", "error", err)
-			time.Sleep(10 * time.Second)
-		}
-	}()
 	return claim, nil
 }
 
-// calculateClaimPayouts calculates the payout amounts for a claim
-func (s *RegisteredPolicyService) calculateClaimPayouts(
-	policy *models.RegisteredPolicy,
-	basePolicy *models.BasePolicy,
-	triggeredConditions []TriggeredCondition,
-) (fixPayout float64, thresholdPayout float64, totalPayout float64, overThresholdValue *float64) {
-	// Calculate fix payout (base payout amount)
-	fixPayout = policy.CoverageAmount
-
-	// Calculate over-threshold payout based on how much the condition exceeded the threshold
-	var maxOverThreshold float64
-	for _, tc := range triggeredConditions {
-		if tc.IsEarlyWarning {
-			continue // Skip early warnings for payout calculation
-		}
-
-		// Calculate how much the measured value exceeded the threshold
-		var overAmount float64
-		switch tc.Operator {
-		case models.ThresholdGT, models.ThresholdGTE, models.ThresholdChangeGT:
-			// For "greater than" operators, the over amount is measured - threshold
-			overAmount = tc.MeasuredValue - tc.ThresholdValue
-		case models.ThresholdLT, models.ThresholdLTE, models.ThresholdChangeLT:
-			// For "less than" operators, the over amount is threshold - measured
-			overAmount = tc.ThresholdValue - tc.MeasuredValue
-		}
-
-		if overAmount > maxOverThreshold {
-			maxOverThreshold = overAmount
-		}
-	}
-
-	if maxOverThreshold > 0 {
-		overThresholdValue = &maxOverThreshold
-		// Calculate threshold payout using the over-threshold multiplier
-		thresholdPayout = maxOverThreshold * basePolicy.OverThresholdMultiplier
-	}
-
-	// Total payout is fix + threshold payout
-	totalPayout = fixPayout + thresholdPayout
-
-	// Apply payout cap if specified
-	if basePolicy.PayoutCap != nil && totalPayout > float64(*basePolicy.PayoutCap) {
-		totalPayout = float64(*basePolicy.PayoutCap)
-		slog.Info("Payout capped",
-			"original_total", fixPayout+thresholdPayout,
-			"capped_total", totalPayout,
-			"payout_cap", *basePolicy.PayoutCap)
-	}
-
-	return fixPayout, thresholdPayout, totalPayout, overThresholdValue
-}
-
 // buildEvidenceSummary creates a JSON summary of triggered conditions for the claim
 func (s *RegisteredPolicyService) buildEvidenceSummary(triggeredConditions []TriggeredCondition) utils.JSONMap {
 	evidence := utils.JSONMap{
@@ -1085,7 +1090,42 @@ func (s *RegisteredPolicyService) buildEvidenceSummary(triggeredConditions []Tri
 	return evidence
 }
 
-// evaluateTriggerConditions checks if fetched monitoring data satisfies trigger conditions
+// EvaluatePolicyTriggers runs the trigger evaluation of the monitoring job against the monitoring
+// data stored for a policy, without generating a claim. An empty providerID skips the ownership
+// check for admin callers.
+func (s *RegisteredPolicyService) EvaluatePolicyTriggers(ctx context.Context, policyID uuid.UUID, providerID string) (*TriggerEvaluation, error) {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if providerID != "" && policy.InsuranceProviderID != providerID {
+		return nil, fmt.Errorf("unauthorized: policy belongs to another provider")
+	}
+
+	triggers, err := s.basePolicyRepo.GetBasePolicyTriggersByPolicyID(policy.BasePolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base policy triggers: %w", err)
+	}
+
+	// Without freshly fetched data the evaluation only reads the stored history of the farm
+	triggeredConditions := s.evaluateTriggerConditions(ctx, triggers, nil, policy.FarmID, policy)
+	if triggeredConditions == nil {
+		triggeredConditions = []TriggeredCondition{}
+	}
+
+	return &TriggerEvaluation{
+		PolicyID:            policy.ID,
+		EvaluatedAt:         time.Now().Unix(),
+		ClaimCandidate:      len(triggeredConditions) > 0,
+		TriggeredConditions: triggeredConditions,
+	}, nil
+}
+
+// evaluateTriggerConditions checks if fetched monitoring data satisfies trigger conditions.
+// Each condition aggregates the data of its data source over its aggregation window
+// (sum/avg/min/max/change), optionally against a baseline window, and may require the threshold
+// to hold on consecutive days. The results of a trigger are combined with its logical operator;
+// the conditions of every satisfied trigger are returned and the caller turns them into a claim.
 func (s *RegisteredPolicyService) evaluateTriggerConditions(
 	ctx context.Context,
 	triggers []models.BasePolicyTrigger,
@@ -1411,44 +1451,12 @@ func (s *RegisteredPolicyService) evaluateTriggerConditions(
 
 // isInBlackoutPeriod checks if current time falls within any blackout period
 func (s *RegisteredPolicyService) isInBlackoutPeriod(blackoutPeriods utils.JSONMap, currentTime time.Time) bool {
-	if blackoutPeriods == nil {
-		return false
-	}
-
-	// Blackout periods expected format: {"periods": [{"start": "MM-DD", "end": "MM-DD"}, ...]}
-	periods, ok := blackoutPeriods["periods"].([]any)
-	if !ok {
-		return false
-	}
-
 	currentMonthDay := currentTime.Format("01-02")
-
-	for _, p := range periods {
-		period, ok := p.(map[string]any)
-		if !ok {
-			continue
-		}
-
-		start, startOk := period["start"].(string)
-		end, endOk := period["end"].(string)
-		if !startOk || !endOk {
-			continue
-		}
-
-		// Simple string comparison for MM-DD format
-		if start <= end {
-			// Normal range (e.g., 03-01 to 05-31)
-			if currentMonthDay >= start && currentMonthDay <= end {
-				return true
-			}
-		} else {
-			// Wrapping range (e.g., 11-01 to 02-28)
-			if currentMonthDay >= start || currentMonthDay <= end {
-				return true
-			}
+	for _, period := range parseBlackoutPeriods(blackoutPeriods) {
+		if period.contains(currentMonthDay) {
+			return true
 		}
 	}
-
 	return false
 }
 
@@ -1982,6 +1990,21 @@ func fetchDataWithRetry(
 	return nil, "", fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
 }
 
+// Confidence of weather values that weather-service did not score
+const defaultWeatherConfidenceScore = 0.9
+
+// dataQualityFromConfidence grades a value by its confidence score
+func dataQualityFromConfidence(confidenceScore float64) models.DataQuality {
+	switch {
+	case confidenceScore >= 0.75:
+		return models.DataQualityGood
+	case confidenceScore >= 0.5:
+		return models.DataQualityAcceptable
+	default:
+		return models.DataQualityPoor
+	}
+}
+
 func fetchWeatherData(client *http.Client,
 	endpoint string,
 	req DataRequest,
@@ -2067,10 +2090,13 @@ func fetchWeatherData(client *http.Client,
 			End   int64 `json:"end"`
 		} `json:"time_range"`
 		Data []struct {
-			Dt    int64   `json:"dt"`
-			Data  float64 `json:"data"`
-			Count int     `json:"count"`
-			Unit  string  `json:"unit"`
+			Dt              int64    `json:"dt"`
+			Data            float64  `json:"data"`
+			Count           int      `json:"count"`
+			Unit            string   `json:"unit"`
+			ConfidenceScore *float64 `json:"confidence_score"`
+			// Cloud cover of the satellite image a vegetation index was read from
+			CloudCover *float64 `json:"cloud_cover"`
 		} `json:"data"`
 		TotalDataValue float64 `json:"total_data_value"`
 		DataPointCount int     `json:"data_point_count"`
@@ -2090,11 +2116,12 @@ func fetchWeatherData(client *http.Client,
 	var monitoringData []models.FarmMonitoringData
 
 	for _, dataPoint := range apiResp.Data {
-		// Determine data quality based on measurement count
-		dataQuality := models.DataQualityGood
-
-		// Calculate confidence score based on data count
-		confidenceScore := 0.9
+		// Weather-service scores each value; older responses without a score keep the default
+		confidenceScore := defaultWeatherConfidenceScore
+		if dataPoint.ConfidenceScore != nil {
+			confidenceScore = *dataPoint.ConfidenceScore
+		}
+		dataQuality := dataQualityFromConfidence(confidenceScore)
 
 		// Build component data
 		componentData := utils.JSONMap{
@@ -2115,6 +2142,7 @@ func fetchWeatherData(client *http.Client,
 			ComponentData:        componentData,
 			DataQuality:          dataQuality,
 			ConfidenceScore:      &confidenceScore,
+			CloudCoverPercentage: dataPoint.CloudCover,
 			MeasurementSource:    req.DataSource.DataProvider,
 			CreatedAt:            time.Now(),
 		})
@@ -2432,3 +2460,35 @@ func countTrueValues(values []bool) int {
 	}
 	return count
 }
+
+// checkMonitoringConsent returns an error when the policy is gated on its farmer's satellite
+// monitoring consent and the farmer has not granted it
+func (s *RegisteredPolicyService) checkMonitoringConsent(policy *models.RegisteredPolicy) error {
+	required, err := s.registeredPolicyRepo.MonitoringConsentRequired(policy.ID)
+	if err != nil {
+		slog.Warn("consent requirement check failed, continuing monitoring",
+			"policy_id", policy.ID,
+			"error", err)
+		return nil
+	}
+	if !required {
+		return nil
+	}
+
+	granted, err := s.consentChecker.HasConsent(policy.FarmerID, ConsentSatelliteMonitoring)
+	if err != nil {
+		slog.Warn("consent check failed, continuing monitoring",
+			"policy_id", policy.ID,
+			"farmer_id", policy.FarmerID,
+			"error", err)
+		return nil
+	}
+	if !granted {
+		slog.Warn("Job blocked by missing consent",
+			"policy_id", policy.ID,
+			"farmer_id", policy.FarmerID,
+			"consent_type", ConsentSatelliteMonitoring)
+		return fmt.Errorf("fetch farm monitoring data job blocked: farmer %s has not consented to %s", policy.FarmerID, ConsentSatelliteMonitoring)
+	}
+	return nil
+}
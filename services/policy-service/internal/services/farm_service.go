@@ -10,6 +10,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"policy-service/internal/ai/gemini"
 	"policy-service/internal/config"
 	"policy-service/internal/database/minio"
 	"policy-service/internal/models"
@@ -27,10 +28,14 @@ type FarmService struct {
 	config         *config.PolicyServiceConfig
 	minioClient    *minio.MinioClient
 	workerManager  *worker.WorkerManagerV2
+	scanService    *DocumentScanService
+	geminiSelector *gemini.GeminiClientSelector
+	auditService   *AuditService
+	aiUsageService *AIUsageService
 }
 
-func NewFarmService(farmRepo *repository.FarmRepository, cfg *config.PolicyServiceConfig, minioClient *minio.MinioClient, workerManager *worker.WorkerManagerV2) *FarmService {
-	return &FarmService{farmRepository: farmRepo, config: cfg, minioClient: minioClient, workerManager: workerManager}
+func NewFarmService(farmRepo *repository.FarmRepository, cfg *config.PolicyServiceConfig, minioClient *minio.MinioClient, workerManager *worker.WorkerManagerV2, scanService *DocumentScanService, geminiSelector *gemini.GeminiClientSelector, auditService *AuditService, aiUsageService *AIUsageService) *FarmService {
+	return &FarmService{farmRepository: farmRepo, config: cfg, minioClient: minioClient, workerManager: workerManager, scanService: scanService, geminiSelector: geminiSelector, auditService: auditService, aiUsageService: aiUsageService}
 }
 
 func (s *FarmService) GetFarmByOwnerID(ctx context.Context, userID string) ([]models.Farm, error) {
@@ -72,13 +77,10 @@ func (s *FarmService) CreateFarm(farm *models.Farm, ownerID string) error {
 		return err
 	}
 
-	// Calculate center location to WGS84
-	centralPoint := CalculateFarmCenter(*farm.Boundary)
-	if farm.CenterLocation == nil {
-		farm.CenterLocation = &models.GeoJSONPoint{}
-		farm.CenterLocation.Type = "Point"
+	// Validate the converted boundary and derive the area and center location from it
+	if err := s.prepareBoundary(context.Background(), farm); err != nil {
+		return err
 	}
-	farm.CenterLocation.Coordinates = []float64{centralPoint.Lng, centralPoint.Lat}
 
 	err = s.farmRepository.Create(farm)
 	if err != nil {
@@ -114,12 +116,24 @@ func (s *FarmService) CreateFarm(farm *models.Farm, ownerID string) error {
 		MaxRetries: 100,
 		OneTime:    false,
 	}
+	// A year of weather history for risk analysis, loaded over as many runs as the provider
+	// budget needs
+	weatherBackfillJob := worker.JobPayload{
+		JobID:      uuid.NewString(),
+		Type:       "farm-weather-backfill",
+		Params:     map[string]any{"farm_id": farm.ID, "start_date": previousYearFormattedTime, "end_date": formattedTime},
+		MaxRetries: 500,
+		OneTime:    true,
+		RunNow:     true,
+	}
 	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(farm.ID)
 	if !ok {
 		slog.Error("error get farm-imagery scheduler", "error", "scheduler doesn't exist")
 	}
 	scheduler.AddJob(fullYearJob)
 	scheduler.AddJob(everydayJob)
+	scheduler.AddJob(weatherBackfillJob)
+	scheduler.AddJob(cropVerificationJob(farm.ID))
 	return nil
 }
 
@@ -153,13 +167,10 @@ func (s *FarmService) CreateFarmTx(farm *models.Farm, ownerID string, tx *sqlx.T
 		return errConvert
 	}
 
-	// Calculate center location to WGS84
-	centralPoint := CalculateFarmCenter(*farm.Boundary)
-	if farm.CenterLocation == nil {
-		farm.CenterLocation = &models.GeoJSONPoint{}
-		farm.CenterLocation.Type = "Point"
+	// Validate the converted boundary and derive the area and center location from it
+	if err := s.prepareBoundary(context.Background(), farm); err != nil {
+		return err
 	}
-	farm.CenterLocation.Coordinates = []float64{centralPoint.Lng, centralPoint.Lat}
 
 	err := s.farmRepository.CreateTx(tx, farm)
 	if err != nil {
@@ -195,12 +206,24 @@ func (s *FarmService) CreateFarmTx(farm *models.Farm, ownerID string, tx *sqlx.T
 		MaxRetries: 100,
 		OneTime:    false,
 	}
+	// A year of weather history for risk analysis, loaded over as many runs as the provider
+	// budget needs
+	weatherBackfillJob := worker.JobPayload{
+		JobID:      uuid.NewString(),
+		Type:       "farm-weather-backfill",
+		Params:     map[string]any{"farm_id": farm.ID, "start_date": previousYearFormattedTime, "end_date": formattedTime},
+		MaxRetries: 500,
+		OneTime:    true,
+		RunNow:     true,
+	}
 	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(farm.ID)
 	if !ok {
 		slog.Error("error get farm-imagery scheduler", "error", "scheduler doesn't exist")
 	}
 	scheduler.AddJob(fullYearJob)
 	scheduler.AddJob(everydayJob)
+	scheduler.AddJob(weatherBackfillJob)
+	scheduler.AddJob(cropVerificationJob(farm.ID))
 	return nil
 }
 
@@ -208,6 +231,11 @@ func (s *FarmService) GetAllFarms(ctx context.Context) ([]models.Farm, error) {
 	return s.farmRepository.GetAll(ctx)
 }
 
+// GetInsuredFarmLocations returns the locations that external monitors should poll
+func (s *FarmService) GetInsuredFarmLocations(ctx context.Context) ([]models.InsuredFarmLocation, error) {
+	return s.farmRepository.GetInsuredFarmLocations(ctx)
+}
+
 func (s *FarmService) GetByFarmID(ctx context.Context, farmID string) (*models.Farm, error) {
 	return s.farmRepository.GetFarmByID(ctx, farmID)
 }
@@ -328,7 +356,9 @@ func (s *FarmService) DeleteFarm(ctx context.Context, id string, deletedBy strin
 	return s.farmRepository.Delete(farmID)
 }
 
-func (s *FarmService) VerifyLandCertificateAPI(nationalIDInput string, token string) (bool, error) {
+// VerifyLandCertificateAPI checks with auth-service that the national ID is the one the farmer
+// verified through eKYC, and returns the name on the card
+func (s *FarmService) VerifyLandCertificateAPI(nationalIDInput string, token string) (bool, string, error) {
 	apiURl := s.config.VerifyNationalIDURL
 	requestBody := models.VerifyNationalIDRequest{
 		NationalID: nationalIDInput,
@@ -337,13 +367,13 @@ func (s *FarmService) VerifyLandCertificateAPI(nationalIDInput string, token str
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		slog.Error("failed to marshal request body", "error", err)
-		return false, fmt.Errorf("badrequest: failed to marshal request body: %w", err)
+		return false, "", fmt.Errorf("badrequest: failed to marshal request body: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", apiURl, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		slog.Error("failed to create HTTP request", "error", err)
-		return false, fmt.Errorf("internal_error: failed to create HTTP request")
+		return false, "", fmt.Errorf("internal_error: failed to create HTTP request")
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -355,45 +385,48 @@ func (s *FarmService) VerifyLandCertificateAPI(nationalIDInput string, token str
 	resp, err := client.Do(req)
 	if err != nil {
 		slog.Error("failed to send HTTP request", "error", err)
-		return false, fmt.Errorf("internal_error: failed to send HTTP request")
+		return false, "", fmt.Errorf("internal_error: failed to send HTTP request")
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		slog.Error("failed to read response body", "error", err)
-		return false, fmt.Errorf("internal_error: failed to read response body")
+		return false, "", fmt.Errorf("internal_error: failed to read response body")
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errorResp models.VerifyNationalIDErrorResponse
 		if err := json.Unmarshal(body, &errorResp); err != nil {
 			slog.Error("failed to unmarshal error response of API Verify nationalID", "error", err, "response_body", string(body))
-			return false, fmt.Errorf("internal_error")
+			return false, "", fmt.Errorf("internal_error")
 		}
-		return false, fmt.Errorf("API Verify nationalID error: code=%s, message=%s", errorResp.Error.Code, errorResp.Error.Message)
+		return false, "", fmt.Errorf("API Verify nationalID error: code=%s, message=%s", errorResp.Error.Code, errorResp.Error.Message)
 	}
 
 	var successResp models.VerifyNationalIDResponse
 	if err := json.Unmarshal(body, &successResp); err != nil {
-		return false, fmt.Errorf("internal_error: failed to unmarshal success response: %w", err)
+		return false, "", fmt.Errorf("internal_error: failed to unmarshal success response: %w", err)
 	}
 
-	return successResp.Data.IsValid, nil
+	return successResp.Data.IsValid, successResp.Data.OwnerName, nil
 }
 
 func (s *FarmService) VerifyLandCertificate(verifyRequest models.VerifyLandCertificateRequest, farm *models.Farm) (err error) {
-	isLandCertificateVerify, err := s.VerifyLandCertificateAPI(verifyRequest.OwnerNationalID, verifyRequest.Token)
+	isLandCertificateVerify, identityName, err := s.VerifyLandCertificateAPI(verifyRequest.OwnerNationalID, verifyRequest.Token)
 	if err != nil {
 		return err
 	}
 	if !isLandCertificateVerify {
 		return fmt.Errorf("unauthorized: land certificate verification failed")
 	}
+	if identityName == "" {
+		return fmt.Errorf("internal_error: auth-service returned no name for the national ID")
+	}
 
-	farm.LandOwnershipVerified = true
-	secondnow := time.Now().Unix()
-	farm.LandOwnershipVerifiedAt = &secondnow
+	// Ownership is verified once the OCR of the certificate finds the farmer among its owners
+	farm.LandOwnershipVerified = false
+	farm.LandOwnershipVerifiedAt = nil
 
 	// upload land certificate image to MinIO
 	fileuploadRquest := []minio.FileUpload{}
@@ -413,6 +446,29 @@ func (s *FarmService) VerifyLandCertificate(verifyRequest models.VerifyLandCerti
 
 	landCertificateURLs := minio.JoinResourceURLs(fileUploadedInfos)
 	farm.LandCertificateURL = &landCertificateURLs
+
+	// The farm is created after this check, its ID is set now so the scans and the OCR refer to it
+	if farm.ID == uuid.Nil {
+		farm.ID = uuid.New()
+	}
+	objectKeys := make([]string, 0, len(fileUploadedInfos))
+	for _, info := range fileUploadedInfos {
+		objectKeys = append(objectKeys, info.ObjectName)
+	}
+	ocrJob := landCertificateOCRJob(farm.ID, identityName, objectKeys)
+
+	// The photos are already served, a suspicious one is pulled once its scan finishes. The OCR
+	// waits for the last scan and reads the photos still in the bucket.
+	for i, info := range fileUploadedInfos {
+		var followUp *worker.JobPayload
+		if i == len(fileUploadedInfos)-1 {
+			followUp = &ocrJob
+		}
+		if _, err := s.scanService.QueueScan(context.Background(), models.DocumentScanLandCertificate,
+			minio.Storage.PolicyDocuments, info.ObjectName, &farm.ID, followUp); err != nil {
+			return fmt.Errorf("failed to queue land certificate scan: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -663,8 +719,12 @@ func (s *FarmService) CreateFarmValidate(farm *models.Farm, token string) error
 		return fmt.Errorf("bad_request: crop_type is required")
 	}
 
-	if farm.AreaSqm <= 0 {
-		return fmt.Errorf("bad_request: area_sqm must be greater than 0")
+	// The area is measured from the boundary, so area_sqm may be left out
+	if farm.Boundary == nil {
+		return fmt.Errorf("bad_request: boundary is required")
+	}
+	if farm.AreaSqm < 0 {
+		return fmt.Errorf("bad_request: area_sqm must not be negative")
 	}
 
 	if !ValidateCroptype(farm.CropType) {
@@ -771,6 +831,7 @@ func (s *FarmService) FarmJobRecovery() error {
 		}
 
 		scheduler.AddJob(everydayJob)
+		scheduler.AddJob(cropVerificationJob(farm.ID))
 
 		slog.Info("FarmJobRecovery: successfully recovered farm", "farm_id", farm.ID, "jobs_added", 2)
 		successCount++
@@ -857,55 +918,6 @@ func ConvertVN2000ToWGS84(x, y, zoneWidth, centralMeridian float64) (*VN2000ToWG
 	return &result, nil
 }
 
-type Point struct {
-	Lng float64
-	Lat float64
-}
-
-func CalculateFarmCenter(boundary models.GeoJSONPolygon) Point {
-	if len(boundary.Coordinates) == 0 || len(boundary.Coordinates[0]) == 0 {
-		return Point{}
-	}
-
-	outerRing := boundary.Coordinates[0]
-
-	return CalculatePolygonCentroid(outerRing)
-}
-
-func CalculatePolygonCentroid(coordinates [][]float64) Point {
-	if len(coordinates) == 0 {
-		return Point{}
-	}
-
-	var area float64
-	var centroidLng float64
-	var centroidLat float64
-
-	// Tính diện tích có dấu và centroid
-	for i := 0; i < len(coordinates)-1; i++ {
-		x0 := coordinates[i][0]
-		y0 := coordinates[i][1]
-		x1 := coordinates[i+1][0]
-		y1 := coordinates[i+1][1]
-
-		// Cross product
-		cross := x0*y1 - x1*y0
-
-		area += cross
-		centroidLng += (x0 + x1) * cross
-		centroidLat += (y0 + y1) * cross
-	}
-
-	area /= 2.0
-	centroidLng /= (6.0 * area)
-	centroidLat /= (6.0 * area)
-
-	return Point{
-		Lng: centroidLng,
-		Lat: centroidLat,
-	}
-}
-
 func ValidateCroptype(cropType string) bool {
 	cropTypes := []string{"rice", "coffee"}
 	for _, ct := range cropTypes {
@@ -0,0 +1,264 @@
+package services
+
+import (
+	utils "agrisa_utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"policy-service/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// weatherBackfillPath loads provider archives into the weather history of a polygon
+	weatherBackfillPath = "/weather/internal/api/v2/history/backfill"
+	// A backfill run stays under the 5 minute job timeout of the farm pool; an unfinished
+	// backfill fails the run so the job is retried
+	weatherBackfillRunTimeout = 4 * time.Minute
+	// Wait before asking again when weather-service fetched nothing, as while its provider budget
+	// is held back
+	weatherBackfillIdleWait = time.Minute
+)
+
+// weatherBackfillResponse matches the backfill response of weather-service
+type weatherBackfillResponse struct {
+	PolygonID     string                 `json:"polygon_id"`
+	DaysRequested int                    `json:"days_requested"`
+	DaysFetched   int                    `json:"days_fetched"`
+	DaysMissing   int                    `json:"days_missing"`
+	Complete      bool                   `json:"complete"`
+	Data          map[string][]DataPoint `json:"data"`
+}
+
+// requestWeatherBackfill asks weather-service for one backfill round of a farm
+func requestWeatherBackfill(client *http.Client, endpoint string, farmCoordinates [][]float64, agroPolygonID *string, start, end time.Time) (*weatherBackfillResponse, error) {
+	if len(farmCoordinates) < 4 {
+		return nil, fmt.Errorf("insufficient coordinates: need at least 4 points, got %d", len(farmCoordinates))
+	}
+
+	params := url.Values{}
+	for i := 0; i < 4; i++ {
+		if len(farmCoordinates[i]) < 2 {
+			return nil, fmt.Errorf("invalid coordinate at index %d", i)
+		}
+		params.Set(fmt.Sprintf("lat%d", i+1), fmt.Sprintf("%.6f", farmCoordinates[i][1]))
+		params.Set(fmt.Sprintf("lon%d", i+1), fmt.Sprintf("%.6f", farmCoordinates[i][0]))
+	}
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	if agroPolygonID != nil {
+		params.Set("polygon_id", *agroPolygonID)
+	}
+
+	resp, err := client.Post(endpoint+"?"+params.Encode(), "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("backfill request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("backfill returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var backfill weatherBackfillResponse
+	if err := json.NewDecoder(resp.Body).Decode(&backfill); err != nil {
+		return nil, fmt.Errorf("failed to parse backfill response: %w", err)
+	}
+	return &backfill, nil
+}
+
+// backfillMonitoringData converts the daily values of a backfill for a data source, leaving out
+// the days the farm already has data of the source for
+func backfillMonitoringData(farmID uuid.UUID, dataSource models.DataSource, polygonID string, points []DataPoint, existing map[int64]bool) []models.FarmMonitoringData {
+	var monitoringData []models.FarmMonitoringData
+	for _, point := range points {
+		if existing[point.Dt] {
+			continue
+		}
+		confidenceScore := defaultWeatherConfidenceScore
+		if point.ConfidenceScore != nil {
+			confidenceScore = *point.ConfidenceScore
+		}
+		unit := point.Unit
+		monitoringData = append(monitoringData, models.FarmMonitoringData{
+			ID:                   uuid.New(),
+			FarmID:               farmID,
+			DataSourceID:         dataSource.ID,
+			ParameterName:        dataSource.ParameterName,
+			MeasuredValue:        point.Data,
+			Unit:                 &unit,
+			MeasurementTimestamp: point.Dt,
+			ComponentData: utils.JSONMap{
+				"measurement_count": point.Count,
+				"polygon_id":        polygonID,
+				"backfill":          true,
+			},
+			DataQuality:       dataQualityFromConfidence(confidenceScore),
+			ConfidenceScore:   &confidenceScore,
+			MeasurementSource: dataSource.DataProvider,
+			CreatedAt:         time.Now(),
+		})
+	}
+	return monitoringData
+}
+
+// FarmWeatherBackfillJob loads a year of weather history of a newly registered farm, so that risk
+// analysis has the history monitoring never collected. Weather-service fetches the history from
+// provider archives over several rounds within its provider budget; once it is complete, the
+// daily values of every active weather data source and the derived data sources computed from
+// them are stored as farm monitoring data.
+func (s *RegisteredPolicyService) FarmWeatherBackfillJob(params map[string]any) error {
+	farmIDStr, ok := params["farm_id"].(string)
+	if !ok {
+		slog.Error("FarmWeatherBackfillJob: missing or invalid farm_id parameter")
+		return fmt.Errorf("missing or invalid farm_id parameter")
+	}
+	farmID, err := uuid.Parse(farmIDStr)
+	if err != nil {
+		slog.Error("FarmWeatherBackfillJob: invalid farm_id format", "error", err)
+		return fmt.Errorf("invalid farm_id format: %w", err)
+	}
+	startDateStr, _ := params["start_date"].(string)
+	endDateStr, _ := params["end_date"].(string)
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		return fmt.Errorf("invalid start_date parameter: %w", err)
+	}
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		return fmt.Errorf("invalid end_date parameter: %w", err)
+	}
+
+	ctx := context.Background()
+	farm, err := s.farmService.GetByFarmID(ctx, farmID.String())
+	if err != nil {
+		return fmt.Errorf("failed to load farm: %w", err)
+	}
+	if farm.Boundary == nil {
+		return fmt.Errorf("farm boundary is required for weather backfill")
+	}
+	farmCoordinates := extractPolygonCoordinates(farm.Boundary)
+
+	slog.Info("FarmWeatherBackfillJob: starting backfill",
+		"farm_id", farmID,
+		"agro_polygon_id", farm.AgroPolygonID,
+		"start_date", startDateStr,
+		"end_date", endDateStr)
+
+	httpClient := &http.Client{Timeout: 120 * time.Second}
+	endpoint := s.farmService.config.WeatherDataServiceURL + weatherBackfillPath
+	deadline := time.Now().Add(weatherBackfillRunTimeout)
+	var backfill *weatherBackfillResponse
+	for {
+		backfill, err = requestWeatherBackfill(httpClient, endpoint, farmCoordinates, farm.AgroPolygonID, startDate, endDate)
+		if err != nil {
+			slog.Error("FarmWeatherBackfillJob: backfill round failed", "farm_id", farmID, "error", err)
+			return err
+		}
+		slog.Info("FarmWeatherBackfillJob: backfill round completed",
+			"farm_id", farmID,
+			"polygon_id", backfill.PolygonID,
+			"days_requested", backfill.DaysRequested,
+			"days_fetched", backfill.DaysFetched,
+			"days_missing", backfill.DaysMissing)
+
+		// Later rounds must extend the history of the same polygon
+		if backfill.PolygonID != "" && (farm.AgroPolygonID == nil || *farm.AgroPolygonID != backfill.PolygonID) {
+			polygonID := backfill.PolygonID
+			farm.AgroPolygonID = &polygonID
+			if err := s.farmService.UpdateFarm(ctx, farm, "system", farmID.String()); err != nil {
+				slog.Error("FarmWeatherBackfillJob: failed to update farm AgroPolygonID",
+					"farm_id", farmID,
+					"polygon_id", polygonID,
+					"error", err)
+			}
+		}
+		if backfill.Complete {
+			break
+		}
+		if backfill.DaysFetched == 0 {
+			if time.Now().Add(weatherBackfillIdleWait).After(deadline) {
+				return fmt.Errorf("weather backfill incomplete: %d of %d days missing", backfill.DaysMissing, backfill.DaysRequested)
+			}
+			time.Sleep(weatherBackfillIdleWait)
+		} else if time.Now().After(deadline) {
+			return fmt.Errorf("weather backfill incomplete: %d of %d days missing", backfill.DaysMissing, backfill.DaysRequested)
+		}
+	}
+
+	dataSources, err := s.dataSourceRepo.GetActiveDataSources()
+	if err != nil {
+		return fmt.Errorf("failed to load data sources: %w", err)
+	}
+
+	var allMonitoringData []models.FarmMonitoringData
+	for _, dataSource := range dataSources {
+		if dataSource.DataSource != models.DataSourceWeather && dataSource.DataSource != models.DataSourceDerived {
+			continue
+		}
+
+		stored, err := s.farmMonitoringDataRepo.GetByTimeRangeAndParameter(ctx, farmID, string(dataSource.ParameterName), startDate.Unix(), endDate.Add(24*time.Hour).Unix())
+		if err != nil {
+			return fmt.Errorf("failed to load stored %s: %w", dataSource.ParameterName, err)
+		}
+		existing := map[int64]bool{}
+		for _, data := range stored {
+			if data.DataSourceID == dataSource.ID {
+				existing[data.MeasurementTimestamp] = true
+			}
+		}
+
+		if points, ok := backfill.Data[string(dataSource.ParameterName)]; ok {
+			allMonitoringData = append(allMonitoringData, backfillMonitoringData(farmID, dataSource, backfill.PolygonID, points, existing)...)
+			continue
+		}
+		// Derived data sources are computed by weather-service from the backfilled history
+		if dataSource.DataSource != models.DataSourceDerived || dataSource.APIEndpoint == nil {
+			continue
+		}
+		monitoringData, _, err := fetchWeatherData(httpClient, *dataSource.APIEndpoint, DataRequest{
+			DataSource:      dataSource,
+			FarmID:          farmID,
+			FarmCoordinates: farmCoordinates,
+			AgroPolygonID:   &backfill.PolygonID,
+			StartDate:       startDateStr,
+			EndDate:         endDateStr,
+			DataSourceID:    dataSource.ID,
+		})
+		if err != nil {
+			slog.Error("FarmWeatherBackfillJob: failed to fetch derived data source",
+				"farm_id", farmID,
+				"parameter", dataSource.ParameterName,
+				"error", err)
+			return fmt.Errorf("failed to fetch %s: %w", dataSource.ParameterName, err)
+		}
+		for _, data := range monitoringData {
+			if existing[data.MeasurementTimestamp] {
+				continue
+			}
+			data.ComponentData["backfill"] = true
+			allMonitoringData = append(allMonitoringData, data)
+		}
+	}
+
+	if len(allMonitoringData) > 0 {
+		if err := s.farmMonitoringDataRepo.CreateBatch(ctx, allMonitoringData); err != nil {
+			return fmt.Errorf("failed to store backfilled monitoring data: %w", err)
+		}
+	}
+
+	slog.Info("FarmWeatherBackfillJob: backfill stored",
+		"farm_id", farmID,
+		"polygon_id", backfill.PolygonID,
+		"days", backfill.DaysRequested,
+		"records", len(allMonitoringData))
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"policy-service/internal/database/redis"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// How long a completed response is replayed for
+	idempotencyTTL = 24 * time.Hour
+	// How long a key stays reserved by a request that never completes, e.g. after a crash
+	idempotencyReservationTTL = 2 * time.Minute
+)
+
+var (
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was already used with a different request")
+)
+
+// IdempotencyRecord is what is kept in Redis under an idempotency key
+type IdempotencyRecord struct {
+	RequestHash string `json:"request_hash"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// IdempotencyStore keeps the responses of requests sent with an idempotency key, so a retried
+// request gets the first response back instead of being processed again
+type IdempotencyStore struct {
+	redisClient *redis.Client
+}
+
+func NewIdempotencyStore(redisClient *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{redisClient: redisClient}
+}
+
+// Key scopes a client key to an operation and the user sending it
+func (s *IdempotencyStore) Key(operation, userID, key string) string {
+	return fmt.Sprintf("Idempotency-%s-%s-%s", operation, userID, key)
+}
+
+// Begin reserves redisKey for a request. It returns nil when the request should be processed,
+// or the stored record when an identical request already completed.
+func (s *IdempotencyStore) Begin(ctx context.Context, redisKey, requestHash string) (*IdempotencyRecord, error) {
+	reservation, err := json.Marshal(IdempotencyRecord{RequestHash: requestHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	reserved, err := s.redisClient.GetClient().SetNX(ctx, redisKey, reservation, idempotencyReservationTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	data, err := s.redisClient.GetClient().Get(ctx, redisKey).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		// The reservation expired in between; the client can simply retry
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse idempotency record: %w", err)
+	}
+	if record.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	if !record.Completed {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return &record, nil
+}
+
+// Complete stores the response of the request holding redisKey for later retries
+func (s *IdempotencyStore) Complete(ctx context.Context, redisKey, requestHash string, statusCode int, body []byte) error {
+	record, err := json.Marshal(IdempotencyRecord{
+		RequestHash: requestHash,
+		Completed:   true,
+		StatusCode:  statusCode,
+		Body:        string(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	if err := s.redisClient.GetClient().Set(ctx, redisKey, record, idempotencyTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Release frees redisKey so the request can be retried, used when it failed without a result
+// worth replaying
+func (s *IdempotencyStore) Release(ctx context.Context, redisKey string) error {
+	return s.redisClient.GetClient().Del(ctx, redisKey).Err()
+}
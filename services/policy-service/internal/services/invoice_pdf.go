@@ -0,0 +1,205 @@
+package services
+
+import (
+	utils "agrisa_utils"
+	"bytes"
+	"fmt"
+	"math"
+	"policy-service/internal/models"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Layout of the invoice PDFs: A4 pages in points, text in the fixed width Courier so the columns
+// of the line items line up without font metrics
+const (
+	pdfPageWidth     = 595.28
+	pdfPageHeight    = 841.89
+	pdfMargin        = 50.0
+	pdfLineHeight    = 14.0
+	pdfBodyFontSize  = 10.0
+	pdfTitleFontSize = 16.0
+
+	invoiceDescriptionWidth = 44
+)
+
+// pdfTextLine is a line of an invoice PDF; Bold lines are headings in Helvetica-Bold
+type pdfTextLine struct {
+	Text string
+	Bold bool
+}
+
+// renderInvoicePDF lays out an invoice and its line items as a PDF. It uses the standard PDF fonts,
+// which need no embedded font file but have no Vietnamese glyphs, so text is printed without
+// diacritics.
+func renderInvoicePDF(invoice *models.PartnerInvoice) []byte {
+	return writeTextPDF(invoicePDFLines(invoice))
+}
+
+func invoicePDFLines(invoice *models.PartnerInvoice) []pdfTextLine {
+	field := func(label, value string) pdfTextLine {
+		return pdfTextLine{Text: fmt.Sprintf("%-20s %s", label, value)}
+	}
+	amountRow := func(label string, amount float64) pdfTextLine {
+		return pdfTextLine{Text: fmt.Sprintf("%66s %14s", label, formatInvoiceAmount(amount))}
+	}
+	rule := pdfTextLine{Text: strings.Repeat("-", 81)}
+
+	contract := "none, no markup applied"
+	if invoice.ContractID != nil {
+		contract = *invoice.ContractID
+		if invoice.ContractVersion != nil {
+			contract += fmt.Sprintf(" (version %d)", *invoice.ContractVersion)
+		}
+	}
+
+	lines := []pdfTextLine{
+		{Text: "AGRISA - DATA COST INVOICE", Bold: true},
+		{},
+		field("Invoice number:", invoice.InvoiceNumber),
+		field("Billing month:", invoice.Period().Format("2006-01")),
+		field("Issued:", invoice.CreatedAt.UTC().Format(time.DateOnly)),
+		field("Due date:", time.Unix(invoice.DueDate, 0).UTC().Format(time.DateOnly)),
+		field("Insurance partner:", invoice.InsuranceProviderID),
+		field("Contract:", contract),
+		field("Currency:", invoice.Currency),
+		{},
+		{Text: fmt.Sprintf("%-*s %6s %14s %14s", invoiceDescriptionWidth, "Description", "Qty", "Unit cost", "Amount")},
+		rule,
+	}
+	for _, item := range invoice.LineItems {
+		description := item.ItemType
+		if item.Description != nil {
+			description = *item.Description
+		}
+		description = pdfASCII(description)
+		if len(description) > invoiceDescriptionWidth {
+			description = description[:invoiceDescriptionWidth-3] + "..."
+		}
+		lines = append(lines, pdfTextLine{Text: fmt.Sprintf("%-*s %6d %14s %14s", invoiceDescriptionWidth,
+			description, item.Quantity, formatInvoiceAmount(item.UnitCost), formatInvoiceAmount(item.TotalCost))})
+	}
+	lines = append(lines,
+		rule,
+		amountRow("Data cost", invoice.TotalDataComplexityFee),
+		amountRow(fmt.Sprintf("Markup (%.2f%%)", invoice.MarkupPercent), invoice.DataCostMarkup),
+		amountRow("Subtotal", invoice.Subtotal),
+		amountRow(fmt.Sprintf("VAT (%.2f%%)", invoice.TaxPercent), invoice.Tax),
+		amountRow("Total due ("+invoice.Currency+")", invoice.TotalDue),
+		pdfTextLine{},
+		pdfTextLine{Text: fmt.Sprintf("Billed for %d active policies whose coverage started in %s.",
+			invoice.ActivePoliciesCount, invoice.Period().Format("2006-01"))},
+		pdfTextLine{Text: "Please quote the invoice number with your payment."},
+	)
+	return lines
+}
+
+// writeTextPDF writes lines on as many A4 pages as they need, each page numbered in its footer
+func writeTextPDF(lines []pdfTextLine) []byte {
+	perPage := int(math.Floor((pdfPageHeight - 2*pdfMargin) / pdfLineHeight))
+	var pages [][]pdfTextLine
+	for len(lines) > perPage {
+		pages = append(pages, lines[:perPage])
+		lines = lines[perPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objects 1 and 2 are the catalog and the page tree, 3 and 4 the fonts, then each page is
+	// followed by its content stream
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, written once the pages are numbered
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	var kids []string
+	for i, page := range pages {
+		pageObject := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+
+		var content strings.Builder
+		y := pdfPageHeight - pdfMargin
+		for _, line := range page {
+			y -= pdfLineHeight
+			if line.Text == "" {
+				continue
+			}
+			font, size := "/F1", pdfBodyFontSize
+			if line.Bold {
+				font, size = "/F2", pdfTitleFontSize
+			}
+			fmt.Fprintf(&content, "BT %s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, pdfMargin, y, pdfEscape(line.Text))
+		}
+		fmt.Fprintf(&content, "BT /F1 8 Tf %.2f %.2f Td (Page %d/%d) Tj ET\n", pdfPageWidth-pdfMargin-50, pdfMargin/2, i+1, len(pages))
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, pageObject+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfASCII removes the Vietnamese diacritics of text, keeping its case, and replaces the other
+// characters the standard fonts cannot print
+func pdfASCII(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r < unicode.MaxASCII {
+			if unicode.IsControl(r) {
+				r = ' '
+			}
+			b.WriteRune(r)
+			continue
+		}
+		folded := []rune(utils.FoldVietnamese(string(r)))
+		if len(folded) != 1 || folded[0] >= unicode.MaxASCII {
+			b.WriteRune('?')
+			continue
+		}
+		if unicode.IsUpper(r) {
+			folded[0] = unicode.ToUpper(folded[0])
+		}
+		b.WriteRune(folded[0])
+	}
+	return b.String()
+}
+
+func pdfEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(pdfASCII(text))
+}
+
+// formatInvoiceAmount writes an amount with 2 decimals and thousands separators: 1,234,567.80
+func formatInvoiceAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	cents := int64(math.Round(amount * 100))
+	whole := fmt.Sprintf("%d", cents/100)
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, grouped.String(), cents%100)
+}
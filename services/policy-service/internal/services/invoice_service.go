@@ -0,0 +1,491 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"policy-service/internal/config"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/event"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Months a revenue report covers at most
+const maxRevenueReportMonths = 36
+
+// InvoiceService bills insurance partners the data cost of their policies. Each month it snapshots
+// the data cost of every partner, as GetMonthlyDataCost computes it, into an invoice with the
+// markup of the partner contract and VAT, renders the invoice as a PDF kept in MinIO, and tracks
+// its payment until an admin records it.
+type InvoiceService struct {
+	invoiceRepo             *repository.InvoiceRepository
+	outboxRepo              *repository.OutboxRepository
+	registeredPolicyService *RegisteredPolicyService
+	minioClient             *minio.MinioClient
+	cfg                     config.InvoicingConfig
+}
+
+func NewInvoiceService(
+	invoiceRepo *repository.InvoiceRepository,
+	outboxRepo *repository.OutboxRepository,
+	registeredPolicyService *RegisteredPolicyService,
+	minioClient *minio.MinioClient,
+	cfg config.InvoicingConfig,
+) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepo:             invoiceRepo,
+		outboxRepo:              outboxRepo,
+		registeredPolicyService: registeredPolicyService,
+		minioClient:             minioClient,
+		cfg:                     cfg,
+	}
+}
+
+// GenerateMonthlyInvoices issues the invoices of the previous month to the partners that do not
+// have theirs yet. It is run daily by the invoice-generation cron job, so a partner missed on the
+// first run, e.g. because profile-service was down, is billed on a later one.
+func (s *InvoiceService) GenerateMonthlyInvoices(ctx context.Context) error {
+	period := previousBillingMonth(time.Now())
+	response, err := s.GenerateInvoices(ctx, models.GenerateInvoicesRequest{Year: period.Year(), Month: int(period.Month())})
+	if err != nil {
+		return err
+	}
+	if len(response.Failures) > 0 {
+		return fmt.Errorf("failed to invoice %d partners for %s", len(response.Failures), period.Format("2006-01"))
+	}
+	return nil
+}
+
+// GenerateInvoices issues the invoices of a month that is over, to one partner or to every partner
+// with billable policies and no invoice for it. A partner already invoiced for the month gets its
+// issued invoice back. The partners that could not be invoiced are reported in Failures, the
+// others are still invoiced.
+func (s *InvoiceService) GenerateInvoices(ctx context.Context, req models.GenerateInvoicesRequest) (*models.GenerateInvoicesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	period := time.Date(req.Year, time.Month(req.Month), 1, 0, 0, 0, 0, time.UTC)
+	if period.AddDate(0, 1, 0).After(time.Now()) {
+		return nil, fmt.Errorf("invalid operation: %s is not over yet", period.Format("2006-01"))
+	}
+
+	providers := []string{req.InsuranceProviderID}
+	if req.InsuranceProviderID == "" {
+		var err error
+		providers, err = s.invoiceRepo.GetUninvoicedProviders(ctx, invoiceMonthOf(period), period.Unix(), period.AddDate(0, 1, 0).Unix())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := &models.GenerateInvoicesResponse{Invoices: []models.PartnerInvoice{}}
+	for _, providerID := range providers {
+		invoice, err := s.generateInvoice(ctx, providerID, period)
+		if err != nil {
+			slog.Error("Failed to invoice partner",
+				"provider_id", providerID,
+				"month", period.Format("2006-01"),
+				"error", err)
+			if response.Failures == nil {
+				response.Failures = make(map[string]string)
+			}
+			response.Failures[providerID] = err.Error()
+			continue
+		}
+		if invoice != nil {
+			response.Invoices = append(response.Invoices, *invoice)
+		}
+	}
+	return response, nil
+}
+
+// generateInvoice issues the invoice of a partner for the month starting at period. It returns the
+// invoice already issued for that month if there is one, and nil when the partner has nothing to
+// pay for it.
+func (s *InvoiceService) generateInvoice(ctx context.Context, providerID string, period time.Time) (*models.PartnerInvoice, error) {
+	issued, err := s.invoiceRepo.GetIssued(ctx, providerID, invoiceMonthOf(period))
+	if err != nil {
+		return nil, err
+	}
+	if issued != nil {
+		return issued, nil
+	}
+
+	cost, err := s.registeredPolicyService.GetMonthlyDataCost(models.MonthlyDataCostRequest{
+		Month:              int(period.Month()),
+		Year:               period.Year(),
+		Direction:          "DESC",
+		Status:             string(models.PolicyActive),
+		UnderwritingStatus: string(models.UnderwritingApproved),
+		OrderBy:            "sum_total_data_cost",
+	}, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly data cost: %w", err)
+	}
+	if len(cost.BasePolicyCosts) == 0 {
+		slog.Info("No billable policies, partner not invoiced",
+			"provider_id", providerID,
+			"month", period.Format("2006-01"))
+		return nil, nil
+	}
+
+	now := time.Now()
+	invoice := buildInvoice(cost, s.cfg, now)
+	// The PDF is kept in MinIO before the invoice is saved so a partner notified of its invoice can
+	// download it; when MinIO is unavailable it is rendered on the first download instead
+	if objectKey, err := s.storePDF(ctx, invoice); err != nil {
+		slog.Warn("Failed to store invoice PDF, rendering it on download",
+			"invoice_number", invoice.InvoiceNumber,
+			"error", err)
+	} else {
+		invoice.PDFObjectKey = &objectKey
+	}
+
+	tx, err := s.invoiceRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.invoiceRepo.CreateTx(tx, ctx, invoice); err != nil {
+		return nil, err
+	}
+	notification, err := event.InvoiceIssuedNotification(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	slog.Info("Partner invoice issued",
+		"invoice_id", invoice.ID,
+		"invoice_number", invoice.InvoiceNumber,
+		"provider_id", providerID,
+		"total_due", invoice.TotalDue,
+		"currency", invoice.Currency)
+	return invoice, nil
+}
+
+// GetInvoice retrieves an invoice with its line items
+func (s *InvoiceService) GetInvoice(ctx context.Context, id uuid.UUID) (*models.PartnerInvoice, error) {
+	invoice, err := s.invoiceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.LineItems, err = s.invoiceRepo.GetLineItems(ctx, id); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) ListInvoices(ctx context.Context, filter models.InvoiceFilter) ([]models.PartnerInvoice, error) {
+	return s.invoiceRepo.List(ctx, filter)
+}
+
+// GetDownloadURL returns a presigned URL of the PDF of an invoice, storing the PDF first if it
+// could not be when the invoice was issued
+func (s *InvoiceService) GetDownloadURL(ctx context.Context, id uuid.UUID) (*models.InvoiceDownloadResponse, error) {
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is unavailable")
+	}
+	invoice, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.PDFObjectKey == nil {
+		objectKey, err := s.storePDF(ctx, invoice)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.invoiceRepo.SetPDFObjectKey(ctx, invoice.ID, objectKey); err != nil {
+			return nil, err
+		}
+		invoice.PDFObjectKey = &objectKey
+	}
+
+	downloadURL, err := s.minioClient.GetPresignedURL(ctx, minio.Storage.Invoices, *invoice.PDFObjectKey, attachmentDownloadExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate download URL: %w", err)
+	}
+	return &models.InvoiceDownloadResponse{
+		InvoiceNumber: invoice.InvoiceNumber,
+		DownloadURL:   downloadURL,
+		ExpiresAt:     time.Now().Add(attachmentDownloadExpiry),
+	}, nil
+}
+
+// MarkPaid records the payment of a pending or overdue invoice
+func (s *InvoiceService) MarkPaid(ctx context.Context, id uuid.UUID, adminID string, req models.MarkInvoicePaidRequest) (*models.PartnerInvoice, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	paidAt := time.Now().Unix()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	return s.updateStatus(ctx, id, func(invoice *models.PartnerInvoice) error {
+		if err := checkInvoiceOpen(invoice); err != nil {
+			return err
+		}
+		invoice.PaymentStatus = models.PaymentPaid
+		invoice.PaidDate = &paidAt
+		invoice.PaymentReference = &req.PaymentReference
+		invoice.MarkedPaidBy = &adminID
+		return nil
+	})
+}
+
+// Cancel voids a pending or overdue invoice, e.g. one billed on wrong data; the month can then be
+// invoiced again
+func (s *InvoiceService) Cancel(ctx context.Context, id uuid.UUID, adminID string, req models.CancelInvoiceRequest) (*models.PartnerInvoice, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	invoice, err := s.updateStatus(ctx, id, func(invoice *models.PartnerInvoice) error {
+		if err := checkInvoiceOpen(invoice); err != nil {
+			return err
+		}
+		invoice.PaymentStatus = models.PaymentCancelled
+		invoice.CancellationReason = &req.Reason
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Partner invoice cancelled",
+		"invoice_id", id,
+		"admin_id", adminID,
+		"reason", req.Reason)
+	return invoice, nil
+}
+
+// CheckOverdue marks the pending invoices past their due date overdue and notifies their partner,
+// run daily by the invoice-overdue-check cron job
+func (s *InvoiceService) CheckOverdue(ctx context.Context) error {
+	now := time.Now().Unix()
+	ids, err := s.invoiceRepo.GetOverdueIDs(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if err := s.markOverdue(ctx, id, now); err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", id, err))
+		}
+	}
+	if len(ids) > 0 {
+		slog.Info("Overdue partner invoices checked", "overdue", len(ids), "failed", len(errs))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *InvoiceService) markOverdue(ctx context.Context, id uuid.UUID, now int64) error {
+	tx, err := s.invoiceRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	invoice, err := s.invoiceRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return err
+	}
+	// Paid or cancelled since it was listed
+	if invoice.PaymentStatus != models.PaymentPending || invoice.DueDate >= now {
+		return nil
+	}
+	invoice.PaymentStatus = models.PaymentOverdue
+	if err := s.invoiceRepo.UpdateStatusTx(tx, ctx, invoice); err != nil {
+		return err
+	}
+
+	notification, err := event.InvoiceOverdueNotification(ctx, invoice)
+	if err != nil {
+		return err
+	}
+	if err := s.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting transaction: %w", err)
+	}
+	return nil
+}
+
+// GetRevenueReport sums the invoices of the months from and to (both included) per month and
+// currency, with the totals of each currency over the range
+func (s *InvoiceService) GetRevenueReport(ctx context.Context, from, to time.Time) (*models.RevenueReport, error) {
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: from must not be after to")
+	}
+	if from.AddDate(0, maxRevenueReportMonths, 0).Before(to.AddDate(0, 1, 0)) {
+		return nil, fmt.Errorf("invalid range: at most %d months can be requested", maxRevenueReportMonths)
+	}
+
+	months, err := s.invoiceRepo.GetRevenueByMonth(ctx, invoiceMonthOf(from), invoiceMonthOf(to))
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevenueReport{
+		FromMonth: invoiceMonthOf(from),
+		ToMonth:   invoiceMonthOf(to),
+		Months:    months,
+		Totals:    sumRevenueByCurrency(months),
+	}, nil
+}
+
+// updateStatus applies change to an invoice under a row lock and saves its status
+func (s *InvoiceService) updateStatus(ctx context.Context, id uuid.UUID, change func(*models.PartnerInvoice) error) (*models.PartnerInvoice, error) {
+	tx, err := s.invoiceRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := s.invoiceRepo.GetByIDForUpdateTx(tx, ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(invoice); err != nil {
+		return nil, err
+	}
+	if err := s.invoiceRepo.UpdateStatusTx(tx, ctx, invoice); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+	return invoice, nil
+}
+
+// storePDF renders the PDF of an invoice and uploads it to MinIO, returning its object key
+func (s *InvoiceService) storePDF(ctx context.Context, invoice *models.PartnerInvoice) (string, error) {
+	if s.minioClient == nil {
+		return "", fmt.Errorf("file storage is unavailable")
+	}
+	objectKey := invoiceObjectKey(invoice)
+	if err := s.minioClient.UploadBytes(ctx, minio.Storage.Invoices, objectKey, renderInvoicePDF(invoice), "application/pdf"); err != nil {
+		return "", fmt.Errorf("failed to upload invoice PDF: %w", err)
+	}
+	return objectKey, nil
+}
+
+// buildInvoice snapshots the data cost of a partner for a month into a pending invoice: a line
+// per base policy, a line for the markup of the partner contract, and VAT on their sum
+func buildInvoice(cost *models.MonthlyDataCostResponse, cfg config.InvoicingConfig, now time.Time) *models.PartnerInvoice {
+	period := time.Date(cost.Year, time.Month(cost.Month), 1, 0, 0, 0, 0, time.UTC)
+	invoice := &models.PartnerInvoice{
+		ID:                     uuid.New(),
+		InsuranceProviderID:    cost.InsuranceProviderID,
+		InvoiceMonth:           invoiceMonthOf(period),
+		ActivePoliciesCount:    cost.TotalActivePolicies,
+		TotalDataComplexityFee: roundAmount(cost.TotalBasePolicyDataCost),
+		DataCostMarkup:         roundAmount(cost.DataCostMarkup),
+		TaxPercent:             cfg.TaxPercent,
+		Currency:               cost.Currency,
+		PaymentStatus:          models.PaymentPending,
+		DueDate:                now.Add(cfg.PaymentTerm).Unix(),
+		CreatedAt:              now,
+	}
+	invoice.InvoiceNumber = fmt.Sprintf("INV-%d-%s", invoice.InvoiceMonth, strings.ToUpper(invoice.ID.String()[:8]))
+	if cost.ContractTerms != nil {
+		invoice.ContractID = &cost.ContractTerms.ContractID
+		invoice.ContractVersion = &cost.ContractTerms.Version
+		invoice.MarkupPercent = cost.ContractTerms.DataCostMarkupPercent
+	}
+
+	for _, baseCost := range cost.BasePolicyCosts {
+		if baseCost.ActivePolicyCount == 0 {
+			continue
+		}
+		basePolicyID := baseCost.BasePolicyID
+		description := baseCost.ProductName
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			ItemType:     models.InvoiceItemDataCost,
+			BasePolicyID: &basePolicyID,
+			Description:  &description,
+			Quantity:     baseCost.ActivePolicyCount,
+			UnitCost:     math.Round(baseCost.SumTotalDataCost/float64(baseCost.ActivePolicyCount)*10000) / 10000,
+			TotalCost:    roundAmount(baseCost.SumTotalDataCost),
+		})
+	}
+	if invoice.DataCostMarkup > 0 {
+		description := fmt.Sprintf("Phụ phí dữ liệu %.2f%% theo hợp đồng", invoice.MarkupPercent)
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			ItemType:    models.InvoiceItemDataCostMarkup,
+			Description: &description,
+			Quantity:    1,
+			UnitCost:    invoice.DataCostMarkup,
+			TotalCost:   invoice.DataCostMarkup,
+		})
+	}
+
+	invoice.Subtotal = roundAmount(invoice.TotalDataComplexityFee + invoice.DataCostMarkup)
+	invoice.Tax = roundAmount(invoice.Subtotal * cfg.TaxPercent / 100)
+	invoice.TotalDue = roundAmount(invoice.Subtotal + invoice.Tax)
+	return invoice
+}
+
+// checkInvoiceOpen refuses to change an invoice that is already paid, cancelled or refunded
+func checkInvoiceOpen(invoice *models.PartnerInvoice) error {
+	if invoice.PaymentStatus != models.PaymentPending && invoice.PaymentStatus != models.PaymentOverdue {
+		return fmt.Errorf("invalid operation: invoice %s is %s", invoice.InvoiceNumber, invoice.PaymentStatus)
+	}
+	return nil
+}
+
+// sumRevenueByCurrency totals monthly revenue per currency, in the order currencies first appear
+func sumRevenueByCurrency(months []models.RevenueMonth) []models.RevenueMonth {
+	totals := []models.RevenueMonth{}
+	index := make(map[string]int)
+	for _, month := range months {
+		i, ok := index[month.Currency]
+		if !ok {
+			i = len(totals)
+			index[month.Currency] = i
+			totals = append(totals, models.RevenueMonth{Currency: month.Currency})
+		}
+		totals[i].InvoiceCount += month.InvoiceCount
+		totals[i].DataCost = roundAmount(totals[i].DataCost + month.DataCost)
+		totals[i].Markup = roundAmount(totals[i].Markup + month.Markup)
+		totals[i].Tax = roundAmount(totals[i].Tax + month.Tax)
+		totals[i].Invoiced = roundAmount(totals[i].Invoiced + month.Invoiced)
+		totals[i].Collected = roundAmount(totals[i].Collected + month.Collected)
+		totals[i].Outstanding = roundAmount(totals[i].Outstanding + month.Outstanding)
+		totals[i].Overdue = roundAmount(totals[i].Overdue + month.Overdue)
+	}
+	return totals
+}
+
+// previousBillingMonth is the first day, in UTC, of the month before the one of now
+func previousBillingMonth(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// invoiceMonthOf writes the month of t as YYYYMM
+func invoiceMonthOf(t time.Time) int64 {
+	return int64(t.Year()*100 + int(t.Month()))
+}
+
+// invoiceObjectKey is where the PDF of an invoice is kept in the invoices bucket
+func invoiceObjectKey(invoice *models.PartnerInvoice) string {
+	return fmt.Sprintf("%s/%d/%s.pdf", invoice.InsuranceProviderID, invoice.InvoiceMonth, invoice.InvoiceNumber)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"policy-service/internal/config"
+	"policy-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func invoiceDataCost() *models.MonthlyDataCostResponse {
+	return &models.MonthlyDataCostResponse{
+		InsuranceProviderID: "provider-1",
+		Month:               9,
+		Year:                2026,
+		BasePolicyCosts: []models.BasePolicyDataCost{
+			{BasePolicyID: uuid.New(), ProductName: "Bảo hiểm lúa Đông Xuân", ActivePolicyCount: 3, SumTotalDataCost: 1000},
+			{BasePolicyID: uuid.New(), ProductName: "Bảo hiểm cà phê", ActivePolicyCount: 2, SumTotalDataCost: 500.5},
+		},
+		TotalActivePolicies:     5,
+		TotalBasePolicyDataCost: 1500.5,
+		Currency:                "VND",
+		ContractTerms:           &models.PartnerContractTerms{ContractID: "contract-1", Version: 2, DataCostMarkupPercent: 10, Currency: "VND"},
+		DataCostMarkup:          150.05,
+		TotalBillableDataCost:   1650.55,
+	}
+}
+
+func TestBuildInvoice(t *testing.T) {
+	now := time.Date(2026, 10, 2, 2, 0, 0, 0, time.UTC)
+	cfg := config.InvoicingConfig{TaxPercent: 10, PaymentTerm: 30 * 24 * time.Hour}
+
+	invoice := buildInvoice(invoiceDataCost(), cfg, now)
+	assert.Equal(t, int64(202609), invoice.InvoiceMonth)
+	assert.True(t, strings.HasPrefix(invoice.InvoiceNumber, "INV-202609-"))
+	assert.Equal(t, models.PaymentPending, invoice.PaymentStatus)
+	assert.Equal(t, now.Add(30*24*time.Hour).Unix(), invoice.DueDate)
+	assert.Equal(t, "contract-1", *invoice.ContractID)
+	assert.Equal(t, 5, invoice.ActivePoliciesCount)
+
+	assert.Len(t, invoice.LineItems, 3)
+	assert.Equal(t, models.InvoiceItemDataCost, invoice.LineItems[0].ItemType)
+	assert.Equal(t, 3, invoice.LineItems[0].Quantity)
+	assert.Equal(t, 333.3333, invoice.LineItems[0].UnitCost)
+	assert.Equal(t, 1000.0, invoice.LineItems[0].TotalCost)
+	assert.Equal(t, models.InvoiceItemDataCostMarkup, invoice.LineItems[2].ItemType)
+	assert.Equal(t, 150.05, invoice.LineItems[2].TotalCost)
+
+	assert.Equal(t, 1650.55, invoice.Subtotal)
+	assert.Equal(t, 165.06, invoice.Tax)
+	assert.Equal(t, 1815.61, invoice.TotalDue)
+}
+
+func TestBuildInvoiceWithoutContract(t *testing.T) {
+	cost := invoiceDataCost()
+	cost.ContractTerms = nil
+	cost.DataCostMarkup = 0
+
+	invoice := buildInvoice(cost, config.InvoicingConfig{}, time.Now())
+	assert.Nil(t, invoice.ContractID)
+	// No markup line without a contract, and no VAT when none is configured
+	assert.Len(t, invoice.LineItems, 2)
+	assert.Equal(t, 1500.5, invoice.Subtotal)
+	assert.Equal(t, invoice.Subtotal, invoice.TotalDue)
+}
+
+func TestRenderInvoicePDF(t *testing.T) {
+	invoice := buildInvoice(invoiceDataCost(), config.InvoicingConfig{TaxPercent: 10}, time.Now())
+
+	pdf := renderInvoicePDF(invoice)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), invoice.InvoiceNumber)
+	// Product names are printed without diacritics
+	assert.Contains(t, string(pdf), "Bao hiem lua Dong Xuan")
+	assert.Contains(t, string(pdf), "/Count 1")
+}
+
+func TestRenderInvoicePDFPaginates(t *testing.T) {
+	invoice := buildInvoice(invoiceDataCost(), config.InvoicingConfig{}, time.Now())
+	for i := 0; i < 100; i++ {
+		description := fmt.Sprintf("Product (%d)", i)
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{Description: &description, Quantity: 1})
+	}
+
+	pdf := string(renderInvoicePDF(invoice))
+	assert.Contains(t, pdf, "/Count 3")
+	assert.Contains(t, pdf, "(Page 3/3)")
+	assert.Contains(t, pdf, `Product \(99\)`)
+}
+
+func TestPDFASCII(t *testing.T) {
+	assert.Equal(t, "Dong Thap - Hau Giang", pdfASCII("Đồng Tháp - Hậu Giang"))
+	assert.Equal(t, "a?b", pdfASCII("a€b"))
+}
+
+func TestFormatInvoiceAmount(t *testing.T) {
+	assert.Equal(t, "0.00", formatInvoiceAmount(0))
+	assert.Equal(t, "999.50", formatInvoiceAmount(999.5))
+	assert.Equal(t, "1,234,567.89", formatInvoiceAmount(1234567.891))
+	assert.Equal(t, "-1,000.00", formatInvoiceAmount(-1000))
+}
+
+func TestCheckInvoiceOpen(t *testing.T) {
+	for status, open := range map[models.PaymentStatus]bool{
+		models.PaymentPending:   true,
+		models.PaymentOverdue:   true,
+		models.PaymentPaid:      false,
+		models.PaymentCancelled: false,
+		models.PaymentRefunded:  false,
+	} {
+		err := checkInvoiceOpen(&models.PartnerInvoice{InvoiceNumber: "INV-1", PaymentStatus: status})
+		if open {
+			assert.NoError(t, err, status)
+		} else {
+			assert.ErrorContains(t, err, "invalid operation", status)
+		}
+	}
+}
+
+func TestPreviousBillingMonth(t *testing.T) {
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), previousBillingMonth(time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), previousBillingMonth(time.Date(2026, 1, 1, 0, 30, 0, 0, time.UTC)))
+	assert.Equal(t, int64(202512), invoiceMonthOf(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestSumRevenueByCurrency(t *testing.T) {
+	totals := sumRevenueByCurrency([]models.RevenueMonth{
+		{InvoiceMonth: 202608, Currency: "VND", InvoiceCount: 2, Invoiced: 1000, Collected: 600, Outstanding: 400, Overdue: 400},
+		{InvoiceMonth: 202608, Currency: "USD", InvoiceCount: 1, Invoiced: 10, Collected: 10},
+		{InvoiceMonth: 202609, Currency: "VND", InvoiceCount: 3, Invoiced: 1500.25, Outstanding: 1500.25},
+	})
+	assert.Len(t, totals, 2)
+	assert.Equal(t, "VND", totals[0].Currency)
+	assert.Equal(t, int64(5), totals[0].InvoiceCount)
+	assert.Equal(t, 2500.25, totals[0].Invoiced)
+	assert.Equal(t, 1900.25, totals[0].Outstanding)
+	assert.Equal(t, 10.0, totals[1].Collected)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	utils "agrisa_utils"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"policy-service/internal/ai/gemini"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/models"
+	"policy-service/internal/worker"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LandCertificateOCRJobType = "land-certificate-ocr"
+
+	// Confidence below which a reading of the certificate is not trusted either way
+	landCertificateMinConfidence = 0.5
+)
+
+// Titles the certificate prints before the names of its owners, folded
+var ownerTitles = []string{"ong ", "ba ", "ho ong ", "ho ba "}
+
+// landCertificateOCRJob reads the land certificate of a farm once its photos are scanned.
+// identityName is the name on the national ID card the farmer verified through eKYC.
+func landCertificateOCRJob(farmID uuid.UUID, identityName string, objectKeys []string) worker.JobPayload {
+	return worker.JobPayload{
+		JobID:      uuid.NewString(),
+		Type:       LandCertificateOCRJobType,
+		Params:     map[string]any{"farm_id": farmID.String(), "identity_name": identityName, "object_keys": objectKeys},
+		MaxRetries: 5,
+		OneTime:    true,
+		RunNow:     true,
+	}
+}
+
+// LandCertificateOCRJob reads the certificate number and owners from the photos of a farm's land
+// certificate and verifies the land ownership of the farm when an owner is the farmer. The
+// reading and the comparison are kept as evidence whatever the outcome.
+func (s *FarmService) LandCertificateOCRJob(params map[string]any) error {
+	farmIDStr, _ := params["farm_id"].(string)
+	farmID, err := uuid.Parse(farmIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid or missing farm_id parameter: %w", err)
+	}
+	identityName, _ := params["identity_name"].(string)
+	if identityName == "" {
+		return fmt.Errorf("missing identity_name parameter")
+	}
+	rawKeys, _ := params["object_keys"].([]any)
+	objectKeys := make([]string, 0, len(rawKeys))
+	for _, raw := range rawKeys {
+		if key, ok := raw.(string); ok && key != "" {
+			objectKeys = append(objectKeys, key)
+		}
+	}
+	ctx := context.Background()
+
+	// The certificate is read while the farm of the request is being created, a missing farm is
+	// retried
+	farm, err := s.farmRepository.GetFarmByID(ctx, farmID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get farm: %w", err)
+	}
+
+	images := s.downloadLandCertificate(ctx, objectKeys)
+	reading := models.LandCertificateReading{Reasoning: "no certificate photo passed the document scan"}
+	if len(images) > 0 {
+		if s.geminiSelector == nil {
+			return fmt.Errorf("gemini selector is not configured")
+		}
+		aiCtx, usageMeter := gemini.WithUsageMeter(ctx)
+		aiResp, err := gemini.SendAIWithImagesAndRetry(aiCtx, gemini.LandCertificateOCRPrompt, images, s.geminiSelector)
+		s.aiUsageService.RecordJob(ctx, models.AIUsageSource{JobType: LandCertificateOCRJobType}, usageMeter, err == nil)
+		if err != nil {
+			return fmt.Errorf("AI land certificate reading failed: %w", err)
+		}
+		if reading, err = parseLandCertificateReading(aiResp); err != nil {
+			return err
+		}
+	}
+
+	verification := compareLandCertificate(reading, identityName, farm.LandCertificateNumber)
+	verification.FarmID = farmID
+	verification.DocumentObjects = objectKeys
+
+	before := *farm
+	farm.LandOwnershipVerified = verification.Status == models.LandCertificateVerified
+	farm.LandOwnershipVerifiedAt = nil
+	if farm.LandOwnershipVerified {
+		now := time.Now().Unix()
+		farm.LandOwnershipVerifiedAt = &now
+		if farm.LandCertificateNumber == nil {
+			farm.LandCertificateNumber = verification.CertificateNumber
+		}
+	}
+
+	tx, err := s.farmRepository.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.farmRepository.UpdateLandOwnershipTx(tx, farm); err != nil {
+		return err
+	}
+	if err := s.farmRepository.CreateLandCertificateVerificationTx(tx, verification); err != nil {
+		return err
+	}
+	if err := s.auditService.RecordTx(tx, ctx, models.AuditEntityFarm, farm.ID, models.AuditActionUpdate,
+		landOwnershipAuditState(&before), landOwnershipAuditState(farm)); err != nil {
+		return fmt.Errorf("failed to audit land certificate verification: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit land certificate verification: %w", err)
+	}
+
+	slog.Info("LandCertificateOCRJob: land certificate read",
+		"farm_id", farmID,
+		"status", verification.Status,
+		"owner_matched", verification.OwnerMatched,
+		"certificate_number_matched", verification.CertificateNumberMatched)
+	return nil
+}
+
+// GetLandCertificateVerifications lists the land certificate readings of a farm, most recent first
+func (s *FarmService) GetLandCertificateVerifications(ctx context.Context, farmID uuid.UUID) ([]models.LandCertificateVerification, error) {
+	return s.farmRepository.GetLandCertificateVerificationsByFarmID(ctx, farmID)
+}
+
+// downloadLandCertificate reads the certificate photos as base64. Photos quarantined by their scan
+// are gone from the bucket and left out.
+func (s *FarmService) downloadLandCertificate(ctx context.Context, objectKeys []string) []string {
+	images := make([]string, 0, len(objectKeys))
+	for _, key := range objectKeys {
+		obj, err := s.minioClient.GetFile(ctx, minio.Storage.PolicyDocuments, key)
+		if err != nil {
+			slog.Warn("Failed to get land certificate photo", "object_key", key, "error", err)
+			continue
+		}
+		data, err := io.ReadAll(obj)
+		obj.Close()
+		if err != nil || len(data) == 0 {
+			slog.Warn("Failed to read land certificate photo", "object_key", key, "error", err)
+			continue
+		}
+		images = append(images, base64.StdEncoding.EncodeToString(data))
+	}
+	return images
+}
+
+// landOwnershipAuditState is the part of a farm a land certificate verification changes
+func landOwnershipAuditState(farm *models.Farm) map[string]any {
+	return map[string]any{
+		"land_certificate_number":    farm.LandCertificateNumber,
+		"land_ownership_verified":    farm.LandOwnershipVerified,
+		"land_ownership_verified_at": farm.LandOwnershipVerifiedAt,
+	}
+}
+
+// compareLandCertificate checks a reading of a land certificate against the identity of the
+// farmer and the certificate number the farmer declared, if any. The land is verified when one of
+// the owners is the farmer and the number, when both are known, is the declared one.
+func compareLandCertificate(reading models.LandCertificateReading, identityName string, declaredNumber *string) *models.LandCertificateVerification {
+	verification := &models.LandCertificateVerification{
+		Status:                    models.LandCertificateUnreadable,
+		OwnerNames:                reading.OwnerNames,
+		IdentityName:              identityName,
+		DeclaredCertificateNumber: declaredNumber,
+	}
+	if verification.OwnerNames == nil {
+		verification.OwnerNames = models.StringList{}
+	}
+	if reading.CertificateNumber != "" {
+		verification.CertificateNumber = &reading.CertificateNumber
+	}
+	if reading.ParcelAddress != "" {
+		verification.ParcelAddress = &reading.ParcelAddress
+	}
+	if reading.Reasoning != "" {
+		verification.Reasoning = &reading.Reasoning
+	}
+	if !reading.Readable || len(reading.OwnerNames) == 0 || reading.Confidence < landCertificateMinConfidence {
+		return verification
+	}
+	verification.Confidence = &reading.Confidence
+
+	identity := foldPersonName(identityName)
+	for _, owner := range reading.OwnerNames {
+		if foldPersonName(owner) == identity {
+			verification.OwnerMatched = true
+			break
+		}
+	}
+	if declaredNumber != nil && *declaredNumber != "" && reading.CertificateNumber != "" {
+		matched := foldCertificateNumber(*declaredNumber) == foldCertificateNumber(reading.CertificateNumber)
+		verification.CertificateNumberMatched = &matched
+	}
+
+	verification.Status = models.LandCertificateMismatch
+	if verification.OwnerMatched && (verification.CertificateNumberMatched == nil || *verification.CertificateNumberMatched) {
+		verification.Status = models.LandCertificateVerified
+	}
+	return verification
+}
+
+// parseLandCertificateReading reads the answer of the land certificate OCR prompt. A confidence
+// given in percent is scaled to 0-1.
+func parseLandCertificateReading(resp map[string]any) (models.LandCertificateReading, error) {
+	var reading models.LandCertificateReading
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return reading, fmt.Errorf("failed to marshal AI response: %w", err)
+	}
+	if err := json.Unmarshal(raw, &reading); err != nil {
+		return reading, fmt.Errorf("failed to parse land certificate reading: %w", err)
+	}
+
+	reading.CertificateNumber = strings.TrimSpace(reading.CertificateNumber)
+	reading.ParcelAddress = strings.TrimSpace(reading.ParcelAddress)
+	reading.Reasoning = strings.TrimSpace(reading.Reasoning)
+	owners := make([]string, 0, len(reading.OwnerNames))
+	for _, owner := range reading.OwnerNames {
+		if owner = strings.TrimSpace(owner); owner != "" {
+			owners = append(owners, owner)
+		}
+	}
+	reading.OwnerNames = owners
+	if reading.Confidence > 1 {
+		reading.Confidence /= 100
+	}
+	reading.Confidence = roundConfidence(math.Max(0, math.Min(1, reading.Confidence)))
+	return reading, nil
+}
+
+// foldPersonName folds a name as printed on a certificate or an ID card, without its title, so
+// spellings with and without diacritics compare equal
+func foldPersonName(name string) string {
+	folded := utils.FoldVietnamese(strings.ReplaceAll(name, ".", " "))
+	for _, title := range ownerTitles {
+		if strings.HasPrefix(folded, title) {
+			return strings.TrimPrefix(folded, title)
+		}
+	}
+	return folded
+}
+
+// foldCertificateNumber folds a certificate serial number, which is printed with or without the
+// space after its letters
+func foldCertificateNumber(number string) string {
+	return strings.ReplaceAll(strings.ToUpper(utils.FoldVietnamese(number)), " ", "")
+}
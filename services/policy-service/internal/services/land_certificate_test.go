@@ -0,0 +1,72 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareLandCertificate(t *testing.T) {
+	declared := "CS 123456"
+	otherNumber := "CS 654321"
+	reading := models.LandCertificateReading{
+		Readable:          true,
+		CertificateNumber: "CS123456",
+		OwnerNames:        []string{"Ông Nguyễn Văn An", "Bà Trần Thị Bình"},
+		Confidence:        0.9,
+	}
+
+	tests := []struct {
+		name          string
+		reading       models.LandCertificateReading
+		identityName  string
+		declared      *string
+		status        models.LandCertificateStatus
+		numberMatched *bool
+	}{
+		{"owner and number match", reading, "NGUYEN VAN AN", &declared, models.LandCertificateVerified, boolPtr(true)},
+		{"co-owner matches", reading, "Trần Thị Bình", nil, models.LandCertificateVerified, nil},
+		{"owner is someone else", reading, "Lê Văn Cường", &declared, models.LandCertificateMismatch, boolPtr(true)},
+		{"number differs", reading, "Nguyễn Văn An", &otherNumber, models.LandCertificateMismatch, boolPtr(false)},
+		{"not readable", models.LandCertificateReading{Readable: false}, "Nguyễn Văn An", nil, models.LandCertificateUnreadable, nil},
+		{"low confidence", models.LandCertificateReading{Readable: true, OwnerNames: []string{"Nguyễn Văn An"}, Confidence: 0.3},
+			"Nguyễn Văn An", nil, models.LandCertificateUnreadable, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := compareLandCertificate(tt.reading, tt.identityName, tt.declared)
+
+			assert.Equal(t, tt.status, verification.Status)
+			assert.Equal(t, tt.numberMatched, verification.CertificateNumberMatched)
+			assert.Equal(t, tt.identityName, verification.IdentityName)
+			assert.NotNil(t, verification.OwnerNames)
+		})
+	}
+}
+
+func TestParseLandCertificateReading(t *testing.T) {
+	reading, err := parseLandCertificateReading(map[string]any{
+		"readable":           true,
+		"certificate_number": " BĐ 987654 ",
+		"owner_names":        []any{" Nguyễn Văn An ", ""},
+		"confidence":         92.0,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "BĐ 987654", reading.CertificateNumber)
+	assert.Equal(t, []string{"Nguyễn Văn An"}, reading.OwnerNames)
+	assert.Equal(t, 0.92, reading.Confidence)
+}
+
+func TestFoldPersonNameAndCertificateNumber(t *testing.T) {
+	assert.Equal(t, "nguyen van an", foldPersonName("Ông NGUYỄN  Văn An"))
+	assert.Equal(t, "tran thi binh", foldPersonName("Hộ bà Trần Thị Bình"))
+	assert.Equal(t, "BD987654", foldCertificateNumber("bđ 987654"))
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
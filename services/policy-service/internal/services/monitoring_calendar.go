@@ -0,0 +1,162 @@
+package services
+
+import (
+	utils "agrisa_utils"
+	"fmt"
+	"policy-service/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A policy is covered for about a season; longer ranges are cut so a bad coverage date cannot
+// build an unbounded calendar
+const maxMonitoringCalendarDays = 2 * 366
+
+// blackoutPeriod is a yearly range of days, as MM-DD, during which a trigger is not evaluated.
+// A range whose start is after its end wraps over the new year.
+type blackoutPeriod struct {
+	start string
+	end   string
+}
+
+func (p blackoutPeriod) contains(monthDay string) bool {
+	if p.start <= p.end {
+		return monthDay >= p.start && monthDay <= p.end
+	}
+	return monthDay >= p.start || monthDay <= p.end
+}
+
+// parseBlackoutPeriods reads the blackout periods of a trigger, stored as
+// {"periods": [{"start": "MM-DD", "end": "MM-DD"}, ...]}. Malformed entries are skipped.
+func parseBlackoutPeriods(blackoutPeriods utils.JSONMap) []blackoutPeriod {
+	if blackoutPeriods == nil {
+		return nil
+	}
+	periods, ok := blackoutPeriods["periods"].([]any)
+	if !ok {
+		return nil
+	}
+
+	var result []blackoutPeriod
+	for _, p := range periods {
+		period, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		start, startOk := period["start"].(string)
+		end, endOk := period["end"].(string)
+		if !startOk || !endOk {
+			continue
+		}
+		result = append(result, blackoutPeriod{start: start, end: end})
+	}
+	return result
+}
+
+// validateBlackoutPeriods rejects blackout periods the evaluation would silently skip
+func validateBlackoutPeriods(blackoutPeriods utils.JSONMap) error {
+	if len(blackoutPeriods) == 0 {
+		return nil
+	}
+	periods, ok := blackoutPeriods["periods"].([]any)
+	if !ok {
+		return fmt.Errorf(`invalid blackout_periods: expected {"periods": [{"start": "MM-DD", "end": "MM-DD"}]}`)
+	}
+
+	for i, p := range periods {
+		period, ok := p.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid blackout_periods: period %d is not an object", i+1)
+		}
+		for _, key := range []string{"start", "end"} {
+			value, ok := period[key].(string)
+			if !ok {
+				return fmt.Errorf("invalid blackout_periods: period %d has no %s", i+1, key)
+			}
+			// Parsed against a leap year so 02-29 is accepted
+			if _, err := time.Parse("2006-01-02", "2024-"+value); err != nil || len(value) != 5 {
+				return fmt.Errorf("invalid blackout_periods: period %d %s %q is not a MM-DD date", i+1, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// buildMonitoringCalendar splits the days from..to into the windows a trigger is monitored in
+// and the blackout windows it is skipped in
+func buildMonitoringCalendar(periods []blackoutPeriod, from, to time.Time) []models.MonitoringCalendarWindow {
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+	if limit := day.AddDate(0, 0, maxMonitoringCalendarDays); last.After(limit) {
+		last = limit
+	}
+
+	var windows []models.MonitoringCalendarWindow
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		monthDay := day.Format("01-02")
+		blackout := false
+		for _, period := range periods {
+			if period.contains(monthDay) {
+				blackout = true
+				break
+			}
+		}
+
+		date := day.Format("2006-01-02")
+		if n := len(windows); n > 0 && windows[n-1].Blackout == blackout {
+			windows[n-1].EndDate = date
+			continue
+		}
+		windows = append(windows, models.MonitoringCalendarWindow{
+			StartDate: date,
+			EndDate:   date,
+			Blackout:  blackout,
+		})
+	}
+	return windows
+}
+
+// GetMonitoringCalendar lays out, for each trigger of a policy, the days over its coverage when
+// the trigger is evaluated and the blackout days when it is not. Coverage that has not started
+// yet is shown from today. An empty providerID skips the ownership check for admin callers.
+func (s *RegisteredPolicyService) GetMonitoringCalendar(policyID uuid.UUID, providerID string) (*models.MonitoringCalendar, error) {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if providerID != "" && policy.InsuranceProviderID != providerID {
+		return nil, fmt.Errorf("unauthorized: policy belongs to another provider")
+	}
+
+	from := time.Now()
+	if policy.CoverageStartDate > 0 {
+		from = time.Unix(policy.CoverageStartDate, 0)
+	}
+	to := time.Unix(policy.CoverageEndDate, 0)
+	if policy.CoverageEndDate <= 0 || to.Before(from) {
+		return nil, fmt.Errorf("invalid policy: coverage period is not set")
+	}
+
+	triggers, err := s.basePolicyRepo.GetBasePolicyTriggersByPolicyID(policy.BasePolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base policy triggers: %w", err)
+	}
+
+	calendar := &models.MonitoringCalendar{
+		PolicyID:  policy.ID,
+		StartDate: from.Format("2006-01-02"),
+		EndDate:   to.Format("2006-01-02"),
+		Triggers:  make([]models.TriggerMonitoringCalendar, 0, len(triggers)),
+	}
+	for _, trigger := range triggers {
+		calendar.Triggers = append(calendar.Triggers, models.TriggerMonitoringCalendar{
+			TriggerID:            trigger.ID,
+			GrowthStage:          trigger.GrowthStage,
+			MonitorInterval:      trigger.MonitorInterval,
+			MonitorFrequencyUnit: trigger.MonitorFrequencyUnit,
+			Windows:              buildMonitoringCalendar(parseBlackoutPeriods(trigger.BlackoutPeriods), from, to),
+		})
+	}
+	return calendar, nil
+}
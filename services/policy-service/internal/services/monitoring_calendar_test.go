@@ -0,0 +1,80 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMonitoringCalendar_WrappingBlackout(t *testing.T) {
+	periods := parseBlackoutPeriods(map[string]any{
+		"periods": []any{
+			map[string]any{"start": "12-30", "end": "01-02"},
+		},
+	})
+	from := time.Date(2025, 12, 28, 9, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC)
+
+	windows := buildMonitoringCalendar(periods, from, to)
+
+	assert.Equal(t, []models.MonitoringCalendarWindow{
+		{StartDate: "2025-12-28", EndDate: "2025-12-29", Blackout: false},
+		{StartDate: "2025-12-30", EndDate: "2026-01-02", Blackout: true},
+		{StartDate: "2026-01-03", EndDate: "2026-01-05", Blackout: false},
+	}, windows)
+}
+
+func TestBuildMonitoringCalendar_NoBlackout(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 8, 31, 0, 0, 0, 0, time.UTC)
+
+	windows := buildMonitoringCalendar(nil, from, to)
+
+	assert.Equal(t, []models.MonitoringCalendarWindow{
+		{StartDate: "2026-03-01", EndDate: "2026-08-31", Blackout: false},
+	}, windows)
+}
+
+func TestValidateBlackoutPeriods(t *testing.T) {
+	tests := []struct {
+		name            string
+		blackoutPeriods map[string]any
+		wantErr         bool
+	}{
+		{name: "Not set", blackoutPeriods: nil},
+		{
+			name: "Valid periods",
+			blackoutPeriods: map[string]any{"periods": []any{
+				map[string]any{"start": "11-01", "end": "02-29"},
+			}},
+		},
+		{name: "Missing periods key", blackoutPeriods: map[string]any{"start": "11-01"}, wantErr: true},
+		{
+			name: "Full date instead of MM-DD",
+			blackoutPeriods: map[string]any{"periods": []any{
+				map[string]any{"start": "2026-11-01", "end": "12-31"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "Missing end",
+			blackoutPeriods: map[string]any{"periods": []any{
+				map[string]any{"start": "11-01"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBlackoutPeriods(tt.blackoutPeriods)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"policy-service/internal/config"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"strconv"
+	"time"
+)
+
+const monitoringArchiveFormat = "csv.gz"
+
+// Columns of an archived month of monitoring readings, one reading per row
+var monitoringArchiveHeader = []string{
+	"id", "farm_id", "data_source_id",
+	"parameter_name", "measured_value", "unit", "measurement_timestamp",
+	"component_data", "data_quality", "confidence_score",
+	"measurement_source", "distance_from_farm_meters", "cloud_cover_percentage",
+	"created_at",
+}
+
+// MonitoringRetentionService keeps the monthly partitions of farm_monitoring_data: it creates the
+// partitions of the coming months and moves the months past the retention window to MinIO
+type MonitoringRetentionService struct {
+	monitoringRepo *repository.FarmMonitoringDataRepository
+	minioClient    *minio.MinioClient
+	cfg            config.MonitoringRetentionConfig
+}
+
+func NewMonitoringRetentionService(monitoringRepo *repository.FarmMonitoringDataRepository, minioClient *minio.MinioClient, cfg config.MonitoringRetentionConfig) *MonitoringRetentionService {
+	return &MonitoringRetentionService{
+		monitoringRepo: monitoringRepo,
+		minioClient:    minioClient,
+		cfg:            cfg,
+	}
+}
+
+// MaintainPartitions creates the partitions of the current and coming months, then archives and
+// drops the partitions of the months past the retention window. A month failing to archive is
+// kept and retried on the next run.
+func (s *MonitoringRetentionService) MaintainPartitions(ctx context.Context) error {
+	now := time.Now()
+	for _, monthStart := range upcomingMonitoringMonths(now, s.cfg.PrecreateMonths) {
+		if _, err := s.monitoringRepo.EnsurePartition(ctx, monthStart); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.RetentionMonths <= 0 {
+		return nil
+	}
+	if s.minioClient == nil {
+		return fmt.Errorf("minio is not available, monitoring partitions past retention are kept")
+	}
+
+	partitions, err := s.monitoringRepo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, partition := range expiredMonitoringPartitions(partitions, monitoringRetentionHorizon(now, s.cfg.RetentionMonths)) {
+		if err := s.archivePartition(ctx, partition); err != nil {
+			slog.Error("Failed to archive farm monitoring partition", "partition", partition.Name, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// archivePartition streams a partition to MinIO as gzipped CSV, then drops it. The object key only
+// depends on the partition, so a run interrupted between the two steps overwrites its own export.
+func (s *MonitoringRetentionService) archivePartition(ctx context.Context, partition models.FarmMonitoringPartition) error {
+	objectKey := monitoringArchiveObjectKey(partition)
+
+	reader, writer := io.Pipe()
+	exported := make(chan int64, 1)
+	go func() {
+		count, err := s.exportPartition(ctx, partition.Name, writer)
+		exported <- count
+		writer.CloseWithError(err)
+	}()
+
+	err := s.minioClient.UploadFile(ctx, minio.Storage.MonitoringArchive, objectKey, reader, -1, "application/gzip")
+	reader.CloseWithError(err)
+	rowCount := <-exported
+	if err != nil {
+		return fmt.Errorf("failed to upload archive of %s: %w", partition.Name, err)
+	}
+
+	info, err := s.minioClient.StatFile(ctx, minio.Storage.MonitoringArchive, objectKey)
+	if err != nil {
+		return fmt.Errorf("failed to check archive of %s: %w", partition.Name, err)
+	}
+
+	return s.monitoringRepo.DropArchivedPartition(ctx, &models.FarmMonitoringArchive{
+		PartitionName: partition.Name,
+		RangeStart:    partition.MonthStart.Unix(),
+		RangeEnd:      partition.MonthStart.AddDate(0, 1, 0).Unix(),
+		Bucket:        minio.Storage.MonitoringArchive,
+		ObjectKey:     objectKey,
+		Format:        monitoringArchiveFormat,
+		RowCount:      rowCount,
+		SizeBytes:     info.Size,
+	})
+}
+
+// exportPartition writes the readings of a partition to w as gzipped CSV
+func (s *MonitoringRetentionService) exportPartition(ctx context.Context, partitionName string, w io.Writer) (int64, error) {
+	gz := gzip.NewWriter(w)
+	out := csv.NewWriter(gz)
+	if err := out.Write(monitoringArchiveHeader); err != nil {
+		return 0, err
+	}
+
+	count, err := s.monitoringRepo.ForEachInPartition(ctx, partitionName, func(data *models.FarmMonitoringData) error {
+		return out.Write(monitoringArchiveRecord(data))
+	})
+	if err != nil {
+		return count, err
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return count, fmt.Errorf("failed to write archive of %s: %w", partitionName, err)
+	}
+	if err := gz.Close(); err != nil {
+		return count, fmt.Errorf("failed to compress archive of %s: %w", partitionName, err)
+	}
+	return count, nil
+}
+
+// monitoringArchiveRecord is the CSV row of a reading, in the order of monitoringArchiveHeader.
+// Missing values are left empty.
+func monitoringArchiveRecord(data *models.FarmMonitoringData) []string {
+	componentData := ""
+	if len(data.ComponentData) > 0 {
+		if raw, err := json.Marshal(data.ComponentData); err == nil {
+			componentData = string(raw)
+		}
+	}
+
+	return []string{
+		data.ID.String(),
+		data.FarmID.String(),
+		data.DataSourceID.String(),
+		string(data.ParameterName),
+		strconv.FormatFloat(data.MeasuredValue, 'f', -1, 64),
+		stringOrEmpty(data.Unit),
+		strconv.FormatInt(data.MeasurementTimestamp, 10),
+		componentData,
+		string(data.DataQuality),
+		floatOrEmpty(data.ConfidenceScore),
+		stringOrEmpty(data.MeasurementSource),
+		floatOrEmpty(data.DistanceFromFarmMeters),
+		floatOrEmpty(data.CloudCoverPercentage),
+		data.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// monitoringArchiveObjectKey is where the readings of a partition are archived, by year and month
+func monitoringArchiveObjectKey(partition models.FarmMonitoringPartition) string {
+	return fmt.Sprintf("farm_monitoring_data/%s/%s.%s",
+		partition.MonthStart.Format("2006"), partition.Name, monitoringArchiveFormat)
+}
+
+// upcomingMonitoringMonths are the starts of the current month and of the ahead months after it
+func upcomingMonitoringMonths(now time.Time, ahead int) []time.Time {
+	current := repository.MonitoringMonthStart(now)
+	months := make([]time.Time, 0, ahead+1)
+	for i := 0; i <= ahead; i++ {
+		months = append(months, current.AddDate(0, i, 0))
+	}
+	return months
+}
+
+// monitoringRetentionHorizon is the start of the oldest month kept: the current month and the
+// retentionMonths before it stay in Postgres
+func monitoringRetentionHorizon(now time.Time, retentionMonths int) time.Time {
+	return repository.MonitoringMonthStart(now).AddDate(0, -retentionMonths, 0)
+}
+
+// expiredMonitoringPartitions are the partitions of the months before the horizon
+func expiredMonitoringPartitions(partitions []models.FarmMonitoringPartition, horizon time.Time) []models.FarmMonitoringPartition {
+	var expired []models.FarmMonitoringPartition
+	for _, partition := range partitions {
+		if partition.MonthStart.Before(horizon) {
+			expired = append(expired, partition)
+		}
+	}
+	return expired
+}
+
+func floatOrEmpty(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
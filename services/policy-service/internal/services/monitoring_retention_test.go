@@ -0,0 +1,82 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitoringPartitionMonths(t *testing.T) {
+	// Partitions follow UTC months, whatever the local time of the server
+	now := time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC)
+
+	months := upcomingMonitoringMonths(now, 2)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}, months)
+	assert.Equal(t, "farm_monitoring_data_p202602", repository.MonitoringPartitionName(months[1]))
+
+	monthStart, ok := repository.ParseMonitoringPartitionName("farm_monitoring_data_p202512")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), monthStart)
+	_, ok = repository.ParseMonitoringPartitionName("farm_monitoring_data_default")
+	assert.False(t, ok)
+}
+
+func TestExpiredMonitoringPartitions(t *testing.T) {
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	horizon := monitoringRetentionHorizon(now, 12)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), horizon)
+
+	var partitions []models.FarmMonitoringPartition
+	for _, month := range []time.Time{
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		partitions = append(partitions, models.FarmMonitoringPartition{Name: repository.MonitoringPartitionName(month), MonthStart: month})
+	}
+
+	expired := expiredMonitoringPartitions(partitions, horizon)
+	assert.Len(t, expired, 2)
+	assert.Equal(t, "farm_monitoring_data_p202501", expired[0].Name)
+	assert.Equal(t, "farm_monitoring_data_p202502", expired[1].Name)
+	assert.Equal(t, "farm_monitoring_data/2025/farm_monitoring_data_p202501.csv.gz", monitoringArchiveObjectKey(expired[0]))
+}
+
+func TestMonitoringArchiveRecord(t *testing.T) {
+	unit := "mm"
+	confidence := 0.9
+	data := &models.FarmMonitoringData{
+		ID:                   uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		FarmID:               uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		DataSourceID:         uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+		ParameterName:        models.DataSourceParameterName("rainfall"),
+		MeasuredValue:        12.5,
+		Unit:                 &unit,
+		MeasurementTimestamp: 1767225600,
+		ComponentData:        map[string]any{"station": "HN01"},
+		DataQuality:          models.DataQuality("good"),
+		ConfidenceScore:      &confidence,
+		CreatedAt:            time.Date(2026, 1, 1, 7, 0, 0, 0, time.FixedZone("ICT", 7*3600)),
+	}
+
+	record := monitoringArchiveRecord(data)
+	assert.Len(t, record, len(monitoringArchiveHeader))
+	assert.Equal(t, []string{
+		"11111111-1111-1111-1111-111111111111",
+		"22222222-2222-2222-2222-222222222222",
+		"33333333-3333-3333-3333-333333333333",
+		"rainfall", "12.5", "mm", "1767225600",
+		`{"station":"HN01"}`, "good", "0.9",
+		"", "", "",
+		"2026-01-01T00:00:00Z",
+	}, record)
+}
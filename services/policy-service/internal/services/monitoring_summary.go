@@ -0,0 +1,190 @@
+package services
+
+import (
+	"math"
+	"policy-service/internal/models"
+	"sort"
+)
+
+const (
+	// Width of the buckets the series of each parameter is downsampled to; a year of data gives
+	// about 53 points whatever the measurement frequency
+	monitoringSummaryBucketSeconds int64 = 7 * 24 * 60 * 60
+
+	// A measurement this many standard deviations from the mean of its parameter is an anomaly
+	monitoringAnomalyZScore = 3.0
+
+	// Anomalies kept per parameter, the furthest from the mean first
+	maxMonitoringAnomalies = 20
+)
+
+// summarizeMonitoringData computes per-parameter statistics, a weekly series and the anomalies of
+// the monitoring data. Parameters are sorted by name so the summary of equal data is equal.
+func summarizeMonitoringData(data []models.FarmMonitoringData) models.MonitoringDataSummary {
+	grouped := make(map[models.DataSourceParameterName][]models.FarmMonitoringData)
+	for _, d := range data {
+		grouped[d.ParameterName] = append(grouped[d.ParameterName], d)
+	}
+
+	names := make([]models.DataSourceParameterName, 0, len(grouped))
+	for name := range grouped {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	summary := models.MonitoringDataSummary{
+		TotalMeasurements: len(data),
+		BucketSeconds:     monitoringSummaryBucketSeconds,
+		Parameters:        make([]models.MonitoringParameterSummary, 0, len(names)),
+	}
+	for _, name := range names {
+		summary.Parameters = append(summary.Parameters, summarizeParameter(name, grouped[name]))
+	}
+	return summary
+}
+
+func summarizeParameter(name models.DataSourceParameterName, measurements []models.FarmMonitoringData) models.MonitoringParameterSummary {
+	sort.SliceStable(measurements, func(i, j int) bool {
+		return measurements[i].MeasurementTimestamp < measurements[j].MeasurementTimestamp
+	})
+
+	p := models.MonitoringParameterSummary{
+		ParameterName:  name,
+		Count:          len(measurements),
+		FirstTimestamp: measurements[0].MeasurementTimestamp,
+		LastTimestamp:  measurements[len(measurements)-1].MeasurementTimestamp,
+		Min:            math.Inf(1),
+		Max:            math.Inf(-1),
+		QualityCounts:  make(map[models.DataQuality]int),
+	}
+
+	values := make([]float64, len(measurements))
+	var sum float64
+	var confidence, cloudCover, distance []float64
+	for i, m := range measurements {
+		values[i] = m.MeasuredValue
+		sum += m.MeasuredValue
+		p.Min = math.Min(p.Min, m.MeasuredValue)
+		p.Max = math.Max(p.Max, m.MeasuredValue)
+		p.QualityCounts[m.DataQuality]++
+		if p.Unit == "" && m.Unit != nil {
+			p.Unit = *m.Unit
+		}
+		if m.ConfidenceScore != nil {
+			confidence = append(confidence, *m.ConfidenceScore)
+		}
+		if m.CloudCoverPercentage != nil {
+			cloudCover = append(cloudCover, *m.CloudCoverPercentage)
+		}
+		if m.DistanceFromFarmMeters != nil {
+			distance = append(distance, *m.DistanceFromFarmMeters)
+		}
+	}
+	p.Mean = sum / float64(len(values))
+	p.Median = median(values)
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - p.Mean) * (v - p.Mean)
+	}
+	p.StdDev = math.Sqrt(squares / float64(len(values)))
+
+	p.AvgConfidence = meanOrNil(confidence)
+	p.AvgCloudCover = meanOrNil(cloudCover)
+	p.AvgDistanceMeters = meanOrNil(distance)
+
+	p.Series = downsampleSeries(measurements, monitoringSummaryBucketSeconds)
+	p.Anomalies = findAnomalies(measurements, p.Mean, p.StdDev)
+	return p
+}
+
+// downsampleSeries aggregates measurements sorted by time into buckets aligned on the Unix epoch,
+// so the buckets of different parameters cover the same periods
+func downsampleSeries(measurements []models.FarmMonitoringData, bucketSeconds int64) []models.MonitoringSeriesPoint {
+	var series []models.MonitoringSeriesPoint
+	var sum float64
+	for _, m := range measurements {
+		start := m.MeasurementTimestamp - m.MeasurementTimestamp%bucketSeconds
+		if m.MeasurementTimestamp < 0 && m.MeasurementTimestamp%bucketSeconds != 0 {
+			start -= bucketSeconds
+		}
+
+		last := len(series) - 1
+		if last < 0 || series[last].PeriodStart != start {
+			if last >= 0 {
+				series[last].Mean = sum / float64(series[last].Count)
+			}
+			series = append(series, models.MonitoringSeriesPoint{
+				PeriodStart: start,
+				Min:         m.MeasuredValue,
+				Max:         m.MeasuredValue,
+			})
+			sum = 0
+			last++
+		}
+
+		point := &series[last]
+		point.Count++
+		point.Min = math.Min(point.Min, m.MeasuredValue)
+		point.Max = math.Max(point.Max, m.MeasuredValue)
+		sum += m.MeasuredValue
+	}
+	if len(series) > 0 {
+		series[len(series)-1].Mean = sum / float64(series[len(series)-1].Count)
+	}
+	return series
+}
+
+// findAnomalies returns the measurements at least monitoringAnomalyZScore standard deviations
+// from the mean, at most maxMonitoringAnomalies of them, in time order
+func findAnomalies(measurements []models.FarmMonitoringData, mean, stdDev float64) []models.MonitoringAnomaly {
+	if stdDev == 0 {
+		return nil
+	}
+
+	var anomalies []models.MonitoringAnomaly
+	for _, m := range measurements {
+		z := (m.MeasuredValue - mean) / stdDev
+		if math.Abs(z) >= monitoringAnomalyZScore {
+			anomalies = append(anomalies, models.MonitoringAnomaly{
+				MeasurementTimestamp: m.MeasurementTimestamp,
+				MeasuredValue:        m.MeasuredValue,
+				ZScore:               z,
+				DataQuality:          m.DataQuality,
+			})
+		}
+	}
+
+	if len(anomalies) > maxMonitoringAnomalies {
+		sort.SliceStable(anomalies, func(i, j int) bool {
+			return math.Abs(anomalies[i].ZScore) > math.Abs(anomalies[j].ZScore)
+		})
+		anomalies = anomalies[:maxMonitoringAnomalies]
+		sort.SliceStable(anomalies, func(i, j int) bool {
+			return anomalies[i].MeasurementTimestamp < anomalies[j].MeasurementTimestamp
+		})
+	}
+	return anomalies
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func meanOrNil(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	return &mean
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const day int64 = 24 * 60 * 60
+
+func measurement(name models.DataSourceParameterName, ts int64, value float64) models.FarmMonitoringData {
+	return models.FarmMonitoringData{
+		ParameterName:        name,
+		MeasuredValue:        value,
+		MeasurementTimestamp: ts,
+		DataQuality:          models.DataQualityGood,
+	}
+}
+
+func TestSummarizeMonitoringData(t *testing.T) {
+	unit := "mm"
+	confidence := 0.8
+	rain := measurement("rainfall", 3*day, 10)
+	rain.Unit = &unit
+	rain.ConfidenceScore = &confidence
+
+	data := []models.FarmMonitoringData{
+		measurement("ndvi", 8*day, 0.4),
+		rain,
+		measurement("ndvi", 1*day, 0.6),
+		measurement("ndvi", 2*day, 0.5),
+		measurement("rainfall", 10*day, 30),
+	}
+
+	summary := summarizeMonitoringData(data)
+	assert.Equal(t, 5, summary.TotalMeasurements)
+	assert.Equal(t, monitoringSummaryBucketSeconds, summary.BucketSeconds)
+	require.Len(t, summary.Parameters, 2)
+
+	ndvi := summary.Parameters[0]
+	assert.Equal(t, models.DataSourceParameterName("ndvi"), ndvi.ParameterName)
+	assert.Equal(t, 3, ndvi.Count)
+	assert.Equal(t, 1*day, ndvi.FirstTimestamp)
+	assert.Equal(t, 8*day, ndvi.LastTimestamp)
+	assert.InDelta(t, 0.4, ndvi.Min, 1e-9)
+	assert.InDelta(t, 0.6, ndvi.Max, 1e-9)
+	assert.InDelta(t, 0.5, ndvi.Mean, 1e-9)
+	assert.InDelta(t, 0.5, ndvi.Median, 1e-9)
+	assert.Equal(t, 3, ndvi.QualityCounts[models.DataQualityGood])
+	assert.Nil(t, ndvi.AvgConfidence)
+
+	// The Unix epoch is a Thursday: days 1 and 2 share a bucket, day 8 starts the next one
+	require.Len(t, ndvi.Series, 2)
+	assert.Equal(t, int64(0), ndvi.Series[0].PeriodStart)
+	assert.Equal(t, 2, ndvi.Series[0].Count)
+	assert.InDelta(t, 0.55, ndvi.Series[0].Mean, 1e-9)
+	assert.Equal(t, 7*day, ndvi.Series[1].PeriodStart)
+	assert.Equal(t, 1, ndvi.Series[1].Count)
+
+	rainfall := summary.Parameters[1]
+	assert.Equal(t, "mm", rainfall.Unit)
+	require.NotNil(t, rainfall.AvgConfidence)
+	assert.InDelta(t, 0.8, *rainfall.AvgConfidence, 1e-9)
+	assert.InDelta(t, 20, rainfall.Median, 1e-9)
+}
+
+func TestSummarizeMonitoringDataAnomalies(t *testing.T) {
+	var data []models.FarmMonitoringData
+	for i := int64(0); i < 50; i++ {
+		data = append(data, measurement("ndvi", i*day, 0.6))
+	}
+	data = append(data, measurement("ndvi", 60*day, 0.05))
+
+	ndvi := summarizeMonitoringData(data).Parameters[0]
+	require.Len(t, ndvi.Anomalies, 1)
+	assert.Equal(t, 60*day, ndvi.Anomalies[0].MeasurementTimestamp)
+	assert.InDelta(t, 0.05, ndvi.Anomalies[0].MeasuredValue, 1e-9)
+	assert.Less(t, ndvi.Anomalies[0].ZScore, -monitoringAnomalyZScore)
+
+	flat := summarizeMonitoringData(data[:50]).Parameters[0]
+	assert.Empty(t, flat.Anomalies, "no anomaly when every value is equal")
+}
+
+func TestFindAnomaliesKeepsTheFurthest(t *testing.T) {
+	var data []models.FarmMonitoringData
+	for i := 0; i < maxMonitoringAnomalies+5; i++ {
+		data = append(data, measurement("ndvi", int64(i)*day, float64(10+i)))
+	}
+
+	anomalies := findAnomalies(data, 0, 1)
+	require.Len(t, anomalies, maxMonitoringAnomalies)
+	assert.Equal(t, 5*day, anomalies[0].MeasurementTimestamp, "the values closest to the mean are dropped")
+	for i := 1; i < len(anomalies); i++ {
+		assert.Less(t, anomalies[i-1].MeasurementTimestamp, anomalies[i].MeasurementTimestamp)
+	}
+}
+
+func TestSummarizeMonitoringDataEmpty(t *testing.T) {
+	summary := summarizeMonitoringData(nil)
+	assert.Zero(t, summary.TotalMeasurements)
+	assert.Empty(t, summary.Parameters)
+}
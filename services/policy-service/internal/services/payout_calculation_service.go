@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+)
+
+const squareMetresPerHectare = 10000
+
+type PayoutCalculationService struct {
+	basePolicyRepo *repository.BasePolicyRepository
+	farmRepo       *repository.FarmRepository
+}
+
+func NewPayoutCalculationService(
+	basePolicyRepo *repository.BasePolicyRepository,
+	farmRepo *repository.FarmRepository,
+) *PayoutCalculationService {
+	return &PayoutCalculationService{
+		basePolicyRepo: basePolicyRepo,
+		farmRepo:       farmRepo,
+	}
+}
+
+// Calculate works out the payout of a claim. The fix payout is fix_payout_amount scaled by
+// payout_base_rate, and by the farm area in hectares when the payout is per hectare. The
+// threshold payout adds over_threshold_multiplier times the fix payout for each unit of relative
+// breach, and the total is limited to payout_cap.
+func (s *PayoutCalculationService) Calculate(basePolicy *models.BasePolicy, farmAreaSqm float64, breachMagnitude float64) models.PayoutCalculation {
+	calculation := models.PayoutCalculation{
+		FarmAreaHectares: farmAreaSqm / squareMetresPerHectare,
+		BreachMagnitude:  math.Max(breachMagnitude, 0),
+	}
+
+	fixPayout := float64(basePolicy.FixPayoutAmount) * basePolicy.PayoutBaseRate
+	if basePolicy.IsPayoutPerHectare {
+		fixPayout *= calculation.FarmAreaHectares
+	}
+	thresholdPayout := fixPayout * basePolicy.OverThresholdMultiplier * calculation.BreachMagnitude
+
+	calculation.FixPayout = roundAmount(fixPayout)
+	calculation.ThresholdPayout = roundAmount(thresholdPayout)
+	calculation.TotalPayout = calculation.FixPayout + calculation.ThresholdPayout
+	if basePolicy.PayoutCap != nil && *basePolicy.PayoutCap > 0 && calculation.TotalPayout > float64(*basePolicy.PayoutCap) {
+		calculation.TotalPayout = float64(*basePolicy.PayoutCap)
+		calculation.Capped = true
+	}
+
+	return calculation
+}
+
+// BreachMagnitude returns how far the worst of the triggered conditions went past its threshold,
+// relative to the threshold. Early warnings do not count, and a zero threshold gives the absolute
+// excess.
+func (s *PayoutCalculationService) BreachMagnitude(triggeredConditions []TriggeredCondition) float64 {
+	var magnitude float64
+	for _, tc := range triggeredConditions {
+		if tc.IsEarlyWarning {
+			continue
+		}
+
+		var overAmount float64
+		switch tc.Operator {
+		case models.ThresholdGT, models.ThresholdGTE, models.ThresholdChangeGT:
+			overAmount = tc.MeasuredValue - tc.ThresholdValue
+		case models.ThresholdLT, models.ThresholdLTE, models.ThresholdChangeLT:
+			overAmount = tc.ThresholdValue - tc.MeasuredValue
+		}
+		if tc.ThresholdValue != 0 {
+			overAmount /= math.Abs(tc.ThresholdValue)
+		}
+
+		magnitude = math.Max(magnitude, overAmount)
+	}
+	return magnitude
+}
+
+// PreviewPayout calculates the payout a base policy of the partner would make for a breach,
+// without creating a claim
+func (s *PayoutCalculationService) PreviewPayout(ctx context.Context, req models.PayoutPreviewRequest, partnerID string) (*models.PayoutCalculation, error) {
+	basePolicy, err := s.basePolicyRepo.GetBasePolicyByID(req.BasePolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("base policy not found: %w", err)
+	}
+	if basePolicy.InsuranceProviderID != partnerID {
+		return nil, fmt.Errorf("unauthorized: base policy does not belong to this partner")
+	}
+
+	areaSqm := req.FarmAreaSqm
+	if req.FarmID != nil {
+		farm, err := s.farmRepo.GetFarmByID(ctx, req.FarmID.String())
+		if err != nil {
+			return nil, fmt.Errorf("farm not found: %w", err)
+		}
+		areaSqm = farm.AreaSqm
+	}
+
+	calculation := s.Calculate(basePolicy, areaSqm, req.BreachMagnitude)
+	slog.Info("Payout previewed",
+		"base_policy_id", basePolicy.ID,
+		"farm_area_sqm", areaSqm,
+		"breach_magnitude", req.BreachMagnitude,
+		"total_payout", calculation.TotalPayout)
+	return &calculation, nil
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculate_FixedPayout(t *testing.T) {
+	service := &PayoutCalculationService{}
+	basePolicy := &models.BasePolicy{FixPayoutAmount: 1000000, PayoutBaseRate: 0.8}
+
+	calculation := service.Calculate(basePolicy, 25000, 0)
+
+	assert.Equal(t, 2.5, calculation.FarmAreaHectares)
+	assert.Equal(t, 800000.0, calculation.FixPayout)
+	assert.Equal(t, 0.0, calculation.ThresholdPayout)
+	assert.Equal(t, 800000.0, calculation.TotalPayout)
+	assert.False(t, calculation.Capped)
+}
+
+func TestCalculate_PerHectareWithBreach(t *testing.T) {
+	service := &PayoutCalculationService{}
+	basePolicy := &models.BasePolicy{
+		FixPayoutAmount:         1000000,
+		IsPayoutPerHectare:      true,
+		PayoutBaseRate:          1,
+		OverThresholdMultiplier: 2,
+	}
+
+	calculation := service.Calculate(basePolicy, 20000, 0.25)
+
+	assert.Equal(t, 2000000.0, calculation.FixPayout)
+	assert.Equal(t, 1000000.0, calculation.ThresholdPayout)
+	assert.Equal(t, 3000000.0, calculation.TotalPayout)
+}
+
+func TestCalculate_PayoutCap(t *testing.T) {
+	service := &PayoutCalculationService{}
+	payoutCap := 1500000
+	basePolicy := &models.BasePolicy{
+		FixPayoutAmount:         1000000,
+		PayoutBaseRate:          1,
+		OverThresholdMultiplier: 1,
+		PayoutCap:               &payoutCap,
+	}
+
+	calculation := service.Calculate(basePolicy, 10000, 1)
+
+	assert.Equal(t, 1500000.0, calculation.TotalPayout)
+	assert.True(t, calculation.Capped)
+}
+
+func TestBreachMagnitude(t *testing.T) {
+	service := &PayoutCalculationService{}
+	conditions := []TriggeredCondition{
+		// Rainfall 30 below a threshold of 100
+		{MeasuredValue: 70, ThresholdValue: 100, Operator: models.ThresholdLT},
+		// Temperature 4 above a threshold of 40
+		{MeasuredValue: 44, ThresholdValue: 40, Operator: models.ThresholdGT},
+		// Early warnings do not count towards the payout
+		{MeasuredValue: 10, ThresholdValue: 100, Operator: models.ThresholdLT, IsEarlyWarning: true},
+	}
+
+	assert.InDelta(t, 0.3, service.BreachMagnitude(conditions), 1e-9)
+	assert.Equal(t, 0.0, service.BreachMagnitude(nil))
+}
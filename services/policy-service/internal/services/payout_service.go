@@ -206,7 +206,8 @@ func (s *PayoutService) GetByProviderID(ctx context.Context, providerID string)
 	return s.payoutRepo.GetByInsuranceProvider(ctx, providerID)
 }
 
-func (s *PayoutService) ConfirmPayout(ctx context.Context, request models.ConfirmPayoutRequest, payoutID uuid.UUID) (string, error) {
+// ConfirmPayout records the farmer confirmation of a completed payout with farmer authorization
+func (s *PayoutService) ConfirmPayout(ctx context.Context, request models.ConfirmPayoutRequest, payoutID uuid.UUID, farmerID string) (string, error) {
 	payout, err := s.payoutRepo.GetByID(ctx, payoutID)
 	if err != nil {
 		slog.Error("error retriving payout", "error", err)
@@ -221,7 +222,7 @@ func (s *PayoutService) ConfirmPayout(ctx context.Context, request models.Confir
 		return "", err
 	}
 
-	if policy.FarmerID != payout.FarmerID {
+	if policy.FarmerID != payout.FarmerID || payout.FarmerID != farmerID {
 		return "", fmt.Errorf("unauthorized: policy does not belong to this user")
 	}
 
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Time a client has to PUT the file to the URL it was given
+	attachmentUploadExpiry = 15 * time.Minute
+	// Lifetime of the download links in responses
+	attachmentDownloadExpiry = time.Hour
+)
+
+var allowedAttachmentExtensions = []string{".pdf", ".doc", ".docx", ".png", ".jpg", ".jpeg"}
+
+var validAttachmentTypes = map[models.PolicyAttachmentType]bool{
+	models.PolicyAttachmentContract:   true,
+	models.PolicyAttachmentAppendix:   true,
+	models.PolicyAttachmentBrochure:   true,
+	models.PolicyAttachmentSignedCopy: true,
+	models.PolicyAttachmentOther:      true,
+}
+
+// PolicyAttachmentService manages the files attached to base and registered policies. Clients
+// upload files straight to MinIO with presigned URLs, then confirm the upload.
+type PolicyAttachmentService struct {
+	attachmentRepo       *repository.PolicyAttachmentRepository
+	basePolicyRepo       *repository.BasePolicyRepository
+	registeredPolicyRepo *repository.RegisteredPolicyRepository
+	minioClient          *minio.MinioClient
+}
+
+func NewPolicyAttachmentService(
+	attachmentRepo *repository.PolicyAttachmentRepository,
+	basePolicyRepo *repository.BasePolicyRepository,
+	registeredPolicyRepo *repository.RegisteredPolicyRepository,
+	minioClient *minio.MinioClient,
+) *PolicyAttachmentService {
+	return &PolicyAttachmentService{
+		attachmentRepo:       attachmentRepo,
+		basePolicyRepo:       basePolicyRepo,
+		registeredPolicyRepo: registeredPolicyRepo,
+		minioClient:          minioClient,
+	}
+}
+
+// CreateAttachment records the next version of a document of a policy and returns the URL its
+// file is uploaded to. The attachment is listed once ConfirmUpload finds the file.
+func (s *PolicyAttachmentService) CreateAttachment(ctx context.Context, req models.CreatePolicyAttachmentRequest, uploadedBy string) (*models.CreatePolicyAttachmentResponse, error) {
+	if err := validateAttachmentRequest(req); err != nil {
+		return nil, err
+	}
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+	if err := s.checkPolicyExists(req.BasePolicyID, req.RegisteredPolicyID); err != nil {
+		return nil, err
+	}
+
+	version, err := s.attachmentRepo.NextVersion(ctx, req.BasePolicyID, req.RegisteredPolicyID, req.DocumentType)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.PolicyAttachment{
+		ID:                 uuid.New(),
+		BasePolicyID:       req.BasePolicyID,
+		RegisteredPolicyID: req.RegisteredPolicyID,
+		DocumentType:       req.DocumentType,
+		Version:            version,
+		Title:              req.Title,
+		FileName:           req.FileName,
+		ContentType:        minio.GetContentType(req.FileName),
+		Status:             models.PolicyAttachmentPendingUpload,
+		UploadedBy:         uploadedBy,
+	}
+	attachment.ObjectKey = attachmentObjectKey(attachment)
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	uploadURL, err := s.minioClient.GetPresignedUploadURL(ctx, minio.Storage.PolicyAttachments, attachment.ObjectKey, attachmentUploadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Policy attachment created, waiting for upload",
+		"attachment_id", attachment.ID,
+		"document_type", attachment.DocumentType,
+		"version", attachment.Version,
+		"object_key", attachment.ObjectKey)
+
+	return &models.CreatePolicyAttachmentResponse{
+		Attachment:      attachment,
+		UploadURL:       uploadURL,
+		UploadExpiresAt: time.Now().Add(attachmentUploadExpiry),
+	}, nil
+}
+
+// ConfirmUpload marks an attachment uploaded once its file is in storage
+func (s *PolicyAttachmentService) ConfirmUpload(ctx context.Context, id uuid.UUID) (*models.PolicyAttachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.Status == models.PolicyAttachmentUploaded {
+		return s.withDownloadURL(ctx, attachment), nil
+	}
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+
+	exists, err := s.minioClient.FileExists(ctx, minio.Storage.PolicyAttachments, attachment.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("invalid operation: file of attachment %s has not been uploaded", id)
+	}
+	info, err := s.minioClient.StatFile(ctx, minio.Storage.PolicyAttachments, attachment.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachmentRepo.MarkUploaded(ctx, id, info.Size); err != nil {
+		return nil, err
+	}
+	attachment.Status = models.PolicyAttachmentUploaded
+	attachment.SizeBytes = &info.Size
+
+	return s.withDownloadURL(ctx, attachment), nil
+}
+
+func (s *PolicyAttachmentService) GetAttachment(ctx context.Context, id uuid.UUID) (*models.PolicyAttachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withDownloadURL(ctx, attachment), nil
+}
+
+func (s *PolicyAttachmentService) ListAttachments(ctx context.Context, filter models.PolicyAttachmentFilter) ([]models.PolicyAttachment, error) {
+	if (filter.BasePolicyID == nil) == (filter.RegisteredPolicyID == nil) {
+		return nil, fmt.Errorf("invalid filter: exactly one of base_policy_id and registered_policy_id is required")
+	}
+	if filter.DocumentType != "" && !validAttachmentTypes[filter.DocumentType] {
+		return nil, fmt.Errorf("invalid document_type: %s", filter.DocumentType)
+	}
+
+	attachments, err := s.attachmentRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		s.withDownloadURL(ctx, &attachments[i])
+	}
+	return attachments, nil
+}
+
+func (s *PolicyAttachmentService) UpdateAttachment(ctx context.Context, id uuid.UUID, req models.UpdatePolicyAttachmentRequest) (*models.PolicyAttachment, error) {
+	if err := s.attachmentRepo.UpdateTitle(ctx, id, req.Title); err != nil {
+		return nil, err
+	}
+	return s.GetAttachment(ctx, id)
+}
+
+// DeleteAttachment removes an attachment and its file. Other versions of the document are kept.
+func (s *PolicyAttachmentService) DeleteAttachment(ctx context.Context, id uuid.UUID) error {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.attachmentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// The row is gone, a file left behind is only wasted space
+	if s.minioClient != nil {
+		if err := s.minioClient.DeleteFile(ctx, minio.Storage.PolicyAttachments, attachment.ObjectKey); err != nil {
+			slog.Error("Failed to delete policy attachment file",
+				"attachment_id", id,
+				"object_key", attachment.ObjectKey,
+				"error", err)
+		}
+	}
+	return nil
+}
+
+func (s *PolicyAttachmentService) checkPolicyExists(basePolicyID, registeredPolicyID *uuid.UUID) error {
+	if basePolicyID != nil {
+		exists, err := s.basePolicyRepo.CheckBasePolicyExists(*basePolicyID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("base policy not found: %s", *basePolicyID)
+		}
+		return nil
+	}
+
+	if _, err := s.registeredPolicyRepo.GetByID(*registeredPolicyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("registered policy not found: %s", *registeredPolicyID)
+		}
+		return err
+	}
+	return nil
+}
+
+// withDownloadURL fills the download link of an uploaded attachment; without one the client can
+// still see the attachment, so a failure is only logged
+func (s *PolicyAttachmentService) withDownloadURL(ctx context.Context, attachment *models.PolicyAttachment) *models.PolicyAttachment {
+	if attachment.Status != models.PolicyAttachmentUploaded || s.minioClient == nil {
+		return attachment
+	}
+	downloadURL, err := s.minioClient.GetPresignedURL(ctx, minio.Storage.PolicyAttachments, attachment.ObjectKey, attachmentDownloadExpiry)
+	if err != nil {
+		slog.Error("Failed to presign policy attachment", "attachment_id", attachment.ID, "error", err)
+		return attachment
+	}
+	attachment.DownloadURL = &downloadURL
+	return attachment
+}
+
+func validateAttachmentRequest(req models.CreatePolicyAttachmentRequest) error {
+	if (req.BasePolicyID == nil) == (req.RegisteredPolicyID == nil) {
+		return fmt.Errorf("invalid request: exactly one of base_policy_id and registered_policy_id is required")
+	}
+	if !validAttachmentTypes[req.DocumentType] {
+		return fmt.Errorf("invalid document_type: %s", req.DocumentType)
+	}
+	if req.DocumentType == models.PolicyAttachmentSignedCopy && req.RegisteredPolicyID == nil {
+		return fmt.Errorf("invalid request: signed copies belong to a registered policy")
+	}
+	if strings.TrimSpace(req.FileName) == "" {
+		return fmt.Errorf("invalid request: file_name is required")
+	}
+	if len(req.FileName) > 255 {
+		return fmt.Errorf("invalid request: file_name is longer than 255 characters")
+	}
+	if ext := strings.ToLower(filepath.Ext(req.FileName)); !slices.Contains(allowedAttachmentExtensions, ext) {
+		return fmt.Errorf("invalid file type %q, allowed: %s", ext, strings.Join(allowedAttachmentExtensions, ", "))
+	}
+	return nil
+}
+
+// attachmentObjectKey places the file under its policy and document type, e.g.
+// base-policies/<id>/contract/v2-<attachment id>-<file name>
+func attachmentObjectKey(attachment *models.PolicyAttachment) string {
+	var owner string
+	if attachment.BasePolicyID != nil {
+		owner = "base-policies/" + attachment.BasePolicyID.String()
+	} else {
+		owner = "registered-policies/" + attachment.RegisteredPolicyID.String()
+	}
+	return fmt.Sprintf("%s/%s/v%d-%s-%s", owner, attachment.DocumentType, attachment.Version,
+		attachment.ID, minio.GetSafeFileName(filepath.Base(attachment.FileName)))
+}
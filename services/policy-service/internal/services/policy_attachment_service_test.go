@@ -0,0 +1,84 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAttachmentRequest(t *testing.T) {
+	baseID := uuid.New()
+	registeredID := uuid.New()
+
+	tests := []struct {
+		name    string
+		req     models.CreatePolicyAttachmentRequest
+		wantErr string
+	}{
+		{
+			name: "Base policy contract",
+			req:  models.CreatePolicyAttachmentRequest{BasePolicyID: &baseID, DocumentType: models.PolicyAttachmentContract, FileName: "contract.pdf"},
+		},
+		{
+			name: "Registered policy signed copy",
+			req:  models.CreatePolicyAttachmentRequest{RegisteredPolicyID: &registeredID, DocumentType: models.PolicyAttachmentSignedCopy, FileName: "signed.PDF"},
+		},
+		{
+			name:    "No policy",
+			req:     models.CreatePolicyAttachmentRequest{DocumentType: models.PolicyAttachmentContract, FileName: "contract.pdf"},
+			wantErr: "exactly one of",
+		},
+		{
+			name:    "Both policies",
+			req:     models.CreatePolicyAttachmentRequest{BasePolicyID: &baseID, RegisteredPolicyID: &registeredID, DocumentType: models.PolicyAttachmentContract, FileName: "contract.pdf"},
+			wantErr: "exactly one of",
+		},
+		{
+			name:    "Unknown type",
+			req:     models.CreatePolicyAttachmentRequest{BasePolicyID: &baseID, DocumentType: "invoice", FileName: "invoice.pdf"},
+			wantErr: "invalid document_type",
+		},
+		{
+			name:    "Signed copy of a base policy",
+			req:     models.CreatePolicyAttachmentRequest{BasePolicyID: &baseID, DocumentType: models.PolicyAttachmentSignedCopy, FileName: "signed.pdf"},
+			wantErr: "signed copies",
+		},
+		{
+			name:    "Disallowed extension",
+			req:     models.CreatePolicyAttachmentRequest{BasePolicyID: &baseID, DocumentType: models.PolicyAttachmentBrochure, FileName: "brochure.exe"},
+			wantErr: "invalid file type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAttachmentRequest(tt.req)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAttachmentObjectKey(t *testing.T) {
+	baseID := uuid.New()
+	attachment := &models.PolicyAttachment{
+		ID:           uuid.New(),
+		BasePolicyID: &baseID,
+		DocumentType: models.PolicyAttachmentAppendix,
+		Version:      3,
+		FileName:     "../phụ lục.pdf",
+	}
+
+	key := attachmentObjectKey(attachment)
+	assert.Equal(t, "base-policies/"+baseID.String()+"/appendix/v3-"+attachment.ID.String()+"-ph%E1%BB%A5%20l%E1%BB%A5c.pdf", key)
+
+	registeredID := uuid.New()
+	attachment.BasePolicyID = nil
+	attachment.RegisteredPolicyID = &registeredID
+	assert.Contains(t, attachmentObjectKey(attachment), "registered-policies/"+registeredID.String()+"/appendix/v3-")
+}
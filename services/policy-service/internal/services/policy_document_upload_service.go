@@ -0,0 +1,224 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"path/filepath"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	policyDocumentUploadExpiry  = 15 * time.Minute
+	maxPolicyDocumentUploadSize = 50 << 20 // 50MB
+	policyDocumentContentType   = "application/pdf"
+)
+
+var pdfMagic = []byte("%PDF-")
+
+// PolicyDocumentUploadService lets clients upload the PDF of a base policy straight to MinIO
+// instead of sending it base64 encoded in the creation request. Files are staged in the private
+// attachments bucket until a base policy uses them.
+type PolicyDocumentUploadService struct {
+	uploadRepo  *repository.PolicyDocumentUploadRepository
+	minioClient *minio.MinioClient
+}
+
+func NewPolicyDocumentUploadService(uploadRepo *repository.PolicyDocumentUploadRepository, minioClient *minio.MinioClient) *PolicyDocumentUploadService {
+	return &PolicyDocumentUploadService{
+		uploadRepo:  uploadRepo,
+		minioClient: minioClient,
+	}
+}
+
+// CreateUpload registers a pending upload and returns the presigned URL the file is PUT to
+func (s *PolicyDocumentUploadService) CreateUpload(ctx context.Context, req models.CreatePolicyDocumentUploadRequest, uploadedBy string) (*models.CreatePolicyDocumentUploadResponse, error) {
+	if err := validateDocumentUploadRequest(req); err != nil {
+		return nil, err
+	}
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+
+	upload := &models.PolicyDocumentUpload{
+		ID:         uuid.New(),
+		FileName:   req.FileName,
+		Status:     models.PolicyDocumentUploadPending,
+		UploadedBy: uploadedBy,
+	}
+	upload.ObjectKey = fmt.Sprintf("document-uploads/%s-%s", upload.ID, minio.GetSafeFileName(filepath.Base(req.FileName)))
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	uploadURL, err := s.minioClient.GetPresignedUploadURL(ctx, minio.Storage.PolicyAttachments, upload.ObjectKey, policyDocumentUploadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreatePolicyDocumentUploadResponse{
+		Upload:          upload,
+		UploadURL:       uploadURL,
+		UploadExpiresAt: time.Now().Add(policyDocumentUploadExpiry),
+		MaxSizeBytes:    maxPolicyDocumentUploadSize,
+	}, nil
+}
+
+// ConfirmUpload checks the uploaded file is a PDF within the size limit and makes it usable in a
+// complete policy creation request. A rejected file is removed so the client can upload again
+// while the URL is valid.
+func (s *PolicyDocumentUploadService) ConfirmUpload(ctx context.Context, id uuid.UUID, userID string) (*models.PolicyDocumentUpload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UploadedBy != userID {
+		return nil, fmt.Errorf("policy document upload not found: %s", id)
+	}
+	if upload.Status != models.PolicyDocumentUploadPending {
+		return upload, nil
+	}
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("file storage is not available")
+	}
+
+	exists, err := s.minioClient.FileExists(ctx, minio.Storage.PolicyAttachments, upload.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("invalid operation: file of upload %s has not been uploaded", id)
+	}
+	info, err := s.minioClient.StatFile(ctx, minio.Storage.PolicyAttachments, upload.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.readFileHead(ctx, upload.ObjectKey, len(pdfMagic))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUploadedDocument(info.ContentType, info.Size, head); err != nil {
+		if delErr := s.minioClient.DeleteFile(ctx, minio.Storage.PolicyAttachments, upload.ObjectKey); delErr != nil {
+			slog.Error("Failed to delete rejected policy document upload",
+				"upload_id", id,
+				"object_key", upload.ObjectKey,
+				"error", delErr)
+		}
+		return nil, err
+	}
+
+	if err := s.uploadRepo.MarkUploaded(ctx, id, policyDocumentContentType, info.Size); err != nil {
+		return nil, err
+	}
+	contentType := policyDocumentContentType
+	upload.Status = models.PolicyDocumentUploadUploaded
+	upload.ContentType = &contentType
+	upload.SizeBytes = &info.Size
+
+	slog.Info("Policy document upload confirmed",
+		"upload_id", id,
+		"object_key", upload.ObjectKey,
+		"size_bytes", info.Size)
+	return upload, nil
+}
+
+// GetConfirmedUpload returns the upload a creation request refers to, as long as the requesting
+// user confirmed it and no base policy used it yet
+func (s *PolicyDocumentUploadService) GetConfirmedUpload(ctx context.Context, objectKey, userID string) (*models.PolicyDocumentUpload, error) {
+	upload, err := s.uploadRepo.GetByObjectKey(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UploadedBy != userID {
+		return nil, fmt.Errorf("policy document upload not found: %s", objectKey)
+	}
+	switch upload.Status {
+	case models.PolicyDocumentUploadPending:
+		return nil, fmt.Errorf("invalid policy document: upload %s has not been confirmed", upload.ID)
+	case models.PolicyDocumentUploadUsed:
+		return nil, fmt.Errorf("invalid policy document: upload %s is already used by base policy %s", upload.ID, upload.BasePolicyID)
+	}
+	return upload, nil
+}
+
+// PublishUpload copies a confirmed upload to the template path of a base policy in the
+// policy-documents bucket and drops the staged file
+func (s *PolicyDocumentUploadService) PublishUpload(ctx context.Context, upload *models.PolicyDocumentUpload, templatePath string, basePolicyID uuid.UUID) error {
+	if s.minioClient == nil {
+		return fmt.Errorf("file storage is not available")
+	}
+
+	if err := s.minioClient.CopyFile(ctx, minio.Storage.PolicyAttachments, upload.ObjectKey, minio.Storage.PolicyDocuments, templatePath); err != nil {
+		return err
+	}
+	if err := s.uploadRepo.MarkUsed(ctx, upload.ID, basePolicyID); err != nil {
+		return err
+	}
+
+	// The template copy is what the policy serves from now on
+	if err := s.minioClient.DeleteFile(ctx, minio.Storage.PolicyAttachments, upload.ObjectKey); err != nil {
+		slog.Error("Failed to delete staged policy document",
+			"upload_id", upload.ID,
+			"object_key", upload.ObjectKey,
+			"error", err)
+	}
+	return nil
+}
+
+func (s *PolicyDocumentUploadService) readFileHead(ctx context.Context, objectKey string, n int) ([]byte, error) {
+	obj, err := s.minioClient.GetFile(ctx, minio.Storage.PolicyAttachments, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	head := make([]byte, n)
+	read, err := io.ReadFull(obj, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read policy document upload %s: %w", objectKey, err)
+	}
+	return head[:read], nil
+}
+
+func validateDocumentUploadRequest(req models.CreatePolicyDocumentUploadRequest) error {
+	if strings.TrimSpace(req.FileName) == "" {
+		return fmt.Errorf("invalid request: file_name is required")
+	}
+	if len(req.FileName) > 255 {
+		return fmt.Errorf("invalid request: file_name is longer than 255 characters")
+	}
+	if ext := strings.ToLower(filepath.Ext(req.FileName)); ext != ".pdf" {
+		return fmt.Errorf("invalid file type %q, only .pdf is allowed", ext)
+	}
+	return nil
+}
+
+// validateUploadedDocument checks what was PUT to the presigned URL: the declared content type,
+// the size and the PDF signature at the start of the file
+func validateUploadedDocument(contentType string, size int64, head []byte) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != policyDocumentContentType {
+		return fmt.Errorf("invalid file: content type %q, expected %s", contentType, policyDocumentContentType)
+	}
+	if size <= 0 {
+		return fmt.Errorf("invalid file: the file is empty")
+	}
+	if size > maxPolicyDocumentUploadSize {
+		return fmt.Errorf("invalid file: larger than %dMB", maxPolicyDocumentUploadSize>>20)
+	}
+	if !bytes.HasPrefix(head, pdfMagic) {
+		return fmt.Errorf("invalid file: not a PDF document")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDocumentUploadRequest(t *testing.T) {
+	assert.NoError(t, validateDocumentUploadRequest(models.CreatePolicyDocumentUploadRequest{FileName: "Hợp đồng.PDF"}))
+	assert.ErrorContains(t, validateDocumentUploadRequest(models.CreatePolicyDocumentUploadRequest{FileName: "  "}), "file_name is required")
+	assert.ErrorContains(t, validateDocumentUploadRequest(models.CreatePolicyDocumentUploadRequest{FileName: "contract.docx"}), "only .pdf")
+}
+
+func TestValidateUploadedDocument(t *testing.T) {
+	pdfHead := []byte("%PDF-")
+
+	tests := []struct {
+		name        string
+		contentType string
+		size        int64
+		head        []byte
+		wantErr     string
+	}{
+		{name: "PDF", contentType: "application/pdf", size: 1024, head: pdfHead},
+		{name: "PDF with parameters", contentType: "application/pdf; charset=binary", size: 1024, head: pdfHead},
+		{name: "Wrong content type", contentType: "application/octet-stream", size: 1024, head: pdfHead, wantErr: "content type"},
+		{name: "Missing content type", contentType: "", size: 1024, head: pdfHead, wantErr: "content type"},
+		{name: "Empty file", contentType: "application/pdf", size: 0, head: nil, wantErr: "empty"},
+		{name: "Too large", contentType: "application/pdf", size: maxPolicyDocumentUploadSize + 1, head: pdfHead, wantErr: "larger than 50MB"},
+		{name: "Not a PDF", contentType: "application/pdf", size: 1024, head: []byte("PK\x03\x04"), wantErr: "not a PDF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateUploadedDocument(tt.contentType, tt.size, tt.head)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	utils "agrisa_utils"
 	"context"
 	"fmt"
 	"log/slog"
@@ -18,7 +17,8 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// PolicyExpirationService handles auto-commit of expired archive policies
+// PolicyExpirationService acts on the Redis keys expiring at the deadlines of policies: the end of
+// the validation window of drafts, of enrollment, of validity and of cancellation notice periods
 type PolicyExpirationService struct {
 	redisClient               *redis.Client
 	minioClient               *minio.MinioClient
@@ -30,8 +30,17 @@ type PolicyExpirationService struct {
 	cancelRequestRepo         *repository.CancelRequestRepository
 	basePolicyRepo            *repository.BasePolicyRepository
 	notievent                 *event.NotificationHelper
+	draftExpirationRepo       *repository.DraftExpirationEventRepository
+	locker                    *worker.Locker
 }
 
+// expiredKeyClaimTTL is how long the claim on an expired key is kept. Every instance hears the
+// expiry at once, so it only has to outlast the delivery to all of them.
+const expiredKeyClaimTTL = 10 * time.Minute
+
+// expiredKeyClaimPrefix starts the key claiming an expired key, followed by the expired key
+const expiredKeyClaimPrefix = "expiration:claim:"
+
 // ExpirationStats tracks processing statistics
 type ExpirationStats struct {
 	TotalExpired      int64
@@ -42,9 +51,9 @@ type ExpirationStats struct {
 }
 
 // NewPolicyExpirationService creates a new expiration service instance
-func NewPolicyExpirationService(redisClient *redis.Client, policyService *BasePolicyService, minioClient *minio.MinioClient, policyRepo *repository.RegisteredPolicyRepository, basePolicyRepo *repository.BasePolicyRepository, notievent *event.NotificationHelper, workerManager *worker.WorkerManagerV2, cancelRequestRepo *repository.CancelRequestRepository) *PolicyExpirationService {
+func NewPolicyExpirationService(redisClient *redis.Client, policyService *BasePolicyService, minioClient *minio.MinioClient, policyRepo *repository.RegisteredPolicyRepository, basePolicyRepo *repository.BasePolicyRepository, notievent *event.NotificationHelper, workerManager *worker.WorkerManagerV2, cancelRequestRepo *repository.CancelRequestRepository, outboxRepo *repository.OutboxRepository, draftExpirationRepo *repository.DraftExpirationEventRepository) *PolicyExpirationService {
 	validityCalculator := NewBasePolicyValidityCalculator()
-	policyRenewalOrchestrator := NewPolicyRenewalOrchestrator(basePolicyRepo, policyRepo, validityCalculator, workerManager, notievent)
+	policyRenewalOrchestrator := NewPolicyRenewalOrchestrator(basePolicyRepo, policyRepo, validityCalculator, workerManager, notievent, outboxRepo, policyService)
 	return &PolicyExpirationService{
 		minioClient:   minioClient,
 		redisClient:   redisClient,
@@ -58,6 +67,8 @@ func NewPolicyExpirationService(redisClient *redis.Client, policyService *BasePo
 		notievent:                 notievent,
 		cancelRequestRepo:         cancelRequestRepo,
 		registerPolicyRepo:        policyRepo,
+		draftExpirationRepo:       draftExpirationRepo,
+		locker:                    workerManager.Locker(),
 	}
 }
 
@@ -73,8 +84,11 @@ func (s *PolicyExpirationService) StartListener(ctx context.Context) error {
 	for {
 		select {
 		case msg := <-pubsub.Channel():
-			if s.isArchivePolicyKey(msg.Payload) {
-				go s.processExpiredDraftPolicy(ctx, msg.Payload)
+			if !s.isHandledKey(msg.Payload) || !s.claimExpiredKey(ctx, msg.Payload) {
+				continue
+			}
+			if s.isDraftExpiryKey(msg.Payload) {
+				go s.processExpiredDraft(ctx, msg.Payload)
 			}
 			if s.isValidDateKey(msg.Payload) {
 				slog.Info("DEBUG Expiration key catched", "key", msg.Payload)
@@ -112,15 +126,34 @@ func (s *PolicyExpirationService) Stop() {
 	close(s.stopChannel)
 }
 
-// isArchivePolicyKey checks if the expired key is a BasePolicy with archive:true
-func (s *PolicyExpirationService) isArchivePolicyKey(expiredKey string) bool {
-	// Pattern: {provider}--{policyID}--BasePolicy--archive:true
-	if strings.Contains(expiredKey, "--BasePolicy--archive:true--COMMIT_EVENT") {
+// isHandledKey checks if the expired key is one this service acts on. A claim contains the key it
+// claims, its own expiry is not one.
+func (s *PolicyExpirationService) isHandledKey(expiredKey string) bool {
+	if strings.HasPrefix(expiredKey, expiredKeyClaimPrefix) {
+		return false
+	}
+	return s.isDraftExpiryKey(expiredKey) || s.isValidDateKey(expiredKey) ||
+		s.isEnrollmentClosed(expiredKey) || s.isNoticePeriod(expiredKey)
+}
+
+// claimExpiredKey reports whether this instance is the one to act on an expired key. Every
+// instance listening hears the expiry; the first to claim it acts on it.
+func (s *PolicyExpirationService) claimExpiredKey(ctx context.Context, expiredKey string) bool {
+	claimed, err := s.locker.Acquire(ctx, expiredKeyClaimPrefix+expiredKey, expiredKeyClaimTTL)
+	if err != nil {
+		// Acting twice is safer than not at all: the handlers check the state they change
+		slog.Error("Failed to claim expired key, handling it anyway", "key", expiredKey, "error", err)
 		return true
 	}
-	expiredKey = strings.Split(expiredKey, "--COMMIT_EVENT")[0]
-	go s.processUnArchivedExpiredPolicy(context.Background(), expiredKey)
-	return false
+	if !claimed {
+		slog.Debug("Expired key handled by another instance", "key", expiredKey)
+	}
+	return claimed
+}
+
+// isDraftExpiryKey checks if the expired key ends the validation window of a draft policy
+func (s *PolicyExpirationService) isDraftExpiryKey(expiredKey string) bool {
+	return strings.HasSuffix(expiredKey, repository.DraftExpiryKeySuffix)
 }
 
 func (s *PolicyExpirationService) isEnrollmentClosed(expiredKey string) bool {
@@ -257,19 +290,8 @@ func (s *PolicyExpirationService) processExpiredPolicy(ctx context.Context, expi
 
 	slog.Info("policy renew successfully", "result", result)
 
-	if result.IsExpired {
-		go func() {
-			for {
-				err := s.notievent.NotifyPolicyExpiredBatch(context.Background(), result.FarmerIDs, result.PolicyCode)
-				if err == nil {
-					slog.Info("policy expiration notification sent", "policy id", result.PolicyCode)
-					return
-				}
-				slog.Error("error sending policy expiration notification", "error", err)
-				time.Sleep(10 * time.Second)
-			}
-		}()
-	} else {
+	// Expired policies notify their farmers through the outbox as they are saved
+	if !result.IsExpired {
 		go func() {
 			for {
 				err := s.notievent.NotifyPolicyRenewedBatch(context.Background(), result.FarmerIDs, result.PolicyCode)
@@ -284,93 +306,188 @@ func (s *PolicyExpirationService) processExpiredPolicy(ctx context.Context, expi
 	}
 }
 
-func (s *PolicyExpirationService) processUnArchivedExpiredPolicy(ctx context.Context, expiredKey string) {
+// Retries of draft expiration events back off from draftExpiryRetryBase up to
+// draftExpiryRetryMax; past draftExpiryAlertAttempts each failure is logged as critical
+const (
+	draftExpiryRetryBase     = 30 * time.Second
+	draftExpiryRetryMax      = time.Hour
+	draftExpiryAlertAttempts = 5
+	draftExpirySweepBatch    = 100
+)
+
+// processExpiredDraft records the end of the validation window of the draft whose expiry key
+// expired and processes it. A failed attempt is retried by SweepExpiredDrafts.
+func (s *PolicyExpirationService) processExpiredDraft(ctx context.Context, expiredKey string) {
 	defer func() {
 		if r := recover(); r != nil {
-			slog.Error("Panic recovery", "panic", r)
+			slog.Error("CRITICAL: Panic recovery", "panic", r)
 		}
 	}()
-	policyData, err := s.policyService.basePolicyRepo.GetTempBasePolicyModels(ctx, expiredKey)
+	slog.Info("Processing expired draft policy", "expired_key", expiredKey)
+
+	draftID, err := uuid.Parse(strings.TrimSuffix(expiredKey, repository.DraftExpiryKeySuffix))
 	if err != nil {
-		slog.Error("Failed to extract Policy data", "error", err)
+		slog.Error("Failed to parse draft policy ID", "expired_key", expiredKey, "error", err)
 		return
 	}
-	var policy models.BasePolicy
-	err = utils.DeserializeModel(policyData, &policy)
-	if err != nil {
-		slog.Error("Failed to deserialze policy model", "error", err)
+	if err := s.draftExpirationRepo.Record(ctx, draftID, models.DraftExpiryListener); err != nil {
+		// The sweeper records it from the draft
+		slog.Error("Failed to record draft expiration", "draft_id", draftID, "error", err)
 		return
 	}
+	if err := s.processDraftExpiration(ctx, draftID); err != nil {
+		slog.Error("Failed to process expired draft policy", "draft_id", draftID, "error", err)
+	}
+}
+
+// SweepExpiredDrafts records the ends of validation windows whose key expiry was not heard, the
+// listener being down or the notification lost, and processes every event due, those recorded
+// now and those whose processing failed before
+func (s *PolicyExpirationService) SweepExpiredDrafts(ctx context.Context) error {
+	now := time.Now().Unix()
+	recorded, err := s.draftExpirationRepo.RecordMissed(ctx, now)
+	if err != nil {
+		return err
+	}
+	if recorded > 0 {
+		slog.Warn("Recorded draft expirations the listener missed", "count", recorded)
+	}
 
-	err = s.minioClient.DeleteFile(ctx, minio.Storage.PolicyDocuments, *policy.TemplateDocumentURL)
+	draftIDs, err := s.draftExpirationRepo.GetDueDraftIDs(ctx, now, draftExpirySweepBatch)
 	if err != nil {
-		slog.Error("Failed to delete Temp Policy Document", "error", err)
+		return err
 	}
+	failed := 0
+	for _, draftID := range draftIDs {
+		if err := s.processDraftExpiration(ctx, draftID); err != nil {
+			slog.Error("Failed to process expired draft policy", "draft_id", draftID, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d draft expirations failed", failed, len(draftIDs))
+	}
+	return nil
 }
 
-// processExpiredPolicy handles a single expired archive policy
-func (s *PolicyExpirationService) processExpiredDraftPolicy(ctx context.Context, expiredKey string) {
-	slog.Info("Processing expired archive policy", "expired_key", expiredKey)
+// processDraftExpiration processes the expiration event of a draft if it is due, holding it so
+// the listener and the sweeper of every instance process it once at a time. The event stays
+// pending until its handler succeeds; the handler is safe to run again after a partial attempt.
+func (s *PolicyExpirationService) processDraftExpiration(ctx context.Context, draftID uuid.UUID) error {
+	tx, err := s.draftExpirationRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	event, err := s.draftExpirationRepo.GetDueForUpdateTx(tx, ctx, draftID, now.Unix())
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		slog.Info("Draft expiration is not due or already processed", "draft_id", draftID)
+		return nil
+	}
 
 	s.updateStats(true, false) // Mark as processed
 
-	expiredKey = strings.Split(expiredKey, "--COMMIT_EVENT")[0]
-	// Extract policy information from expired key
-	policyInfo, err := s.extractPolicyInfo(expiredKey)
-	if err != nil {
-		slog.Error("Failed to extract policy info", "expired_key", expiredKey, "error", err)
+	event.Attempts++
+	outcome, handleErr := s.closeDraftValidationWindow(ctx, draftID)
+	if handleErr != nil {
+		lastError := handleErr.Error()
+		event.LastError = &lastError
+		event.NextAttemptAt = now.Add(draftExpiryRetryDelay(event.Attempts)).Unix()
 		s.updateStats(false, true)
-		return
+		if event.Attempts >= draftExpiryAlertAttempts {
+			slog.Error("CRITICAL: draft expiration keeps failing",
+				"draft_id", draftID,
+				"attempts", event.Attempts,
+				"error", handleErr)
+		}
+	} else {
+		processedAt := time.Now().Unix()
+		event.Status = models.DraftExpirationProcessed
+		event.Outcome = &outcome
+		event.ProcessedAt = &processedAt
+		event.LastError = nil
 	}
 
-	// Auto-commit to database
-	commitRequest := &models.CommitPoliciesRequest{
-		BasePolicyID:    policyInfo.PolicyID,
-		ProviderID:      policyInfo.ProviderID,
-		ArchiveStatus:   "true",
-		DeleteFromRedis: true,
-		BatchSize:       1,
+	if err := s.draftExpirationRepo.UpdateTx(tx, ctx, event); err != nil {
+		return err
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit draft expiration: %w", err)
+	}
+	return handleErr
+}
 
-	response, err := s.policyService.CommitPolicies(ctx, commitRequest)
+// closeDraftValidationWindow acts on a draft whose validation window ended: drafts created to be
+// archived are committed as they are, the others expire and their policy document is deleted. It
+// returns the status the draft was left in; a draft committed or expired already is not changed,
+// but the document of an expired draft is deleted again in case an earlier attempt failed to.
+func (s *PolicyExpirationService) closeDraftValidationWindow(ctx context.Context, draftID uuid.UUID) (models.DraftPolicyStatus, error) {
+	draftRepo := s.policyService.draftRepo
+	draft, err := draftRepo.GetByID(ctx, draftID)
 	if err != nil {
-		slog.Error("Auto-commit failed",
-			"expired_key", expiredKey,
-			"policy_id", policyInfo.PolicyID,
-			"provider_id", policyInfo.ProviderID,
-			"error", err)
-		s.updateStats(false, true)
-		return
+		return "", err
 	}
 
-	// Log success
-	slog.Info("Auto-commit completed successfully",
-		"expired_key", expiredKey,
-		"policy_id", policyInfo.PolicyID,
-		"provider_id", policyInfo.ProviderID,
-		"committed_count", response.TotalCommitted)
+	switch {
+	case draft.Status == models.DraftCommitted:
+		return models.DraftCommitted, nil
 
-	s.updateStats(false, false) // Mark as successful
-}
+	case draft.Status == models.DraftPending && draft.AutoCommit:
+		response, err := s.policyService.CommitPolicies(ctx, &models.CommitPoliciesRequest{
+			BasePolicyID: draftID.String(),
+			BatchSize:    1,
+		})
+		if err != nil {
+			return "", fmt.Errorf("auto-commit failed: %w", err)
+		}
+		if response.TotalCommitted == 0 {
+			// Committed meanwhile, or failed; either shows on the next attempt
+			if len(response.FailedPolicies) > 0 {
+				return "", fmt.Errorf("auto-commit failed: %s", response.FailedPolicies[0].ErrorMessage)
+			}
+			return "", fmt.Errorf("auto-commit failed: draft %s is no longer pending", draftID)
+		}
+		slog.Info("Auto-commit completed successfully",
+			"draft_id", draftID,
+			"provider_id", draft.InsuranceProviderID,
+			"committed_count", response.TotalCommitted)
+		return models.DraftCommitted, nil
+
+	case draft.Status == models.DraftPending:
+		expired, err := draftRepo.MarkExpired(ctx, draftID)
+		if err != nil {
+			return "", err
+		}
+		if !expired {
+			return "", fmt.Errorf("draft %s changed while expiring", draftID)
+		}
+		slog.Info("Draft policy expired", "draft_id", draftID, "provider_id", draft.InsuranceProviderID)
+	}
 
-// PolicyInfo holds extracted policy information from Redis key
-type PolicyInfo struct {
-	ProviderID string
-	PolicyID   string
+	templatePath := draft.Content.BasePolicy.TemplateDocumentURL
+	if s.minioClient != nil && templatePath != nil {
+		if err := s.minioClient.DeleteFile(ctx, minio.Storage.PolicyDocuments, *templatePath); err != nil {
+			return "", fmt.Errorf("failed to delete document of expired draft: %w", err)
+		}
+	}
+	return models.DraftExpired, nil
 }
 
-// extractPolicyInfo extracts policy information from expired Redis key
-func (s *PolicyExpirationService) extractPolicyInfo(expiredKey string) (*PolicyInfo, error) {
-	// Expected format: {provider}--{policyID}--BasePolicy--archive:true
-	parts := strings.Split(expiredKey, "--")
-	if len(parts) < 4 {
-		return nil, fmt.Errorf("invalid key format: %s", expiredKey)
+// draftExpiryRetryDelay is the wait before retrying an event after its attempts-th failure
+func draftExpiryRetryDelay(attempts int) time.Duration {
+	delay := draftExpiryRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= draftExpiryRetryMax {
+			return draftExpiryRetryMax
+		}
 	}
-
-	return &PolicyInfo{
-		ProviderID: parts[0],
-		PolicyID:   parts[1],
-	}, nil
+	return delay
 }
 
 // updateStats updates processing statistics
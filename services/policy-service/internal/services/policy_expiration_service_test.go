@@ -0,0 +1,42 @@
+package services
+
+import (
+	"policy-service/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDraftExpiryRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, draftExpiryRetryDelay(1))
+	assert.Equal(t, time.Minute, draftExpiryRetryDelay(2))
+	assert.Equal(t, 4*time.Minute, draftExpiryRetryDelay(4))
+	// Capped, however often it failed
+	assert.Equal(t, time.Hour, draftExpiryRetryDelay(8))
+	assert.Equal(t, time.Hour, draftExpiryRetryDelay(1000))
+}
+
+func TestIsDraftExpiryKey(t *testing.T) {
+	s := &PolicyExpirationService{}
+	id := uuid.New()
+
+	assert.True(t, s.isDraftExpiryKey(repository.DraftExpiryKey(id)))
+	assert.False(t, s.isDraftExpiryKey(id.String()+"--BasePolicy--ValidDate"))
+	assert.False(t, s.isDraftExpiryKey("provider-1--"+id.String()+"--BasePolicy--archive:true--COMMIT_EVENT"))
+}
+
+func TestIsHandledKey(t *testing.T) {
+	s := &PolicyExpirationService{}
+	id := uuid.New()
+
+	assert.True(t, s.isHandledKey(repository.DraftExpiryKey(id)))
+	assert.True(t, s.isHandledKey(id.String()+"--BasePolicy--ValidDate"))
+	assert.True(t, s.isHandledKey(id.String()+"--BasePolicy--EnrollmentClosed"))
+	assert.True(t, s.isHandledKey(id.String()+"--CancelRequest--NoticePeriod"))
+	// Expiring caches and locks are not claimed
+	assert.False(t, s.isHandledKey("base_policy_draft:"+id.String()))
+	assert.False(t, s.isHandledKey("cron:lock:analytics-refresh:1760000000"))
+	assert.False(t, s.isHandledKey(expiredKeyClaimPrefix+id.String()+"--BasePolicy--ValidDate"))
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"policy-service/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Columns of an import file. Policy tags go in columns named "tag:<name>".
+const (
+	importColumnFarmerID     = "farmer_id"
+	importColumnFarmID       = "farm_id"
+	importColumnPlantingDate = "planting_date"
+	importTagColumnPrefix    = "tag:"
+)
+
+var importDateLayouts = []string{"2006-01-02", "02/01/2006"}
+
+// importRowError is the reason a single row is rejected for, as opposed to failures that stop
+// the whole import
+type importRowError struct {
+	reason string
+}
+
+func (e *importRowError) Error() string {
+	return e.reason
+}
+
+// policyImportReader reads the rows of an import file one at a time
+type policyImportReader struct {
+	next    func() ([]string, error)
+	close   func() error
+	columns map[string]int
+	tags    map[string]int
+	row     int
+}
+
+// newPolicyImportReader opens a CSV or XLSX import file, chosen by the extension of fileName,
+// and reads its header row. Only the first sheet of a workbook is read.
+func newPolicyImportReader(fileName string, r io.Reader) (*policyImportReader, error) {
+	reader := &policyImportReader{close: func() error { return nil }}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		csvReader := csv.NewReader(r)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		reader.next = csvReader.Read
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %w", err)
+		}
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			workbook.Close()
+			return nil, fmt.Errorf("invalid xlsx file: workbook has no sheet")
+		}
+		rows, err := workbook.Rows(sheets[0])
+		if err != nil {
+			workbook.Close()
+			return nil, fmt.Errorf("invalid xlsx file: %w", err)
+		}
+		reader.next = func() ([]string, error) {
+			if !rows.Next() {
+				if err := rows.Error(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			return rows.Columns()
+		}
+		reader.close = func() error {
+			rows.Close()
+			return workbook.Close()
+		}
+	default:
+		return nil, fmt.Errorf("invalid file type: only .csv and .xlsx files can be imported")
+	}
+
+	if err := reader.readHeader(); err != nil {
+		reader.close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (r *policyImportReader) readHeader() error {
+	header, err := r.next()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid file: header row is missing")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid file: %w", err)
+	}
+	r.row = 1
+
+	r.columns = make(map[string]int)
+	r.tags = make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if len(name) > len(importTagColumnPrefix) && strings.EqualFold(name[:len(importTagColumnPrefix)], importTagColumnPrefix) {
+			// Tag names are kept as written, they must match the document tags of the base policy
+			r.tags[strings.TrimSpace(name[len(importTagColumnPrefix):])] = i
+			continue
+		}
+		r.columns[strings.ToLower(name)] = i
+	}
+
+	for _, required := range []string{importColumnFarmerID, importColumnFarmID, importColumnPlantingDate} {
+		if _, ok := r.columns[required]; !ok {
+			return fmt.Errorf("invalid file: missing column %s", required)
+		}
+	}
+	return nil
+}
+
+// Next returns the next data row, skipping blank ones. A row that cannot be parsed is returned
+// along with an *importRowError; io.EOF marks the end of the file.
+func (r *policyImportReader) Next() (*models.PolicyImportRow, error) {
+	for {
+		record, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		r.row++
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := &models.PolicyImportRow{
+			RowNumber:  r.row,
+			FarmerID:   cell(record, r.columns[importColumnFarmerID]),
+			FarmID:     cell(record, r.columns[importColumnFarmID]),
+			PolicyTags: make(map[string]string, len(r.tags)),
+		}
+		for tag, i := range r.tags {
+			if value := cell(record, i); value != "" {
+				row.PolicyTags[tag] = value
+			}
+		}
+
+		if row.FarmerID == "" {
+			return row, &importRowError{reason: "farmer_id is required"}
+		}
+		if row.FarmID == "" {
+			return row, &importRowError{reason: "farm_id is required"}
+		}
+		plantingDate, err := parseImportDate(cell(record, r.columns[importColumnPlantingDate]))
+		if err != nil {
+			return row, &importRowError{reason: err.Error()}
+		}
+		row.PlantingDate = plantingDate
+		return row, nil
+	}
+}
+
+func (r *policyImportReader) Close() error {
+	return r.close()
+}
+
+// parseImportDate accepts a date as YYYY-MM-DD, DD/MM/YYYY or a Unix timestamp
+func parseImportDate(value string) (int64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("planting_date is required")
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil && unix > 0 {
+		return unix, nil
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("planting_date %q is not a valid date, use YYYY-MM-DD", value)
+}
+
+func cell(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestPolicyImportReader_CSV(t *testing.T) {
+	file := "\ufeffFarmer_ID,farm_id,planting_date,Tag:full_name\n" +
+		"farmer-1,farm-1,2026-03-01,Nguyen Van A\n" +
+		",,,\n" +
+		"farmer-2,,01/03/2026,\n" +
+		"farmer-3,farm-3,next week,\n"
+
+	reader, err := newPolicyImportReader("farmers.csv", strings.NewReader(file))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	row, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 2, row.RowNumber)
+	assert.Equal(t, "farmer-1", row.FarmerID)
+	assert.Equal(t, "farm-1", row.FarmID)
+	assert.Equal(t, map[string]string{"full_name": "Nguyen Van A"}, row.PolicyTags)
+	assert.NotZero(t, row.PlantingDate)
+
+	// The blank row 3 is skipped, row numbers still follow the file
+	var rowErr *importRowError
+	row, err = reader.Next()
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 4, row.RowNumber)
+	assert.Equal(t, "farm_id is required", err.Error())
+
+	row, err = reader.Next()
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 5, row.RowNumber)
+	assert.Contains(t, err.Error(), "planting_date")
+
+	_, err = reader.Next()
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestPolicyImportReader_XLSX(t *testing.T) {
+	workbook := excelize.NewFile()
+	sheet := workbook.GetSheetName(0)
+	require.NoError(t, workbook.SetSheetRow(sheet, "A1", &[]any{"farmer_id", "farm_id", "planting_date"}))
+	require.NoError(t, workbook.SetSheetRow(sheet, "A2", &[]any{"farmer-1", "farm-1", "1772323200"}))
+	var buf bytes.Buffer
+	require.NoError(t, workbook.Write(&buf))
+
+	reader, err := newPolicyImportReader("farmers.xlsx", &buf)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	row, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "farmer-1", row.FarmerID)
+	assert.Equal(t, int64(1772323200), row.PlantingDate)
+
+	_, err = reader.Next()
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestPolicyImportReader_MissingColumn(t *testing.T) {
+	_, err := newPolicyImportReader("farmers.csv", strings.NewReader("farmer_id,planting_date\n"))
+	assert.EqualError(t, err, "invalid file: missing column farm_id")
+
+	_, err = newPolicyImportReader("farmers.xls", strings.NewReader(""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"agrisa_utils/logging"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"policy-service/internal/database/minio"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"policy-service/internal/worker"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PolicyImportJobType is the worker job that registers the rows of an import file
+	PolicyImportJobType = "policy-import"
+
+	maxPolicyImportFileSize = 10 << 20 // 10MB
+	// Progress is saved every few rows so partners can follow a long import
+	policyImportProgressInterval = 25
+)
+
+// PolicyImportService registers policies in bulk from a CSV/XLSX file uploaded by an insurance
+// partner. The file is kept in MinIO and processed by a background job, which registers every
+// row through the same path as a farmer registration and records the rows it rejects.
+type PolicyImportService struct {
+	importRepo              *repository.PolicyImportRepository
+	registeredPolicyService *RegisteredPolicyService
+	basePolicyService       *BasePolicyService
+	farmService             *FarmService
+	minioClient             *minio.MinioClient
+	workerManager           *worker.WorkerManagerV2
+}
+
+func NewPolicyImportService(
+	importRepo *repository.PolicyImportRepository,
+	registeredPolicyService *RegisteredPolicyService,
+	basePolicyService *BasePolicyService,
+	farmService *FarmService,
+	minioClient *minio.MinioClient,
+	workerManager *worker.WorkerManagerV2,
+) *PolicyImportService {
+	return &PolicyImportService{
+		importRepo:              importRepo,
+		registeredPolicyService: registeredPolicyService,
+		basePolicyService:       basePolicyService,
+		farmService:             farmService,
+		minioClient:             minioClient,
+		workerManager:           workerManager,
+	}
+}
+
+// CreateImport stores an import file and queues it for processing. The header of the file is
+// checked right away so a malformed file is refused before any job is created.
+func (s *PolicyImportService) CreateImport(ctx context.Context, req models.CreatePolicyImportRequest, providerID, userID string, partnerUserIDs []string) (*models.PolicyImportJob, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	data, err := minio.Base64ToBytes(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file data: %w", err)
+	}
+	if len(data) > maxPolicyImportFileSize {
+		return nil, fmt.Errorf("invalid file: larger than %dMB", maxPolicyImportFileSize>>20)
+	}
+
+	reader, err := newPolicyImportReader(req.FileName, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	reader.Close()
+
+	basePolicy, err := s.basePolicyService.GetByID(req.BasePolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("base policy not found: %w", err)
+	}
+	if basePolicy.InsuranceProviderID != providerID {
+		return nil, fmt.Errorf("unauthorized: base policy belongs to another provider")
+	}
+	if basePolicy.Status != models.BasePolicyActive {
+		return nil, fmt.Errorf("invalid base policy: status=%s", basePolicy.Status)
+	}
+
+	job := &models.PolicyImportJob{
+		ID:                  uuid.New(),
+		InsuranceProviderID: providerID,
+		BasePolicyID:        req.BasePolicyID,
+		FileName:            path.Base(req.FileName),
+		CreatedBy:           userID,
+	}
+	job.FilePath = fmt.Sprintf("imports/%s/%s", job.ID, minio.GetSafeFileName(job.FileName))
+
+	if err := s.minioClient.UploadBytes(ctx, minio.Storage.PolicyAttachments, job.FilePath, data, minio.GetContentType(job.FileName)); err != nil {
+		slog.Error("error uploading policy import file", "import_id", job.ID, "error", err)
+		return nil, fmt.Errorf("error uploading import file: %w", err)
+	}
+
+	if err := s.importRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if worker.ImportWorkerPoolUUID == nil {
+		return nil, fmt.Errorf("policy import worker pool is not running")
+	}
+	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(*worker.ImportWorkerPoolUUID)
+	if !ok {
+		return nil, fmt.Errorf("policy import scheduler doesn't exist")
+	}
+	scheduler.AddJob(worker.JobPayload{
+		JobID: uuid.NewString(),
+		Type:  PolicyImportJobType,
+		Params: map[string]any{
+			"import_id":        job.ID.String(),
+			"partner_user_ids": partnerUserIDs,
+			"request_id":       logging.RequestID(ctx),
+		},
+		MaxRetries:  3,
+		OneTime:     true,
+		RunNow:      true,
+		RequestID:   logging.RequestID(ctx),
+		SubmittedBy: userID,
+		Result:      &worker.JobResultRef{Resource: "policy_import", ID: job.ID.String()},
+	})
+
+	slog.Info("policy import queued",
+		"import_id", job.ID,
+		"provider_id", providerID,
+		"base_policy_id", req.BasePolicyID,
+		"file_name", job.FileName)
+	return job, nil
+}
+
+// GetImport retrieves an import of the provider
+func (s *PolicyImportService) GetImport(ctx context.Context, importID uuid.UUID, providerID string) (*models.PolicyImportJob, error) {
+	job, err := s.importRepo.GetByID(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+	if job.InsuranceProviderID != providerID {
+		return nil, fmt.Errorf("unauthorized: import belongs to another provider")
+	}
+	return job, nil
+}
+
+func (s *PolicyImportService) GetImportsByProvider(ctx context.Context, providerID string) ([]models.PolicyImportJob, error) {
+	return s.importRepo.GetByProviderID(ctx, providerID)
+}
+
+// GetErrorReport renders the rejected rows of an import as CSV, one line per row with the reason
+// it was rejected
+func (s *PolicyImportService) GetErrorReport(ctx context.Context, importID uuid.UUID, providerID string) ([]byte, error) {
+	if _, err := s.GetImport(ctx, importID, providerID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.importRepo.GetRejectedRows(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"row_number", importColumnFarmerID, importColumnFarmID, "reason"})
+	for _, row := range rows {
+		writer.Write([]string{
+			strconv.Itoa(row.RowNumber),
+			stringOrEmpty(row.FarmerID),
+			stringOrEmpty(row.FarmID),
+			row.Reason,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("error writing error report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ============================================================================
+// BACKGROUND JOB
+// ============================================================================
+
+// PolicyImportJob processes a queued import. It is registered on the import worker pool under
+// PolicyImportJobType.
+func (s *PolicyImportService) PolicyImportJob(params map[string]any) error {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("PolicyImportJob: recovered from panic", "panic", r)
+		}
+	}()
+
+	importIDStr, ok := params["import_id"].(string)
+	if !ok || importIDStr == "" {
+		return fmt.Errorf("invalid or missing import_id parameter")
+	}
+	importID, err := uuid.Parse(importIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse import_id: %w", err)
+	}
+	var partnerUserIDs []string
+	if ids, ok := params["partner_user_ids"].([]any); ok {
+		for _, id := range ids {
+			if userID, ok := id.(string); ok {
+				partnerUserIDs = append(partnerUserIDs, userID)
+			}
+		}
+	}
+	requestID, _ := params["request_id"].(string)
+	ctx := logging.WithRequestID(context.Background(), requestID)
+
+	// The scheduler may hand the same job out more than once, only the first run gets the import
+	claimed, err := s.importRepo.Claim(ctx, importID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		slog.Info("policy import already claimed, skipping", "import_id", importID)
+		return nil
+	}
+
+	job, err := s.importRepo.GetByID(ctx, importID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("policy import started", "import_id", job.ID, "base_policy_id", job.BasePolicyID)
+	if err := s.processImport(ctx, job, partnerUserIDs); err != nil {
+		slog.Error("policy import failed", "import_id", job.ID, "error", err)
+		message := err.Error()
+		job.Status = models.PolicyImportFailed
+		job.ErrorMessage = &message
+	} else {
+		job.Status = models.PolicyImportCompleted
+	}
+
+	if err := s.importRepo.Finish(ctx, job); err != nil {
+		return err
+	}
+	slog.Info("policy import finished",
+		"import_id", job.ID,
+		"status", job.Status,
+		"total_rows", job.TotalRows,
+		"imported_rows", job.ImportedRows,
+		"rejected_rows", job.RejectedRows)
+	return nil
+}
+
+// processImport registers the rows of the import file one by one. Rows that cannot be registered
+// are recorded and skipped; an error is only returned when the import as a whole cannot go on.
+func (s *PolicyImportService) processImport(ctx context.Context, job *models.PolicyImportJob, partnerUserIDs []string) error {
+	completeBasePolicy, err := s.basePolicyService.GetCompletePolicyDetail(ctx, models.PolicyDetailFilterRequest{ID: &job.BasePolicyID})
+	if err != nil {
+		return fmt.Errorf("error getting base policy: %w", err)
+	}
+	basePolicy := completeBasePolicy.BasePolicy
+	if basePolicy.Status != models.BasePolicyActive {
+		return fmt.Errorf("base policy is not active: status=%s", basePolicy.Status)
+	}
+	if basePolicy.EnrollmentEndDay != nil && time.Now().Unix() > int64(*basePolicy.EnrollmentEndDay) {
+		return fmt.Errorf("base policy enrollment is over")
+	}
+	requiredTags := basePolicy.DocumentTags.KeySlice()
+
+	obj, err := s.minioClient.GetFile(ctx, minio.Storage.PolicyAttachments, job.FilePath)
+	if err != nil {
+		return fmt.Errorf("error getting import file: %w", err)
+	}
+	defer obj.Close()
+
+	reader, err := newPolicyImportReader(job.FileName, obj)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	seenFarms := make(map[string]int)
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rowErr *importRowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return fmt.Errorf("error reading import file: %w", err)
+		}
+
+		job.TotalRows++
+		if err == nil {
+			if firstRow, ok := seenFarms[row.FarmID]; ok {
+				err = fmt.Errorf("farm %s is already listed on row %d", row.FarmID, firstRow)
+			} else {
+				seenFarms[row.FarmID] = row.RowNumber
+				err = s.importRow(ctx, job, completeBasePolicy, requiredTags, row, partnerUserIDs)
+			}
+		}
+
+		if err != nil {
+			job.RejectedRows++
+			rejected := &models.PolicyImportRejectedRow{
+				ImportJobID: job.ID,
+				RowNumber:   row.RowNumber,
+				FarmerID:    nilIfEmpty(row.FarmerID),
+				FarmID:      nilIfEmpty(row.FarmID),
+				Reason:      err.Error(),
+			}
+			if err := s.importRepo.CreateRejectedRow(ctx, rejected); err != nil {
+				return err
+			}
+		} else {
+			job.ImportedRows++
+		}
+
+		if job.TotalRows%policyImportProgressInterval == 0 {
+			if err := s.importRepo.UpdateProgress(ctx, job); err != nil {
+				slog.Error("error saving policy import progress", "import_id", job.ID, "error", err)
+			}
+		}
+	}
+}
+
+// importRow checks a row against the base policy and registers it. The premium and data cost
+// are priced here the way the farmer app prices them, RegisterAPolicy checks them again.
+func (s *PolicyImportService) importRow(
+	ctx context.Context,
+	job *models.PolicyImportJob,
+	completeBasePolicy *models.CompletePolicyDetailResponse,
+	requiredTags []string,
+	row *models.PolicyImportRow,
+	partnerUserIDs []string,
+) error {
+	if _, err := uuid.Parse(row.FarmID); err != nil {
+		return fmt.Errorf("farm_id %q is not a valid id", row.FarmID)
+	}
+	farm, err := s.farmService.GetByFarmID(ctx, row.FarmID)
+	if err != nil {
+		slog.Error("error getting farm for policy import", "import_id", job.ID, "farm_id", row.FarmID, "error", err)
+		return fmt.Errorf("farm %s not found", row.FarmID)
+	}
+	if farm.OwnerID != row.FarmerID {
+		return fmt.Errorf("farm %s does not belong to farmer %s", row.FarmID, row.FarmerID)
+	}
+	if farm.CropType != completeBasePolicy.BasePolicy.CropType {
+		return fmt.Errorf("farm crop type %s does not match base policy crop type %s", farm.CropType, completeBasePolicy.BasePolicy.CropType)
+	}
+	if err := s.registeredPolicyService.validatePolicyTags(row.PolicyTags, requiredTags); err != nil {
+		return err
+	}
+
+	basePolicy := completeBasePolicy.BasePolicy
+	request := models.RegisterAPolicyRequest{
+		RegisteredPolicy: models.RegisteredPolicy{
+			BasePolicyID:        basePolicy.ID,
+			InsuranceProviderID: job.InsuranceProviderID,
+			FarmerID:            row.FarmerID,
+			PlantingDate:        row.PlantingDate,
+			TotalFarmerPremium:  s.registeredPolicyService.calculateFarmerPremium(farm.AreaSqm, basePolicy.PremiumBaseRate, basePolicy.FixPremiumAmount),
+			TotalDataCost:       completeBasePolicy.Metadata.TotalDataCost,
+		},
+		FarmID:     row.FarmID,
+		IsNewFarm:  false,
+		PolicyTags: row.PolicyTags,
+	}
+
+	response, err := s.registeredPolicyService.RegisterAPolicy(request, ctx, partnerUserIDs)
+	if err != nil {
+		return err
+	}
+	slog.Info("policy imported",
+		"import_id", job.ID,
+		"row_number", row.RowNumber,
+		"registered_policy_id", response.RegisterPolicyID)
+	return nil
+}
+
+func nilIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
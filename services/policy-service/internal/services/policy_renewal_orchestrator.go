@@ -20,6 +20,8 @@ type PolicyRenewalOrchestrator struct {
 	validityCalculator   *BasePolicyValidityCalculator
 	workerManager        *worker.WorkerManagerV2
 	notievent            *event.NotificationHelper
+	outboxRepo           *repository.OutboxRepository
+	basePolicyService    *BasePolicyService
 }
 
 // NewPolicyRenewalOrchestrator creates a new renewal orchestrator instance
@@ -29,6 +31,8 @@ func NewPolicyRenewalOrchestrator(
 	validityCalculator *BasePolicyValidityCalculator,
 	workerManager *worker.WorkerManagerV2,
 	notievent *event.NotificationHelper,
+	outboxRepo *repository.OutboxRepository,
+	basePolicyService *BasePolicyService,
 ) *PolicyRenewalOrchestrator {
 	return &PolicyRenewalOrchestrator{
 		basePolicyRepo:       basePolicyRepo,
@@ -36,6 +40,8 @@ func NewPolicyRenewalOrchestrator(
 		validityCalculator:   validityCalculator,
 		workerManager:        workerManager,
 		notievent:            notievent,
+		outboxRepo:           outboxRepo,
+		basePolicyService:    basePolicyService,
 	}
 }
 
@@ -119,6 +125,16 @@ func (o *PolicyRenewalOrchestrator) PrepareRenewal(
 		"base_policy_id", basePolicy.ID,
 		"new_window", fmt.Sprintf("Day %d-%d", nextWindow.FromDay, nextWindow.ToDay))
 
+	// Renewed policies are sold again under the terms of the new window
+	var renewedVersion *int
+	if version, err := o.basePolicyService.RecordPolicyVersion(ctx, basePolicy.ID); err != nil {
+		slog.Error("Failed to record base policy version, renewed policies keep their version",
+			"base_policy_id", basePolicy.ID,
+			"error", err)
+	} else {
+		renewedVersion = &version
+	}
+
 	// Step 2: Calculate renewal premium (if discount applies)
 	if len(registeredPolicies) > 0 {
 		var discountRate float64
@@ -154,6 +170,9 @@ func (o *PolicyRenewalOrchestrator) PrepareRenewal(
 				policy.PremiumPaidAt = nil
 				policy.PremiumPaidByFarmer = false
 				policy.Status = models.PolicyPendingPayment
+				if renewedVersion != nil {
+					policy.BasePolicyVersion = renewedVersion
+				}
 
 				slog.Info("Calculated renewal premium",
 					"base_policy_id", basePolicy.ID,
@@ -244,7 +263,7 @@ func (o *PolicyRenewalOrchestrator) PrepareExpired(
 			slog.Info("Calculated renewal premium",
 				"base_policy_id", basePolicy.ID,
 			)
-			if err := o.registeredPolicyRepo.Update(&policy); err != nil {
+			if err := o.expirePolicy(ctx, &policy); err != nil {
 				errMsg := fmt.Errorf("failed to update policy %s premium: %w", policy.ID, err)
 				result.Errors = append(result.Errors, errMsg)
 				slog.Error("Failed to update policy premium",
@@ -279,6 +298,28 @@ func (o *PolicyRenewalOrchestrator) PrepareExpired(
 	return result, nil
 }
 
+// expirePolicy saves an expired policy together with the notification to its farmer
+func (o *PolicyRenewalOrchestrator) expirePolicy(ctx context.Context, policy *models.RegisteredPolicy) error {
+	notification, err := event.PolicyExpiredNotification(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	tx, err := o.registeredPolicyRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := o.registeredPolicyRepo.UpdateTx(tx, policy); err != nil {
+		return err
+	}
+	if err := o.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // calculateRenewalPremium calculates the renewal premium with discount applied
 func (o *PolicyRenewalOrchestrator) calculateRenewalPremium(
 	originalPremium float64,
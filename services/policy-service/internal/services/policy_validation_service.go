@@ -1,15 +1,16 @@
 package services
 
 import (
-	utils "agrisa_utils"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"policy-service/internal/ai/gemini"
 	"policy-service/internal/database/minio"
 	"policy-service/internal/models"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,15 +36,18 @@ func (s *BasePolicyService) ValidatePolicy(ctx context.Context, request *models.
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Verify policy exists
+	// Verify policy exists, as a pending draft or committed
 	basePolicy := &models.BasePolicy{}
+	isDraft := false
 
-	policyPattern := fmt.Sprintf("*--%s--BasePolicy--*", request.BasePolicyID)
-	slog.Info("DEBUG pattern", "pattern", policyPattern)
-	policyKeys, err := s.basePolicyRepo.FindKeysByPattern(ctx, policyPattern, "--COMMIT_EVENT")
-	slog.Info("DEBUG key", "keys", policyKeys)
-	if err != nil || len(policyKeys) == 0 {
-		slog.Error("Failed to find policy keys", "policy id", request.BasePolicyID, "error", err)
+	draft, err := s.draftRepo.GetByID(ctx, request.BasePolicyID)
+	if err == nil && draft.Status == models.DraftPending {
+		basePolicy = draft.Content.BasePolicy
+		isDraft = true
+	} else {
+		if err != nil && !strings.HasPrefix(err.Error(), "not found") {
+			slog.Warn("Failed to look up base policy draft", "base_policy_id", request.BasePolicyID, "error", err)
+		}
 		basePolicy, err = s.basePolicyRepo.GetBasePolicyByID(request.BasePolicyID)
 		if err != nil {
 			slog.Error("Failed to get base policy",
@@ -51,21 +55,6 @@ func (s *BasePolicyService) ValidatePolicy(ctx context.Context, request *models.
 				"error", err)
 			return nil, fmt.Errorf("failed to get base policy: %w", err)
 		}
-	} else {
-		if len(policyKeys) > 1 {
-			return nil, fmt.Errorf("logic error: many matching policies exist in cache: %v", policyKeys)
-		}
-		basePolicyByte, err := s.basePolicyRepo.GetTempBasePolicyModels(ctx, policyKeys[0])
-		slog.Info("DEBUG data", "data", basePolicyByte)
-		if err != nil {
-			slog.Info("Failed to get base policy data", "key", policyKeys[0], "error", err)
-			return nil, fmt.Errorf("failed to get base policy: %w", err)
-		}
-
-		if err := utils.DeserializeModel(basePolicyByte, &basePolicy); err != nil {
-			slog.Info("Failed to deserialize base policy", "key", policyKeys[0], "error", err)
-			return nil, fmt.Errorf("failed to deserialize base policy: %w", err)
-		}
 	}
 
 	slog.Info("Retrieved base policy for validation",
@@ -108,12 +97,11 @@ func (s *BasePolicyService) ValidatePolicy(ctx context.Context, request *models.
 		"base_policy_id", request.BasePolicyID,
 		"validation_status", request.ValidationStatus)
 
-	// Commit temporary draft policy data if present
-	if len(policyKeys) > 0 && validation.ValidationStatus == models.ValidationPassed {
-		slog.Info("policies data are in temp cache, begin to commit before further operations")
+	// Commit the draft policy if it passed
+	if isDraft && validation.ValidationStatus == models.ValidationPassed {
+		slog.Info("policy is a draft, begin to commit before further operations")
 		result, err := s.CommitPolicies(ctx, &models.CommitPoliciesRequest{
-			BasePolicyID:    basePolicy.ID.String(),
-			DeleteFromRedis: true,
+			BasePolicyID: basePolicy.ID.String(),
 		})
 		if err != nil {
 			slog.Error("commit temp policy data failed", "error", err)
@@ -143,24 +131,29 @@ func (s *BasePolicyService) ValidatePolicy(ctx context.Context, request *models.
 				"error", err)
 			return nil, fmt.Errorf("failed to update policy validation status: %w", err)
 		}
-	} else {
-		// Save validation to Redis for non-passed or non-cached policies
-		slog.Info("Saving validation to Redis for non-passed status",
+	} else if isDraft {
+		// Keep the validation with the draft, it is committed with it
+		slog.Info("Saving validation with draft policy for non-passed status",
 			"base_policy_id", request.BasePolicyID,
 			"validation_status", request.ValidationStatus,
 			"validation_id", validation.ID)
 
-		if err := s.basePolicyRepo.SaveValidationToRedis(ctx, validation); err != nil {
-			slog.Error("Failed to save validation to Redis",
+		if err := s.draftRepo.AddValidation(ctx, request.BasePolicyID, validation); err != nil {
+			slog.Error("Failed to save validation with draft policy",
 				"base_policy_id", request.BasePolicyID,
 				"validation_id", validation.ID,
 				"error", err)
-			return nil, fmt.Errorf("failed to save validation to Redis: %w", err)
+			return nil, fmt.Errorf("failed to save draft validation: %w", err)
+		}
+	} else {
+		// Committed policies keep the validation without changing status
+		if err := s.basePolicyRepo.CreateBasePolicyDocumentValidation(validation); err != nil {
+			slog.Error("Failed to create validation record",
+				"base_policy_id", request.BasePolicyID,
+				"validation_id", validation.ID,
+				"error", err)
+			return nil, fmt.Errorf("failed to create validation record: %w", err)
 		}
-
-		slog.Info("Successfully saved validation to Redis",
-			"base_policy_id", request.BasePolicyID,
-			"validation_id", validation.ID)
 	}
 
 	// Commit transaction
@@ -270,27 +263,19 @@ func (s *BasePolicyService) AIPolicyValidationJob(params map[string]any) error {
 	}
 	finalPrompt := fmt.Sprintf(gemini.ValidationPromptTemplate, string(inputJSONBytes))
 
-	aiRequestData := map[string]any{"pdf": templateData}
-
 	// Call AI validation service with automatic failover
 	slog.Info("Sending validation request to AI service with multi-client failover",
 		"base_policy_id", basePolicyIDStr)
 
-	resp, err := gemini.SendAIWithPDFAndRetry(context.Background(), finalPrompt, aiRequestData, s.geminiSelector)
-	if err != nil {
-		return fmt.Errorf("AI validation request failed: %w", err)
-	}
-
-	// Parse AI response into validation request structure
-	var aiResponse models.BasePolicyDocumentValidation
-	respBytes, err := json.Marshal(resp)
+	aiCtx, usageMeter := gemini.WithUsageMeter(context.Background())
+	aiResponse, err := s.requestAIValidation(aiCtx, basePolicyID, finalPrompt, templateData)
+	s.aiUsageService.RecordJob(context.Background(), models.AIUsageSource{
+		JobType:             "document-validation",
+		InsuranceProviderID: completePolicy.BasePolicy.InsuranceProviderID,
+		BasePolicyID:        &basePolicyID,
+	}, usageMeter, err == nil)
 	if err != nil {
-		return fmt.Errorf("failed to marshal AI response: %w", err)
-	}
-
-	err = json.Unmarshal(respBytes, &aiResponse)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal AI response: %w", err)
+		return err
 	}
 
 	slog.Info("AI validation response parsed",
@@ -331,3 +316,78 @@ func (s *BasePolicyService) AIPolicyValidationJob(params map[string]any) error {
 
 	return nil
 }
+
+// Attempts at a validation answer that matches the output schema: the first answer plus one repair
+const aiValidationAttempts = 2
+
+// rejectedAIResponse is what is kept of an answer that failed the output schema
+type rejectedAIResponse struct {
+	Attempt  int    `json:"attempt"`
+	Problem  string `json:"problem"`
+	Response string `json:"response"`
+}
+
+// requestAIValidation asks the AI to compare the policy with its document. An answer that cannot
+// be parsed or breaks the output schema is sent back once with the problems found; when the
+// repaired answer is rejected too, both answers are stored for review.
+func (s *BasePolicyService) requestAIValidation(ctx context.Context, basePolicyID uuid.UUID, prompt string, pdf []byte) (*models.BasePolicyDocumentValidation, error) {
+	aiRequestData := map[string]any{"pdf": pdf}
+	attemptPrompt := prompt
+	var rejected []rejectedAIResponse
+
+	for attempt := 1; attempt <= aiValidationAttempts; attempt++ {
+		var raw string
+		var problem error
+
+		resp, err := gemini.SendAIWithPDFAndRetry(ctx, attemptPrompt, aiRequestData, s.geminiSelector)
+		if err != nil {
+			var aiErr *gemini.Error
+			if !errors.As(err, &aiErr) || aiErr.Kind != gemini.ErrorInvalidResponse {
+				return nil, fmt.Errorf("AI validation request failed: %w", err)
+			}
+			raw, problem = aiErr.Response, aiErr.Err
+		} else {
+			validation, err := parseAIValidationResponse(resp)
+			if err == nil {
+				return validation, nil
+			}
+			rawBytes, _ := json.MarshalIndent(resp, "", "  ")
+			raw, problem = string(rawBytes), err
+		}
+
+		slog.Warn("AI validation response rejected",
+			"base_policy_id", basePolicyID,
+			"attempt", attempt,
+			"problem", problem)
+		rejected = append(rejected, rejectedAIResponse{Attempt: attempt, Problem: problem.Error(), Response: raw})
+		attemptPrompt = prompt + fmt.Sprintf(gemini.ValidationRepairPromptTemplate, problem, raw)
+	}
+
+	s.storeRejectedAIResponses(ctx, basePolicyID, rejected)
+	return nil, &gemini.Error{
+		Kind: gemini.ErrorInvalidResponse,
+		Err:  fmt.Errorf("AI validation response still invalid after repair: %s", rejected[len(rejected)-1].Problem),
+	}
+}
+
+// storeRejectedAIResponses keeps the rejected answers in the validation reports bucket, under
+// ai-responses/<base policy id>/. The job fails either way, so a failed upload is only logged.
+func (s *BasePolicyService) storeRejectedAIResponses(ctx context.Context, basePolicyID uuid.UUID, rejected []rejectedAIResponse) {
+	content, err := json.MarshalIndent(rejected, "", "  ")
+	if err != nil {
+		slog.Error("Failed to encode rejected AI responses", "base_policy_id", basePolicyID, "error", err)
+		return
+	}
+
+	objectName := fmt.Sprintf("ai-responses/%s/%s.json", basePolicyID, time.Now().UTC().Format("20060102T150405Z"))
+	err = s.minioClient.UploadFile(ctx, minio.Storage.ValidationReports, objectName,
+		strings.NewReader(string(content)), int64(len(content)), "application/json")
+	if err != nil {
+		slog.Error("Failed to store rejected AI responses", "base_policy_id", basePolicyID, "error", err)
+		return
+	}
+	slog.Warn("Rejected AI responses stored for review",
+		"base_policy_id", basePolicyID,
+		"bucket", minio.Storage.ValidationReports,
+		"object_name", objectName)
+}
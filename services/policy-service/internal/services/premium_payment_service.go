@@ -0,0 +1,189 @@
+package services
+
+import (
+	"agrisa_utils/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"policy-service/internal/config"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The payment gateway rejects longer transfer descriptions
+const maxPaymentDescriptionLength = 25
+
+// PremiumPaymentService opens checkouts for farmer premiums with payment-service. The amount is
+// priced here from the policy rather than trusted from the client. Payment-service verifies the
+// signed gateway webhook and publishes the payment event that activates the policy.
+type PremiumPaymentService struct {
+	registeredPolicyRepo *repository.RegisteredPolicyRepository
+	premiumScheduleRepo  *repository.PremiumScheduleRepository
+	paymentServiceURL    string
+	client               *http.Client
+}
+
+func NewPremiumPaymentService(
+	registeredPolicyRepo *repository.RegisteredPolicyRepository,
+	premiumScheduleRepo *repository.PremiumScheduleRepository,
+	cfg *config.PolicyServiceConfig,
+) *PremiumPaymentService {
+	return &PremiumPaymentService{
+		registeredPolicyRepo: registeredPolicyRepo,
+		premiumScheduleRepo:  premiumScheduleRepo,
+		paymentServiceURL:    cfg.PaymentServiceURL,
+		client:               logging.NewHTTPClient(15 * time.Second),
+	}
+}
+
+// CreatePaymentIntent opens a checkout for what the farmer owes on a policy next: the whole
+// premium, or the next installment when the premium is paid in installments
+func (s *PremiumPaymentService) CreatePaymentIntent(ctx context.Context, policyID uuid.UUID, farmerID string, req models.CreatePremiumPaymentRequest) (*models.PremiumPaymentIntent, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if policy.FarmerID != farmerID {
+		return nil, fmt.Errorf("unauthorized: policy does not belong to this farmer")
+	}
+	if policy.UnderwritingStatus != models.UnderwritingApproved {
+		return nil, fmt.Errorf("invalid policy status: underwriting is %s", policy.UnderwritingStatus)
+	}
+	if policy.PremiumPaidByFarmer {
+		return nil, fmt.Errorf("invalid request: premium is already paid")
+	}
+
+	intent, err := s.priceIntent(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.createPaymentLink(ctx, policy, intent, req); err != nil {
+		return nil, err
+	}
+
+	slog.Info("premium payment intent created",
+		"policy_id", policyID,
+		"payment_type", intent.PaymentType,
+		"amount", intent.Amount,
+		"order_code", intent.OrderCode)
+	return intent, nil
+}
+
+func (s *PremiumPaymentService) priceIntent(ctx context.Context, policy *models.RegisteredPolicy) (*models.PremiumPaymentIntent, error) {
+	installments, err := s.premiumScheduleRepo.GetByPolicyID(ctx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(installments) == 0 {
+		if policy.Status != models.PolicyPendingPayment {
+			return nil, fmt.Errorf("invalid policy status: %s", policy.Status)
+		}
+		return &models.PremiumPaymentIntent{
+			RegisteredPolicyID: policy.ID,
+			PaymentType:        models.PaymentTypePolicyRegistration,
+			Amount:             policy.TotalFarmerPremium,
+		}, nil
+	}
+
+	if policy.Status != models.PolicyPendingPayment && policy.Status != models.PolicyActive {
+		return nil, fmt.Errorf("invalid policy status: %s", policy.Status)
+	}
+	for _, installment := range installments {
+		if installment.Status != models.PaymentPending && installment.Status != models.PaymentOverdue {
+			continue
+		}
+		number := installment.InstallmentNumber
+		return &models.PremiumPaymentIntent{
+			RegisteredPolicyID: policy.ID,
+			PaymentType:        models.PaymentTypePremiumInstallment,
+			Amount:             installment.Outstanding(),
+			InstallmentNumber:  &number,
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid request: no installment left to pay")
+}
+
+// createPaymentLink asks payment-service for a gateway checkout on behalf of the farmer and fills
+// in the checkout details of intent
+func (s *PremiumPaymentService) createPaymentLink(ctx context.Context, policy *models.RegisteredPolicy, intent *models.PremiumPaymentIntent, req models.CreatePremiumPaymentRequest) error {
+	description := "BH " + policy.PolicyNumber
+	if len(description) > maxPaymentDescriptionLength {
+		description = description[:maxPaymentDescriptionLength]
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"amount":      intent.Amount,
+		"description": description,
+		"return_url":  req.ReturnURL,
+		"cancel_url":  req.CancelURL,
+		"type":        intent.PaymentType,
+		"items": []map[string]any{{
+			"item_id":  policy.ID.String(),
+			"name":     policy.PolicyNumber,
+			"price":    intent.Amount,
+			"quantity": 1,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment link request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		s.paymentServiceURL+"/payment/protected/link", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build payment link request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", policy.FarmerID)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error requesting payment link: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading payment link response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		slog.Error("unexpected payment link response", "status_code", resp.StatusCode, "body", string(body))
+		return fmt.Errorf("unexpected status code from payment-service: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			CheckoutURL string          `json:"checkout_url"`
+			OrderCode   json.RawMessage `json:"order_code"`
+			QRCode      string          `json:"qr_code"`
+			ExpiredAt   *time.Time      `json:"expired_at"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("error parsing payment link response: %w", err)
+	}
+	if result.Data.CheckoutURL == "" {
+		return fmt.Errorf("payment-service returned no checkout url")
+	}
+
+	intent.CheckoutURL = result.Data.CheckoutURL
+	// The gateway order code comes back as a number or a string
+	intent.OrderCode = strings.Trim(string(result.Data.OrderCode), `"`)
+	intent.QRCode = result.Data.QRCode
+	intent.ExpiredAt = result.Data.ExpiredAt
+	return nil
+}
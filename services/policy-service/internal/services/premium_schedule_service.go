@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/event"
+	"policy-service/internal/models"
+	"policy-service/internal/repository"
+	"policy-service/internal/worker"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How long an installment may stay overdue before its policy lapses
+const premiumLapseGracePeriod = 7 * 24 * time.Hour
+
+// PremiumScheduleService manages premiums paid in installments: it splits the premium of a policy
+// over its payment window and lapses policies whose installments stay unpaid. Payments against a
+// schedule arrive through the payment consumer.
+type PremiumScheduleService struct {
+	scheduleRepo         *repository.PremiumScheduleRepository
+	registeredPolicyRepo *repository.RegisteredPolicyRepository
+	basePolicyRepo       *repository.BasePolicyRepository
+	workerManager        *worker.WorkerManagerV2
+	outboxRepo           *repository.OutboxRepository
+}
+
+func NewPremiumScheduleService(
+	scheduleRepo *repository.PremiumScheduleRepository,
+	registeredPolicyRepo *repository.RegisteredPolicyRepository,
+	basePolicyRepo *repository.BasePolicyRepository,
+	workerManager *worker.WorkerManagerV2,
+	outboxRepo *repository.OutboxRepository,
+) *PremiumScheduleService {
+	return &PremiumScheduleService{
+		scheduleRepo:         scheduleRepo,
+		registeredPolicyRepo: registeredPolicyRepo,
+		basePolicyRepo:       basePolicyRepo,
+		workerManager:        workerManager,
+		outboxRepo:           outboxRepo,
+	}
+}
+
+// CreateSchedule splits the premium of a policy awaiting payment into installments due at even
+// intervals over the payment window of its base policy (farmer authorization)
+func (s *PremiumScheduleService) CreateSchedule(ctx context.Context, policyID uuid.UUID, farmerID string, req models.CreatePremiumScheduleRequest) (*models.PremiumSchedule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if policy.FarmerID != farmerID {
+		return nil, fmt.Errorf("unauthorized: policy does not belong to this farmer")
+	}
+	if policy.Status != models.PolicyPendingPayment || policy.UnderwritingStatus != models.UnderwritingApproved {
+		return nil, fmt.Errorf("invalid policy status: only approved policies pending payment can be paid in installments")
+	}
+
+	basePolicy, err := s.basePolicyRepo.GetBasePolicyByID(policy.BasePolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("base policy not found: %w", err)
+	}
+	var windowHours int64
+	if basePolicy.MaxPremiumPaymentProlong != nil {
+		windowHours = *basePolicy.MaxPremiumPaymentProlong
+	}
+	if windowHours <= 0 {
+		return nil, fmt.Errorf("invalid request: base policy has no premium payment window")
+	}
+
+	exists, err := s.registeredPolicyRepo.HasPremiumSchedule(policyID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("invalid request: policy already has a premium schedule")
+	}
+
+	installments := models.SplitPremium(policyID, policy.TotalFarmerPremium, req.Installments,
+		time.Now(), time.Duration(windowHours)*time.Hour)
+
+	tx, err := s.scheduleRepo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.scheduleRepo.CreateInstallmentsTx(tx, ctx, installments); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit premium schedule: %w", err)
+	}
+
+	slog.Info("premium schedule created",
+		"policy_id", policyID,
+		"installments", req.Installments,
+		"total_premium", policy.TotalFarmerPremium)
+
+	return s.buildSchedule(ctx, policy)
+}
+
+// GetScheduleForFarmer retrieves the premium schedule of a policy (farmer authorization)
+func (s *PremiumScheduleService) GetScheduleForFarmer(ctx context.Context, policyID uuid.UUID, farmerID string) (*models.PremiumSchedule, error) {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if policy.FarmerID != farmerID {
+		return nil, fmt.Errorf("unauthorized: policy does not belong to this farmer")
+	}
+	return s.buildSchedule(ctx, policy)
+}
+
+// GetScheduleForPartner retrieves the premium schedule of a policy (partner authorization)
+func (s *PremiumScheduleService) GetScheduleForPartner(ctx context.Context, policyID uuid.UUID, providerID string) (*models.PremiumSchedule, error) {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if policy.InsuranceProviderID != providerID {
+		return nil, fmt.Errorf("unauthorized: policy does not belong to this partner")
+	}
+	return s.buildSchedule(ctx, policy)
+}
+
+// GetSchedule retrieves the premium schedule of a policy (admin only - no authorization)
+func (s *PremiumScheduleService) GetSchedule(ctx context.Context, policyID uuid.UUID) (*models.PremiumSchedule, error) {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	return s.buildSchedule(ctx, policy)
+}
+
+func (s *PremiumScheduleService) buildSchedule(ctx context.Context, policy *models.RegisteredPolicy) (*models.PremiumSchedule, error) {
+	installments, err := s.scheduleRepo.GetByPolicyID(ctx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(installments) == 0 {
+		return nil, fmt.Errorf("premium schedule not found for policy %s", policy.ID)
+	}
+	payments, err := s.scheduleRepo.GetPaymentsByPolicyID(ctx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &models.PremiumSchedule{
+		RegisteredPolicyID: policy.ID,
+		Installments:       installments,
+		Payments:           payments,
+	}
+	for _, installment := range installments {
+		schedule.TotalPremium += installment.AmountDue
+		schedule.TotalPaid += installment.AmountPaid
+		if installment.Status != models.PaymentCancelled {
+			schedule.Outstanding += installment.Outstanding()
+		}
+	}
+	return schedule, nil
+}
+
+// CheckOverdue marks installments past their due date as overdue and lapses the policies with an
+// installment overdue for longer than the grace period. It runs hourly as a cron job.
+func (s *PremiumScheduleService) CheckOverdue(ctx context.Context) error {
+	now := time.Now()
+	marked, err := s.scheduleRepo.MarkOverdue(ctx, now.Unix())
+	if err != nil {
+		return err
+	}
+	if marked > 0 {
+		slog.Info("premium installments marked overdue", "count", marked)
+	}
+
+	policyIDs, err := s.scheduleRepo.GetPoliciesToLapse(ctx, now.Add(-premiumLapseGracePeriod).Unix())
+	if err != nil {
+		return err
+	}
+	for _, policyID := range policyIDs {
+		if err := s.lapsePolicy(ctx, policyID); err != nil {
+			// Continue with other policies; this one is picked up again on the next check
+			slog.Error("failed to lapse policy", "policy_id", policyID, "error", err)
+		}
+	}
+	return nil
+}
+
+// lapsePolicy moves a policy to lapsed, cancels its remaining installments and notifies the
+// farmer in one transaction, then stops its monitoring
+func (s *PremiumScheduleService) lapsePolicy(ctx context.Context, policyID uuid.UUID) error {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return err
+	}
+	wasActive := policy.Status == models.PolicyActive
+	policy.Status = models.PolicyLapsed
+
+	notification, err := event.PolicyLapsedNotification(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.registeredPolicyRepo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.registeredPolicyRepo.UpdateTx(tx, policy); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.CancelUnpaidTx(tx, ctx, policyID); err != nil {
+		return err
+	}
+	if err := s.outboxRepo.CreateTx(tx, ctx, notification); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit policy lapse: %w", err)
+	}
+
+	slog.Info("policy lapsed for missed premium installment",
+		"policy_id", policyID,
+		"policy_number", policy.PolicyNumber)
+
+	if wasActive {
+		if err := s.workerManager.CleanupWorkerInfrastructure(ctx, policyID); err != nil {
+			slog.Error("Failed to cleanup worker infrastructure",
+				"policy_id", policyID,
+				"error", err)
+		}
+	}
+	return nil
+}
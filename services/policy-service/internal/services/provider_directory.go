@@ -0,0 +1,87 @@
+package services
+
+import (
+	"agrisa_utils/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"policy-service/internal/config"
+	"policy-service/internal/models"
+	"time"
+)
+
+const providerStatusActive = "active"
+
+// ProviderDirectory looks up insurance providers in profile-service
+type ProviderDirectory struct {
+	profileServiceURL string
+	client            *http.Client
+}
+
+func NewProviderDirectory(cfg *config.PolicyServiceConfig) *ProviderDirectory {
+	return &ProviderDirectory{
+		profileServiceURL: cfg.ProfileServiceURL,
+		client:            logging.NewHTTPClient(10 * time.Second),
+	}
+}
+
+// GetInsuranceProvider returns the summary of a provider, or nil when profile-service does not
+// know it
+func (d *ProviderDirectory) GetInsuranceProvider(ctx context.Context, providerID string) (*models.InsuranceProviderSummary, error) {
+	endpoint := fmt.Sprintf("%s/profile/internal/api/v1/insurance-partners/%s/summary",
+		d.profileServiceURL, url.PathEscape(providerID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating insurance provider request: %w", err)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting insurance provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading insurance provider response: %w", err)
+	}
+
+	// A malformed ID is rejected by profile-service before the lookup, so it is as unknown as a
+	// missing one
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("unexpected insurance provider response", "status_code", resp.StatusCode, "body", string(body))
+		return nil, fmt.Errorf("unexpected status code from profile-service: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data models.InsuranceProviderSummary `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing insurance provider response: %w", err)
+	}
+	return &result.Data, nil
+}
+
+// VerifyActiveProvider fails unless the provider exists and is active, so policies are only
+// created for partners that can currently sell them
+func (d *ProviderDirectory) VerifyActiveProvider(ctx context.Context, providerID string) (*models.InsuranceProviderSummary, error) {
+	provider, err := d.GetInsuranceProvider(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify insurance provider: %w", err)
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("insurance provider not found: %s", providerID)
+	}
+	if provider.Status != providerStatusActive {
+		return nil, fmt.Errorf("invalid insurance provider: %s is %s, not active", providerID, provider.Status)
+	}
+	return provider, nil
+}
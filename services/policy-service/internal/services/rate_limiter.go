@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"policy-service/internal/config"
+	"policy-service/internal/database/redis"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Groups of routes sharing a rate limit
+const (
+	RateLimitGroupAIValidation = "ai-validation"
+	RateLimitGroupOCR          = "ocr"
+	RateLimitGroupRiskAnalysis = "risk-analysis"
+)
+
+// tokenBucketScript takes a token from the bucket at KEYS[1], holding ARGV[1] tokens at most and
+// refilled with ARGV[2] tokens per millisecond. The clock of Redis is used so every instance of
+// the service agrees on it. It returns whether a token was taken, the tokens left and, when
+// none was, the milliseconds until the next one.
+var tokenBucketScript = goredis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1]) or capacity
+local updatedAt = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updatedAt) * rate)
+
+local allowed = 0
+local retryAfter = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retryAfter = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate))
+return {allowed, math.floor(tokens), retryAfter}
+`)
+
+// RateLimitResult is the outcome of taking a token
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// RateLimiter limits how often each user may call expensive routes, with a token bucket per user
+// and route group kept in Redis so the limit holds across instances
+type RateLimiter struct {
+	redisClient       *redis.Client
+	limits            map[string]config.RateLimit
+	providerOverrides map[string]map[string]config.RateLimit
+}
+
+func NewRateLimiter(redisClient *redis.Client, cfg config.RateLimitConfig) *RateLimiter {
+	limits := map[string]config.RateLimit{
+		RateLimitGroupAIValidation: cfg.AIValidation,
+		RateLimitGroupOCR:          cfg.OCR,
+		RateLimitGroupRiskAnalysis: cfg.RiskAnalysis,
+	}
+	for providerID, groups := range cfg.ProviderOverrides {
+		for group := range groups {
+			if _, ok := limits[group]; !ok {
+				slog.Warn("Rate limit override for an unknown group is ignored", "provider_id", providerID, "group", group)
+			}
+		}
+	}
+	return &RateLimiter{
+		redisClient:       redisClient,
+		limits:            limits,
+		providerOverrides: cfg.ProviderOverrides,
+	}
+}
+
+// LimitFor returns the limit of a group for a user of the providers, the first provider with an
+// override taking precedence over the default
+func (r *RateLimiter) LimitFor(group string, providerIDs []string) (config.RateLimit, bool) {
+	for _, providerID := range providerIDs {
+		if limit, ok := r.providerOverrides[providerID][group]; ok {
+			return limit, true
+		}
+	}
+	limit, ok := r.limits[group]
+	return limit, ok
+}
+
+// Take takes a token from the bucket of the user for the group
+func (r *RateLimiter) Take(ctx context.Context, group, userID string, providerIDs []string) (*RateLimitResult, error) {
+	limit, ok := r.LimitFor(group, providerIDs)
+	if !ok {
+		return nil, fmt.Errorf("unknown rate limit group %s", group)
+	}
+
+	ratePerMs := float64(limit.Burst) / float64(limit.Period.Milliseconds())
+	key := fmt.Sprintf("RateLimit-%s-%s", group, userID)
+	values, err := tokenBucketScript.Run(ctx, r.redisClient.GetClient(), []string{key}, limit.Burst, ratePerMs).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return &RateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
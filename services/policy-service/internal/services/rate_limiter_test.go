@@ -0,0 +1,42 @@
+package services
+
+import (
+	"policy-service/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterLimitFor(t *testing.T) {
+	limiter := NewRateLimiter(nil, config.RateLimitConfig{
+		AIValidation: config.RateLimit{Burst: 10, Period: time.Hour},
+		OCR:          config.RateLimit{Burst: 20, Period: time.Hour},
+		RiskAnalysis: config.RateLimit{Burst: 30, Period: time.Hour},
+		ProviderOverrides: map[string]map[string]config.RateLimit{
+			"provider-a": {RateLimitGroupAIValidation: {Burst: 100, Period: time.Hour}},
+			"provider-b": {RateLimitGroupAIValidation: {Burst: 50, Period: time.Minute}},
+		},
+	})
+
+	tests := []struct {
+		name        string
+		group       string
+		providerIDs []string
+		want        config.RateLimit
+		found       bool
+	}{
+		{"Default", RateLimitGroupAIValidation, nil, config.RateLimit{Burst: 10, Period: time.Hour}, true},
+		{"Provider override", RateLimitGroupAIValidation, []string{"provider-a"}, config.RateLimit{Burst: 100, Period: time.Hour}, true},
+		{"First provider with an override", RateLimitGroupAIValidation, []string{"provider-c", "provider-b", "provider-a"}, config.RateLimit{Burst: 50, Period: time.Minute}, true},
+		{"Override of another group", RateLimitGroupOCR, []string{"provider-a"}, config.RateLimit{Burst: 20, Period: time.Hour}, true},
+		{"Unknown group", "reports", nil, config.RateLimit{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, found := limiter.LimitFor(tt.group, tt.providerIDs)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, limit)
+		})
+	}
+}
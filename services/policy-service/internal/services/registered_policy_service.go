@@ -2,6 +2,7 @@ package services
 
 import (
 	utils "agrisa_utils"
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -19,8 +20,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
 )
 
+// defaultBillingCurrency is billed when a partner has no contract stating otherwise
+const defaultBillingCurrency = "VND"
+
 // RegisteredPolicyService handles registered policy operations and worker infrastructure lifecycle
 type RegisteredPolicyService struct {
 	registeredPolicyRepo   *repository.RegisteredPolicyRepository
@@ -35,6 +40,11 @@ type RegisteredPolicyService struct {
 	notievent              *event.NotificationHelper
 	geminiSelector         *gemini.GeminiClientSelector
 	redisClient            *redis.Client
+	consentChecker         *ConsentChecker
+	payoutCalculator       *PayoutCalculationService
+	providerDirectory      *ProviderDirectory
+	auditService           *AuditService
+	aiUsageService         *AIUsageService
 }
 
 // NewRegisteredPolicyService creates a new registered policy service
@@ -51,6 +61,11 @@ func NewRegisteredPolicyService(
 	notievent *event.NotificationHelper,
 	geminiSelector *gemini.GeminiClientSelector,
 	redisClient *redis.Client,
+	consentChecker *ConsentChecker,
+	payoutCalculator *PayoutCalculationService,
+	providerDirectory *ProviderDirectory,
+	auditService *AuditService,
+	aiUsageService *AIUsageService,
 ) *RegisteredPolicyService {
 	return &RegisteredPolicyService{
 		registeredPolicyRepo:   registeredPolicyRepo,
@@ -65,6 +80,11 @@ func NewRegisteredPolicyService(
 		notievent:              notievent,
 		geminiSelector:         geminiSelector,
 		redisClient:            redisClient,
+		consentChecker:         consentChecker,
+		payoutCalculator:       payoutCalculator,
+		providerDirectory:      providerDirectory,
+		auditService:           auditService,
+		aiUsageService:         aiUsageService,
 	}
 }
 
@@ -201,6 +221,7 @@ func (s *RegisteredPolicyService) RegisterAPolicy(request models.RegisterAPolicy
 	}
 
 	var panicErr error
+	committed := false
 	defer func() {
 		if r := recover(); r != nil {
 			slog.Error("panic recovered", "panic", r)
@@ -208,8 +229,8 @@ func (s *RegisteredPolicyService) RegisterAPolicy(request models.RegisterAPolicy
 			err = panicErr
 		}
 
-		// Single rollback point
-		if err != nil && tx != nil {
+		// Single rollback point, the early returns below leave err unset
+		if !committed && tx != nil {
 			if rbErr := tx.Rollback(); rbErr != nil {
 				slog.Error("failed to rollback transaction", "rollback_error", rbErr, "original_error", err)
 			}
@@ -314,6 +335,22 @@ func (s *RegisteredPolicyService) RegisterAPolicy(request models.RegisterAPolicy
 		return nil, fmt.Errorf("base policy is not active: status=%s", completeBasePolicy.BasePolicy.Status)
 	}
 
+	if request.RegisteredPolicy.InsuranceProviderID != completeBasePolicy.BasePolicy.InsuranceProviderID {
+		return nil, fmt.Errorf("invalid insurance provider: base policy belongs to another provider")
+	}
+	if _, err := s.providerDirectory.VerifyActiveProvider(ctx, completeBasePolicy.BasePolicy.InsuranceProviderID); err != nil {
+		slog.Error("insurance provider verification failed", "provider_id", completeBasePolicy.BasePolicy.InsuranceProviderID, "error", err)
+		return nil, err
+	}
+
+	suspended, err := s.redisClient.GetClient().Get(ctx, fmt.Sprintf("Suspended-Profile-%s", completeBasePolicy.BasePolicy.InsuranceProviderID)).Result()
+	if err != nil && err != goredis.Nil {
+		slog.Error("error check partner suspension failed", "error", err)
+	}
+	if suspended == "true" {
+		return nil, fmt.Errorf("insurance partner is suspended: new registrations are paused")
+	}
+
 	if completeBasePolicy.BasePolicy.InsuranceValidToDay != nil {
 		if now.Unix() > int64(*completeBasePolicy.BasePolicy.InsuranceValidToDay) {
 			return nil, fmt.Errorf("base policy is invalid")
@@ -371,12 +408,31 @@ func (s *RegisteredPolicyService) RegisterAPolicy(request models.RegisterAPolicy
 		request.RegisteredPolicy.SignedPolicyDocumentURL = &signedDocumentLocation
 	}
 
+	// pin the policy to the version of the terms it is sold under
+	basePolicyVersion, err := s.basePolicyService.RecordPolicyVersionTx(tx, ctx, request.RegisteredPolicy.BasePolicyID)
+	if err != nil {
+		slog.Error("error recording base policy version", "base_policy_id", request.RegisteredPolicy.BasePolicyID, "error", err)
+		return nil, fmt.Errorf("error recording base policy version: %w", err)
+	}
+	request.RegisteredPolicy.BasePolicyVersion = &basePolicyVersion
+
 	// create new register policy
 	err = s.registeredPolicyRepo.CreateTx(tx, &request.RegisteredPolicy)
 	if err != nil {
 		slog.Error("error creating new registered policy", "policy", request.RegisteredPolicy, "error", err)
 		return nil, fmt.Errorf("error creating new registered policy: %w", err)
 	}
+	// Policies registered from now on are only monitored while the farmer consents to it
+	err = s.registeredPolicyRepo.RequireMonitoringConsentTx(tx, request.RegisteredPolicy.ID, request.RegisteredPolicy.FarmerID)
+	if err != nil {
+		slog.Error("error requiring monitoring consent", "policy_id", request.RegisteredPolicy.ID, "error", err)
+		return nil, fmt.Errorf("error requiring monitoring consent: %w", err)
+	}
+	err = s.auditService.RecordTx(tx, ctx, models.AuditEntityRegisteredPolicy, request.RegisteredPolicy.ID, models.AuditActionCreate, nil, request.RegisteredPolicy)
+	if err != nil {
+		slog.Error("error recording registered policy creation", "policy_id", request.RegisteredPolicy.ID, "error", err)
+		return nil, fmt.Errorf("error recording registered policy creation: %w", err)
+	}
 	basePolicyTrigger, err := s.basePolicyRepo.GetBasePolicyTriggersByPolicyID(request.RegisteredPolicy.BasePolicyID)
 	if err != nil {
 		slog.Error("error getting base policy trigger", "error", err)
@@ -392,6 +448,7 @@ func (s *RegisteredPolicyService) RegisterAPolicy(request models.RegisterAPolicy
 		slog.Error("error commiting registered policy transaction", "error", err)
 		return nil, fmt.Errorf("error commiting registered policy transaction: %w", err)
 	}
+	committed = true
 	// start create worker infrastructure and data jobs
 	go func() {
 		retryWait := 0.5
@@ -429,6 +486,7 @@ func (s *RegisteredPolicyService) RegisterAPolicy(request models.RegisterAPolicy
 			MaxRetries: 10,
 			OneTime:    true,
 			RunNow:     true,
+			RequestID:  logging.RequestID(ctx),
 		}
 		scheduler, ok := s.workerManager.GetSchedulerByPolicyID(request.RegisteredPolicy.ID)
 		if !ok {
@@ -477,18 +535,36 @@ func (s *RegisteredPolicyService) calculateFarmerPremium(areasqm, basePremiumRat
 }
 
 // GetPolicyStats retrieves policy statistics (optionally filtered by provider)
-func (s *RegisteredPolicyService) GetPolicyStats(providerID string) (map[string]any, error) {
+func (s *RegisteredPolicyService) GetPolicyStats(providerID string) (*models.PolicyStats, error) {
 	return s.registeredPolicyRepo.GetPolicyStats(providerID)
 }
 
 // UpdatePolicyStatus updates the status of a registered policy
-func (s *RegisteredPolicyService) UpdatePolicyStatus(policyID uuid.UUID, status models.PolicyStatus) error {
-	return s.registeredPolicyRepo.UpdateStatus(policyID, status)
+func (s *RegisteredPolicyService) UpdatePolicyStatus(ctx context.Context, policyID uuid.UUID, status models.PolicyStatus) error {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return err
+	}
+	if err := s.registeredPolicyRepo.UpdateStatus(policyID, status); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, models.AuditEntityRegisteredPolicy, policyID, models.AuditActionUpdate,
+		map[string]any{"status": policy.Status}, map[string]any{"status": status})
+	return nil
 }
 
 // UpdateUnderwritingStatus updates the underwriting status of a registered policy
-func (s *RegisteredPolicyService) UpdateUnderwritingStatus(policyID uuid.UUID, status models.UnderwritingStatus) error {
-	return s.registeredPolicyRepo.UpdateUnderwritingStatus(policyID, status)
+func (s *RegisteredPolicyService) UpdateUnderwritingStatus(ctx context.Context, policyID uuid.UUID, status models.UnderwritingStatus) error {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		return err
+	}
+	if err := s.registeredPolicyRepo.UpdateUnderwritingStatus(policyID, status); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, models.AuditEntityRegisteredPolicy, policyID, models.AuditActionUpdate,
+		map[string]any{"underwriting_status": policy.UnderwritingStatus}, map[string]any{"underwriting_status": status})
+	return nil
 }
 
 // GetPolicyByID retrieves a single policy by ID
@@ -505,6 +581,24 @@ func (s *RegisteredPolicyService) GetPolicyByID(policyID uuid.UUID) (*models.Reg
 	return policy, nil
 }
 
+// GetPolicyDetail retrieves a single policy along with its insurance provider. The policy is
+// still returned without the provider when profile-service cannot be reached.
+func (s *RegisteredPolicyService) GetPolicyDetail(ctx context.Context, policyID uuid.UUID) (*models.RegisteredPolicy, error) {
+	policy, err := s.GetPolicyByID(policyID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.providerDirectory.GetInsuranceProvider(ctx, policy.InsuranceProviderID)
+	if err != nil {
+		slog.Warn("failed to get insurance provider for policy detail",
+			"policy_id", policyID,
+			"provider_id", policy.InsuranceProviderID,
+			"error", err)
+	}
+	policy.InsuranceProvider = provider
+	return policy, nil
+}
+
 // GetPoliciesByFarmerID retrieves all policies for a specific farmer
 func (s *RegisteredPolicyService) GetPoliciesByFarmerID(farmerID string) ([]models.RegisteredPolicy, error) {
 	return s.registeredPolicyRepo.GetByFarmerID(farmerID)
@@ -840,6 +934,7 @@ func (s *RegisteredPolicyService) CreatePartnerPolicyUnderwriting(
 	}
 
 	// 4. If approved, update policy status
+	before := *policy
 	responseMessage := "Underwriting record created"
 	policy.UnderwritingStatus = req.UnderwritingStatus
 	if req.UnderwritingStatus == models.UnderwritingApproved {
@@ -860,6 +955,7 @@ func (s *RegisteredPolicyService) CreatePartnerPolicyUnderwriting(
 		}
 		responseMessage = "Underwriting rejected, policy rejected"
 	}
+	s.auditService.Record(ctx, models.AuditEntityRegisteredPolicy, policyID, models.AuditActionUpdate, before, policy)
 
 	go func() {
 		for {
@@ -899,12 +995,20 @@ func (s *RegisteredPolicyService) CreatePartnerPolicyUnderwriting(
 				slog.Info("policy status invalid skip payment window", "status", policy.Status)
 				return
 			}
+			// Installment plans have their own deadlines, enforced by the premium schedule
+			if scheduled, err := s.registeredPolicyRepo.HasPremiumSchedule(policyID); err != nil || scheduled {
+				slog.Info("policy premium paid in installments skip payment window", "policy_id", policyID, "error", err)
+				return
+			}
+			before := *policy
 			policy.Status = models.PolicyCancelled
 			err = s.registeredPolicyRepo.Update(policy)
 			if err != nil {
 				slog.Info("error updating policy", "error", err)
 				return
 			}
+			// No user cancelled it: record the change under the system actor
+			s.auditService.Record(context.Background(), models.AuditEntityRegisteredPolicy, policyID, models.AuditActionUpdate, before, policy)
 			slog.Info("payment pending due: policy status set to cancelled", "policy_id", policy.ID)
 
 			go func() {
@@ -1033,6 +1137,12 @@ func (s *RegisteredPolicyService) GetMonthlyDataCost(
 		totalDataCost += cost.SumTotalDataCost
 	}
 
+	billingDate := time.Date(request.Year, time.Month(request.Month), 1, 0, 0, 0, 0, time.UTC)
+	terms, err := s.GetPartnerContractTerms(insuranceProviderID, billingDate)
+	if err != nil {
+		return nil, err
+	}
+
 	response := &models.MonthlyDataCostResponse{
 		InsuranceProviderID:     insuranceProviderID,
 		Month:                   request.Month,
@@ -1040,12 +1150,59 @@ func (s *RegisteredPolicyService) GetMonthlyDataCost(
 		BasePolicyCosts:         basePolicyCosts,
 		TotalActivePolicies:     totalActivePolicies,
 		TotalBasePolicyDataCost: totalDataCost,
-		Currency:                "VND",
+		Currency:                defaultBillingCurrency,
+		TotalBillableDataCost:   totalDataCost,
+	}
+	if terms != nil {
+		response.ContractTerms = terms
+		response.Currency = terms.Currency
+		response.DataCostMarkup = math.Round(totalDataCost*terms.DataCostMarkupPercent) / 100
+		response.TotalBillableDataCost = totalDataCost + response.DataCostMarkup
+	} else {
+		slog.Warn("no partner contract in effect, billing data cost without markup",
+			"provider_id", insuranceProviderID,
+			"billing_date", billingDate.Format("2006-01-02"))
 	}
 
 	return response, nil
 }
 
+// GetPartnerContractTerms asks profile-service for the contract terms in effect for a partner on
+// date. It returns nil terms when the partner has no contract covering that date.
+func (s *RegisteredPolicyService) GetPartnerContractTerms(providerID string, date time.Time) (*models.PartnerContractTerms, error) {
+	url := fmt.Sprintf("http://profile-service:8087/profile/internal/api/v1/partner-contracts/%s/terms?date=%s", providerID, date.Format("2006-01-02"))
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		slog.Error("Error making request for partner contract terms", "error", err)
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		slog.Error("Error reading response body for partner contract terms", "error", err)
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("Unexpected status code for partner contract terms", "status_code", resp.StatusCode, "body", string(body))
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data models.PartnerContractTerms `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		slog.Error("Error parsing JSON for partner contract terms", "error", err)
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+	return &result.Data, nil
+}
+
 func (s *RegisteredPolicyService) GetAllUserIDsFromInsuranceProvider(providerID string, token string) ([]string, error) {
 	url := "http://profile-service:8087/profile/public/api/v1/users/" + providerID
 	req, err := http.NewRequest("GET", url, nil)
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"policy-service/internal/models"
+	"time"
+)
+
+// riskAnalysisCacheVersion is hashed with the inputs of a risk analysis. Bump it when the risk
+// analysis prompt changes, so analyses made with the previous prompt are not reused.
+const riskAnalysisCacheVersion = 2
+
+// riskAnalysisInputs is the data a risk analysis is made from. Photo contents are left out, the
+// farm lists its photos by URL; so is the climatology, fetched only when the analysis runs.
+type riskAnalysisInputs struct {
+	Version        int                                 `json:"version"`
+	Policy         models.RegisteredPolicy             `json:"policy"`
+	Farm           models.Farm                         `json:"farm"`
+	MonitoringData []models.FarmMonitoringData         `json:"monitoring_data"`
+	Trigger        models.BasePolicyTrigger            `json:"trigger"`
+	Conditions     []models.BasePolicyTriggerCondition `json:"conditions"`
+	DataSources    map[string]models.DataSource        `json:"data_sources"`
+}
+
+// riskAnalysisInputHash returns the SHA-256 of the inputs. Fields that change without changing
+// what the AI is told, such as the update time of the policy, are cleared first.
+func riskAnalysisInputHash(inputs riskAnalysisInputs) (string, error) {
+	inputs.Version = riskAnalysisCacheVersion
+	inputs.Policy.UpdatedAt = time.Time{}
+	inputs.Policy.InsuranceProvider = nil
+
+	// encoding/json writes map keys in order, so equal inputs give equal bytes
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode risk analysis inputs: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"policy-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleRiskAnalysisInputs returns the same inputs on every call
+func sampleRiskAnalysisInputs() riskAnalysisInputs {
+	farmID := uuid.MustParse("0b6f3c1e-6d2a-4f5e-9a1b-2c3d4e5f6a7b")
+	dataSourceID := uuid.MustParse("5e4d3c2b-1a09-4f8e-8d7c-6b5a49382716")
+	return riskAnalysisInputs{
+		Policy: models.RegisteredPolicy{
+			ID:             uuid.MustParse("9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"),
+			FarmID:         farmID,
+			CoverageAmount: 50_000_000,
+			Status:         models.PolicyPendingReview,
+			UpdatedAt:      time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+		Farm: models.Farm{
+			ID:         farmID,
+			FarmPhotos: []models.FarmPhoto{{ID: uuid.MustParse("c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f"), PhotoURL: "farm-photos/a.jpg"}},
+		},
+		MonitoringData: []models.FarmMonitoringData{
+			{ID: uuid.MustParse("d4c3b2a1-0f9e-4d8c-b7a6-958473625140"), FarmID: farmID, DataSourceID: dataSourceID, ParameterName: "ndvi", MeasuredValue: 0.61, MeasurementTimestamp: 1767225600},
+		},
+		Trigger:    models.BasePolicyTrigger{ID: uuid.MustParse("e5f6a7b8-c9d0-4e1f-a2b3-c4d5e6f7a8b9")},
+		Conditions: []models.BasePolicyTriggerCondition{{ID: uuid.MustParse("f6a7b8c9-d0e1-4f2a-b3c4-d5e6f7a8b9c0"), DataSourceID: dataSourceID}},
+		DataSources: map[string]models.DataSource{
+			dataSourceID.String(): {ID: dataSourceID},
+		},
+	}
+}
+
+func TestRiskAnalysisInputHash(t *testing.T) {
+	inputs := sampleRiskAnalysisInputs()
+
+	hash, err := riskAnalysisInputHash(inputs)
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	again, err := riskAnalysisInputHash(inputs)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	touched := inputs
+	touched.Policy.UpdatedAt = time.Now()
+	touchedHash, err := riskAnalysisInputHash(touched)
+	require.NoError(t, err)
+	assert.Equal(t, hash, touchedHash, "the update time of the policy is not sent to the AI")
+}
+
+func TestRiskAnalysisInputHashChanges(t *testing.T) {
+	base, err := riskAnalysisInputHash(sampleRiskAnalysisInputs())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(inputs *riskAnalysisInputs)
+	}{
+		{
+			name: "New monitoring data",
+			modify: func(inputs *riskAnalysisInputs) {
+				inputs.MonitoringData = append(inputs.MonitoringData, models.FarmMonitoringData{ID: uuid.New(), MeasuredValue: 0.4})
+			},
+		},
+		{
+			name:   "Measured value corrected",
+			modify: func(inputs *riskAnalysisInputs) { inputs.MonitoringData[0].MeasuredValue = 0.2 },
+		},
+		{
+			name: "New farm photo",
+			modify: func(inputs *riskAnalysisInputs) {
+				inputs.Farm.FarmPhotos = append(inputs.Farm.FarmPhotos, models.FarmPhoto{ID: uuid.New(), PhotoURL: "farm-photos/b.jpg"})
+			},
+		},
+		{
+			name:   "Coverage changed",
+			modify: func(inputs *riskAnalysisInputs) { inputs.Policy.CoverageAmount = 60_000_000 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := sampleRiskAnalysisInputs()
+			tt.modify(&inputs)
+
+			modified, err := riskAnalysisInputHash(inputs)
+			require.NoError(t, err)
+			assert.NotEqual(t, base, modified)
+		})
+	}
+}
@@ -2,13 +2,20 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"policy-service/internal/ai/gemini"
+	"policy-service/internal/database/minio"
 	"policy-service/internal/models"
+	"policy-service/internal/worker"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,10 +23,49 @@ import (
 	"github.com/google/uuid"
 )
 
-// RiskAnalysisJob performs AI-powered risk analysis on a registered policy
+// RequestRiskAnalysis queues a risk analysis of a policy under review and returns the ID of the
+// job. Unless force is set the job keeps the latest analysis if its data has not changed.
+func (s *RegisteredPolicyService) RequestRiskAnalysis(ctx context.Context, policyID uuid.UUID, force bool) (string, error) {
+	policy, err := s.registeredPolicyRepo.GetByID(policyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("registered policy not found: %s", policyID)
+		}
+		return "", err
+	}
+	if policy.Status != models.PolicyPendingReview && policy.UnderwritingStatus != models.UnderwritingPending {
+		return "", fmt.Errorf("invalid operation: policy %s is no longer under review", policyID)
+	}
+
+	scheduler, ok := s.workerManager.GetSchedulerByPolicyID(policyID)
+	if !ok {
+		return "", fmt.Errorf("scheduler not found for policy %s", policyID)
+	}
+
+	job := worker.JobPayload{
+		JobID:      uuid.NewString(),
+		Type:       "risk-analysis",
+		Params:     map[string]any{"registered_policy_id": policyID.String(), "force": force},
+		MaxRetries: 5,
+		OneTime:    true,
+		RunNow:     true,
+		Result:     &worker.JobResultRef{Resource: "registered_policy_risk_analysis", ID: policyID.String()},
+	}
+	scheduler.AddJob(job)
+
+	slog.InfoContext(ctx, "Risk analysis requested",
+		"policy_id", policyID,
+		"job_id", job.JobID,
+		"force", force)
+	return job.JobID, nil
+}
+
+// RiskAnalysisJob performs AI-powered risk analysis on a registered policy. The analysis is
+// skipped when the latest one was made from the same data.
 // Parameters:
 //   - registered_policy_id (string, required): UUID of the policy to analyze
-//   - force_reanalysis (bool, optional): Skip existing analysis check
+//   - force (bool, optional): Analyze again even if the data has not changed; force_reanalysis
+//     is read too, for jobs queued before it was renamed
 func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 	defer func() {
 		if r := recover(); r != nil {
@@ -40,11 +86,14 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 		return fmt.Errorf("invalid UUID format for registered_policy_id: %w", err)
 	}
 
-	forceReanalysis, _ := params["force_reanalysis"].(bool)
+	force, _ := params["force"].(bool)
+	if legacyForce, _ := params["force_reanalysis"].(bool); legacyForce {
+		force = true
+	}
 
 	slog.Info("Starting risk analysis job",
 		"registered_policy_id", policyIDStr,
-		"force_reanalysis", forceReanalysis)
+		"force", force)
 
 	// 2. Get registered policy
 	policy, err := s.registeredPolicyRepo.GetByID(policyID)
@@ -67,19 +116,7 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 		return nil
 	}
 
-	// 3. Check for existing analysis (skip if exists and not forced)
-	if !forceReanalysis {
-		existing, _ := s.registeredPolicyRepo.GetRiskAnalysesByPolicyID(policyID)
-		if len(existing) > 0 {
-			slog.Info("Risk analysis already exists, skipping",
-				"policy_id", policyIDStr,
-				"analysis_count", len(existing),
-				"latest_analysis_id", existing[0].ID)
-			return nil
-		}
-	}
-
-	// 4. Parallel data fetching
+	// 3. Parallel data fetching
 	var (
 		farm           *models.Farm
 		farmPhotos     []models.FarmPhoto
@@ -180,7 +217,7 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 		"monitoring_data_points", len(monitoringData),
 		"conditions_count", len(conditions))
 
-	// 5. Resolve data sources for all conditions
+	// 4. Resolve data sources for all conditions
 	dataSources := make(map[string]models.DataSource)
 	for _, cond := range conditions {
 		ds, err := s.dataSourceRepo.GetDataSourceByID(cond.DataSourceID)
@@ -196,11 +233,44 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 
 	slog.Info("Resolved data sources", "count", len(dataSources))
 
-	// 6. Download farm photos from MinIO concurrently
+	// 5. Keep the latest analysis when it was made from the same data, before downloading
+	// photos and building the prompt
+	inputHash, err := riskAnalysisInputHash(riskAnalysisInputs{
+		Policy:         *policy,
+		Farm:           *farm,
+		MonitoringData: monitoringData,
+		Trigger:        *trigger,
+		Conditions:     conditions,
+		DataSources:    dataSources,
+	})
+	if err != nil {
+		return err
+	}
+	if !force {
+		existing, _ := s.registeredPolicyRepo.GetRiskAnalysesByPolicyID(policyID)
+		if len(existing) > 0 && existing[0].InputHash != nil && *existing[0].InputHash == inputHash {
+			slog.Info("Risk analysis inputs unchanged, keeping the latest analysis",
+				"policy_id", policyIDStr,
+				"latest_analysis_id", existing[0].ID,
+				"input_hash", inputHash)
+			return nil
+		}
+	}
+
+	// 6. Summarize the monitoring data for the prompt; the full series is archived instead
+	monitoringSummary := summarizeMonitoringData(monitoringData)
+	monitoringSummary.RawDataObject = s.storeMonitoringData(ctx, policyID, inputHash, monitoringData)
+
+	slog.Info("Monitoring data summarized",
+		"measurements", monitoringSummary.TotalMeasurements,
+		"parameters", len(monitoringSummary.Parameters),
+		"raw_data_object", monitoringSummary.RawDataObject)
+
+	// 7. Download farm photos from MinIO concurrently
 	farmPhotoData := make([]string, 0)
 	if len(farmPhotos) > 0 && s.minioClient != nil {
 		var downloadErr error
-		farmPhotoData, downloadErr = s.downloadFarmPhotosParallel(ctx, farmPhotos)
+		farmPhotoData, downloadErr = downloadFarmPhotosParallel(ctx, s.minioClient, farmPhotos)
 		if downloadErr != nil {
 			slog.Warn("Some photos failed to download", "error", downloadErr)
 			// Continue with available photos
@@ -211,16 +281,25 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 		"requested", len(farmPhotos),
 		"downloaded", len(farmPhotoData))
 
-	// 7. Build risk analysis prompt
+	// Recent weather against the location's normals; the analysis goes ahead without it
+	climatology, err := s.fetchClimatology(ctx, farm)
+	if err != nil {
+		slog.Warn("Failed to fetch climatological context, continuing without it",
+			"farm_id", farm.ID,
+			"error", err)
+	}
+
+	// 8. Build risk analysis prompt
 	prompt := gemini.BuildRiskAnalysisPrompt(
 		*farm,
 		farmPhotos,
 		farmPhotoData,
-		monitoringData,
+		monitoringSummary,
 		*trigger,
 		conditions,
 		dataSources,
 		*policy,
+		climatology,
 	)
 
 	slog.Info("Risk analysis prompt constructed",
@@ -229,27 +308,34 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 		"monitoring_data_points", len(monitoringData),
 		"conditions_count", len(conditions))
 
-	// 8. Call AI service with failover
+	// 9. Call AI service with failover
 	if s.geminiSelector == nil {
 		return fmt.Errorf("gemini selector is not configured")
 	}
 
+	aiCtx, usageMeter := gemini.WithUsageMeter(ctx)
 	var aiResp map[string]any
 	if len(farmPhotoData) > 0 {
 		// Use multi-modal with images
-		aiResp, err = gemini.SendAIWithImagesAndRetry(ctx, prompt, farmPhotoData, s.geminiSelector)
+		aiResp, err = gemini.SendAIWithImagesAndRetry(aiCtx, prompt, farmPhotoData, s.geminiSelector)
 	} else {
 		// Use text-only (no images available)
 		// For text-only, we can use a simple wrapper or just send without images
-		aiResp, err = gemini.SendAIWithImagesAndRetry(ctx, prompt, []string{}, s.geminiSelector)
+		aiResp, err = gemini.SendAIWithImagesAndRetry(aiCtx, prompt, []string{}, s.geminiSelector)
 	}
+	s.aiUsageService.RecordJob(ctx, models.AIUsageSource{
+		JobType:             "risk-analysis",
+		InsuranceProviderID: policy.InsuranceProviderID,
+		BasePolicyID:        &policy.BasePolicyID,
+		RegisteredPolicyID:  &policy.ID,
+	}, usageMeter, err == nil)
 
 	if err != nil {
 		slog.Error("AI risk analysis request failed", "error", err)
 		return fmt.Errorf("AI risk analysis failed: %w", err)
 	}
 
-	// 9. Parse AI response into risk analysis structure
+	// 10. Parse AI response into risk analysis structure
 	var riskAnalysis models.RegisteredPolicyRiskAnalysis
 	respBytes, err := json.Marshal(aiResp)
 	if err != nil {
@@ -270,6 +356,7 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 	// Set metadata fields
 	riskAnalysis.ID = uuid.New()
 	riskAnalysis.RegisteredPolicyID = policyID
+	riskAnalysis.InputHash = &inputHash
 	riskAnalysis.CreatedAt = time.Now()
 
 	// Ensure analysis type is set
@@ -317,7 +404,7 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 		"analysis_timestamp_value", riskAnalysis.AnalysisTimestamp,
 		"overall_risk_level", riskAnalysis.OverallRiskLevel)
 
-	// 10. Persist risk analysis
+	// 11. Persist risk analysis
 	if err := s.registeredPolicyRepo.CreateRiskAnalysis(&riskAnalysis); err != nil {
 		slog.Error("Failed to persist risk analysis", "error", err)
 		return fmt.Errorf("failed to persist risk analysis: %w", err)
@@ -332,9 +419,32 @@ func (s *RegisteredPolicyService) RiskAnalysisJob(params map[string]any) error {
 	return nil
 }
 
+// storeMonitoringData archives the monitoring data an analysis is made from at
+// risk-analysis-inputs/<policy id>/<input hash>.json and returns bucket/key, or "" when it could
+// not be stored; the prompt only carries the summary, so the analysis goes ahead either way
+func (s *RegisteredPolicyService) storeMonitoringData(ctx context.Context, policyID uuid.UUID, inputHash string, data []models.FarmMonitoringData) string {
+	if s.minioClient == nil || len(data) == 0 {
+		return ""
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		slog.Warn("Failed to encode monitoring data", "policy_id", policyID, "error", err)
+		return ""
+	}
+
+	objectName := fmt.Sprintf("risk-analysis-inputs/%s/%s.json", policyID, inputHash)
+	if err := s.minioClient.UploadBytes(ctx, minio.Storage.PolicyService, objectName, content, "application/json"); err != nil {
+		slog.Warn("Failed to store monitoring data", "policy_id", policyID, "error", err)
+		return ""
+	}
+	return minio.Storage.PolicyService + "/" + objectName
+}
+
 // downloadFarmPhotosParallel downloads farm photos from MinIO concurrently
-func (s *RegisteredPolicyService) downloadFarmPhotosParallel(
+func downloadFarmPhotosParallel(
 	ctx context.Context,
+	minioClient *minio.MinioClient,
 	photos []models.FarmPhoto,
 ) ([]string, error) {
 	if len(photos) == 0 {
@@ -367,7 +477,7 @@ func (s *RegisteredPolicyService) downloadFarmPhotosParallel(
 				"bucket", bucket,
 				"extracted_key", objectKey)
 
-			obj, err := s.minioClient.GetFile(ctx, bucket, objectKey)
+			obj, err := minioClient.GetFile(ctx, bucket, objectKey)
 			if err != nil {
 				errChan <- fmt.Errorf("photo %d (%s): %w", idx, p.ID, err)
 				return
@@ -501,6 +611,56 @@ func (s *RegisteredPolicyService) determineUnderwritingStatus(analysis models.Re
 	return models.UnderwritingPending
 }
 
+// Days of recent weather compared with the climatological normals of a farm in risk analysis
+const riskClimatologyDays = 90
+
+// fetchClimatology fetches the recent rainfall and temperature of a farm against their
+// climatological normals from weather-service, as indented JSON for the analysis prompt
+func (s *RegisteredPolicyService) fetchClimatology(ctx context.Context, farm *models.Farm) (string, error) {
+	params := url.Values{}
+	params.Set("days", strconv.Itoa(riskClimatologyDays))
+	switch {
+	case farm.AgroPolygonID != nil && *farm.AgroPolygonID != "":
+		params.Set("polygon_id", *farm.AgroPolygonID)
+	case farm.CenterLocation != nil && len(farm.CenterLocation.Coordinates) == 2:
+		params.Set("lat", strconv.FormatFloat(farm.CenterLocation.Coordinates[1], 'f', 6, 64))
+		params.Set("lon", strconv.FormatFloat(farm.CenterLocation.Coordinates[0], 'f', 6, 64))
+	default:
+		return "", fmt.Errorf("farm has no location")
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	endpoint := s.farmService.config.WeatherDataServiceURL + "/weather/public/api/v2/climatology?" + params.Encode()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("climatology request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read climatology response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("climatology returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var climatology map[string]any
+	if err := json.Unmarshal(body, &climatology); err != nil {
+		return "", fmt.Errorf("failed to parse climatology response: %w", err)
+	}
+	formatted, err := json.MarshalIndent(climatology, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format climatology: %w", err)
+	}
+	return string(formatted), nil
+}
+
 // getMapKeys returns the keys of a map for logging purposes
 func getMapKeys(m map[string]any) []string {
 	keys := make([]string, 0, len(m))
@@ -0,0 +1,254 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+)
+
+const defaultCronJobTimeout = 10 * time.Minute
+
+// CronJob is a task run on a schedule by a single instance of the service
+type CronJob struct {
+	Name string
+	// Standard 5-field cron expression ("0 2 * * *") or descriptor ("@hourly", "@every 15m"),
+	// evaluated in the server's local time
+	Schedule string
+	// Bounds a run. A tick coming while the previous run still goes on, on any instance, is skipped.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type cronEntry struct {
+	job      CronJob
+	schedule cron.Schedule
+}
+
+// CronScheduler runs the registered cron jobs. Every instance of the service ticks; for each tick
+// the instances race for a Redis lock and only the winner runs the job, provided no run of the job
+// is still going. Run counts are kept in Redis as well so they cover all instances.
+type CronScheduler struct {
+	redisClient *goredis.Client
+	locker      *Locker
+	instanceID  string
+
+	mu      sync.RWMutex
+	entries map[string]*cronEntry
+
+	// Set once started, jobs registered later start right away
+	ctx context.Context
+	wg  *sync.WaitGroup
+}
+
+func NewCronScheduler(redisClient *goredis.Client, locker *Locker) *CronScheduler {
+	return &CronScheduler{
+		redisClient: redisClient,
+		locker:      locker,
+		instanceID:  locker.InstanceID(),
+		entries:     make(map[string]*cronEntry),
+	}
+}
+
+// Register adds a job to the scheduler
+func (s *CronScheduler) Register(job CronJob) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("invalid cron job: name and run function are required")
+	}
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid cron job %s: bad schedule %q: %w", job.Name, job.Schedule, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = defaultCronJobTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.entries[job.Name]; exists {
+		return fmt.Errorf("cron job already registered: %s", job.Name)
+	}
+	entry := &cronEntry{job: job, schedule: schedule}
+	s.entries[job.Name] = entry
+
+	if s.ctx != nil {
+		s.wg.Add(1)
+		go s.loop(s.ctx, s.wg, entry)
+	}
+	return nil
+}
+
+// Start runs the registered jobs until ctx is cancelled
+func (s *CronScheduler) Start(ctx context.Context, managerWg *sync.WaitGroup) {
+	defer managerWg.Done()
+
+	if s.redisClient == nil {
+		slog.Warn("Cron scheduler skipping start: Redis client not available")
+		return
+	}
+
+	var entryWg sync.WaitGroup
+	s.mu.Lock()
+	s.ctx, s.wg = ctx, &entryWg
+	for _, entry := range s.entries {
+		entryWg.Add(1)
+		go s.loop(ctx, &entryWg, entry)
+	}
+	count := len(s.entries)
+	s.mu.Unlock()
+
+	slog.Info("Cron scheduler started", "instance_id", s.instanceID, "job_count", count)
+	<-ctx.Done()
+	entryWg.Wait()
+	slog.Info("Cron scheduler stopped", "instance_id", s.instanceID)
+}
+
+// loop waits for each tick of one job and runs it
+func (s *CronScheduler) loop(ctx context.Context, wg *sync.WaitGroup, entry *cronEntry) {
+	defer wg.Done()
+
+	for {
+		tick := entry.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(tick))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runTick(ctx, entry, tick)
+	}
+}
+
+// runTick runs a job for one tick if this instance wins the lock of that tick
+func (s *CronScheduler) runTick(ctx context.Context, entry *cronEntry, tick time.Time) {
+	name := entry.job.Name
+
+	// The lock is per tick, so it only has to outlive the clock skew between instances
+	lockKey := fmt.Sprintf("cron:lock:%s:%d", name, tick.Unix())
+	acquired, err := s.redisClient.SetNX(ctx, lockKey, s.instanceID, entry.job.Timeout+time.Minute).Result()
+	if err != nil {
+		slog.Error("Failed to acquire cron lock", "cron_job", name, "error", err)
+		return
+	}
+	if !acquired {
+		s.redisClient.HIncrBy(ctx, cronMetricsKey(name), "skipped", 1)
+		slog.Debug("Cron tick run by another instance", "cron_job", name, "tick", tick)
+		return
+	}
+
+	// A run lasts at most its timeout, so the run lock outlives it without renewal
+	runLockKey := cronRunLockKey(name)
+	running, err := s.locker.Acquire(ctx, runLockKey, entry.job.Timeout+time.Minute)
+	if err != nil {
+		slog.Error("Failed to acquire cron run lock", "cron_job", name, "error", err)
+		return
+	}
+	if !running {
+		s.redisClient.HIncrBy(ctx, cronMetricsKey(name), "overlapped", 1)
+		slog.Warn("Cron tick skipped, the previous run is still going", "cron_job", name, "tick", tick)
+		return
+	}
+
+	slog.Info("Cron job started", "cron_job", name, "tick", tick, "instance_id", s.instanceID)
+	started := time.Now()
+	runErr := s.run(ctx, entry.job)
+	duration := time.Since(started)
+
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+		slog.Error("Cron job failed", "cron_job", name, "duration", duration, "error", runErr)
+	} else {
+		slog.Info("Cron job completed", "cron_job", name, "duration", duration)
+	}
+
+	// Recorded even when the run was cut short by a shutdown
+	metricsCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.locker.Release(metricsCtx, runLockKey); err != nil {
+		slog.Error("Failed to release cron run lock", "cron_job", name, "error", err)
+	}
+	pipe := s.redisClient.TxPipeline()
+	pipe.HIncrBy(metricsCtx, cronMetricsKey(name), "runs", 1)
+	if runErr != nil {
+		pipe.HIncrBy(metricsCtx, cronMetricsKey(name), "failures", 1)
+	}
+	pipe.HSet(metricsCtx, cronMetricsKey(name),
+		"last_run_at", started.Unix(),
+		"last_duration_ms", duration.Milliseconds(),
+		"last_error", lastError,
+		"last_instance", s.instanceID,
+	)
+	if _, err := pipe.Exec(metricsCtx); err != nil {
+		slog.Error("Failed to record cron metrics", "cron_job", name, "error", err)
+	}
+}
+
+// run calls the job with its timeout and recovers a panic into an error
+func (s *CronScheduler) run(ctx context.Context, job CronJob) (runErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			runErr = fmt.Errorf("panic recovered: %v", r)
+		}
+	}()
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+	return job.Run(runCtx)
+}
+
+// Metrics returns the schedule and run counts of every registered job
+func (s *CronScheduler) Metrics(ctx context.Context) ([]CronJobMetrics, error) {
+	s.mu.RLock()
+	entries := make([]*cronEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	metrics := make([]CronJobMetrics, 0, len(entries))
+	for _, entry := range entries {
+		m := CronJobMetrics{
+			Name:      entry.job.Name,
+			Schedule:  entry.job.Schedule,
+			NextRunAt: entry.schedule.Next(now),
+		}
+		if s.redisClient != nil {
+			values, err := s.redisClient.HGetAll(ctx, cronMetricsKey(entry.job.Name)).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get cron metrics: %w", err)
+			}
+			m.Runs, _ = strconv.ParseInt(values["runs"], 10, 64)
+			m.Failures, _ = strconv.ParseInt(values["failures"], 10, 64)
+			m.Skipped, _ = strconv.ParseInt(values["skipped"], 10, 64)
+			m.Overlapped, _ = strconv.ParseInt(values["overlapped"], 10, 64)
+			m.LastDurationMs, _ = strconv.ParseInt(values["last_duration_ms"], 10, 64)
+			m.LastError = values["last_error"]
+			m.LastInstance = values["last_instance"]
+			if lastRun, err := strconv.ParseInt(values["last_run_at"], 10, 64); err == nil {
+				lastRunAt := time.Unix(lastRun, 0)
+				m.LastRunAt = &lastRunAt
+			}
+		}
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics, nil
+}
+
+// cronRunLockKey is held by the instance running a job for as long as the run goes on
+func cronRunLockKey(name string) string {
+	return "cron:running:" + name
+}
+
+func cronMetricsKey(name string) string {
+	return "cron:metrics:" + name
+}
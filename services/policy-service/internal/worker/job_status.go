@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// trackedJobTypes are the jobs whose progress clients poll. Scheduled monitoring jobs run
+// for every policy every day and are left out to keep job_status small.
+var trackedJobTypes = map[string]bool{
+	"document-validation": true,
+	"risk-analysis":       true,
+	"policy-import":       true,
+}
+
+// IsTrackedJobType reports whether the status of a job type is recorded
+func IsTrackedJobType(jobType string) bool {
+	return trackedJobTypes[jobType]
+}
+
+// ClassifiedError is an error that knows its category, such as a Gemini rate limit. The
+// category is recorded with the job status, and a job failing with an error that is not
+// retryable goes to the dead-letter store without waiting for its retries.
+type ClassifiedError interface {
+	error
+	ErrorKind() string
+	Retryable() bool
+}
+
+// classifyJobError returns the classified error in the chain of err, if any
+func classifyJobError(err error) (ClassifiedError, bool) {
+	var classified ClassifiedError
+	if err == nil || !errors.As(err, &classified) {
+		return nil, false
+	}
+	return classified, true
+}
+
+// JobStatusRecorder stores the state changes of tracked jobs
+type JobStatusRecorder interface {
+	RecordJobStatus(ctx context.Context, status *JobStatus) error
+}
+
+// recordJobStatus saves the new state of a job. Tracking must never fail the job itself, so
+// errors are only logged.
+func (p *WorkingPool) recordJobStatus(ctx context.Context, job JobPayload, state JobState, jobErr error) {
+	if p.Statuses == nil || !IsTrackedJobType(job.Type) {
+		return
+	}
+
+	now := time.Now()
+	status := &JobStatus{
+		JobID:       job.JobID,
+		JobType:     job.Type,
+		PoolName:    p.GetName(),
+		Status:      state,
+		SubmittedBy: job.SubmittedBy,
+		RetryCount:  job.RetryCount,
+		Result:      job.Result,
+		QueuedAt:    now,
+	}
+	switch state {
+	case JobStateRunning:
+		status.StartedAt = &now
+	case JobStateSucceeded, JobStateFailed:
+		status.FinishedAt = &now
+	}
+	if jobErr != nil {
+		message := jobErr.Error()
+		status.ErrorMessage = &message
+		if classified, ok := classifyJobError(jobErr); ok {
+			kind := classified.ErrorKind()
+			status.ErrorKind = &kind
+		}
+	}
+
+	// Written even when the job ended on a shutdown
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := p.Statuses.RecordJobStatus(recordCtx, status); err != nil {
+		slog.ErrorContext(ctx, "Failed to record job status",
+			"job_id", job.JobID,
+			"job_type", job.Type,
+			"status", state,
+			"error", err)
+	}
+}
@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// acquireScript takes a free lock, or extends it when the caller holds it already
+var acquireScript = goredis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0`)
+
+// renewScript extends a lock only while the caller still holds it
+var renewScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes a lock only while the caller still holds it
+var releaseScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`)
+
+// Locker takes the Redis locks that keep the instances of the service from doing the same work
+// twice. A lock holds the ID of the instance holding it and expires after its TTL, so the locks of
+// an instance that died are freed on their own. Without Redis there is a single instance and every
+// lock is granted.
+type Locker struct {
+	redisClient *goredis.Client
+	instanceID  string
+}
+
+func NewLocker(redisClient *goredis.Client) *Locker {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "instance"
+	}
+	return &Locker{
+		redisClient: redisClient,
+		instanceID:  fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+	}
+}
+
+// InstanceID identifies this instance as the holder of its locks
+func (l *Locker) InstanceID() string {
+	return l.instanceID
+}
+
+// Acquire takes the lock at key for ttl, or extends it when this instance holds it already. It
+// reports false when another instance holds it.
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if l.redisClient == nil {
+		return true, nil
+	}
+	acquired, err := acquireScript.Run(ctx, l.redisClient, []string{key}, l.instanceID, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	return acquired == 1, nil
+}
+
+// Holder returns the instance holding the lock at key, empty when the lock is free
+func (l *Locker) Holder(ctx context.Context, key string) (string, error) {
+	if l.redisClient == nil {
+		return "", nil
+	}
+	holder, err := l.redisClient.Get(ctx, key).Result()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return "", fmt.Errorf("failed to get lock %s: %w", key, err)
+	}
+	return holder, nil
+}
+
+// Renew extends a lock this instance holds for ttl. It reports false when the lock expired or was
+// taken from this instance.
+func (l *Locker) Renew(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if l.redisClient == nil {
+		return true, nil
+	}
+	renewed, err := renewScript.Run(ctx, l.redisClient, []string{key}, l.instanceID, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lock %s: %w", key, err)
+	}
+	return renewed == 1, nil
+}
+
+// Release frees a lock this instance holds; a lock held by another instance is left alone
+func (l *Locker) Release(ctx context.Context, key string) error {
+	if l.redisClient == nil {
+		return nil
+	}
+	if err := releaseScript.Run(ctx, l.redisClient, []string{key}, l.instanceID).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", key, err)
+	}
+	return nil
+}
+
+// ForceRelease frees a lock whichever instance holds it, so the holder finds it lost at its next
+// renewal
+func (l *Locker) ForceRelease(ctx context.Context, key string) error {
+	if l.redisClient == nil {
+		return nil
+	}
+	if err := l.redisClient.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", key, err)
+	}
+	return nil
+}
+
+// WithLock runs fn while holding the lock at key, renewing it every third of ttl until fn returns.
+// ran is false when another instance holds the lock. The context of fn is cancelled if the lock is
+// lost, since another instance may be doing the same work from then on.
+func (l *Locker) WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) (ran bool, err error) {
+	acquired, err := l.Acquire(ctx, key, ttl)
+	if err != nil || !acquired {
+		return false, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			held, err := l.Renew(runCtx, key, ttl)
+			if err != nil {
+				// Tried again at the next tick, the lock outlives two missed renewals
+				slog.Warn("Failed to renew lock", "lock", key, "error", err)
+				continue
+			}
+			if !held {
+				slog.Error("Lock lost while running, stopping", "lock", key, "instance_id", l.instanceID)
+				cancel()
+				return
+			}
+		}
+	}()
+
+	defer func() {
+		cancel()
+		<-renewed
+		releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer releaseCancel()
+		if err := l.Release(releaseCtx, key); err != nil {
+			slog.Warn("Failed to release lock", "lock", key, "error", err)
+		}
+	}()
+	return true, fn(runCtx)
+}
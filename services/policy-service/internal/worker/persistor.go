@@ -25,6 +25,17 @@ type WorkerPersistor interface {
 	UpdateJobExecution(ctx context.Context, execution *WorkerJobExecution) error
 	GetJobExecutionsByPolicyID(ctx context.Context, policyID uuid.UUID, limit int) ([]*WorkerJobExecution, error)
 
+	// Dead-Letter Store
+	CreateDeadLetterJob(ctx context.Context, job *DeadLetterJob) error
+	GetDeadLetterJob(ctx context.Context, id uuid.UUID) (*DeadLetterJob, error)
+	ListDeadLetterJobs(ctx context.Context, jobType string, includeRequeued bool, limit int) ([]*DeadLetterJob, error)
+	MarkDeadLetterJobRequeued(ctx context.Context, id uuid.UUID) error
+
+	// Job Status Tracking
+	RecordJobStatus(ctx context.Context, status *JobStatus) error
+	GetJobStatus(ctx context.Context, jobID string) (*JobStatus, error)
+	ListJobStatuses(ctx context.Context, filter JobStatusFilter) ([]*JobStatus, error)
+
 	// Disaster Recovery
 	LoadActiveWorkerInfrastructure(ctx context.Context) ([]uuid.UUID, error)
 
@@ -455,6 +455,289 @@ func (p *PostgresPersistor) GetJobExecutionsByPolicyID(ctx context.Context, poli
 	return executions, nil
 }
 
+// Dead-Letter Store
+
+// CreateDeadLetterJob records a job that exhausted its retries
+func (p *PostgresPersistor) CreateDeadLetterJob(ctx context.Context, job *DeadLetterJob) error {
+	query := `
+		INSERT INTO dead_letter_jobs (
+			id, job_id, job_type, pool_name, payload, error_message, retry_count, failed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.FailedAt.IsZero() {
+		job.FailedAt = time.Now()
+	}
+
+	payloadJSON, err := json.Marshal(job.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	_, err = p.db.ExecContext(ctx, query,
+		job.ID,
+		job.JobID,
+		job.JobType,
+		job.PoolName,
+		payloadJSON,
+		job.ErrorMessage,
+		job.RetryCount,
+		job.FailedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter job: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeadLetterJob retrieves a dead letter job by ID
+func (p *PostgresPersistor) GetDeadLetterJob(ctx context.Context, id uuid.UUID) (*DeadLetterJob, error) {
+	query := `
+		SELECT id, job_id, job_type, pool_name, payload, error_message, retry_count,
+		       failed_at, requeued_at, requeue_count
+		FROM dead_letter_jobs
+		WHERE id = $1
+	`
+
+	var job DeadLetterJob
+	var payloadJSON []byte
+
+	err := p.db.QueryRowContext(ctx, query, id).Scan(
+		&job.ID,
+		&job.JobID,
+		&job.JobType,
+		&job.PoolName,
+		&payloadJSON,
+		&job.ErrorMessage,
+		&job.RetryCount,
+		&job.FailedAt,
+		&job.RequeuedAt,
+		&job.RequeueCount,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dead letter job not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter job: %w", err)
+	}
+
+	if err := json.Unmarshal(payloadJSON, &job.Payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ListDeadLetterJobs retrieves the most recent dead letter jobs, optionally of one job type.
+// Jobs already requeued are left out unless includeRequeued is set.
+func (p *PostgresPersistor) ListDeadLetterJobs(ctx context.Context, jobType string, includeRequeued bool, limit int) ([]*DeadLetterJob, error) {
+	query := `
+		SELECT id, job_id, job_type, pool_name, payload, error_message, retry_count,
+		       failed_at, requeued_at, requeue_count
+		FROM dead_letter_jobs
+		WHERE ($1 = '' OR job_type = $1)
+		  AND ($2 OR requeued_at IS NULL)
+		ORDER BY failed_at DESC
+		LIMIT $3
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, jobType, includeRequeued, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letter jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*DeadLetterJob{}
+	for rows.Next() {
+		var job DeadLetterJob
+		var payloadJSON []byte
+
+		err := rows.Scan(
+			&job.ID,
+			&job.JobID,
+			&job.JobType,
+			&job.PoolName,
+			&payloadJSON,
+			&job.ErrorMessage,
+			&job.RetryCount,
+			&job.FailedAt,
+			&job.RequeuedAt,
+			&job.RequeueCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter job: %w", err)
+		}
+
+		if err := json.Unmarshal(payloadJSON, &job.Payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+
+		jobs = append(jobs, &job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dead letter jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// MarkDeadLetterJobRequeued stamps a dead letter job as sent back to its pool
+func (p *PostgresPersistor) MarkDeadLetterJobRequeued(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE dead_letter_jobs SET
+			requeued_at = NOW(),
+			requeue_count = requeue_count + 1
+		WHERE id = $1
+	`
+
+	result, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark dead letter job requeued: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("dead letter job not found: %s", id)
+	}
+
+	return nil
+}
+
+// Job Status Tracking
+
+// A queued write arriving after the worker already picked the job up must not move it back,
+// so "queued" only replaces another state when it starts a new retry
+const recordJobStatusQuery = `
+	INSERT INTO job_status (
+		job_id, job_type, pool_name, status, submitted_by, retry_count, error_message, error_kind,
+		result_resource, result_id, queued_at, started_at, finished_at, updated_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
+	ON CONFLICT (job_id) DO UPDATE SET
+		status = EXCLUDED.status,
+		retry_count = EXCLUDED.retry_count,
+		error_message = CASE WHEN EXCLUDED.status = 'succeeded' THEN NULL
+		                     ELSE COALESCE(EXCLUDED.error_message, job_status.error_message) END,
+		error_kind = CASE WHEN EXCLUDED.status = 'succeeded' THEN NULL
+		                  WHEN EXCLUDED.error_message IS NOT NULL THEN EXCLUDED.error_kind
+		                  ELSE job_status.error_kind END,
+		result_resource = COALESCE(EXCLUDED.result_resource, job_status.result_resource),
+		result_id = COALESCE(EXCLUDED.result_id, job_status.result_id),
+		started_at = COALESCE(job_status.started_at, EXCLUDED.started_at),
+		finished_at = EXCLUDED.finished_at,
+		updated_at = NOW()
+	WHERE EXCLUDED.status <> 'queued'
+	   OR job_status.status = 'queued'
+	   OR EXCLUDED.retry_count > job_status.retry_count
+`
+
+const selectJobStatusColumns = `
+	SELECT job_id, job_type, pool_name, status, submitted_by, retry_count, error_message, error_kind,
+	       result_resource, result_id, queued_at, started_at, finished_at, updated_at
+	FROM job_status
+`
+
+const listJobStatusesQuery = selectJobStatusColumns + `
+	WHERE ($1 = '' OR job_type = $1)
+	  AND ($2 = '' OR status = $2)
+	  AND ($3 = '' OR submitted_by = $3)
+	ORDER BY queued_at DESC
+	LIMIT $4
+`
+
+func recordJobStatusArgs(status *JobStatus) []any {
+	var resultResource, resultID *string
+	if status.Result != nil {
+		resultResource, resultID = &status.Result.Resource, &status.Result.ID
+	}
+	return []any{
+		status.JobID, status.JobType, status.PoolName, status.Status, status.SubmittedBy,
+		status.RetryCount, status.ErrorMessage, status.ErrorKind, resultResource, resultID,
+		status.QueuedAt, status.StartedAt, status.FinishedAt,
+	}
+}
+
+// scanJobStatus reads a row selected with selectJobStatusColumns
+func scanJobStatus(row interface{ Scan(dest ...any) error }) (*JobStatus, error) {
+	var status JobStatus
+	var resultResource, resultID sql.NullString
+
+	err := row.Scan(
+		&status.JobID,
+		&status.JobType,
+		&status.PoolName,
+		&status.Status,
+		&status.SubmittedBy,
+		&status.RetryCount,
+		&status.ErrorMessage,
+		&status.ErrorKind,
+		&resultResource,
+		&resultID,
+		&status.QueuedAt,
+		&status.StartedAt,
+		&status.FinishedAt,
+		&status.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if resultResource.Valid && resultID.Valid {
+		status.Result = &JobResultRef{Resource: resultResource.String, ID: resultID.String}
+	}
+	return &status, nil
+}
+
+// RecordJobStatus creates or advances the status record of a job
+func (p *PostgresPersistor) RecordJobStatus(ctx context.Context, status *JobStatus) error {
+	if _, err := p.db.ExecContext(ctx, recordJobStatusQuery, recordJobStatusArgs(status)...); err != nil {
+		return fmt.Errorf("failed to record job status: %w", err)
+	}
+	return nil
+}
+
+// GetJobStatus retrieves the status of a job by its job ID
+func (p *PostgresPersistor) GetJobStatus(ctx context.Context, jobID string) (*JobStatus, error) {
+	status, err := scanJobStatus(p.db.QueryRowContext(ctx, selectJobStatusColumns+` WHERE job_id = $1`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job status: %w", err)
+	}
+	return status, nil
+}
+
+// ListJobStatuses retrieves job statuses matching the filter, most recently queued first
+func (p *PostgresPersistor) ListJobStatuses(ctx context.Context, filter JobStatusFilter) ([]*JobStatus, error) {
+	rows, err := p.db.QueryContext(ctx, listJobStatusesQuery,
+		filter.JobType, string(filter.Status), filter.SubmittedBy, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []*JobStatus{}
+	for rows.Next() {
+		status, err := scanJobStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job statuses: %w", err)
+	}
+
+	return statuses, nil
+}
+
 // Disaster Recovery
 
 // LoadActiveWorkerInfrastructure loads all active policy IDs
@@ -831,6 +1114,144 @@ func (p *postgresTxPersistor) GetJobExecutionsByPolicyID(ctx context.Context, po
 	return executions, rows.Err()
 }
 
+func (p *postgresTxPersistor) CreateDeadLetterJob(ctx context.Context, job *DeadLetterJob) error {
+	query := `
+		INSERT INTO dead_letter_jobs (
+			id, job_id, job_type, pool_name, payload, error_message, retry_count, failed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.FailedAt.IsZero() {
+		job.FailedAt = time.Now()
+	}
+
+	payloadJSON, _ := json.Marshal(job.Payload)
+	_, err := p.tx.ExecContext(ctx, query,
+		job.ID, job.JobID, job.JobType, job.PoolName, payloadJSON,
+		job.ErrorMessage, job.RetryCount, job.FailedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter job: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresTxPersistor) GetDeadLetterJob(ctx context.Context, id uuid.UUID) (*DeadLetterJob, error) {
+	query := `
+		SELECT id, job_id, job_type, pool_name, payload, error_message, retry_count,
+		       failed_at, requeued_at, requeue_count
+		FROM dead_letter_jobs WHERE id = $1
+	`
+
+	var job DeadLetterJob
+	var payloadJSON []byte
+	err := p.tx.QueryRowContext(ctx, query, id).Scan(
+		&job.ID, &job.JobID, &job.JobType, &job.PoolName, &payloadJSON,
+		&job.ErrorMessage, &job.RetryCount, &job.FailedAt, &job.RequeuedAt, &job.RequeueCount,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dead letter job not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter job: %w", err)
+	}
+
+	json.Unmarshal(payloadJSON, &job.Payload)
+	return &job, nil
+}
+
+func (p *postgresTxPersistor) ListDeadLetterJobs(ctx context.Context, jobType string, includeRequeued bool, limit int) ([]*DeadLetterJob, error) {
+	query := `
+		SELECT id, job_id, job_type, pool_name, payload, error_message, retry_count,
+		       failed_at, requeued_at, requeue_count
+		FROM dead_letter_jobs
+		WHERE ($1 = '' OR job_type = $1)
+		  AND ($2 OR requeued_at IS NULL)
+		ORDER BY failed_at DESC
+		LIMIT $3
+	`
+
+	rows, err := p.tx.QueryContext(ctx, query, jobType, includeRequeued, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letter jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*DeadLetterJob{}
+	for rows.Next() {
+		var job DeadLetterJob
+		var payloadJSON []byte
+
+		err := rows.Scan(&job.ID, &job.JobID, &job.JobType, &job.PoolName, &payloadJSON,
+			&job.ErrorMessage, &job.RetryCount, &job.FailedAt, &job.RequeuedAt, &job.RequeueCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter job: %w", err)
+		}
+
+		json.Unmarshal(payloadJSON, &job.Payload)
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+func (p *postgresTxPersistor) MarkDeadLetterJobRequeued(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE dead_letter_jobs SET requeued_at = NOW(), requeue_count = requeue_count + 1
+		WHERE id = $1
+	`
+
+	result, err := p.tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark dead letter job requeued: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("dead letter job not found: %s", id)
+	}
+	return nil
+}
+
+func (p *postgresTxPersistor) RecordJobStatus(ctx context.Context, status *JobStatus) error {
+	if _, err := p.tx.ExecContext(ctx, recordJobStatusQuery, recordJobStatusArgs(status)...); err != nil {
+		return fmt.Errorf("failed to record job status: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresTxPersistor) GetJobStatus(ctx context.Context, jobID string) (*JobStatus, error) {
+	status, err := scanJobStatus(p.tx.QueryRowContext(ctx, selectJobStatusColumns+` WHERE job_id = $1`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job status: %w", err)
+	}
+	return status, nil
+}
+
+func (p *postgresTxPersistor) ListJobStatuses(ctx context.Context, filter JobStatusFilter) ([]*JobStatus, error) {
+	rows, err := p.tx.QueryContext(ctx, listJobStatusesQuery,
+		filter.JobType, string(filter.Status), filter.SubmittedBy, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []*JobStatus{}
+	for rows.Next() {
+		status, err := scanJobStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
 func (p *postgresTxPersistor) LoadActiveWorkerInfrastructure(ctx context.Context) ([]uuid.UUID, error) {
 	query := `
 		SELECT DISTINCT p.policy_id
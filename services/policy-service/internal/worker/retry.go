@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy decides how a failed job is retried. The delay before retry n is
+// BaseDelay * 2^(n-1), capped at MaxDelay, with jitter so jobs failing together do not retry
+// together. MaxRetries caps the MaxRetries of the jobs themselves.
+type RetryPolicy struct {
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	MaxRetries int
+}
+
+// DefaultRetryPolicy applies to job types without a policy of their own
+var DefaultRetryPolicy = RetryPolicy{
+	BaseDelay:  10 * time.Second,
+	MaxDelay:   10 * time.Minute,
+	MaxRetries: 5,
+}
+
+var jobRetryPolicies = map[string]RetryPolicy{
+	// Gemini quota errors clear slowly, retrying fast only burns the daily quota
+	"document-validation":  {BaseDelay: 1 * time.Minute, MaxDelay: 30 * time.Minute, MaxRetries: 5},
+	"crop-verification":    {BaseDelay: 1 * time.Minute, MaxDelay: 30 * time.Minute, MaxRetries: 5},
+	"land-certificate-ocr": {BaseDelay: 1 * time.Minute, MaxDelay: 30 * time.Minute, MaxRetries: 5},
+	// Satellite and weather providers fail transiently, worth retrying longer
+	"fetch-farm-monitoring-data": {BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, MaxRetries: 10},
+	"farm-imagery":               {BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, MaxRetries: 10},
+	"farm-weather-backfill":      {BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, MaxRetries: 10},
+	// An import is claimed once, retries only help when it fails before the claim
+	"policy-import": {BaseDelay: 1 * time.Minute, MaxDelay: 5 * time.Minute, MaxRetries: 3},
+}
+
+// RetryPolicyFor returns the retry policy of a job type
+func RetryPolicyFor(jobType string) RetryPolicy {
+	if policy, ok := jobRetryPolicies[jobType]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// MaxAttempts is the number of retries a job gets: its own MaxRetries, capped by the policy
+func (p RetryPolicy) MaxAttempts(job JobPayload) int {
+	if p.MaxRetries > 0 && job.MaxRetries > p.MaxRetries {
+		return p.MaxRetries
+	}
+	return job.MaxRetries
+}
+
+// Backoff returns the delay before the given retry, counted from 1
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: half of the delay is kept, the other half is random
+	half := delay / 2
+	return half + rand.N(half+1)
+}
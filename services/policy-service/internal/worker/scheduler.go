@@ -27,26 +27,48 @@ func NewJobScheduler(name string, interval time.Duration, pool Pool) *JobSchedul
 	}
 }
 
+// jobStatusTracker is implemented by pools that record the status of tracked jobs
+type jobStatusTracker interface {
+	recordJobStatus(ctx context.Context, job JobPayload, state JobState, jobErr error)
+}
+
 func (s *JobScheduler) AddJob(job JobPayload) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.Jobs = append(s.Jobs, job)
+	if !job.RunNow {
+		s.mu.Lock()
+		s.Jobs = append(s.Jobs, job)
+		s.mu.Unlock()
 
-	if job.RunNow {
-		submitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		if err := s.Pool.SubmitJob(submitCtx, job); err != nil {
-			slog.Error("Failed to submit job to pool",
-				"scheduler_name", s.Name,
-				"job_id", job.JobID,
-				"job_type", job.Type,
-				"error", err)
-		} else {
-			slog.Info("Job submitted successfully",
-				"scheduler_name", s.Name,
-				"job_id", job.JobID,
-				"job_type", job.Type)
+		// The job waits for the next tick, it is reported as queued from now on
+		if tracker, ok := s.Pool.(jobStatusTracker); ok {
+			tracker.recordJobStatus(context.Background(), job, JobStateQueued, nil)
 		}
-		cancel()
+		return
+	}
+
+	submitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Pool.SubmitJob(submitCtx, job); err != nil {
+		slog.Error("Failed to submit job to pool",
+			"scheduler_name", s.Name,
+			"job_id", job.JobID,
+			"job_type", job.Type,
+			"error", err)
+		// Left to the next tick to try again
+		s.mu.Lock()
+		s.Jobs = append(s.Jobs, job)
+		s.mu.Unlock()
+		return
+	}
+	slog.Info("Job submitted successfully",
+		"scheduler_name", s.Name,
+		"job_id", job.JobID,
+		"job_type", job.Type)
+
+	// A one-time job has had its run; running it again at the next tick would do the work twice
+	if !job.OneTime {
+		s.mu.Lock()
+		s.Jobs = append(s.Jobs, job)
+		s.mu.Unlock()
 	}
 }
 
@@ -84,7 +106,10 @@ func (s *JobScheduler) submitJobs(ctx context.Context) {
 
 	newJobs := make([]JobPayload, 0, len(jobsToRun))
 	for _, job := range jobsToRun {
-		job.JobID = uuid.NewString()
+		// A one-time job keeps the ID it was added with so its status can be looked up by it
+		if !job.OneTime {
+			job.JobID = uuid.NewString()
+		}
 		job.RetryCount = 0
 
 		// Use a short timeout for the submit itself
@@ -37,6 +37,16 @@ const (
 	JobStatusRetrying  WorkerJobStatus = "retrying"
 )
 
+// JobState is the progress of a tracked job as reported to clients
+type JobState string
+
+const (
+	JobStateQueued    JobState = "queued"    // Waiting in the pool, also between retries
+	JobStateRunning   JobState = "running"   // Picked up by a worker
+	JobStateSucceeded JobState = "succeeded" // Finished, the result can be read
+	JobStateFailed    JobState = "failed"    // Out of retries, moved to the dead-letter store
+)
+
 // WorkerPoolState represents the persisted state of a worker pool
 type WorkerPoolState struct {
 	PolicyID      uuid.UUID        `db:"policy_id" json:"policy_id"`
@@ -84,6 +94,60 @@ type WorkerJobExecution struct {
 	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
 }
 
+// DeadLetterJob is a job that failed all of its retries, kept so it can be inspected and requeued
+type DeadLetterJob struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	JobID        string     `db:"job_id" json:"job_id"`
+	JobType      string     `db:"job_type" json:"job_type"`
+	PoolName     string     `db:"pool_name" json:"pool_name"`
+	Payload      JobPayload `db:"-" json:"payload"`
+	ErrorMessage string     `db:"error_message" json:"error_message"`
+	RetryCount   int        `db:"retry_count" json:"retry_count"`
+	FailedAt     time.Time  `db:"failed_at" json:"failed_at"`
+	RequeuedAt   *time.Time `db:"requeued_at" json:"requeued_at,omitempty"`
+	RequeueCount int        `db:"requeue_count" json:"requeue_count"`
+}
+
+// JobStatus is the latest known state of a tracked job
+type JobStatus struct {
+	JobID        string        `db:"job_id" json:"job_id"`
+	JobType      string        `db:"job_type" json:"job_type"`
+	PoolName     string        `db:"pool_name" json:"pool_name"`
+	Status       JobState      `db:"status" json:"status"`
+	SubmittedBy  string        `db:"submitted_by" json:"submitted_by,omitempty"`
+	RetryCount   int           `db:"retry_count" json:"retry_count"`
+	ErrorMessage *string       `db:"error_message" json:"error_message,omitempty"`
+	ErrorKind    *string       `db:"error_kind" json:"error_kind,omitempty"`
+	Result       *JobResultRef `db:"-" json:"result,omitempty"`
+	QueuedAt     time.Time     `db:"queued_at" json:"queued_at"`
+	StartedAt    *time.Time    `db:"started_at" json:"started_at,omitempty"`
+	FinishedAt   *time.Time    `db:"finished_at" json:"finished_at,omitempty"`
+	UpdatedAt    time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// JobStatusFilter narrows a listing of job statuses; empty fields match everything
+type JobStatusFilter struct {
+	JobType     string
+	Status      JobState
+	SubmittedBy string
+	Limit       int
+}
+
+// CronJobMetrics describes a cron job and its runs across all instances
+type CronJobMetrics struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	Skipped        int64      `json:"skipped"`    // Ticks an instance lost to the one that ran them
+	Overlapped     int64      `json:"overlapped"` // Ticks not run because the previous run still went on
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastInstance   string     `json:"last_instance,omitempty"`
+}
+
 // WorkerInfrastructureConfig contains configuration for creating worker infrastructure
 type WorkerInfrastructureConfig struct {
 	PolicyID             uuid.UUID
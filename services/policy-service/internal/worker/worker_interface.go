@@ -20,6 +20,18 @@ type JobPayload struct {
 	RetryCount int            `json:"retry_count"`
 	OneTime    bool           `json:"one_time"`
 	RunNow     bool           `json:"run_now"`
+	// ID of the request the job was created for, added to the logs of its runs
+	RequestID string `json:"request_id,omitempty"`
+	// User the job was submitted for, who may poll its status
+	SubmittedBy string `json:"submitted_by,omitempty"`
+	// Where the outcome of the job can be read once it succeeded
+	Result *JobResultRef `json:"result,omitempty"`
+}
+
+// JobResultRef points at the record a job produces, e.g. the validation of a base policy
+type JobResultRef struct {
+	Resource string `json:"resource"`
+	ID       string `json:"id"`
 }
 
 type Pool interface {
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"policy-service/internal/database/redis"
@@ -16,6 +17,20 @@ import (
 
 var AIWorkerPoolUUID *uuid.UUID
 
+// ImportWorkerPoolUUID is the pool running bulk policy imports
+var ImportWorkerPoolUUID *uuid.UUID
+
+// ScanWorkerPoolUUID is the pool scanning uploaded documents
+var ScanWorkerPoolUUID *uuid.UUID
+
+// ErrPolicyRunElsewhere is returned when the worker infrastructure of a policy is created while
+// another instance runs it
+var ErrPolicyRunElsewhere = errors.New("invalid operation: the worker infrastructure of the policy runs on another instance")
+
+// policyOwnershipTTL is how long the instance running the worker infrastructure of a policy keeps
+// it once it stops renewing, after which another instance recovers the policy
+const policyOwnershipTTL = 90 * time.Second
+
 // WorkerManagerV2 is the refactored worker manager with persistence and lifecycle management
 type WorkerManagerV2 struct {
 	// Pool and scheduler storage by policy ID
@@ -45,6 +60,14 @@ type WorkerManagerV2 struct {
 	jobHandlers map[string]func(map[string]any) error
 	handlersMu  sync.RWMutex
 
+	// Periodic tasks shared by all instances
+	cron *CronScheduler
+
+	// Locks shared by all instances, and the policies whose worker infrastructure this instance
+	// holds the ownership lock of
+	locker        *Locker
+	ownedPolicies map[uuid.UUID]struct{}
+
 	// Wait group for graceful shutdown
 	wg *sync.WaitGroup
 }
@@ -53,7 +76,13 @@ type WorkerManagerV2 struct {
 func NewWorkerManagerV2(db *sqlx.DB, redisClient *redis.Client) *WorkerManagerV2 {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &WorkerManagerV2{
+	var goRedisClient *goredis.Client
+	if redisClient != nil {
+		goRedisClient = redisClient.GetClient()
+	}
+
+	locker := NewLocker(goRedisClient)
+	m := &WorkerManagerV2{
 		pools:            make(map[uuid.UUID]Pool),
 		schedulers:       make(map[uuid.UUID]*JobScheduler),
 		poolsByName:      make(map[string]Pool),
@@ -65,8 +94,15 @@ func NewWorkerManagerV2(db *sqlx.DB, redisClient *redis.Client) *WorkerManagerV2
 		db:               db,
 		persistor:        NewPostgresPersistor(db),
 		jobHandlers:      make(map[string]func(map[string]any) error),
+		cron:             NewCronScheduler(goRedisClient, locker),
+		locker:           locker,
+		ownedPolicies:    make(map[uuid.UUID]struct{}),
 		wg:               new(sync.WaitGroup),
 	}
+
+	m.wg.Add(1)
+	go m.renewPolicyOwnership(ctx)
+	return m
 }
 
 // RegisterJobHandler registers a job handler function
@@ -125,6 +161,15 @@ func (m *WorkerManagerV2) CreatePolicyWorkerInfrastructure(
 		return fmt.Errorf("unsupported monitor frequency unit: %s", basePolicyTrigger.MonitorFrequencyUnit)
 	}
 
+	// A policy is run by a single instance, or its jobs would be scheduled once per instance
+	owned, err := m.locker.Acquire(ctx, policyOwnershipKey(registeredPolicy.ID), policyOwnershipTTL)
+	if err != nil {
+		return fmt.Errorf("failed to claim worker infrastructure: %w", err)
+	}
+	if !owned {
+		return ErrPolicyRunElsewhere
+	}
+
 	// 1. Create pool
 	poolName := fmt.Sprintf("policy-%s-pool", registeredPolicy.ID)
 
@@ -142,14 +187,18 @@ func (m *WorkerManagerV2) CreatePolicyWorkerInfrastructure(
 		len(basePolicyCondition),
 		-1,
 	)
+	pool.DeadLetters = m.persistor
+	pool.Statuses = m.persistor
 
 	// Register job handler for farm monitoring data fetch
 	handler, exists := m.GetJobHandler("fetch-farm-monitoring-data")
 	if !exists {
+		m.releasePolicyOwnership(ctx, registeredPolicy.ID)
 		return fmt.Errorf("job handler not registered: fetch-farm-monitoring-data")
 	}
 	riskHandler, exists := m.GetJobHandler("risk-analysis")
 	if !exists {
+		m.releasePolicyOwnership(ctx, registeredPolicy.ID)
 		return fmt.Errorf("job handler not registered: risk-analysis")
 	}
 
@@ -167,6 +216,7 @@ func (m *WorkerManagerV2) CreatePolicyWorkerInfrastructure(
 	m.poolsByName[poolName] = pool
 	m.schedulers[registeredPolicy.ID] = scheduler
 	m.schedulersByName[schedulerName] = scheduler
+	m.ownedPolicies[registeredPolicy.ID] = struct{}{}
 	m.mu.Unlock()
 
 	slog.Info("Worker infrastructure created successfully",
@@ -250,8 +300,16 @@ func (m *WorkerManagerV2) StopWorkerInfrastructure(ctx context.Context, poolID u
 	return nil
 }
 
-// ArchiveWorkerInfrastructure archives pool + scheduler for expired policy
+// ArchiveWorkerInfrastructure archives pool + scheduler for expired policy. The instance running
+// them, when it is another one, stops them once it finds its ownership of the policy gone.
 func (m *WorkerManagerV2) ArchiveWorkerInfrastructure(ctx context.Context, policyID uuid.UUID) error {
+	m.archive(ctx, policyID)
+	m.disownPolicy(ctx, policyID)
+	return nil
+}
+
+// archive stops and forgets the pool + scheduler of a policy run by this instance
+func (m *WorkerManagerV2) archive(ctx context.Context, policyID uuid.UUID) {
 	slog.Info("Archiving worker infrastructure", "policy_id", policyID)
 
 	// First stop if not already stopped
@@ -277,10 +335,9 @@ func (m *WorkerManagerV2) ArchiveWorkerInfrastructure(ctx context.Context, polic
 	if _, exists := m.poolCancels[policyID]; exists {
 		delete(m.poolCancels, policyID)
 	}
+	delete(m.ownedPolicies, policyID)
 
 	slog.Info("Worker infrastructure archived successfully", "policy_id", policyID)
-
-	return nil
 }
 
 func (m *WorkerManagerV2) CreateAIWorkerInfrastructure(ctx context.Context) (*uuid.UUID, error) {
@@ -306,6 +363,8 @@ func (m *WorkerManagerV2) CreateAIWorkerInfrastructure(ctx context.Context) (*uu
 		1,
 		100,
 	)
+	pool.DeadLetters = m.persistor
+	pool.Statuses = m.persistor
 
 	// Register job handler for farm monitoring data fetch
 	handler, exists := m.GetJobHandler("document-validation")
@@ -313,6 +372,9 @@ func (m *WorkerManagerV2) CreateAIWorkerInfrastructure(ctx context.Context) (*uu
 		return nil, fmt.Errorf("job handler not registered: document-validation")
 	}
 	pool.RegisterJob("document-validation", handler)
+	if ocrHandler, exists := m.GetJobHandler("land-certificate-ocr"); exists {
+		pool.RegisterJob("land-certificate-ocr", ocrHandler)
+	}
 
 	schedulerName := "AI-JobScheduler"
 
@@ -371,6 +433,90 @@ func (m *WorkerManagerV2) StartAIWorkerInfrastructure(ctx context.Context, poolI
 	return nil
 }
 
+// CreatePolicyImportWorkerInfrastructure creates the pool for bulk policy imports. A single
+// worker runs the imports one after another, each one registers many policies in a row.
+func (m *WorkerManagerV2) CreatePolicyImportWorkerInfrastructure(ctx context.Context) (*uuid.UUID, error) {
+	poolName := "Import-JobPool"
+
+	var goRedisClient *goredis.Client
+	if m.redisClient != nil {
+		goRedisClient = m.redisClient.GetClient()
+	}
+
+	pool := NewWorkingPool(
+		1,
+		poolName,
+		30*time.Minute,
+		goRedisClient,
+		1,
+		1,
+		0,
+	)
+	pool.DeadLetters = m.persistor
+	pool.Statuses = m.persistor
+
+	handler, exists := m.GetJobHandler("policy-import")
+	if !exists {
+		return nil, fmt.Errorf("job handler not registered: policy-import")
+	}
+	pool.RegisterJob("policy-import", handler)
+
+	schedulerName := "Import-JobScheduler"
+	scheduler := NewJobScheduler(schedulerName, 1*time.Minute, pool)
+
+	importUUID := uuid.New()
+	m.mu.Lock()
+	m.pools[importUUID] = pool
+	m.poolsByName[poolName] = pool
+	m.schedulers[importUUID] = scheduler
+	m.schedulersByName[schedulerName] = scheduler
+	m.mu.Unlock()
+
+	return &importUUID, nil
+}
+
+// CreateDocumentScanWorkerInfrastructure creates the pool scanning uploaded files. Scans are
+// submitted to run right away, the scheduler tick only picks up the odd job added without RunNow.
+func (m *WorkerManagerV2) CreateDocumentScanWorkerInfrastructure(ctx context.Context) (*uuid.UUID, error) {
+	poolName := "Scan-JobPool"
+
+	var goRedisClient *goredis.Client
+	if m.redisClient != nil {
+		goRedisClient = m.redisClient.GetClient()
+	}
+
+	pool := NewWorkingPool(
+		2,
+		poolName,
+		5*time.Minute,
+		goRedisClient,
+		5,
+		5,
+		0,
+	)
+	pool.DeadLetters = m.persistor
+	pool.Statuses = m.persistor
+
+	handler, exists := m.GetJobHandler("document-scan")
+	if !exists {
+		return nil, fmt.Errorf("job handler not registered: document-scan")
+	}
+	pool.RegisterJob("document-scan", handler)
+
+	schedulerName := "Scan-JobScheduler"
+	scheduler := NewJobScheduler(schedulerName, 1*time.Minute, pool)
+
+	scanUUID := uuid.New()
+	m.mu.Lock()
+	m.pools[scanUUID] = pool
+	m.poolsByName[poolName] = pool
+	m.schedulers[scanUUID] = scheduler
+	m.schedulersByName[schedulerName] = scheduler
+	m.mu.Unlock()
+
+	return &scanUUID, nil
+}
+
 func (m *WorkerManagerV2) CreateFarmImageryWorkerInfrastructure(ctx context.Context, farmID uuid.UUID) (*uuid.UUID, error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -394,6 +540,8 @@ func (m *WorkerManagerV2) CreateFarmImageryWorkerInfrastructure(ctx context.Cont
 		1,
 		-1,
 	)
+	pool.DeadLetters = m.persistor
+	pool.Statuses = m.persistor
 
 	// Register job handler for farm monitoring data fetch
 	handler, exists := m.GetJobHandler("farm-imagery")
@@ -401,6 +549,12 @@ func (m *WorkerManagerV2) CreateFarmImageryWorkerInfrastructure(ctx context.Cont
 		return nil, fmt.Errorf("job handler not registered: farm-imagery")
 	}
 	pool.RegisterJob("farm-imagery", handler)
+	if backfillHandler, exists := m.GetJobHandler("farm-weather-backfill"); exists {
+		pool.RegisterJob("farm-weather-backfill", backfillHandler)
+	}
+	if cropHandler, exists := m.GetJobHandler("crop-verification"); exists {
+		pool.RegisterJob("crop-verification", cropHandler)
+	}
 
 	schedulerName := fmt.Sprintf("farm-imagery-%s", farmID)
 
@@ -517,8 +671,8 @@ func (m *WorkerManagerV2) ManagerContext() context.Context {
 	return m.managerCtx
 }
 
-// Shutdown gracefully shuts down all worker infrastructure
-func (m *WorkerManagerV2) Shutdown() {
+// Shutdown stops all worker infrastructure, letting the jobs in progress finish until ctx expires
+func (m *WorkerManagerV2) Shutdown(ctx context.Context) {
 	slog.Info("Shutting down worker manager")
 
 	m.mu.RLock()
@@ -529,9 +683,6 @@ func (m *WorkerManagerV2) Shutdown() {
 	m.mu.RUnlock()
 
 	// Stop all infrastructure
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	for _, policyID := range policyIDs {
 		if err := m.StopWorkerInfrastructure(ctx, policyID); err != nil {
 			slog.Error("Failed to stop worker infrastructure during shutdown",
@@ -546,9 +697,83 @@ func (m *WorkerManagerV2) Shutdown() {
 	// Wait for all goroutines
 	m.wg.Wait()
 
+	// Let the other instances recover the policies of this one right away
+	m.releaseOwnedPolicies(ctx)
+
 	slog.Info("Worker manager shutdown complete")
 }
 
+// ListDeadLetterJobs returns the jobs that failed all of their retries, most recent first
+func (m *WorkerManagerV2) ListDeadLetterJobs(ctx context.Context, jobType string, includeRequeued bool, limit int) ([]*DeadLetterJob, error) {
+	return m.persistor.ListDeadLetterJobs(ctx, jobType, includeRequeued, limit)
+}
+
+// RequeueDeadLetterJob submits a dead letter job to its pool again with a fresh retry budget.
+// A job can be requeued once; if it fails again it comes back as a new dead letter job.
+func (m *WorkerManagerV2) RequeueDeadLetterJob(ctx context.Context, id uuid.UUID) (*JobPayload, error) {
+	deadLetter, err := m.persistor.GetDeadLetterJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter.RequeuedAt != nil {
+		return nil, fmt.Errorf("invalid request: dead letter job %s was already requeued", id)
+	}
+
+	pool, exists := m.GetPool(deadLetter.PoolName)
+	if !exists {
+		return nil, fmt.Errorf("pool not found: %s is not running", deadLetter.PoolName)
+	}
+
+	job := deadLetter.Payload
+	job.JobID = uuid.NewString()
+	job.RetryCount = 0
+	if err := pool.SubmitJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	if err := m.persistor.MarkDeadLetterJobRequeued(ctx, id); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Dead letter job requeued",
+		"dead_letter_id", id,
+		"job_id", job.JobID,
+		"job_type", job.Type,
+		"pool_name", deadLetter.PoolName)
+	return &job, nil
+}
+
+// GetJobStatus returns the latest state of a tracked job
+func (m *WorkerManagerV2) GetJobStatus(ctx context.Context, jobID string) (*JobStatus, error) {
+	return m.persistor.GetJobStatus(ctx, jobID)
+}
+
+// ListJobStatuses returns tracked jobs matching the filter, most recently queued first
+func (m *WorkerManagerV2) ListJobStatuses(ctx context.Context, filter JobStatusFilter) ([]*JobStatus, error) {
+	return m.persistor.ListJobStatuses(ctx, filter)
+}
+
+// Locker returns the locks shared by all instances of the service
+func (m *WorkerManagerV2) Locker() *Locker {
+	return m.locker
+}
+
+// RegisterCronJob schedules a periodic task, run by one instance of the service per tick
+func (m *WorkerManagerV2) RegisterCronJob(job CronJob) error {
+	return m.cron.Register(job)
+}
+
+// StartCronScheduler starts running the cron jobs until the manager shuts down
+func (m *WorkerManagerV2) StartCronScheduler() {
+	m.wg.Add(1)
+	go m.cron.Start(m.managerCtx, m.wg)
+}
+
+// GetCronJobMetrics returns the schedule and run counts of the cron jobs
+func (m *WorkerManagerV2) GetCronJobMetrics(ctx context.Context) ([]CronJobMetrics, error) {
+	return m.cron.Metrics(ctx)
+}
+
 // GetPersistor returns the persistor (for testing)
 func (m *WorkerManagerV2) GetPersistor() WorkerPersistor {
 	return m.persistor
@@ -589,6 +814,10 @@ func (m *WorkerManagerV2) CleanupWorkerInfrastructure(
 	if _, exists := m.poolCancels[policyID]; exists {
 		delete(m.poolCancels, policyID)
 	}
+	delete(m.ownedPolicies, policyID)
+
+	// The instance running the policy, when it is another one, stops it at its next renewal
+	m.disownPolicy(ctx, policyID)
 
 	// Delete all database records for this worker infrastructure
 	//	if err := m.persistor.DeleteWorkerInfrastructure(ctx, policyID); err != nil {
@@ -632,3 +861,91 @@ func (m *WorkerManagerV2) CleanupWorkerInfrastructure(
 
 	return nil
 }
+
+// ============================================================================
+// POLICY OWNERSHIP
+// ============================================================================
+
+func policyOwnershipKey(policyID uuid.UUID) string {
+	return "worker:owner:policy:" + policyID.String()
+}
+
+// PolicyRunElsewhere reports whether another instance runs the worker infrastructure of a policy
+func (m *WorkerManagerV2) PolicyRunElsewhere(ctx context.Context, policyID uuid.UUID) (bool, error) {
+	holder, err := m.locker.Holder(ctx, policyOwnershipKey(policyID))
+	if err != nil {
+		return false, err
+	}
+	return holder != "" && holder != m.locker.InstanceID(), nil
+}
+
+// renewPolicyOwnership keeps the ownership of the policies this instance runs until the manager
+// shuts down. A policy whose ownership was lost, ended by another instance or taken over after
+// renewals failed for too long, is stopped here so it does not run on two instances.
+func (m *WorkerManagerV2) renewPolicyOwnership(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(policyOwnershipTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.RLock()
+		policyIDs := make([]uuid.UUID, 0, len(m.ownedPolicies))
+		for policyID := range m.ownedPolicies {
+			policyIDs = append(policyIDs, policyID)
+		}
+		m.mu.RUnlock()
+
+		for _, policyID := range policyIDs {
+			owned, err := m.locker.Renew(ctx, policyOwnershipKey(policyID), policyOwnershipTTL)
+			if err != nil {
+				// Tried again at the next tick, the ownership outlives two missed renewals
+				slog.Warn("Failed to renew policy ownership", "policy_id", policyID, "error", err)
+				continue
+			}
+			if !owned {
+				slog.Warn("Policy ownership lost, stopping its worker infrastructure",
+					"policy_id", policyID,
+					"instance_id", m.locker.InstanceID())
+				m.archive(ctx, policyID)
+			}
+		}
+	}
+}
+
+// releasePolicyOwnership gives up a policy this instance claimed but does not run
+func (m *WorkerManagerV2) releasePolicyOwnership(ctx context.Context, policyID uuid.UUID) {
+	if err := m.locker.Release(ctx, policyOwnershipKey(policyID)); err != nil {
+		slog.Warn("Failed to release policy ownership", "policy_id", policyID, "error", err)
+	}
+}
+
+// disownPolicy ends the ownership of a policy whichever instance holds it
+func (m *WorkerManagerV2) disownPolicy(ctx context.Context, policyID uuid.UUID) {
+	if err := m.locker.ForceRelease(ctx, policyOwnershipKey(policyID)); err != nil {
+		slog.Warn("Failed to release policy ownership", "policy_id", policyID, "error", err)
+	}
+}
+
+// releaseOwnedPolicies gives up the ownership of every policy this instance runs
+func (m *WorkerManagerV2) releaseOwnedPolicies(ctx context.Context) {
+	m.mu.Lock()
+	policyIDs := make([]uuid.UUID, 0, len(m.ownedPolicies))
+	for policyID := range m.ownedPolicies {
+		policyIDs = append(policyIDs, policyID)
+	}
+	m.ownedPolicies = make(map[uuid.UUID]struct{})
+	m.mu.Unlock()
+
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	for _, policyID := range policyIDs {
+		m.releasePolicyOwnership(releaseCtx, policyID)
+	}
+	slog.Info("Released policy ownership", "count", len(policyIDs))
+}
@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"agrisa_utils/logging"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -13,11 +14,20 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// DeadLetterRecorder keeps the jobs a pool gave up on. It is optional; without it failed jobs
+// only land in the Redis DLQ list.
+type DeadLetterRecorder interface {
+	CreateDeadLetterJob(ctx context.Context, job *DeadLetterJob) error
+}
+
 type WorkingPool struct {
 	NumWorkers          int
 	QueueName           string // e.g., "queue:general:pending"
 	RunningQueueName    string // e.g., "queue:general:running"
 	DeadLetterQueueName string // e.g., "queue:general:dlq"
+	DelayedQueueName    string // e.g., "queue:general:delayed", sorted set scored by due time in ms
+	DeadLetters         DeadLetterRecorder
+	Statuses            JobStatusRecorder
 	JobTimeout          time.Duration
 	RedisClient         *redis.Client
 	dispatcher          map[string]func(map[string]any) error
@@ -40,6 +50,7 @@ func NewWorkingPool(
 		QueueName:           queueNameBase + ":pending",
 		RunningQueueName:    queueNameBase + ":running",
 		DeadLetterQueueName: queueNameBase + ":dlq",
+		DelayedQueueName:    queueNameBase + ":delayed",
 		JobTimeout:          jobTimeout,
 		RedisClient:         redisClient,
 		dispatcher:          make(map[string]func(map[string]any) error),
@@ -65,7 +76,11 @@ func (p *WorkingPool) SubmitJob(ctx context.Context, job JobPayload) error {
 		return fmt.Errorf("failed to marshal job: %w", err)
 	}
 
-	return p.RedisClient.LPush(ctx, p.QueueName, payload).Err()
+	if err := p.RedisClient.LPush(ctx, p.QueueName, payload).Err(); err != nil {
+		return err
+	}
+	p.recordJobStatus(ctx, job, JobStateQueued, nil)
+	return nil
 }
 
 func (p *WorkingPool) Start(ctx context.Context, managerWg *sync.WaitGroup) {
@@ -93,6 +108,9 @@ func (p *WorkingPool) Start(ctx context.Context, managerWg *sync.WaitGroup) {
 		go p.worker(ctx, &workerWg, i+1)
 	}
 
+	workerWg.Add(1)
+	go p.promoteDelayedJobs(ctx, &workerWg)
+
 	<-ctx.Done()
 	workerWg.Wait()
 	slog.Info("Working pool stopped, all workers exited", "queue_name", p.QueueName)
@@ -218,21 +236,25 @@ func (p *WorkingPool) dispatchJob(ctx context.Context, payload string, workerID
 		return fmt.Errorf("failed to unmarshal job: %w", err)
 	}
 
+	// Runs of a job created for a request are logged under its request ID
+	ctx = logging.WithRequestID(ctx, jobData.RequestID)
+
 	jobFunc, exists := p.dispatcher[jobData.Type]
 	if !exists {
-		slog.Error("Unknown job type",
+		slog.ErrorContext(ctx, "Unknown job type",
 			"worker_id", workerID,
 			"job_id", jobData.JobID,
 			"job_type", jobData.Type)
 		return fmt.Errorf("unknown job type: %s", jobData.Type)
 	}
 
-	slog.Info("Executing job",
+	slog.InfoContext(ctx, "Executing job",
 		"worker_id", workerID,
 		"job_id", jobData.JobID,
 		"job_type", jobData.Type,
 		"retry_count", jobData.RetryCount,
 		"max_retries", jobData.MaxRetries)
+	p.recordJobStatus(ctx, jobData, JobStateRunning, nil)
 
 	jobCtx, cancel := context.WithTimeout(ctx, p.JobTimeout)
 	defer cancel()
@@ -246,13 +268,13 @@ func (p *WorkingPool) dispatchJob(ctx context.Context, payload string, workerID
 	case err := <-done:
 		// Job finished, pass the error (or nil) up.
 		if err != nil {
-			slog.Error("Job execution failed",
+			slog.ErrorContext(ctx, "Job execution failed",
 				"worker_id", workerID,
 				"job_id", jobData.JobID,
 				"job_type", jobData.Type,
 				"error", err)
 		} else {
-			slog.Info("Job execution completed successfully",
+			slog.InfoContext(ctx, "Job execution completed successfully",
 				"worker_id", workerID,
 				"job_id", jobData.JobID,
 				"job_type", jobData.Type)
@@ -260,7 +282,7 @@ func (p *WorkingPool) dispatchJob(ctx context.Context, payload string, workerID
 		return err
 	case <-jobCtx.Done():
 		// Job timed out.
-		slog.Error("Job timed out",
+		slog.ErrorContext(ctx, "Job timed out",
 			"worker_id", workerID,
 			"job_id", jobData.JobID,
 			"job_type", jobData.Type,
@@ -268,7 +290,7 @@ func (p *WorkingPool) dispatchJob(ctx context.Context, payload string, workerID
 		return fmt.Errorf("job timed out after %v", p.JobTimeout)
 	case <-ctx.Done():
 		// Global shutdown signaled *during* job execution.
-		slog.Warn("Job cancelled by global shutdown",
+		slog.WarnContext(ctx, "Job cancelled by global shutdown",
 			"worker_id", workerID,
 			"job_id", jobData.JobID,
 			"job_type", jobData.Type)
@@ -294,53 +316,141 @@ func (p *WorkingPool) handleJobResult(
 		// If this fails, we're in big trouble.
 	}
 
-	if jobErr == nil {
-		return // Success! Already logged in dispatchJob
-	}
-
-	// --- Job Failed. Handle Retry/DLQ ---
 	var jobData JobPayload
 	if err := json.Unmarshal([]byte(jobPayload), &jobData); err != nil {
-		slog.Error("CRITICAL: Failed to unmarshal failed job, dropping it",
+		slog.Error("CRITICAL: Failed to unmarshal finished job, dropping it",
 			"worker_id", workerID,
 			"error", err)
 		return
 	}
 
-	if jobData.RetryCount < jobData.MaxRetries {
+	// Retries are logged under the request ID of the job as well
+	ctx = logging.WithRequestID(ctx, jobData.RequestID)
+
+	if jobErr == nil {
+		// Success! Already logged in dispatchJob
+		p.recordJobStatus(ctx, jobData, JobStateSucceeded, nil)
+		return
+	}
+
+	// --- Job Failed. Handle Retry/DLQ ---
+
+	policy := RetryPolicyFor(jobData.Type)
+	retryable := true
+	if classified, ok := classifyJobError(jobErr); ok && !classified.Retryable() {
+		retryable = false
+		slog.WarnContext(ctx, "Job failed with an error that is not retryable",
+			"worker_id", workerID,
+			"job_id", jobData.JobID,
+			"job_type", jobData.Type,
+			"error_kind", classified.ErrorKind())
+	}
+	if retryable && jobData.RetryCount < policy.MaxAttempts(jobData) {
 		jobData.RetryCount++
+		delay := policy.Backoff(jobData.RetryCount)
 		newPayload, _ := json.Marshal(jobData)
-		slog.Info("Retrying job",
+		slog.InfoContext(ctx, "Retrying job after backoff",
 			"worker_id", workerID,
 			"job_id", jobData.JobID,
 			"job_type", jobData.Type,
 			"retry_count", jobData.RetryCount,
-			"max_retries", jobData.MaxRetries)
-
-		err := p.RedisClient.LPush(ctx, p.QueueName, newPayload).Err()
+			"max_retries", policy.MaxAttempts(jobData),
+			"delay", delay)
+
+		// The job waits in the delayed set until promoteDelayedJobs moves it back to pending
+		err := p.RedisClient.ZAdd(ctx, p.DelayedQueueName, redis.Z{
+			Score:  float64(time.Now().Add(delay).UnixMilli()),
+			Member: newPayload,
+		}).Err()
 		if err != nil {
-			slog.Error("CRITICAL: Failed to requeue job for retry",
+			slog.ErrorContext(ctx, "CRITICAL: Failed to schedule job for retry",
 				"worker_id", workerID,
 				"job_id", jobData.JobID,
 				"job_type", jobData.Type,
 				"error", err)
 		}
-	} else {
-		// Max retries hit: Move to Dead-Letter Queue
-		slog.Warn("Job exceeded max retries, moving to DLQ",
+		p.recordJobStatus(ctx, jobData, JobStateQueued, jobErr)
+		return
+	}
+
+	// Max retries hit: Move to Dead-Letter Queue
+	slog.WarnContext(ctx, "Job exceeded max retries, moving to DLQ",
+		"worker_id", workerID,
+		"job_id", jobData.JobID,
+		"job_type", jobData.Type,
+		"retry_count", jobData.RetryCount,
+		"dlq", p.DeadLetterQueueName)
+	err := p.RedisClient.LPush(ctx, p.DeadLetterQueueName, jobPayload).Err()
+	if err != nil {
+		slog.ErrorContext(ctx, "CRITICAL: Failed to move job to DLQ",
 			"worker_id", workerID,
 			"job_id", jobData.JobID,
 			"job_type", jobData.Type,
-			"retry_count", jobData.RetryCount,
-			"dlq", p.DeadLetterQueueName)
-		err := p.RedisClient.LPush(ctx, p.DeadLetterQueueName, jobPayload).Err()
+			"dlq", p.DeadLetterQueueName,
+			"error", err)
+	}
+	p.recordJobStatus(ctx, jobData, JobStateFailed, jobErr)
+
+	if p.DeadLetters == nil {
+		return
+	}
+	deadLetter := &DeadLetterJob{
+		JobID:        jobData.JobID,
+		JobType:      jobData.Type,
+		PoolName:     p.GetName(),
+		Payload:      jobData,
+		ErrorMessage: jobErr.Error(),
+		RetryCount:   jobData.RetryCount,
+	}
+	// The job may have failed on a shutdown, the record is written regardless
+	if err := p.DeadLetters.CreateDeadLetterJob(context.WithoutCancel(ctx), deadLetter); err != nil {
+		slog.ErrorContext(ctx, "Failed to record dead letter job",
+			"worker_id", workerID,
+			"job_id", jobData.JobID,
+			"job_type", jobData.Type,
+			"error", err)
+	}
+}
+
+// promoteDelayedJobs moves jobs whose backoff has elapsed from the delayed set to "pending".
+func (p *WorkingPool) promoteDelayedJobs(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		due, err := p.RedisClient.ZRangeByScore(ctx, p.DelayedQueueName, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   fmt.Sprintf("%d", time.Now().UnixMilli()),
+			Count: 100,
+		}).Result()
 		if err != nil {
-			slog.Error("CRITICAL: Failed to move job to DLQ",
-				"worker_id", workerID,
-				"job_id", jobData.JobID,
-				"job_type", jobData.Type,
-				"dlq", p.DeadLetterQueueName,
-				"error", err)
+			if ctx.Err() == nil {
+				slog.Error("Failed to read delayed jobs",
+					"queue_name", p.DelayedQueueName,
+					"error", err)
+			}
+			continue
+		}
+
+		for _, jobPayload := range due {
+			// Only the caller that removes the job pushes it, in case several instances run the pool
+			removed, err := p.RedisClient.ZRem(ctx, p.DelayedQueueName, jobPayload).Result()
+			if err != nil || removed == 0 {
+				continue
+			}
+			if err := p.RedisClient.LPush(ctx, p.QueueName, jobPayload).Err(); err != nil {
+				slog.Error("CRITICAL: Failed to move delayed job to pending",
+					"queue_name", p.QueueName,
+					"error", err)
+			}
 		}
 	}
 }
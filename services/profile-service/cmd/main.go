@@ -1,13 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
+	"net/http"
 	"os"
-	"path/filepath"
 	"time"
+	"utils/logging"
+	"utils/shutdown"
 
 	"profile-service/internal/config"
+	"profile-service/internal/database/minio"
 	"profile-service/internal/database/postgres"
 	"profile-service/internal/event"
 	"profile-service/internal/handlers"
@@ -17,51 +20,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func setupLogging() (*os.File, error) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic: %v\n", r)
-		}
-	}()
-
-	logDir := filepath.Join("/agrisa", "log", "profile_service")
-	fmt.Println("Log directory:", logDir)
-	err := os.MkdirAll(logDir, 0o755)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %v", err)
-	}
-
-	currentTime := time.Now()
-	logFileName := fmt.Sprintf("log_%s.log", currentTime.Format("2006-01-02"))
-	logFile := filepath.Join(logDir, logFileName)
-
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
-	}
-
-	if _, err := os.Stat(logFile); err == nil {
-		absPath, err := filepath.Abs(logFile)
-		if err != nil {
-			fmt.Printf("Failed to get absolute path of log file: %v\n", err)
-		} else {
-			fmt.Printf("Log file exists at absolute path: %s\n", absPath)
-		}
-	} else if os.IsNotExist(err) {
-		fmt.Println("Log file does not exist (it will be created)")
-	} else {
-		fmt.Printf("Error checking log file existence: %v\n", err)
-	}
-
-	log.SetOutput(file)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-
-	return file, nil
-}
-
 func main() {
 	// Setup logging
-	logFile, err := setupLogging()
+	logFile, err := logging.Setup("profile_service")
 	if err != nil {
 		log.Fatalf("Error setting up logging: %v", err)
 	}
@@ -77,38 +38,101 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error connecting to PostgreSQL: %v", err)
 	}
-	defer db.Close()
 
 	rabbitConn, err := event.ConnectRabbitMQ(cfg.RabbitMQCfg)
 	if err != nil {
 		log.Fatalf("CRITICAL: Cannot start policy service without RabbitMQ connection: %v", err)
 	}
-	defer rabbitConn.Close()
 
 	profilePublisher := event.NewNotificationPublisher(rabbitConn)
+
+	minioClient, err := minio.NewMinioClient(cfg.MinioCfg)
+	if err != nil {
+		log.Fatalf("Error connecting to MinIO: %v", err)
+	}
+
 	r := gin.Default()
+	r.Use(logging.GinMiddleware())
 
 	// repositories
 	insurancePartnerRepository := repository.NewInsurancePartnerRepository(db)
 	userRepository := repository.NewUserRepository(db)
+	accountStateRepository := repository.NewAccountStateRepository(db)
+	partnerStaffRepository := repository.NewPartnerStaffRepository(db)
+	partnerBranchRepository := repository.NewPartnerBranchRepository(db)
+	partnerIntegrationRepository := repository.NewPartnerIntegrationRepository(db)
+	partnerReviewRepository := repository.NewPartnerReviewRepository(db)
+	partnerSettlementRepository := repository.NewPartnerSettlementRepository(db)
+	partnerFileRepository := repository.NewPartnerFileRepository(db)
+	partnerLifecycleRepository := repository.NewPartnerLifecycleRepository(db)
+	farmerProfileRepository := repository.NewFarmerProfileRepository(db)
+	partnerComplianceRepository := repository.NewPartnerComplianceRepository(db)
+	partnerContractRepository := repository.NewPartnerContractRepository(db)
 
 	// services
-	insurancePartnerService := services.NewInsurancePartnerService(insurancePartnerRepository, userRepository, profilePublisher)
+	insurancePartnerService := services.NewInsurancePartnerService(insurancePartnerRepository, userRepository, profilePublisher, minioClient)
 	userService := services.NewUserService(userRepository)
+	partnerStaffService := services.NewPartnerStaffService(partnerStaffRepository, insurancePartnerRepository, userRepository)
+	partnerBranchService := services.NewPartnerBranchService(partnerBranchRepository, partnerStaffService)
+	partnerIntegrationService := services.NewPartnerIntegrationService(partnerIntegrationRepository, partnerStaffService)
+	partnerReviewService := services.NewPartnerReviewService(partnerReviewRepository, insurancePartnerRepository, userRepository)
+	partnerSettlementService := services.NewPartnerSettlementService(partnerSettlementRepository, partnerStaffService)
+	partnerFileService := services.NewPartnerFileService(partnerFileRepository, partnerStaffService, minioClient)
+	partnerLifecycleService := services.NewPartnerLifecycleService(partnerLifecycleRepository, insurancePartnerRepository, userRepository, partnerStaffService, profilePublisher)
+	farmerProfileService := services.NewFarmerProfileService(farmerProfileRepository, userRepository)
+	partnerComplianceService := services.NewPartnerComplianceService(partnerComplianceRepository, partnerFileRepository, partnerStaffService, profilePublisher)
+	partnerMarketplaceService := services.NewPartnerMarketplaceService(insurancePartnerRepository, insurancePartnerService, partnerReviewService)
+	partnerContractService := services.NewPartnerContractService(partnerContractRepository, insurancePartnerRepository, partnerStaffService)
 	// handlers
 	insurancePartnerHandler := handlers.NewInsurancePartnerHandler(insurancePartnerService)
 	userProfileHandler := handlers.NewUserProfileHandler(userService)
+	partnerStaffHandler := handlers.NewPartnerStaffHandler(partnerStaffService, cfg.APIKey)
+	partnerBranchHandler := handlers.NewPartnerBranchHandler(partnerBranchService)
+	partnerIntegrationHandler := handlers.NewPartnerIntegrationHandler(partnerIntegrationService)
+	partnerReviewHandler := handlers.NewPartnerReviewHandler(partnerReviewService)
+	partnerSettlementHandler := handlers.NewPartnerSettlementHandler(partnerSettlementService)
+	partnerFileHandler := handlers.NewPartnerFileHandler(partnerFileService)
+	partnerLifecycleHandler := handlers.NewPartnerLifecycleHandler(partnerLifecycleService)
+	farmerProfileHandler := handlers.NewFarmerProfileHandler(farmerProfileService)
+	partnerComplianceHandler := handlers.NewPartnerComplianceHandler(partnerComplianceService)
+	partnerMarketplaceHandler := handlers.NewPartnerMarketplaceHandler(partnerMarketplaceService)
+	partnerContractHandler := handlers.NewPartnerContractHandler(partnerContractService)
 
 	// Register routes
 	insurancePartnerHandler.RegisterRoutes(r)
 	userProfileHandler.RegisterRoutes(r)
+	partnerStaffHandler.RegisterRoutes(r)
+	partnerBranchHandler.RegisterRoutes(r)
+	partnerIntegrationHandler.RegisterRoutes(r)
+	partnerReviewHandler.RegisterRoutes(r)
+	partnerSettlementHandler.RegisterRoutes(r)
+	partnerFileHandler.RegisterRoutes(r)
+	partnerLifecycleHandler.RegisterRoutes(r)
+	farmerProfileHandler.RegisterRoutes(r)
+	partnerComplianceHandler.RegisterRoutes(r)
+	partnerMarketplaceHandler.RegisterRoutes(r)
+	partnerContractHandler.RegisterRoutes(r)
+
+	coordinator := shutdown.New()
+	coordinator.Go("partner-compliance-expiry-watcher", func(ctx context.Context) {
+		partnerComplianceService.StartExpiryWatcher(ctx, time.Hour)
+	})
+	coordinator.Go("account-event-consumer", event.NewAccountEventConsumer(rabbitConn, accountStateRepository).Start)
+
 	serverPort := os.Getenv("PROFILE_SERVICE_PORT")
 	if serverPort == "" {
 		serverPort = "8087"
 	}
 
 	log.Printf("Starting auth-service on port %s", serverPort)
-	if err := r.Run(":" + serverPort); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	coordinator.ListenAndServe("http", &http.Server{Addr: ":" + serverPort, Handler: r})
+
+	coordinator.Closer("postgres", db.Close)
+	coordinator.Closer("rabbitmq", rabbitConn.Close)
+
+	if err := coordinator.Wait(); err != nil {
+		log.Printf("profile-service stopped: %v", err)
+		logFile.Close()
+		os.Exit(1)
 	}
 }
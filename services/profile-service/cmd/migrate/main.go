@@ -0,0 +1,35 @@
+// Command migrate runs the schema migrations of profile-service, to roll back or inspect them, or to
+// apply them when migration on startup is turned off with DB_AUTO_MIGRATE=false.
+//
+//	migrate <up|up-by-one|up-to|down|down-to|redo|status|version> [version]
+package main
+
+import (
+	"log"
+	"os"
+	"profile-service/internal/config"
+	"profile-service/internal/database/postgres"
+	"strings"
+
+	"agrisa/migration"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: migrate <%s> [version]", strings.Join(migration.Commands, "|"))
+	}
+
+	cfg := config.New()
+	cfg.PostgresCfg.AutoMigrate = false
+	cfg.PostgresCfg.SkipSchemaCheck = true
+	db, err := postgres.ConnectAndCreateDB(cfg.PostgresCfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	err = postgres.RunMigration(db, os.Args[1], os.Args[2:]...)
+	db.Close()
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
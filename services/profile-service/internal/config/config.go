@@ -7,6 +7,8 @@ type ProfileServiceConfig struct {
 	PostgresCfg PostgresConfig
 	MinioCfg    MinioConfig
 	RabbitMQCfg RabbitMQConfig
+	// Key other services call the service-to-service endpoints with
+	APIKey string
 }
 
 type PostgresConfig struct {
@@ -15,6 +17,10 @@ type PostgresConfig struct {
 	Password string
 	Host     string
 	Port     string
+	// Apply pending schema migrations when connecting, otherwise refuse a schema missing some
+	AutoMigrate bool
+	// Connect whatever the schema version, for cmd/migrate which manages the schema itself
+	SkipSchemaCheck bool
 }
 
 type MinioConfig struct {
@@ -37,11 +43,12 @@ func New() *ProfileServiceConfig {
 	return &ProfileServiceConfig{
 		Port: getEnvOrDefault("PROFILE_SERVICE_PORT", "8087"),
 		PostgresCfg: PostgresConfig{
-			DBname:   getEnvOrDefault("POSTGRES_DB", ""),
-			Username: getEnvOrDefault("POSTGRES_USER", "user"),
-			Password: getEnvOrDefault("POSTGRES_PASSWORD", "password"),
-			Host:     getEnvOrDefault("POSTGRES_HOST", "localhost"),
-			Port:     getEnvOrDefault("POSTGRES_PORT", "5432"),
+			DBname:      getEnvOrDefault("POSTGRES_DB", ""),
+			Username:    getEnvOrDefault("POSTGRES_USER", "user"),
+			Password:    getEnvOrDefault("POSTGRES_PASSWORD", "password"),
+			Host:        getEnvOrDefault("POSTGRES_HOST", "localhost"),
+			Port:        getEnvOrDefault("POSTGRES_PORT", "5432"),
+			AutoMigrate: getEnvOrDefault("DB_AUTO_MIGRATE", "true") == "true",
 		},
 		MinioCfg: MinioConfig{
 			MinioUrl:         getEnvOrDefault("MINIO_ENDPOINT", "http://localhost:9407"),
@@ -57,6 +64,7 @@ func New() *ProfileServiceConfig {
 			Password: getEnvOrDefault("RABBITMQ_PWD", "admin"),
 			Port:     getEnvOrDefault("RABBITMQ_PORT", "5672"),
 		},
+		APIKey: getEnvOrDefault("API_KEY", ""),
 	}
 }
 
@@ -0,0 +1,155 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"profile-service/internal/config"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ObjectRefScheme prefixes object references stored in partner URL columns, e.g.
+// minio://partner-logos/<partner_id>/<file>.png; they are presigned when served
+const ObjectRefScheme = "minio://"
+
+// MinioClient wraps the MinIO client with profile service specific functionality
+type MinioClient struct {
+	client *minio.Client
+	config config.MinioConfig
+}
+
+// Storage defines bucket names for partner files
+var Storage = struct {
+	PartnerLogos     string
+	PartnerDocuments string
+	PartnerAssets    string
+}{
+	PartnerLogos:     "partner-logos",
+	PartnerDocuments: "partner-documents",
+	PartnerAssets:    "partner-assets",
+}
+
+// BucketNames contains all bucket names for profile service
+var BucketNames = []string{
+	Storage.PartnerLogos,
+	Storage.PartnerDocuments,
+	Storage.PartnerAssets,
+}
+
+// NewMinioClient initializes a new MinIO client and makes sure the partner buckets exist
+func NewMinioClient(cfg config.MinioConfig) (*MinioClient, error) {
+	endpoint := strings.TrimPrefix(cfg.MinioUrl, "http://")
+	endpoint = strings.TrimPrefix(endpoint, "https://")
+
+	isSecure, err := strconv.ParseBool(cfg.MinioSecure)
+	if err != nil {
+		log.Printf("Invalid value for MinIO secure flag: %v. Defaulting to false.", err)
+		isSecure = false
+	}
+
+	minioClient, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.MinioAccessKey, cfg.MinioSecretKey, ""),
+		Secure: isSecure,
+		Region: cfg.MinioLocation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MinIO client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := minioClient.ListBuckets(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to MinIO server: %w", err)
+	}
+	log.Printf("Successfully connected to MinIO at %s", cfg.MinioUrl)
+
+	mc := &MinioClient{
+		client: minioClient,
+		config: cfg,
+	}
+	for _, bucketName := range BucketNames {
+		if err := mc.ensureBucket(ctx, bucketName); err != nil {
+			return nil, fmt.Errorf("failed to ensure bucket %s: %w", bucketName, err)
+		}
+	}
+	return mc, nil
+}
+
+func (mc *MinioClient) ensureBucket(ctx context.Context, bucketName string) error {
+	exists, err := mc.client.BucketExists(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("error checking bucket existence: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	if err := mc.client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{Region: mc.config.MinioLocation}); err != nil {
+		return fmt.Errorf("error creating bucket %s: %w", bucketName, err)
+	}
+	log.Printf("Created bucket: %s", bucketName)
+	return nil
+}
+
+// UploadFile uploads a file to the specified bucket
+func (mc *MinioClient) UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, contentType string) error {
+	_, err := mc.client.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload file %s to bucket %s: %w", objectName, bucketName, err)
+	}
+	return nil
+}
+
+// DeleteFile deletes a file from the specified bucket
+func (mc *MinioClient) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	if err := mc.client.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete file %s from bucket %s: %w", objectName, bucketName, err)
+	}
+	return nil
+}
+
+// GetPresignedURL generates a presigned URL for temporary access to an object
+func (mc *MinioClient) GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	presignedURL, err := mc.client.PresignedGetObject(ctx, bucketName, objectName, expiry, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned URL for %s in bucket %s: %w", objectName, bucketName, err)
+	}
+	return presignedURL.String(), nil
+}
+
+// ObjectRef builds the reference stored in the database for an object
+func ObjectRef(bucketName, objectName string) string {
+	return ObjectRefScheme + bucketName + "/" + objectName
+}
+
+// ParseObjectRef splits an object reference into bucket and object name
+func ParseObjectRef(ref string) (bucketName, objectName string, ok bool) {
+	if !strings.HasPrefix(ref, ObjectRefScheme) {
+		return "", "", false
+	}
+	bucketName, objectName, ok = strings.Cut(strings.TrimPrefix(ref, ObjectRefScheme), "/")
+	return bucketName, objectName, ok && bucketName != "" && objectName != ""
+}
+
+// PresignRef turns a stored object reference into a presigned URL. Other values, such as
+// external URLs, are returned unchanged, as is the reference when presigning fails.
+func (mc *MinioClient) PresignRef(ctx context.Context, ref string, expiry time.Duration) string {
+	if mc == nil {
+		return ref
+	}
+	bucketName, objectName, ok := ParseObjectRef(ref)
+	if !ok {
+		return ref
+	}
+	presignedURL, err := mc.GetPresignedURL(ctx, bucketName, objectName, expiry)
+	if err != nil {
+		log.Printf("Failed to presign %s: %v", ref, err)
+		return ref
+	}
+	return presignedURL
+}
@@ -1,3 +1,6 @@
+-- Schema of the service before versioned migrations. Databases created from it are adopted at
+-- this version without running it again.
+-- +goose Up
 -- enum
 CREATE TYPE deletion_request_status AS ENUM ('pending', 'approved', 'rejected', 'cancelled', 'completed');
 
@@ -518,4 +521,13 @@ INSERT INTO partner_deletion_requests (
     'pending',                                       
     NOW(),                                         
     NOW() + INTERVAL '7 days'                       
-);
\ No newline at end of file
+);
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+    RAISE EXCEPTION 'the baseline schema cannot be rolled back';
+END
+$$;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- Partner staff membership: links auth-service users to the partner they work for
+-- +goose Up
+CREATE TABLE partner_staff_members (
+    member_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    user_id VARCHAR(255) NOT NULL, -- From Auth Service
+    staff_role VARCHAR(50) NOT NULL CHECK (staff_role IN ('partner_admin', 'underwriter', 'claims_officer', 'viewer')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'deactivated')),
+    added_by VARCHAR(255),
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deactivated_by VARCHAR(255),
+    deactivated_at TIMESTAMP,
+    deactivation_reason TEXT,
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT unique_partner_staff UNIQUE(partner_id, user_id),
+    CONSTRAINT fk_staff_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_partner_staff_partner_id ON partner_staff_members(partner_id);
+CREATE INDEX idx_partner_staff_user_id ON partner_staff_members(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_staff_members;
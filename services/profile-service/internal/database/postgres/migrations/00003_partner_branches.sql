@@ -0,0 +1,40 @@
+-- Partner branches: regional offices used to route claims and inspections
+-- +goose Up
+CREATE TABLE partner_branches (
+    branch_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    branch_code VARCHAR(50) NOT NULL,
+    branch_name VARCHAR(255) NOT NULL,
+
+    -- Location
+    address TEXT NOT NULL,
+    province_code VARCHAR(10) NOT NULL,
+    province_name VARCHAR(100),
+    ward_code VARCHAR(10),
+    ward_name VARCHAR(100),
+    latitude DECIMAL(10, 8),
+    longitude DECIMAL(11, 8),
+
+    -- Contact
+    contact_name VARCHAR(255),
+    contact_phone VARCHAR(20),
+    contact_email VARCHAR(255),
+
+    -- Provinces this branch handles claims and inspections for
+    service_area_provinces TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT unique_partner_branch_code UNIQUE(partner_id, branch_code),
+    CONSTRAINT fk_branch_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_partner_branches_partner_id ON partner_branches(partner_id);
+CREATE INDEX idx_partner_branches_service_area ON partner_branches USING GIN(service_area_provinces);
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_branches;
@@ -0,0 +1,57 @@
+-- Partner integration settings: webhook signing secret and IP allowlist for partner API calls,
+-- webhook URLs per event type and API credentials, of which only a hash of the secret is stored
+-- +goose Up
+CREATE TABLE partner_integration_settings (
+    partner_id UUID PRIMARY KEY,
+    webhook_secret VARCHAR(128),
+    webhook_secret_rotated_at TIMESTAMP,
+    ip_allowlist TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT fk_integration_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+-- Partner webhooks: one delivery URL per event type
+CREATE TABLE partner_webhooks (
+    webhook_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    url TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT unique_partner_webhook_event UNIQUE(partner_id, event_type),
+    CONSTRAINT fk_webhook_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+-- Partner API credentials: only a hash of the client secret is stored
+CREATE TABLE partner_api_credentials (
+    credential_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_hash VARCHAR(128) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'revoked')),
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_by VARCHAR(255),
+    revoked_at TIMESTAMP,
+
+    CONSTRAINT fk_credential_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_partner_webhooks_partner_id ON partner_webhooks(partner_id);
+CREATE INDEX idx_partner_api_credentials_partner_id ON partner_api_credentials(partner_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_api_credentials;
+DROP TABLE IF EXISTS partner_webhooks;
+DROP TABLE IF EXISTS partner_integration_settings;
@@ -0,0 +1,24 @@
+-- Reviews are written by auth-service users who completed a policy with the partner, one per
+-- partner, and moderated by admins
+-- +goose Up
+ALTER TABLE partner_reviews
+    ALTER COLUMN reviewer_id TYPE VARCHAR(255) USING reviewer_id::TEXT,
+    ADD COLUMN policy_id VARCHAR(255),
+    ADD COLUMN moderation_status VARCHAR(20) NOT NULL DEFAULT 'published' CHECK (moderation_status IN ('published', 'hidden', 'rejected')),
+    ADD COLUMN moderated_by VARCHAR(255),
+    ADD COLUMN moderated_at TIMESTAMP,
+    ADD COLUMN moderation_note TEXT,
+    ADD CONSTRAINT unique_partner_reviewer UNIQUE(partner_id, reviewer_id);
+
+CREATE INDEX idx_reviews_moderation_status ON partner_reviews(moderation_status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reviews_moderation_status;
+ALTER TABLE partner_reviews
+    DROP CONSTRAINT IF EXISTS unique_partner_reviewer,
+    DROP COLUMN IF EXISTS moderation_note,
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS moderation_status,
+    DROP COLUMN IF EXISTS policy_id,
+    ALTER COLUMN reviewer_id TYPE UUID USING reviewer_id::UUID;
@@ -0,0 +1,43 @@
+-- Partner settlement accounts: bank accounts and e-wallets used for billing and claim disbursement.
+-- Every change goes through admin approval; only one account per partner is active at a time.
+-- +goose Up
+CREATE TABLE partner_settlement_accounts (
+    account_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('bank', 'ewallet')),
+
+    -- Bank account
+    bank_code VARCHAR(20),
+    branch_name VARCHAR(255),
+
+    -- E-wallet
+    wallet_provider VARCHAR(50),
+
+    account_number VARCHAR(50) NOT NULL,
+    account_name VARCHAR(255) NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_approval' CHECK (status IN ('pending_approval', 'active', 'rejected', 'cancelled', 'archived')),
+    verification_status VARCHAR(20) NOT NULL DEFAULT 'unverified' CHECK (verification_status IN ('unverified', 'verified', 'failed')),
+    change_reason TEXT,
+
+    requested_by VARCHAR(255) NOT NULL,
+    requested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMP,
+    review_note TEXT,
+    activated_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT chk_settlement_bank CHECK (account_type <> 'bank' OR bank_code IS NOT NULL),
+    CONSTRAINT chk_settlement_wallet CHECK (account_type <> 'ewallet' OR wallet_provider IS NOT NULL),
+    CONSTRAINT fk_settlement_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_settlement_accounts_partner_id ON partner_settlement_accounts(partner_id);
+CREATE INDEX idx_settlement_accounts_status ON partner_settlement_accounts(status);
+CREATE UNIQUE INDEX idx_settlement_accounts_one_active ON partner_settlement_accounts(partner_id) WHERE status = 'active';
+CREATE UNIQUE INDEX idx_settlement_accounts_one_pending ON partner_settlement_accounts(partner_id) WHERE status = 'pending_approval';
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_settlement_accounts;
@@ -0,0 +1,22 @@
+-- Partner files stored in MinIO: logos, license scans and marketing assets
+-- +goose Up
+CREATE TABLE partner_files (
+    file_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    file_type VARCHAR(30) NOT NULL CHECK (file_type IN ('logo', 'cover_photo', 'license', 'marketing')),
+    bucket_name VARCHAR(100) NOT NULL,
+    object_name TEXT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    uploaded_by VARCHAR(255) NOT NULL,
+    uploaded_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_file_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_partner_files_partner_id ON partner_files(partner_id, file_type);
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_files;
@@ -0,0 +1,8 @@
+-- Indexes for the partner search filters
+-- +goose Up
+CREATE INDEX idx_partners_status ON insurance_partners(status);
+CREATE INDEX idx_partners_operating_provinces ON insurance_partners USING GIN(operating_provinces);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_partners_operating_provinces;
+DROP INDEX IF EXISTS idx_partners_status;
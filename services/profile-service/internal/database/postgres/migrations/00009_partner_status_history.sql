@@ -0,0 +1,20 @@
+-- Partner status history: every lifecycle transition with its reason
+-- +goose Up
+CREATE TABLE partner_status_history (
+    history_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    reason TEXT,
+    changed_by VARCHAR(255) NOT NULL,
+    changed_by_name VARCHAR(255),
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_status_history_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_partner_status_history_partner_id ON partner_status_history(partner_id, changed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_status_history;
@@ -0,0 +1,26 @@
+-- Farmer profiles: household, localization and cooperative details of farmer users.
+-- The payout bank account stays in user_profiles (account_number, account_name, bank_code).
+-- +goose Up
+CREATE TABLE farmer_profiles (
+    user_id VARCHAR(255) PRIMARY KEY,
+    household_head_name VARCHAR(255),
+    household_size INT NOT NULL DEFAULT 1 CHECK (household_size BETWEEN 1 AND 50),
+    dependents_count INT NOT NULL DEFAULT 0 CHECK (dependents_count >= 0),
+    farming_experience_years INT CHECK (farming_experience_years >= 0),
+    annual_household_income BIGINT CHECK (annual_household_income >= 0),
+    preferred_language VARCHAR(10) NOT NULL DEFAULT 'vi' CHECK (preferred_language IN ('vi', 'en')),
+    cooperative_name VARCHAR(255),
+    cooperative_code VARCHAR(50),
+    cooperative_role VARCHAR(20) CHECK (cooperative_role IN ('member', 'leader')),
+    cooperative_joined_at DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_farmer_profile_user FOREIGN KEY (user_id)
+        REFERENCES user_profiles(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_farmer_profiles_cooperative_code ON farmer_profiles(cooperative_code);
+
+-- +goose Down
+DROP TABLE IF EXISTS farmer_profiles;
@@ -0,0 +1,36 @@
+-- Partner compliance documents: licenses and certificates with an expiry date. The insurance
+-- license on the partner profile is mirrored here with source 'partner_profile'. A partner is
+-- flagged while any of its documents has lapsed.
+-- +goose Up
+ALTER TABLE insurance_partners ADD COLUMN compliance_flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE partner_compliance_documents (
+    document_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    document_type VARCHAR(30) NOT NULL CHECK (document_type IN ('insurance_license', 'business_registration', 'certificate', 'other')),
+    document_name VARCHAR(255) NOT NULL,
+    document_number VARCHAR(100),
+    issued_at DATE,
+    expires_at DATE NOT NULL,
+    file_id UUID,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'partner_profile')),
+    -- Smallest reminder threshold (in days) already sent for the current expiry date
+    last_reminder_days INT,
+    lapsed_at TIMESTAMP,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_compliance_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE,
+    CONSTRAINT fk_compliance_file FOREIGN KEY (file_id)
+        REFERENCES partner_files(file_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_compliance_documents_partner_id ON partner_compliance_documents(partner_id);
+CREATE INDEX idx_compliance_documents_expires_at ON partner_compliance_documents(expires_at) WHERE lapsed_at IS NULL;
+CREATE UNIQUE INDEX idx_compliance_documents_profile_license ON partner_compliance_documents(partner_id) WHERE source = 'partner_profile';
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_compliance_documents;
+ALTER TABLE insurance_partners DROP COLUMN IF EXISTS compliance_flagged;
@@ -0,0 +1,33 @@
+-- Commercial contracts between the platform and a partner. Every change of terms is a new
+-- version; activating a version closes the validity period of the one it supersedes.
+-- +goose Up
+CREATE TABLE partner_contracts (
+    contract_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL,
+    version INT NOT NULL,
+    contract_number VARCHAR(100),
+    revenue_share_percent NUMERIC(5,2) NOT NULL CHECK (revenue_share_percent BETWEEN 0 AND 100),
+    data_cost_markup_percent NUMERIC(6,2) NOT NULL DEFAULT 0 CHECK (data_cost_markup_percent >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'VND',
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'active', 'superseded', 'terminated')),
+    notes TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    activated_by VARCHAR(255),
+    activated_at TIMESTAMP,
+    termination_reason TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_contract_partner FOREIGN KEY (partner_id)
+        REFERENCES insurance_partners(partner_id) ON DELETE CASCADE,
+    CONSTRAINT uq_contract_version UNIQUE (partner_id, version),
+    CONSTRAINT chk_contract_validity CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX idx_partner_contracts_partner_id ON partner_contracts(partner_id);
+CREATE UNIQUE INDEX idx_partner_contracts_one_active ON partner_contracts(partner_id) WHERE status = 'active';
+
+-- +goose Down
+DROP TABLE IF EXISTS partner_contracts;
@@ -0,0 +1,15 @@
+-- Copy of the account status and marketing consent auth-service holds for each user, kept up to
+-- date from its account events, so campaign audiences can leave out closed and unconsented
+-- accounts. Rows are keyed on the user alone, as events may arrive before the profile exists.
+-- +goose Up
+CREATE TABLE account_states (
+    user_id VARCHAR(255) PRIMARY KEY,
+    deactivated BOOLEAN NOT NULL DEFAULT FALSE,
+    status_changed_at TIMESTAMP,
+    marketing_consent BOOLEAN NOT NULL DEFAULT FALSE,
+    marketing_consent_changed_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS account_states;
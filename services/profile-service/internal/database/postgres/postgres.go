@@ -30,6 +30,20 @@ func ConnectAndCreateDB(cfg config.PostgresConfig) (*sqlx.DB, error) {
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping target database: %w", err)
 	}
+
+	// Bring the schema up to date; with auto migration off, migrations are run with cmd/migrate
+	// and the service refuses to start until they are
+	switch {
+	case cfg.SkipSchemaCheck:
+	case cfg.AutoMigrate:
+		if err := RunMigration(db, "up"); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	default:
+		if err := CheckMigration(db); err != nil {
+			return nil, err
+		}
+	}
 	DB_Status = true
 
 	return db, nil
@@ -0,0 +1,131 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"profile-service/internal/repository"
+	"time"
+	"utils/logging"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// accountEventsExchange is the fanout exchange auth-service publishes account changes on
+const accountEventsExchange = "account_events"
+
+// accountEventsQueue is the queue of the account events profile-service keeps
+const accountEventsQueue = "profile.account_events"
+
+// Account events profile-service acts on
+const (
+	accountDeactivated = "account_deactivated"
+	accountReactivated = "account_reactivated"
+	consentGranted     = "consent_granted"
+	consentWithdrawn   = "consent_withdrawn"
+)
+
+// marketingConsent is the consent type campaign audiences are limited to
+const marketingConsent = "marketing"
+
+type accountEvent struct {
+	Type        string    `json:"type"`
+	UserID      string    `json:"user_id"`
+	ConsentType string    `json:"consent_type"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// AccountEventConsumer keeps the account states of users in line with the account events of
+// auth-service
+type AccountEventConsumer struct {
+	conn   *RabbitMQConnection
+	states repository.IAccountStateRepository
+}
+
+func NewAccountEventConsumer(conn *RabbitMQConnection, states repository.IAccountStateRepository) *AccountEventConsumer {
+	return &AccountEventConsumer{
+		conn:   conn,
+		states: states,
+	}
+}
+
+// Start consumes account events until ctx is done, reconnecting when the channel closes
+func (c *AccountEventConsumer) Start(ctx context.Context) {
+	for {
+		if err := c.consume(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Account event consumer stopped, retrying", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (c *AccountEventConsumer) consume(ctx context.Context) error {
+	ch, err := c.conn.Connection.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open account event channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(accountEventsExchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare account event exchange: %w", err)
+	}
+	if _, err := ch.QueueDeclare(accountEventsQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare account event queue: %w", err)
+	}
+	if err := ch.QueueBind(accountEventsQueue, "", accountEventsExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind account event queue: %w", err)
+	}
+
+	msgs, err := ch.Consume(accountEventsQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register account event consumer: %w", err)
+	}
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("account event channel closed")
+			}
+			msgCtx := logging.FromMessageHeaders(ctx, msg.Headers)
+			if err := c.handle(msg.Body); err != nil {
+				slog.ErrorContext(msgCtx, "Failed to handle account event", "error", err)
+				msg.Nack(false, true)
+			} else {
+				msg.Ack(false)
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *AccountEventConsumer) handle(body []byte) error {
+	var accountEvent accountEvent
+	if err := json.Unmarshal(body, &accountEvent); err != nil {
+		// Redelivering a malformed event would not make it readable
+		slog.Error("Dropping malformed account event", "body", string(body), "error", err)
+		return nil
+	}
+	if accountEvent.UserID == "" {
+		slog.Error("Dropping account event without user", "type", accountEvent.Type)
+		return nil
+	}
+
+	switch accountEvent.Type {
+	case accountDeactivated, accountReactivated:
+		return c.states.SetDeactivated(accountEvent.UserID, accountEvent.Type == accountDeactivated, accountEvent.OccurredAt)
+	case consentGranted, consentWithdrawn:
+		if accountEvent.ConsentType != marketingConsent {
+			return nil
+		}
+		return c.states.SetMarketingConsent(accountEvent.UserID, accountEvent.Type == consentGranted, accountEvent.OccurredAt)
+	}
+	return nil
+}
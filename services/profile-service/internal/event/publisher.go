@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"time"
+	"utils/logging"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -61,6 +62,7 @@ func (p *NotificationPublisher) PublishEvent(ctx context.Context, event ProfileE
 			ContentType:  "application/json",
 			Body:         body,
 			Timestamp:    time.Now(),
+			Headers:      logging.MessageHeaders(ctx),
 		},
 	)
 	if err != nil {
@@ -79,6 +81,58 @@ func (p *NotificationPublisher) PublishEvent(ctx context.Context, event ProfileE
 	return nil
 }
 
+// PublishNotification publishes a push notification event to the push_noti_events queue
+func (p *NotificationPublisher) PublishNotification(ctx context.Context, event NotificationEventPushModel) error {
+	_, err := p.conn.Channel.QueueDeclare(
+		PushNotiQueue, // queue name
+		true,          // durable
+		false,         // delete when unused
+		false,         // exclusive
+		false,         // no-wait
+		nil,           // arguments
+	)
+	if err != nil {
+		p.messagesFailed++
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.messagesFailed++
+		return fmt.Errorf("failed to marshal notification event: %w", err)
+	}
+
+	err = p.conn.Channel.PublishWithContext(
+		ctx,
+		"",            // exchange
+		PushNotiQueue, // routing key (queue name)
+		false,         // mandatory
+		false,         // immediate
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         body,
+			Timestamp:    time.Now(),
+			Headers:      logging.MessageHeaders(ctx),
+		},
+	)
+	if err != nil {
+		p.messagesFailed++
+		return fmt.Errorf("failed to publish notification event: %w", err)
+	}
+
+	p.messagesPublished++
+	p.lastPublishTime = time.Now()
+
+	slog.Info("Notification event published",
+		"queue", PushNotiQueue,
+		"title", event.Title,
+		"user_count", len(event.LstUserIds),
+	)
+
+	return nil
+}
+
 // GetMetrics returns publisher metrics
 func (p *NotificationPublisher) GetMetrics() map[string]any {
 	return map[string]any{
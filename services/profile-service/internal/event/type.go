@@ -2,6 +2,16 @@ package event
 
 const ProfileQueue string = "profile_events"
 
+// PushNotiQueue is consumed by noti-service, which delivers push notifications to users
+const PushNotiQueue string = "push_noti_events"
+
+type NotificationEventPushModel struct {
+	LstUserIds []string       `json:"lstUserIds,omitempty"`
+	Title      string         `json:"title"`
+	Body       string         `json:"body"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
 type ProfileEvent struct {
 	ID         string           `json:"id"`
 	EventType  ProfileEventType `json:"event_type"`
@@ -16,4 +26,7 @@ const (
 	ProfilePendingDelete = "pending_delete"
 	ProfileCancelDelete  = "delete_cancelled"
 	ProfleConfirmDelete  = "confirm_delete"
+	PartnerSuspended     = "partner_suspended"
+	PartnerReinstated    = "partner_reinstated"
+	PartnerTerminated    = "partner_terminated"
 )
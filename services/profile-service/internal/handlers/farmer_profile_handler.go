@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FarmerProfileHandler struct {
+	FarmerProfileService services.IFarmerProfileService
+}
+
+func NewFarmerProfileHandler(farmerProfileService services.IFarmerProfileService) *FarmerProfileHandler {
+	return &FarmerProfileHandler{
+		FarmerProfileService: farmerProfileService,
+	}
+}
+
+func (h *FarmerProfileHandler) RegisterRoutes(router *gin.Engine) {
+	farmerProGr := router.Group("/profile/protected/api/v1/farmer-profile")
+	farmerProGr.GET("/me", h.GetMyProfile)
+	farmerProGr.PUT("/me", h.UpsertMyProfile)
+	farmerProGr.PUT("/me/payout-account", h.UpdatePayoutAccount)
+
+	// Service-to-service lookups, not exposed through the gateway
+	farmerIntGr := router.Group("/profile/internal/api/v1/farmer-profiles")
+	farmerIntGr.POST("/preferred-languages", h.GetPreferredLanguages)
+	farmerIntGr.POST("/audience", h.GetAudience)
+	farmerIntGr.GET("/:user_id", h.GetProfile)
+	farmerIntGr.GET("/:user_id/payout-account", h.GetPayoutAccount)
+}
+
+func (h *FarmerProfileHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *FarmerProfileHandler) GetMyProfile(c *gin.Context) {
+	profile, err := h.FarmerProfileService.GetMyProfile(c.GetHeader("X-User-ID"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(profile))
+}
+
+func (h *FarmerProfileHandler) UpsertMyProfile(c *gin.Context) {
+	var req models.UpsertFarmerProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+		return
+	}
+
+	profile, err := h.FarmerProfileService.UpsertMyProfile(c.GetHeader("X-User-ID"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(profile))
+}
+
+func (h *FarmerProfileHandler) UpdatePayoutAccount(c *gin.Context) {
+	var req models.UpdatePayoutAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "account_number, account_name and bank_code are required"))
+		return
+	}
+
+	account, err := h.FarmerProfileService.UpdatePayoutAccount(c.GetHeader("X-User-ID"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(account))
+}
+
+func (h *FarmerProfileHandler) GetProfile(c *gin.Context) {
+	profile, err := h.FarmerProfileService.GetProfile(c.Param("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(profile))
+}
+
+func (h *FarmerProfileHandler) GetPayoutAccount(c *gin.Context) {
+	account, err := h.FarmerProfileService.GetPayoutAccount(c.Param("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(account))
+}
+
+func (h *FarmerProfileHandler) GetPreferredLanguages(c *gin.Context) {
+	var req models.GetPreferredLanguagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "user_ids is required"))
+		return
+	}
+
+	preferences, err := h.FarmerProfileService.GetPreferredLanguages(req.UserIDs)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(preferences))
+}
+
+func (h *FarmerProfileHandler) GetAudience(c *gin.Context) {
+	var req models.GetFarmerAudienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+		return
+	}
+
+	page, err := h.FarmerProfileService.GetAudience(&req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(page))
+}
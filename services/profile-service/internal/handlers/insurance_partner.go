@@ -29,6 +29,7 @@ func (h *InsurancePartnerHandler) RegisterRoutes(router *gin.Engine) {
 	insurancePartnerProfileGrPub.GET("/insurance-partners/:partner_id/profile", h.GetInsurancePartnerPublicByID)
 	insurancePartnerProfileGrPub.GET("/insurance-partners/:partner_id/reviews", h.GetPartnerReviews)
 	insurancePartnerProfileGrPub.GET("/insurance-partners", h.GetAllInsurancePartnersPublicProfiles)
+	insurancePartnerProfileGrPub.GET("/insurance-partners/search", h.SearchInsurancePartnersPublic)
 	insurancePartnerProfileGrPub.GET("/insurance-partners/:partner_id", h.GetPrivateProfileByPartnerID)
 
 	insurancePartnerProtectedGrPub := router.Group("/profile/protected/api/v1")
@@ -46,10 +47,15 @@ func (h *InsurancePartnerHandler) RegisterRoutes(router *gin.Engine) {
 
 	// admin endpoint
 	partnerAdminGr := insurancePartnerProtectedGrPub.Group("/insurance-partners/admin")
+	partnerAdminGr.GET("/partners", h.SearchInsurancePartners)
 	partnerAdminGr.POST("/process-request", h.ProcessPartnerDeletionRequestReview)
 	partnerAdminGr.GET("/deletion-requests", h.GetAllPartnerDeletionRequest)
 	partnerAdminGr.GET("/requests/:request_id/deletion-request", h.GetPartnerDeletionRequestByID)
 	partnerAdminGr.GET("/partners/:partner_id/deletion-requests", h.GetPartnerDeleletionRequestsByPartnerID)
+
+	// Service-to-service lookups, not exposed through the gateway
+	insurancePartnerIntGr := router.Group("/profile/internal/api/v1")
+	insurancePartnerIntGr.GET("/insurance-partners/:partner_id/summary", h.GetPartnerSummary)
 }
 
 func MapErrorToHTTPStatusExtended(errorString string) (errorCode string, httpStatus int) {
@@ -101,6 +107,40 @@ func (h *InsurancePartnerHandler) GetAllInsurancePartnersPrivateProfiles(c *gin.
 	c.JSON(http.StatusOK, response)
 }
 
+// SearchInsurancePartnersPublic handles GET /insurance-partners/search for the farmer marketplace
+func (h *InsurancePartnerHandler) SearchInsurancePartnersPublic(c *gin.Context) {
+	var query models.PartnerListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid query parameters"))
+		return
+	}
+
+	result, err := h.InsurancePartnerService.SearchPublicPartners(query)
+	if err != nil {
+		errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+		c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
+
+// SearchInsurancePartners handles GET /insurance-partners/admin/partners for the admin console
+func (h *InsurancePartnerHandler) SearchInsurancePartners(c *gin.Context) {
+	var query models.PartnerListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid query parameters"))
+		return
+	}
+
+	result, err := h.InsurancePartnerService.SearchPartners(c.GetHeader("X-User-ID"), query)
+	if err != nil {
+		errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+		c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
+
 func (h *InsurancePartnerHandler) GetInsurancePartnerPublicByID(c *gin.Context) {
 	partnerID := c.Param("partner_id")
 	result, err := h.InsurancePartnerService.GetPublicProfile(partnerID)
@@ -115,6 +155,18 @@ func (h *InsurancePartnerHandler) GetInsurancePartnerPublicByID(c *gin.Context)
 	c.JSON(http.StatusOK, response)
 }
 
+// GetPartnerSummary is called by policy-service to verify and describe the provider of a policy
+func (h *InsurancePartnerHandler) GetPartnerSummary(c *gin.Context) {
+	partnerID := c.Param("partner_id")
+	result, err := h.InsurancePartnerService.GetPartnerSummary(partnerID)
+	if err != nil {
+		errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+		c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
+
 // GetPartnerReviews handles GET /insurance-partners/:partner_id/reviews
 func (h *InsurancePartnerHandler) GetPartnerReviews(c *gin.Context) {
 	partnerID := c.Param("partner_id")
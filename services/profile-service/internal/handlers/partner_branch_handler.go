@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"strconv"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerBranchHandler struct {
+	PartnerBranchService services.IPartnerBranchService
+}
+
+func NewPartnerBranchHandler(partnerBranchService services.IPartnerBranchService) *PartnerBranchHandler {
+	return &PartnerBranchHandler{
+		PartnerBranchService: partnerBranchService,
+	}
+}
+
+func (h *PartnerBranchHandler) RegisterRoutes(router *gin.Engine) {
+	branchPubGr := router.Group("/profile/public/api/v1/partner-branches")
+	branchPubGr.GET("/:partner_id", h.GetActiveBranches)
+	branchPubGr.GET("/:partner_id/nearest", h.FindNearestBranch)
+	branchPubGr.GET("/:partner_id/:branch_id", h.GetBranchByID)
+
+	branchProGr := router.Group("/profile/protected/api/v1/partner-branches")
+	branchProGr.GET("/:partner_id", h.GetAllBranches)
+	branchProGr.POST("/:partner_id", h.CreateBranch)
+	branchProGr.PUT("/:partner_id/:branch_id", h.UpdateBranch)
+	branchProGr.DELETE("/:partner_id/:branch_id", h.DeactivateBranch)
+}
+
+func (h *PartnerBranchHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerBranchHandler) GetActiveBranches(c *gin.Context) {
+	branches, err := h.PartnerBranchService.GetBranches(c.Param("partner_id"), true)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(branches))
+}
+
+func (h *PartnerBranchHandler) GetAllBranches(c *gin.Context) {
+	branches, err := h.PartnerBranchService.GetBranches(c.Param("partner_id"), c.Query("include_inactive") != "true")
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(branches))
+}
+
+func (h *PartnerBranchHandler) GetBranchByID(c *gin.Context) {
+	branch, err := h.PartnerBranchService.GetBranchByID(c.Param("partner_id"), c.Param("branch_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(branch))
+}
+
+// FindNearestBranch routes a claim or inspection location to a branch of the partner
+func (h *PartnerBranchHandler) FindNearestBranch(c *gin.Context) {
+	var latitude, longitude *float64
+	if c.Query("lat") != "" || c.Query("lng") != "" {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+		if latErr != nil || lngErr != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "lat and lng must be valid numbers"))
+			return
+		}
+		latitude, longitude = &lat, &lng
+	}
+
+	result, err := h.PartnerBranchService.FindNearestBranch(c.Param("partner_id"), c.Query("province_code"), latitude, longitude)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
+
+func (h *PartnerBranchHandler) CreateBranch(c *gin.Context) {
+	var req models.CreatePartnerBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "branch_code, branch_name, address and province_code are required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	branch, err := h.PartnerBranchService.CreateBranch(actorID, c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(branch))
+}
+
+func (h *PartnerBranchHandler) UpdateBranch(c *gin.Context) {
+	var req models.UpdatePartnerBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	branch, err := h.PartnerBranchService.UpdateBranch(actorID, c.Param("partner_id"), c.Param("branch_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(branch))
+}
+
+func (h *PartnerBranchHandler) DeactivateBranch(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if err := h.PartnerBranchService.DeactivateBranch(actorID, c.Param("partner_id"), c.Param("branch_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Branch deactivated successfully"))
+}
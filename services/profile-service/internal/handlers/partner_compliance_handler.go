@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerComplianceHandler struct {
+	PartnerComplianceService services.IPartnerComplianceService
+}
+
+func NewPartnerComplianceHandler(partnerComplianceService services.IPartnerComplianceService) *PartnerComplianceHandler {
+	return &PartnerComplianceHandler{
+		PartnerComplianceService: partnerComplianceService,
+	}
+}
+
+func (h *PartnerComplianceHandler) RegisterRoutes(router *gin.Engine) {
+	complianceProGr := router.Group("/profile/protected/api/v1/partner-compliance")
+	complianceProGr.GET("/:partner_id", h.GetDocuments)
+	complianceProGr.POST("/:partner_id", h.CreateDocument)
+	complianceProGr.PUT("/:partner_id/:document_id", h.RenewDocument)
+	complianceProGr.DELETE("/:partner_id/:document_id", h.DeleteDocument)
+}
+
+func (h *PartnerComplianceHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerComplianceHandler) GetDocuments(c *gin.Context) {
+	documents, err := h.PartnerComplianceService.GetDocuments(c.GetHeader("X-User-ID"), c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(documents))
+}
+
+func (h *PartnerComplianceHandler) CreateDocument(c *gin.Context) {
+	var req models.CreateComplianceDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "document_type, document_name and expires_at are required"))
+		return
+	}
+
+	document, err := h.PartnerComplianceService.CreateDocument(c.GetHeader("X-User-ID"), c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(document))
+}
+
+func (h *PartnerComplianceHandler) RenewDocument(c *gin.Context) {
+	var req models.RenewComplianceDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "expires_at is required"))
+		return
+	}
+
+	document, err := h.PartnerComplianceService.RenewDocument(c.GetHeader("X-User-ID"), c.Param("partner_id"), c.Param("document_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(document))
+}
+
+func (h *PartnerComplianceHandler) DeleteDocument(c *gin.Context) {
+	if err := h.PartnerComplianceService.DeleteDocument(c.GetHeader("X-User-ID"), c.Param("partner_id"), c.Param("document_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Compliance document deleted successfully"))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerContractHandler struct {
+	PartnerContractService services.IPartnerContractService
+}
+
+func NewPartnerContractHandler(partnerContractService services.IPartnerContractService) *PartnerContractHandler {
+	return &PartnerContractHandler{
+		PartnerContractService: partnerContractService,
+	}
+}
+
+func (h *PartnerContractHandler) RegisterRoutes(router *gin.Engine) {
+	contractProGr := router.Group("/profile/protected/api/v1/partner-contracts")
+	contractProGr.GET("/:partner_id", h.GetContracts)
+	contractProGr.POST("/:partner_id", h.CreateContract)
+	contractProGr.POST("/:partner_id/:contract_id/activate", h.ActivateContract)
+	contractProGr.POST("/:partner_id/:contract_id/terminate", h.TerminateContract)
+	contractProGr.DELETE("/:partner_id/:contract_id", h.DeleteDraftContract)
+
+	contractIntGr := router.Group("/profile/internal/api/v1/partner-contracts")
+	contractIntGr.GET("/:partner_id/terms", h.GetContractTerms)
+}
+
+func (h *PartnerContractHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerContractHandler) GetContracts(c *gin.Context) {
+	contracts, err := h.PartnerContractService.GetContracts(c.GetHeader("X-User-ID"), c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(contracts))
+}
+
+func (h *PartnerContractHandler) CreateContract(c *gin.Context) {
+	var req models.CreatePartnerContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "revenue_share_percent and valid_from are required"))
+		return
+	}
+
+	contract, err := h.PartnerContractService.CreateContract(c.GetHeader("X-User-ID"), c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(contract))
+}
+
+func (h *PartnerContractHandler) ActivateContract(c *gin.Context) {
+	contract, err := h.PartnerContractService.ActivateContract(c.GetHeader("X-User-ID"), c.Param("partner_id"), c.Param("contract_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(contract))
+}
+
+func (h *PartnerContractHandler) TerminateContract(c *gin.Context) {
+	var req models.TerminatePartnerContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "valid_to and reason are required"))
+		return
+	}
+
+	contract, err := h.PartnerContractService.TerminateContract(c.GetHeader("X-User-ID"), c.Param("partner_id"), c.Param("contract_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(contract))
+}
+
+func (h *PartnerContractHandler) DeleteDraftContract(c *gin.Context) {
+	if err := h.PartnerContractService.DeleteDraftContract(c.GetHeader("X-User-ID"), c.Param("partner_id"), c.Param("contract_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Draft contract deleted successfully"))
+}
+
+// GetContractTerms is called by policy-service billing with an optional ?date=YYYY-MM-DD
+func (h *PartnerContractHandler) GetContractTerms(c *gin.Context) {
+	terms, err := h.PartnerContractService.GetContractTerms(c.Param("partner_id"), c.Query("date"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(terms))
+}
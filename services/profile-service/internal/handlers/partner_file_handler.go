@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerFileHandler struct {
+	PartnerFileService services.IPartnerFileService
+}
+
+func NewPartnerFileHandler(partnerFileService services.IPartnerFileService) *PartnerFileHandler {
+	return &PartnerFileHandler{
+		PartnerFileService: partnerFileService,
+	}
+}
+
+func (h *PartnerFileHandler) RegisterRoutes(router *gin.Engine) {
+	fileProGr := router.Group("/profile/protected/api/v1/partner-files")
+	fileProGr.GET("/:partner_id", h.GetFiles)
+	fileProGr.POST("/:partner_id", h.UploadFile)
+	fileProGr.DELETE("/:partner_id/:file_id", h.DeleteFile)
+}
+
+func (h *PartnerFileHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerFileHandler) GetFiles(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	files, err := h.PartnerFileService.GetFiles(actorID, c.Param("partner_id"), models.PartnerFileType(c.Query("file_type")))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(files))
+}
+
+// UploadFile accepts a multipart form with the file in "file" and its purpose in "file_type"
+func (h *PartnerFileHandler) UploadFile(c *gin.Context) {
+	fileType := models.PartnerFileType(c.PostForm("file_type"))
+	header, err := c.FormFile("file")
+	if err != nil || fileType == "" {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "file and file_type are required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	file, err := h.PartnerFileService.UploadFile(actorID, c.Param("partner_id"), fileType, header)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(file))
+}
+
+func (h *PartnerFileHandler) DeleteFile(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if err := h.PartnerFileService.DeleteFile(actorID, c.Param("partner_id"), c.Param("file_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("File deleted successfully"))
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerIntegrationHandler struct {
+	PartnerIntegrationService services.IPartnerIntegrationService
+}
+
+func NewPartnerIntegrationHandler(partnerIntegrationService services.IPartnerIntegrationService) *PartnerIntegrationHandler {
+	return &PartnerIntegrationHandler{
+		PartnerIntegrationService: partnerIntegrationService,
+	}
+}
+
+func (h *PartnerIntegrationHandler) RegisterRoutes(router *gin.Engine) {
+	integrationProGr := router.Group("/profile/protected/api/v1/partner-integrations/:partner_id")
+	integrationProGr.GET("", h.GetIntegrationSettings)
+	integrationProGr.PUT("/webhooks", h.UpsertWebhooks)
+	integrationProGr.DELETE("/webhooks/:event_type", h.DeleteWebhook)
+	integrationProGr.POST("/webhook-secret/rotate", h.RotateWebhookSecret)
+	integrationProGr.PUT("/ip-allowlist", h.UpdateIPAllowlist)
+	integrationProGr.GET("/credentials", h.GetAPICredentials)
+	integrationProGr.POST("/credentials", h.IssueAPICredential)
+	integrationProGr.POST("/credentials/:credential_id/revoke", h.RevokeAPICredential)
+
+	// internal routes are not exposed through the gateway; they are called by the
+	// notification webhook channel and the gateway itself
+	integrationIntGr := router.Group("/profile/internal/api/v1/partner-integrations")
+	integrationIntGr.GET("/:partner_id/webhooks/:event_type", h.GetWebhookTarget)
+	integrationIntGr.POST("/credentials/verify", h.VerifyAPICredential)
+}
+
+func (h *PartnerIntegrationHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerIntegrationHandler) GetIntegrationSettings(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	settings, err := h.PartnerIntegrationService.GetIntegrationSettings(actorID, c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(settings))
+}
+
+func (h *PartnerIntegrationHandler) UpsertWebhooks(c *gin.Context) {
+	var req models.UpsertPartnerWebhooksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "webhooks with event_type and url are required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	settings, err := h.PartnerIntegrationService.UpsertWebhooks(actorID, c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(settings))
+}
+
+func (h *PartnerIntegrationHandler) DeleteWebhook(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	eventType := models.WebhookEventType(c.Param("event_type"))
+	if err := h.PartnerIntegrationService.DeleteWebhook(actorID, c.Param("partner_id"), eventType); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Webhook deleted successfully"))
+}
+
+func (h *PartnerIntegrationHandler) RotateWebhookSecret(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	secret, err := h.PartnerIntegrationService.RotateWebhookSecret(actorID, c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(secret))
+}
+
+func (h *PartnerIntegrationHandler) UpdateIPAllowlist(c *gin.Context) {
+	var req models.UpdateIPAllowlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "ip_allowlist is required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	settings, err := h.PartnerIntegrationService.UpdateIPAllowlist(actorID, c.Param("partner_id"), req.IPAllowlist)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(settings))
+}
+
+func (h *PartnerIntegrationHandler) GetAPICredentials(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	credentials, err := h.PartnerIntegrationService.GetAPICredentials(actorID, c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(credentials))
+}
+
+func (h *PartnerIntegrationHandler) IssueAPICredential(c *gin.Context) {
+	var req models.CreateAPICredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "name is required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	issued, err := h.PartnerIntegrationService.IssueAPICredential(actorID, c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(issued))
+}
+
+func (h *PartnerIntegrationHandler) RevokeAPICredential(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if err := h.PartnerIntegrationService.RevokeAPICredential(actorID, c.Param("partner_id"), c.Param("credential_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("API credential revoked successfully"))
+}
+
+func (h *PartnerIntegrationHandler) GetWebhookTarget(c *gin.Context) {
+	eventType := models.WebhookEventType(c.Param("event_type"))
+	target, err := h.PartnerIntegrationService.GetWebhookTarget(c.Param("partner_id"), eventType)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(target))
+}
+
+func (h *PartnerIntegrationHandler) VerifyAPICredential(c *gin.Context) {
+	var req models.VerifyAPICredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "client_id and client_secret are required"))
+		return
+	}
+
+	result, err := h.PartnerIntegrationService.VerifyAPICredential(&req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerLifecycleHandler struct {
+	PartnerLifecycleService services.IPartnerLifecycleService
+}
+
+func NewPartnerLifecycleHandler(partnerLifecycleService services.IPartnerLifecycleService) *PartnerLifecycleHandler {
+	return &PartnerLifecycleHandler{
+		PartnerLifecycleService: partnerLifecycleService,
+	}
+}
+
+func (h *PartnerLifecycleHandler) RegisterRoutes(router *gin.Engine) {
+	lifecycleProGr := router.Group("/profile/protected/api/v1/partner-lifecycle")
+	lifecycleProGr.GET("/:partner_id/history", h.GetStatusHistory)
+	// action is one of review, approve, suspend, reinstate or terminate
+	lifecycleProGr.POST("/:partner_id/:action", h.TransitionStatus)
+}
+
+func (h *PartnerLifecycleHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerLifecycleHandler) TransitionStatus(c *gin.Context) {
+	var req models.PartnerStatusTransitionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+			return
+		}
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	history, err := h.PartnerLifecycleService.TransitionStatus(actorID, c.Param("partner_id"), c.Param("action"), req.Reason)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(history))
+}
+
+func (h *PartnerLifecycleHandler) GetStatusHistory(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	history, err := h.PartnerLifecycleService.GetStatusHistory(actorID, c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(history))
+}
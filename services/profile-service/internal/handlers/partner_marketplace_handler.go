@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerMarketplaceHandler struct {
+	PartnerMarketplaceService services.IPartnerMarketplaceService
+}
+
+func NewPartnerMarketplaceHandler(partnerMarketplaceService services.IPartnerMarketplaceService) *PartnerMarketplaceHandler {
+	return &PartnerMarketplaceHandler{
+		PartnerMarketplaceService: partnerMarketplaceService,
+	}
+}
+
+func (h *PartnerMarketplaceHandler) RegisterRoutes(router *gin.Engine) {
+	marketplacePubGr := router.Group("/profile/public/api/v1/marketplace")
+	marketplacePubGr.GET("/partners/:partner_id", h.GetMarketplaceProfile)
+}
+
+func (h *PartnerMarketplaceHandler) GetMarketplaceProfile(c *gin.Context) {
+	profile, err := h.PartnerMarketplaceService.GetMarketplaceProfile(c.Param("partner_id"))
+	if err != nil {
+		errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+		c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(profile))
+}
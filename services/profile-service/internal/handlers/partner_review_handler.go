@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"strconv"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerReviewHandler struct {
+	PartnerReviewService services.IPartnerReviewService
+}
+
+func NewPartnerReviewHandler(partnerReviewService services.IPartnerReviewService) *PartnerReviewHandler {
+	return &PartnerReviewHandler{
+		PartnerReviewService: partnerReviewService,
+	}
+}
+
+func (h *PartnerReviewHandler) RegisterRoutes(router *gin.Engine) {
+	reviewPubGr := router.Group("/profile/public/api/v1/partner-reviews")
+	reviewPubGr.GET("/:partner_id/summary", h.GetRatingSummary)
+
+	reviewProGr := router.Group("/profile/protected/api/v1/partner-reviews")
+	reviewProGr.POST("/:partner_id", h.SubmitReview)
+	reviewProGr.GET("/:partner_id/mine", h.GetOwnReview)
+	reviewProGr.DELETE("/:partner_id/mine", h.DeleteOwnReview)
+
+	// admin endpoint
+	moderationGr := router.Group("/profile/protected/api/v1/review-moderation")
+	moderationGr.GET("", h.GetReviewsForModeration)
+	moderationGr.PUT("/:review_id", h.ModerateReview)
+}
+
+func (h *PartnerReviewHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerReviewHandler) SubmitReview(c *gin.Context) {
+	var req models.SubmitPartnerReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "rating_stars must be between 1 and 5"))
+		return
+	}
+
+	farmerID := c.GetHeader("X-User-ID")
+	review, err := h.PartnerReviewService.SubmitReview(farmerID, c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(review))
+}
+
+func (h *PartnerReviewHandler) GetOwnReview(c *gin.Context) {
+	farmerID := c.GetHeader("X-User-ID")
+	review, err := h.PartnerReviewService.GetOwnReview(farmerID, c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(review))
+}
+
+func (h *PartnerReviewHandler) DeleteOwnReview(c *gin.Context) {
+	farmerID := c.GetHeader("X-User-ID")
+	if err := h.PartnerReviewService.DeleteOwnReview(farmerID, c.Param("partner_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Review deleted successfully"))
+}
+
+func (h *PartnerReviewHandler) GetRatingSummary(c *gin.Context) {
+	summary, err := h.PartnerReviewService.GetRatingSummary(c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(summary))
+}
+
+func (h *PartnerReviewHandler) GetReviewsForModeration(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	status := models.ReviewModerationStatus(c.Query("status"))
+
+	actorID := c.GetHeader("X-User-ID")
+	reviews, err := h.PartnerReviewService.GetReviewsForModeration(actorID, status, limit, page)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(reviews))
+}
+
+func (h *PartnerReviewHandler) ModerateReview(c *gin.Context) {
+	var req models.ModeratePartnerReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "moderation_status is required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	review, err := h.PartnerReviewService.ModerateReview(actorID, c.Param("review_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(review))
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerSettlementHandler struct {
+	PartnerSettlementService services.IPartnerSettlementService
+}
+
+func NewPartnerSettlementHandler(partnerSettlementService services.IPartnerSettlementService) *PartnerSettlementHandler {
+	return &PartnerSettlementHandler{
+		PartnerSettlementService: partnerSettlementService,
+	}
+}
+
+func (h *PartnerSettlementHandler) RegisterRoutes(router *gin.Engine) {
+	settlementProGr := router.Group("/profile/protected/api/v1/partner-settlement-accounts")
+	settlementProGr.GET("/:partner_id", h.GetAccounts)
+	settlementProGr.POST("/:partner_id", h.SubmitAccount)
+	settlementProGr.POST("/:partner_id/:account_id/cancel", h.CancelAccount)
+
+	// admin endpoint
+	approvalGr := router.Group("/profile/protected/api/v1/settlement-account-approvals")
+	approvalGr.GET("", h.GetPendingAccounts)
+	approvalGr.POST("/:account_id/approve", h.ApproveAccount)
+	approvalGr.POST("/:account_id/reject", h.RejectAccount)
+
+	// internal route for billing and claim disbursement, not exposed through the gateway
+	settlementIntGr := router.Group("/profile/internal/api/v1/partner-settlement-accounts")
+	settlementIntGr.GET("/:partner_id/active", h.GetActiveAccount)
+}
+
+func (h *PartnerSettlementHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerSettlementHandler) GetAccounts(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	accounts, err := h.PartnerSettlementService.GetAccounts(actorID, c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(accounts))
+}
+
+func (h *PartnerSettlementHandler) SubmitAccount(c *gin.Context) {
+	var req models.SubmitSettlementAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "account_type, account_number and account_name are required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	account, err := h.PartnerSettlementService.SubmitAccount(actorID, c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(account))
+}
+
+func (h *PartnerSettlementHandler) CancelAccount(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	if err := h.PartnerSettlementService.CancelAccount(actorID, c.Param("partner_id"), c.Param("account_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Settlement account change cancelled"))
+}
+
+func (h *PartnerSettlementHandler) GetPendingAccounts(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	accounts, err := h.PartnerSettlementService.GetPendingAccounts(actorID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(accounts))
+}
+
+func (h *PartnerSettlementHandler) ApproveAccount(c *gin.Context) {
+	var req models.ReviewSettlementAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+			return
+		}
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	account, err := h.PartnerSettlementService.ApproveAccount(actorID, c.Param("account_id"), req.ReviewNote)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(account))
+}
+
+func (h *PartnerSettlementHandler) RejectAccount(c *gin.Context) {
+	var req models.ReviewSettlementAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "review_note is required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	account, err := h.PartnerSettlementService.RejectAccount(actorID, c.Param("account_id"), req.ReviewNote)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(account))
+}
+
+func (h *PartnerSettlementHandler) GetActiveAccount(c *gin.Context) {
+	account, err := h.PartnerSettlementService.GetActiveAccount(c.Param("partner_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(account))
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/services"
+	"utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerStaffHandler struct {
+	PartnerStaffService services.IPartnerStaffService
+	// Key of the services allowed to check memberships
+	apiKey string
+}
+
+func NewPartnerStaffHandler(partnerStaffService services.IPartnerStaffService, apiKey string) *PartnerStaffHandler {
+	return &PartnerStaffHandler{
+		PartnerStaffService: partnerStaffService,
+		apiKey:              apiKey,
+	}
+}
+
+func (h *PartnerStaffHandler) RegisterRoutes(router *gin.Engine) {
+	staffProGr := router.Group("/profile/protected/api/v1/partner-staff")
+	// service-to-service or admin endpoint
+	staffProGr.GET("/:partner_id/members/:user_id", h.CheckMembership)
+	staffProGr.GET("/:partner_id", h.GetStaffMembers)
+	staffProGr.POST("/:partner_id", h.AddStaffMember)
+	staffProGr.PUT("/:partner_id/:user_id/role", h.UpdateStaffRole)
+	staffProGr.POST("/:partner_id/:user_id/deactivate", h.DeactivateStaffMember)
+}
+
+func (h *PartnerStaffHandler) respondError(c *gin.Context, err error) {
+	errorCode, httpStatus := MapErrorToHTTPStatusExtended(err.Error())
+	c.JSON(httpStatus, utils.CreateErrorResponse(errorCode, err.Error()))
+}
+
+func (h *PartnerStaffHandler) GetStaffMembers(c *gin.Context) {
+	actorID := c.GetHeader("X-User-ID")
+	members, err := h.PartnerStaffService.GetStaffMembers(actorID, c.Param("partner_id"), c.Query("status"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(members))
+}
+
+func (h *PartnerStaffHandler) AddStaffMember(c *gin.Context) {
+	var req models.AddPartnerStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "user_id and staff_role are required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	member, err := h.PartnerStaffService.AddStaffMember(actorID, c.Param("partner_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(member))
+}
+
+func (h *PartnerStaffHandler) UpdateStaffRole(c *gin.Context) {
+	var req models.UpdatePartnerStaffRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "staff_role is required"))
+		return
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	member, err := h.PartnerStaffService.UpdateStaffRole(actorID, c.Param("partner_id"), c.Param("user_id"), req.StaffRole)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(member))
+}
+
+func (h *PartnerStaffHandler) DeactivateStaffMember(c *gin.Context) {
+	var req models.DeactivatePartnerStaffRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("BAD_REQUEST", "Invalid request payload"))
+			return
+		}
+	}
+
+	actorID := c.GetHeader("X-User-ID")
+	if err := h.PartnerStaffService.DeactivateStaffMember(actorID, c.Param("partner_id"), c.Param("user_id"), req.Reason); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse("Staff member deactivated successfully"))
+}
+
+// CheckMembership is called by other services, with the API key, to verify that a requester acts
+// for a partner. Admins may call it as well.
+func (h *PartnerStaffHandler) CheckMembership(c *gin.Context) {
+	if h.apiKey == "" || c.GetHeader("API-KEY") != h.apiKey {
+		if err := h.PartnerStaffService.AuthorizeAdmin(c.GetHeader("X-User-ID")); err != nil {
+			h.respondError(c, err)
+			return
+		}
+	}
+	membership, err := h.PartnerStaffService.CheckMembership(c.Param("partner_id"), c.Param("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(membership))
+}
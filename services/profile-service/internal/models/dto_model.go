@@ -88,6 +88,19 @@ type PublicPartnerProfile struct {
 	YearEstablished      int    `db:"year_established" json:"year_established"`
 }
 
+// PartnerSummary - identity and status of a partner for other services, whatever its status
+type PartnerSummary struct {
+	PartnerID            uuid.UUID `db:"partner_id" json:"partner_id"`
+	PartnerDisplayName   string    `db:"partner_display_name" json:"partner_display_name"`
+	PartnerLogoURL       string    `db:"partner_logo_url" json:"partner_logo_url"`
+	PartnerPhone         string    `db:"partner_phone" json:"partner_phone"`
+	PartnerOfficialEmail string    `db:"partner_official_email" json:"partner_official_email"`
+	Hotline              string    `db:"hotline" json:"hotline"`
+	PartnerRatingScore   float64   `db:"partner_rating_score" json:"partner_rating_score"`
+	PartnerRatingCount   int       `db:"partner_rating_count" json:"partner_rating_count"`
+	Status               string    `db:"status" json:"status"`
+}
+
 // PrivatePartnerProfile - detailed profile view for the insurance partner themselves
 type PrivatePartnerProfile struct {
 	// ========== PUBLIC INFORMATION (similar to PublicPartnerProfile) ==========
@@ -159,6 +172,8 @@ type PrivatePartnerProfile struct {
 	AccountNumber *string `db:"account_number" json:"account_number"`
 	AccountName   *string `db:"account_name" json:"account_name"`
 	BankCode      *string `db:"bank_code" json:"bank_code"`
+
+	ComplianceFlagged bool `db:"compliance_flagged" json:"compliance_flagged"`
 }
 
 type CreateUserProfileRequest struct {
@@ -233,3 +248,296 @@ type DeletionRequestResponse struct {
 	ReviewNote          *string               `db:"review_note" json:"review_note"`
 	UpdatedAt           *time.Time            `db:"updated_at" json:"updated_at"`
 }
+
+type AddPartnerStaffRequest struct {
+	UserID    string    `json:"user_id" binding:"required"`
+	StaffRole StaffRole `json:"staff_role" binding:"required"`
+}
+
+type UpdatePartnerStaffRoleRequest struct {
+	StaffRole StaffRole `json:"staff_role" binding:"required"`
+}
+
+type DeactivatePartnerStaffRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PartnerMembershipResponse answers whether a user may act for a partner
+type PartnerMembershipResponse struct {
+	PartnerID string    `json:"partner_id"`
+	UserID    string    `json:"user_id"`
+	IsMember  bool      `json:"is_member"`
+	StaffRole StaffRole `json:"staff_role,omitempty"`
+}
+
+type CreatePartnerBranchRequest struct {
+	BranchCode           string   `json:"branch_code" binding:"required"`
+	BranchName           string   `json:"branch_name" binding:"required"`
+	Address              string   `json:"address" binding:"required"`
+	ProvinceCode         string   `json:"province_code" binding:"required"`
+	ProvinceName         *string  `json:"province_name"`
+	WardCode             *string  `json:"ward_code"`
+	WardName             *string  `json:"ward_name"`
+	Latitude             *float64 `json:"latitude"`
+	Longitude            *float64 `json:"longitude"`
+	ContactName          *string  `json:"contact_name"`
+	ContactPhone         *string  `json:"contact_phone"`
+	ContactEmail         *string  `json:"contact_email"`
+	ServiceAreaProvinces []string `json:"service_area_provinces"`
+}
+
+type UpdatePartnerBranchRequest struct {
+	BranchName           *string   `json:"branch_name"`
+	Address              *string   `json:"address"`
+	ProvinceCode         *string   `json:"province_code"`
+	ProvinceName         *string   `json:"province_name"`
+	WardCode             *string   `json:"ward_code"`
+	WardName             *string   `json:"ward_name"`
+	Latitude             *float64  `json:"latitude"`
+	Longitude            *float64  `json:"longitude"`
+	ContactName          *string   `json:"contact_name"`
+	ContactPhone         *string   `json:"contact_phone"`
+	ContactEmail         *string   `json:"contact_email"`
+	ServiceAreaProvinces *[]string `json:"service_area_provinces"`
+	IsActive             *bool     `json:"is_active"`
+}
+
+// NearestBranchResponse is the branch a claim or inspection is routed to
+type NearestBranchResponse struct {
+	Branch        PartnerBranch `json:"branch"`
+	InServiceArea bool          `json:"in_service_area"`
+	DistanceKm    *float64      `json:"distance_km,omitempty"`
+}
+
+type PartnerWebhookInput struct {
+	EventType WebhookEventType `json:"event_type" binding:"required"`
+	URL       string           `json:"url" binding:"required"`
+	IsActive  *bool            `json:"is_active"`
+}
+
+type UpsertPartnerWebhooksRequest struct {
+	Webhooks []PartnerWebhookInput `json:"webhooks" binding:"required,min=1,dive"`
+}
+
+type UpdateIPAllowlistRequest struct {
+	IPAllowlist []string `json:"ip_allowlist" binding:"required"`
+}
+
+type CreateAPICredentialRequest struct {
+	Name          string `json:"name" binding:"required"`
+	ExpiresInDays *int   `json:"expires_in_days"`
+}
+
+type PartnerIntegrationResponse struct {
+	PartnerID              string           `json:"partner_id"`
+	Webhooks               []PartnerWebhook `json:"webhooks"`
+	IPAllowlist            []string         `json:"ip_allowlist"`
+	HasWebhookSecret       bool             `json:"has_webhook_secret"`
+	WebhookSecretRotatedAt *time.Time       `json:"webhook_secret_rotated_at,omitempty"`
+}
+
+// WebhookSecretResponse carries a newly generated secret; it is only shown once
+type WebhookSecretResponse struct {
+	WebhookSecret string    `json:"webhook_secret"`
+	RotatedAt     time.Time `json:"rotated_at"`
+}
+
+// IssuedAPICredentialResponse carries a newly issued client secret; it is only shown once
+type IssuedAPICredentialResponse struct {
+	Credential   PartnerAPICredential `json:"credential"`
+	ClientSecret string               `json:"client_secret"`
+}
+
+// WebhookTargetResponse is what the notification webhook channel needs to deliver an event
+type WebhookTargetResponse struct {
+	PartnerID string           `json:"partner_id"`
+	EventType WebhookEventType `json:"event_type"`
+	URL       string           `json:"url"`
+	Secret    string           `json:"secret"`
+}
+
+type VerifyAPICredentialRequest struct {
+	ClientID     string `json:"client_id" binding:"required"`
+	ClientSecret string `json:"client_secret" binding:"required"`
+	ClientIP     string `json:"client_ip"`
+}
+
+// VerifyAPICredentialResponse is returned to the gateway when a partner API call is authenticated
+type VerifyAPICredentialResponse struct {
+	Valid     bool   `json:"valid"`
+	PartnerID string `json:"partner_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type SubmitPartnerReviewRequest struct {
+	RatingStars   int     `json:"rating_stars" binding:"required,min=1,max=5"`
+	ReviewContent *string `json:"review_content"`
+}
+
+type ModeratePartnerReviewRequest struct {
+	ModerationStatus ReviewModerationStatus `json:"moderation_status" binding:"required"`
+	ModerationNote   *string                `json:"moderation_note"`
+}
+
+// PartnerRatingSummary aggregates the published reviews of a partner
+type PartnerRatingSummary struct {
+	PartnerID    string      `json:"partner_id"`
+	AverageScore float64     `json:"average_score"`
+	RatingCount  int         `json:"rating_count"`
+	Distribution map[int]int `json:"distribution"`
+}
+
+// PartnerClaimStats - all-time claim settlement figures of a partner, sourced from policy-service
+type PartnerClaimStats struct {
+	TotalClaims           int64    `json:"total_claims"`
+	SettledClaims         int64    `json:"settled_claims"`
+	RejectedClaims        int64    `json:"rejected_claims"`
+	PendingClaims         int64    `json:"pending_claims"`
+	SettlementRatePercent float64  `json:"settlement_rate_percent"`
+	AvgSettlementDays     *float64 `json:"avg_settlement_days"`
+	TotalPayoutDisbursed  float64  `json:"total_payout_disbursed"`
+}
+
+// PartnerMarketplaceProfile - what farmers see when browsing a partner on the marketplace.
+// ActiveBasePolicyCount and ClaimStats are null while policy-service is unavailable.
+type PartnerMarketplaceProfile struct {
+	Partner               PublicPartnerProfile `json:"partner"`
+	Rating                PartnerRatingSummary `json:"rating"`
+	ActiveBasePolicyCount *int64               `json:"active_base_policy_count"`
+	ClaimStats            *PartnerClaimStats   `json:"claim_stats"`
+	GeneratedAt           time.Time            `json:"generated_at"`
+}
+
+type SubmitSettlementAccountRequest struct {
+	AccountType    SettlementAccountType `json:"account_type" binding:"required"`
+	BankCode       *string               `json:"bank_code"`
+	BranchName     *string               `json:"branch_name"`
+	WalletProvider *string               `json:"wallet_provider"`
+	AccountNumber  string                `json:"account_number" binding:"required"`
+	AccountName    string                `json:"account_name" binding:"required"`
+	ChangeReason   *string               `json:"change_reason"`
+}
+
+type ReviewSettlementAccountRequest struct {
+	ReviewNote *string `json:"review_note"`
+}
+
+type PartnerStatusTransitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PartnerListQuery - search, filter, sort and paging options of the partner listings
+type PartnerListQuery struct {
+	Search        string `form:"search"`
+	Status        string `form:"status"`
+	ProvinceCode  string `form:"province_code"`
+	SortBy        string `form:"sort_by"`
+	SortDirection string `form:"sort_direction"`
+	Page          int    `form:"page"`
+	Limit         int    `form:"limit"`
+}
+
+// PartnerListResponse - one page of a partner listing
+type PartnerListResponse[T any] struct {
+	Items      []T `json:"items"`
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	TotalPages int `json:"total_pages"`
+}
+
+type UpsertFarmerProfileRequest struct {
+	HouseholdHeadName      *string           `json:"household_head_name"`
+	HouseholdSize          int               `json:"household_size"`
+	DependentsCount        int               `json:"dependents_count"`
+	FarmingExperienceYears *int              `json:"farming_experience_years"`
+	AnnualHouseholdIncome  *int64            `json:"annual_household_income"`
+	PreferredLanguage      PreferredLanguage `json:"preferred_language"`
+	CooperativeName        *string           `json:"cooperative_name"`
+	CooperativeCode        *string           `json:"cooperative_code"`
+	CooperativeRole        *CooperativeRole  `json:"cooperative_role"`
+	CooperativeJoinedAt    *string           `json:"cooperative_joined_at"` // YYYY-MM-DD
+}
+
+type UpdatePayoutAccountRequest struct {
+	AccountNumber string `json:"account_number" binding:"required"`
+	AccountName   string `json:"account_name" binding:"required"`
+	BankCode      string `json:"bank_code" binding:"required"`
+}
+
+type GetPreferredLanguagesRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required"`
+}
+
+// GetFarmerAudienceRequest selects farmers by where they live or their cooperative, a page at a
+// time in user ID order. A farmer matching any of the codes of a list matches that list.
+type GetFarmerAudienceRequest struct {
+	ProvinceCodes    []string `json:"province_codes"`
+	DistrictCodes    []string `json:"district_codes"`
+	CooperativeCodes []string `json:"cooperative_codes"`
+	AfterUserID      string   `json:"after_user_id"`
+	Limit            int      `json:"limit"`
+}
+
+// FarmerAudienceMember - a farmer notification-service can reach in app or by SMS
+type FarmerAudienceMember struct {
+	UserID string `json:"user_id" db:"user_id"`
+	Phone  string `json:"phone" db:"primary_phone"`
+}
+
+type FarmerAudiencePage struct {
+	Members []FarmerAudienceMember `json:"members"`
+	// User ID to pass as after_user_id for the next page, null on the last page
+	NextAfterUserID *string `json:"next_after_user_id"`
+}
+
+// FarmerLanguagePreference - language notification-service should use for a user
+type FarmerLanguagePreference struct {
+	UserID            string            `json:"user_id" db:"user_id"`
+	PreferredLanguage PreferredLanguage `json:"preferred_language" db:"preferred_language"`
+}
+
+type CreateComplianceDocumentRequest struct {
+	DocumentType   ComplianceDocumentType `json:"document_type" binding:"required"`
+	DocumentName   string                 `json:"document_name" binding:"required"`
+	DocumentNumber *string                `json:"document_number"`
+	IssuedAt       *string                `json:"issued_at"`                     // YYYY-MM-DD
+	ExpiresAt      string                 `json:"expires_at" binding:"required"` // YYYY-MM-DD
+	FileID         *string                `json:"file_id"`
+}
+
+// RenewComplianceDocumentRequest - a renewal replaces the number, dates and scan of a document
+type RenewComplianceDocumentRequest struct {
+	DocumentNumber *string `json:"document_number"`
+	IssuedAt       *string `json:"issued_at"`                     // YYYY-MM-DD
+	ExpiresAt      string  `json:"expires_at" binding:"required"` // YYYY-MM-DD
+	FileID         *string `json:"file_id"`
+}
+
+// CreatePartnerContractRequest - a new contract version, created as a draft
+type CreatePartnerContractRequest struct {
+	ContractNumber        *string  `json:"contract_number"`
+	RevenueSharePercent   *float64 `json:"revenue_share_percent" binding:"required"`
+	DataCostMarkupPercent *float64 `json:"data_cost_markup_percent"`
+	Currency              string   `json:"currency"`
+	ValidFrom             string   `json:"valid_from" binding:"required"` // YYYY-MM-DD
+	ValidTo               *string  `json:"valid_to"`                      // YYYY-MM-DD
+	Notes                 *string  `json:"notes"`
+}
+
+type TerminatePartnerContractRequest struct {
+	ValidTo string `json:"valid_to" binding:"required"` // YYYY-MM-DD, last day the terms apply
+	Reason  string `json:"reason" binding:"required"`
+}
+
+// PartnerContractTerms - the contract terms billing applies to a partner on a given date
+type PartnerContractTerms struct {
+	PartnerID             uuid.UUID  `json:"partner_id" db:"partner_id"`
+	ContractID            uuid.UUID  `json:"contract_id" db:"contract_id"`
+	Version               int        `json:"version" db:"version"`
+	RevenueSharePercent   float64    `json:"revenue_share_percent" db:"revenue_share_percent"`
+	DataCostMarkupPercent float64    `json:"data_cost_markup_percent" db:"data_cost_markup_percent"`
+	Currency              string     `json:"currency" db:"currency"`
+	ValidFrom             time.Time  `json:"valid_from" db:"valid_from"`
+	ValidTo               *time.Time `json:"valid_to,omitempty" db:"valid_to"`
+}
@@ -14,3 +14,190 @@ const (
 	DeletionRequestCancelled DeletionRequestStatus = "cancelled"
 	DeletionRequestCompleted DeletionRequestStatus = "completed"
 )
+
+// Insurance partner lifecycle statuses; an approved partner is "active"
+type PartnerStatus string
+
+const (
+	PartnerStatusPending     PartnerStatus = "pending"
+	PartnerStatusUnderReview PartnerStatus = "under_review"
+	PartnerStatusActive      PartnerStatus = "active"
+	PartnerStatusSuspended   PartnerStatus = "suspended"
+	PartnerStatusTerminated  PartnerStatus = "terminated"
+)
+
+func (s PartnerStatus) IsValid() bool {
+	switch s {
+	case PartnerStatusPending, PartnerStatusUnderReview, PartnerStatusActive, PartnerStatusSuspended, PartnerStatusTerminated:
+		return true
+	}
+	return false
+}
+
+type StaffRole string
+
+const (
+	StaffRolePartnerAdmin  StaffRole = "partner_admin"
+	StaffRoleUnderwriter   StaffRole = "underwriter"
+	StaffRoleClaimsOfficer StaffRole = "claims_officer"
+	StaffRoleViewer        StaffRole = "viewer"
+)
+
+func (r StaffRole) IsValid() bool {
+	switch r {
+	case StaffRolePartnerAdmin, StaffRoleUnderwriter, StaffRoleClaimsOfficer, StaffRoleViewer:
+		return true
+	}
+	return false
+}
+
+type StaffStatus string
+
+const (
+	StaffStatusActive      StaffStatus = "active"
+	StaffStatusDeactivated StaffStatus = "deactivated"
+)
+
+// Auth-service role names stored in user_profiles.role_id
+const (
+	AuthRoleAdmin        = "admin"
+	AuthRoleAdminPartner = "admin_partner"
+	AuthRoleFarmer       = "farmer"
+)
+
+// Events partners can subscribe a webhook to
+type WebhookEventType string
+
+const (
+	WebhookEventPolicyRegistered WebhookEventType = "policy.registered"
+	WebhookEventPolicyApproved   WebhookEventType = "policy.approved"
+	WebhookEventPolicyCancelled  WebhookEventType = "policy.cancelled"
+	WebhookEventClaimCreated     WebhookEventType = "claim.created"
+	WebhookEventClaimApproved    WebhookEventType = "claim.approved"
+	WebhookEventClaimRejected    WebhookEventType = "claim.rejected"
+	WebhookEventPayoutCompleted  WebhookEventType = "payout.completed"
+)
+
+func (e WebhookEventType) IsValid() bool {
+	switch e {
+	case WebhookEventPolicyRegistered, WebhookEventPolicyApproved, WebhookEventPolicyCancelled,
+		WebhookEventClaimCreated, WebhookEventClaimApproved, WebhookEventClaimRejected,
+		WebhookEventPayoutCompleted:
+		return true
+	}
+	return false
+}
+
+type APICredentialStatus string
+
+const (
+	APICredentialActive  APICredentialStatus = "active"
+	APICredentialRevoked APICredentialStatus = "revoked"
+)
+
+type ReviewModerationStatus string
+
+const (
+	ReviewPublished ReviewModerationStatus = "published"
+	ReviewHidden    ReviewModerationStatus = "hidden"
+	ReviewRejected  ReviewModerationStatus = "rejected"
+)
+
+func (s ReviewModerationStatus) IsValid() bool {
+	switch s {
+	case ReviewPublished, ReviewHidden, ReviewRejected:
+		return true
+	}
+	return false
+}
+
+type SettlementAccountType string
+
+const (
+	SettlementAccountBank    SettlementAccountType = "bank"
+	SettlementAccountEWallet SettlementAccountType = "ewallet"
+)
+
+type SettlementAccountStatus string
+
+const (
+	SettlementAccountPendingApproval SettlementAccountStatus = "pending_approval"
+	SettlementAccountActive          SettlementAccountStatus = "active"
+	SettlementAccountRejected        SettlementAccountStatus = "rejected"
+	SettlementAccountCancelled       SettlementAccountStatus = "cancelled"
+	SettlementAccountArchived        SettlementAccountStatus = "archived"
+)
+
+type SettlementVerificationStatus string
+
+const (
+	SettlementUnverified SettlementVerificationStatus = "unverified"
+	SettlementVerified   SettlementVerificationStatus = "verified"
+	SettlementFailed     SettlementVerificationStatus = "failed"
+)
+
+type PartnerFileType string
+
+const (
+	PartnerFileLogo       PartnerFileType = "logo"
+	PartnerFileCoverPhoto PartnerFileType = "cover_photo"
+	PartnerFileLicense    PartnerFileType = "license"
+	PartnerFileMarketing  PartnerFileType = "marketing"
+)
+
+// Languages farmers can receive notifications and documents in
+type PreferredLanguage string
+
+const (
+	LanguageVietnamese PreferredLanguage = "vi"
+	LanguageEnglish    PreferredLanguage = "en"
+)
+
+func (l PreferredLanguage) IsValid() bool {
+	switch l {
+	case LanguageVietnamese, LanguageEnglish:
+		return true
+	}
+	return false
+}
+
+type CooperativeRole string
+
+const (
+	CooperativeRoleMember CooperativeRole = "member"
+	CooperativeRoleLeader CooperativeRole = "leader"
+)
+
+func (r CooperativeRole) IsValid() bool {
+	switch r {
+	case CooperativeRoleMember, CooperativeRoleLeader:
+		return true
+	}
+	return false
+}
+
+type ComplianceDocumentType string
+
+const (
+	ComplianceInsuranceLicense     ComplianceDocumentType = "insurance_license"
+	ComplianceBusinessRegistration ComplianceDocumentType = "business_registration"
+	ComplianceCertificate          ComplianceDocumentType = "certificate"
+	ComplianceOther                ComplianceDocumentType = "other"
+)
+
+func (t ComplianceDocumentType) IsValid() bool {
+	switch t {
+	case ComplianceInsuranceLicense, ComplianceBusinessRegistration, ComplianceCertificate, ComplianceOther:
+		return true
+	}
+	return false
+}
+
+type ContractStatus string
+
+const (
+	ContractStatusDraft      ContractStatus = "draft"
+	ContractStatusActive     ContractStatus = "active"
+	ContractStatusSuperseded ContractStatus = "superseded"
+	ContractStatusTerminated ContractStatus = "terminated"
+)
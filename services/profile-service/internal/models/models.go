@@ -58,9 +58,10 @@ type InsurancePartner struct {
 	LastUpdatedByName          *string        `db:"last_updated_by_name"`
 	LegalDocumentURLs          pq.StringArray `db:"legal_document_urls"`
 	// bank info
-	AccountNumber *string `db:"account_number"`
-	AccountName   *string `db:"account_name"`
-	BankCode      *string `db:"bank_code"`
+	AccountNumber     *string `db:"account_number"`
+	AccountName       *string `db:"account_name"`
+	BankCode          *string `db:"bank_code"`
+	ComplianceFlagged bool    `db:"compliance_flagged"`
 }
 
 type Product struct {
@@ -86,6 +87,16 @@ type PartnerReview struct {
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// PartnerReviewDetail is a review with its eligibility and moderation data, for moderators and authors
+type PartnerReviewDetail struct {
+	PartnerReview
+	PolicyID         *string                `json:"policy_id,omitempty" db:"policy_id"`
+	ModerationStatus ReviewModerationStatus `json:"moderation_status" db:"moderation_status"`
+	ModeratedBy      *string                `json:"moderated_by,omitempty" db:"moderated_by"`
+	ModeratedAt      *time.Time             `json:"moderated_at,omitempty" db:"moderated_at"`
+	ModerationNote   *string                `json:"moderation_note,omitempty" db:"moderation_note"`
+}
+
 type UserProfile struct {
 	ProfileID         uuid.UUID  `json:"profile_id" db:"profile_id"`
 	UserID            string     `json:"user_id" db:"user_id"`
@@ -141,3 +152,190 @@ type PartnerDeletionRequest struct {
 	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 	TransferPartnerID *uuid.UUID `json:"transfer_partner_id,omitempty" db:"transfer_partner_id"`
 }
+
+type PartnerStaffMember struct {
+	MemberID           uuid.UUID   `json:"member_id" db:"member_id"`
+	PartnerID          uuid.UUID   `json:"partner_id" db:"partner_id"`
+	UserID             string      `json:"user_id" db:"user_id"`
+	StaffRole          StaffRole   `json:"staff_role" db:"staff_role"`
+	Status             StaffStatus `json:"status" db:"status"`
+	AddedBy            *string     `json:"added_by,omitempty" db:"added_by"`
+	AddedAt            time.Time   `json:"added_at" db:"added_at"`
+	DeactivatedBy      *string     `json:"deactivated_by,omitempty" db:"deactivated_by"`
+	DeactivatedAt      *time.Time  `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	DeactivationReason *string     `json:"deactivation_reason,omitempty" db:"deactivation_reason"`
+	UpdatedAt          time.Time   `json:"updated_at" db:"updated_at"`
+
+	// joined from user_profiles
+	FullName     *string `json:"full_name,omitempty" db:"full_name"`
+	Email        *string `json:"email,omitempty" db:"email"`
+	PrimaryPhone *string `json:"primary_phone,omitempty" db:"primary_phone"`
+}
+
+type PartnerBranch struct {
+	BranchID             uuid.UUID      `json:"branch_id" db:"branch_id"`
+	PartnerID            uuid.UUID      `json:"partner_id" db:"partner_id"`
+	BranchCode           string         `json:"branch_code" db:"branch_code"`
+	BranchName           string         `json:"branch_name" db:"branch_name"`
+	Address              string         `json:"address" db:"address"`
+	ProvinceCode         string         `json:"province_code" db:"province_code"`
+	ProvinceName         *string        `json:"province_name,omitempty" db:"province_name"`
+	WardCode             *string        `json:"ward_code,omitempty" db:"ward_code"`
+	WardName             *string        `json:"ward_name,omitempty" db:"ward_name"`
+	Latitude             *float64       `json:"latitude,omitempty" db:"latitude"`
+	Longitude            *float64       `json:"longitude,omitempty" db:"longitude"`
+	ContactName          *string        `json:"contact_name,omitempty" db:"contact_name"`
+	ContactPhone         *string        `json:"contact_phone,omitempty" db:"contact_phone"`
+	ContactEmail         *string        `json:"contact_email,omitempty" db:"contact_email"`
+	ServiceAreaProvinces pq.StringArray `json:"service_area_provinces" db:"service_area_provinces"`
+	IsActive             bool           `json:"is_active" db:"is_active"`
+	CreatedBy            *string        `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type PartnerIntegrationSettings struct {
+	PartnerID              uuid.UUID      `json:"partner_id" db:"partner_id"`
+	WebhookSecret          *string        `json:"-" db:"webhook_secret"`
+	WebhookSecretRotatedAt *time.Time     `json:"webhook_secret_rotated_at,omitempty" db:"webhook_secret_rotated_at"`
+	IPAllowlist            pq.StringArray `json:"ip_allowlist" db:"ip_allowlist"`
+	UpdatedBy              *string        `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt              time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type PartnerWebhook struct {
+	WebhookID uuid.UUID        `json:"webhook_id" db:"webhook_id"`
+	PartnerID uuid.UUID        `json:"partner_id" db:"partner_id"`
+	EventType WebhookEventType `json:"event_type" db:"event_type"`
+	URL       string           `json:"url" db:"url"`
+	IsActive  bool             `json:"is_active" db:"is_active"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+type PartnerAPICredential struct {
+	CredentialID uuid.UUID           `json:"credential_id" db:"credential_id"`
+	PartnerID    uuid.UUID           `json:"partner_id" db:"partner_id"`
+	Name         string              `json:"name" db:"name"`
+	ClientID     string              `json:"client_id" db:"client_id"`
+	SecretHash   string              `json:"-" db:"secret_hash"`
+	Status       APICredentialStatus `json:"status" db:"status"`
+	ExpiresAt    *time.Time          `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt   *time.Time          `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedBy    *string             `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	RevokedBy    *string             `json:"revoked_by,omitempty" db:"revoked_by"`
+	RevokedAt    *time.Time          `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+type PartnerSettlementAccount struct {
+	AccountID          uuid.UUID                    `json:"account_id" db:"account_id"`
+	PartnerID          uuid.UUID                    `json:"partner_id" db:"partner_id"`
+	AccountType        SettlementAccountType        `json:"account_type" db:"account_type"`
+	BankCode           *string                      `json:"bank_code,omitempty" db:"bank_code"`
+	BranchName         *string                      `json:"branch_name,omitempty" db:"branch_name"`
+	WalletProvider     *string                      `json:"wallet_provider,omitempty" db:"wallet_provider"`
+	AccountNumber      string                       `json:"account_number" db:"account_number"`
+	AccountName        string                       `json:"account_name" db:"account_name"`
+	Status             SettlementAccountStatus      `json:"status" db:"status"`
+	VerificationStatus SettlementVerificationStatus `json:"verification_status" db:"verification_status"`
+	ChangeReason       *string                      `json:"change_reason,omitempty" db:"change_reason"`
+	RequestedBy        string                       `json:"requested_by" db:"requested_by"`
+	RequestedAt        time.Time                    `json:"requested_at" db:"requested_at"`
+	ReviewedBy         *string                      `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt         *time.Time                   `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewNote         *string                      `json:"review_note,omitempty" db:"review_note"`
+	ActivatedAt        *time.Time                   `json:"activated_at,omitempty" db:"activated_at"`
+	UpdatedAt          time.Time                    `json:"updated_at" db:"updated_at"`
+}
+
+type PartnerFile struct {
+	FileID      uuid.UUID       `json:"file_id" db:"file_id"`
+	PartnerID   uuid.UUID       `json:"partner_id" db:"partner_id"`
+	FileType    PartnerFileType `json:"file_type" db:"file_type"`
+	BucketName  string          `json:"-" db:"bucket_name"`
+	ObjectName  string          `json:"-" db:"object_name"`
+	FileName    string          `json:"file_name" db:"file_name"`
+	ContentType string          `json:"content_type" db:"content_type"`
+	SizeBytes   int64           `json:"size_bytes" db:"size_bytes"`
+	UploadedBy  string          `json:"uploaded_by" db:"uploaded_by"`
+	UploadedAt  time.Time       `json:"uploaded_at" db:"uploaded_at"`
+
+	// Presigned download URL, filled when the file is served
+	URL string `json:"url,omitempty" db:"-"`
+}
+
+type PartnerStatusHistory struct {
+	HistoryID     uuid.UUID     `json:"history_id" db:"history_id"`
+	PartnerID     uuid.UUID     `json:"partner_id" db:"partner_id"`
+	FromStatus    PartnerStatus `json:"from_status" db:"from_status"`
+	ToStatus      PartnerStatus `json:"to_status" db:"to_status"`
+	Reason        *string       `json:"reason,omitempty" db:"reason"`
+	ChangedBy     string        `json:"changed_by" db:"changed_by"`
+	ChangedByName *string       `json:"changed_by_name,omitempty" db:"changed_by_name"`
+	ChangedAt     time.Time     `json:"changed_at" db:"changed_at"`
+}
+
+type FarmerProfile struct {
+	UserID                 string            `json:"user_id" db:"user_id"`
+	HouseholdHeadName      *string           `json:"household_head_name,omitempty" db:"household_head_name"`
+	HouseholdSize          int               `json:"household_size" db:"household_size"`
+	DependentsCount        int               `json:"dependents_count" db:"dependents_count"`
+	FarmingExperienceYears *int              `json:"farming_experience_years,omitempty" db:"farming_experience_years"`
+	AnnualHouseholdIncome  *int64            `json:"annual_household_income,omitempty" db:"annual_household_income"`
+	PreferredLanguage      PreferredLanguage `json:"preferred_language" db:"preferred_language"`
+	CooperativeName        *string           `json:"cooperative_name,omitempty" db:"cooperative_name"`
+	CooperativeCode        *string           `json:"cooperative_code,omitempty" db:"cooperative_code"`
+	CooperativeRole        *CooperativeRole  `json:"cooperative_role,omitempty" db:"cooperative_role"`
+	CooperativeJoinedAt    *time.Time        `json:"cooperative_joined_at,omitempty" db:"cooperative_joined_at"`
+	CreatedAt              time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Payout bank account, stored on the user profile
+	PayoutAccount *FarmerPayoutAccount `json:"payout_account,omitempty" db:"-"`
+}
+
+type FarmerPayoutAccount struct {
+	UserID        string `json:"user_id" db:"user_id"`
+	AccountNumber string `json:"account_number" db:"account_number"`
+	AccountName   string `json:"account_name" db:"account_name"`
+	BankCode      string `json:"bank_code" db:"bank_code"`
+}
+
+type PartnerComplianceDocument struct {
+	DocumentID       uuid.UUID              `json:"document_id" db:"document_id"`
+	PartnerID        uuid.UUID              `json:"partner_id" db:"partner_id"`
+	DocumentType     ComplianceDocumentType `json:"document_type" db:"document_type"`
+	DocumentName     string                 `json:"document_name" db:"document_name"`
+	DocumentNumber   *string                `json:"document_number,omitempty" db:"document_number"`
+	IssuedAt         *time.Time             `json:"issued_at,omitempty" db:"issued_at"`
+	ExpiresAt        time.Time              `json:"expires_at" db:"expires_at"`
+	FileID           *uuid.UUID             `json:"file_id,omitempty" db:"file_id"`
+	Source           string                 `json:"source" db:"source"`
+	LastReminderDays *int                   `json:"last_reminder_days,omitempty" db:"last_reminder_days"`
+	LapsedAt         *time.Time             `json:"lapsed_at,omitempty" db:"lapsed_at"`
+	CreatedBy        string                 `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+type PartnerContract struct {
+	ContractID            uuid.UUID      `json:"contract_id" db:"contract_id"`
+	PartnerID             uuid.UUID      `json:"partner_id" db:"partner_id"`
+	Version               int            `json:"version" db:"version"`
+	ContractNumber        *string        `json:"contract_number,omitempty" db:"contract_number"`
+	RevenueSharePercent   float64        `json:"revenue_share_percent" db:"revenue_share_percent"`
+	DataCostMarkupPercent float64        `json:"data_cost_markup_percent" db:"data_cost_markup_percent"`
+	Currency              string         `json:"currency" db:"currency"`
+	ValidFrom             time.Time      `json:"valid_from" db:"valid_from"`
+	ValidTo               *time.Time     `json:"valid_to,omitempty" db:"valid_to"`
+	Status                ContractStatus `json:"status" db:"status"`
+	Notes                 *string        `json:"notes,omitempty" db:"notes"`
+	CreatedBy             string         `json:"created_by" db:"created_by"`
+	CreatedAt             time.Time      `json:"created_at" db:"created_at"`
+	ActivatedBy           *string        `json:"activated_by,omitempty" db:"activated_by"`
+	ActivatedAt           *time.Time     `json:"activated_at,omitempty" db:"activated_at"`
+	TerminationReason     *string        `json:"termination_reason,omitempty" db:"termination_reason"`
+	UpdatedAt             time.Time      `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IAccountStateRepository interface {
+	SetDeactivated(userID string, deactivated bool, changedAt time.Time) error
+	SetMarketingConsent(userID string, granted bool, changedAt time.Time) error
+}
+
+type AccountStateRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountStateRepository(db *sqlx.DB) IAccountStateRepository {
+	return &AccountStateRepository{
+		db: db,
+	}
+}
+
+// SetDeactivated records the account status of the user, unless a newer change was already recorded
+func (r *AccountStateRepository) SetDeactivated(userID string, deactivated bool, changedAt time.Time) error {
+	query := `
+		INSERT INTO account_states (user_id, deactivated, status_changed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET deactivated = EXCLUDED.deactivated,
+			status_changed_at = EXCLUDED.status_changed_at,
+			updated_at = NOW()
+		WHERE account_states.status_changed_at IS NULL
+			OR account_states.status_changed_at < EXCLUDED.status_changed_at`
+
+	if _, err := r.db.Exec(query, userID, deactivated, changedAt); err != nil {
+		return fmt.Errorf("failed to set account status: %w", err)
+	}
+	return nil
+}
+
+// SetMarketingConsent records the marketing consent decision of the user, unless a newer decision
+// was already recorded
+func (r *AccountStateRepository) SetMarketingConsent(userID string, granted bool, changedAt time.Time) error {
+	query := `
+		INSERT INTO account_states (user_id, marketing_consent, marketing_consent_changed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET marketing_consent = EXCLUDED.marketing_consent,
+			marketing_consent_changed_at = EXCLUDED.marketing_consent_changed_at,
+			updated_at = NOW()
+		WHERE account_states.marketing_consent_changed_at IS NULL
+			OR account_states.marketing_consent_changed_at < EXCLUDED.marketing_consent_changed_at`
+
+	if _, err := r.db.Exec(query, userID, granted, changedAt); err != nil {
+		return fmt.Errorf("failed to set marketing consent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IFarmerProfileRepository interface {
+	GetByUserID(userID string) (*models.FarmerProfile, error)
+	Upsert(profile *models.FarmerProfile) error
+	GetPayoutAccount(userID string) (*models.FarmerPayoutAccount, error)
+	UpdatePayoutAccount(account *models.FarmerPayoutAccount, updatedBy string) error
+	GetPreferredLanguages(userIDs []string) ([]models.FarmerLanguagePreference, error)
+	GetAudience(req *models.GetFarmerAudienceRequest) ([]models.FarmerAudienceMember, error)
+}
+
+type FarmerProfileRepository struct {
+	db *sqlx.DB
+}
+
+func NewFarmerProfileRepository(db *sqlx.DB) IFarmerProfileRepository {
+	return &FarmerProfileRepository{
+		db: db,
+	}
+}
+
+func (r *FarmerProfileRepository) GetByUserID(userID string) (*models.FarmerProfile, error) {
+	var profile models.FarmerProfile
+	query := `
+		SELECT user_id, household_head_name, household_size, dependents_count, farming_experience_years,
+			annual_household_income, preferred_language, cooperative_name, cooperative_code, cooperative_role,
+			cooperative_joined_at, created_at, updated_at
+		FROM farmer_profiles
+		WHERE user_id = $1`
+	if err := r.db.Get(&profile, query, userID); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *FarmerProfileRepository) Upsert(profile *models.FarmerProfile) error {
+	query := `
+		INSERT INTO farmer_profiles (
+			user_id, household_head_name, household_size, dependents_count, farming_experience_years,
+			annual_household_income, preferred_language, cooperative_name, cooperative_code, cooperative_role,
+			cooperative_joined_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (user_id) DO UPDATE
+		SET household_head_name = EXCLUDED.household_head_name,
+			household_size = EXCLUDED.household_size,
+			dependents_count = EXCLUDED.dependents_count,
+			farming_experience_years = EXCLUDED.farming_experience_years,
+			annual_household_income = EXCLUDED.annual_household_income,
+			preferred_language = EXCLUDED.preferred_language,
+			cooperative_name = EXCLUDED.cooperative_name,
+			cooperative_code = EXCLUDED.cooperative_code,
+			cooperative_role = EXCLUDED.cooperative_role,
+			cooperative_joined_at = EXCLUDED.cooperative_joined_at,
+			updated_at = NOW()
+		RETURNING created_at, updated_at`
+	err := r.db.QueryRow(query,
+		profile.UserID, profile.HouseholdHeadName, profile.HouseholdSize, profile.DependentsCount,
+		profile.FarmingExperienceYears, profile.AnnualHouseholdIncome, profile.PreferredLanguage,
+		profile.CooperativeName, profile.CooperativeCode, profile.CooperativeRole, profile.CooperativeJoinedAt,
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		slog.Error("Error saving farmer profile", "user_id", profile.UserID, "error", err)
+		return fmt.Errorf("failed to save farmer profile: %w", err)
+	}
+	return nil
+}
+
+// GetPayoutAccount returns the bank account claim payouts are sent to; it fails with
+// "no rows in result set" when the user has not registered one
+func (r *FarmerProfileRepository) GetPayoutAccount(userID string) (*models.FarmerPayoutAccount, error) {
+	var account models.FarmerPayoutAccount
+	query := `
+		SELECT user_id, account_number, COALESCE(account_name, '') AS account_name, bank_code
+		FROM user_profiles
+		WHERE user_id = $1 AND COALESCE(account_number, '') <> '' AND COALESCE(bank_code, '') <> ''`
+	if err := r.db.Get(&account, query, userID); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *FarmerProfileRepository) UpdatePayoutAccount(account *models.FarmerPayoutAccount, updatedBy string) error {
+	result, err := r.db.Exec(`
+		UPDATE user_profiles
+		SET account_number = $2, account_name = $3, bank_code = $4, last_updated_by = $5, updated_at = NOW()
+		WHERE user_id = $1`,
+		account.UserID, account.AccountNumber, account.AccountName, account.BankCode, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update payout account: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user profile: no rows in result set")
+	}
+	return nil
+}
+
+// GetPreferredLanguages returns the language of every known user; users without a farmer
+// profile default to Vietnamese
+func (r *FarmerProfileRepository) GetPreferredLanguages(userIDs []string) ([]models.FarmerLanguagePreference, error) {
+	preferences := []models.FarmerLanguagePreference{}
+	if len(userIDs) == 0 {
+		return preferences, nil
+	}
+	query := `
+		SELECT up.user_id, COALESCE(fp.preferred_language, 'vi') AS preferred_language
+		FROM user_profiles up
+		LEFT JOIN farmer_profiles fp ON fp.user_id = up.user_id
+		WHERE up.user_id = ANY($1)`
+	if err := r.db.Select(&preferences, query, pq.Array(userIDs)); err != nil {
+		slog.Error("Error fetching preferred languages", "error", err)
+		return nil, fmt.Errorf("failed to get preferred languages: %w", err)
+	}
+	return preferences, nil
+}
+
+// GetAudience returns the farmers matching the request after its cursor, in user ID order. Empty
+// code lists do not filter. Only farmers with an active account who consented to marketing are
+// returned.
+func (r *FarmerProfileRepository) GetAudience(req *models.GetFarmerAudienceRequest) ([]models.FarmerAudienceMember, error) {
+	members := []models.FarmerAudienceMember{}
+	query := `
+		SELECT up.user_id, up.primary_phone
+		FROM user_profiles up
+		JOIN farmer_profiles fp ON fp.user_id = up.user_id
+		JOIN account_states s ON s.user_id = up.user_id
+		WHERE up.user_id > $1
+			AND NOT s.deactivated
+			AND s.marketing_consent
+			AND (CARDINALITY($2::TEXT[]) = 0 OR up.province_code = ANY($2))
+			AND (CARDINALITY($3::TEXT[]) = 0 OR up.district_code = ANY($3))
+			AND (CARDINALITY($4::TEXT[]) = 0 OR fp.cooperative_code = ANY($4))
+		ORDER BY up.user_id
+		LIMIT $5`
+	err := r.db.Select(&members, query, req.AfterUserID, pq.Array(req.ProvinceCodes), pq.Array(req.DistrictCodes),
+		pq.Array(req.CooperativeCodes), req.Limit)
+	if err != nil {
+		slog.Error("Error fetching farmer audience", "error", err)
+		return nil, fmt.Errorf("failed to get farmer audience: %w", err)
+	}
+	return members, nil
+}
@@ -22,9 +22,12 @@ type IInsurancePartnerRepository interface {
 	CreateInsurancePartner(req models.CreateInsurancePartnerRequest, createdByID, createdByName string) error
 	GetPublicProfile(partnerID string) (*models.PublicPartnerProfile, error)
 	GetPrivateProfile(partnerID string) (*models.PrivatePartnerProfile, error)
+	GetPartnerSummary(partnerID string) (*models.PartnerSummary, error)
 	UpdateInsurancePartner(query string, args ...any) error
 	GetAllPublicProfiles() ([]models.PublicPartnerProfile, error)
 	GetAllPrivateProfiles() ([]models.PrivatePartnerProfile, error)
+	ListPublicProfiles(query models.PartnerListQuery) ([]models.PublicPartnerProfile, int, error)
+	ListPrivateProfiles(query models.PartnerListQuery) ([]models.PrivatePartnerProfile, int, error)
 	SearchDeletionRequestsByRequesterName(ctx context.Context, searchTerm string) ([]models.PartnerDeletionRequest, error)
 	CreateDeletionRequest(ctx context.Context, req *models.PartnerDeletionRequest) (*models.PartnerDeletionRequest, error)
 	GetDeletionRequestsByRequesterID(ctx context.Context, requesterID string) ([]models.DeletionRequestResponse, error)
@@ -118,7 +121,7 @@ func (r *InsurancePartnerRepository) GetPartnerReviews(partnerID string, sortBy
 			created_at,
 			updated_at
 		FROM partner_reviews
-		WHERE partner_id = $1
+		WHERE partner_id = $1 AND moderation_status = 'published'
 		ORDER BY %s
 		LIMIT $2 OFFSET $3
 	`, orderByClause)
@@ -240,6 +243,29 @@ func (r *InsurancePartnerRepository) CreateInsurancePartner(req models.CreateIns
 	return nil
 }
 
+func (r *InsurancePartnerRepository) GetPartnerSummary(partnerID string) (*models.PartnerSummary, error) {
+	var summary models.PartnerSummary
+	query := `
+		SELECT
+			ip.partner_id,
+			COALESCE(ip.partner_display_name, '') AS partner_display_name,
+			COALESCE(ip.partner_logo_url, '') AS partner_logo_url,
+			COALESCE(ip.partner_phone, '') AS partner_phone,
+			COALESCE(ip.partner_official_email, '') AS partner_official_email,
+			COALESCE(ip.hotline, '') AS hotline,
+			COALESCE(ip.partner_rating_score, 0.0) AS partner_rating_score,
+			COALESCE(ip.partner_rating_count, 0) AS partner_rating_count,
+			ip.status
+		FROM insurance_partners ip
+		WHERE ip.partner_id = $1
+	`
+	if err := r.db.Get(&summary, query, partnerID); err != nil {
+		log.Printf("Error getting partner summary for ID %s: %s", partnerID, err.Error())
+		return nil, err
+	}
+	return &summary, nil
+}
+
 func (r *InsurancePartnerRepository) GetPublicProfile(partnerID string) (*models.PublicPartnerProfile, error) {
 	var profile models.PublicPartnerProfile
 	query := `
@@ -423,7 +449,8 @@ func (r *InsurancePartnerRepository) GetPrivateProfile(partnerID string) (*model
 			COALESCE(ip.last_updated_by_name, '') AS last_updated_by_name,
 			COALESCE(ip.account_number, '') AS account_number,
 			COALESCE(ip.account_name, '') AS account_name,
-			COALESCE(ip.bank_code, '') AS bank_code
+			COALESCE(ip.bank_code, '') AS bank_code,
+			ip.compliance_flagged
 		FROM insurance_partners ip
 		WHERE ip.partner_id = $1
 	`
@@ -508,7 +535,8 @@ func (r *InsurancePartnerRepository) GetAllPrivateProfiles() ([]models.PrivatePa
 			-- D. Bank Account Information
 			ip.account_number,
 			COALESCE(ip.account_name, '') AS account_name,
-			COALESCE(ip.bank_code, '') AS bank_code
+			COALESCE(ip.bank_code, '') AS bank_code,
+			ip.compliance_flagged
 		FROM insurance_partners ip
 	`
 
@@ -526,6 +554,143 @@ func (r *InsurancePartnerRepository) GetAllPrivateProfiles() ([]models.PrivatePa
 	return profiles, nil
 }
 
+const publicPartnerProfileColumns = `
+	ip.partner_id,
+	COALESCE(ip.partner_display_name, '') AS partner_display_name,
+	COALESCE(ip.partner_logo_url, '') AS partner_logo_url,
+	COALESCE(ip.cover_photo_url, '') AS cover_photo_url,
+	COALESCE(ip.partner_tagline, '') AS partner_tagline,
+	COALESCE(ip.partner_description, '') AS partner_description,
+	COALESCE(ip.partner_phone, '') AS partner_phone,
+	COALESCE(ip.partner_official_email, '') AS partner_official_email,
+	COALESCE(ip.customer_service_hotline, '') AS customer_service_hotline,
+	COALESCE(ip.hotline, '') AS hotline,
+	COALESCE(ip.support_hours, '') AS support_hours,
+	COALESCE(ip.partner_website, '') AS partner_website,
+	COALESCE(ip.fax_number, '') AS fax_number,
+	COALESCE(ip.head_office_address, '') AS head_office_address,
+	COALESCE(ip.province_name, '') AS province_name,
+	COALESCE(ip.ward_name, '') AS ward_name,
+	COALESCE(ip.partner_rating_score, 0.0) AS partner_rating_score,
+	COALESCE(ip.partner_rating_count, 0) AS partner_rating_count,
+	COALESCE(ip.trust_metric_experience, 0) AS trust_metric_experience,
+	COALESCE(ip.trust_metric_clients, 0) AS trust_metric_clients,
+	COALESCE(ip.trust_metric_claim_rate, 0) AS trust_metric_claim_rate,
+	COALESCE(ip.total_payouts, '') AS total_payouts,
+	COALESCE(ip.average_payout_time, '') AS average_payout_time,
+	COALESCE(ip.confirmation_timeline, '') AS confirmation_timeline,
+	COALESCE(ip.coverage_areas, '') AS coverage_areas,
+	COALESCE(ip.year_established, 0) AS year_established`
+
+const privatePartnerProfileColumns = publicPartnerProfileColumns + `,
+	ip.legal_company_name,
+	COALESCE(ip.partner_trading_name, '') AS partner_trading_name,
+	COALESCE(ip.company_type, '') AS company_type,
+	COALESCE(ip.incorporation_date, '1970-01-01'::date) AS incorporation_date,
+	ip.tax_identification_number,
+	ip.business_registration_number,
+	COALESCE(ip.insurance_license_number, '') AS insurance_license_number,
+	ip.license_issue_date,
+	ip.license_expiry_date,
+	COALESCE(ip.authorized_insurance_lines, ARRAY[]::TEXT[]) AS authorized_insurance_lines,
+	COALESCE(ip.operating_provinces, ARRAY[]::TEXT[]) AS operating_provinces,
+	COALESCE(ip.legal_document_urls, ARRAY[]::TEXT[]) AS legal_document_urls,
+	COALESCE(ip.province_code, '') AS province_code,
+	COALESCE(ip.ward_code, '') AS ward_code,
+	COALESCE(ip.postal_code, '') AS postal_code,
+	ip.status,
+	ip.created_at,
+	ip.updated_at,
+	COALESCE(ip.last_updated_by_id, '') AS last_updated_by_id,
+	COALESCE(ip.last_updated_by_name, '') AS last_updated_by_name,
+	ip.account_number,
+	ip.account_name,
+	ip.bank_code,
+	ip.compliance_flagged`
+
+// partnerListSortColumns whitelists the sort_by values of the partner listings
+var partnerListSortColumns = map[string]string{
+	"partner_display_name": "ip.partner_display_name",
+	"legal_company_name":   "ip.legal_company_name",
+	"partner_rating_score": "ip.partner_rating_score",
+	"partner_rating_count": "ip.partner_rating_count",
+	"year_established":     "ip.year_established",
+	"created_at":           "ip.created_at",
+}
+
+// buildPartnerListQuery returns the WHERE and ORDER BY clauses of a partner listing.
+// The query must already be validated by the service.
+func buildPartnerListQuery(query models.PartnerListQuery) (where, orderBy string, args []any) {
+	conditions := []string{"1 = 1"}
+	if query.Status != "" {
+		args = append(args, query.Status)
+		conditions = append(conditions, fmt.Sprintf("ip.status = $%d", len(args)))
+	}
+	if query.ProvinceCode != "" {
+		args = append(args, query.ProvinceCode)
+		conditions = append(conditions, fmt.Sprintf("($%d = ANY(ip.operating_provinces) OR ip.province_code = $%d)", len(args), len(args)))
+	}
+	if query.Search != "" {
+		escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+		args = append(args, "%"+escaper.Replace(query.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf(`(ip.legal_company_name ILIKE $%[1]d
+			OR ip.partner_display_name ILIKE $%[1]d
+			OR ip.partner_trading_name ILIKE $%[1]d
+			OR ip.tax_identification_number ILIKE $%[1]d)`, len(args)))
+	}
+
+	orderBy = fmt.Sprintf("%s %s NULLS LAST, ip.partner_id ASC", partnerListSortColumns[query.SortBy], strings.ToUpper(query.SortDirection))
+	return strings.Join(conditions, " AND "), orderBy, args
+}
+
+func (r *InsurancePartnerRepository) countPartners(where string, args []any) (int, error) {
+	var total int
+	if err := r.db.Get(&total, "SELECT COUNT(*) FROM insurance_partners ip WHERE "+where, args...); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ListPublicProfiles returns one page of public profiles matching the query, with the total match count
+func (r *InsurancePartnerRepository) ListPublicProfiles(query models.PartnerListQuery) ([]models.PublicPartnerProfile, int, error) {
+	where, orderBy, args := buildPartnerListQuery(query)
+	total, err := r.countPartners(where, args)
+	if err != nil {
+		slog.Error("Error counting public profiles", "error", err)
+		return nil, 0, fmt.Errorf("failed to list insurance partners: %w", err)
+	}
+
+	profiles := []models.PublicPartnerProfile{}
+	sqlQuery := fmt.Sprintf(`SELECT %s FROM insurance_partners ip WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		publicPartnerProfileColumns, where, orderBy, len(args)+1, len(args)+2)
+	args = append(args, query.Limit, (query.Page-1)*query.Limit)
+	if err := r.db.Select(&profiles, sqlQuery, args...); err != nil {
+		slog.Error("Error listing public profiles", "error", err)
+		return nil, 0, fmt.Errorf("failed to list insurance partners: %w", err)
+	}
+	return profiles, total, nil
+}
+
+// ListPrivateProfiles returns one page of private profiles matching the query, with the total match count
+func (r *InsurancePartnerRepository) ListPrivateProfiles(query models.PartnerListQuery) ([]models.PrivatePartnerProfile, int, error) {
+	where, orderBy, args := buildPartnerListQuery(query)
+	total, err := r.countPartners(where, args)
+	if err != nil {
+		slog.Error("Error counting private profiles", "error", err)
+		return nil, 0, fmt.Errorf("failed to list insurance partners: %w", err)
+	}
+
+	profiles := []models.PrivatePartnerProfile{}
+	sqlQuery := fmt.Sprintf(`SELECT %s FROM insurance_partners ip WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		privatePartnerProfileColumns, where, orderBy, len(args)+1, len(args)+2)
+	args = append(args, query.Limit, (query.Page-1)*query.Limit)
+	if err := r.db.Select(&profiles, sqlQuery, args...); err != nil {
+		slog.Error("Error listing private profiles", "error", err)
+		return nil, 0, fmt.Errorf("failed to list insurance partners: %w", err)
+	}
+	return profiles, total, nil
+}
+
 func (r *InsurancePartnerRepository) UpdateInsurancePartner(query string, args ...any) error {
 	if err := utils.ExecWithCheck(r.db, query, utils.ExecUpdate, args...); err != nil {
 		return fmt.Errorf("failed to update insurance partner: %w", err)
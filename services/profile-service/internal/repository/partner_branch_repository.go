@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerBranchRepository interface {
+	CreateBranch(branch *models.PartnerBranch) error
+	GetBranchByID(partnerID, branchID string) (*models.PartnerBranch, error)
+	GetBranchesByPartnerID(partnerID string, activeOnly bool) ([]models.PartnerBranch, error)
+	UpdateBranch(branch *models.PartnerBranch) error
+	DeactivateBranch(partnerID, branchID string) error
+}
+
+type PartnerBranchRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerBranchRepository(db *sqlx.DB) IPartnerBranchRepository {
+	return &PartnerBranchRepository{
+		db: db,
+	}
+}
+
+func (r *PartnerBranchRepository) CreateBranch(branch *models.PartnerBranch) error {
+	query := `
+		INSERT INTO partner_branches (
+			partner_id, branch_code, branch_name, address,
+			province_code, province_name, ward_code, ward_name, latitude, longitude,
+			contact_name, contact_phone, contact_email, service_area_provinces, created_by
+		) VALUES (
+			:partner_id, :branch_code, :branch_name, :address,
+			:province_code, :province_name, :ward_code, :ward_name, :latitude, :longitude,
+			:contact_name, :contact_phone, :contact_email, :service_area_provinces, :created_by
+		)
+		RETURNING branch_id, is_active, created_at, updated_at`
+
+	rows, err := r.db.NamedQuery(query, branch)
+	if err != nil {
+		slog.Error("Error creating partner branch", "partner_id", branch.PartnerID, "error", err)
+		return fmt.Errorf("failed to create partner branch: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&branch.BranchID, &branch.IsActive, &branch.CreatedAt, &branch.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to scan created partner branch: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *PartnerBranchRepository) GetBranchByID(partnerID, branchID string) (*models.PartnerBranch, error) {
+	var branch models.PartnerBranch
+	err := r.db.Get(&branch, `SELECT * FROM partner_branches WHERE partner_id = $1 AND branch_id = $2`, partnerID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *PartnerBranchRepository) GetBranchesByPartnerID(partnerID string, activeOnly bool) ([]models.PartnerBranch, error) {
+	branches := []models.PartnerBranch{}
+	query := `SELECT * FROM partner_branches WHERE partner_id = $1`
+	if activeOnly {
+		query += ` AND is_active = TRUE`
+	}
+	query += ` ORDER BY branch_code ASC`
+
+	if err := r.db.Select(&branches, query, partnerID); err != nil {
+		slog.Error("Error fetching partner branches", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get partner branches: %w", err)
+	}
+	return branches, nil
+}
+
+func (r *PartnerBranchRepository) UpdateBranch(branch *models.PartnerBranch) error {
+	query := `
+		UPDATE partner_branches
+		SET branch_name = :branch_name,
+			address = :address,
+			province_code = :province_code,
+			province_name = :province_name,
+			ward_code = :ward_code,
+			ward_name = :ward_name,
+			latitude = :latitude,
+			longitude = :longitude,
+			contact_name = :contact_name,
+			contact_phone = :contact_phone,
+			contact_email = :contact_email,
+			service_area_provinces = :service_area_provinces,
+			is_active = :is_active,
+			updated_at = NOW()
+		WHERE partner_id = :partner_id AND branch_id = :branch_id`
+
+	result, err := r.db.NamedExec(query, branch)
+	if err != nil {
+		return fmt.Errorf("failed to update partner branch: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("partner branch: no rows in result set")
+	}
+	return nil
+}
+
+func (r *PartnerBranchRepository) DeactivateBranch(partnerID, branchID string) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_branches
+		SET is_active = FALSE, updated_at = NOW()
+		WHERE partner_id = $1 AND branch_id = $2`,
+		partnerID, branchID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate partner branch: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("partner branch: no rows in result set")
+	}
+	return nil
+}
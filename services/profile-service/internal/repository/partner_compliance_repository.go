@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerComplianceRepository interface {
+	GetDocumentsByPartnerID(partnerID string) ([]models.PartnerComplianceDocument, error)
+	GetDocumentByID(partnerID, documentID string) (*models.PartnerComplianceDocument, error)
+	CreateDocument(document *models.PartnerComplianceDocument) error
+	RenewDocument(document *models.PartnerComplianceDocument) error
+	DeleteDocument(partnerID, documentID string) error
+	SyncProfileLicenses() (int64, error)
+	GetDocumentsExpiringWithin(days int) ([]models.PartnerComplianceDocument, error)
+	MarkReminderSent(documentID string, days int) error
+	GetNewlyLapsedDocuments() ([]models.PartnerComplianceDocument, error)
+	MarkLapsed(documentID string) error
+	RefreshComplianceFlags() (int64, error)
+	GetPartnerAdminUserIDs(partnerID string) ([]string, error)
+}
+
+type PartnerComplianceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerComplianceRepository(db *sqlx.DB) IPartnerComplianceRepository {
+	return &PartnerComplianceRepository{
+		db: db,
+	}
+}
+
+const complianceDocumentSelect = `
+	SELECT document_id, partner_id, document_type, document_name, document_number, issued_at, expires_at,
+		file_id, source, last_reminder_days, lapsed_at, created_by, created_at, updated_at
+	FROM partner_compliance_documents`
+
+func (r *PartnerComplianceRepository) GetDocumentsByPartnerID(partnerID string) ([]models.PartnerComplianceDocument, error) {
+	documents := []models.PartnerComplianceDocument{}
+	if err := r.db.Select(&documents, complianceDocumentSelect+` WHERE partner_id = $1 ORDER BY expires_at ASC`, partnerID); err != nil {
+		slog.Error("Error fetching compliance documents", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get compliance documents: %w", err)
+	}
+	return documents, nil
+}
+
+func (r *PartnerComplianceRepository) GetDocumentByID(partnerID, documentID string) (*models.PartnerComplianceDocument, error) {
+	var document models.PartnerComplianceDocument
+	if err := r.db.Get(&document, complianceDocumentSelect+` WHERE partner_id = $1 AND document_id = $2`, partnerID, documentID); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (r *PartnerComplianceRepository) CreateDocument(document *models.PartnerComplianceDocument) error {
+	query := `
+		INSERT INTO partner_compliance_documents (
+			partner_id, document_type, document_name, document_number, issued_at, expires_at, file_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING document_id, source, created_at, updated_at`
+	err := r.db.QueryRow(query,
+		document.PartnerID, document.DocumentType, document.DocumentName, document.DocumentNumber,
+		document.IssuedAt, document.ExpiresAt, document.FileID, document.CreatedBy,
+	).Scan(&document.DocumentID, &document.Source, &document.CreatedAt, &document.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create compliance document: %w", err)
+	}
+	return nil
+}
+
+// RenewDocument stores a new expiry date and restarts the reminder cycle for it
+func (r *PartnerComplianceRepository) RenewDocument(document *models.PartnerComplianceDocument) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_compliance_documents
+		SET document_number = $3, issued_at = $4, expires_at = $5, file_id = $6,
+			last_reminder_days = NULL, lapsed_at = NULL, updated_at = NOW()
+		WHERE partner_id = $1 AND document_id = $2 AND source = 'manual'`,
+		document.PartnerID, document.DocumentID, document.DocumentNumber, document.IssuedAt, document.ExpiresAt, document.FileID)
+	if err != nil {
+		return fmt.Errorf("failed to renew compliance document: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("compliance document: no rows in result set")
+	}
+	return nil
+}
+
+func (r *PartnerComplianceRepository) DeleteDocument(partnerID, documentID string) error {
+	result, err := r.db.Exec(`DELETE FROM partner_compliance_documents WHERE partner_id = $1 AND document_id = $2 AND source = 'manual'`, partnerID, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete compliance document: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("compliance document: no rows in result set")
+	}
+	return nil
+}
+
+// SyncProfileLicenses mirrors the insurance license expiry on each partner profile into
+// partner_compliance_documents. A changed expiry date counts as a renewal.
+func (r *PartnerComplianceRepository) SyncProfileLicenses() (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO partner_compliance_documents (
+			partner_id, document_type, document_name, document_number, issued_at, expires_at, source, created_by
+		)
+		SELECT partner_id, 'insurance_license', 'Insurance business license', NULLIF(insurance_license_number, ''),
+			license_issue_date, license_expiry_date, 'partner_profile', 'system'
+		FROM insurance_partners
+		WHERE license_expiry_date IS NOT NULL AND status <> 'terminated'
+		ON CONFLICT (partner_id) WHERE source = 'partner_profile' DO UPDATE
+		SET document_number = EXCLUDED.document_number,
+			issued_at = EXCLUDED.issued_at,
+			expires_at = EXCLUDED.expires_at,
+			last_reminder_days = NULL,
+			lapsed_at = NULL,
+			updated_at = NOW()
+		WHERE partner_compliance_documents.expires_at IS DISTINCT FROM EXCLUDED.expires_at
+			OR partner_compliance_documents.document_number IS DISTINCT FROM EXCLUDED.document_number`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sync partner licenses: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetDocumentsExpiringWithin returns documents of non-terminated partners that have not lapsed
+// and expire within the given number of days
+func (r *PartnerComplianceRepository) GetDocumentsExpiringWithin(days int) ([]models.PartnerComplianceDocument, error) {
+	documents := []models.PartnerComplianceDocument{}
+	query := complianceDocumentSelect + `
+		WHERE lapsed_at IS NULL
+			AND expires_at >= CURRENT_DATE
+			AND expires_at <= CURRENT_DATE + $1::int
+			AND partner_id IN (SELECT partner_id FROM insurance_partners WHERE status <> 'terminated')
+		ORDER BY expires_at ASC`
+	if err := r.db.Select(&documents, query, days); err != nil {
+		return nil, fmt.Errorf("failed to get expiring compliance documents: %w", err)
+	}
+	return documents, nil
+}
+
+func (r *PartnerComplianceRepository) MarkReminderSent(documentID string, days int) error {
+	_, err := r.db.Exec(`UPDATE partner_compliance_documents SET last_reminder_days = $2 WHERE document_id = $1`, documentID, days)
+	if err != nil {
+		return fmt.Errorf("failed to mark reminder sent: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerComplianceRepository) GetNewlyLapsedDocuments() ([]models.PartnerComplianceDocument, error) {
+	documents := []models.PartnerComplianceDocument{}
+	query := complianceDocumentSelect + `
+		WHERE lapsed_at IS NULL
+			AND expires_at < CURRENT_DATE
+			AND partner_id IN (SELECT partner_id FROM insurance_partners WHERE status <> 'terminated')`
+	if err := r.db.Select(&documents, query); err != nil {
+		return nil, fmt.Errorf("failed to get lapsed compliance documents: %w", err)
+	}
+	return documents, nil
+}
+
+// MarkLapsed records that a document expired and flags its partner
+func (r *PartnerComplianceRepository) MarkLapsed(documentID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var partnerID string
+	err = tx.QueryRow(`
+		UPDATE partner_compliance_documents SET lapsed_at = NOW()
+		WHERE document_id = $1 AND lapsed_at IS NULL
+		RETURNING partner_id`, documentID).Scan(&partnerID)
+	if err != nil {
+		return fmt.Errorf("failed to mark compliance document lapsed: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE insurance_partners SET compliance_flagged = TRUE, updated_at = NOW() WHERE partner_id = $1`, partnerID); err != nil {
+		return fmt.Errorf("failed to flag partner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RefreshComplianceFlags sets compliance_flagged on exactly the partners that have a lapsed document,
+// which clears the flag once every lapsed document is renewed or removed
+func (r *PartnerComplianceRepository) RefreshComplianceFlags() (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE insurance_partners ip
+		SET compliance_flagged = flagged.value, updated_at = NOW()
+		FROM (
+			SELECT p.partner_id, EXISTS (
+				SELECT 1 FROM partner_compliance_documents d
+				WHERE d.partner_id = p.partner_id AND d.lapsed_at IS NOT NULL
+			) AS value
+			FROM insurance_partners p
+		) flagged
+		WHERE ip.partner_id = flagged.partner_id AND ip.compliance_flagged <> flagged.value`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh compliance flags: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetPartnerAdminUserIDs returns the users who receive compliance reminders for a partner: active
+// partner admins, and partner accounts linked before staff memberships existed
+func (r *PartnerComplianceRepository) GetPartnerAdminUserIDs(partnerID string) ([]string, error) {
+	userIDs := []string{}
+	query := `
+		SELECT user_id FROM partner_staff_members
+		WHERE partner_id = $1 AND status = 'active' AND staff_role = 'partner_admin'
+		UNION
+		SELECT user_id FROM user_profiles up
+		WHERE up.partner_id = $1 AND up.role_id = 'admin_partner'
+			AND NOT EXISTS (SELECT 1 FROM partner_staff_members m WHERE m.partner_id = $1 AND m.user_id = up.user_id)`
+	if err := r.db.Select(&userIDs, query, partnerID); err != nil {
+		return nil, fmt.Errorf("failed to get partner admins: %w", err)
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerContractRepository interface {
+	GetContractsByPartnerID(partnerID string) ([]models.PartnerContract, error)
+	GetContractByID(partnerID, contractID string) (*models.PartnerContract, error)
+	GetActiveContract(partnerID string) (*models.PartnerContract, error)
+	CreateContract(contract *models.PartnerContract) error
+	ActivateContract(partnerID, contractID, activatedBy string) error
+	TerminateContract(partnerID, contractID string, validTo time.Time, reason string) error
+	DeleteDraftContract(partnerID, contractID string) error
+	GetTermsInEffect(partnerID string, date time.Time) (*models.PartnerContractTerms, error)
+}
+
+type PartnerContractRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerContractRepository(db *sqlx.DB) IPartnerContractRepository {
+	return &PartnerContractRepository{
+		db: db,
+	}
+}
+
+const partnerContractColumns = `
+	contract_id, partner_id, version, contract_number, revenue_share_percent, data_cost_markup_percent,
+	currency, valid_from, valid_to, status, notes, created_by, created_at, activated_by, activated_at,
+	termination_reason, updated_at`
+
+func (r *PartnerContractRepository) GetContractsByPartnerID(partnerID string) ([]models.PartnerContract, error) {
+	contracts := []models.PartnerContract{}
+	query := `SELECT ` + partnerContractColumns + `
+		FROM partner_contracts
+		WHERE partner_id = $1
+		ORDER BY version DESC`
+	if err := r.db.Select(&contracts, query, partnerID); err != nil {
+		slog.Error("Error fetching partner contracts", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get partner contracts: %w", err)
+	}
+	return contracts, nil
+}
+
+func (r *PartnerContractRepository) GetContractByID(partnerID, contractID string) (*models.PartnerContract, error) {
+	var contract models.PartnerContract
+	query := `SELECT ` + partnerContractColumns + `
+		FROM partner_contracts
+		WHERE partner_id = $1 AND contract_id = $2`
+	if err := r.db.Get(&contract, query, partnerID, contractID); err != nil {
+		return nil, fmt.Errorf("failed to get partner contract: %w", err)
+	}
+	return &contract, nil
+}
+
+func (r *PartnerContractRepository) GetActiveContract(partnerID string) (*models.PartnerContract, error) {
+	var contract models.PartnerContract
+	query := `SELECT ` + partnerContractColumns + `
+		FROM partner_contracts
+		WHERE partner_id = $1 AND status = 'active'`
+	if err := r.db.Get(&contract, query, partnerID); err != nil {
+		return nil, fmt.Errorf("failed to get active partner contract: %w", err)
+	}
+	return &contract, nil
+}
+
+// CreateContract stores a draft with the next version number of the partner. Two drafts
+// created at once collide on the version constraint instead of sharing a number.
+func (r *PartnerContractRepository) CreateContract(contract *models.PartnerContract) error {
+	query := `
+		INSERT INTO partner_contracts (
+			partner_id, version, contract_number, revenue_share_percent, data_cost_markup_percent,
+			currency, valid_from, valid_to, notes, created_by
+		)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9
+		FROM partner_contracts
+		WHERE partner_id = $1
+		RETURNING contract_id, version, status, created_at, updated_at`
+	err := r.db.QueryRowx(query,
+		contract.PartnerID, contract.ContractNumber, contract.RevenueSharePercent, contract.DataCostMarkupPercent,
+		contract.Currency, contract.ValidFrom, contract.ValidTo, contract.Notes, contract.CreatedBy).
+		Scan(&contract.ContractID, &contract.Version, &contract.Status, &contract.CreatedAt, &contract.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create partner contract: %w", err)
+	}
+	return nil
+}
+
+// ActivateContract makes a draft the active contract. The contract it supersedes stays valid
+// until the day before the new one starts.
+func (r *PartnerContractRepository) ActivateContract(partnerID, contractID, activatedBy string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var validFrom time.Time
+	err = tx.Get(&validFrom, `
+		SELECT valid_from FROM partner_contracts
+		WHERE partner_id = $1 AND contract_id = $2 AND status = 'draft'
+		FOR UPDATE`, partnerID, contractID)
+	if err != nil {
+		return fmt.Errorf("failed to lock draft contract: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE partner_contracts
+		SET status = 'superseded',
+			valid_to = LEAST(COALESCE(valid_to, $2::date - 1), $2::date - 1),
+			updated_at = NOW()
+		WHERE partner_id = $1 AND status = 'active'`, partnerID, validFrom)
+	if err != nil {
+		return fmt.Errorf("failed to supersede active contract: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE partner_contracts
+		SET status = 'active', activated_by = $3, activated_at = NOW(), updated_at = NOW()
+		WHERE partner_id = $1 AND contract_id = $2`, partnerID, contractID, activatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to activate contract: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerContractRepository) TerminateContract(partnerID, contractID string, validTo time.Time, reason string) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_contracts
+		SET status = 'terminated', valid_to = $3, termination_reason = $4, updated_at = NOW()
+		WHERE partner_id = $1 AND contract_id = $2 AND status = 'active'`,
+		partnerID, contractID, validTo, reason)
+	if err != nil {
+		return fmt.Errorf("failed to terminate contract: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invalid request: only the active contract can be terminated")
+	}
+	return nil
+}
+
+func (r *PartnerContractRepository) DeleteDraftContract(partnerID, contractID string) error {
+	result, err := r.db.Exec(`
+		DELETE FROM partner_contracts
+		WHERE partner_id = $1 AND contract_id = $2 AND status = 'draft'`,
+		partnerID, contractID)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft contract: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invalid request: only draft contracts can be deleted")
+	}
+	return nil
+}
+
+// GetTermsInEffect returns the terms of the latest activated contract whose validity period covers date
+func (r *PartnerContractRepository) GetTermsInEffect(partnerID string, date time.Time) (*models.PartnerContractTerms, error) {
+	var terms models.PartnerContractTerms
+	query := `
+		SELECT partner_id, contract_id, version, revenue_share_percent, data_cost_markup_percent,
+			currency, valid_from, valid_to
+		FROM partner_contracts
+		WHERE partner_id = $1
+			AND status IN ('active', 'superseded', 'terminated')
+			AND valid_from <= $2::date
+			AND (valid_to IS NULL OR valid_to >= $2::date)
+		ORDER BY version DESC
+		LIMIT 1`
+	if err := r.db.Get(&terms, query, partnerID, date); err != nil {
+		return nil, fmt.Errorf("failed to get contract terms: %w", err)
+	}
+	return &terms, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerFileRepository interface {
+	CreateFile(file *models.PartnerFile, ref string) error
+	GetFileByID(partnerID, fileID string) (*models.PartnerFile, error)
+	GetFilesByPartnerID(partnerID string, fileType models.PartnerFileType) ([]models.PartnerFile, error)
+	DeleteFile(file *models.PartnerFile, ref string) error
+}
+
+type PartnerFileRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerFileRepository(db *sqlx.DB) IPartnerFileRepository {
+	return &PartnerFileRepository{
+		db: db,
+	}
+}
+
+// partnerFileAttachQueries link an uploaded file into the partner profile columns
+var partnerFileAttachQueries = map[models.PartnerFileType]string{
+	models.PartnerFileLogo:       `UPDATE insurance_partners SET partner_logo_url = $2, updated_at = CURRENT_TIMESTAMP WHERE partner_id = $1`,
+	models.PartnerFileCoverPhoto: `UPDATE insurance_partners SET cover_photo_url = $2, updated_at = CURRENT_TIMESTAMP WHERE partner_id = $1`,
+	models.PartnerFileLicense:    `UPDATE insurance_partners SET legal_document_urls = array_append(COALESCE(legal_document_urls, ARRAY[]::TEXT[]), $2), updated_at = CURRENT_TIMESTAMP WHERE partner_id = $1`,
+}
+
+var partnerFileDetachQueries = map[models.PartnerFileType]string{
+	models.PartnerFileLogo:       `UPDATE insurance_partners SET partner_logo_url = '', updated_at = CURRENT_TIMESTAMP WHERE partner_id = $1 AND partner_logo_url = $2`,
+	models.PartnerFileCoverPhoto: `UPDATE insurance_partners SET cover_photo_url = '', updated_at = CURRENT_TIMESTAMP WHERE partner_id = $1 AND cover_photo_url = $2`,
+	models.PartnerFileLicense:    `UPDATE insurance_partners SET legal_document_urls = array_remove(legal_document_urls, $2), updated_at = CURRENT_TIMESTAMP WHERE partner_id = $1`,
+}
+
+// CreateFile records an uploaded file and links it into the partner profile
+func (r *PartnerFileRepository) CreateFile(file *models.PartnerFile, ref string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO partner_files (partner_id, file_type, bucket_name, object_name, file_name, content_type, size_bytes, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING file_id, uploaded_at`,
+		file.PartnerID, file.FileType, file.BucketName, file.ObjectName, file.FileName, file.ContentType, file.SizeBytes, file.UploadedBy,
+	).Scan(&file.FileID, &file.UploadedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save partner file: %w", err)
+	}
+
+	if query, ok := partnerFileAttachQueries[file.FileType]; ok {
+		if _, err := tx.Exec(query, file.PartnerID, ref); err != nil {
+			return fmt.Errorf("failed to link partner file: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerFileRepository) GetFileByID(partnerID, fileID string) (*models.PartnerFile, error) {
+	var file models.PartnerFile
+	if err := r.db.Get(&file, `SELECT * FROM partner_files WHERE partner_id = $1 AND file_id = $2`, partnerID, fileID); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetFilesByPartnerID lists a partner's files, newest first; an empty fileType lists every type
+func (r *PartnerFileRepository) GetFilesByPartnerID(partnerID string, fileType models.PartnerFileType) ([]models.PartnerFile, error) {
+	files := []models.PartnerFile{}
+	query := `SELECT * FROM partner_files WHERE partner_id = $1 AND ($2 = '' OR file_type = $2) ORDER BY uploaded_at DESC`
+	if err := r.db.Select(&files, query, partnerID, string(fileType)); err != nil {
+		return nil, fmt.Errorf("failed to get partner files: %w", err)
+	}
+	return files, nil
+}
+
+// DeleteFile removes a file record and unlinks it from the partner profile
+func (r *PartnerFileRepository) DeleteFile(file *models.PartnerFile, ref string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM partner_files WHERE file_id = $1`, file.FileID); err != nil {
+		return fmt.Errorf("failed to delete partner file: %w", err)
+	}
+	if query, ok := partnerFileDetachQueries[file.FileType]; ok {
+		if _, err := tx.Exec(query, file.PartnerID, ref); err != nil {
+			return fmt.Errorf("failed to unlink partner file: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IPartnerIntegrationRepository interface {
+	GetSettings(partnerID string) (*models.PartnerIntegrationSettings, error)
+	UpdateWebhookSecret(partnerID, secret, updatedBy string) (*models.PartnerIntegrationSettings, error)
+	UpdateIPAllowlist(partnerID string, allowlist []string, updatedBy string) (*models.PartnerIntegrationSettings, error)
+	GetWebhooksByPartnerID(partnerID string) ([]models.PartnerWebhook, error)
+	GetWebhook(partnerID string, eventType models.WebhookEventType) (*models.PartnerWebhook, error)
+	UpsertWebhooks(partnerID string, webhooks []models.PartnerWebhook) error
+	DeleteWebhook(partnerID string, eventType models.WebhookEventType) error
+	CreateCredential(credential *models.PartnerAPICredential) error
+	GetCredentialsByPartnerID(partnerID string) ([]models.PartnerAPICredential, error)
+	GetCredentialByClientID(clientID string) (*models.PartnerAPICredential, error)
+	RevokeCredential(partnerID, credentialID, revokedBy string) error
+	TouchCredential(credentialID string) error
+}
+
+type PartnerIntegrationRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerIntegrationRepository(db *sqlx.DB) IPartnerIntegrationRepository {
+	return &PartnerIntegrationRepository{
+		db: db,
+	}
+}
+
+func (r *PartnerIntegrationRepository) GetSettings(partnerID string) (*models.PartnerIntegrationSettings, error) {
+	var settings models.PartnerIntegrationSettings
+	err := r.db.Get(&settings, `SELECT * FROM partner_integration_settings WHERE partner_id = $1`, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *PartnerIntegrationRepository) UpdateWebhookSecret(partnerID, secret, updatedBy string) (*models.PartnerIntegrationSettings, error) {
+	var settings models.PartnerIntegrationSettings
+	err := r.db.Get(&settings, `
+		INSERT INTO partner_integration_settings (partner_id, webhook_secret, webhook_secret_rotated_at, updated_by)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (partner_id) DO UPDATE
+		SET webhook_secret = EXCLUDED.webhook_secret,
+			webhook_secret_rotated_at = NOW(),
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING *`,
+		partnerID, secret, updatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook secret: %w", err)
+	}
+	return &settings, nil
+}
+
+func (r *PartnerIntegrationRepository) UpdateIPAllowlist(partnerID string, allowlist []string, updatedBy string) (*models.PartnerIntegrationSettings, error) {
+	var settings models.PartnerIntegrationSettings
+	err := r.db.Get(&settings, `
+		INSERT INTO partner_integration_settings (partner_id, ip_allowlist, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (partner_id) DO UPDATE
+		SET ip_allowlist = EXCLUDED.ip_allowlist,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING *`,
+		partnerID, pq.StringArray(allowlist), updatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ip allowlist: %w", err)
+	}
+	return &settings, nil
+}
+
+func (r *PartnerIntegrationRepository) GetWebhooksByPartnerID(partnerID string) ([]models.PartnerWebhook, error) {
+	webhooks := []models.PartnerWebhook{}
+	if err := r.db.Select(&webhooks, `SELECT * FROM partner_webhooks WHERE partner_id = $1 ORDER BY event_type ASC`, partnerID); err != nil {
+		slog.Error("Error fetching partner webhooks", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get partner webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *PartnerIntegrationRepository) GetWebhook(partnerID string, eventType models.WebhookEventType) (*models.PartnerWebhook, error) {
+	var webhook models.PartnerWebhook
+	err := r.db.Get(&webhook, `SELECT * FROM partner_webhooks WHERE partner_id = $1 AND event_type = $2`, partnerID, eventType)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *PartnerIntegrationRepository) UpsertWebhooks(partnerID string, webhooks []models.PartnerWebhook) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, webhook := range webhooks {
+		_, err := tx.Exec(`
+			INSERT INTO partner_webhooks (partner_id, event_type, url, is_active)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (partner_id, event_type) DO UPDATE
+			SET url = EXCLUDED.url,
+				is_active = EXCLUDED.is_active,
+				updated_at = NOW()`,
+			partnerID, webhook.EventType, webhook.URL, webhook.IsActive)
+		if err != nil {
+			return fmt.Errorf("failed to save webhook %s: %w", webhook.EventType, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerIntegrationRepository) DeleteWebhook(partnerID string, eventType models.WebhookEventType) error {
+	result, err := r.db.Exec(`DELETE FROM partner_webhooks WHERE partner_id = $1 AND event_type = $2`, partnerID, eventType)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("partner webhook: no rows in result set")
+	}
+	return nil
+}
+
+func (r *PartnerIntegrationRepository) CreateCredential(credential *models.PartnerAPICredential) error {
+	err := r.db.QueryRow(`
+		INSERT INTO partner_api_credentials (partner_id, name, client_id, secret_hash, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING credential_id, status, created_at`,
+		credential.PartnerID, credential.Name, credential.ClientID, credential.SecretHash, credential.ExpiresAt, credential.CreatedBy).
+		Scan(&credential.CredentialID, &credential.Status, &credential.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api credential: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerIntegrationRepository) GetCredentialsByPartnerID(partnerID string) ([]models.PartnerAPICredential, error) {
+	credentials := []models.PartnerAPICredential{}
+	if err := r.db.Select(&credentials, `SELECT * FROM partner_api_credentials WHERE partner_id = $1 ORDER BY created_at DESC`, partnerID); err != nil {
+		slog.Error("Error fetching partner api credentials", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get api credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+func (r *PartnerIntegrationRepository) GetCredentialByClientID(clientID string) (*models.PartnerAPICredential, error) {
+	var credential models.PartnerAPICredential
+	err := r.db.Get(&credential, `SELECT * FROM partner_api_credentials WHERE client_id = $1`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *PartnerIntegrationRepository) RevokeCredential(partnerID, credentialID, revokedBy string) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_api_credentials
+		SET status = 'revoked', revoked_by = $3, revoked_at = NOW()
+		WHERE partner_id = $1 AND credential_id = $2 AND status = 'active'`,
+		partnerID, credentialID, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api credential: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("active api credential: no rows in result set")
+	}
+	return nil
+}
+
+func (r *PartnerIntegrationRepository) TouchCredential(credentialID string) error {
+	if _, err := r.db.Exec(`UPDATE partner_api_credentials SET last_used_at = NOW() WHERE credential_id = $1`, credentialID); err != nil {
+		return fmt.Errorf("failed to update credential last use: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerLifecycleRepository interface {
+	TransitionStatus(history *models.PartnerStatusHistory) error
+	GetStatusHistory(partnerID string) ([]models.PartnerStatusHistory, error)
+}
+
+type PartnerLifecycleRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerLifecycleRepository(db *sqlx.DB) IPartnerLifecycleRepository {
+	return &PartnerLifecycleRepository{
+		db: db,
+	}
+}
+
+// TransitionStatus moves a partner from history.FromStatus to history.ToStatus and records the change.
+// It fails when the partner is no longer in FromStatus, so concurrent transitions cannot both apply.
+func (r *PartnerLifecycleRepository) TransitionStatus(history *models.PartnerStatusHistory) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE insurance_partners
+		SET status = $3, updated_at = NOW(), last_updated_by_id = $4, last_updated_by_name = $5
+		WHERE partner_id = $1 AND status = $2`,
+		history.PartnerID, history.FromStatus, history.ToStatus, history.ChangedBy, history.ChangedByName)
+	if err != nil {
+		return fmt.Errorf("failed to update partner status: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invalid status transition: partner status changed concurrently")
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO partner_status_history (partner_id, from_status, to_status, reason, changed_by, changed_by_name)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING history_id, changed_at`,
+		history.PartnerID, history.FromStatus, history.ToStatus, history.Reason, history.ChangedBy, history.ChangedByName).
+		Scan(&history.HistoryID, &history.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record partner status history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerLifecycleRepository) GetStatusHistory(partnerID string) ([]models.PartnerStatusHistory, error) {
+	history := []models.PartnerStatusHistory{}
+	query := `
+		SELECT history_id, partner_id, from_status, to_status, reason, changed_by, changed_by_name, changed_at
+		FROM partner_status_history
+		WHERE partner_id = $1
+		ORDER BY changed_at DESC`
+	if err := r.db.Select(&history, query, partnerID); err != nil {
+		slog.Error("Error fetching partner status history", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get partner status history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerReviewRepository interface {
+	UpsertReview(review *models.PartnerReviewDetail) error
+	GetReviewByID(reviewID string) (*models.PartnerReviewDetail, error)
+	GetReviewByReviewer(partnerID, reviewerID string) (*models.PartnerReviewDetail, error)
+	GetReviewsByModerationStatus(status models.ReviewModerationStatus, limit, offset int) ([]models.PartnerReviewDetail, error)
+	DeleteReview(partnerID, reviewerID string) error
+	ModerateReview(reviewID string, status models.ReviewModerationStatus, moderatedBy string, note *string) error
+	GetRatingDistribution(partnerID string) (map[int]int, error)
+	RefreshPartnerRating(partnerID string) error
+}
+
+type PartnerReviewRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerReviewRepository(db *sqlx.DB) IPartnerReviewRepository {
+	return &PartnerReviewRepository{
+		db: db,
+	}
+}
+
+// UpsertReview stores a farmer's review of a partner; a farmer has at most one review per
+// partner, and editing it sends it back to the published state
+func (r *PartnerReviewRepository) UpsertReview(review *models.PartnerReviewDetail) error {
+	query := `
+		INSERT INTO partner_reviews (
+			partner_id, reviewer_id, reviewer_name, reviewer_avatar_url,
+			rating_stars, review_content, policy_id, moderation_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, 'published')
+		ON CONFLICT (partner_id, reviewer_id) DO UPDATE
+		SET reviewer_name = EXCLUDED.reviewer_name,
+			reviewer_avatar_url = EXCLUDED.reviewer_avatar_url,
+			rating_stars = EXCLUDED.rating_stars,
+			review_content = EXCLUDED.review_content,
+			policy_id = EXCLUDED.policy_id,
+			moderation_status = 'published',
+			moderated_by = NULL,
+			moderated_at = NULL,
+			moderation_note = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING review_id, moderation_status, created_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		review.PartnerID, review.ReviewerID, review.ReviewerName, review.ReviewerAvatarURL,
+		review.RatingStars, review.ReviewContent, review.PolicyID,
+	).Scan(&review.ReviewID, &review.ModerationStatus, &review.CreatedAt, &review.UpdatedAt)
+	if err != nil {
+		slog.Error("Error saving partner review", "partner_id", review.PartnerID, "reviewer_id", review.ReviewerID, "error", err)
+		return fmt.Errorf("failed to save partner review: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerReviewRepository) GetReviewByID(reviewID string) (*models.PartnerReviewDetail, error) {
+	var review models.PartnerReviewDetail
+	if err := r.db.Get(&review, `SELECT * FROM partner_reviews WHERE review_id = $1`, reviewID); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *PartnerReviewRepository) GetReviewByReviewer(partnerID, reviewerID string) (*models.PartnerReviewDetail, error) {
+	var review models.PartnerReviewDetail
+	if err := r.db.Get(&review, `SELECT * FROM partner_reviews WHERE partner_id = $1 AND reviewer_id = $2`, partnerID, reviewerID); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *PartnerReviewRepository) GetReviewsByModerationStatus(status models.ReviewModerationStatus, limit, offset int) ([]models.PartnerReviewDetail, error) {
+	reviews := []models.PartnerReviewDetail{}
+	query := `
+		SELECT * FROM partner_reviews
+		WHERE moderation_status = $1
+		ORDER BY updated_at DESC
+		LIMIT $2 OFFSET $3`
+	if err := r.db.Select(&reviews, query, status, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to get partner reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+func (r *PartnerReviewRepository) DeleteReview(partnerID, reviewerID string) error {
+	result, err := r.db.Exec(`DELETE FROM partner_reviews WHERE partner_id = $1 AND reviewer_id = $2`, partnerID, reviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete partner review: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("partner review: no rows in result set")
+	}
+	return nil
+}
+
+func (r *PartnerReviewRepository) ModerateReview(reviewID string, status models.ReviewModerationStatus, moderatedBy string, note *string) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_reviews
+		SET moderation_status = $2,
+			moderated_by = $3,
+			moderated_at = CURRENT_TIMESTAMP,
+			moderation_note = $4
+		WHERE review_id = $1`,
+		reviewID, status, moderatedBy, note)
+	if err != nil {
+		return fmt.Errorf("failed to moderate partner review: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("partner review: no rows in result set")
+	}
+	return nil
+}
+
+// GetRatingDistribution counts the published reviews of a partner per star rating
+func (r *PartnerReviewRepository) GetRatingDistribution(partnerID string) (map[int]int, error) {
+	var rows []struct {
+		RatingStars int `db:"rating_stars"`
+		Count       int `db:"count"`
+	}
+	query := `
+		SELECT rating_stars, COUNT(*) AS count
+		FROM partner_reviews
+		WHERE partner_id = $1 AND moderation_status = 'published'
+		GROUP BY rating_stars`
+	if err := r.db.Select(&rows, query, partnerID); err != nil {
+		return nil, fmt.Errorf("failed to get rating distribution: %w", err)
+	}
+
+	distribution := map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	for _, row := range rows {
+		distribution[row.RatingStars] = row.Count
+	}
+	return distribution, nil
+}
+
+// RefreshPartnerRating recomputes the rating shown on the partner's public profile from its published reviews
+func (r *PartnerReviewRepository) RefreshPartnerRating(partnerID string) error {
+	query := `
+		UPDATE insurance_partners ip
+		SET partner_rating_score = stats.score,
+			partner_rating_count = stats.count
+		FROM (
+			SELECT
+				COALESCE(ROUND(AVG(rating_stars)::numeric, 1), 0) AS score,
+				COUNT(*) AS count
+			FROM partner_reviews
+			WHERE partner_id = $1 AND moderation_status = 'published'
+		) stats
+		WHERE ip.partner_id = $1`
+	if _, err := r.db.Exec(query, partnerID); err != nil {
+		return fmt.Errorf("failed to refresh partner rating: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerSettlementRepository interface {
+	CreateAccount(account *models.PartnerSettlementAccount) error
+	GetAccountByID(accountID string) (*models.PartnerSettlementAccount, error)
+	GetAccountsByPartnerID(partnerID string) ([]models.PartnerSettlementAccount, error)
+	GetActiveAccount(partnerID string) (*models.PartnerSettlementAccount, error)
+	GetPendingAccounts() ([]models.PartnerSettlementAccount, error)
+	ApproveAccount(accountID, reviewedBy string, note *string) error
+	RejectAccount(accountID, reviewedBy string, note *string) error
+	CancelAccount(partnerID, accountID string) error
+}
+
+type PartnerSettlementRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerSettlementRepository(db *sqlx.DB) IPartnerSettlementRepository {
+	return &PartnerSettlementRepository{
+		db: db,
+	}
+}
+
+func (r *PartnerSettlementRepository) CreateAccount(account *models.PartnerSettlementAccount) error {
+	err := r.db.QueryRow(`
+		INSERT INTO partner_settlement_accounts (
+			partner_id, account_type, bank_code, branch_name, wallet_provider,
+			account_number, account_name, change_reason, requested_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING account_id, status, verification_status, requested_at, updated_at`,
+		account.PartnerID, account.AccountType, account.BankCode, account.BranchName, account.WalletProvider,
+		account.AccountNumber, account.AccountName, account.ChangeReason, account.RequestedBy,
+	).Scan(&account.AccountID, &account.Status, &account.VerificationStatus, &account.RequestedAt, &account.UpdatedAt)
+	if err != nil {
+		slog.Error("Error creating settlement account", "partner_id", account.PartnerID, "error", err)
+		return fmt.Errorf("failed to create settlement account: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerSettlementRepository) GetAccountByID(accountID string) (*models.PartnerSettlementAccount, error) {
+	var account models.PartnerSettlementAccount
+	if err := r.db.Get(&account, `SELECT * FROM partner_settlement_accounts WHERE account_id = $1`, accountID); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *PartnerSettlementRepository) GetAccountsByPartnerID(partnerID string) ([]models.PartnerSettlementAccount, error) {
+	accounts := []models.PartnerSettlementAccount{}
+	if err := r.db.Select(&accounts, `SELECT * FROM partner_settlement_accounts WHERE partner_id = $1 ORDER BY requested_at DESC`, partnerID); err != nil {
+		return nil, fmt.Errorf("failed to get settlement accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+func (r *PartnerSettlementRepository) GetActiveAccount(partnerID string) (*models.PartnerSettlementAccount, error) {
+	var account models.PartnerSettlementAccount
+	if err := r.db.Get(&account, `SELECT * FROM partner_settlement_accounts WHERE partner_id = $1 AND status = 'active'`, partnerID); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *PartnerSettlementRepository) GetPendingAccounts() ([]models.PartnerSettlementAccount, error) {
+	accounts := []models.PartnerSettlementAccount{}
+	if err := r.db.Select(&accounts, `SELECT * FROM partner_settlement_accounts WHERE status = 'pending_approval' ORDER BY requested_at ASC`); err != nil {
+		return nil, fmt.Errorf("failed to get pending settlement accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// ApproveAccount activates a pending account, archives the account it replaces and mirrors
+// it onto the partner's bank info columns read by billing
+func (r *PartnerSettlementRepository) ApproveAccount(accountID, reviewedBy string, note *string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var account models.PartnerSettlementAccount
+	err = tx.Get(&account, `SELECT * FROM partner_settlement_accounts WHERE account_id = $1 AND status = 'pending_approval' FOR UPDATE`, accountID)
+	if err != nil {
+		return fmt.Errorf("pending settlement account: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE partner_settlement_accounts
+		SET status = 'archived', updated_at = NOW()
+		WHERE partner_id = $1 AND status = 'active'`, account.PartnerID); err != nil {
+		return fmt.Errorf("failed to archive previous settlement account: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE partner_settlement_accounts
+		SET status = 'active',
+			verification_status = 'verified',
+			reviewed_by = $2,
+			reviewed_at = NOW(),
+			review_note = $3,
+			activated_at = NOW(),
+			updated_at = NOW()
+		WHERE account_id = $1`, accountID, reviewedBy, note); err != nil {
+		return fmt.Errorf("failed to activate settlement account: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE insurance_partners
+		SET account_number = $2,
+			account_name = $3,
+			bank_code = COALESCE($4, $5),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE partner_id = $1`,
+		account.PartnerID, account.AccountNumber, account.AccountName, account.BankCode, account.WalletProvider); err != nil {
+		return fmt.Errorf("failed to update partner bank info: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerSettlementRepository) RejectAccount(accountID, reviewedBy string, note *string) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_settlement_accounts
+		SET status = 'rejected',
+			verification_status = 'failed',
+			reviewed_by = $2,
+			reviewed_at = NOW(),
+			review_note = $3,
+			updated_at = NOW()
+		WHERE account_id = $1 AND status = 'pending_approval'`,
+		accountID, reviewedBy, note)
+	if err != nil {
+		return fmt.Errorf("failed to reject settlement account: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending settlement account: no rows in result set")
+	}
+	return nil
+}
+
+func (r *PartnerSettlementRepository) CancelAccount(partnerID, accountID string) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_settlement_accounts
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE partner_id = $1 AND account_id = $2 AND status = 'pending_approval'`,
+		partnerID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel settlement account: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending settlement account: no rows in result set")
+	}
+	return nil
+}
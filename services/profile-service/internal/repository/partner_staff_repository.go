@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IPartnerStaffRepository interface {
+	GetMember(partnerID, userID string) (*models.PartnerStaffMember, error)
+	GetMembersByPartnerID(partnerID, status string) ([]models.PartnerStaffMember, error)
+	GetActiveMembershipByUserID(userID string) (*models.PartnerStaffMember, error)
+	AddMember(member *models.PartnerStaffMember) error
+	UpdateStaffRole(partnerID, userID string, staffRole models.StaffRole) error
+	DeactivateMember(partnerID, userID, deactivatedBy, reason string) error
+}
+
+type PartnerStaffRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerStaffRepository(db *sqlx.DB) IPartnerStaffRepository {
+	return &PartnerStaffRepository{
+		db: db,
+	}
+}
+
+const partnerStaffSelect = `
+	SELECT
+		m.member_id, m.partner_id, m.user_id, m.staff_role, m.status,
+		m.added_by, m.added_at, m.deactivated_by, m.deactivated_at, m.deactivation_reason, m.updated_at,
+		up.full_name, up.email, up.primary_phone
+	FROM partner_staff_members m
+	LEFT JOIN user_profiles up ON up.user_id = m.user_id`
+
+func (r *PartnerStaffRepository) GetMember(partnerID, userID string) (*models.PartnerStaffMember, error) {
+	var member models.PartnerStaffMember
+	err := r.db.Get(&member, partnerStaffSelect+` WHERE m.partner_id = $1 AND m.user_id = $2`, partnerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembersByPartnerID lists the staff of a partner; status "all" returns deactivated members too
+func (r *PartnerStaffRepository) GetMembersByPartnerID(partnerID, status string) ([]models.PartnerStaffMember, error) {
+	members := []models.PartnerStaffMember{}
+	query := partnerStaffSelect + ` WHERE m.partner_id = $1`
+	args := []any{partnerID}
+	if status != "all" {
+		query += ` AND m.status = $2`
+		args = append(args, status)
+	}
+	query += ` ORDER BY m.added_at ASC`
+
+	if err := r.db.Select(&members, query, args...); err != nil {
+		slog.Error("Error fetching partner staff", "partner_id", partnerID, "error", err)
+		return nil, fmt.Errorf("failed to get partner staff: %w", err)
+	}
+	return members, nil
+}
+
+func (r *PartnerStaffRepository) GetActiveMembershipByUserID(userID string) (*models.PartnerStaffMember, error) {
+	var member models.PartnerStaffMember
+	err := r.db.Get(&member, partnerStaffSelect+` WHERE m.user_id = $1 AND m.status = 'active'`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// AddMember creates or reactivates a membership and links the user's profile to the partner
+func (r *PartnerStaffRepository) AddMember(member *models.PartnerStaffMember) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO partner_staff_members (partner_id, user_id, staff_role, status, added_by)
+		VALUES ($1, $2, $3, 'active', $4)
+		ON CONFLICT (partner_id, user_id) DO UPDATE
+		SET staff_role = EXCLUDED.staff_role,
+			status = 'active',
+			added_by = EXCLUDED.added_by,
+			added_at = NOW(),
+			deactivated_by = NULL,
+			deactivated_at = NULL,
+			deactivation_reason = NULL,
+			updated_at = NOW()
+		RETURNING member_id, status, added_at, updated_at`
+	err = tx.QueryRow(query, member.PartnerID, member.UserID, member.StaffRole, member.AddedBy).
+		Scan(&member.MemberID, &member.Status, &member.AddedAt, &member.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add partner staff: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE user_profiles SET partner_id = $1, updated_at = NOW() WHERE user_id = $2`, member.PartnerID, member.UserID); err != nil {
+		return fmt.Errorf("failed to link user profile to partner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PartnerStaffRepository) UpdateStaffRole(partnerID, userID string, staffRole models.StaffRole) error {
+	result, err := r.db.Exec(`
+		UPDATE partner_staff_members
+		SET staff_role = $3, updated_at = NOW()
+		WHERE partner_id = $1 AND user_id = $2 AND status = 'active'`,
+		partnerID, userID, staffRole)
+	if err != nil {
+		return fmt.Errorf("failed to update staff role: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("active staff member: no rows in result set")
+	}
+	return nil
+}
+
+// DeactivateMember ends a membership and unlinks the user's profile from the partner,
+// which also revokes access through the partner's private profile
+func (r *PartnerStaffRepository) DeactivateMember(partnerID, userID, deactivatedBy, reason string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE partner_staff_members
+		SET status = 'deactivated',
+			deactivated_by = $3,
+			deactivated_at = NOW(),
+			deactivation_reason = NULLIF($4, ''),
+			updated_at = NOW()
+		WHERE partner_id = $1 AND user_id = $2 AND status = 'active'`,
+		partnerID, userID, deactivatedBy, reason)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate partner staff: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("active staff member: no rows in result set")
+	}
+
+	if _, err := tx.Exec(`UPDATE user_profiles SET partner_id = NULL, updated_at = NOW() WHERE user_id = $1 AND partner_id = $2`, userID, partnerID); err != nil {
+		return fmt.Errorf("failed to unlink user profile from partner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxPreferredLanguageLookup = 500
+	maxFarmerAudiencePage      = 1000
+)
+
+var bankCodeRegex = regexp.MustCompile(`^[A-Za-z0-9]{2,20}$`)
+
+type FarmerProfileService struct {
+	repo                  repository.IFarmerProfileRepository
+	userProfileRepository repository.IUserRepository
+}
+
+type IFarmerProfileService interface {
+	GetMyProfile(userID string) (*models.FarmerProfile, error)
+	UpsertMyProfile(userID string, req *models.UpsertFarmerProfileRequest) (*models.FarmerProfile, error)
+	UpdatePayoutAccount(userID string, req *models.UpdatePayoutAccountRequest) (*models.FarmerPayoutAccount, error)
+	GetProfile(userID string) (*models.FarmerProfile, error)
+	GetPayoutAccount(userID string) (*models.FarmerPayoutAccount, error)
+	GetPreferredLanguages(userIDs []string) ([]models.FarmerLanguagePreference, error)
+	GetAudience(req *models.GetFarmerAudienceRequest) (*models.FarmerAudiencePage, error)
+}
+
+func NewFarmerProfileService(repo repository.IFarmerProfileRepository, userProfileRepository repository.IUserRepository) IFarmerProfileService {
+	return &FarmerProfileService{
+		repo:                  repo,
+		userProfileRepository: userProfileRepository,
+	}
+}
+
+func (s *FarmerProfileService) authorizeFarmer(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+	profile, err := s.userProfileRepository.GetUserProfileByUserID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user profile %s: %w", userID, err)
+		}
+		return err
+	}
+	if profile.RoleID != models.AuthRoleFarmer {
+		return fmt.Errorf("forbidden: only farmers have a farmer profile")
+	}
+	return nil
+}
+
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func buildFarmerProfile(userID string, req *models.UpsertFarmerProfileRequest) (*models.FarmerProfile, error) {
+	profile := &models.FarmerProfile{
+		UserID:                 userID,
+		HouseholdHeadName:      trimOptional(req.HouseholdHeadName),
+		HouseholdSize:          req.HouseholdSize,
+		DependentsCount:        req.DependentsCount,
+		FarmingExperienceYears: req.FarmingExperienceYears,
+		AnnualHouseholdIncome:  req.AnnualHouseholdIncome,
+		PreferredLanguage:      req.PreferredLanguage,
+		CooperativeName:        trimOptional(req.CooperativeName),
+		CooperativeCode:        trimOptional(req.CooperativeCode),
+		CooperativeRole:        req.CooperativeRole,
+	}
+
+	if profile.HouseholdSize == 0 {
+		profile.HouseholdSize = 1
+	}
+	if profile.HouseholdSize < 1 || profile.HouseholdSize > 50 {
+		return nil, fmt.Errorf("invalid household_size: must be between 1 and 50")
+	}
+	if profile.DependentsCount < 0 || profile.DependentsCount >= profile.HouseholdSize {
+		return nil, fmt.Errorf("invalid dependents_count: must be less than household_size")
+	}
+	if profile.FarmingExperienceYears != nil && (*profile.FarmingExperienceYears < 0 || *profile.FarmingExperienceYears > 100) {
+		return nil, fmt.Errorf("invalid farming_experience_years: %d", *profile.FarmingExperienceYears)
+	}
+	if profile.AnnualHouseholdIncome != nil && *profile.AnnualHouseholdIncome < 0 {
+		return nil, fmt.Errorf("invalid annual_household_income: must not be negative")
+	}
+	if profile.PreferredLanguage == "" {
+		profile.PreferredLanguage = models.LanguageVietnamese
+	}
+	if !profile.PreferredLanguage.IsValid() {
+		return nil, fmt.Errorf("invalid preferred_language: %s", profile.PreferredLanguage)
+	}
+
+	// Cooperative details only make sense together with the cooperative name
+	if profile.CooperativeName == nil {
+		profile.CooperativeCode, profile.CooperativeRole = nil, nil
+		return profile, nil
+	}
+	if profile.CooperativeRole == nil {
+		role := models.CooperativeRoleMember
+		profile.CooperativeRole = &role
+	}
+	if !profile.CooperativeRole.IsValid() {
+		return nil, fmt.Errorf("invalid cooperative_role: %s", *profile.CooperativeRole)
+	}
+	if joinedAt := trimOptional(req.CooperativeJoinedAt); joinedAt != nil {
+		date, err := time.Parse("2006-01-02", *joinedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cooperative_joined_at: expected YYYY-MM-DD")
+		}
+		if date.After(time.Now()) {
+			return nil, fmt.Errorf("invalid cooperative_joined_at: must not be in the future")
+		}
+		profile.CooperativeJoinedAt = &date
+	}
+	return profile, nil
+}
+
+func (s *FarmerProfileService) GetMyProfile(userID string) (*models.FarmerProfile, error) {
+	if err := s.authorizeFarmer(userID); err != nil {
+		return nil, err
+	}
+	return s.GetProfile(userID)
+}
+
+func (s *FarmerProfileService) UpsertMyProfile(userID string, req *models.UpsertFarmerProfileRequest) (*models.FarmerProfile, error) {
+	profile, err := buildFarmerProfile(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeFarmer(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Upsert(profile); err != nil {
+		return nil, err
+	}
+	slog.Info("farmer profile saved", "user_id", userID)
+	return s.GetProfile(userID)
+}
+
+func (s *FarmerProfileService) UpdatePayoutAccount(userID string, req *models.UpdatePayoutAccountRequest) (*models.FarmerPayoutAccount, error) {
+	account := &models.FarmerPayoutAccount{
+		UserID:        userID,
+		AccountNumber: strings.TrimSpace(req.AccountNumber),
+		AccountName:   strings.ToUpper(strings.TrimSpace(req.AccountName)),
+		BankCode:      strings.ToUpper(strings.TrimSpace(req.BankCode)),
+	}
+	if !bankAccountNumberRegex.MatchString(account.AccountNumber) {
+		return nil, fmt.Errorf("invalid account_number: bank account numbers must be 6-20 digits")
+	}
+	if account.AccountName == "" {
+		return nil, fmt.Errorf("invalid account_name")
+	}
+	if !bankCodeRegex.MatchString(account.BankCode) {
+		return nil, fmt.Errorf("invalid bank_code: %s", account.BankCode)
+	}
+	if err := s.authorizeFarmer(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePayoutAccount(account, userID); err != nil {
+		return nil, err
+	}
+	slog.Info("farmer payout account updated", "user_id", userID, "bank_code", account.BankCode)
+	return account, nil
+}
+
+// GetProfile returns a farmer profile with its payout account, if one is registered
+func (s *FarmerProfileService) GetProfile(userID string) (*models.FarmerProfile, error) {
+	profile, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("farmer profile %s: %w", userID, err)
+		}
+		return nil, err
+	}
+
+	account, err := s.repo.GetPayoutAccount(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	profile.PayoutAccount = account
+	return profile, nil
+}
+
+// GetPayoutAccount is used by policy-service to find where a farmer's payouts go
+func (s *FarmerProfileService) GetPayoutAccount(userID string) (*models.FarmerPayoutAccount, error) {
+	account, err := s.repo.GetPayoutAccount(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("payout account of %s: %w", userID, err)
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetPreferredLanguages is used by notification-service to localize messages
+func (s *FarmerProfileService) GetPreferredLanguages(userIDs []string) ([]models.FarmerLanguagePreference, error) {
+	if len(userIDs) > maxPreferredLanguageLookup {
+		return nil, fmt.Errorf("invalid request: at most %d user_ids per lookup", maxPreferredLanguageLookup)
+	}
+	return s.repo.GetPreferredLanguages(userIDs)
+}
+
+// GetAudience is used by notification-service to page through the farmers a campaign goes to
+func (s *FarmerProfileService) GetAudience(req *models.GetFarmerAudienceRequest) (*models.FarmerAudiencePage, error) {
+	if len(req.ProvinceCodes) == 0 && len(req.DistrictCodes) == 0 && len(req.CooperativeCodes) == 0 {
+		return nil, fmt.Errorf("invalid request: at least one of province_codes, district_codes or cooperative_codes is required")
+	}
+	if req.Limit <= 0 || req.Limit > maxFarmerAudiencePage {
+		return nil, fmt.Errorf("invalid request: limit must be between 1 and %d", maxFarmerAudiencePage)
+	}
+
+	members, err := s.repo.GetAudience(req)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.FarmerAudiencePage{Members: members}
+	if len(members) == req.Limit {
+		page.NextAfterUserID = &members[len(members)-1].UserID
+	}
+	return page, nil
+}
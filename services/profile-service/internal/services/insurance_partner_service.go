@@ -8,6 +8,7 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"profile-service/internal/database/minio"
 	"profile-service/internal/event"
 	"profile-service/internal/models"
 	"profile-service/internal/repository"
@@ -25,6 +26,7 @@ type InsurancePartnerService struct {
 	repo                  repository.IInsurancePartnerRepository
 	userProfileRepository repository.IUserRepository
 	profilePublisher      *event.NotificationPublisher
+	storage               *minio.MinioClient
 }
 
 type IInsurancePartnerService interface {
@@ -32,9 +34,12 @@ type IInsurancePartnerService interface {
 	GetPartnerReviews(partnerID string, sortBy string, sortDirection string, limit int, offset int) ([]models.PartnerReview, error)
 	CreateInsurancePartner(req *models.CreateInsurancePartnerRequest, userID string) CreateInsurancePartnerResult
 	GetPrivateProfile(userID string) (*models.PrivatePartnerProfile, error)
+	GetPartnerSummary(partnerID string) (*models.PartnerSummary, error)
 	UpdateInsurancePartner(updateProfileRequestBody map[string]any, updateByID, updateByName string) (*models.PrivatePartnerProfile, error)
 	GetAllPartnersPublicProfiles() ([]models.PublicPartnerProfile, error)
 	GetAllPartnersPrivateProfiles() ([]models.PrivatePartnerProfile, error)
+	SearchPublicPartners(query models.PartnerListQuery) (*models.PartnerListResponse[models.PublicPartnerProfile], error)
+	SearchPartners(actorID string, query models.PartnerListQuery) (*models.PartnerListResponse[models.PrivatePartnerProfile], error)
 	GetPrivateProfileByPartnerID(partnerID string) (*models.PrivatePartnerProfile, error)
 	CreatePartnerDeletionRequest(req *models.PartnerDeletionRequest, partnerAdminID string) (result *models.PartnerDeletionRequest, err error)
 	GetDeletionRequestsByRequesterID(requesterID string) ([]models.DeletionRequestResponse, error)
@@ -47,11 +52,12 @@ type IInsurancePartnerService interface {
 	GetActiveContracts(token, providerID string) (map[string]any, error)
 }
 
-func NewInsurancePartnerService(repo repository.IInsurancePartnerRepository, userProfileRepository repository.IUserRepository, profilePublisher *event.NotificationPublisher) IInsurancePartnerService {
+func NewInsurancePartnerService(repo repository.IInsurancePartnerRepository, userProfileRepository repository.IUserRepository, profilePublisher *event.NotificationPublisher, storage *minio.MinioClient) IInsurancePartnerService {
 	return &InsurancePartnerService{
 		repo:                  repo,
 		userProfileRepository: userProfileRepository,
 		profilePublisher:      profilePublisher,
+		storage:               storage,
 	}
 }
 
@@ -218,14 +224,19 @@ var allowedUpdateInsuranceProfileFields = map[string]bool{
 	"hotline":                      true,
 	"support_hours":                true,
 	"coverage_areas":               true,
-	"status":                       true,
 	"updated_at":                   true,
 	"last_updated_by_id":           true,
 	"last_updated_by_name":         true,
 	"legal_document_urls":          true,
-	"account_number":               true,
-	"account_name":                 true,
-	"bank_code":                    true,
+	// account_number, account_name and bank_code change through the settlement account approval workflow
+	// and status through the partner lifecycle endpoints
+}
+
+// Stored file references are only written by the partner file upload flow
+var fileReferenceInsuranceProfileFields = map[string]bool{
+	"partner_logo_url":    true,
+	"cover_photo_url":     true,
+	"legal_document_urls": true,
 }
 
 var arrayInsuranceProfileFields = map[string]bool{
@@ -280,6 +291,9 @@ func (s *InsurancePartnerService) UpdateInsurancePartner(updateProfileRequestBod
 			log.Printf("Field %s is not allowed to be updated", field)
 			return nil, fmt.Errorf("bad request: field %s is not allowed to be updated", field)
 		}
+		if fileReferenceInsuranceProfileFields[field] && strings.Contains(fmt.Sprintf("%v", value), minio.ObjectRefScheme) {
+			return nil, fmt.Errorf("bad request: field %s cannot reference stored files, upload them through partner files", field)
+		}
 
 		// Xử lý các field có kiểu array
 		if arrayInsuranceProfileFields[field] {
@@ -339,6 +353,7 @@ func (s *InsurancePartnerService) UpdateInsurancePartner(updateProfileRequestBod
 		log.Printf("Error getting private profile for insurance partner ID %s after update: %s", partnerID, err.Error())
 		return nil, fmt.Errorf("%v", err)
 	}
+	s.presignPrivateProfile(privateProfile)
 
 	return privateProfile, nil
 }
@@ -1016,16 +1031,174 @@ func ValidateLicenseExpiryDate(licenseExpiryDate time.Time, licenseIssueDate tim
 	return nil
 }
 
+// presignPublicProfile replaces uploaded file references in a public profile with presigned URLs
+func (s *InsurancePartnerService) presignPublicProfile(profile *models.PublicPartnerProfile) {
+	ctx := context.Background()
+	profile.PartnerLogoURL = s.storage.PresignRef(ctx, profile.PartnerLogoURL, PresignedURLExpiry)
+	profile.CoverPhotoURL = s.storage.PresignRef(ctx, profile.CoverPhotoURL, PresignedURLExpiry)
+}
+
+// presignPrivateProfile replaces uploaded file references in a private profile with presigned URLs
+func (s *InsurancePartnerService) presignPrivateProfile(profile *models.PrivatePartnerProfile) {
+	ctx := context.Background()
+	profile.PartnerLogoURL = s.storage.PresignRef(ctx, profile.PartnerLogoURL, PresignedURLExpiry)
+	profile.CoverPhotoURL = s.storage.PresignRef(ctx, profile.CoverPhotoURL, PresignedURLExpiry)
+	for i, ref := range profile.LegalDocumentURLs {
+		profile.LegalDocumentURLs[i] = s.storage.PresignRef(ctx, ref, PresignedURLExpiry)
+	}
+}
+
 func (s *InsurancePartnerService) GetPublicProfile(partnerID string) (*models.PublicPartnerProfile, error) {
-	return s.repo.GetPublicProfile(partnerID)
+	profile, err := s.repo.GetPublicProfile(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	s.presignPublicProfile(profile)
+	return profile, nil
+}
+
+// GetPartnerSummary returns the partner whatever its status, so callers can tell an inactive
+// partner from a missing one
+func (s *InsurancePartnerService) GetPartnerSummary(partnerID string) (*models.PartnerSummary, error) {
+	summary, err := s.repo.GetPartnerSummary(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	summary.PartnerLogoURL = s.storage.PresignRef(context.Background(), summary.PartnerLogoURL, PresignedURLExpiry)
+	return summary, nil
 }
 
 func (s *InsurancePartnerService) GetAllPartnersPublicProfiles() ([]models.PublicPartnerProfile, error) {
-	return s.repo.GetAllPublicProfiles()
+	profiles, err := s.repo.GetAllPublicProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		s.presignPublicProfile(&profiles[i])
+	}
+	return profiles, nil
 }
 
 func (s *InsurancePartnerService) GetAllPartnersPrivateProfiles() ([]models.PrivatePartnerProfile, error) {
-	return s.repo.GetAllPrivateProfiles()
+	profiles, err := s.repo.GetAllPrivateProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		s.presignPrivateProfile(&profiles[i])
+	}
+	return profiles, nil
+}
+
+const (
+	defaultPartnerListLimit = 20
+	maxPartnerListLimit     = 100
+)
+
+// normalizePartnerListQuery validates a listing query and fills in its defaults
+func normalizePartnerListQuery(query *models.PartnerListQuery, defaultSortBy string) error {
+	query.Search = strings.TrimSpace(query.Search)
+	query.Status = strings.ToLower(strings.TrimSpace(query.Status))
+	query.ProvinceCode = strings.TrimSpace(query.ProvinceCode)
+	query.SortBy = strings.ToLower(strings.TrimSpace(query.SortBy))
+	query.SortDirection = strings.ToLower(strings.TrimSpace(query.SortDirection))
+
+	if query.Status != "" && !models.PartnerStatus(query.Status).IsValid() {
+		return fmt.Errorf("invalid status: %s", query.Status)
+	}
+	if utf8.RuneCountInString(query.Search) > 255 {
+		return fmt.Errorf("invalid search: must be at most 255 characters")
+	}
+	if query.SortBy == "" {
+		query.SortBy = defaultSortBy
+	}
+	switch query.SortBy {
+	case "partner_display_name", "legal_company_name", "partner_rating_score", "partner_rating_count", "year_established", "created_at":
+	default:
+		return fmt.Errorf("invalid sort_by: %s", query.SortBy)
+	}
+	switch query.SortDirection {
+	case "":
+		query.SortDirection = "desc"
+		if query.SortBy == "partner_display_name" || query.SortBy == "legal_company_name" {
+			query.SortDirection = "asc"
+		}
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("invalid sort_direction: %s", query.SortDirection)
+	}
+	if query.Page < 0 || query.Limit < 0 {
+		return fmt.Errorf("invalid pagination: page and limit must be positive")
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPartnerListLimit
+	}
+	if query.Limit > maxPartnerListLimit {
+		query.Limit = maxPartnerListLimit
+	}
+	return nil
+}
+
+func totalPages(total, limit int) int {
+	return (total + limit - 1) / limit
+}
+
+// SearchPublicPartners lists active partners for the farmer marketplace
+func (s *InsurancePartnerService) SearchPublicPartners(query models.PartnerListQuery) (*models.PartnerListResponse[models.PublicPartnerProfile], error) {
+	query.Status = "active"
+	if err := normalizePartnerListQuery(&query, "partner_rating_score"); err != nil {
+		return nil, err
+	}
+
+	profiles, total, err := s.repo.ListPublicProfiles(query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		s.presignPublicProfile(&profiles[i])
+	}
+	return &models.PartnerListResponse[models.PublicPartnerProfile]{
+		Items:      profiles,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		TotalPages: totalPages(total, query.Limit),
+	}, nil
+}
+
+// SearchPartners lists partners of any status for the admin console
+func (s *InsurancePartnerService) SearchPartners(actorID string, query models.PartnerListQuery) (*models.PartnerListResponse[models.PrivatePartnerProfile], error) {
+	if actorID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+	actor, err := s.userProfileRepository.GetUserProfileByUserID(actorID)
+	if err != nil && !strings.Contains(err.Error(), "no rows in result set") {
+		return nil, err
+	}
+	if actor == nil || actor.RoleID != models.AuthRoleAdmin {
+		return nil, fmt.Errorf("forbidden: admin role is required")
+	}
+	if err := normalizePartnerListQuery(&query, "created_at"); err != nil {
+		return nil, err
+	}
+
+	profiles, total, err := s.repo.ListPrivateProfiles(query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		s.presignPrivateProfile(&profiles[i])
+	}
+	return &models.PartnerListResponse[models.PrivatePartnerProfile]{
+		Items:      profiles,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		TotalPages: totalPages(total, query.Limit),
+	}, nil
 }
 
 func (s *InsurancePartnerService) GetPrivateProfile(userID string) (*models.PrivatePartnerProfile, error) {
@@ -1037,11 +1210,16 @@ func (s *InsurancePartnerService) GetPrivateProfile(userID string) (*models.Priv
 		return nil, fmt.Errorf("forbidden: user is not associated with any insurance partner")
 	}
 	partnerID := staff.PartnerID
-	return s.repo.GetPrivateProfile(partnerID.String())
+	return s.GetPrivateProfileByPartnerID(partnerID.String())
 }
 
 func (s *InsurancePartnerService) GetPrivateProfileByPartnerID(partnerID string) (*models.PrivatePartnerProfile, error) {
-	return s.repo.GetPrivateProfile(partnerID)
+	profile, err := s.repo.GetPrivateProfile(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	s.presignPrivateProfile(profile)
+	return profile, nil
 }
 
 // ======= PARTNER DELETION REQUESTS =======
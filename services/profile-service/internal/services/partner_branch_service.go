@@ -0,0 +1,251 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PartnerBranchService struct {
+	repo                repository.IPartnerBranchRepository
+	partnerStaffService IPartnerStaffService
+}
+
+type IPartnerBranchService interface {
+	GetBranches(partnerID string, activeOnly bool) ([]models.PartnerBranch, error)
+	GetBranchByID(partnerID, branchID string) (*models.PartnerBranch, error)
+	CreateBranch(actorID, partnerID string, req *models.CreatePartnerBranchRequest) (*models.PartnerBranch, error)
+	UpdateBranch(actorID, partnerID, branchID string, req *models.UpdatePartnerBranchRequest) (*models.PartnerBranch, error)
+	DeactivateBranch(actorID, partnerID, branchID string) error
+	FindNearestBranch(partnerID, provinceCode string, latitude, longitude *float64) (*models.NearestBranchResponse, error)
+}
+
+func NewPartnerBranchService(repo repository.IPartnerBranchRepository, partnerStaffService IPartnerStaffService) IPartnerBranchService {
+	return &PartnerBranchService{
+		repo:                repo,
+		partnerStaffService: partnerStaffService,
+	}
+}
+
+func validateBranchLocation(latitude, longitude *float64) error {
+	if (latitude == nil) != (longitude == nil) {
+		return fmt.Errorf("invalid location: latitude and longitude must be provided together")
+	}
+	if latitude != nil && (*latitude < -90 || *latitude > 90 || *longitude < -180 || *longitude > 180) {
+		return fmt.Errorf("invalid location: coordinates out of range")
+	}
+	return nil
+}
+
+// normalizeServiceArea trims and de-duplicates province codes; a branch always serves its own province
+func normalizeServiceArea(provinceCode string, provinces []string) pq.StringArray {
+	area := pq.StringArray{provinceCode}
+	for _, code := range provinces {
+		code = strings.TrimSpace(code)
+		if code != "" && !slices.Contains(area, code) {
+			area = append(area, code)
+		}
+	}
+	return area
+}
+
+func (s *PartnerBranchService) GetBranches(partnerID string, activeOnly bool) ([]models.PartnerBranch, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	return s.repo.GetBranchesByPartnerID(partnerID, activeOnly)
+}
+
+func (s *PartnerBranchService) GetBranchByID(partnerID, branchID string) (*models.PartnerBranch, error) {
+	if _, err := uuid.Parse(branchID); err != nil {
+		return nil, fmt.Errorf("invalid branch_id: %s", branchID)
+	}
+	return s.repo.GetBranchByID(partnerID, branchID)
+}
+
+func (s *PartnerBranchService) CreateBranch(actorID, partnerID string, req *models.CreatePartnerBranchRequest) (*models.PartnerBranch, error) {
+	if err := validateBranchLocation(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	provinceCode := strings.TrimSpace(req.ProvinceCode)
+	branch := &models.PartnerBranch{
+		PartnerID:            uuid.MustParse(partnerID),
+		BranchCode:           strings.TrimSpace(req.BranchCode),
+		BranchName:           strings.TrimSpace(req.BranchName),
+		Address:              strings.TrimSpace(req.Address),
+		ProvinceCode:         provinceCode,
+		ProvinceName:         req.ProvinceName,
+		WardCode:             req.WardCode,
+		WardName:             req.WardName,
+		Latitude:             req.Latitude,
+		Longitude:            req.Longitude,
+		ContactName:          req.ContactName,
+		ContactPhone:         req.ContactPhone,
+		ContactEmail:         req.ContactEmail,
+		ServiceAreaProvinces: normalizeServiceArea(provinceCode, req.ServiceAreaProvinces),
+		CreatedBy:            &actorID,
+	}
+	if err := s.repo.CreateBranch(branch); err != nil {
+		return nil, err
+	}
+	slog.Info("partner branch created", "partner_id", partnerID, "branch_id", branch.BranchID, "created_by", actorID)
+	return branch, nil
+}
+
+func (s *PartnerBranchService) UpdateBranch(actorID, partnerID, branchID string, req *models.UpdatePartnerBranchRequest) (*models.PartnerBranch, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	branch, err := s.GetBranchByID(partnerID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.BranchName != nil {
+		branch.BranchName = strings.TrimSpace(*req.BranchName)
+	}
+	if req.Address != nil {
+		branch.Address = strings.TrimSpace(*req.Address)
+	}
+	if req.ProvinceCode != nil {
+		branch.ProvinceCode = strings.TrimSpace(*req.ProvinceCode)
+	}
+	if req.ProvinceName != nil {
+		branch.ProvinceName = req.ProvinceName
+	}
+	if req.WardCode != nil {
+		branch.WardCode = req.WardCode
+	}
+	if req.WardName != nil {
+		branch.WardName = req.WardName
+	}
+	if req.Latitude != nil || req.Longitude != nil {
+		branch.Latitude, branch.Longitude = req.Latitude, req.Longitude
+	}
+	if req.ContactName != nil {
+		branch.ContactName = req.ContactName
+	}
+	if req.ContactPhone != nil {
+		branch.ContactPhone = req.ContactPhone
+	}
+	if req.ContactEmail != nil {
+		branch.ContactEmail = req.ContactEmail
+	}
+	if req.IsActive != nil {
+		branch.IsActive = *req.IsActive
+	}
+	serviceArea := []string(branch.ServiceAreaProvinces)
+	if req.ServiceAreaProvinces != nil {
+		serviceArea = *req.ServiceAreaProvinces
+	}
+	branch.ServiceAreaProvinces = normalizeServiceArea(branch.ProvinceCode, serviceArea)
+
+	if branch.BranchName == "" || branch.Address == "" || branch.ProvinceCode == "" {
+		return nil, fmt.Errorf("invalid branch: branch_name, address and province_code cannot be empty")
+	}
+	if err := validateBranchLocation(branch.Latitude, branch.Longitude); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateBranch(branch); err != nil {
+		return nil, err
+	}
+	slog.Info("partner branch updated", "partner_id", partnerID, "branch_id", branchID, "updated_by", actorID)
+	return s.repo.GetBranchByID(partnerID, branchID)
+}
+
+func (s *PartnerBranchService) DeactivateBranch(actorID, partnerID, branchID string) error {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(branchID); err != nil {
+		return fmt.Errorf("invalid branch_id: %s", branchID)
+	}
+	if err := s.repo.DeactivateBranch(partnerID, branchID); err != nil {
+		return err
+	}
+	slog.Info("partner branch deactivated", "partner_id", partnerID, "branch_id", branchID, "deactivated_by", actorID)
+	return nil
+}
+
+// FindNearestBranch picks the active branch a claim or inspection at the given location
+// is routed to. Branches whose service area covers the province are preferred; among the
+// candidates the closest one wins when coordinates are known. Without any covering
+// branch, the closest branch overall is returned.
+func (s *PartnerBranchService) FindNearestBranch(partnerID, provinceCode string, latitude, longitude *float64) (*models.NearestBranchResponse, error) {
+	if err := validateBranchLocation(latitude, longitude); err != nil {
+		return nil, err
+	}
+	provinceCode = strings.TrimSpace(provinceCode)
+	if provinceCode == "" && latitude == nil {
+		return nil, fmt.Errorf("invalid location: province_code or latitude/longitude is required")
+	}
+
+	branches, err := s.GetBranches(partnerID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var covering []models.PartnerBranch
+	if provinceCode != "" {
+		for _, branch := range branches {
+			if slices.Contains(branch.ServiceAreaProvinces, provinceCode) {
+				covering = append(covering, branch)
+			}
+		}
+	}
+
+	candidates, inServiceArea := covering, true
+	if len(candidates) == 0 {
+		if latitude == nil {
+			return nil, fmt.Errorf("partner branch serving province %s: no rows in result set", provinceCode)
+		}
+		candidates, inServiceArea = branches, false
+	}
+
+	var best *models.NearestBranchResponse
+	for _, branch := range candidates {
+		response := &models.NearestBranchResponse{
+			Branch:        branch,
+			InServiceArea: inServiceArea,
+		}
+		if latitude != nil && branch.Latitude != nil && branch.Longitude != nil {
+			distance := haversineKm(*latitude, *longitude, *branch.Latitude, *branch.Longitude)
+			response.DistanceKm = &distance
+		}
+
+		switch {
+		case best == nil:
+			best = response
+		case response.DistanceKm != nil && (best.DistanceKm == nil || *response.DistanceKm < *best.DistanceKm):
+			best = response
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("partner branch: no rows in result set")
+	}
+	return best, nil
+}
+
+// haversineKm returns the great-circle distance between two coordinates in kilometers
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
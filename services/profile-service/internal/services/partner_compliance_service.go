@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"profile-service/internal/event"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// complianceReminderDays are the days before expiry at which partner admins are reminded, largest first
+var complianceReminderDays = []int{90, 30, 7}
+
+type PartnerComplianceService struct {
+	repo                  repository.IPartnerComplianceRepository
+	partnerFileRepository repository.IPartnerFileRepository
+	partnerStaffService   IPartnerStaffService
+	notificationPublisher *event.NotificationPublisher
+}
+
+type IPartnerComplianceService interface {
+	GetDocuments(actorID, partnerID string) ([]models.PartnerComplianceDocument, error)
+	CreateDocument(actorID, partnerID string, req *models.CreateComplianceDocumentRequest) (*models.PartnerComplianceDocument, error)
+	RenewDocument(actorID, partnerID, documentID string, req *models.RenewComplianceDocumentRequest) (*models.PartnerComplianceDocument, error)
+	DeleteDocument(actorID, partnerID, documentID string) error
+	RunExpiryCheck(ctx context.Context)
+	StartExpiryWatcher(ctx context.Context, interval time.Duration)
+}
+
+func NewPartnerComplianceService(repo repository.IPartnerComplianceRepository, partnerFileRepository repository.IPartnerFileRepository, partnerStaffService IPartnerStaffService, notificationPublisher *event.NotificationPublisher) IPartnerComplianceService {
+	return &PartnerComplianceService{
+		repo:                  repo,
+		partnerFileRepository: partnerFileRepository,
+		partnerStaffService:   partnerStaffService,
+		notificationPublisher: notificationPublisher,
+	}
+}
+
+// parseDateField parses an optional YYYY-MM-DD request field, returning nil when it is empty
+func parseDateField(field string, value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(*value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected YYYY-MM-DD", field)
+	}
+	return &date, nil
+}
+
+// validateComplianceDates parses the issue and expiry dates and checks that they are in order
+func validateComplianceDates(issuedAt *string, expiresAt string) (*time.Time, time.Time, error) {
+	issued, err := parseDateField("issued_at", issuedAt)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	expires, err := parseDateField("expires_at", &expiresAt)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if expires == nil {
+		return nil, time.Time{}, fmt.Errorf("invalid expires_at: required")
+	}
+	if issued != nil && !expires.After(*issued) {
+		return nil, time.Time{}, fmt.Errorf("invalid expires_at: must be after issued_at")
+	}
+	return issued, *expires, nil
+}
+
+func (s *PartnerComplianceService) resolveFileID(partnerID string, fileID *string) (*uuid.UUID, error) {
+	if fileID == nil || strings.TrimSpace(*fileID) == "" {
+		return nil, nil
+	}
+	file, err := s.partnerFileRepository.GetFileByID(partnerID, strings.TrimSpace(*fileID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid file_id: %s is not a file of this partner", *fileID)
+	}
+	return &file.FileID, nil
+}
+
+func (s *PartnerComplianceService) GetDocuments(actorID, partnerID string) ([]models.PartnerComplianceDocument, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDocumentsByPartnerID(partnerID)
+}
+
+func (s *PartnerComplianceService) CreateDocument(actorID, partnerID string, req *models.CreateComplianceDocumentRequest) (*models.PartnerComplianceDocument, error) {
+	if !req.DocumentType.IsValid() {
+		return nil, fmt.Errorf("invalid document_type: %s", req.DocumentType)
+	}
+	if strings.TrimSpace(req.DocumentName) == "" {
+		return nil, fmt.Errorf("invalid document_name")
+	}
+	issuedAt, expiresAt, err := validateComplianceDates(req.IssuedAt, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	fileID, err := s.resolveFileID(partnerID, req.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &models.PartnerComplianceDocument{
+		PartnerID:      uuid.MustParse(partnerID),
+		DocumentType:   req.DocumentType,
+		DocumentName:   strings.TrimSpace(req.DocumentName),
+		DocumentNumber: trimOptional(req.DocumentNumber),
+		IssuedAt:       issuedAt,
+		ExpiresAt:      expiresAt,
+		FileID:         fileID,
+		CreatedBy:      actorID,
+	}
+	if err := s.repo.CreateDocument(document); err != nil {
+		return nil, err
+	}
+	slog.Info("compliance document created", "partner_id", partnerID, "document_id", document.DocumentID, "expires_at", req.ExpiresAt)
+	return document, nil
+}
+
+func (s *PartnerComplianceService) RenewDocument(actorID, partnerID, documentID string, req *models.RenewComplianceDocumentRequest) (*models.PartnerComplianceDocument, error) {
+	if _, err := uuid.Parse(documentID); err != nil {
+		return nil, fmt.Errorf("invalid document_id: %s", documentID)
+	}
+	issuedAt, expiresAt, err := validateComplianceDates(req.IssuedAt, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	document, err := s.repo.GetDocumentByID(partnerID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if document.Source != "manual" {
+		return nil, fmt.Errorf("invalid request: the insurance license is renewed through the partner profile")
+	}
+	fileID, err := s.resolveFileID(partnerID, req.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	document.DocumentNumber = trimOptional(req.DocumentNumber)
+	document.IssuedAt = issuedAt
+	document.ExpiresAt = expiresAt
+	document.FileID = fileID
+	if err := s.repo.RenewDocument(document); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.RefreshComplianceFlags(); err != nil {
+		slog.Error("failed to refresh compliance flags", "error", err)
+	}
+	slog.Info("compliance document renewed", "partner_id", partnerID, "document_id", documentID, "expires_at", req.ExpiresAt)
+	return s.repo.GetDocumentByID(partnerID, documentID)
+}
+
+func (s *PartnerComplianceService) DeleteDocument(actorID, partnerID, documentID string) error {
+	if _, err := uuid.Parse(documentID); err != nil {
+		return fmt.Errorf("invalid document_id: %s", documentID)
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteDocument(partnerID, documentID); err != nil {
+		return err
+	}
+	if _, err := s.repo.RefreshComplianceFlags(); err != nil {
+		slog.Error("failed to refresh compliance flags", "error", err)
+	}
+	slog.Info("compliance document deleted", "partner_id", partnerID, "document_id", documentID, "deleted_by", actorID)
+	return nil
+}
+
+// reminderThreshold returns the smallest reminder threshold covering daysLeft, or 0 when none does
+func reminderThreshold(daysLeft int) int {
+	threshold := 0
+	for _, days := range complianceReminderDays {
+		if daysLeft <= days {
+			threshold = days
+		}
+	}
+	return threshold
+}
+
+// RunExpiryCheck sends the due expiry reminders and flags partners whose documents lapsed.
+// Each reminder is sent once per expiry date, so the check can run at any interval.
+func (s *PartnerComplianceService) RunExpiryCheck(ctx context.Context) {
+	if synced, err := s.repo.SyncProfileLicenses(); err != nil {
+		slog.Error("failed to sync partner licenses", "error", err)
+	} else if synced > 0 {
+		slog.Info("partner licenses synced", "count", synced)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	expiring, err := s.repo.GetDocumentsExpiringWithin(complianceReminderDays[0])
+	if err != nil {
+		slog.Error("failed to get expiring compliance documents", "error", err)
+	}
+	for _, document := range expiring {
+		daysLeft := int(document.ExpiresAt.Sub(today).Hours() / 24)
+		threshold := reminderThreshold(daysLeft)
+		if threshold == 0 || (document.LastReminderDays != nil && *document.LastReminderDays <= threshold) {
+			continue
+		}
+		s.notifyPartnerAdmins(ctx, document, "Giấy tờ sắp hết hạn",
+			fmt.Sprintf("%s sẽ hết hạn sau %d ngày (%s). Vui lòng gia hạn và cập nhật hồ sơ.", document.DocumentName, daysLeft, document.ExpiresAt.Format("02/01/2006")),
+			"compliance_document_expiring")
+		if err := s.repo.MarkReminderSent(document.DocumentID.String(), threshold); err != nil {
+			slog.Error("failed to mark compliance reminder sent", "document_id", document.DocumentID, "error", err)
+		}
+	}
+
+	lapsed, err := s.repo.GetNewlyLapsedDocuments()
+	if err != nil {
+		slog.Error("failed to get lapsed compliance documents", "error", err)
+	}
+	for _, document := range lapsed {
+		if err := s.repo.MarkLapsed(document.DocumentID.String()); err != nil {
+			slog.Error("failed to mark compliance document lapsed", "document_id", document.DocumentID, "error", err)
+			continue
+		}
+		slog.Warn("compliance document lapsed, partner flagged", "partner_id", document.PartnerID, "document_id", document.DocumentID)
+		s.notifyPartnerAdmins(ctx, document, "Giấy tờ đã hết hạn",
+			fmt.Sprintf("%s đã hết hạn từ %s. Hồ sơ đối tác đã bị gắn cờ cho đến khi giấy tờ được gia hạn.", document.DocumentName, document.ExpiresAt.Format("02/01/2006")),
+			"compliance_document_lapsed")
+	}
+
+	if _, err := s.repo.RefreshComplianceFlags(); err != nil {
+		slog.Error("failed to refresh compliance flags", "error", err)
+	}
+}
+
+func (s *PartnerComplianceService) notifyPartnerAdmins(ctx context.Context, document models.PartnerComplianceDocument, title, body, notificationType string) {
+	userIDs, err := s.repo.GetPartnerAdminUserIDs(document.PartnerID.String())
+	if err != nil {
+		slog.Error("failed to get partner admins for compliance notification", "partner_id", document.PartnerID, "error", err)
+		return
+	}
+	if len(userIDs) == 0 {
+		slog.Warn("no partner admin to notify about compliance document", "partner_id", document.PartnerID, "document_id", document.DocumentID)
+		return
+	}
+
+	notification := event.NotificationEventPushModel{
+		LstUserIds: userIDs,
+		Title:      title,
+		Body:       body,
+		Data: map[string]any{
+			"type":        notificationType,
+			"partner_id":  document.PartnerID.String(),
+			"document_id": document.DocumentID.String(),
+			"expires_at":  document.ExpiresAt.Format("2006-01-02"),
+		},
+	}
+	if err := s.notificationPublisher.PublishNotification(ctx, notification); err != nil {
+		slog.Error("failed to publish compliance notification", "partner_id", document.PartnerID, "error", err)
+	}
+}
+
+// StartExpiryWatcher periodically runs the expiry check until ctx is cancelled
+func (s *PartnerComplianceService) StartExpiryWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunExpiryCheck(ctx)
+		}
+	}
+}
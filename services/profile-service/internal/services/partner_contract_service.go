@@ -0,0 +1,230 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultContractCurrency = "VND"
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type PartnerContractService struct {
+	repo                repository.IPartnerContractRepository
+	partnerRepository   repository.IInsurancePartnerRepository
+	partnerStaffService IPartnerStaffService
+}
+
+type IPartnerContractService interface {
+	GetContracts(actorID, partnerID string) ([]models.PartnerContract, error)
+	CreateContract(actorID, partnerID string, req *models.CreatePartnerContractRequest) (*models.PartnerContract, error)
+	ActivateContract(actorID, partnerID, contractID string) (*models.PartnerContract, error)
+	TerminateContract(actorID, partnerID, contractID string, req *models.TerminatePartnerContractRequest) (*models.PartnerContract, error)
+	DeleteDraftContract(actorID, partnerID, contractID string) error
+	GetContractTerms(partnerID, date string) (*models.PartnerContractTerms, error)
+}
+
+func NewPartnerContractService(repo repository.IPartnerContractRepository, partnerRepository repository.IInsurancePartnerRepository, partnerStaffService IPartnerStaffService) IPartnerContractService {
+	return &PartnerContractService{
+		repo:                repo,
+		partnerRepository:   partnerRepository,
+		partnerStaffService: partnerStaffService,
+	}
+}
+
+// authorizeAdminForPartner checks that the actor is a system admin and that the partner exists
+func (s *PartnerContractService) authorizeAdminForPartner(actorID, partnerID string) error {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	if err := s.partnerStaffService.AuthorizeAdmin(actorID); err != nil {
+		return err
+	}
+	if _, err := s.partnerRepository.GetInsurancePartnerByID(partnerID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateContractID(contractID string) error {
+	if _, err := uuid.Parse(contractID); err != nil {
+		return fmt.Errorf("invalid contract_id: %s", contractID)
+	}
+	return nil
+}
+
+// GetContracts lists every version of a partner's contracts, newest first. Partner managers
+// may read their own contracts; only admins change them.
+func (s *PartnerContractService) GetContracts(actorID, partnerID string) ([]models.PartnerContract, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetContractsByPartnerID(partnerID)
+}
+
+func (s *PartnerContractService) CreateContract(actorID, partnerID string, req *models.CreatePartnerContractRequest) (*models.PartnerContract, error) {
+	if *req.RevenueSharePercent < 0 || *req.RevenueSharePercent > 100 {
+		return nil, fmt.Errorf("invalid revenue_share_percent: must be between 0 and 100")
+	}
+	markup := 0.0
+	if req.DataCostMarkupPercent != nil {
+		markup = *req.DataCostMarkupPercent
+	}
+	if markup < 0 || markup > 1000 {
+		return nil, fmt.Errorf("invalid data_cost_markup_percent: must be between 0 and 1000")
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = defaultContractCurrency
+	}
+	if !currencyCodeRegex.MatchString(currency) {
+		return nil, fmt.Errorf("invalid currency: %s", req.Currency)
+	}
+	validFrom, err := parseDateField("valid_from", &req.ValidFrom)
+	if err != nil {
+		return nil, err
+	}
+	if validFrom == nil {
+		return nil, fmt.Errorf("invalid valid_from: required")
+	}
+	validTo, err := parseDateField("valid_to", req.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+	if validTo != nil && validTo.Before(*validFrom) {
+		return nil, fmt.Errorf("invalid valid_to: must not be before valid_from")
+	}
+	if err := s.authorizeAdminForPartner(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	contract := &models.PartnerContract{
+		PartnerID:             uuid.MustParse(partnerID),
+		ContractNumber:        trimOptional(req.ContractNumber),
+		RevenueSharePercent:   *req.RevenueSharePercent,
+		DataCostMarkupPercent: markup,
+		Currency:              currency,
+		ValidFrom:             *validFrom,
+		ValidTo:               validTo,
+		Notes:                 trimOptional(req.Notes),
+		CreatedBy:             actorID,
+	}
+	if err := s.repo.CreateContract(contract); err != nil {
+		return nil, err
+	}
+	slog.Info("partner contract drafted", "partner_id", partnerID, "contract_id", contract.ContractID, "version", contract.Version, "created_by", actorID)
+	return s.repo.GetContractByID(partnerID, contract.ContractID.String())
+}
+
+// ActivateContract puts a draft into force. Its validity must start after the current active
+// contract's, which then stays valid until the day before.
+func (s *PartnerContractService) ActivateContract(actorID, partnerID, contractID string) (*models.PartnerContract, error) {
+	if err := validateContractID(contractID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeAdminForPartner(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.repo.GetContractByID(partnerID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != models.ContractStatusDraft {
+		return nil, fmt.Errorf("invalid request: only draft contracts can be activated, contract is %s", draft.Status)
+	}
+	active, err := s.repo.GetActiveContract(partnerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if active != nil && !draft.ValidFrom.After(active.ValidFrom) {
+		return nil, fmt.Errorf("invalid valid_from: must be after %s when version %d is active", active.ValidFrom.Format("2006-01-02"), active.Version)
+	}
+
+	if err := s.repo.ActivateContract(partnerID, contractID, actorID); err != nil {
+		return nil, err
+	}
+	slog.Info("partner contract activated", "partner_id", partnerID, "contract_id", contractID, "version", draft.Version, "activated_by", actorID)
+	return s.repo.GetContractByID(partnerID, contractID)
+}
+
+func (s *PartnerContractService) TerminateContract(actorID, partnerID, contractID string, req *models.TerminatePartnerContractRequest) (*models.PartnerContract, error) {
+	if err := validateContractID(contractID); err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("invalid reason: required")
+	}
+	validTo, err := parseDateField("valid_to", &req.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+	if validTo == nil {
+		return nil, fmt.Errorf("invalid valid_to: required")
+	}
+	if err := s.authorizeAdminForPartner(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	contract, err := s.repo.GetContractByID(partnerID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if validTo.Before(contract.ValidFrom) {
+		return nil, fmt.Errorf("invalid valid_to: must not be before valid_from")
+	}
+	if err := s.repo.TerminateContract(partnerID, contractID, *validTo, reason); err != nil {
+		return nil, err
+	}
+	slog.Info("partner contract terminated", "partner_id", partnerID, "contract_id", contractID, "valid_to", req.ValidTo, "terminated_by", actorID)
+	return s.repo.GetContractByID(partnerID, contractID)
+}
+
+func (s *PartnerContractService) DeleteDraftContract(actorID, partnerID, contractID string) error {
+	if err := validateContractID(contractID); err != nil {
+		return err
+	}
+	if err := s.authorizeAdminForPartner(actorID, partnerID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteDraftContract(partnerID, contractID); err != nil {
+		return err
+	}
+	slog.Info("partner contract draft deleted", "partner_id", partnerID, "contract_id", contractID, "deleted_by", actorID)
+	return nil
+}
+
+// GetContractTerms is used by billing to find the terms that apply to a partner on a date,
+// today when date is empty
+func (s *PartnerContractService) GetContractTerms(partnerID, date string) (*models.PartnerContractTerms, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	on := time.Now()
+	if strings.TrimSpace(date) != "" {
+		parsed, err := parseDateField("date", &date)
+		if err != nil {
+			return nil, err
+		}
+		on = *parsed
+	}
+
+	terms, err := s.repo.GetTermsInEffect(partnerID, on)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("contract terms for partner %s on %s: %w", partnerID, on.Format("2006-01-02"), err)
+		}
+		return nil, err
+	}
+	return terms, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"profile-service/internal/database/minio"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PresignedURLExpiry is how long presigned partner file URLs stay valid
+const PresignedURLExpiry = time.Hour
+
+type partnerFileRule struct {
+	bucket       string
+	contentTypes []string
+	maxSizeBytes int64
+}
+
+var partnerFileRules = map[models.PartnerFileType]partnerFileRule{
+	models.PartnerFileLogo: {
+		bucket:       minio.Storage.PartnerLogos,
+		contentTypes: []string{"image/png", "image/jpeg", "image/webp"},
+		maxSizeBytes: 2 << 20,
+	},
+	models.PartnerFileCoverPhoto: {
+		bucket:       minio.Storage.PartnerAssets,
+		contentTypes: []string{"image/png", "image/jpeg", "image/webp"},
+		maxSizeBytes: 5 << 20,
+	},
+	models.PartnerFileLicense: {
+		bucket:       minio.Storage.PartnerDocuments,
+		contentTypes: []string{"application/pdf", "image/png", "image/jpeg"},
+		maxSizeBytes: 10 << 20,
+	},
+	models.PartnerFileMarketing: {
+		bucket:       minio.Storage.PartnerAssets,
+		contentTypes: []string{"image/png", "image/jpeg", "image/webp", "application/pdf"},
+		maxSizeBytes: 20 << 20,
+	},
+}
+
+var contentTypeExtensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+type PartnerFileService struct {
+	repo                repository.IPartnerFileRepository
+	partnerStaffService IPartnerStaffService
+	storage             *minio.MinioClient
+}
+
+type IPartnerFileService interface {
+	UploadFile(actorID, partnerID string, fileType models.PartnerFileType, header *multipart.FileHeader) (*models.PartnerFile, error)
+	GetFiles(actorID, partnerID string, fileType models.PartnerFileType) ([]models.PartnerFile, error)
+	DeleteFile(actorID, partnerID, fileID string) error
+}
+
+func NewPartnerFileService(repo repository.IPartnerFileRepository, partnerStaffService IPartnerStaffService, storage *minio.MinioClient) IPartnerFileService {
+	return &PartnerFileService{
+		repo:                repo,
+		partnerStaffService: partnerStaffService,
+		storage:             storage,
+	}
+}
+
+func (s *PartnerFileService) UploadFile(actorID, partnerID string, fileType models.PartnerFileType, header *multipart.FileHeader) (*models.PartnerFile, error) {
+	if s.storage == nil {
+		return nil, fmt.Errorf("file storage is unavailable")
+	}
+	rule, ok := partnerFileRules[fileType]
+	if !ok {
+		return nil, fmt.Errorf("invalid file_type: %s", fileType)
+	}
+	if header.Size <= 0 || header.Size > rule.maxSizeBytes {
+		return nil, fmt.Errorf("invalid file size: %s files must be at most %d MB", fileType, rule.maxSizeBytes>>20)
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("invalid file: %w", err)
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, rule.maxSizeBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	if int64(len(data)) > rule.maxSizeBytes {
+		return nil, fmt.Errorf("invalid file size: %s files must be at most %d MB", fileType, rule.maxSizeBytes>>20)
+	}
+
+	// The declared content type is not trusted; the type is sniffed from the content
+	contentType := http.DetectContentType(data)
+	if idx := strings.Index(contentType, ";"); idx >= 0 {
+		contentType = contentType[:idx]
+	}
+	if !slices.Contains(rule.contentTypes, contentType) {
+		return nil, fmt.Errorf("invalid file type %s: allowed types for %s are %s", contentType, fileType, strings.Join(rule.contentTypes, ", "))
+	}
+
+	file := &models.PartnerFile{
+		PartnerID:   uuid.MustParse(partnerID),
+		FileType:    fileType,
+		BucketName:  rule.bucket,
+		ObjectName:  fmt.Sprintf("%s/%s/%s%s", partnerID, fileType, uuid.New().String(), contentTypeExtensions[contentType]),
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		UploadedBy:  actorID,
+	}
+
+	ctx := context.Background()
+	if err := s.storage.UploadFile(ctx, file.BucketName, file.ObjectName, bytes.NewReader(data), file.SizeBytes, contentType); err != nil {
+		return nil, err
+	}
+
+	// Logos and cover photos are single-valued; the files they replace are removed
+	var replaced []models.PartnerFile
+	if fileType == models.PartnerFileLogo || fileType == models.PartnerFileCoverPhoto {
+		if replaced, err = s.repo.GetFilesByPartnerID(partnerID, fileType); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.CreateFile(file, minio.ObjectRef(file.BucketName, file.ObjectName)); err != nil {
+		if delErr := s.storage.DeleteFile(ctx, file.BucketName, file.ObjectName); delErr != nil {
+			slog.Error("failed to clean up uploaded partner file", "object_name", file.ObjectName, "error", delErr)
+		}
+		return nil, err
+	}
+	for i := range replaced {
+		s.removeFile(ctx, &replaced[i])
+	}
+	slog.Info("partner file uploaded", "partner_id", partnerID, "file_id", file.FileID, "file_type", fileType, "uploaded_by", actorID)
+
+	file.URL = s.storage.PresignRef(ctx, minio.ObjectRef(file.BucketName, file.ObjectName), PresignedURLExpiry)
+	return file, nil
+}
+
+func (s *PartnerFileService) GetFiles(actorID, partnerID string, fileType models.PartnerFileType) ([]models.PartnerFile, error) {
+	if fileType != "" {
+		if _, ok := partnerFileRules[fileType]; !ok {
+			return nil, fmt.Errorf("invalid file_type: %s", fileType)
+		}
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	files, err := s.repo.GetFilesByPartnerID(partnerID, fileType)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	for i := range files {
+		files[i].URL = s.storage.PresignRef(ctx, minio.ObjectRef(files[i].BucketName, files[i].ObjectName), PresignedURLExpiry)
+	}
+	return files, nil
+}
+
+func (s *PartnerFileService) DeleteFile(actorID, partnerID, fileID string) error {
+	if _, err := uuid.Parse(fileID); err != nil {
+		return fmt.Errorf("invalid file_id: %s", fileID)
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+
+	file, err := s.repo.GetFileByID(partnerID, fileID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteFile(file, minio.ObjectRef(file.BucketName, file.ObjectName)); err != nil {
+		return err
+	}
+	if s.storage != nil {
+		if err := s.storage.DeleteFile(context.Background(), file.BucketName, file.ObjectName); err != nil {
+			slog.Error("failed to delete partner file object", "file_id", fileID, "error", err)
+		}
+	}
+	slog.Info("partner file deleted", "partner_id", partnerID, "file_id", fileID, "deleted_by", actorID)
+	return nil
+}
+
+func (s *PartnerFileService) removeFile(ctx context.Context, file *models.PartnerFile) {
+	if err := s.repo.DeleteFile(file, minio.ObjectRef(file.BucketName, file.ObjectName)); err != nil {
+		slog.Error("failed to delete replaced partner file", "file_id", file.FileID, "error", err)
+		return
+	}
+	if err := s.storage.DeleteFile(ctx, file.BucketName, file.ObjectName); err != nil {
+		slog.Error("failed to delete replaced partner file object", "file_id", file.FileID, "error", err)
+	}
+}
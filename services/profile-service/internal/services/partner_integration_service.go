@@ -0,0 +1,371 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookSecretBytes      = 32
+	apiClientIDBytes        = 12
+	apiClientSecretBytes    = 32
+	apiCredentialMaxTTLDays = 730
+)
+
+type PartnerIntegrationService struct {
+	repo                repository.IPartnerIntegrationRepository
+	partnerStaffService IPartnerStaffService
+}
+
+type IPartnerIntegrationService interface {
+	GetIntegrationSettings(actorID, partnerID string) (*models.PartnerIntegrationResponse, error)
+	UpsertWebhooks(actorID, partnerID string, req *models.UpsertPartnerWebhooksRequest) (*models.PartnerIntegrationResponse, error)
+	DeleteWebhook(actorID, partnerID string, eventType models.WebhookEventType) error
+	RotateWebhookSecret(actorID, partnerID string) (*models.WebhookSecretResponse, error)
+	UpdateIPAllowlist(actorID, partnerID string, allowlist []string) (*models.PartnerIntegrationResponse, error)
+	GetAPICredentials(actorID, partnerID string) ([]models.PartnerAPICredential, error)
+	IssueAPICredential(actorID, partnerID string, req *models.CreateAPICredentialRequest) (*models.IssuedAPICredentialResponse, error)
+	RevokeAPICredential(actorID, partnerID, credentialID string) error
+	GetWebhookTarget(partnerID string, eventType models.WebhookEventType) (*models.WebhookTargetResponse, error)
+	VerifyAPICredential(req *models.VerifyAPICredentialRequest) (*models.VerifyAPICredentialResponse, error)
+}
+
+func NewPartnerIntegrationService(repo repository.IPartnerIntegrationRepository, partnerStaffService IPartnerStaffService) IPartnerIntegrationService {
+	return &PartnerIntegrationService{
+		repo:                repo,
+		partnerStaffService: partnerStaffService,
+	}
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook url: %s", raw)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("invalid webhook url: %s must use https", raw)
+	}
+	return nil
+}
+
+// normalizeIPAllowlist validates entries as IPs or CIDR ranges and stores single IPs as host ranges
+func normalizeIPAllowlist(entries []string) ([]string, error) {
+	allowlist := []string{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip allowlist entry: %s", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip allowlist entry: %s", entry)
+		}
+		if cidr := network.String(); !seen[cidr] {
+			seen[cidr] = true
+			allowlist = append(allowlist, cidr)
+		}
+	}
+	return allowlist, nil
+}
+
+func ipAllowed(allowlist []string, clientIP string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	ip := net.ParseIP(strings.TrimSpace(clientIP))
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowlist {
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PartnerIntegrationService) buildResponse(partnerID string) (*models.PartnerIntegrationResponse, error) {
+	webhooks, err := s.repo.GetWebhooksByPartnerID(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	response := &models.PartnerIntegrationResponse{
+		PartnerID:   partnerID,
+		Webhooks:    webhooks,
+		IPAllowlist: []string{},
+	}
+
+	settings, err := s.repo.GetSettings(partnerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return response, nil
+		}
+		return nil, err
+	}
+	response.IPAllowlist = settings.IPAllowlist
+	response.HasWebhookSecret = settings.WebhookSecret != nil && *settings.WebhookSecret != ""
+	response.WebhookSecretRotatedAt = settings.WebhookSecretRotatedAt
+	return response, nil
+}
+
+func (s *PartnerIntegrationService) GetIntegrationSettings(actorID, partnerID string) (*models.PartnerIntegrationResponse, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	return s.buildResponse(partnerID)
+}
+
+func (s *PartnerIntegrationService) UpsertWebhooks(actorID, partnerID string, req *models.UpsertPartnerWebhooksRequest) (*models.PartnerIntegrationResponse, error) {
+	webhooks := make([]models.PartnerWebhook, 0, len(req.Webhooks))
+	for _, input := range req.Webhooks {
+		if !input.EventType.IsValid() {
+			return nil, fmt.Errorf("invalid webhook event type: %s", input.EventType)
+		}
+		webhookURL := strings.TrimSpace(input.URL)
+		if err := validateWebhookURL(webhookURL); err != nil {
+			return nil, err
+		}
+		webhook := models.PartnerWebhook{
+			EventType: input.EventType,
+			URL:       webhookURL,
+			IsActive:  true,
+		}
+		if input.IsActive != nil {
+			webhook.IsActive = *input.IsActive
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertWebhooks(partnerID, webhooks); err != nil {
+		return nil, err
+	}
+	slog.Info("partner webhooks updated", "partner_id", partnerID, "count", len(webhooks), "updated_by", actorID)
+	return s.buildResponse(partnerID)
+}
+
+func (s *PartnerIntegrationService) DeleteWebhook(actorID, partnerID string, eventType models.WebhookEventType) error {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteWebhook(partnerID, eventType); err != nil {
+		return err
+	}
+	slog.Info("partner webhook deleted", "partner_id", partnerID, "event_type", eventType, "deleted_by", actorID)
+	return nil
+}
+
+func (s *PartnerIntegrationService) RotateWebhookSecret(actorID, partnerID string) (*models.WebhookSecretResponse, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	secret, err := randomHex(webhookSecretBytes)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.repo.UpdateWebhookSecret(partnerID, "whsec_"+secret, actorID)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("partner webhook secret rotated", "partner_id", partnerID, "rotated_by", actorID)
+
+	return &models.WebhookSecretResponse{
+		WebhookSecret: *settings.WebhookSecret,
+		RotatedAt:     *settings.WebhookSecretRotatedAt,
+	}, nil
+}
+
+func (s *PartnerIntegrationService) UpdateIPAllowlist(actorID, partnerID string, allowlist []string) (*models.PartnerIntegrationResponse, error) {
+	normalized, err := normalizeIPAllowlist(allowlist)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.UpdateIPAllowlist(partnerID, normalized, actorID); err != nil {
+		return nil, err
+	}
+	slog.Info("partner ip allowlist updated", "partner_id", partnerID, "entries", len(normalized), "updated_by", actorID)
+	return s.buildResponse(partnerID)
+}
+
+func (s *PartnerIntegrationService) GetAPICredentials(actorID, partnerID string) ([]models.PartnerAPICredential, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCredentialsByPartnerID(partnerID)
+}
+
+func (s *PartnerIntegrationService) IssueAPICredential(actorID, partnerID string, req *models.CreateAPICredentialRequest) (*models.IssuedAPICredentialResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("invalid credential name")
+	}
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays <= 0 || *req.ExpiresInDays > apiCredentialMaxTTLDays {
+			return nil, fmt.Errorf("invalid expires_in_days: must be between 1 and %d", apiCredentialMaxTTLDays)
+		}
+		expiry := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &expiry
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	clientID, err := randomHex(apiClientIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	clientSecret, err := randomHex(apiClientSecretBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := &models.PartnerAPICredential{
+		PartnerID:  uuid.MustParse(partnerID),
+		Name:       name,
+		ClientID:   "agp_" + clientID,
+		SecretHash: hashClientSecret(clientSecret),
+		ExpiresAt:  expiresAt,
+		CreatedBy:  &actorID,
+	}
+	if err := s.repo.CreateCredential(credential); err != nil {
+		return nil, err
+	}
+	slog.Info("partner api credential issued", "partner_id", partnerID, "credential_id", credential.CredentialID, "created_by", actorID)
+
+	return &models.IssuedAPICredentialResponse{
+		Credential:   *credential,
+		ClientSecret: clientSecret,
+	}, nil
+}
+
+func (s *PartnerIntegrationService) RevokeAPICredential(actorID, partnerID, credentialID string) error {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return fmt.Errorf("invalid credential_id: %s", credentialID)
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeCredential(partnerID, credentialID, actorID); err != nil {
+		return err
+	}
+	slog.Info("partner api credential revoked", "partner_id", partnerID, "credential_id", credentialID, "revoked_by", actorID)
+	return nil
+}
+
+// GetWebhookTarget returns the delivery URL and signing secret of a partner's webhook for an event
+func (s *PartnerIntegrationService) GetWebhookTarget(partnerID string, eventType models.WebhookEventType) (*models.WebhookTargetResponse, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	if !eventType.IsValid() {
+		return nil, fmt.Errorf("invalid webhook event type: %s", eventType)
+	}
+
+	webhook, err := s.repo.GetWebhook(partnerID, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.IsActive {
+		return nil, fmt.Errorf("active partner webhook: %w", sql.ErrNoRows)
+	}
+	settings, err := s.repo.GetSettings(partnerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if settings == nil || settings.WebhookSecret == nil {
+		return nil, fmt.Errorf("forbidden: partner has no webhook secret configured")
+	}
+
+	return &models.WebhookTargetResponse{
+		PartnerID: partnerID,
+		EventType: eventType,
+		URL:       webhook.URL,
+		Secret:    *settings.WebhookSecret,
+	}, nil
+}
+
+// VerifyAPICredential authenticates a partner API call for the gateway. Rejections are
+// reported in the response rather than as errors so the gateway can log the reason.
+func (s *PartnerIntegrationService) VerifyAPICredential(req *models.VerifyAPICredentialRequest) (*models.VerifyAPICredentialResponse, error) {
+	credential, err := s.repo.GetCredentialByClientID(strings.TrimSpace(req.ClientID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.VerifyAPICredentialResponse{Reason: "unknown client_id"}, nil
+		}
+		return nil, err
+	}
+
+	hash := hashClientSecret(req.ClientSecret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(credential.SecretHash)) != 1 {
+		return &models.VerifyAPICredentialResponse{Reason: "invalid client_secret"}, nil
+	}
+	if credential.Status != models.APICredentialActive {
+		return &models.VerifyAPICredentialResponse{Reason: "credential revoked"}, nil
+	}
+	if credential.ExpiresAt != nil && time.Now().After(*credential.ExpiresAt) {
+		return &models.VerifyAPICredentialResponse{Reason: "credential expired"}, nil
+	}
+
+	partnerID := credential.PartnerID.String()
+	settings, err := s.repo.GetSettings(partnerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if settings != nil && !ipAllowed(settings.IPAllowlist, req.ClientIP) {
+		return &models.VerifyAPICredentialResponse{Reason: "client ip not allowed"}, nil
+	}
+
+	if err := s.repo.TouchCredential(credential.CredentialID.String()); err != nil {
+		slog.Warn("failed to record api credential use", "credential_id", credential.CredentialID, "error", err)
+	}
+	return &models.VerifyAPICredentialResponse{
+		Valid:     true,
+		PartnerID: partnerID,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"profile-service/internal/event"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// partnerTransition describes one lifecycle action: the statuses it may start from, the status it
+// leads to and the event other services receive when it happens
+type partnerTransition struct {
+	from           []models.PartnerStatus
+	to             models.PartnerStatus
+	reasonRequired bool
+	eventType      event.ProfileEventType
+}
+
+var partnerTransitions = map[string]partnerTransition{
+	"review": {
+		from: []models.PartnerStatus{models.PartnerStatusPending},
+		to:   models.PartnerStatusUnderReview,
+	},
+	"approve": {
+		from: []models.PartnerStatus{models.PartnerStatusPending, models.PartnerStatusUnderReview},
+		to:   models.PartnerStatusActive,
+	},
+	"suspend": {
+		from:           []models.PartnerStatus{models.PartnerStatusActive},
+		to:             models.PartnerStatusSuspended,
+		reasonRequired: true,
+		eventType:      event.PartnerSuspended,
+	},
+	"reinstate": {
+		from:      []models.PartnerStatus{models.PartnerStatusSuspended},
+		to:        models.PartnerStatusActive,
+		eventType: event.PartnerReinstated,
+	},
+	"terminate": {
+		from:           []models.PartnerStatus{models.PartnerStatusPending, models.PartnerStatusUnderReview, models.PartnerStatusActive, models.PartnerStatusSuspended},
+		to:             models.PartnerStatusTerminated,
+		reasonRequired: true,
+		eventType:      event.PartnerTerminated,
+	},
+}
+
+type PartnerLifecycleService struct {
+	repo                  repository.IPartnerLifecycleRepository
+	partnerRepository     repository.IInsurancePartnerRepository
+	userProfileRepository repository.IUserRepository
+	partnerStaffService   IPartnerStaffService
+	profilePublisher      *event.NotificationPublisher
+}
+
+type IPartnerLifecycleService interface {
+	TransitionStatus(actorID, partnerID, action, reason string) (*models.PartnerStatusHistory, error)
+	GetStatusHistory(actorID, partnerID string) ([]models.PartnerStatusHistory, error)
+}
+
+func NewPartnerLifecycleService(repo repository.IPartnerLifecycleRepository, partnerRepository repository.IInsurancePartnerRepository, userProfileRepository repository.IUserRepository, partnerStaffService IPartnerStaffService, profilePublisher *event.NotificationPublisher) IPartnerLifecycleService {
+	return &PartnerLifecycleService{
+		repo:                  repo,
+		partnerRepository:     partnerRepository,
+		userProfileRepository: userProfileRepository,
+		partnerStaffService:   partnerStaffService,
+		profilePublisher:      profilePublisher,
+	}
+}
+
+func (s *PartnerLifecycleService) TransitionStatus(actorID, partnerID, action, reason string) (*models.PartnerStatusHistory, error) {
+	transition, ok := partnerTransitions[strings.ToLower(action)]
+	if !ok {
+		return nil, fmt.Errorf("invalid action: %s", action)
+	}
+	reason = strings.TrimSpace(reason)
+	if transition.reasonRequired && reason == "" {
+		return nil, fmt.Errorf("invalid request: a reason is required to %s a partner", action)
+	}
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	if err := s.partnerStaffService.AuthorizeAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	partner, err := s.partnerRepository.GetInsurancePartnerByID(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	current := models.PartnerStatus(partner.Status)
+	if !slices.Contains(transition.from, current) {
+		return nil, fmt.Errorf("invalid status transition: cannot %s a partner in status %s", action, current)
+	}
+
+	history := &models.PartnerStatusHistory{
+		PartnerID:  partner.PartnerID,
+		FromStatus: current,
+		ToStatus:   transition.to,
+		ChangedBy:  actorID,
+	}
+	if reason != "" {
+		history.Reason = &reason
+	}
+	if actor, err := s.userProfileRepository.GetUserProfileByUserID(actorID); err == nil && actor.FullName != "" {
+		history.ChangedByName = &actor.FullName
+	}
+	if err := s.repo.TransitionStatus(history); err != nil {
+		return nil, err
+	}
+	slog.Info("partner status changed", "partner_id", partnerID, "from", current, "to", transition.to, "changed_by", actorID)
+
+	if transition.eventType != "" {
+		s.publishTransition(history, transition.eventType)
+	}
+	return history, nil
+}
+
+// publishTransition tells policy-service about the change, e.g. to pause new registrations
+// on the base policies of a suspended partner
+func (s *PartnerLifecycleService) publishTransition(history *models.PartnerStatusHistory, eventType event.ProfileEventType) {
+	go func() {
+		eventPayload := event.ProfileEvent{
+			ID:        uuid.NewString(),
+			EventType: eventType,
+			UserID:    history.ChangedBy,
+			ProfileID: history.PartnerID.String(),
+			Additional: map[string]any{
+				"fromStatus": history.FromStatus,
+				"toStatus":   history.ToStatus,
+				"reason":     history.Reason,
+			},
+		}
+		if err := s.profilePublisher.PublishEvent(context.Background(), eventPayload); err != nil {
+			slog.Error("error publishing event", "error", err)
+			return
+		}
+		slog.Info("profile event published", "event", eventPayload)
+	}()
+}
+
+func (s *PartnerLifecycleService) GetStatusHistory(actorID, partnerID string) ([]models.PartnerStatusHistory, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStatusHistory(partnerID)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	partnerPublicStatsURL      = "http://policy-service:8089/policy/public/api/v2/dashboard/partner/%s/public-stats"
+	marketplaceProfileCacheTTL = 2 * time.Minute
+)
+
+type marketplaceCacheEntry struct {
+	profile   *models.PartnerMarketplaceProfile
+	expiresAt time.Time
+}
+
+type PartnerMarketplaceService struct {
+	partnerRepository       repository.IInsurancePartnerRepository
+	insurancePartnerService IInsurancePartnerService
+	partnerReviewService    IPartnerReviewService
+	httpClient              *http.Client
+
+	cacheMu sync.Mutex
+	cache   map[string]marketplaceCacheEntry
+}
+
+type IPartnerMarketplaceService interface {
+	GetMarketplaceProfile(partnerID string) (*models.PartnerMarketplaceProfile, error)
+}
+
+func NewPartnerMarketplaceService(partnerRepository repository.IInsurancePartnerRepository, insurancePartnerService IInsurancePartnerService, partnerReviewService IPartnerReviewService) IPartnerMarketplaceService {
+	return &PartnerMarketplaceService{
+		partnerRepository:       partnerRepository,
+		insurancePartnerService: insurancePartnerService,
+		partnerReviewService:    partnerReviewService,
+		httpClient:              &http.Client{Timeout: 5 * time.Second},
+		cache:                   make(map[string]marketplaceCacheEntry),
+	}
+}
+
+// fetchPublicStats asks policy-service for the partner's base policy and claim settlement figures
+func (s *PartnerMarketplaceService) fetchPublicStats(partnerID string) (*int64, *models.PartnerClaimStats, error) {
+	resp, err := s.httpClient.Get(fmt.Sprintf(partnerPublicStatsURL, partnerID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			ActiveBasePolicyCount int64 `json:"active_base_policy_count"`
+			models.PartnerClaimStats
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+	return &result.Data.ActiveBasePolicyCount, &result.Data.PartnerClaimStats, nil
+}
+
+// GetMarketplaceProfile combines the public profile, rating and policy-service figures of an
+// active partner. Results are cached briefly since the page is read far more than it changes.
+func (s *PartnerMarketplaceService) GetMarketplaceProfile(partnerID string) (*models.PartnerMarketplaceProfile, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+
+	s.cacheMu.Lock()
+	entry, ok := s.cache[partnerID]
+	if ok && time.Now().After(entry.expiresAt) {
+		delete(s.cache, partnerID)
+		ok = false
+	}
+	s.cacheMu.Unlock()
+	if ok {
+		return entry.profile, nil
+	}
+
+	partner, err := s.partnerRepository.GetInsurancePartnerByID(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if partner.Status != string(models.PartnerStatusActive) {
+		return nil, fmt.Errorf("insurance partner %s: %w", partnerID, sql.ErrNoRows)
+	}
+
+	publicProfile, err := s.insurancePartnerService.GetPublicProfile(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := s.partnerReviewService.GetRatingSummary(partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.PartnerMarketplaceProfile{
+		Partner:     *publicProfile,
+		Rating:      *rating,
+		GeneratedAt: time.Now(),
+	}
+	// The profile is still worth showing without the policy-service figures
+	profile.ActiveBasePolicyCount, profile.ClaimStats, err = s.fetchPublicStats(partnerID)
+	if err != nil {
+		slog.Error("failed to fetch partner public stats", "partner_id", partnerID, "error", err)
+	}
+
+	s.cacheMu.Lock()
+	s.cache[partnerID] = marketplaceCacheEntry{
+		profile:   profile,
+		expiresAt: time.Now().Add(marketplaceProfileCacheTTL),
+	}
+	s.cacheMu.Unlock()
+	return profile, nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	farmerPoliciesURL     = "http://policy-service:8089/policy/protected/api/v2/policies/read-own/list"
+	maxReviewContentRunes = 2000
+)
+
+// Policy statuses of a policy whose coverage has run its course
+var completedPolicyStatuses = map[string]bool{
+	"expired": true,
+	"payout":  true,
+}
+
+type PartnerReviewService struct {
+	repo                  repository.IPartnerReviewRepository
+	partnerRepository     repository.IInsurancePartnerRepository
+	userProfileRepository repository.IUserRepository
+	httpClient            *http.Client
+}
+
+type IPartnerReviewService interface {
+	SubmitReview(farmerID, partnerID string, req *models.SubmitPartnerReviewRequest) (*models.PartnerReviewDetail, error)
+	GetOwnReview(farmerID, partnerID string) (*models.PartnerReviewDetail, error)
+	DeleteOwnReview(farmerID, partnerID string) error
+	GetReviewsForModeration(actorID string, status models.ReviewModerationStatus, limit, page int) ([]models.PartnerReviewDetail, error)
+	ModerateReview(actorID, reviewID string, req *models.ModeratePartnerReviewRequest) (*models.PartnerReviewDetail, error)
+	GetRatingSummary(partnerID string) (*models.PartnerRatingSummary, error)
+}
+
+func NewPartnerReviewService(repo repository.IPartnerReviewRepository, partnerRepository repository.IInsurancePartnerRepository, userProfileRepository repository.IUserRepository) IPartnerReviewService {
+	return &PartnerReviewService{
+		repo:                  repo,
+		partnerRepository:     partnerRepository,
+		userProfileRepository: userProfileRepository,
+		httpClient:            &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// findCompletedPolicy asks policy-service for the farmer's policies and returns the ID of
+// one with the partner whose coverage has completed
+func (s *PartnerReviewService) findCompletedPolicy(farmerID, partnerID string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, farmerPoliciesURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("X-User-ID", farmerID)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		slog.Error("Error fetching farmer policies", "farmer_id", farmerID, "error", err)
+		return "", fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("Unexpected status code for farmer policies", "status_code", resp.StatusCode, "body", string(body))
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Policies []struct {
+				ID                  string `json:"id"`
+				InsuranceProviderID string `json:"insurance_provider_id"`
+				Status              string `json:"status"`
+			} `json:"policies"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("error parsing JSON: %w", err)
+	}
+
+	for _, policy := range result.Data.Policies {
+		if policy.InsuranceProviderID == partnerID && completedPolicyStatuses[policy.Status] {
+			return policy.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *PartnerReviewService) SubmitReview(farmerID, partnerID string, req *models.SubmitPartnerReviewRequest) (*models.PartnerReviewDetail, error) {
+	if farmerID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	if req.RatingStars < 1 || req.RatingStars > 5 {
+		return nil, fmt.Errorf("invalid rating_stars: must be between 1 and 5")
+	}
+	var content *string
+	if req.ReviewContent != nil {
+		trimmed := strings.TrimSpace(*req.ReviewContent)
+		if len([]rune(trimmed)) > maxReviewContentRunes {
+			return nil, fmt.Errorf("invalid review_content: must not exceed %d characters", maxReviewContentRunes)
+		}
+		if trimmed != "" {
+			content = &trimmed
+		}
+	}
+
+	if _, err := s.partnerRepository.GetPrivateProfile(partnerID); err != nil {
+		return nil, err
+	}
+	profile, err := s.userProfileRepository.GetUserProfileByUserID(farmerID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.RoleID != models.AuthRoleFarmer {
+		return nil, fmt.Errorf("forbidden: only farmers can review insurance partners")
+	}
+
+	policyID, err := s.findCompletedPolicy(farmerID, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check policy eligibility: %w", err)
+	}
+	if policyID == "" {
+		return nil, fmt.Errorf("forbidden: a completed policy with this insurance partner is required to leave a review")
+	}
+
+	reviewerName := profile.DisplayName
+	if reviewerName == "" {
+		reviewerName = profile.FullName
+	}
+	review := &models.PartnerReviewDetail{
+		PartnerReview: models.PartnerReview{
+			PartnerID:     partnerID,
+			ReviewerID:    farmerID,
+			ReviewerName:  reviewerName,
+			RatingStars:   req.RatingStars,
+			ReviewContent: content,
+		},
+		PolicyID: &policyID,
+	}
+	if err := s.repo.UpsertReview(review); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RefreshPartnerRating(partnerID); err != nil {
+		slog.Error("failed to refresh partner rating", "partner_id", partnerID, "error", err)
+	}
+	slog.Info("partner review submitted", "partner_id", partnerID, "reviewer_id", farmerID, "rating_stars", req.RatingStars)
+	return review, nil
+}
+
+func (s *PartnerReviewService) GetOwnReview(farmerID, partnerID string) (*models.PartnerReviewDetail, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	return s.repo.GetReviewByReviewer(partnerID, farmerID)
+}
+
+func (s *PartnerReviewService) DeleteOwnReview(farmerID, partnerID string) error {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	if err := s.repo.DeleteReview(partnerID, farmerID); err != nil {
+		return err
+	}
+	if err := s.repo.RefreshPartnerRating(partnerID); err != nil {
+		slog.Error("failed to refresh partner rating", "partner_id", partnerID, "error", err)
+	}
+	return nil
+}
+
+func (s *PartnerReviewService) requireAdmin(actorID string) error {
+	if actorID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+	actor, err := s.userProfileRepository.GetUserProfileByUserID(actorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("forbidden: only admins can moderate reviews")
+		}
+		return err
+	}
+	if actor.RoleID != models.AuthRoleAdmin {
+		return fmt.Errorf("forbidden: only admins can moderate reviews")
+	}
+	return nil
+}
+
+func (s *PartnerReviewService) GetReviewsForModeration(actorID string, status models.ReviewModerationStatus, limit, page int) ([]models.PartnerReviewDetail, error) {
+	if status == "" {
+		status = models.ReviewPublished
+	}
+	if !status.IsValid() {
+		return nil, fmt.Errorf("invalid moderation_status: %s", status)
+	}
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	return s.repo.GetReviewsByModerationStatus(status, limit, (page-1)*limit)
+}
+
+func (s *PartnerReviewService) ModerateReview(actorID, reviewID string, req *models.ModeratePartnerReviewRequest) (*models.PartnerReviewDetail, error) {
+	if _, err := uuid.Parse(reviewID); err != nil {
+		return nil, fmt.Errorf("invalid review_id: %s", reviewID)
+	}
+	if !req.ModerationStatus.IsValid() {
+		return nil, fmt.Errorf("invalid moderation_status: %s", req.ModerationStatus)
+	}
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	review, err := s.repo.GetReviewByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ModerateReview(reviewID, req.ModerationStatus, actorID, req.ModerationNote); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RefreshPartnerRating(review.PartnerID); err != nil {
+		slog.Error("failed to refresh partner rating", "partner_id", review.PartnerID, "error", err)
+	}
+	slog.Info("partner review moderated", "review_id", reviewID, "moderation_status", req.ModerationStatus, "moderated_by", actorID)
+	return s.repo.GetReviewByID(reviewID)
+}
+
+func (s *PartnerReviewService) GetRatingSummary(partnerID string) (*models.PartnerRatingSummary, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	distribution, err := s.repo.GetRatingDistribution(partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.PartnerRatingSummary{
+		PartnerID:    partnerID,
+		Distribution: distribution,
+	}
+	total := 0
+	for stars, count := range distribution {
+		summary.RatingCount += count
+		total += stars * count
+	}
+	if summary.RatingCount > 0 {
+		summary.AverageScore = math.Round(float64(total)/float64(summary.RatingCount)*10) / 10
+	}
+	return summary, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	bankAccountNumberRegex   = regexp.MustCompile(`^[0-9]{6,20}$`)
+	walletAccountNumberRegex = regexp.MustCompile(`^[0-9A-Za-z._@-]{6,50}$`)
+)
+
+type PartnerSettlementService struct {
+	repo                repository.IPartnerSettlementRepository
+	partnerStaffService IPartnerStaffService
+}
+
+type IPartnerSettlementService interface {
+	GetAccounts(actorID, partnerID string) ([]models.PartnerSettlementAccount, error)
+	SubmitAccount(actorID, partnerID string, req *models.SubmitSettlementAccountRequest) (*models.PartnerSettlementAccount, error)
+	CancelAccount(actorID, partnerID, accountID string) error
+	GetPendingAccounts(actorID string) ([]models.PartnerSettlementAccount, error)
+	ApproveAccount(actorID, accountID string, note *string) (*models.PartnerSettlementAccount, error)
+	RejectAccount(actorID, accountID string, note *string) (*models.PartnerSettlementAccount, error)
+	GetActiveAccount(partnerID string) (*models.PartnerSettlementAccount, error)
+}
+
+func NewPartnerSettlementService(repo repository.IPartnerSettlementRepository, partnerStaffService IPartnerStaffService) IPartnerSettlementService {
+	return &PartnerSettlementService{
+		repo:                repo,
+		partnerStaffService: partnerStaffService,
+	}
+}
+
+func validateSettlementAccount(req *models.SubmitSettlementAccountRequest) error {
+	req.AccountNumber = strings.TrimSpace(req.AccountNumber)
+	req.AccountName = strings.ToUpper(strings.TrimSpace(req.AccountName))
+	if req.AccountName == "" {
+		return fmt.Errorf("invalid account_name")
+	}
+
+	switch req.AccountType {
+	case models.SettlementAccountBank:
+		if req.BankCode == nil || strings.TrimSpace(*req.BankCode) == "" {
+			return fmt.Errorf("invalid settlement account: bank_code is required for bank accounts")
+		}
+		if !bankAccountNumberRegex.MatchString(req.AccountNumber) {
+			return fmt.Errorf("invalid account_number: bank account numbers must be 6-20 digits")
+		}
+		req.WalletProvider = nil
+	case models.SettlementAccountEWallet:
+		if req.WalletProvider == nil || strings.TrimSpace(*req.WalletProvider) == "" {
+			return fmt.Errorf("invalid settlement account: wallet_provider is required for e-wallets")
+		}
+		if !walletAccountNumberRegex.MatchString(req.AccountNumber) {
+			return fmt.Errorf("invalid account_number: %s", req.AccountNumber)
+		}
+		req.BankCode, req.BranchName = nil, nil
+	default:
+		return fmt.Errorf("invalid account_type: %s", req.AccountType)
+	}
+	return nil
+}
+
+func (s *PartnerSettlementService) GetAccounts(actorID, partnerID string) ([]models.PartnerSettlementAccount, error) {
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAccountsByPartnerID(partnerID)
+}
+
+// SubmitAccount files a settlement account change; it only takes effect once an admin approves it
+func (s *PartnerSettlementService) SubmitAccount(actorID, partnerID string, req *models.SubmitSettlementAccountRequest) (*models.PartnerSettlementAccount, error) {
+	if err := validateSettlementAccount(req); err != nil {
+		return nil, err
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	account := &models.PartnerSettlementAccount{
+		PartnerID:      uuid.MustParse(partnerID),
+		AccountType:    req.AccountType,
+		BankCode:       req.BankCode,
+		BranchName:     req.BranchName,
+		WalletProvider: req.WalletProvider,
+		AccountNumber:  req.AccountNumber,
+		AccountName:    req.AccountName,
+		ChangeReason:   req.ChangeReason,
+		RequestedBy:    actorID,
+	}
+	if err := s.repo.CreateAccount(account); err != nil {
+		if strings.Contains(err.Error(), "idx_settlement_accounts_one_pending") {
+			return nil, fmt.Errorf("duplicate: a settlement account change is already pending approval")
+		}
+		return nil, err
+	}
+	slog.Info("settlement account change submitted", "partner_id", partnerID, "account_id", account.AccountID, "requested_by", actorID)
+	return account, nil
+}
+
+func (s *PartnerSettlementService) CancelAccount(actorID, partnerID, accountID string) error {
+	if _, err := uuid.Parse(accountID); err != nil {
+		return fmt.Errorf("invalid account_id: %s", accountID)
+	}
+	if err := s.partnerStaffService.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+	return s.repo.CancelAccount(partnerID, accountID)
+}
+
+func (s *PartnerSettlementService) GetPendingAccounts(actorID string) ([]models.PartnerSettlementAccount, error) {
+	if err := s.partnerStaffService.AuthorizeAdmin(actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPendingAccounts()
+}
+
+func (s *PartnerSettlementService) ApproveAccount(actorID, accountID string, note *string) (*models.PartnerSettlementAccount, error) {
+	if _, err := uuid.Parse(accountID); err != nil {
+		return nil, fmt.Errorf("invalid account_id: %s", accountID)
+	}
+	if err := s.partnerStaffService.AuthorizeAdmin(actorID); err != nil {
+		return nil, err
+	}
+	account, err := s.repo.GetAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.RequestedBy == actorID {
+		return nil, fmt.Errorf("forbidden: a settlement account change cannot be approved by its requester")
+	}
+
+	if err := s.repo.ApproveAccount(accountID, actorID, note); err != nil {
+		return nil, err
+	}
+	slog.Info("settlement account approved", "partner_id", account.PartnerID, "account_id", accountID, "approved_by", actorID)
+	return s.repo.GetAccountByID(accountID)
+}
+
+func (s *PartnerSettlementService) RejectAccount(actorID, accountID string, note *string) (*models.PartnerSettlementAccount, error) {
+	if _, err := uuid.Parse(accountID); err != nil {
+		return nil, fmt.Errorf("invalid account_id: %s", accountID)
+	}
+	if note == nil || strings.TrimSpace(*note) == "" {
+		return nil, fmt.Errorf("invalid review_note: a reason is required to reject a settlement account")
+	}
+	if err := s.partnerStaffService.AuthorizeAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RejectAccount(accountID, actorID, note); err != nil {
+		return nil, err
+	}
+	slog.Info("settlement account rejected", "account_id", accountID, "rejected_by", actorID)
+	return s.repo.GetAccountByID(accountID)
+}
+
+// GetActiveAccount returns the approved account billing and claim disbursement pay to
+func (s *PartnerSettlementService) GetActiveAccount(partnerID string) (*models.PartnerSettlementAccount, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	return s.repo.GetActiveAccount(partnerID)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"profile-service/internal/models"
+	"profile-service/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type PartnerStaffService struct {
+	repo                  repository.IPartnerStaffRepository
+	partnerRepository     repository.IInsurancePartnerRepository
+	userProfileRepository repository.IUserRepository
+}
+
+type IPartnerStaffService interface {
+	GetStaffMembers(actorID, partnerID, status string) ([]models.PartnerStaffMember, error)
+	AddStaffMember(actorID, partnerID string, req *models.AddPartnerStaffRequest) (*models.PartnerStaffMember, error)
+	UpdateStaffRole(actorID, partnerID, userID string, staffRole models.StaffRole) (*models.PartnerStaffMember, error)
+	DeactivateStaffMember(actorID, partnerID, userID, reason string) error
+	CheckMembership(partnerID, userID string) (*models.PartnerMembershipResponse, error)
+	AuthorizeManager(actorID, partnerID string) error
+	AuthorizeAdmin(actorID string) error
+}
+
+func NewPartnerStaffService(repo repository.IPartnerStaffRepository, partnerRepository repository.IInsurancePartnerRepository, userProfileRepository repository.IUserRepository) IPartnerStaffService {
+	return &PartnerStaffService{
+		repo:                  repo,
+		partnerRepository:     partnerRepository,
+		userProfileRepository: userProfileRepository,
+	}
+}
+
+func (s *PartnerStaffService) validatePartnerID(partnerID string) error {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	if _, err := s.partnerRepository.GetPrivateProfile(partnerID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// canManage reports whether the actor may manage the staff of a partner: system admins,
+// active partner admins of that partner, and partner accounts linked before staff
+// memberships existed
+func (s *PartnerStaffService) canManage(actorID, partnerID string) (bool, error) {
+	actor, err := s.userProfileRepository.GetUserProfileByUserID(actorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if actor.RoleID == models.AuthRoleAdmin {
+		return true, nil
+	}
+
+	member, err := s.repo.GetMember(partnerID, actorID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	if member != nil {
+		return member.Status == models.StaffStatusActive && member.StaffRole == models.StaffRolePartnerAdmin, nil
+	}
+
+	return actor.RoleID == models.AuthRoleAdminPartner && actor.PartnerID != nil && actor.PartnerID.String() == partnerID, nil
+}
+
+// AuthorizeManager checks that the partner exists and that the actor may manage it
+func (s *PartnerStaffService) AuthorizeManager(actorID, partnerID string) error {
+	if actorID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+	if err := s.validatePartnerID(partnerID); err != nil {
+		return err
+	}
+	allowed, err := s.canManage(actorID, partnerID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("forbidden: user is not allowed to manage this insurance partner")
+	}
+	return nil
+}
+
+// AuthorizeAdmin checks that the actor is a system admin
+func (s *PartnerStaffService) AuthorizeAdmin(actorID string) error {
+	if actorID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+	actor, err := s.userProfileRepository.GetUserProfileByUserID(actorID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if actor == nil || actor.RoleID != models.AuthRoleAdmin {
+		return fmt.Errorf("forbidden: admin role is required")
+	}
+	return nil
+}
+
+func (s *PartnerStaffService) GetStaffMembers(actorID, partnerID, status string) ([]models.PartnerStaffMember, error) {
+	if err := s.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case "":
+		status = string(models.StaffStatusActive)
+	case string(models.StaffStatusActive), string(models.StaffStatusDeactivated), "all":
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	return s.repo.GetMembersByPartnerID(partnerID, status)
+}
+
+func (s *PartnerStaffService) AddStaffMember(actorID, partnerID string, req *models.AddPartnerStaffRequest) (*models.PartnerStaffMember, error) {
+	if !req.StaffRole.IsValid() {
+		return nil, fmt.Errorf("invalid staff role: %s", req.StaffRole)
+	}
+	if err := s.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+
+	target, err := s.userProfileRepository.GetUserProfileByUserID(req.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user profile %s: %w", req.UserID, err)
+		}
+		return nil, err
+	}
+	if target.PartnerID != nil && target.PartnerID.String() != partnerID {
+		return nil, fmt.Errorf("duplicate: user already belongs to another insurance partner")
+	}
+	if existing, err := s.repo.GetActiveMembershipByUserID(req.UserID); err == nil && existing.PartnerID.String() != partnerID {
+		return nil, fmt.Errorf("duplicate: user already belongs to another insurance partner")
+	}
+
+	member := &models.PartnerStaffMember{
+		PartnerID: uuid.MustParse(partnerID),
+		UserID:    req.UserID,
+		StaffRole: req.StaffRole,
+		AddedBy:   &actorID,
+	}
+	if err := s.repo.AddMember(member); err != nil {
+		return nil, err
+	}
+	slog.Info("partner staff member added", "partner_id", partnerID, "user_id", req.UserID, "staff_role", req.StaffRole, "added_by", actorID)
+
+	return s.repo.GetMember(partnerID, req.UserID)
+}
+
+func (s *PartnerStaffService) UpdateStaffRole(actorID, partnerID, userID string, staffRole models.StaffRole) (*models.PartnerStaffMember, error) {
+	if !staffRole.IsValid() {
+		return nil, fmt.Errorf("invalid staff role: %s", staffRole)
+	}
+	if err := s.AuthorizeManager(actorID, partnerID); err != nil {
+		return nil, err
+	}
+	if actorID == userID && staffRole != models.StaffRolePartnerAdmin {
+		return nil, fmt.Errorf("forbidden: partner admins cannot demote themselves")
+	}
+
+	if err := s.repo.UpdateStaffRole(partnerID, userID, staffRole); err != nil {
+		return nil, err
+	}
+	slog.Info("partner staff role updated", "partner_id", partnerID, "user_id", userID, "staff_role", staffRole, "updated_by", actorID)
+
+	return s.repo.GetMember(partnerID, userID)
+}
+
+func (s *PartnerStaffService) DeactivateStaffMember(actorID, partnerID, userID, reason string) error {
+	if err := s.AuthorizeManager(actorID, partnerID); err != nil {
+		return err
+	}
+	if actorID == userID {
+		return fmt.Errorf("forbidden: partner admins cannot deactivate themselves")
+	}
+
+	if err := s.repo.DeactivateMember(partnerID, userID, actorID, strings.TrimSpace(reason)); err != nil {
+		return err
+	}
+	slog.Info("partner staff member deactivated", "partner_id", partnerID, "user_id", userID, "deactivated_by", actorID)
+	return nil
+}
+
+// CheckMembership answers whether a user currently acts for a partner. Users linked to a
+// partner through their profile before staff memberships existed count as members.
+func (s *PartnerStaffService) CheckMembership(partnerID, userID string) (*models.PartnerMembershipResponse, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, fmt.Errorf("invalid partner_id: %s", partnerID)
+	}
+	response := &models.PartnerMembershipResponse{
+		PartnerID: partnerID,
+		UserID:    userID,
+	}
+
+	member, err := s.repo.GetMember(partnerID, userID)
+	if err == nil {
+		if member.Status == models.StaffStatusActive {
+			response.IsMember = true
+			response.StaffRole = member.StaffRole
+		}
+		return response, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	profile, err := s.userProfileRepository.GetUserProfileByUserID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return response, nil
+		}
+		return nil, err
+	}
+	if profile.PartnerID != nil && profile.PartnerID.String() == partnerID {
+		response.IsMember = true
+		if profile.RoleID == models.AuthRoleAdminPartner {
+			response.StaffRole = models.StaffRolePartnerAdmin
+		} else {
+			response.StaffRole = models.StaffRoleViewer
+		}
+	}
+	return response, nil
+}
@@ -1,63 +1,33 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
-	"path/filepath"
 	"time"
+	"utils/logging"
+	"utils/shutdown"
+	"weather-service/internal/cache"
 	"weather-service/internal/config"
+	"weather-service/internal/database/postgres"
+	"weather-service/internal/event"
+	"weather-service/internal/grpcserver"
 	"weather-service/internal/handlers"
+	"weather-service/internal/provider"
+	"weather-service/internal/quota"
+	"weather-service/internal/repository"
 	"weather-service/internal/services"
 
+	"agrisa/migration"
+
 	"github.com/gin-gonic/gin"
 )
 
-func setupLogging() (*os.File, error) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic: %v\n", r)
-		}
-	}()
-
-	logDir := filepath.Join("/agrisa", "log", "weather_service")
-	fmt.Println("Log directory:", logDir)
-	err := os.MkdirAll(logDir, 0755)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %v", err)
-	}
-
-	currentTime := time.Now()
-	logFileName := fmt.Sprintf("log_%s.log", currentTime.Format("2006-01-02"))
-	logFile := filepath.Join(logDir, logFileName)
-
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
-	}
-
-	if _, err := os.Stat(logFile); err == nil {
-		absPath, err := filepath.Abs(logFile)
-		if err != nil {
-			fmt.Printf("Failed to get absolute path of log file: %v\n", err)
-		} else {
-			fmt.Printf("Log file exists at absolute path: %s\n", absPath)
-		}
-	} else if os.IsNotExist(err) {
-		fmt.Println("Log file does not exist (it will be created)")
-	} else {
-		fmt.Printf("Error checking log file existence: %v\n", err)
-	}
-
-	log.SetOutput(file)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-
-	return file, nil
-}
-
 func main() {
 	// Setup logging
-	logFile, err := setupLogging()
+	logFile, err := logging.Setup("weather_service")
 	if err != nil {
 		log.Fatalf("Error setting up logging: %v", err)
 	}
@@ -72,16 +42,172 @@ func main() {
 		serverPort = "8086"
 	}
 
+	coordinator := shutdown.New()
+
 	r := gin.Default()
+	r.Use(logging.GinMiddleware())
 	// Initialize and register routes
 	// Initialize services and handlers here
-	weatherService := services.NewWeatherService(*config)
-	agroService := services.NewAgroService(*config)
-	weatherHandler := handlers.NewWeatherHandler(weatherService, agroService)
+	// Responses are served uncached when Redis is unreachable
+	var weatherCache *cache.WeatherCache
+	redisClient, err := cache.NewRedisClient(config.RedisHost, config.RedisPort, config.RedisPassword, config.RedisDB)
+	if err != nil {
+		log.Printf("Weather cache disabled: %v", err)
+	} else {
+		cache.SetTTLs(config.CacheTTLs)
+		weatherCache = cache.NewWeatherCache(redisClient)
+	}
+
+	// Provider budgets are shared through Redis and counted per replica without it
+	limiter := quota.NewLimiter(redisClient, map[string]quota.Budget{
+		quota.ProviderOneCall:     {PerMinute: config.OneCallMinuteQuota, PerDay: config.OneCallDailyQuota},
+		quota.ProviderAgro:        {PerMinute: config.AgroMinuteQuota, PerDay: config.AgroDailyQuota},
+		quota.ProviderStatistics:  {PerMinute: config.StatisticsMinuteQuota, PerDay: config.StatisticsDailyQuota},
+		quota.ProviderAirQuality:  {PerMinute: config.AirQualityMinuteQuota, PerDay: config.AirQualityDailyQuota},
+		quota.ProviderOpenMeteo:   {PerMinute: config.OpenMeteoMinuteQuota, PerDay: config.OpenMeteoDailyQuota},
+		quota.ProviderNationalMet: {PerMinute: config.NationalMetMinuteQuota, PerDay: config.NationalMetDailyQuota},
+	}, config.QuotaReservePercent)
+
+	// Observations are not persisted when the history database is unreachable
+	var observationRepository repository.IObservationRepository
+	var alertRepository repository.IAlertRepository
+	var stationRepository repository.IStationRepository
+	var farmLocationRepository repository.IFarmLocationRepository
+	var webhookRepository repository.IWebhookRepository
+	db, err := postgres.Connect(*config)
+	if errors.Is(err, migration.ErrSchemaOutdated) {
+		log.Fatalf("Refusing to start, run cmd/migrate first: %v", err)
+	}
+	if err != nil {
+		log.Printf("Weather history, alerts, stations, farm polling and webhooks disabled: %v", err)
+	} else {
+		observationRepository = repository.NewObservationRepository(db)
+		alertRepository = repository.NewAlertRepository(db)
+		stationRepository = repository.NewStationRepository(db)
+		farmLocationRepository = repository.NewFarmLocationRepository(db)
+		webhookRepository = repository.NewWebhookRepository(db)
+	}
+	qualityService := services.NewQualityService(observationRepository, stationRepository)
+	historyService := services.NewHistoryService(observationRepository, qualityService, *config)
+	coordinator.Go("retention-watcher", func(ctx context.Context) {
+		historyService.StartRetentionWatcher(ctx, time.Hour)
+	})
+
+	weatherService := services.NewWeatherService(*config, weatherCache, historyService, limiter)
+	agroService := services.NewAgroService(*config, weatherCache, historyService, qualityService, limiter)
+	forecastService := services.NewForecastService(weatherService, agroService)
+	weatherHandler := handlers.NewWeatherHandler(weatherService, agroService, weatherCache, historyService, forecastService, limiter)
 	weatherHandler.RegisterRoutes(r)
 
+	// Current conditions merged over the configured providers, in their priority order
+	var weatherProviders []provider.WeatherProvider
+	for _, name := range config.WeatherProviders {
+		switch {
+		case name == quota.ProviderOneCall && config.APIKey != "":
+			weatherProviders = append(weatherProviders, services.NewOneCallProvider(weatherService))
+		case name == quota.ProviderOpenMeteo:
+			weatherProviders = append(weatherProviders, provider.NewOpenMeteo(config.OpenMeteoBaseURL, limiter))
+		case name == quota.ProviderNationalMet && config.NationalMetURL != "":
+			weatherProviders = append(weatherProviders, provider.NewNationalMet(config.NationalMetURL, config.NationalMetAPIKey, limiter))
+		default:
+			log.Printf("Weather provider %s unknown or not configured, leaving it out", name)
+		}
+	}
+	mergedWeatherService := services.NewMergedWeatherService(provider.NewRegistry(weatherProviders...), historyService, config.WeatherMergeStrategy)
+	providerHandler := handlers.NewProviderHandler(mergedWeatherService)
+	providerHandler.RegisterRoutes(r)
+
+	// Severe weather alerts are not evaluated and polled readings are not pushed while RabbitMQ is unreachable
+	var eventPublisher *event.Publisher
+	rabbitConn, err := event.ConnectRabbitMQ(*config)
+	if err != nil {
+		log.Printf("Weather alert notifications and reading events disabled: %v", err)
+	} else {
+		eventPublisher = event.NewPublisher(rabbitConn)
+	}
+	// Official warnings, such as typhoon warnings, are polled from the national met service when its feed is configured
+	var warningFeed services.WarningFeed
+	if config.NationalMetWarningsURL != "" {
+		warningFeed = provider.NewNationalMetWarnings(config.NationalMetWarningsURL, config.NationalMetAPIKey)
+	}
+	alertService := services.NewAlertService(alertRepository, forecastService, eventPublisher, warningFeed)
+	coordinator.Go("alert-watcher", func(ctx context.Context) {
+		alertService.StartAlertWatcher(ctx, time.Hour)
+	})
+	coordinator.Go("warning-watcher", func(ctx context.Context) {
+		alertService.StartWarningWatcher(ctx, 15*time.Minute)
+	})
+	alertHandler := handlers.NewAlertHandler(alertService)
+	alertHandler.RegisterRoutes(r)
+
+	webhookService := services.NewWebhookService(webhookRepository)
+	coordinator.Go("webhook-delivery-worker", func(ctx context.Context) {
+		webhookService.StartDeliveryWorker(ctx, time.Minute)
+	})
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	webhookHandler.RegisterRoutes(r)
+
+	pollingService := services.NewPollingService(farmLocationRepository, weatherService, agroService, forecastService, qualityService, webhookService, eventPublisher, limiter, *config)
+	coordinator.Go("polling-worker", func(ctx context.Context) {
+		pollingService.StartPollingWorker(ctx, 15*time.Minute)
+	})
+
+	stationService := services.NewStationService(stationRepository)
+	stationHandler := handlers.NewStationHandler(stationService)
+	stationHandler.RegisterRoutes(r)
+
+	droughtService := services.NewDroughtService(observationRepository, agroService)
+	droughtHandler := handlers.NewDroughtHandler(droughtService)
+	droughtHandler.RegisterRoutes(r)
+
+	agronomyService := services.NewAgronomyService(observationRepository, agroService)
+	agronomyHandler := handlers.NewAgronomyHandler(agronomyService)
+	agronomyHandler.RegisterRoutes(r)
+
+	vegetationService := services.NewVegetationService(*config, agroService, limiter)
+	vegetationHandler := handlers.NewVegetationHandler(vegetationService)
+	vegetationHandler.RegisterRoutes(r)
+
+	backfillService := services.NewBackfillService(observationRepository, agroService, qualityService, limiter, *config)
+	backfillHandler := handlers.NewBackfillHandler(backfillService)
+	backfillHandler.RegisterRoutes(r)
+
+	climatologyService := services.NewClimatologyService(observationRepository, agroService, weatherCache, limiter, *config)
+	climatologyHandler := handlers.NewClimatologyHandler(climatologyService)
+	climatologyHandler.RegisterRoutes(r)
+
+	airQualityService := services.NewAirQualityService(agroService, limiter, *config)
+	airQualityHandler := handlers.NewAirQualityHandler(airQualityService)
+	airQualityHandler.RegisterRoutes(r)
+
+	// Policy-service reads weather data over gRPC; the HTTP API keeps serving if it cannot start
+	grpcServer := grpcserver.NewServer(grpcserver.NewWeatherDataServer(historyService, forecastService))
+	go func() {
+		if err := grpcserver.Serve(config.GRPCPort, grpcServer); err != nil {
+			log.Printf("Weather data gRPC server stopped: %v", err)
+		}
+	}()
+	coordinator.Server("grpc", func(ctx context.Context) error {
+		return grpcserver.Shutdown(ctx, grpcServer)
+	})
+
 	log.Printf("Starting weather-service on port %s", serverPort)
-	if err := r.Run(":" + serverPort); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	coordinator.ListenAndServe("http", &http.Server{Addr: ":" + serverPort, Handler: r})
+
+	// Optional clients are closed when they connected
+	if db != nil {
+		coordinator.Closer("postgres", db.Close)
+	}
+	if redisClient != nil {
+		coordinator.Closer("redis", redisClient.Close)
+	}
+	if rabbitConn != nil {
+		coordinator.Closer("rabbitmq", rabbitConn.Close)
+	}
+
+	if err := coordinator.Wait(); err != nil {
+		log.Printf("weather-service stopped: %v", err)
+		logFile.Close()
+		os.Exit(1)
 	}
 }
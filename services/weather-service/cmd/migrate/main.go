@@ -0,0 +1,35 @@
+// Command migrate runs the schema migrations of weather-service, to roll back or inspect them, or to
+// apply them when migration on startup is turned off with DB_AUTO_MIGRATE=false.
+//
+//	migrate <up|up-by-one|up-to|down|down-to|redo|status|version> [version]
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+	"weather-service/internal/config"
+	"weather-service/internal/database/postgres"
+
+	"agrisa/migration"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: migrate <%s> [version]", strings.Join(migration.Commands, "|"))
+	}
+
+	cfg := config.New()
+	cfg.PostgresAutoMigrate = false
+	cfg.PostgresSkipSchemaCheck = true
+	db, err := postgres.Connect(*cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	err = postgres.RunMigration(db, os.Args[1], os.Args[2:]...)
+	db.Close()
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
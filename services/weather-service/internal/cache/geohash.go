@@ -0,0 +1,65 @@
+package cache
+
+import "strings"
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes a point as a geohash of precision characters. Nearby points share a prefix,
+// so a cell of the grid is addressed by one short string whatever the latitude.
+func Geohash(lat, lon float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	bit, ch, even := 0, 0, true
+	for hash.Len() < precision {
+		// Bits alternate between longitude and latitude, longitude first
+		value, interval := lat, &latRange
+		if even {
+			value, interval = lon, &lonRange
+		}
+		mid := (interval[0] + interval[1]) / 2
+		ch <<= 1
+		if value >= mid {
+			ch |= 1
+			interval[0] = mid
+		} else {
+			interval[1] = mid
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			hash.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return hash.String()
+}
+
+// GeohashCenter decodes a geohash to the center of its cell
+func GeohashCenter(hash string) (lat, lon float64) {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+
+	even := true
+	for i := 0; i < len(hash); i++ {
+		ch := strings.IndexByte(geohashAlphabet, hash[i])
+		if ch < 0 {
+			break
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			interval := &latRange
+			if even {
+				interval = &lonRange
+			}
+			mid := (interval[0] + interval[1]) / 2
+			if ch&mask != 0 {
+				interval[0] = mid
+			} else {
+				interval[1] = mid
+			}
+			even = !even
+		}
+	}
+	return (latRange[0] + latRange[1]) / 2, (lonRange[0] + lonRange[1]) / 2
+}
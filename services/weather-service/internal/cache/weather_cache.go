@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cached parameters, one per kind of upstream lookup
+const (
+	ParamOneCall  = "onecall"
+	ParamCurrent  = "current"
+	ParamForecast = "forecast"
+	ParamPolygon  = "polygon"
+	ParamClimate  = "climate"
+)
+
+const keyPrefix = "weather-cache"
+
+// How long one caller holds the refresh of an entry being revalidated
+const revalidateLockTTL = 30 * time.Second
+
+// Policy says how long a parameter stays fresh and the geohash precision its points are keyed
+// by. Entries expire at the end of their time bucket, so callers in the same bucket share a key.
+// The latest entry of a key is also kept as a stale copy for StaleTTL, served when a provider
+// budget is nearly spent, and served for Revalidate past its bucket while it is refreshed.
+type Policy struct {
+	TTL        time.Duration
+	Precision  int
+	StaleTTL   time.Duration
+	Revalidate time.Duration
+}
+
+var policies = map[string]Policy{
+	// ~1.2 x 0.6 km cells: finer than the resolution of the upstream models
+	ParamOneCall:  {TTL: 10 * time.Minute, Precision: 6, StaleTTL: 6 * time.Hour, Revalidate: 10 * time.Minute},
+	ParamCurrent:  {TTL: 10 * time.Minute, Precision: 6, StaleTTL: 6 * time.Hour, Revalidate: 10 * time.Minute},
+	ParamForecast: {TTL: time.Hour, Precision: 6, StaleTTL: 12 * time.Hour, Revalidate: time.Hour},
+	// Polygons are farm boundaries, so they keep ~5 m cells
+	ParamPolygon: {TTL: 24 * time.Hour, Precision: 9, StaleTTL: 7 * 24 * time.Hour},
+	// Climatological normals are long-term statistics on ~5 km cells and hardly change
+	ParamClimate: {TTL: 30 * 24 * time.Hour, Precision: 5, StaleTTL: 365 * 24 * time.Hour},
+}
+
+// SetTTLs overrides the TTL of the named parameters, ignoring unknown names and non-positive
+// durations. It is meant for startup, before the cache is used.
+func SetTTLs(ttls map[string]time.Duration) {
+	for param, ttl := range ttls {
+		policy, ok := policies[param]
+		if !ok || ttl <= 0 {
+			log.Printf("Ignoring cache TTL %s for %q", ttl, param)
+			continue
+		}
+		// A window shorter than the TTL would leave most of a bucket without revalidation
+		if policy.Revalidate > 0 {
+			policy.Revalidate = ttl
+		}
+		policy.TTL = ttl
+		policies[param] = policy
+	}
+}
+
+// staleEntry is the stale copy of an entry, with when it was stored
+type staleEntry struct {
+	StoredAt int64           `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+type counters struct {
+	hits          atomic.Int64
+	misses        atomic.Int64
+	errors        atomic.Int64
+	staleHits     atomic.Int64
+	revalidations atomic.Int64
+}
+
+// Metrics are the cache counters of one parameter since the service started
+type Metrics struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Errors  int64   `json:"errors"`
+	HitRate float64 `json:"hit_rate"`
+	// Stale entries served in place of an upstream call
+	StaleHits int64 `json:"stale_hits"`
+	// Stale entries served while they were refreshed in the background
+	Revalidations int64 `json:"revalidations"`
+}
+
+// WeatherCache stores upstream weather responses in Redis. A nil *WeatherCache is valid and
+// caches nothing, so the service keeps working when Redis is unavailable.
+type WeatherCache struct {
+	client   *redis.Client
+	counters map[string]*counters
+}
+
+func NewWeatherCache(client *redis.Client) *WeatherCache {
+	c := &WeatherCache{
+		client:   client,
+		counters: make(map[string]*counters, len(policies)),
+	}
+	for param := range policies {
+		c.counters[param] = &counters{}
+	}
+	return c
+}
+
+// SnapPoint moves a point to the center of its geohash cell at the precision of the parameter,
+// so the points sharing a key are looked up at one place
+func SnapPoint(param string, lat, lon float64) (string, string) {
+	lat, lon = GeohashCenter(Geohash(lat, lon, policies[param].Precision))
+	return strconv.FormatFloat(lat, 'f', 6, 64), strconv.FormatFloat(lon, 'f', 6, 64)
+}
+
+// CoordinateKey builds the key of a lookup by one or more [lon, lat] points in the current
+// time bucket, each point keyed by its geohash. Variant holds any other request option that
+// changes the response.
+func CoordinateKey(param string, coordinates [][2]float64, variant ...string) string {
+	points := make([]string, len(coordinates))
+	for i, coord := range coordinates {
+		points[i] = Geohash(coord[1], coord[0], policies[param].Precision)
+	}
+	return Key(param, strings.Join(points, ";"), variant...)
+}
+
+// Key builds the key of a lookup by location (geohashes or a polygon ID) in the current time
+// bucket
+func Key(param, location string, variant ...string) string {
+	parts := []string{keyPrefix, param, location, strconv.FormatInt(bucket(param, time.Now()), 10)}
+	parts = append(parts, variant...)
+	return strings.Join(parts, ":")
+}
+
+// staleKey is the key of the stale copy of an entry: the entry key without its time bucket
+func staleKey(key string) string {
+	parts := strings.Split(key, ":")
+	if len(parts) < 4 {
+		return key
+	}
+	stale := []string{keyPrefix, "stale", parts[1], parts[2]}
+	return strings.Join(append(stale, parts[4:]...), ":")
+}
+
+// revalidateKey is the key of the lock held by the caller refreshing an entry
+func revalidateKey(key string) string {
+	return keyPrefix + ":revalidate:" + strings.TrimPrefix(staleKey(key), keyPrefix+":stale:")
+}
+
+func bucket(param string, now time.Time) int64 {
+	return now.Unix() / int64(policies[param].TTL.Seconds())
+}
+
+// remainingTTL is the time left until the current bucket of the parameter ends
+func remainingTTL(param string, now time.Time) time.Duration {
+	ttl := int64(policies[param].TTL.Seconds())
+	end := (bucket(param, now) + 1) * ttl
+	return time.Duration(end-now.Unix()) * time.Second
+}
+
+// Get loads the cached value of key into dest and reports whether it was found
+func (c *WeatherCache) Get(ctx context.Context, param, key string, dest any) bool {
+	if c == nil {
+		return false
+	}
+	counter := c.counters[param]
+
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			counter.errors.Add(1)
+			log.Printf("Weather cache read failed for %s: %v", key, err)
+		}
+		counter.misses.Add(1)
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		counter.errors.Add(1)
+		counter.misses.Add(1)
+		log.Printf("Weather cache entry %s is unreadable: %v", key, err)
+		return false
+	}
+	counter.hits.Add(1)
+	return true
+}
+
+// Set caches value under key until the end of the current time bucket
+func (c *WeatherCache) Set(ctx context.Context, param, key string, value any) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.counters[param].errors.Add(1)
+		log.Printf("Weather cache entry %s cannot be encoded: %v", key, err)
+		return
+	}
+	if err := c.client.Set(ctx, key, data, remainingTTL(param, time.Now())).Err(); err != nil {
+		c.counters[param].errors.Add(1)
+		log.Printf("Weather cache write failed for %s: %v", key, err)
+	}
+	if staleTTL := policies[param].StaleTTL; staleTTL > 0 {
+		stale, _ := json.Marshal(staleEntry{StoredAt: time.Now().Unix(), Value: data})
+		if err := c.client.Set(ctx, staleKey(key), stale, staleTTL).Err(); err != nil {
+			c.counters[param].errors.Add(1)
+			log.Printf("Weather cache write failed for stale copy of %s: %v", key, err)
+		}
+	}
+}
+
+// GetStale loads the latest cached value of key into dest, even when its time bucket has
+// ended, and reports whether it was found
+func (c *WeatherCache) GetStale(ctx context.Context, param, key string, dest any) bool {
+	if c == nil || key == "" {
+		return false
+	}
+	if _, ok := c.readStale(ctx, param, key, dest); !ok {
+		return false
+	}
+	c.counters[param].staleHits.Add(1)
+	return true
+}
+
+// GetRevalidating loads the stale copy of key into dest when its time bucket ended less than the
+// revalidate window of the parameter ago, and reports whether it was found. Refresh is true for
+// the one caller that should fetch the entry again in the background.
+func (c *WeatherCache) GetRevalidating(ctx context.Context, param, key string, dest any) (found, refresh bool) {
+	policy := policies[param]
+	if c == nil || key == "" || policy.Revalidate <= 0 {
+		return false, false
+	}
+	storedAt, ok := c.readStale(ctx, param, key, dest)
+	if !ok {
+		return false, false
+	}
+	expiredAt := storedAt.Add(remainingTTL(param, storedAt))
+	if time.Since(expiredAt) > policy.Revalidate {
+		return false, false
+	}
+	c.counters[param].revalidations.Add(1)
+
+	refresh, err := c.client.SetNX(ctx, revalidateKey(key), 1, revalidateLockTTL).Result()
+	if err != nil {
+		c.counters[param].errors.Add(1)
+		log.Printf("Weather cache revalidation lock failed for %s: %v", key, err)
+	}
+	return true, refresh
+}
+
+// readStale loads the stale copy of key into dest and returns when it was stored
+func (c *WeatherCache) readStale(ctx context.Context, param, key string, dest any) (time.Time, bool) {
+	data, err := c.client.Get(ctx, staleKey(key)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.counters[param].errors.Add(1)
+			log.Printf("Weather cache read failed for stale copy of %s: %v", key, err)
+		}
+		return time.Time{}, false
+	}
+	var entry staleEntry
+	if err := json.Unmarshal(data, &entry); err == nil {
+		err = json.Unmarshal(entry.Value, dest)
+	}
+	if err != nil {
+		c.counters[param].errors.Add(1)
+		log.Printf("Weather cache stale copy of %s is unreadable: %v", key, err)
+		return time.Time{}, false
+	}
+	return time.Unix(entry.StoredAt, 0), true
+}
+
+// Metrics returns the counters of every cached parameter
+func (c *WeatherCache) Metrics() map[string]Metrics {
+	result := make(map[string]Metrics, len(policies))
+	for param := range policies {
+		var m Metrics
+		if c != nil {
+			counter := c.counters[param]
+			m = Metrics{
+				Hits:          counter.hits.Load(),
+				Misses:        counter.misses.Load(),
+				Errors:        counter.errors.Load(),
+				StaleHits:     counter.staleHits.Load(),
+				Revalidations: counter.revalidations.Load(),
+			}
+		}
+		if total := m.Hits + m.Misses; total > 0 {
+			m.HitRate = float64(m.Hits) / float64(total)
+		}
+		result[param] = m
+	}
+	return result
+}
+
+// NewRedisClient connects to Redis, failing when it does not answer a ping
+func NewRedisClient(host, port, password string, db int) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", host, port),
+		Password: password,
+		DB:       db,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return client, nil
+}
@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type WeatherServiceConfig struct {
 	APIKey               string
@@ -8,15 +13,114 @@ type WeatherServiceConfig struct {
 	XweatherClientSecret string
 	AgroAPIKey           string
 	AgroAPIBaseURL       string
+	RedisHost            string
+	RedisPort            string
+	RedisPassword        string
+	RedisDB              int
+	PostgresHost         string
+	PostgresPort         string
+	PostgresUser         string
+	PostgresPassword     string
+	PostgresDB           string
+	PostgresAutoMigrate  bool
+	// Connect whatever the schema version, for cmd/migrate which manages the schema itself
+	PostgresSkipSchemaCheck bool
+	RabbitMQHost            string
+	RabbitMQPort            string
+	RabbitMQUser            string
+	RabbitMQPassword        string
+	// Providers the merged current conditions come from, in priority order. Providers without
+	// their settings are left out: One Call needs APIKey and the national met service its URL.
+	WeatherProviders []string
+	// How readings of several providers are merged, priority or median
+	WeatherMergeStrategy string
+	OpenMeteoBaseURL     string
+	NationalMetURL       string
+	NationalMetAPIKey    string
+	// Feed of the official severe weather warnings, such as typhoon warnings; empty to only take
+	// warnings posted to the ingest endpoint
+	NationalMetWarningsURL string
+	// Base URL the insured farm registry is synced from
+	PolicyServiceURL string
+	// Port of the gRPC weather data service
+	GRPCPort string
+	// Upstream call budgets per minute and per UTC day; zero is unlimited
+	OneCallMinuteQuota int
+	OneCallDailyQuota  int
+	AgroMinuteQuota    int
+	AgroDailyQuota     int
+	// Budget of the statistical weather API the climatological normals come from
+	StatisticsMinuteQuota int
+	StatisticsDailyQuota  int
+	// Budget of the air pollution API air quality comes from
+	AirQualityMinuteQuota int
+	AirQualityDailyQuota  int
+	// Budgets of the other current conditions providers
+	OpenMeteoMinuteQuota   int
+	OpenMeteoDailyQuota    int
+	NationalMetMinuteQuota int
+	NationalMetDailyQuota  int
+	// Share of a daily budget held back, during which cached data is preferred
+	QuotaReservePercent int
+	// TTLs overriding the cache defaults per cached parameter (onecall, current, forecast,
+	// polygon, climate)
+	CacheTTLs map[string]time.Duration
+	// Upstream calls a batch lookup runs at once
+	BatchConcurrency int
+	// Days stored observations and forecasts are kept before being purged
+	ObservationRetentionDays int
+	ForecastRetentionDays    int
+	// Past days a backfill request fetches at most, so one request stays short
+	BackfillDaysPerRequest int
 }
 
 func New() *WeatherServiceConfig {
 	return &WeatherServiceConfig{
-		APIKey:               getEnvOrDefault("WEATHER_API_KEY", ""),
-		XweatherClientID:     getEnvOrDefault("XWEATHER_CLIENT_ID", ""),
-		XweatherClientSecret: getEnvOrDefault("XWEATHER_CLIENT_SECRET", ""),
-		AgroAPIKey:           getEnvOrDefault("AGRO_API_KEY", ""),
-		AgroAPIBaseURL:       getEnvOrDefault("AGRO_API_BASE_URL", "http://api.agromonitoring.com/agro/1.0"),
+		APIKey:                   getEnvOrDefault("WEATHER_API_KEY", ""),
+		XweatherClientID:         getEnvOrDefault("XWEATHER_CLIENT_ID", ""),
+		XweatherClientSecret:     getEnvOrDefault("XWEATHER_CLIENT_SECRET", ""),
+		AgroAPIKey:               getEnvOrDefault("AGRO_API_KEY", ""),
+		AgroAPIBaseURL:           getEnvOrDefault("AGRO_API_BASE_URL", "http://api.agromonitoring.com/agro/1.0"),
+		RedisHost:                getEnvOrDefault("REDIS_HOST", "localhost"),
+		RedisPort:                getEnvOrDefault("REDIS_PORT", "6379"),
+		RedisPassword:            getEnvOrDefault("REDIS_PASSWORD", ""),
+		RedisDB:                  getEnvAsIntOrDefault("REDIS_DB", 0),
+		PostgresHost:             getEnvOrDefault("POSTGRES_HOST", "localhost"),
+		PostgresPort:             getEnvOrDefault("POSTGRES_PORT", "5432"),
+		PostgresUser:             getEnvOrDefault("POSTGRES_USER", "postgres"),
+		PostgresPassword:         getEnvOrDefault("POSTGRES_PASSWORD", ""),
+		PostgresDB:               getEnvOrDefault("POSTGRES_DB", "weather_service"),
+		PostgresAutoMigrate:      getEnvOrDefault("DB_AUTO_MIGRATE", "true") == "true",
+		RabbitMQHost:             getEnvOrDefault("RABBITMQ_HOST", "rabbitmq"),
+		RabbitMQPort:             getEnvOrDefault("RABBITMQ_PORT", "5672"),
+		RabbitMQUser:             getEnvOrDefault("RABBITMQ_USER", "admin"),
+		RabbitMQPassword:         getEnvOrDefault("RABBITMQ_PWD", "admin"),
+		WeatherProviders:         getEnvListOrDefault("WEATHER_PROVIDERS", "openweathermap_onecall,national_met,open_meteo"),
+		WeatherMergeStrategy:     getEnvOrDefault("WEATHER_MERGE_STRATEGY", "priority"),
+		OpenMeteoBaseURL:         getEnvOrDefault("OPEN_METEO_BASE_URL", "https://api.open-meteo.com/v1"),
+		NationalMetURL:           getEnvOrDefault("NATIONAL_MET_URL", ""),
+		NationalMetAPIKey:        getEnvOrDefault("NATIONAL_MET_API_KEY", ""),
+		NationalMetWarningsURL:   getEnvOrDefault("NATIONAL_MET_WARNINGS_URL", ""),
+		PolicyServiceURL:         getEnvOrDefault("POLICY_SERVICE_URL", "http://policy-service:8089"),
+		GRPCPort:                 getEnvOrDefault("GRPC_PORT", "9086"),
+		OneCallMinuteQuota:       getEnvAsIntOrDefault("WEATHER_ONECALL_MINUTE_QUOTA", 60),
+		OneCallDailyQuota:        getEnvAsIntOrDefault("WEATHER_ONECALL_DAILY_QUOTA", 1000),
+		AgroMinuteQuota:          getEnvAsIntOrDefault("AGRO_MINUTE_QUOTA", 60),
+		AgroDailyQuota:           getEnvAsIntOrDefault("AGRO_DAILY_QUOTA", 0),
+		StatisticsMinuteQuota:    getEnvAsIntOrDefault("WEATHER_STATISTICS_MINUTE_QUOTA", 60),
+		StatisticsDailyQuota:     getEnvAsIntOrDefault("WEATHER_STATISTICS_DAILY_QUOTA", 0),
+		AirQualityMinuteQuota:    getEnvAsIntOrDefault("WEATHER_AIR_QUALITY_MINUTE_QUOTA", 60),
+		AirQualityDailyQuota:     getEnvAsIntOrDefault("WEATHER_AIR_QUALITY_DAILY_QUOTA", 0),
+		OpenMeteoMinuteQuota:     getEnvAsIntOrDefault("OPEN_METEO_MINUTE_QUOTA", 600),
+		OpenMeteoDailyQuota:      getEnvAsIntOrDefault("OPEN_METEO_DAILY_QUOTA", 10000),
+		NationalMetMinuteQuota:   getEnvAsIntOrDefault("NATIONAL_MET_MINUTE_QUOTA", 60),
+		NationalMetDailyQuota:    getEnvAsIntOrDefault("NATIONAL_MET_DAILY_QUOTA", 0),
+		QuotaReservePercent:      getEnvAsIntOrDefault("WEATHER_QUOTA_RESERVE_PERCENT", 10),
+		CacheTTLs:                getEnvDurationsOrDefault("WEATHER_CACHE_TTLS", ""),
+		BatchConcurrency:         getEnvAsIntOrDefault("WEATHER_BATCH_CONCURRENCY", 8),
+		ObservationRetentionDays: getEnvAsIntOrDefault("WEATHER_OBSERVATION_RETENTION_DAYS", 730),
+		ForecastRetentionDays:    getEnvAsIntOrDefault("WEATHER_FORECAST_RETENTION_DAYS", 30),
+		BackfillDaysPerRequest:   getEnvAsIntOrDefault("WEATHER_BACKFILL_DAYS_PER_REQUEST", 30),
 	}
 }
 
@@ -26,3 +130,37 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvAsIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault reads a comma-separated list, dropping blank entries
+func getEnvListOrDefault(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnvOrDefault(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvDurationsOrDefault reads a comma-separated list of name=duration pairs such as
+// "onecall=5m,forecast=2h", dropping the pairs that do not parse
+func getEnvDurationsOrDefault(key, defaultValue string) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	for _, pair := range getEnvListOrDefault(key, defaultValue) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if duration, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			durations[strings.TrimSpace(name)] = duration
+		}
+	}
+	return durations
+}
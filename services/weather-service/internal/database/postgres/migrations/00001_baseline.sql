@@ -0,0 +1,3 @@
+-- Schema of the service before versioned migrations. The service had no database then, so the
+-- baseline is empty and every table comes from a later migration.
+-- +goose Up
@@ -0,0 +1,27 @@
+-- Weather observations fetched from upstream providers, kept as history for policy triggers.
+-- A reading is stored once per location, parameter and observation time; forecasts are kept
+-- apart from observed values and revised when a newer forecast arrives.
+-- +goose Up
+CREATE TABLE weather_observations (
+    id BIGSERIAL PRIMARY KEY,
+    -- Rounded "lon,lat" of a point lookup or "polygon:<id>" of a polygon lookup
+    location_key VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    polygon_id VARCHAR(64),
+    parameter VARCHAR(50) NOT NULL CHECK (parameter IN ('temperature', 'humidity', 'pressure', 'wind_speed', 'cloud_cover', 'precipitation')),
+    observed_at TIMESTAMPTZ NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    source VARCHAR(30) NOT NULL,
+    is_forecast BOOLEAN NOT NULL DEFAULT FALSE,
+    ingested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_weather_observation UNIQUE (location_key, parameter, observed_at, is_forecast)
+);
+
+CREATE INDEX idx_weather_observations_lookup ON weather_observations(location_key, parameter, observed_at DESC);
+CREATE INDEX idx_weather_observations_retention ON weather_observations(is_forecast, observed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS weather_observations;
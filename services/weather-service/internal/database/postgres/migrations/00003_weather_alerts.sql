@@ -0,0 +1,45 @@
+-- Severe weather alert subscriptions of a farm or region, located by polygon or point.
+-- Thresholds left NULL use the service defaults.
+-- +goose Up
+CREATE TABLE weather_alert_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    farm_id VARCHAR(255),
+    region VARCHAR(255),
+    polygon_id VARCHAR(64),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    event_types TEXT[] NOT NULL,
+    heavy_rain_mm DOUBLE PRECISION,
+    heatwave_celsius DOUBLE PRECISION,
+    typhoon_wind_ms DOUBLE PRECISION,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_alert_subscription_location CHECK (polygon_id IS NOT NULL OR (latitude IS NOT NULL AND longitude IS NOT NULL)),
+    CONSTRAINT chk_alert_subscription_event_types CHECK (event_types <@ ARRAY['heavy_rain', 'heatwave', 'typhoon']::TEXT[] AND cardinality(event_types) > 0)
+);
+
+CREATE INDEX idx_weather_alert_subscriptions_user ON weather_alert_subscriptions(user_id);
+CREATE INDEX idx_weather_alert_subscriptions_active ON weather_alert_subscriptions(is_active) WHERE is_active;
+
+-- Alerts raised for a subscription; one per event type and forecast day so a breach is only pushed once
+CREATE TABLE weather_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES weather_alert_subscriptions(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('heavy_rain', 'heatwave', 'typhoon')),
+    forecast_date DATE NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    provider VARCHAR(30) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_weather_alert UNIQUE (subscription_id, event_type, forecast_date)
+);
+
+CREATE INDEX idx_weather_alerts_subscription ON weather_alerts(subscription_id, forecast_date DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS weather_alerts;
+DROP TABLE IF EXISTS weather_alert_subscriptions;
@@ -0,0 +1,29 @@
+-- Weather stations that produce readings; farms are mapped to their nearest reliable station
+-- +goose Up
+CREATE TABLE weather_stations (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    operator VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    elevation_m DOUBLE PRECISION,
+    parameters TEXT[] NOT NULL,
+    -- Share of expected readings delivered and passing quality checks, from 0 to 1
+    reliability DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (reliability BETWEEN 0 AND 1),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_weather_station_parameters CHECK (parameters <@ ARRAY['temperature', 'humidity', 'pressure', 'wind_speed', 'cloud_cover', 'precipitation']::TEXT[])
+);
+
+CREATE INDEX idx_weather_stations_active ON weather_stations(is_active, latitude, longitude);
+
+-- Station that produced the reading, NULL for gridded provider data
+ALTER TABLE weather_observations
+    ADD COLUMN station_id BIGINT REFERENCES weather_stations(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE weather_observations DROP COLUMN IF EXISTS station_id;
+DROP TABLE IF EXISTS weather_stations;
@@ -0,0 +1,7 @@
+-- Confidence in the reading from 0 to 1, scored at ingest
+-- +goose Up
+ALTER TABLE weather_observations
+    ADD COLUMN confidence_score DOUBLE PRECISION CHECK (confidence_score BETWEEN 0 AND 1);
+
+-- +goose Down
+ALTER TABLE weather_observations DROP COLUMN IF EXISTS confidence_score;
@@ -0,0 +1,20 @@
+-- Insured farm locations polled on the provider update schedule, synced from policy-service.
+-- Farms that lose their last active policy are deactivated rather than deleted so the poll
+-- times survive a policy renewal.
+-- +goose Up
+CREATE TABLE farm_locations (
+    farm_id VARCHAR(64) PRIMARY KEY,
+    polygon_id VARCHAR(64),
+    province VARCHAR(255),
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_current_poll_at TIMESTAMPTZ,
+    last_forecast_poll_at TIMESTAMPTZ,
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_farm_locations_active ON farm_locations(is_active) WHERE is_active;
+
+-- +goose Down
+DROP TABLE IF EXISTS farm_locations;
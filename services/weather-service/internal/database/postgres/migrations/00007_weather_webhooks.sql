@@ -0,0 +1,52 @@
+-- Webhooks of external consumers, called when a current reading at their location meets their
+-- condition. The secret signs the payloads and is only shown when the webhook is created.
+-- +goose Up
+CREATE TABLE weather_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    -- Rounded "lon,lat" of a point or "polygon:<id>", matched against polled readings
+    location_key VARCHAR(255) NOT NULL,
+    polygon_id VARCHAR(64),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    parameter VARCHAR(50) NOT NULL CHECK (parameter IN ('temperature', 'humidity', 'pressure', 'wind_speed', 'cloud_cover', 'precipitation')),
+    operator VARCHAR(2) NOT NULL CHECK (operator IN ('<', '<=', '>', '>=')),
+    threshold DOUBLE PRECISION NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_weather_webhook_location CHECK (polygon_id IS NOT NULL OR (latitude IS NOT NULL AND longitude IS NOT NULL))
+);
+
+CREATE INDEX idx_weather_webhooks_owner ON weather_webhooks(owner_id);
+CREATE INDEX idx_weather_webhooks_location ON weather_webhooks(location_key) WHERE is_active;
+
+-- Webhook deliveries, one per webhook and reading so a met condition is delivered once.
+-- Failed deliveries are retried with backoff until they run out of attempts.
+CREATE TABLE weather_webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES weather_webhooks(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    observed_at TIMESTAMPTZ NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_status INT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+
+    CONSTRAINT uq_weather_webhook_delivery UNIQUE (webhook_id, observed_at)
+);
+
+CREATE INDEX idx_weather_webhook_deliveries_due ON weather_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_weather_webhook_deliveries_webhook ON weather_webhook_deliveries(webhook_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS weather_webhook_deliveries;
+DROP TABLE IF EXISTS weather_webhooks;
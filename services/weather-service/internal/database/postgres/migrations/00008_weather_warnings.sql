@@ -0,0 +1,56 @@
+-- Official severe weather warnings, such as typhoon warnings of the national met service, and the
+-- areas of interest they are matched against. A subscription may now cover a whole province or
+-- district instead of a point; such subscriptions only receive official warnings, as there is no
+-- point to forecast. Deliveries record which subscriptions a warning was pushed to, so it is
+-- pushed once per subscription however often it is ingested again.
+-- +goose Up
+ALTER TABLE weather_alert_subscriptions
+    ADD COLUMN province_code VARCHAR(20),
+    ADD COLUMN district_code VARCHAR(20),
+    DROP CONSTRAINT chk_alert_subscription_location,
+    ADD CONSTRAINT chk_alert_subscription_location CHECK (
+        polygon_id IS NOT NULL OR (latitude IS NOT NULL AND longitude IS NOT NULL)
+        OR province_code IS NOT NULL OR district_code IS NOT NULL);
+
+CREATE INDEX idx_weather_alert_subscriptions_province ON weather_alert_subscriptions(province_code) WHERE province_code IS NOT NULL;
+CREATE INDEX idx_weather_alert_subscriptions_district ON weather_alert_subscriptions(district_code) WHERE district_code IS NOT NULL;
+
+CREATE TABLE weather_warnings (
+    id BIGSERIAL PRIMARY KEY,
+    source VARCHAR(30) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('heavy_rain', 'heatwave', 'typhoon')),
+    severity VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    province_codes TEXT[] NOT NULL DEFAULT '{}',
+    district_codes TEXT[] NOT NULL DEFAULT '{}',
+    issued_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_weather_warning UNIQUE (source, external_id),
+    CONSTRAINT chk_weather_warning_area CHECK (cardinality(province_codes) > 0 OR cardinality(district_codes) > 0)
+);
+
+CREATE INDEX idx_weather_warnings_expires ON weather_warnings(expires_at DESC);
+
+CREATE TABLE weather_warning_deliveries (
+    warning_id BIGINT NOT NULL REFERENCES weather_warnings(id) ON DELETE CASCADE,
+    subscription_id BIGINT NOT NULL REFERENCES weather_alert_subscriptions(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (warning_id, subscription_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS weather_warning_deliveries;
+DROP TABLE IF EXISTS weather_warnings;
+DROP INDEX IF EXISTS idx_weather_alert_subscriptions_district;
+DROP INDEX IF EXISTS idx_weather_alert_subscriptions_province;
+DELETE FROM weather_alert_subscriptions WHERE polygon_id IS NULL AND (latitude IS NULL OR longitude IS NULL);
+ALTER TABLE weather_alert_subscriptions
+    DROP CONSTRAINT chk_alert_subscription_location,
+    ADD CONSTRAINT chk_alert_subscription_location CHECK (polygon_id IS NOT NULL OR (latitude IS NOT NULL AND longitude IS NOT NULL)),
+    DROP COLUMN district_code,
+    DROP COLUMN province_code;
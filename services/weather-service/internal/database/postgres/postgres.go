@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"fmt"
+	"weather-service/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// Connect opens the weather history database, checks that it answers and migrates its schema
+func Connect(cfg config.WeatherServiceConfig) (*sqlx.DB, error) {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.PostgresHost, cfg.PostgresPort, cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresDB)
+
+	db, err := sqlx.Connect("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Bring the schema up to date; with auto migration off, migrations are run with cmd/migrate
+	// and the service refuses to start until they are
+	switch {
+	case cfg.PostgresSkipSchemaCheck:
+	case cfg.PostgresAutoMigrate:
+		if err := RunMigration(db, "up"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	default:
+		if err := CheckMigration(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"utils/logging"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publisher publishes events to RabbitMQ queues
+type Publisher struct {
+	conn *RabbitMQConnection
+	// An AMQP channel is not safe for concurrent publishing
+	mu sync.Mutex
+}
+
+// NewPublisher creates a new event publisher
+func NewPublisher(conn *RabbitMQConnection) *Publisher {
+	return &Publisher{conn: conn}
+}
+
+// PublishNotification publishes a push notification event to the push_noti_events queue
+func (p *Publisher) PublishNotification(ctx context.Context, event NotificationEventPushModel) error {
+	if err := p.publish(ctx, PushNotiQueue, event); err != nil {
+		return fmt.Errorf("failed to publish notification event: %w", err)
+	}
+	log.Printf("Notification event published to %s: %s (%d users)", PushNotiQueue, event.Title, len(event.LstUserIds))
+	return nil
+}
+
+// PublishWeatherReadings publishes the readings polled for a farm to the weather_readings queue
+func (p *Publisher) PublishWeatherReadings(ctx context.Context, event WeatherReadingsEventModel) error {
+	if err := p.publish(ctx, WeatherReadingsQueue, event); err != nil {
+		return fmt.Errorf("failed to publish weather readings event: %w", err)
+	}
+	return nil
+}
+
+func (p *Publisher) publish(ctx context.Context, queue string, event any) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.conn.Channel.QueueDeclare(
+		queue, // queue name
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	return p.conn.Channel.PublishWithContext(
+		ctx,
+		"",    // exchange
+		queue, // routing key (queue name)
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         body,
+			Timestamp:    time.Now(),
+			Headers:      logging.MessageHeaders(ctx),
+		},
+	)
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"fmt"
+	"log"
+	"weather-service/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQConnection holds the RabbitMQ connection and channel
+type RabbitMQConnection struct {
+	Connection *amqp.Connection
+	Channel    *amqp.Channel
+}
+
+// ConnectRabbitMQ establishes a connection to RabbitMQ
+func ConnectRabbitMQ(cfg config.WeatherServiceConfig) (*RabbitMQConnection, error) {
+	connStr := fmt.Sprintf("amqp://%s:%s@%s:%s/",
+		cfg.RabbitMQUser,
+		cfg.RabbitMQPassword,
+		cfg.RabbitMQHost,
+		cfg.RabbitMQPort,
+	)
+
+	conn, err := amqp.Dial(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	log.Printf("Connected to RabbitMQ at %s:%s", cfg.RabbitMQHost, cfg.RabbitMQPort)
+
+	return &RabbitMQConnection{
+		Connection: conn,
+		Channel:    ch,
+	}, nil
+}
+
+// Close closes the RabbitMQ connection and channel
+func (r *RabbitMQConnection) Close() error {
+	if r.Channel != nil {
+		if err := r.Channel.Close(); err != nil {
+			log.Printf("Failed to close RabbitMQ channel: %v", err)
+		}
+	}
+	if r.Connection != nil {
+		if err := r.Connection.Close(); err != nil {
+			log.Printf("Failed to close RabbitMQ connection: %v", err)
+			return err
+		}
+	}
+	log.Println("RabbitMQ connection closed")
+	return nil
+}
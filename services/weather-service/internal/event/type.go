@@ -0,0 +1,37 @@
+package event
+
+import "agrisa/parameter"
+
+// PushNotiQueue is consumed by noti-service, which delivers push notifications to users
+const PushNotiQueue string = "push_noti_events"
+
+type NotificationEventPushModel struct {
+	LstUserIds []string       `json:"lstUserIds,omitempty"`
+	Title      string         `json:"title"`
+	Body       string         `json:"body"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// WeatherReadingsQueue carries the normalized readings polled for insured farms
+const WeatherReadingsQueue string = "weather_readings"
+
+// WeatherReading is one reading named and measured as the shared parameters of policy-service
+type WeatherReading struct {
+	Parameter       parameter.Name `json:"parameter"`
+	Value           float64        `json:"value"`
+	Unit            parameter.Unit `json:"unit"`
+	ObservedAt      int64          `json:"observed_at"` // Unix timestamp
+	Source          string         `json:"source"`
+	IsForecast      bool           `json:"is_forecast"`
+	ConfidenceScore *float64       `json:"confidence_score,omitempty"`
+}
+
+type WeatherReadingsEventModel struct {
+	FarmID    string           `json:"farm_id"`
+	PolygonID string           `json:"polygon_id,omitempty"`
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	Feed      string           `json:"feed"`
+	PolledAt  int64            `json:"polled_at"` // Unix timestamp
+	Readings  []WeatherReading `json:"readings"`
+}
@@ -0,0 +1,198 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"agrisa/weatherpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WeatherDataServer serves the weather history and forecasts over gRPC, with the same
+// services as the HTTP endpoints
+type WeatherDataServer struct {
+	weatherpb.UnimplementedWeatherDataServer
+	historyService  services.IHistoryService
+	forecastService services.IForecastService
+}
+
+func NewWeatherDataServer(historyService services.IHistoryService, forecastService services.IForecastService) *WeatherDataServer {
+	return &WeatherDataServer{historyService: historyService, forecastService: forecastService}
+}
+
+// NewServer returns a gRPC server exposing the weather data service
+func NewServer(server weatherpb.WeatherDataServer) *grpc.Server {
+	grpcServer := grpc.NewServer()
+	weatherpb.RegisterWeatherDataServer(grpcServer, server)
+	return grpcServer
+}
+
+// Serve listens on port and serves grpcServer until it is stopped or the listener fails
+func Serve(port string, grpcServer *grpc.Server) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", port, err)
+	}
+	log.Printf("Starting weather-service gRPC server on port %s", port)
+	return grpcServer.Serve(listener)
+}
+
+// Shutdown stops grpcServer once its calls in flight complete; calls still running when ctx
+// expires are cancelled
+func Shutdown(ctx context.Context, grpcServer *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// locationOf returns the polygon ID or the point of a location
+func locationOf(location *weatherpb.Location) (string, *float64, *float64, error) {
+	if polygonID := location.GetPolygonId(); polygonID != "" {
+		return polygonID, nil, nil, nil
+	}
+	if point := location.GetPoint(); point != nil {
+		if point.Lat < -90 || point.Lat > 90 || point.Lon < -180 || point.Lon > 180 {
+			return "", nil, nil, status.Error(codes.InvalidArgument, "invalid location: point out of range")
+		}
+		lat, lon := point.Lat, point.Lon
+		return "", &lat, &lon, nil
+	}
+	return "", nil, nil, status.Error(codes.InvalidArgument, "invalid location: either polygon_id or point is required")
+}
+
+// statusError maps a service error to a gRPC status, like respondError does for HTTP
+func statusError(err error) error {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "invalid") || strings.Contains(message, "unknown parameter"):
+		return status.Error(codes.InvalidArgument, message)
+	case strings.Contains(message, "not configured"):
+		return status.Error(codes.Unavailable, message)
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
+
+func (s *WeatherDataServer) GetObservations(ctx context.Context, req *weatherpb.GetObservationsRequest) (*weatherpb.GetObservationsResponse, error) {
+	polygonID, lat, lon, err := locationOf(req.GetLocation())
+	if err != nil {
+		return nil, err
+	}
+	if req.End < req.Start {
+		return nil, status.Error(codes.InvalidArgument, "invalid time range: end is before start")
+	}
+
+	history, err := s.historyService.GetHistory(models.HistoryRequest{
+		PolygonID:       polygonID,
+		Lat:             lat,
+		Lon:             lon,
+		Parameter:       req.Parameter,
+		Start:           req.Start,
+		End:             req.End,
+		IncludeForecast: req.IncludeForecast,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &weatherpb.GetObservationsResponse{
+		LocationKey:  history.LocationKey,
+		Parameter:    history.Parameter,
+		Unit:         history.Unit,
+		Observations: make([]*weatherpb.Observation, 0, len(history.Observations)),
+	}
+	for _, observation := range history.Observations {
+		response.Observations = append(response.Observations, &weatherpb.Observation{
+			Parameter:       observation.Parameter,
+			ObservedAt:      observation.ObservedAt.Unix(),
+			Value:           observation.Value,
+			Unit:            observation.Unit,
+			Source:          observation.Source,
+			IsForecast:      observation.IsForecast,
+			ConfidenceScore: observation.ConfidenceScore,
+		})
+	}
+	return response, nil
+}
+
+func (s *WeatherDataServer) GetAggregate(ctx context.Context, req *weatherpb.GetAggregateRequest) (*weatherpb.GetAggregateResponse, error) {
+	polygonID, lat, lon, err := locationOf(req.GetLocation())
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate, err := s.historyService.GetAggregate(models.AggregateRequest{
+		PolygonID: polygonID,
+		Lat:       lat,
+		Lon:       lon,
+		Parameter: req.Parameter,
+		Start:     req.Start,
+		End:       req.End,
+		Function:  req.Function,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &weatherpb.GetAggregateResponse{
+		LocationKey:    aggregate.LocationKey,
+		Parameter:      aggregate.Parameter,
+		Unit:           aggregate.Unit,
+		Function:       aggregate.Function,
+		Value:          aggregate.Value,
+		ValueCount:     int32(aggregate.ValueCount),
+		DataPointCount: int32(aggregate.DataPointCount),
+	}, nil
+}
+
+func (s *WeatherDataServer) GetForecast(ctx context.Context, req *weatherpb.GetForecastRequest) (*weatherpb.GetForecastResponse, error) {
+	polygonID, lat, lon, err := locationOf(req.GetLocation())
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := s.forecastService.GetHourlyForecast(models.ForecastRequest{PolygonID: polygonID, Lat: lat, Lon: lon, Lang: req.Lang})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &weatherpb.GetForecastResponse{
+		Provider:             forecast.Provider,
+		StepHours:            int32(forecast.StepHours),
+		Points:               make([]*weatherpb.ForecastPoint, 0, len(forecast.Points)),
+		TotalPrecipitationMm: forecast.TotalPrecipitation,
+	}
+	for _, point := range forecast.Points {
+		response.Points = append(response.Points, &weatherpb.ForecastPoint{
+			Dt:                       point.Dt,
+			TemperatureC:             point.Temperature,
+			FeelsLikeC:               point.FeelsLike,
+			HumidityPercent:          point.Humidity,
+			PressureHpa:              point.Pressure,
+			WindSpeedMs:              point.WindSpeed,
+			CloudCoverPercent:        point.CloudCover,
+			PrecipitationMm:          point.Precipitation,
+			PrecipitationProbability: point.PrecipitationProbability,
+			Description:              point.Description,
+		})
+	}
+	return response, nil
+}
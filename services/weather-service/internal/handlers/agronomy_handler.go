@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AgronomyHandler struct {
+	agronomyService services.IAgronomyService
+}
+
+func NewAgronomyHandler(agronomyService services.IAgronomyService) *AgronomyHandler {
+	return &AgronomyHandler{agronomyService: agronomyService}
+}
+
+func (h *AgronomyHandler) RegisterRoutes(router *gin.Engine) {
+	derivedGroup := router.Group("/weather/public/api/v2/derived")
+	derivedGroup.GET("/et0", h.GetEvapotranspiration)
+	derivedGroup.GET("/gdd", h.GetGrowingDegreeDays)
+	derivedGroup.GET("/disease-risk", h.GetDiseaseRisk)
+	derivedGroup.GET("/dry-spell", h.GetDrySpell)
+}
+
+func bindDerivedRequest(c *gin.Context) (models.PrecipitationRequest, bool) {
+	var req models.PrecipitationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return req, false
+	}
+	if req.End <= req.Start {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "End time must be greater than start time"))
+		return req, false
+	}
+	return req, true
+}
+
+// GetEvapotranspiration returns the daily reference evapotranspiration of a polygon in mm. It
+// takes the same query as the precipitation endpoint and answers in the same shape, so
+// policy-service can use it as a derived data source.
+func (h *AgronomyHandler) GetEvapotranspiration(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+
+	et0Response, err := h.agronomyService.GetEvapotranspiration(req)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, et0Response)
+}
+
+// GetGrowingDegreeDays returns the daily growing degree days of a polygon above base_celsius,
+// 10 °C by default, in the shape of the precipitation endpoint
+func (h *AgronomyHandler) GetGrowingDegreeDays(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+	baseCelsius := services.DefaultGDDBaseCelsius
+	if value := c.Query("base_celsius"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "Invalid base_celsius"))
+			return
+		}
+		baseCelsius = parsed
+	}
+
+	gddResponse, err := h.agronomyService.GetGrowingDegreeDays(req, baseCelsius)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, gddResponse)
+}
+
+// GetDiseaseRisk returns the daily fungal disease infection risk of a polygon from 0 to 100,
+// rated from humidity and temperature, in the shape of the precipitation endpoint
+func (h *AgronomyHandler) GetDiseaseRisk(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+
+	riskResponse, err := h.agronomyService.GetDiseaseRisk(req)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, riskResponse)
+}
+
+// GetDrySpell returns for each day of a polygon the length of the dry spell it ends, counting
+// days with less than threshold_mm of rain, 1 mm by default, in the shape of the precipitation
+// endpoint
+func (h *AgronomyHandler) GetDrySpell(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+	thresholdMM := services.DefaultDryDayThresholdMM
+	if value := c.Query("threshold_mm"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "Invalid threshold_mm"))
+			return
+		}
+		thresholdMM = parsed
+	}
+
+	drySpellResponse, err := h.agronomyService.GetDrySpell(req, thresholdMM)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, drySpellResponse)
+}
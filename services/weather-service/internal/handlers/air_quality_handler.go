@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AirQualityHandler struct {
+	airQualityService services.IAirQualityService
+}
+
+func NewAirQualityHandler(airQualityService services.IAirQualityService) *AirQualityHandler {
+	return &AirQualityHandler{airQualityService: airQualityService}
+}
+
+func (h *AirQualityHandler) RegisterRoutes(router *gin.Engine) {
+	publicGroup := router.Group("/weather/public/api/v2")
+	publicGroup.GET("/air-quality/polygon", h.GetAirQuality)
+}
+
+// GetAirQuality returns the daily highest air quality index of a polygon, from 1 (good) to 5
+// (very poor), in the shape of the precipitation endpoint so policy-service can use it as a
+// weather data source
+func (h *AirQualityHandler) GetAirQuality(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+
+	airQualityResponse, err := h.airQualityService.GetAirQuality(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, airQualityResponse)
+}
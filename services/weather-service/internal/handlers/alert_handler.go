@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertHandler struct {
+	alertService services.IAlertService
+}
+
+func NewAlertHandler(alertService services.IAlertService) *AlertHandler {
+	return &AlertHandler{alertService: alertService}
+}
+
+func (h *AlertHandler) RegisterRoutes(router *gin.Engine) {
+	alertGroup := router.Group("/weather/protected/api/v2/alerts/subscriptions")
+	alertGroup.GET("", h.GetSubscriptions)
+	alertGroup.POST("", h.CreateSubscription)
+	alertGroup.DELETE("/:subscription_id", h.DeleteSubscription)
+	alertGroup.GET("/:subscription_id/alerts", h.GetAlerts)
+
+	protectedGroup := router.Group("/weather/protected/api/v2/alerts")
+	protectedGroup.GET("/warnings", h.GetActiveWarnings)
+
+	// Other services and operators post official warnings the feed does not carry
+	internalGroup := router.Group("/weather/internal/api/v2/alerts")
+	internalGroup.POST("/warnings", h.IngestWarning)
+}
+
+func (h *AlertHandler) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := h.alertService.GetSubscriptions(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, err, "Alert subscription not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(subscriptions))
+}
+
+// CreateSubscription subscribes a farm or region of the caller to severe weather alerts
+func (h *AlertHandler) CreateSubscription(c *gin.Context) {
+	var req models.CreateAlertSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	subscription, err := h.alertService.CreateSubscription(c.GetHeader("X-User-ID"), req)
+	if err != nil {
+		respondError(c, err, "Alert subscription not found")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(subscription))
+}
+
+func (h *AlertHandler) DeleteSubscription(c *gin.Context) {
+	subscriptionID, ok := int64Param(c, "subscription_id")
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.DeleteSubscription(c.GetHeader("X-User-ID"), subscriptionID); err != nil {
+		respondError(c, err, "Alert subscription not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(gin.H{"subscription_id": subscriptionID}))
+}
+
+// GetAlerts lists the alerts raised for a subscription, latest forecast day first
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	subscriptionID, ok := int64Param(c, "subscription_id")
+	if !ok {
+		return
+	}
+
+	alerts, err := h.alertService.GetAlerts(c.GetHeader("X-User-ID"), subscriptionID)
+	if err != nil {
+		respondError(c, err, "Alert subscription not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(alerts))
+}
+
+// GetActiveWarnings lists the official warnings in force, with the insured farms in their provinces
+func (h *AlertHandler) GetActiveWarnings(c *gin.Context) {
+	warnings, err := h.alertService.GetActiveWarnings()
+	if err != nil {
+		respondError(c, err, "Weather warning not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(warnings))
+}
+
+// IngestWarning stores an official warning and pushes it to the subscriptions of its area
+func (h *AlertHandler) IngestWarning(c *gin.Context) {
+	var req models.IngestWarningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	result, err := h.alertService.IngestWarning(c.Request.Context(), models.WarningSourceManual, req)
+	if err != nil {
+		respondError(c, err, "Weather warning not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(result))
+}
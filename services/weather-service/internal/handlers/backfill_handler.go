@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BackfillHandler struct {
+	backfillService services.IBackfillService
+}
+
+func NewBackfillHandler(backfillService services.IBackfillService) *BackfillHandler {
+	return &BackfillHandler{backfillService: backfillService}
+}
+
+func (h *BackfillHandler) RegisterRoutes(router *gin.Engine) {
+	internalGroup := router.Group("/weather/internal/api/v2")
+	internalGroup.POST("/history/backfill", h.Backfill)
+}
+
+// Backfill loads the archived weather of a polygon over a past range into the weather history and
+// returns its daily values. It takes the query of the precipitation endpoint; callers repeat it
+// until the response reports the range complete.
+func (h *BackfillHandler) Backfill(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+
+	backfillResponse, err := h.backfillService.Backfill(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, backfillResponse)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClimatologyHandler struct {
+	climatologyService services.IClimatologyService
+}
+
+func NewClimatologyHandler(climatologyService services.IClimatologyService) *ClimatologyHandler {
+	return &ClimatologyHandler{climatologyService: climatologyService}
+}
+
+func (h *ClimatologyHandler) RegisterRoutes(router *gin.Engine) {
+	publicGroup := router.Group("/weather/public/api/v2")
+	publicGroup.GET("/climatology", h.GetClimatology)
+}
+
+// GetClimatology compares the rainfall and temperature of the last days (30 by default) at a
+// polygon or point with the climatological normals of the same days
+func (h *ClimatologyHandler) GetClimatology(c *gin.Context) {
+	var req models.ClimatologyRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+	if req.PolygonID == "" && (req.Lat == nil || req.Lon == nil) {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "Either polygon_id or lat and lon are required"))
+		return
+	}
+
+	climatologyResponse, err := h.climatologyService.Compare(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, climatologyResponse)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DroughtHandler struct {
+	droughtService services.IDroughtService
+}
+
+func NewDroughtHandler(droughtService services.IDroughtService) *DroughtHandler {
+	return &DroughtHandler{droughtService: droughtService}
+}
+
+func (h *DroughtHandler) RegisterRoutes(router *gin.Engine) {
+	derivedGroup := router.Group("/weather/public/api/v2/derived")
+	derivedGroup.GET("/spi/:scale_months", h.GetSPI)
+}
+
+// GetSPI returns the monthly Standardized Precipitation Index of a polygon. It takes the same
+// query as the precipitation endpoint and answers in the same shape, so policy-service can use
+// it as a derived data source.
+func (h *DroughtHandler) GetSPI(c *gin.Context) {
+	scaleMonths, err := strconv.Atoi(c.Param("scale_months"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "Invalid scale_months"))
+		return
+	}
+
+	var req models.PrecipitationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	if req.End <= req.Start {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "End time must be greater than start time"))
+		return
+	}
+
+	spiResponse, err := h.droughtService.GetSPI(req, scaleMonths)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, spiResponse)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProviderHandler struct {
+	mergedWeatherService services.IMergedWeatherService
+}
+
+func NewProviderHandler(mergedWeatherService services.IMergedWeatherService) *ProviderHandler {
+	return &ProviderHandler{mergedWeatherService: mergedWeatherService}
+}
+
+func (h *ProviderHandler) RegisterRoutes(router *gin.Engine) {
+	publicGroup := router.Group("/weather/public/api/v2")
+	publicGroup.GET("/current/merged", h.GetMergedCurrent)
+
+	protectedGroup := router.Group("/weather/protected/api/v2")
+	protectedGroup.GET("/providers/health", h.GetProviderHealth)
+}
+
+// GetMergedCurrent returns the current conditions at a point merged over the weather providers,
+// with the reading of each provider
+func (h *ProviderHandler) GetMergedCurrent(c *gin.Context) {
+	var req models.MergedWeatherRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	mergedResponse, err := h.mergedWeatherService.GetCurrent(c.Request.Context(), req)
+	if err != nil {
+		respondUpstreamError(c, err, "Failed to fetch current weather: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, mergedResponse)
+}
+
+// GetProviderHealth reports the health of each weather provider in priority order; their budget
+// consumption is under the quota metrics
+func (h *ProviderHandler) GetProviderHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(h.mergedWeatherService.ProviderHealth()))
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"utils"
+	"weather-service/internal/quota"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError maps a service error to its HTTP status by the wording the services use
+func respondError(c *gin.Context, err error, notFoundMessage string) {
+	message := err.Error()
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, utils.CreateErrorResponse("Not Found", notFoundMessage))
+	case errors.Is(err, quota.ErrQuotaExceeded):
+		c.JSON(http.StatusTooManyRequests, utils.CreateErrorResponse("Too Many Requests", message))
+	case strings.Contains(message, "duplicate"):
+		c.JSON(http.StatusConflict, utils.CreateErrorResponse("Conflict", message))
+	case strings.Contains(message, "invalid"):
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", message))
+	case strings.Contains(message, "unauthorized"):
+		c.JSON(http.StatusUnauthorized, utils.CreateErrorResponse("Unauthorized", message))
+	case strings.Contains(message, "forbidden"):
+		c.JSON(http.StatusForbidden, utils.CreateErrorResponse("Forbidden", message))
+	default:
+		c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("Internal server error", message))
+	}
+}
+
+// respondUpstreamError answers 429 when an upstream provider budget is spent and 500 otherwise
+func respondUpstreamError(c *gin.Context, err error, message string) {
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		c.JSON(http.StatusTooManyRequests, utils.CreateErrorResponse("Too Many Requests", message))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, utils.CreateErrorResponse("Internal server error", message))
+}
+
+// int64Param reads a numeric path parameter, answering 400 when it is not a number
+func int64Param(c *gin.Context, name string) (int64, bool) {
+	value, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "Invalid "+name))
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StationHandler struct {
+	stationService services.IStationService
+}
+
+func NewStationHandler(stationService services.IStationService) *StationHandler {
+	return &StationHandler{stationService: stationService}
+}
+
+func (h *StationHandler) RegisterRoutes(router *gin.Engine) {
+	stationGroupPublic := router.Group("/weather/public/api/v2/stations")
+	stationGroupPublic.GET("/nearest", h.GetNearestStations)
+
+	stationGroupProtected := router.Group("/weather/protected/api/v2/stations")
+	stationGroupProtected.GET("", h.GetStations)
+	stationGroupProtected.POST("", h.CreateStation)
+	stationGroupProtected.GET("/:station_id", h.GetStation)
+	stationGroupProtected.PUT("/:station_id", h.UpdateStation)
+	stationGroupProtected.DELETE("/:station_id", h.DeleteStation)
+}
+
+// GetStations lists the registry; include_inactive=true also lists retired stations
+func (h *StationHandler) GetStations(c *gin.Context) {
+	stations, err := h.stationService.GetStations(c.Query("include_inactive") != "true")
+	if err != nil {
+		respondError(c, err, "Weather station not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(stations))
+}
+
+func (h *StationHandler) GetStation(c *gin.Context) {
+	stationID, ok := int64Param(c, "station_id")
+	if !ok {
+		return
+	}
+
+	station, err := h.stationService.GetStation(stationID)
+	if err != nil {
+		respondError(c, err, "Weather station not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(station))
+}
+
+func (h *StationHandler) CreateStation(c *gin.Context) {
+	var req models.StationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	station, err := h.stationService.CreateStation(req)
+	if err != nil {
+		respondError(c, err, "Weather station not found")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(station))
+}
+
+func (h *StationHandler) UpdateStation(c *gin.Context) {
+	stationID, ok := int64Param(c, "station_id")
+	if !ok {
+		return
+	}
+
+	var req models.StationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	station, err := h.stationService.UpdateStation(stationID, req)
+	if err != nil {
+		respondError(c, err, "Weather station not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(station))
+}
+
+func (h *StationHandler) DeleteStation(c *gin.Context) {
+	stationID, ok := int64Param(c, "station_id")
+	if !ok {
+		return
+	}
+
+	if err := h.stationService.DeleteStation(stationID); err != nil {
+		respondError(c, err, "Weather station not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(gin.H{"station_id": stationID}))
+}
+
+// GetNearestStations returns the closest active stations to a farm, optionally only those
+// measuring a parameter, within a distance or above a reliability
+func (h *StationHandler) GetNearestStations(c *gin.Context) {
+	var req models.NearestStationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	stations, err := h.stationService.GetNearestStations(req)
+	if err != nil {
+		respondError(c, err, "Weather station not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(stations))
+}
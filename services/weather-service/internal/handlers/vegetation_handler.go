@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"utils"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VegetationHandler struct {
+	vegetationService services.IVegetationService
+}
+
+func NewVegetationHandler(vegetationService services.IVegetationService) *VegetationHandler {
+	return &VegetationHandler{vegetationService: vegetationService}
+}
+
+func (h *VegetationHandler) RegisterRoutes(router *gin.Engine) {
+	vegetationGroup := router.Group("/weather/public/api/v2/vegetation")
+	vegetationGroup.GET("/ndvi", h.GetNDVI)
+}
+
+// GetNDVI returns the mean NDVI of each satellite image of a polygon with at most max_cloud_cover
+// percent of clouds, 60 by default. It takes the same query as the precipitation endpoint and
+// answers in the same shape, so policy-service can use it as a satellite data source.
+func (h *VegetationHandler) GetNDVI(c *gin.Context) {
+	req, ok := bindDerivedRequest(c)
+	if !ok {
+		return
+	}
+	maxCloudCover := services.DefaultMaxCloudCover
+	if value := c.Query("max_cloud_cover"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", "Invalid max_cloud_cover"))
+			return
+		}
+		maxCloudCover = parsed
+	}
+
+	ndviResponse, err := h.vegetationService.GetNDVI(req, maxCloudCover)
+	if err != nil {
+		respondError(c, err, "Polygon not found")
+		return
+	}
+	c.JSON(http.StatusOK, ndviResponse)
+}
@@ -4,23 +4,34 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"utils"
+	"weather-service/internal/cache"
 	"weather-service/internal/models"
+	"weather-service/internal/quota"
 	"weather-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type WeatherHandler struct {
-	weatherService services.IWeatherService
-	agroService    services.IAgroService
+	weatherService  services.IWeatherService
+	agroService     services.IAgroService
+	weatherCache    *cache.WeatherCache
+	historyService  services.IHistoryService
+	forecastService services.IForecastService
+	limiter         *quota.Limiter
 }
 
-func NewWeatherHandler(weatherService services.IWeatherService, agroService services.IAgroService) *WeatherHandler {
+func NewWeatherHandler(weatherService services.IWeatherService, agroService services.IAgroService, weatherCache *cache.WeatherCache, historyService services.IHistoryService, forecastService services.IForecastService, limiter *quota.Limiter) *WeatherHandler {
 	return &WeatherHandler{
-		weatherService: weatherService,
-		agroService:    agroService,
+		weatherService:  weatherService,
+		agroService:     agroService,
+		weatherCache:    weatherCache,
+		historyService:  historyService,
+		forecastService: forecastService,
+		limiter:         limiter,
 	}
 }
 
@@ -29,6 +40,17 @@ func (h *WeatherHandler) RegisterRoutes(router *gin.Engine) {
 	weatherGroupPublic.GET("/current", h.GetWeatherByCoordinates)
 	weatherGroupPublic.GET("/current/polygon", h.GetCurrentWeatherByPolygon)
 	weatherGroupPublic.GET("/precipitation/polygon", h.GetPrecipitationByPolygon)
+	weatherGroupPublic.GET("/history", h.GetWeatherHistory)
+	weatherGroupPublic.GET("/history/rainfall", h.GetRainfallAccumulation)
+	weatherGroupPublic.GET("/forecast/hourly", h.GetHourlyForecast)
+	weatherGroupPublic.GET("/forecast/daily", h.GetDailyForecast)
+
+	weatherGroupInternal := router.Group("/weather/internal/api/v2")
+	weatherGroupInternal.POST("/batch/current", h.GetWeatherBatch)
+
+	weatherGroupProtected := router.Group("/weather/protected/api/v2")
+	weatherGroupProtected.GET("/cache/metrics", h.GetCacheMetrics)
+	weatherGroupProtected.GET("/quota/metrics", h.GetQuotaMetrics)
 }
 
 func (h *WeatherHandler) GetWeather(c *gin.Context) {
@@ -50,14 +72,25 @@ func (h *WeatherHandler) GetWeatherByCoordinates(c *gin.Context) {
 
 	weatherResponse, err := h.weatherService.FetchWeatherData(lat, lon, exclude, units, lang)
 	if err != nil {
-		errorResponse := utils.CreateErrorResponse("Internal server error", "Failed to fetch weather data")
-		c.JSON(http.StatusInternalServerError, errorResponse)
+		respondUpstreamError(c, err, "Failed to fetch weather data")
 		return
 	}
 
 	c.JSON(http.StatusOK, weatherResponse)
 }
 
+// GetWeatherBatch returns One Call readings for many points, such as every farm of a policy cycle
+func (h *WeatherHandler) GetWeatherBatch(c *gin.Context) {
+	var req models.BatchWeatherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse := utils.CreateErrorResponse("Bad Request", err.Error())
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.weatherService.FetchWeatherBatch(req))
+}
+
 func (h *WeatherHandler) GetCurrentWeatherByPolygon(c *gin.Context) {
 	// Simple endpoint: only polygon_id required, no time ranges
 	polygonID := c.Query("polygon_id")
@@ -105,8 +138,7 @@ func (h *WeatherHandler) GetCurrentWeatherByPolygon(c *gin.Context) {
 		polygonName := fmt.Sprintf("temp_polygon_%d", time.Now().Unix())
 		polygon, err := h.agroService.CreatePolygon(polygonName, coords)
 		if err != nil {
-			errorResponse := utils.CreateErrorResponse("Internal server error", "Failed to create polygon: "+err.Error())
-			c.JSON(http.StatusInternalServerError, errorResponse)
+			respondUpstreamError(c, err, "Failed to create polygon: "+err.Error())
 			return
 		}
 		polygonID = polygon.ID
@@ -115,8 +147,7 @@ func (h *WeatherHandler) GetCurrentWeatherByPolygon(c *gin.Context) {
 	// Get current weather for the polygon - real-time data only
 	currentWeather, err := h.agroService.GetCurrentWeather(polygonID)
 	if err != nil {
-		errorResponse := utils.CreateErrorResponse("Internal server error", "Failed to fetch current weather: "+err.Error())
-		c.JSON(http.StatusInternalServerError, errorResponse)
+		respondUpstreamError(c, err, "Failed to fetch current weather: "+err.Error())
 		return
 	}
 
@@ -160,11 +191,141 @@ func (h *WeatherHandler) GetPrecipitationByPolygon(c *gin.Context) {
 	// Call improved Agro service method that handles both scenarios
 	precipitationResponse, err := h.agroService.GetPrecipitationWithPolygonID(req.PolygonID, coordinates, req.Start, req.End)
 	if err != nil {
-		errorResponse := utils.CreateErrorResponse("Internal server error", "Failed to fetch precipitation data: "+err.Error())
-		c.JSON(http.StatusInternalServerError, errorResponse)
+		respondUpstreamError(c, err, "Failed to fetch precipitation data: "+err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, precipitationResponse)
 }
 
+// GetCacheMetrics reports cache hits and misses per cached parameter since startup
+func (h *WeatherHandler) GetCacheMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(gin.H{
+		"enabled": h.weatherCache != nil,
+		"metrics": h.weatherCache.Metrics(),
+	}))
+}
+
+// GetQuotaMetrics reports the budget consumption of each upstream provider
+func (h *WeatherHandler) GetQuotaMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(gin.H{
+		"enabled": h.limiter != nil,
+		"metrics": h.limiter.Metrics(c.Request.Context()),
+	}))
+}
+
+// GetWeatherHistory returns stored observations of one parameter for a polygon or a lat/lon point
+func (h *WeatherHandler) GetWeatherHistory(c *gin.Context) {
+	var req models.HistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse := utils.CreateErrorResponse("Bad Request", err.Error())
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	if req.PolygonID == "" && (req.Lat == nil || req.Lon == nil) {
+		errorResponse := utils.CreateErrorResponse("Bad Request", "Either polygon_id or lat and lon are required")
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	if req.End <= req.Start {
+		errorResponse := utils.CreateErrorResponse("Bad Request", "End time must be greater than start time")
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	if _, ok := models.ObservationUnits[req.Parameter]; !ok {
+		errorResponse := utils.CreateErrorResponse("Bad Request", "Unknown parameter: "+req.Parameter)
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	historyResponse, err := h.historyService.GetHistory(req)
+	if err != nil {
+		errorResponse := utils.CreateErrorResponse("Internal server error", "Failed to fetch weather history: "+err.Error())
+		c.JSON(http.StatusInternalServerError, errorResponse)
+		return
+	}
+
+	c.JSON(http.StatusOK, historyResponse)
+}
+
+// GetRainfallAccumulation aggregates stored daily rainfall over 7/14/30-day or requested windows
+func (h *WeatherHandler) GetRainfallAccumulation(c *gin.Context) {
+	var req models.RainfallAccumulationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse := utils.CreateErrorResponse("Bad Request", err.Error())
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	if req.PolygonID == "" && (req.Lat == nil || req.Lon == nil) {
+		errorResponse := utils.CreateErrorResponse("Bad Request", "Either polygon_id or lat and lon are required")
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return
+	}
+
+	rainfallResponse, err := h.historyService.GetRainfallAccumulation(req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		errorResponse := utils.CreateErrorResponse("Internal server error", "Failed to aggregate rainfall: "+err.Error())
+		if strings.HasPrefix(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+			errorResponse = utils.CreateErrorResponse("Bad Request", err.Error())
+		}
+		c.JSON(status, errorResponse)
+		return
+	}
+
+	c.JSON(http.StatusOK, rainfallResponse)
+}
+
+func bindForecastRequest(c *gin.Context) (*models.ForecastRequest, bool) {
+	var req models.ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse := utils.CreateErrorResponse("Bad Request", err.Error())
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return nil, false
+	}
+
+	if req.PolygonID == "" && (req.Lat == nil || req.Lon == nil) {
+		errorResponse := utils.CreateErrorResponse("Bad Request", "Either polygon_id or lat and lon are required")
+		c.JSON(http.StatusBadRequest, errorResponse)
+		return nil, false
+	}
+	return &req, true
+}
+
+// GetHourlyForecast returns the upcoming forecast steps: hourly for a lat/lon point,
+// 3-hourly over 5 days for a polygon
+func (h *WeatherHandler) GetHourlyForecast(c *gin.Context) {
+	req, ok := bindForecastRequest(c)
+	if !ok {
+		return
+	}
+
+	forecastResponse, err := h.forecastService.GetHourlyForecast(*req)
+	if err != nil {
+		respondUpstreamError(c, err, "Failed to fetch forecast: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, forecastResponse)
+}
+
+// GetDailyForecast returns up to 7 forecast days for a lat/lon point, or 5 for a polygon
+func (h *WeatherHandler) GetDailyForecast(c *gin.Context) {
+	req, ok := bindForecastRequest(c)
+	if !ok {
+		return
+	}
+
+	forecastResponse, err := h.forecastService.GetDailyForecast(*req)
+	if err != nil {
+		respondUpstreamError(c, err, "Failed to fetch forecast: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, forecastResponse)
+}
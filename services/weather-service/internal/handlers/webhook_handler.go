@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"utils"
+	"weather-service/internal/models"
+	"weather-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService services.IWebhookService
+}
+
+func NewWebhookHandler(webhookService services.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router *gin.Engine) {
+	webhookGroup := router.Group("/weather/protected/api/v2/webhooks")
+	webhookGroup.GET("", h.GetWebhooks)
+	webhookGroup.POST("", h.CreateWebhook)
+	webhookGroup.DELETE("/:webhook_id", h.DeleteWebhook)
+	webhookGroup.GET("/:webhook_id/deliveries", h.GetDeliveries)
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.GetWebhooks(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, err, "Webhook not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(webhooks))
+}
+
+// CreateWebhook registers a webhook of the caller for a weather condition at a location.
+// The response carries the signing secret, which is not shown again.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.CreateErrorResponse("Bad Request", err.Error()))
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(c.GetHeader("X-User-ID"), req)
+	if err != nil {
+		respondError(c, err, "Webhook not found")
+		return
+	}
+	c.JSON(http.StatusCreated, utils.CreateSuccessResponse(webhook))
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhookID, ok := int64Param(c, "webhook_id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.GetHeader("X-User-ID"), webhookID); err != nil {
+		respondError(c, err, "Webhook not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(gin.H{"webhook_id": webhookID}))
+}
+
+// GetDeliveries lists the latest deliveries of a webhook with their attempts and outcome
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhookID, ok := int64Param(c, "webhook_id")
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(c.GetHeader("X-User-ID"), webhookID)
+	if err != nil {
+		respondError(c, err, "Webhook not found")
+		return
+	}
+	c.JSON(http.StatusOK, utils.CreateSuccessResponse(deliveries))
+}
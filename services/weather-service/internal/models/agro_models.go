@@ -56,6 +56,10 @@ type DataPoint struct {
 	Data  float64 `json:"data"`  // Precipitation in mm
 	Count int     `json:"count"` // Number of measurements
 	Unit  string  `json:"unit"`
+	// Confidence in the value from 0 to 1
+	ConfidenceScore *float64 `json:"confidence_score,omitempty"`
+	// Cloud cover in percent of the satellite image a vegetation index was read from
+	CloudCover *float64 `json:"cloud_cover,omitempty"`
 }
 type UnifiedAPIResponse struct {
 	PolygonID         string      `json:"polygon_id"`
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Severe weather event types a subscription can be alerted about
+const (
+	AlertHeavyRain = "heavy_rain"
+	AlertHeatwave  = "heatwave"
+	AlertTyphoon   = "typhoon"
+)
+
+// Default alert thresholds, following the national forecasting centre's warning levels
+const (
+	DefaultHeavyRainMM     = 50.0 // Daily rainfall of heavy rain
+	DefaultHeatwaveCelsius = 35.0 // Daily maximum of a heatwave
+	DefaultTyphoonWindMS   = 17.2 // Beaufort force 8, the lower bound of a tropical storm
+)
+
+var AlertEventTypes = map[string]bool{
+	AlertHeavyRain: true,
+	AlertHeatwave:  true,
+	AlertTyphoon:   true,
+}
+
+// WeatherAlertSubscription subscribes a farm or region to severe weather alerts. Subscriptions with
+// a polygon or point are alerted on forecast breaches; those covering a province or district only
+// on official warnings for it.
+type WeatherAlertSubscription struct {
+	ID              int64          `json:"id" db:"id"`
+	UserID          string         `json:"user_id" db:"user_id"`
+	FarmID          *string        `json:"farm_id,omitempty" db:"farm_id"`
+	Region          *string        `json:"region,omitempty" db:"region"`
+	PolygonID       *string        `json:"polygon_id,omitempty" db:"polygon_id"`
+	Latitude        *float64       `json:"latitude,omitempty" db:"latitude"`
+	Longitude       *float64       `json:"longitude,omitempty" db:"longitude"`
+	ProvinceCode    *string        `json:"province_code,omitempty" db:"province_code"`
+	DistrictCode    *string        `json:"district_code,omitempty" db:"district_code"`
+	EventTypes      pq.StringArray `json:"event_types" db:"event_types"`
+	HeavyRainMM     *float64       `json:"heavy_rain_mm,omitempty" db:"heavy_rain_mm"`
+	HeatwaveCelsius *float64       `json:"heatwave_celsius,omitempty" db:"heatwave_celsius"`
+	TyphoonWindMS   *float64       `json:"typhoon_wind_ms,omitempty" db:"typhoon_wind_ms"`
+	IsActive        bool           `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Threshold returns the subscription's threshold for an event type, or the default
+func (s *WeatherAlertSubscription) Threshold(eventType string) float64 {
+	switch eventType {
+	case AlertHeavyRain:
+		if s.HeavyRainMM != nil {
+			return *s.HeavyRainMM
+		}
+		return DefaultHeavyRainMM
+	case AlertHeatwave:
+		if s.HeatwaveCelsius != nil {
+			return *s.HeatwaveCelsius
+		}
+		return DefaultHeatwaveCelsius
+	default:
+		if s.TyphoonWindMS != nil {
+			return *s.TyphoonWindMS
+		}
+		return DefaultTyphoonWindMS
+	}
+}
+
+// WeatherAlert is a threshold breach forecast for a subscription
+type WeatherAlert struct {
+	ID             int64     `json:"id" db:"id"`
+	SubscriptionID int64     `json:"subscription_id" db:"subscription_id"`
+	EventType      string    `json:"event_type" db:"event_type"`
+	ForecastDate   time.Time `json:"forecast_date" db:"forecast_date"`
+	Value          float64   `json:"value" db:"value"`
+	Threshold      float64   `json:"threshold" db:"threshold"`
+	Provider       string    `json:"provider" db:"provider"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// HasLocation reports whether the subscription has a point or polygon whose forecast is evaluated
+func (s *WeatherAlertSubscription) HasLocation() bool {
+	return s.PolygonID != nil || (s.Latitude != nil && s.Longitude != nil)
+}
+
+// CreateAlertSubscriptionRequest represents the body of a new alert subscription.
+// A location is a polygon_id, a latitude/longitude point, or a province or district code.
+type CreateAlertSubscriptionRequest struct {
+	FarmID          *string  `json:"farm_id"`
+	Region          *string  `json:"region"`
+	PolygonID       *string  `json:"polygon_id"`
+	Latitude        *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude       *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	ProvinceCode    *string  `json:"province_code"`
+	DistrictCode    *string  `json:"district_code"`
+	EventTypes      []string `json:"event_types" binding:"required,min=1"`
+	HeavyRainMM     *float64 `json:"heavy_rain_mm" binding:"omitempty,gt=0"`
+	HeatwaveCelsius *float64 `json:"heatwave_celsius" binding:"omitempty,gt=0"`
+	TyphoonWindMS   *float64 `json:"typhoon_wind_ms" binding:"omitempty,gt=0"`
+}
+
+// Sources of official warnings
+const (
+	WarningSourceNationalMet = "national_met"
+	WarningSourceManual      = "manual"
+)
+
+// WeatherWarning is an official severe weather warning for provinces or districts, such as a
+// typhoon warning of the national met service
+type WeatherWarning struct {
+	ID            int64          `json:"id" db:"id"`
+	Source        string         `json:"source" db:"source"`
+	ExternalID    string         `json:"external_id" db:"external_id"`
+	EventType     string         `json:"event_type" db:"event_type"`
+	Severity      string         `json:"severity" db:"severity"`
+	Title         string         `json:"title" db:"title"`
+	Description   string         `json:"description" db:"description"`
+	ProvinceCodes pq.StringArray `json:"province_codes" db:"province_codes"`
+	DistrictCodes pq.StringArray `json:"district_codes" db:"district_codes"`
+	IssuedAt      time.Time      `json:"issued_at" db:"issued_at"`
+	ExpiresAt     time.Time      `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+	// Active insured farms in the warned provinces
+	InsuredFarmCount int `json:"insured_farm_count" db:"insured_farm_count"`
+}
+
+// IngestWarningRequest is an official warning as the national met service gateway lists it, or as
+// posted to the ingest endpoint
+type IngestWarningRequest struct {
+	ExternalID    string    `json:"id" binding:"required"`
+	EventType     string    `json:"event_type" binding:"required"`
+	Severity      string    `json:"severity" binding:"required"`
+	Title         string    `json:"title" binding:"required"`
+	Description   string    `json:"description"`
+	ProvinceCodes []string  `json:"province_codes"`
+	DistrictCodes []string  `json:"district_codes"`
+	IssuedAt      time.Time `json:"issued_at" binding:"required"`
+	ExpiresAt     time.Time `json:"expires_at" binding:"required"`
+}
+
+// WarningIngestResult counts the subscriptions a warning was pushed to
+type WarningIngestResult struct {
+	WarningID     int64 `json:"warning_id"`
+	Notified      int   `json:"notified_subscriptions"`
+	AffectedFarms int   `json:"affected_insured_farms"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// BackfillResponse reports a backfill of the weather history of a polygon from provider archives,
+// and the daily values of the stored history over the requested range. A backfill fetches a
+// bounded number of days per request, so callers repeat it until Complete is true.
+type BackfillResponse struct {
+	PolygonID     string    `json:"polygon_id"`
+	PolygonName   string    `json:"polygon_name"`
+	PolygonCenter []float64 `json:"polygon_center"`
+	TimeRange     TimeRange `json:"time_range"`
+	// Past days in the range, the days fetched by this request and the days still lacking history
+	DaysRequested int  `json:"days_requested"`
+	DaysFetched   int  `json:"days_fetched"`
+	DaysMissing   int  `json:"days_missing"`
+	Complete      bool `json:"complete"`
+	// Daily values of each parameter, keyed by shared parameter name
+	Data map[string][]DataPoint `json:"data"`
+}
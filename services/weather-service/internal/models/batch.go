@@ -0,0 +1,16 @@
+package models
+
+// BatchPoint is one location of a batch lookup; ID is echoed back so callers can match results
+type BatchPoint struct {
+	ID  string  `json:"id" binding:"required"`
+	Lat float64 `json:"lat" binding:"min=-90,max=90"`
+	Lon float64 `json:"lon" binding:"min=-180,max=180"`
+}
+
+// BatchWeatherRequest represents the body of a batch One Call lookup
+type BatchWeatherRequest struct {
+	Points  []BatchPoint `json:"points" binding:"required,min=1,max=1000,dive"`
+	Exclude string       `json:"exclude"`
+	Units   string       `json:"units"`
+	Lang    string       `json:"lang"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// ClimatologyRequest represents the query parameters of the climatology comparison endpoint.
+// A location is either a polygon_id or a lat/lon point; the period is the days up to now.
+type ClimatologyRequest struct {
+	PolygonID string   `form:"polygon_id"`
+	Lat       *float64 `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lon       *float64 `form:"lon" binding:"omitempty,min=-180,max=180"`
+	Days      int      `form:"days" binding:"omitempty,min=1,max=366"`
+}
+
+// MonthlyNormal is the long-term average weather of a calendar month at a location
+type MonthlyNormal struct {
+	Month int `json:"month"`
+	// Mean temperature in celsius and mean daily precipitation in mm
+	Temperature   float64 `json:"temperature"`
+	Precipitation float64 `json:"precipitation"`
+}
+
+// ClimatologyComparison compares the stored history of a parameter over a period with its
+// normal over the same days. Only days with enough stored readings are compared, so a partly
+// recorded period is not mistaken for a dry or cold one.
+type ClimatologyComparison struct {
+	Parameter   string   `json:"parameter"`
+	Unit        string   `json:"unit"`
+	Current     *float64 `json:"current"`
+	Normal      *float64 `json:"normal"`
+	Anomaly     *float64 `json:"anomaly"`
+	DaysCovered int      `json:"days_covered"`
+	// Current rainfall as a percentage of the normal rainfall
+	PercentOfNormal *float64 `json:"percent_of_normal,omitempty"`
+}
+
+// ClimatologyResponse represents current conditions at a location against its climatological
+// normals: rainfall as a total and temperature as a mean over the period
+type ClimatologyResponse struct {
+	LocationKey string                `json:"location_key"`
+	Latitude    float64               `json:"latitude"`
+	Longitude   float64               `json:"longitude"`
+	TimeRange   TimeRange             `json:"time_range"`
+	Days        int                   `json:"days"`
+	Rainfall    ClimatologyComparison `json:"rainfall"`
+	Temperature ClimatologyComparison `json:"temperature"`
+	Normals     []MonthlyNormal       `json:"normals"`
+}
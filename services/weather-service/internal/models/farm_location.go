@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Polling feeds, each polled on the update frequency of its providers
+const (
+	FeedCurrent  = "current"
+	FeedForecast = "forecast"
+)
+
+// FarmLocation is an insured farm in the polling registry
+type FarmLocation struct {
+	FarmID             string     `json:"farm_id" db:"farm_id"`
+	PolygonID          *string    `json:"polygon_id,omitempty" db:"polygon_id"`
+	Province           *string    `json:"province,omitempty" db:"province"`
+	Latitude           float64    `json:"latitude" db:"latitude"`
+	Longitude          float64    `json:"longitude" db:"longitude"`
+	IsActive           bool       `json:"is_active" db:"is_active"`
+	LastCurrentPollAt  *time.Time `json:"last_current_poll_at,omitempty" db:"last_current_poll_at"`
+	LastForecastPollAt *time.Time `json:"last_forecast_poll_at,omitempty" db:"last_forecast_poll_at"`
+	SyncedAt           time.Time  `json:"synced_at" db:"synced_at"`
+}
+
+// InsuredFarmLocation is a farm location as listed by policy-service
+type InsuredFarmLocation struct {
+	FarmID        string  `json:"farm_id"`
+	AgroPolygonID *string `json:"agro_polygon_id,omitempty"`
+	Province      *string `json:"province,omitempty"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+}
@@ -0,0 +1,66 @@
+package models
+
+// Forecast providers
+const (
+	ForecastProviderOneCall = "openweathermap_onecall"
+	ForecastProviderAgro    = "agro"
+)
+
+// ForecastRequest represents the query parameters of the forecast endpoints.
+// A location is either a polygon_id or a lat/lon point.
+type ForecastRequest struct {
+	PolygonID string   `form:"polygon_id"`
+	Lat       *float64 `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lon       *float64 `form:"lon" binding:"omitempty,min=-180,max=180"`
+	Lang      string   `form:"lang"`
+}
+
+// HourlyForecastPoint is one forecast step, normalized to celsius, m/s and mm
+type HourlyForecastPoint struct {
+	Dt                       int64   `json:"dt"` // Unix timestamp of the start of the step
+	Temperature              float64 `json:"temperature_c"`
+	FeelsLike                float64 `json:"feels_like_c"`
+	Humidity                 float64 `json:"humidity_percent"`
+	Pressure                 float64 `json:"pressure_hpa"`
+	WindSpeed                float64 `json:"wind_speed_ms"`
+	CloudCover               float64 `json:"cloud_cover_percent"`
+	Precipitation            float64 `json:"precipitation_mm"`          // Accumulated over the step
+	PrecipitationProbability float64 `json:"precipitation_probability"` // 0 to 1
+	Description              string  `json:"description,omitempty"`
+}
+
+// DailyForecastPoint is one forecast day, normalized to celsius, m/s and mm
+type DailyForecastPoint struct {
+	Dt                       int64   `json:"dt"` // Unix timestamp of the day
+	TemperatureMin           float64 `json:"temperature_min_c"`
+	TemperatureMax           float64 `json:"temperature_max_c"`
+	Humidity                 float64 `json:"humidity_percent"`
+	WindSpeed                float64 `json:"wind_speed_ms"`
+	CloudCover               float64 `json:"cloud_cover_percent"`
+	Precipitation            float64 `json:"precipitation_mm"`
+	PrecipitationProbability float64 `json:"precipitation_probability"` // 0 to 1
+	Description              string  `json:"description,omitempty"`
+}
+
+// HourlyForecastResponse represents the hourly forecast of a location
+type HourlyForecastResponse struct {
+	Provider           string                `json:"provider"`
+	PolygonID          string                `json:"polygon_id,omitempty"`
+	Lat                *float64              `json:"lat,omitempty"`
+	Lon                *float64              `json:"lon,omitempty"`
+	StepHours          int                   `json:"step_hours"`
+	Points             []HourlyForecastPoint `json:"points"`
+	TotalPrecipitation float64               `json:"total_precipitation_mm"`
+	DataPointCount     int                   `json:"data_point_count"`
+}
+
+// DailyForecastResponse represents the daily forecast of a location
+type DailyForecastResponse struct {
+	Provider           string               `json:"provider"`
+	PolygonID          string               `json:"polygon_id,omitempty"`
+	Lat                *float64             `json:"lat,omitempty"`
+	Lon                *float64             `json:"lon,omitempty"`
+	Days               []DailyForecastPoint `json:"days"`
+	TotalPrecipitation float64              `json:"total_precipitation_mm"`
+	DataPointCount     int                  `json:"data_point_count"`
+}
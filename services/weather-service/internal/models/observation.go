@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"agrisa/parameter"
+)
+
+// Observation parameters stored in the weather history, always in the units below
+const (
+	ParamTemperature   = "temperature"   // celsius
+	ParamHumidity      = "humidity"      // percent
+	ParamPressure      = "pressure"      // hPa
+	ParamWindSpeed     = "wind_speed"    // m/s
+	ParamCloudCover    = "cloud_cover"   // percent
+	ParamPrecipitation = "precipitation" // mm
+)
+
+// SharedParameters are the shared parameter names readings are pushed to other services under
+var SharedParameters = map[string]parameter.Name{
+	ParamTemperature:   parameter.Temperature,
+	ParamHumidity:      parameter.Humidity,
+	ParamPressure:      parameter.Pressure,
+	ParamWindSpeed:     parameter.WindSpeed,
+	ParamCloudCover:    parameter.CloudCover,
+	ParamPrecipitation: parameter.RainFall,
+}
+
+var ObservationUnits = map[string]string{
+	ParamTemperature:   string(parameter.UnitCelsius),
+	ParamHumidity:      string(parameter.UnitPercent),
+	ParamPressure:      string(parameter.UnitHectopascal),
+	ParamWindSpeed:     string(parameter.UnitMeterPerSecond),
+	ParamCloudCover:    string(parameter.UnitPercent),
+	ParamPrecipitation: string(parameter.UnitMillimeter),
+}
+
+// WeatherObservation is one reading of a parameter at a location
+type WeatherObservation struct {
+	ID          int64     `json:"id" db:"id"`
+	LocationKey string    `json:"location_key" db:"location_key"`
+	Latitude    *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude   *float64  `json:"longitude,omitempty" db:"longitude"`
+	PolygonID   *string   `json:"polygon_id,omitempty" db:"polygon_id"`
+	StationID   *int64    `json:"station_id,omitempty" db:"station_id"`
+	Parameter   string    `json:"parameter" db:"parameter"`
+	ObservedAt  time.Time `json:"observed_at" db:"observed_at"`
+	Value       float64   `json:"value" db:"value"`
+	Unit        string    `json:"unit" db:"unit"`
+	Source      string    `json:"source" db:"source"`
+	IsForecast  bool      `json:"is_forecast" db:"is_forecast"`
+	// Confidence in the reading from 0 to 1, scored at ingest
+	ConfidenceScore *float64  `json:"confidence_score,omitempty" db:"confidence_score"`
+	IngestedAt      time.Time `json:"ingested_at" db:"ingested_at"`
+}
+
+// HistoryRequest represents the query parameters of the weather history endpoint.
+// A location is either a polygon_id or a lat/lon point.
+type HistoryRequest struct {
+	PolygonID       string   `form:"polygon_id"`
+	Lat             *float64 `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lon             *float64 `form:"lon" binding:"omitempty,min=-180,max=180"`
+	Parameter       string   `form:"parameter" binding:"required"`
+	Start           int64    `form:"start" binding:"required,min=0"`
+	End             int64    `form:"end" binding:"required,min=0"`
+	IncludeForecast bool     `form:"include_forecast"`
+}
+
+// HistoryResponse represents the stored readings of one parameter at one location
+type HistoryResponse struct {
+	LocationKey  string               `json:"location_key"`
+	Parameter    string               `json:"parameter"`
+	Unit         string               `json:"unit"`
+	TimeRange    TimeRange            `json:"time_range"`
+	Observations []WeatherObservation `json:"observations"`
+	Count        int                  `json:"count"`
+}
+
+// AggregateRequest asks for the aggregate of the stored readings of one parameter at one location.
+// A location is either a polygon_id or a lat/lon point.
+type AggregateRequest struct {
+	PolygonID string
+	Lat       *float64
+	Lon       *float64
+	Parameter string
+	Start     int64
+	End       int64
+	Function  string
+}
+
+// AggregateResponse is the aggregate of the stored readings in a time range
+type AggregateResponse struct {
+	LocationKey    string
+	Parameter      string
+	Unit           string
+	Function       string
+	Value          float64
+	ValueCount     int
+	DataPointCount int
+}
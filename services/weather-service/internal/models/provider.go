@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Strategies merging the readings of several providers
+const (
+	// MergeStrategyPriority takes each parameter from the first provider in priority order reporting it
+	MergeStrategyPriority = "priority"
+	// MergeStrategyMedian takes the median of the providers reporting a parameter
+	MergeStrategyMedian = "median"
+)
+
+// ProviderReading is the current conditions a provider reports at a point, by observation
+// parameter in the units of ObservationUnits
+type ProviderReading struct {
+	Source     string             `json:"source"`
+	ObservedAt time.Time          `json:"observed_at"`
+	Values     map[string]float64 `json:"values"`
+}
+
+// MergedValue is the merged value of a parameter and the providers it was taken from
+type MergedValue struct {
+	Value   float64  `json:"value"`
+	Unit    string   `json:"unit"`
+	Sources []string `json:"sources"`
+	// Largest difference between the providers reporting the parameter
+	Spread float64 `json:"spread"`
+}
+
+type MergedWeatherRequest struct {
+	Lat      *float64 `form:"lat" binding:"required,min=-90,max=90"`
+	Lon      *float64 `form:"lon" binding:"required,min=-180,max=180"`
+	Strategy string   `form:"strategy" binding:"omitempty,oneof=priority median"`
+}
+
+// MergedWeatherResponse is the current conditions at a point merged over the available providers
+type MergedWeatherResponse struct {
+	Lat      float64                `json:"lat"`
+	Lon      float64                `json:"lon"`
+	Strategy string                 `json:"strategy"`
+	Values   map[string]MergedValue `json:"values"`
+	Readings []ProviderReading      `json:"readings"`
+	// Providers that were skipped or failed, with the reason
+	Unavailable map[string]string `json:"unavailable,omitempty"`
+}
+
+// ProviderHealth is the recent record of a provider. A provider failing repeatedly is skipped
+// until SkippedUntil, then tried again.
+type ProviderHealth struct {
+	Name                string     `json:"name"`
+	Priority            int        `json:"priority"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	SkippedUntil        *time.Time `json:"skipped_until,omitempty"`
+	// Since the service started
+	Calls        int64   `json:"calls"`
+	Failures     int64   `json:"failures"`
+	AvgLatencyMS float64 `json:"avg_latency_ms"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Factors of a confidence score
+const (
+	QualityProvider  = "provider_accuracy"
+	QualityStation   = "station_distance"
+	QualityFreshness = "freshness"
+	QualityAgreement = "cross_provider_agreement"
+)
+
+// QualityInput describes a reading to score
+type QualityInput struct {
+	Source      string
+	Parameter   string
+	LocationKey string
+	ObservedAt  time.Time
+	Value       float64
+	IsForecast  bool
+	// Location of the reading, or the centre of its polygon; without it stations are not assessed
+	Lat *float64
+	Lon *float64
+}
+
+// QualityScore is the confidence in a reading from 0 to 1 and the factors it was built from
+type QualityScore struct {
+	Score   float64            `json:"score"`
+	Factors map[string]float64 `json:"factors"`
+}
@@ -0,0 +1,49 @@
+package models
+
+// Aggregation functions, the same as the trigger condition functions of policy-service
+const (
+	AggregationSum    = "sum"
+	AggregationAvg    = "avg"
+	AggregationMin    = "min"
+	AggregationMax    = "max"
+	AggregationChange = "change"
+)
+
+var AggregationFunctions = map[string]bool{
+	AggregationSum:    true,
+	AggregationAvg:    true,
+	AggregationMin:    true,
+	AggregationMax:    true,
+	AggregationChange: true,
+}
+
+// RainfallAccumulationRequest represents the query parameters of the rainfall accumulation endpoint.
+// Windows is a comma-separated list of window lengths in days ending at End (default now);
+// CoverageStart clamps the windows the way a policy's coverage start does.
+type RainfallAccumulationRequest struct {
+	PolygonID     string   `form:"polygon_id"`
+	Lat           *float64 `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lon           *float64 `form:"lon" binding:"omitempty,min=-180,max=180"`
+	Windows       string   `form:"windows"`
+	Function      string   `form:"function"`
+	End           int64    `form:"end" binding:"omitempty,min=0"`
+	CoverageStart int64    `form:"coverage_start" binding:"omitempty,min=0"`
+}
+
+// RainfallWindow is the aggregated daily rainfall over one window
+type RainfallWindow struct {
+	WindowDays     int     `json:"window_days"`
+	Start          int64   `json:"start"` // Unix timestamp, after clamping to the coverage start
+	End            int64   `json:"end"`   // Unix timestamp
+	Value          float64 `json:"value"`
+	DayCount       int     `json:"day_count"`
+	DataPointCount int     `json:"data_point_count"`
+}
+
+// RainfallAccumulationResponse represents the rainfall windows of one location
+type RainfallAccumulationResponse struct {
+	LocationKey string           `json:"location_key"`
+	Function    string           `json:"function"`
+	Unit        string           `json:"unit"`
+	Windows     []RainfallWindow `json:"windows"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WeatherStation is a station that produces readings
+type WeatherStation struct {
+	ID          int64          `json:"id" db:"id"`
+	Code        string         `json:"code" db:"code"`
+	Name        string         `json:"name" db:"name"`
+	Operator    string         `json:"operator" db:"operator"`
+	Latitude    float64        `json:"latitude" db:"latitude"`
+	Longitude   float64        `json:"longitude" db:"longitude"`
+	ElevationM  *float64       `json:"elevation_m,omitempty" db:"elevation_m"`
+	Parameters  pq.StringArray `json:"parameters" db:"parameters"`
+	Reliability float64        `json:"reliability" db:"reliability"`
+	IsActive    bool           `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// NearbyStation is a station with its distance from the queried point
+type NearbyStation struct {
+	WeatherStation
+	DistanceKM float64 `json:"distance_km" db:"distance_km"`
+}
+
+// StationRequest represents the body of a station create or update
+type StationRequest struct {
+	Code        string   `json:"code" binding:"required,max=50"`
+	Name        string   `json:"name" binding:"required,max=255"`
+	Operator    string   `json:"operator" binding:"required,max=255"`
+	Latitude    float64  `json:"latitude" binding:"min=-90,max=90"`
+	Longitude   float64  `json:"longitude" binding:"min=-180,max=180"`
+	ElevationM  *float64 `json:"elevation_m"`
+	Parameters  []string `json:"parameters" binding:"required,min=1"`
+	Reliability *float64 `json:"reliability" binding:"omitempty,min=0,max=1"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// NearestStationRequest represents the query parameters of the nearest station lookup
+type NearestStationRequest struct {
+	Lat            *float64 `form:"lat" binding:"required,min=-90,max=90"`
+	Lon            *float64 `form:"lon" binding:"required,min=-180,max=180"`
+	Parameter      string   `form:"parameter"`
+	MinReliability float64  `form:"min_reliability" binding:"omitempty,min=0,max=1"`
+	MaxDistanceKM  float64  `form:"max_distance_km" binding:"omitempty,gt=0"`
+	Limit          int      `form:"limit" binding:"omitempty,min=1,max=50"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Condition operators of a webhook, with the symbols of policy-service trigger conditions
+const (
+	WebhookOperatorLT  = "<"
+	WebhookOperatorLTE = "<="
+	WebhookOperatorGT  = ">"
+	WebhookOperatorGTE = ">="
+)
+
+var WebhookOperators = map[string]bool{
+	WebhookOperatorLT:  true,
+	WebhookOperatorLTE: true,
+	WebhookOperatorGT:  true,
+	WebhookOperatorGTE: true,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WeatherWebhook calls an external consumer when a reading at its location meets its condition
+type WeatherWebhook struct {
+	ID          int64     `json:"id" db:"id"`
+	OwnerID     string    `json:"owner_id" db:"owner_id"`
+	Name        string    `json:"name" db:"name"`
+	URL         string    `json:"url" db:"url"`
+	Secret      string    `json:"-" db:"secret"`
+	LocationKey string    `json:"location_key" db:"location_key"`
+	PolygonID   *string   `json:"polygon_id,omitempty" db:"polygon_id"`
+	Latitude    *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude   *float64  `json:"longitude,omitempty" db:"longitude"`
+	Parameter   string    `json:"parameter" db:"parameter"`
+	Operator    string    `json:"operator" db:"operator"`
+	Threshold   float64   `json:"threshold" db:"threshold"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Matches reports whether a reading meets the webhook condition
+func (w *WeatherWebhook) Matches(value float64) bool {
+	switch w.Operator {
+	case WebhookOperatorLT:
+		return value < w.Threshold
+	case WebhookOperatorLTE:
+		return value <= w.Threshold
+	case WebhookOperatorGT:
+		return value > w.Threshold
+	case WebhookOperatorGTE:
+		return value >= w.Threshold
+	default:
+		return false
+	}
+}
+
+// CreatedWebhook is a new webhook with the secret its payloads are signed with
+type CreatedWebhook struct {
+	WeatherWebhook
+	Secret string `json:"secret"`
+}
+
+// WebhookLocation is a distinct location of active webhooks, polled alongside the farms
+type WebhookLocation struct {
+	LocationKey string   `db:"location_key"`
+	PolygonID   *string  `db:"polygon_id"`
+	Latitude    *float64 `db:"latitude"`
+	Longitude   *float64 `db:"longitude"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to a webhook
+type WebhookDelivery struct {
+	ID             int64           `json:"id" db:"id"`
+	WebhookID      int64           `json:"webhook_id" db:"webhook_id"`
+	EventID        string          `json:"event_id" db:"event_id"`
+	ObservedAt     time.Time       `json:"observed_at" db:"observed_at"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseStatus *int            `json:"response_status,omitempty" db:"response_status"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// WebhookEvent is the payload posted to a webhook
+type WebhookEvent struct {
+	EventID     string           `json:"event_id"`
+	WebhookID   int64            `json:"webhook_id"`
+	WebhookName string           `json:"webhook_name"`
+	LocationKey string           `json:"location_key"`
+	PolygonID   *string          `json:"polygon_id,omitempty"`
+	Latitude    *float64         `json:"latitude,omitempty"`
+	Longitude   *float64         `json:"longitude,omitempty"`
+	Condition   WebhookCondition `json:"condition"`
+	Reading     WebhookReading   `json:"reading"`
+	TriggeredAt int64            `json:"triggered_at"` // Unix timestamp
+}
+
+type WebhookCondition struct {
+	Parameter string  `json:"parameter"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+}
+
+type WebhookReading struct {
+	Value           float64  `json:"value"`
+	Unit            string   `json:"unit"`
+	ObservedAt      int64    `json:"observed_at"` // Unix timestamp
+	Source          string   `json:"source"`
+	ConfidenceScore *float64 `json:"confidence_score,omitempty"`
+}
+
+// CreateWebhookRequest represents the body of a new webhook.
+// A location is either a polygon_id or a latitude/longitude point.
+type CreateWebhookRequest struct {
+	Name      string   `json:"name" binding:"required,max=255"`
+	URL       string   `json:"url" binding:"required,url"`
+	PolygonID *string  `json:"polygon_id"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	Parameter string   `json:"parameter" binding:"required"`
+	Operator  string   `json:"operator" binding:"required"`
+	Threshold *float64 `json:"threshold" binding:"required"`
+}
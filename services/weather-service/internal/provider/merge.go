@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"math"
+	"slices"
+	"weather-service/internal/models"
+)
+
+// Merge combines readings given in priority order into one value per parameter
+func Merge(strategy string, readings []models.ProviderReading) map[string]models.MergedValue {
+	type sourced struct {
+		source string
+		value  float64
+	}
+	byParam := map[string][]sourced{}
+	for _, reading := range readings {
+		for param, value := range reading.Values {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			byParam[param] = append(byParam[param], sourced{reading.Source, value})
+		}
+	}
+
+	merged := make(map[string]models.MergedValue, len(byParam))
+	for param, values := range byParam {
+		low, high := values[0].value, values[0].value
+		for _, v := range values[1:] {
+			low, high = math.Min(low, v.value), math.Max(high, v.value)
+		}
+		result := models.MergedValue{Unit: models.ObservationUnits[param], Spread: high - low}
+
+		switch strategy {
+		case models.MergeStrategyMedian:
+			sorted := make([]float64, len(values))
+			for i, v := range values {
+				sorted[i] = v.value
+				result.Sources = append(result.Sources, v.source)
+			}
+			result.Value = median(sorted)
+		default:
+			// Readings are in priority order, so the first one reporting the parameter wins
+			result.Value = values[0].value
+			result.Sources = []string{values[0].source}
+		}
+		merged[param] = result
+	}
+	return merged
+}
+
+func median(values []float64) float64 {
+	slices.Sort(values)
+	middle := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[middle]
+	}
+	return (values[middle-1] + values[middle]) / 2
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+)
+
+// NationalMet reads the current conditions from the gateway of the national hydro-meteorological
+// service. The gateway answers GET {url}?lat=&lon= with the observation nearest to the point:
+//
+//	{"observed_at": 1735689600, "station_code": "48820",
+//	 "values": {"temperature": 27.5, "humidity": 81, "precipitation": 0.4}}
+//
+// with values named and unitized as the observation parameters of the history.
+type NationalMet struct {
+	url     string
+	apiKey  string
+	limiter *quota.Limiter
+	client  *http.Client
+}
+
+func NewNationalMet(url, apiKey string, limiter *quota.Limiter) *NationalMet {
+	return &NationalMet{
+		url:     url,
+		apiKey:  apiKey,
+		limiter: limiter,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type nationalMetResponse struct {
+	ObservedAt  int64              `json:"observed_at"`
+	StationCode string             `json:"station_code"`
+	Values      map[string]float64 `json:"values"`
+}
+
+func (p *NationalMet) Name() string {
+	return quota.ProviderNationalMet
+}
+
+func (p *NationalMet) Current(ctx context.Context, lat, lon float64) (*models.ProviderReading, error) {
+	if err := p.limiter.Acquire(ctx, quota.ProviderNationalMet); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"lat": {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"lon": {strconv.FormatFloat(lon, 'f', 4, 64)},
+	}
+	header := http.Header{}
+	if p.apiKey != "" {
+		header.Set("X-API-Key", p.apiKey)
+	}
+
+	var response nationalMetResponse
+	if err := getJSON(ctx, p.client, p.url+"?"+query.Encode(), header, &response); err != nil {
+		return nil, err
+	}
+	if response.ObservedAt == 0 {
+		return nil, fmt.Errorf("national met service has no observation near the point")
+	}
+
+	reading := &models.ProviderReading{
+		Source:     quota.ProviderNationalMet,
+		ObservedAt: time.Unix(response.ObservedAt, 0),
+		Values:     map[string]float64{},
+	}
+	// Parameters the history does not know are dropped rather than merged under a wrong unit
+	for param, value := range response.Values {
+		if _, ok := models.ObservationUnits[param]; ok {
+			reading.Values[param] = value
+		}
+	}
+	return reading, nil
+}
+
+// NationalMetWarnings lists the severe weather warnings in force from the gateway of the national
+// met service, which answers GET {url} with {"warnings": [...]} in the shape of
+// models.IngestWarningRequest
+type NationalMetWarnings struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewNationalMetWarnings(url, apiKey string) *NationalMetWarnings {
+	return &NationalMetWarnings{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (f *NationalMetWarnings) ActiveWarnings(ctx context.Context) ([]models.IngestWarningRequest, error) {
+	header := http.Header{}
+	if f.apiKey != "" {
+		header.Set("X-API-Key", f.apiKey)
+	}
+	var response struct {
+		Warnings []models.IngestWarningRequest `json:"warnings"`
+	}
+	if err := getJSON(ctx, f.client, f.url, header, &response); err != nil {
+		return nil, err
+	}
+	return response.Warnings, nil
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+)
+
+// Open-Meteo current variables and the observation parameters they are stored as. Wind is asked
+// in m/s; the other variables are in the observation units already.
+var openMeteoVariables = map[string]string{
+	"temperature_2m":       models.ParamTemperature,
+	"relative_humidity_2m": models.ParamHumidity,
+	"surface_pressure":     models.ParamPressure,
+	"wind_speed_10m":       models.ParamWindSpeed,
+	"cloud_cover":          models.ParamCloudCover,
+	"precipitation":        models.ParamPrecipitation,
+}
+
+// OpenMeteo reads the current conditions from the Open-Meteo forecast API, which needs no key
+type OpenMeteo struct {
+	baseURL string
+	limiter *quota.Limiter
+	client  *http.Client
+}
+
+func NewOpenMeteo(baseURL string, limiter *quota.Limiter) *OpenMeteo {
+	return &OpenMeteo{
+		baseURL: baseURL,
+		limiter: limiter,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type openMeteoResponse struct {
+	Current map[string]any `json:"current"`
+}
+
+func (p *OpenMeteo) Name() string {
+	return quota.ProviderOpenMeteo
+}
+
+func (p *OpenMeteo) Current(ctx context.Context, lat, lon float64) (*models.ProviderReading, error) {
+	if err := p.limiter.Acquire(ctx, quota.ProviderOpenMeteo); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"latitude":        {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"longitude":       {strconv.FormatFloat(lon, 'f', 4, 64)},
+		"wind_speed_unit": {"ms"},
+		"timeformat":      {"unixtime"},
+	}
+	query.Set("current", strings.Join(slices.Sorted(maps.Keys(openMeteoVariables)), ","))
+
+	var response openMeteoResponse
+	if err := getJSON(ctx, p.client, p.baseURL+"/forecast?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+
+	observedAt, ok := response.Current["time"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("open-meteo response has no current time")
+	}
+	reading := &models.ProviderReading{
+		Source:     quota.ProviderOpenMeteo,
+		ObservedAt: time.Unix(int64(observedAt), 0),
+		Values:     map[string]float64{},
+	}
+	for variable, param := range openMeteoVariables {
+		if value, ok := response.Current[variable].(float64); ok {
+			reading.Values[param] = value
+		}
+	}
+	return reading, nil
+}
+
+// getJSON gets a URL and decodes its JSON body, failing on any status but 200
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API 3rd party returned status %d: %.200s", resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
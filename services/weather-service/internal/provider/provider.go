@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+)
+
+const (
+	// Failures in a row after which a provider is skipped for a while
+	failureThreshold = 3
+	// How long a failing provider is skipped before it is tried again
+	failureCooldown = 5 * time.Minute
+)
+
+// WeatherProvider is an upstream source of the current conditions at a point
+type WeatherProvider interface {
+	// Name is the source of the readings, and the quota provider the calls are counted under
+	Name() string
+	Current(ctx context.Context, lat, lon float64) (*models.ProviderReading, error)
+}
+
+type trackedProvider struct {
+	provider WeatherProvider
+
+	mu     sync.Mutex
+	health models.ProviderHealth
+	// Total latency of the calls, for the average
+	latency time.Duration
+}
+
+// Registry holds the providers in priority order and tracks their health. A provider that fails
+// failureThreshold times in a row is skipped for failureCooldown; running out of quota is not a
+// failure, the limiter already keeps such calls from going out.
+type Registry struct {
+	providers []*trackedProvider
+}
+
+func NewRegistry(providers ...WeatherProvider) *Registry {
+	r := &Registry{}
+	for i, p := range providers {
+		r.providers = append(r.providers, &trackedProvider{
+			provider: p,
+			health:   models.ProviderHealth{Name: p.Name(), Priority: i + 1, Healthy: true},
+		})
+	}
+	return r
+}
+
+// Len is the number of providers configured
+func (r *Registry) Len() int {
+	return len(r.providers)
+}
+
+// FetchAll asks every provider that is not being skipped for the current conditions at a point, at
+// once. Readings are returned in priority order, with the reason of the providers that gave none.
+func (r *Registry) FetchAll(ctx context.Context, lat, lon float64) ([]models.ProviderReading, map[string]error) {
+	readings := make([]*models.ProviderReading, len(r.providers))
+	errs := make([]error, len(r.providers))
+
+	var wg sync.WaitGroup
+	for i, tracked := range r.providers {
+		if until, skipped := tracked.skipped(); skipped {
+			errs[i] = fmt.Errorf("skipped after repeated failures until %s", until.Format(time.RFC3339))
+			continue
+		}
+		wg.Add(1)
+		go func(i int, tracked *trackedProvider) {
+			defer wg.Done()
+			started := time.Now()
+			readings[i], errs[i] = tracked.provider.Current(ctx, lat, lon)
+			if errs[i] == nil && readings[i] == nil {
+				errs[i] = fmt.Errorf("no reading")
+			}
+			tracked.record(time.Since(started), errs[i])
+		}(i, tracked)
+	}
+	wg.Wait()
+
+	var result []models.ProviderReading
+	failed := map[string]error{}
+	for i, tracked := range r.providers {
+		if errs[i] != nil {
+			failed[tracked.provider.Name()] = errs[i]
+			continue
+		}
+		result = append(result, *readings[i])
+	}
+	return result, failed
+}
+
+// Health returns the record of every provider in priority order
+func (r *Registry) Health() []models.ProviderHealth {
+	health := make([]models.ProviderHealth, 0, len(r.providers))
+	for _, tracked := range r.providers {
+		tracked.mu.Lock()
+		h := tracked.health
+		if h.Calls > 0 {
+			h.AvgLatencyMS = float64(tracked.latency.Milliseconds()) / float64(h.Calls)
+		}
+		tracked.mu.Unlock()
+		health = append(health, h)
+	}
+	return health
+}
+
+// skipped reports whether the provider is in its cooldown, and until when
+func (t *trackedProvider) skipped() (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.health.SkippedUntil == nil || time.Now().After(*t.health.SkippedUntil) {
+		return time.Time{}, false
+	}
+	return *t.health.SkippedUntil, true
+}
+
+func (t *trackedProvider) record(latency time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.health.Calls++
+	t.latency += latency
+	if err == nil {
+		t.health.Healthy = true
+		t.health.ConsecutiveFailures = 0
+		t.health.SkippedUntil = nil
+		t.health.LastSuccessAt = &now
+		return
+	}
+
+	t.health.LastError = err.Error()
+	t.health.LastErrorAt = &now
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return
+	}
+	t.health.Failures++
+	t.health.ConsecutiveFailures++
+	if t.health.ConsecutiveFailures >= failureThreshold {
+		until := now.Add(failureCooldown)
+		t.health.Healthy = false
+		t.health.SkippedUntil = &until
+		log.Printf("Weather provider %s failed %d times in a row, skipping it until %s: %v",
+			t.health.Name, t.health.ConsecutiveFailures, until.Format(time.RFC3339), err)
+	}
+}
@@ -0,0 +1,238 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Upstream providers with a call budget
+const (
+	ProviderOneCall     = "openweathermap_onecall"
+	ProviderAgro        = "agro"
+	ProviderStatistics  = "openweathermap_statistics"
+	ProviderAirQuality  = "openweathermap_air_pollution"
+	ProviderOpenMeteo   = "open_meteo"
+	ProviderNationalMet = "national_met"
+)
+
+const keyPrefix = "weather-quota"
+
+// ErrQuotaExceeded is returned when a call would go over a provider budget
+var ErrQuotaExceeded = errors.New("provider quota exceeded")
+
+// Budget caps the calls to a provider per minute and per UTC day, the windows providers bill
+// by. A zero cap is unlimited.
+type Budget struct {
+	PerMinute int
+	PerDay    int
+}
+
+type counters struct {
+	calls       atomic.Int64
+	rejected    atomic.Int64
+	staleServed atomic.Int64
+}
+
+type localCount struct {
+	count   int64
+	expires time.Time
+}
+
+// Metrics are the budget consumption of one provider
+type Metrics struct {
+	MinuteLimit    int   `json:"minute_limit"`
+	MinuteUsed     int64 `json:"minute_used"`
+	DailyLimit     int   `json:"daily_limit"`
+	DailyUsed      int64 `json:"daily_used"`
+	DailyRemaining int64 `json:"daily_remaining"`
+	NearExhaustion bool  `json:"near_exhaustion"`
+	// Since the service started
+	Calls       int64 `json:"calls"`
+	Rejected    int64 `json:"rejected"`
+	StaleServed int64 `json:"stale_served"`
+}
+
+// Limiter enforces provider budgets. Usage is counted in Redis so that every replica draws on the
+// same budget, and in memory while Redis is unavailable. A nil *Limiter allows every call.
+type Limiter struct {
+	client         *redis.Client
+	budgets        map[string]Budget
+	reservePercent int
+	counters       map[string]*counters
+
+	mu    sync.Mutex
+	local map[string]*localCount
+}
+
+// NewLimiter creates a limiter for the given budgets. Once less than reservePercent of a daily
+// budget is left the provider is near exhaustion, and callers should prefer cached data.
+func NewLimiter(client *redis.Client, budgets map[string]Budget, reservePercent int) *Limiter {
+	l := &Limiter{
+		client:         client,
+		budgets:        budgets,
+		reservePercent: reservePercent,
+		counters:       make(map[string]*counters, len(budgets)),
+		local:          map[string]*localCount{},
+	}
+	for provider := range budgets {
+		l.counters[provider] = &counters{}
+	}
+	return l
+}
+
+func windowKeys(provider string, now time.Time) (string, string) {
+	minute := fmt.Sprintf("%s:%s:minute:%s", keyPrefix, provider, strconv.FormatInt(now.Unix()/60, 10))
+	day := fmt.Sprintf("%s:%s:day:%s", keyPrefix, provider, now.UTC().Format("2006-01-02"))
+	return minute, day
+}
+
+// Acquire reserves one call to provider, failing with ErrQuotaExceeded when a budget is spent
+func (l *Limiter) Acquire(ctx context.Context, provider string) error {
+	if l == nil {
+		return nil
+	}
+	budget, ok := l.budgets[provider]
+	if !ok {
+		return nil
+	}
+	counter := l.counters[provider]
+	minuteKey, dayKey := windowKeys(provider, time.Now())
+
+	if budget.PerMinute > 0 {
+		if used := l.incr(ctx, minuteKey, 2*time.Minute); used > int64(budget.PerMinute) {
+			l.decr(ctx, minuteKey)
+			counter.rejected.Add(1)
+			return fmt.Errorf("%w: %s allows %d calls per minute", ErrQuotaExceeded, provider, budget.PerMinute)
+		}
+	}
+	if budget.PerDay > 0 {
+		if used := l.incr(ctx, dayKey, 48*time.Hour); used > int64(budget.PerDay) {
+			l.decr(ctx, dayKey)
+			if budget.PerMinute > 0 {
+				l.decr(ctx, minuteKey)
+			}
+			counter.rejected.Add(1)
+			return fmt.Errorf("%w: %s allows %d calls per day", ErrQuotaExceeded, provider, budget.PerDay)
+		}
+	}
+	counter.calls.Add(1)
+	return nil
+}
+
+// NearExhaustion reports whether less than the reserve of the provider's daily budget is left
+func (l *Limiter) NearExhaustion(ctx context.Context, provider string) bool {
+	if l == nil {
+		return false
+	}
+	budget := l.budgets[provider]
+	if budget.PerDay <= 0 {
+		return false
+	}
+	_, dayKey := windowKeys(provider, time.Now())
+	threshold := int64(budget.PerDay) * int64(100-l.reservePercent) / 100
+	return l.get(ctx, dayKey) >= threshold
+}
+
+// RecordStaleServed counts a response served from the stale cache to spare the provider budget
+func (l *Limiter) RecordStaleServed(provider string) {
+	if l == nil {
+		return
+	}
+	if counter, ok := l.counters[provider]; ok {
+		counter.staleServed.Add(1)
+	}
+}
+
+// Metrics returns the budget consumption of every provider
+func (l *Limiter) Metrics(ctx context.Context) map[string]Metrics {
+	if l == nil {
+		return map[string]Metrics{}
+	}
+	result := make(map[string]Metrics, len(l.budgets))
+	for provider, budget := range l.budgets {
+		minuteKey, dayKey := windowKeys(provider, time.Now())
+		counter := l.counters[provider]
+		m := Metrics{
+			MinuteLimit:    budget.PerMinute,
+			MinuteUsed:     l.get(ctx, minuteKey),
+			DailyLimit:     budget.PerDay,
+			DailyUsed:      l.get(ctx, dayKey),
+			NearExhaustion: l.NearExhaustion(ctx, provider),
+			Calls:          counter.calls.Load(),
+			Rejected:       counter.rejected.Load(),
+			StaleServed:    counter.staleServed.Load(),
+		}
+		if budget.PerDay > 0 {
+			m.DailyRemaining = max(int64(budget.PerDay)-m.DailyUsed, 0)
+		}
+		result[provider] = m
+	}
+	return result
+}
+
+func (l *Limiter) incr(ctx context.Context, key string, ttl time.Duration) int64 {
+	if l.client != nil {
+		var incr *redis.IntCmd
+		_, err := l.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			incr = pipe.Incr(ctx, key)
+			pipe.Expire(ctx, key, ttl)
+			return nil
+		})
+		if err == nil {
+			return incr.Val()
+		}
+		log.Printf("Quota counter %s unavailable, counting locally: %v", key, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for k, entry := range l.local {
+		if now.After(entry.expires) {
+			delete(l.local, k)
+		}
+	}
+	entry, ok := l.local[key]
+	if !ok {
+		entry = &localCount{expires: now.Add(ttl)}
+		l.local[key] = entry
+	}
+	entry.count++
+	return entry.count
+}
+
+func (l *Limiter) decr(ctx context.Context, key string) {
+	if l.client != nil {
+		if err := l.client.Decr(ctx, key).Err(); err == nil {
+			return
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.local[key]; ok && entry.count > 0 {
+		entry.count--
+	}
+}
+
+func (l *Limiter) get(ctx context.Context, key string) int64 {
+	if l.client != nil {
+		value, err := l.client.Get(ctx, key).Int64()
+		if err == nil || errors.Is(err, redis.Nil) {
+			return value
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.local[key]; ok && time.Now().Before(entry.expires) {
+		return entry.count
+	}
+	return 0
+}
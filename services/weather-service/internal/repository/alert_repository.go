@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"weather-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IAlertRepository interface {
+	CreateSubscription(subscription *models.WeatherAlertSubscription) error
+	GetSubscriptionByID(id int64) (*models.WeatherAlertSubscription, error)
+	GetSubscriptionsByUser(userID string) ([]models.WeatherAlertSubscription, error)
+	GetActiveSubscriptions() ([]models.WeatherAlertSubscription, error)
+	DeleteSubscription(id int64) error
+	// RecordAlert stores an alert and reports false when it was already raised
+	RecordAlert(alert *models.WeatherAlert) (bool, error)
+	DeleteAlerts(ids []int64) error
+	GetAlertsBySubscription(subscriptionID int64, limit int) ([]models.WeatherAlert, error)
+	// UpsertWarning stores an official warning, updating it when the source issued it before
+	UpsertWarning(warning *models.WeatherWarning) error
+	GetActiveWarnings() ([]models.WeatherWarning, error)
+	// GetSubscriptionsForWarning returns the active subscriptions to an event type in the warned
+	// provinces or districts, directly or through the insured farm they are for
+	GetSubscriptionsForWarning(warning *models.WeatherWarning) ([]models.WeatherAlertSubscription, error)
+	// RecordWarningDeliveries records the subscriptions a warning is pushed to and returns those it
+	// was not pushed to before
+	RecordWarningDeliveries(warningID int64, subscriptionIDs []int64) ([]int64, error)
+	DeleteWarningDeliveries(warningID int64, subscriptionIDs []int64) error
+	// GetInsuredFarmIDs returns the active insured farms in the given provinces
+	GetInsuredFarmIDs(provinceCodes []string) ([]string, error)
+}
+
+type AlertRepository struct {
+	db *sqlx.DB
+}
+
+func NewAlertRepository(db *sqlx.DB) IAlertRepository {
+	return &AlertRepository{db: db}
+}
+
+const alertSubscriptionColumns = `id, user_id, farm_id, region, polygon_id, latitude, longitude, province_code,
+	district_code, event_types, heavy_rain_mm, heatwave_celsius, typhoon_wind_ms, is_active, created_at, updated_at`
+
+func (r *AlertRepository) CreateSubscription(subscription *models.WeatherAlertSubscription) error {
+	query := `
+		INSERT INTO weather_alert_subscriptions (
+			user_id, farm_id, region, polygon_id, latitude, longitude, province_code, district_code,
+			event_types, heavy_rain_mm, heatwave_celsius, typhoon_wind_ms
+		) VALUES (
+			:user_id, :farm_id, :region, :polygon_id, :latitude, :longitude, :province_code, :district_code,
+			:event_types, :heavy_rain_mm, :heatwave_celsius, :typhoon_wind_ms
+		)
+		RETURNING id, is_active, created_at, updated_at`
+
+	rows, err := r.db.NamedQuery(query, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to create alert subscription: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&subscription.ID, &subscription.IsActive, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to read created alert subscription: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *AlertRepository) GetSubscriptionByID(id int64) (*models.WeatherAlertSubscription, error) {
+	var subscription models.WeatherAlertSubscription
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM weather_alert_subscriptions WHERE id = $1`
+	if err := r.db.Get(&subscription, query, id); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *AlertRepository) GetSubscriptionsByUser(userID string) ([]models.WeatherAlertSubscription, error) {
+	subscriptions := []models.WeatherAlertSubscription{}
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM weather_alert_subscriptions WHERE user_id = $1 ORDER BY created_at DESC`
+	if err := r.db.Select(&subscriptions, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get alert subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *AlertRepository) GetActiveSubscriptions() ([]models.WeatherAlertSubscription, error) {
+	subscriptions := []models.WeatherAlertSubscription{}
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM weather_alert_subscriptions WHERE is_active ORDER BY id`
+	if err := r.db.Select(&subscriptions, query); err != nil {
+		return nil, fmt.Errorf("failed to get active alert subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *AlertRepository) DeleteSubscription(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM weather_alert_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert subscription: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *AlertRepository) RecordAlert(alert *models.WeatherAlert) (bool, error) {
+	query := `
+		INSERT INTO weather_alerts (subscription_id, event_type, forecast_date, value, threshold, provider)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (subscription_id, event_type, forecast_date) DO NOTHING
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowx(query, alert.SubscriptionID, alert.EventType, alert.ForecastDate,
+		alert.Value, alert.Threshold, alert.Provider).Scan(&alert.ID, &alert.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record weather alert: %w", err)
+	}
+	return true, nil
+}
+
+func (r *AlertRepository) DeleteAlerts(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := r.db.Exec(`DELETE FROM weather_alerts WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to delete weather alerts: %w", err)
+	}
+	return nil
+}
+
+func (r *AlertRepository) GetAlertsBySubscription(subscriptionID int64, limit int) ([]models.WeatherAlert, error) {
+	alerts := []models.WeatherAlert{}
+	query := `
+		SELECT id, subscription_id, event_type, forecast_date, value, threshold, provider, created_at
+		FROM weather_alerts
+		WHERE subscription_id = $1
+		ORDER BY forecast_date DESC, id DESC
+		LIMIT $2`
+	if err := r.db.Select(&alerts, query, subscriptionID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get weather alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (r *AlertRepository) UpsertWarning(warning *models.WeatherWarning) error {
+	query := `
+		INSERT INTO weather_warnings (
+			source, external_id, event_type, severity, title, description,
+			province_codes, district_codes, issued_at, expires_at
+		) VALUES (
+			:source, :external_id, :event_type, :severity, :title, :description,
+			:province_codes, :district_codes, :issued_at, :expires_at
+		)
+		ON CONFLICT (source, external_id) DO UPDATE SET
+			event_type = EXCLUDED.event_type,
+			severity = EXCLUDED.severity,
+			title = EXCLUDED.title,
+			description = EXCLUDED.description,
+			province_codes = EXCLUDED.province_codes,
+			district_codes = EXCLUDED.district_codes,
+			issued_at = EXCLUDED.issued_at,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	rows, err := r.db.NamedQuery(query, warning)
+	if err != nil {
+		return fmt.Errorf("failed to store weather warning: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&warning.ID, &warning.CreatedAt, &warning.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to read stored weather warning: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *AlertRepository) GetActiveWarnings() ([]models.WeatherWarning, error) {
+	warnings := []models.WeatherWarning{}
+	query := `
+		SELECT w.id, w.source, w.external_id, w.event_type, w.severity, w.title, w.description,
+			w.province_codes, w.district_codes, w.issued_at, w.expires_at, w.created_at, w.updated_at,
+			(SELECT COUNT(*) FROM farm_locations f WHERE f.is_active AND f.province = ANY(w.province_codes)) AS insured_farm_count
+		FROM weather_warnings w
+		WHERE w.expires_at > NOW()
+		ORDER BY w.issued_at DESC`
+	if err := r.db.Select(&warnings, query); err != nil {
+		return nil, fmt.Errorf("failed to get active weather warnings: %w", err)
+	}
+	return warnings, nil
+}
+
+func (r *AlertRepository) GetSubscriptionsForWarning(warning *models.WeatherWarning) ([]models.WeatherAlertSubscription, error) {
+	subscriptions := []models.WeatherAlertSubscription{}
+	query := `
+		SELECT ` + alertSubscriptionColumns + `
+		FROM weather_alert_subscriptions s
+		WHERE s.is_active AND $1 = ANY(s.event_types)
+			AND (s.province_code = ANY($2) OR s.district_code = ANY($3)
+				OR s.farm_id IN (SELECT farm_id FROM farm_locations WHERE is_active AND province = ANY($2)))
+		ORDER BY s.id`
+	if err := r.db.Select(&subscriptions, query, warning.EventType, warning.ProvinceCodes, warning.DistrictCodes); err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions for weather warning: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *AlertRepository) RecordWarningDeliveries(warningID int64, subscriptionIDs []int64) ([]int64, error) {
+	created := []int64{}
+	if len(subscriptionIDs) == 0 {
+		return created, nil
+	}
+	query := `
+		INSERT INTO weather_warning_deliveries (warning_id, subscription_id)
+		SELECT $1, subscription_id FROM UNNEST($2::BIGINT[]) AS subscription_id
+		ON CONFLICT DO NOTHING
+		RETURNING subscription_id`
+	if err := r.db.Select(&created, query, warningID, pq.Array(subscriptionIDs)); err != nil {
+		return nil, fmt.Errorf("failed to record weather warning deliveries: %w", err)
+	}
+	return created, nil
+}
+
+func (r *AlertRepository) DeleteWarningDeliveries(warningID int64, subscriptionIDs []int64) error {
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+	query := `DELETE FROM weather_warning_deliveries WHERE warning_id = $1 AND subscription_id = ANY($2)`
+	if _, err := r.db.Exec(query, warningID, pq.Array(subscriptionIDs)); err != nil {
+		return fmt.Errorf("failed to delete weather warning deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *AlertRepository) GetInsuredFarmIDs(provinceCodes []string) ([]string, error) {
+	farmIDs := []string{}
+	if len(provinceCodes) == 0 {
+		return farmIDs, nil
+	}
+	query := `SELECT farm_id FROM farm_locations WHERE is_active AND province = ANY($1) ORDER BY farm_id`
+	if err := r.db.Select(&farmIDs, query, pq.Array(provinceCodes)); err != nil {
+		return nil, fmt.Errorf("failed to get insured farms: %w", err)
+	}
+	return farmIDs, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"weather-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IFarmLocationRepository interface {
+	// SyncLocations upserts the given farms as active and deactivates every other farm
+	SyncLocations(locations []models.InsuredFarmLocation) error
+	GetActiveLocations() ([]models.FarmLocation, error)
+	MarkPolled(farmID, feed string, polledAt time.Time) error
+}
+
+type FarmLocationRepository struct {
+	db *sqlx.DB
+}
+
+func NewFarmLocationRepository(db *sqlx.DB) IFarmLocationRepository {
+	return &FarmLocationRepository{db: db}
+}
+
+func (r *FarmLocationRepository) SyncLocations(locations []models.InsuredFarmLocation) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin farm location sync: %w", err)
+	}
+	defer tx.Rollback()
+
+	farmIDs := make([]string, 0, len(locations))
+	for _, location := range locations {
+		_, err := tx.Exec(`
+			INSERT INTO farm_locations (farm_id, polygon_id, province, latitude, longitude, is_active, synced_at)
+			VALUES ($1, $2, $3, $4, $5, TRUE, NOW())
+			ON CONFLICT (farm_id) DO UPDATE
+			SET polygon_id = EXCLUDED.polygon_id, province = EXCLUDED.province,
+				latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+				is_active = TRUE, synced_at = NOW()`,
+			location.FarmID, location.AgroPolygonID, location.Province, location.Latitude, location.Longitude)
+		if err != nil {
+			return fmt.Errorf("failed to upsert farm location %s: %w", location.FarmID, err)
+		}
+		farmIDs = append(farmIDs, location.FarmID)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE farm_locations SET is_active = FALSE, synced_at = NOW()
+		WHERE is_active AND NOT (farm_id = ANY($1))`, pq.Array(farmIDs))
+	if err != nil {
+		return fmt.Errorf("failed to deactivate farm locations: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *FarmLocationRepository) GetActiveLocations() ([]models.FarmLocation, error) {
+	locations := []models.FarmLocation{}
+	query := `
+		SELECT farm_id, polygon_id, province, latitude, longitude, is_active,
+			last_current_poll_at, last_forecast_poll_at, synced_at
+		FROM farm_locations
+		WHERE is_active
+		ORDER BY farm_id`
+	if err := r.db.Select(&locations, query); err != nil {
+		return nil, fmt.Errorf("failed to get farm locations: %w", err)
+	}
+	return locations, nil
+}
+
+func (r *FarmLocationRepository) MarkPolled(farmID, feed string, polledAt time.Time) error {
+	column := map[string]string{
+		models.FeedCurrent:  "last_current_poll_at",
+		models.FeedForecast: "last_forecast_poll_at",
+	}[feed]
+	if column == "" {
+		return fmt.Errorf("unknown polling feed: %s", feed)
+	}
+	if _, err := r.db.Exec(`UPDATE farm_locations SET `+column+` = $1 WHERE farm_id = $2`, polledAt, farmID); err != nil {
+		return fmt.Errorf("failed to mark farm location %s polled: %w", farmID, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"weather-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IObservationRepository interface {
+	InsertObservations(observations []models.WeatherObservation) (int64, error)
+	GetObservations(locationKey, parameter string, start, end time.Time, includeForecast bool) ([]models.WeatherObservation, error)
+	DeleteOlderThan(isForecast bool, before time.Time) (int64, error)
+}
+
+type ObservationRepository struct {
+	db *sqlx.DB
+}
+
+func NewObservationRepository(db *sqlx.DB) IObservationRepository {
+	return &ObservationRepository{db: db}
+}
+
+// InsertObservations stores a batch of readings. Observed values already stored are kept as
+// they were; forecasts are replaced by the newer forecast for the same time.
+func (r *ObservationRepository) InsertObservations(observations []models.WeatherObservation) (int64, error) {
+	if len(observations) == 0 {
+		return 0, nil
+	}
+	query := `
+		INSERT INTO weather_observations (
+			location_key, latitude, longitude, polygon_id, station_id, parameter,
+			observed_at, value, unit, source, is_forecast, confidence_score
+		) VALUES (
+			:location_key, :latitude, :longitude, :polygon_id, :station_id, :parameter,
+			:observed_at, :value, :unit, :source, :is_forecast, :confidence_score
+		)
+		ON CONFLICT (location_key, parameter, observed_at, is_forecast) DO UPDATE
+		SET value = EXCLUDED.value, source = EXCLUDED.source,
+			confidence_score = EXCLUDED.confidence_score, ingested_at = NOW()
+		WHERE weather_observations.is_forecast AND weather_observations.value <> EXCLUDED.value`
+
+	result, err := r.db.NamedExec(query, observations)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert weather observations: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *ObservationRepository) GetObservations(locationKey, parameter string, start, end time.Time, includeForecast bool) ([]models.WeatherObservation, error) {
+	observations := []models.WeatherObservation{}
+	query := `
+		SELECT id, location_key, latitude, longitude, polygon_id, station_id, parameter,
+			observed_at, value, unit, source, is_forecast, confidence_score, ingested_at
+		FROM weather_observations
+		WHERE location_key = $1
+			AND parameter = $2
+			AND observed_at >= $3
+			AND observed_at <= $4
+			AND (NOT is_forecast OR $5)
+		ORDER BY observed_at, is_forecast`
+	if err := r.db.Select(&observations, query, locationKey, parameter, start, end, includeForecast); err != nil {
+		return nil, fmt.Errorf("failed to get weather observations: %w", err)
+	}
+	return observations, nil
+}
+
+func (r *ObservationRepository) DeleteOlderThan(isForecast bool, before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM weather_observations WHERE is_forecast = $1 AND observed_at < $2`, isForecast, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old weather observations: %w", err)
+	}
+	return result.RowsAffected()
+}
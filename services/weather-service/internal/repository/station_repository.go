@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"weather-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IStationRepository interface {
+	CreateStation(station *models.WeatherStation) error
+	UpdateStation(station *models.WeatherStation) error
+	DeleteStation(id int64) error
+	GetStationByID(id int64) (*models.WeatherStation, error)
+	GetStations(activeOnly bool) ([]models.WeatherStation, error)
+	GetNearestStations(req models.NearestStationRequest) ([]models.NearbyStation, error)
+}
+
+type StationRepository struct {
+	db *sqlx.DB
+}
+
+func NewStationRepository(db *sqlx.DB) IStationRepository {
+	return &StationRepository{db: db}
+}
+
+const stationColumns = `id, code, name, operator, latitude, longitude, elevation_m, parameters,
+	reliability, is_active, created_at, updated_at`
+
+// uniqueViolation is the Postgres error code of a duplicate key
+const uniqueViolation = "23505"
+
+func stationWriteError(action string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("duplicate station code")
+	}
+	return fmt.Errorf("failed to %s weather station: %w", action, err)
+}
+
+func (r *StationRepository) CreateStation(station *models.WeatherStation) error {
+	query := `
+		INSERT INTO weather_stations (
+			code, name, operator, latitude, longitude, elevation_m, parameters, reliability, is_active
+		) VALUES (
+			:code, :name, :operator, :latitude, :longitude, :elevation_m, :parameters, :reliability, :is_active
+		)
+		RETURNING id, created_at, updated_at`
+
+	rows, err := r.db.NamedQuery(query, station)
+	if err != nil {
+		return stationWriteError("create", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&station.ID, &station.CreatedAt, &station.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to read created weather station: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *StationRepository) UpdateStation(station *models.WeatherStation) error {
+	query := `
+		UPDATE weather_stations
+		SET code = :code, name = :name, operator = :operator, latitude = :latitude, longitude = :longitude,
+			elevation_m = :elevation_m, parameters = :parameters, reliability = :reliability,
+			is_active = :is_active, updated_at = NOW()
+		WHERE id = :id`
+
+	result, err := r.db.NamedExec(query, station)
+	if err != nil {
+		return stationWriteError("update", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *StationRepository) DeleteStation(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM weather_stations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete weather station: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *StationRepository) GetStationByID(id int64) (*models.WeatherStation, error) {
+	var station models.WeatherStation
+	if err := r.db.Get(&station, `SELECT `+stationColumns+` FROM weather_stations WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+func (r *StationRepository) GetStations(activeOnly bool) ([]models.WeatherStation, error) {
+	stations := []models.WeatherStation{}
+	query := `SELECT ` + stationColumns + ` FROM weather_stations WHERE is_active OR NOT $1 ORDER BY code`
+	if err := r.db.Select(&stations, query, activeOnly); err != nil {
+		return nil, fmt.Errorf("failed to get weather stations: %w", err)
+	}
+	return stations, nil
+}
+
+// GetNearestStations returns active stations by great-circle distance. Ties go to the more
+// reliable and then the older station, so a farm always maps to the same station.
+func (r *StationRepository) GetNearestStations(req models.NearestStationRequest) ([]models.NearbyStation, error) {
+	stations := []models.NearbyStation{}
+	query := `
+		SELECT * FROM (
+			SELECT ` + stationColumns + `,
+				6371 * 2 * ASIN(SQRT(
+					POWER(SIN(RADIANS(latitude - $1) / 2), 2) +
+					COS(RADIANS($1)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - $2) / 2), 2)
+				)) AS distance_km
+			FROM weather_stations
+			WHERE is_active
+				AND ($3 = '' OR $3 = ANY(parameters))
+				AND reliability >= $4
+		) nearby
+		WHERE $5 = 0 OR distance_km <= $5
+		ORDER BY distance_km, reliability DESC, id
+		LIMIT $6`
+	if err := r.db.Select(&stations, query, *req.Lat, *req.Lon, req.Parameter, req.MinReliability, req.MaxDistanceKM, req.Limit); err != nil {
+		return nil, fmt.Errorf("failed to get nearest weather stations: %w", err)
+	}
+	return stations, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"weather-service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IWebhookRepository interface {
+	CreateWebhook(webhook *models.WeatherWebhook) error
+	GetWebhookByID(id int64) (*models.WeatherWebhook, error)
+	GetWebhooksByOwner(ownerID string) ([]models.WeatherWebhook, error)
+	GetActiveWebhooksByLocation(locationKey string) ([]models.WeatherWebhook, error)
+	GetActiveLocations() ([]models.WebhookLocation, error)
+	DeleteWebhook(id int64) error
+	// CreateDelivery stores a delivery and reports false when the reading was already delivered
+	CreateDelivery(delivery *models.WebhookDelivery) (bool, error)
+	GetDueDeliveries(limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	GetDeliveriesByWebhook(webhookID int64, limit int) ([]models.WebhookDelivery, error)
+}
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) IWebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookColumns = `id, owner_id, name, url, secret, location_key, polygon_id, latitude, longitude,
+	parameter, operator, threshold, is_active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, observed_at, payload, status, attempts, next_attempt_at,
+	response_status, last_error, created_at, delivered_at`
+
+func (r *WebhookRepository) CreateWebhook(webhook *models.WeatherWebhook) error {
+	query := `
+		INSERT INTO weather_webhooks (
+			owner_id, name, url, secret, location_key, polygon_id, latitude, longitude,
+			parameter, operator, threshold
+		) VALUES (
+			:owner_id, :name, :url, :secret, :location_key, :polygon_id, :latitude, :longitude,
+			:parameter, :operator, :threshold
+		)
+		RETURNING id, is_active, created_at, updated_at`
+
+	rows, err := r.db.NamedQuery(query, webhook)
+	if err != nil {
+		return fmt.Errorf("failed to create weather webhook: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&webhook.ID, &webhook.IsActive, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to read created weather webhook: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *WebhookRepository) GetWebhookByID(id int64) (*models.WeatherWebhook, error) {
+	var webhook models.WeatherWebhook
+	query := `SELECT ` + webhookColumns + ` FROM weather_webhooks WHERE id = $1`
+	if err := r.db.Get(&webhook, query, id); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) GetWebhooksByOwner(ownerID string) ([]models.WeatherWebhook, error) {
+	webhooks := []models.WeatherWebhook{}
+	query := `SELECT ` + webhookColumns + ` FROM weather_webhooks WHERE owner_id = $1 ORDER BY created_at DESC`
+	if err := r.db.Select(&webhooks, query, ownerID); err != nil {
+		return nil, fmt.Errorf("failed to get weather webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) GetActiveWebhooksByLocation(locationKey string) ([]models.WeatherWebhook, error) {
+	webhooks := []models.WeatherWebhook{}
+	query := `SELECT ` + webhookColumns + ` FROM weather_webhooks WHERE location_key = $1 AND is_active ORDER BY id`
+	if err := r.db.Select(&webhooks, query, locationKey); err != nil {
+		return nil, fmt.Errorf("failed to get weather webhooks of %s: %w", locationKey, err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) GetActiveLocations() ([]models.WebhookLocation, error) {
+	locations := []models.WebhookLocation{}
+	query := `
+		SELECT DISTINCT ON (location_key) location_key, polygon_id, latitude, longitude
+		FROM weather_webhooks
+		WHERE is_active
+		ORDER BY location_key, id`
+	if err := r.db.Select(&locations, query); err != nil {
+		return nil, fmt.Errorf("failed to get weather webhook locations: %w", err)
+	}
+	return locations, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM weather_webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete weather webhook: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *WebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) (bool, error) {
+	query := `
+		INSERT INTO weather_webhook_deliveries (webhook_id, event_id, observed_at, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (webhook_id, observed_at) DO NOTHING
+		RETURNING id, status, attempts, next_attempt_at, created_at`
+
+	err := r.db.QueryRowx(query, delivery.WebhookID, delivery.EventID, delivery.ObservedAt, string(delivery.Payload)).
+		Scan(&delivery.ID, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return true, nil
+}
+
+func (r *WebhookRepository) GetDueDeliveries(limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM weather_webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT $1`
+	if err := r.db.Select(&deliveries, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE weather_webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, response_status = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1`
+	if _, err := r.db.Exec(query, delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetDeliveriesByWebhook(webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM weather_webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+	if err := r.db.Select(&deliveries, query, webhookID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
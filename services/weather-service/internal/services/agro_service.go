@@ -2,31 +2,39 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"time"
+	"weather-service/internal/cache"
 	"weather-service/internal/config"
 	"weather-service/internal/models"
+	"weather-service/internal/quota"
 )
 
 type AgroService struct {
-	cfg config.WeatherServiceConfig
+	cfg     config.WeatherServiceConfig
+	cache   *cache.WeatherCache
+	history IHistoryService
+	quality IQualityService
+	limiter *quota.Limiter
 }
 
 type IAgroService interface {
 	CreatePolygon(name string, coordinates [][2]float64) (*models.AgroPolygonResponse, error)
 	GetPolygon(polygonID string) (*models.AgroPolygonResponse, error)
+	GetForecast(polygonID string) ([]models.ForecastWeatherResponse, error)
 	GetForecastPrecipitation(polygonID string) ([]models.PrecipitationDataPoint, error)
 	GetCurrentWeather(polygonID string) (*models.CurrentWeatherResponse, error)
 	CreatePolygonAndGetPrecipitation(coordinates [][2]float64, start, end int64) (*models.UnifiedAPIResponse, error)
 	GetPrecipitationWithPolygonID(polygonID string, coordinates [][2]float64, start, end int64) (*models.UnifiedAPIResponse, error)
 }
 
-func NewAgroService(cfg config.WeatherServiceConfig) IAgroService {
-	return &AgroService{cfg: cfg}
+func NewAgroService(cfg config.WeatherServiceConfig, weatherCache *cache.WeatherCache, history IHistoryService, quality IQualityService, limiter *quota.Limiter) IAgroService {
+	return &AgroService{cfg: cfg, cache: weatherCache, history: history, quality: quality, limiter: limiter}
 }
 
 // CreatePolygon creates a polygon in Agro API and returns the polygon ID
@@ -36,6 +44,20 @@ func (a *AgroService) CreatePolygon(name string, coordinates [][2]float64) (*mod
 		return nil, fmt.Errorf("agro API key not configured")
 	}
 
+	// The same farm boundary is looked up repeatedly, so reuse the polygon created for it
+	cacheKey := cache.CoordinateKey(cache.ParamPolygon, coordinates)
+	var cached models.AgroPolygonResponse
+	if a.cache.Get(context.Background(), cache.ParamPolygon, cacheKey, &cached) {
+		return &cached, nil
+	}
+	served, err := reserveCall(context.Background(), a.limiter, a.cache, quota.ProviderAgro, cache.ParamPolygon, cacheKey, &cached)
+	if err != nil {
+		return nil, err
+	}
+	if served {
+		return &cached, nil
+	}
+
 	// Convert coordinates to GeoJSON format
 	// Note: Agro API expects [lon, lat] format
 	geoJSONCoords := make([][]float64, len(coordinates))
@@ -105,6 +127,7 @@ func (a *AgroService) CreatePolygon(name string, coordinates [][2]float64) (*mod
 	}
 
 	log.Printf("Successfully created polygon with ID: %s", polygonResp.ID)
+	a.cache.Set(context.Background(), cache.ParamPolygon, cacheKey, polygonResp)
 	return &polygonResp, nil
 }
 
@@ -115,6 +138,11 @@ func (a *AgroService) GetPolygon(polygonID string) (*models.AgroPolygonResponse,
 		return nil, fmt.Errorf("agro API key not configured")
 	}
 
+	if err := a.limiter.Acquire(context.Background(), quota.ProviderAgro); err != nil {
+		log.Printf("Skipping polygon lookup: %v", err)
+		return nil, err
+	}
+
 	url := fmt.Sprintf("%s/polygons/%s?appid=%s", a.cfg.AgroAPIBaseURL, polygonID, a.cfg.AgroAPIKey)
 
 	client := &http.Client{Timeout: 30 * time.Second}
@@ -151,14 +179,38 @@ func (a *AgroService) GetPolygon(polygonID string) (*models.AgroPolygonResponse,
 	return &polygonResp, nil
 }
 
-// GetForecastPrecipitation fetches forecast precipitation data for a polygon (free tier)
-// Returns precipitation data from 5-day forecast (available with free API key)
-func (a *AgroService) GetForecastPrecipitation(polygonID string) ([]models.PrecipitationDataPoint, error) {
+// GetForecast fetches the raw 5-day forecast in 3-hour steps for a polygon (free tier)
+func (a *AgroService) GetForecast(polygonID string) ([]models.ForecastWeatherResponse, error) {
 	if a.cfg.AgroAPIKey == "" {
 		log.Println("Agro API key not configured")
 		return nil, fmt.Errorf("Agro API key not configured")
 	}
 
+	cacheKey := cache.Key(cache.ParamForecast, polygonID)
+	var forecastData []models.ForecastWeatherResponse
+	if a.cache.Get(context.Background(), cache.ParamForecast, cacheKey, &forecastData) {
+		return forecastData, nil
+	}
+	if serveRevalidating(a.cache, cache.ParamForecast, cacheKey, &forecastData, func() error {
+		_, err := a.fetchForecast(polygonID, cacheKey)
+		return err
+	}) {
+		return forecastData, nil
+	}
+	return a.fetchForecast(polygonID, cacheKey)
+}
+
+// fetchForecast calls the forecast of a polygon upstream and caches it under cacheKey
+func (a *AgroService) fetchForecast(polygonID, cacheKey string) ([]models.ForecastWeatherResponse, error) {
+	var forecastData []models.ForecastWeatherResponse
+	served, err := reserveCall(context.Background(), a.limiter, a.cache, quota.ProviderAgro, cache.ParamForecast, cacheKey, &forecastData)
+	if err != nil {
+		return nil, err
+	}
+	if served {
+		return forecastData, nil
+	}
+
 	url := fmt.Sprintf("%s/weather/forecast?polyid=%s&appid=%s",
 		a.cfg.AgroAPIBaseURL, polygonID, a.cfg.AgroAPIKey)
 
@@ -181,11 +233,24 @@ func (a *AgroService) GetForecastPrecipitation(polygonID string) ([]models.Preci
 		return nil, fmt.Errorf("Agro API error: %s", string(body))
 	}
 
-	var forecastData []models.ForecastWeatherResponse
 	if err := json.Unmarshal(body, &forecastData); err != nil {
 		log.Printf("Error unmarshaling forecast data: %v", err)
 		return nil, fmt.Errorf("failed to parse response")
 	}
+	a.history.Record(agroForecastObservations(polygonID, forecastData))
+
+	log.Printf("Retrieved %d forecast points for polygon: %s", len(forecastData), polygonID)
+	a.cache.Set(context.Background(), cache.ParamForecast, cacheKey, forecastData)
+	return forecastData, nil
+}
+
+// GetForecastPrecipitation fetches forecast precipitation data for a polygon (free tier)
+// Returns precipitation data from 5-day forecast (available with free API key)
+func (a *AgroService) GetForecastPrecipitation(polygonID string) ([]models.PrecipitationDataPoint, error) {
+	forecastData, err := a.GetForecast(polygonID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert forecast data to precipitation data points
 	precipData := make([]models.PrecipitationDataPoint, 0)
@@ -223,6 +288,31 @@ func (a *AgroService) GetCurrentWeather(polygonID string) (*models.CurrentWeathe
 		return nil, fmt.Errorf("Agro API key not configured")
 	}
 
+	var currentWeather models.CurrentWeatherResponse
+	cacheKey := cache.Key(cache.ParamCurrent, polygonID)
+	if a.cache.Get(context.Background(), cache.ParamCurrent, cacheKey, &currentWeather) {
+		return &currentWeather, nil
+	}
+	if serveRevalidating(a.cache, cache.ParamCurrent, cacheKey, &currentWeather, func() error {
+		_, err := a.fetchCurrentWeather(polygonID, cacheKey)
+		return err
+	}) {
+		return &currentWeather, nil
+	}
+	return a.fetchCurrentWeather(polygonID, cacheKey)
+}
+
+// fetchCurrentWeather calls the current weather of a polygon upstream and caches it under cacheKey
+func (a *AgroService) fetchCurrentWeather(polygonID, cacheKey string) (*models.CurrentWeatherResponse, error) {
+	var currentWeather models.CurrentWeatherResponse
+	served, err := reserveCall(context.Background(), a.limiter, a.cache, quota.ProviderAgro, cache.ParamCurrent, cacheKey, &currentWeather)
+	if err != nil {
+		return nil, err
+	}
+	if served {
+		return &currentWeather, nil
+	}
+
 	url := fmt.Sprintf("%s/weather?polyid=%s&appid=%s",
 		a.cfg.AgroAPIBaseURL, polygonID, a.cfg.AgroAPIKey)
 
@@ -245,16 +335,35 @@ func (a *AgroService) GetCurrentWeather(polygonID string) (*models.CurrentWeathe
 		return nil, fmt.Errorf("Agro API error: %s", string(body))
 	}
 
-	var currentWeather models.CurrentWeatherResponse
 	if err := json.Unmarshal(body, &currentWeather); err != nil {
 		log.Printf("Error unmarshaling current weather data: %v", err)
 		return nil, fmt.Errorf("failed to parse response")
 	}
 
 	log.Printf("Successfully retrieved current weather for polygon: %s", polygonID)
+	a.cache.Set(context.Background(), cache.ParamCurrent, cacheKey, currentWeather)
+	a.history.Record(agroCurrentObservations(polygonID, &currentWeather))
 	return &currentWeather, nil
 }
 
+// precipitationConfidence scores a forecast precipitation point of a polygon
+func (a *AgroService) precipitationConfidence(polygon *models.AgroPolygonResponse, data models.PrecipitationDataPoint) *float64 {
+	input := models.QualityInput{
+		Source:      SourceAgroForecast,
+		Parameter:   models.ParamPrecipitation,
+		LocationKey: PolygonLocationKey(polygon.ID),
+		ObservedAt:  time.Unix(data.Dt, 0),
+		Value:       data.Rain,
+		IsForecast:  true,
+	}
+	// Agro reports the centre as [lon, lat]
+	if len(polygon.Center) == 2 {
+		input.Lon, input.Lat = &polygon.Center[0], &polygon.Center[1]
+	}
+	score := a.quality.Score(input).Score
+	return &score
+}
+
 // CreatePolygonAndGetPrecipitation combines polygon creation and precipitation fetching
 // Note: Uses forecast data (free tier) instead of historical data (requires paid plan)
 func (a *AgroService) CreatePolygonAndGetPrecipitation(coordinates [][2]float64, start, end int64) (*models.UnifiedAPIResponse, error) {
@@ -280,10 +389,11 @@ func (a *AgroService) CreatePolygonAndGetPrecipitation(coordinates [][2]float64,
 		// Only include data points within the requested time range
 		if data.Dt >= start && data.Dt <= end {
 			dataPoints = append(dataPoints, models.DataPoint{
-				Dt:    data.Dt,
-				Data:  data.Rain,
-				Count: data.Count,
-				Unit:  "mm",
+				Dt:              data.Dt,
+				Data:            data.Rain,
+				Count:           data.Count,
+				Unit:            "mm",
+				ConfidenceScore: a.precipitationConfidence(polygonResp, data),
 			})
 			totalRainfall += data.Rain
 		}
@@ -358,10 +468,11 @@ func (a *AgroService) GetPrecipitationWithPolygonID(polygonID string, coordinate
 		// Only include data points within the requested time range
 		if data.Dt >= start && data.Dt <= end {
 			dataPoints = append(dataPoints, models.DataPoint{
-				Dt:    data.Dt,
-				Data:  data.Rain,
-				Count: data.Count,
-				Unit:  "mm",
+				Dt:              data.Dt,
+				Data:            data.Rain,
+				Count:           data.Count,
+				Unit:            "mm",
+				ConfidenceScore: a.precipitationConfidence(polygonResp, data),
 			})
 			totalRainfall += data.Rain
 		}
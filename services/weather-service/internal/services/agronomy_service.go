@@ -0,0 +1,369 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+
+	"agrisa/parameter"
+)
+
+const (
+	// DefaultGDDBaseCelsius is the base temperature of growing degree days when none is given,
+	// the development threshold of rice and maize
+	DefaultGDDBaseCelsius = 10.0
+	// Hours with a temperature reading needed for a day to count; the extremes of fewer readings
+	// understate the daily range
+	agronomyMinDailyHours = 6
+	// Height of provider wind readings, reduced to the 2 m of the FAO-56 reference crop
+	windMeasurementHeight = 10.0
+	// Hargreaves radiation coefficient for inland areas (FAO-56 eq. 50), as no provider reports
+	// solar radiation
+	hargreavesRadiationCoefficient = 0.16
+	// Atmospheric pressure in kPa used for days without a pressure reading
+	standardPressureKPa = 101.3
+	// FAO-56 constants: solar constant in MJ m-2 min-1, Stefan-Boltzmann constant in
+	// MJ K-4 m-2 day-1 and the albedo of the reference crop
+	solarConstant   = 0.0820
+	stefanBoltzmann = 4.903e-9
+	referenceAlbedo = 0.23
+	// Mean relative humidity from which leaves stay wet long enough for fungal infection, and
+	// from which the infection risk is highest
+	diseaseHumidityOnset = 60.0
+	diseaseHumidityPeak  = 90.0
+	// Mean temperatures in °C between which fungal pathogens such as rice blast infect; the risk
+	// rises from the minimum to the optimum range and falls to the maximum
+	diseaseTemperatureMin         = 10.0
+	diseaseTemperatureOptimumLow  = 20.0
+	diseaseTemperatureOptimumHigh = 30.0
+	diseaseTemperatureMax         = 35.0
+	// DefaultDryDayThresholdMM is the daily rainfall below which a day counts as dry when no
+	// threshold is given, the usual limit of a rain day
+	DefaultDryDayThresholdMM = 1.0
+	// Days before the requested range searched for the start of a dry spell running into it
+	drySpellLookbackDays = 120
+)
+
+type AgronomyService struct {
+	repo        repository.IObservationRepository
+	agroService IAgroService
+}
+
+type IAgronomyService interface {
+	// GetEvapotranspiration returns the daily FAO-56 reference evapotranspiration of a polygon
+	GetEvapotranspiration(req models.PrecipitationRequest) (*models.UnifiedAPIResponse, error)
+	// GetGrowingDegreeDays returns the daily growing degree days of a polygon above baseCelsius
+	GetGrowingDegreeDays(req models.PrecipitationRequest, baseCelsius float64) (*models.UnifiedAPIResponse, error)
+	// GetDiseaseRisk returns the daily fungal disease infection risk of a polygon from 0 to 100
+	GetDiseaseRisk(req models.PrecipitationRequest) (*models.UnifiedAPIResponse, error)
+	// GetDrySpell returns, for each day of a polygon, the days in a row up to it with less than
+	// thresholdMM of rain
+	GetDrySpell(req models.PrecipitationRequest, thresholdMM float64) (*models.UnifiedAPIResponse, error)
+}
+
+// NewAgronomyService creates the service computing agronomic parameters from stored weather history
+func NewAgronomyService(repo repository.IObservationRepository, agroService IAgroService) IAgronomyService {
+	return &AgronomyService{repo: repo, agroService: agroService}
+}
+
+// dailyWeather holds the readings of one local day that the agronomic parameters are computed from
+type dailyWeather struct {
+	tMin, tMax    float64
+	hours         map[int64]bool
+	humiditySum   float64
+	humidityCount int
+	windSum       float64
+	windCount     int
+	pressureSum   float64
+	pressureCount int
+}
+
+func (d *dailyWeather) complete() bool {
+	return len(d.hours) >= agronomyMinDailyHours
+}
+
+// collectDailyWeather groups stored readings into local days
+func collectDailyWeather(observations map[string][]models.WeatherObservation) map[int64]*dailyWeather {
+	days := map[int64]*dailyWeather{}
+	dayOf := func(t time.Time) *dailyWeather {
+		local := t.In(forecastLocation)
+		key := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, forecastLocation).Unix()
+		day, ok := days[key]
+		if !ok {
+			day = &dailyWeather{tMin: math.Inf(1), tMax: math.Inf(-1), hours: map[int64]bool{}}
+			days[key] = day
+		}
+		return day
+	}
+
+	for _, observation := range observations[models.ParamTemperature] {
+		day := dayOf(observation.ObservedAt)
+		day.tMin = math.Min(day.tMin, observation.Value)
+		day.tMax = math.Max(day.tMax, observation.Value)
+		day.hours[observation.ObservedAt.Truncate(time.Hour).Unix()] = true
+	}
+	for _, observation := range observations[models.ParamHumidity] {
+		day := dayOf(observation.ObservedAt)
+		day.humiditySum += observation.Value
+		day.humidityCount++
+	}
+	for _, observation := range observations[models.ParamWindSpeed] {
+		day := dayOf(observation.ObservedAt)
+		day.windSum += observation.Value
+		day.windCount++
+	}
+	for _, observation := range observations[models.ParamPressure] {
+		day := dayOf(observation.ObservedAt)
+		day.pressureSum += observation.Value
+		day.pressureCount++
+	}
+	return days
+}
+
+// saturationVapourPressure in kPa at a temperature in °C (FAO-56 eq. 11)
+func saturationVapourPressure(celsius float64) float64 {
+	return 0.6108 * math.Exp(17.27*celsius/(celsius+237.3))
+}
+
+// extraterrestrialRadiation in MJ m-2 day-1 at a latitude on a day of the year (FAO-56 eq. 21)
+func extraterrestrialRadiation(latitude float64, dayOfYear int) float64 {
+	phi := latitude * math.Pi / 180
+	angle := 2 * math.Pi * float64(dayOfYear) / 365
+	inverseDistance := 1 + 0.033*math.Cos(angle)
+	declination := 0.409 * math.Sin(angle-1.39)
+	sunsetAngle := math.Acos(math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(declination))))
+	return 24 * 60 / math.Pi * solarConstant * inverseDistance *
+		(sunsetAngle*math.Sin(phi)*math.Sin(declination) + math.Cos(phi)*math.Cos(declination)*math.Sin(sunsetAngle))
+}
+
+// referenceEvapotranspiration computes the FAO-56 Penman-Monteith reference evapotranspiration
+// of a day in mm (eq. 6). Solar radiation is estimated from the temperature range (eq. 50) and
+// soil heat flux is taken as zero, as for daily steps. It reports false for days lacking the
+// humidity or wind readings the equation needs.
+func referenceEvapotranspiration(day *dailyWeather, latitude float64, dayOfYear int) (float64, bool) {
+	if !day.complete() || day.humidityCount == 0 || day.windCount == 0 {
+		return 0, false
+	}
+	tMean := (day.tMax + day.tMin) / 2
+	pressure := standardPressureKPa
+	if day.pressureCount > 0 {
+		pressure = day.pressureSum / float64(day.pressureCount) / 10
+	}
+	windSpeed := day.windSum / float64(day.windCount) * 4.87 / math.Log(67.8*windMeasurementHeight-5.42)
+
+	saturation := (saturationVapourPressure(day.tMax) + saturationVapourPressure(day.tMin)) / 2
+	actual := saturation * day.humiditySum / float64(day.humidityCount) / 100
+	slope := 4098 * saturationVapourPressure(tMean) / math.Pow(tMean+237.3, 2)
+	psychrometric := 0.000665 * pressure
+
+	ra := extraterrestrialRadiation(latitude, dayOfYear)
+	rs := hargreavesRadiationCoefficient * math.Sqrt(day.tMax-day.tMin) * ra
+	rso := 0.75 * ra
+	relativeRadiation := 1.0
+	if rso > 0 {
+		relativeRadiation = math.Min(rs/rso, 1)
+	}
+	netShortwave := (1 - referenceAlbedo) * rs
+	netLongwave := stefanBoltzmann * (math.Pow(day.tMax+273.16, 4) + math.Pow(day.tMin+273.16, 4)) / 2 *
+		(0.34 - 0.14*math.Sqrt(actual)) * (1.35*relativeRadiation - 0.35)
+	netRadiation := netShortwave - netLongwave
+
+	et0 := (0.408*slope*netRadiation + psychrometric*900/(tMean+273)*windSpeed*(saturation-actual)) /
+		(slope + psychrometric*(1+0.34*windSpeed))
+	return math.Max(et0, 0), true
+}
+
+// growingDegreeDays of a day above a base temperature, by the averaging method
+func growingDegreeDays(day *dailyWeather, baseCelsius float64) (float64, bool) {
+	if !day.complete() {
+		return 0, false
+	}
+	return math.Max((day.tMax+day.tMin)/2-baseCelsius, 0), true
+}
+
+// diseaseRisk rates the fungal infection risk of a day from 0 to 100 as the product of a
+// humidity and a temperature factor: infection needs leaves wet for long, which a high mean
+// humidity stands for, in warm weather
+func diseaseRisk(day *dailyWeather) (float64, bool) {
+	if !day.complete() || day.humidityCount == 0 {
+		return 0, false
+	}
+	humidity := day.humiditySum / float64(day.humidityCount)
+	humidityFactor := math.Max(0, math.Min(1, (humidity-diseaseHumidityOnset)/(diseaseHumidityPeak-diseaseHumidityOnset)))
+
+	tMean := (day.tMax + day.tMin) / 2
+	temperatureFactor := 0.0
+	switch {
+	case tMean <= diseaseTemperatureMin || tMean >= diseaseTemperatureMax:
+	case tMean < diseaseTemperatureOptimumLow:
+		temperatureFactor = (tMean - diseaseTemperatureMin) / (diseaseTemperatureOptimumLow - diseaseTemperatureMin)
+	case tMean <= diseaseTemperatureOptimumHigh:
+		temperatureFactor = 1
+	default:
+		temperatureFactor = (diseaseTemperatureMax - tMean) / (diseaseTemperatureMax - diseaseTemperatureOptimumHigh)
+	}
+	return 100 * humidityFactor * temperatureFactor, true
+}
+
+// dailyParameter computes one agronomic parameter per local day from start to end, from the
+// stored observed history of a polygon. Days without enough readings are left out.
+func (s *AgronomyService) dailyParameter(req models.PrecipitationRequest, name parameter.Name, parameters []string, compute func(day *dailyWeather, latitude float64, date time.Time) (float64, bool)) (*models.UnifiedAPIResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+
+	polygon, reused, err := resolvePolygon(s.agroService, req)
+	if err != nil {
+		return nil, err
+	}
+	// Record the latest reading, so that polling the parameter also grows the history it is computed on
+	if _, err := s.agroService.GetCurrentWeather(polygon.ID); err != nil {
+		log.Printf("Failed to refresh current weather for %s of polygon %s: %v", name, polygon.ID, err)
+	}
+	latitude := 0.0
+	if len(polygon.Center) == 2 {
+		latitude = polygon.Center[1]
+	}
+
+	start := time.Unix(req.Start, 0).In(forecastLocation)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, forecastLocation)
+	end := time.Unix(req.End, 0)
+	observations := map[string][]models.WeatherObservation{}
+	for _, param := range parameters {
+		observations[param], err = s.repo.GetObservations(PolygonLocationKey(polygon.ID), param, start, end, false)
+		if err != nil {
+			log.Printf("Error fetching %s history for %s of polygon %s: %v", param, name, polygon.ID, err)
+			return nil, fmt.Errorf("failed to fetch weather history")
+		}
+	}
+	days := collectDailyWeather(observations)
+
+	response := &models.UnifiedAPIResponse{
+		PolygonID:         polygon.ID,
+		PolygonName:       polygon.Name,
+		PolygonCenter:     polygon.Center,
+		PolygonArea:       polygon.Area,
+		PolygonReused:     reused,
+		PolygonCreatedNew: !reused,
+		TimeRange:         models.TimeRange{Start: req.Start, End: req.End},
+		Data:              []models.DataPoint{},
+	}
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day, ok := days[date.Unix()]
+		if !ok {
+			continue
+		}
+		value, ok := compute(day, latitude, date)
+		if !ok {
+			continue
+		}
+		value = math.Round(value*100) / 100
+		response.Data = append(response.Data, models.DataPoint{
+			Dt:    date.Unix(),
+			Data:  value,
+			Count: len(day.hours),
+			Unit:  string(name.Unit()),
+		})
+		response.TotalDataValue += value
+	}
+	response.DataPointCount = len(response.Data)
+
+	log.Printf("Computed %d daily %s values for polygon %s", response.DataPointCount, name, polygon.ID)
+	return response, nil
+}
+
+func (s *AgronomyService) GetEvapotranspiration(req models.PrecipitationRequest) (*models.UnifiedAPIResponse, error) {
+	parameters := []string{models.ParamTemperature, models.ParamHumidity, models.ParamWindSpeed, models.ParamPressure}
+	return s.dailyParameter(req, parameter.ET0, parameters, func(day *dailyWeather, latitude float64, date time.Time) (float64, bool) {
+		return referenceEvapotranspiration(day, latitude, date.YearDay())
+	})
+}
+
+func (s *AgronomyService) GetGrowingDegreeDays(req models.PrecipitationRequest, baseCelsius float64) (*models.UnifiedAPIResponse, error) {
+	if baseCelsius < -10 || baseCelsius > 30 {
+		return nil, fmt.Errorf("invalid base temperature: must be between -10 and 30 °C")
+	}
+	return s.dailyParameter(req, parameter.GDD, []string{models.ParamTemperature}, func(day *dailyWeather, _ float64, _ time.Time) (float64, bool) {
+		return growingDegreeDays(day, baseCelsius)
+	})
+}
+
+func (s *AgronomyService) GetDiseaseRisk(req models.PrecipitationRequest) (*models.UnifiedAPIResponse, error) {
+	parameters := []string{models.ParamTemperature, models.ParamHumidity}
+	return s.dailyParameter(req, parameter.DiseaseRisk, parameters, func(day *dailyWeather, _ float64, _ time.Time) (float64, bool) {
+		return diseaseRisk(day)
+	})
+}
+
+// GetDrySpell counts the dry days in a row ending on each day from start to end. The count runs
+// from before start when the spell began earlier; a day without any rainfall reading is unknown,
+// so it is left out and the count restarts after it.
+func (s *AgronomyService) GetDrySpell(req models.PrecipitationRequest, thresholdMM float64) (*models.UnifiedAPIResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	if thresholdMM <= 0 || thresholdMM > 50 {
+		return nil, fmt.Errorf("invalid threshold: must be above 0 and at most 50 mm")
+	}
+
+	polygon, reused, err := resolvePolygon(s.agroService, req)
+	if err != nil {
+		return nil, err
+	}
+	// Record the latest reading, so that polling the parameter also grows the history it is computed on
+	if _, err := s.agroService.GetCurrentWeather(polygon.ID); err != nil {
+		log.Printf("Failed to refresh current weather for %s of polygon %s: %v", parameter.DrySpell, polygon.ID, err)
+	}
+
+	start := time.Unix(req.Start, 0).In(forecastLocation)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, forecastLocation)
+	from := start.AddDate(0, 0, -drySpellLookbackDays)
+	end := time.Unix(req.End, 0)
+	observations, err := s.repo.GetObservations(PolygonLocationKey(polygon.ID), models.ParamPrecipitation, from, end, false)
+	if err != nil {
+		log.Printf("Error fetching precipitation history for %s of polygon %s: %v", parameter.DrySpell, polygon.ID, err)
+		return nil, fmt.Errorf("failed to fetch precipitation history")
+	}
+	dailyTotals, _ := dailyRainfallTotals(observations, from)
+
+	response := &models.UnifiedAPIResponse{
+		PolygonID:         polygon.ID,
+		PolygonName:       polygon.Name,
+		PolygonCenter:     polygon.Center,
+		PolygonArea:       polygon.Area,
+		PolygonReused:     reused,
+		PolygonCreatedNew: !reused,
+		TimeRange:         models.TimeRange{Start: req.Start, End: req.End},
+		Data:              []models.DataPoint{},
+	}
+	spell := 0
+	for date := from; !date.After(end); date = date.AddDate(0, 0, 1) {
+		total, ok := dailyTotals[date.Unix()]
+		if !ok {
+			spell = 0
+			continue
+		}
+		if total < thresholdMM {
+			spell++
+		} else {
+			spell = 0
+		}
+		if date.Before(start) {
+			continue
+		}
+		response.Data = append(response.Data, models.DataPoint{
+			Dt:    date.Unix(),
+			Data:  float64(spell),
+			Count: 1,
+			Unit:  string(parameter.DrySpell.Unit()),
+		})
+		response.TotalDataValue += float64(spell)
+	}
+	response.DataPointCount = len(response.Data)
+
+	log.Printf("Computed %d daily %s values for polygon %s", response.DataPointCount, parameter.DrySpell, polygon.ID)
+	return response, nil
+}
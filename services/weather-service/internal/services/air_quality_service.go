@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+	"weather-service/internal/config"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+
+	"agrisa/parameter"
+)
+
+type AirQualityService struct {
+	agroService IAgroService
+	limiter     *quota.Limiter
+	cfg         config.WeatherServiceConfig
+	client      *http.Client
+}
+
+type IAirQualityService interface {
+	// GetAirQuality returns the daily worst air quality index of a polygon
+	GetAirQuality(ctx context.Context, req models.PrecipitationRequest) (*models.UnifiedAPIResponse, error)
+}
+
+// NewAirQualityService creates the service reading air quality from the OpenWeatherMap air
+// pollution API
+func NewAirQualityService(agroService IAgroService, limiter *quota.Limiter, cfg config.WeatherServiceConfig) IAirQualityService {
+	return &AirQualityService{
+		agroService: agroService,
+		limiter:     limiter,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// airPollutionHistory is the part of an air pollution API response the index is read from
+type airPollutionHistory struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			AQI float64 `json:"aqi"`
+		} `json:"main"`
+	} `json:"list"`
+}
+
+// GetAirQuality reports the highest hourly air quality index of each local day from start to
+// end, on the provider scale from 1 (good) to 5 (very poor). The provider keeps air pollution
+// history only for past hours, so the range ends now.
+func (s *AirQualityService) GetAirQuality(ctx context.Context, req models.PrecipitationRequest) (*models.UnifiedAPIResponse, error) {
+	if s.cfg.APIKey == "" {
+		log.Println("API key not configured")
+		return nil, fmt.Errorf("API key not configured")
+	}
+
+	polygon, reused, err := resolvePolygon(s.agroService, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(polygon.Center) != 2 {
+		return nil, fmt.Errorf("polygon %s has no centre", polygon.ID)
+	}
+	// Agro reports the centre as [lon, lat]
+	lat, lon := polygon.Center[1], polygon.Center[0]
+
+	response := &models.UnifiedAPIResponse{
+		PolygonID:         polygon.ID,
+		PolygonName:       polygon.Name,
+		PolygonCenter:     polygon.Center,
+		PolygonArea:       polygon.Area,
+		PolygonReused:     reused,
+		PolygonCreatedNew: !reused,
+		TimeRange:         models.TimeRange{Start: req.Start, End: req.End},
+		Data:              []models.DataPoint{},
+	}
+	end := req.End
+	if now := time.Now().Unix(); end > now {
+		end = now
+	}
+	if end <= req.Start {
+		return response, nil
+	}
+
+	if err := s.limiter.Acquire(ctx, quota.ProviderAirQuality); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://api.openweathermap.org/data/2.5/air_pollution/history?lat=%f&lon=%f&start=%d&end=%d&appid=%s",
+		lat, lon, req.Start, end, s.cfg.APIKey)
+	resp, err := s.client.Get(url)
+	if err != nil {
+		log.Printf("Error fetching air pollution history: %v", err)
+		return nil, fmt.Errorf("failed to call API")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading response body: %v", err)
+		return nil, fmt.Errorf("failed to read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("API 3rd party returned non-200 status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API 3rd party error")
+	}
+
+	var history airPollutionHistory
+	if err := json.Unmarshal(body, &history); err != nil {
+		log.Println("Error unmarshaling JSON:", err)
+		return nil, fmt.Errorf("failed to parse JSON")
+	}
+
+	days := map[int64]*models.DataPoint{}
+	for _, hour := range history.List {
+		local := time.Unix(hour.Dt, 0).In(forecastLocation)
+		key := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, forecastLocation).Unix()
+		day, ok := days[key]
+		if !ok {
+			day = &models.DataPoint{Dt: key, Unit: string(parameter.AQI.Unit())}
+			days[key] = day
+		}
+		if hour.Main.AQI > day.Data {
+			day.Data = hour.Main.AQI
+		}
+		day.Count++
+	}
+	for _, day := range days {
+		response.Data = append(response.Data, *day)
+		response.TotalDataValue += day.Data
+	}
+	sort.Slice(response.Data, func(i, j int) bool { return response.Data[i].Dt < response.Data[j].Dt })
+	response.DataPointCount = len(response.Data)
+
+	log.Printf("Retrieved %d daily air quality values for polygon %s", response.DataPointCount, polygon.ID)
+	return response, nil
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"weather-service/internal/event"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+)
+
+// Alerts listed per subscription
+const alertHistoryLimit = 100
+
+var alertTitles = map[string]string{
+	models.AlertHeavyRain: "Cảnh báo mưa lớn",
+	models.AlertHeatwave:  "Cảnh báo nắng nóng",
+	models.AlertTyphoon:   "Cảnh báo bão",
+}
+
+var alertDescriptions = map[string]string{
+	models.AlertHeavyRain: "Dự báo mưa lớn %.0f mm/ngày",
+	models.AlertHeatwave:  "Dự báo nắng nóng %.1f°C",
+	models.AlertTyphoon:   "Dự báo gió mạnh %.1f m/s",
+}
+
+// WarningFeed lists the official severe weather warnings in force
+type WarningFeed interface {
+	ActiveWarnings(ctx context.Context) ([]models.IngestWarningRequest, error)
+}
+
+type AlertService struct {
+	repo                  repository.IAlertRepository
+	forecastService       IForecastService
+	notificationPublisher *event.Publisher
+	warningFeed           WarningFeed
+}
+
+type IAlertService interface {
+	CreateSubscription(userID string, req models.CreateAlertSubscriptionRequest) (*models.WeatherAlertSubscription, error)
+	GetSubscriptions(userID string) ([]models.WeatherAlertSubscription, error)
+	DeleteSubscription(userID string, subscriptionID int64) error
+	GetAlerts(userID string, subscriptionID int64) ([]models.WeatherAlert, error)
+	EvaluateAlerts(ctx context.Context) error
+	StartAlertWatcher(ctx context.Context, interval time.Duration)
+	// IngestWarning stores an official warning and pushes it to the subscriptions of its area that
+	// did not get it yet
+	IngestWarning(ctx context.Context, source string, req models.IngestWarningRequest) (*models.WarningIngestResult, error)
+	GetActiveWarnings() ([]models.WeatherWarning, error)
+	// StartWarningWatcher ingests the warnings of the feed every interval until ctx is cancelled
+	StartWarningWatcher(ctx context.Context, interval time.Duration)
+}
+
+// NewAlertService creates the alert service. Without a repository subscriptions are unavailable;
+// without a publisher forecasts are not evaluated and warnings not pushed, so they are alerted
+// once it is back. Without a feed warnings only come in through IngestWarning.
+func NewAlertService(repo repository.IAlertRepository, forecastService IForecastService, notificationPublisher *event.Publisher, warningFeed WarningFeed) IAlertService {
+	return &AlertService{repo: repo, forecastService: forecastService, notificationPublisher: notificationPublisher, warningFeed: warningFeed}
+}
+
+func (s *AlertService) CreateSubscription(userID string, req models.CreateAlertSubscriptionRequest) (*models.WeatherAlertSubscription, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather alert storage is not configured")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+	req.ProvinceCode = trimmedCode(req.ProvinceCode)
+	req.DistrictCode = trimmedCode(req.DistrictCode)
+	if (req.PolygonID == nil || *req.PolygonID == "") && (req.Latitude == nil || req.Longitude == nil) &&
+		req.ProvinceCode == nil && req.DistrictCode == nil {
+		return nil, fmt.Errorf("invalid location: one of polygon_id, latitude and longitude, province_code or district_code is required")
+	}
+
+	eventTypes := []string{}
+	seen := map[string]bool{}
+	for _, eventType := range req.EventTypes {
+		if !models.AlertEventTypes[eventType] {
+			return nil, fmt.Errorf("invalid event type: %s", eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	subscription := &models.WeatherAlertSubscription{
+		UserID:          userID,
+		FarmID:          req.FarmID,
+		Region:          req.Region,
+		ProvinceCode:    req.ProvinceCode,
+		DistrictCode:    req.DistrictCode,
+		EventTypes:      eventTypes,
+		HeavyRainMM:     req.HeavyRainMM,
+		HeatwaveCelsius: req.HeatwaveCelsius,
+		TyphoonWindMS:   req.TyphoonWindMS,
+	}
+	if req.PolygonID != nil && *req.PolygonID != "" {
+		subscription.PolygonID = req.PolygonID
+	} else if req.Latitude != nil && req.Longitude != nil {
+		subscription.Latitude = req.Latitude
+		subscription.Longitude = req.Longitude
+	}
+
+	if err := s.repo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *AlertService) GetSubscriptions(userID string) ([]models.WeatherAlertSubscription, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather alert storage is not configured")
+	}
+	return s.repo.GetSubscriptionsByUser(userID)
+}
+
+func (s *AlertService) ownedSubscription(userID string, subscriptionID int64) (*models.WeatherAlertSubscription, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather alert storage is not configured")
+	}
+	subscription, err := s.repo.GetSubscriptionByID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.UserID != userID {
+		return nil, fmt.Errorf("forbidden: subscription belongs to another user")
+	}
+	return subscription, nil
+}
+
+func (s *AlertService) DeleteSubscription(userID string, subscriptionID int64) error {
+	if _, err := s.ownedSubscription(userID, subscriptionID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(subscriptionID)
+}
+
+func (s *AlertService) GetAlerts(userID string, subscriptionID int64) ([]models.WeatherAlert, error) {
+	if _, err := s.ownedSubscription(userID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAlertsBySubscription(subscriptionID, alertHistoryLimit)
+}
+
+func subscriptionForecastRequest(subscription models.WeatherAlertSubscription) (string, models.ForecastRequest) {
+	if subscription.PolygonID != nil {
+		return PolygonLocationKey(*subscription.PolygonID), models.ForecastRequest{PolygonID: *subscription.PolygonID}
+	}
+	lat, lon := roundCoordinate(*subscription.Latitude), roundCoordinate(*subscription.Longitude)
+	return PointLocationKey(lat, lon), models.ForecastRequest{Lat: &lat, Lon: &lon}
+}
+
+func forecastDayValue(day models.DailyForecastPoint, eventType string) float64 {
+	switch eventType {
+	case models.AlertHeavyRain:
+		return day.Precipitation
+	case models.AlertHeatwave:
+		return day.TemperatureMax
+	default:
+		return day.WindSpeed
+	}
+}
+
+// pendingAlert gathers the subscriptions of one location that breached the same threshold on the same days
+type pendingAlert struct {
+	eventType string
+	threshold float64
+	provider  string
+	dates     []string
+	peak      float64
+	alertIDs  []int64
+	userIDs   map[string]bool
+	farmIDs   map[string]bool
+	regions   map[string]bool
+	request   models.ForecastRequest
+}
+
+// EvaluateAlerts checks the daily forecast of every subscribed location against the subscribed
+// thresholds and pushes one notification per newly forecast breach
+func (s *AlertService) EvaluateAlerts(ctx context.Context) error {
+	if s.repo == nil || s.notificationPublisher == nil {
+		return nil
+	}
+	subscriptions, err := s.repo.GetActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	locations := map[string][]models.WeatherAlertSubscription{}
+	requests := map[string]models.ForecastRequest{}
+	for _, subscription := range subscriptions {
+		// Province and district subscriptions have no forecast of their own, they get warnings only
+		if !subscription.HasLocation() {
+			continue
+		}
+		locationKey, request := subscriptionForecastRequest(subscription)
+		locations[locationKey] = append(locations[locationKey], subscription)
+		requests[locationKey] = request
+	}
+
+	for locationKey, locationSubscriptions := range locations {
+		forecast, err := s.forecastService.GetDailyForecast(requests[locationKey])
+		if err != nil {
+			log.Printf("Error fetching forecast for weather alerts at %s: %v", locationKey, err)
+			continue
+		}
+
+		pending := map[string]*pendingAlert{}
+		for _, subscription := range locationSubscriptions {
+			for _, eventType := range subscription.EventTypes {
+				s.collectBreaches(subscription, eventType, forecast, requests[locationKey], pending)
+			}
+		}
+		for _, alert := range pending {
+			s.publishAlert(ctx, alert)
+		}
+	}
+	return nil
+}
+
+func (s *AlertService) collectBreaches(subscription models.WeatherAlertSubscription, eventType string, forecast *models.DailyForecastResponse, request models.ForecastRequest, pending map[string]*pendingAlert) {
+	threshold := subscription.Threshold(eventType)
+	dates := []string{}
+	alertIDs := []int64{}
+	peak := 0.0
+
+	for _, day := range forecast.Days {
+		value := forecastDayValue(day, eventType)
+		if value < threshold {
+			continue
+		}
+		forecastDate := time.Unix(day.Dt, 0).In(forecastLocation)
+		alert := &models.WeatherAlert{
+			SubscriptionID: subscription.ID,
+			EventType:      eventType,
+			ForecastDate:   time.Date(forecastDate.Year(), forecastDate.Month(), forecastDate.Day(), 0, 0, 0, 0, time.UTC),
+			Value:          value,
+			Threshold:      threshold,
+			Provider:       forecast.Provider,
+		}
+		created, err := s.repo.RecordAlert(alert)
+		if err != nil {
+			log.Printf("Error recording %s alert for subscription %d: %v", eventType, subscription.ID, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		dates = append(dates, alert.ForecastDate.Format("2006-01-02"))
+		alertIDs = append(alertIDs, alert.ID)
+		peak = max(peak, value)
+	}
+	if len(dates) == 0 {
+		return
+	}
+
+	key := fmt.Sprintf("%s|%g|%s", eventType, threshold, strings.Join(dates, ","))
+	alert, ok := pending[key]
+	if !ok {
+		alert = &pendingAlert{
+			eventType: eventType,
+			threshold: threshold,
+			provider:  forecast.Provider,
+			dates:     dates,
+			userIDs:   map[string]bool{},
+			farmIDs:   map[string]bool{},
+			regions:   map[string]bool{},
+			request:   request,
+		}
+		pending[key] = alert
+	}
+	alert.peak = max(alert.peak, peak)
+	alert.alertIDs = append(alert.alertIDs, alertIDs...)
+	alert.userIDs[subscription.UserID] = true
+	if subscription.FarmID != nil {
+		alert.farmIDs[*subscription.FarmID] = true
+	}
+	if subscription.Region != nil {
+		alert.regions[*subscription.Region] = true
+	}
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *AlertService) publishAlert(ctx context.Context, alert *pendingAlert) {
+	firstDate, _ := time.Parse("2006-01-02", alert.dates[0])
+	body := fmt.Sprintf(alertDescriptions[alert.eventType], alert.peak) + " từ ngày " + firstDate.Format("02/01/2006")
+	if len(alert.dates) > 1 {
+		body += fmt.Sprintf(" (%d ngày)", len(alert.dates))
+	}
+	body += ". Vui lòng chủ động phòng tránh cho nông trại."
+
+	data := map[string]any{
+		"type":           "weather_alert",
+		"event_type":     alert.eventType,
+		"forecast_dates": alert.dates,
+		"peak_value":     alert.peak,
+		"threshold":      alert.threshold,
+		"provider":       alert.provider,
+		"farm_ids":       sortedKeys(alert.farmIDs),
+		"regions":        sortedKeys(alert.regions),
+	}
+	if alert.request.PolygonID != "" {
+		data["polygon_id"] = alert.request.PolygonID
+	} else {
+		data["latitude"] = *alert.request.Lat
+		data["longitude"] = *alert.request.Lon
+	}
+
+	notification := event.NotificationEventPushModel{
+		LstUserIds: sortedKeys(alert.userIDs),
+		Title:      alertTitles[alert.eventType],
+		Body:       body,
+		Data:       data,
+	}
+	if err := s.notificationPublisher.PublishNotification(ctx, notification); err != nil {
+		log.Printf("Error publishing %s alert: %v", alert.eventType, err)
+		// Forget the breach so that the next evaluation raises it again
+		if err := s.repo.DeleteAlerts(alert.alertIDs); err != nil {
+			log.Printf("Error discarding unpublished %s alerts: %v", alert.eventType, err)
+		}
+	}
+}
+
+// StartAlertWatcher periodically evaluates alert subscriptions until ctx is cancelled
+func (s *AlertService) StartAlertWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.EvaluateAlerts(ctx); err != nil {
+				log.Printf("Error evaluating weather alerts: %v", err)
+			}
+		}
+	}
+}
+
+func trimmedCode(code *string) *string {
+	if code == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*code)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func trimmedCodes(codes []string) []string {
+	result := []string{}
+	for _, code := range codes {
+		if code = strings.TrimSpace(code); code != "" {
+			result = append(result, code)
+		}
+	}
+	return result
+}
+
+func (s *AlertService) IngestWarning(ctx context.Context, source string, req models.IngestWarningRequest) (*models.WarningIngestResult, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather alert storage is not configured")
+	}
+	if !models.AlertEventTypes[req.EventType] {
+		return nil, fmt.Errorf("invalid event type: %s", req.EventType)
+	}
+	warning := &models.WeatherWarning{
+		Source:        source,
+		ExternalID:    strings.TrimSpace(req.ExternalID),
+		EventType:     req.EventType,
+		Severity:      req.Severity,
+		Title:         strings.TrimSpace(req.Title),
+		Description:   strings.TrimSpace(req.Description),
+		ProvinceCodes: trimmedCodes(req.ProvinceCodes),
+		DistrictCodes: trimmedCodes(req.DistrictCodes),
+		IssuedAt:      req.IssuedAt,
+		ExpiresAt:     req.ExpiresAt,
+	}
+	if warning.ExternalID == "" || warning.Title == "" {
+		return nil, fmt.Errorf("invalid warning: id and title are required")
+	}
+	if len(warning.ProvinceCodes) == 0 && len(warning.DistrictCodes) == 0 {
+		return nil, fmt.Errorf("invalid warning: province_codes or district_codes are required")
+	}
+	if !warning.ExpiresAt.After(warning.IssuedAt) {
+		return nil, fmt.Errorf("invalid warning: expires_at must be after issued_at")
+	}
+	if err := s.repo.UpsertWarning(warning); err != nil {
+		return nil, err
+	}
+
+	result := &models.WarningIngestResult{WarningID: warning.ID}
+	farmIDs, err := s.repo.GetInsuredFarmIDs(warning.ProvinceCodes)
+	if err != nil {
+		return nil, err
+	}
+	result.AffectedFarms = len(farmIDs)
+
+	// An expired warning is kept for the record but no longer pushed
+	if s.notificationPublisher == nil || !time.Now().Before(warning.ExpiresAt) {
+		return result, nil
+	}
+	subscriptions, err := s.repo.GetSubscriptionsForWarning(warning)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionIDs := make([]int64, len(subscriptions))
+	for i, subscription := range subscriptions {
+		subscriptionIDs[i] = subscription.ID
+	}
+	newIDs, err := s.repo.RecordWarningDeliveries(warning.ID, subscriptionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(newIDs) == 0 {
+		return result, nil
+	}
+
+	isNew := map[int64]bool{}
+	for _, id := range newIDs {
+		isNew[id] = true
+	}
+	userIDs, subscribedFarms := map[string]bool{}, map[string]bool{}
+	for _, subscription := range subscriptions {
+		if !isNew[subscription.ID] {
+			continue
+		}
+		userIDs[subscription.UserID] = true
+		if subscription.FarmID != nil {
+			subscribedFarms[*subscription.FarmID] = true
+		}
+	}
+
+	body := warning.Title
+	if warning.Description != "" {
+		body += ". " + warning.Description
+	}
+	notification := event.NotificationEventPushModel{
+		LstUserIds: sortedKeys(userIDs),
+		Title:      alertTitles[warning.EventType],
+		Body:       body,
+		Data: map[string]any{
+			"type":           "weather_warning",
+			"warning_id":     warning.ID,
+			"event_type":     warning.EventType,
+			"severity":       warning.Severity,
+			"province_codes": warning.ProvinceCodes,
+			"district_codes": warning.DistrictCodes,
+			"expires_at":     warning.ExpiresAt,
+			"farm_ids":       sortedKeys(subscribedFarms),
+		},
+	}
+	if err := s.notificationPublisher.PublishNotification(ctx, notification); err != nil {
+		// Forget the deliveries so that the next ingestion pushes the warning again
+		if err := s.repo.DeleteWarningDeliveries(warning.ID, newIDs); err != nil {
+			log.Printf("Error discarding unpublished deliveries of warning %d: %v", warning.ID, err)
+		}
+		return nil, fmt.Errorf("failed to publish weather warning: %w", err)
+	}
+	result.Notified = len(newIDs)
+	log.Printf("Weather warning %s/%s pushed to %d subscriptions, %d insured farms in the area",
+		source, warning.ExternalID, result.Notified, result.AffectedFarms)
+	return result, nil
+}
+
+func (s *AlertService) GetActiveWarnings() ([]models.WeatherWarning, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather alert storage is not configured")
+	}
+	return s.repo.GetActiveWarnings()
+}
+
+func (s *AlertService) ingestFeed(ctx context.Context) {
+	warnings, err := s.warningFeed.ActiveWarnings(ctx)
+	if err != nil {
+		log.Printf("Error fetching official weather warnings: %v", err)
+		return
+	}
+	for _, warning := range warnings {
+		if _, err := s.IngestWarning(ctx, models.WarningSourceNationalMet, warning); err != nil {
+			log.Printf("Error ingesting weather warning %s: %v", warning.ExternalID, err)
+		}
+	}
+}
+
+func (s *AlertService) StartWarningWatcher(ctx context.Context, interval time.Duration) {
+	if s.warningFeed == nil || s.repo == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.ingestFeed(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ingestFeed(ctx)
+		}
+	}
+}
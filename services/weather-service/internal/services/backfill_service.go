@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"weather-service/internal/config"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+	"weather-service/internal/repository"
+
+	"agrisa/parameter"
+)
+
+// Longest range a backfill covers, a year of history with room for leap years and partial months
+const backfillMaxDays = 400
+
+// daySummaryField is a field of the One Call day summary and the local hour its value is stored
+// at. The temperatures are spread over the day as the provider measures them, so that a
+// backfilled day has the temperature hours agronomic parameters need.
+type daySummaryField struct {
+	field     string
+	parameter string
+	hour      int
+}
+
+var daySummaryFields = []daySummaryField{
+	{"temperature.night", models.ParamTemperature, 0},
+	{"temperature.min", models.ParamTemperature, 5},
+	{"temperature.morning", models.ParamTemperature, 6},
+	{"temperature.afternoon", models.ParamTemperature, 12},
+	{"temperature.max", models.ParamTemperature, 14},
+	{"temperature.evening", models.ParamTemperature, 18},
+	{"humidity.afternoon", models.ParamHumidity, 12},
+	{"pressure.afternoon", models.ParamPressure, 12},
+	{"wind.max.speed", models.ParamWindSpeed, 14},
+	{"cloud_cover.afternoon", models.ParamCloudCover, 12},
+	{"precipitation.total", models.ParamPrecipitation, 12},
+}
+
+type BackfillService struct {
+	repo        repository.IObservationRepository
+	agroService IAgroService
+	quality     IQualityService
+	limiter     *quota.Limiter
+	cfg         config.WeatherServiceConfig
+	client      *http.Client
+}
+
+type IBackfillService interface {
+	// Backfill fetches the archived weather of the past days of a polygon that lack history and
+	// returns the daily values of its stored history
+	Backfill(ctx context.Context, req models.PrecipitationRequest) (*models.BackfillResponse, error)
+}
+
+// NewBackfillService creates the service loading the weather history of new locations from the
+// One Call day summary archive
+func NewBackfillService(repo repository.IObservationRepository, agroService IAgroService, quality IQualityService, limiter *quota.Limiter, cfg config.WeatherServiceConfig) IBackfillService {
+	return &BackfillService{
+		repo:        repo,
+		agroService: agroService,
+		quality:     quality,
+		limiter:     limiter,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// fetchDaySummary fetches the One Call day summary of a local date in metric units
+func (s *BackfillService) fetchDaySummary(ctx context.Context, lat, lon float64, date time.Time) (map[string]any, error) {
+	if err := s.limiter.Acquire(ctx, quota.ProviderOneCall); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://api.openweathermap.org/data/3.0/onecall/day_summary?lat=%f&lon=%f&date=%s&tz=%%2B07:00&units=%s&appid=%s",
+		lat, lon, date.Format("2006-01-02"), parameter.SystemMetric, s.cfg.APIKey)
+	resp, err := s.client.Get(url)
+	if err != nil {
+		log.Printf("Error fetching day summary: %v", err)
+		return nil, fmt.Errorf("failed to call API")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading response body: %v", err)
+		return nil, fmt.Errorf("failed to read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("API 3rd party returned non-200 status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API 3rd party error")
+	}
+
+	var summary map[string]any
+	if err := json.Unmarshal(body, &summary); err != nil {
+		log.Println("Error unmarshaling JSON:", err)
+		return nil, fmt.Errorf("failed to parse JSON")
+	}
+	return summary, nil
+}
+
+// daySummaryObservations extracts the readings of a day summary as observations of a polygon.
+// Rainfall is left out for days that already have rainfall readings, which the daily total
+// would count twice.
+func daySummaryObservations(polygonID string, date time.Time, summary map[string]any, withRainfall bool) []models.WeatherObservation {
+	observations := []models.WeatherObservation{}
+	for _, field := range daySummaryFields {
+		if field.parameter == models.ParamPrecipitation && !withRainfall {
+			continue
+		}
+		keys := strings.Split(field.field, ".")
+		fields := summary
+		for _, key := range keys[:len(keys)-1] {
+			fields, _ = fields[key].(map[string]any)
+		}
+		value, ok := numberField(fields, keys[len(keys)-1])
+		if !ok {
+			continue
+		}
+		reading, err := parameter.Normalize(parameter.ProviderDaySummary, field.field, value, parameter.SystemMetric)
+		if err != nil {
+			log.Printf("Skipping day summary reading %s: %v", field.field, err)
+			continue
+		}
+		observedAt := date.Add(time.Duration(field.hour) * time.Hour)
+		observations = append(observations, newPolygonObservation(polygonID, field.parameter, observedAt, reading.Value, SourceDaySummary, false))
+	}
+	return observations
+}
+
+// dailyHistory aggregates the observations of a parameter per local day: rainfall is summed, the
+// temperature is the mean of the extremes and other parameters are averaged
+func dailyHistory(param string, observations []models.WeatherObservation, start time.Time) []models.DataPoint {
+	type day struct {
+		sum, low, high float64
+		count          int
+		scoreSum       float64
+		scoreCount     int
+	}
+	days := map[int64]*day{}
+	for _, observation := range observations {
+		local := observation.ObservedAt.In(forecastLocation)
+		key := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, forecastLocation).Unix()
+		d, ok := days[key]
+		if !ok {
+			d = &day{low: math.Inf(1), high: math.Inf(-1)}
+			days[key] = d
+		}
+		d.sum += observation.Value
+		d.low = math.Min(d.low, observation.Value)
+		d.high = math.Max(d.high, observation.Value)
+		d.count++
+		if observation.ConfidenceScore != nil {
+			d.scoreSum += *observation.ConfidenceScore
+			d.scoreCount++
+		}
+	}
+	rainfall := map[int64]float64{}
+	if param == models.ParamPrecipitation {
+		rainfall, _ = dailyRainfallTotals(observations, start)
+	}
+
+	points := make([]models.DataPoint, 0, len(days))
+	for key, d := range days {
+		value := d.sum / float64(d.count)
+		switch param {
+		case models.ParamPrecipitation:
+			value = rainfall[key]
+		case models.ParamTemperature:
+			value = (d.low + d.high) / 2
+		}
+		point := models.DataPoint{
+			Dt:    key,
+			Data:  math.Round(value*100) / 100,
+			Count: d.count,
+			Unit:  models.ObservationUnits[param],
+		}
+		if d.scoreCount > 0 {
+			score := math.Round(d.scoreSum/float64(d.scoreCount)*1000) / 1000
+			point.ConfidenceScore = &score
+		}
+		points = append(points, point)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Dt < points[j].Dt })
+	return points
+}
+
+// Backfill covers the past days from start to end that have too few temperature hours for the
+// agronomic parameters. At most BackfillDaysPerRequest days are fetched, and fetching stops
+// early while the One Call budget is held back for live lookups, so a backfill may take several
+// requests.
+func (s *BackfillService) Backfill(ctx context.Context, req models.PrecipitationRequest) (*models.BackfillResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	if s.cfg.APIKey == "" {
+		log.Println("API key not configured")
+		return nil, fmt.Errorf("API key not configured")
+	}
+
+	start := time.Unix(req.Start, 0).In(forecastLocation)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, forecastLocation)
+	// Today is still being observed, so the backfill ends with yesterday
+	now := time.Now().In(forecastLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, forecastLocation)
+	end := time.Unix(req.End, 0)
+	if end.After(today) {
+		end = today.Add(-time.Second)
+	}
+	if end.Sub(start) > backfillMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("invalid time range: a backfill covers at most %d days", backfillMaxDays)
+	}
+
+	polygon, _, err := resolvePolygon(s.agroService, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(polygon.Center) != 2 {
+		return nil, fmt.Errorf("polygon %s has no centre", polygon.ID)
+	}
+	// Agro reports the centre as [lon, lat]
+	lat, lon := polygon.Center[1], polygon.Center[0]
+	locationKey := PolygonLocationKey(polygon.ID)
+
+	stored := map[string][]models.WeatherObservation{}
+	for _, param := range []string{models.ParamTemperature, models.ParamPrecipitation} {
+		stored[param], err = s.repo.GetObservations(locationKey, param, start, end, false)
+		if err != nil {
+			log.Printf("Error fetching %s history for backfill of polygon %s: %v", param, polygon.ID, err)
+			return nil, fmt.Errorf("failed to fetch weather history")
+		}
+	}
+	covered := collectDailyWeather(stored)
+	rainfallDays, _ := dailyRainfallTotals(stored[models.ParamPrecipitation], start)
+
+	response := &models.BackfillResponse{
+		PolygonID:     polygon.ID,
+		PolygonName:   polygon.Name,
+		PolygonCenter: polygon.Center,
+		TimeRange:     models.TimeRange{Start: req.Start, End: req.End},
+		Data:          map[string][]models.DataPoint{},
+	}
+	fetching := true
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		response.DaysRequested++
+		if day, ok := covered[date.Unix()]; ok && day.complete() {
+			continue
+		}
+		if fetching && (response.DaysFetched >= s.cfg.BackfillDaysPerRequest || s.limiter.NearExhaustion(ctx, quota.ProviderOneCall)) {
+			fetching = false
+		}
+		if !fetching {
+			response.DaysMissing++
+			continue
+		}
+
+		summary, err := s.fetchDaySummary(ctx, lat, lon, date)
+		if err != nil {
+			log.Printf("Failed to backfill %s of polygon %s: %v", date.Format("2006-01-02"), polygon.ID, err)
+			fetching = false
+			response.DaysMissing++
+			continue
+		}
+		_, hasRainfall := rainfallDays[date.Unix()]
+		observations := daySummaryObservations(polygon.ID, date, summary, !hasRainfall)
+		for i := range observations {
+			score := s.quality.Score(observationQualityInput(observations[i])).Score
+			observations[i].ConfidenceScore = &score
+		}
+		if _, err := s.repo.InsertObservations(observations); err != nil {
+			log.Printf("Error storing backfill of %s for polygon %s: %v", date.Format("2006-01-02"), polygon.ID, err)
+			return nil, fmt.Errorf("failed to store weather history")
+		}
+		response.DaysFetched++
+	}
+	response.Complete = response.DaysMissing == 0
+
+	for param, name := range models.SharedParameters {
+		observations, err := s.repo.GetObservations(locationKey, param, start, end, false)
+		if err != nil {
+			log.Printf("Error fetching %s history for backfill of polygon %s: %v", param, polygon.ID, err)
+			return nil, fmt.Errorf("failed to fetch weather history")
+		}
+		response.Data[string(name)] = dailyHistory(param, observations, start)
+	}
+
+	log.Printf("Backfilled %d of %d days for polygon %s, %d still missing", response.DaysFetched, response.DaysRequested, polygon.ID, response.DaysMissing)
+	return response, nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"weather-service/internal/cache"
+	"weather-service/internal/config"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+	"weather-service/internal/repository"
+
+	"agrisa/parameter"
+)
+
+// Period compared with the normals when none is given
+const defaultClimatologyDays = 30
+
+type ClimatologyService struct {
+	repo        repository.IObservationRepository
+	agroService IAgroService
+	cache       *cache.WeatherCache
+	limiter     *quota.Limiter
+	cfg         config.WeatherServiceConfig
+	client      *http.Client
+}
+
+type IClimatologyService interface {
+	// Compare returns the rainfall and temperature of the days up to now at a location against
+	// their climatological normals
+	Compare(ctx context.Context, req models.ClimatologyRequest) (*models.ClimatologyResponse, error)
+}
+
+// NewClimatologyService creates the service comparing stored history with the long-term monthly
+// statistics of the OpenWeatherMap statistical weather API
+func NewClimatologyService(repo repository.IObservationRepository, agroService IAgroService, weatherCache *cache.WeatherCache, limiter *quota.Limiter, cfg config.WeatherServiceConfig) IClimatologyService {
+	return &ClimatologyService{
+		repo:        repo,
+		agroService: agroService,
+		cache:       weatherCache,
+		limiter:     limiter,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// monthlyStatistics is the part of a statistical weather API month aggregate that normals are
+// read from
+type monthlyStatistics struct {
+	Result struct {
+		Month int            `json:"month"`
+		Temp  map[string]any `json:"temp"`
+		// Statistics of hourly precipitation
+		Precipitation map[string]any `json:"precipitation"`
+	} `json:"result"`
+}
+
+// monthlyNormal returns the normal of a calendar month at a point. Normals are cached for long,
+// so a location costs at most twelve upstream calls.
+func (s *ClimatologyService) monthlyNormal(ctx context.Context, lat, lon float64, month time.Month) (*models.MonthlyNormal, error) {
+	var normal models.MonthlyNormal
+	cacheKey := cache.CoordinateKey(cache.ParamClimate, [][2]float64{{lon, lat}}, strconv.Itoa(int(month)))
+	if s.cache.Get(ctx, cache.ParamClimate, cacheKey, &normal) {
+		return &normal, nil
+	}
+	served, err := reserveCall(ctx, s.limiter, s.cache, quota.ProviderStatistics, cache.ParamClimate, cacheKey, &normal)
+	if err != nil {
+		return nil, err
+	}
+	if served {
+		return &normal, nil
+	}
+
+	cellLat, cellLon := cache.SnapPoint(cache.ParamClimate, lat, lon)
+	url := fmt.Sprintf("https://history.openweathermap.org/data/2.5/aggregated/month?month=%d&lat=%s&lon=%s&appid=%s",
+		int(month), cellLat, cellLon, s.cfg.APIKey)
+	resp, err := s.client.Get(url)
+	if err != nil {
+		log.Printf("Error fetching monthly statistics: %v", err)
+		return nil, fmt.Errorf("failed to call API")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading response body: %v", err)
+		return nil, fmt.Errorf("failed to read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("API 3rd party returned non-200 status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API 3rd party error")
+	}
+
+	var statistics monthlyStatistics
+	if err := json.Unmarshal(body, &statistics); err != nil {
+		log.Println("Error unmarshaling JSON:", err)
+		return nil, fmt.Errorf("failed to parse JSON")
+	}
+
+	normal.Month = int(month)
+	values := normalizedFields(parameter.ProviderStatistics, parameter.SystemStandard, []providerField{
+		{statistics.Result.Temp, "mean", "temp.mean", models.ParamTemperature},
+		{statistics.Result.Precipitation, "mean", "precipitation.mean", models.ParamPrecipitation},
+	})
+	temperature, ok := values[models.ParamTemperature]
+	if !ok {
+		return nil, fmt.Errorf("no temperature normal for month %d", int(month))
+	}
+	normal.Temperature = math.Round(temperature*100) / 100
+	normal.Precipitation = math.Round(values[models.ParamPrecipitation]*24*100) / 100
+
+	s.cache.Set(ctx, cache.ParamClimate, cacheKey, normal)
+	return &normal, nil
+}
+
+func (s *ClimatologyService) Compare(ctx context.Context, req models.ClimatologyRequest) (*models.ClimatologyResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	if s.cfg.APIKey == "" {
+		log.Println("API key not configured")
+		return nil, fmt.Errorf("API key not configured")
+	}
+	days := req.Days
+	if days == 0 {
+		days = defaultClimatologyDays
+	}
+
+	var lat, lon float64
+	if req.PolygonID != "" {
+		polygon, err := s.agroService.GetPolygon(req.PolygonID)
+		if err != nil {
+			return nil, err
+		}
+		if len(polygon.Center) != 2 {
+			return nil, fmt.Errorf("polygon %s has no centre", polygon.ID)
+		}
+		// Agro reports the centre as [lon, lat]
+		lat, lon = polygon.Center[1], polygon.Center[0]
+	} else {
+		lat, lon = *req.Lat, *req.Lon
+	}
+	locationKey := historyLocationKey(req.PolygonID, req.Lat, req.Lon)
+
+	// Today is still being observed, so the period ends with yesterday
+	now := time.Now().In(forecastLocation)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, forecastLocation)
+	start := end.AddDate(0, 0, -days)
+	history := map[string][]models.WeatherObservation{}
+	for _, param := range []string{models.ParamPrecipitation, models.ParamTemperature} {
+		observations, err := s.repo.GetObservations(locationKey, param, start, end.Add(-time.Second), false)
+		if err != nil {
+			log.Printf("Error fetching %s history for climatology of %s: %v", param, locationKey, err)
+			return nil, fmt.Errorf("failed to fetch weather history")
+		}
+		history[param] = observations
+	}
+	rainfall, _ := dailyRainfallTotals(history[models.ParamPrecipitation], start)
+	temperatures := collectDailyWeather(map[string][]models.WeatherObservation{models.ParamTemperature: history[models.ParamTemperature]})
+
+	response := &models.ClimatologyResponse{
+		LocationKey: locationKey,
+		Latitude:    lat,
+		Longitude:   lon,
+		TimeRange:   models.TimeRange{Start: start.Unix(), End: end.Unix()},
+		Days:        days,
+		Rainfall:    models.ClimatologyComparison{Parameter: string(parameter.RainFall), Unit: string(parameter.RainFall.Unit())},
+		Temperature: models.ClimatologyComparison{Parameter: string(parameter.Temperature), Unit: string(parameter.Temperature.Unit())},
+		Normals:     []models.MonthlyNormal{},
+	}
+
+	normals := map[time.Month]*models.MonthlyNormal{}
+	rainfallTotal, rainfallNormal := 0.0, 0.0
+	temperatureSum, temperatureNormalSum := 0.0, 0.0
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		normal, ok := normals[date.Month()]
+		if !ok {
+			var err error
+			normal, err = s.monthlyNormal(ctx, lat, lon, date.Month())
+			if err != nil {
+				log.Printf("Failed to fetch climatological normal of month %d at %s: %v", int(date.Month()), locationKey, err)
+				return nil, err
+			}
+			normals[date.Month()] = normal
+			response.Normals = append(response.Normals, *normal)
+		}
+
+		if amount, ok := rainfall[date.Unix()]; ok {
+			rainfallTotal += amount
+			rainfallNormal += normal.Precipitation
+			response.Rainfall.DaysCovered++
+		}
+		if day, ok := temperatures[date.Unix()]; ok && day.complete() {
+			temperatureSum += (day.tMin + day.tMax) / 2
+			temperatureNormalSum += normal.Temperature
+			response.Temperature.DaysCovered++
+		}
+	}
+
+	round := func(value float64) *float64 {
+		rounded := math.Round(value*100) / 100
+		return &rounded
+	}
+	if response.Rainfall.DaysCovered > 0 {
+		response.Rainfall.Current = round(rainfallTotal)
+		response.Rainfall.Normal = round(rainfallNormal)
+		response.Rainfall.Anomaly = round(rainfallTotal - rainfallNormal)
+		if rainfallNormal > 0 {
+			response.Rainfall.PercentOfNormal = round(rainfallTotal / rainfallNormal * 100)
+		}
+	}
+	if covered := float64(response.Temperature.DaysCovered); covered > 0 {
+		response.Temperature.Current = round(temperatureSum / covered)
+		response.Temperature.Normal = round(temperatureNormalSum / covered)
+		response.Temperature.Anomaly = round((temperatureSum - temperatureNormalSum) / covered)
+	}
+
+	log.Printf("Compared %d days at %s with climatological normals", days, locationKey)
+	return response, nil
+}
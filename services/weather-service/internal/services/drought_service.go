@@ -0,0 +1,288 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+)
+
+const (
+	// Longest SPI accumulation period accepted
+	spiMaxScaleMonths = 24
+	// Years of history the distribution is fitted on
+	spiLookbackYears = 30
+	// Accumulation samples needed before a distribution is fitted
+	spiMinSamples = 12
+	// From this many years of history each calendar month gets its own distribution, as in the
+	// standard SPI; shorter histories pool all months together
+	spiStratifyYears = 10
+	// Probabilities are clamped so that SPI stays within about ±3.7
+	spiProbabilityBound = 0.0001
+)
+
+type DroughtService struct {
+	repo        repository.IObservationRepository
+	agroService IAgroService
+}
+
+type IDroughtService interface {
+	GetSPI(req models.PrecipitationRequest, scaleMonths int) (*models.UnifiedAPIResponse, error)
+}
+
+// NewDroughtService creates the service computing drought indices from stored precipitation history
+func NewDroughtService(repo repository.IObservationRepository, agroService IAgroService) IDroughtService {
+	return &DroughtService{repo: repo, agroService: agroService}
+}
+
+// gammaFit is a two-parameter gamma distribution with a probability of zero rainfall
+type gammaFit struct {
+	alpha, beta float64
+	zeros, n    int
+}
+
+// fitGamma estimates the gamma distribution of non-zero samples with Thom's maximum likelihood
+// approximation. It reports false when the samples cannot be fitted.
+func fitGamma(samples []float64) (gammaFit, bool) {
+	fit := gammaFit{n: len(samples)}
+	sum, logSum := 0.0, 0.0
+	nonZero := 0
+	for _, sample := range samples {
+		if sample <= 0 {
+			fit.zeros++
+			continue
+		}
+		sum += sample
+		logSum += math.Log(sample)
+		nonZero++
+	}
+	if nonZero < 2 {
+		return fit, false
+	}
+
+	mean := sum / float64(nonZero)
+	a := math.Log(mean) - logSum/float64(nonZero)
+	if a <= 0 {
+		return fit, false
+	}
+	fit.alpha = (1 + math.Sqrt(1+4*a/3)) / (4 * a)
+	fit.beta = mean / fit.alpha
+	return fit, true
+}
+
+// probability is the cumulative probability of an accumulation. Zero accumulations take the centre
+// of the zero mass (Stagge et al., 2015) so that dry periods do not all map to the same extreme.
+func (f gammaFit) probability(value float64) float64 {
+	q := float64(f.zeros) / float64(f.n)
+	if value <= 0 {
+		return float64(f.zeros+1) / float64(2*(f.n+1))
+	}
+	return q + (1-q)*regularizedGammaP(f.alpha, value/f.beta)
+}
+
+// regularizedGammaP is the regularized lower incomplete gamma function P(a, x)
+func regularizedGammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	logGammaA, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - logGammaA)
+
+	if x < a+1 {
+		// Series expansion
+		term := 1 / a
+		sum := term
+		for n := 1; n < 500; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-14 {
+				break
+			}
+		}
+		return sum * prefix
+	}
+
+	// Continued fraction of the upper function, by the modified Lentz method
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 500; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-14 {
+			break
+		}
+	}
+	return 1 - prefix*h
+}
+
+// standardNormalQuantile is the inverse of the standard normal distribution function
+func standardNormalQuantile(p float64) float64 {
+	p = math.Min(math.Max(p, spiProbabilityBound), 1-spiProbabilityBound)
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+func monthStart(t time.Time) time.Time {
+	local := t.In(forecastLocation)
+	return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, forecastLocation)
+}
+
+// resolvePolygon reuses the requested polygon or creates one from the corner coordinates
+func resolvePolygon(agroService IAgroService, req models.PrecipitationRequest) (*models.AgroPolygonResponse, bool, error) {
+	if req.PolygonID != "" {
+		polygon, err := agroService.GetPolygon(req.PolygonID)
+		if err == nil {
+			return polygon, true, nil
+		}
+		log.Printf("Failed to retrieve polygon %s: %v. Will create new polygon.", req.PolygonID, err)
+	}
+
+	coordinates := [][2]float64{
+		{req.Lon1, req.Lat1},
+		{req.Lon2, req.Lat2},
+		{req.Lon3, req.Lat3},
+		{req.Lon4, req.Lat4},
+	}
+	polygon, err := agroService.CreatePolygon(fmt.Sprintf("temp_polygon_%d", time.Now().Unix()), coordinates)
+	if err != nil {
+		return nil, false, err
+	}
+	return polygon, false, nil
+}
+
+// GetSPI computes the Standardized Precipitation Index of a polygon for each month from start to
+// end, over accumulation periods of scaleMonths. Rainfall is accumulated from the stored observed
+// history; accumulation periods with a month lacking any reading are left out, and nothing is
+// returned until enough history exists to fit the distribution.
+func (s *DroughtService) GetSPI(req models.PrecipitationRequest, scaleMonths int) (*models.UnifiedAPIResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	if scaleMonths < 1 || scaleMonths > spiMaxScaleMonths {
+		return nil, fmt.Errorf("invalid scale: must be between 1 and %d months", spiMaxScaleMonths)
+	}
+
+	polygon, reused, err := resolvePolygon(s.agroService, req)
+	if err != nil {
+		return nil, err
+	}
+	// Record the latest reading, so that polling the index also grows the history it is computed on
+	if _, err := s.agroService.GetCurrentWeather(polygon.ID); err != nil {
+		log.Printf("Failed to refresh current weather for SPI of polygon %s: %v", polygon.ID, err)
+	}
+
+	end := time.Unix(req.End, 0)
+	observations, err := s.repo.GetObservations(PolygonLocationKey(polygon.ID), models.ParamPrecipitation,
+		end.AddDate(-spiLookbackYears, 0, 0), end, false)
+	if err != nil {
+		log.Printf("Error fetching precipitation history for SPI of polygon %s: %v", polygon.ID, err)
+		return nil, fmt.Errorf("failed to fetch precipitation history")
+	}
+
+	// Monthly totals and the number of days with readings in each month
+	dailyTotals, _ := dailyRainfallTotals(observations, time.Time{})
+	monthlyTotals := map[int64]float64{}
+	monthlyDays := map[int64]int{}
+	var firstMonth time.Time
+	for day, total := range dailyTotals {
+		month := monthStart(time.Unix(day, 0))
+		monthlyTotals[month.Unix()] += total
+		monthlyDays[month.Unix()]++
+		if firstMonth.IsZero() || month.Before(firstMonth) {
+			firstMonth = month
+		}
+	}
+
+	// Accumulations over scaleMonths ending at each month, where every month has readings
+	accumulations := map[int64]float64{}
+	accumulationDays := map[int64]int{}
+	lastMonth := monthStart(end)
+	for month := firstMonth; !firstMonth.IsZero() && !month.After(lastMonth); month = month.AddDate(0, 1, 0) {
+		total, days := 0.0, 0
+		complete := true
+		for offset := 0; offset < scaleMonths; offset++ {
+			key := month.AddDate(0, -offset, 0).Unix()
+			if monthlyDays[key] == 0 {
+				complete = false
+				break
+			}
+			total += monthlyTotals[key]
+			days += monthlyDays[key]
+		}
+		if complete {
+			accumulations[month.Unix()] = total
+			accumulationDays[month.Unix()] = days
+		}
+	}
+
+	stratify := !firstMonth.IsZero() && lastMonth.Sub(firstMonth) >= spiStratifyYears*365*24*time.Hour
+	samples := map[time.Month][]float64{}
+	for month, total := range accumulations {
+		calendarMonth := time.Month(0)
+		if stratify {
+			calendarMonth = time.Unix(month, 0).In(forecastLocation).Month()
+		}
+		samples[calendarMonth] = append(samples[calendarMonth], total)
+	}
+	fits := map[time.Month]gammaFit{}
+	for calendarMonth, monthSamples := range samples {
+		if len(monthSamples) < spiMinSamples {
+			continue
+		}
+		if fit, ok := fitGamma(monthSamples); ok {
+			fits[calendarMonth] = fit
+		}
+	}
+
+	response := &models.UnifiedAPIResponse{
+		PolygonID:         polygon.ID,
+		PolygonName:       polygon.Name,
+		PolygonCenter:     polygon.Center,
+		PolygonArea:       polygon.Area,
+		PolygonReused:     reused,
+		PolygonCreatedNew: !reused,
+		TimeRange:         models.TimeRange{Start: req.Start, End: req.End},
+		Data:              []models.DataPoint{},
+	}
+	for month := monthStart(time.Unix(req.Start, 0)); !month.After(lastMonth); month = month.AddDate(0, 1, 0) {
+		total, ok := accumulations[month.Unix()]
+		if !ok {
+			continue
+		}
+		calendarMonth := time.Month(0)
+		if stratify {
+			calendarMonth = month.Month()
+		}
+		fit, ok := fits[calendarMonth]
+		if !ok {
+			continue
+		}
+		spi := math.Round(standardNormalQuantile(fit.probability(total))*100) / 100
+		response.Data = append(response.Data, models.DataPoint{
+			Dt:    month.Unix(),
+			Data:  spi,
+			Count: accumulationDays[month.Unix()],
+			Unit:  "spi",
+		})
+		response.TotalDataValue += spi
+	}
+	response.DataPointCount = len(response.Data)
+
+	log.Printf("Computed %d SPI-%d values for polygon %s from %d monthly accumulations", response.DataPointCount, scaleMonths, polygon.ID, len(accumulations))
+	return response, nil
+}
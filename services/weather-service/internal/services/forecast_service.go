@@ -0,0 +1,240 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"weather-service/internal/models"
+
+	"agrisa/parameter"
+)
+
+const (
+	// Days returned by the daily forecast; Agro only forecasts 5 days ahead
+	forecastDays = 7
+	// Steps of the Agro forecast
+	agroForecastStepHours = 3
+	// One Call fields the forecasts do not use
+	forecastExclude = "current,minutely,alerts"
+)
+
+// Agro does not report a timezone for polygons, so forecast days are taken in Vietnam time
+var forecastLocation = time.FixedZone("ICT", 7*60*60)
+
+type ForecastService struct {
+	weatherService IWeatherService
+	agroService    IAgroService
+}
+
+type IForecastService interface {
+	GetHourlyForecast(req models.ForecastRequest) (*models.HourlyForecastResponse, error)
+	GetDailyForecast(req models.ForecastRequest) (*models.DailyForecastResponse, error)
+}
+
+// NewForecastService creates the forecast service. Polygon forecasts come from Agro and point
+// forecasts from One Call; both are normalized to the same units.
+func NewForecastService(weatherService IWeatherService, agroService IAgroService) IForecastService {
+	return &ForecastService{weatherService: weatherService, agroService: agroService}
+}
+
+func fieldNumber(fields map[string]any, key string) float64 {
+	value, _ := numberField(fields, key)
+	return value
+}
+
+// fieldPrecipitation reads a rain or snow field that is either an amount or a map of amounts per period
+func fieldPrecipitation(fields map[string]any, key, period string) float64 {
+	switch value := fields[key].(type) {
+	case float64:
+		return value
+	case map[string]any:
+		return fieldNumber(value, period)
+	}
+	return 0
+}
+
+func weatherDescription(conditions []map[string]any) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	description, _ := conditions[0]["description"].(string)
+	return description
+}
+
+func mapSlice(value any) []map[string]any {
+	items, _ := value.([]any)
+	result := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if fields, ok := item.(map[string]any); ok {
+			result = append(result, fields)
+		}
+	}
+	return result
+}
+
+func (s *ForecastService) fetchOneCall(req models.ForecastRequest) (*WeatherResponse, error) {
+	lat := strconv.FormatFloat(*req.Lat, 'f', -1, 64)
+	lon := strconv.FormatFloat(*req.Lon, 'f', -1, 64)
+	return s.weatherService.FetchWeatherData(lat, lon, forecastExclude, "metric", req.Lang)
+}
+
+// agroCelsius converts an Agro temperature, always reported in kelvin
+func agroCelsius(kelvin float64) float64 {
+	celsius, _ := parameter.Convert(kelvin, parameter.UnitKelvin, parameter.UnitCelsius)
+	return celsius
+}
+
+func agroHourlyPoint(step models.ForecastWeatherResponse) models.HourlyForecastPoint {
+	return models.HourlyForecastPoint{
+		Dt:                       step.Dt,
+		Temperature:              agroCelsius(fieldNumber(step.Main, "temp")),
+		FeelsLike:                agroCelsius(fieldNumber(step.Main, "feels_like")),
+		Humidity:                 fieldNumber(step.Main, "humidity"),
+		Pressure:                 fieldNumber(step.Main, "pressure"),
+		WindSpeed:                fieldNumber(step.Wind, "speed"),
+		CloudCover:               fieldNumber(step.Clouds, "all"),
+		Precipitation:            precipitationAmount(step.Rain, step.Snow),
+		PrecipitationProbability: step.Pop,
+		Description:              weatherDescription(step.Weather),
+	}
+}
+
+func (s *ForecastService) GetHourlyForecast(req models.ForecastRequest) (*models.HourlyForecastResponse, error) {
+	response := &models.HourlyForecastResponse{PolygonID: req.PolygonID, Lat: req.Lat, Lon: req.Lon}
+
+	if req.PolygonID != "" {
+		forecast, err := s.agroService.GetForecast(req.PolygonID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+		}
+		response.Provider = models.ForecastProviderAgro
+		response.StepHours = agroForecastStepHours
+		for _, step := range forecast {
+			response.Points = append(response.Points, agroHourlyPoint(step))
+		}
+	} else {
+		weather, err := s.fetchOneCall(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+		}
+		response.Provider = models.ForecastProviderOneCall
+		response.StepHours = 1
+		for _, hour := range weather.Hourly {
+			response.Points = append(response.Points, models.HourlyForecastPoint{
+				Dt:                       int64(fieldNumber(hour, "dt")),
+				Temperature:              fieldNumber(hour, "temp"),
+				FeelsLike:                fieldNumber(hour, "feels_like"),
+				Humidity:                 fieldNumber(hour, "humidity"),
+				Pressure:                 fieldNumber(hour, "pressure"),
+				WindSpeed:                fieldNumber(hour, "wind_speed"),
+				CloudCover:               fieldNumber(hour, "clouds"),
+				Precipitation:            fieldPrecipitation(hour, "rain", "1h") + fieldPrecipitation(hour, "snow", "1h"),
+				PrecipitationProbability: fieldNumber(hour, "pop"),
+				Description:              weatherDescription(mapSlice(hour["weather"])),
+			})
+		}
+	}
+
+	if response.Points == nil {
+		response.Points = []models.HourlyForecastPoint{}
+	}
+	for _, point := range response.Points {
+		response.TotalPrecipitation += point.Precipitation
+	}
+	response.DataPointCount = len(response.Points)
+	return response, nil
+}
+
+func (s *ForecastService) GetDailyForecast(req models.ForecastRequest) (*models.DailyForecastResponse, error) {
+	response := &models.DailyForecastResponse{PolygonID: req.PolygonID, Lat: req.Lat, Lon: req.Lon}
+
+	if req.PolygonID != "" {
+		forecast, err := s.agroService.GetForecast(req.PolygonID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+		}
+		response.Provider = models.ForecastProviderAgro
+		response.Days = agroDailyForecast(forecast)
+	} else {
+		weather, err := s.fetchOneCall(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+		}
+		response.Provider = models.ForecastProviderOneCall
+		for _, day := range weather.Daily {
+			temperature, _ := day["temp"].(map[string]any)
+			response.Days = append(response.Days, models.DailyForecastPoint{
+				Dt:                       int64(fieldNumber(day, "dt")),
+				TemperatureMin:           fieldNumber(temperature, "min"),
+				TemperatureMax:           fieldNumber(temperature, "max"),
+				Humidity:                 fieldNumber(day, "humidity"),
+				WindSpeed:                fieldNumber(day, "wind_speed"),
+				CloudCover:               fieldNumber(day, "clouds"),
+				Precipitation:            fieldPrecipitation(day, "rain", "") + fieldPrecipitation(day, "snow", ""),
+				PrecipitationProbability: fieldNumber(day, "pop"),
+				Description:              weatherDescription(mapSlice(day["weather"])),
+			})
+		}
+	}
+
+	if len(response.Days) > forecastDays {
+		response.Days = response.Days[:forecastDays]
+	}
+	if response.Days == nil {
+		response.Days = []models.DailyForecastPoint{}
+	}
+	for _, day := range response.Days {
+		response.TotalPrecipitation += day.Precipitation
+	}
+	response.DataPointCount = len(response.Days)
+	return response, nil
+}
+
+// agroDailyForecast aggregates 3-hour steps into days: temperature extremes, mean humidity and
+// cloud cover, peak wind and rain probability, and total precipitation
+func agroDailyForecast(forecast []models.ForecastWeatherResponse) []models.DailyForecastPoint {
+	days := []models.DailyForecastPoint{}
+	steps := 0
+	for _, step := range forecast {
+		stepTime := time.Unix(step.Dt, 0).In(forecastLocation)
+		dayStart := time.Date(stepTime.Year(), stepTime.Month(), stepTime.Day(), 0, 0, 0, 0, forecastLocation).Unix()
+		point := agroHourlyPoint(step)
+		minTemp := agroCelsius(fieldNumber(step.Main, "temp_min"))
+		maxTemp := agroCelsius(fieldNumber(step.Main, "temp_max"))
+
+		if len(days) == 0 || days[len(days)-1].Dt != dayStart {
+			if len(days) > 0 {
+				finishAgroDay(&days[len(days)-1], steps)
+			}
+			days = append(days, models.DailyForecastPoint{
+				Dt:             dayStart,
+				TemperatureMin: minTemp,
+				TemperatureMax: maxTemp,
+				Description:    point.Description,
+			})
+			steps = 0
+		}
+
+		day := &days[len(days)-1]
+		day.TemperatureMin = min(day.TemperatureMin, minTemp)
+		day.TemperatureMax = max(day.TemperatureMax, maxTemp)
+		day.Humidity += point.Humidity
+		day.CloudCover += point.CloudCover
+		day.WindSpeed = max(day.WindSpeed, point.WindSpeed)
+		day.Precipitation += point.Precipitation
+		day.PrecipitationProbability = max(day.PrecipitationProbability, point.PrecipitationProbability)
+		steps++
+	}
+	if len(days) > 0 {
+		finishAgroDay(&days[len(days)-1], steps)
+	}
+	return days
+}
+
+func finishAgroDay(day *models.DailyForecastPoint, steps int) {
+	if steps == 0 {
+		return
+	}
+	day.Humidity /= float64(steps)
+	day.CloudCover /= float64(steps)
+}
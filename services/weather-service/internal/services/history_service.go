@@ -0,0 +1,536 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"weather-service/internal/config"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+
+	"agrisa/parameter"
+)
+
+// Point lookups are stored on a ~1.1 km grid so nearby farms share a history
+const observationPrecision = 2
+
+// Upstream sources of stored observations
+const (
+	SourceOneCall      = "openweathermap_onecall"
+	SourceAgroCurrent  = "agro_current"
+	SourceAgroForecast = "agro_forecast"
+	SourceDaySummary   = "openweathermap_day_summary"
+	SourceOpenMeteo    = "open_meteo"
+	SourceNationalMet  = "national_met"
+)
+
+type HistoryService struct {
+	repo    repository.IObservationRepository
+	quality IQualityService
+	cfg     config.WeatherServiceConfig
+}
+
+type IHistoryService interface {
+	Record(observations []models.WeatherObservation)
+	GetHistory(req models.HistoryRequest) (*models.HistoryResponse, error)
+	GetRainfallAccumulation(req models.RainfallAccumulationRequest) (*models.RainfallAccumulationResponse, error)
+	GetAggregate(req models.AggregateRequest) (*models.AggregateResponse, error)
+	PurgeExpired() error
+	StartRetentionWatcher(ctx context.Context, interval time.Duration)
+}
+
+// NewHistoryService creates the history service. With a nil repository nothing is stored and
+// history queries fail, so the service still serves live data without its database.
+func NewHistoryService(repo repository.IObservationRepository, quality IQualityService, cfg config.WeatherServiceConfig) IHistoryService {
+	return &HistoryService{repo: repo, quality: quality, cfg: cfg}
+}
+
+func roundCoordinate(value float64) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'f', observationPrecision, 64), 64)
+	return rounded
+}
+
+// PointLocationKey is the history location of a lat/lon lookup
+func PointLocationKey(lat, lon float64) string {
+	return strconv.FormatFloat(lon, 'f', observationPrecision, 64) + "," + strconv.FormatFloat(lat, 'f', observationPrecision, 64)
+}
+
+// PolygonLocationKey is the history location of a polygon lookup
+func PolygonLocationKey(polygonID string) string {
+	return "polygon:" + polygonID
+}
+
+func historyLocationKey(polygonID string, lat, lon *float64) string {
+	if polygonID != "" {
+		return PolygonLocationKey(polygonID)
+	}
+	return PointLocationKey(*lat, *lon)
+}
+
+func newPointObservation(lat, lon float64, parameter string, observedAt time.Time, value float64, source string) models.WeatherObservation {
+	roundedLat, roundedLon := roundCoordinate(lat), roundCoordinate(lon)
+	return models.WeatherObservation{
+		LocationKey: PointLocationKey(lat, lon),
+		Latitude:    &roundedLat,
+		Longitude:   &roundedLon,
+		Parameter:   parameter,
+		ObservedAt:  observedAt.UTC(),
+		Value:       value,
+		Unit:        models.ObservationUnits[parameter],
+		Source:      source,
+	}
+}
+
+func newPolygonObservation(polygonID, parameter string, observedAt time.Time, value float64, source string, isForecast bool) models.WeatherObservation {
+	return models.WeatherObservation{
+		LocationKey: PolygonLocationKey(polygonID),
+		PolygonID:   &polygonID,
+		Parameter:   parameter,
+		ObservedAt:  observedAt.UTC(),
+		Value:       value,
+		Unit:        models.ObservationUnits[parameter],
+		Source:      source,
+		IsForecast:  isForecast,
+	}
+}
+
+func numberField(fields map[string]any, key string) (float64, bool) {
+	value, ok := fields[key].(float64)
+	return value, ok
+}
+
+// precipitationAmount sums rain and snow over the period the provider reports, in mm
+func precipitationAmount(rain, snow map[string]float64) float64 {
+	total := 0.0
+	for _, amounts := range []map[string]float64{rain, snow} {
+		if value, ok := amounts["1h"]; ok {
+			total += value
+		} else if value, ok := amounts["3h"]; ok {
+			total += value
+		}
+	}
+	return total
+}
+
+// providerField is a provider reading field and the history parameter it is stored as
+type providerField struct {
+	fields    map[string]any
+	key       string
+	field     string
+	parameter string
+}
+
+// normalizedFields reads the fields present in a provider response, normalized to the shared
+// units. The units system only applies to One Call; Agro always reports standard units.
+func normalizedFields(provider, units string, fields []providerField) map[string]float64 {
+	values := map[string]float64{}
+	for _, field := range fields {
+		value, ok := numberField(field.fields, field.key)
+		if !ok {
+			continue
+		}
+		reading, err := parameter.Normalize(provider, field.field, value, units)
+		if err != nil {
+			log.Printf("Skipping %s reading %s: %v", provider, field.field, err)
+			continue
+		}
+		values[field.parameter] = reading.Value
+	}
+	return values
+}
+
+// Parameters read from provider fields, in the order their observations are built
+var fieldParameters = []string{models.ParamTemperature, models.ParamHumidity, models.ParamPressure, models.ParamWindSpeed, models.ParamCloudCover}
+
+// oneCallObservations extracts the current readings of a One Call response
+func oneCallObservations(lat, lon float64, units string, weather *WeatherResponse) []models.WeatherObservation {
+	current := weather.Current
+	dt, ok := numberField(current, "dt")
+	if !ok {
+		return nil
+	}
+	observedAt := time.Unix(int64(dt), 0)
+
+	values := normalizedFields(parameter.ProviderOneCall, units, []providerField{
+		{current, "temp", "temp", models.ParamTemperature},
+		{current, "humidity", "humidity", models.ParamHumidity},
+		{current, "pressure", "pressure", models.ParamPressure},
+		{current, "wind_speed", "wind_speed", models.ParamWindSpeed},
+		{current, "clouds", "clouds", models.ParamCloudCover},
+	})
+	observations := []models.WeatherObservation{}
+	for _, param := range fieldParameters {
+		if value, ok := values[param]; ok {
+			observations = append(observations, newPointObservation(lat, lon, param, observedAt, value, SourceOneCall))
+		}
+	}
+
+	rain, snow := map[string]float64{}, map[string]float64{}
+	for key, target := range map[string]map[string]float64{"rain": rain, "snow": snow} {
+		if amounts, ok := current[key].(map[string]any); ok {
+			for period, amount := range amounts {
+				if value, ok := amount.(float64); ok {
+					target[period] = value
+				}
+			}
+		}
+	}
+	observations = append(observations, newPointObservation(lat, lon, models.ParamPrecipitation, observedAt, precipitationAmount(rain, snow), SourceOneCall))
+	return observations
+}
+
+// agroCurrentObservations extracts the readings of an Agro current weather response
+func agroCurrentObservations(polygonID string, weather *models.CurrentWeatherResponse) []models.WeatherObservation {
+	if weather.Dt == 0 {
+		return nil
+	}
+	observedAt := time.Unix(weather.Dt, 0)
+
+	values := normalizedFields(parameter.ProviderAgro, parameter.SystemStandard, []providerField{
+		{weather.Main, "temp", "main.temp", models.ParamTemperature},
+		{weather.Main, "humidity", "main.humidity", models.ParamHumidity},
+		{weather.Main, "pressure", "main.pressure", models.ParamPressure},
+		{weather.Wind, "speed", "wind.speed", models.ParamWindSpeed},
+		{weather.Clouds, "all", "clouds.all", models.ParamCloudCover},
+	})
+	observations := []models.WeatherObservation{}
+	for _, param := range fieldParameters {
+		if value, ok := values[param]; ok {
+			observations = append(observations, newPolygonObservation(polygonID, param, observedAt, value, SourceAgroCurrent, false))
+		}
+	}
+	observations = append(observations, newPolygonObservation(polygonID, models.ParamPrecipitation, observedAt, precipitationAmount(weather.Rain, weather.Snow), SourceAgroCurrent, false))
+	return observations
+}
+
+// agroForecastObservations extracts the 3-hourly precipitation of an Agro forecast, dry periods included
+func agroForecastObservations(polygonID string, forecast []models.ForecastWeatherResponse) []models.WeatherObservation {
+	observations := make([]models.WeatherObservation, 0, len(forecast))
+	for _, point := range forecast {
+		observations = append(observations, newPolygonObservation(polygonID, models.ParamPrecipitation, time.Unix(point.Dt, 0), precipitationAmount(point.Rain, point.Snow), SourceAgroForecast, true))
+	}
+	return observations
+}
+
+func observationQualityInput(observation models.WeatherObservation) models.QualityInput {
+	return models.QualityInput{
+		Source:      observation.Source,
+		Parameter:   observation.Parameter,
+		LocationKey: observation.LocationKey,
+		ObservedAt:  observation.ObservedAt,
+		Value:       observation.Value,
+		IsForecast:  observation.IsForecast,
+		Lat:         observation.Latitude,
+		Lon:         observation.Longitude,
+	}
+}
+
+// Record scores and stores observations in the background so that live responses are not delayed
+func (s *HistoryService) Record(observations []models.WeatherObservation) {
+	if s.repo == nil || len(observations) == 0 {
+		return
+	}
+	go func() {
+		for i := range observations {
+			score := s.quality.Score(observationQualityInput(observations[i])).Score
+			observations[i].ConfidenceScore = &score
+		}
+		stored, err := s.repo.InsertObservations(observations)
+		if err != nil {
+			log.Printf("Error storing %d weather observations: %v", len(observations), err)
+			return
+		}
+		log.Printf("Stored %d of %d weather observations for %s", stored, len(observations), observations[0].LocationKey)
+	}()
+}
+
+func (s *HistoryService) GetHistory(req models.HistoryRequest) (*models.HistoryResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	unit, ok := models.ObservationUnits[req.Parameter]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter: %s", req.Parameter)
+	}
+
+	locationKey := historyLocationKey(req.PolygonID, req.Lat, req.Lon)
+	observations, err := s.repo.GetObservations(locationKey, req.Parameter, time.Unix(req.Start, 0), time.Unix(req.End, 0), req.IncludeForecast)
+	if err != nil {
+		log.Printf("Error fetching weather history for %s: %v", locationKey, err)
+		return nil, fmt.Errorf("failed to fetch weather history")
+	}
+
+	return &models.HistoryResponse{
+		LocationKey: locationKey,
+		Parameter:   req.Parameter,
+		Unit:        unit,
+		TimeRange: models.TimeRange{
+			Start: req.Start,
+			End:   req.End,
+		},
+		Observations: observations,
+		Count:        len(observations),
+	}, nil
+}
+
+// Rainfall windows returned when none are requested
+var defaultRainfallWindows = []int{7, 14, 30}
+
+// Aggregate applies a trigger aggregation function to values in time order, with the semantics
+// of policy-service: no values give 0, change needs two values and unknown functions give the latest
+func Aggregate(values []float64, function string) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	switch function {
+	case models.AggregationSum, models.AggregationAvg:
+		sum := 0.0
+		for _, value := range values {
+			sum += value
+		}
+		if function == models.AggregationAvg {
+			return sum / float64(len(values))
+		}
+		return sum
+	case models.AggregationMin:
+		result := values[0]
+		for _, value := range values[1:] {
+			result = min(result, value)
+		}
+		return result
+	case models.AggregationMax:
+		result := values[0]
+		for _, value := range values[1:] {
+			result = max(result, value)
+		}
+		return result
+	case models.AggregationChange:
+		if len(values) < 2 {
+			return 0
+		}
+		return values[len(values)-1] - values[0]
+	default:
+		return values[len(values)-1]
+	}
+}
+
+func parseRainfallWindows(windows string) ([]int, error) {
+	if windows == "" {
+		return defaultRainfallWindows, nil
+	}
+	days := []int{}
+	for _, window := range strings.Split(windows, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(window))
+		if err != nil || value <= 0 || value > 366 {
+			return nil, fmt.Errorf("invalid window: %s", window)
+		}
+		days = append(days, value)
+	}
+	return days, nil
+}
+
+// dailyRainfallTotals sums rainfall readings taken at or after start into totals per local day.
+// Readings hold the last hour's rainfall and may be taken several times an hour, so each hour
+// counts once with its largest reading. It also returns the number of readings used.
+func dailyRainfallTotals(observations []models.WeatherObservation, start time.Time) (map[int64]float64, int) {
+	hourlyAmounts := map[time.Time]float64{}
+	dataPoints := 0
+	for _, observation := range observations {
+		if observation.ObservedAt.Before(start) {
+			continue
+		}
+		hour := observation.ObservedAt.Truncate(time.Hour)
+		hourlyAmounts[hour] = max(hourlyAmounts[hour], observation.Value)
+		dataPoints++
+	}
+
+	dailyTotals := map[int64]float64{}
+	for hour, amount := range hourlyAmounts {
+		local := hour.In(forecastLocation)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, forecastLocation).Unix()
+		dailyTotals[day] += amount
+	}
+	return dailyTotals, dataPoints
+}
+
+// GetRainfallAccumulation aggregates stored rainfall over windows ending at req.End. Daily totals
+// are aggregated, since trigger conditions evaluate one value per day; a reading is in a window
+// when it was taken at or after the window start, as in policy-service.
+func (s *HistoryService) GetRainfallAccumulation(req models.RainfallAccumulationRequest) (*models.RainfallAccumulationResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	function := req.Function
+	if function == "" {
+		function = models.AggregationSum
+	}
+	if !models.AggregationFunctions[function] {
+		return nil, fmt.Errorf("invalid aggregation function: %s", function)
+	}
+	windows, err := parseRainfallWindows(req.Windows)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if req.End > 0 {
+		end = time.Unix(req.End, 0)
+	}
+	windowStart := func(days int) time.Time {
+		start := end.AddDate(0, 0, -days)
+		if req.CoverageStart > 0 && start.Unix() < req.CoverageStart {
+			start = time.Unix(req.CoverageStart, 0)
+		}
+		return start
+	}
+	earliest := end
+	for _, days := range windows {
+		earliest = minTime(earliest, windowStart(days))
+	}
+
+	locationKey := historyLocationKey(req.PolygonID, req.Lat, req.Lon)
+	observations, err := s.repo.GetObservations(locationKey, models.ParamPrecipitation, earliest, end, false)
+	if err != nil {
+		log.Printf("Error fetching rainfall history for %s: %v", locationKey, err)
+		return nil, fmt.Errorf("failed to fetch rainfall history")
+	}
+
+	response := &models.RainfallAccumulationResponse{
+		LocationKey: locationKey,
+		Function:    function,
+		Unit:        models.ObservationUnits[models.ParamPrecipitation],
+		Windows:     make([]models.RainfallWindow, 0, len(windows)),
+	}
+	for _, days := range windows {
+		start := windowStart(days)
+		dailyTotals, dataPoints := dailyRainfallTotals(observations, start)
+
+		dayKeys := make([]int64, 0, len(dailyTotals))
+		for day := range dailyTotals {
+			dayKeys = append(dayKeys, day)
+		}
+		sort.Slice(dayKeys, func(i, j int) bool { return dayKeys[i] < dayKeys[j] })
+		values := make([]float64, len(dayKeys))
+		for i, day := range dayKeys {
+			values[i] = dailyTotals[day]
+		}
+
+		response.Windows = append(response.Windows, models.RainfallWindow{
+			WindowDays:     days,
+			Start:          start.Unix(),
+			End:            end.Unix(),
+			Value:          Aggregate(values, function),
+			DayCount:       len(values),
+			DataPointCount: dataPoints,
+		})
+	}
+	return response, nil
+}
+
+// GetAggregate aggregates the stored readings of a parameter in time order. Precipitation is
+// aggregated over daily totals, like the rainfall accumulation windows.
+func (s *HistoryService) GetAggregate(req models.AggregateRequest) (*models.AggregateResponse, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather history storage is not configured")
+	}
+	unit, ok := models.ObservationUnits[req.Parameter]
+	if !ok {
+		return nil, fmt.Errorf("invalid parameter: %s", req.Parameter)
+	}
+	function := req.Function
+	if function == "" {
+		function = models.AggregationSum
+	}
+	if !models.AggregationFunctions[function] {
+		return nil, fmt.Errorf("invalid aggregation function: %s", function)
+	}
+	if req.End < req.Start {
+		return nil, fmt.Errorf("invalid time range: end is before start")
+	}
+
+	locationKey := historyLocationKey(req.PolygonID, req.Lat, req.Lon)
+	start := time.Unix(req.Start, 0)
+	observations, err := s.repo.GetObservations(locationKey, req.Parameter, start, time.Unix(req.End, 0), false)
+	if err != nil {
+		log.Printf("Error fetching %s history for %s: %v", req.Parameter, locationKey, err)
+		return nil, fmt.Errorf("failed to fetch weather history")
+	}
+
+	values := make([]float64, 0, len(observations))
+	dataPoints := len(observations)
+	if req.Parameter == models.ParamPrecipitation {
+		var dailyTotals map[int64]float64
+		dailyTotals, dataPoints = dailyRainfallTotals(observations, start)
+		days := make([]int64, 0, len(dailyTotals))
+		for day := range dailyTotals {
+			days = append(days, day)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		for _, day := range days {
+			values = append(values, dailyTotals[day])
+		}
+	} else {
+		for _, observation := range observations {
+			values = append(values, observation.Value)
+		}
+	}
+
+	return &models.AggregateResponse{
+		LocationKey:    locationKey,
+		Parameter:      req.Parameter,
+		Unit:           unit,
+		Function:       function,
+		Value:          Aggregate(values, function),
+		ValueCount:     len(values),
+		DataPointCount: dataPoints,
+	}, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// PurgeExpired deletes observations and forecasts older than their retention period
+func (s *HistoryService) PurgeExpired() error {
+	if s.repo == nil {
+		return nil
+	}
+	now := time.Now()
+	observed, err := s.repo.DeleteOlderThan(false, now.AddDate(0, 0, -s.cfg.ObservationRetentionDays))
+	if err != nil {
+		return err
+	}
+	forecasts, err := s.repo.DeleteOlderThan(true, now.AddDate(0, 0, -s.cfg.ForecastRetentionDays))
+	if err != nil {
+		return err
+	}
+	if observed > 0 || forecasts > 0 {
+		log.Printf("Purged %d observations and %d forecasts past retention", observed, forecasts)
+	}
+	return nil
+}
+
+// StartRetentionWatcher periodically purges expired history until ctx is cancelled
+func (s *HistoryService) StartRetentionWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PurgeExpired(); err != nil {
+				log.Printf("Error purging weather history: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"weather-service/internal/models"
+	"weather-service/internal/provider"
+	"weather-service/internal/quota"
+)
+
+// One Call fields the current conditions do not use
+const currentExclude = "minutely,hourly,daily,alerts"
+
+// oneCallProvider serves the current conditions of One Call through the weather service, so they
+// share its cache, quota and history
+type oneCallProvider struct {
+	weatherService IWeatherService
+}
+
+// NewOneCallProvider adapts the One Call lookup of the weather service to a weather provider
+func NewOneCallProvider(weatherService IWeatherService) provider.WeatherProvider {
+	return &oneCallProvider{weatherService: weatherService}
+}
+
+func (p *oneCallProvider) Name() string {
+	return quota.ProviderOneCall
+}
+
+func (p *oneCallProvider) Current(ctx context.Context, lat, lon float64) (*models.ProviderReading, error) {
+	weather, err := p.weatherService.FetchWeatherData(strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lon, 'f', -1, 64), currentExclude, "metric", "")
+	if err != nil {
+		return nil, err
+	}
+	observations := oneCallObservations(lat, lon, "metric", weather)
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("one call response has no current conditions")
+	}
+	reading := &models.ProviderReading{
+		Source:     SourceOneCall,
+		ObservedAt: observations[0].ObservedAt,
+		Values:     map[string]float64{},
+	}
+	for _, observation := range observations {
+		reading.Values[observation.Parameter] = observation.Value
+	}
+	return reading, nil
+}
+
+type MergedWeatherService struct {
+	registry        *provider.Registry
+	history         IHistoryService
+	defaultStrategy string
+}
+
+type IMergedWeatherService interface {
+	// GetCurrent merges the current conditions at a point over every available provider
+	GetCurrent(ctx context.Context, req models.MergedWeatherRequest) (*models.MergedWeatherResponse, error)
+	// ProviderHealth reports the recent record of every provider in priority order
+	ProviderHealth() []models.ProviderHealth
+}
+
+// NewMergedWeatherService creates the service merging the providers of registry, which are in
+// priority order
+func NewMergedWeatherService(registry *provider.Registry, history IHistoryService, defaultStrategy string) IMergedWeatherService {
+	if defaultStrategy != models.MergeStrategyMedian {
+		defaultStrategy = models.MergeStrategyPriority
+	}
+	return &MergedWeatherService{registry: registry, history: history, defaultStrategy: defaultStrategy}
+}
+
+func (s *MergedWeatherService) GetCurrent(ctx context.Context, req models.MergedWeatherRequest) (*models.MergedWeatherResponse, error) {
+	if s.registry.Len() == 0 {
+		return nil, fmt.Errorf("no weather provider configured")
+	}
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = s.defaultStrategy
+	}
+	lat, lon := *req.Lat, *req.Lon
+
+	readings, failed := s.registry.FetchAll(ctx, lat, lon)
+	if len(readings) == 0 {
+		return nil, allProvidersFailed(failed)
+	}
+
+	response := &models.MergedWeatherResponse{
+		Lat:      lat,
+		Lon:      lon,
+		Strategy: strategy,
+		Values:   provider.Merge(strategy, readings),
+		Readings: readings,
+	}
+	if len(failed) > 0 {
+		response.Unavailable = map[string]string{}
+		for name, err := range failed {
+			response.Unavailable[name] = err.Error()
+			log.Printf("Weather provider %s unavailable for merged reading: %v", name, err)
+		}
+	}
+
+	// One Call readings are recorded by the weather service already; the others join the history
+	// so their agreement counts towards the confidence of every source
+	var observations []models.WeatherObservation
+	for _, reading := range readings {
+		if reading.Source == SourceOneCall {
+			continue
+		}
+		for param, value := range reading.Values {
+			observations = append(observations, newPointObservation(lat, lon, param, reading.ObservedAt, value, reading.Source))
+		}
+	}
+	s.history.Record(observations)
+	return response, nil
+}
+
+func (s *MergedWeatherService) ProviderHealth() []models.ProviderHealth {
+	return s.registry.Health()
+}
+
+// allProvidersFailed reports the failure of every provider; it is a quota error only when every
+// provider ran out of quota
+func allProvidersFailed(failed map[string]error) error {
+	reasons := make([]string, 0, len(failed))
+	quotaOnly := len(failed) > 0
+	for name, err := range failed {
+		reasons = append(reasons, name+": "+err.Error())
+		quotaOnly = quotaOnly && errors.Is(err, quota.ErrQuotaExceeded)
+	}
+	if quotaOnly {
+		return fmt.Errorf("%w: every weather provider is out of quota", quota.ErrQuotaExceeded)
+	}
+	return fmt.Errorf("every weather provider failed: %s", strings.Join(reasons, "; "))
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"weather-service/internal/config"
+	"weather-service/internal/event"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+	"weather-service/internal/repository"
+)
+
+// Update frequency of the providers behind each feed. Current readings report rain over the last
+// hour, so hourly polls leave no gap in the rainfall history; forecasts are issued in 3h steps.
+var feedIntervals = map[string]time.Duration{
+	models.FeedCurrent:  time.Hour,
+	models.FeedForecast: 3 * time.Hour,
+}
+
+const insuredLocationsPath = "/policy/internal/api/v2/farms/insured-locations"
+
+type PollingService struct {
+	repo            repository.IFarmLocationRepository
+	weatherService  IWeatherService
+	agroService     IAgroService
+	forecastService IForecastService
+	quality         IQualityService
+	webhooks        IWebhookService
+	publisher       *event.Publisher
+	limiter         *quota.Limiter
+	cfg             config.WeatherServiceConfig
+	client          *http.Client
+
+	// Last current-weather poll of each webhook location, kept in memory as the locations
+	// come and go with the webhooks
+	mu              sync.Mutex
+	webhookPolledAt map[string]time.Time
+}
+
+type IPollingService interface {
+	// SyncLocations replaces the registry with the insured farms listed by policy-service
+	SyncLocations(ctx context.Context) (int, error)
+	// PollDue polls every feed whose providers have updated since the farm was last polled
+	PollDue(ctx context.Context) error
+	// PollWebhookLocations polls the current weather of webhook locations and evaluates the webhooks
+	PollWebhookLocations(ctx context.Context) error
+	StartPollingWorker(ctx context.Context, interval time.Duration)
+}
+
+// NewPollingService creates the farm polling worker. Without a repository there is no registry
+// and nothing is polled; without a publisher readings are only stored in the weather history.
+// Current readings of farms and of webhook locations are evaluated against the webhooks.
+func NewPollingService(repo repository.IFarmLocationRepository, weatherService IWeatherService, agroService IAgroService, forecastService IForecastService, quality IQualityService, webhooks IWebhookService, publisher *event.Publisher, limiter *quota.Limiter, cfg config.WeatherServiceConfig) IPollingService {
+	return &PollingService{
+		repo:            repo,
+		weatherService:  weatherService,
+		agroService:     agroService,
+		forecastService: forecastService,
+		quality:         quality,
+		webhooks:        webhooks,
+		publisher:       publisher,
+		limiter:         limiter,
+		cfg:             cfg,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		webhookPolledAt: map[string]time.Time{},
+	}
+}
+
+func (s *PollingService) SyncLocations(ctx context.Context) (int, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("farm location storage is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.PolicyServiceURL+insuredLocationsPath, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build insured locations request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call policy-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read insured locations: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("policy-service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Success bool                         `json:"success"`
+		Data    []models.InsuredFarmLocation `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse insured locations: %w", err)
+	}
+	if !result.Success {
+		return 0, fmt.Errorf("policy-service reported an error: %s", string(body))
+	}
+
+	if err := s.repo.SyncLocations(result.Data); err != nil {
+		return 0, err
+	}
+	return len(result.Data), nil
+}
+
+func (s *PollingService) PollDue(ctx context.Context) error {
+	if s.repo == nil {
+		return fmt.Errorf("farm location storage is not configured")
+	}
+	locations, err := s.repo.GetActiveLocations()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	type pollJob struct {
+		location models.FarmLocation
+		feed     string
+	}
+	jobs := []pollJob{}
+	for _, location := range locations {
+		if pollDue(location.LastCurrentPollAt, models.FeedCurrent, now) {
+			jobs = append(jobs, pollJob{location: location, feed: models.FeedCurrent})
+		}
+		if pollDue(location.LastForecastPollAt, models.FeedForecast, now) {
+			jobs = append(jobs, pollJob{location: location, feed: models.FeedForecast})
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	semaphore := make(chan struct{}, max(s.cfg.BatchConcurrency, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+	for _, job := range jobs {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(job pollJob) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := s.poll(ctx, job.location, job.feed, now); err != nil {
+				log.Printf("Error polling %s weather for farm %s: %v", job.feed, job.location.FarmID, err)
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(job)
+	}
+	wg.Wait()
+
+	log.Printf("Polled weather for %d farms: %d feeds due, %d failed", len(locations), len(jobs), failed)
+	return nil
+}
+
+// pollDue reports whether the providers of a feed have updated since the last poll. The margin
+// keeps a poll that ran a little late from pushing the next one back by a whole tick.
+func pollDue(lastPolledAt *time.Time, feed string, now time.Time) bool {
+	if lastPolledAt == nil {
+		return true
+	}
+	return now.Sub(*lastPolledAt) >= feedIntervals[feed]-time.Minute
+}
+
+// poll fetches one feed of a farm, pushes its readings and records the poll. A failed push
+// leaves the farm due, so the readings are pushed again on the next tick.
+func (s *PollingService) poll(ctx context.Context, location models.FarmLocation, feed string, polledAt time.Time) error {
+	var observations []models.WeatherObservation
+	var err error
+	if feed == models.FeedCurrent {
+		observations, err = s.currentObservations(location)
+	} else {
+		observations, err = s.forecastObservations(location)
+	}
+	if err != nil {
+		return err
+	}
+	s.scoreObservations(observations)
+	if feed == models.FeedCurrent {
+		if err := s.webhooks.Evaluate(observations); err != nil {
+			log.Printf("Error evaluating weather webhooks for farm %s: %v", location.FarmID, err)
+		}
+	}
+
+	if s.publisher != nil && len(observations) > 0 {
+		readingsEvent := event.WeatherReadingsEventModel{
+			FarmID:    location.FarmID,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+			Feed:      feed,
+			PolledAt:  polledAt.Unix(),
+			Readings:  make([]event.WeatherReading, 0, len(observations)),
+		}
+		if location.PolygonID != nil {
+			readingsEvent.PolygonID = *location.PolygonID
+		}
+		for _, observation := range observations {
+			readingsEvent.Readings = append(readingsEvent.Readings, event.WeatherReading{
+				Parameter:       models.SharedParameters[observation.Parameter],
+				Value:           observation.Value,
+				Unit:            models.SharedParameters[observation.Parameter].Unit(),
+				ObservedAt:      observation.ObservedAt.Unix(),
+				Source:          observation.Source,
+				IsForecast:      observation.IsForecast,
+				ConfidenceScore: observation.ConfidenceScore,
+			})
+		}
+		if err := s.publisher.PublishWeatherReadings(ctx, readingsEvent); err != nil {
+			return err
+		}
+	}
+	return s.repo.MarkPolled(location.FarmID, feed, polledAt)
+}
+
+// scoreObservations sets the confidence score pushed and delivered with each reading
+func (s *PollingService) scoreObservations(observations []models.WeatherObservation) {
+	for i := range observations {
+		score := s.quality.Score(observationQualityInput(observations[i])).Score
+		observations[i].ConfidenceScore = &score
+	}
+}
+
+// usePolygon reports whether a farm is polled through Agro by its polygon. Farms with a polygon
+// fall back to One Call at their center while the Agro budget is nearly spent and One Call's is not.
+func (s *PollingService) usePolygon(location models.FarmLocation) bool {
+	if location.PolygonID == nil || *location.PolygonID == "" {
+		return false
+	}
+	ctx := context.Background()
+	return !s.limiter.NearExhaustion(ctx, quota.ProviderAgro) || s.limiter.NearExhaustion(ctx, quota.ProviderOneCall)
+}
+
+// currentObservations fetches the current weather of a farm, by polygon when it has one. The
+// fetch itself stores the readings in the weather history.
+func (s *PollingService) currentObservations(location models.FarmLocation) ([]models.WeatherObservation, error) {
+	if s.usePolygon(location) {
+		return s.polygonCurrentObservations(*location.PolygonID)
+	}
+	return s.pointCurrentObservations(location.Latitude, location.Longitude)
+}
+
+func (s *PollingService) polygonCurrentObservations(polygonID string) ([]models.WeatherObservation, error) {
+	weather, err := s.agroService.GetCurrentWeather(polygonID)
+	if err != nil {
+		return nil, err
+	}
+	return agroCurrentObservations(polygonID, weather), nil
+}
+
+func (s *PollingService) pointCurrentObservations(latitude, longitude float64) ([]models.WeatherObservation, error) {
+	lat := strconv.FormatFloat(latitude, 'f', -1, 64)
+	lon := strconv.FormatFloat(longitude, 'f', -1, 64)
+	weather, err := s.weatherService.FetchWeatherData(lat, lon, "minutely,hourly,daily,alerts", "metric", "")
+	if err != nil {
+		return nil, err
+	}
+	return oneCallObservations(latitude, longitude, "metric", weather), nil
+}
+
+// forecastObservations fetches the hourly forecast of a farm as forecast readings
+func (s *PollingService) forecastObservations(location models.FarmLocation) ([]models.WeatherObservation, error) {
+	req := models.ForecastRequest{Lat: &location.Latitude, Lon: &location.Longitude}
+	if s.usePolygon(location) {
+		req = models.ForecastRequest{PolygonID: *location.PolygonID}
+	}
+	forecast, err := s.forecastService.GetHourlyForecast(req)
+	if err != nil {
+		return nil, err
+	}
+
+	source := SourceOneCall
+	if forecast.Provider == models.ForecastProviderAgro {
+		source = SourceAgroForecast
+	}
+	observations := make([]models.WeatherObservation, 0, len(forecast.Points)*6)
+	for _, point := range forecast.Points {
+		observedAt := time.Unix(point.Dt, 0)
+		values := map[string]float64{
+			models.ParamTemperature:   point.Temperature,
+			models.ParamHumidity:      point.Humidity,
+			models.ParamPressure:      point.Pressure,
+			models.ParamWindSpeed:     point.WindSpeed,
+			models.ParamCloudCover:    point.CloudCover,
+			models.ParamPrecipitation: point.Precipitation,
+		}
+		for _, parameter := range []string{models.ParamTemperature, models.ParamHumidity, models.ParamPressure, models.ParamWindSpeed, models.ParamCloudCover, models.ParamPrecipitation} {
+			if req.PolygonID != "" {
+				observations = append(observations, newPolygonObservation(req.PolygonID, parameter, observedAt, values[parameter], source, true))
+				continue
+			}
+			observation := newPointObservation(location.Latitude, location.Longitude, parameter, observedAt, values[parameter], source)
+			observation.IsForecast = true
+			observations = append(observations, observation)
+		}
+	}
+	return observations, nil
+}
+
+func (s *PollingService) PollWebhookLocations(ctx context.Context) error {
+	locations, err := s.webhooks.ActiveLocations()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	polled, failed := 0, 0
+	for _, location := range locations {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.mu.Lock()
+		lastPolledAt, ok := s.webhookPolledAt[location.LocationKey]
+		s.mu.Unlock()
+		if ok && !pollDue(&lastPolledAt, models.FeedCurrent, now) {
+			continue
+		}
+
+		// Webhooks match readings by location key, so a polygon is always polled by polygon
+		var observations []models.WeatherObservation
+		if location.PolygonID != nil {
+			observations, err = s.polygonCurrentObservations(*location.PolygonID)
+		} else {
+			observations, err = s.pointCurrentObservations(*location.Latitude, *location.Longitude)
+		}
+		if err == nil {
+			s.scoreObservations(observations)
+			err = s.webhooks.Evaluate(observations)
+		}
+		if err != nil {
+			log.Printf("Error polling weather for webhooks at %s: %v", location.LocationKey, err)
+			failed++
+			continue
+		}
+		polled++
+		s.mu.Lock()
+		s.webhookPolledAt[location.LocationKey] = now
+		s.mu.Unlock()
+	}
+
+	if polled > 0 || failed > 0 {
+		log.Printf("Polled weather for %d webhook locations, %d failed", polled, failed)
+	}
+	return nil
+}
+
+// StartPollingWorker syncs the farm registry and polls due farms and webhook locations until ctx
+// is cancelled. The interval only bounds how late a poll can run; each feed keeps its provider
+// update frequency.
+func (s *PollingService) StartPollingWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A failed sync keeps polling the last known registry
+			if count, err := s.SyncLocations(ctx); err != nil {
+				log.Printf("Error syncing insured farm locations: %v", err)
+			} else {
+				log.Printf("Synced %d insured farm locations", count)
+			}
+			if err := s.PollDue(ctx); err != nil {
+				log.Printf("Error polling farm weather: %v", err)
+			}
+			if err := s.PollWebhookLocations(ctx); err != nil {
+				log.Printf("Error polling webhook weather: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"log"
+	"math"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+)
+
+// Accuracy of each source, from 0 to 1
+var providerAccuracy = map[string]float64{
+	SourceOneCall:      0.85,
+	SourceAgroCurrent:  0.8,
+	SourceAgroForecast: 0.7,
+	SourceDaySummary:   0.8,
+	SourceOpenMeteo:    0.8,
+	// Station observations of the national met service
+	SourceNationalMet: 0.9,
+}
+
+// Sources of consolidated past readings, whose age says nothing about their freshness
+var archiveSources = map[string]bool{
+	SourceDaySummary: true,
+}
+
+// Weights of the quality factors; factors that cannot be assessed are left out and the others
+// are weighted up
+var qualityWeights = map[string]float64{
+	models.QualityProvider:  0.35,
+	models.QualityStation:   0.2,
+	models.QualityFreshness: 0.25,
+	models.QualityAgreement: 0.2,
+}
+
+// Difference between two readings of a parameter that still counts as rough agreement
+var agreementTolerance = map[string]float64{
+	models.ParamTemperature:   2,
+	models.ParamHumidity:      10,
+	models.ParamPressure:      5,
+	models.ParamWindSpeed:     2,
+	models.ParamCloudCover:    20,
+	models.ParamPrecipitation: 1,
+}
+
+const (
+	// Lowest score of a decaying factor
+	qualityFloor = 0.4
+	// A station within this distance fully grounds a reading; beyond the far distance it does not help
+	stationNearKM = 5.0
+	stationFarKM  = 50.0
+	// Forecasts lose confidence linearly up to this lead time
+	forecastHorizon = 120 * time.Hour
+	// Readings older than the fresh age when captured lose confidence up to the stale age
+	observationFreshAge = time.Hour
+	observationStaleAge = 24 * time.Hour
+	// Readings of other sources within this interval are compared
+	agreementWindow = 90 * time.Minute
+)
+
+type QualityService struct {
+	observationRepo repository.IObservationRepository
+	stationRepo     repository.IStationRepository
+}
+
+type IQualityService interface {
+	Score(input models.QualityInput) models.QualityScore
+}
+
+// NewQualityService creates the confidence scorer. Either repository may be nil, in which case
+// the factors relying on it are not assessed.
+func NewQualityService(observationRepo repository.IObservationRepository, stationRepo repository.IStationRepository) IQualityService {
+	return &QualityService{observationRepo: observationRepo, stationRepo: stationRepo}
+}
+
+// linearDecay is 1 up to full, falls linearly to qualityFloor at zero and stays there
+func linearDecay(value, full, zero float64) float64 {
+	if value <= full {
+		return 1
+	}
+	if value >= zero {
+		return qualityFloor
+	}
+	return 1 - (1-qualityFloor)*(value-full)/(zero-full)
+}
+
+func (s *QualityService) stationFactor(input models.QualityInput) (float64, bool) {
+	if s.stationRepo == nil || input.Lat == nil || input.Lon == nil {
+		return 0, false
+	}
+	stations, err := s.stationRepo.GetNearestStations(models.NearestStationRequest{
+		Lat:       input.Lat,
+		Lon:       input.Lon,
+		Parameter: input.Parameter,
+		Limit:     1,
+	})
+	if err != nil {
+		log.Printf("Error finding station for quality score at %s: %v", input.LocationKey, err)
+		return 0, false
+	}
+	if len(stations) == 0 {
+		return qualityFloor, true
+	}
+	return linearDecay(stations[0].DistanceKM, stationNearKM, stationFarKM) * stations[0].Reliability, true
+}
+
+func freshnessFactor(input models.QualityInput, now time.Time) float64 {
+	if input.IsForecast {
+		return linearDecay(input.ObservedAt.Sub(now).Hours(), 0, forecastHorizon.Hours())
+	}
+	return linearDecay(now.Sub(input.ObservedAt).Hours(), observationFreshAge.Hours(), observationStaleAge.Hours())
+}
+
+// agreementFactor compares the reading with observed readings of other sources at the same place
+// and time; there is nothing to compare when no other source reported
+func (s *QualityService) agreementFactor(input models.QualityInput) (float64, bool) {
+	if s.observationRepo == nil {
+		return 0, false
+	}
+	locationKeys := []string{input.LocationKey}
+	if input.Lat != nil && input.Lon != nil {
+		if pointKey := PointLocationKey(*input.Lat, *input.Lon); pointKey != input.LocationKey {
+			locationKeys = append(locationKeys, pointKey)
+		}
+	}
+
+	sum, count := 0.0, 0
+	for _, locationKey := range locationKeys {
+		observations, err := s.observationRepo.GetObservations(locationKey, input.Parameter,
+			input.ObservedAt.Add(-agreementWindow), input.ObservedAt.Add(agreementWindow), false)
+		if err != nil {
+			log.Printf("Error reading observations for quality score at %s: %v", locationKey, err)
+			return 0, false
+		}
+		for _, observation := range observations {
+			if observation.Source == input.Source {
+				continue
+			}
+			sum += observation.Value
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+
+	mean := sum / float64(count)
+	scale := math.Max(agreementTolerance[input.Parameter], math.Max(math.Abs(input.Value), math.Abs(mean)))
+	return math.Max(0, 1-math.Abs(input.Value-mean)/scale), true
+}
+
+// Score rates a reading from 0 to 1 by its source's accuracy, the distance to the nearest station
+// measuring the parameter, its age or forecast lead time, and its agreement with other sources.
+// Archived readings are not rated on age.
+func (s *QualityService) Score(input models.QualityInput) models.QualityScore {
+	factors := map[string]float64{}
+	if !archiveSources[input.Source] {
+		factors[models.QualityFreshness] = freshnessFactor(input, time.Now())
+	}
+	if accuracy, ok := providerAccuracy[input.Source]; ok {
+		factors[models.QualityProvider] = accuracy
+	} else {
+		factors[models.QualityProvider] = qualityFloor
+	}
+	if station, ok := s.stationFactor(input); ok {
+		factors[models.QualityStation] = station
+	}
+	if agreement, ok := s.agreementFactor(input); ok {
+		factors[models.QualityAgreement] = agreement
+	}
+
+	weighted, weights := 0.0, 0.0
+	for factor, value := range factors {
+		weighted += qualityWeights[factor] * value
+		weights += qualityWeights[factor]
+	}
+	return models.QualityScore{
+		Score:   math.Round(weighted/weights*1000) / 1000,
+		Factors: factors,
+	}
+}
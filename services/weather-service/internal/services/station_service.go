@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+)
+
+// Stations returned by a nearest lookup without a limit
+const defaultNearestStationLimit = 5
+
+type StationService struct {
+	repo repository.IStationRepository
+}
+
+type IStationService interface {
+	CreateStation(req models.StationRequest) (*models.WeatherStation, error)
+	UpdateStation(id int64, req models.StationRequest) (*models.WeatherStation, error)
+	DeleteStation(id int64) error
+	GetStation(id int64) (*models.WeatherStation, error)
+	GetStations(activeOnly bool) ([]models.WeatherStation, error)
+	GetNearestStations(req models.NearestStationRequest) ([]models.NearbyStation, error)
+}
+
+func NewStationService(repo repository.IStationRepository) IStationService {
+	return &StationService{repo: repo}
+}
+
+var errStationStorage = fmt.Errorf("weather station storage is not configured")
+
+func stationFromRequest(req models.StationRequest) (*models.WeatherStation, error) {
+	parameters := []string{}
+	seen := map[string]bool{}
+	for _, parameter := range req.Parameters {
+		if _, ok := models.ObservationUnits[parameter]; !ok {
+			return nil, fmt.Errorf("invalid parameter: %s", parameter)
+		}
+		if !seen[parameter] {
+			seen[parameter] = true
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	station := &models.WeatherStation{
+		Code:        strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:        strings.TrimSpace(req.Name),
+		Operator:    strings.TrimSpace(req.Operator),
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		ElevationM:  req.ElevationM,
+		Parameters:  parameters,
+		Reliability: 1,
+		IsActive:    true,
+	}
+	if req.Reliability != nil {
+		station.Reliability = *req.Reliability
+	}
+	if req.IsActive != nil {
+		station.IsActive = *req.IsActive
+	}
+	return station, nil
+}
+
+func (s *StationService) CreateStation(req models.StationRequest) (*models.WeatherStation, error) {
+	if s.repo == nil {
+		return nil, errStationStorage
+	}
+	station, err := stationFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateStation(station); err != nil {
+		return nil, err
+	}
+	return station, nil
+}
+
+func (s *StationService) UpdateStation(id int64, req models.StationRequest) (*models.WeatherStation, error) {
+	if s.repo == nil {
+		return nil, errStationStorage
+	}
+	station, err := stationFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	station.ID = id
+	if err := s.repo.UpdateStation(station); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStationByID(id)
+}
+
+func (s *StationService) DeleteStation(id int64) error {
+	if s.repo == nil {
+		return errStationStorage
+	}
+	return s.repo.DeleteStation(id)
+}
+
+func (s *StationService) GetStation(id int64) (*models.WeatherStation, error) {
+	if s.repo == nil {
+		return nil, errStationStorage
+	}
+	return s.repo.GetStationByID(id)
+}
+
+func (s *StationService) GetStations(activeOnly bool) ([]models.WeatherStation, error) {
+	if s.repo == nil {
+		return nil, errStationStorage
+	}
+	return s.repo.GetStations(activeOnly)
+}
+
+func (s *StationService) GetNearestStations(req models.NearestStationRequest) ([]models.NearbyStation, error) {
+	if s.repo == nil {
+		return nil, errStationStorage
+	}
+	if req.Parameter != "" {
+		if _, ok := models.ObservationUnits[req.Parameter]; !ok {
+			return nil, fmt.Errorf("invalid parameter: %s", req.Parameter)
+		}
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultNearestStationLimit
+	}
+	return s.repo.GetNearestStations(req)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"time"
+	"weather-service/internal/config"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
+
+	"agrisa/parameter"
+)
+
+// DefaultMaxCloudCover is the cloud cover in percent above which an image is left out when no
+// limit is given; cloudier images mostly measure the clouds
+const DefaultMaxCloudCover = 60.0
+
+type VegetationService struct {
+	cfg         config.WeatherServiceConfig
+	agroService IAgroService
+	limiter     *quota.Limiter
+	client      *http.Client
+}
+
+type IVegetationService interface {
+	// GetNDVI returns the mean NDVI of each satellite image of a polygon from start to end whose
+	// cloud cover is at most maxCloudCover percent
+	GetNDVI(req models.PrecipitationRequest, maxCloudCover float64) (*models.UnifiedAPIResponse, error)
+}
+
+// NewVegetationService creates the service reading vegetation indices of farm polygons from the
+// satellite imagery of the Agro API
+func NewVegetationService(cfg config.WeatherServiceConfig, agroService IAgroService, limiter *quota.Limiter) IVegetationService {
+	return &VegetationService{
+		cfg:         cfg,
+		agroService: agroService,
+		limiter:     limiter,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// agroIndexImage is the statistics of a vegetation index over a polygon in one satellite image
+type agroIndexImage struct {
+	Dt     int64  `json:"dt"`
+	Source string `json:"source"`
+	// Share of the polygon the image covers and share of it under clouds, in percent
+	DataCoverage float64 `json:"dc"`
+	CloudCover   float64 `json:"cl"`
+	Data         struct {
+		Mean   float64 `json:"mean"`
+		Median float64 `json:"median"`
+		Std    float64 `json:"std"`
+		Num    int     `json:"num"`
+	} `json:"data"`
+}
+
+func (s *VegetationService) GetNDVI(req models.PrecipitationRequest, maxCloudCover float64) (*models.UnifiedAPIResponse, error) {
+	if s.cfg.AgroAPIKey == "" {
+		log.Println("Agro API key not configured")
+		return nil, fmt.Errorf("agro API key not configured")
+	}
+	if maxCloudCover < 0 || maxCloudCover > 100 {
+		return nil, fmt.Errorf("invalid max_cloud_cover: must be between 0 and 100")
+	}
+
+	polygon, reused, err := resolvePolygon(s.agroService, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limiter.Acquire(context.Background(), quota.ProviderAgro); err != nil {
+		log.Printf("Skipping NDVI history call: %v", err)
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/ndvi/history?polyid=%s&start=%d&end=%d&appid=%s",
+		s.cfg.AgroAPIBaseURL, polygon.ID, req.Start, req.End, s.cfg.AgroAPIKey)
+	resp, err := s.client.Get(url)
+	if err != nil {
+		log.Printf("Error fetching NDVI history: %v", err)
+		return nil, fmt.Errorf("failed to fetch NDVI history")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading response body: %v", err)
+		return nil, fmt.Errorf("failed to read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Agro API returned non-200 status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("Agro API error: %s", string(body))
+	}
+
+	var images []agroIndexImage
+	if err := json.Unmarshal(body, &images); err != nil {
+		log.Printf("Error unmarshaling NDVI history: %v", err)
+		return nil, fmt.Errorf("failed to parse response")
+	}
+
+	response := &models.UnifiedAPIResponse{
+		PolygonID:         polygon.ID,
+		PolygonName:       polygon.Name,
+		PolygonCenter:     polygon.Center,
+		PolygonArea:       polygon.Area,
+		PolygonReused:     reused,
+		PolygonCreatedNew: !reused,
+		TimeRange:         models.TimeRange{Start: req.Start, End: req.End},
+		Data:              []models.DataPoint{},
+	}
+	skipped := 0
+	for _, image := range images {
+		if image.CloudCover > maxCloudCover || image.DataCoverage <= 0 || image.Data.Num == 0 {
+			skipped++
+			continue
+		}
+		// The clear share of the polygon the image saw is how far its mean can be trusted
+		confidence := math.Round((1-image.CloudCover/100)*math.Min(image.DataCoverage, 100)) / 100
+		cloudCover := image.CloudCover
+		response.Data = append(response.Data, models.DataPoint{
+			Dt:              image.Dt,
+			Data:            math.Round(image.Data.Mean*10000) / 10000,
+			Count:           image.Data.Num,
+			Unit:            string(parameter.NDVI.Unit()),
+			ConfidenceScore: &confidence,
+			CloudCover:      &cloudCover,
+		})
+		response.TotalDataValue += image.Data.Mean
+	}
+	response.DataPointCount = len(response.Data)
+
+	log.Printf("Retrieved %d NDVI images for polygon %s, %d left out for cloud cover above %.0f%% or no coverage",
+		response.DataPointCount, polygon.ID, skipped, maxCloudCover)
+	return response, nil
+}
@@ -1,25 +1,70 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
+	"weather-service/internal/cache"
 	"weather-service/internal/config"
+	"weather-service/internal/models"
+	"weather-service/internal/quota"
 )
 
 type WeatherService struct {
-	cfg config.WeatherServiceConfig
+	cfg     config.WeatherServiceConfig
+	cache   *cache.WeatherCache
+	history IHistoryService
+	limiter *quota.Limiter
 }
 
 type IWeatherService interface {
 	// Define service methods here
 	FetchWeatherData(lat, lon, exclude, units, lang string) (*WeatherResponse, error)
+	FetchWeatherBatch(req models.BatchWeatherRequest) *BatchWeatherResponse
 }
 
-func NewWeatherService(cfg config.WeatherServiceConfig) IWeatherService {
-	return &WeatherService{cfg: cfg}
+func NewWeatherService(cfg config.WeatherServiceConfig, weatherCache *cache.WeatherCache, history IHistoryService, limiter *quota.Limiter) IWeatherService {
+	return &WeatherService{cfg: cfg, cache: weatherCache, history: history, limiter: limiter}
+}
+
+// reserveCall reserves an upstream call to provider. While the provider budget is nearly spent,
+// or once it is spent, the stale cached response of key is loaded into dest instead and the call
+// is reported as served; an empty key has no stale fallback.
+func reserveCall(ctx context.Context, limiter *quota.Limiter, weatherCache *cache.WeatherCache, provider, param, key string, dest any) (bool, error) {
+	if limiter.NearExhaustion(ctx, provider) && weatherCache.GetStale(ctx, param, key, dest) {
+		limiter.RecordStaleServed(provider)
+		return true, nil
+	}
+	if err := limiter.Acquire(ctx, provider); err != nil {
+		if weatherCache.GetStale(ctx, param, key, dest) {
+			limiter.RecordStaleServed(provider)
+			log.Printf("Served stale %s data: %v", param, err)
+			return true, nil
+		}
+		log.Printf("Skipping %s call: %v", provider, err)
+		return false, err
+	}
+	return false, nil
+}
+
+// serveRevalidating loads the stale copy of key into dest while its time bucket ended only
+// recently, and has one caller refresh the entry in the background. It reports whether dest was
+// served.
+func serveRevalidating(weatherCache *cache.WeatherCache, param, key string, dest any, refresh func() error) bool {
+	found, owner := weatherCache.GetRevalidating(context.Background(), param, key, dest)
+	if found && owner {
+		go func() {
+			if err := refresh(); err != nil {
+				log.Printf("Failed to revalidate %s cache entry %s: %v", param, key, err)
+			}
+		}()
+	}
+	return found
 }
 
 type WeatherResponse struct {
@@ -34,17 +79,65 @@ type WeatherResponse struct {
 	Alerts         []map[string]any `json:"alerts,omitempty"`
 }
 
+// BatchPointResult is the reading of one batch point, or the error that prevented it
+type BatchPointResult struct {
+	ID      string           `json:"id"`
+	Lat     float64          `json:"lat"`
+	Lon     float64          `json:"lon"`
+	GridLat string           `json:"grid_lat"`
+	GridLon string           `json:"grid_lon"`
+	Weather *WeatherResponse `json:"weather,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// BatchWeatherResponse lists the results in the order of the requested points
+type BatchWeatherResponse struct {
+	Results       []BatchPointResult `json:"results"`
+	PointCount    int                `json:"point_count"`
+	GridCellCount int                `json:"grid_cell_count"`
+	FailedCount   int                `json:"failed_count"`
+}
+
 func (w *WeatherService) FetchWeatherData(lat, lon, exclude, units, lang string) (*WeatherResponse, error) {
 	var weather WeatherResponse
 
-	appid := w.cfg.APIKey
-	if appid == "" {
+	if w.cfg.APIKey == "" {
 		log.Println("API key not configured")
 		return nil, fmt.Errorf("API key not configured")
 	}
 
+	// Lookups of nearby points share a cache entry; unparsable coordinates go straight upstream
+	latValue, latErr := strconv.ParseFloat(lat, 64)
+	lonValue, lonErr := strconv.ParseFloat(lon, 64)
+	if latErr != nil || lonErr != nil {
+		return w.fetchOneCall(lat, lon, exclude, units, lang, "")
+	}
+	cacheKey := cache.CoordinateKey(cache.ParamOneCall, [][2]float64{{lonValue, latValue}}, exclude, units, lang)
+	if w.cache.Get(context.Background(), cache.ParamOneCall, cacheKey, &weather) {
+		return &weather, nil
+	}
+	if serveRevalidating(w.cache, cache.ParamOneCall, cacheKey, &weather, func() error {
+		_, err := w.fetchOneCall(lat, lon, exclude, units, lang, cacheKey)
+		return err
+	}) {
+		return &weather, nil
+	}
+	return w.fetchOneCall(lat, lon, exclude, units, lang, cacheKey)
+}
+
+// fetchOneCall calls One Call upstream and caches the response under cacheKey, unless it is empty
+func (w *WeatherService) fetchOneCall(lat, lon, exclude, units, lang, cacheKey string) (*WeatherResponse, error) {
+	var weather WeatherResponse
+	served, err := reserveCall(context.Background(), w.limiter, w.cache, quota.ProviderOneCall, cache.ParamOneCall, cacheKey, &weather)
+	if err != nil {
+		return nil, err
+	}
+	if served {
+		return &weather, nil
+	}
+
 	// Build the API URL
-	url := fmt.Sprintf("https://api.openweathermap.org/data/3.0/onecall?lat=%s&lon=%s&appid=%s", lat, lon, appid)
+	url := fmt.Sprintf("https://api.openweathermap.org/data/3.0/onecall?lat=%s&lon=%s&appid=%s", lat, lon, w.cfg.APIKey)
 	if exclude != "" {
 		url += fmt.Sprintf("&exclude=%s", exclude)
 	}
@@ -82,5 +175,58 @@ func (w *WeatherService) FetchWeatherData(lat, lon, exclude, units, lang string)
 		return nil, fmt.Errorf("failed to parse JSON")
 	}
 
+	if cacheKey != "" {
+		w.cache.Set(context.Background(), cache.ParamOneCall, cacheKey, weather)
+		latValue, _ := strconv.ParseFloat(lat, 64)
+		lonValue, _ := strconv.ParseFloat(lon, 64)
+		w.history.Record(oneCallObservations(latValue, lonValue, units, &weather))
+	}
 	return &weather, nil
 }
+
+// FetchWeatherBatch fetches One Call data for many points. Points are snapped onto the cache cells
+// so nearby points share one upstream call, and at most BatchConcurrency calls run at once.
+func (w *WeatherService) FetchWeatherBatch(req models.BatchWeatherRequest) *BatchWeatherResponse {
+	type gridCell struct {
+		lat, lon string
+		weather  *WeatherResponse
+		err      error
+	}
+
+	cells := map[string]*gridCell{}
+	results := make([]BatchPointResult, len(req.Points))
+	for i, point := range req.Points {
+		lat, lon := cache.SnapPoint(cache.ParamOneCall, point.Lat, point.Lon)
+		if _, ok := cells[lat+","+lon]; !ok {
+			cells[lat+","+lon] = &gridCell{lat: lat, lon: lon}
+		}
+		results[i] = BatchPointResult{ID: point.ID, Lat: point.Lat, Lon: point.Lon, GridLat: lat, GridLon: lon}
+	}
+
+	concurrency := max(w.cfg.BatchConcurrency, 1)
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, cell := range cells {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(cell *gridCell) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			cell.weather, cell.err = w.FetchWeatherData(cell.lat, cell.lon, req.Exclude, req.Units, req.Lang)
+		}(cell)
+	}
+	wg.Wait()
+
+	response := &BatchWeatherResponse{Results: results, PointCount: len(results), GridCellCount: len(cells)}
+	for i := range results {
+		cell := cells[results[i].GridLat+","+results[i].GridLon]
+		if cell.err != nil {
+			results[i].Error = cell.err.Error()
+			response.FailedCount++
+			continue
+		}
+		results[i].Weather = cell.weather
+	}
+	log.Printf("Batch weather fetch: %d points on %d grid cells, %d failed", response.PointCount, response.GridCellCount, response.FailedCount)
+	return response
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"weather-service/internal/models"
+	"weather-service/internal/repository"
+)
+
+const (
+	// Deliveries listed per webhook
+	webhookDeliveryLimit = 100
+	// Deliveries sent per worker tick
+	webhookDeliveryBatch = 50
+	// A delivery is given up after this many attempts, retried 1, 2, 4 and 8 minutes apart
+	webhookMaxAttempts = 5
+	webhookRetryDelay  = time.Minute
+)
+
+// Headers of a webhook delivery. The signature is the hex HMAC-SHA256, keyed by the webhook
+// secret, of "<timestamp>.<body>", so consumers can reject forged and replayed payloads.
+const (
+	WebhookSignatureHeader = "X-Agrisa-Signature"
+	WebhookTimestampHeader = "X-Agrisa-Timestamp"
+	WebhookEventIDHeader   = "X-Agrisa-Event-ID"
+)
+
+type WebhookService struct {
+	repo   repository.IWebhookRepository
+	client *http.Client
+}
+
+type IWebhookService interface {
+	CreateWebhook(ownerID string, req models.CreateWebhookRequest) (*models.CreatedWebhook, error)
+	GetWebhooks(ownerID string) ([]models.WeatherWebhook, error)
+	DeleteWebhook(ownerID string, webhookID int64) error
+	GetDeliveries(ownerID string, webhookID int64) ([]models.WebhookDelivery, error)
+	// ActiveLocations lists the locations the polling workers fetch readings of for webhooks
+	ActiveLocations() ([]models.WebhookLocation, error)
+	// Evaluate queues a delivery to every webhook whose condition a current reading meets
+	Evaluate(observations []models.WeatherObservation) error
+	// DeliverDue sends the queued deliveries whose attempt is due
+	DeliverDue(ctx context.Context) error
+	StartDeliveryWorker(ctx context.Context, interval time.Duration)
+}
+
+// NewWebhookService creates the webhook service. Without a repository webhooks are unavailable
+// and polled readings are not evaluated.
+func NewWebhookService(repo repository.IWebhookRepository) IWebhookService {
+	return &WebhookService{repo: repo, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *WebhookService) CreateWebhook(ownerID string, req models.CreateWebhookRequest) (*models.CreatedWebhook, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather webhook storage is not configured")
+	}
+	if ownerID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+	if target, err := url.Parse(req.URL); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid url: an http or https url is required")
+	}
+	if (req.PolygonID == nil || *req.PolygonID == "") && (req.Latitude == nil || req.Longitude == nil) {
+		return nil, fmt.Errorf("invalid location: either polygon_id or latitude and longitude are required")
+	}
+	if _, ok := models.ObservationUnits[req.Parameter]; !ok {
+		return nil, fmt.Errorf("invalid parameter: %s", req.Parameter)
+	}
+	if !models.WebhookOperators[req.Operator] {
+		return nil, fmt.Errorf("invalid operator: %s", req.Operator)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook := &models.WeatherWebhook{
+		OwnerID:   ownerID,
+		Name:      req.Name,
+		URL:       req.URL,
+		Secret:    secret,
+		Parameter: req.Parameter,
+		Operator:  req.Operator,
+		Threshold: *req.Threshold,
+	}
+	if req.PolygonID != nil && *req.PolygonID != "" {
+		webhook.PolygonID = req.PolygonID
+		webhook.LocationKey = PolygonLocationKey(*req.PolygonID)
+	} else {
+		webhook.Latitude = req.Latitude
+		webhook.Longitude = req.Longitude
+		webhook.LocationKey = PointLocationKey(*req.Latitude, *req.Longitude)
+	}
+
+	if err := s.repo.CreateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return &models.CreatedWebhook{WeatherWebhook: *webhook, Secret: secret}, nil
+}
+
+func newWebhookSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func newWebhookEventID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate webhook event id: %w", err)
+	}
+	return "evt_" + hex.EncodeToString(id), nil
+}
+
+func (s *WebhookService) GetWebhooks(ownerID string) ([]models.WeatherWebhook, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather webhook storage is not configured")
+	}
+	return s.repo.GetWebhooksByOwner(ownerID)
+}
+
+func (s *WebhookService) ownedWebhook(ownerID string, webhookID int64) (*models.WeatherWebhook, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("weather webhook storage is not configured")
+	}
+	webhook, err := s.repo.GetWebhookByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.OwnerID != ownerID {
+		return nil, fmt.Errorf("forbidden: webhook belongs to another user")
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ownerID string, webhookID int64) error {
+	if _, err := s.ownedWebhook(ownerID, webhookID); err != nil {
+		return err
+	}
+	return s.repo.DeleteWebhook(webhookID)
+}
+
+func (s *WebhookService) GetDeliveries(ownerID string, webhookID int64) ([]models.WebhookDelivery, error) {
+	if _, err := s.ownedWebhook(ownerID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveriesByWebhook(webhookID, webhookDeliveryLimit)
+}
+
+func (s *WebhookService) ActiveLocations() ([]models.WebhookLocation, error) {
+	if s.repo == nil {
+		return nil, nil
+	}
+	return s.repo.GetActiveLocations()
+}
+
+func (s *WebhookService) Evaluate(observations []models.WeatherObservation) error {
+	if s.repo == nil || len(observations) == 0 {
+		return nil
+	}
+
+	// Only the latest current reading of each parameter is checked
+	latest := map[string]models.WeatherObservation{}
+	for _, observation := range observations {
+		if observation.IsForecast {
+			continue
+		}
+		if current, ok := latest[observation.Parameter]; !ok || observation.ObservedAt.After(current.ObservedAt) {
+			latest[observation.Parameter] = observation
+		}
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+
+	locationKey := observations[0].LocationKey
+	webhooks, err := s.repo.GetActiveWebhooksByLocation(locationKey)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		observation, ok := latest[webhook.Parameter]
+		if !ok || !webhook.Matches(observation.Value) {
+			continue
+		}
+		if err := s.queueDelivery(webhook, observation); err != nil {
+			log.Printf("Error queueing delivery to webhook %d: %v", webhook.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) queueDelivery(webhook models.WeatherWebhook, observation models.WeatherObservation) error {
+	eventID, err := newWebhookEventID()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(models.WebhookEvent{
+		EventID:     eventID,
+		WebhookID:   webhook.ID,
+		WebhookName: webhook.Name,
+		LocationKey: webhook.LocationKey,
+		PolygonID:   webhook.PolygonID,
+		Latitude:    webhook.Latitude,
+		Longitude:   webhook.Longitude,
+		Condition: models.WebhookCondition{
+			Parameter: webhook.Parameter,
+			Operator:  webhook.Operator,
+			Threshold: webhook.Threshold,
+		},
+		Reading: models.WebhookReading{
+			Value:           observation.Value,
+			Unit:            observation.Unit,
+			ObservedAt:      observation.ObservedAt.Unix(),
+			Source:          observation.Source,
+			ConfidenceScore: observation.ConfidenceScore,
+		},
+		TriggeredAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID:  webhook.ID,
+		EventID:    eventID,
+		ObservedAt: observation.ObservedAt,
+		Payload:    payload,
+	}
+	if _, err := s.repo.CreateDelivery(delivery); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	if s.repo == nil {
+		return nil
+	}
+	deliveries, err := s.repo.GetDueDeliveries(webhookDeliveryBatch)
+	if err != nil {
+		return err
+	}
+
+	webhooks := map[int64]*models.WeatherWebhook{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if webhook, err = s.repo.GetWebhookByID(delivery.WebhookID); err != nil {
+				log.Printf("Error loading webhook %d for delivery %d: %v", delivery.WebhookID, delivery.ID, err)
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		s.attempt(ctx, webhook, delivery)
+		if err := s.repo.UpdateDelivery(delivery); err != nil {
+			log.Printf("Error recording webhook delivery %d: %v", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// attempt posts a delivery once and records the outcome on it. Any 2xx response delivers it;
+// otherwise it is retried with exponential backoff until it runs out of attempts.
+func (s *WebhookService) attempt(ctx context.Context, webhook *models.WeatherWebhook, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+
+	statusCode, err := s.post(ctx, webhook, delivery, now)
+	if statusCode != 0 {
+		delivery.ResponseStatus = &statusCode
+	}
+	if err == nil {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+		return
+	}
+
+	message := err.Error()
+	delivery.LastError = &message
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		log.Printf("Giving up webhook delivery %d to webhook %d after %d attempts: %v", delivery.ID, webhook.ID, delivery.Attempts, err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhookRetryDelay << (delivery.Attempts - 1))
+}
+
+func (s *WebhookService) post(ctx context.Context, webhook *models.WeatherWebhook, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventIDHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func signWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StartDeliveryWorker sends due webhook deliveries until ctx is cancelled
+func (s *WebhookService) StartDeliveryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.DeliverDue(ctx); err != nil {
+				log.Printf("Error delivering weather webhooks: %v", err)
+			}
+		}
+	}
+}
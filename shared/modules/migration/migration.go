@@ -0,0 +1,141 @@
+// Package migration applies the versioned schema migrations of a service with goose. Every
+// service embeds its migrations and starts with a baseline migration holding the schema it had
+// before migrations, so databases created from that schema are adopted at the baseline rather
+// than created again.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// BaselineVersion is the version of the first migration of every service
+const BaselineVersion = 1
+
+// ErrSchemaOutdated means the database is missing migrations of the running build
+var ErrSchemaOutdated = errors.New("database schema is out of date")
+
+// Commands lists the goose commands that can run against embedded migrations
+var Commands = []string{"up", "up-by-one", "up-to", "down", "down-to", "redo", "status", "version"}
+
+// Migrations are the migration files of a service, the table their versions are recorded in and
+// the table showing that the baseline schema is already in place
+type Migrations struct {
+	FS fs.FS
+	// VersionTable is named after the service, as services may share a database
+	VersionTable string
+	// BaselineTable is a table created by the baseline migration, empty when the baseline is
+	// empty and no database predates the migrations
+	BaselineTable string
+}
+
+func (m Migrations) setup() error {
+	goose.SetBaseFS(m.FS)
+	goose.SetLogger(log.Default())
+	goose.SetTableName(m.VersionTable)
+	if err := goose.SetDialect("postgres"); err != nil {
+		return fmt.Errorf("failed to set migration dialect: %w", err)
+	}
+	return nil
+}
+
+// adoptBaseline records the baseline as applied on a database whose schema was created before
+// migrations were introduced
+func (m Migrations) adoptBaseline(db *sql.DB) error {
+	versioned, err := tableExists(db, goose.TableName())
+	if err != nil {
+		return fmt.Errorf("failed to check migration version table: %w", err)
+	}
+	if versioned {
+		return nil
+	}
+	baselined, err := tableExists(db, m.BaselineTable)
+	if err != nil {
+		return fmt.Errorf("failed to check baseline table: %w", err)
+	}
+	if !baselined {
+		return nil
+	}
+
+	if _, err := goose.EnsureDBVersion(db); err != nil {
+		return fmt.Errorf("failed to create migration version table: %w", err)
+	}
+	insert := fmt.Sprintf(`INSERT INTO %s (version_id, is_applied) VALUES ($1, TRUE)`, goose.TableName())
+	if _, err := db.Exec(insert, BaselineVersion); err != nil {
+		return fmt.Errorf("failed to record baseline migration: %w", err)
+	}
+	log.Printf("Existing schema adopted at baseline migration %d", BaselineVersion)
+	return nil
+}
+
+// Run runs a goose command such as up, down, down-to <version> or status against the database
+func (m Migrations) Run(ctx context.Context, db *sql.DB, command string, args ...string) error {
+	if !slices.Contains(Commands, command) {
+		return fmt.Errorf("invalid migration command %q: must be one of %s", command, strings.Join(Commands, ", "))
+	}
+	if err := m.setup(); err != nil {
+		return err
+	}
+	if err := m.adoptBaseline(db); err != nil {
+		return err
+	}
+	if err := goose.RunContext(ctx, command, db, ".", args...); err != nil {
+		return fmt.Errorf("migration %s failed: %w", command, err)
+	}
+	return nil
+}
+
+// Check returns ErrSchemaOutdated when the database is behind the embedded migrations, so a
+// service started with auto migration off refuses to run against a schema it does not know.
+// A database ahead of them is accepted, as happens while a release is rolled back. Check does
+// not write to the database.
+func (m Migrations) Check(ctx context.Context, db *sql.DB) error {
+	if err := m.setup(); err != nil {
+		return err
+	}
+	migrations, err := goose.CollectMigrations(".", 0, goose.MaxVersion)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	latest, err := migrations.Last()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	// Looked up first, getting the version would create the table and hide a baseline to adopt
+	versioned, err := tableExists(db, goose.TableName())
+	if err != nil {
+		return fmt.Errorf("failed to check migration version table: %w", err)
+	}
+	if !versioned {
+		return fmt.Errorf("%w: no migration applied, latest is %d; run migrate up", ErrSchemaOutdated, latest.Version)
+	}
+
+	current, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to get database version: %w", err)
+	}
+	switch {
+	case current < latest.Version:
+		return fmt.Errorf("%w: database is at version %d, latest is %d; run migrate up",
+			ErrSchemaOutdated, current, latest.Version)
+	case current > latest.Version:
+		log.Printf("Database is at version %d, ahead of the latest migration %d of this build", current, latest.Version)
+	}
+	return nil
+}
+
+func tableExists(db *sql.DB, table string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1)`
+	err := db.QueryRow(query, table).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,40 @@
+package parameter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedConversion is returned when there is no conversion between two units
+var ErrUnsupportedConversion = errors.New("unsupported unit conversion")
+
+// Conversions to a canonical unit from the other units of the same quantity
+var toCanonical = map[Unit]map[Unit]func(float64) float64{
+	UnitMillimeter: {
+		UnitInch: func(v float64) float64 { return v * 25.4 },
+	},
+	UnitCelsius: {
+		UnitFahrenheit: func(v float64) float64 { return (v - 32) * 5 / 9 },
+		UnitKelvin:     func(v float64) float64 { return v - 273.15 },
+	},
+	UnitHectopascal: {
+		UnitInchOfMercury: func(v float64) float64 { return v * 33.8639 },
+	},
+	UnitMeterPerSecond: {
+		UnitMilePerHour:      func(v float64) float64 { return v * 0.44704 },
+		UnitKilometerPerHour: func(v float64) float64 { return v / 3.6 },
+	},
+}
+
+// Convert converts a value between units of the same quantity. Only conversions into a
+// canonical unit are supported, the only direction readings are normalized in.
+func Convert(value float64, from, to Unit) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+	convert, ok := toCanonical[to][from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s to %s", ErrUnsupportedConversion, from, to)
+	}
+	return convert(value), nil
+}
@@ -0,0 +1,3 @@
+module agrisa/parameter
+
+go 1.25.1
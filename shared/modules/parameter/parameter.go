@@ -0,0 +1,83 @@
+// Package parameter holds the parameter names and units shared by the services. Every reading
+// that crosses a service boundary is named and measured as below, whichever provider it came from.
+package parameter
+
+// Name identifies a monitored parameter, stored by policy-service as a data source parameter name
+type Name string
+
+const (
+	NDVI     Name = "ndvi"
+	NDMI     Name = "ndmi"
+	RainFall Name = "rainfall"
+	// Standardized Precipitation Index over 1, 3 and 6 months, derived by weather-service
+	SPI1 Name = "spi_1"
+	SPI3 Name = "spi_3"
+	SPI6 Name = "spi_6"
+	// Daily FAO-56 reference evapotranspiration and growing degree days, derived by weather-service
+	ET0 Name = "et0"
+	GDD Name = "gdd"
+	// Daily fungal disease infection risk from 0 to 100, derived by weather-service from humidity
+	// and temperature
+	DiseaseRisk Name = "disease_risk"
+	// Daily worst air quality index on the OpenWeatherMap scale from 1 (good) to 5 (very poor)
+	AQI Name = "aqi"
+	// Length in days of the dry spell a day ends, derived by weather-service from daily rainfall
+	DrySpell Name = "dry_spell"
+	// Weather readings pushed by the weather-service polling workers
+	Temperature Name = "temperature"
+	Humidity    Name = "humidity"
+	Pressure    Name = "pressure"
+	WindSpeed   Name = "wind_speed"
+	CloudCover  Name = "cloud_cover"
+)
+
+// Unit is the unit of a reading
+type Unit string
+
+const (
+	UnitIndex            Unit = "index"
+	UnitDegreeDay        Unit = "degree_day"
+	UnitDay              Unit = "day"
+	UnitMillimeter       Unit = "mm"
+	UnitInch             Unit = "in"
+	UnitCelsius          Unit = "celsius"
+	UnitFahrenheit       Unit = "fahrenheit"
+	UnitKelvin           Unit = "kelvin"
+	UnitPercent          Unit = "percent"
+	UnitHectopascal      Unit = "hPa"
+	UnitInchOfMercury    Unit = "inHg"
+	UnitMeterPerSecond   Unit = "m/s"
+	UnitMilePerHour      Unit = "mph"
+	UnitKilometerPerHour Unit = "km/h"
+)
+
+// CanonicalUnits are the units every reading of a parameter is normalized to
+var CanonicalUnits = map[Name]Unit{
+	NDVI:        UnitIndex,
+	NDMI:        UnitIndex,
+	RainFall:    UnitMillimeter,
+	SPI1:        UnitIndex,
+	SPI3:        UnitIndex,
+	SPI6:        UnitIndex,
+	ET0:         UnitMillimeter,
+	GDD:         UnitDegreeDay,
+	DiseaseRisk: UnitIndex,
+	AQI:         UnitIndex,
+	DrySpell:    UnitDay,
+	Temperature: UnitCelsius,
+	Humidity:    UnitPercent,
+	Pressure:    UnitHectopascal,
+	WindSpeed:   UnitMeterPerSecond,
+	CloudCover:  UnitPercent,
+}
+
+// Valid reports whether name is a shared parameter name
+func (n Name) Valid() bool {
+	_, ok := CanonicalUnits[n]
+	return ok
+}
+
+// Unit returns the canonical unit of the parameter
+func (n Name) Unit() Unit {
+	return CanonicalUnits[n]
+}
@@ -0,0 +1,110 @@
+package parameter
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		from, to Unit
+		want     float64
+	}{
+		{"inches to mm", 2, UnitInch, UnitMillimeter, 50.8},
+		{"fahrenheit to celsius", 212, UnitFahrenheit, UnitCelsius, 100},
+		{"freezing fahrenheit", 32, UnitFahrenheit, UnitCelsius, 0},
+		{"kelvin to celsius", 300.15, UnitKelvin, UnitCelsius, 27},
+		{"mph to m/s", 10, UnitMilePerHour, UnitMeterPerSecond, 4.4704},
+		{"km/h to m/s", 36, UnitKilometerPerHour, UnitMeterPerSecond, 10},
+		{"inHg to hPa", 1, UnitInchOfMercury, UnitHectopascal, 33.8639},
+		{"same unit", 12.5, UnitMillimeter, UnitMillimeter, 12.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.value, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !almostEqual(got, tt.want) {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert_Unsupported(t *testing.T) {
+	for _, units := range [][2]Unit{
+		{UnitMillimeter, UnitCelsius},
+		{UnitMillimeter, UnitInch},
+		{UnitPercent, UnitIndex},
+	} {
+		if _, err := Convert(1, units[0], units[1]); !errors.Is(err, ErrUnsupportedConversion) {
+			t.Errorf("Convert(%s, %s) error = %v, want ErrUnsupportedConversion", units[0], units[1], err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		field    string
+		value    float64
+		system   string
+		want     Reading
+	}{
+		{"one call metric temperature", ProviderOneCall, "temp", 25, SystemMetric, Reading{Temperature, 25, UnitCelsius}},
+		{"one call imperial temperature", ProviderOneCall, "temp", 77, SystemImperial, Reading{Temperature, 25, UnitCelsius}},
+		{"one call standard temperature", ProviderOneCall, "temp", 298.15, SystemStandard, Reading{Temperature, 25, UnitCelsius}},
+		{"one call default system is standard", ProviderOneCall, "temp", 273.15, "", Reading{Temperature, 0, UnitCelsius}},
+		{"one call imperial wind", ProviderOneCall, "wind_speed", 10, SystemImperial, Reading{WindSpeed, 4.4704, UnitMeterPerSecond}},
+		{"one call imperial rain stays mm", ProviderOneCall, "rain.1h", 3.2, SystemImperial, Reading{RainFall, 3.2, UnitMillimeter}},
+		{"one call imperial pressure stays hPa", ProviderOneCall, "pressure", 1012, SystemImperial, Reading{Pressure, 1012, UnitHectopascal}},
+		{"one call clouds", ProviderOneCall, "clouds", 40, SystemMetric, Reading{CloudCover, 40, UnitPercent}},
+		{"day summary metric minimum temperature", ProviderDaySummary, "temperature.min", 21.5, SystemMetric, Reading{Temperature, 21.5, UnitCelsius}},
+		{"day summary imperial wind", ProviderDaySummary, "wind.max.speed", 10, SystemImperial, Reading{WindSpeed, 4.4704, UnitMeterPerSecond}},
+		{"day summary precipitation total", ProviderDaySummary, "precipitation.total", 12.4, SystemMetric, Reading{RainFall, 12.4, UnitMillimeter}},
+		{"statistics temperature is kelvin in any system", ProviderStatistics, "temp.mean", 300.15, SystemMetric, Reading{Temperature, 27, UnitCelsius}},
+		{"agro temperature is kelvin in any system", ProviderAgro, "main.temp", 300.15, SystemMetric, Reading{Temperature, 27, UnitCelsius}},
+		{"agro 3h rain", ProviderAgro, "rain.3h", 7, SystemStandard, Reading{RainFall, 7, UnitMillimeter}},
+		{"agro wind", ProviderAgro, "wind.speed", 5, SystemStandard, Reading{WindSpeed, 5, UnitMeterPerSecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.provider, tt.field, tt.value, tt.system)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if got.Name != tt.want.Name || got.Unit != tt.want.Unit || !almostEqual(got.Value, tt.want.Value) {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_UnknownField(t *testing.T) {
+	if _, err := Normalize(ProviderOneCall, "dew_point", 10, SystemMetric); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Normalize() error = %v, want ErrUnknownField", err)
+	}
+	if _, err := Normalize("unknown", "temp", 10, SystemMetric); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Normalize() error = %v, want ErrUnknownField", err)
+	}
+}
+
+func TestNameUnits(t *testing.T) {
+	for _, name := range []Name{NDVI, NDMI, RainFall, SPI1, SPI3, SPI6, ET0, GDD, Temperature, Humidity, Pressure, WindSpeed, CloudCover} {
+		if !name.Valid() || name.Unit() == "" {
+			t.Errorf("%s has no canonical unit", name)
+		}
+	}
+	if Name("soil_moisture").Valid() {
+		t.Error("unknown name reported valid")
+	}
+}
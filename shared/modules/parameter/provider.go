@@ -0,0 +1,112 @@
+package parameter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Providers with a field mapping
+const (
+	ProviderOneCall    = "openweathermap_onecall"
+	ProviderDaySummary = "openweathermap_day_summary"
+	ProviderStatistics = "openweathermap_statistics"
+	ProviderAgro       = "agro"
+)
+
+// Unit systems of the OpenWeatherMap units parameter. Agro and the statistics always report the
+// standard system.
+const (
+	SystemStandard = "standard"
+	SystemMetric   = "metric"
+	SystemImperial = "imperial"
+)
+
+// ErrUnknownField is returned for a provider field without a shared parameter
+var ErrUnknownField = errors.New("unknown provider field")
+
+// Shared parameter of each provider field. Nested fields are joined with a dot.
+var providerFields = map[string]map[string]Name{
+	ProviderOneCall: {
+		"temp":       Temperature,
+		"humidity":   Humidity,
+		"pressure":   Pressure,
+		"wind_speed": WindSpeed,
+		"clouds":     CloudCover,
+		"rain.1h":    RainFall,
+		"snow.1h":    RainFall,
+	},
+	ProviderDaySummary: {
+		"temperature.min":       Temperature,
+		"temperature.max":       Temperature,
+		"temperature.morning":   Temperature,
+		"temperature.afternoon": Temperature,
+		"temperature.evening":   Temperature,
+		"temperature.night":     Temperature,
+		"humidity.afternoon":    Humidity,
+		"pressure.afternoon":    Pressure,
+		"wind.max.speed":        WindSpeed,
+		"cloud_cover.afternoon": CloudCover,
+		"precipitation.total":   RainFall,
+	},
+	// Long-term statistics of hourly readings; precipitation is the mean hourly amount
+	ProviderStatistics: {
+		"temp.mean":          Temperature,
+		"humidity.mean":      Humidity,
+		"pressure.mean":      Pressure,
+		"wind.mean":          WindSpeed,
+		"clouds.mean":        CloudCover,
+		"precipitation.mean": RainFall,
+	},
+	ProviderAgro: {
+		"main.temp":     Temperature,
+		"main.humidity": Humidity,
+		"main.pressure": Pressure,
+		"wind.speed":    WindSpeed,
+		"clouds.all":    CloudCover,
+		"rain.1h":       RainFall,
+		"rain.3h":       RainFall,
+		"snow.1h":       RainFall,
+		"snow.3h":       RainFall,
+	},
+}
+
+// Units reported in a unit system in place of the canonical unit; precipitation is always in mm
+// and pressure in hPa
+var systemUnits = map[string]map[Unit]Unit{
+	SystemStandard: {UnitCelsius: UnitKelvin},
+	SystemImperial: {UnitCelsius: UnitFahrenheit, UnitMeterPerSecond: UnitMilePerHour},
+}
+
+// Reading is a provider value normalized to a shared parameter and its canonical unit
+type Reading struct {
+	Name  Name
+	Value float64
+	Unit  Unit
+}
+
+// ProviderUnit returns the unit a provider reports a parameter in for the given unit system.
+// Without a system OpenWeatherMap reports the standard one.
+func ProviderUnit(provider string, name Name, system string) Unit {
+	if provider == ProviderAgro || provider == ProviderStatistics || system == "" {
+		system = SystemStandard
+	}
+	canonical := name.Unit()
+	if unit, ok := systemUnits[system][canonical]; ok {
+		return unit
+	}
+	return canonical
+}
+
+// Normalize maps a provider field to its shared parameter and converts the value to the
+// parameter's canonical unit
+func Normalize(provider, field string, value float64, system string) (Reading, error) {
+	name, ok := providerFields[provider][field]
+	if !ok {
+		return Reading{}, fmt.Errorf("%w: %s %s", ErrUnknownField, provider, field)
+	}
+	converted, err := Convert(value, ProviderUnit(provider, name, system), name.Unit())
+	if err != nil {
+		return Reading{}, err
+	}
+	return Reading{Name: name, Value: converted, Unit: name.Unit()}, nil
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Address match levels, from no match to a full province + commune match
+const (
+	AddressMatchNone     = "none"
+	AddressMatchProvince = "province"
+	AddressMatchCommune  = "commune"
+)
+
+// NormalizedAddress is a free-text address mapped onto the two-level
+// province/commune taxonomy in effect since the 2025 administrative merges.
+// The pre-merge province and district found in the raw text are kept for
+// analytics that still group by the old units.
+type NormalizedAddress struct {
+	Raw            string `json:"raw"`
+	Street         string `json:"street"`
+	CommuneCode    string `json:"commune_code"`
+	CommuneName    string `json:"commune_name"`
+	ProvinceCode   string `json:"province_code"`
+	ProvinceName   string `json:"province_name"`
+	LegacyDistrict string `json:"legacy_district"`
+	LegacyProvince string `json:"legacy_province"`
+	MatchLevel     string `json:"match_level"`
+}
+
+// mergedProvinces maps every province abolished by the 2025 merges to the
+// province that absorbed it. Keys are folded with foldAddressName.
+var mergedProvinces = map[string]string{
+	"ha giang":        "tuyen quang",
+	"yen bai":         "lao cai",
+	"bac kan":         "thai nguyen",
+	"vinh phuc":       "phu tho",
+	"hoa binh":        "phu tho",
+	"bac giang":       "bac ninh",
+	"thai binh":       "hung yen",
+	"hai duong":       "hai phong",
+	"ha nam":          "ninh binh",
+	"nam dinh":        "ninh binh",
+	"quang binh":      "quang tri",
+	"quang nam":       "da nang",
+	"kon tum":         "quang ngai",
+	"binh dinh":       "gia lai",
+	"ninh thuan":      "khanh hoa",
+	"dak nong":        "lam dong",
+	"binh thuan":      "lam dong",
+	"phu yen":         "dak lak",
+	"binh duong":      "ho chi minh",
+	"ba ria vung tau": "ho chi minh",
+	"binh phuoc":      "dong nai",
+	"long an":         "tay ninh",
+	"soc trang":       "can tho",
+	"hau giang":       "can tho",
+	"ben tre":         "vinh long",
+	"tra vinh":        "vinh long",
+	"tien giang":      "dong thap",
+	"bac lieu":        "ca mau",
+	"kien giang":      "an giang",
+	"thua thien hue":  "hue",
+}
+
+// Administrative prefixes stripped before names are compared, longest first
+var addressUnitPrefixes = []string{
+	"thanh pho ", "thi tran ", "thi xa ", "dac khu ",
+	"tinh ", "huyen ", "quan ", "phuong ", "xa ",
+	"tp. ", "tp.", "tp ", "tx. ", "tt. ", "q. ", "h. ", "p. ", "x. ",
+}
+
+var districtPrefixes = []string{"huyen ", "quan ", "thi xa ", "thanh pho ", "tp. ", "tp ", "tx. ", "q. ", "h. "}
+
+var vietnameseFold = map[rune]rune{}
+
+func init() {
+	groups := map[rune]string{
+		'a': "àáạảãâầấậẩẫăằắặẳẵ",
+		'e': "èéẹẻẽêềếệểễ",
+		'i': "ìíịỉĩ",
+		'o': "òóọỏõôồốộổỗơờớợởỡ",
+		'u': "ùúụủũưừứựửữ",
+		'y': "ỳýỵỷỹ",
+		'd': "đ",
+	}
+	for base, variants := range groups {
+		for _, r := range variants {
+			vietnameseFold[r] = base
+		}
+	}
+}
+
+// foldAddressName lowercases a name, removes Vietnamese diacritics and
+// punctuation, and collapses whitespace so OCR and API spellings compare equal
+func foldAddressName(name string) string {
+	return FoldVietnamese(name)
+}
+
+// FoldVietnamese lowercases text, removes Vietnamese diacritics, turns hyphens
+// and underscores into spaces and collapses whitespace, so spellings of a name
+// with and without accents compare equal
+func FoldVietnamese(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if folded, ok := vietnameseFold[r]; ok {
+			r = folded
+		}
+		if r == '-' || r == '_' {
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// addressNameKey folds a name and strips its administrative prefix
+func addressNameKey(name string) string {
+	folded := foldAddressName(name)
+	for _, prefix := range addressUnitPrefixes {
+		if strings.HasPrefix(folded, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(folded, prefix))
+		}
+	}
+	return folded
+}
+
+func isDistrictSegment(segment string) bool {
+	folded := foldAddressName(segment)
+	for _, prefix := range districtPrefixes {
+		if strings.HasPrefix(folded, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressTaxonomy caches the province and commune lists of the address API;
+// the taxonomy only changes with a new administrative decree
+type addressTaxonomy struct {
+	mu        sync.Mutex
+	provinces map[string]Province
+	communes  map[string]map[string]Commune
+}
+
+var taxonomy = &addressTaxonomy{
+	communes: make(map[string]map[string]Commune),
+}
+
+func (t *addressTaxonomy) getProvinces() (map[string]Province, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.provinces != nil {
+		return t.provinces, nil
+	}
+	provinces, err := GetProvinceInfo()
+	if err != nil {
+		return nil, err
+	}
+	t.provinces = make(map[string]Province, len(provinces))
+	for _, province := range provinces {
+		t.provinces[addressNameKey(province.Name)] = province
+	}
+	return t.provinces, nil
+}
+
+func (t *addressTaxonomy) getCommunes(provinceCode string) (map[string]Commune, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if communes, ok := t.communes[provinceCode]; ok {
+		return communes, nil
+	}
+	list, err := GetWardInfo(provinceCode)
+	if err != nil {
+		return nil, err
+	}
+	communes := make(map[string]Commune, len(list))
+	for _, commune := range list {
+		communes[addressNameKey(commune.Name)] = commune
+	}
+	t.communes[provinceCode] = communes
+	return communes, nil
+}
+
+// NormalizeAddress maps a comma separated free-text address, as returned by
+// OCR, to the current province and commune. Pre-merge province names are
+// resolved to the province that absorbed them. The result is always non-nil
+// and keeps the raw text; an error is returned only when the taxonomy cannot
+// be loaded.
+func NormalizeAddress(raw string) (*NormalizedAddress, error) {
+	result := &NormalizedAddress{
+		Raw:        raw,
+		MatchLevel: AddressMatchNone,
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(raw, ",") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return result, nil
+	}
+
+	provinces, err := taxonomy.getProvinces()
+	if err != nil {
+		return result, fmt.Errorf("failed to load provinces: %w", err)
+	}
+
+	// The province is the last segment that names one, current or merged
+	provinceIdx := -1
+	var province Province
+	for i := len(segments) - 1; i >= 0 && provinceIdx < 0; i-- {
+		key := addressNameKey(segments[i])
+		if p, ok := provinces[key]; ok {
+			province, provinceIdx = p, i
+		} else if merged, ok := mergedProvinces[key]; ok {
+			if p, ok := provinces[merged]; ok {
+				province, provinceIdx = p, i
+				result.LegacyProvince = segments[i]
+			}
+		}
+	}
+	if provinceIdx < 0 {
+		result.Street = strings.Join(segments, ", ")
+		return result, nil
+	}
+	result.ProvinceCode = province.Code
+	result.ProvinceName = province.Name
+	result.MatchLevel = AddressMatchProvince
+
+	rest := segments[:provinceIdx]
+	if len(rest) > 0 && isDistrictSegment(rest[len(rest)-1]) {
+		result.LegacyDistrict = rest[len(rest)-1]
+		rest = rest[:len(rest)-1]
+	}
+
+	communes, err := taxonomy.getCommunes(province.Code)
+	if err != nil {
+		result.Street = strings.Join(rest, ", ")
+		return result, fmt.Errorf("failed to load communes of province %s: %w", province.Code, err)
+	}
+
+	// The commune is the segment closest to the province that names one
+	for i := len(rest) - 1; i >= 0; i-- {
+		if commune, ok := communes[addressNameKey(rest[i])]; ok {
+			result.CommuneCode = commune.Code
+			result.CommuneName = commune.Name
+			result.MatchLevel = AddressMatchCommune
+			rest = rest[:i]
+			break
+		}
+	}
+	result.Street = strings.Join(rest, ", ")
+	return result, nil
+}
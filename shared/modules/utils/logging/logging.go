@@ -0,0 +1,92 @@
+// Package logging sets up the structured logs of a service: JSON records written to rotating
+// files and tagged with the ID of the request, queue message or job they belong to.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Directory the services keep their logs under, one subdirectory per service
+const baseDir = "/agrisa/log"
+
+// Setup sends the log and slog output of a service as JSON records to rotating files under
+// /agrisa/log/<service>. LOG_LEVEL (debug, info, warn or error), LOG_MAX_SIZE_MB and
+// LOG_MAX_AGE_DAYS set the lowest level written, the size a file is rotated at and how long
+// rotated files are kept. Rotated files are gzipped unless LOG_COMPRESS is false, and
+// LOG_STDOUT=true copies the records to stdout for container log collectors. The returned
+// closer closes the current file.
+func Setup(service string) (io.Closer, error) {
+	maxSize := int64(envInt("LOG_MAX_SIZE_MB", 100)) * 1024 * 1024
+	maxAge := time.Duration(envInt("LOG_MAX_AGE_DAYS", 30)) * 24 * time.Hour
+	file, err := NewRotatingFile(filepath.Join(baseDir, service), maxSize, maxAge, envBool("LOG_COMPRESS", true))
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = file
+	if envBool("LOG_STDOUT", false) {
+		out = io.MultiWriter(file, os.Stdout)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(envString("LOG_LEVEL", "info"))); err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+	handler := slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level, AddSource: true})
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	// Records of the log package are written by the handler, which adds the time itself
+	log.SetFlags(0)
+	return file, nil
+}
+
+// contextHandler adds the request ID carried by the context of a record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func envString(key, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func envBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(envString(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func envInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(envString(key, ""))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
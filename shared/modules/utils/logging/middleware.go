@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v3"
+)
+
+// HeaderUserID carries the ID of the authenticated user, set by the gateway
+const HeaderUserID = "X-User-ID"
+
+// GinMiddleware tags each request with the request ID sent in its X-Request-ID header or a new
+// one, returns the ID in the response and logs the request once it completes. The user ID set by
+// the gateway is carried by the request context too.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := acceptRequestID(c.GetHeader(HeaderRequestID))
+		ctx := WithRequestID(c.Request.Context(), id)
+		if userID := c.GetHeader(HeaderUserID); userID != "" {
+			ctx = WithUserID(ctx, userID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Set("request_id", id)
+		c.Header(HeaderRequestID, id)
+
+		c.Next()
+
+		var err error
+		if len(c.Errors) > 0 {
+			err = c.Errors.Last()
+		}
+		logRequest(ctx, c.Request.Method, c.Request.URL.Path, c.Writer.Status(),
+			c.GetHeader(HeaderUserID), time.Since(start), err)
+	}
+}
+
+// FiberMiddleware is GinMiddleware for Fiber; handlers find the request ID in c.Context()
+func FiberMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		id := acceptRequestID(c.Get(HeaderRequestID))
+		ctx := WithRequestID(c.Context(), id)
+		if userID := c.Get(HeaderUserID); userID != "" {
+			ctx = WithUserID(ctx, userID)
+		}
+		c.SetContext(ctx)
+		c.Locals("request_id", id)
+		c.Set(HeaderRequestID, id)
+
+		err := c.Next()
+
+		logRequest(ctx, c.Method(), c.Path(), c.Response().StatusCode(),
+			c.Get(HeaderUserID), time.Since(start), err)
+		return err
+	}
+}
+
+// logRequest writes the access log record of a request: server errors at error level, client
+// errors at warn level so they can be filtered out of the normal traffic
+func logRequest(ctx context.Context, method, path string, status int, userID string, latency time.Duration, err error) {
+	attrs := []any{
+		"method", method,
+		"path", path,
+		"status", status,
+		"duration_ms", latency.Milliseconds(),
+	}
+	if userID != "" {
+		attrs = append(attrs, "user_id", userID)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "Request completed", attrs...)
+}
+
+// Transport sends the request ID carried by the context of an outgoing request in its
+// X-Request-ID header, so the logs of the called service join those of the caller
+type Transport struct {
+	// Base sends the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := RequestID(req.Context())
+	if id == "" || req.Header.Get(HeaderRequestID) != "" {
+		return base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderRequestID, id)
+	return base.RoundTrip(req)
+}
+
+// NewHTTPClient returns an HTTP client that forwards request IDs, for calls to other services
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: &Transport{}}
+}
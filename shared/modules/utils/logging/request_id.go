@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// HeaderRequestID carries the request ID on HTTP requests, responses and queue messages
+const HeaderRequestID = "X-Request-ID"
+
+// Longest request ID taken from a caller; longer ones are replaced
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type userIDKey struct{}
+
+// WithUserID returns ctx carrying the ID of the user the request is made for
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserID returns the user ID carried by ctx, or an empty string for work no user asked for
+func UserID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// acceptRequestID returns the request ID sent by a caller, or a new one when it sent none or an
+// unusable one
+func acceptRequestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return NewRequestID()
+	}
+	for _, r := range id {
+		if r < 0x20 || r > 0x7e {
+			return NewRequestID()
+		}
+	}
+	return id
+}
+
+// MessageHeaders returns the headers carrying the request ID of ctx on a queue message, or nil
+// when ctx has none
+func MessageHeaders(ctx context.Context) map[string]any {
+	id := RequestID(ctx)
+	if id == "" {
+		return nil
+	}
+	return map[string]any{HeaderRequestID: id}
+}
+
+// FromMessageHeaders returns ctx carrying the request ID of a queue message, or a new one for
+// messages published without one
+func FromMessageHeaders(ctx context.Context, headers map[string]any) context.Context {
+	id, _ := headers[HeaderRequestID].(string)
+	return WithRequestID(ctx, acceptRequestID(id))
+}
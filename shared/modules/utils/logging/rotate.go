@@ -0,0 +1,206 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile writes logs to one file per day, log_<date>.log, and moves on to a new file when
+// the current one reaches its size limit. The full file is kept as log_<date>.<n>.log, and files
+// older than the retention are removed whenever a file is rotated. With compression on, the
+// files no longer written to are gzipped in the background to log_<date>[.<n>].log.gz.
+type RotatingFile struct {
+	mu       sync.Mutex
+	dir      string
+	maxSize  int64
+	maxAge   time.Duration
+	compress bool
+	file     *os.File
+	day      string
+	size     int64
+
+	// Held while compressing so overlapping rotations do not gzip the same file twice
+	compressMu sync.Mutex
+}
+
+// NewRotatingFile opens the log file of today in dir, creating the directory if needed
+func NewRotatingFile(dir string, maxSize int64, maxAge time.Duration, compress bool) (*RotatingFile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f := &RotatingFile{dir: dir, maxSize: maxSize, maxAge: maxAge, compress: compress}
+	if err := f.open(time.Now().Format("2006-01-02")); err != nil {
+		return nil, err
+	}
+	// Files left uncompressed by an earlier run
+	if compress {
+		go f.compressRotated(f.path(f.day))
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) path(day string) string {
+	return filepath.Join(f.dir, fmt.Sprintf("log_%s.log", day))
+}
+
+func (f *RotatingFile) open(day string) error {
+	file, err := os.OpenFile(f.path(day), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file, f.day, f.size = file, day, info.Size()
+	return nil
+}
+
+// rotate closes the current file, keeping it under a numbered name if it filled up on the same
+// day, and opens the file of day
+func (f *RotatingFile) rotate(day string) error {
+	if f.file != nil {
+		f.file.Close()
+		if day == f.day {
+			current := f.path(f.day)
+			for n := 1; ; n++ {
+				rotated := strings.TrimSuffix(current, ".log") + fmt.Sprintf(".%d.log", n)
+				// A compressed file holds the number as well
+				if exists(rotated) || exists(rotated+".gz") {
+					continue
+				}
+				if err := os.Rename(current, rotated); err != nil {
+					return fmt.Errorf("failed to rotate log file: %w", err)
+				}
+				break
+			}
+		}
+		f.file = nil
+	}
+	f.removeExpired()
+	if err := f.open(day); err != nil {
+		return err
+	}
+	if f.compress {
+		go f.compressRotated(f.path(f.day))
+	}
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
+
+// removeExpired removes the log files last written before the retention
+func (f *RotatingFile) removeExpired() {
+	if f.maxAge <= 0 {
+		return
+	}
+	paths, err := filepath.Glob(filepath.Join(f.dir, "log_*.log"))
+	if err != nil {
+		return
+	}
+	compressed, _ := filepath.Glob(filepath.Join(f.dir, "log_*.log.gz"))
+	paths = append(paths, compressed...)
+	cutoff := time.Now().Add(-f.maxAge)
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+	}
+}
+
+// compressRotated gzips every log file in the directory except current, the one being written
+func (f *RotatingFile) compressRotated(current string) {
+	f.compressMu.Lock()
+	defer f.compressMu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(f.dir, "log_*.log"))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		if path == current {
+			continue
+		}
+		if err := gzipFile(path); err != nil {
+			// Logging here would write back into the file being rotated
+			fmt.Fprintf(os.Stderr, "failed to compress log file %s: %v\n", path, err)
+		}
+	}
+}
+
+// gzipFile replaces path with path.gz, keeping its modification time for the retention
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Written under a temporary name so a crash never leaves a truncated .gz behind
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	day := time.Now().Format("2006-01-02")
+	if f.file == nil || day != f.day || (f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize) {
+		if err := f.rotate(day); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file; a later write opens it again
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,238 @@
+// Package shutdown stops a service in order once it receives SIGINT or SIGTERM: servers stop
+// accepting connections and finish the requests in flight, background work drains, then the
+// clients of Postgres, Redis, RabbitMQ and MinIO are closed.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultTimeout bounds the draining of servers and workers. Together with the time given to
+// close the clients it stays under the stop grace period of the containers (30s).
+const DefaultTimeout = 20 * time.Second
+
+// closeTimeout bounds each client close; closes run even when draining used up the deadline
+const closeTimeout = 2 * time.Second
+
+type phase int
+
+const (
+	phaseServers phase = iota
+	phaseWorkers
+	phaseClients
+)
+
+func (p phase) String() string {
+	switch p {
+	case phaseServers:
+		return "servers"
+	case phaseWorkers:
+		return "workers"
+	default:
+		return "clients"
+	}
+}
+
+type step struct {
+	phase phase
+	name  string
+	fn    func(ctx context.Context) error
+}
+
+// Coordinator runs the shutdown steps registered by a service
+type Coordinator struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	steps   []step
+	failure error
+
+	// Cancelled when the servers have drained
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	trigger     chan struct{}
+	triggerOnce sync.Once
+}
+
+// New returns a coordinator whose draining is bounded by the SHUTDOWN_TIMEOUT environment
+// variable, a duration such as "20s", or DefaultTimeout when it is not set
+func New() *Coordinator {
+	timeout := DefaultTimeout
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			timeout = d
+		} else {
+			slog.Warn("Invalid SHUTDOWN_TIMEOUT, using default", "value", value, "default", DefaultTimeout)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Coordinator{
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		trigger: make(chan struct{}),
+	}
+}
+
+// Context is cancelled once the servers have drained. Background loops started with it stop
+// before the clients they use are closed.
+func (c *Coordinator) Context() context.Context {
+	return c.ctx
+}
+
+// Server registers a step that stops a server from accepting connections and waits for its
+// requests in flight, such as http.Server.Shutdown or fiber.App.ShutdownWithContext
+func (c *Coordinator) Server(name string, fn func(ctx context.Context) error) {
+	c.add(phaseServers, name, fn)
+}
+
+// Worker registers a step that waits for background work to finish; it runs after Context is
+// cancelled
+func (c *Coordinator) Worker(name string, fn func(ctx context.Context) error) {
+	c.add(phaseWorkers, name, fn)
+}
+
+// Go runs a background loop with Context and registers a worker step waiting for it to return
+func (c *Coordinator) Go(name string, fn func(ctx context.Context)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(c.ctx)
+	}()
+	c.Worker(name, func(context.Context) error {
+		<-done
+		return nil
+	})
+}
+
+// Closer registers a client to close once the servers and workers no longer use it
+func (c *Coordinator) Closer(name string, fn func() error) {
+	c.add(phaseClients, name, func(context.Context) error { return fn() })
+}
+
+func (c *Coordinator) add(p phase, name string, fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps = append(c.steps, step{phase: p, name: name, fn: fn})
+}
+
+// ListenAndServe starts srv in the background and registers its shutdown. A server that cannot
+// listen shuts the service down.
+func (c *Coordinator) ListenAndServe(name string, srv *http.Server) {
+	c.Server(name, srv.Shutdown)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.Fail(fmt.Errorf("%s server: %w", name, err))
+		}
+	}()
+}
+
+// Fail starts the shutdown because the service cannot keep running; Wait returns err
+func (c *Coordinator) Fail(err error) {
+	c.mu.Lock()
+	if c.failure == nil {
+		c.failure = err
+	}
+	c.mu.Unlock()
+	c.triggerOnce.Do(func() { close(c.trigger) })
+}
+
+// Wait blocks until SIGINT, SIGTERM or Fail, then runs the registered steps: servers first, then
+// workers, then clients, each group in registration order. A server or worker still running at
+// the deadline is left behind so the clients are closed anyway. A second signal exits at once.
+// Wait returns the error given to Fail, if any.
+func (c *Coordinator) Wait() error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-signals:
+		slog.Info("Shutdown signal received", "signal", sig.String(), "timeout", c.timeout)
+	case <-c.trigger:
+		slog.Error("Shutting down after failure", "error", c.err())
+	}
+
+	go func() {
+		sig := <-signals
+		slog.Warn("Second shutdown signal received, exiting immediately", "signal", sig.String())
+		os.Exit(1)
+	}()
+
+	c.run()
+	return c.err()
+}
+
+func (c *Coordinator) err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failure
+}
+
+func (c *Coordinator) run() {
+	c.mu.Lock()
+	steps := append([]step(nil), c.steps...)
+	c.mu.Unlock()
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	for _, p := range []phase{phaseServers, phaseWorkers, phaseClients} {
+		if p == phaseWorkers {
+			c.cancel()
+		}
+		for _, s := range steps {
+			if s.phase != p {
+				continue
+			}
+			if p == phaseClients {
+				closeCtx, closeCancel := context.WithTimeout(context.Background(), closeTimeout)
+				runStep(closeCtx, s)
+				closeCancel()
+				continue
+			}
+			runStep(ctx, s)
+		}
+	}
+
+	slog.Info("Shutdown complete", "duration", time.Since(started))
+}
+
+// runStep runs a step until it returns or ctx expires, whichever comes first
+func runStep(ctx context.Context, s step) {
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic recovered: %v", r)
+			}
+		}()
+		done <- s.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			slog.Error("Shutdown step failed", "phase", s.phase.String(), "step", s.name,
+				"duration", time.Since(started), "error", err)
+			return
+		}
+		slog.Info("Shutdown step completed", "phase", s.phase.String(), "step", s.name,
+			"duration", time.Since(started))
+	case <-ctx.Done():
+		slog.Error("Shutdown step did not finish before the deadline, skipping",
+			"phase", s.phase.String(), "step", s.name, "duration", time.Since(started))
+	}
+}
@@ -0,0 +1,4 @@
+// Package weatherpb holds the gRPC contract of weather-service and its generated client and server.
+package weatherpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative weather.proto
@@ -0,0 +1,957 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: weather.proto
+
+package weatherpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Location is an Agro polygon or a point
+type Location struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Location:
+	//
+	//	*Location_PolygonId
+	//	*Location_Point
+	Location      isLocation_Location `protobuf_oneof:"location"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Location) GetLocation() isLocation_Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Location) GetPolygonId() string {
+	if x != nil {
+		if x, ok := x.Location.(*Location_PolygonId); ok {
+			return x.PolygonId
+		}
+	}
+	return ""
+}
+
+func (x *Location) GetPoint() *Point {
+	if x != nil {
+		if x, ok := x.Location.(*Location_Point); ok {
+			return x.Point
+		}
+	}
+	return nil
+}
+
+type isLocation_Location interface {
+	isLocation_Location()
+}
+
+type Location_PolygonId struct {
+	PolygonId string `protobuf:"bytes,1,opt,name=polygon_id,json=polygonId,proto3,oneof"`
+}
+
+type Location_Point struct {
+	Point *Point `protobuf:"bytes,2,opt,name=point,proto3,oneof"`
+}
+
+func (*Location_PolygonId) isLocation_Location() {}
+
+func (*Location_Point) isLocation_Location() {}
+
+type Point struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon           float64                `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Point) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Point) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+// Observation is one stored reading, in the units of the weather history
+type Observation struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Parameter  string                 `protobuf:"bytes,1,opt,name=parameter,proto3" json:"parameter,omitempty"`
+	ObservedAt int64                  `protobuf:"varint,2,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"` // Unix timestamp
+	Value      float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	Unit       string                 `protobuf:"bytes,4,opt,name=unit,proto3" json:"unit,omitempty"`
+	Source     string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	IsForecast bool                   `protobuf:"varint,6,opt,name=is_forecast,json=isForecast,proto3" json:"is_forecast,omitempty"`
+	// Confidence in the reading from 0 to 1, unset when it was not scored
+	ConfidenceScore *float64 `protobuf:"fixed64,7,opt,name=confidence_score,json=confidenceScore,proto3,oneof" json:"confidence_score,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Observation) Reset() {
+	*x = Observation{}
+	mi := &file_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Observation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Observation) ProtoMessage() {}
+
+func (x *Observation) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Observation.ProtoReflect.Descriptor instead.
+func (*Observation) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Observation) GetParameter() string {
+	if x != nil {
+		return x.Parameter
+	}
+	return ""
+}
+
+func (x *Observation) GetObservedAt() int64 {
+	if x != nil {
+		return x.ObservedAt
+	}
+	return 0
+}
+
+func (x *Observation) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Observation) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Observation) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Observation) GetIsForecast() bool {
+	if x != nil {
+		return x.IsForecast
+	}
+	return false
+}
+
+func (x *Observation) GetConfidenceScore() float64 {
+	if x != nil && x.ConfidenceScore != nil {
+		return *x.ConfidenceScore
+	}
+	return 0
+}
+
+type GetObservationsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Location *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// temperature, humidity, pressure, wind_speed, cloud_cover or precipitation
+	Parameter       string `protobuf:"bytes,2,opt,name=parameter,proto3" json:"parameter,omitempty"`
+	Start           int64  `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"` // Unix timestamp
+	End             int64  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`     // Unix timestamp
+	IncludeForecast bool   `protobuf:"varint,5,opt,name=include_forecast,json=includeForecast,proto3" json:"include_forecast,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetObservationsRequest) Reset() {
+	*x = GetObservationsRequest{}
+	mi := &file_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetObservationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetObservationsRequest) ProtoMessage() {}
+
+func (x *GetObservationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetObservationsRequest.ProtoReflect.Descriptor instead.
+func (*GetObservationsRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetObservationsRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *GetObservationsRequest) GetParameter() string {
+	if x != nil {
+		return x.Parameter
+	}
+	return ""
+}
+
+func (x *GetObservationsRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *GetObservationsRequest) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *GetObservationsRequest) GetIncludeForecast() bool {
+	if x != nil {
+		return x.IncludeForecast
+	}
+	return false
+}
+
+type GetObservationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LocationKey   string                 `protobuf:"bytes,1,opt,name=location_key,json=locationKey,proto3" json:"location_key,omitempty"`
+	Parameter     string                 `protobuf:"bytes,2,opt,name=parameter,proto3" json:"parameter,omitempty"`
+	Unit          string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	Observations  []*Observation         `protobuf:"bytes,4,rep,name=observations,proto3" json:"observations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetObservationsResponse) Reset() {
+	*x = GetObservationsResponse{}
+	mi := &file_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetObservationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetObservationsResponse) ProtoMessage() {}
+
+func (x *GetObservationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetObservationsResponse.ProtoReflect.Descriptor instead.
+func (*GetObservationsResponse) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetObservationsResponse) GetLocationKey() string {
+	if x != nil {
+		return x.LocationKey
+	}
+	return ""
+}
+
+func (x *GetObservationsResponse) GetParameter() string {
+	if x != nil {
+		return x.Parameter
+	}
+	return ""
+}
+
+func (x *GetObservationsResponse) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *GetObservationsResponse) GetObservations() []*Observation {
+	if x != nil {
+		return x.Observations
+	}
+	return nil
+}
+
+type GetAggregateRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Location  *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Parameter string                 `protobuf:"bytes,2,opt,name=parameter,proto3" json:"parameter,omitempty"`
+	Start     int64                  `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"` // Unix timestamp
+	End       int64                  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`     // Unix timestamp
+	// sum, avg, min, max or change; sum when empty
+	Function      string `protobuf:"bytes,5,opt,name=function,proto3" json:"function,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAggregateRequest) Reset() {
+	*x = GetAggregateRequest{}
+	mi := &file_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAggregateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAggregateRequest) ProtoMessage() {}
+
+func (x *GetAggregateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAggregateRequest.ProtoReflect.Descriptor instead.
+func (*GetAggregateRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetAggregateRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *GetAggregateRequest) GetParameter() string {
+	if x != nil {
+		return x.Parameter
+	}
+	return ""
+}
+
+func (x *GetAggregateRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *GetAggregateRequest) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *GetAggregateRequest) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+// GetAggregateResponse holds the aggregate of the stored readings. Precipitation is aggregated
+// over daily totals, as trigger conditions evaluate one rainfall value per day.
+type GetAggregateResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	LocationKey    string                 `protobuf:"bytes,1,opt,name=location_key,json=locationKey,proto3" json:"location_key,omitempty"`
+	Parameter      string                 `protobuf:"bytes,2,opt,name=parameter,proto3" json:"parameter,omitempty"`
+	Unit           string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	Function       string                 `protobuf:"bytes,4,opt,name=function,proto3" json:"function,omitempty"`
+	Value          float64                `protobuf:"fixed64,5,opt,name=value,proto3" json:"value,omitempty"`
+	ValueCount     int32                  `protobuf:"varint,6,opt,name=value_count,json=valueCount,proto3" json:"value_count,omitempty"`               // Values aggregated, days for precipitation
+	DataPointCount int32                  `protobuf:"varint,7,opt,name=data_point_count,json=dataPointCount,proto3" json:"data_point_count,omitempty"` // Stored readings used
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetAggregateResponse) Reset() {
+	*x = GetAggregateResponse{}
+	mi := &file_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAggregateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAggregateResponse) ProtoMessage() {}
+
+func (x *GetAggregateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAggregateResponse.ProtoReflect.Descriptor instead.
+func (*GetAggregateResponse) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetAggregateResponse) GetLocationKey() string {
+	if x != nil {
+		return x.LocationKey
+	}
+	return ""
+}
+
+func (x *GetAggregateResponse) GetParameter() string {
+	if x != nil {
+		return x.Parameter
+	}
+	return ""
+}
+
+func (x *GetAggregateResponse) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *GetAggregateResponse) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *GetAggregateResponse) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *GetAggregateResponse) GetValueCount() int32 {
+	if x != nil {
+		return x.ValueCount
+	}
+	return 0
+}
+
+func (x *GetAggregateResponse) GetDataPointCount() int32 {
+	if x != nil {
+		return x.DataPointCount
+	}
+	return 0
+}
+
+type GetForecastRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetForecastRequest) Reset() {
+	*x = GetForecastRequest{}
+	mi := &file_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetForecastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetForecastRequest) ProtoMessage() {}
+
+func (x *GetForecastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetForecastRequest.ProtoReflect.Descriptor instead.
+func (*GetForecastRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetForecastRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *GetForecastRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+// ForecastPoint is one forecast step, normalized to celsius, m/s and mm
+type ForecastPoint struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Dt                       int64                  `protobuf:"varint,1,opt,name=dt,proto3" json:"dt,omitempty"` // Unix timestamp of the start of the step
+	TemperatureC             float64                `protobuf:"fixed64,2,opt,name=temperature_c,json=temperatureC,proto3" json:"temperature_c,omitempty"`
+	FeelsLikeC               float64                `protobuf:"fixed64,3,opt,name=feels_like_c,json=feelsLikeC,proto3" json:"feels_like_c,omitempty"`
+	HumidityPercent          float64                `protobuf:"fixed64,4,opt,name=humidity_percent,json=humidityPercent,proto3" json:"humidity_percent,omitempty"`
+	PressureHpa              float64                `protobuf:"fixed64,5,opt,name=pressure_hpa,json=pressureHpa,proto3" json:"pressure_hpa,omitempty"`
+	WindSpeedMs              float64                `protobuf:"fixed64,6,opt,name=wind_speed_ms,json=windSpeedMs,proto3" json:"wind_speed_ms,omitempty"`
+	CloudCoverPercent        float64                `protobuf:"fixed64,7,opt,name=cloud_cover_percent,json=cloudCoverPercent,proto3" json:"cloud_cover_percent,omitempty"`
+	PrecipitationMm          float64                `protobuf:"fixed64,8,opt,name=precipitation_mm,json=precipitationMm,proto3" json:"precipitation_mm,omitempty"`                            // Accumulated over the step
+	PrecipitationProbability float64                `protobuf:"fixed64,9,opt,name=precipitation_probability,json=precipitationProbability,proto3" json:"precipitation_probability,omitempty"` // 0 to 1
+	Description              string                 `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *ForecastPoint) Reset() {
+	*x = ForecastPoint{}
+	mi := &file_weather_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForecastPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForecastPoint) ProtoMessage() {}
+
+func (x *ForecastPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForecastPoint.ProtoReflect.Descriptor instead.
+func (*ForecastPoint) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ForecastPoint) GetDt() int64 {
+	if x != nil {
+		return x.Dt
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetTemperatureC() float64 {
+	if x != nil {
+		return x.TemperatureC
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetFeelsLikeC() float64 {
+	if x != nil {
+		return x.FeelsLikeC
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetHumidityPercent() float64 {
+	if x != nil {
+		return x.HumidityPercent
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetPressureHpa() float64 {
+	if x != nil {
+		return x.PressureHpa
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetWindSpeedMs() float64 {
+	if x != nil {
+		return x.WindSpeedMs
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetCloudCoverPercent() float64 {
+	if x != nil {
+		return x.CloudCoverPercent
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetPrecipitationMm() float64 {
+	if x != nil {
+		return x.PrecipitationMm
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetPrecipitationProbability() float64 {
+	if x != nil {
+		return x.PrecipitationProbability
+	}
+	return 0
+}
+
+func (x *ForecastPoint) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type GetForecastResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Provider             string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	StepHours            int32                  `protobuf:"varint,2,opt,name=step_hours,json=stepHours,proto3" json:"step_hours,omitempty"`
+	Points               []*ForecastPoint       `protobuf:"bytes,3,rep,name=points,proto3" json:"points,omitempty"`
+	TotalPrecipitationMm float64                `protobuf:"fixed64,4,opt,name=total_precipitation_mm,json=totalPrecipitationMm,proto3" json:"total_precipitation_mm,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetForecastResponse) Reset() {
+	*x = GetForecastResponse{}
+	mi := &file_weather_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetForecastResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetForecastResponse) ProtoMessage() {}
+
+func (x *GetForecastResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetForecastResponse.ProtoReflect.Descriptor instead.
+func (*GetForecastResponse) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetForecastResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *GetForecastResponse) GetStepHours() int32 {
+	if x != nil {
+		return x.StepHours
+	}
+	return 0
+}
+
+func (x *GetForecastResponse) GetPoints() []*ForecastPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *GetForecastResponse) GetTotalPrecipitationMm() float64 {
+	if x != nil {
+		return x.TotalPrecipitationMm
+	}
+	return 0
+}
+
+var File_weather_proto protoreflect.FileDescriptor
+
+const file_weather_proto_rawDesc = "" +
+	"\n" +
+	"\rweather.proto\x12\n" +
+	"weather.v1\"b\n" +
+	"\bLocation\x12\x1f\n" +
+	"\n" +
+	"polygon_id\x18\x01 \x01(\tH\x00R\tpolygonId\x12)\n" +
+	"\x05point\x18\x02 \x01(\v2\x11.weather.v1.PointH\x00R\x05pointB\n" +
+	"\n" +
+	"\blocation\"+\n" +
+	"\x05Point\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lon\x18\x02 \x01(\x01R\x03lon\"\xf4\x01\n" +
+	"\vObservation\x12\x1c\n" +
+	"\tparameter\x18\x01 \x01(\tR\tparameter\x12\x1f\n" +
+	"\vobserved_at\x18\x02 \x01(\x03R\n" +
+	"observedAt\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04unit\x18\x04 \x01(\tR\x04unit\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x1f\n" +
+	"\vis_forecast\x18\x06 \x01(\bR\n" +
+	"isForecast\x12.\n" +
+	"\x10confidence_score\x18\a \x01(\x01H\x00R\x0fconfidenceScore\x88\x01\x01B\x13\n" +
+	"\x11_confidence_score\"\xbb\x01\n" +
+	"\x16GetObservationsRequest\x120\n" +
+	"\blocation\x18\x01 \x01(\v2\x14.weather.v1.LocationR\blocation\x12\x1c\n" +
+	"\tparameter\x18\x02 \x01(\tR\tparameter\x12\x14\n" +
+	"\x05start\x18\x03 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x03R\x03end\x12)\n" +
+	"\x10include_forecast\x18\x05 \x01(\bR\x0fincludeForecast\"\xab\x01\n" +
+	"\x17GetObservationsResponse\x12!\n" +
+	"\flocation_key\x18\x01 \x01(\tR\vlocationKey\x12\x1c\n" +
+	"\tparameter\x18\x02 \x01(\tR\tparameter\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\x12;\n" +
+	"\fobservations\x18\x04 \x03(\v2\x17.weather.v1.ObservationR\fobservations\"\xa9\x01\n" +
+	"\x13GetAggregateRequest\x120\n" +
+	"\blocation\x18\x01 \x01(\v2\x14.weather.v1.LocationR\blocation\x12\x1c\n" +
+	"\tparameter\x18\x02 \x01(\tR\tparameter\x12\x14\n" +
+	"\x05start\x18\x03 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x03R\x03end\x12\x1a\n" +
+	"\bfunction\x18\x05 \x01(\tR\bfunction\"\xe8\x01\n" +
+	"\x14GetAggregateResponse\x12!\n" +
+	"\flocation_key\x18\x01 \x01(\tR\vlocationKey\x12\x1c\n" +
+	"\tparameter\x18\x02 \x01(\tR\tparameter\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\x12\x1a\n" +
+	"\bfunction\x18\x04 \x01(\tR\bfunction\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\x01R\x05value\x12\x1f\n" +
+	"\vvalue_count\x18\x06 \x01(\x05R\n" +
+	"valueCount\x12(\n" +
+	"\x10data_point_count\x18\a \x01(\x05R\x0edataPointCount\"Z\n" +
+	"\x12GetForecastRequest\x120\n" +
+	"\blocation\x18\x01 \x01(\v2\x14.weather.v1.LocationR\blocation\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\"\x92\x03\n" +
+	"\rForecastPoint\x12\x0e\n" +
+	"\x02dt\x18\x01 \x01(\x03R\x02dt\x12#\n" +
+	"\rtemperature_c\x18\x02 \x01(\x01R\ftemperatureC\x12 \n" +
+	"\ffeels_like_c\x18\x03 \x01(\x01R\n" +
+	"feelsLikeC\x12)\n" +
+	"\x10humidity_percent\x18\x04 \x01(\x01R\x0fhumidityPercent\x12!\n" +
+	"\fpressure_hpa\x18\x05 \x01(\x01R\vpressureHpa\x12\"\n" +
+	"\rwind_speed_ms\x18\x06 \x01(\x01R\vwindSpeedMs\x12.\n" +
+	"\x13cloud_cover_percent\x18\a \x01(\x01R\x11cloudCoverPercent\x12)\n" +
+	"\x10precipitation_mm\x18\b \x01(\x01R\x0fprecipitationMm\x12;\n" +
+	"\x19precipitation_probability\x18\t \x01(\x01R\x18precipitationProbability\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\"\xb9\x01\n" +
+	"\x13GetForecastResponse\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1d\n" +
+	"\n" +
+	"step_hours\x18\x02 \x01(\x05R\tstepHours\x121\n" +
+	"\x06points\x18\x03 \x03(\v2\x19.weather.v1.ForecastPointR\x06points\x124\n" +
+	"\x16total_precipitation_mm\x18\x04 \x01(\x01R\x14totalPrecipitationMm2\x8c\x02\n" +
+	"\vWeatherData\x12Z\n" +
+	"\x0fGetObservations\x12\".weather.v1.GetObservationsRequest\x1a#.weather.v1.GetObservationsResponse\x12Q\n" +
+	"\fGetAggregate\x12\x1f.weather.v1.GetAggregateRequest\x1a .weather.v1.GetAggregateResponse\x12N\n" +
+	"\vGetForecast\x12\x1e.weather.v1.GetForecastRequest\x1a\x1f.weather.v1.GetForecastResponseB\x12Z\x10agrisa/weatherpbb\x06proto3"
+
+var (
+	file_weather_proto_rawDescOnce sync.Once
+	file_weather_proto_rawDescData []byte
+)
+
+func file_weather_proto_rawDescGZIP() []byte {
+	file_weather_proto_rawDescOnce.Do(func() {
+		file_weather_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weather_proto_rawDesc), len(file_weather_proto_rawDesc)))
+	})
+	return file_weather_proto_rawDescData
+}
+
+var file_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_weather_proto_goTypes = []any{
+	(*Location)(nil),                // 0: weather.v1.Location
+	(*Point)(nil),                   // 1: weather.v1.Point
+	(*Observation)(nil),             // 2: weather.v1.Observation
+	(*GetObservationsRequest)(nil),  // 3: weather.v1.GetObservationsRequest
+	(*GetObservationsResponse)(nil), // 4: weather.v1.GetObservationsResponse
+	(*GetAggregateRequest)(nil),     // 5: weather.v1.GetAggregateRequest
+	(*GetAggregateResponse)(nil),    // 6: weather.v1.GetAggregateResponse
+	(*GetForecastRequest)(nil),      // 7: weather.v1.GetForecastRequest
+	(*ForecastPoint)(nil),           // 8: weather.v1.ForecastPoint
+	(*GetForecastResponse)(nil),     // 9: weather.v1.GetForecastResponse
+}
+var file_weather_proto_depIdxs = []int32{
+	1, // 0: weather.v1.Location.point:type_name -> weather.v1.Point
+	0, // 1: weather.v1.GetObservationsRequest.location:type_name -> weather.v1.Location
+	2, // 2: weather.v1.GetObservationsResponse.observations:type_name -> weather.v1.Observation
+	0, // 3: weather.v1.GetAggregateRequest.location:type_name -> weather.v1.Location
+	0, // 4: weather.v1.GetForecastRequest.location:type_name -> weather.v1.Location
+	8, // 5: weather.v1.GetForecastResponse.points:type_name -> weather.v1.ForecastPoint
+	3, // 6: weather.v1.WeatherData.GetObservations:input_type -> weather.v1.GetObservationsRequest
+	5, // 7: weather.v1.WeatherData.GetAggregate:input_type -> weather.v1.GetAggregateRequest
+	7, // 8: weather.v1.WeatherData.GetForecast:input_type -> weather.v1.GetForecastRequest
+	4, // 9: weather.v1.WeatherData.GetObservations:output_type -> weather.v1.GetObservationsResponse
+	6, // 10: weather.v1.WeatherData.GetAggregate:output_type -> weather.v1.GetAggregateResponse
+	9, // 11: weather.v1.WeatherData.GetForecast:output_type -> weather.v1.GetForecastResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_weather_proto_init() }
+func file_weather_proto_init() {
+	if File_weather_proto != nil {
+		return
+	}
+	file_weather_proto_msgTypes[0].OneofWrappers = []any{
+		(*Location_PolygonId)(nil),
+		(*Location_Point)(nil),
+	}
+	file_weather_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weather_proto_rawDesc), len(file_weather_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_proto_goTypes,
+		DependencyIndexes: file_weather_proto_depIdxs,
+		MessageInfos:      file_weather_proto_msgTypes,
+	}.Build()
+	File_weather_proto = out.File
+	file_weather_proto_goTypes = nil
+	file_weather_proto_depIdxs = nil
+}
@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: weather.proto
+
+package weatherpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WeatherData_GetObservations_FullMethodName = "/weather.v1.WeatherData/GetObservations"
+	WeatherData_GetAggregate_FullMethodName    = "/weather.v1.WeatherData/GetAggregate"
+	WeatherData_GetForecast_FullMethodName     = "/weather.v1.WeatherData/GetForecast"
+)
+
+// WeatherDataClient is the client API for WeatherData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WeatherData serves stored and forecast weather of farm locations to other services
+type WeatherDataClient interface {
+	// GetObservations lists the stored readings of one parameter at one location
+	GetObservations(ctx context.Context, in *GetObservationsRequest, opts ...grpc.CallOption) (*GetObservationsResponse, error)
+	// GetAggregate aggregates stored readings with a trigger condition aggregation function
+	GetAggregate(ctx context.Context, in *GetAggregateRequest, opts ...grpc.CallOption) (*GetAggregateResponse, error)
+	// GetForecast returns the hourly forecast of a location
+	GetForecast(ctx context.Context, in *GetForecastRequest, opts ...grpc.CallOption) (*GetForecastResponse, error)
+}
+
+type weatherDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWeatherDataClient(cc grpc.ClientConnInterface) WeatherDataClient {
+	return &weatherDataClient{cc}
+}
+
+func (c *weatherDataClient) GetObservations(ctx context.Context, in *GetObservationsRequest, opts ...grpc.CallOption) (*GetObservationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetObservationsResponse)
+	err := c.cc.Invoke(ctx, WeatherData_GetObservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherDataClient) GetAggregate(ctx context.Context, in *GetAggregateRequest, opts ...grpc.CallOption) (*GetAggregateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAggregateResponse)
+	err := c.cc.Invoke(ctx, WeatherData_GetAggregate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherDataClient) GetForecast(ctx context.Context, in *GetForecastRequest, opts ...grpc.CallOption) (*GetForecastResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetForecastResponse)
+	err := c.cc.Invoke(ctx, WeatherData_GetForecast_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WeatherDataServer is the server API for WeatherData service.
+// All implementations must embed UnimplementedWeatherDataServer
+// for forward compatibility.
+//
+// WeatherData serves stored and forecast weather of farm locations to other services
+type WeatherDataServer interface {
+	// GetObservations lists the stored readings of one parameter at one location
+	GetObservations(context.Context, *GetObservationsRequest) (*GetObservationsResponse, error)
+	// GetAggregate aggregates stored readings with a trigger condition aggregation function
+	GetAggregate(context.Context, *GetAggregateRequest) (*GetAggregateResponse, error)
+	// GetForecast returns the hourly forecast of a location
+	GetForecast(context.Context, *GetForecastRequest) (*GetForecastResponse, error)
+	mustEmbedUnimplementedWeatherDataServer()
+}
+
+// UnimplementedWeatherDataServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWeatherDataServer struct{}
+
+func (UnimplementedWeatherDataServer) GetObservations(context.Context, *GetObservationsRequest) (*GetObservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetObservations not implemented")
+}
+func (UnimplementedWeatherDataServer) GetAggregate(context.Context, *GetAggregateRequest) (*GetAggregateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAggregate not implemented")
+}
+func (UnimplementedWeatherDataServer) GetForecast(context.Context, *GetForecastRequest) (*GetForecastResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetForecast not implemented")
+}
+func (UnimplementedWeatherDataServer) mustEmbedUnimplementedWeatherDataServer() {}
+func (UnimplementedWeatherDataServer) testEmbeddedByValue()                     {}
+
+// UnsafeWeatherDataServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WeatherDataServer will
+// result in compilation errors.
+type UnsafeWeatherDataServer interface {
+	mustEmbedUnimplementedWeatherDataServer()
+}
+
+func RegisterWeatherDataServer(s grpc.ServiceRegistrar, srv WeatherDataServer) {
+	// If the following call pancis, it indicates UnimplementedWeatherDataServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WeatherData_ServiceDesc, srv)
+}
+
+func _WeatherData_GetObservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetObservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherDataServer).GetObservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherData_GetObservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherDataServer).GetObservations(ctx, req.(*GetObservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherData_GetAggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAggregateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherDataServer).GetAggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherData_GetAggregate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherDataServer).GetAggregate(ctx, req.(*GetAggregateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherData_GetForecast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetForecastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherDataServer).GetForecast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherData_GetForecast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherDataServer).GetForecast(ctx, req.(*GetForecastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WeatherData_ServiceDesc is the grpc.ServiceDesc for WeatherData service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WeatherData_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "weather.v1.WeatherData",
+	HandlerType: (*WeatherDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetObservations",
+			Handler:    _WeatherData_GetObservations_Handler,
+		},
+		{
+			MethodName: "GetAggregate",
+			Handler:    _WeatherData_GetAggregate_Handler,
+		},
+		{
+			MethodName: "GetForecast",
+			Handler:    _WeatherData_GetForecast_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "weather.proto",
+}